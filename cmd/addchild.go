@@ -1,32 +1,31 @@
 package cmd
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
-	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 
 	"github.com/eykd/prosemark-go/internal/binder"
 	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
 	"github.com/eykd/prosemark-go/internal/node"
 )
 
 // AddChildIO handles I/O for the add command.
 type AddChildIO interface {
-	ReadBinder(ctx context.Context, path string) ([]byte, error)
-	ScanProject(ctx context.Context, binderPath string) (*binder.Project, error)
-	WriteBinderAtomic(ctx context.Context, path string, data []byte) error
+	core.BinderIO
 }
 
 // newNodeIO defines the I/O capabilities required for --new mode node file management.
 type newNodeIO interface {
+	core.TxIO
 	WriteNodeFileAtomic(path string, content []byte) error
 	DeleteFile(path string) error
 	OpenEditor(editor, path string) error
@@ -41,22 +40,34 @@ type NewNodeAddChildIO interface {
 	newNodeIO
 }
 
-// nodeIDGenerator generates a new UUIDv7-based node filename.
+// nodeIDGenerator generates a new node filename in the given ID scheme.
 // Override in tests to inject specific values or simulate errors.
-var nodeIDGenerator = nodeIDv7Impl
+var nodeIDGenerator = nodeIDImpl
 
 // nowUTCFunc returns the current UTC time as a string for frontmatter timestamps.
 // Override in tests to inject specific values or simulate clock behavior.
 var nowUTCFunc = node.NowUTC
 
-// nodeIDv7Impl calls uuid.NewV7 to produce a UUIDv7-based node filename.
+// nodeIDImpl asks scheme for a new ID and returns it as a node filename.
 // Excluded from coverage because it wraps an external entropy source.
-func nodeIDv7Impl() (string, error) {
-	id, err := uuid.NewV7()
+func nodeIDImpl(scheme node.IDScheme) (string, error) {
+	id, err := scheme.NewID(time.Now())
 	if err != nil {
 		return "", err
 	}
-	return id.String() + ".md", nil
+	return id + ".md", nil
+}
+
+// projectIDScheme returns the node ID scheme selected by the project whose
+// binder is binderPath. A scan failure yields the default scheme; the
+// operation that follows scans again and reports it. An unknown scheme is
+// an error, so no IDs are minted in the wrong one.
+func projectIDScheme(ctx context.Context, io core.BinderIO, binderPath string) (node.IDScheme, error) {
+	proj, err := io.ScanProject(ctx, binderPath)
+	if err != nil {
+		return node.DefaultIDScheme, nil
+	}
+	return core.ProjectIDScheme(proj)
 }
 
 // NewAddChildCmd creates the add subcommand.
@@ -66,18 +77,20 @@ func NewAddChildCmd(io NewNodeAddChildIO) *cobra.Command {
 
 func newAddChildCmdWithGetCWD(io NewNodeAddChildIO, getwd func() (string, error)) *cobra.Command {
 	var (
-		parent   string
-		target   string
-		title    string
-		first    bool
-		at       int
-		before   string
-		after    string
-		force    bool
-		jsonMode bool
-		newMode  bool
-		synopsis string
-		editMode bool
+		parent     string
+		target     string
+		title      string
+		pos        positionFlags
+		force      bool
+		jsonMode   bool
+		newMode    bool
+		synopsis   string
+		editMode   bool
+		outline    string
+		nodeType   string
+		style      string
+		forceParse bool
+		dryRun     bool
 	)
 
 	cmd := &cobra.Command{
@@ -91,86 +104,83 @@ func newAddChildCmdWithGetCWD(io NewNodeAddChildIO, getwd func() (string, error)
 				return err
 			}
 
-			ctx := cmd.Context()
-
-			binderBytes, err := io.ReadBinder(ctx, binderPath)
-			if err != nil {
-				return fmt.Errorf("reading binder: %w", err)
+			if replayed, err := replayRecordedOp(cmd, io, binderPath, jsonMode); replayed || err != nil {
+				return err
 			}
 
-			proj, err := io.ScanProject(ctx, binderPath)
+			point, err := pos.resolve(cmd)
 			if err != nil {
-				return emitOPE009AndError(cmd, jsonMode, err)
+				return err
 			}
 
-			if err := checkConflictingPositionFlags(cmd, first, before, after); err != nil {
-				return err
+			if outline != "" && (target != "" || title != "" || synopsis != "" || editMode || nodeType != "") {
+				return fmt.Errorf("--outline cannot be combined with --target, --title, --synopsis, --type, or --edit")
 			}
 
 			if synopsis != "" && !newMode {
 				return fmt.Errorf("--synopsis requires --new: synopsis frontmatter can only be written when creating a new node file")
 			}
 
-			position := "last"
-			if first {
-				position = "first"
+			if nodeType != "" && !newMode {
+				return fmt.Errorf("--type requires --new: type frontmatter can only be written when creating a new node file")
+			}
+
+			if dryRun && editMode {
+				return fmt.Errorf("--dry-run cannot be combined with --edit")
+			}
+
+			switch style {
+			case ops.LinkStyleInline, ops.LinkStyleReference, ops.LinkStyleWikilink, ops.LinkStyleAuto:
+			default:
+				return fmt.Errorf("unknown --style %q: want inline, reference, wikilink, or auto", style)
 			}
 
 			params := binder.AddChildParams{
 				ParentSelector: parent,
 				Target:         target,
 				Title:          title,
-				Position:       position,
-				Before:         before,
-				After:          after,
+				Position:       point.Position,
+				At:             point.At,
+				Before:         point.Before,
+				After:          point.After,
 				Force:          force,
+				Style:          style,
+				ForceParse:     forceParse,
+				DryRun:         dryRun,
 			}
-			if cmd.Flags().Changed("at") {
-				params.At = &at
+			ctx := cmd.Context()
+			if outline != "" {
+				return runOutlineMode(ctx, cmd, io, binderPath, params, outline, newMode, jsonMode)
 			}
 
 			if newMode {
-				if err := node.ValidateNewNodeInput(target, title, synopsis); err != nil {
+				scheme, err := projectIDScheme(ctx, io, binderPath)
+				if err != nil {
+					return err
+				}
+				if err := node.ValidateNewNodeInput(scheme, target, title, synopsis); err != nil {
 					return err
 				}
 				if target == "" {
-					id, genErr := nodeIDGenerator()
+					id, genErr := nodeIDGenerator(scheme)
 					if genErr != nil {
 						return fmt.Errorf("generating node ID: %w", genErr)
 					}
 					params.Target = id
 				}
-				return runNewMode(ctx, cmd, io, binderPath, binderBytes, proj, params, synopsis, editMode)
-			}
-
-			modifiedBytes, diags := ops.AddChild(ctx, binderBytes, proj, params)
-			if diags == nil {
-				diags = []binder.Diagnostic{}
-			}
-
-			changed := !bytes.Equal(binderBytes, modifiedBytes)
-
-			if jsonMode {
-				out := binder.OpResult{Version: "1", Changed: changed, Diagnostics: diags}
-				if err := json.NewEncoder(cmd.OutOrStdout()).Encode(out); err != nil {
-					return fmt.Errorf("encoding output: %w", err)
-				}
-			} else {
-				printDiagnostics(cmd, diags)
-			}
-
-			if hasDiagnosticError(diags) {
-				return fmt.Errorf("add has errors")
+				fm := node.Frontmatter{Title: params.Title, Type: nodeType, Synopsis: synopsis}
+				withJournalOps(cmd, binder.NewOpSpec(binder.OpAdd, params))
+				return runNewMode(ctx, cmd, io, binderPath, params, fm, editMode, jsonMode)
 			}
 
-			if changed {
-				if err = io.WriteBinderAtomic(ctx, binderPath, modifiedBytes); err != nil {
-					return fmt.Errorf("writing binder: %w", err)
-				}
+			withJournalOps(cmd, binder.NewOpSpec(binder.OpAdd, params))
+			res, err := core.AddChild(ctx, io, binderPath, params)
+			if err := finishBinderOp(cmd, io, binderPath, jsonMode, res, err); err != nil {
+				return err
 			}
 
-			if !jsonMode {
-				if changed {
+			if !jsonMode && !dryRun {
+				if res.Changed {
 					if _, err := fmt.Fprintln(cmd.OutOrStdout(), "Added "+sanitizePath(target)+" to "+sanitizePath(binderPath)); err != nil {
 						return fmt.Errorf("writing output: %w", err)
 					}
@@ -185,19 +195,24 @@ func newAddChildCmdWithGetCWD(io NewNodeAddChildIO, getwd func() (string, error)
 		},
 	}
 
-	cmd.Flags().String("project", "", "project directory containing _binder.md (default: current directory)")
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
 	cmd.Flags().StringVar(&parent, "parent", "", "Parent selector")
 	cmd.Flags().StringVar(&target, "target", "", "Target path for new child")
 	cmd.Flags().StringVar(&title, "title", "", "Display title (empty = derive from stem)")
-	cmd.Flags().BoolVar(&first, "first", false, "Insert as first child")
-	cmd.Flags().IntVar(&at, "at", 0, "Zero-based insertion index")
-	cmd.Flags().StringVar(&before, "before", "", "Insert before selector")
-	cmd.Flags().StringVar(&after, "after", "", "Insert after selector")
+	addPositionFlags(cmd, &pos)
 	cmd.Flags().BoolVar(&force, "force", false, "Allow duplicate target")
 	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
-	cmd.Flags().BoolVar(&newMode, "new", false, "Create a new UUID node file")
+	addFormatFlag(cmd)
+	addForceParseFlag(cmd, &forceParse)
+	addDryRunFlag(cmd, &dryRun)
+	addDiffFlag(cmd)
+	addOpIDFlag(cmd)
+	cmd.Flags().BoolVar(&newMode, "new", false, "Create a new node file named by the project's ID scheme (UUIDv7 unless id_scheme is set)")
 	cmd.Flags().StringVar(&synopsis, "synopsis", "", "Set the synopsis frontmatter field (≤2000 chars)")
+	cmd.Flags().StringVar(&nodeType, "type", "", "Set the node type (chapter, scene, note, character, place, reference, or a type from .prosemark.yml) and use its template")
 	cmd.Flags().BoolVar(&editMode, "edit", false, "Open node file in $EDITOR after creation")
+	cmd.Flags().StringVar(&style, "style", ops.LinkStyleInline, "Link style for new entries: inline, reference, wikilink, or auto (match siblings)")
+	cmd.Flags().StringVar(&outline, "outline", "", "Add a nested-list outline as a subtree (path, or - for stdin)")
 
 	return cmd
 }
@@ -216,109 +231,139 @@ func refreshNodeUpdated(io nodeRefresher, path string) error {
 	if err != nil {
 		return fmt.Errorf("reading node file after edit: %w", err)
 	}
-	fm, body, err := node.ParseFrontmatter(content)
+	refreshed, _, err := stampUpdated(content)
 	if err != nil {
 		return fmt.Errorf("parsing node file after edit: %w", err)
 	}
-	fm.Updated = nowUTCFunc()
-	refreshed := append(node.SerializeFrontmatter(fm), body...)
 	if err := io.WriteNodeFileAtomic(path, refreshed); err != nil {
 		return fmt.Errorf("refreshing node file after edit: %w", err)
 	}
 	return nil
 }
 
+// stampUpdated returns the node file content with its 'updated'
+// frontmatter field set to the current UTC time, and that time. Only the
+// field is rewritten, so fields pmk does not know are kept.
+func stampUpdated(content []byte) ([]byte, string, error) {
+	doc, err := node.ReadFrontmatter(content)
+	if err != nil {
+		return nil, "", err
+	}
+	now := nowUTCFunc()
+	if err := node.SetField(doc, "updated", now); err != nil {
+		return nil, "", err
+	}
+	return node.WriteFrontmatter(doc), now, nil
+}
+
 // runNewMode handles the --new flag workflow: creates a UUID node file, updates
 // the binder, and optionally opens an editor to populate the file.
 // params.Target must already be set to a valid UUID filename before calling.
-func runNewMode(ctx context.Context, cmd *cobra.Command, io NewNodeAddChildIO, binderPath string, binderBytes []byte, proj *binder.Project, params binder.AddChildParams, synopsis string, editMode bool) error {
-	uuidStem := strings.TrimSuffix(params.Target, ".md")
-	binderDir := filepath.Dir(binderPath)
-	nodePath := filepath.Join(binderDir, params.Target)
-	now := nowUTCFunc()
-	fm := node.Frontmatter{
-		ID:       uuidStem,
-		Title:    params.Title,
-		Synopsis: synopsis,
-		Created:  now,
-		Updated:  now,
+// fm supplies the title, type, and synopsis; a typed node starts from its
+// type's template. The outcome is reported through finishBinderOp, as for
+// the other add modes.
+func runNewMode(ctx context.Context, cmd *cobra.Command, io NewNodeAddChildIO, binderPath string, params binder.AddChildParams, fm node.Frontmatter, editMode, jsonMode bool) error {
+	res, err := core.AddNewNode(ctx, io, binderPath, params, fm, nil, nowUTCFunc())
+	var opRes *binder.OpResult
+	if res != nil {
+		opRes = &res.OpResult
+		if editMode && err == nil && !params.DryRun && !hasDiagnosticError(res.Diagnostics) {
+			if err := editNewNode(ctx, io, binderPath, res); err != nil {
+				return err
+			}
+		}
 	}
-	content := node.SerializeFrontmatter(fm)
-
-	if err := io.WriteNodeFileAtomic(nodePath, content); err != nil {
-		return fmt.Errorf("creating node file: %w", err)
+	if err := finishBinderOp(cmd, io, binderPath, jsonMode, opRes, err); err != nil {
+		return err
 	}
-
-	modifiedBytes, diags := ops.AddChild(ctx, binderBytes, proj, params)
-	if diags == nil {
-		diags = []binder.Diagnostic{}
+	if jsonMode || params.DryRun {
+		return nil
 	}
-
-	printDiagnostics(cmd, diags)
-
-	if hasDiagnosticError(diags) {
-		rollbackErr := io.DeleteFile(nodePath)
-		return errors.Join(fmt.Errorf("add has errors"), rollbackErr)
+	if _, err := fmt.Fprintln(cmd.OutOrStdout(), "Created "+sanitizePath(params.Target)+" in "+sanitizePath(binderPath)); err != nil {
+		return fmt.Errorf("writing output: %w", err)
 	}
+	return nil
+}
 
-	changed := !bytes.Equal(binderBytes, modifiedBytes)
-	if changed {
-		if writeErr := io.WriteBinderAtomic(ctx, binderPath, modifiedBytes); writeErr != nil {
-			if rollbackErr := io.DeleteFile(nodePath); rollbackErr != nil {
-				return fmt.Errorf("writing binder: %w; rollback also failed: %v", writeErr, rollbackErr)
-			}
-			return fmt.Errorf("writing binder: %w", writeErr)
-		}
+// editNewNode opens the node file res created in the project's editor, then
+// stamps its updated field. When the editor cannot be opened the node file
+// is removed and the binder restored.
+func editNewNode(ctx context.Context, io NewNodeAddChildIO, binderPath string, res *core.NewNodeResult) error {
+	editor, err := fsio.EditorCommand(filepath.Dir(binderPath))
+	if err != nil {
+		return err
 	}
-
-	if editMode {
-		editor := os.Getenv("EDITOR")
-		if len(strings.Fields(editor)) == 0 {
-			return fmt.Errorf("$EDITOR is not set")
-		}
-		if err := io.OpenEditor(editor, nodePath); err != nil {
-			_ = io.DeleteFile(nodePath)
-			if changed {
-				if rollbackErr := io.WriteBinderAtomic(ctx, binderPath, binderBytes); rollbackErr != nil {
-					return fmt.Errorf("opening editor: %w; binder rollback also failed: %v", err, rollbackErr)
-				}
+	if len(strings.Fields(editor)) == 0 {
+		return errEditorNotSet
+	}
+	if err := io.OpenEditor(editor, res.NodePath); err != nil {
+		_ = io.DeleteFile(res.NodePath)
+		if res.Changed {
+			if rollbackErr := io.WriteBinderAtomic(ctx, binderPath, res.PrevBinder); rollbackErr != nil {
+				return fmt.Errorf("opening editor: %w; binder rollback also failed: %v", err, rollbackErr)
 			}
-			return fmt.Errorf("opening editor: %w", err)
-		}
-		// Re-read the file after the editor exits so body text is preserved,
-		// then stamp the 'updated' frontmatter field and write back atomically.
-		if err := refreshNodeUpdated(io, nodePath); err != nil {
-			return err
 		}
+		return fmt.Errorf("opening editor: %w", err)
 	}
+	// Re-read the file after the editor exits so body text is preserved,
+	// then stamp the 'updated' frontmatter field and write back atomically.
+	return refreshNodeUpdated(io, res.NodePath)
+}
 
-	if _, err := fmt.Fprintln(cmd.OutOrStdout(), "Created "+sanitizePath(params.Target)+" in "+sanitizePath(binderPath)); err != nil {
-		return fmt.Errorf("writing output: %w", err)
+// readOutline reads the outline at path, or cmd's stdin when path is "-".
+func readOutline(cmd *cobra.Command, path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(cmd.InOrStdin())
 	}
+	return fsio.ReadFile(path)
+}
 
+// runOutlineMode handles the --outline flag: inserts every outline item as a
+// subtree under params.ParentSelector in one binder write (see
+// core.AddOutline), journaling one add operation per item. With newMode each
+// item gets a node file named by the project's ID scheme.
+func runOutlineMode(ctx context.Context, cmd *cobra.Command, fio NewNodeAddChildIO, binderPath string, params binder.AddChildParams, outlinePath string, newMode, jsonMode bool) error {
+	src, err := readOutline(cmd, outlinePath)
+	if err != nil {
+		return fmt.Errorf("reading outline: %w", err)
+	}
+	outlineParams := core.OutlineParams{Params: params, Outline: src, Now: nowUTCFunc()}
+	if newMode {
+		outlineParams.NewID = nodeIDGenerator
+	}
+	res, err := core.AddOutline(ctx, fio, binderPath, outlineParams)
+	var opRes *binder.OpResult
+	if res != nil {
+		withJournalOps(cmd, res.Ops...)
+		opRes = &res.OpResult
+	}
+	if err := finishBinderOp(cmd, fio, binderPath, jsonMode, opRes, err); err != nil {
+		return err
+	}
+	if !jsonMode && !params.DryRun {
+		if _, err := fmt.Fprintf(cmd.OutOrStdout(), "Added %d nodes to %s\n", res.Count, sanitizePath(binderPath)); err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+	}
 	return nil
 }
 
 // fileAddChildIO implements NewNodeAddChildIO using OS file I/O.
-type fileAddChildIO struct{ binderLocker }
+type fileAddChildIO struct {
+	fileProjectIO
+	binderLocker
+	opJournaler
+}
 
 func newDefaultAddChildIO() *fileAddChildIO {
 	return &fileAddChildIO{}
 }
 
-// ReadBinder reads the binder file at path.
-func (w *fileAddChildIO) ReadBinder(_ context.Context, path string) ([]byte, error) {
-	return readBinderSizeLimitedImpl(path)
-}
-
-// ScanProject scans the project directory for .md files.
-func (w *fileAddChildIO) ScanProject(ctx context.Context, binderPath string) (*binder.Project, error) {
-	return ScanProjectImpl(ctx, binderPath)
-}
-
-// WriteBinderAtomic writes data to path atomically via a temp file.
-func (w *fileAddChildIO) WriteBinderAtomic(ctx context.Context, path string, data []byte) error {
-	return w.WriteBinderAtomicImpl(ctx, path, data)
+// WriteBinderAtomic acquires the per-file binder lock, merges incoming data
+// with current on-disk content, and writes atomically. This prevents lost
+// updates when concurrent commands start from the same stale snapshot.
+func (w *fileAddChildIO) WriteBinderAtomic(_ context.Context, path string, data []byte) error {
+	return writeBinderAtomicMergeImpl(path, data)
 }
 
 // writeBinderAtomicMergeImpl acquires the per-file binder lock, reads the current
@@ -331,105 +376,40 @@ func writeBinderAtomicMergeImpl(path string, data []byte) error {
 	}
 	defer func() { _ = unlock() }()
 
-	if fi, statErr := os.Stat(path); statErr == nil {
-		if fi.Mode().Perm()&0200 == 0 {
-			return fmt.Errorf("binder file is read-only")
-		}
+	if err := fsio.CheckWritable(path); err != nil {
+		return err
 	}
 
-	current, readErr := os.ReadFile(path)
-	if readErr != nil && !os.IsNotExist(readErr) {
+	current, _, readErr := fsio.ReadFileIfExists(path)
+	if readErr != nil {
 		return fmt.Errorf("reading current binder: %w", readErr)
 	}
 	merged := mergeBinderLines(current, data)
-	return writeFileAtomicDirectImpl(path, ".binder", merged)
-}
-
-// writeBinderDirectImpl writes binder data to path atomically without merging.
-// Commands use this to ensure the exact post-operation content is written,
-// regardless of any concurrent writes that may have occurred.
-func writeBinderDirectImpl(path string, data []byte) error {
-	return writeFileAtomicDirectImpl(path, ".binder", data)
-}
-
-// writeFileAtomicDirectImpl writes data to path atomically via a temp file and rename.
-func writeFileAtomicDirectImpl(path, tmpPrefix string, data []byte) error {
-	dir := filepath.Dir(path)
-	tmp, err := os.CreateTemp(dir, tmpPrefix+"-*.tmp")
-	if err != nil {
-		return fmt.Errorf("creating temp file: %w", err)
-	}
-	tmpName := tmp.Name()
-	if _, err = tmp.Write(data); err != nil {
-		_ = tmp.Close()
-		_ = os.Remove(tmpName)
-		return fmt.Errorf("writing temp file: %w", err)
-	}
-	if err = tmp.Close(); err != nil {
-		_ = os.Remove(tmpName)
-		return fmt.Errorf("closing temp file: %w", err)
-	}
-	if err = os.Rename(tmpName, path); err != nil {
-		_ = os.Remove(tmpName)
-		return fmt.Errorf("renaming temp file: %w", err)
-	}
-	return nil
-}
-
-// writeBinderCheckedImpl checks that path is writable (if it exists) then
-// writes data atomically. Shared by all file-IO WriteBinderAtomicImpl methods.
-func writeBinderCheckedImpl(path string, data []byte) error {
-	if fi, statErr := os.Stat(path); statErr == nil {
-		if fi.Mode().Perm()&0200 == 0 {
-			return fmt.Errorf("binder file is read-only")
-		}
-	}
-	return writeBinderDirectImpl(path, data)
-}
-
-// WriteBinderAtomicImpl acquires the per-file binder lock, merges incoming data
-// with current on-disk content, and writes atomically. This prevents lost updates
-// when concurrent commands start from the same stale snapshot.
-func (w *fileAddChildIO) WriteBinderAtomicImpl(ctx context.Context, path string, data []byte) error {
-	return writeBinderAtomicMergeImpl(path, data)
+	return fsio.WriteFileAtomic(path, ".binder", merged)
 }
 
 // WriteNodeFileAtomic writes content to path atomically (for --new mode).
 func (w *fileAddChildIO) WriteNodeFileAtomic(path string, content []byte) error {
-	return w.WriteNodeFileAtomicImpl(path, content)
-}
-
-// WriteNodeFileAtomicImpl performs the atomic write of a new node file.
-func (w *fileAddChildIO) WriteNodeFileAtomicImpl(path string, content []byte) error {
-	return writeFileAtomicDirectImpl(path, ".node", content)
-}
-
-// DeleteFile removes the file at path (used for rollback in --new mode).
-func (w *fileAddChildIO) DeleteFile(path string) error {
-	return w.DeleteFileImpl(path)
+	return fsio.WriteFileAtomic(path, ".node", content)
 }
 
-// DeleteFileImpl removes the file at path using os.Remove.
-func (w *fileAddChildIO) DeleteFileImpl(path string) error {
-	return os.Remove(path)
+// BeginTx starts a journaled file transaction in projectDir for --new mode,
+// whose binder write merges with the binder on disk as WriteBinderAtomic's
+// does.
+func (w *fileAddChildIO) BeginTx(projectDir string) (core.FileTx, error) {
+	tx, err := fsio.BeginTx(projectDir, fsio.SyncAlways)
+	if err != nil {
+		return nil, err
+	}
+	return &mergingTx{Tx: tx, ctx: context.Background()}, nil
 }
 
 // OpenEditor opens the file at path in the named editor.
 func (w *fileAddChildIO) OpenEditor(editor, path string) error {
-	return w.OpenEditorImpl(editor, path)
-}
-
-// OpenEditorImpl launches the editor process, splitting $EDITOR with strings.Fields.
-func (w *fileAddChildIO) OpenEditorImpl(editor, path string) error {
-	return openEditorImpl(editor, path)
+	return fsio.OpenEditor(editor, path)
 }
 
 // ReadNodeFile reads the node file at path.
 func (w *fileAddChildIO) ReadNodeFile(path string) ([]byte, error) {
-	return w.ReadNodeFileImpl(path)
-}
-
-// ReadNodeFileImpl reads the node file at path using os.ReadFile.
-func (w *fileAddChildIO) ReadNodeFileImpl(path string) ([]byte, error) {
-	return os.ReadFile(path)
+	return fsio.ReadFile(path)
 }
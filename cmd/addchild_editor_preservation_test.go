@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
 )
 
 // mockAddChildIOWithEditorEdits simulates a user editing the node file in
@@ -26,6 +27,10 @@ type mockAddChildIOWithEditorEdits struct {
 	editorHasOpened bool
 }
 
+func (m *mockAddChildIOWithEditorEdits) BeginTx(string) (core.FileTx, error) {
+	return beginMemTx(m)
+}
+
 // OpenEditor marks that the editor ran and records the call.
 func (m *mockAddChildIOWithEditorEdits) OpenEditor(editor, path string) error {
 	m.editorHasOpened = true
@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
 )
 
 // mockAddChildIOWithBinderHistory wraps mockAddChildIOWithNew and records every
@@ -33,6 +34,10 @@ type mockAddChildIOWithBinderHistory struct {
 	binderWrittenHistory [][]byte
 }
 
+func (m *mockAddChildIOWithBinderHistory) BeginTx(string) (core.FileTx, error) {
+	return beginMemTx(m)
+}
+
 func (m *mockAddChildIOWithBinderHistory) WriteBinderAtomic(ctx context.Context, path string, data []byte) error {
 	m.binderWrittenHistory = append(m.binderWrittenHistory, append([]byte(nil), data...))
 	return m.mockAddChildIOWithNew.mockAddChildIO.WriteBinderAtomic(ctx, path, data)
@@ -151,6 +156,10 @@ type mockAddChildIOWithRollbackWriteFail struct {
 	rollbackWriteErr error
 }
 
+func (m *mockAddChildIOWithRollbackWriteFail) BeginTx(string) (core.FileTx, error) {
+	return beginMemTx(m)
+}
+
 func (m *mockAddChildIOWithRollbackWriteFail) WriteBinderAtomic(ctx context.Context, path string, data []byte) error {
 	m.writeCallCount++
 	if m.writeCallCount > 1 && m.rollbackWriteErr != nil {
@@ -63,14 +63,14 @@ func TestNewAddChildCmd_NewMode_WhitespaceVariantsRejected(t *testing.T) {
 	}
 }
 
-// TestFileAddChildIO_OpenEditorImpl_WhitespaceOnlyReturnsError verifies that
-// the shared openEditorImpl safety net — used by both fileAddChildIO and
-// fileEditIO — rejects a whitespace-only editor string at the Impl level.
+// TestFileAddChildIO_OpenEditor_WhitespaceOnlyReturnsError verifies that
+// the shared fsio.OpenEditor safety net — used by both fileAddChildIO and
+// fileEditIO — rejects a whitespace-only editor string at the IO level.
 // This is the inner guard: len(strings.Fields("   ")) == 0.
 //
 // The outer command-level check prevents whitespace from reaching this point
-// during normal use, but this test ensures the Impl-level guard is intact.
-func TestFileAddChildIO_OpenEditorImpl_WhitespaceOnlyReturnsError(t *testing.T) {
+// during normal use, but this test ensures the IO-level guard is intact.
+func TestFileAddChildIO_OpenEditor_WhitespaceOnlyReturnsError(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "test.md")
 	if err := os.WriteFile(path, []byte("draft"), 0600); err != nil {
@@ -78,14 +78,14 @@ func TestFileAddChildIO_OpenEditorImpl_WhitespaceOnlyReturnsError(t *testing.T)
 	}
 
 	fio := newDefaultAddChildIO()
-	// Calling OpenEditorImpl directly with whitespace bypasses the command-level
+	// Calling OpenEditor directly with whitespace bypasses the command-level
 	// check and exercises the inner guard: strings.Fields("   ") = [] → error.
-	if err := fio.OpenEditorImpl("   ", path); err == nil {
-		t.Fatal("expected error when OpenEditorImpl called with whitespace-only editor, got nil")
+	if err := fio.OpenEditor("   ", path); err == nil {
+		t.Fatal("expected error when OpenEditor called with whitespace-only editor, got nil")
 	}
 }
 
-// TestFileAddChildIO_OpenEditor_MultiWordEditor verifies that OpenEditorImpl
+// TestFileAddChildIO_OpenEditor_MultiWordEditor verifies that OpenEditor
 // splits a multi-word $EDITOR value on spaces, executing only the first token
 // as the command name. The original buggy implementation called
 // exec.Command(editor, path) which fails when editor contains spaces because no
@@ -109,7 +109,7 @@ func TestFileAddChildIO_OpenEditor_MultiWordEditor(t *testing.T) {
 // TestNewAddChildCmd_NewMode_EditorShellSplit verifies that the add command
 // passes the full $EDITOR string to OpenEditor. This mirrors the contract in
 // TestNewEditCmd_EditorShellSplit: the command passes the raw value, and
-// OpenEditorImpl is responsible for splitting with strings.Fields.
+// fsio.OpenEditor is responsible for splitting with strings.Fields.
 func TestNewAddChildCmd_NewMode_EditorShellSplit(t *testing.T) {
 	t.Setenv("EDITOR", "code --wait")
 	mock := &mockAddChildIOWithNew{
@@ -2,12 +2,16 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/node"
 )
 
 // TestNewAddChildCmd_NewMode_UUIDGenError verifies that UUID generation failure
@@ -15,7 +19,7 @@ import (
 func TestNewAddChildCmd_NewMode_UUIDGenError(t *testing.T) {
 	orig := nodeIDGenerator
 	defer func() { nodeIDGenerator = orig }()
-	nodeIDGenerator = func() (string, error) {
+	nodeIDGenerator = func(node.IDScheme) (string, error) {
 		return "", errors.New("entropy exhausted")
 	}
 
@@ -232,6 +236,10 @@ type mockAddChildIOWithFailingRefresh struct {
 	writeCallCount int
 }
 
+func (m *mockAddChildIOWithFailingRefresh) BeginTx(string) (core.FileTx, error) {
+	return beginMemTx(m)
+}
+
 // WriteNodeFileAtomic succeeds on the first call and returns refreshErr on
 // subsequent calls (simulating a disk failure during the post-editor refresh).
 func (m *mockAddChildIOWithFailingRefresh) WriteNodeFileAtomic(path string, content []byte) error {
@@ -279,14 +287,11 @@ func TestNewAddChildCmd_NewMode_RefreshNodeWriteError(t *testing.T) {
 	}
 }
 
-// TestNewAddChildCmd_NewMode_ErrorDiagnosticRollsBackNode verifies that when
+// TestNewAddChildCmd_NewMode_ErrorDiagnosticWritesNothing verifies that when
 // ops.AddChild produces an error-severity diagnostic (e.g. OPE001: parent not
-// found) in --new mode, the command returns an error and rolls back the node
-// file that was already created on disk.
-//
-// The implementation inspects diagnostic severity and rolls back the node file
-// when ops.AddChild returns an error-severity diagnostic.
-func TestNewAddChildCmd_NewMode_ErrorDiagnosticRollsBackNode(t *testing.T) {
+// found) in --new mode, the command returns an error without creating the
+// node file: the binder edit is checked before the file transaction starts.
+func TestNewAddChildCmd_NewMode_ErrorDiagnosticWritesNothing(t *testing.T) {
 	mock := &mockAddChildIOWithNew{
 		mockAddChildIO: mockAddChildIO{
 			binderBytes: emptyBinder(),
@@ -308,11 +313,8 @@ func TestNewAddChildCmd_NewMode_ErrorDiagnosticRollsBackNode(t *testing.T) {
 	if err == nil {
 		t.Error("expected error when ops.AddChild reports error diagnostics in --new mode")
 	}
-	if mock.nodeWrittenPath == "" {
-		t.Error("expected WriteNodeFileAtomic to be called before the diagnostic check")
-	}
-	if mock.deletedPath == "" {
-		t.Error("expected DeleteFile to roll back the node file on ops error diagnostic")
+	if mock.nodeWrittenPath != "" || mock.deletedPath != "" {
+		t.Errorf("node file written %q and deleted %q, want neither", mock.nodeWrittenPath, mock.deletedPath)
 	}
 }
 
@@ -347,3 +349,269 @@ func TestNewAddChildCmd_NewMode_EmptyEditorEnv(t *testing.T) {
 		t.Error("expected node file to be created before editor check")
 	}
 }
+
+// TestNewAddChildCmd_NewMode_ScanProjectError verifies that a scan failure is
+// reported as OPE009 and no node file is written.
+func TestNewAddChildCmd_NewMode_ScanProjectError(t *testing.T) {
+	mock := &mockAddChildIOWithNew{
+		mockAddChildIO: mockAddChildIO{binderBytes: emptyBinder(), projectErr: errors.New("disk error")},
+	}
+	c := NewAddChildCmd(mock)
+	c.SetOut(new(bytes.Buffer))
+	errOut := new(bytes.Buffer)
+	c.SetErr(errOut)
+	c.SetArgs([]string{"--new", "--parent", ".", "--title", "T", "--project", "."})
+
+	err := c.Execute()
+	if err == nil || !strings.Contains(err.Error(), "operation failed: disk error") {
+		t.Fatalf("error = %v, want scan failure", err)
+	}
+	if !strings.Contains(errOut.String(), "OPE009") {
+		t.Errorf("stderr = %q, want OPE009", errOut.String())
+	}
+	if mock.nodeWrittenPath != "" {
+		t.Errorf("node file written to %q on scan failure", mock.nodeWrittenPath)
+	}
+}
+
+// TestNewAddChildCmd_NewMode_ProjectIDScheme verifies that --new names and
+// validates node files by the project's id_scheme.
+func TestNewAddChildCmd_NewMode_ProjectIDScheme(t *testing.T) {
+	orig := nodeIDGenerator
+	defer func() { nodeIDGenerator = orig }()
+	var gotScheme node.IDScheme
+	nodeIDGenerator = func(scheme node.IDScheme) (string, error) {
+		gotScheme = scheme
+		return "01J5Z3V0A4Q8W2E6R9T1Y3X5H7.md", nil
+	}
+
+	newMock := func() *mockAddChildIOWithNew {
+		return &mockAddChildIOWithNew{
+			mockAddChildIO: mockAddChildIO{
+				binderBytes: emptyBinder(),
+				project:     &binder.Project{Files: []string{}, BinderDir: ".", IDScheme: "ulid"},
+			},
+		}
+	}
+	run := func(mock *mockAddChildIOWithNew, args ...string) error {
+		c := NewAddChildCmd(mock)
+		c.SetOut(new(bytes.Buffer))
+		c.SetErr(new(bytes.Buffer))
+		c.SetArgs(append([]string{"--new", "--title", "Node", "--parent", ".", "--project", "."}, args...))
+		return c.Execute()
+	}
+
+	mock := newMock()
+	if err := run(mock); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotScheme != node.ULIDIDs {
+		t.Errorf("generator scheme = %v, want ulid", gotScheme)
+	}
+	if !strings.HasSuffix(mock.nodeWrittenPath, "01J5Z3V0A4Q8W2E6R9T1Y3X5H7.md") {
+		t.Errorf("node written to %q, want the ULID filename", mock.nodeWrittenPath)
+	}
+
+	err := run(newMock(), "--target", "0192f0c1-3e7a-7000-8000-5a4b3c2d1e0f.md")
+	if err == nil || !strings.Contains(err.Error(), "valid ULID filename") {
+		t.Errorf("UUID --target in a ulid project: err = %v, want ULID filename error", err)
+	}
+}
+
+// TestNewAddChildCmd_NewMode_UnknownIDScheme verifies that an unknown
+// id_scheme is an error rather than a silent fallback to the default.
+func TestNewAddChildCmd_NewMode_UnknownIDScheme(t *testing.T) {
+	for _, args := range [][]string{
+		{"--new", "--title", "Node"},
+		{"--new", "--outline", "-"},
+	} {
+		mock := &mockAddChildIOWithNew{
+			mockAddChildIO: mockAddChildIO{
+				binderBytes: emptyBinder(),
+				project:     &binder.Project{Files: []string{}, BinderDir: ".", IDScheme: "sequential"},
+			},
+		}
+		c := NewAddChildCmd(mock)
+		c.SetOut(new(bytes.Buffer))
+		c.SetErr(new(bytes.Buffer))
+		c.SetIn(strings.NewReader("- Node\n"))
+		c.SetArgs(append(args, "--parent", ".", "--project", "."))
+		err := c.Execute()
+		if err == nil || !strings.Contains(err.Error(), `.prosemark.yml: unknown id_scheme "sequential"`) {
+			t.Errorf("%v: err = %v, want unknown id_scheme error", args, err)
+		}
+		if mock.nodeWrittenPath != "" || mock.writtenBytes != nil {
+			t.Errorf("%v: wrote %q after the error", args, mock.nodeWrittenPath)
+		}
+	}
+}
+
+// TestNewAddChildCmd_NewMode_InvalidConfigOnDisk verifies that --new fails
+// with the config error, creating no files, when the project's
+// .prosemark.yml on disk has an unknown id_scheme.
+func TestNewAddChildCmd_NewMode_InvalidConfigOnDisk(t *testing.T) {
+	for _, args := range [][]string{
+		{"--new", "--title", "Node"},
+		{"--new", "--outline", "-"},
+	} {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "_binder.md"), emptyBinder(), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, ".prosemark.yml"), []byte("id_scheme: bogus\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		c := NewAddChildCmd(newDefaultAddChildIO())
+		c.SetOut(new(bytes.Buffer))
+		c.SetErr(new(bytes.Buffer))
+		c.SetIn(strings.NewReader("- Node\n"))
+		c.SetArgs(append(args, "--parent", ".", "--project", dir))
+		err := c.Execute()
+		if err == nil || !strings.Contains(err.Error(), `.prosemark.yml: unknown id_scheme "bogus"`) {
+			t.Errorf("%v: err = %v, want unknown id_scheme error", args, err)
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 2 {
+			t.Errorf("%v: project holds %d files after the error, want only the binder and config", args, len(entries))
+		}
+	}
+}
+
+// TestNewAddChildCmd_NewMode_DryRunAndDiff verifies that --dry-run prints the
+// binder diff without writing anything and --diff prints it after writing.
+func TestNewAddChildCmd_NewMode_DryRunAndDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantWrite bool
+		wantOut   []string
+	}{
+		{name: "dry run", args: []string{"--dry-run"}, wantOut: []string{"--- a/_binder.md", "+- [Node]("}},
+		{name: "diff", args: []string{"--diff"}, wantWrite: true, wantOut: []string{"+- [Node](", "Created "}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockAddChildIOWithNew{
+				mockAddChildIO: mockAddChildIO{
+					binderBytes: emptyBinder(),
+					project:     &binder.Project{Files: []string{}, BinderDir: "."},
+				},
+			}
+			c := NewAddChildCmd(mock)
+			out := new(bytes.Buffer)
+			c.SetOut(out)
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(append([]string{"--new", "--title", "Node", "--parent", ".", "--project", "."}, tt.args...))
+
+			if err := c.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("stdout = %q, want it to contain %q", out.String(), want)
+				}
+			}
+			if wrote := mock.writtenBytes != nil || mock.nodeWrittenPath != ""; wrote != tt.wantWrite {
+				t.Errorf("wrote binder %q, node %q; want written %v", mock.writtenBytes, mock.nodeWrittenPath, tt.wantWrite)
+			}
+		})
+	}
+}
+
+// TestNewAddChildCmd_NewMode_JSON verifies that --new --json prints the
+// operation result as one JSON document, with the diff only under --diff.
+func TestNewAddChildCmd_NewMode_JSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantDiff  bool
+		wantWrite bool
+	}{
+		{name: "json", args: []string{"--json"}, wantWrite: true},
+		{name: "json with diff", args: []string{"--json", "--diff"}, wantDiff: true, wantWrite: true},
+		{name: "json dry run", args: []string{"--json", "--dry-run"}, wantDiff: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockAddChildIOWithNew{
+				mockAddChildIO: mockAddChildIO{
+					binderBytes: emptyBinder(),
+					project:     &binder.Project{Files: []string{}, BinderDir: "."},
+				},
+			}
+			c := NewAddChildCmd(mock)
+			out := new(bytes.Buffer)
+			c.SetOut(out)
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(append([]string{"--new", "--title", "Node", "--parent", ".", "--project", "."}, tt.args...))
+
+			if err := c.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var res binder.OpResult
+			if err := json.Unmarshal(out.Bytes(), &res); err != nil {
+				t.Fatalf("stdout is not one JSON document: %v\n%s", err, out)
+			}
+			if !res.Changed || len(res.Entries) != 1 || res.Entries[0].Title != "Node" {
+				t.Errorf("result = %+v, want the added entry", res)
+			}
+			if (res.Diff != "") != tt.wantDiff {
+				t.Errorf("diff = %q, want present %v", res.Diff, tt.wantDiff)
+			}
+			if wrote := mock.writtenBytes != nil || mock.nodeWrittenPath != ""; wrote != tt.wantWrite {
+				t.Errorf("wrote binder %q, node %q; want written %v", mock.writtenBytes, mock.nodeWrittenPath, tt.wantWrite)
+			}
+		})
+	}
+}
+
+// TestNewAddChildCmd_NewMode_DiffOutputError verifies that a failure to print
+// the --diff output is propagated as an error.
+func TestNewAddChildCmd_NewMode_DiffOutputError(t *testing.T) {
+	mock := &mockAddChildIOWithNew{
+		mockAddChildIO: mockAddChildIO{
+			binderBytes: emptyBinder(),
+			project:     &binder.Project{Files: []string{}, BinderDir: "."},
+		},
+	}
+	c := NewAddChildCmd(mock)
+	c.SetOut(&errWriter{err: errors.New("write error")})
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--new", "--title", "Node", "--parent", ".", "--project", ".", "--diff"})
+
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "writing output") {
+		t.Errorf("error = %v, want a writing output error", err)
+	}
+}
+
+// TestNewAddChildCmd_DryRunWithEdit verifies that --dry-run and --edit are
+// rejected together, since a dry run cannot open the editor.
+func TestNewAddChildCmd_DryRunWithEdit(t *testing.T) {
+	mock := &mockAddChildIOWithNew{mockAddChildIO: mockAddChildIO{binderBytes: emptyBinder()}}
+	c := NewAddChildCmd(mock)
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--new", "--title", "Node", "--parent", ".", "--project", ".", "--edit", "--dry-run"})
+
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "--dry-run cannot be combined with --edit") {
+		t.Errorf("error = %v, want the --dry-run/--edit conflict", err)
+	}
+	if mock.writtenBytes != nil || mock.nodeWrittenPath != "" {
+		t.Error("nothing may be written when flags conflict")
+	}
+}
+
+// TestStampUpdated_InvalidTimestamp verifies that a timestamp the frontmatter
+// cannot hold is reported rather than written.
+func TestStampUpdated_InvalidTimestamp(t *testing.T) {
+	orig := nowUTCFunc
+	defer func() { nowUTCFunc = orig }()
+	nowUTCFunc = func() string { return "2026\x01" }
+
+	if _, _, err := stampUpdated([]byte("---\ntitle: T\n---\n")); err == nil {
+		t.Error("expected an error for a timestamp with a control character")
+	}
+}
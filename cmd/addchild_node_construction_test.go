@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
 )
 
 // mockAddChildIOWithEditorBody is a mock for --new mode that tracks node file
@@ -23,6 +24,10 @@ type mockAddChildIOWithEditorBody struct {
 	editorErr  error
 }
 
+func (m *mockAddChildIOWithEditorBody) BeginTx(string) (core.FileTx, error) {
+	return beginMemTx(m)
+}
+
 func (m *mockAddChildIOWithEditorBody) WriteNodeFileAtomic(path string, content []byte) error {
 	if m.files == nil {
 		m.files = make(map[string][]byte)
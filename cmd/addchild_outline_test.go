@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+func runAddOutline(t *testing.T, mock NewNodeAddChildIO, stdin string, args ...string) (string, error) {
+	t.Helper()
+	c := NewAddChildCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetIn(strings.NewReader(stdin))
+	c.SetArgs(append([]string{"--project", "."}, args...))
+	err := c.Execute()
+	return out.String(), err
+}
+
+func TestAddChildCmd_Outline_ExistingTargets(t *testing.T) {
+	mock := &mockAddChildIO{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [Part](part.md)\n")}
+	outline := "- [Chapter One](ch1.md)\n  - scene-a.md\n- ch2.md\n"
+
+	out, err := runAddOutline(t, mock, outline, "--parent", "part", "--outline", "-")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "<!-- prosemark-binder:v1 -->\n" +
+		"- [Part](part.md)\n" +
+		"  - [Chapter One](ch1.md)\n" +
+		"    - [scene-a](scene-a.md)\n" +
+		"  - [ch2](ch2.md)\n"
+	if got := string(mock.writtenBytes); got != want {
+		t.Errorf("binder =\n%s\nwant\n%s", got, want)
+	}
+	if !strings.Contains(out, "Added 3 nodes") {
+		t.Errorf("stdout = %q, want node count", out)
+	}
+}
+
+func TestAddChildCmd_Outline_FirstKeepsOrder(t *testing.T) {
+	mock := &mockAddChildIO{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [Old](old.md)\n")}
+
+	if _, err := runAddOutline(t, mock, "- a.md\n- b.md\n", "--parent", ".", "--first", "--outline", "-"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "<!-- prosemark-binder:v1 -->\n- [a](a.md)\n- [b](b.md)\n- [Old](old.md)\n"
+	if got := string(mock.writtenBytes); got != want {
+		t.Errorf("binder =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAddChildCmd_Outline_NewCreatesNodeFiles(t *testing.T) {
+	sequentialNodeIDs(t)
+	mock := &mockAddChildIOWithNew{mockAddChildIO: mockAddChildIO{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n")}}
+
+	if _, err := runAddOutline(t, mock, "- Act I\n\t- Opening image\n", "--parent", ".", "--new", "--outline", "-"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(mock.nodeWrittenPaths) != 2 {
+		t.Fatalf("node files written = %v, want 2", mock.nodeWrittenPaths)
+	}
+	if !strings.Contains(string(mock.nodeWrittenContents[1]), "synopsis: Opening image\n") {
+		t.Errorf("second node content = %q, want synopsis from item text", mock.nodeWrittenContents[1])
+	}
+	wantBinder := "  - [Opening image](01234567-89ab-7def-8000-000000000002.md)\n"
+	if !strings.Contains(string(mock.writtenBytes), wantBinder) {
+		t.Errorf("binder = %q, want nested child %q", mock.writtenBytes, wantBinder)
+	}
+}
+
+func TestAddChildCmd_Outline_NewReportsNoMissingFiles(t *testing.T) {
+	sequentialNodeIDs(t)
+	mock := &mockAddChildIOWithNew{mockAddChildIO: mockAddChildIO{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n")}}
+
+	out, err := runAddOutline(t, mock, "- Act I\n\t- Opening\n\t- Catalyst\n- Act II\n", "--parent", ".", "--new", "--outline", "-", "--json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result binder.OpResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("decoding output %q: %v", out, err)
+	}
+	if len(result.Diagnostics) != 0 {
+		t.Errorf("diagnostics = %+v, want none for freshly created nodes", result.Diagnostics)
+	}
+	if len(mock.nodeWrittenPaths) != 4 {
+		t.Errorf("node files written = %v, want 4", mock.nodeWrittenPaths)
+	}
+	if len(result.Entries) != 4 || result.Entries[1].Title != "Opening" || len(result.Entries[1].Path) != 2 {
+		t.Errorf("entries = %+v, want the 4 added entries", result.Entries)
+	}
+}
+
+func TestAddChildCmd_Outline_BinderWriteFailureRollsBack(t *testing.T) {
+	sequentialNodeIDs(t)
+	mock := &mockAddChildIOWithNew{mockAddChildIO: mockAddChildIO{
+		binderBytes: []byte("<!-- prosemark-binder:v1 -->\n"),
+		writeErr:    errors.New("disk full"),
+	}}
+
+	_, err := runAddOutline(t, mock, "- One\n- Two\n", "--parent", ".", "--new", "--outline", "-")
+	if err == nil || !strings.Contains(err.Error(), "writing binder") {
+		t.Fatalf("error = %v, want writing binder failure", err)
+	}
+	// The transaction undoes its steps last first, so the first node file
+	// is the last one deleted.
+	if want := fmt.Sprintf("01234567-89ab-7def-8000-%012d.md", 1); filepath.Base(mock.deletedPath) != want {
+		t.Errorf("last deleted = %q, want %q", mock.deletedPath, want)
+	}
+}
+
+func TestAddChildCmd_Outline_FromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outline.md")
+	if err := os.WriteFile(path, []byte("- x.md\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	mock := &mockAddChildIO{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n")}
+
+	if _, err := runAddOutline(t, mock, "", "--parent", ".", "--outline", path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(mock.writtenBytes), "- [x](x.md)\n") {
+		t.Errorf("binder = %q, want x.md added", mock.writtenBytes)
+	}
+}
+
+func TestAddChildCmd_Outline_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		stdin   string
+		args    []string
+		wantErr string
+	}{
+		{"conflicts with target", "- a.md\n", []string{"--target", "a.md"}, "--outline cannot be combined"},
+		{"empty outline", "just text\n", nil, "contains no list items"},
+		{"item without target", "- Plain words\n", nil, "has no .md target"},
+		{"missing file", "", []string{"--outline", "/nonexistent/outline.md"}, "reading outline"},
+		{"parent not found", "- a.md\n", []string{"--parent", "missing"}, "add has errors"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockAddChildIO{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n")}
+			args := append([]string{"--parent", "."}, tt.args...)
+			if !strings.Contains(strings.Join(args, " "), "--outline") {
+				args = append(args, "--outline", "-")
+			}
+			_, err := runAddOutline(t, mock, tt.stdin, args...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+			if mock.writtenBytes != nil {
+				t.Error("binder must not be written on error")
+			}
+		})
+	}
+}
+
+func TestAddChildCmd_Outline_NodeWriteFailure(t *testing.T) {
+	sequentialNodeIDs(t)
+	mock := &mockAddChildIOWithNew{
+		mockAddChildIO: mockAddChildIO{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n")},
+		nodeWriteErr:   errors.New("disk full"),
+	}
+
+	_, err := runAddOutline(t, mock, "- One\n", "--parent", ".", "--new", "--outline", "-")
+	if err == nil || !strings.Contains(err.Error(), "creating 01234567-89ab-7def-8000-000000000001.md: disk full") {
+		t.Fatalf("error = %v, want creating node file failure", err)
+	}
+	if mock.writtenBytes != nil {
+		t.Error("binder must not be written when a node file fails")
+	}
+}
+
+// mockAddChildIOBeginTxErr is a mockAddChildIOWithNew that cannot start a
+// file transaction.
+type mockAddChildIOBeginTxErr struct {
+	mockAddChildIOWithNew
+	err error
+}
+
+func (m *mockAddChildIOBeginTxErr) BeginTx(string) (core.FileTx, error) {
+	return nil, m.err
+}
+
+func TestAddChildCmd_Outline_BeginTxFailure(t *testing.T) {
+	sequentialNodeIDs(t)
+	mock := &mockAddChildIOBeginTxErr{
+		mockAddChildIOWithNew: mockAddChildIOWithNew{mockAddChildIO: mockAddChildIO{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n")}},
+		err:                   errors.New("journal locked"),
+	}
+
+	_, err := runAddOutline(t, mock, "- One\n", "--parent", ".", "--new", "--outline", "-")
+	if err == nil || err.Error() != "journal locked" {
+		t.Fatalf("error = %v, want the transaction error", err)
+	}
+	if len(mock.nodeWrittenPaths) != 0 || mock.writtenBytes != nil {
+		t.Errorf("wrote %v, binder %q; want nothing written", mock.nodeWrittenPaths, mock.writtenBytes)
+	}
+}
+
+func TestAddChildCmd_Outline_NodeFileEscapes(t *testing.T) {
+	sequentialNodeIDs(t)
+	dir := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "outside.md")
+	if err := os.WriteFile(outside, []byte("Outside.\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	second := fmt.Sprintf("01234567-89ab-7def-8000-%012d.md", 2)
+	if err := os.Symlink(outside, filepath.Join(dir, second)); err != nil {
+		t.Fatal(err)
+	}
+	mock := &mockAddChildIOWithNew{mockAddChildIO: mockAddChildIO{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n")}}
+
+	_, err := runAddOutline(t, mock, "- One\n- Two\n", "--project", dir, "--parent", ".", "--new", "--outline", "-")
+	if !errors.Is(err, safepath.ErrTraversal) {
+		t.Fatalf("error = %v, want a traversal error", err)
+	}
+	if len(mock.nodeWrittenPaths) != 0 || mock.writtenBytes != nil {
+		t.Errorf("wrote %v, binder %q; want nothing written", mock.nodeWrittenPaths, mock.writtenBytes)
+	}
+}
+
+func TestAddChildCmd_Outline_OutputErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"json encode error", []string{"--json"}, "encoding output"},
+		{"text write error", nil, "writing output"},
+		{"diff write error", []string{"--diff"}, "writing output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockAddChildIO{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n")}
+			c := NewAddChildCmd(mock)
+			c.SetOut(&errWriter{err: errors.New("closed")})
+			c.SetErr(new(bytes.Buffer))
+			c.SetIn(strings.NewReader("- a.md\n"))
+			c.SetArgs(append([]string{"--project", ".", "--parent", ".", "--outline", "-"}, tt.args...))
+			err := c.Execute()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAddChildCmd_Outline_ProjectReadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    *mockAddChildIO
+		wantErr string
+	}{
+		{"binder", &mockAddChildIO{binderErr: errors.New("disk error")}, "reading binder: disk error"},
+		{"scan", &mockAddChildIO{binderBytes: emptyBinder(), projectErr: errors.New("disk error")}, "operation failed: disk error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runAddOutline(t, tt.mock, "- a.md\n", "--parent", ".", "--outline", "-")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAddChildCmd_Outline_DryRunAndDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantOut   []string
+		wantWrite bool
+	}{
+		{name: "dry run", args: []string{"--dry-run"}, wantOut: []string{"+- [a](a.md)\n"}},
+		{name: "dry run as JSON", args: []string{"--dry-run", "--json", "--diff"}, wantOut: []string{`"diff":`, `"changed":true`}},
+		{name: "dry run without changes", args: []string{"--dry-run"}, wantOut: []string{"No changes to _binder.md (dry run)"}},
+		{name: "diff", args: []string{"--diff"}, wantOut: []string{"+- [a](a.md)\n", "Added 1 nodes"}, wantWrite: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockAddChildIO{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n")}
+			if strings.HasSuffix(tt.name, "without changes") {
+				mock.binderBytes = []byte("<!-- prosemark-binder:v1 -->\n- [a](a.md)\n")
+			}
+			out, err := runAddOutline(t, mock, "- a.md\n", append([]string{"--parent", ".", "--outline", "-"}, tt.args...)...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(out, want) {
+					t.Errorf("stdout = %q, want it to contain %q", out, want)
+				}
+			}
+			if tt.wantWrite != (mock.writtenBytes != nil) {
+				t.Errorf("written = %q, want written %v", mock.writtenBytes, tt.wantWrite)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
 )
 
 // mockAddChildIOFailSecondWrite is like mockAddChildIOWithNew but fails on the
@@ -18,6 +19,10 @@ type mockAddChildIOFailSecondWrite struct {
 	secondWriteErr error
 }
 
+func (m *mockAddChildIOFailSecondWrite) BeginTx(string) (core.FileTx, error) {
+	return beginMemTx(m)
+}
+
 func (m *mockAddChildIOFailSecondWrite) WriteNodeFileAtomic(path string, content []byte) error {
 	m.writeCallCount++
 	if m.writeCallCount == 1 {
@@ -36,6 +41,10 @@ type mockAddChildIOWithReadNodeErr struct {
 	readNodeErr error
 }
 
+func (m *mockAddChildIOWithReadNodeErr) BeginTx(string) (core.FileTx, error) {
+	return beginMemTx(m)
+}
+
 func (m *mockAddChildIOWithReadNodeErr) ReadNodeFile(_ string) ([]byte, error) {
 	return nil, m.readNodeErr
 }
@@ -48,6 +57,10 @@ type mockAddChildIOWithBadNodeContent struct {
 	badContent []byte
 }
 
+func (m *mockAddChildIOWithBadNodeContent) BeginTx(string) (core.FileTx, error) {
+	return beginMemTx(m)
+}
+
 func (m *mockAddChildIOWithBadNodeContent) ReadNodeFile(_ string) ([]byte, error) {
 	return m.badContent, nil
 }
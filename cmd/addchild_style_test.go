@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestNewAddChildCmd_Style(t *testing.T) {
+	src := "<!-- prosemark-binder:v1 -->\n\n- [One][one]\n\n[one]: one.md\n"
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"default inline", nil, "- [Two](two.md)\n"},
+		{"reference", []string{"--style", "reference"}, "- [Two][two]\n\n[one]: one.md\n[two]: two.md\n"},
+		{"wikilink", []string{"--style", "wikilink"}, "- [[two|Two]]\n"},
+		{"auto matches siblings", []string{"--style", "auto"}, "- [Two][two]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockAddChildIO{
+				binderBytes: []byte(src),
+				project:     &binder.Project{Files: []string{"one.md", "two.md"}, BinderDir: "."},
+			}
+			c := NewAddChildCmd(mock)
+			c.SetOut(new(bytes.Buffer))
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(append([]string{"--project", ".", "--parent", ".", "--target", "two.md", "--title", "Two"}, tt.args...))
+			if err := c.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(string(mock.writtenBytes), tt.want) {
+				t.Errorf("binder missing %q:\n%s", tt.want, mock.writtenBytes)
+			}
+		})
+	}
+}
+
+func TestNewAddChildCmd_Style_Unknown(t *testing.T) {
+	mock := &mockAddChildIO{binderBytes: emptyBinder(), project: &binder.Project{Files: []string{}, BinderDir: "."}}
+	c := NewAddChildCmd(mock)
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--project", ".", "--parent", ".", "--target", "two.md", "--style", "fancy"})
+	err := c.Execute()
+	if err == nil || !strings.Contains(err.Error(), "unknown --style") {
+		t.Fatalf("expected unknown --style error, got %v", err)
+	}
+	if mock.writtenBytes != nil {
+		t.Error("binder should not be written")
+	}
+}
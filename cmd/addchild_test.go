@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
 	"github.com/eykd/prosemark-go/internal/node"
 )
 
@@ -24,6 +25,10 @@ type mockAddChildIO struct {
 	writtenPath  string
 }
 
+func (m *mockAddChildIO) BeginTx(string) (core.FileTx, error) {
+	return beginMemTx(m)
+}
+
 func (m *mockAddChildIO) ReadBinder(_ context.Context, _ string) ([]byte, error) {
 	return m.binderBytes, m.binderErr
 }
@@ -278,6 +283,30 @@ func TestNewAddChildCmd_ForceFlag(t *testing.T) {
 	}
 }
 
+func TestNewAddChildCmd_ForceParseFlag(t *testing.T) {
+	broken := []byte("<!-- prosemark-binder:v1 -->\n- [Bad](b<c.md)\n")
+	for _, force := range []bool{false, true} {
+		mock := &mockAddChildIO{binderBytes: broken}
+		c := NewAddChildCmd(mock)
+		c.SetOut(new(bytes.Buffer))
+		errOut := new(bytes.Buffer)
+		c.SetErr(errOut)
+		args := []string{"--parent", ".", "--target", "new.md", "--project", "."}
+		if force {
+			args = append(args, "--force-parse")
+		}
+		c.SetArgs(args)
+
+		err := c.Execute()
+		if force && (err != nil || mock.writtenPath == "") {
+			t.Errorf("--force-parse: err = %v, written = %v; want the binder written", err, mock.writtenPath != "")
+		}
+		if !force && (err == nil || mock.writtenPath != "" || !strings.Contains(errOut.String(), "binder has parse errors")) {
+			t.Errorf("without --force-parse: err = %v, stderr = %q; want a refusal listing the parse errors", err, errOut.String())
+		}
+	}
+}
+
 func TestNewAddChildCmd_WriteSuccessMessageError(t *testing.T) {
 	mock := &mockAddChildIO{
 		binderBytes: acBinder(),
@@ -507,6 +536,10 @@ func (m *mockAddChildIOWithNew) ReadNodeFile(_ string) ([]byte, error) {
 	return m.nodeWrittenContent, nil
 }
 
+func (m *mockAddChildIOWithNew) BeginTx(string) (core.FileTx, error) {
+	return beginMemTx(m)
+}
+
 // emptyBinder returns a minimal initialized binder with no children.
 func emptyBinder() []byte {
 	return []byte("<!-- prosemark-binder:v1 -->\n")
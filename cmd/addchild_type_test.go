@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+)
+
+// mockAddChildIOWithConfig serves a .prosemark.yml for --type lookups.
+type mockAddChildIOWithConfig struct {
+	mockAddChildIOWithNew
+	config    []byte
+	configErr error
+}
+
+func (m *mockAddChildIOWithConfig) BeginTx(string) (core.FileTx, error) {
+	return beginMemTx(m)
+}
+
+func (m *mockAddChildIOWithConfig) ReadNodeFile(path string) ([]byte, error) {
+	if filepath.Base(path) == ".prosemark.yml" {
+		return m.config, m.configErr
+	}
+	return m.mockAddChildIOWithNew.ReadNodeFile(path)
+}
+
+func newTypedAddMock(config string, configErr error) *mockAddChildIOWithConfig {
+	return &mockAddChildIOWithConfig{
+		mockAddChildIOWithNew: mockAddChildIOWithNew{mockAddChildIO: mockAddChildIO{
+			binderBytes: emptyBinder(),
+			project:     &binder.Project{Files: []string{}, BinderDir: "."},
+		}},
+		config:    []byte(config),
+		configErr: configErr,
+	}
+}
+
+func runTypedAdd(t *testing.T, mock NewNodeAddChildIO, args ...string) error {
+	t.Helper()
+	c := NewAddChildCmd(mock)
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(append([]string{"--parent", ".", "--project", "."}, args...))
+	return c.Execute()
+}
+
+func TestNewAddChildCmd_NewMode_Type(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		configErr error
+		nodeType  string
+		wantBody  string
+	}{
+		{"built-in type without config", "", os.ErrNotExist, "scene", ""},
+		{"template from config", "types:\n  scene:\n    template: |\n      ## Goal\n      ## Conflict\n", nil, "scene", "## Goal\n## Conflict\n"},
+		{"project-declared type", "types:\n  letter:\n    template: Dear\n", nil, "letter", "Dear\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newTypedAddMock(tt.config, tt.configErr)
+			if err := runTypedAdd(t, mock, "--new", "--title", "Arrival", "--type", tt.nodeType); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			content := string(mock.nodeWrittenContent)
+			if !strings.Contains(content, "title: Arrival\ntype: "+tt.nodeType+"\n") {
+				t.Errorf("node content missing type:\n%s", content)
+			}
+			if _, body, _ := strings.Cut(content, "\n---\n"); body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestNewAddChildCmd_Type_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		configErr error
+		args      []string
+		wantErr   string
+	}{
+		{"requires --new", "", nil, []string{"--target", "a.md", "--type", "scene"}, "--type requires --new"},
+		{"conflicts with --outline", "", nil, []string{"--outline", "-", "--type", "scene"}, "--outline cannot be combined"},
+		{"unknown type", "", nil, []string{"--new", "--title", "X", "--type", "spaceship"}, `unknown node type "spaceship"`},
+		{"config read error", "", errors.New("denied"), []string{"--new", "--title", "X", "--type", "scene"}, "reading .prosemark.yml: denied"},
+		{"invalid config", "types:\n  scene:\n    required: {pov: strng}\n", nil, []string{"--new", "--title", "X", "--type", "scene"}, ".prosemark.yml: type \"scene\""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newTypedAddMock(tt.config, tt.configErr)
+			err := runTypedAdd(t, mock, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+			if mock.nodeWrittenPath != "" || mock.writtenBytes != nil {
+				t.Error("nothing must be written when --type is rejected")
+			}
+		})
+	}
+}
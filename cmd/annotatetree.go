@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/stats"
+)
+
+// AnnotateTreeIO handles I/O for the annotate-tree command.
+type AnnotateTreeIO interface {
+	core.BinderIO
+	// ReadNodeFile reads the node file at path, unlocking a locked body.
+	ReadNodeFile(path string) ([]byte, error)
+}
+
+// NewAnnotateTreeCmd creates the annotate-tree subcommand.
+func NewAnnotateTreeCmd(io AnnotateTreeIO) *cobra.Command {
+	return newAnnotateTreeCmdWithGetCWD(io, os.Getwd)
+}
+
+func newAnnotateTreeCmdWithGetCWD(io AnnotateTreeIO, getwd func() (string, error)) *cobra.Command {
+	var jsonMode, remove, forceParse, dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "annotate-tree",
+		Short: "Annotate binder entries with word counts and status",
+		Long: "End each binder entry with an HTML comment holding its node's word count\n" +
+			"and frontmatter status, such as \"<!-- 2,314 words, revised -->\". The\n" +
+			"comments do not change the binder's structure; running the command again\n" +
+			"refreshes them in place. --remove strips them.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+
+			projectDir := filepath.Dir(binderPath)
+			res, err := core.ApplyBinderOp(cmd.Context(), io, binderPath, dryRun, func(ctx context.Context, src []byte, proj *binder.Project) ([]byte, []binder.Diagnostic) {
+				params := ops.AnnotateParams{ForceParse: forceParse}
+				if !remove {
+					params.Notes = readEntryAnnotations(ctx, cmd, io, projectDir, src)
+				}
+				return ops.Annotate(ctx, src, proj, params)
+			})
+			if err := finishBinderOp(cmd, io, binderPath, jsonMode, res, err); err != nil {
+				return err
+			}
+
+			if !jsonMode && !dryRun {
+				msg := "Annotations in " + sanitizePath(binderPath) + " are up to date"
+				switch {
+				case res.Changed && remove:
+					msg = "Removed annotations from " + sanitizePath(binderPath)
+				case res.Changed:
+					msg = "Annotated " + sanitizePath(binderPath)
+				}
+				if _, err := fmt.Fprintln(cmd.OutOrStdout(), msg); err != nil {
+					return fmt.Errorf("writing output: %w", err)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+	cmd.Flags().BoolVar(&remove, "remove", false, "remove annotations instead of refreshing them")
+	addForceParseFlag(cmd, &forceParse)
+	addDryRunFlag(cmd, &dryRun)
+	addDiffFlag(cmd)
+	return cmd
+}
+
+// readEntryAnnotations reads the node file of every entry in the binder src
+// and returns its annotation, keyed by target. Missing files get no
+// annotation; files that cannot be read otherwise are reported and skipped.
+func readEntryAnnotations(ctx context.Context, cmd *cobra.Command, io AnnotateTreeIO, projectDir string, src []byte) map[string]ops.EntryAnnotation {
+	notes := make(map[string]ops.EntryAnnotation)
+	parsed, _, err := binder.Parse(ctx, src, nil)
+	if err != nil {
+		return notes // ops.Annotate reports the parse failure
+	}
+	for _, n := range binderTargetNodes(parsed.Root) {
+		nodePath, ok := resolveTargetPath(cmd, projectDir, n.Target)
+		if !ok {
+			continue
+		}
+		content, err := io.ReadNodeFile(nodePath)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: skipping unreadable node file %s\n", sanitizePath(n.Target))
+			}
+			continue
+		}
+		note := ops.EntryAnnotation{Words: stats.CountNode(content).Words}
+		if status, ok := node.FrontmatterFields(content)["status"]; ok && status != nil {
+			note.Status = fmt.Sprint(status)
+		}
+		notes[n.Target] = note
+	}
+	return notes
+}
+
+// fileAnnotateTreeIO implements AnnotateTreeIO using OS file I/O.
+type fileAnnotateTreeIO struct {
+	fileProjectIO
+	binderLocker
+}
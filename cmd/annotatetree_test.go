@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// mockAnnotateTreeIO is a test double for AnnotateTreeIO.
+type mockAnnotateTreeIO struct {
+	mockCheckIO
+	files    map[string]string
+	readErrs map[string]error
+}
+
+func (m *mockAnnotateTreeIO) ReadNodeFile(path string) ([]byte, error) {
+	name := filepath.Base(path)
+	if err, ok := m.readErrs[name]; ok {
+		return nil, err
+	}
+	if content, ok := m.files[name]; ok {
+		return []byte(content), nil
+	}
+	return nil, os.ErrNotExist
+}
+
+const annotateTreeBinder = "<!-- prosemark-binder:v1 -->\n" +
+	"- [Part One](part1.md) <!-- 9 words -->\n" +
+	"  - [Chapter One](ch1.md)\n" +
+	"  - [Placeholder]()\n" +
+	"  - [Chapter Two](ch2.md) <!-- keep me -->\n" +
+	"- [Locked](locked.md) <!-- 40 words, draft -->\n" +
+	"- [Escapes](a/../../outside.md)\n"
+
+func newAnnotateTreeTestIO() *mockAnnotateTreeIO {
+	return &mockAnnotateTreeIO{
+		mockCheckIO: mockCheckIO{binderBytes: []byte(annotateTreeBinder)},
+		files: map[string]string{
+			"ch1.md": "---\ntitle: Chapter One\nstatus: revised\n---\n\nIt was a dark and stormy night.\n",
+			"ch2.md": "One word? No: five words.\n",
+		},
+		readErrs: map[string]error{"locked.md": errors.New("locked")},
+	}
+}
+
+func runAnnotateTreeCmd(t *testing.T, mock *mockAnnotateTreeIO, args ...string) (string, string, error) {
+	t.Helper()
+	c := NewAnnotateTreeCmd(mock)
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(errOut)
+	c.SetArgs(append(args, "--project", "."))
+	err := c.Execute()
+	return out.String(), errOut.String(), err
+}
+
+func TestAnnotateTree_WritesAnnotations(t *testing.T) {
+	mock := newAnnotateTreeTestIO()
+	out, errOut, err := runAnnotateTreeCmd(t, mock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "<!-- prosemark-binder:v1 -->\n" +
+		"- [Part One](part1.md)\n" +
+		"  - [Chapter One](ch1.md) <!-- 7 words, revised -->\n" +
+		"  - [Placeholder]()\n" +
+		"  - [Chapter Two](ch2.md) <!-- keep me --> <!-- 5 words -->\n" +
+		"- [Locked](locked.md)\n" +
+		"- [Escapes](a/../../outside.md)\n"
+	if got := string(mock.writtenBytes); got != want {
+		t.Errorf("written =\n%s\nwant\n%s", got, want)
+	}
+	if !strings.Contains(out, "Annotated _binder.md") {
+		t.Errorf("stdout = %q", out)
+	}
+	if !strings.Contains(errOut, "skipping unreadable node file locked.md") {
+		t.Errorf("stderr = %q, want a warning about locked.md", errOut)
+	}
+
+	again := newAnnotateTreeTestIO()
+	again.binderBytes = mock.writtenBytes
+	out, _, err = runAnnotateTreeCmd(t, again)
+	if err != nil || again.writtenBytes != nil || !strings.Contains(out, "are up to date") {
+		t.Errorf("second run: stdout = %q, written = %q, err = %v", out, again.writtenBytes, err)
+	}
+}
+
+func TestAnnotateTree_Remove(t *testing.T) {
+	mock := newAnnotateTreeTestIO()
+	out, _, err := runAnnotateTreeCmd(t, mock, "--remove", "--json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result binder.OpResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil || !result.Changed {
+		t.Fatalf("result = %+v, %v", result, err)
+	}
+	if got := string(mock.writtenBytes); strings.Contains(got, "words") || !strings.Contains(got, "<!-- keep me -->") {
+		t.Errorf("written =\n%s", got)
+	}
+
+	out, _, err = runAnnotateTreeCmd(t, newAnnotateTreeTestIO(), "--remove")
+	if err != nil || !strings.Contains(out, "Removed annotations from _binder.md") {
+		t.Errorf("stdout = %q, err = %v", out, err)
+	}
+}
+
+func TestAnnotateTree_DryRunAndDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		binder    string
+		args      []string
+		wantOut   []string
+		wantWrite bool
+	}{
+		{
+			name:    "dry run",
+			binder:  annotateTreeBinder,
+			args:    []string{"--dry-run"},
+			wantOut: []string{"--- a/_binder.md", "+  - [Chapter One](ch1.md) <!-- 7 words, revised -->"},
+		},
+		{
+			name:    "dry run without changes",
+			binder:  "<!-- prosemark-binder:v1 -->\n- [Missing](missing.md)\n",
+			args:    []string{"--dry-run"},
+			wantOut: []string{"No changes to _binder.md (dry run)"},
+		},
+		{
+			name:      "diff",
+			binder:    annotateTreeBinder,
+			args:      []string{"--diff"},
+			wantOut:   []string{"Annotated _binder.md", "-- [Part One](part1.md) <!-- 9 words -->"},
+			wantWrite: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newAnnotateTreeTestIO()
+			mock.binderBytes = []byte(tt.binder)
+			out, _, err := runAnnotateTreeCmd(t, mock, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(out, want) {
+					t.Errorf("stdout = %q, want it to contain %q", out, want)
+				}
+			}
+			if tt.wantWrite != (mock.writtenBytes != nil) {
+				t.Errorf("written = %q, want written %v", mock.writtenBytes, tt.wantWrite)
+			}
+		})
+	}
+}
+
+func TestAnnotateTree_Errors(t *testing.T) {
+	parseErrors := newAnnotateTreeTestIO()
+	parseErrors.binderBytes = []byte("<!-- prosemark-binder:v1 -->\n- [Bad](b<c.md)\n")
+	writeFails := newAnnotateTreeTestIO()
+	writeFails.writeErr = errors.New("disk")
+
+	tests := []struct {
+		name    string
+		mock    *mockAnnotateTreeIO
+		wantErr string
+	}{
+		{"read binder error", &mockAnnotateTreeIO{mockCheckIO: mockCheckIO{binderErr: errors.New("boom")}}, "reading binder"},
+		{"invalid binder", &mockAnnotateTreeIO{mockCheckIO: mockCheckIO{binderBytes: []byte("\xff\xfe")}}, "annotate-tree has errors"},
+		{"parse errors", parseErrors, "annotate-tree has errors"},
+		{"write error", writeFails, "writing binder"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runAnnotateTreeCmd(t, tt.mock)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnnotateTree_OutputError(t *testing.T) {
+	c := NewAnnotateTreeCmd(newAnnotateTreeTestIO())
+	c.SetOut(&errWriter{err: errors.New("closed")})
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--project", "."})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "writing output") {
+		t.Errorf("error = %v, want output error", err)
+	}
+}
+
+func TestAnnotateTree_GetwdError(t *testing.T) {
+	c := newAnnotateTreeCmdWithGetCWD(newAnnotateTreeTestIO(), func() (string, error) {
+		return "", errors.New("getwd failed")
+	})
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(nil)
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+		t.Errorf("error = %v, want getwd failure", err)
+	}
+}
+
+func TestFileAnnotateTreeIO_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	if err := os.WriteFile(filepath.Join(dir, "ch1.md"), []byte("Some words.\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fio := fileAnnotateTreeIO{}
+	content := []byte("<!-- prosemark-binder:v1 -->\n- [One](ch1.md)\n")
+	if err := fio.WriteBinderAtomic(context.Background(), binderPath, content); err != nil {
+		t.Fatalf("WriteBinderAtomic: %v", err)
+	}
+	if got, err := fio.ReadBinder(context.Background(), binderPath); err != nil || !bytes.Equal(got, content) {
+		t.Errorf("ReadBinder = %q, %v", got, err)
+	}
+	if proj, err := fio.ScanProject(context.Background(), binderPath); err != nil || len(proj.Files) != 1 {
+		t.Errorf("ScanProject = %+v, %v", proj, err)
+	}
+	if got, err := fio.ReadNodeFile(filepath.Join(dir, "ch1.md")); err != nil || string(got) != "Some words.\n" {
+		t.Errorf("ReadNodeFile = %q, %v", got, err)
+	}
+}
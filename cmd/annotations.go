@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// formatGitHub is the --format value that emits GitHub Actions workflow
+// commands, so CI annotates findings inline on pull requests.
+const formatGitHub = "github"
+
+// githubAnnotation formats one GitHub Actions workflow command reporting msg
+// against file, and against line and col when they are positive. title is
+// usually the diagnostic code. Errors and warnings keep their level; any
+// other severity becomes a notice.
+func githubAnnotation(severity, file string, line, col int, title, msg string) string {
+	level := "notice"
+	if severity == "error" || severity == "warning" {
+		level = severity
+	}
+	var props []string
+	if file != "" {
+		props = append(props, "file="+escapeAnnotationProperty(file))
+		if line > 0 {
+			props = append(props, fmt.Sprintf("line=%d", line))
+			if col > 0 {
+				props = append(props, fmt.Sprintf("col=%d", col))
+			}
+		}
+	}
+	if title != "" {
+		props = append(props, "title="+escapeAnnotationProperty(title))
+	}
+	cmd := "::" + level
+	if len(props) > 0 {
+		cmd += " " + strings.Join(props, ",")
+	}
+	return cmd + "::" + escapeAnnotationData(msg) + "\n"
+}
+
+// escapeAnnotationData escapes a workflow command message.
+func escapeAnnotationData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeAnnotationProperty escapes a workflow command property value, which
+// additionally may not contain the ':' and ',' separators.
+func escapeAnnotationProperty(s string) string {
+	return strings.NewReplacer(":", "%3A", ",", "%2C").Replace(escapeAnnotationData(s))
+}
+
+// annotationPath returns path as a slash path relative to the working
+// directory, which GitHub resolves annotation files against (the checkout
+// root in a typical workflow). Paths outside the working directory, or when
+// it is unknown, are returned as given.
+func annotationPath(path string, getwd func() (string, error)) string {
+	cwd, err := getwd()
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	abs := path
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(cwd, abs)
+	}
+	rel, err := filepath.Rel(cwd, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
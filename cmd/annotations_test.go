@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGitHubAnnotation(t *testing.T) {
+	tests := []struct {
+		name      string
+		severity  string
+		file      string
+		line, col int
+		title     string
+		msg       string
+		want      string
+	}{
+		{"error with position", "error", "book/_binder.md", 3, 5, "BNDE001", "bad target", "::error file=book/_binder.md,line=3,col=5,title=BNDE001::bad target\n"},
+		{"warning with line only", "warning", "_binder.md", 2, 0, "BNDW004", "missing", "::warning file=_binder.md,line=2,title=BNDW004::missing\n"},
+		{"info becomes notice", "info", "a.md", 0, 7, "X", "note", "::notice file=a.md,title=X::note\n"},
+		{"no file ignores line", "warning", "", 4, 1, "PMKW001", "conflict", "::warning title=PMKW001::conflict\n"},
+		{"bare", "error", "", 0, 0, "", "oops", "::error::oops\n"},
+		{"escaping", "error", "a,b:c.md", 1, 0, "T:1", "100% done\r\nnext", "::error file=a%2Cb%3Ac.md,line=1,title=T%3A1::100%25 done%0D%0Anext\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := githubAnnotation(tt.severity, tt.file, tt.line, tt.col, tt.title, tt.msg); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnotationPath(t *testing.T) {
+	cwd := func() (string, error) { return "/repo", nil }
+	tests := []struct {
+		name  string
+		path  string
+		getwd func() (string, error)
+		want  string
+	}{
+		{"inside", "/repo/book/_binder.md", cwd, "book/_binder.md"},
+		{"relative", "book/../book/_binder.md", cwd, "book/_binder.md"},
+		{"outside", "/elsewhere/_binder.md", cwd, "/elsewhere/_binder.md"},
+		{"parent", "/", cwd, "/"},
+		{"no working directory", "book/_binder.md", func() (string, error) { return "", errors.New("gone") }, "book/_binder.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := annotationPath(tt.path, tt.getwd); got != tt.want {
+				t.Errorf("annotationPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// AppendIO handles I/O for the append and prepend commands.
+type AppendIO interface {
+	ReadBinder(ctx context.Context, path string) ([]byte, error)
+	// ReadNodeFile reads the node file at path, unlocking a locked body.
+	ReadNodeFile(path string) ([]byte, error)
+	// WriteNodeFileAtomic writes content to path atomically, keeping a
+	// locked node locked.
+	WriteNodeFileAtomic(path string, content []byte) error
+}
+
+// appendOutput is the JSON output schema for append and prepend.
+type appendOutput struct {
+	Version string `json:"version"`
+	Target  string `json:"target"`
+	Updated string `json:"updated"`
+}
+
+// NewAppendCmd creates the append subcommand, which adds text to the end of
+// a node's body.
+func NewAppendCmd(io AppendIO) *cobra.Command {
+	return newBodyInsertCmdWithGetCWD(io, os.Getwd, false)
+}
+
+// NewPrependCmd creates the prepend subcommand, which adds text to the start
+// of a node's body.
+func NewPrependCmd(io AppendIO) *cobra.Command {
+	return newBodyInsertCmdWithGetCWD(io, os.Getwd, true)
+}
+
+func newBodyInsertCmdWithGetCWD(fio AppendIO, getwd func() (string, error), prepend bool) *cobra.Command {
+	var (
+		text     string
+		jsonMode bool
+	)
+
+	use, short, verb, where := "append", "Add text to the end of a node's body", "Appended to", "after the existing body"
+	if prepend {
+		use, short, verb, where = "prepend", "Add text to the start of a node's body", "Prepended to", "right after the frontmatter"
+	}
+
+	cmd := &cobra.Command{
+		Use:   use + " <selector>",
+		Short: short,
+		Long: short + " without opening an editor.\n\n" +
+			"The text comes from --text, or from stdin when --text is not given, and is\n" +
+			"added as its own paragraph " + where + ". The node's updated\n" +
+			"timestamp is refreshed and the file is rewritten atomically; a locked node\n" +
+			"stays locked. The selector is a node's file stem, path, or title, or a\n" +
+			"binder path such as chapter-1:scene-2, and must name exactly one node file.",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("text") {
+				data, err := io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return fmt.Errorf("reading stdin: %w", err)
+				}
+				text = string(data)
+			}
+			text = strings.Trim(text, "\n")
+			if strings.TrimSpace(text) == "" {
+				return fmt.Errorf("nothing to %s: pass --text or pipe text on stdin", use)
+			}
+
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			binderBytes, err := fio.ReadBinder(ctx, binderPath)
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					return fmt.Errorf("project not initialized — run 'pmk init' first")
+				}
+				return fmt.Errorf("reading binder: %w", err)
+			}
+			parsed, _, err := binder.Parse(ctx, binderBytes, nil)
+			if err != nil {
+				return fmt.Errorf("cannot parse binder: %w", err)
+			}
+			selector := args[0]
+			if err := resolveBookmarkSelectors(fio.ReadNodeFile, filepath.Dir(binderPath), &selector); err != nil {
+				return err
+			}
+			target, err := selectNodeTarget(parsed.Root, selector)
+			if err != nil {
+				return err
+			}
+			nodePath, err := safepath.Resolve(filepath.Dir(binderPath), target)
+			if err != nil {
+				return err
+			}
+
+			content, err := fio.ReadNodeFile(nodePath)
+			if err != nil {
+				if errors.Is(err, node.ErrLockedBody) {
+					return fmt.Errorf("%s: %w", target, err)
+				}
+				return fmt.Errorf("reading node file: %w", err)
+			}
+			_, body, err := node.ParseFrontmatter(content)
+			if err != nil {
+				return fmt.Errorf("%s: %w", target, err)
+			}
+			content = slices.Concat(content[:len(content)-len(body)], insertBodyText(body, text, prepend))
+			content, updated, err := stampUpdated(content)
+			if err != nil {
+				return fmt.Errorf("%s: %w", target, err)
+			}
+			if err := fio.WriteNodeFileAtomic(nodePath, content); err != nil {
+				return fmt.Errorf("writing node file: %w", err)
+			}
+
+			if jsonMode {
+				if err := encodeOutput(cmd, appendOutput{Version: "1", Target: target, Updated: updated}); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+				return nil
+			}
+			if _, err := fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", verb, sanitizePath(target)); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().StringVar(&text, "text", "", "text to add (default: read from stdin)")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+	return cmd
+}
+
+// selectNodeTarget resolves selector against the binder tree rooted at root
+// and returns the node file target it names. Matching several entries is
+// fine as long as they all link the same file.
+func selectNodeTarget(root *binder.Node, selector string) (string, error) {
+	res, diags := binder.FindNodes(selector, root)
+	if len(diags) > 0 {
+		return "", fmt.Errorf("%s (%s)", diags[0].Message, diags[0].Code)
+	}
+	target := res.Nodes[0].Target
+	if target == "" {
+		return "", fmt.Errorf("selector %q does not name a node file", selector)
+	}
+	for _, n := range res.Nodes[1:] {
+		if n.Target != target {
+			return "", fmt.Errorf("selector %q is ambiguous: matches %s and %s", selector, sanitizePath(target), sanitizePath(n.Target))
+		}
+	}
+	return target, nil
+}
+
+// insertBodyText adds text, which has no surrounding newlines, to body as a
+// paragraph of its own: after the last non-blank line, or before the first
+// one when prepend is set.
+func insertBodyText(body []byte, text string, prepend bool) []byte {
+	if prepend {
+		rest := strings.TrimLeft(string(body), "\n")
+		if strings.TrimSpace(rest) == "" {
+			return []byte("\n" + text + "\n")
+		}
+		return []byte("\n" + text + "\n\n" + rest)
+	}
+	existing := strings.TrimRight(string(body), "\n")
+	if strings.TrimSpace(existing) == "" {
+		return []byte("\n" + text + "\n")
+	}
+	return []byte(existing + "\n\n" + text + "\n")
+}
+
+// fileAppendIO implements AppendIO using OS file I/O.
+type fileAppendIO struct {
+	fileProjectIO
+}
@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// mockAppendIO is a test double for AppendIO.
+type mockAppendIO struct {
+	binderBytes []byte
+	binderErr   error
+	files       map[string]string
+	readErr     error
+	writeErr    error
+	written     map[string][]byte
+}
+
+func (m *mockAppendIO) ReadBinder(_ context.Context, _ string) ([]byte, error) {
+	return m.binderBytes, m.binderErr
+}
+
+func (m *mockAppendIO) ReadNodeFile(path string) ([]byte, error) {
+	if m.readErr != nil {
+		return nil, m.readErr
+	}
+	if content, ok := m.files[filepath.Base(path)]; ok {
+		return []byte(content), nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func (m *mockAppendIO) WriteNodeFileAtomic(path string, content []byte) error {
+	if m.written == nil {
+		m.written = make(map[string][]byte)
+	}
+	m.written[path] = content
+	return m.writeErr
+}
+
+const appendTestBinder = "<!-- prosemark-binder:v1 -->\n" +
+	"- [Journal](journal.md)\n" +
+	"  - [Today](today.md)\n" +
+	"- [Empty](empty.md)\n" +
+	"- [Twin](a.md)\n" +
+	"- [Twin](b.md)\n" +
+	"- [Outside](a/../../outside.md)\n"
+
+const appendTestFrontmatter = "---\nid: journal\ntitle: Journal\ncreated: 2025-01-01T00:00:00Z\nupdated: 2025-01-01T00:00:00Z\n---\n"
+
+func newAppendTestIO() *mockAppendIO {
+	return &mockAppendIO{
+		binderBytes: []byte(appendTestBinder),
+		files: map[string]string{
+			"journal.md": appendTestFrontmatter + "\nFirst entry.\n\n",
+			"today.md":   appendTestFrontmatter + "\n",
+			"empty.md":   "no frontmatter\n",
+		},
+	}
+}
+
+func runAppendCmd(t *testing.T, mock *mockAppendIO, prepend bool, stdin string, args ...string) (string, error) {
+	t.Helper()
+	orig := nowUTCFunc
+	nowUTCFunc = func() string { return "2026-03-01T12:00:00Z" }
+	t.Cleanup(func() { nowUTCFunc = orig })
+
+	c := NewAppendCmd(mock)
+	if prepend {
+		c = NewPrependCmd(mock)
+	}
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetIn(strings.NewReader(stdin))
+	c.SetArgs(append(args, "--project", "/proj"))
+	err := c.Execute()
+	return out.String(), err
+}
+
+func TestAppend_AddsParagraphAndStampsUpdated(t *testing.T) {
+	mock := newAppendTestIO()
+	out, err := runAppendCmd(t, mock, false, "", "journal", "--text", "Second entry.")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "Appended to journal.md\n" {
+		t.Errorf("output = %q", out)
+	}
+	written := string(mock.written["/proj/journal.md"])
+	if !strings.HasSuffix(written, "---\n\nFirst entry.\n\nSecond entry.\n") {
+		t.Errorf("written = %q", written)
+	}
+	fm, _, err := node.ParseFrontmatter([]byte(written))
+	if err != nil || fm.Updated != "2026-03-01T12:00:00Z" || fm.Created != "2025-01-01T00:00:00Z" {
+		t.Errorf("frontmatter = %+v, %v; want updated refreshed and created kept", fm, err)
+	}
+}
+
+func TestAppend_KeepsUnknownFrontmatter(t *testing.T) {
+	mock := newAppendTestIO()
+	mock.files["journal.md"] = "---\nid: journal\nstatus: drafted # keep\nupdated: 2025-01-01T00:00:00Z\ntags:\n- diary\n---\nFirst entry.\n"
+	if _, err := runAppendCmd(t, mock, false, "", "journal", "--text", "Second entry."); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "---\nid: journal\nstatus: drafted # keep\nupdated: 2026-03-01T12:00:00Z\ntags:\n- diary\n---\nFirst entry.\n\nSecond entry.\n"
+	if written := string(mock.written["/proj/journal.md"]); written != want {
+		t.Errorf("written = %q, want %q", written, want)
+	}
+}
+
+func TestPrepend_AddsParagraphAfterFrontmatter(t *testing.T) {
+	mock := newAppendTestIO()
+	if _, err := runAppendCmd(t, mock, true, "", "Journal", "--text", "Newest entry."); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	written := string(mock.written["/proj/journal.md"])
+	if !strings.HasSuffix(written, "---\n\nNewest entry.\n\nFirst entry.\n\n") {
+		t.Errorf("written = %q", written)
+	}
+}
+
+func TestAppend_ReadsStdinIntoEmptyBody(t *testing.T) {
+	for _, prepend := range []bool{false, true} {
+		mock := newAppendTestIO()
+		if _, err := runAppendCmd(t, mock, prepend, "dictated\nnote\n\n", "journal:today"); err != nil {
+			t.Fatalf("prepend=%v: unexpected error: %v", prepend, err)
+		}
+		if written := string(mock.written["/proj/today.md"]); !strings.HasSuffix(written, "---\n\ndictated\nnote\n") {
+			t.Errorf("prepend=%v: written = %q", prepend, written)
+		}
+	}
+}
+
+func TestAppend_JSON(t *testing.T) {
+	out, err := runAppendCmd(t, newAppendTestIO(), false, "", "today", "--text", "x", "--json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got appendOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if got != (appendOutput{Version: "1", Target: "today.md", Updated: "2026-03-01T12:00:00Z"}) {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestAppend_Errors(t *testing.T) {
+	locked := newAppendTestIO()
+	locked.readErr = node.ErrLockedBody
+	readFails := newAppendTestIO()
+	readFails.readErr = errors.New("io")
+	writeFails := newAppendTestIO()
+	writeFails.writeErr = errors.New("disk full")
+
+	tests := []struct {
+		name    string
+		mock    *mockAppendIO
+		stdin   string
+		args    []string
+		wantErr string
+	}{
+		{"no text", newAppendTestIO(), "  \n", []string{"journal"}, "nothing to append"},
+		{"binder missing", &mockAppendIO{binderErr: os.ErrNotExist}, "", []string{"journal", "--text", "x"}, "project not initialized"},
+		{"binder unreadable", &mockAppendIO{binderErr: errors.New("io")}, "", []string{"journal", "--text", "x"}, "reading binder"},
+		{"no match", newAppendTestIO(), "", []string{"nope", "--text", "x"}, "matched no nodes"},
+		{"ambiguous", newAppendTestIO(), "", []string{"twin", "--text", "x"}, "ambiguous"},
+		{"root", newAppendTestIO(), "", []string{".", "--text", "x"}, "does not name a node file"},
+		{"escapes project", newAppendTestIO(), "", []string{"outside", "--text", "x"}, "escapes the project directory"},
+		{"missing file", newAppendTestIO(), "", []string{"a", "--text", "x"}, "reading node file"},
+		{"locked", locked, "", []string{"journal", "--text", "x"}, "journal.md: "},
+		{"read fails", readFails, "", []string{"journal", "--text", "x"}, "reading node file"},
+		{"no frontmatter", newAppendTestIO(), "", []string{"empty", "--text", "x"}, "empty.md: "},
+		{"write fails", writeFails, "", []string{"journal", "--text", "x"}, "writing node file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runAppendCmd(t, tt.mock, false, tt.stdin, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAppend_StdinReadError(t *testing.T) {
+	c := NewAppendCmd(newAppendTestIO())
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetIn(iotest.ErrReader(errors.New("broken pipe")))
+	c.SetArgs([]string{"journal", "--project", "/proj"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "reading stdin") {
+		t.Fatalf("err = %v, want stdin read error", err)
+	}
+}
+
+func TestAppend_InvalidTimestamp(t *testing.T) {
+	orig := nowUTCFunc
+	defer func() { nowUTCFunc = orig }()
+	nowUTCFunc = func() string { return "2026\x01" }
+
+	c := NewAppendCmd(newAppendTestIO())
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"journal", "--text", "x", "--project", "/proj"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "journal.md: ") {
+		t.Fatalf("err = %v, want the stamping error", err)
+	}
+}
+
+func TestAppend_InvalidBinder(t *testing.T) {
+	mock := &mockAppendIO{binderBytes: []byte("\xff\xfe")}
+	if _, err := runAppendCmd(t, mock, false, "", "journal", "--text", "x"); err == nil || !strings.Contains(err.Error(), "cannot parse binder") {
+		t.Fatalf("err = %v, want parse error", err)
+	}
+}
+
+func TestAppend_GetwdError(t *testing.T) {
+	c := newBodyInsertCmdWithGetCWD(newAppendTestIO(), func() (string, error) { return "", errors.New("no cwd") }, false)
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"journal", "--text", "x"})
+	if err := c.Execute(); err == nil {
+		t.Fatal("expected error when getwd fails")
+	}
+}
+
+func TestAppend_OutputErrors(t *testing.T) {
+	for _, args := range [][]string{{"journal", "--text", "x"}, {"journal", "--text", "x", "--json"}} {
+		c := NewAppendCmd(newAppendTestIO())
+		c.SetOut(&errWriter{err: errors.New("closed")})
+		c.SetErr(new(bytes.Buffer))
+		c.SetArgs(append(args, "--project", "/proj"))
+		if err := c.Execute(); err == nil {
+			t.Errorf("%v: expected output error", args)
+		}
+	}
+}
+
+func TestFileAppendIO_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	if err := os.WriteFile(binderPath, []byte(appendTestBinder), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fio := fileAppendIO{}
+	if got, err := fio.ReadBinder(context.Background(), binderPath); err != nil || string(got) != appendTestBinder {
+		t.Errorf("ReadBinder = %q, %v", got, err)
+	}
+	nodePath := filepath.Join(dir, "journal.md")
+	if err := fio.WriteNodeFileAtomic(nodePath, []byte(appendTestFrontmatter)); err != nil {
+		t.Fatalf("WriteNodeFileAtomic: %v", err)
+	}
+	if got, err := fio.ReadNodeFile(nodePath); err != nil || string(got) != appendTestFrontmatter {
+		t.Errorf("ReadNodeFile = %q, %v", got, err)
+	}
+}
+
+func TestAppend_ResolvesBookmark(t *testing.T) {
+	mock := newAppendTestIO()
+	mock.files[".prosemark.yml"] = "bookmarks:\n  today: journal:today\n"
+	out, err := runAppendCmd(t, mock, false, "", "@today", "--text", "Entry.")
+	if err != nil || out != "Appended to today.md\n" {
+		t.Fatalf("output = %q, %v; want today.md appended", out, err)
+	}
+
+	if _, err := runAppendCmd(t, mock, false, "", "@yesterday", "--text", "Entry."); err == nil || !strings.Contains(err.Error(), `bookmark "@yesterday" is not defined`) {
+		t.Errorf("undefined bookmark: error = %v", err)
+	}
+
+	mock.files[".prosemark.yml"] = "bookmarks: [\n"
+	if _, err := runAppendCmd(t, mock, false, "", "@today", "--text", "Entry."); err == nil || !strings.Contains(err.Error(), ".prosemark.yml") {
+		t.Errorf("invalid config: error = %v", err)
+	}
+
+	mock.readErr = errors.New("disk error")
+	if _, err := runAppendCmd(t, mock, false, "", "@today", "--text", "Entry."); err == nil || !strings.Contains(err.Error(), "reading .prosemark.yml") {
+		t.Errorf("unreadable config: error = %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/spf13/cobra"
+)
+
+// ApplyIO handles I/O for the apply command.
+type ApplyIO interface {
+	core.BinderIO
+	// ReadOpsFile reads the batch file at path.
+	ReadOpsFile(path string) ([]byte, error)
+}
+
+// NewApplyCmd creates the apply subcommand, which applies a batch of
+// operations from a file all or nothing.
+func NewApplyCmd(io ApplyIO) *cobra.Command {
+	return newApplyCmdWithGetCWD(io, os.Getwd)
+}
+
+func newApplyCmdWithGetCWD(aio ApplyIO, getwd func() (string, error)) *cobra.Command {
+	var jsonMode, forceParse, dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "apply <ops.json>",
+		Short: "Apply a batch of operations to a binder, all or nothing",
+		Long: "Apply the operations in a JSON array of op.json objects (the shape the\n" +
+			"conformance fixtures use: version, operation add, delete, or move, and\n" +
+			"params) in order, each to the binder the one before left. If any\n" +
+			"operation fails, nothing is written. Use - to read the array from stdin.",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+
+			var data []byte
+			if args[0] == "-" {
+				data, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				data, err = aio.ReadOpsFile(args[0])
+			}
+			if err != nil {
+				return fmt.Errorf("reading operations: %w", err)
+			}
+			specs, err := binder.ParseOpSpecs(data)
+			if err != nil {
+				return fmt.Errorf("parsing operations: %w", err)
+			}
+
+			// The operations are read first so that a retried --op-id is
+			// checked against them.
+			withJournalOps(cmd, specs...)
+			if replayed, err := replayRecordedOp(cmd, aio, binderPath, jsonMode); replayed || err != nil {
+				return err
+			}
+			res, err := core.Apply(cmd.Context(), aio, binderPath, specs, forceParse, dryRun)
+			if err := finishBinderOp(cmd, aio, binderPath, jsonMode, res, err); err != nil {
+				return err
+			}
+
+			if !jsonMode && !dryRun {
+				if _, err := fmt.Fprintf(cmd.OutOrStdout(), "Applied %d operations to %s\n", len(specs), sanitizePath(binderPath)); err != nil {
+					return fmt.Errorf("writing output: %w", err)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+	addForceParseFlag(cmd, &forceParse)
+	addDryRunFlag(cmd, &dryRun)
+	addDiffFlag(cmd)
+	addOpIDFlag(cmd)
+
+	return cmd
+}
+
+// fileApplyIO implements ApplyIO using OS file I/O.
+type fileApplyIO struct {
+	fileProjectIO
+	binderLocker
+	opJournaler
+}
+
+// ReadOpsFile reads the batch file at path.
+func (w *fileApplyIO) ReadOpsFile(path string) ([]byte, error) {
+	return fsio.ReadFile(path)
+}
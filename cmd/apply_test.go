@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mockApplyIO is a mockMoveIO that also serves batch files.
+type mockApplyIO struct {
+	mockMoveIO
+	opsFiles map[string][]byte
+}
+
+func (m *mockApplyIO) ReadOpsFile(path string) ([]byte, error) {
+	data, ok := m.opsFiles[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return data, nil
+}
+
+const applyCmdOps = `[{"version":"1","operation":"move","params":{"sourceSelector":"chapter-two.md","destinationParentSelector":"chapter-one.md","yes":true}},
+{"version":"1","operation":"delete","params":{"selector":"chapter-one.md","yes":true}}]`
+
+func TestNewApplyCmd(t *testing.T) {
+	mock := &mockApplyIO{mockMoveIO: mockMoveIO{binderBytes: moveBinder()}, opsFiles: map[string][]byte{"ops.json": []byte(applyCmdOps)}}
+	c := newApplyCmdWithGetCWD(mock, func() (string, error) { return ".", nil })
+	out, err := runCmdInCWD(c, "ops.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "Applied 2 operations to _binder.md\n"; !strings.HasSuffix(out, want) {
+		t.Errorf("output = %q, want it to end %q", out, want)
+	}
+	if want := "<!-- prosemark-binder:v1 -->\n"; string(mock.writtenBytes) != want {
+		t.Errorf("binder = %q, want %q", mock.writtenBytes, want)
+	}
+}
+
+func TestNewApplyCmd_Stdin(t *testing.T) {
+	mock := &mockApplyIO{mockMoveIO: mockMoveIO{binderBytes: moveBinder()}}
+	c := newApplyCmdWithGetCWD(mock, func() (string, error) { return ".", nil })
+	c.SetIn(strings.NewReader(applyCmdOps))
+	if _, err := runCmdInCWD(c, "-", "--dry-run"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mock.writtenBytes != nil {
+		t.Errorf("dry run wrote %q", mock.writtenBytes)
+	}
+}
+
+func TestNewApplyCmd_Errors(t *testing.T) {
+	tests := []struct {
+		name, ops, wantErr string
+	}{
+		{"missing file", "", "reading operations"},
+		{"bad batch", `{}`, "parsing operations: want a JSON array of operations"},
+		{"failed operation", `[{"version":"1","operation":"delete","params":{"selector":"missing.md","yes":true}}]`, "apply has errors"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockApplyIO{mockMoveIO: mockMoveIO{binderBytes: moveBinder()}, opsFiles: map[string][]byte{}}
+			if tt.ops != "" {
+				mock.opsFiles["ops.json"] = []byte(tt.ops)
+			}
+			c := newApplyCmdWithGetCWD(mock, func() (string, error) { return ".", nil })
+			if _, err := runCmdInCWD(c, "ops.json"); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+			if mock.writtenBytes != nil {
+				t.Errorf("wrote %q", mock.writtenBytes)
+			}
+		})
+	}
+}
+
+func TestNewApplyCmd_CommandErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		getwd   func() (string, error)
+		args    []string
+		out     *errWriter
+		wantErr string
+	}{
+		{name: "getwd", getwd: func() (string, error) { return "", errors.New("getwd failed") }, wantErr: "getwd failed"},
+		{name: "invalid op id", args: []string{"--op-id", "not-a-uuid"}, wantErr: "invalid --op-id"},
+		{name: "output", out: &errWriter{errors.New("closed")}, wantErr: "writing output: closed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockApplyIO{mockMoveIO: mockMoveIO{binderBytes: moveBinder()}, opsFiles: map[string][]byte{"ops.json": []byte(applyCmdOps)}}
+			getwd := tt.getwd
+			if getwd == nil {
+				getwd = func() (string, error) { return ".", nil }
+			}
+			c := newApplyCmdWithGetCWD(mock, getwd)
+			c.SetOut(new(bytes.Buffer))
+			if tt.out != nil {
+				c.SetOut(tt.out)
+			}
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(append([]string{"ops.json"}, tt.args...))
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewApplyCmd_OpIDChecksOperations(t *testing.T) {
+	j := &memOpJournal{}
+	run := func(ops string) (*mockApplyIO, error) {
+		mock := &mockApplyIO{mockMoveIO: mockMoveIO{binderBytes: moveBinder()}, opsFiles: map[string][]byte{"ops.json": []byte(ops)}}
+		c := newApplyCmdWithGetCWD(struct {
+			*mockApplyIO
+			*memOpJournal
+		}{mock, j}, func() (string, error) { return ".", nil })
+		c.SetOut(new(bytes.Buffer))
+		c.SetErr(new(bytes.Buffer))
+		c.SetArgs([]string{"ops.json", "--op-id", testOpID})
+		return mock, c.Execute()
+	}
+	if _, err := run(applyCmdOps); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if mock, err := run(applyCmdOps); err != nil || mock.writtenBytes != nil {
+		t.Errorf("retry: err = %v, wrote %q; want the recorded result", err, mock.writtenBytes)
+	}
+	other := `[{"version":"1","operation":"delete","params":{"selector":"chapter-one.md","yes":true}}]`
+	if mock, err := run(other); err == nil || !strings.Contains(err.Error(), "with different arguments or flags") || mock.writtenBytes != nil {
+		t.Errorf("retry with other operations: err = %v, wrote %q", err, mock.writtenBytes)
+	}
+}
+
+func TestFileApplyIO_ReadOpsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ops.json")
+	if err := os.WriteFile(path, []byte(applyCmdOps), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var aio fileApplyIO
+	if got, err := aio.ReadOpsFile(path); err != nil || string(got) != applyCmdOps {
+		t.Errorf("ReadOpsFile = %q, %v", got, err)
+	}
+}
+
+func TestNewRootCmd_RegistersApplySubcommand(t *testing.T) {
+	if c, _, err := NewRootCmd().Find([]string{"apply"}); err != nil || c.Name() != "apply" {
+		t.Errorf("apply subcommand not registered: %v", err)
+	}
+}
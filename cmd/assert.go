@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+)
+
+// AssertIO handles I/O for the assert command.
+type AssertIO interface {
+	core.ParseIO
+	// ReadFile reads the project file at path, for resolving @bookmarks.
+	ReadFile(path string) ([]byte, error)
+}
+
+// NewAssertCmd creates the assert command with exists, count, and
+// no-diagnostics subcommands.
+func NewAssertCmd(io AssertIO) *cobra.Command {
+	return newAssertCmdWithGetCWD(io, os.Getwd)
+}
+
+func newAssertCmdWithGetCWD(io AssertIO, getwd func() (string, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "assert",
+		Short: "Check project invariants, exiting non-zero when one fails",
+		Long: "Check project invariants, exiting non-zero when one fails.\n\n" +
+			"Each subcommand checks one rule against the binder and prints nothing\n" +
+			"when it holds, so CI can enforce project-specific rules without\n" +
+			"post-processing JSON output. A failed assertion is reported on stderr.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.PersistentFlags().String("project", "", "project directory containing the binder (default: current directory)")
+
+	cmd.AddCommand(newAssertExistsCmd(io, getwd))
+	cmd.AddCommand(newAssertCountCmd(io, getwd))
+	cmd.AddCommand(newAssertNoDiagnosticsCmd(io, getwd))
+	return cmd
+}
+
+func newAssertExistsCmd(io AssertIO, getwd func() (string, error)) *cobra.Command {
+	return &cobra.Command{
+		Use:          "exists <selector>",
+		Short:        "Assert that the selector matches at least one node",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			binderPath, parsed, err := parseForAssert(cmd, io, getwd)
+			if err != nil {
+				return err
+			}
+			selector := args[0]
+			if err := resolveBookmarkSelectors(io.ReadFile, filepath.Dir(binderPath), &selector); err != nil {
+				return err
+			}
+			if res, _ := binder.FindNodes(selector, parsed.Result.Root); len(res.Nodes) == 0 {
+				return fmt.Errorf("assertion failed: no node matches %q", args[0])
+			}
+			return nil
+		},
+	}
+}
+
+func newAssertCountCmd(io AssertIO, getwd func() (string, error)) *cobra.Command {
+	var (
+		parent                   string
+		minChildren, maxChildren int
+	)
+	cmd := &cobra.Command{
+		Use:   "count",
+		Short: "Assert how many children a node has",
+		Long: "Assert how many children a node has.\n\n" +
+			"Counts the direct children of --parent (default: the binder root),\n" +
+			"placeholders included, and fails when there are fewer than --min or more\n" +
+			"than --max. At least one of the two is required.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			hasMin, hasMax := cmd.Flags().Changed("min"), cmd.Flags().Changed("max")
+			if !hasMin && !hasMax {
+				return fmt.Errorf("--min or --max is required")
+			}
+			if hasMin && hasMax && minChildren > maxChildren {
+				return fmt.Errorf("--min %d is greater than --max %d", minChildren, maxChildren)
+			}
+			binderPath, parsed, err := parseForAssert(cmd, io, getwd)
+			if err != nil {
+				return err
+			}
+			selector := parent
+			if err := resolveBookmarkSelectors(io.ReadFile, filepath.Dir(binderPath), &selector); err != nil {
+				return err
+			}
+			n, err := selectCompileNode(parsed.Result.Root, "--parent", selector)
+			if err != nil {
+				return err
+			}
+			count := len(n.Children)
+			if hasMin && count < minChildren {
+				return fmt.Errorf("assertion failed: %q has %d children, want at least %d", parent, count, minChildren)
+			}
+			if hasMax && count > maxChildren {
+				return fmt.Errorf("assertion failed: %q has %d children, want at most %d", parent, count, maxChildren)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&parent, "parent", ".", "selector of the node whose children to count")
+	cmd.Flags().IntVar(&minChildren, "min", 0, "fewest children allowed")
+	cmd.Flags().IntVar(&maxChildren, "max", 0, "most children allowed")
+	return cmd
+}
+
+func newAssertNoDiagnosticsCmd(io AssertIO, getwd func() (string, error)) *cobra.Command {
+	var codes []string
+	cmd := &cobra.Command{
+		Use:   "no-diagnostics",
+		Short: "Assert that parsing the binder reports no diagnostics",
+		Long: "Assert that parsing the binder reports no diagnostics.\n\n" +
+			"Without --code, any error or warning fails the assertion; info\n" +
+			"diagnostics do not. With --code (repeatable), only diagnostics with one\n" +
+			"of the given codes count, whatever their severity. Offending diagnostics\n" +
+			"are printed on stderr.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			_, parsed, err := parseForAssert(cmd, io, getwd)
+			if err != nil {
+				return err
+			}
+			var found []binder.Diagnostic
+			for _, d := range parsed.Diagnostics {
+				if len(codes) > 0 && slices.Contains(codes, d.Code) ||
+					len(codes) == 0 && d.Severity != binder.SeverityInfo {
+					found = append(found, d)
+				}
+			}
+			if len(found) == 0 {
+				return nil
+			}
+			printDiagnostics(cmd, found)
+			return fmt.Errorf("assertion failed: binder has %d diagnostic(s)", len(found))
+		},
+	}
+	cmd.Flags().StringArrayVar(&codes, "code", nil, "diagnostic code to check for (repeatable; default: every error and warning)")
+	return cmd
+}
+
+// parseForAssert parses the binder of the project the assert command names,
+// project-aware, as parse does. A binder that cannot be parsed at all is an
+// error rather than a failed assertion.
+func parseForAssert(cmd *cobra.Command, io AssertIO, getwd func() (string, error)) (string, *core.Parsed, error) {
+	binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+	if err != nil {
+		return "", nil, err
+	}
+	parsed, err := core.Parse(cmd.Context(), io, binderPath, core.ParseOptions{})
+	if err != nil {
+		return "", nil, binderReadError(err, "")
+	}
+	if parsed.Err != nil {
+		return "", nil, fmt.Errorf("cannot parse binder: %w", parsed.Err)
+	}
+	return binderPath, parsed, nil
+}
+
+// fileAssertIO implements AssertIO using OS file I/O.
+type fileAssertIO struct {
+	fileProjectIO
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// mockAssertIO is a test double for AssertIO.
+type mockAssertIO struct {
+	binderBytes  []byte
+	binderErr    error
+	projectFiles []string
+	files        map[string]string // by base name
+}
+
+func (m *mockAssertIO) ReadBinder(_ context.Context, _ string) ([]byte, error) {
+	return m.binderBytes, m.binderErr
+}
+
+func (m *mockAssertIO) ScanProject(_ context.Context, _ string) (*binder.Project, error) {
+	return &binder.Project{Files: m.projectFiles, BinderDir: "."}, nil
+}
+
+func (m *mockAssertIO) ReadFile(path string) ([]byte, error) {
+	if content, ok := m.files[filepath.Base(path)]; ok {
+		return []byte(content), nil
+	}
+	return nil, os.ErrNotExist
+}
+
+const assertTestBinder = "<!-- prosemark-binder:v1 -->\n" +
+	"- [Part One](part1.md)\n" +
+	"  - [Chapter One](ch1.md)\n" +
+	"  - [Chapter Two](ch2.md)\n" +
+	"  - [Placeholder]()\n" +
+	"- [Part Two](part2.md)\n"
+
+func newAssertTestIO() *mockAssertIO {
+	return &mockAssertIO{
+		binderBytes:  []byte(assertTestBinder),
+		projectFiles: []string{"part1.md", "ch1.md", "ch2.md"},
+		files:        map[string]string{".prosemark.yml": "bookmarks:\n  part1: Part One\n"},
+	}
+}
+
+func runAssertCmd(t *testing.T, mock *mockAssertIO, args ...string) (string, string, error) {
+	t.Helper()
+	c := NewAssertCmd(mock)
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(errOut)
+	c.SetArgs(append(args, "--project", "/proj"))
+	err := c.Execute()
+	return out.String(), errOut.String(), err
+}
+
+func TestAssert(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "exists by title", args: []string{"exists", "Chapter Two"}},
+		{name: "exists by bookmark", args: []string{"exists", "@part1"}},
+		{name: "does not exist", args: []string{"exists", "Chapter Nine"}, wantErr: `assertion failed: no node matches "Chapter Nine"`},
+		{name: "unknown bookmark", args: []string{"exists", "@nope"}, wantErr: "nope"},
+		{name: "count root", args: []string{"count", "--min", "2", "--max", "2"}},
+		{name: "count with placeholders", args: []string{"count", "--parent", "part1", "--min", "3"}},
+		{name: "too few", args: []string{"count", "--parent", "part2", "--min", "1"}, wantErr: `assertion failed: "part2" has 0 children, want at least 1`},
+		{name: "too many", args: []string{"count", "--parent", "@part1", "--max", "2"}, wantErr: `assertion failed: "@part1" has 3 children, want at most 2`},
+		{name: "count without bounds", args: []string{"count"}, wantErr: "--min or --max is required"},
+		{name: "min above max", args: []string{"count", "--min", "3", "--max", "1"}, wantErr: "--min 3 is greater than --max 1"},
+		{name: "missing parent", args: []string{"count", "--parent", "nope", "--min", "1"}, wantErr: "--parent"},
+		{name: "unknown parent bookmark", args: []string{"count", "--parent", "@nope", "--min", "1"}, wantErr: "nope"},
+		{name: "no diagnostics of a code", args: []string{"no-diagnostics", "--code", "BNDW003"}},
+		{name: "diagnostics of a code", args: []string{"no-diagnostics", "--code", "BNDW003", "--code", "BNDW004"}, wantErr: "assertion failed: binder has 1 diagnostic(s)"},
+		{name: "any diagnostics", args: []string{"no-diagnostics"}, wantErr: "assertion failed: binder has 1 diagnostic(s)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, _, err := runAssertCmd(t, newAssertTestIO(), tt.args...)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+			if out != "" {
+				t.Errorf("stdout = %q, want nothing", out)
+			}
+		})
+	}
+}
+
+func TestAssert_NoDiagnosticsPrintsOffenders(t *testing.T) {
+	mock := newAssertTestIO()
+	mock.projectFiles = append(mock.projectFiles, "part2.md")
+	if _, _, err := runAssertCmd(t, mock, "no-diagnostics"); err != nil {
+		t.Fatalf("clean binder: %v", err)
+	}
+
+	mock.projectFiles = mock.projectFiles[:1]
+	_, errOut, err := runAssertCmd(t, mock, "no-diagnostics")
+	if err == nil || !strings.Contains(err.Error(), "3 diagnostic(s)") {
+		t.Fatalf("err = %v, want 3 diagnostics", err)
+	}
+	if strings.Count(errOut, binder.CodeMissingTargetFile) != 3 {
+		t.Errorf("stderr = %q, want the three %s diagnostics", errOut, binder.CodeMissingTargetFile)
+	}
+}
+
+func TestAssert_BinderErrors(t *testing.T) {
+	mock := newAssertTestIO()
+	mock.binderErr = os.ErrNotExist
+	for _, args := range [][]string{{"exists", "ch1"}, {"count", "--min", "1"}, {"no-diagnostics"}} {
+		if _, _, err := runAssertCmd(t, mock, args...); err == nil || !strings.Contains(err.Error(), "project not initialized") {
+			t.Errorf("missing binder: %s: err = %v", args[0], err)
+		}
+	}
+
+	mock = newAssertTestIO()
+	mock.binderBytes = []byte("<!-- prosemark-binder:v1 -->\n\xff\n")
+	if _, _, err := runAssertCmd(t, mock, "no-diagnostics"); err == nil || !strings.Contains(err.Error(), "cannot parse binder") {
+		t.Errorf("invalid binder: err = %v", err)
+	}
+}
+
+func TestAssert_GetwdError(t *testing.T) {
+	c := newAssertCmdWithGetCWD(newAssertTestIO(), func() (string, error) { return "", errors.New("getwd failed") })
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"exists", "ch1"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+		t.Errorf("error = %v, want getwd failure", err)
+	}
+}
+
+func TestAssert_NoSubcommandPrintsHelp(t *testing.T) {
+	out, _, err := runAssertCmd(t, newAssertTestIO())
+	if err != nil || !strings.Contains(out, "no-diagnostics") {
+		t.Errorf("output = %q, %v; want help listing subcommands", out, err)
+	}
+}
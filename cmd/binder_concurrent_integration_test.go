@@ -3,7 +3,7 @@ package cmd
 // binder_concurrent_integration_test.go — GREEN tests for concurrent write protection.
 //
 // Test 1: TestWriteBinderAtomic_InterfaceMethodPath_ConcurrentCallsPreserveAllEntries
-//   Exercises the production code path: io.WriteBinderAtomic → WriteBinderAtomicImpl →
+//   Exercises the production code path: io.WriteBinderAtomic →
 //   writeBinderAtomicMergeImpl (lock+merge). Verifies all N entries survive concurrent writes.
 //
 // Test 2: TestAddChildCmd_RunE_ConcurrentCallsPreserveAllEntries
@@ -29,7 +29,7 @@ import (
 // barrier (all reads before any write) and writes via the AddChildIO.WriteBinderAtomic
 // interface method — the code path taken by the real command at runtime.
 //
-// This test FAILS in RED because WriteBinderAtomicImpl routes to writeBinderCheckedImpl
+// This test FAILS in RED because WriteBinderAtomic routes to fsio.WriteBinder
 // (no lock, no merge) rather than writeBinderAtomicMergeImpl (locked read-merge-write).
 // Last-writer-wins silently drops N-1 entries.
 func TestWriteBinderAtomic_InterfaceMethodPath_ConcurrentCallsPreserveAllEntries(t *testing.T) {
@@ -96,7 +96,7 @@ func TestWriteBinderAtomic_InterfaceMethodPath_ConcurrentCallsPreserveAllEntries
 			}
 
 			// ── Write via the AddChildIO interface method ──────────────────────
-			// Exercises the production path: WriteBinderAtomic → WriteBinderAtomicImpl
+			// Exercises the production path: WriteBinderAtomic
 			// → writeBinderAtomicMergeImpl (lock + merge). All N entries must survive.
 			var io AddChildIO = newDefaultAddChildIO()
 			if werr := io.WriteBinderAtomic(context.Background(), binderPath, modified); werr != nil {
@@ -254,7 +254,7 @@ func TestAddChildCmd_RunE_ConcurrentCallsPreserveAllEntries(t *testing.T) {
 // WriteBinderAtomic on a fileAddChildIO acquires the global binder lock and merges
 // the incoming data with the current on-disk content.
 //
-// Regression guard: if WriteBinderAtomicImpl stops calling writeBinderAtomicMergeImpl,
+// Regression guard: if WriteBinderAtomic stops calling writeBinderAtomicMergeImpl,
 // a concurrent caller will overwrite without merging and this test will fail.
 func TestWriteBinderAtomic_InterfaceMethodUsesLockAndMerge(t *testing.T) {
 	dir := t.TempDir()
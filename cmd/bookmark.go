@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// BookmarkIO handles I/O for the bookmark command.
+type BookmarkIO interface {
+	ReadFile(path string) ([]byte, error)
+	WriteFileAtomic(path string, content []byte) error
+}
+
+// bookmarkJSON is the JSON output type for a single bookmark.
+type bookmarkJSON struct {
+	Name     string `json:"name"`
+	Selector string `json:"selector"`
+}
+
+// bookmarksOutput is the JSON output schema for bookmark list.
+type bookmarksOutput struct {
+	Version   string         `json:"version"`
+	Bookmarks []bookmarkJSON `json:"bookmarks"`
+}
+
+// NewBookmarkCmd creates the bookmark command with set, list, and remove
+// subcommands.
+func NewBookmarkCmd(io BookmarkIO) *cobra.Command {
+	return newBookmarkCmdWithGetCWD(io, os.Getwd)
+}
+
+func newBookmarkCmdWithGetCWD(io BookmarkIO, getwd func() (string, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bookmark",
+		Short: "Name frequently used selectors",
+		Long: "Name frequently used selectors.\n\n" +
+			"A bookmark is a name for a selector, kept under bookmarks: in\n" +
+			".prosemark.yml. Wherever a selector is accepted, @name stands for the\n" +
+			"bookmarked selector, so a deep node can be targeted as @act2 instead of\n" +
+			"by its full binder path.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.PersistentFlags().String("project", "", "project directory containing .prosemark.yml (default: current directory)")
+
+	cmd.AddCommand(newBookmarkSetCmd(io, getwd))
+	cmd.AddCommand(newBookmarkListCmd(io, getwd))
+	cmd.AddCommand(newBookmarkRemoveCmd(io, getwd))
+	return cmd
+}
+
+func newBookmarkSetCmd(io BookmarkIO, getwd func() (string, error)) *cobra.Command {
+	return &cobra.Command{
+		Use:          "set <name> <selector>",
+		Short:        "Bookmark a selector as @name, replacing any bookmark of that name",
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := strings.TrimPrefix(args[0], binder.BookmarkPrefix)
+			configPath, config, err := readBookmarkConfig(cmd, io, getwd)
+			if err != nil {
+				return err
+			}
+			updated, err := node.SetBookmark(config, name, args[1])
+			if err != nil {
+				return fmt.Errorf(".prosemark.yml: %w", err)
+			}
+			if err := io.WriteFileAtomic(configPath, updated); err != nil {
+				return fmt.Errorf("writing .prosemark.yml: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Bookmarked %s%s as %s\n", binder.BookmarkPrefix, name, args[1])
+			return nil
+		},
+	}
+}
+
+func newBookmarkListCmd(io BookmarkIO, getwd func() (string, error)) *cobra.Command {
+	var jsonMode bool
+
+	cmd := &cobra.Command{
+		Use:          "list",
+		Short:        "List bookmarks and the selectors they stand for",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			_, config, err := readBookmarkConfig(cmd, io, getwd)
+			if err != nil {
+				return err
+			}
+			settings, err := node.ParseProjectConfig(config)
+			if err != nil {
+				return fmt.Errorf(".prosemark.yml: %w", err)
+			}
+			out := bookmarksOutput{Version: "1", Bookmarks: []bookmarkJSON{}}
+			for _, name := range slices.Sorted(maps.Keys(settings.Bookmarks)) {
+				out.Bookmarks = append(out.Bookmarks, bookmarkJSON{Name: name, Selector: settings.Bookmarks[name]})
+			}
+
+			if jsonMode {
+				if err := encodeOutput(cmd, out); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+				return nil
+			}
+			var b strings.Builder
+			for _, bm := range out.Bookmarks {
+				fmt.Fprintf(&b, "%s%s\t%s\n", binder.BookmarkPrefix, bm.Name, bm.Selector)
+			}
+			if _, err := fmt.Fprint(cmd.OutOrStdout(), b.String()); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output bookmarks as JSON")
+	addFormatFlag(cmd)
+	return cmd
+}
+
+func newBookmarkRemoveCmd(io BookmarkIO, getwd func() (string, error)) *cobra.Command {
+	return &cobra.Command{
+		Use:          "remove <name>",
+		Short:        "Remove a bookmark",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := strings.TrimPrefix(args[0], binder.BookmarkPrefix)
+			configPath, config, err := readBookmarkConfig(cmd, io, getwd)
+			if err != nil {
+				return err
+			}
+			updated, found, err := node.RemoveBookmark(config, name)
+			if err != nil {
+				return fmt.Errorf(".prosemark.yml: %w", err)
+			}
+			if !found {
+				return fmt.Errorf("no bookmark named %s%s", binder.BookmarkPrefix, name)
+			}
+			if err := io.WriteFileAtomic(configPath, updated); err != nil {
+				return fmt.Errorf("writing .prosemark.yml: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed bookmark %s%s\n", binder.BookmarkPrefix, name)
+			return nil
+		},
+	}
+}
+
+// readBookmarkConfig returns the path and content of the project config; a
+// project without one has empty content.
+func readBookmarkConfig(cmd *cobra.Command, io BookmarkIO, getwd func() (string, error)) (string, []byte, error) {
+	projectDir, err := resolveProjectDirFromCmd(cmd, getwd)
+	if err != nil {
+		return "", nil, err
+	}
+	configPath := node.ConfigPath(projectDir)
+	config, err := io.ReadFile(configPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", nil, fmt.Errorf("reading .prosemark.yml: %w", err)
+	}
+	return configPath, config, nil
+}
+
+// resolveBookmarkSelectors replaces each @name selector with the selector it
+// bookmarks in the config of the project in projectDir. The config is read
+// only when a selector needs it.
+func resolveBookmarkSelectors(readFile func(string) ([]byte, error), projectDir string, selectors ...*string) error {
+	if !slices.ContainsFunc(selectors, func(s *string) bool { return strings.HasPrefix(*s, binder.BookmarkPrefix) }) {
+		return nil
+	}
+	settings, err := readProjectSettings(readFile, projectDir)
+	if err != nil {
+		return err
+	}
+	project := &binder.Project{Bookmarks: settings.Bookmarks}
+	for _, s := range selectors {
+		resolved, diag := binder.ResolveBookmark(*s, project)
+		if diag != nil {
+			return fmt.Errorf("%s (%s)", diag.Message, diag.Code)
+		}
+		*s = resolved
+	}
+	return nil
+}
+
+// fileBookmarkIO is the production implementation of BookmarkIO.
+type fileBookmarkIO struct {
+	fileProjectIO
+}
+
+// WriteFileAtomic writes content to path atomically.
+func (fileBookmarkIO) WriteFileAtomic(path string, content []byte) error {
+	return fsio.WriteFileAtomic(path, ".config", content)
+}
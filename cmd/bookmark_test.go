@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mockBookmarkIO is a test double for BookmarkIO.
+type mockBookmarkIO struct {
+	config   []byte
+	readErr  error
+	writeErr error
+	written  map[string][]byte
+}
+
+func (m *mockBookmarkIO) ReadFile(_ string) ([]byte, error) {
+	if m.readErr != nil {
+		return nil, m.readErr
+	}
+	if m.config == nil {
+		return nil, os.ErrNotExist
+	}
+	return m.config, nil
+}
+
+func (m *mockBookmarkIO) WriteFileAtomic(path string, content []byte) error {
+	if m.written == nil {
+		m.written = make(map[string][]byte)
+	}
+	m.written[path] = content
+	return m.writeErr
+}
+
+func runBookmarkCmd(t *testing.T, mock *mockBookmarkIO, args ...string) (string, error) {
+	t.Helper()
+	c := NewBookmarkCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(append(args, "--project", "/proj"))
+	err := c.Execute()
+	return out.String(), err
+}
+
+func TestBookmarkSet_WritesConfig(t *testing.T) {
+	mock := &mockBookmarkIO{config: []byte("version: \"1\"\n")}
+	out, err := runBookmarkCmd(t, mock, "set", "@act2", "part-two:ch3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "Bookmarked @act2 as part-two:ch3\n" {
+		t.Errorf("output = %q", out)
+	}
+	want := "version: \"1\"\nbookmarks:\n  act2: part-two:ch3\n"
+	if got := string(mock.written["/proj/.prosemark.yml"]); got != want {
+		t.Errorf("written = %q, want %q", got, want)
+	}
+}
+
+func TestBookmarkSet_WithoutConfig(t *testing.T) {
+	mock := &mockBookmarkIO{}
+	if _, err := runBookmarkCmd(t, mock, "set", "act2", "ch3"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := string(mock.written[filepath.Join("/proj", ".prosemark.yml")]); got != "bookmarks:\n  act2: ch3\n" {
+		t.Errorf("written = %q", got)
+	}
+}
+
+func TestBookmarkSet_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		mock *mockBookmarkIO
+		args []string
+		want string
+	}{
+		{"invalid name", &mockBookmarkIO{}, []string{"act 2", "ch3"}, "invalid bookmark name"},
+		{"bookmark of a bookmark", &mockBookmarkIO{}, []string{"act2", "@act1"}, "cannot point at another bookmark"},
+		{"read error", &mockBookmarkIO{readErr: errors.New("boom")}, []string{"act2", "ch3"}, "reading .prosemark.yml"},
+		{"write error", &mockBookmarkIO{writeErr: errors.New("boom")}, []string{"act2", "ch3"}, "writing .prosemark.yml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runBookmarkCmd(t, tt.mock, append([]string{"set"}, tt.args...)...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBookmarkList(t *testing.T) {
+	mock := &mockBookmarkIO{config: []byte("bookmarks:\n  b: ch2\n  a: part-one:ch1\n")}
+	out, err := runBookmarkCmd(t, mock, "list")
+	if err != nil || out != "@a\tpart-one:ch1\n@b\tch2\n" {
+		t.Errorf("list = %q, %v", out, err)
+	}
+
+	out, err = runBookmarkCmd(t, mock, "list", "--json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got bookmarksOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil || len(got.Bookmarks) != 2 || got.Bookmarks[0] != (bookmarkJSON{Name: "a", Selector: "part-one:ch1"}) {
+		t.Errorf("list --json = %s (%v)", out, err)
+	}
+
+	out, err = runBookmarkCmd(t, &mockBookmarkIO{}, "list", "--json")
+	if err != nil || !strings.Contains(out, `"bookmarks":[]`) {
+		t.Errorf("list --json without bookmarks = %q, %v", out, err)
+	}
+}
+
+func TestBookmarkList_Errors(t *testing.T) {
+	if _, err := runBookmarkCmd(t, &mockBookmarkIO{readErr: errors.New("boom")}, "list"); err == nil {
+		t.Error("read error: want error")
+	}
+	if _, err := runBookmarkCmd(t, &mockBookmarkIO{config: []byte("bookmarks:\n  a b: ch1\n")}, "list"); err == nil || !strings.Contains(err.Error(), ".prosemark.yml") {
+		t.Errorf("invalid config: error = %v", err)
+	}
+	for _, args := range [][]string{{"list"}, {"list", "--json"}} {
+		c := NewBookmarkCmd(&mockBookmarkIO{config: []byte("bookmarks:\n  a: ch1\n")})
+		c.SetOut(&errWriter{errors.New("closed")})
+		c.SetErr(new(bytes.Buffer))
+		c.SetArgs(append(args, "--project", "/proj"))
+		if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "closed") {
+			t.Errorf("%v with closed output: error = %v", args, err)
+		}
+	}
+}
+
+func TestBookmarkRemove(t *testing.T) {
+	mock := &mockBookmarkIO{config: []byte("bookmarks:\n  act1: ch1\n  act2: ch2\n")}
+	out, err := runBookmarkCmd(t, mock, "remove", "@act2")
+	if err != nil || out != "Removed bookmark @act2\n" {
+		t.Fatalf("remove = %q, %v", out, err)
+	}
+	if got := string(mock.written["/proj/.prosemark.yml"]); got != "bookmarks:\n  act1: ch1\n" {
+		t.Errorf("written = %q", got)
+	}
+}
+
+func TestBookmarkRemove_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		mock *mockBookmarkIO
+		want string
+	}{
+		{"not defined", &mockBookmarkIO{config: []byte("bookmarks:\n  act1: ch1\n")}, "no bookmark named @act2"},
+		{"invalid config", &mockBookmarkIO{config: []byte("bookmarks: [\n")}, ".prosemark.yml"},
+		{"read error", &mockBookmarkIO{readErr: errors.New("boom")}, "reading .prosemark.yml"},
+		{"write error", &mockBookmarkIO{config: []byte("bookmarks:\n  act2: ch2\n"), writeErr: errors.New("boom")}, "writing .prosemark.yml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runBookmarkCmd(t, tt.mock, "remove", "act2")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBookmark_NoSubcommandShowsHelp(t *testing.T) {
+	out, err := runBookmarkCmd(t, &mockBookmarkIO{})
+	if err != nil || !strings.Contains(out, "@name") {
+		t.Errorf("help = %q, %v", out, err)
+	}
+}
+
+func TestBookmark_ProjectDirError(t *testing.T) {
+	c := newBookmarkCmdWithGetCWD(&mockBookmarkIO{}, func() (string, error) { return "", errors.New("no cwd") })
+	if _, err := runCmdInCWD(c, "list"); err == nil {
+		t.Error("want error when the working directory is unknown")
+	}
+}
+
+func TestFileBookmarkIO(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".prosemark.yml")
+	io := fileBookmarkIO{}
+	if err := io.WriteFileAtomic(path, []byte("bookmarks: {}\n")); err != nil {
+		t.Fatalf("WriteFileAtomic: %v", err)
+	}
+	if got, err := io.ReadFile(path); err != nil || string(got) != "bookmarks: {}\n" {
+		t.Errorf("ReadFile = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/eykd/prosemark-go/internal/browse"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// BrowseIO handles I/O for the browse command.
+type BrowseIO interface {
+	browse.IO
+	// Audit runs a doctor audit of the project whose binder is binderPath.
+	Audit(ctx context.Context, binderPath string) ([]node.AuditDiagnostic, error)
+	// OpenEditor launches editor with path as its argument and waits for it.
+	OpenEditor(editor, path string) error
+}
+
+// terminal is the terminal pmk browse draws on.
+type terminal interface {
+	// IsTerminal reports whether stdin and stdout are a terminal.
+	IsTerminal() bool
+	// MakeRaw puts the terminal into raw mode, returning a function that
+	// restores its previous state.
+	MakeRaw() (restore func() error, err error)
+	// Size returns the terminal's width and height.
+	Size() (width, height int, err error)
+}
+
+// NewBrowseCmd creates the browse subcommand, an interactive outliner for
+// the binder.
+func NewBrowseCmd(io BrowseIO) *cobra.Command {
+	return newBrowseCmd(io, os.Getwd, stdTerminal{})
+}
+
+func newBrowseCmd(bio BrowseIO, getwd func() (string, error), tty terminal) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "browse",
+		Short: "Browse and reorganize the binder interactively",
+		Long: "Show the binder as a collapsible outline in the terminal. Move\n" +
+			"among nodes with the arrow keys or h/j/k/l; reorder with K and J;\n" +
+			"promote and demote with < and >; retitle with r; open a node in\n" +
+			"$EDITOR with e or Enter; reload with R; quit with q. Each change is\n" +
+			"written to the binder at once, as the matching pmk command would.\n" +
+			"Nodes with binder or doctor diagnostics are marked with !, and the\n" +
+			"selected node's diagnostics are listed at the bottom.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			if !tty.IsTerminal() {
+				return fmt.Errorf("pmk browse needs an interactive terminal")
+			}
+			restore, err := tty.MakeRaw()
+			if err != nil {
+				return fmt.Errorf("entering raw mode: %w", err)
+			}
+			out := cmd.OutOrStdout()
+			enter := func() { fmt.Fprint(out, "\x1b[?1049h\x1b[?25l") }
+			leave := func() { fmt.Fprint(out, "\x1b[?25h\x1b[?1049l") }
+			enter()
+			defer func() {
+				leave()
+				_ = restore()
+			}()
+
+			opts := browse.Options{
+				Audit: func(ctx context.Context) ([]node.AuditDiagnostic, error) {
+					return bio.Audit(ctx, binderPath)
+				},
+				Edit: func(path string) error {
+					editor, err := fsio.EditorCommand(filepath.Dir(binderPath))
+					if err != nil {
+						return err
+					}
+					if len(strings.Fields(editor)) == 0 {
+						return errEditorNotSet
+					}
+					leave()
+					_ = restore()
+					editErr := bio.OpenEditor(editor, path)
+					raw, err := tty.MakeRaw()
+					if err != nil {
+						return fmt.Errorf("entering raw mode: %w", err)
+					}
+					restore = raw
+					enter()
+					return editErr
+				},
+			}
+			m, err := browse.New(cmd.Context(), bio, binderPath, opts)
+			if err != nil {
+				return err
+			}
+			return runBrowse(m, cmd.InOrStdin(), out, tty)
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+
+	return cmd
+}
+
+// runBrowse draws m and feeds it key presses read from in until the user
+// quits or in ends.
+func runBrowse(m *browse.Model, in io.Reader, out io.Writer, tty terminal) error {
+	buf := make([]byte, 256)
+	for !m.Done() {
+		width, height, err := tty.Size()
+		if err != nil || width <= 0 || height <= 0 {
+			width, height = 80, 24
+		}
+		// Raw mode turns off output newline translation, so each line
+		// needs its own carriage return; \x1b[K clears what the last
+		// frame left to its right.
+		frame := strings.ReplaceAll(m.View(width, height), "\n", "\x1b[K\r\n")
+		if _, err := fmt.Fprint(out, "\x1b[H"+frame+"\x1b[J"); err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+		n, err := in.Read(buf)
+		for _, k := range browse.ParseKeys(buf[:n]) {
+			m.Update(k)
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading input: %w", err)
+		}
+	}
+	return nil
+}
+
+// makeRaw is term.MakeRaw; tests replace it, having no terminal to put in
+// raw mode.
+var makeRaw = term.MakeRaw
+
+// stdTerminal is the terminal on the process's stdin and stdout.
+type stdTerminal struct{}
+
+// IsTerminal reports whether stdin and stdout are a terminal.
+func (stdTerminal) IsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// MakeRaw puts stdin into raw mode.
+func (stdTerminal) MakeRaw() (func() error, error) {
+	fd := int(os.Stdin.Fd())
+	state, err := makeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	return func() error { return term.Restore(fd, state) }, nil
+}
+
+// Size returns stdout's width and height.
+func (stdTerminal) Size() (int, int, error) {
+	return term.GetSize(int(os.Stdout.Fd()))
+}
+
+// fileBrowseIO implements BrowseIO using OS file I/O.
+type fileBrowseIO struct {
+	fileProjectIO
+	binderLocker
+	opJournaler
+}
+
+// Audit runs a doctor audit of the project whose binder is binderPath.
+func (w *fileBrowseIO) Audit(ctx context.Context, binderPath string) ([]node.AuditDiagnostic, error) {
+	return core.Doctor(ctx, fileDoctorIO{}, binderPath, core.DoctorOptions{})
+}
+
+// OpenEditor launches editor with path as its argument.
+func (w *fileBrowseIO) OpenEditor(editor, path string) error {
+	return fsio.OpenEditor(editor, path)
+}
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"golang.org/x/term"
+
+	"github.com/eykd/prosemark-go/internal/browse"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// mockBrowseIO is a mockMoveIO whose binder writes are read back.
+type mockBrowseIO struct {
+	mockMoveIO
+	audited bool
+	edited  []string
+	onEdit  func() // called from OpenEditor, if set
+}
+
+func (m *mockBrowseIO) BeginTx(string) (core.FileTx, error) {
+	return beginMemTx(m)
+}
+
+func (m *mockBrowseIO) WriteBinderAtomic(ctx context.Context, path string, data []byte) error {
+	if err := m.mockMoveIO.WriteBinderAtomic(ctx, path, data); err != nil {
+		return err
+	}
+	m.binderBytes = data
+	return nil
+}
+
+func (m *mockBrowseIO) Audit(_ context.Context, _ string) ([]node.AuditDiagnostic, error) {
+	m.audited = true
+	return nil, nil
+}
+
+func (m *mockBrowseIO) OpenEditor(editor, path string) error {
+	m.edited = append(m.edited, editor+" "+path)
+	if m.onEdit != nil {
+		m.onEdit()
+	}
+	return nil
+}
+
+// fakeTerminal records raw mode switches.
+type fakeTerminal struct {
+	notTTY   bool
+	rawErr   error
+	raw      int // MakeRaw calls
+	restored int
+}
+
+func (f *fakeTerminal) IsTerminal() bool { return !f.notTTY }
+
+func (f *fakeTerminal) MakeRaw() (func() error, error) {
+	if f.rawErr != nil {
+		return nil, f.rawErr
+	}
+	f.raw++
+	return func() error { f.restored++; return nil }, nil
+}
+
+func (f *fakeTerminal) Size() (int, int, error) { return 0, 0, errors.New("no size") }
+
+func TestNewBrowseCmd(t *testing.T) {
+	t.Setenv("EDITOR", "vi")
+	mock := &mockBrowseIO{mockMoveIO: mockMoveIO{binderBytes: moveBinder()}}
+	tty := &fakeTerminal{}
+	c := newBrowseCmd(mock, func() (string, error) { return ".", nil }, tty)
+	// Move chapter one down and open it in the editor; input then ends.
+	c.SetIn(strings.NewReader("Je"))
+	out, err := runCmdInCWD(c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "<!-- prosemark-binder:v1 -->\n- [Chapter Two](chapter-two.md)\n- [Chapter One](chapter-one.md)\n"
+	if string(mock.binderBytes) != want {
+		t.Errorf("binder = %q, want %q", mock.binderBytes, want)
+	}
+	if len(mock.edited) != 1 || mock.edited[0] != "vi chapter-one.md" {
+		t.Errorf("edited = %v, want [vi chapter-one.md]", mock.edited)
+	}
+	if !mock.audited {
+		t.Error("doctor audit not run")
+	}
+	if tty.raw != 2 || tty.restored != 2 {
+		t.Errorf("raw mode entered %d times and restored %d, want 2 and 2", tty.raw, tty.restored)
+	}
+	if !strings.Contains(out, "\x1b[H>   Chapter One  (chapter-one.md)  !\x1b[K\r\n") {
+		t.Errorf("output does not draw the outline in raw mode: %q", out)
+	}
+}
+
+func TestNewBrowseCmd_EditorUnset(t *testing.T) {
+	t.Setenv("EDITOR", "")
+	mock := &mockBrowseIO{mockMoveIO: mockMoveIO{binderBytes: moveBinder()}}
+	c := newBrowseCmd(mock, func() (string, error) { return ".", nil }, &fakeTerminal{})
+	c.SetIn(iotest.OneByteReader(strings.NewReader("eq")))
+	out, err := runCmdInCWD(c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mock.edited) != 0 || !strings.Contains(out, "editor: $EDITOR is not set") {
+		t.Errorf("edited %v; output %q", mock.edited, out)
+	}
+}
+
+func TestNewBrowseCmd_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    *mockBrowseIO
+		tty     *fakeTerminal
+		wantErr string
+	}{
+		{"not a terminal", &mockBrowseIO{mockMoveIO: mockMoveIO{binderBytes: moveBinder()}}, &fakeTerminal{notTTY: true}, "needs an interactive terminal"},
+		{"raw mode", &mockBrowseIO{mockMoveIO: mockMoveIO{binderBytes: moveBinder()}}, &fakeTerminal{rawErr: errors.New("bad tty")}, "entering raw mode: bad tty"},
+		{"unreadable binder", &mockBrowseIO{mockMoveIO: mockMoveIO{binderErr: errors.New("denied")}}, &fakeTerminal{}, "denied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newBrowseCmd(tt.mock, func() (string, error) { return ".", nil }, tt.tty)
+			c.SetIn(strings.NewReader("q"))
+			if _, err := runCmdInCWD(c); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+			if tt.tty.raw != tt.tty.restored {
+				t.Errorf("raw mode entered %d times but restored %d", tt.tty.raw, tt.tty.restored)
+			}
+		})
+	}
+}
+
+func TestNewBrowseCmd_GetCWDError(t *testing.T) {
+	mock := &mockBrowseIO{mockMoveIO: mockMoveIO{binderBytes: moveBinder()}}
+	c := newBrowseCmd(mock, func() (string, error) { return "", errors.New("getwd failed") }, &fakeTerminal{})
+	if _, err := runCmdInCWD(c); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+		t.Errorf("err = %v, want the getwd error", err)
+	}
+}
+
+func TestNewBrowseCmd_RawModeLostAfterEdit(t *testing.T) {
+	t.Setenv("EDITOR", "vi")
+	tty := &fakeTerminal{}
+	mock := &mockBrowseIO{mockMoveIO: mockMoveIO{binderBytes: moveBinder()}, onEdit: func() { tty.rawErr = errors.New("bad tty") }}
+	c := newBrowseCmd(mock, func() (string, error) { return ".", nil }, tty)
+	c.SetIn(iotest.OneByteReader(strings.NewReader("eq")))
+	out, err := runCmdInCWD(c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "editor: entering raw mode: bad tty") {
+		t.Errorf("output does not report the raw mode error: %q", out)
+	}
+	if tty.raw != 1 || tty.restored == 0 {
+		t.Errorf("raw mode entered %d times and restored %d, want 1 and at least 1", tty.raw, tty.restored)
+	}
+}
+
+func TestRunBrowse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      *strings.Reader
+		out     *errWriter
+		wantErr string
+	}{
+		{name: "output", in: strings.NewReader("q"), out: &errWriter{errors.New("closed")}, wantErr: "writing output: closed"},
+		{name: "input", wantErr: "reading input: gone"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockBrowseIO{mockMoveIO: mockMoveIO{binderBytes: moveBinder()}}
+			m, err := browse.New(context.Background(), mock, "_binder.md", browse.Options{})
+			if err != nil {
+				t.Fatalf("browse.New: %v", err)
+			}
+			var in io.Reader = iotest.ErrReader(errors.New("gone"))
+			if tt.in != nil {
+				in = tt.in
+			}
+			var out io.Writer = new(bytes.Buffer)
+			if tt.out != nil {
+				out = tt.out
+			}
+			if err := runBrowse(m, in, out, &fakeTerminal{}); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStdTerminal(t *testing.T) {
+	var tty stdTerminal
+	// The test process's stdin and stdout are not a terminal.
+	if tty.IsTerminal() {
+		t.Skip("running on a terminal")
+	}
+	if _, err := tty.MakeRaw(); err == nil {
+		t.Error("MakeRaw off a terminal: want an error")
+	}
+	if _, _, err := tty.Size(); err == nil {
+		t.Error("Size off a terminal: want an error")
+	}
+
+	orig := makeRaw
+	makeRaw = func(int) (*term.State, error) { return &term.State{}, nil }
+	t.Cleanup(func() { makeRaw = orig })
+	restore, err := tty.MakeRaw()
+	if err != nil {
+		t.Fatalf("MakeRaw: %v", err)
+	}
+	if err := restore(); err == nil {
+		t.Error("restoring off a terminal: want an error")
+	}
+}
+
+func TestFileBrowseIO(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	if err := os.WriteFile(binderPath, []byte("<!-- prosemark-binder:v1 -->\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var bio fileBrowseIO
+	if _, err := bio.Audit(context.Background(), binderPath); err != nil {
+		t.Errorf("Audit: %v", err)
+	}
+	if err := bio.OpenEditor("true", binderPath); err != nil {
+		t.Errorf("OpenEditor: %v", err)
+	}
+}
+
+func TestNewRootCmd_RegistersBrowseSubcommand(t *testing.T) {
+	if c, _, err := NewRootCmd().Find([]string{"browse"}); err != nil || c.Name() != "browse" {
+		t.Errorf("browse subcommand not registered: %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/capture"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// defaultInboxTitle is the title of the binder node captures are filed
+// under when --parent is not given.
+const defaultInboxTitle = "Inbox"
+
+// maxCaptureBytes caps how much of a fetched page is read.
+const maxCaptureBytes = 10 << 20
+
+// FetchedPage is a fetched web resource.
+type FetchedPage struct {
+	Body []byte
+	// ContentType is the response's Content-Type header.
+	ContentType string
+	// URL is the final URL after redirects.
+	URL string
+}
+
+// CaptureIO handles I/O for the capture command.
+type CaptureIO interface {
+	core.NewNodeIO
+	// FetchURL fetches rawURL, giving up after timeout.
+	FetchURL(ctx context.Context, rawURL string, timeout time.Duration) (FetchedPage, error)
+}
+
+// NewCaptureCmd creates the capture subcommand.
+func NewCaptureCmd(io CaptureIO) *cobra.Command {
+	return newCaptureCmdWithGetCWD(io, os.Getwd)
+}
+
+func newCaptureCmdWithGetCWD(io CaptureIO, getwd func() (string, error)) *cobra.Command {
+	var (
+		fromURL  string
+		parent   string
+		title    string
+		timeout  time.Duration
+		jsonMode bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "capture",
+		Short: "Capture a web page as a reference node",
+		Long: "Fetch a web page, extract its main content as Markdown (dropping navigation,\n" +
+			"headers, footers, and other page chrome), and file it as a new node of type\n" +
+			"reference, with the page's URL and the capture time in its source and\n" +
+			"captured frontmatter.\n\n" +
+			"The node is added under the node titled \"" + defaultInboxTitle + "\", which is created at the\n" +
+			"end of the binder on first use; --parent files it elsewhere. Plain-text and\n" +
+			"Markdown resources are captured as they are.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if fromURL == "" {
+				return fmt.Errorf("--from-url is required")
+			}
+			u, err := url.Parse(fromURL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("invalid --from-url %q: want an http or https URL", fromURL)
+			}
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			fetched, err := io.FetchURL(ctx, fromURL, timeout)
+			if err != nil {
+				return fmt.Errorf("fetching %s: %w", sanitizePath(fromURL), err)
+			}
+			page, err := capturedPage(fetched)
+			if err != nil {
+				return err
+			}
+			if page.Markdown == "" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: no readable content found at %s\n", sanitizePath(fetched.URL))
+			}
+			if title != "" {
+				page.Title = title
+			}
+			if page.Title == "" {
+				page.Title = sanitizePath(fetched.URL)
+			}
+
+			scheme, err := projectIDScheme(ctx, io, binderPath)
+			if err != nil {
+				return err
+			}
+			id, err := nodeIDGenerator(scheme)
+			if err != nil {
+				return fmt.Errorf("generating node ID: %w", err)
+			}
+			now := nowUTCFunc()
+			params := binder.AddChildParams{ParentSelector: parent, Target: id, Title: page.Title, Position: "last"}
+			fm := node.Frontmatter{Title: page.Title, Type: node.TypeReference, Source: fetched.URL, Captured: now}
+			res, err := core.AddNewNode(ctx, io, binderPath, params, fm, []byte(page.Markdown), now)
+			if res != nil && err == nil && !cmd.Flags().Changed("parent") && hasDiagnosticCode(res.Diagnostics, binder.CodeSelectorNoMatch) {
+				// The first capture into a project creates its inbox.
+				if err := createInbox(cmd, io, binderPath, scheme, now); err != nil {
+					return err
+				}
+				res, err = core.AddNewNode(ctx, io, binderPath, params, fm, []byte(page.Markdown), now)
+			}
+
+			var opRes *binder.OpResult
+			if res != nil {
+				opRes = &res.OpResult
+			}
+			if err := finishBinderOp(cmd, io, binderPath, jsonMode, opRes, err); err != nil {
+				return err
+			}
+			if !jsonMode {
+				if _, err := fmt.Fprintf(cmd.OutOrStdout(), "Captured %s as %s in %s\n", sanitizePath(fetched.URL), sanitizePath(id), sanitizePath(binderPath)); err != nil {
+					return fmt.Errorf("writing output: %w", err)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().StringVar(&fromURL, "from-url", "", "URL of the page to capture")
+	cmd.Flags().StringVar(&parent, "parent", defaultInboxTitle, "Parent selector for the new node")
+	cmd.Flags().StringVar(&title, "title", "", "Node title (default: the page title)")
+	cmd.Flags().DurationVar(&timeout, "timeout", 30*time.Second, "give up fetching after this long")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+	return cmd
+}
+
+// capturedPage converts a fetched resource into a node title and body. HTML
+// is reduced to its main content; plain text and Markdown are kept as is.
+func capturedPage(fetched FetchedPage) (capture.Page, error) {
+	mediaType, _, err := mime.ParseMediaType(fetched.ContentType)
+	if err != nil {
+		mediaType = "text/html"
+	}
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+		// An unparsable final URL leaves relative links as the page wrote them.
+		base, err := url.Parse(fetched.URL)
+		if err != nil {
+			base = nil
+		}
+		return capture.Extract(fetched.Body, base), nil
+	case "text/plain", "text/markdown":
+		body := string(fetched.Body)
+		if body != "" && !strings.HasSuffix(body, "\n") {
+			body += "\n"
+		}
+		return capture.Page{Markdown: body}, nil
+	default:
+		return capture.Page{}, fmt.Errorf("cannot capture %s: unsupported content type %q", sanitizePath(fetched.URL), mediaType)
+	}
+}
+
+// createInbox adds an untyped node titled defaultInboxTitle at the end of
+// the binder.
+func createInbox(cmd *cobra.Command, io CaptureIO, binderPath string, scheme node.IDScheme, now string) error {
+	id, err := nodeIDGenerator(scheme)
+	if err != nil {
+		return fmt.Errorf("generating node ID: %w", err)
+	}
+	params := binder.AddChildParams{ParentSelector: ".", Target: id, Title: defaultInboxTitle, Position: "last"}
+	res, err := core.AddNewNode(cmd.Context(), io, binderPath, params, node.Frontmatter{Title: defaultInboxTitle}, nil, now)
+	if res != nil && hasDiagnosticError(res.Diagnostics) {
+		printDiagnostics(cmd, res.Diagnostics)
+		return errors.Join(fmt.Errorf("creating %s node failed", defaultInboxTitle), err)
+	}
+	if err != nil {
+		return fmt.Errorf("creating %s node: %w", defaultInboxTitle, err)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Created %s node %s\n", defaultInboxTitle, sanitizePath(id))
+	return nil
+}
+
+// hasDiagnosticCode reports whether any diagnostic in diags has code.
+func hasDiagnosticCode(diags []binder.Diagnostic, code string) bool {
+	for _, d := range diags {
+		if d.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// fileCaptureIO implements CaptureIO using OS file and network I/O.
+type fileCaptureIO struct {
+	fileAddChildIO
+}
+
+// FetchURL GETs rawURL and returns up to maxCaptureBytes of the response.
+// Responses other than 2xx are errors.
+func (f *fileCaptureIO) FetchURL(ctx context.Context, rawURL string, timeout time.Duration) (FetchedPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return FetchedPage{}, err
+	}
+	req.Header.Set("User-Agent", "pmk-capture")
+	req.Header.Set("Accept", "text/html, application/xhtml+xml, text/markdown;q=0.9, text/plain;q=0.8")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return FetchedPage{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return FetchedPage{}, fmt.Errorf("server returned %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCaptureBytes))
+	if err != nil {
+		return FetchedPage{}, fmt.Errorf("reading response: %w", err)
+	}
+	return FetchedPage{Body: body, ContentType: resp.Header.Get("Content-Type"), URL: resp.Request.URL.String()}, nil
+}
@@ -0,0 +1,374 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// mockCaptureIO is an in-memory CaptureIO for a project rooted at /proj.
+type mockCaptureIO struct {
+	binder    []byte
+	files     map[string][]byte
+	page      FetchedPage
+	fetchErr  error
+	scanErr   error
+	idScheme  string
+	writeErr  error
+	deleteErr error
+}
+
+func (m *mockCaptureIO) BeginTx(string) (core.FileTx, error) {
+	return beginMemTx(m)
+}
+
+func (m *mockCaptureIO) ReadBinder(_ context.Context, _ string) ([]byte, error) {
+	return m.binder, nil
+}
+
+func (m *mockCaptureIO) ScanProject(_ context.Context, _ string) (*binder.Project, error) {
+	if m.scanErr != nil {
+		return nil, m.scanErr
+	}
+	return &binder.Project{Files: []string{}, BinderDir: "/proj", IDScheme: m.idScheme}, nil
+}
+
+func (m *mockCaptureIO) WriteBinderAtomic(_ context.Context, _ string, data []byte) error {
+	if m.writeErr != nil {
+		return m.writeErr
+	}
+	m.binder = data
+	return nil
+}
+
+func (m *mockCaptureIO) ReadNodeFile(path string) ([]byte, error) {
+	if content, ok := m.files[path]; ok {
+		return content, nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func (m *mockCaptureIO) WriteNodeFileAtomic(path string, content []byte) error {
+	m.files[path] = content
+	return nil
+}
+
+func (m *mockCaptureIO) DeleteFile(path string) error {
+	delete(m.files, path)
+	return m.deleteErr
+}
+
+func (m *mockCaptureIO) FetchURL(_ context.Context, _ string, _ time.Duration) (FetchedPage, error) {
+	return m.page, m.fetchErr
+}
+
+const captureHTML = `<html><head><title>Sailing Ships</title></head><body>
+<nav>Home | About</nav><article><h1>Sailing Ships</h1><p>A <a href="/rig">rig</a> is the ship's sails and masts.</p></article></body></html>`
+
+func newCaptureMock(binderSrc string) *mockCaptureIO {
+	return &mockCaptureIO{
+		binder: []byte(binderSrc),
+		files:  map[string][]byte{},
+		page:   FetchedPage{Body: []byte(captureHTML), ContentType: "text/html; charset=utf-8", URL: "https://example.com/ships"},
+	}
+}
+
+// stubCaptureIDs makes nodeIDGenerator return ids in turn.
+func stubCaptureIDs(t *testing.T, ids ...string) {
+	t.Helper()
+	origID, origNow := nodeIDGenerator, nowUTCFunc
+	t.Cleanup(func() { nodeIDGenerator, nowUTCFunc = origID, origNow })
+	nodeIDGenerator = func(node.IDScheme) (string, error) {
+		id := ids[0]
+		ids = ids[1:]
+		return id, nil
+	}
+	nowUTCFunc = func() string { return "2026-03-04T05:06:07Z" }
+}
+
+func runCaptureCmd(t *testing.T, mock *mockCaptureIO, args ...string) (string, string, error) {
+	t.Helper()
+	c := NewCaptureCmd(mock)
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(errOut)
+	c.SetArgs(append(args, "--project", "/proj"))
+	err := c.Execute()
+	return out.String(), errOut.String(), err
+}
+
+const (
+	captureID = "0192f0c1-0000-7000-8000-0000000000c1.md"
+	inboxID   = "0192f0c1-0000-7000-8000-0000000000b0.md"
+)
+
+func TestCaptureCmd_IntoExistingInbox(t *testing.T) {
+	stubCaptureIDs(t, captureID)
+	mock := newCaptureMock("<!-- prosemark-binder:v1 -->\n- [Inbox](inbox.md)\n")
+	out, _, err := runCaptureCmd(t, mock, "--from-url", "https://example.com/ships")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "Captured https://example.com/ships as " + captureID + " in /proj/_binder.md\n"; out != want {
+		t.Errorf("stdout = %q, want %q", out, want)
+	}
+	wantBinder := "<!-- prosemark-binder:v1 -->\n- [Inbox](inbox.md)\n  - [Sailing Ships](" + captureID + ")\n"
+	if string(mock.binder) != wantBinder {
+		t.Errorf("binder = %q, want %q", mock.binder, wantBinder)
+	}
+	content := string(mock.files["/proj/"+captureID])
+	for _, want := range []string{
+		"title: Sailing Ships\n",
+		"type: reference\n",
+		"source: https://example.com/ships\n",
+		"captured: 2026-03-04T05:06:07Z\n",
+		"---\n# Sailing Ships\n\nA [rig](https://example.com/rig) is the ship's sails and masts.\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("node file %q missing %q", content, want)
+		}
+	}
+}
+
+func TestCaptureCmd_CreatesInbox(t *testing.T) {
+	stubCaptureIDs(t, captureID, inboxID)
+	mock := newCaptureMock("<!-- prosemark-binder:v1 -->\n- [Chapter](ch.md)\n")
+	_, errOut, err := runCaptureCmd(t, mock, "--from-url", "https://example.com/ships", "--title", "Rigging", "--json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantBinder := "<!-- prosemark-binder:v1 -->\n- [Chapter](ch.md)\n- [Inbox](" + inboxID + ")\n  - [Rigging](" + captureID + ")\n"
+	if string(mock.binder) != wantBinder {
+		t.Errorf("binder = %q, want %q", mock.binder, wantBinder)
+	}
+	if !strings.Contains(errOut, "Created Inbox node "+inboxID) {
+		t.Errorf("stderr = %q", errOut)
+	}
+	if _, ok := mock.files["/proj/"+inboxID]; !ok {
+		t.Error("inbox node file not written")
+	}
+}
+
+func TestCaptureCmd_SanitizesRedirectedURL(t *testing.T) {
+	stubCaptureIDs(t, captureID)
+	mock := newCaptureMock("<!-- prosemark-binder:v1 -->\n- [Inbox](inbox.md)\n")
+	mock.page.URL = "https://example.com/\x1b[2Jships"
+	mock.page.Body = nil
+	out, errOut, err := runCaptureCmd(t, mock, "--from-url", "https://example.com/ships")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.ContainsRune(out+errOut, 0x1b) {
+		t.Errorf("output carries an escape sequence: stdout %q, stderr %q", out, errOut)
+	}
+	if want := "Captured https://example.com/?[2Jships as "; !strings.HasPrefix(out, want) {
+		t.Errorf("stdout = %q, want prefix %q", out, want)
+	}
+	if strings.ContainsRune(string(mock.binder), 0x1b) {
+		t.Errorf("binder title carries an escape sequence: %q", mock.binder)
+	}
+}
+
+func TestCaptureCmd_ExplicitParentMissing(t *testing.T) {
+	stubCaptureIDs(t, captureID)
+	mock := newCaptureMock("<!-- prosemark-binder:v1 -->\n")
+	_, errOut, err := runCaptureCmd(t, mock, "--from-url", "https://example.com/ships", "--parent", "Research")
+	if err == nil || !strings.Contains(errOut, binder.CodeSelectorNoMatch) {
+		t.Errorf("error = %v, stderr = %q, want selector failure", err, errOut)
+	}
+	if len(mock.files) != 0 {
+		t.Errorf("node files left behind: %v", mock.files)
+	}
+}
+
+func TestCaptureCmd_PlainTextAndEmptyPages(t *testing.T) {
+	tests := []struct {
+		name      string
+		page      FetchedPage
+		wantBody  string
+		wantTitle string
+		wantWarn  bool
+	}{
+		{"plain text", FetchedPage{Body: []byte("Notes"), ContentType: "text/plain", URL: "https://example.com/notes.txt"}, "---\nNotes\n", "https://example.com/notes.txt", false},
+		{"markdown", FetchedPage{Body: []byte("# Notes\n"), ContentType: "text/markdown", URL: "https://example.com/n.md"}, "---\n# Notes\n", "https://example.com/n.md", false},
+		{"no content type", FetchedPage{Body: []byte("<nav>x</nav>"), URL: "https://example.com/"}, "---\n", "https://example.com/", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubCaptureIDs(t, captureID)
+			mock := newCaptureMock("<!-- prosemark-binder:v1 -->\n- [Inbox](inbox.md)\n")
+			mock.page = tt.page
+			_, errOut, err := runCaptureCmd(t, mock, "--from-url", tt.page.URL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			content := string(mock.files["/proj/"+captureID])
+			if !strings.HasSuffix(content, tt.wantBody) || !strings.Contains(content, "title: "+tt.wantTitle+"\n") {
+				t.Errorf("node file = %q", content)
+			}
+			if got := strings.Contains(errOut, "no readable content"); got != tt.wantWarn {
+				t.Errorf("stderr = %q", errOut)
+			}
+		})
+	}
+}
+
+func TestCaptureCmd_Errors(t *testing.T) {
+	const binderSrc = "<!-- prosemark-binder:v1 -->\n- [Inbox](inbox.md)\n"
+	withMock := func(edit func(m *mockCaptureIO)) *mockCaptureIO {
+		m := newCaptureMock(binderSrc)
+		edit(m)
+		return m
+	}
+	tests := []struct {
+		name    string
+		mock    *mockCaptureIO
+		args    []string
+		wantErr string
+	}{
+		{"missing url", newCaptureMock(binderSrc), nil, "--from-url is required"},
+		{"relative url", newCaptureMock(binderSrc), []string{"--from-url", "/ships"}, "want an http or https URL"},
+		{"unparseable url", newCaptureMock(binderSrc), []string{"--from-url", "http://[::1"}, "invalid --from-url"},
+		{"fetch", withMock(func(m *mockCaptureIO) { m.fetchErr = errors.New("timeout") }), []string{"--from-url", "https://example.com/"}, "fetching https://example.com/: timeout"},
+		{"content type", withMock(func(m *mockCaptureIO) { m.page.ContentType = "application/pdf" }), []string{"--from-url", "https://example.com/"}, `unsupported content type "application/pdf"`},
+		{"binder write", withMock(func(m *mockCaptureIO) { m.writeErr = errors.New("full") }), []string{"--from-url", "https://example.com/"}, "writing binder: full"},
+		{"scan", withMock(func(m *mockCaptureIO) { m.scanErr = errors.New("denied") }), []string{"--from-url", "https://example.com/"}, "denied"},
+		{"id scheme", withMock(func(m *mockCaptureIO) { m.idScheme = "sequential" }), []string{"--from-url", "https://example.com/"}, `unknown id_scheme "sequential"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubCaptureIDs(t, captureID)
+			if _, _, err := runCaptureCmd(t, tt.mock, tt.args...); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCaptureCmd_InboxErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		inboxID string
+		mock    func(m *mockCaptureIO)
+		wantErr string
+	}{
+		{"inbox write", inboxID, func(m *mockCaptureIO) { m.writeErr = errors.New("full") }, "creating Inbox node: writing binder: full"},
+		{"inbox diagnostics", "bad|name.md", func(*mockCaptureIO) {}, "creating Inbox node failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubCaptureIDs(t, captureID, tt.inboxID)
+			mock := newCaptureMock("<!-- prosemark-binder:v1 -->\n")
+			tt.mock(mock)
+			if _, _, err := runCaptureCmd(t, mock, "--from-url", "https://example.com/"); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCaptureCmd_IDErrors(t *testing.T) {
+	orig := nodeIDGenerator
+	t.Cleanup(func() { nodeIDGenerator = orig })
+	for _, fail := range []int{1, 2} {
+		calls := 0
+		nodeIDGenerator = func(node.IDScheme) (string, error) {
+			calls++
+			if calls == fail {
+				return "", errors.New("no entropy")
+			}
+			return captureID, nil
+		}
+		_, _, err := runCaptureCmd(t, newCaptureMock("<!-- prosemark-binder:v1 -->\n"), "--from-url", "https://example.com/")
+		if err == nil || !strings.Contains(err.Error(), "generating node ID: no entropy") {
+			t.Errorf("call %d: error = %v", fail, err)
+		}
+	}
+}
+
+func TestCaptureCmd_OutputError(t *testing.T) {
+	stubCaptureIDs(t, captureID)
+	c := NewCaptureCmd(newCaptureMock("<!-- prosemark-binder:v1 -->\n- [Inbox](inbox.md)\n"))
+	c.SetOut(&errWriter{err: errors.New("closed")})
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--project", "/proj", "--from-url", "https://example.com/"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "writing output") {
+		t.Errorf("error = %v, want output failure", err)
+	}
+}
+
+func TestCaptureCmd_JSON(t *testing.T) {
+	stubCaptureIDs(t, captureID)
+	out, _, err := runCaptureCmd(t, newCaptureMock("<!-- prosemark-binder:v1 -->\n- [Inbox](inbox.md)\n"), "--from-url", "https://example.com/", "--json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var res binder.OpResult
+	if err := json.Unmarshal([]byte(out), &res); err != nil || !res.Changed {
+		t.Errorf("output = %q (%v)", out, err)
+	}
+}
+
+func TestCaptureCmd_GetwdError(t *testing.T) {
+	c := newCaptureCmdWithGetCWD(newCaptureMock(""), func() (string, error) {
+		return "", errors.New("getwd failed")
+	})
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--from-url", "https://example.com/"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+		t.Errorf("error = %v, want getwd failure", err)
+	}
+}
+
+func TestFileCaptureIO_FetchURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/page", http.StatusFound)
+		case "/page":
+			if r.Header.Get("User-Agent") != "pmk-capture" {
+				t.Errorf("User-Agent = %q", r.Header.Get("User-Agent"))
+			}
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<p>hi</p>"))
+		case "/truncated":
+			w.Header().Set("Content-Length", "100")
+			_, _ = w.Write([]byte("short"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	fio := &fileCaptureIO{}
+	ctx := context.Background()
+	page, err := fio.FetchURL(ctx, srv.URL+"/old", time.Second)
+	if err != nil || string(page.Body) != "<p>hi</p>" || page.ContentType != "text/html" || page.URL != srv.URL+"/page" {
+		t.Errorf("FetchURL = %+v, %v", page, err)
+	}
+	for path, wantErr := range map[string]string{"/missing": "404", "/truncated": "reading response"} {
+		if _, err := fio.FetchURL(ctx, srv.URL+path, time.Second); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("FetchURL(%s) error = %v, want %q", path, err, wantErr)
+		}
+	}
+	if _, err := fio.FetchURL(ctx, "http://[::1", time.Second); err == nil {
+		t.Error("FetchURL with invalid URL: want error")
+	}
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	if _, err := fio.FetchURL(ctx, closed.URL, time.Second); err == nil {
+		t.Error("FetchURL against closed server: want error")
+	}
+}
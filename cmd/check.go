@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/spf13/cobra"
+)
+
+// CheckIO handles I/O for the check and uncheck commands.
+type CheckIO interface {
+	core.BinderIO
+}
+
+// NewCheckCmd creates the check subcommand, which marks a node's task
+// checkbox as done ("[x]").
+func NewCheckCmd(io CheckIO) *cobra.Command {
+	return newCheckStateCmdWithGetCWD(io, os.Getwd, true)
+}
+
+// NewUncheckCmd creates the uncheck subcommand, which marks a node's task
+// checkbox as not done ("[ ]").
+func NewUncheckCmd(io CheckIO) *cobra.Command {
+	return newCheckStateCmdWithGetCWD(io, os.Getwd, false)
+}
+
+func newCheckStateCmdWithGetCWD(io CheckIO, getwd func() (string, error), checked bool) *cobra.Command {
+	var jsonMode, forceParse, dryRun bool
+
+	use, short, verb := "check <selector>", "Mark a node's task checkbox as done", "Checked"
+	if !checked {
+		use, short, verb = "uncheck <selector>", "Mark a node's task checkbox as not done", "Unchecked"
+	}
+
+	cmd := &cobra.Command{
+		Use:          use,
+		Short:        short,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			selector := args[0]
+
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+
+			if replayed, err := replayRecordedOp(cmd, io, binderPath, jsonMode); replayed || err != nil {
+				return err
+			}
+
+			params := binder.SetCheckedParams{Selector: selector, Checked: checked, ForceParse: forceParse, DryRun: dryRun}
+			res, err := core.SetChecked(cmd.Context(), io, binderPath, params)
+			if err := finishBinderOp(cmd, io, binderPath, jsonMode, res, err); err != nil {
+				return err
+			}
+
+			if !jsonMode && !dryRun {
+				if _, err := fmt.Fprintln(cmd.OutOrStdout(), verb+" "+sanitizePath(selector)+" in "+sanitizePath(binderPath)); err != nil {
+					return fmt.Errorf("writing output: %w", err)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+	addForceParseFlag(cmd, &forceParse)
+	addDryRunFlag(cmd, &dryRun)
+	addDiffFlag(cmd)
+	addOpIDFlag(cmd)
+
+	return cmd
+}
+
+// fileCheckIO implements CheckIO using OS file I/O.
+type fileCheckIO struct {
+	fileProjectIO
+	binderLocker
+	opJournaler
+}
+
+func newDefaultCheckIO() *fileCheckIO {
+	return &fileCheckIO{}
+}
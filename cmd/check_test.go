@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// mockCheckIO is a test double for CheckIO.
+type mockCheckIO struct {
+	binderBytes  []byte
+	binderErr    error
+	projectErr   error
+	writeErr     error
+	writtenBytes []byte
+}
+
+func (m *mockCheckIO) ReadBinder(_ context.Context, _ string) ([]byte, error) {
+	return m.binderBytes, m.binderErr
+}
+
+func (m *mockCheckIO) ScanProject(_ context.Context, _ string) (*binder.Project, error) {
+	return &binder.Project{Files: []string{"chapter-one.md"}, BinderDir: "."}, m.projectErr
+}
+
+func (m *mockCheckIO) WriteBinderAtomic(_ context.Context, _ string, data []byte) error {
+	m.writtenBytes = data
+	return m.writeErr
+}
+
+func checkBinder(item string) []byte {
+	return []byte("<!-- prosemark-binder:v1 -->\n" + item + "\n")
+}
+
+func TestNewCheckCmd_WritesCheckbox(t *testing.T) {
+	tests := []struct {
+		name     string
+		newCmd   func(CheckIO) *cobra.Command
+		item     string
+		wantItem string
+		wantOut  string
+	}{
+		{"check adds [x]", NewCheckCmd, "- [Chapter One](chapter-one.md)", "- [x] [Chapter One](chapter-one.md)", "Checked chapter-one"},
+		{"uncheck clears [x]", NewUncheckCmd, "- [x] [Chapter One](chapter-one.md)", "- [ ] [Chapter One](chapter-one.md)", "Unchecked chapter-one"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockCheckIO{binderBytes: checkBinder(tt.item)}
+			c := tt.newCmd(mock)
+			out := new(bytes.Buffer)
+			c.SetOut(out)
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs([]string{"chapter-one", "--project", "."})
+
+			if err := c.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got, want := string(mock.writtenBytes), string(checkBinder(tt.wantItem)); got != want {
+				t.Errorf("written = %q, want %q", got, want)
+			}
+			if !strings.Contains(out.String(), tt.wantOut) {
+				t.Errorf("stdout = %q, want to contain %q", out.String(), tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestNewCheckCmd_NoChangeSkipsWrite(t *testing.T) {
+	mock := &mockCheckIO{binderBytes: checkBinder("- [x] [Chapter One](chapter-one.md)")}
+	c := NewCheckCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetArgs([]string{"chapter-one", "--project", ".", "--json"})
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mock.writtenBytes != nil {
+		t.Error("binder must not be written when state is unchanged")
+	}
+	var result binder.OpResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if result.Changed {
+		t.Error("changed = true, want false")
+	}
+}
+
+func TestNewCheckCmd_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    *mockCheckIO
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "selector no match",
+			mock:    &mockCheckIO{binderBytes: checkBinder("- [Chapter One](chapter-one.md)")},
+			args:    []string{"missing", "--project", "."},
+			wantErr: "check has errors",
+		},
+		{
+			name:    "read binder error",
+			mock:    &mockCheckIO{binderErr: errors.New("boom")},
+			args:    []string{"chapter-one", "--project", "."},
+			wantErr: "reading binder",
+		},
+		{
+			name:    "scan project error",
+			mock:    &mockCheckIO{binderBytes: checkBinder("- [Chapter One](chapter-one.md)"), projectErr: errors.New("scan")},
+			args:    []string{"chapter-one", "--project", "."},
+			wantErr: "operation failed",
+		},
+		{
+			name:    "write error",
+			mock:    &mockCheckIO{binderBytes: checkBinder("- [Chapter One](chapter-one.md)"), writeErr: errors.New("disk")},
+			args:    []string{"chapter-one", "--project", "."},
+			wantErr: "writing binder",
+		},
+		{
+			name:    "missing selector argument",
+			mock:    &mockCheckIO{},
+			args:    []string{"--project", "."},
+			wantErr: "accepts 1 arg",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCheckCmd(tt.mock)
+			c.SetOut(new(bytes.Buffer))
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(tt.args)
+			err := c.Execute()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Compile-time assertion: *fileCheckIO satisfies CheckIO.
+var _ CheckIO = (*fileCheckIO)(nil)
+
+func TestFileCheckIO_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	content := checkBinder("- [Chapter One](chapter-one.md)")
+	if err := os.WriteFile(filepath.Join(dir, "chapter-one.md"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fio := newDefaultCheckIO()
+	if err := fio.WriteBinderAtomic(context.Background(), binderPath, content); err != nil {
+		t.Fatalf("WriteBinderAtomic: %v", err)
+	}
+	if got, err := fio.ReadBinder(context.Background(), binderPath); err != nil || !bytes.Equal(got, content) {
+		t.Errorf("ReadBinder = %q, %v", got, err)
+	}
+	proj, err := fio.ScanProject(context.Background(), binderPath)
+	if err != nil || len(proj.Files) != 1 || proj.Files[0] != "chapter-one.md" {
+		t.Errorf("ScanProject = %+v, %v", proj, err)
+	}
+}
+
+func TestNewCheckCmd_OutputErrors(t *testing.T) {
+	src := checkBinder("- [Chapter One](chapter-one.md)")
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"json encode error", []string{"chapter-one", "--project", ".", "--json"}, "encoding output"},
+		{"text write error", []string{"chapter-one", "--project", "."}, "writing output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCheckCmd(&mockCheckIO{binderBytes: src})
+			c.SetOut(&errWriter{err: errors.New("closed")})
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(tt.args)
+			err := c.Execute()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewCheckCmd_GetwdError(t *testing.T) {
+	c := newCheckStateCmdWithGetCWD(&mockCheckIO{}, func() (string, error) {
+		return "", errors.New("getwd failed")
+	}, true)
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"chapter-one"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+		t.Errorf("error = %v, want getwd failure", err)
+	}
+}
@@ -0,0 +1,398 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// Implementation-specific diagnostic codes reported by check-links.
+const (
+	// CodeBrokenLocalLink marks a body link to a project file that does not exist.
+	CodeBrokenLocalLink = "PMKE002"
+	// CodeDeadExternalLink marks an http(s) link whose server answered with
+	// an error status.
+	CodeDeadExternalLink = "PMKE003"
+	// CodeUnreachableExternalLink marks an http(s) link that could not be
+	// checked (DNS failure, refused connection, timeout). It is a warning
+	// because the cause is often transient.
+	CodeUnreachableExternalLink = "PMKW005"
+)
+
+// linkCacheFilename is the external link cache's path relative to the
+// project directory.
+const linkCacheFilename = ".prosemark/link-cache.json"
+
+// CheckLinksIO handles I/O for the check-links command.
+type CheckLinksIO interface {
+	ReadBinder(ctx context.Context, path string) ([]byte, error)
+	ReadNodeFile(path string) ([]byte, error)
+	// FileExists reports whether a file exists at path.
+	FileExists(path string) (bool, error)
+	// CheckURL returns the HTTP status code for rawURL, giving up after timeout.
+	CheckURL(ctx context.Context, rawURL string, timeout time.Duration) (int, error)
+	// ReadLinkCache returns the raw external link cache for projectDir, or
+	// nil when there is none.
+	ReadLinkCache(projectDir string) ([]byte, error)
+	// WriteLinkCache replaces the external link cache for projectDir.
+	WriteLinkCache(projectDir string, data []byte) error
+}
+
+// linkDiagnosticJSON is the JSON output type for a single link problem.
+type linkDiagnosticJSON struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Target   string `json:"target"`
+}
+
+// checkLinksOutput is the JSON output schema for check-links.
+type checkLinksOutput struct {
+	Version     string               `json:"version"`
+	Diagnostics []linkDiagnosticJSON `json:"diagnostics"`
+}
+
+// linkCache records the outcome of past external link checks, so repeated
+// runs and --offline runs need not hit the network.
+type linkCache struct {
+	Version string                    `json:"version"`
+	URLs    map[string]linkCacheEntry `json:"urls"`
+}
+
+// linkCacheEntry is the cached HTTP status of one URL.
+type linkCacheEntry struct {
+	Status  int    `json:"status"`
+	Checked string `json:"checked"` // RFC 3339 UTC time of the check
+}
+
+// urlCheck is the outcome of checking one external URL.
+type urlCheck struct {
+	status int
+	err    error
+}
+
+// nodeLinks holds the links found in one binder node's file.
+type nodeLinks struct {
+	target string
+	path   string
+	links  []node.BodyLink
+}
+
+// NewCheckLinksCmd creates the check-links subcommand.
+func NewCheckLinksCmd(io CheckLinksIO) *cobra.Command {
+	return newCheckLinksCmdWithGetCWD(io, os.Getwd)
+}
+
+func newCheckLinksCmdWithGetCWD(io CheckLinksIO, getwd func() (string, error)) *cobra.Command {
+	var (
+		external    bool
+		offline     bool
+		jsonMode    bool
+		concurrency int
+		timeout     time.Duration
+		cacheTTL    time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "check-links",
+		Short: "Report broken links in node bodies",
+		Long: "Report broken links in the bodies of the binder's node files.\n\n" +
+			"Relative links to project files are always checked. With --external,\n" +
+			"http(s) links are checked too, with HEAD requests (falling back to GET\n" +
+			"for servers that refuse HEAD). Results are cached in " + linkCacheFilename + "\n" +
+			"for --cache-ttl; --offline reports from the cache without any requests.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if concurrency < 1 {
+				return fmt.Errorf("--concurrency must be at least 1")
+			}
+			if timeout <= 0 {
+				return fmt.Errorf("--timeout must be positive")
+			}
+			if offline && !external {
+				return fmt.Errorf("--offline requires --external")
+			}
+
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			binderBytes, err := io.ReadBinder(ctx, binderPath)
+			if err != nil {
+				return fmt.Errorf("reading binder: %w", err)
+			}
+			parsed, _, err := binder.Parse(ctx, binderBytes, nil)
+			if err != nil {
+				return fmt.Errorf("cannot parse binder: %w", err)
+			}
+
+			projectDir := filepath.Dir(binderPath)
+			var files []nodeLinks
+			for _, n := range binderTargetNodes(parsed.Root) {
+				nodePath, ok := resolveTargetPath(cmd, projectDir, n.Target)
+				if !ok {
+					continue
+				}
+				content, err := io.ReadNodeFile(nodePath)
+				if errors.Is(err, node.ErrLockedBody) {
+					return fmt.Errorf("%s: %w", n.Target, err)
+				}
+				if err != nil {
+					continue // missing files are doctor's concern (AUD001)
+				}
+				files = append(files, nodeLinks{target: n.Target, path: nodePath, links: node.ParseBodyLinks(content)})
+			}
+
+			var checks map[string]urlCheck
+			if external {
+				var skipped int
+				checks, skipped, err = checkExternalLinks(ctx, io, projectDir, files, externalCheckOptions{
+					offline: offline, concurrency: concurrency, timeout: timeout, cacheTTL: cacheTTL,
+				})
+				if err != nil {
+					return err
+				}
+				if skipped > 0 {
+					fmt.Fprintf(cmd.ErrOrStderr(), "%d external link(s) not checked: not in the cache and --offline is set\n", skipped)
+				}
+			}
+
+			out := checkLinksOutput{Version: "1", Diagnostics: []linkDiagnosticJSON{}}
+			for _, f := range files {
+				for _, l := range f.links {
+					d, err := diagnoseLink(io, f, l, checks)
+					if err != nil {
+						return err
+					}
+					if d != nil {
+						out.Diagnostics = append(out.Diagnostics, *d)
+					}
+				}
+			}
+
+			if jsonMode {
+				if err := encodeOutput(cmd, out); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+			} else {
+				for _, d := range out.Diagnostics {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s:%d:%d %s %s\n", sanitizePath(d.Path), d.Line, d.Column, d.Code, sanitizePath(d.Message))
+				}
+			}
+
+			for _, d := range out.Diagnostics {
+				if d.Severity == "error" {
+					return fmt.Errorf("found broken links")
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().BoolVar(&external, "external", false, "also check http(s) links")
+	cmd.Flags().BoolVar(&offline, "offline", false, "with --external, report only from the link cache without network requests")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 8, "maximum simultaneous external requests")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "timeout for each external request")
+	cmd.Flags().DurationVar(&cacheTTL, "cache-ttl", 24*time.Hour, "reuse cached external results younger than this (0 disables the cache)")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+
+	return cmd
+}
+
+// diagnoseLink returns the diagnostic for one link, or nil when it is fine
+// or was not checked. checks holds external results by URL (nil when
+// external links are not being checked).
+func diagnoseLink(io CheckLinksIO, f nodeLinks, l node.BodyLink, checks map[string]urlCheck) (*linkDiagnosticJSON, error) {
+	d := &linkDiagnosticJSON{Severity: "error", Path: f.target, Line: l.Line, Column: l.Column, Target: l.Target}
+	switch {
+	case l.External():
+		c, ok := checks[l.Target]
+		switch {
+		case !ok || (c.err == nil && c.status < 400):
+			return nil, nil
+		case c.err != nil:
+			d.Severity, d.Code = "warning", CodeUnreachableExternalLink
+			d.Message = fmt.Sprintf("could not reach %s: %v", l.Target, c.err)
+		default:
+			d.Code = CodeDeadExternalLink
+			d.Message = fmt.Sprintf("dead link %s: HTTP %d", l.Target, c.status)
+		}
+	case l.Local():
+		rel := l.LocalPath()
+		exists, err := io.FileExists(filepath.Join(filepath.Dir(f.path), filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, fmt.Errorf("checking link %s in %s: %w", l.Target, f.target, err)
+		}
+		if exists {
+			return nil, nil
+		}
+		d.Code = CodeBrokenLocalLink
+		d.Message = fmt.Sprintf("broken link %s: no such file", l.Target)
+	default:
+		return nil, nil // other schemes (mailto:, ftp:) and #fragments
+	}
+	return d, nil
+}
+
+// externalCheckOptions tune checkExternalLinks.
+type externalCheckOptions struct {
+	offline     bool
+	concurrency int
+	timeout     time.Duration
+	cacheTTL    time.Duration
+}
+
+// checkExternalLinks checks every distinct http(s) URL linked from files,
+// reusing fresh cache entries (any cached entry when offline), and returns
+// the outcome per URL with the number of URLs left unchecked because they
+// were uncached in offline mode. New results are written back to the cache;
+// failures to reach a server are not cached since they are often transient.
+func checkExternalLinks(ctx context.Context, io CheckLinksIO, projectDir string, files []nodeLinks, opts externalCheckOptions) (map[string]urlCheck, int, error) {
+	cache := loadLinkCache(io, projectDir)
+	now := nowUTCFunc()
+	// nowUTCFunc always returns RFC 3339.
+	current, _ := time.Parse(time.RFC3339, now)
+	checks := make(map[string]urlCheck)
+	seen := make(map[string]bool)
+	var pending []string
+	skipped := 0
+	for _, f := range files {
+		for _, l := range f.links {
+			if seen[l.Target] || !l.External() {
+				continue
+			}
+			seen[l.Target] = true
+			if e, ok := cache.URLs[l.Target]; ok && (opts.offline || linkCacheFresh(e, current, opts.cacheTTL)) {
+				checks[l.Target] = urlCheck{status: e.Status}
+				continue
+			}
+			if opts.offline {
+				skipped++
+				continue
+			}
+			pending = append(pending, l.Target)
+		}
+	}
+	if len(pending) == 0 {
+		return checks, skipped, nil
+	}
+
+	results := make([]urlCheck, len(pending))
+	sem := make(chan struct{}, opts.concurrency)
+	var wg sync.WaitGroup
+	for i, u := range pending {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i].status, results[i].err = io.CheckURL(ctx, u, opts.timeout)
+		}()
+	}
+	wg.Wait()
+
+	for i, u := range pending {
+		checks[u] = results[i]
+		if results[i].err == nil {
+			cache.URLs[u] = linkCacheEntry{Status: results[i].status, Checked: now}
+		}
+	}
+	if err := io.WriteLinkCache(projectDir, encodeLinkCache(cache)); err != nil {
+		return nil, 0, fmt.Errorf("writing link cache: %w", err)
+	}
+	return checks, skipped, nil
+}
+
+// loadLinkCache reads the project's link cache. A missing, unreadable, or
+// malformed cache is treated as empty: it only saves network requests.
+func loadLinkCache(io CheckLinksIO, projectDir string) linkCache {
+	cache := linkCache{Version: "1"}
+	if data, err := io.ReadLinkCache(projectDir); err == nil && data != nil {
+		_ = json.Unmarshal(data, &cache)
+	}
+	if cache.URLs == nil {
+		cache.URLs = make(map[string]linkCacheEntry)
+	}
+	return cache
+}
+
+// encodeLinkCache returns the on-disk form of cache.
+func encodeLinkCache(cache linkCache) []byte {
+	// Encoding plain strings and ints cannot fail.
+	data, _ := json.MarshalIndent(cache, "", "  ")
+	return append(data, '\n')
+}
+
+// linkCacheFresh reports whether e was checked less than ttl before now.
+// An entry with an unreadable time is stale.
+func linkCacheFresh(e linkCacheEntry, now time.Time, ttl time.Duration) bool {
+	checked, err := time.Parse(time.RFC3339, e.Checked)
+	return err == nil && now.Sub(checked) < ttl
+}
+
+// fileCheckLinksIO implements CheckLinksIO using OS file and network I/O.
+type fileCheckLinksIO struct {
+	fileProjectIO
+}
+
+// FileExists reports whether a file exists at path.
+func (f fileCheckLinksIO) FileExists(path string) (bool, error) {
+	return fsio.StatFile(path)
+}
+
+// CheckURL sends a HEAD request for rawURL, retrying with GET when the
+// server does not support HEAD, and returns the response status.
+func (f fileCheckLinksIO) CheckURL(ctx context.Context, rawURL string, timeout time.Duration) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	status, err := httpStatus(ctx, http.MethodHead, rawURL)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = httpStatus(ctx, http.MethodGet, rawURL)
+	}
+	return status, err
+}
+
+// ReadLinkCache reads the project's link cache, returning nil when absent.
+func (f fileCheckLinksIO) ReadLinkCache(projectDir string) ([]byte, error) {
+	data, _, err := fsio.ReadFileIfExists(filepath.Join(projectDir, filepath.FromSlash(linkCacheFilename)))
+	return data, err
+}
+
+// WriteLinkCache writes the project's link cache atomically.
+func (f fileCheckLinksIO) WriteLinkCache(projectDir string, data []byte) error {
+	return fsio.WriteFileAtomicMkdir(filepath.Join(projectDir, filepath.FromSlash(linkCacheFilename)), ".link-cache", data)
+}
+
+// httpStatus performs one request and returns the response status code.
+func httpStatus(ctx context.Context, method, rawURL string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "pmk-check-links")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,337 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mockCheckLinksIO is a test double for CheckLinksIO rooted at /proj.
+type mockCheckLinksIO struct {
+	binderBytes []byte
+	binderErr   error
+	nodeFiles   map[string]string // full path → content; missing paths fail to read
+	existing    map[string]bool   // full path → exists
+	existsErr   error
+	statuses    map[string]int
+	urlErrs     map[string]error
+	cache       []byte
+	cacheErr    error
+	writeErr    error
+
+	mu      sync.Mutex
+	checked []string
+	written []byte
+}
+
+func (m *mockCheckLinksIO) ReadBinder(_ context.Context, _ string) ([]byte, error) {
+	return m.binderBytes, m.binderErr
+}
+
+func (m *mockCheckLinksIO) ReadNodeFile(path string) ([]byte, error) {
+	content, ok := m.nodeFiles[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return []byte(content), nil
+}
+
+func (m *mockCheckLinksIO) FileExists(path string) (bool, error) {
+	return m.existing[path], m.existsErr
+}
+
+func (m *mockCheckLinksIO) CheckURL(_ context.Context, rawURL string, _ time.Duration) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checked = append(m.checked, rawURL)
+	return m.statuses[rawURL], m.urlErrs[rawURL]
+}
+
+func (m *mockCheckLinksIO) ReadLinkCache(_ string) ([]byte, error) {
+	return m.cache, m.cacheErr
+}
+
+func (m *mockCheckLinksIO) WriteLinkCache(_ string, data []byte) error {
+	m.written = data
+	return m.writeErr
+}
+
+const checkLinksNow = "2026-03-01T12:00:00Z"
+
+func newLinksMock(body string) *mockCheckLinksIO {
+	return &mockCheckLinksIO{
+		binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [Ch](ch.md)\n"),
+		nodeFiles:   map[string]string{"/proj/ch.md": "---\nid: ch\n---\n" + body},
+		existing:    map[string]bool{},
+		statuses:    map[string]int{},
+		urlErrs:     map[string]error{},
+	}
+}
+
+func runCheckLinks(t *testing.T, mock *mockCheckLinksIO, args ...string) (string, string, error) {
+	t.Helper()
+	orig := nowUTCFunc
+	nowUTCFunc = func() string { return checkLinksNow }
+	t.Cleanup(func() { nowUTCFunc = orig })
+
+	c := NewCheckLinksCmd(mock)
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(errOut)
+	c.SetArgs(append([]string{"--project", "/proj"}, args...))
+	err := c.Execute()
+	return out.String(), errOut.String(), err
+}
+
+func decodeLinkDiags(t *testing.T, out string) []linkDiagnosticJSON {
+	t.Helper()
+	var got checkLinksOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("decoding %q: %v", out, err)
+	}
+	return got.Diagnostics
+}
+
+func TestCheckLinks_LocalLinks(t *testing.T) {
+	mock := newLinksMock("See [notes](notes.md#intro), [img](img/a%20b.png?x=1), [gone](gone.md),\n" +
+		"[mail](mailto:a@b.c), [here](#top), [abs](/etc/x), and https://example.com/page.\n")
+	mock.nodeFiles["/proj/part/sub.md"] = "Back to [ch](../ch.md) or [lost](lost.md), [pct](100%.md).\n"
+	mock.binderBytes = []byte("<!-- prosemark-binder:v1 -->\n- [Ch](ch.md)\n  - [Sub](part/sub.md)\n- [Missing](missing.md)\n- [Abs](/abs.md)\n")
+	mock.existing["/proj/notes.md"] = true
+	mock.existing["/proj/img/a b.png"] = true
+	mock.existing["/proj/ch.md"] = true
+	mock.existing["/proj/part/100%.md"] = true
+
+	out, _, err := runCheckLinks(t, mock)
+	if err == nil || err.Error() != "found broken links" {
+		t.Errorf("err = %v, want found broken links", err)
+	}
+	want := "ch.md:4:56 PMKE002 broken link gone.md: no such file\n" +
+		"part/sub.md:1:27 PMKE002 broken link lost.md: no such file\n"
+	if out != want {
+		t.Errorf("stdout =\n%s\nwant\n%s", out, want)
+	}
+	if len(mock.checked) != 0 {
+		t.Errorf("external links checked without --external: %v", mock.checked)
+	}
+}
+
+func TestCheckLinks_External(t *testing.T) {
+	mock := newLinksMock("[ok](https://ok.test/) [dead](https://dead.test/x)\n" +
+		"<https://down.test/> and again https://dead.test/x.\n")
+	mock.statuses["https://ok.test/"] = 200
+	mock.statuses["https://dead.test/x"] = 404
+	mock.urlErrs["https://down.test/"] = errors.New("connection refused")
+
+	out, _, err := runCheckLinks(t, mock, "--external", "--json", "--concurrency", "2")
+	if err == nil {
+		t.Error("expected an error for a dead link")
+	}
+	diags := decodeLinkDiags(t, out)
+	var got []string
+	for _, d := range diags {
+		got = append(got, d.Severity+" "+d.Code+" "+d.Target)
+	}
+	want := []string{
+		"error PMKE003 https://dead.test/x",
+		"warning PMKW005 https://down.test/",
+		"error PMKE003 https://dead.test/x",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("diagnostics =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if len(mock.checked) != 3 {
+		t.Errorf("checked %v, want each distinct URL once", mock.checked)
+	}
+
+	var cache linkCache
+	if err := json.Unmarshal(mock.written, &cache); err != nil {
+		t.Fatalf("cache %q: %v", mock.written, err)
+	}
+	if len(cache.URLs) != 2 || cache.URLs["https://dead.test/x"] != (linkCacheEntry{Status: 404, Checked: checkLinksNow}) {
+		t.Errorf("cache = %+v, want ok and dead entries only", cache.URLs)
+	}
+}
+
+func TestCheckLinks_Cache(t *testing.T) {
+	cache := `{"version":"1","urls":{
+		"https://fresh.test/": {"status": 410, "checked": "2026-03-01T11:00:00Z"},
+		"https://stale.test/": {"status": 410, "checked": "2026-02-27T11:00:00Z"},
+		"https://bad.test/": {"status": 410, "checked": "yesterday"}}}`
+	body := "https://fresh.test/ https://stale.test/ https://bad.test/ https://new.test/\n"
+
+	t.Run("fresh entries skip the network", func(t *testing.T) {
+		mock := newLinksMock(body)
+		mock.cache = []byte(cache)
+		mock.statuses["https://stale.test/"] = 200
+		mock.statuses["https://bad.test/"] = 200
+		mock.statuses["https://new.test/"] = 200
+		out, _, _ := runCheckLinks(t, mock, "--external", "--json")
+		if strings.Join(mock.checked, " ") == "" || containsURL(mock.checked, "https://fresh.test/") {
+			t.Errorf("checked = %v, want all but the fresh entry", mock.checked)
+		}
+		if diags := decodeLinkDiags(t, out); len(diags) != 1 || diags[0].Target != "https://fresh.test/" {
+			t.Errorf("diagnostics = %+v, want the cached 410 only", diags)
+		}
+	})
+
+	t.Run("offline uses any cached entry", func(t *testing.T) {
+		mock := newLinksMock(body)
+		mock.cache = []byte(cache)
+		out, errOut, err := runCheckLinks(t, mock, "--external", "--offline", "--json")
+		if err == nil {
+			t.Error("expected an error for cached dead links")
+		}
+		if len(mock.checked) != 0 || mock.written != nil {
+			t.Errorf("offline run checked %v / wrote cache %q", mock.checked, mock.written)
+		}
+		if diags := decodeLinkDiags(t, out); len(diags) != 3 {
+			t.Errorf("diagnostics = %+v, want the three cached 410s", diags)
+		}
+		if !strings.Contains(errOut, "1 external link(s) not checked") {
+			t.Errorf("stderr = %q, want the unchecked count", errOut)
+		}
+	})
+
+	for name, mock := range map[string]*mockCheckLinksIO{
+		"malformed cache":  {cache: []byte("{not json")},
+		"unreadable cache": {cacheErr: errors.New("denied")},
+	} {
+		t.Run(name+" is ignored", func(t *testing.T) {
+			m := newLinksMock("https://new.test/\n")
+			m.cache, m.cacheErr = mock.cache, mock.cacheErr
+			m.statuses["https://new.test/"] = 200
+			if _, _, err := runCheckLinks(t, m, "--external"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(m.checked) != 1 {
+				t.Errorf("checked = %v", m.checked)
+			}
+		})
+	}
+}
+
+func containsURL(list []string, u string) bool {
+	for _, s := range list {
+		if s == u {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCheckLinks_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(m *mockCheckLinksIO)
+		args    []string
+		out     *errWriter
+		wantErr string
+	}{
+		{name: "concurrency", args: []string{"--concurrency", "0"}, wantErr: "--concurrency must be at least 1"},
+		{name: "timeout", args: []string{"--timeout", "0s"}, wantErr: "--timeout must be positive"},
+		{name: "offline without external", args: []string{"--offline"}, wantErr: "--offline requires --external"},
+		{name: "empty project", args: []string{"--project", ""}, wantErr: "--project flag cannot be empty"},
+		{name: "binder read", setup: func(m *mockCheckLinksIO) { m.binderErr = errors.New("denied") }, wantErr: "reading binder: denied"},
+		{name: "binder parse", setup: func(m *mockCheckLinksIO) { m.binderBytes = []byte{0xff} }, wantErr: "cannot parse binder"},
+		{name: "stat failure", setup: func(m *mockCheckLinksIO) { m.existsErr = errors.New("io") }, wantErr: "checking link a.md in ch.md: io"},
+		{
+			name: "cache write", args: []string{"--external"},
+			setup:   func(m *mockCheckLinksIO) { m.writeErr = errors.New("full") },
+			wantErr: "writing link cache: full",
+		},
+		{name: "json output", args: []string{"--json"}, out: &errWriter{errors.New("closed")}, wantErr: "encoding output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newLinksMock("[a](a.md) https://x.test/\n")
+			if tt.setup != nil {
+				tt.setup(mock)
+			}
+			c := NewCheckLinksCmd(mock)
+			if tt.out != nil {
+				c.SetOut(tt.out)
+			} else {
+				c.SetOut(new(bytes.Buffer))
+			}
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(append([]string{"--project", "/proj"}, tt.args...))
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckLinks_CleanProject(t *testing.T) {
+	mock := newLinksMock("No links here.\n```\n[a](inside-fence.md)\n```\n")
+	out, _, err := runCheckLinks(t, mock, "--external")
+	if err != nil || out != "" {
+		t.Errorf("out = %q, err = %v; want silence", out, err)
+	}
+	if mock.written != nil {
+		t.Error("cache must not be written when nothing was checked")
+	}
+}
+
+func TestFileCheckLinksIO(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/no-head" && r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case r.URL.Path == "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	var fio fileCheckLinksIO
+	ctx := context.Background()
+	for path, want := range map[string]int{"/": 200, "/no-head": 200, "/missing": 404} {
+		if got, err := fio.CheckURL(ctx, srv.URL+path, time.Second); err != nil || got != want {
+			t.Errorf("CheckURL(%s) = %d, %v; want %d", path, got, err, want)
+		}
+	}
+	if _, err := fio.CheckURL(ctx, "http://bad host/", time.Second); err == nil {
+		t.Error("expected error for an invalid URL")
+	}
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	if _, err := fio.CheckURL(ctx, closed.URL, time.Second); err == nil {
+		t.Error("expected error for an unreachable server")
+	}
+
+	dir := t.TempDir()
+	if data, err := fio.ReadLinkCache(dir); err != nil || data != nil {
+		t.Errorf("ReadLinkCache on a new project = %q, %v", data, err)
+	}
+	if err := fio.WriteLinkCache(dir, []byte("{}\n")); err != nil {
+		t.Fatalf("WriteLinkCache: %v", err)
+	}
+	if data, err := fio.ReadLinkCache(dir); err != nil || string(data) != "{}\n" {
+		t.Errorf("ReadLinkCache = %q, %v", data, err)
+	}
+
+	path := filepath.Join(dir, "ch.md")
+	if err := os.WriteFile(path, []byte("body"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := fio.FileExists(path); err != nil || !exists {
+		t.Errorf("FileExists = %v, %v", exists, err)
+	}
+	if data, err := fio.ReadNodeFile(path); err != nil || string(data) != "body" {
+		t.Errorf("ReadNodeFile = %q, %v", data, err)
+	}
+	if _, err := fio.ReadBinder(ctx, filepath.Join(dir, "_binder.md")); err == nil {
+		t.Error("expected error reading a missing binder")
+	}
+}
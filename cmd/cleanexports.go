@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+// exportManifestFilename is the export manifest's path relative to the
+// project directory.
+const exportManifestFilename = ".prosemark/exports.json"
+
+// exportManifest lists the files pmk export has written since the last
+// clean-exports, so stale outputs can be removed after nodes are renamed or
+// deleted.
+type exportManifest struct {
+	Version string `json:"version"`
+	// Files holds project-relative slash paths, or absolute paths for
+	// outputs written outside the project.
+	Files []string `json:"files"`
+}
+
+// CleanExportsIO handles I/O for the clean-exports command.
+type CleanExportsIO interface {
+	// ReadExportManifest returns the raw export manifest for projectDir, or
+	// nil when there is none.
+	ReadExportManifest(projectDir string) ([]byte, error)
+	// WriteExportManifest replaces the export manifest for projectDir.
+	WriteExportManifest(projectDir string, data []byte) error
+	RemoveFile(path string) error
+}
+
+// cleanExportsOutput is the JSON output schema for clean-exports.
+type cleanExportsOutput struct {
+	Version string   `json:"version"`
+	DryRun  bool     `json:"dryRun"`
+	Removed []string `json:"removed"`
+	// Missing lists manifest entries that were already gone.
+	Missing []string `json:"missing"`
+}
+
+// NewCleanExportsCmd creates the clean-exports subcommand.
+func NewCleanExportsCmd(io CleanExportsIO) *cobra.Command {
+	return newCleanExportsCmdWithGetCWD(io, os.Getwd)
+}
+
+func newCleanExportsCmdWithGetCWD(io CleanExportsIO, getwd func() (string, error)) *cobra.Command {
+	var (
+		dryRun   bool
+		jsonMode bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "clean-exports",
+		Short: "Remove the files written by previous exports",
+		Long: "Remove every file pmk export has written since the last clean-exports, as\n" +
+			"listed in " + exportManifestFilename + ", so stale chunks do not linger after\n" +
+			"nodes are renamed or deleted. Only listed files are removed; directories\n" +
+			"and anything else in them are left alone.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			projectDir, err := resolveProjectDirFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			manifest, err := loadExportManifest(io, projectDir)
+			if err != nil {
+				return err
+			}
+
+			out := cleanExportsOutput{Version: "1", DryRun: dryRun, Removed: []string{}, Missing: []string{}}
+			for i, f := range manifest.Files {
+				if dryRun {
+					out.Removed = append(out.Removed, f)
+					continue
+				}
+				err := io.RemoveFile(exportManifestFilePath(projectDir, f))
+				switch {
+				case errors.Is(err, os.ErrNotExist):
+					out.Missing = append(out.Missing, f)
+				case err != nil:
+					// Keep the files not yet removed listed for the next run.
+					manifest.Files = manifest.Files[i:]
+					return errors.Join(fmt.Errorf("removing %s: %w", sanitizePath(f), err), writeExportManifest(io, projectDir, manifest))
+				default:
+					out.Removed = append(out.Removed, f)
+				}
+			}
+			if !dryRun && len(manifest.Files) > 0 {
+				manifest.Files = nil
+				if err := writeExportManifest(io, projectDir, manifest); err != nil {
+					return err
+				}
+			}
+
+			if jsonMode {
+				if err := encodeOutput(cmd, out); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+				return nil
+			}
+			return printCleanExportsReport(cmd, out)
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory (default: current directory)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "list the files that would be removed without removing them")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+
+	return cmd
+}
+
+// printCleanExportsReport writes the human-readable clean-exports summary.
+func printCleanExportsReport(cmd *cobra.Command, out cleanExportsOutput) error {
+	verb := "Removed"
+	if out.DryRun {
+		verb = "Would remove"
+	}
+	var buf bytes.Buffer
+	for _, f := range out.Removed {
+		fmt.Fprintf(&buf, "%s %s\n", verb, sanitizePath(f))
+	}
+	for _, f := range out.Missing {
+		fmt.Fprintf(&buf, "Already gone: %s\n", sanitizePath(f))
+	}
+	if len(out.Removed) == 0 && len(out.Missing) == 0 {
+		buf.WriteString("No exported files to clean\n")
+	}
+	if _, err := cmd.OutOrStdout().Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	return nil
+}
+
+// loadExportManifest reads the export manifest for projectDir. A missing
+// manifest is empty; a malformed one is an error, since the files it lists
+// could not otherwise be found again.
+func loadExportManifest(io interface {
+	ReadExportManifest(projectDir string) ([]byte, error)
+}, projectDir string) (exportManifest, error) {
+	manifest := exportManifest{Version: "1"}
+	data, err := io.ReadExportManifest(projectDir)
+	if err != nil {
+		return manifest, fmt.Errorf("reading export manifest: %w", err)
+	}
+	if data != nil {
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return manifest, fmt.Errorf("cannot decode %s: %w (remove it to start a new one)", exportManifestFilename, err)
+		}
+	}
+	return manifest, nil
+}
+
+// writeExportManifest replaces the export manifest for projectDir.
+func writeExportManifest(io interface {
+	WriteExportManifest(projectDir string, data []byte) error
+}, projectDir string, manifest exportManifest) error {
+	if manifest.Files == nil {
+		manifest.Files = []string{}
+	}
+	// Encoding plain strings cannot fail.
+	data, _ := json.MarshalIndent(manifest, "", "  ")
+	if err := io.WriteExportManifest(projectDir, append(data, '\n')); err != nil {
+		return fmt.Errorf("writing export manifest: %w", err)
+	}
+	return nil
+}
+
+// addExportManifestFiles adds absolute paths to m, keeping its files sorted
+// and unique. Paths under projectAbs are recorded relative to it.
+func addExportManifestFiles(m *exportManifest, projectAbs string, paths []string) {
+	for _, p := range paths {
+		rel, err := filepath.Rel(projectAbs, p)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			p = rel
+		}
+		m.Files = append(m.Files, filepath.ToSlash(p))
+	}
+	slices.Sort(m.Files)
+	m.Files = slices.Compact(m.Files)
+}
+
+// exportManifestFilePath resolves a manifest entry against projectDir.
+func exportManifestFilePath(projectDir, entry string) string {
+	p := filepath.FromSlash(entry)
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(projectDir, p)
+}
+
+// fileCleanExportsIO implements CleanExportsIO using OS file I/O.
+type fileCleanExportsIO struct{}
+
+// ReadExportManifest reads the project's export manifest, returning nil when absent.
+func (f fileCleanExportsIO) ReadExportManifest(projectDir string) ([]byte, error) {
+	return readExportManifestFile(projectDir)
+}
+
+// WriteExportManifest writes the project's export manifest atomically.
+func (f fileCleanExportsIO) WriteExportManifest(projectDir string, data []byte) error {
+	return writeExportManifestFile(projectDir, data)
+}
+
+// RemoveFile removes the file at path.
+func (f fileCleanExportsIO) RemoveFile(path string) error {
+	return fsio.DeleteFile(path)
+}
+
+// readExportManifestFile reads projectDir's export manifest, or nil when absent.
+func readExportManifestFile(projectDir string) ([]byte, error) {
+	data, _, err := fsio.ReadFileIfExists(filepath.Join(projectDir, filepath.FromSlash(exportManifestFilename)))
+	return data, err
+}
+
+// writeExportManifestFile writes projectDir's export manifest atomically.
+func writeExportManifestFile(projectDir string, data []byte) error {
+	return fsio.WriteFileAtomicMkdir(filepath.Join(projectDir, filepath.FromSlash(exportManifestFilename)), ".exports", data)
+}
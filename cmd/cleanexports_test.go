@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mockCleanExportsIO is a test double for CleanExportsIO.
+type mockCleanExportsIO struct {
+	manifest         []byte
+	manifestErr      error
+	manifestWriteErr error
+	manifestWritten  []byte
+	removeErrs       map[string]error
+	removed          []string
+}
+
+func (m *mockCleanExportsIO) ReadExportManifest(_ string) ([]byte, error) {
+	return m.manifest, m.manifestErr
+}
+
+func (m *mockCleanExportsIO) WriteExportManifest(_ string, data []byte) error {
+	m.manifestWritten = data
+	return m.manifestWriteErr
+}
+
+func (m *mockCleanExportsIO) RemoveFile(path string) error {
+	if err := m.removeErrs[path]; err != nil {
+		return err
+	}
+	m.removed = append(m.removed, path)
+	return nil
+}
+
+const cleanExportsManifest = `{"version":"1","files":["/tmp/audio/0001.txt","audio/0001.txt","audio/0002.txt"]}`
+
+func runCleanExportsCmd(t *testing.T, mock *mockCleanExportsIO, args ...string) (string, error) {
+	t.Helper()
+	c := NewCleanExportsCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(append(args, "--project", "/proj"))
+	err := c.Execute()
+	return out.String(), err
+}
+
+func TestCleanExportsCmd(t *testing.T) {
+	mock := &mockCleanExportsIO{
+		manifest:   []byte(cleanExportsManifest),
+		removeErrs: map[string]error{"/proj/audio/0002.txt": os.ErrNotExist},
+	}
+	out, err := runCleanExportsCmd(t, mock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(mock.removed, ","); got != "/tmp/audio/0001.txt,/proj/audio/0001.txt" {
+		t.Errorf("removed = %s", got)
+	}
+	want := "Removed /tmp/audio/0001.txt\nRemoved audio/0001.txt\nAlready gone: audio/0002.txt\n"
+	if out != want {
+		t.Errorf("stdout = %q, want %q", out, want)
+	}
+	if want := "{\n  \"version\": \"1\",\n  \"files\": []\n}\n"; string(mock.manifestWritten) != want {
+		t.Errorf("manifest = %q, want %q", mock.manifestWritten, want)
+	}
+}
+
+func TestCleanExportsCmd_DryRunJSON(t *testing.T) {
+	mock := &mockCleanExportsIO{manifest: []byte(cleanExportsManifest)}
+	out, err := runCleanExportsCmd(t, mock, "--dry-run", "--json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got cleanExportsOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if !got.DryRun || len(got.Removed) != 3 || got.Missing == nil {
+		t.Errorf("output = %+v", got)
+	}
+	if mock.removed != nil || mock.manifestWritten != nil {
+		t.Errorf("dry run removed %v, wrote manifest %q", mock.removed, mock.manifestWritten)
+	}
+}
+
+func TestCleanExportsCmd_DryRunText(t *testing.T) {
+	out, err := runCleanExportsCmd(t, &mockCleanExportsIO{manifest: []byte(`{"version":"1","files":["a.txt"]}`)}, "--dry-run")
+	if err != nil || out != "Would remove a.txt\n" {
+		t.Errorf("clean-exports = %q, %v", out, err)
+	}
+}
+
+func TestCleanExportsCmd_NothingToClean(t *testing.T) {
+	mock := &mockCleanExportsIO{}
+	out, err := runCleanExportsCmd(t, mock)
+	if err != nil || out != "No exported files to clean\n" {
+		t.Errorf("clean-exports = %q, %v", out, err)
+	}
+	if mock.manifestWritten != nil {
+		t.Errorf("manifest written: %q", mock.manifestWritten)
+	}
+}
+
+func TestCleanExportsCmd_RemoveErrorKeepsRemaining(t *testing.T) {
+	mock := &mockCleanExportsIO{
+		manifest:   []byte(cleanExportsManifest),
+		removeErrs: map[string]error{"/proj/audio/0001.txt": errors.New("busy")},
+	}
+	_, err := runCleanExportsCmd(t, mock)
+	if err == nil || !strings.Contains(err.Error(), "removing audio/0001.txt: busy") {
+		t.Fatalf("error = %v, want remove failure", err)
+	}
+	var got exportManifest
+	if err := json.Unmarshal(mock.manifestWritten, &got); err != nil {
+		t.Fatalf("manifest %q: %v", mock.manifestWritten, err)
+	}
+	if files := strings.Join(got.Files, ","); files != "audio/0001.txt,audio/0002.txt" {
+		t.Errorf("remaining files = %s", files)
+	}
+}
+
+func TestCleanExportsCmd_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    *mockCleanExportsIO
+		wantErr string
+	}{
+		{"manifest read", &mockCleanExportsIO{manifestErr: errors.New("denied")}, "reading export manifest: denied"},
+		{"malformed manifest", &mockCleanExportsIO{manifest: []byte("[")}, "cannot decode .prosemark/exports.json"},
+		{"manifest write", &mockCleanExportsIO{manifest: []byte(cleanExportsManifest), manifestWriteErr: errors.New("full")}, "writing export manifest: full"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := runCleanExportsCmd(t, tt.mock); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCleanExportsCmd_OutputErrors(t *testing.T) {
+	for _, args := range [][]string{nil, {"--json"}} {
+		c := NewCleanExportsCmd(&mockCleanExportsIO{})
+		c.SetOut(&errWriter{err: errors.New("closed")})
+		c.SetErr(new(bytes.Buffer))
+		c.SetArgs(append(args, "--project", "/proj"))
+		if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "closed") {
+			t.Errorf("%v: error = %v, want output failure", args, err)
+		}
+	}
+}
+
+func TestCleanExportsCmd_GetwdError(t *testing.T) {
+	c := newCleanExportsCmdWithGetCWD(&mockCleanExportsIO{}, func() (string, error) {
+		return "", errors.New("getwd failed")
+	})
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+		t.Errorf("error = %v, want getwd failure", err)
+	}
+}
+
+func TestFileCleanExportsIO(t *testing.T) {
+	dir := t.TempDir()
+	fio := fileCleanExportsIO{}
+	if err := fio.WriteExportManifest(dir, []byte(cleanExportsManifest)); err != nil {
+		t.Fatalf("WriteExportManifest: %v", err)
+	}
+	if got, err := fio.ReadExportManifest(dir); err != nil || string(got) != cleanExportsManifest {
+		t.Errorf("ReadExportManifest = %q, %v", got, err)
+	}
+	path := filepath.Join(dir, "0001.txt")
+	if err := os.WriteFile(path, []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := fio.RemoveFile(path); err != nil {
+		t.Errorf("RemoveFile: %v", err)
+	}
+	if err := fio.RemoveFile(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("RemoveFile missing = %v, want ErrNotExist", err)
+	}
+}
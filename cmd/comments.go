@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// CommentsIO handles I/O for the comments command.
+type CommentsIO interface {
+	ReadBinder(ctx context.Context, path string) ([]byte, error)
+	ReadNodeFile(path string) ([]byte, error)
+	WriteNodeFileAtomic(path string, content []byte) error
+}
+
+// commentJSON is the JSON output type for a single review comment.
+type commentJSON struct {
+	Target   string `json:"target"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Author   string `json:"author,omitempty"`
+	Text     string `json:"text"`
+	Resolved bool   `json:"resolved"`
+}
+
+// commentsOutput is the JSON output schema for comments list.
+type commentsOutput struct {
+	Version  string        `json:"version"`
+	Comments []commentJSON `json:"comments"`
+}
+
+// NewCommentsCmd creates the comments command with list and resolve subcommands.
+func NewCommentsCmd(io CommentsIO) *cobra.Command {
+	return newCommentsCmdWithGetCWD(io, os.Getwd)
+}
+
+func newCommentsCmdWithGetCWD(io CommentsIO, getwd func() (string, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "comments",
+		Short: "List and resolve review comments in node files",
+		Long: "List and resolve review comments in node files.\n\n" +
+			"Comments are written inline in node bodies as\n" +
+			"  %% author: text %%   or   <!-- author: text -->\n" +
+			"The author tag is optional for %% comments. Resolving a comment marks it\n" +
+			"\"(resolved)\" in place rather than deleting it.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.PersistentFlags().String("project", "", "project directory containing the binder (default: current directory)")
+
+	cmd.AddCommand(newCommentsListCmd(io, getwd))
+	cmd.AddCommand(newCommentsResolveCmd(io, getwd))
+	return cmd
+}
+
+func newCommentsListCmd(io CommentsIO, getwd func() (string, error)) *cobra.Command {
+	var all, jsonMode bool
+
+	cmd := &cobra.Command{
+		Use:          "list [target]",
+		Short:        "List unresolved review comments, optionally for one node file",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			targets, err := commentsBinderTargets(cmd.Context(), io, binderPath)
+			if err != nil {
+				return err
+			}
+			if len(args) == 1 {
+				targets = []string{args[0]}
+			}
+
+			binderDir := filepath.Dir(binderPath)
+			out := commentsOutput{Version: "1", Comments: []commentJSON{}}
+			for _, target := range targets {
+				nodePath, err := safepath.Resolve(binderDir, target)
+				if err != nil {
+					return err
+				}
+				content, err := io.ReadNodeFile(nodePath)
+				if err != nil {
+					if errors.Is(err, node.ErrLockedBody) {
+						return fmt.Errorf("%s: %w", target, err)
+					}
+					if len(args) == 1 {
+						return fmt.Errorf("reading node file: %w", err)
+					}
+					continue // missing files are doctor's concern (AUD001)
+				}
+				for _, c := range node.ParseComments(content) {
+					if c.Resolved && !all {
+						continue
+					}
+					out.Comments = append(out.Comments, commentJSON{
+						Target: target, Line: c.Line, Column: c.Column,
+						Author: c.Author, Text: c.Text, Resolved: c.Resolved,
+					})
+				}
+			}
+
+			if jsonMode {
+				if err := encodeOutput(cmd, out); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+				return nil
+			}
+			for _, c := range out.Comments {
+				author := c.Author
+				if author == "" {
+					author = "(no author)"
+				}
+				status := ""
+				if c.Resolved {
+					status = " [resolved]"
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s:%d:%d %s%s: %s\n",
+					sanitizePath(c.Target), c.Line, c.Column, sanitizePath(author), status,
+					sanitizePath(node.FormatCommentText(c.Text)))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "include resolved comments")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+	return cmd
+}
+
+func newCommentsResolveCmd(io CommentsIO, getwd func() (string, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "resolve <target>:<line>",
+		Short:        "Mark the review comment at a location as resolved",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, line, err := parseCommentLocation(args[0])
+			if err != nil {
+				return err
+			}
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			nodePath, err := safepath.Resolve(filepath.Dir(binderPath), target)
+			if err != nil {
+				return err
+			}
+
+			content, err := io.ReadNodeFile(nodePath)
+			if err != nil {
+				return fmt.Errorf("reading node file: %w", err)
+			}
+
+			var matches []node.Comment
+			for _, c := range node.ParseComments(content) {
+				if c.Line == line && !c.Resolved {
+					matches = append(matches, c)
+				}
+			}
+			if len(matches) == 0 {
+				return fmt.Errorf("no unresolved comment at %s", sanitizePath(args[0]))
+			}
+
+			// Resolve from the end so earlier insertion offsets stay valid.
+			for i := len(matches) - 1; i >= 0; i-- {
+				content = node.ResolveComment(content, matches[i])
+			}
+			if stamped, _, err := stampUpdated(content); err == nil {
+				content = stamped
+			}
+			if err := io.WriteNodeFileAtomic(nodePath, content); err != nil {
+				return fmt.Errorf("writing node file: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Resolved %d comment(s) at %s\n", len(matches), sanitizePath(args[0]))
+			return nil
+		},
+	}
+	return cmd
+}
+
+// parseCommentLocation splits a "<target>:<line>" argument.
+func parseCommentLocation(arg string) (string, int, error) {
+	i := strings.LastIndex(arg, ":")
+	if i <= 0 {
+		return "", 0, fmt.Errorf("location %q must be <target>:<line>", arg)
+	}
+	line, err := strconv.Atoi(arg[i+1:])
+	if err != nil || line < 1 {
+		return "", 0, fmt.Errorf("location %q has an invalid line number", arg)
+	}
+	return arg[:i], line, nil
+}
+
+// commentsBinderTargets returns the binder's node targets in reading order,
+// skipping repeated targets.
+func commentsBinderTargets(ctx context.Context, io CommentsIO, binderPath string) ([]string, error) {
+	binderBytes, err := io.ReadBinder(ctx, binderPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading binder: %w", err)
+	}
+	result, _, err := binder.Parse(ctx, binderBytes, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse binder: %w", err)
+	}
+	var targets []string
+	for _, n := range binderTargetNodes(result.Root) {
+		targets = append(targets, n.Target)
+	}
+	return targets, nil
+}
+
+// fileCommentsIO implements CommentsIO using OS file I/O.
+type fileCommentsIO struct {
+	fileProjectIO
+}
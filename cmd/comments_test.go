@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mockCommentsIO is a test double for CommentsIO.
+type mockCommentsIO struct {
+	binderBytes []byte
+	binderErr   error
+	files       map[string]string
+	writeErr    error
+	written     map[string][]byte
+}
+
+func (m *mockCommentsIO) ReadBinder(_ context.Context, _ string) ([]byte, error) {
+	return m.binderBytes, m.binderErr
+}
+
+func (m *mockCommentsIO) ReadNodeFile(path string) ([]byte, error) {
+	for name, content := range m.files {
+		if strings.HasSuffix(path, "/"+name) {
+			return []byte(content), nil
+		}
+	}
+	return nil, os.ErrNotExist
+}
+
+func (m *mockCommentsIO) WriteNodeFileAtomic(path string, content []byte) error {
+	if m.written == nil {
+		m.written = make(map[string][]byte)
+	}
+	m.written[path] = content
+	return m.writeErr
+}
+
+const commentsTestBinder = "<!-- prosemark-binder:v1 -->\n" +
+	"- [One](one.md)\n" +
+	"  - [Two](two.md)\n" +
+	"- [Missing](missing.md)\n"
+
+const commentsTestNode = "---\nid: one\ncreated: 2025-01-01T00:00:00Z\nupdated: 2025-01-01T00:00:00Z\n---\n" +
+	"Body text %% ed: tighten this %%\n" +
+	"<!-- ann (resolved): old note -->\n"
+
+func runCommentsCmd(t *testing.T, mock *mockCommentsIO, args ...string) (string, error) {
+	t.Helper()
+	c := NewCommentsCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(append(args, "--project", "/proj"))
+	err := c.Execute()
+	return out.String(), err
+}
+
+func TestCommentsList_PrintsUnresolvedInBinderOrder(t *testing.T) {
+	mock := &mockCommentsIO{
+		binderBytes: []byte(commentsTestBinder),
+		files: map[string]string{
+			"one.md": commentsTestNode,
+			"two.md": "%% fix %%\n",
+		},
+	}
+	out, err := runCommentsCmd(t, mock, "list")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "one.md:6:11 ed: tighten this\ntwo.md:1:1 (no author): fix\n"
+	if out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestCommentsList_AllAndJSON(t *testing.T) {
+	mock := &mockCommentsIO{
+		binderBytes: []byte(commentsTestBinder),
+		files:       map[string]string{"one.md": commentsTestNode},
+	}
+	out, err := runCommentsCmd(t, mock, "list", "one.md", "--all", "--json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got commentsOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if got.Version != "1" || len(got.Comments) != 2 {
+		t.Fatalf("got %+v, want version 1 with 2 comments", got)
+	}
+	if c := got.Comments[1]; c.Author != "ann" || !c.Resolved || c.Line != 7 {
+		t.Errorf("second comment = %+v, want resolved comment by ann on line 7", c)
+	}
+}
+
+func TestCommentsList_AllText(t *testing.T) {
+	mock := &mockCommentsIO{
+		binderBytes: []byte(commentsTestBinder),
+		files:       map[string]string{"one.md": commentsTestNode},
+	}
+	out, err := runCommentsCmd(t, mock, "list", "--all")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "one.md:7:1 ann [resolved]: old note\n"; !strings.Contains(out, want) {
+		t.Errorf("output = %q, want to contain %q", out, want)
+	}
+}
+
+func TestCommentsList_EncodeError(t *testing.T) {
+	c := NewCommentsCmd(&mockCommentsIO{binderBytes: []byte(commentsTestBinder)})
+	c.SetOut(&errWriter{err: errors.New("closed")})
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"list", "--json", "--project", "/proj"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "encoding output") {
+		t.Errorf("error = %v, want encoding output error", err)
+	}
+}
+
+func TestCommentsList_InvalidBinder(t *testing.T) {
+	mock := &mockCommentsIO{binderBytes: []byte("- [Bad](b\xff.md)\n")}
+	if _, err := runCommentsCmd(t, mock, "list"); err == nil || !strings.Contains(err.Error(), "cannot parse binder") {
+		t.Errorf("error = %v, want binder parse error", err)
+	}
+}
+
+func TestComments_GetwdError(t *testing.T) {
+	for _, args := range [][]string{{"list"}, {"resolve", "one.md:6"}} {
+		t.Run(args[0], func(t *testing.T) {
+			c := newCommentsCmdWithGetCWD(&mockCommentsIO{}, func() (string, error) {
+				return "", errors.New("getwd failed")
+			})
+			c.SetOut(new(bytes.Buffer))
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(args)
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+				t.Errorf("error = %v, want getwd failure", err)
+			}
+		})
+	}
+}
+
+func TestComments_NoSubcommandPrintsHelp(t *testing.T) {
+	c := NewCommentsCmd(&mockCommentsIO{})
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetArgs(nil)
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "resolve") {
+		t.Errorf("help output = %q, want subcommand list", out.String())
+	}
+}
+
+func TestCommentsList_ExplicitTargetMissing(t *testing.T) {
+	mock := &mockCommentsIO{binderBytes: []byte(commentsTestBinder)}
+	if _, err := runCommentsCmd(t, mock, "list", "nope.md"); err == nil {
+		t.Fatal("expected error for missing target file")
+	}
+}
+
+func TestCommentsList_BinderReadError(t *testing.T) {
+	mock := &mockCommentsIO{binderErr: errors.New("boom")}
+	if _, err := runCommentsCmd(t, mock, "list"); err == nil {
+		t.Fatal("expected error when binder cannot be read")
+	}
+}
+
+func TestCommentsResolve_MarksCommentAndStampsUpdated(t *testing.T) {
+	orig := nowUTCFunc
+	nowUTCFunc = func() string { return "2026-01-01T00:00:00Z" }
+	t.Cleanup(func() { nowUTCFunc = orig })
+
+	mock := &mockCommentsIO{files: map[string]string{"one.md": commentsTestNode}}
+	out, err := runCommentsCmd(t, mock, "resolve", "one.md:6")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "Resolved 1 comment(s) at one.md:6") {
+		t.Errorf("output = %q", out)
+	}
+	written := string(mock.written["/proj/one.md"])
+	if !strings.Contains(written, "%% ed (resolved): tighten this %%") {
+		t.Errorf("comment not resolved in %q", written)
+	}
+	if !strings.Contains(written, "updated: \"2026-01-01T00:00:00Z\"") && !strings.Contains(written, "updated: 2026-01-01T00:00:00Z") {
+		t.Errorf("updated timestamp not stamped in %q", written)
+	}
+}
+
+func TestCommentsResolve_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  string
+		mock *mockCommentsIO
+	}{
+		{"missing line", "one.md", &mockCommentsIO{}},
+		{"bad line", "one.md:x", &mockCommentsIO{}},
+		{"missing file", "one.md:1", &mockCommentsIO{}},
+		{"no comment on line", "one.md:1", &mockCommentsIO{files: map[string]string{"one.md": commentsTestNode}}},
+		{"already resolved", "one.md:7", &mockCommentsIO{files: map[string]string{"one.md": commentsTestNode}}},
+		{"write fails", "one.md:6", &mockCommentsIO{files: map[string]string{"one.md": commentsTestNode}, writeErr: errors.New("disk full")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := runCommentsCmd(t, tt.mock, "resolve", tt.arg); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestComments_RejectsTargetsOutsideProject(t *testing.T) {
+	mock := &mockCommentsIO{binderBytes: []byte(commentsTestBinder)}
+	for _, args := range [][]string{{"list", "../secret.md"}, {"resolve", "../secret.md:1"}} {
+		if _, err := runCommentsCmd(t, mock, args...); err == nil || !strings.Contains(err.Error(), "escapes the project directory") {
+			t.Errorf("%v: err = %v, want containment error", args, err)
+		}
+	}
+}
+
+func TestFileCommentsIO_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	if err := os.WriteFile(binderPath, []byte(commentsTestBinder), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fio := fileCommentsIO{}
+	if got, err := fio.ReadBinder(context.Background(), binderPath); err != nil || string(got) != commentsTestBinder {
+		t.Errorf("ReadBinder = %q, %v", got, err)
+	}
+	nodePath := filepath.Join(dir, "one.md")
+	if err := fio.WriteNodeFileAtomic(nodePath, []byte(commentsTestNode)); err != nil {
+		t.Fatalf("WriteNodeFileAtomic: %v", err)
+	}
+	if got, err := fio.ReadNodeFile(nodePath); err != nil || string(got) != commentsTestNode {
+		t.Errorf("ReadNodeFile = %q, %v", got, err)
+	}
+}
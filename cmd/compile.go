@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// CompileIO handles I/O for the compile command.
+type CompileIO interface {
+	ReadBinder(ctx context.Context, path string) ([]byte, error)
+	// ReadNodeFile reads the node file at path, unlocking a locked body.
+	ReadNodeFile(path string) ([]byte, error)
+	// WriteOutputFile writes the manuscript to path atomically, creating
+	// parent directories.
+	WriteOutputFile(path string, data []byte) error
+	// Page shows data in pager, attached to the terminal.
+	Page(pager string, data []byte) error
+	// CopyToClipboard puts data on the system clipboard.
+	CopyToClipboard(data []byte) error
+}
+
+// NewCompileCmd creates the compile subcommand.
+func NewCompileCmd(io CompileIO) *cobra.Command {
+	return newCompileCmdWithGetCWD(io, os.Getwd)
+}
+
+func newCompileCmdWithGetCWD(io CompileIO, getwd func() (string, error)) *cobra.Command {
+	var from, to, selector, output string
+	var types []string
+	var pager, clipboard bool
+
+	cmd := &cobra.Command{
+		Use:   "compile",
+		Short: "Assemble the manuscript from node bodies in binder order",
+		Long: "Walk the binder in order and concatenate the body of each node file, with\n" +
+			"its frontmatter stripped, into a single Markdown manuscript on stdout or\n" +
+			"in --output. Placeholders are skipped.\n\n" +
+			"--from starts the manuscript at a node and --to ends it after a node's\n" +
+			"subtree; --selector is shorthand for giving both the same selector, to\n" +
+			"compile just that subtree. --type keeps only the nodes whose frontmatter\n" +
+			"type: is one of those given.\n\n" +
+			"--pager pipes the manuscript into $PAGER (default: less) and --clipboard\n" +
+			"copies it to the system clipboard, for reading a chapter as continuous\n" +
+			"prose without creating files.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if selector != "" && (from != "" || to != "") {
+				return fmt.Errorf("--selector conflicts with --from and --to")
+			}
+			if pager && clipboard {
+				return fmt.Errorf("--pager conflicts with --clipboard")
+			}
+			if (pager || clipboard) && output != "" {
+				return fmt.Errorf("--output conflicts with --pager and --clipboard")
+			}
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			binderBytes, err := io.ReadBinder(ctx, binderPath)
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					return fmt.Errorf("project not initialized — run 'pmk init' first")
+				}
+				return fmt.Errorf("reading binder: %w", err)
+			}
+			parsed, _, err := binder.Parse(ctx, binderBytes, nil)
+			if err != nil {
+				return fmt.Errorf("cannot parse binder: %w", err)
+			}
+			start, end := from, to
+			if selector != "" {
+				start, end = selector, selector
+			}
+			if err := resolveBookmarkSelectors(io.ReadNodeFile, filepath.Dir(binderPath), &start, &end); err != nil {
+				return err
+			}
+			nodes, err := compileRange(parsed.Root, start, end)
+			if err != nil {
+				return err
+			}
+			if len(types) > 0 {
+				nodes = filterNodeTypes(io, filepath.Dir(binderPath), nodes, types)
+			}
+
+			sep, err := compileSeparator(io, filepath.Dir(binderPath))
+			if err != nil {
+				return err
+			}
+			manuscript, count, err := assembleManuscript(cmd, io, filepath.Dir(binderPath), nodes, sep)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case pager:
+				p := os.Getenv("PAGER")
+				if p == "" {
+					p = "less"
+				}
+				if err := io.Page(p, manuscript); err != nil {
+					return fmt.Errorf("running pager: %w", err)
+				}
+				return nil
+			case clipboard:
+				if err := io.CopyToClipboard(manuscript); err != nil {
+					return fmt.Errorf("copying to clipboard: %w", err)
+				}
+				fmt.Fprintf(cmd.ErrOrStderr(), "Copied %d node(s) to the clipboard\n", count)
+				return nil
+			case output == "":
+				if _, err := cmd.OutOrStdout().Write(manuscript); err != nil {
+					return fmt.Errorf("writing output: %w", err)
+				}
+				return nil
+			}
+			if err := io.WriteOutputFile(output, manuscript); err != nil {
+				return fmt.Errorf("writing %s: %w", sanitizePath(output), err)
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Compiled %d node(s) into %s\n", count, sanitizePath(output))
+			return nil
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().StringVar(&from, "from", "", "selector of the node to start at (default: the beginning)")
+	cmd.Flags().StringVar(&to, "to", "", "selector of the node whose subtree ends the manuscript (default: the end)")
+	cmd.Flags().StringVar(&selector, "selector", "", "selector of the one node whose subtree to compile")
+	cmd.Flags().StringSliceVar(&types, "type", nil, "compile only nodes of these frontmatter types (repeatable or comma-separated)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "file to write the manuscript to (default: stdout)")
+	cmd.Flags().BoolVar(&pager, "pager", false, "pipe the manuscript into $PAGER instead of stdout")
+	cmd.Flags().BoolVar(&clipboard, "clipboard", false, "copy the manuscript to the system clipboard instead of stdout")
+	return cmd
+}
+
+// nodeFileReader reads node files, unlocking locked bodies.
+type nodeFileReader interface {
+	ReadNodeFile(path string) ([]byte, error)
+}
+
+// compileSeparator returns what goes between node bodies in a manuscript:
+// compile.separator in the config of the project in projectDir, or a blank
+// line.
+func compileSeparator(io nodeFileReader, projectDir string) ([]byte, error) {
+	settings, err := readProjectSettings(io.ReadNodeFile, projectDir)
+	if err != nil {
+		return nil, err
+	}
+	if settings.CompileSeparator != nil {
+		return []byte(*settings.CompileSeparator), nil
+	}
+	return []byte("\n\n"), nil
+}
+
+// assembleManuscript joins the bodies of nodes' files in projectDir, their
+// frontmatter stripped and includes expanded (see node.ExpandIncludes), into
+// one Markdown document with sep between them, returning it and how many
+// nodes had prose. Unreadable files and broken includes are warned about and
+// skipped; a locked body that cannot be unlocked is an error.
+func assembleManuscript(cmd *cobra.Command, io nodeFileReader, projectDir string, nodes []*binder.Node, sep []byte) ([]byte, int, error) {
+	readInclude := func(target string) ([]byte, error) {
+		path, err := safepath.Resolve(projectDir, target)
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadNodeFile(path)
+	}
+	var (
+		bodies [][]byte
+		diags  []binder.Diagnostic
+	)
+	for _, n := range nodes {
+		nodePath, err := safepath.Resolve(projectDir, n.Target)
+		if err != nil {
+			diags = append(diags, safepath.Diagnostic(n.Target))
+			continue
+		}
+		content, err := io.ReadNodeFile(nodePath)
+		if errors.Is(err, node.ErrLockedBody) {
+			return nil, 0, fmt.Errorf("%s: %w", n.Target, err)
+		}
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: skipping unreadable node file %s\n", sanitizePath(n.Target))
+			continue
+		}
+		body, includeDiags, err := node.ExpandIncludes(n.Target, content, readInclude)
+		if err != nil {
+			return nil, 0, err
+		}
+		diags = append(diags, includeDiags...)
+		if body := bytes.Trim(body, "\r\n"); len(bytes.TrimSpace(body)) > 0 {
+			bodies = append(bodies, body)
+		}
+	}
+	printDiagnostics(cmd, diags)
+	manuscript := bytes.Join(bodies, sep)
+	if len(manuscript) > 0 {
+		manuscript = append(manuscript, '\n')
+	}
+	return manuscript, len(bodies), nil
+}
+
+// filterNodeTypes returns the nodes whose node files in projectDir have one
+// of types as their frontmatter type. A file that cannot be read is kept, so
+// assembleManuscript reports it.
+func filterNodeTypes(io nodeFileReader, projectDir string, nodes []*binder.Node, types []string) []*binder.Node {
+	want := make(map[string]bool, len(types))
+	for _, t := range types {
+		want[strings.TrimSpace(t)] = true
+	}
+	var kept []*binder.Node
+	for _, n := range nodes {
+		nodePath, err := safepath.Resolve(projectDir, n.Target)
+		if err != nil {
+			kept = append(kept, n)
+			continue
+		}
+		content, err := io.ReadNodeFile(nodePath)
+		if err != nil {
+			kept = append(kept, n)
+			continue
+		}
+		if fm, _, err := node.ParseFrontmatter(content); err == nil && want[fm.Type] {
+			kept = append(kept, n)
+		}
+	}
+	return kept
+}
+
+// compileRange returns the nodes with a target under root in binder order,
+// starting at the node selected by from and ending with the last node in the
+// subtree of the node selected by to. An empty selector leaves that end
+// open. A target linked more than once is compiled once, where it first
+// appears.
+func compileRange(root *binder.Node, from, to string) ([]*binder.Node, error) {
+	// order lists root's descendants depth-first; end[n] is the index just
+	// past n's subtree.
+	var order []*binder.Node
+	index := map[*binder.Node]int{root: 0}
+	end := make(map[*binder.Node]int)
+	var walk func(n *binder.Node)
+	walk = func(n *binder.Node) {
+		for _, c := range n.Children {
+			index[c] = len(order)
+			order = append(order, c)
+			walk(c)
+			end[c] = len(order)
+		}
+	}
+	walk(root)
+	end[root] = len(order)
+
+	start, stop := 0, len(order)
+	if from != "" {
+		n, err := selectCompileNode(root, "--from", from)
+		if err != nil {
+			return nil, err
+		}
+		start = index[n]
+	}
+	if to != "" {
+		n, err := selectCompileNode(root, "--to", to)
+		if err != nil {
+			return nil, err
+		}
+		stop = end[n]
+	}
+	if from != "" && to != "" && stop <= start {
+		return nil, fmt.Errorf("--to %q ends before --from %q starts", to, from)
+	}
+
+	var nodes []*binder.Node
+	seen := make(map[string]bool)
+	for _, n := range order[start:stop] {
+		if n.Target != "" && !seen[n.Target] {
+			seen[n.Target] = true
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes, nil
+}
+
+// selectCompileNode resolves the selector given to flag to exactly one node.
+func selectCompileNode(root *binder.Node, flag, selector string) (*binder.Node, error) {
+	res, diags := binder.FindNodes(selector, root)
+	if len(res.Nodes) == 0 {
+		return nil, fmt.Errorf("%s: %s (%s)", flag, diags[0].Message, diags[0].Code)
+	}
+	if len(res.Nodes) > 1 {
+		return nil, fmt.Errorf("%s %q is ambiguous: matches %d nodes", flag, selector, len(res.Nodes))
+	}
+	return res.Nodes[0], nil
+}
+
+// fileCompileIO implements CompileIO using OS file I/O.
+type fileCompileIO struct {
+	fileProjectIO
+}
+
+// WriteOutputFile creates the output directory and writes data atomically.
+func (f fileCompileIO) WriteOutputFile(path string, data []byte) error {
+	return fsio.WriteFileAtomicMkdir(path, ".compile", data)
+}
+
+// Page shows data in pager.
+func (f fileCompileIO) Page(pager string, data []byte) error {
+	return fsio.Page(pager, data)
+}
+
+// CopyToClipboard puts data on the system clipboard.
+func (f fileCompileIO) CopyToClipboard(data []byte) error {
+	return fsio.CopyToClipboard(data)
+}
@@ -0,0 +1,365 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// mockCompileIO is a test double for CompileIO.
+type mockCompileIO struct {
+	binderBytes []byte
+	binderErr   error
+	files       map[string]string
+	lockedFile  string
+	writeErr    error
+	written     map[string][]byte
+	pager       string
+	paged       []byte
+	pageErr     error
+	clipboard   []byte
+	copyErr     error
+}
+
+func (m *mockCompileIO) ReadBinder(_ context.Context, _ string) ([]byte, error) {
+	return m.binderBytes, m.binderErr
+}
+
+func (m *mockCompileIO) ReadNodeFile(path string) ([]byte, error) {
+	if filepath.Base(path) == m.lockedFile {
+		return nil, node.ErrLockedBody
+	}
+	if content, ok := m.files[filepath.Base(path)]; ok {
+		return []byte(content), nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func (m *mockCompileIO) WriteOutputFile(path string, data []byte) error {
+	if m.written == nil {
+		m.written = make(map[string][]byte)
+	}
+	m.written[path] = data
+	return m.writeErr
+}
+
+func (m *mockCompileIO) Page(pager string, data []byte) error {
+	m.pager, m.paged = pager, data
+	return m.pageErr
+}
+
+func (m *mockCompileIO) CopyToClipboard(data []byte) error {
+	m.clipboard = data
+	return m.copyErr
+}
+
+const compileTestBinder = "<!-- prosemark-binder:v1 -->\n" +
+	"- [Part One](part1.md)\n" +
+	"  - [Chapter One](ch1.md)\n" +
+	"  - [Placeholder]()\n" +
+	"  - [Chapter Two](ch2.md)\n" +
+	"- [Part Two](part2.md)\n" +
+	"  - [Chapter One again](ch1.md)\n" +
+	"  - [Chapter Three](ch3.md)\n" +
+	"- [Escapes](a/../../outside.md)\n"
+
+func newCompileTestIO() *mockCompileIO {
+	return &mockCompileIO{
+		binderBytes: []byte(compileTestBinder),
+		files: map[string]string{
+			"part1.md": "---\ntitle: Part One\n---\n\n# Part One\n",
+			"ch1.md":   "---\ntitle: Chapter One\n---\n\nIt began.\n\nIt went on.\n\n\n",
+			"ch2.md":   "---\ntitle: Chapter Two\n---\n\n\n",
+			"part2.md": "No frontmatter here.\n",
+			"ch3.md":   "---\ntitle: Chapter Three\n---\nThe end.",
+		},
+	}
+}
+
+func runCompileCmd(t *testing.T, mock *mockCompileIO, args ...string) (string, string, error) {
+	t.Helper()
+	c := NewCompileCmd(mock)
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(errOut)
+	c.SetArgs(append(args, "--project", "/proj"))
+	err := c.Execute()
+	return out.String(), errOut.String(), err
+}
+
+func TestCompile_ConcatenatesBodiesInBinderOrder(t *testing.T) {
+	mock := newCompileTestIO()
+	delete(mock.files, "ch3.md")
+	out, errOut, err := runCompileCmd(t, mock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "# Part One\n\nIt began.\n\nIt went on.\n\nNo frontmatter here.\n"
+	if out != want {
+		t.Errorf("manuscript = %q, want %q", out, want)
+	}
+	if !strings.Contains(errOut, "skipping unreadable node file ch3.md") {
+		t.Errorf("stderr = %q, want a warning about ch3.md", errOut)
+	}
+	if !strings.Contains(errOut, binder.CodePathEscapesRoot) || !strings.Contains(errOut, "a/../../outside.md") {
+		t.Errorf("stderr = %q, want %s for the escaping target", errOut, binder.CodePathEscapesRoot)
+	}
+}
+
+func TestCompile_FromTo(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"subtree", []string{"--from", "part1", "--to", "part1"}, "# Part One\n\nIt began.\n\nIt went on.\n"},
+		{"from only", []string{"--from", "ch3"}, "The end.\n"},
+		{"to only", []string{"--to", "Chapter One"}, "# Part One\n\nIt began.\n\nIt went on.\n"},
+		{"root", []string{"--from", ".", "--to", "."}, "# Part One\n\nIt began.\n\nIt went on.\n\nNo frontmatter here.\n\nThe end.\n"},
+		{"across parts", []string{"--from", "part2", "--to", "Chapter One again"}, "No frontmatter here.\n\nIt began.\n\nIt went on.\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, _, err := runCompileCmd(t, newCompileTestIO(), tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out != tt.want {
+				t.Errorf("manuscript = %q, want %q", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_Types(t *testing.T) {
+	mock := newCompileTestIO()
+	mock.files["part1.md"] = "---\ntype: chapter\n---\n# Part One\n"
+	mock.files["ch1.md"] = "---\ntype: scene\n---\nIt began.\n"
+	mock.files["ch3.md"] = "---\ntype: note\n---\nThe end.\n"
+	mock.lockedFile = "ch2.md"
+	delete(mock.files, "part2.md")
+
+	_, _, err := runCompileCmd(t, mock, "--type", "scene", "--type", " note", "--from", "part1")
+	if !errors.Is(err, node.ErrLockedBody) {
+		t.Fatalf("err = %v, want the locked ch2.md kept and reported", err)
+	}
+
+	mock.lockedFile = ""
+	out, errOut, err := runCompileCmd(t, mock, "--type", "scene, note")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "It began.\n\nThe end.\n" {
+		t.Errorf("manuscript = %q, want only the scene and the note", out)
+	}
+	if !strings.Contains(errOut, "skipping unreadable node file part2.md") || !strings.Contains(errOut, binder.CodePathEscapesRoot) {
+		t.Errorf("stderr = %q, want unreadable and escaping nodes still reported", errOut)
+	}
+}
+
+func TestCompile_ConfigSeparator(t *testing.T) {
+	mock := newCompileTestIO()
+	mock.files[".prosemark.yml"] = "compile:\n  separator: \"\\n\\n* * *\\n\\n\"\n"
+	out, _, err := runCompileCmd(t, mock, "--from", "part2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "No frontmatter here.\n\n* * *\n\nIt began.\n\nIt went on.\n\n* * *\n\nThe end.\n"
+	if out != want {
+		t.Errorf("manuscript = %q, want %q", out, want)
+	}
+
+	mock.files[".prosemark.yml"] = "compile: [separator]\n"
+	if _, _, err := runCompileCmd(t, mock); err == nil || !strings.Contains(err.Error(), ".prosemark.yml") {
+		t.Errorf("with a malformed config: err = %v", err)
+	}
+}
+
+func TestCompile_Includes(t *testing.T) {
+	mock := newCompileTestIO()
+	mock.files["epigraph.md"] = "---\ntitle: Epigraph\n---\n> All is flux.\n"
+	mock.files["ch3.md"] = "---\nincludes: [epigraph.md]\n---\nThe end.\n<!-- pmk-include: ch3.md -->\n<!-- pmk-include: gone.md -->\n<!-- pmk-include: ../../outside.md -->\n"
+	out, errOut, err := runCompileCmd(t, mock, "--from", "ch3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "> All is flux.\n\nThe end.\n"; out != want {
+		t.Errorf("manuscript = %q, want %q", out, want)
+	}
+	for _, code := range []string{node.CodeIncludeCycle, node.CodeUnreadableInclude} {
+		if !strings.Contains(errOut, code) {
+			t.Errorf("stderr = %q, want a %s warning", errOut, code)
+		}
+	}
+}
+
+func TestCompile_Output(t *testing.T) {
+	mock := newCompileTestIO()
+	out, errOut, err := runCompileCmd(t, mock, "--output", "build/book.md", "--from", "ch3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := string(mock.written["build/book.md"]); got != "The end.\n" || out != "" {
+		t.Errorf("written = %q, stdout = %q", got, out)
+	}
+	if !strings.Contains(errOut, "Compiled 1 node(s) into build/book.md") {
+		t.Errorf("stderr = %q", errOut)
+	}
+}
+
+func TestCompile_EmptyBinder(t *testing.T) {
+	out, _, err := runCompileCmd(t, &mockCompileIO{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n")})
+	if err != nil || out != "" {
+		t.Errorf("compile = %q, %v; want empty output", out, err)
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	locked := newCompileTestIO()
+	locked.lockedFile = "ch2.md"
+	lockedInclude := newCompileTestIO()
+	lockedInclude.lockedFile = "secret.md"
+	lockedInclude.files["ch3.md"] = "<!-- pmk-include: secret.md -->\n"
+	writeFails := newCompileTestIO()
+	writeFails.writeErr = errors.New("disk full")
+
+	tests := []struct {
+		name    string
+		mock    *mockCompileIO
+		args    []string
+		wantErr string
+	}{
+		{"binder missing", &mockCompileIO{binderErr: os.ErrNotExist}, nil, "project not initialized"},
+		{"binder unreadable", &mockCompileIO{binderErr: errors.New("io")}, nil, "reading binder"},
+		{"invalid binder", &mockCompileIO{binderBytes: []byte("\xff\xfe")}, nil, "cannot parse binder"},
+		{"from no match", newCompileTestIO(), []string{"--from", "nope"}, "--from: "},
+		{"to ambiguous", newCompileTestIO(), []string{"--to", "ch1"}, `--to "ch1" is ambiguous`},
+		{"reversed", newCompileTestIO(), []string{"--from", "part2", "--to", "part1"}, "ends before"},
+		{"locked", locked, nil, "ch2.md: "},
+		{"locked include", lockedInclude, []string{"--from", "ch3"}, "secret.md: "},
+		{"write fails", writeFails, []string{"--output", "book.md"}, "writing book.md"},
+		{"selector with from", newCompileTestIO(), []string{"--selector", "part2", "--from", "part1"}, "--selector conflicts"},
+		{"pager with clipboard", newCompileTestIO(), []string{"--pager", "--clipboard"}, "--pager conflicts with --clipboard"},
+		{"pager with output", newCompileTestIO(), []string{"--pager", "-o", "book.md"}, "--output conflicts"},
+		{"pager fails", &mockCompileIO{binderBytes: []byte(compileTestBinder), pageErr: errors.New("exit status 1")}, []string{"--pager"}, "running pager"},
+		{"clipboard fails", &mockCompileIO{binderBytes: []byte(compileTestBinder), copyErr: errors.New("no xclip")}, []string{"--clipboard"}, "copying to clipboard"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runCompileCmd(t, tt.mock, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompile_GetwdError(t *testing.T) {
+	c := newCompileCmdWithGetCWD(newCompileTestIO(), func() (string, error) { return "", errors.New("no cwd") })
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(nil)
+	if err := c.Execute(); err == nil {
+		t.Fatal("expected error when getwd fails")
+	}
+}
+
+func TestCompile_StdoutError(t *testing.T) {
+	c := NewCompileCmd(newCompileTestIO())
+	c.SetOut(&errWriter{err: errors.New("closed")})
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--project", "/proj"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "writing output") {
+		t.Fatalf("err = %v, want output error", err)
+	}
+}
+
+func TestFileCompileIO_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	if err := os.WriteFile(binderPath, []byte(compileTestBinder), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fio := fileCompileIO{}
+	if got, err := fio.ReadBinder(context.Background(), binderPath); err != nil || string(got) != compileTestBinder {
+		t.Errorf("ReadBinder = %q, %v", got, err)
+	}
+	outPath := filepath.Join(dir, "build", "book.md")
+	if err := fio.WriteOutputFile(outPath, []byte("manuscript\n")); err != nil {
+		t.Fatalf("WriteOutputFile: %v", err)
+	}
+	if got, err := fio.ReadNodeFile(outPath); err != nil || string(got) != "manuscript\n" {
+		t.Errorf("ReadNodeFile = %q, %v", got, err)
+	}
+}
+
+func TestFileCompileIO_PagerAndClipboardFailures(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("WAYLAND_DISPLAY", "")
+	fio := fileCompileIO{}
+	if err := fio.Page(" ", []byte("manuscript\n")); err == nil || !strings.Contains(err.Error(), "PAGER is empty") {
+		t.Errorf("Page with a blank pager: err = %v", err)
+	}
+	if err := fio.CopyToClipboard([]byte("manuscript\n")); err == nil || !strings.Contains(err.Error(), "no clipboard command found") {
+		t.Errorf("CopyToClipboard without a clipboard command: err = %v", err)
+	}
+}
+
+func TestCompile_FromToBookmarks(t *testing.T) {
+	mock := newCompileTestIO()
+	mock.files[".prosemark.yml"] = "bookmarks:\n  p1: part1\n"
+	out, _, err := runCompileCmd(t, mock, "--from", "@p1", "--to", "@p1")
+	if err != nil || out != "# Part One\n\nIt began.\n\nIt went on.\n" {
+		t.Errorf("manuscript = %q, %v; want part1's subtree", out, err)
+	}
+	if _, _, err := runCompileCmd(t, mock, "--to", "@p2"); err == nil || !strings.Contains(err.Error(), "OPE001") {
+		t.Errorf("undefined bookmark: error = %v, want OPE001", err)
+	}
+}
+
+func TestCompile_SelectorPager(t *testing.T) {
+	t.Setenv("PAGER", "most -s")
+	mock := newCompileTestIO()
+	out, _, err := runCompileCmd(t, mock, "--selector", "ch3", "--pager")
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if out != "" {
+		t.Errorf("stdout = %q, want nothing with --pager", out)
+	}
+	if mock.pager != "most -s" || string(mock.paged) != "The end.\n" {
+		t.Errorf("paged %q with %q, want ch3 with $PAGER", mock.paged, mock.pager)
+	}
+
+	t.Setenv("PAGER", "")
+	if _, _, err := runCompileCmd(t, mock, "--selector", "part1", "--pager"); err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if want := "# Part One\n\nIt began.\n\nIt went on.\n"; mock.pager != "less" || string(mock.paged) != want {
+		t.Errorf("paged %q with %q, want part1's subtree with less", mock.paged, mock.pager)
+	}
+}
+
+func TestCompile_Clipboard(t *testing.T) {
+	mock := newCompileTestIO()
+	out, errOut, err := runCompileCmd(t, mock, "--selector", "part2", "--clipboard")
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if out != "" {
+		t.Errorf("stdout = %q, want nothing with --clipboard", out)
+	}
+	if want := "No frontmatter here.\n\nIt began.\n\nIt went on.\n\nThe end.\n"; string(mock.clipboard) != want {
+		t.Errorf("clipboard = %q, want %q", mock.clipboard, want)
+	}
+	if !strings.Contains(errOut, "Copied 3 node(s) to the clipboard") {
+		t.Errorf("stderr = %q, want a confirmation", errOut)
+	}
+}
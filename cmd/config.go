@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// errEditorNotSet is returned by commands that open an editor when neither
+// $EDITOR nor the project config names one.
+var errEditorNotSet = errors.New("$EDITOR is not set (or set editor in " + node.ConfigFilename + ")")
+
+// setConfigOverride points every project config read at a file, for
+// --config. Override in tests to avoid changing process-wide state.
+var setConfigOverride = node.SetConfigOverride
+
+// applyConfigFlag validates --config and makes the file it names the project
+// config for this run. A relative path is taken from the working directory,
+// after -C has applied.
+func applyConfigFlag(cmd *cobra.Command) error {
+	if !cmd.Flags().Changed("config") {
+		return nil
+	}
+	path, _ := cmd.Flags().GetString("config")
+	if path == "" {
+		return fmt.Errorf("--config flag cannot be empty")
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("--config: %w", err)
+	}
+	if _, err := os.Stat(abs); err != nil {
+		return fmt.Errorf("--config: %w", err)
+	}
+	setConfigOverride(abs)
+	return nil
+}
+
+// readProjectSettings reads and parses the config of the project in
+// projectDir through readFile. A project without a config has the zero
+// settings.
+func readProjectSettings(readFile func(string) ([]byte, error), projectDir string) (node.ProjectConfig, error) {
+	path := node.ConfigPath(projectDir)
+	content, err := readFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return node.ProjectConfig{}, fmt.Errorf("reading %s: %w", filepath.Base(path), err)
+	}
+	settings, err := node.ParseProjectConfig(content)
+	if err != nil {
+		return node.ProjectConfig{}, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return settings, nil
+}
+
+// readNodeTypes reads the node types declared in the config of the project
+// in projectDir through readFile. A project without a config has only the
+// built-in types.
+func readNodeTypes(readFile func(string) ([]byte, error), projectDir string) (node.FrontmatterSchema, error) {
+	path := node.ConfigPath(projectDir)
+	content, err := readFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading %s: %w", filepath.Base(path), err)
+	}
+	types, err := node.ParseFrontmatterSchema(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return types, nil
+}
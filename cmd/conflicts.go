@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/diff"
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// ConflictsIO handles I/O for the conflicts command.
+type ConflictsIO interface {
+	ScanProject(ctx context.Context, binderPath string) (*binder.Project, error)
+	ReadFile(path string) ([]byte, error)
+	WriteFileAtomic(path string, content []byte) error
+	DeleteFile(path string) error
+}
+
+// conflictJSON is the JSON output type for a cloud-sync conflict copy.
+type conflictJSON struct {
+	Copy      string `json:"copy"`
+	Canonical string `json:"canonical"`
+}
+
+// conflictsOutput is the JSON output schema for conflicts list.
+type conflictsOutput struct {
+	Version   string         `json:"version"`
+	Conflicts []conflictJSON `json:"conflicts"`
+}
+
+// NewConflictsCmd creates the conflicts command with list and resolve
+// subcommands.
+func NewConflictsCmd(io ConflictsIO) *cobra.Command {
+	return newConflictsCmdWithGetCWD(io, os.Getwd)
+}
+
+func newConflictsCmdWithGetCWD(io ConflictsIO, getwd func() (string, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "conflicts",
+		Short: "List and resolve cloud-sync conflict copies",
+		Long: "List and resolve cloud-sync conflict copies.\n\n" +
+			"When a file changes in two places at once, sync clients keep both:\n" +
+			"Dropbox as \"chapter (conflicted copy 2026-03-01).md\", Syncthing as\n" +
+			"\"chapter.sync-conflict-20260301-120000-ABCDEF7.md\". pmk doctor reports\n" +
+			"each copy as AUD012; resolve diffs it against the file it copies and keeps\n" +
+			"one version.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.PersistentFlags().String("project", "", "project directory containing the binder (default: current directory)")
+
+	cmd.AddCommand(newConflictsListCmd(io, getwd))
+	cmd.AddCommand(newConflictsResolveCmd(io, getwd))
+	return cmd
+}
+
+func newConflictsListCmd(io ConflictsIO, getwd func() (string, error)) *cobra.Command {
+	var jsonMode bool
+	cmd := &cobra.Command{
+		Use:          "list",
+		Short:        "List the project's cloud-sync conflict copies",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			proj, err := io.ScanProject(cmd.Context(), binderPath)
+			if err != nil {
+				return fmt.Errorf("scanning project: %w", err)
+			}
+			out := conflictsOutput{Version: "1", Conflicts: []conflictJSON{}}
+			for _, f := range proj.Files {
+				if canonical, ok := node.ConflictCopy(f); ok {
+					out.Conflicts = append(out.Conflicts, conflictJSON{Copy: f, Canonical: canonical})
+				}
+			}
+			if jsonMode {
+				if err := encodeOutput(cmd, out); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+				return nil
+			}
+			for _, c := range out.Conflicts {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", sanitizePath(c.Copy), sanitizePath(c.Canonical))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "output JSON")
+	addFormatFlag(cmd)
+	return cmd
+}
+
+func newConflictsResolveCmd(io ConflictsIO, getwd func() (string, error)) *cobra.Command {
+	var use string
+	cmd := &cobra.Command{
+		Use:   "resolve <copy>",
+		Short: "Diff a conflict copy against its file, or keep one of them",
+		Long: "Diff a conflict copy against its file, or keep one of them.\n\n" +
+			"Without --use, prints the unified diff from the file to the copy. To\n" +
+			"merge, edit the file to taste and then discard the copy with\n" +
+			"--use canonical; --use copy replaces the file with the copy instead.\n" +
+			"Either way the copy is deleted.",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if use != "" && use != "copy" && use != "canonical" {
+				return fmt.Errorf("--use must be \"copy\" or \"canonical\", got %q", use)
+			}
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			rel := filepath.ToSlash(filepath.Clean(args[0]))
+			canonical, ok := node.ConflictCopy(rel)
+			if !ok {
+				return fmt.Errorf("%s is not a cloud-sync conflict copy", args[0])
+			}
+			projectDir := filepath.Dir(binderPath)
+			copyPath, err := safepath.Resolve(projectDir, rel)
+			if err != nil {
+				return err
+			}
+			canonicalPath, err := safepath.Resolve(projectDir, canonical)
+			if err != nil {
+				return err
+			}
+			copyContent, err := io.ReadFile(copyPath)
+			if err != nil {
+				return fmt.Errorf("reading conflict copy: %w", err)
+			}
+			canonicalContent, err := io.ReadFile(canonicalPath)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("reading %s: %w", canonical, err)
+			}
+
+			w := cmd.OutOrStdout()
+			switch use {
+			case "":
+				d := diff.Unified("a/"+sanitizePath(canonical), "b/"+sanitizePath(rel), canonicalContent, copyContent)
+				if d == "" {
+					fmt.Fprintf(w, "%s is identical to %s\n", sanitizePath(rel), sanitizePath(canonical))
+				}
+				fmt.Fprint(w, d)
+				return nil
+			case "copy":
+				if err := io.WriteFileAtomic(canonicalPath, copyContent); err != nil {
+					return fmt.Errorf("writing %s: %w", canonical, err)
+				}
+				if err := io.DeleteFile(copyPath); err != nil {
+					return fmt.Errorf("deleting conflict copy: %w", err)
+				}
+				fmt.Fprintf(w, "Replaced %s with %s\n", sanitizePath(canonical), sanitizePath(rel))
+			default:
+				if err := io.DeleteFile(copyPath); err != nil {
+					return fmt.Errorf("deleting conflict copy: %w", err)
+				}
+				fmt.Fprintf(w, "Discarded %s\n", sanitizePath(rel))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&use, "use", "", "keep the copy or the canonical file, deleting the copy: copy or canonical")
+	return cmd
+}
+
+// fileConflictsIO implements ConflictsIO using OS file I/O.
+type fileConflictsIO struct {
+	fileProjectIO
+}
+
+// WriteFileAtomic replaces the file at path with content.
+func (fileConflictsIO) WriteFileAtomic(path string, content []byte) error {
+	return fsio.WriteFileAtomic(path, ".node", content)
+}
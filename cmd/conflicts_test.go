@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// mockConflictsIO is a test double for ConflictsIO over files under /proj.
+type mockConflictsIO struct {
+	files     map[string]string // by project-relative path
+	readErrs  map[string]error  // by project-relative path
+	scanErr   error
+	writeErr  error
+	deleteErr error
+}
+
+func (m *mockConflictsIO) rel(path string) string {
+	rel, _ := filepath.Rel("/proj", path)
+	return filepath.ToSlash(rel)
+}
+
+func (m *mockConflictsIO) ScanProject(_ context.Context, _ string) (*binder.Project, error) {
+	if m.scanErr != nil {
+		return nil, m.scanErr
+	}
+	proj := &binder.Project{BinderDir: "."}
+	for _, f := range []string{"ch1.md", "ch1 (conflicted copy 2026-03-01).md", "part/ch2.sync-conflict-20260301-120000-ABCDEF7.md"} {
+		if _, ok := m.files[f]; ok {
+			proj.Files = append(proj.Files, f)
+		}
+	}
+	return proj, nil
+}
+
+func (m *mockConflictsIO) ReadFile(path string) ([]byte, error) {
+	if err := m.readErrs[m.rel(path)]; err != nil {
+		return nil, err
+	}
+	if content, ok := m.files[m.rel(path)]; ok {
+		return []byte(content), nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func (m *mockConflictsIO) WriteFileAtomic(path string, content []byte) error {
+	if m.writeErr != nil {
+		return m.writeErr
+	}
+	m.files[m.rel(path)] = string(content)
+	return nil
+}
+
+func (m *mockConflictsIO) DeleteFile(path string) error {
+	if m.deleteErr != nil {
+		return m.deleteErr
+	}
+	delete(m.files, m.rel(path))
+	return nil
+}
+
+func newConflictsTestIO() *mockConflictsIO {
+	return &mockConflictsIO{files: map[string]string{
+		"ch1.md":                              "It began.\nIt rained.\n",
+		"ch1 (conflicted copy 2026-03-01).md": "It began.\nIt snowed.\n",
+		"part/ch2.sync-conflict-20260301-120000-ABCDEF7.md": "Orphaned copy.\n",
+	}}
+}
+
+func runConflictsCmd(t *testing.T, mock *mockConflictsIO, args ...string) (string, error) {
+	t.Helper()
+	c := NewConflictsCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(append(args, "--project", "/proj"))
+	err := c.Execute()
+	return out.String(), err
+}
+
+func TestConflictsList(t *testing.T) {
+	out, err := runConflictsCmd(t, newConflictsTestIO(), "list")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "ch1 (conflicted copy 2026-03-01).md\tch1.md\npart/ch2.sync-conflict-20260301-120000-ABCDEF7.md\tpart/ch2.md\n"
+	if out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+
+	out, err = runConflictsCmd(t, &mockConflictsIO{files: map[string]string{"ch1.md": ""}}, "list", "--json")
+	if err != nil || out != `{"version":"1","conflicts":[]}`+"\n" {
+		t.Errorf("json output = %q, %v", out, err)
+	}
+
+	out, err = runConflictsCmd(t, newConflictsTestIO(), "list", "--format", "yaml")
+	want = "version: \"1\"\nconflicts:\n" +
+		"  - copy: ch1 (conflicted copy 2026-03-01).md\n    canonical: ch1.md\n" +
+		"  - copy: part/ch2.sync-conflict-20260301-120000-ABCDEF7.md\n    canonical: part/ch2.md\n"
+	if err != nil || out != want {
+		t.Errorf("yaml output = %q, %v; want %q", out, err, want)
+	}
+}
+
+func TestConflictsResolve(t *testing.T) {
+	const copyName = "ch1 (conflicted copy 2026-03-01).md"
+
+	mock := newConflictsTestIO()
+	out, err := runConflictsCmd(t, mock, "resolve", copyName)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	if !strings.Contains(out, "--- a/ch1.md\n+++ b/"+copyName+"\n") || !strings.Contains(out, "-It rained.\n+It snowed.\n") {
+		t.Errorf("diff = %q", out)
+	}
+	if len(mock.files) != 3 {
+		t.Errorf("diff changed files: %v", mock.files)
+	}
+
+	mock = newConflictsTestIO()
+	if _, err := runConflictsCmd(t, mock, "resolve", copyName, "--use", "copy"); err != nil {
+		t.Fatalf("--use copy: %v", err)
+	}
+	if _, ok := mock.files[copyName]; ok || mock.files["ch1.md"] != "It began.\nIt snowed.\n" {
+		t.Errorf("--use copy left %v", mock.files)
+	}
+
+	mock = newConflictsTestIO()
+	out, err = runConflictsCmd(t, mock, "resolve", copyName, "--use", "canonical")
+	if err != nil {
+		t.Fatalf("--use canonical: %v", err)
+	}
+	if _, ok := mock.files[copyName]; ok || mock.files["ch1.md"] != "It began.\nIt rained.\n" || out != "Discarded "+copyName+"\n" {
+		t.Errorf("--use canonical left %v, printed %q", mock.files, out)
+	}
+
+	mock = newConflictsTestIO()
+	mock.files[copyName] = mock.files["ch1.md"]
+	if out, _ := runConflictsCmd(t, mock, "resolve", copyName); out != copyName+" is identical to ch1.md\n" {
+		t.Errorf("identical copy: output = %q", out)
+	}
+}
+
+func TestConflictsResolve_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		mock    func(*mockConflictsIO)
+		wantErr string
+	}{
+		{name: "bad --use", args: []string{"ch1.md", "--use", "both"}, wantErr: `--use must be "copy" or "canonical"`},
+		{name: "not a copy", args: []string{"ch1.md"}, wantErr: "not a cloud-sync conflict copy"},
+		{name: "missing copy", args: []string{"ch9 (conflicted copy 2026-03-01).md"}, wantErr: "reading conflict copy"},
+		{name: "escapes project", args: []string{"../ch1 (conflicted copy 2026-03-01).md"}, wantErr: "escapes the project directory"},
+		{name: "delete fails", args: []string{"ch1 (conflicted copy 2026-03-01).md", "--use", "canonical"},
+			mock: func(m *mockConflictsIO) { m.deleteErr = errors.New("denied") }, wantErr: "deleting conflict copy: denied"},
+		{name: "delete fails after copy", args: []string{"ch1 (conflicted copy 2026-03-01).md", "--use", "copy"},
+			mock: func(m *mockConflictsIO) { m.deleteErr = errors.New("denied") }, wantErr: "deleting conflict copy: denied"},
+		{name: "unreadable canonical", args: []string{"ch1 (conflicted copy 2026-03-01).md"},
+			mock: func(m *mockConflictsIO) { m.readErrs = map[string]error{"ch1.md": errors.New("denied")} }, wantErr: "reading ch1.md: denied"},
+		{name: "write fails", args: []string{"ch1 (conflicted copy 2026-03-01).md", "--use", "copy"},
+			mock: func(m *mockConflictsIO) { m.writeErr = errors.New("disk full") }, wantErr: "writing ch1.md: disk full"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newConflictsTestIO()
+			if tt.mock != nil {
+				tt.mock(mock)
+			}
+			_, err := runConflictsCmd(t, mock, append([]string{"resolve"}, tt.args...)...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConflictsList_Errors(t *testing.T) {
+	mock := newConflictsTestIO()
+	mock.scanErr = errors.New("denied")
+	if _, err := runConflictsCmd(t, mock, "list"); err == nil || !strings.Contains(err.Error(), "scanning project: denied") {
+		t.Errorf("scan failure: error = %v", err)
+	}
+
+	c := NewConflictsCmd(newConflictsTestIO())
+	c.SetOut(&errWriter{err: errors.New("closed")})
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"list", "--json", "--project", "/proj"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "encoding output") {
+		t.Errorf("output failure: error = %v", err)
+	}
+}
+
+func TestConflictsCmd_GetwdError(t *testing.T) {
+	for _, args := range [][]string{{"list"}, {"resolve", "ch1 (conflicted copy 2026-03-01).md"}} {
+		t.Run(args[0], func(t *testing.T) {
+			c := newConflictsCmdWithGetCWD(newConflictsTestIO(), func() (string, error) {
+				return "", errors.New("getwd failed")
+			})
+			c.SetOut(new(bytes.Buffer))
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(args)
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+				t.Errorf("error = %v, want getwd failure", err)
+			}
+		})
+	}
+}
+
+func TestConflictsCmd_NoSubcommandPrintsHelp(t *testing.T) {
+	out, err := runCmdInCWD(NewConflictsCmd(newConflictsTestIO()))
+	if err != nil || !strings.Contains(out, "resolve") {
+		t.Errorf("output = %q, %v; want help listing subcommands", out, err)
+	}
+}
+
+func TestFileConflictsIO_Resolve(t *testing.T) {
+	dir := t.TempDir()
+	const copyName = "ch1 (conflicted copy 2026-03-01).md"
+	for name, content := range map[string]string{"_binder.md": "<!-- prosemark-binder:v1 -->\n", "ch1.md": "It rained.\n", copyName: "It snowed.\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := runCmdInCWD(NewConflictsCmd(fileConflictsIO{}), "resolve", copyName, "--use", "copy", "--project", dir); err != nil {
+		t.Fatalf("--use copy: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "ch1.md"))
+	if err != nil || string(got) != "It snowed.\n" {
+		t.Errorf("ch1.md = %q, %v; want the copy's content", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, copyName)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("conflict copy should be deleted, stat error = %v", err)
+	}
+}
+
+func TestFileConflictsIO_CanonicalSymlinkEscapes(t *testing.T) {
+	dir := t.TempDir()
+	const copyName = "ch1 (conflicted copy 2026-03-01).md"
+	if err := os.WriteFile(filepath.Join(dir, copyName), []byte("copy\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(t.TempDir(), "elsewhere.md"), filepath.Join(dir, "ch1.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := runCmdInCWD(NewConflictsCmd(fileConflictsIO{}), "resolve", copyName, "--use", "copy", "--project", dir)
+	if err == nil || !strings.Contains(err.Error(), "escapes") {
+		t.Errorf("error = %v, want the canonical file's symlink to be refused", err)
+	}
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/stats"
+)
+
+// countChaptersOutput is the JSON output schema for count-chapters.
+type countChaptersOutput struct {
+	Version string `json:"version"`
+	stats.Structure
+}
+
+// NewCountChaptersCmd creates the count-chapters subcommand. It reads node
+// files the same way wc does.
+func NewCountChaptersCmd(io WCIO) *cobra.Command {
+	return newCountChaptersCmdWithGetCWD(io, os.Getwd)
+}
+
+func newCountChaptersCmdWithGetCWD(io WCIO, getwd func() (string, error)) *cobra.Command {
+	var (
+		jsonMode     bool
+		chapterDepth int
+		rev          string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "count-chapters",
+		Short: "Report structural metrics of the binder",
+		Long: "Report how many parts, chapters, and scenes the binder has, how many\n" +
+			"entries sit at each depth, the average scene length, and the longest and\n" +
+			"shortest chapters. Entries above --chapter-depth are parts and entries\n" +
+			"below it are scenes; scenes without a node file are counted as\n" +
+			"placeholders, apart from the scenes and their average. By default\n" +
+			"chapters are at depth 2 when the binder is three or more levels deep,\n" +
+			"and at depth 1 otherwise. --rev reports on the project as committed at\n" +
+			"a git revision, read without checking it out.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if cmd.Flags().Changed("chapter-depth") && chapterDepth < 1 {
+				return fmt.Errorf("--chapter-depth must be at least 1")
+			}
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			if rev != "" {
+				io = io.AtRevision(rev)
+			}
+			parsed, err := core.Parse(cmd.Context(), io, binderPath, core.ParseOptions{})
+			if err != nil {
+				return binderReadError(err, rev)
+			}
+			if parsed.Err != nil {
+				return fmt.Errorf("cannot parse binder: %w", parsed.Err)
+			}
+
+			projectDir := filepath.Dir(binderPath)
+			nodes, _, err := stats.Tally(parsed.Result.Root, func(target string) (stats.Counts, error) {
+				return countNodeFile(cmd, io, projectDir, target)
+			})
+			if err != nil {
+				return err
+			}
+			if chapterDepth == 0 {
+				chapterDepth = stats.DefaultChapterDepth(stats.MaxDepth(nodes))
+			}
+			s := stats.Summarize(nodes, chapterDepth)
+
+			if jsonMode {
+				if err := encodeOutput(cmd, countChaptersOutput{Version: "1", Structure: s}); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+				return nil
+			}
+			if _, err := fmt.Fprint(cmd.OutOrStdout(), formatStructure(s)); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output metrics as JSON")
+	addFormatFlag(cmd)
+	cmd.Flags().IntVar(&chapterDepth, "chapter-depth", 0, "binder depth of chapters (default: guessed from the binder's depth)")
+	addRevFlag(cmd, &rev)
+	return cmd
+}
+
+// formatStructure renders s as the human-readable count-chapters report.
+func formatStructure(s stats.Structure) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Parts: %d  Chapters: %d  Scenes: %d", s.Parts, s.Chapters, s.Scenes)
+	if s.Placeholders > 0 {
+		fmt.Fprintf(&b, "  Placeholders: %d", s.Placeholders)
+	}
+	fmt.Fprintf(&b, "  (chapters at depth %d)\n", s.ChapterDepth)
+	b.WriteString("Entries by depth:\n")
+	for i, n := range s.Depths {
+		fmt.Fprintf(&b, "  %d: %d\n", i+1, n)
+	}
+	fmt.Fprintf(&b, "Average scene length: %d words\n", s.AverageSceneWords)
+	if s.Longest != nil {
+		fmt.Fprintf(&b, "Longest chapter: %s (%d words)\n", sanitizePath(s.Longest.Title), s.Longest.Words)
+		fmt.Fprintf(&b, "Shortest chapter: %s (%d words)\n", sanitizePath(s.Shortest.Title), s.Shortest.Words)
+	}
+	return b.String()
+}
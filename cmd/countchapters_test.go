@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func runCountChaptersCmd(t *testing.T, mock *mockWCIO, args ...string) (string, error) {
+	t.Helper()
+	c := NewCountChaptersCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(append(args, "--project", "."))
+	err := c.Execute()
+	return out.String(), err
+}
+
+func TestCountChapters_Report(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"default depth", nil, "Parts: 0  Chapters: 3  Scenes: 1  Placeholders: 1  (chapters at depth 1)\n" +
+			"Entries by depth:\n" +
+			"  1: 3\n" +
+			"  2: 2\n" +
+			"Average scene length: 5 words\n" +
+			"Longest chapter: Part One (7 words)\n" +
+			"Shortest chapter: Part Two (0 words)\n"},
+		{"chapters at depth 2", []string{"--chapter-depth", "2"}, "Parts: 3  Chapters: 2  Scenes: 0  (chapters at depth 2)\n" +
+			"Entries by depth:\n" +
+			"  1: 3\n" +
+			"  2: 2\n" +
+			"Average scene length: 0 words\n" +
+			"Longest chapter: Chapter One (5 words)\n" +
+			"Shortest chapter: Draft (0 words)\n"},
+		{"no chapters", []string{"--chapter-depth", "3"}, "Parts: 5  Chapters: 0  Scenes: 0  (chapters at depth 3)\n" +
+			"Entries by depth:\n" +
+			"  1: 3\n" +
+			"  2: 2\n" +
+			"Average scene length: 0 words\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := runCountChaptersCmd(t, newWCTestIO(), tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out != tt.want {
+				t.Errorf("count-chapters =\n%s\nwant\n%s", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountChapters_JSON(t *testing.T) {
+	out, err := runCountChaptersCmd(t, newWCTestIO(), "--json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got countChaptersOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if got.Version != "1" || got.Chapters != 3 || got.Scenes != 1 || got.Placeholders != 1 || got.Longest == nil || got.Longest.Target != "part1.md" {
+		t.Errorf("output = %s", out)
+	}
+}
+
+func TestCountChapters_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    *mockWCIO
+		args    []string
+		wantErr string
+	}{
+		{"zero chapter depth", newWCTestIO(), []string{"--chapter-depth", "0"}, "--chapter-depth must be at least 1"},
+		{"binder missing", &mockWCIO{mockTreeIO: mockTreeIO{binderErr: os.ErrNotExist}}, nil, "project not initialized"},
+		{"binder unreadable", &mockWCIO{mockTreeIO: mockTreeIO{binderErr: errors.New("io")}}, nil, "reading binder"},
+		{"invalid binder", &mockWCIO{mockTreeIO: mockTreeIO{binderBytes: []byte("\xff\xfe")}}, nil, "cannot parse binder"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runCountChaptersCmd(t, tt.mock, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCountChapters_OutputErrors(t *testing.T) {
+	for _, args := range [][]string{nil, {"--json"}} {
+		c := NewCountChaptersCmd(newWCTestIO())
+		c.SetOut(&errWriter{err: errors.New("closed")})
+		c.SetErr(new(bytes.Buffer))
+		c.SetArgs(append(args, "--project", "."))
+		if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "output") {
+			t.Errorf("args %v: error = %v, want an output error", args, err)
+		}
+	}
+}
+
+func TestCountChapters_GetwdError(t *testing.T) {
+	c := newCountChaptersCmdWithGetCWD(newWCTestIO(), func() (string, error) { return "", errors.New("getwd failed") })
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(nil)
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+		t.Errorf("error = %v, want getwd failure", err)
+	}
+}
+
+func TestCountChapters_Revision(t *testing.T) {
+	mock := newWCTestIO()
+	mock.revs = map[string]*mockWCIO{"v1": {
+		mockTreeIO: mockTreeIO{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [Part One](part1.md)\n")},
+		files:      map[string]string{"part1.md": "Three short words.\n"},
+	}}
+	out, err := runCountChaptersCmd(t, mock, "--rev", "v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out, "Parts: 0  Chapters: 1  Scenes: 0") {
+		t.Errorf("count-chapters =\n%s\nwant the committed binder's one chapter", out)
+	}
+}
@@ -1,23 +1,20 @@
 package cmd
 
 import (
-	"bytes"
-	"context"
-	"encoding/json"
 	"fmt"
 	"os"
-
-	"github.com/spf13/cobra"
+	"strings"
 
 	"github.com/eykd/prosemark-go/internal/binder"
-	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/spf13/cobra"
 )
 
-// DeleteIO handles I/O for the delete command.
+// DeleteIO handles I/O for the delete command. The node file methods are
+// used only by --cascade.
 type DeleteIO interface {
-	ReadBinder(ctx context.Context, path string) ([]byte, error)
-	ScanProject(ctx context.Context, binderPath string) (*binder.Project, error)
-	WriteBinderAtomic(ctx context.Context, path string, data []byte) error
+	core.DeleteFilesIO
 }
 
 // NewDeleteCmd creates the delete subcommand.
@@ -27,14 +24,24 @@ func NewDeleteCmd(io DeleteIO) *cobra.Command {
 
 func newDeleteCmdWithGetCWD(io DeleteIO, getwd func() (string, error)) *cobra.Command {
 	var (
-		selector string
-		yes      bool
-		jsonMode bool
+		selector   string
+		yes        bool
+		jsonMode   bool
+		forceParse bool
+		dryRun     bool
+		cascade    string
+		archive    bool
 	)
 
 	cmd := &cobra.Command{
-		Use:          "delete",
-		Short:        "Delete a node from a binder",
+		Use:   "delete",
+		Short: "Delete a node from a binder",
+		Long: "Delete the selected entries, with their subtrees, from the binder. The\n" +
+			"node files are left on disk unless --cascade is given: --cascade files\n" +
+			"removes each file no longer referenced by the binder along with its notes\n" +
+			"file, and --cascade trash (or --archive) moves them into the project's\n" +
+			"trash, recording the deletion so pmk restore can bring it back. If any\n" +
+			"step fails, the binder and files are restored.",
 		Args:         cobra.NoArgs,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -43,90 +50,106 @@ func newDeleteCmdWithGetCWD(io DeleteIO, getwd func() (string, error)) *cobra.Co
 				return err
 			}
 
-			ctx := cmd.Context()
-
-			binderBytes, err := io.ReadBinder(ctx, binderPath)
-			if err != nil {
-				return fmt.Errorf("reading binder: %w", err)
+			if replayed, err := replayRecordedOp(cmd, io, binderPath, jsonMode); replayed || err != nil {
+				return err
 			}
 
-			proj, err := io.ScanProject(ctx, binderPath)
-			if err != nil {
-				return emitOPE009AndError(cmd, jsonMode, err)
+			if cascade != "" && cascade != core.CascadeFiles && cascade != core.CascadeTrash {
+				return fmt.Errorf("unknown --cascade %q: want %s or %s", cascade, core.CascadeFiles, core.CascadeTrash)
+			}
+			if archive {
+				if cascade == core.CascadeFiles {
+					return fmt.Errorf("--archive conflicts with --cascade %s", core.CascadeFiles)
+				}
+				cascade = core.CascadeTrash
 			}
 
 			params := binder.DeleteParams{
-				Selector: selector,
-				Yes:      yes,
+				Selector:   selector,
+				Yes:        yes || dryRun,
+				ForceParse: forceParse,
+				DryRun:     dryRun,
 			}
-
-			modifiedBytes, diags := ops.Delete(ctx, binderBytes, proj, params)
-			if diags == nil {
-				diags = []binder.Diagnostic{}
+			withJournalOps(cmd, binder.NewOpSpec(binder.OpDelete, params))
+			var res *binder.OpResult
+			var files []string
+			var trashID string
+			if cascade == "" {
+				res, err = core.Delete(cmd.Context(), io, binderPath, params)
+			} else {
+				var dres *core.DeleteResult
+				dres, err = core.DeleteWithFiles(cmd.Context(), io, binderPath, params, cascade, nowUTCFunc())
+				if dres != nil {
+					res, files, trashID = &dres.OpResult, dres.Files, dres.TrashID
+				}
+			}
+			if err := finishBinderOp(cmd, io, binderPath, jsonMode, res, err); err != nil {
+				return err
 			}
-
-			changed := !bytes.Equal(binderBytes, modifiedBytes)
-
 			if jsonMode {
-				out := binder.OpResult{Version: "1", Changed: changed, Diagnostics: diags}
-				if err := json.NewEncoder(cmd.OutOrStdout()).Encode(out); err != nil {
-					return fmt.Errorf("encoding output: %w", err)
-				}
-			} else {
-				printDiagnostics(cmd, diags)
+				return nil
 			}
 
-			if hasDiagnosticError(diags) {
-				return fmt.Errorf("delete has errors")
+			var b strings.Builder
+			if !dryRun {
+				b.WriteString("Deleted " + sanitizePath(selector) + " from " + sanitizePath(binderPath) + "\n")
 			}
-
-			if changed {
-				if err = io.WriteBinderAtomic(ctx, binderPath, modifiedBytes); err != nil {
-					return fmt.Errorf("writing binder: %w", err)
-				}
+			for _, f := range files {
+				b.WriteString(cascadeLine(cascade, dryRun, f) + "\n")
 			}
-
-			if !jsonMode {
-				if _, err := fmt.Fprintln(cmd.OutOrStdout(), "Deleted "+sanitizePath(selector)+" from "+sanitizePath(binderPath)); err != nil {
-					return fmt.Errorf("writing output: %w", err)
-				}
+			if trashID != "" && !dryRun {
+				b.WriteString("Undo with: pmk restore " + trashID + "\n")
+			}
+			if _, err := fmt.Fprint(cmd.OutOrStdout(), b.String()); err != nil {
+				return fmt.Errorf("writing output: %w", err)
 			}
-
 			return nil
 		},
 	}
 
-	cmd.Flags().String("project", "", "project directory containing _binder.md (default: current directory)")
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
 	cmd.Flags().StringVar(&selector, "selector", "", "Selector for node to delete")
 	cmd.Flags().BoolVar(&yes, "yes", false, "Required confirmation flag")
 	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+	addForceParseFlag(cmd, &forceParse)
+	addDryRunFlag(cmd, &dryRun)
+	addDiffFlag(cmd)
+	addOpIDFlag(cmd)
+	cmd.Flags().StringVar(&cascade, "cascade", "", "also remove the deleted nodes' files: files (delete them) or trash (move them to the trash)")
+	cmd.Flags().BoolVar(&archive, "archive", false, "move the deleted nodes' files to the trash, restorable with pmk restore (same as --cascade trash)")
 
 	return cmd
 }
 
-// fileDeleteIO implements DeleteIO using OS file I/O.
-type fileDeleteIO struct{ binderLocker }
-
-func newDefaultDeleteIO() *fileDeleteIO {
-	return &fileDeleteIO{}
-}
-
-// ReadBinder reads the binder file at path.
-func (w *fileDeleteIO) ReadBinder(_ context.Context, path string) ([]byte, error) {
-	return readBinderSizeLimitedImpl(path)
+// cascadeLine reports what --cascade mode did, or with dryRun would do, to
+// the project file rel.
+func cascadeLine(mode string, dryRun bool, rel string) string {
+	if mode == core.CascadeTrash {
+		if dryRun {
+			return "Would move " + sanitizePath(rel) + " to the trash"
+		}
+		return "Moved " + sanitizePath(rel) + " to the trash"
+	}
+	if dryRun {
+		return "Would remove " + sanitizePath(rel)
+	}
+	return "Removed " + sanitizePath(rel)
 }
 
-// ScanProject scans the project directory for .md files.
-func (w *fileDeleteIO) ScanProject(ctx context.Context, binderPath string) (*binder.Project, error) {
-	return ScanProjectImpl(ctx, binderPath)
+// fileDeleteIO implements DeleteIO using OS file I/O.
+type fileDeleteIO struct {
+	fileProjectIO
+	binderLocker
+	opJournaler
+	fileTxer
 }
 
-// WriteBinderAtomic writes data to path atomically via a temp file.
-func (w *fileDeleteIO) WriteBinderAtomic(ctx context.Context, path string, data []byte) error {
-	return w.WriteBinderAtomicImpl(ctx, path, data)
+func newDefaultDeleteIO() *fileDeleteIO {
+	return &fileDeleteIO{}
 }
 
-// WriteBinderAtomicImpl performs the atomic write via OS temp file rename.
-func (w *fileDeleteIO) WriteBinderAtomicImpl(_ context.Context, path string, data []byte) error {
-	return writeBinderCheckedImpl(path, data)
+// ReadNodeFile reads the node file at path as stored.
+func (w *fileDeleteIO) ReadNodeFile(path string) ([]byte, error) {
+	return fsio.ReadFile(path)
 }
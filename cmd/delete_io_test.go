@@ -49,7 +49,7 @@ func TestFileDeleteIO_ReadBinder(t *testing.T) {
 	}
 }
 
-func TestFileDeleteIO_WriteBinderAtomicImpl(t *testing.T) {
+func TestFileDeleteIO_WriteBinderAtomic(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "_binder.md")
 	content := delBinder()
@@ -68,7 +68,7 @@ func TestFileDeleteIO_WriteBinderAtomicImpl(t *testing.T) {
 	}
 }
 
-func TestFileDeleteIO_WriteBinderAtomicImpl_RejectsReadOnlyFile(t *testing.T) {
+func TestFileDeleteIO_WriteBinderAtomic_RejectsReadOnlyFile(t *testing.T) {
 	if os.Getuid() == 0 {
 		t.Skip("permission check bypassed as root")
 	}
@@ -92,7 +92,7 @@ func TestFileDeleteIO_WriteBinderAtomicImpl_RejectsReadOnlyFile(t *testing.T) {
 	}
 }
 
-func TestFileDeleteIO_WriteBinderAtomicImpl_LeavesOriginalOnError(t *testing.T) {
+func TestFileDeleteIO_WriteBinderAtomic_LeavesOriginalOnError(t *testing.T) {
 	// Write to a path whose directory does not exist → write must fail, original untouched
 	path := filepath.Join(t.TempDir(), "nonexistent-dir", "_binder.md")
 	content := []byte("new content")
@@ -107,3 +107,20 @@ func TestFileDeleteIO_WriteBinderAtomicImpl_LeavesOriginalOnError(t *testing.T)
 		t.Error("expected file to not exist after failed atomic write")
 	}
 }
+
+func TestFileDeleteIO_ReadNodeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ch1.md")
+	content := []byte("---\ntitle: One\n---\nBody.\n")
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fio := newDefaultDeleteIO()
+	got, err := fio.ReadNodeFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("got %q, want %q", got, content)
+	}
+}
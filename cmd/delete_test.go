@@ -5,10 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"os"
+	"sort"
 	"strings"
 	"testing"
 
 	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
 )
 
 // mockDeleteIO is a test double for DeleteIO.
@@ -20,6 +23,12 @@ type mockDeleteIO struct {
 	writeErr     error
 	writtenBytes []byte
 	writtenPath  string
+	// files maps node file paths to content for --cascade.
+	files map[string][]byte
+}
+
+func (m *mockDeleteIO) BeginTx(string) (core.FileTx, error) {
+	return beginMemTx(m)
 }
 
 func (m *mockDeleteIO) ReadBinder(_ context.Context, _ string) ([]byte, error) {
@@ -39,6 +48,27 @@ func (m *mockDeleteIO) WriteBinderAtomic(_ context.Context, path string, data []
 	return m.writeErr
 }
 
+func (m *mockDeleteIO) ReadNodeFile(path string) ([]byte, error) {
+	content, ok := m.files[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return content, nil
+}
+
+func (m *mockDeleteIO) WriteNodeFileAtomic(path string, content []byte) error {
+	if m.files == nil {
+		m.files = map[string][]byte{}
+	}
+	m.files[path] = content
+	return nil
+}
+
+func (m *mockDeleteIO) DeleteFile(path string) error {
+	delete(m.files, path)
+	return nil
+}
+
 // delBinder returns a minimal binder with one child node for delete tests.
 func delBinder() []byte {
 	return []byte("<!-- prosemark-binder:v1 -->\n- [Chapter One](chapter-one.md)\n")
@@ -325,3 +355,103 @@ func TestNewRootCmd_RegistersDeleteSubcommand(t *testing.T) {
 		t.Error("expected \"delete\" subcommand registered on root command")
 	}
 }
+
+func TestNewDeleteCmd_DryRunDoesNotNeedYes(t *testing.T) {
+	mock := &mockDeleteIO{
+		binderBytes: delBinder(),
+		project:     &binder.Project{Files: []string{"chapter-one.md"}, BinderDir: "."},
+	}
+	c := NewDeleteCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetArgs([]string{"--selector", "chapter-one.md", "--dry-run", "--project", "."})
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mock.writtenBytes != nil {
+		t.Errorf("dry run wrote the binder: %q", mock.writtenBytes)
+	}
+	if !strings.Contains(out.String(), "-- [Chapter One](chapter-one.md)\n") || strings.Contains(out.String(), "Deleted") {
+		t.Errorf("output = %q, want only the diff", out.String())
+	}
+}
+
+func TestNewDeleteCmd_Cascade(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantOut   string
+		wantFiles []string
+	}{
+		{"files", []string{"--cascade", "files"}, "Deleted chapter-one from _binder.md\nRemoved chapter-one.md\nRemoved chapter-one.notes.md\n", nil},
+		{"trash", []string{"--cascade", "trash"}, "Deleted chapter-one from _binder.md\nMoved chapter-one.md to the trash\nMoved chapter-one.notes.md to the trash\nUndo with: pmk restore 20260301T120000Z\n",
+			[]string{".prosemark/trash/20260301T120000Z/chapter-one.md", ".prosemark/trash/20260301T120000Z/chapter-one.notes.md", ".prosemark/trash/manifest.json"}},
+		{"archive", []string{"--archive"}, "Undo with: pmk restore 20260301T120000Z\n",
+			[]string{".prosemark/trash/20260301T120000Z/chapter-one.md", ".prosemark/trash/20260301T120000Z/chapter-one.notes.md", ".prosemark/trash/manifest.json"}},
+		{"dry run", []string{"--cascade", "files", "--dry-run"}, "Would remove chapter-one.md\nWould remove chapter-one.notes.md\n",
+			[]string{"chapter-one.md", "chapter-one.notes.md"}},
+		{"archive dry run", []string{"--archive", "--dry-run"}, "Would move chapter-one.md to the trash\nWould move chapter-one.notes.md to the trash\n",
+			[]string{"chapter-one.md", "chapter-one.notes.md"}},
+	}
+	stubDeleteNow(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockDeleteIO{
+				binderBytes: delBinder(),
+				project:     &binder.Project{Files: []string{"chapter-one.md", "chapter-one.notes.md"}, BinderDir: "."},
+				files: map[string][]byte{
+					"chapter-one.md":       []byte("One.\n"),
+					"chapter-one.notes.md": []byte("Notes.\n"),
+				},
+			}
+			c := newDeleteCmdWithGetCWD(mock, func() (string, error) { return ".", nil })
+			out := new(bytes.Buffer)
+			c.SetOut(out)
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(append([]string{"--selector", "chapter-one", "--yes"}, tt.args...))
+			if err := c.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.HasSuffix(out.String(), tt.wantOut) {
+				t.Errorf("output = %q, want it to end with %q", out, tt.wantOut)
+			}
+			var got []string
+			for p := range mock.files {
+				got = append(got, p)
+			}
+			sort.Strings(got)
+			if strings.Join(got, " ") != strings.Join(tt.wantFiles, " ") {
+				t.Errorf("files = %v, want %v", got, tt.wantFiles)
+			}
+		})
+	}
+}
+
+func TestNewDeleteCmd_CascadeUnknownMode(t *testing.T) {
+	c := NewDeleteCmd(&mockDeleteIO{binderBytes: delBinder()})
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--project", ".", "--selector", "chapter-one", "--yes", "--cascade", "shred"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), `unknown --cascade "shred"`) {
+		t.Errorf("error = %v, want an unknown --cascade error", err)
+	}
+}
+
+func TestNewDeleteCmd_ArchiveConflictsWithCascadeFiles(t *testing.T) {
+	c := NewDeleteCmd(&mockDeleteIO{binderBytes: delBinder()})
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--project", ".", "--selector", "chapter-one", "--yes", "--archive", "--cascade", "files"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "--archive conflicts with --cascade files") {
+		t.Errorf("error = %v, want a conflict error", err)
+	}
+}
+
+// stubDeleteNow fixes the deletion time, so trash IDs are 20260301T120000Z.
+func stubDeleteNow(t *testing.T) {
+	t.Helper()
+	orig := nowUTCFunc
+	nowUTCFunc = func() string { return "2026-03-01T12:00:00Z" }
+	t.Cleanup(func() { nowUTCFunc = orig })
+}
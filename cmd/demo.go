@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/demo"
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+// DemoIO handles I/O for the demo command.
+type DemoIO interface {
+	// MkdirTemp creates a new directory under the system temp directory
+	// whose name begins with prefix, and returns its path.
+	MkdirTemp(prefix string) (string, error)
+	StatFile(path string) (bool, error)
+	// WriteFileAtomic writes content to path, creating its directory.
+	WriteFileAtomic(path string, content []byte) error
+}
+
+// demoCommands are the commands demo suggests trying on the example.
+var demoCommands = []string{
+	"pmk tree",
+	"pmk tree --columns title,words,status,updated",
+	"pmk show Arrival",
+	"pmk wc",
+	"pmk doctor",
+	"pmk compile",
+}
+
+// demoProject returns the files of the example project demo writes.
+// Override in tests to simulate a missing project or unreadable files.
+var demoProject = demo.Project
+
+// NewDemoCmd creates the demo subcommand.
+func NewDemoCmd(io DemoIO) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "demo [dir]",
+		Short: "Write an example project to try pmk on",
+		Long: "Write a small example project, a short story with a binder, node files,\n" +
+			"and notes, and print some commands to try on it. The project is written\n" +
+			"to a new temporary directory, or to dir, which must not hold a binder\n" +
+			"already.",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var dir string
+			if len(args) > 0 {
+				dir = args[0]
+				exists, err := io.StatFile(filepath.Join(dir, binder.DefaultBinderFilename))
+				if err != nil {
+					return fmt.Errorf("checking %s: %w", sanitizePath(dir), err)
+				}
+				if exists {
+					return fmt.Errorf("%s already exists in %s; choose another directory", binder.DefaultBinderFilename, sanitizePath(dir))
+				}
+			} else {
+				var err error
+				if dir, err = io.MkdirTemp("pmk-demo-"); err != nil {
+					return fmt.Errorf("creating demo directory: %w", err)
+				}
+			}
+
+			project, err := demoProject()
+			if err != nil {
+				return fmt.Errorf("reading the example project: %w", err)
+			}
+			err = fs.WalkDir(project, ".", func(path string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				content, err := fs.ReadFile(project, path)
+				if err != nil {
+					return err
+				}
+				if err := io.WriteFileAtomic(filepath.Join(dir, filepath.FromSlash(path)), content); err != nil {
+					return fmt.Errorf("writing %s: %w", path, err)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			var b strings.Builder
+			fmt.Fprintf(&b, "Created the example project %q in %s\n\nTry:\n  cd %s\n", demo.Title, sanitizePath(dir), sanitizePath(dir))
+			for _, c := range demoCommands {
+				b.WriteString("  " + c + "\n")
+			}
+			if _, err := fmt.Fprint(cmd.OutOrStdout(), b.String()); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// fileDemoIO implements DemoIO using OS file I/O.
+type fileDemoIO struct{}
+
+// MkdirTemp creates a new directory under the system temp directory.
+func (fileDemoIO) MkdirTemp(prefix string) (string, error) {
+	return os.MkdirTemp("", prefix)
+}
+
+// StatFile reports whether the file at path exists.
+func (fileDemoIO) StatFile(path string) (bool, error) {
+	return fsio.StatFile(path)
+}
+
+// WriteFileAtomic writes content to path atomically, creating its directory.
+func (fileDemoIO) WriteFileAtomic(path string, content []byte) error {
+	return fsio.WriteFileAtomicMkdir(path, ".demo", content)
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// mockDemoIO is a test double for DemoIO.
+type mockDemoIO struct {
+	tempErr  error
+	statErr  error
+	writeErr error
+	existing map[string]bool
+	written  map[string][]byte
+}
+
+func (m *mockDemoIO) MkdirTemp(prefix string) (string, error) {
+	return "/tmp/" + prefix + "1", m.tempErr
+}
+
+func (m *mockDemoIO) StatFile(path string) (bool, error) {
+	return m.existing[path], m.statErr
+}
+
+func (m *mockDemoIO) WriteFileAtomic(path string, content []byte) error {
+	if m.writeErr != nil {
+		return m.writeErr
+	}
+	if m.written == nil {
+		m.written = map[string][]byte{}
+	}
+	m.written[path] = content
+	return nil
+}
+
+// runDemoCmd runs demo with args over mock, or over the file system when mock
+// is nil.
+func runDemoCmd(t *testing.T, mock *mockDemoIO, args ...string) (string, error) {
+	t.Helper()
+	var dio DemoIO = fileDemoIO{}
+	if mock != nil {
+		dio = mock
+	}
+	c := NewDemoCmd(dio)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(args)
+	err := c.Execute()
+	return out.String(), err
+}
+
+func TestDemo(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		dir  string
+	}{
+		{nil, "/tmp/pmk-demo-1"},
+		{[]string{"/work/try"}, "/work/try"},
+	} {
+		mock := &mockDemoIO{}
+		out, err := runDemoCmd(t, mock, tt.args...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(out, `Created the example project "The Lighthouse Keeper" in `+tt.dir+"\n\nTry:\n  cd "+tt.dir+"\n  pmk tree\n") {
+			t.Errorf("output = %q", out)
+		}
+		for _, name := range []string{"_binder.md", ".prosemark.yml", "01928f3a-5c00-7a10-8b00-000000000002.notes.md"} {
+			if len(mock.written[filepath.Join(tt.dir, name)]) == 0 {
+				t.Errorf("%s not written to %s (wrote %d files)", name, tt.dir, len(mock.written))
+			}
+		}
+	}
+}
+
+func TestDemo_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    *mockDemoIO
+		args    []string
+		wantErr string
+	}{
+		{"existing binder", &mockDemoIO{existing: map[string]bool{filepath.Join("book", "_binder.md"): true}}, []string{"book"}, "_binder.md already exists in book"},
+		{"stat error", &mockDemoIO{statErr: errors.New("denied")}, []string{"book"}, "checking book: denied"},
+		{"temp dir error", &mockDemoIO{tempErr: errors.New("full")}, nil, "creating demo directory: full"},
+		{"write error", &mockDemoIO{writeErr: errors.New("full")}, nil, "writing"},
+		{"too many args", &mockDemoIO{}, []string{"a", "b"}, "accepts at most 1 arg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := runDemoCmd(t, tt.mock, tt.args...); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// unreadableFS is an fs.FS whose directories list but whose files cannot be
+// read.
+type unreadableFS struct{ fstest.MapFS }
+
+func (unreadableFS) ReadFile(string) ([]byte, error) {
+	return nil, errors.New("unreadable")
+}
+
+func TestDemo_UnreadableProjectFile(t *testing.T) {
+	orig := demoProject
+	t.Cleanup(func() { demoProject = orig })
+	demoProject = func() (fs.FS, error) { return unreadableFS{fstest.MapFS{"_binder.md": {}}}, nil }
+
+	if _, err := runDemoCmd(t, &mockDemoIO{}); err == nil || !strings.Contains(err.Error(), "unreadable") {
+		t.Errorf("error = %v, want the read failure", err)
+	}
+}
+
+func TestDemo_ProjectError(t *testing.T) {
+	orig := demoProject
+	t.Cleanup(func() { demoProject = orig })
+	demoProject = func() (fs.FS, error) { return nil, errors.New("no example") }
+
+	mock := &mockDemoIO{}
+	if _, err := runDemoCmd(t, mock); err == nil || !strings.Contains(err.Error(), "reading the example project: no example") {
+		t.Errorf("error = %v, want the project failure", err)
+	}
+	if len(mock.written) != 0 {
+		t.Errorf("wrote %d files, want none", len(mock.written))
+	}
+}
+
+func TestDemo_OutputError(t *testing.T) {
+	c := NewDemoCmd(&mockDemoIO{})
+	c.SetOut(&errWriter{err: errors.New("closed")})
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(nil)
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "writing output") {
+		t.Errorf("error = %v, want output failure", err)
+	}
+}
+
+func TestFileDemoIO_WritesProject(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	dir := filepath.Join(t.TempDir(), "try")
+	for _, args := range [][]string{nil, {dir}} {
+		out, err := runDemoCmd(t, nil, args...)
+		if err != nil {
+			t.Fatalf("demo %v: %v", args, err)
+		}
+		if !strings.Contains(out, "Created the example project") {
+			t.Errorf("output = %q", out)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "_binder.md")); err != nil {
+		t.Errorf("binder not written: %v", err)
+	}
+	if _, err := runDemoCmd(t, nil, dir); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("second demo into %s: error = %v, want existing binder refused", dir, err)
+	}
+}
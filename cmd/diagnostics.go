@@ -1,6 +1,12 @@
 package cmd
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
 	"github.com/eykd/prosemark-go/internal/binder"
 	"github.com/eykd/prosemark-go/internal/node"
 )
@@ -29,3 +35,182 @@ func hasAuditDiagnosticError(diags []node.AuditDiagnostic) bool {
 	}
 	return false
 }
+
+// CodeDiagnosticsTruncated is an implementation-specific info diagnostic
+// closing a list cut short by --max-diagnostics.
+const CodeDiagnosticsTruncated = "PMKI002"
+
+// diagnosticGroupMin is how many diagnostics must share a severity and code
+// before human-readable output collapses them into one summary line.
+const diagnosticGroupMin = 4
+
+// diagnosticGroupTargets is how many targets a summary line lists before
+// counting the rest.
+const diagnosticGroupTargets = 5
+
+// diagnosticSummaries describe a group of diagnostics sharing a code, in
+// place of any one diagnostic's message.
+var diagnosticSummaries = map[string]string{
+	binder.CodeIllegalPathChars:       "illegal path characters in link targets",
+	binder.CodePathEscapesRoot:        "link targets resolve outside the project root",
+	binder.CodeAmbiguousWikilink:      "ambiguous wikilinks",
+	binder.CodeMultipleStructLinks:    "list items contain multiple structural links",
+	binder.CodeDuplicateFileRef:       "duplicate file references",
+	binder.CodeMissingTargetFile:      "target files are not present in the project",
+	binder.CodeLinkInCodeFence:        "structural links inside fenced code blocks",
+	binder.CodeLinkOutsideList:        "links to .md files outside list items",
+	binder.CodeNonMarkdownTarget:      "link targets are not .md files",
+	binder.CodeSelfReferentialLink:    "links target the binder file itself",
+	binder.CodeCaseInsensitiveMatch:   "link targets match project files only case-insensitively",
+	binder.CodeReferenceSectionLink:   "prose cross-references in reference sections",
+	binder.CodeWikilinkAliasMatch:     "wikilinks resolved through frontmatter aliases",
+	binder.CodeNonStructuralDestroyed: "non-structural content dropped",
+	binder.CodeEmptySublistPruned:     "empty sublists pruned",
+}
+
+// maxDiagnosticsFromCmd returns --max-diagnostics, or 0 (no limit) when the
+// command runs without the root's persistent flags.
+func maxDiagnosticsFromCmd(cmd *cobra.Command) int {
+	n, _ := cmd.Flags().GetInt("max-diagnostics")
+	return n
+}
+
+// diagnosticKey identifies a diagnostic for deduplication.
+type diagnosticKey struct {
+	severity, code, message string
+	location                binder.Location
+	located                 bool
+}
+
+// dedupDiagnostics returns diags without exact repeats, keeping the first of
+// each in order. The result is never nil.
+func dedupDiagnostics(diags []binder.Diagnostic) []binder.Diagnostic {
+	out := make([]binder.Diagnostic, 0, len(diags))
+	seen := make(map[diagnosticKey]bool, len(diags))
+	for _, d := range diags {
+		key := diagnosticKey{severity: d.Severity, code: d.Code, message: d.Message}
+		if d.Location != nil {
+			key.location, key.located = *d.Location, true
+		}
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// truncationNotice is the PMKI002 diagnostic noting that omitted
+// diagnostics were left out under --max-diagnostics limit.
+func truncationNotice(omitted, limit int) binder.Diagnostic {
+	return binder.Diagnostic{
+		Severity: "info",
+		Code:     CodeDiagnosticsTruncated,
+		Message:  fmt.Sprintf("%d more diagnostics not shown (--max-diagnostics %d)", omitted, limit),
+	}
+}
+
+// reportedDiagnostics is diags as JSON output reports them: exact repeats
+// are dropped and, under --max-diagnostics, the rest are capped and closed
+// with a PMKI002 notice. Error checks must still use the full diags.
+func reportedDiagnostics(cmd *cobra.Command, diags []binder.Diagnostic) []binder.Diagnostic {
+	diags = dedupDiagnostics(diags)
+	limit := maxDiagnosticsFromCmd(cmd)
+	if limit == 0 || len(diags) <= limit {
+		return diags
+	}
+	return append(diags[:limit:limit], truncationNotice(len(diags)-limit, limit))
+}
+
+// diagnosticGroup counts the diagnostics sharing a severity and code, and
+// collects the span of lines they were reported on and their targets.
+type diagnosticGroup struct {
+	count, firstLine, lastLine int
+	targets                    []string
+}
+
+// summary renders the group as one line: a summary of its code, the code
+// with the count and span, and the first diagnosticGroupTargets targets.
+func (g *diagnosticGroup) summary(severity, code string) string {
+	msg, ok := diagnosticSummaries[code]
+	if !ok {
+		msg = "repeated diagnostics"
+	}
+	line := fmt.Sprintf("%s: %s (%s ×%d%s)", severity, msg, code, g.count, g.span())
+	if len(g.targets) == 0 {
+		return line
+	}
+	shown := g.targets[:min(len(g.targets), diagnosticGroupTargets)]
+	line += ": " + strings.Join(shown, ", ")
+	if rest := len(g.targets) - len(shown); rest > 0 {
+		line += fmt.Sprintf(", and %d more", rest)
+	}
+	return line
+}
+
+// span describes the lines the group was reported on, if any.
+func (g *diagnosticGroup) span() string {
+	switch {
+	case g.firstLine == 0:
+		return ""
+	case g.firstLine == g.lastLine:
+		return fmt.Sprintf(" on line %d", g.firstLine)
+	default:
+		return fmt.Sprintf(" within lines %d–%d", g.firstLine, g.lastLine)
+	}
+}
+
+// diagnosticLines renders diags one per line for human-readable output.
+// Exact repeats are dropped, and a severity and code shared by
+// diagnosticGroupMin or more diagnostics collapses into one summary line at
+// its first occurrence, listing the targets concerned ("target files are not
+// present in the project (BNDW004 ×30 within lines 3–40): n1.md, …"). A
+// positive limit keeps that many lines and closes with a PMKI002 notice.
+func diagnosticLines(diags []binder.Diagnostic, limit int) []string {
+	diags = dedupDiagnostics(diags)
+	groups := make(map[[2]string]*diagnosticGroup)
+	for _, d := range diags {
+		key := [2]string{d.Severity, d.Code}
+		g := groups[key]
+		if g == nil {
+			g = &diagnosticGroup{}
+			groups[key] = g
+		}
+		g.count++
+		if d.Location != nil {
+			if g.firstLine == 0 || d.Location.Line < g.firstLine {
+				g.firstLine = d.Location.Line
+			}
+			g.lastLine = max(g.lastLine, d.Location.Line)
+		}
+		if d.Target != "" && !slices.Contains(g.targets, d.Target) {
+			g.targets = append(g.targets, d.Target)
+		}
+	}
+
+	var lines []string
+	omitted := 0
+	shown := make(map[[2]string]bool)
+	for _, d := range diags {
+		key := [2]string{d.Severity, d.Code}
+		g := groups[key]
+		n, line := 1, fmt.Sprintf("%s: %s (%s)", d.Severity, d.Message, d.Code)
+		if g.count >= diagnosticGroupMin {
+			if shown[key] {
+				continue
+			}
+			shown[key] = true
+			n, line = g.count, g.summary(d.Severity, d.Code)
+		}
+		if limit > 0 && len(lines) >= limit {
+			omitted += n
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if omitted > 0 {
+		d := truncationNotice(omitted, limit)
+		lines = append(lines, fmt.Sprintf("%s: %s (%s)", d.Severity, d.Message, d.Code))
+	}
+	return lines
+}
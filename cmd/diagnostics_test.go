@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"slices"
 	"testing"
 
+	"github.com/spf13/cobra"
+
 	"github.com/eykd/prosemark-go/internal/binder"
 	"github.com/eykd/prosemark-go/internal/node"
 )
@@ -82,3 +85,102 @@ func TestHasAuditDiagnosticError_DelegatesViaPrimitive(t *testing.T) {
 		})
 	}
 }
+
+func fenceWarning(line int) binder.Diagnostic {
+	return binder.Diagnostic{Severity: "warning", Code: binder.CodeLinkInCodeFence, Message: "link in fence", Location: &binder.Location{Line: line, Column: 1}}
+}
+
+func TestDedupDiagnostics(t *testing.T) {
+	unlocated := binder.Diagnostic{Severity: "error", Code: "OPE002", Message: "no match"}
+	got := dedupDiagnostics([]binder.Diagnostic{fenceWarning(3), unlocated, fenceWarning(3), fenceWarning(4), unlocated})
+	if len(got) != 3 || got[0].Location.Line != 3 || got[1].Code != "OPE002" || got[2].Location.Line != 4 {
+		t.Errorf("dedupDiagnostics() = %+v", got)
+	}
+	if got := dedupDiagnostics(nil); got == nil {
+		t.Error("dedupDiagnostics(nil) = nil, want empty")
+	}
+}
+
+func TestDiagnosticLines(t *testing.T) {
+	cascade := []binder.Diagnostic{
+		{Severity: "error", Code: "BNDE001", Message: "unclosed fence", Location: &binder.Location{Line: 2}},
+		fenceWarning(9), fenceWarning(5), fenceWarning(5), fenceWarning(7), fenceWarning(12),
+		{Severity: "warning", Code: "BNDW002", Message: "duplicate"},
+		{Severity: "warning", Code: "BNDW002", Message: "duplicate again"},
+	}
+	unlocated := slices.Repeat([]binder.Diagnostic{{Severity: "info", Code: "PMKI001"}}, 4)
+	for i := range unlocated {
+		unlocated[i].Message = string(rune('a' + i))
+	}
+	sameLine := []binder.Diagnostic{fenceWarning(6), fenceWarning(6), fenceWarning(6), fenceWarning(6)}
+	for i := range sameLine {
+		sameLine[i].Message += string(rune('a' + i))
+	}
+	var missing []binder.Diagnostic
+	for i, target := range []string{"n1.md", "n2.md", "n3.md", "n4.md", "n5.md", "n5.md", "n6.md"} {
+		missing = append(missing, binder.Diagnostic{Severity: "warning", Code: binder.CodeMissingTargetFile, Message: "Target file " + target + " is not present in the project", Location: &binder.Location{Line: i + 2}, Target: target})
+	}
+	unsummarized := slices.Repeat([]binder.Diagnostic{{Severity: "error", Code: "PMKE999"}}, 4)
+	for i := range unsummarized {
+		unsummarized[i].Message = string(rune('a' + i))
+	}
+	tests := []struct {
+		name  string
+		diags []binder.Diagnostic
+		limit int
+		want  []string
+	}{
+		{"groups repeated codes", cascade, 0, []string{
+			"error: unclosed fence (BNDE001)",
+			"warning: structural links inside fenced code blocks (BNDW005 ×4 within lines 5–12)",
+			"warning: duplicate (BNDW002)",
+			"warning: duplicate again (BNDW002)",
+		}},
+		{"limit counts a group as all its diagnostics", cascade, 1, []string{
+			"error: unclosed fence (BNDE001)",
+			"info: 6 more diagnostics not shown (--max-diagnostics 1) (PMKI002)",
+		}},
+		{"limit at the line count", cascade, 4, []string{
+			"error: unclosed fence (BNDE001)",
+			"warning: structural links inside fenced code blocks (BNDW005 ×4 within lines 5–12)",
+			"warning: duplicate (BNDW002)",
+			"warning: duplicate again (BNDW002)",
+		}},
+		{"group without locations", unlocated, 0, []string{"info: prose cross-references in reference sections (PMKI001 ×4)"}},
+		{"group on one line", sameLine, 0, []string{"warning: structural links inside fenced code blocks (BNDW005 ×4 on line 6)"}},
+		{"group lists its targets", missing, 0, []string{
+			"warning: target files are not present in the project (BNDW004 ×7 within lines 2–8): n1.md, n2.md, n3.md, n4.md, n5.md, and 1 more",
+		}},
+		{"group of an unsummarized code", unsummarized, 0, []string{"error: repeated diagnostics (PMKE999 ×4)"}},
+		{"none", nil, 3, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diagnosticLines(tt.diags, tt.limit); !slices.Equal(got, tt.want) {
+				t.Errorf("diagnosticLines() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportedDiagnostics(t *testing.T) {
+	diags := []binder.Diagnostic{fenceWarning(3), fenceWarning(3), fenceWarning(4), fenceWarning(5)}
+	cmd := &cobra.Command{}
+	cmd.Flags().Int("max-diagnostics", 0, "")
+
+	if got := reportedDiagnostics(cmd, diags); len(got) != 3 {
+		t.Errorf("unlimited: got %d diagnostics, want 3", len(got))
+	}
+
+	_ = cmd.Flags().Set("max-diagnostics", "2")
+	got := reportedDiagnostics(cmd, diags)
+	if len(got) != 3 || got[1].Location.Line != 4 || got[2].Code != CodeDiagnosticsTruncated {
+		t.Fatalf("limited: got %+v", got)
+	}
+	if want := "1 more diagnostics not shown (--max-diagnostics 2)"; got[2].Message != want || got[2].Severity != "info" {
+		t.Errorf("notice = %+v, want info %q", got[2], want)
+	}
+	if len(diags) != 4 || diags[2].Location.Line != 4 {
+		t.Errorf("input modified: %+v", diags)
+	}
+}
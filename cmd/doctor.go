@@ -2,26 +2,31 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
 	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
 )
 
 // DoctorIO handles I/O for the doctor command.
 type DoctorIO interface {
-	// ReadBinder reads the raw binder file at path.
-	ReadBinder(path string) ([]byte, error)
-	// ListUUIDFiles returns UUID-pattern .md filenames found in dir (non-recursive).
-	ListUUIDFiles(dir string) ([]string, error)
-	// ReadNodeFile reads the file at path. The bool reports whether the file exists.
-	ReadNodeFile(path string) ([]byte, bool, error)
+	core.DoctorIO
+	// WriteReport writes a rendered report to path (for --out).
+	WriteReport(path string, data []byte) error
 }
 
 // DoctorDiagnosticJSON is the JSON output type for a single doctor diagnostic.
@@ -30,6 +35,7 @@ type DoctorDiagnosticJSON struct {
 	Code     string `json:"code"`
 	Message  string `json:"message"`
 	Path     string `json:"path"`
+	Line     int    `json:"line,omitempty"`
 }
 
 // doctorOutput is the wrapped JSON output for the doctor command.
@@ -46,79 +52,84 @@ func NewDoctorCmd(io DoctorIO) *cobra.Command {
 // newDoctorCmdWithGetCWD creates the doctor subcommand with an injectable getwd function.
 func newDoctorCmdWithGetCWD(io DoctorIO, getwd func() (string, error)) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:          "doctor",
-		Short:        "Validate project structural integrity and frontmatter contracts",
-		Args:         cobra.NoArgs,
+		Use:   "doctor [path...]",
+		Short: "Validate project structural integrity and frontmatter contracts",
+		Long: `Validate project structural integrity and frontmatter contracts.
+
+With path arguments or --stdin-list, only diagnostics for those files are
+reported (useful in a pre-commit hook), though the whole binder is still read
+so orphan and duplicate checks keep their project-wide context.
+
+--format github prints each finding as a GitHub Actions workflow command, so
+a CI step annotates problems inline on pull requests.
+
+Frontmatter results for unchanged node files are reused from
+.prosemark/cache/doctor.json; --no-cache validates every file afresh.
+
+--watch audits again whenever project files change, printing each audit's
+diagnostics as one line of JSON until interrupted. Changes are found with
+file notifications, or by checking every --poll-interval where those are
+unavailable, and a burst of changes is audited once it has been quiet for
+--debounce (default: watch.debounce in .prosemark.yml, else 250ms).
+Diagnostics do not fail a watch.`,
+		Args:         cobra.ArbitraryArgs,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			jsonMode, _ := cmd.Flags().GetBool("json")
-
-			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			format, err := doctorFormatFromCmd(cmd)
 			if err != nil {
 				return err
 			}
-			projectDir := filepath.Dir(binderPath)
+			outPath, _ := cmd.Flags().GetString("out")
+			watching := watchRequested(cmd)
+			if watching && outPath != "" {
+				return fmt.Errorf("--watch conflicts with --out")
+			}
+			if watching && cmd.Flags().Changed("format") && format != doctorFormatJSON {
+				return fmt.Errorf("--watch conflicts with --format %s", format)
+			}
 
-			// Read binder — distinguish not-found from permission errors.
-			binderBytes, err := io.ReadBinder(binderPath)
+			binderPath, err := doctorBinderPath(cmd, getwd)
 			if err != nil {
-				if errors.Is(err, os.ErrNotExist) {
-					return fmt.Errorf("project not initialized — run 'pmk init' first")
-				}
-				return fmt.Errorf("cannot read binder: %w", err)
+				return err
 			}
+			projectDir := filepath.Dir(binderPath)
 
-			// List UUID files in project root.
-			uuidFiles, err := io.ListUUIDFiles(projectDir)
+			subset, err := doctorSubsetFromCmd(cmd, args, projectDir, getwd)
 			if err != nil {
-				uuidFiles = []string{}
+				return err
 			}
 
-			// Collect binder refs and binder-level diagnostics (escape warnings, duplicates).
-			// This is the sole binder.Parse call per doctor invocation.
-			refs, refDiags := node.CollectBinderRefs(cmd.Context(), binderBytes)
-
-			// Build FileContents map: one entry per unique referenced filename.
-			fileContents := make(map[string][]byte, len(refs))
-			for _, ref := range refs {
-				fileContents[ref] = doctorReadFile(io, projectDir, ref)
+			noCache, _ := cmd.Flags().GetBool("no-cache")
+			opts := core.DoctorOptions{Subset: subset, NoCache: noCache}
+			if watching {
+				return runWatch(cmd, io, projectDir, func(ctx context.Context) (any, error) {
+					diags, err := core.Doctor(ctx, io, binderPath, opts)
+					if err != nil {
+						return nil, err
+					}
+					return newDoctorOutput(diags).Diagnostics, nil
+				})
 			}
-
-			data := node.DoctorData{
-				BinderSrc:      binderBytes,
-				UUIDFiles:      uuidFiles,
-				FileContents:   fileContents,
-				BinderRefs:     refs,
-				BinderRefDiags: refDiags,
+			diags, err := core.Doctor(cmd.Context(), io, binderPath, opts)
+			if err != nil {
+				return err
 			}
 
-			configDiags := checkProjectConfig(io, projectDir)
-			diags := node.RunDoctor(cmd.Context(), data)
-			diags = append(diags, configDiags...)
-
 			// Emit diagnostics.
-			if jsonMode {
-				jsonDiags := make([]DoctorDiagnosticJSON, len(diags))
-				for i, d := range diags {
-					jsonDiags[i] = DoctorDiagnosticJSON{
-						Severity: string(d.Severity),
-						Code:     string(d.Code),
-						Message:  d.Message,
-						Path:     d.Path,
-					}
+			report := renderDoctorReport(format, filepath.Base(projectDir), annotationPath(projectDir, getwd), diags)
+			switch {
+			case outPath != "":
+				if err := io.WriteReport(outPath, report); err != nil {
+					return fmt.Errorf("writing report: %w", err)
 				}
-				out := doctorOutput{Version: "1", Diagnostics: jsonDiags}
-				if err := json.NewEncoder(cmd.OutOrStdout()).Encode(out); err != nil {
+				fmt.Fprintln(cmd.OutOrStdout(), "Wrote doctor report to "+sanitizePath(outPath))
+			case format == doctorFormatText:
+				// Diagnostics route to stderr in plain-text mode.
+				_, _ = cmd.ErrOrStderr().Write(report)
+			default:
+				if _, err := cmd.OutOrStdout().Write(report); err != nil {
 					return fmt.Errorf("encoding output: %w", err)
 				}
-			} else {
-				for _, d := range diags {
-					fmt.Fprintf(cmd.ErrOrStderr(), "%s %-7s %s\n",
-						string(d.Code),
-						string(d.Severity),
-						sanitizePath(d.Message),
-					)
-				}
 			}
 
 			if hasAuditDiagnosticError(diags) {
@@ -130,101 +141,178 @@ func newDoctorCmdWithGetCWD(io DoctorIO, getwd func() (string, error)) *cobra.Co
 	}
 
 	cmd.Flags().String("project", "", "project directory to audit (default: current directory)")
-	cmd.Flags().Bool("json", false, "output diagnostics as JSON")
+	cmd.Flags().Bool("json", false, "output diagnostics as JSON (same as --format json)")
+	cmd.Flags().String("format", doctorFormatText, "output format: text, json, yaml, markdown, or github (GitHub Actions annotations)")
+	cmd.Flags().String("out", "", "write the report to this file instead of the terminal")
+	cmd.Flags().Bool("stdin-list", false, "read newline-separated paths to audit from stdin")
+	cmd.Flags().Bool("no-cache", false, "re-validate every node's frontmatter instead of using "+core.DoctorCacheFilename)
+	addWatchFlags(cmd)
 
 	return cmd
 }
 
-// checkProjectConfig validates .prosemark.yml existence and YAML integrity.
-// Returns an AUD008 error diagnostic if the file is missing, unreadable, or contains invalid YAML.
-func checkProjectConfig(io DoctorIO, projectDir string) []node.AuditDiagnostic {
-	configPath := filepath.Join(projectDir, ".prosemark.yml")
-	content, exists, err := io.ReadNodeFile(configPath)
+// doctorBinderPath resolves the binder doctor audits like
+// resolveBinderPathFromCmd, except that a config too broken to name the
+// binder leaves the default binder, when there is one, so the config is
+// reported as AUD008 rather than failing the audit.
+func doctorBinderPath(cmd *cobra.Command, getwd func() (string, error)) (string, error) {
+	binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+	var cfgErr *fsio.ConfigError
+	if !errors.As(err, &cfgErr) {
+		return binderPath, err
+	}
+	fallback := filepath.Join(cfgErr.Dir, binder.DefaultBinderFilename)
+	if exists, statErr := fsio.StatFile(fallback); statErr != nil || !exists {
+		return "", err
+	}
+	return fallback, nil
+}
+
+// doctorSubsetFromCmd collects the paths named as arguments or, with
+// --stdin-list, on stdin, and returns them as project-relative slash paths.
+// It returns nil when no subset was requested, so the whole project is
+// audited. Paths outside projectDir are dropped, since they cannot match any
+// diagnostic; relative paths resolve against the working directory, as a
+// pre-commit hook's file list does.
+func doctorSubsetFromCmd(cmd *cobra.Command, args []string, projectDir string, getwd func() (string, error)) (map[string]bool, error) {
+	paths := args
+	if stdinList, _ := cmd.Flags().GetBool("stdin-list"); stdinList {
+		data, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return nil, fmt.Errorf("reading path list from stdin: %w", err)
+		}
+		paths = append(paths, strings.Split(string(data), "\n")...)
+	} else if len(args) == 0 {
+		return nil, nil
+	}
 
-	var msg string
-	if err != nil || !exists {
-		msg = ".prosemark.yml is missing or unreadable"
-	} else {
-		var cfg interface{}
-		if err := yaml.Unmarshal(content, &cfg); err != nil {
-			msg = ".prosemark.yml contains invalid YAML"
+	absProject, err := absFromCWD(projectDir, getwd)
+	if err != nil {
+		return nil, err
+	}
+	subset := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		abs, err := absFromCWD(p, getwd)
+		if err != nil {
+			return nil, err
 		}
+		rel, err := safepath.Rel(absProject, abs)
+		if err != nil {
+			continue
+		}
+		subset[rel] = true
 	}
+	return subset, nil
+}
 
-	if msg == "" {
-		return nil
+// Doctor output formats accepted by --format.
+const (
+	doctorFormatText     = "text"
+	doctorFormatJSON     = "json"
+	doctorFormatYAML     = "yaml"
+	doctorFormatMarkdown = "markdown"
+)
+
+// doctorFormatFromCmd resolves the output format from --format and the legacy
+// --json flag, rejecting unknown formats and contradictory combinations.
+func doctorFormatFromCmd(cmd *cobra.Command) (string, error) {
+	format, _ := cmd.Flags().GetString("format")
+	jsonMode, _ := cmd.Flags().GetBool("json")
+	switch format {
+	case doctorFormatText, doctorFormatJSON, doctorFormatYAML, doctorFormatMarkdown, formatGitHub:
+	default:
+		return "", fmt.Errorf("unknown --format %q: want text, json, yaml, markdown, or github", format)
+	}
+	if jsonMode {
+		if format == doctorFormatYAML {
+			return format, nil
+		}
+		if cmd.Flags().Changed("format") && format != doctorFormatJSON {
+			return "", fmt.Errorf("--json conflicts with --format %s", format)
+		}
+		format = doctorFormatJSON
 	}
-	return []node.AuditDiagnostic{{
-		Code:     node.AUD008,
-		Severity: node.SeverityError,
-		Message:  msg,
-		Path:     ".prosemark.yml",
-	}}
-}
-
-// doctorReadFile reads a binder-referenced file for doctor analysis.
-// Returns nil if the file does not exist or cannot be read.
-// Returns []byte{} (empty, non-nil) for files exceeding 1 MB, causing RunDoctor
-// to emit AUD007 (frontmatter parse failure) rather than AUD001 (file not found).
-func doctorReadFile(io DoctorIO, projectDir, ref string) []byte {
-	resolved := filepath.Join(projectDir, ref)
-	content, exists, err := io.ReadNodeFile(resolved)
-	if err != nil || !exists {
-		return nil
+	return format, nil
+}
+
+// newDoctorOutput returns the JSON form of diags.
+func newDoctorOutput(diags []node.AuditDiagnostic) doctorOutput {
+	jsonDiags := make([]DoctorDiagnosticJSON, len(diags))
+	for i, d := range diags {
+		jsonDiags[i] = DoctorDiagnosticJSON{
+			Severity: string(d.Severity),
+			Code:     string(d.Code),
+			Message:  d.Message,
+			Path:     d.Path,
+			Line:     d.Line,
+		}
 	}
-	if len(content) > 1024*1024 {
-		return []byte{}
+	return doctorOutput{Version: "1", Diagnostics: jsonDiags}
+}
+
+// renderDoctorReport renders diags in the requested format. Text output uses a
+// column-aligned severity field so messages line up. GitHub annotations name
+// files under annotationDir, the project directory as GitHub should see it.
+func renderDoctorReport(format, projectName, annotationDir string, diags []node.AuditDiagnostic) []byte {
+	var buf bytes.Buffer
+	switch format {
+	case doctorFormatJSON, doctorFormatYAML:
+		// Encoding plain strings into a buffer cannot fail, and the JSON
+		// always converts to YAML.
+		_ = json.NewEncoder(&buf).Encode(newDoctorOutput(diags))
+		if format == doctorFormatYAML {
+			data, _ := jsonToYAML(buf.Bytes())
+			return data
+		}
+	case doctorFormatMarkdown:
+		buf.Write(node.RenderMarkdownReport(projectName, diags))
+	case formatGitHub:
+		for _, d := range diags {
+			buf.WriteString(githubAnnotation(string(d.Severity), path.Join(annotationDir, d.Path), d.Line, 0, string(d.Code), d.Message))
+		}
+	default:
+		for _, d := range diags {
+			fmt.Fprintf(&buf, "%s %-7s %s\n",
+				string(d.Code),
+				string(d.Severity),
+				sanitizePath(d.Message),
+			)
+		}
 	}
-	return content
+	return buf.Bytes()
 }
 
 // fileDoctorIO implements DoctorIO using OS file I/O.
-// *Impl methods wrap OS calls and are excluded from coverage requirements.
-type fileDoctorIO struct{}
+type fileDoctorIO struct {
+	fileProjectIO
+	projectWatcher
+}
 
 // ReadBinder reads the binder file at path.
 func (f fileDoctorIO) ReadBinder(path string) ([]byte, error) {
-	return f.ReadBinderImpl(path)
+	return fsio.ReadBinder(path)
 }
 
-// ReadBinderImpl reads the binder file using os.ReadFile.
-func (f fileDoctorIO) ReadBinderImpl(path string) ([]byte, error) {
-	return os.ReadFile(path)
+// ListNodeFiles returns the node filenames of scheme found in dir.
+func (f fileDoctorIO) ListNodeFiles(dir string, scheme node.IDScheme) ([]string, error) {
+	return fsio.ListFiles(dir, scheme.MatchFilename)
 }
 
-// ListUUIDFiles returns UUID-pattern .md filenames found in dir.
-func (f fileDoctorIO) ListUUIDFiles(dir string) ([]string, error) {
-	return f.ListUUIDFilesImpl(dir)
+// WriteReport writes a rendered report to path atomically.
+func (f fileDoctorIO) WriteReport(path string, data []byte) error {
+	return fsio.WriteFileAtomic(path, ".report", data)
 }
 
-// ListUUIDFilesImpl reads the directory and filters for UUID-pattern .md files.
-func (f fileDoctorIO) ListUUIDFilesImpl(dir string) ([]string, error) {
-	entries, err := os.ReadDir(dir)
-	if err != nil {
-		return nil, err
-	}
-	var result []string
-	for _, e := range entries {
-		if !e.IsDir() && node.IsUUIDFilename(e.Name()) {
-			result = append(result, e.Name())
-		}
-	}
-	return result, nil
+// WriteCache writes a cache file atomically, creating its directory.
+func (f fileDoctorIO) WriteCache(path string, data []byte) error {
+	return fsio.WriteFileAtomicMkdir(path, ".doctor-cache", data)
 }
 
 // ReadNodeFile reads the node file at path, returning content, existence flag, and error.
 func (f fileDoctorIO) ReadNodeFile(path string) ([]byte, bool, error) {
-	return f.ReadNodeFileImpl(path)
-}
-
-// ReadNodeFileImpl reads the file using os.ReadFile, mapping ErrNotExist to exists=false.
-func (f fileDoctorIO) ReadNodeFileImpl(path string) ([]byte, bool, error) {
-	content, err := os.ReadFile(path)
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return nil, false, nil
-		}
-		return nil, false, err
-	}
-	return content, true, nil
+	return fsio.ReadFileIfExists(path)
 }
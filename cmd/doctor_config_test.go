@@ -3,6 +3,8 @@ package cmd
 import (
 	"bytes"
 	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -56,6 +58,23 @@ func TestNewDoctorCmd_ProjectConfigValidation(t *testing.T) {
 			wantErr:    false,
 			wantNoCode: "AUD008",
 		},
+		{
+			// Unknown field type in the types schema is a config error.
+			name: "invalid types schema in .prosemark.yml emits AUD008 error",
+			nodeFiles: map[string]nodeFileEntry{
+				".prosemark.yml": {content: []byte("types:\n  scene:\n    required:\n      pov: strng\n"), exists: true},
+			},
+			wantErr:  true,
+			wantCode: "AUD008",
+		},
+		{
+			name: "unknown wikilinks.resolution in .prosemark.yml emits AUD008 error",
+			nodeFiles: map[string]nodeFileEntry{
+				".prosemark.yml": {content: []byte("wikilinks:\n  resolution: nearest\n"), exists: true},
+			},
+			wantErr:  true,
+			wantCode: "AUD008",
+		},
 	}
 
 	for _, tt := range tests {
@@ -105,3 +124,68 @@ func TestNewDoctorCmd_ProjectConfig_JSONMode(t *testing.T) {
 		t.Errorf("--json output missing AUD008 for absent .prosemark.yml: %q", out.String())
 	}
 }
+
+// TestNewDoctorCmd_TypeSchema verifies that frontmatter fields declared for a
+// node's type in .prosemark.yml are validated as AUD010.
+func TestNewDoctorCmd_TypeSchema(t *testing.T) {
+	const id = "01234567-89ab-7def-8000-000000000001"
+	mock := &mockDoctorIO{
+		binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [Scene](" + id + ".md)\n"),
+		uuidFiles:   []string{id + ".md"},
+		nodeFiles: map[string]nodeFileEntry{
+			".prosemark.yml": {content: []byte("version: \"1\"\ntypes:\n  scene:\n    required:\n      pov: string\n"), exists: true},
+			id + ".md":       {content: []byte("---\nid: " + id + "\ntype: scene\ncreated: 2025-01-01T00:00:00Z\nupdated: 2025-01-01T00:00:00Z\n---\nBody.\n"), exists: true},
+		},
+	}
+	c := NewDoctorCmd(mock)
+	errOut := new(bytes.Buffer)
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(errOut)
+	c.SetArgs([]string{"--project", "."})
+
+	if err := c.Execute(); err == nil {
+		t.Fatal("expected error for schema violation")
+	}
+	if want := `AUD010 error   ` + id + `.md: type "scene" requires frontmatter field "pov"`; !strings.Contains(errOut.String(), want) {
+		t.Errorf("stderr = %q, want %q", errOut.String(), want)
+	}
+}
+
+// TestNewDoctorCmd_ConfiguredBinderOnDisk verifies that doctor audits the
+// binder .prosemark.yml names, reporting binder diagnostics against it, and
+// that a config too broken to name the binder is reported as AUD008 when the
+// default binder exists and as an error otherwise.
+func TestNewDoctorCmd_ConfiguredBinderOnDisk(t *testing.T) {
+	run := func(dir string, args ...string) (string, error) {
+		c := NewDoctorCmd(fileDoctorIO{})
+		out := new(bytes.Buffer)
+		c.SetOut(out)
+		c.SetErr(out)
+		c.SetArgs(append([]string{"--project", dir}, args...))
+		err := c.Execute()
+		return out.String(), err
+	}
+	write := func(t *testing.T, path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, ".prosemark.yml"), "binder: outline.md\n")
+	write(t, filepath.Join(dir, "outline.md"), "- [Missing](missing.md)\n")
+	if out, _ := run(dir, "--json"); !strings.Contains(out, `"path":"outline.md"`) || strings.Contains(out, "_binder.md") {
+		t.Errorf("configured binder: output = %q, want diagnostics against outline.md", out)
+	}
+
+	write(t, filepath.Join(dir, ".prosemark.yml"), "binder: outline.md\nid_scheme: bogus\n")
+	if _, err := run(dir); err == nil || !strings.Contains(err.Error(), `unknown id_scheme "bogus"`) {
+		t.Errorf("invalid config without a default binder: err = %v, want the config error", err)
+	}
+
+	write(t, filepath.Join(dir, "_binder.md"), "<!-- prosemark-binder:v1 -->\n")
+	if out, _ := run(dir); !strings.Contains(out, "AUD008") {
+		t.Errorf("invalid config with a default binder: output = %q, want AUD008", out)
+	}
+}
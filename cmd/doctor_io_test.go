@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/eykd/prosemark-go/internal/node"
 )
 
 func TestFileDoctorIO_ReadBinder(t *testing.T) {
@@ -26,7 +28,7 @@ func TestFileDoctorIO_ReadBinder(t *testing.T) {
 	}
 }
 
-func TestFileDoctorIO_ListUUIDFiles(t *testing.T) {
+func TestFileDoctorIO_ListNodeFiles(t *testing.T) {
 	dir := t.TempDir()
 	uuidName := "01234567-89ab-7def-0123-456789abcdef.md"
 	nonUUID := "chapter-one.md"
@@ -38,12 +40,12 @@ func TestFileDoctorIO_ListUUIDFiles(t *testing.T) {
 	}
 
 	fio := fileDoctorIO{}
-	got, err := fio.ListUUIDFiles(dir)
+	got, err := fio.ListNodeFiles(dir, node.UUIDv7IDs)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if len(got) != 1 || got[0] != uuidName {
-		t.Errorf("ListUUIDFiles = %v, want [%s]", got, uuidName)
+		t.Errorf("ListNodeFiles = %v, want [%s]", got, uuidName)
 	}
 }
 
@@ -82,37 +84,9 @@ func TestFileDoctorIO_ReadNodeFile_NotExists(t *testing.T) {
 	}
 }
 
-func TestDoctorReadFile_NotExists(t *testing.T) {
-	mock := &mockDoctorIO{
-		nodeFiles: map[string]nodeFileEntry{
-			"missing.md": {content: nil, exists: false},
-		},
-	}
-	got := doctorReadFile(mock, ".", "missing.md")
-	if got != nil {
-		t.Errorf("expected nil for non-existing file, got %q", got)
-	}
-}
-
-func TestDoctorReadFile_Oversized(t *testing.T) {
-	oversized := bytes.Repeat([]byte("x"), 1024*1024+1)
-	mock := &mockDoctorIO{
-		nodeFiles: map[string]nodeFileEntry{
-			"big.md": {content: oversized, exists: true},
-		},
-	}
-	got := doctorReadFile(mock, ".", "big.md")
-	if got == nil {
-		t.Fatal("expected non-nil sentinel for oversized file")
-	}
-	if len(got) != 0 {
-		t.Errorf("expected empty slice sentinel for oversized file, got len=%d", len(got))
-	}
-}
-
-// TestNewDoctorCmd_ListUUIDFilesError verifies that a ListUUIDFiles failure is
-// non-fatal: the command proceeds with an empty UUID file list and exits 0.
-func TestNewDoctorCmd_ListUUIDFilesError(t *testing.T) {
+// TestNewDoctorCmd_ListNodeFilesError verifies that a ListNodeFiles failure is
+// non-fatal: the command proceeds with an empty node file list and exits 0.
+func TestNewDoctorCmd_ListNodeFilesError(t *testing.T) {
 	mock := &mockDoctorIO{
 		binderBytes:  doctorBinderEmpty(),
 		uuidFilesErr: errors.New("disk error"),
@@ -125,19 +99,30 @@ func TestNewDoctorCmd_ListUUIDFilesError(t *testing.T) {
 	c.SetErr(new(bytes.Buffer))
 	c.SetArgs([]string{"--project", "."})
 	if err := c.Execute(); err != nil {
-		t.Errorf("expected nil error when ListUUIDFiles fails, got: %v", err)
+		t.Errorf("expected nil error when ListNodeFiles fails, got: %v", err)
 	}
 }
 
-func TestDoctorReadFile_Normal(t *testing.T) {
-	content := []byte("---\nid: test\n---\nbody\n")
-	mock := &mockDoctorIO{
-		nodeFiles: map[string]nodeFileEntry{
-			"node.md": {content: content, exists: true},
-		},
+func TestFileDoctorIO_WriteReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.md")
+
+	fio := fileDoctorIO{}
+	if err := fio.WriteReport(path, []byte("# report\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	got := doctorReadFile(mock, ".", "node.md")
-	if !bytes.Equal(got, content) {
-		t.Errorf("got %q, want %q", got, content)
+	if got, _ := os.ReadFile(path); string(got) != "# report\n" {
+		t.Errorf("report = %q", got)
+	}
+}
+
+func TestFileDoctorIO_WriteCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".prosemark", "cache", "doctor.json")
+
+	fio := fileDoctorIO{}
+	if err := fio.WriteCache(path, []byte("{}")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "{}" {
+		t.Errorf("cache = %q", got)
 	}
 }
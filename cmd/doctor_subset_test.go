@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+const doctorTestOrphanUUID = "01234567-89ab-7def-0123-222222222222"
+
+// subsetDoctorIO returns a project with one clean node, one node whose id
+// mismatches its filename, an orphan, and a broken .prosemark.yml.
+func subsetDoctorIO() *mockDoctorIO {
+	return &mockDoctorIO{
+		binderBytes: []byte("<!-- prosemark-binder:v1 -->\n" +
+			"- [Good](" + doctorTestNodeUUID + ".md)\n" +
+			"- [Bad](" + doctorTestNodeUUID2 + ".md)\n"),
+		uuidFiles: []string{doctorTestNodeUUID + ".md", doctorTestNodeUUID2 + ".md", doctorTestOrphanUUID + ".md"},
+		nodeFiles: map[string]nodeFileEntry{
+			doctorTestNodeUUID + ".md":  {content: validDoctorNodeContent(doctorTestNodeUUID), exists: true},
+			doctorTestNodeUUID2 + ".md": {content: mismatchedIDDoctorNodeContent(), exists: true},
+		},
+	}
+}
+
+func runDoctorSubset(t *testing.T, mock *mockDoctorIO, stdin string, args ...string) ([]DoctorDiagnosticJSON, error) {
+	t.Helper()
+	c := newDoctorCmdWithGetCWD(mock, func() (string, error) { return "/work", nil })
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetIn(strings.NewReader(stdin))
+	c.SetArgs(append([]string{"--project", "/work/novel", "--json"}, args...))
+	err := c.Execute()
+	var got doctorOutput
+	if jsonErr := json.Unmarshal(out.Bytes(), &got); jsonErr != nil {
+		t.Fatalf("decoding output %q: %v", out.String(), jsonErr)
+	}
+	return got.Diagnostics, err
+}
+
+func doctorCodes(diags []DoctorDiagnosticJSON) []string {
+	codes := make([]string, len(diags))
+	for i, d := range diags {
+		codes[i] = d.Code + " " + d.Path
+	}
+	return codes
+}
+
+func TestNewDoctorCmd_Subset(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		stdin     string
+		wantCodes []string
+		wantErr   bool
+	}{
+		{
+			name:      "whole project without a subset",
+			wantCodes: []string{"AUD004 " + doctorTestNodeUUID2 + ".md", "AUD002 " + doctorTestOrphanUUID + ".md", "AUD008 .prosemark.yml"},
+			wantErr:   true,
+		},
+		{
+			name:      "clean file argument reports nothing",
+			args:      []string{"/work/novel/" + doctorTestNodeUUID + ".md"},
+			wantCodes: []string{},
+		},
+		{
+			name:      "relative argument resolves against the working directory",
+			args:      []string{"novel/" + doctorTestNodeUUID2 + ".md"},
+			wantCodes: []string{"AUD004 " + doctorTestNodeUUID2 + ".md"},
+			wantErr:   true,
+		},
+		{
+			name:      "stdin list keeps orphan context",
+			args:      []string{"--stdin-list"},
+			stdin:     "\nnovel/" + doctorTestOrphanUUID + ".md\n  novel/.prosemark.yml  \n",
+			wantCodes: []string{"AUD002 " + doctorTestOrphanUUID + ".md", "AUD008 .prosemark.yml"},
+			wantErr:   true,
+		},
+		{
+			name:      "stdin list combines with arguments",
+			args:      []string{"--stdin-list", "novel/" + doctorTestNodeUUID2 + ".md"},
+			stdin:     "novel/" + doctorTestOrphanUUID + ".md\n",
+			wantCodes: []string{"AUD004 " + doctorTestNodeUUID2 + ".md", "AUD002 " + doctorTestOrphanUUID + ".md"},
+			wantErr:   true,
+		},
+		{
+			name:      "paths outside the project are ignored",
+			args:      []string{"README.md", "/elsewhere/" + doctorTestNodeUUID2 + ".md", "novel/../" + doctorTestNodeUUID2 + ".md"},
+			wantCodes: []string{},
+		},
+		{
+			name:      "empty stdin list audits nothing",
+			args:      []string{"--stdin-list"},
+			wantCodes: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags, err := runDoctorSubset(t, subsetDoctorIO(), tt.stdin, tt.args...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := strings.Join(doctorCodes(diags), ", "); got != strings.Join(tt.wantCodes, ", ") {
+				t.Errorf("diagnostics = [%s], want [%s]", got, strings.Join(tt.wantCodes, ", "))
+			}
+		})
+	}
+}
+
+func TestNewDoctorCmd_SubsetReadsOnlyListedNodes(t *testing.T) {
+	mock := subsetDoctorIO()
+	if _, err := runDoctorSubset(t, mock, "", "novel/"+doctorTestNodeUUID+".md"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	for _, p := range mock.readFileCalls {
+		if filepath.Base(p) == doctorTestNodeUUID2+".md" {
+			t.Errorf("read unlisted node file %s", p)
+		}
+	}
+}
+
+func TestNewDoctorCmd_SubsetErrors(t *testing.T) {
+	t.Run("stdin read failure", func(t *testing.T) {
+		c := newDoctorCmdWithGetCWD(subsetDoctorIO(), func() (string, error) { return "/work", nil })
+		c.SetOut(new(bytes.Buffer))
+		c.SetErr(new(bytes.Buffer))
+		c.SetIn(iotest.ErrReader(errors.New("boom")))
+		c.SetArgs([]string{"--project", "/work/novel", "--stdin-list"})
+		err := c.Execute()
+		if err == nil || !strings.Contains(err.Error(), "reading path list from stdin") {
+			t.Errorf("Execute() error = %v, want stdin read error", err)
+		}
+	})
+
+	for _, tc := range []struct {
+		name    string
+		project string
+	}{
+		{"relative project", "novel"},
+		{"relative path argument", "/work/novel"},
+	} {
+		t.Run("getwd failure with "+tc.name, func(t *testing.T) {
+			c := newDoctorCmdWithGetCWD(subsetDoctorIO(), func() (string, error) { return "", errors.New("getwd failed") })
+			c.SetOut(new(bytes.Buffer))
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs([]string{"--project", tc.project, doctorTestNodeUUID + ".md"})
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+				t.Errorf("Execute() error = %v, want getwd error", err)
+			}
+		})
+	}
+}
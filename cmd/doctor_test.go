@@ -2,12 +2,16 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/node"
 )
 
 // ─── Test double ────────────────────────────────────────────────────────────
@@ -21,6 +25,11 @@ type mockDoctorIO struct {
 	// nodeFiles maps filepath.Base(path) → nodeFileEntry; missing key returns (nil, false, nil).
 	nodeFiles     map[string]nodeFileEntry
 	readFileCalls []string // tracks every ReadNodeFile call path
+	// reports maps WriteReport path → data; reportErr is returned from WriteReport.
+	reports   map[string][]byte
+	reportErr error
+	// project is returned by ScanProject; nil means a project with no files.
+	project *binder.Project
 }
 
 // nodeFileEntry holds the mock response for a single node file.
@@ -34,10 +43,17 @@ func (m *mockDoctorIO) ReadBinder(path string) ([]byte, error) {
 	return m.binderBytes, m.binderErr
 }
 
-func (m *mockDoctorIO) ListUUIDFiles(dir string) ([]string, error) {
+func (m *mockDoctorIO) ListNodeFiles(dir string, _ node.IDScheme) ([]string, error) {
 	return m.uuidFiles, m.uuidFilesErr
 }
 
+func (m *mockDoctorIO) ScanProject(_ context.Context, _ string) (*binder.Project, error) {
+	if m.project == nil {
+		return &binder.Project{Files: []string{}, BinderDir: "."}, nil
+	}
+	return m.project, nil
+}
+
 func (m *mockDoctorIO) ReadNodeFile(path string) ([]byte, bool, error) {
 	m.readFileCalls = append(m.readFileCalls, path)
 	if m.nodeFiles != nil {
@@ -49,6 +65,18 @@ func (m *mockDoctorIO) ReadNodeFile(path string) ([]byte, bool, error) {
 	return nil, false, nil
 }
 
+func (m *mockDoctorIO) WriteCache(_ string, _ []byte) error {
+	return nil
+}
+
+func (m *mockDoctorIO) WriteReport(path string, data []byte) error {
+	if m.reports == nil {
+		m.reports = make(map[string][]byte)
+	}
+	m.reports[path] = data
+	return m.reportErr
+}
+
 // ─── Test fixtures ──────────────────────────────────────────────────────────
 
 const (
@@ -704,6 +732,34 @@ func TestNewDoctorCmd_BinderParseWarnings(t *testing.T) {
 			wantErr:     false,
 			wantNoneOut: "BNDW001",
 		},
+		{
+			// BNDE002: root-escaping link is an error → reported, exit non-zero.
+			name: "BNDE002: root-escaping binder link is reported as error",
+			args: []string{"--project", "."},
+			binderBytes: []byte("<!-- prosemark-binder:v1 -->\n" +
+				"- [Node](" + doctorTestNodeUUID + ".md)\n" +
+				"- [Up](../up.md)\n"),
+			nodeFiles: map[string]nodeFileEntry{
+				doctorTestNodeUUID + ".md": {content: validDoctorNodeContent(doctorTestNodeUUID), exists: true},
+				".prosemark.yml":           {content: []byte("version: \"1\"\n"), exists: true},
+			},
+			wantErr:   true,
+			wantInErr: "BNDE002",
+		},
+		{
+			// BNDE001: illegal path characters are an error → reported, exit non-zero.
+			name: "BNDE001: illegal binder path characters are reported as error",
+			args: []string{"--project", "."},
+			binderBytes: []byte("<!-- prosemark-binder:v1 -->\n" +
+				"- [Node](" + doctorTestNodeUUID + ".md)\n" +
+				"- [Bad](bad|name.md)\n"),
+			nodeFiles: map[string]nodeFileEntry{
+				doctorTestNodeUUID + ".md": {content: validDoctorNodeContent(doctorTestNodeUUID), exists: true},
+				".prosemark.yml":           {content: []byte("version: \"1\"\n"), exists: true},
+			},
+			wantErr:   true,
+			wantInErr: "line 3:",
+		},
 	}
 
 	for _, tt := range tests {
@@ -842,3 +898,130 @@ func TestNewDoctorCmd_PlainText_StdoutEmptyWhenDiagnosticsPresent(t *testing.T)
 		t.Errorf("stdout = %q, want empty string (all plain-text doctor output should go to stderr)", out.String())
 	}
 }
+
+func TestNewDoctorCmd_FormatMarkdown(t *testing.T) {
+	mock := &mockDoctorIO{binderBytes: doctorBinderWithNode(doctorTestNodeUUID)}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantStdout bool
+	}{
+		{"stdout", []string{"--format", "markdown"}, true},
+		{"out file", []string{"--format", "markdown", "--out", "report.md"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewDoctorCmd(mock)
+			out := new(bytes.Buffer)
+			c.SetOut(out)
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(append([]string{"--project", "."}, tt.args...))
+
+			err := c.Execute()
+			if err == nil {
+				t.Fatal("expected integrity error for missing node file")
+			}
+
+			report := out.String()
+			if !tt.wantStdout {
+				if !strings.Contains(report, "Wrote doctor report to report.md") {
+					t.Errorf("stdout = %q, want confirmation", report)
+				}
+				report = string(mock.reports["report.md"])
+			}
+			for _, want := range []string{"# pmk doctor report", "## Errors", "**AUD001**", "## What the codes mean"} {
+				if !strings.Contains(report, want) {
+					t.Errorf("report missing %q:\n%s", want, report)
+				}
+			}
+		})
+	}
+}
+
+func TestNewDoctorCmd_OutWritesJSONAndText(t *testing.T) {
+	for _, format := range []string{"json", "text"} {
+		t.Run(format, func(t *testing.T) {
+			mock := &mockDoctorIO{binderBytes: doctorBinderWithNode(doctorTestNodeUUID)}
+			c := NewDoctorCmd(mock)
+			errOut := new(bytes.Buffer)
+			c.SetOut(new(bytes.Buffer))
+			c.SetErr(errOut)
+			c.SetArgs([]string{"--project", ".", "--format", format, "--out", "r.txt"})
+			_ = c.Execute()
+
+			if !strings.Contains(string(mock.reports["r.txt"]), "AUD001") {
+				t.Errorf("report = %q, want AUD001", mock.reports["r.txt"])
+			}
+			if strings.Contains(errOut.String(), "AUD001") {
+				t.Error("diagnostics must not also be printed when --out is set")
+			}
+		})
+	}
+}
+
+func TestNewDoctorCmd_FormatErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		mock    *mockDoctorIO
+		wantErr string
+	}{
+		{"unknown format", []string{"--format", "html"}, &mockDoctorIO{}, "unknown --format"},
+		{"json conflicts", []string{"--json", "--format", "markdown"}, &mockDoctorIO{}, "conflicts"},
+		{"write failure", []string{"--out", "r.md"}, &mockDoctorIO{binderBytes: doctorBinderWithNode(doctorTestNodeUUID), reportErr: errors.New("denied")}, "writing report"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewDoctorCmd(tt.mock)
+			c.SetOut(new(bytes.Buffer))
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(append([]string{"--project", "."}, tt.args...))
+			err := c.Execute()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewDoctorCmd_FormatGitHub(t *testing.T) {
+	mock := &mockDoctorIO{binderBytes: doctorBinderWithNode(doctorTestNodeUUID)}
+	c := newDoctorCmdWithGetCWD(mock, func() (string, error) { return "/repo", nil })
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--project", "/repo/book", "--format", "github"})
+
+	if err := c.Execute(); err == nil {
+		t.Fatal("expected integrity error for missing node file")
+	}
+	want := "::error file=book/" + doctorTestNodeUUID + ".md,title=AUD001::"
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("stdout = %q, want %q", out.String(), want)
+	}
+	if !strings.Contains(out.String(), "::error file=book/.prosemark.yml,title=AUD008::") {
+		t.Errorf("stdout = %q, want AUD008 annotation", out.String())
+	}
+}
+
+func TestRenderDoctorReport_Line(t *testing.T) {
+	diags := []node.AuditDiagnostic{
+		{Code: node.AUD011, Severity: node.SeverityWarning, Message: "line 4: link to gone.md", Path: "a.md", Line: 4},
+		{Code: node.AUD001, Severity: node.SeverityError, Message: "missing", Path: "b.md"},
+	}
+	github := string(renderDoctorReport(formatGitHub, "book", "book", diags))
+	if want := "::warning file=book/a.md,line=4,title=AUD011::line 4: link to gone.md\n::error file=book/b.md,title=AUD001::missing\n"; github != want {
+		t.Errorf("github report = %q, want %q", github, want)
+	}
+	var out doctorOutput
+	if err := json.Unmarshal(renderDoctorReport(doctorFormatJSON, "book", "book", diags), &out); err != nil {
+		t.Fatalf("json report: %v", err)
+	}
+	if out.Diagnostics[0].Line != 4 || out.Diagnostics[1].Line != 0 {
+		t.Errorf("json diagnostics = %+v, want line 4 on the first only", out.Diagnostics)
+	}
+	if yaml := string(renderDoctorReport(doctorFormatYAML, "book", "book", diags)); !strings.Contains(yaml, "line: 4") || strings.Count(yaml, "line:") != 1 {
+		t.Errorf("yaml report = %q, want line 4 on the first only", yaml)
+	}
+}
@@ -4,13 +4,15 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
 )
 
 // EditIO handles I/O for the edit command.
@@ -41,16 +43,18 @@ func newEditCmdWithGetCWD(io EditIO, getwd func() (string, error)) *cobra.Comman
 		RunE: func(cmd *cobra.Command, args []string) error {
 			nodeID := args[0]
 
-			editor := os.Getenv("EDITOR")
-			if len(strings.Fields(editor)) == 0 {
-				return fmt.Errorf("$EDITOR is not set")
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
 			}
 
-			project, _ := cmd.Flags().GetString("project")
-			binderPath, err := resolveBinderPath(project, getwd)
+			editor, err := fsio.EditorCommand(filepath.Dir(binderPath))
 			if err != nil {
 				return err
 			}
+			if len(strings.Fields(editor)) == 0 {
+				return errEditorNotSet
+			}
 
 			part, _ := cmd.Flags().GetString("part")
 			if part != "draft" && part != "notes" {
@@ -71,23 +75,42 @@ func newEditCmdWithGetCWD(io EditIO, getwd func() (string, error)) *cobra.Comman
 			}
 
 			targetFilename := nodeID + ".md"
-			if !findNodeInTree(parsed.Root, targetFilename) {
+			entry := findNodeByTarget(parsed.Root, targetFilename)
+			if entry == nil {
 				return fmt.Errorf("node %q not found in binder", nodeID)
 			}
 
 			binderDir := filepath.Dir(binderPath)
-			draftPath := filepath.Join(binderDir, nodeID+".md")
-			notesPath := filepath.Join(binderDir, nodeID+".notes.md")
+			draftPath, err := safepath.Resolve(binderDir, nodeID+".md")
+			if err != nil {
+				return err
+			}
+			notesPath, err := safepath.Resolve(binderDir, nodeID+".notes.md")
+			if err != nil {
+				return err
+			}
 
 			var editPath string
-			var notesCreated bool
+			var notesCreated, draftCreated bool
+
+			if create, _ := cmd.Flags().GetBool("create"); create {
+				draftCreated, err = createMissingNodeFile(io, draftPath, nodeID, entry.Title)
+				if err != nil {
+					return err
+				}
+			}
 
 			if part == "notes" {
 				editPath = notesPath
-				if _, readErr := io.ReadNodeFile(notesPath); readErr != nil {
-					if !errors.Is(readErr, os.ErrNotExist) {
-						return fmt.Errorf("reading notes file: %w", readErr)
+				content, readErr := io.ReadNodeFile(notesPath)
+				switch {
+				case readErr == nil:
+					if node.BodyLocked(content) {
+						return fmt.Errorf("notes for node %q are locked — run 'pmk unlock' before editing them", nodeID)
 					}
+				case !errors.Is(readErr, os.ErrNotExist):
+					return fmt.Errorf("reading notes file: %w", readErr)
+				default:
 					if createErr := io.CreateNotesFile(notesPath); createErr != nil {
 						return fmt.Errorf("creating notes file: %w", createErr)
 					}
@@ -95,16 +118,26 @@ func newEditCmdWithGetCWD(io EditIO, getwd func() (string, error)) *cobra.Comman
 				}
 			} else {
 				editPath = draftPath
-				if _, readErr := io.ReadNodeFile(draftPath); readErr != nil {
+				content, readErr := io.ReadNodeFile(draftPath)
+				if readErr != nil {
+					if errors.Is(readErr, os.ErrNotExist) {
+						return fmt.Errorf("reading node file: %w (use --create to create it)", readErr)
+					}
 					return fmt.Errorf("reading node file: %w", readErr)
 				}
+				if node.BodyLocked(content) {
+					return fmt.Errorf("node %q is locked — run 'pmk unlock' before editing it", nodeID)
+				}
 			}
 
 			if err := io.OpenEditor(editor, editPath); err != nil {
-				if notesCreated {
-					if deleter, ok := io.(editDeleter); ok {
+				if deleter, ok := io.(editDeleter); ok {
+					if notesCreated {
 						_ = deleter.DeleteFile(notesPath)
 					}
+					if draftCreated {
+						_ = deleter.DeleteFile(draftPath)
+					}
 				}
 				return fmt.Errorf("editor: %w", err)
 			}
@@ -115,96 +148,84 @@ func newEditCmdWithGetCWD(io EditIO, getwd func() (string, error)) *cobra.Comman
 				return err
 			}
 
+			if draftCreated {
+				fmt.Fprintln(cmd.OutOrStdout(), "Created "+sanitizePath(targetFilename))
+			}
 			return nil
 		},
 	}
 
-	cmd.Flags().String("project", "", "project directory containing _binder.md (default: current directory)")
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
 	cmd.Flags().String("part", "draft", "which part to edit: draft or notes")
+	cmd.Flags().Bool("create", false, "create the node file from its binder entry if it is missing")
 
 	return cmd
 }
 
-// findNodeInTree recursively searches the binder tree for a node with the given target filename.
-func findNodeInTree(n *binder.Node, target string) bool {
+// findNodeByTarget recursively searches the binder tree for the first node
+// with the given target filename, returning nil when there is none.
+func findNodeByTarget(n *binder.Node, target string) *binder.Node {
 	if n.Target == target {
-		return true
+		return n
 	}
 	for _, child := range n.Children {
-		if findNodeInTree(child, target) {
-			return true
+		if found := findNodeByTarget(child, target); found != nil {
+			return found
 		}
 	}
-	return false
+	return nil
+}
+
+// createMissingNodeFile writes a fresh node file at path, titled from its
+// binder entry, when no file exists there yet. It reports whether the file
+// was created.
+func createMissingNodeFile(io EditIO, path, id, title string) (bool, error) {
+	_, err := io.ReadNodeFile(path)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("reading node file: %w", err)
+	}
+	now := nowUTCFunc()
+	content := node.SerializeFrontmatter(node.Frontmatter{
+		ID:      id,
+		Title:   title,
+		Created: now,
+		Updated: now,
+	})
+	if err := io.WriteNodeFileAtomic(path, content); err != nil {
+		return false, fmt.Errorf("creating node file: %w", err)
+	}
+	return true, nil
 }
 
 // fileEditIO implements EditIO using OS file I/O.
-type fileEditIO struct{}
+type fileEditIO struct {
+	fileProjectIO
+}
 
 // ReadBinder reads the binder file at path.
 func (f fileEditIO) ReadBinder(path string) ([]byte, error) {
-	return f.ReadBinderImpl(path)
-}
-
-// ReadBinderImpl reads the binder file using os.ReadFile.
-func (f fileEditIO) ReadBinderImpl(path string) ([]byte, error) {
-	return os.ReadFile(path)
+	return fsio.ReadBinder(path)
 }
 
 // ReadNodeFile reads the node file at path.
 func (f fileEditIO) ReadNodeFile(path string) ([]byte, error) {
-	return f.ReadNodeFileImpl(path)
-}
-
-// ReadNodeFileImpl reads the node file using os.ReadFile.
-func (f fileEditIO) ReadNodeFileImpl(path string) ([]byte, error) {
-	return os.ReadFile(path)
+	return fsio.ReadFile(path)
 }
 
 // WriteNodeFileAtomic writes content to path atomically via a temp file.
 func (f fileEditIO) WriteNodeFileAtomic(path string, content []byte) error {
-	return f.WriteNodeFileAtomicImpl(path, content)
+	return fsio.WriteFileAtomic(path, ".node", content)
 }
 
-// WriteNodeFileAtomicImpl performs the atomic write via OS temp file rename.
-func (f fileEditIO) WriteNodeFileAtomicImpl(path string, content []byte) error {
-	return writeFileAtomicDirectImpl(path, ".node", content)
-}
-
-// CreateNotesFile creates a new empty notes file at path using O_CREATE|O_EXCL.
+// CreateNotesFile creates a new empty notes file at path, failing if it exists.
 func (f fileEditIO) CreateNotesFile(path string) error {
-	return f.CreateNotesFileImpl(path)
-}
-
-// CreateNotesFileImpl creates the notes file using os.OpenFile with O_CREATE|O_EXCL.
-func (f fileEditIO) CreateNotesFileImpl(path string) error {
-	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
-	if err != nil {
-		return err
-	}
-	return file.Close()
+	return fsio.CreateExclusive(path)
 }
 
 // OpenEditor opens the file at path in the named editor.
 func (f fileEditIO) OpenEditor(editor, path string) error {
-	return f.OpenEditorImpl(editor, path)
-}
-
-// OpenEditorImpl launches the editor process, splitting $EDITOR with strings.Fields.
-func (f fileEditIO) OpenEditorImpl(editor, path string) error {
-	return openEditorImpl(editor, path)
-}
-
-// openEditorImpl launches the editor process, splitting editor on whitespace.
-// The first token is the executable; remaining tokens are prepended to path as args.
-func openEditorImpl(editor, path string) error {
-	parts := strings.Fields(editor)
-	if len(parts) == 0 {
-		return fmt.Errorf("EDITOR is empty")
-	}
-	c := exec.Command(parts[0], append(parts[1:], path)...)
-	c.Stdin = os.Stdin
-	c.Stdout = os.Stdout
-	c.Stderr = os.Stderr
-	return c.Run()
+	return fsio.OpenEditor(editor, path)
 }
@@ -107,7 +107,7 @@ func TestFileEditIO_OpenEditor(t *testing.T) {
 // "true" is the executable and "--extra-arg" is an argument, not part of the
 // binary name. The original buggy addchild.go implementation called
 // exec.Command(editor, path) which would fail for multi-word editors; this
-// test ensures edit.go's shared openEditorImpl path has equivalent behaviour.
+// test ensures edit.go's path through the shared fsio.OpenEditor has equivalent behaviour.
 func TestFileEditIO_OpenEditor_MultiWordEditor(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "test.md")
@@ -124,11 +124,11 @@ func TestFileEditIO_OpenEditor_MultiWordEditor(t *testing.T) {
 	}
 }
 
-// TestFileEditIO_OpenEditorImpl_WhitespaceOnlyReturnsError verifies that the
-// shared openEditorImpl safety net rejects a whitespace-only editor string.
+// TestFileEditIO_OpenEditor_WhitespaceOnlyReturnsError verifies that the
+// shared fsio.OpenEditor safety net rejects a whitespace-only editor string.
 // This is the inner guard: len(strings.Fields("   ")) == 0 → error.
-// Symmetric with TestFileAddChildIO_OpenEditorImpl_WhitespaceOnlyReturnsError.
-func TestFileEditIO_OpenEditorImpl_WhitespaceOnlyReturnsError(t *testing.T) {
+// Symmetric with TestFileAddChildIO_OpenEditor_WhitespaceOnlyReturnsError.
+func TestFileEditIO_OpenEditor_WhitespaceOnlyReturnsError(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "test.md")
 	if err := os.WriteFile(path, []byte("draft"), 0600); err != nil {
@@ -136,8 +136,8 @@ func TestFileEditIO_OpenEditorImpl_WhitespaceOnlyReturnsError(t *testing.T) {
 	}
 
 	fio := fileEditIO{}
-	if err := fio.OpenEditorImpl("   ", path); err == nil {
-		t.Fatal("expected error when OpenEditorImpl called with whitespace-only editor, got nil")
+	if err := fio.OpenEditor("   ", path); err == nil {
+		t.Fatal("expected error when OpenEditor called with whitespace-only editor, got nil")
 	}
 }
 
@@ -235,3 +235,18 @@ func TestNewEditCmd_PostEditDraftReadError(t *testing.T) {
 		t.Error("expected WriteNodeFileAtomic NOT called when post-edit read fails")
 	}
 }
+
+func TestFileEditIO_DeleteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.md")
+	if err := os.WriteFile(path, []byte("draft"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fio := fileEditIO{}
+	if err := fio.DeleteFile(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("file still exists after DeleteFile: %v", err)
+	}
+}
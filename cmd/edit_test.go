@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/eykd/prosemark-go/internal/safepath"
 )
 
 // mockEditIO is a test double for EditIO.
@@ -114,7 +116,7 @@ func validEditNodeContent() []byte {
 
 func TestNewEditCmd_HasRequiredFlags(t *testing.T) {
 	c := NewEditCmd(nil)
-	for _, name := range []string{"project", "part"} {
+	for _, name := range []string{"project", "part", "create"} {
 		t.Run(name, func(t *testing.T) {
 			if c.Flags().Lookup(name) == nil {
 				t.Errorf("expected --%s flag on edit command", name)
@@ -447,8 +449,8 @@ func TestNewEditCmd_NotesRollbackOnEditorNonZero(t *testing.T) {
 }
 
 // TestNewEditCmd_EditorShellSplit verifies that $EDITOR is shell-split before
-// exec: "code --wait" passes the full value to OpenEditor (the Impl is
-// responsible for splitting with strings.Fields).
+// exec: "code --wait" passes the full value to OpenEditor (fsio.OpenEditor
+// is responsible for splitting with strings.Fields).
 func TestNewEditCmd_EditorShellSplit(t *testing.T) {
 	t.Setenv("EDITOR", "code --wait")
 	mock := &mockEditIO{
@@ -467,7 +469,7 @@ func TestNewEditCmd_EditorShellSplit(t *testing.T) {
 		t.Fatal("expected OpenEditor to be called")
 	}
 	// The implementation passes the full $EDITOR string to OpenEditor; the
-	// OpenEditorImpl Impl performs the shell split with strings.Fields.
+	// fsio.OpenEditor performs the shell split with strings.Fields.
 	gotEditor := mock.editorCalls[0][0]
 	if gotEditor != "code --wait" {
 		t.Errorf("OpenEditor called with editor=%q, want %q", gotEditor, "code --wait")
@@ -573,3 +575,163 @@ func TestNewRootCmd_RegistersEditSubcommand(t *testing.T) {
 // TestFileEditIO_ImplementsEditIO is a compile-time assertion that fileEditIO
 // satisfies the EditIO interface.
 var _ EditIO = (*fileEditIO)(nil)
+
+// ─── --create ────────────────────────────────────────────────────────────────
+
+// mockEditIOCreating records written node files so a file created by
+// --create can be re-read after the editor exits.
+type mockEditIOCreating struct {
+	mockEditIOWithDelete
+}
+
+func (m *mockEditIOCreating) WriteNodeFileAtomic(path string, content []byte) error {
+	m.nodeFiles[filepath.Base(path)] = content
+	return m.mockEditIO.WriteNodeFileAtomic(path, content)
+}
+
+func TestNewEditCmd_CreateMaterializesMissingNode(t *testing.T) {
+	t.Setenv("EDITOR", "vi")
+	orig := nowUTCFunc
+	nowUTCFunc = func() string { return "2026-02-02T00:00:00Z" }
+	t.Cleanup(func() { nowUTCFunc = orig })
+
+	mock := &mockEditIOCreating{mockEditIOWithDelete{mockEditIO: mockEditIO{
+		binderBytes: editBinderWithNode(),
+		nodeFiles:   map[string][]byte{},
+	}}}
+	c := NewEditCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{editTestNodeUUID, "--create", "--project", "."})
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "---\nid: " + editTestNodeUUID + "\ntitle: Chapter One\n" +
+		"created: 2026-02-02T00:00:00Z\nupdated: 2026-02-02T00:00:00Z\n---\n"
+	if got := string(mock.nodeFiles[editTestNodeUUID+".md"]); got != want {
+		t.Errorf("node file = %q, want %q", got, want)
+	}
+	if len(mock.editorCalls) != 1 || !strings.HasSuffix(mock.editorCalls[0][1], editTestNodeUUID+".md") {
+		t.Errorf("editor calls = %v, want one call on the new node file", mock.editorCalls)
+	}
+	if !strings.Contains(out.String(), "Created "+editTestNodeUUID+".md") {
+		t.Errorf("stdout = %q", out.String())
+	}
+}
+
+func TestNewEditCmd_CreateLeavesExistingNodeAlone(t *testing.T) {
+	t.Setenv("EDITOR", "vi")
+	mock := &mockEditIOCreating{mockEditIOWithDelete{mockEditIO: mockEditIO{
+		binderBytes: editBinderWithNode(),
+		nodeFiles:   map[string][]byte{editTestNodeUUID + ".md": validEditNodeContent()},
+	}}}
+	c := NewEditCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{editTestNodeUUID, "--create", "--project", "."})
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(mock.nodeFiles[editTestNodeUUID+".md"]), "Body text.") {
+		t.Error("existing body must be preserved")
+	}
+	if out.Len() != 0 {
+		t.Errorf("stdout = %q, want nothing when no file was created", out.String())
+	}
+}
+
+func TestNewEditCmd_CreateRollsBackOnEditorFailure(t *testing.T) {
+	t.Setenv("EDITOR", "vi")
+	mock := &mockEditIOCreating{mockEditIOWithDelete{mockEditIO: mockEditIO{
+		binderBytes: editBinderWithNode(),
+		nodeFiles:   map[string][]byte{},
+		editorErr:   errors.New("editor crashed"),
+	}}}
+	c := NewEditCmd(mock)
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{editTestNodeUUID, "--create", "--project", "."})
+
+	if err := c.Execute(); err == nil {
+		t.Fatal("expected editor error")
+	}
+	if !strings.HasSuffix(mock.deletedPath, editTestNodeUUID+".md") {
+		t.Errorf("deleted path = %q, want the created node file", mock.deletedPath)
+	}
+}
+
+func TestNewEditCmd_CreateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    *mockEditIO
+		wantErr string
+	}{
+		{
+			name:    "unreadable node file",
+			mock:    &mockEditIO{binderBytes: editBinderWithNode(), nodeFiles: map[string][]byte{}, nodeFileErr: errors.New("permission denied")},
+			wantErr: "reading node file: permission denied",
+		},
+		{
+			name:    "write failure",
+			mock:    &mockEditIO{binderBytes: editBinderWithNode(), nodeFiles: map[string][]byte{}, writeErr: errors.New("disk full")},
+			wantErr: "creating node file: disk full",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("EDITOR", "vi")
+			c := NewEditCmd(tt.mock)
+			c.SetOut(new(bytes.Buffer))
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs([]string{editTestNodeUUID, "--create", "--project", "."})
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+			if len(tt.mock.editorCalls) != 0 {
+				t.Error("editor must not open when --create fails")
+			}
+		})
+	}
+}
+
+func TestNewEditCmd_SymlinkEscapes(t *testing.T) {
+	outside := filepath.Join(t.TempDir(), "outside.md")
+	for _, name := range []string{editTestNodeUUID + ".md", editTestNodeUUID + ".notes.md"} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.Symlink(outside, filepath.Join(dir, name)); err != nil {
+				t.Skipf("symlinks unavailable: %v", err)
+			}
+			t.Setenv("EDITOR", "vi")
+			mock := &mockEditIO{binderBytes: editBinderWithNode(), nodeFileBytes: validEditNodeContent()}
+			c := NewEditCmd(mock)
+			c.SetOut(new(bytes.Buffer))
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs([]string{editTestNodeUUID, "--project", dir})
+			if err := c.Execute(); !errors.Is(err, safepath.ErrTraversal) {
+				t.Errorf("error = %v, want ErrTraversal", err)
+			}
+			if len(mock.editorCalls) != 0 {
+				t.Error("editor must not open on an escaping node file")
+			}
+		})
+	}
+}
+
+func TestNewEditCmd_MissingNodeSuggestsCreate(t *testing.T) {
+	t.Setenv("EDITOR", "vi")
+	mock := &mockEditIO{binderBytes: editBinderWithNode(), nodeFiles: map[string][]byte{}}
+	c := NewEditCmd(mock)
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{editTestNodeUUID, "--project", "."})
+
+	err := c.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--create") {
+		t.Errorf("error = %v, want a hint to use --create", err)
+	}
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// ExportIO handles I/O for the export command.
+type ExportIO interface {
+	ReadBinder(ctx context.Context, path string) ([]byte, error)
+	ReadNodeFile(path string) ([]byte, error)
+	// WriteExportFile writes one exported chunk, creating parent directories.
+	WriteExportFile(path string, data []byte) error
+	// ReadExportManifest returns the raw export manifest for projectDir, or
+	// nil when there is none.
+	ReadExportManifest(projectDir string) ([]byte, error)
+	// WriteExportManifest replaces the export manifest for projectDir.
+	WriteExportManifest(projectDir string, data []byte) error
+	// ReadSlugMap returns the raw slug map for projectDir, or nil when there
+	// is none.
+	ReadSlugMap(projectDir string) ([]byte, error)
+	// RenderPDF converts the Markdown document markdown to a PDF at path,
+	// titled title, creating parent directories.
+	RenderPDF(path string, markdown []byte, title string) error
+}
+
+// Export formats accepted by --format.
+const (
+	exportFormatText = "text"
+	exportFormatSSML = "ssml"
+)
+
+// defaultExportChunkSize keeps each chunk under the request limits of common
+// TTS services.
+const defaultExportChunkSize = 3000
+
+// NewExportCmd creates the export subcommand.
+func NewExportCmd(io ExportIO) *cobra.Command {
+	return newExportCmdWithGetCWD(io, os.Getwd)
+}
+
+func newExportCmdWithGetCWD(io ExportIO, getwd func() (string, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the manuscript in binder order as read-aloud text or SSML chunks",
+		Long: "Export the manuscript in binder order for text-to-speech pipelines.\n\n" +
+			"Frontmatter, review comments, code blocks, and Markdown markup are dropped.\n" +
+			"Output is split into chunks of at most --chunk-size characters; every node\n" +
+			"starts a new chunk, sentences are never split, and each chunk opens with a\n" +
+			"scene marker naming its node. With --out, chunks are written as numbered\n" +
+			"files (0001.txt or 0001.ssml, ...) in that directory and recorded in\n" +
+			exportManifestFilename + " for pmk clean-exports.\n\n" +
+			"--split-by chapter instead writes one Markdown (--format md, the default)\n" +
+			"or PDF (--format pdf, made with pandoc) file per chapter to --out, named\n" +
+			"by number and slug (01-the-flood.md, ...), with a " + chapterManifestFilename + "\n" +
+			"listing them. Chapters are the entries at --chapter-depth, and shallower\n" +
+			"entries without children; each holds its subtree's prose, as pmk compile\n" +
+			"would assemble it.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			format, _ := cmd.Flags().GetString("format")
+			chunkSize, _ := cmd.Flags().GetInt("chunk-size")
+			outDir, _ := cmd.Flags().GetString("out")
+			splitBy, _ := cmd.Flags().GetString("split-by")
+			chapterDepth, _ := cmd.Flags().GetInt("chapter-depth")
+			switch {
+			case splitBy != "" && splitBy != exportSplitChapter:
+				return fmt.Errorf("unknown --split-by %q: want chapter", splitBy)
+			case splitBy != "":
+				if !cmd.Flags().Changed("format") {
+					format = exportFormatMarkdown
+				}
+				if format != exportFormatMarkdown && format != exportFormatPDF {
+					return fmt.Errorf("unknown --format %q with --split-by: want md or pdf", format)
+				}
+				if outDir == "" {
+					return fmt.Errorf("--split-by needs --out")
+				}
+				if chapterDepth < 1 {
+					return fmt.Errorf("--chapter-depth must be at least 1")
+				}
+			case format == exportFormatMarkdown || format == exportFormatPDF:
+				return fmt.Errorf("--format %s needs --split-by chapter", format)
+			case format != exportFormatText && format != exportFormatSSML:
+				return fmt.Errorf("unknown --format %q: want text or ssml", format)
+			}
+
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			binderBytes, err := io.ReadBinder(cmd.Context(), binderPath)
+			if err != nil {
+				return fmt.Errorf("reading binder: %w", err)
+			}
+			result, _, err := binder.Parse(cmd.Context(), binderBytes, nil)
+			if err != nil {
+				return fmt.Errorf("cannot parse binder: %w", err)
+			}
+
+			binderDir := filepath.Dir(binderPath)
+			if splitBy != "" {
+				return exportChapters(cmd, io, binderDir, outDir, format, chapterDepth, getwd, result.Root)
+			}
+			var (
+				manifest           exportManifest
+				projectAbs, outAbs string
+			)
+			if outDir != "" {
+				if projectAbs, err = absFromCWD(binderDir, getwd); err != nil {
+					return err
+				}
+				if outAbs, err = absFromCWD(outDir, getwd); err != nil {
+					return err
+				}
+				if manifest, err = loadExportManifest(io, binderDir); err != nil {
+					return err
+				}
+			}
+
+			var sections []node.ReadAloudSection
+			var diags []binder.Diagnostic
+			for _, n := range binderTargetNodes(result.Root) {
+				nodePath, err := safepath.Resolve(binderDir, n.Target)
+				if err != nil {
+					diags = append(diags, safepath.Diagnostic(n.Target))
+					continue
+				}
+				content, err := io.ReadNodeFile(nodePath)
+				if errors.Is(err, node.ErrLockedBody) {
+					return fmt.Errorf("%s: %w", n.Target, err)
+				}
+				if err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "warning: skipping unreadable node file %s\n", sanitizePath(n.Target))
+					continue
+				}
+				sections = append(sections, node.NewReadAloudSection(exportTitle(n), content))
+			}
+			printDiagnostics(cmd, diags)
+
+			chunks := node.ChunkReadAloud(sections, chunkSize)
+			var written []string
+			for i, c := range chunks {
+				data := c.RenderText()
+				if format == exportFormatSSML {
+					data = c.RenderSSML()
+				}
+				if outDir == "" {
+					if i > 0 && format == exportFormatText {
+						fmt.Fprintln(cmd.OutOrStdout())
+					}
+					if _, err := cmd.OutOrStdout().Write(data); err != nil {
+						return fmt.Errorf("writing output: %w", err)
+					}
+					continue
+				}
+				base := fmt.Sprintf("%04d.%s", i+1, exportExtension(format))
+				name := filepath.Join(outDir, base)
+				if err := io.WriteExportFile(name, data); err != nil {
+					// Record the chunks already written so clean-exports still finds them.
+					addExportManifestFiles(&manifest, projectAbs, written)
+					return errors.Join(fmt.Errorf("writing %s: %w", sanitizePath(name), err), writeExportManifest(io, binderDir, manifest))
+				}
+				written = append(written, filepath.Join(outAbs, base))
+			}
+			if outDir != "" {
+				addExportManifestFiles(&manifest, projectAbs, written)
+				if err := writeExportManifest(io, binderDir, manifest); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Exported %d chunk(s) to %s\n", len(chunks), sanitizePath(outDir))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().String("format", exportFormatText, "output format: text or ssml, or with --split-by, md or pdf")
+	cmd.Flags().Int("chunk-size", defaultExportChunkSize, "maximum characters of spoken text per chunk (0 = one chunk per node)")
+	cmd.Flags().String("out", "", "directory to write numbered chunk files to (default: stdout)")
+	cmd.Flags().String("split-by", "", "write one file per chapter instead of read-aloud chunks: chapter")
+	cmd.Flags().Int("chapter-depth", 1, "with --split-by chapter, the binder depth of chapters")
+	return cmd
+}
+
+// exportTitle returns the scene title for n, falling back to its filename stem.
+func exportTitle(n *binder.Node) string {
+	if n.Title != "" {
+		return n.Title
+	}
+	return strings.TrimSuffix(filepath.Base(n.Target), ".md")
+}
+
+// exportExtension returns the chunk file extension for format.
+func exportExtension(format string) string {
+	if format == exportFormatSSML {
+		return "ssml"
+	}
+	return "txt"
+}
+
+// fileExportIO implements ExportIO using OS file I/O.
+type fileExportIO struct {
+	fileProjectIO
+}
+
+// WriteExportFile creates the output directory and writes data atomically.
+func (f fileExportIO) WriteExportFile(path string, data []byte) error {
+	return fsio.WriteFileAtomicMkdir(path, ".export", data)
+}
+
+// ReadExportManifest reads the project's export manifest, returning nil when absent.
+func (f fileExportIO) ReadExportManifest(projectDir string) ([]byte, error) {
+	return readExportManifestFile(projectDir)
+}
+
+// WriteExportManifest writes the project's export manifest atomically.
+func (f fileExportIO) WriteExportManifest(projectDir string, data []byte) error {
+	return writeExportManifestFile(projectDir, data)
+}
+
+// ReadSlugMap reads the project's slug map, returning nil when absent.
+func (f fileExportIO) ReadSlugMap(projectDir string) ([]byte, error) {
+	return fileSlugsIO{}.ReadSlugMap(projectDir)
+}
+
+// RenderPDF converts markdown to a PDF at path with pandoc.
+func (f fileExportIO) RenderPDF(path string, markdown []byte, title string) error {
+	return fsio.RenderPDF(path, markdown, title)
+}
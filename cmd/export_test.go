@@ -0,0 +1,319 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// mockExportIO is a test double for ExportIO.
+type mockExportIO struct {
+	binderBytes []byte
+	binderErr   error
+	files       map[string]string
+	readErrs    map[string]error
+	writeErr    error
+	written     map[string]string
+	writeOrder  []string
+
+	manifest         []byte
+	manifestErr      error
+	manifestWriteErr error
+	manifestWritten  []byte
+
+	pdfErr error
+	pdfs   map[string]string // path to title
+
+	slugMap    []byte
+	slugMapErr error
+}
+
+func (m *mockExportIO) ReadBinder(_ context.Context, _ string) ([]byte, error) {
+	return m.binderBytes, m.binderErr
+}
+
+func (m *mockExportIO) ReadNodeFile(path string) ([]byte, error) {
+	if err := m.readErrs[filepath.Base(path)]; err != nil {
+		return nil, err
+	}
+	for name, content := range m.files {
+		if strings.HasSuffix(path, "/"+name) {
+			return []byte(content), nil
+		}
+	}
+	return nil, os.ErrNotExist
+}
+
+func (m *mockExportIO) WriteExportFile(path string, data []byte) error {
+	if m.written == nil {
+		m.written = make(map[string]string)
+	}
+	m.written[path] = string(data)
+	m.writeOrder = append(m.writeOrder, path)
+	return m.writeErr
+}
+
+func (m *mockExportIO) ReadExportManifest(_ string) ([]byte, error) {
+	return m.manifest, m.manifestErr
+}
+
+func (m *mockExportIO) WriteExportManifest(_ string, data []byte) error {
+	m.manifestWritten = data
+	return m.manifestWriteErr
+}
+
+func (m *mockExportIO) ReadSlugMap(_ string) ([]byte, error) {
+	return m.slugMap, m.slugMapErr
+}
+
+func (m *mockExportIO) RenderPDF(path string, _ []byte, title string) error {
+	if m.pdfErr != nil {
+		return m.pdfErr
+	}
+	if m.pdfs == nil {
+		m.pdfs = make(map[string]string)
+	}
+	m.pdfs[path] = title
+	return nil
+}
+
+func newExportMock() *mockExportIO {
+	return &mockExportIO{
+		binderBytes: []byte("<!-- prosemark-binder:v1 -->\n" +
+			"- [Opening](one.md)\n" +
+			"  - [Missing](missing.md)\n" +
+			"- [Closing](two.md)\n"),
+		files: map[string]string{
+			"one.md": "---\nid: one\ncreated: a\nupdated: b\n---\nFirst sentence here. Second sentence here.\n",
+			"two.md": "The *end*.\n",
+		},
+	}
+}
+
+func runExportCmd(t *testing.T, mock *mockExportIO, args ...string) (string, string, error) {
+	t.Helper()
+	c := NewExportCmd(mock)
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(errOut)
+	c.SetArgs(append(args, "--project", "/proj"))
+	err := c.Execute()
+	return out.String(), errOut.String(), err
+}
+
+func TestExportCmd_TextToStdout(t *testing.T) {
+	out, errOut, err := runExportCmd(t, newExportMock(), "--chunk-size", "25")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "[Scene: Opening]\n\nFirst sentence here.\n" +
+		"\n[Scene: Opening (continued)]\n\nSecond sentence here.\n" +
+		"\n[Scene: Closing]\n\nThe end.\n"
+	if out != want {
+		t.Errorf("stdout = %q, want %q", out, want)
+	}
+	if !strings.Contains(errOut, "skipping unreadable node file missing.md") {
+		t.Errorf("stderr = %q, want warning for missing.md", errOut)
+	}
+}
+
+func TestExportCmd_SSMLToOutDir(t *testing.T) {
+	mock := newExportMock()
+	out, _, err := runExportCmd(t, mock, "--format", "ssml", "--out", "audio")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(mock.writeOrder, ","); got != "audio/0001.ssml,audio/0002.ssml" {
+		t.Errorf("written files = %s", got)
+	}
+	if !strings.Contains(mock.written["audio/0002.ssml"], "<s>The end.</s>") {
+		t.Errorf("0002.ssml = %q", mock.written["audio/0002.ssml"])
+	}
+	if !strings.Contains(out, "Exported 2 chunk(s) to audio") {
+		t.Errorf("stdout = %q", out)
+	}
+}
+
+func TestExportCmd_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		mock *mockExportIO
+		args []string
+	}{
+		{"unknown format", newExportMock(), []string{"--format", "mp3"}},
+		{"binder read error", &mockExportIO{binderErr: errors.New("boom")}, nil},
+		{"write error", func() *mockExportIO { m := newExportMock(); m.writeErr = errors.New("disk full"); return m }(), []string{"--out", "audio"}},
+		{"invalid binder", &mockExportIO{binderBytes: []byte("- [Bad](b\xff.md)\n")}, nil},
+		{"manifest read error", &mockExportIO{manifestErr: errors.New("denied")}, []string{"--out", "audio"}},
+		{"malformed manifest", &mockExportIO{manifest: []byte("{")}, []string{"--out", "audio"}},
+		{"manifest write error", func() *mockExportIO { m := newExportMock(); m.manifestWriteErr = errors.New("disk full"); return m }(), []string{"--out", "audio"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := runExportCmd(t, tt.mock, tt.args...); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestExportCmd_RecordsManifest(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want string
+	}{
+		{"inside project", "audio", `["audio/0001.txt","audio/0002.txt","old/0001.txt"]`},
+		{"outside project", "/tmp/audio", `["/tmp/audio/0001.txt","/tmp/audio/0002.txt","audio/0001.txt","old/0001.txt"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newExportMock()
+			mock.manifest = []byte(`{"version":"1","files":["old/0001.txt","audio/0001.txt"]}`)
+			c := newExportCmdWithGetCWD(mock, func() (string, error) { return "/proj", nil })
+			c.SetOut(new(bytes.Buffer))
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs([]string{"--out", tt.out})
+			if err := c.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got exportManifest
+			if err := json.Unmarshal(mock.manifestWritten, &got); err != nil {
+				t.Fatalf("manifest %q: %v", mock.manifestWritten, err)
+			}
+			if files, _ := json.Marshal(got.Files); string(files) != tt.want || got.Version != "1" {
+				t.Errorf("manifest = %s", mock.manifestWritten)
+			}
+		})
+	}
+}
+
+func TestExportCmd_WriteErrorRecordsManifest(t *testing.T) {
+	mock := newExportMock()
+	mock.writeErr = errors.New("disk full")
+	mock.manifestWriteErr = errors.New("read-only")
+	_, _, err := runExportCmd(t, mock, "--out", "audio")
+	if err == nil || !strings.Contains(err.Error(), "disk full") || !strings.Contains(err.Error(), "writing export manifest: read-only") {
+		t.Errorf("error = %v, want chunk and manifest write errors", err)
+	}
+	if mock.manifestWritten == nil {
+		t.Error("manifest not written after chunk write error")
+	}
+}
+
+func TestExportCmd_OutDirGetwdError(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"relative project", []string{"--project", "proj", "--out", "/out"}},
+		{"relative out", []string{"--project", "/proj", "--out", "out"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newExportCmdWithGetCWD(newExportMock(), func() (string, error) {
+				return "", errors.New("getwd failed")
+			})
+			c.SetOut(new(bytes.Buffer))
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(tt.args)
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+				t.Errorf("error = %v, want getwd failure", err)
+			}
+		})
+	}
+}
+
+func TestExportCmd_UntitledNodeUsesFilename(t *testing.T) {
+	mock := &mockExportIO{
+		binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [][c9]\n\n[c9]: chapter-9.md\n"),
+		files:       map[string]string{"chapter-9.md": "Words.\n"},
+	}
+	out, _, err := runExportCmd(t, mock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out, "[Scene: chapter-9]") {
+		t.Errorf("stdout = %q, want filename-derived title", out)
+	}
+}
+
+func TestExportCmd_EscapingTargetSkipped(t *testing.T) {
+	mock := newExportMock()
+	mock.binderBytes = append(mock.binderBytes, "- [Outside](a/../../outside.md)\n"...)
+	mock.files["outside.md"] = "Not ours.\n"
+	out, errOut, err := runExportCmd(t, mock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(out, "Not ours") {
+		t.Errorf("stdout = %q, exported a file outside the project", out)
+	}
+	if !strings.Contains(errOut, binder.CodePathEscapesRoot) || !strings.Contains(errOut, "a/../../outside.md") {
+		t.Errorf("stderr = %q, want %s for the escaping target", errOut, binder.CodePathEscapesRoot)
+	}
+}
+
+func TestExportCmd_StdoutWriteError(t *testing.T) {
+	c := NewExportCmd(newExportMock())
+	c.SetOut(&errWriter{err: errors.New("closed")})
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--project", "/proj"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "writing output") {
+		t.Errorf("error = %v, want writing output error", err)
+	}
+}
+
+func TestExportCmd_GetwdError(t *testing.T) {
+	c := newExportCmdWithGetCWD(newExportMock(), func() (string, error) {
+		return "", errors.New("getwd failed")
+	})
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+		t.Errorf("error = %v, want getwd failure", err)
+	}
+}
+
+func TestFileExportIO_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	if err := os.WriteFile(binderPath, newExportMock().binderBytes, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fio := fileExportIO{}
+	if _, err := fio.ReadBinder(context.Background(), binderPath); err != nil {
+		t.Errorf("ReadBinder: %v", err)
+	}
+	chunkPath := filepath.Join(dir, "audio", "0001.txt")
+	if err := fio.WriteExportFile(chunkPath, []byte("[Scene: A]\n")); err != nil {
+		t.Fatalf("WriteExportFile: %v", err)
+	}
+	if got, err := fio.ReadNodeFile(chunkPath); err != nil || string(got) != "[Scene: A]\n" {
+		t.Errorf("ReadNodeFile = %q, %v", got, err)
+	}
+	if got, err := fio.ReadExportManifest(dir); err != nil || got != nil {
+		t.Errorf("ReadExportManifest before write = %q, %v", got, err)
+	}
+	if err := fio.WriteExportManifest(dir, []byte("{}\n")); err != nil {
+		t.Fatalf("WriteExportManifest: %v", err)
+	}
+	if got, err := fio.ReadExportManifest(dir); err != nil || string(got) != "{}\n" {
+		t.Errorf("ReadExportManifest = %q, %v", got, err)
+	}
+	if got, err := fio.ReadSlugMap(dir); err != nil || got != nil {
+		t.Errorf("ReadSlugMap without a slug map = %q, %v", got, err)
+	}
+	t.Setenv("PATH", t.TempDir())
+	if err := fio.RenderPDF(filepath.Join(dir, "a.pdf"), nil, "A"); err == nil || !strings.Contains(err.Error(), "pandoc") {
+		t.Errorf("RenderPDF without pandoc: err = %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/stats"
+)
+
+// Per-chapter export formats accepted by --format with --split-by chapter.
+const (
+	exportFormatMarkdown = "md"
+	exportFormatPDF      = "pdf"
+)
+
+// exportSplitChapter is the only --split-by unit.
+const exportSplitChapter = "chapter"
+
+// chapterManifestFilename is the manifest written beside per-chapter files.
+const chapterManifestFilename = "manifest.json"
+
+// chapterManifest describes the files of a per-chapter export.
+type chapterManifest struct {
+	Version  string               `json:"version"`
+	Format   string               `json:"format"`
+	Chapters []chapterManifestRow `json:"chapters"`
+}
+
+// chapterManifestRow is one chapter of a per-chapter export.
+type chapterManifestRow struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	// Target is the chapter entry's node file, empty for a placeholder.
+	Target string `json:"target,omitempty"`
+	File   string `json:"file"`
+	Words  int    `json:"words"`
+}
+
+// exportChapter is a chapter entry and the nodes whose prose it holds, in
+// binder order.
+type exportChapter struct {
+	node  *binder.Node
+	nodes []*binder.Node
+}
+
+// splitChapters divides the binder under root into chapters: the entries at
+// depth (1 for the top level), and shallower entries with no children. Each
+// chapter holds its subtree, preceded by the entries above the chapter depth
+// that lead to it and were not yet exported, such as a part's title page. A
+// target linked more than once is exported once, where it first appears.
+func splitChapters(root *binder.Node, depth int) []exportChapter {
+	var chapters []exportChapter
+	var pending []*binder.Node
+	seen := make(map[string]bool)
+	add := func(nodes []*binder.Node, n *binder.Node) []*binder.Node {
+		if n.Target != "" && !seen[n.Target] {
+			seen[n.Target] = true
+			nodes = append(nodes, n)
+		}
+		return nodes
+	}
+	var subtree func(nodes []*binder.Node, n *binder.Node) []*binder.Node
+	subtree = func(nodes []*binder.Node, n *binder.Node) []*binder.Node {
+		nodes = add(nodes, n)
+		for _, c := range n.Children {
+			nodes = subtree(nodes, c)
+		}
+		return nodes
+	}
+	var walk func(n *binder.Node, level int)
+	walk = func(n *binder.Node, level int) {
+		for _, c := range n.Children {
+			if level == depth || len(c.Children) == 0 {
+				chapters = append(chapters, exportChapter{node: c, nodes: subtree(pending, c)})
+				pending = nil
+				continue
+			}
+			pending = add(pending, c)
+			walk(c, level+1)
+		}
+	}
+	walk(root, 1)
+	return chapters
+}
+
+// exportChapters writes one file per chapter of the binder under root to
+// outDir, in format, with a manifest, and records them for clean-exports.
+// Files are named by the chapter's slug as pmk slugs assigns it, so they keep
+// their names when a chapter is retitled; a placeholder chapter's slug comes
+// from its title.
+func exportChapters(cmd *cobra.Command, io ExportIO, binderDir, outDir, format string, depth int, getwd func() (string, error), root *binder.Node) error {
+	chapters := splitChapters(root, depth)
+	if len(chapters) == 0 {
+		return fmt.Errorf("the binder has no entries to export")
+	}
+	projectAbs, err := absFromCWD(binderDir, getwd)
+	if err != nil {
+		return err
+	}
+	outAbs, err := absFromCWD(outDir, getwd)
+	if err != nil {
+		return err
+	}
+	manifest, err := loadExportManifest(io, binderDir)
+	if err != nil {
+		return err
+	}
+
+	width := max(len(fmt.Sprint(len(chapters))), 2)
+	listing := chapterManifest{Version: "1", Format: format, Chapters: []chapterManifestRow{}}
+	var written []string
+	// fail records the files already written so clean-exports still finds them.
+	fail := func(name string, err error) error {
+		addExportManifestFiles(&manifest, projectAbs, written)
+		return errors.Join(fmt.Errorf("writing %s: %w", sanitizePath(name), err), writeExportManifest(io, binderDir, manifest))
+	}
+	sep, err := compileSeparator(io, binderDir)
+	if err != nil {
+		return err
+	}
+	pinned, err := loadSlugMap(io, binderDir)
+	if err != nil {
+		return err
+	}
+	_, slugs, err := assignSlugs(io.ReadNodeFile, binderDir, root, pinned.Slugs)
+	if err != nil {
+		return err
+	}
+	for i, ch := range chapters {
+		markdown, _, err := assembleManuscript(cmd, io, binderDir, ch.nodes, sep)
+		if err != nil {
+			return err
+		}
+		title := ch.node.Title
+		slug := cmp.Or(slugs[ch.node.Target], node.Slugify(title), "chapter")
+		base := fmt.Sprintf("%0*d-%s.%s", width, i+1, slug, format)
+		name := filepath.Join(outDir, base)
+		if format == exportFormatPDF {
+			err = io.RenderPDF(name, markdown, title)
+		} else {
+			err = io.WriteExportFile(name, markdown)
+		}
+		if err != nil {
+			return fail(name, err)
+		}
+		written = append(written, filepath.Join(outAbs, base))
+		listing.Chapters = append(listing.Chapters, chapterManifestRow{
+			Number: i + 1,
+			Title:  title,
+			Target: ch.node.Target,
+			File:   base,
+			Words:  stats.CountNode(markdown).Words,
+		})
+	}
+
+	// Encoding strings and numbers cannot fail.
+	data, _ := json.MarshalIndent(listing, "", "  ")
+	name := filepath.Join(outDir, chapterManifestFilename)
+	if err := io.WriteExportFile(name, append(data, '\n')); err != nil {
+		return fail(name, err)
+	}
+	written = append(written, filepath.Join(outAbs, chapterManifestFilename))
+
+	addExportManifestFiles(&manifest, projectAbs, written)
+	if err := writeExportManifest(io, binderDir, manifest); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Exported %d chapter(s) to %s\n", len(chapters), sanitizePath(outDir))
+	return nil
+}
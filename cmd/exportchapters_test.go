@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// newChapterExportMock returns a binder with two parts, one with a title
+// page, and a closing chapter without children.
+func newChapterExportMock() *mockExportIO {
+	return &mockExportIO{
+		binderBytes: []byte("<!-- prosemark-binder:v1 -->\n" +
+			"- [Part One](part1.md)\n" +
+			"  - [The Flood](flood.md)\n" +
+			"    - [Rising](rising.md)\n" +
+			"  - [Aftermath](after.md)\n" +
+			"- [Part Two]()\n" +
+			"  - [Return](return.md)\n" +
+			"- [Epilogue](epilogue.md)\n"),
+		files: map[string]string{
+			"part1.md":    "---\ntitle: Part One\n---\n# Part One\n",
+			"flood.md":    "Water came.\n",
+			"rising.md":   "It rose higher.\n",
+			"after.md":    "Mud everywhere.\n",
+			"return.md":   "They came home.\n",
+			"epilogue.md": "The end.\n",
+		},
+	}
+}
+
+func runChapterExport(t *testing.T, mock *mockExportIO, args ...string) (string, error) {
+	t.Helper()
+	c := newExportCmdWithGetCWD(mock, func() (string, error) { return "/proj", nil })
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(append([]string{"--project", "/proj", "--split-by", "chapter", "--out", "beta"}, args...))
+	err := c.Execute()
+	return out.String(), err
+}
+
+func TestExportCmd_SplitByChapter(t *testing.T) {
+	mock := newChapterExportMock()
+	out, err := runChapterExport(t, mock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "Exported 3 chapter(s) to beta\n"; out != want {
+		t.Errorf("stdout = %q, want %q", out, want)
+	}
+	want := map[string]string{
+		"beta/01-part-one.md": "# Part One\n\nWater came.\n\nIt rose higher.\n\nMud everywhere.\n",
+		"beta/02-part-two.md": "They came home.\n",
+		"beta/03-epilogue.md": "The end.\n",
+	}
+	for name, content := range want {
+		if got := mock.written[name]; got != content {
+			t.Errorf("%s = %q, want %q", name, got, content)
+		}
+	}
+
+	var manifest chapterManifest
+	if err := json.Unmarshal([]byte(mock.written["beta/manifest.json"]), &manifest); err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	if manifest.Format != "md" || len(manifest.Chapters) != 3 {
+		t.Fatalf("manifest = %+v", manifest)
+	}
+	if got, want := manifest.Chapters[0], (chapterManifestRow{Number: 1, Title: "Part One", Target: "part1.md", File: "01-part-one.md", Words: 9}); got != want {
+		t.Errorf("first chapter = %+v, want %+v", got, want)
+	}
+	if got := manifest.Chapters[1]; got.Target != "" || got.Title != "Part Two" {
+		t.Errorf("placeholder chapter = %+v", got)
+	}
+
+	var recorded exportManifest
+	if err := json.Unmarshal(mock.manifestWritten, &recorded); err != nil {
+		t.Fatalf("export manifest: %v", err)
+	}
+	if got := strings.Join(recorded.Files, ","); got != "beta/01-part-one.md,beta/02-part-two.md,beta/03-epilogue.md,beta/manifest.json" {
+		t.Errorf("recorded files = %s", got)
+	}
+}
+
+func TestExportCmd_SplitByChapterDepth(t *testing.T) {
+	mock := newChapterExportMock()
+	if _, err := runChapterExport(t, mock, "--chapter-depth", "2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{
+		"beta/01-the-flood.md": "# Part One\n\nWater came.\n\nIt rose higher.\n",
+		"beta/02-aftermath.md": "Mud everywhere.\n",
+		"beta/03-return.md":    "They came home.\n",
+		"beta/04-epilogue.md":  "The end.\n",
+	}
+	for name, content := range want {
+		if got := mock.written[name]; got != content {
+			t.Errorf("%s = %q, want %q", name, got, content)
+		}
+	}
+}
+
+func TestExportCmd_SplitByChapterPDF(t *testing.T) {
+	mock := newChapterExportMock()
+	if _, err := runChapterExport(t, mock, "--format", "pdf"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := mock.pdfs["beta/03-epilogue.pdf"]; got != "Epilogue" {
+		t.Errorf("PDFs = %v, want beta/03-epilogue.pdf titled Epilogue", mock.pdfs)
+	}
+	if !strings.Contains(mock.written["beta/manifest.json"], `"file": "01-part-one.pdf"`) {
+		t.Errorf("manifest = %s", mock.written["beta/manifest.json"])
+	}
+
+	mock = newChapterExportMock()
+	mock.pdfErr = errors.New("pandoc missing")
+	if _, err := runChapterExport(t, mock, "--format", "pdf"); err == nil || !strings.Contains(err.Error(), "writing beta/01-part-one.pdf: pandoc missing") {
+		t.Errorf("error = %v, want the PDF error", err)
+	}
+	if mock.manifestWritten == nil {
+		t.Error("export manifest not written after a failure")
+	}
+}
+
+func TestExportCmd_SplitByChapterNames(t *testing.T) {
+	mock := &mockExportIO{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n" +
+		"- [](untitled.md)\n" +
+		"- [!!!](marks.md)\n" +
+		"- [???]()\n")}
+	if _, err := runChapterExport(t, mock); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var manifest chapterManifest
+	if err := json.Unmarshal([]byte(mock.written["beta/manifest.json"]), &manifest); err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	if got := manifest.Chapters; got[0].File != "01-untitled.md" || got[0].Title != "untitled" || got[1].File != "02-untitled-2.md" || got[2].File != "03-chapter.md" {
+		t.Errorf("chapters = %+v, want the slugs pmk slugs assigns and the unsluggable placeholder as chapter", got)
+	}
+}
+
+func TestExportCmd_SplitByPinnedSlugs(t *testing.T) {
+	mock := newChapterExportMock()
+	mock.slugMap = []byte(`{"version":"1","slugs":{"part1.md":"the-first-part"}}`)
+	mock.files["epilogue.md"] = "---\nslug: coda\n---\nThe end.\n"
+	if _, err := runChapterExport(t, mock); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"beta/01-the-first-part.md", "beta/02-part-two.md", "beta/03-coda.md"} {
+		if _, ok := mock.written[name]; !ok {
+			t.Errorf("%s not written; got %v", name, mock.writeOrder)
+		}
+	}
+	if _, ok := mock.written[".prosemark/slugs.json"]; ok {
+		t.Error("export wrote the slug map")
+	}
+
+	unreadable := newChapterExportMock()
+	unreadable.slugMapErr = errors.New("denied")
+	if _, err := runChapterExport(t, unreadable); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("unreadable slug map: error = %v", err)
+	}
+
+	malformed := newChapterExportMock()
+	malformed.slugMap = []byte("{")
+	if _, err := runChapterExport(t, malformed); err == nil {
+		t.Error("malformed slug map: want an error")
+	}
+}
+
+func TestExportCmd_SplitByErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"unknown unit", []string{"--split-by", "scene", "--out", "beta"}, `unknown --split-by "scene"`},
+		{"read-aloud format", []string{"--split-by", "chapter", "--out", "beta", "--format", "ssml"}, `unknown --format "ssml" with --split-by`},
+		{"no out", []string{"--split-by", "chapter"}, "--split-by needs --out"},
+		{"bad depth", []string{"--split-by", "chapter", "--out", "beta", "--chapter-depth", "0"}, "--chapter-depth must be at least 1"},
+		{"md without split", []string{"--format", "md"}, "--format md needs --split-by chapter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := runExportCmd(t, newChapterExportMock(), tt.args...); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	locked := newChapterExportMock()
+	locked.readErrs = map[string]error{"after.md": node.ErrLockedBody}
+	if _, err := runChapterExport(t, locked); !errors.Is(err, node.ErrLockedBody) {
+		t.Errorf("locked body: error = %v, want ErrLockedBody", err)
+	}
+
+	lockedInclude := newChapterExportMock()
+	lockedInclude.files["after.md"] = "<!-- pmk-include: secret.md -->\n"
+	lockedInclude.readErrs = map[string]error{"secret.md": node.ErrLockedBody}
+	if _, err := runChapterExport(t, lockedInclude); !errors.Is(err, node.ErrLockedBody) || !strings.Contains(err.Error(), "secret.md") {
+		t.Errorf("locked include: error = %v, want ErrLockedBody for secret.md", err)
+	}
+
+	badSettings := newChapterExportMock()
+	badSettings.readErrs = map[string]error{node.ConfigFilename: errors.New("denied")}
+	if _, err := runChapterExport(t, badSettings); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("unreadable settings: error = %v", err)
+	}
+
+	badManifest := newChapterExportMock()
+	badManifest.manifestErr = errors.New("denied")
+	if _, err := runChapterExport(t, badManifest); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("unreadable export manifest: error = %v", err)
+	}
+
+	listingFails := newChapterExportMock()
+	listingFails.writeErr = errors.New("disk full")
+	if _, err := runChapterExport(t, listingFails, "--format", "pdf"); err == nil || !strings.Contains(err.Error(), "writing beta/manifest.json: disk full") {
+		t.Errorf("manifest write: error = %v", err)
+	}
+
+	recordFails := newChapterExportMock()
+	recordFails.manifestWriteErr = errors.New("read-only")
+	if _, err := runChapterExport(t, recordFails); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("export manifest write: error = %v", err)
+	}
+
+	c := newExportCmdWithGetCWD(newChapterExportMock(), func() (string, error) { return "", errors.New("getwd failed") })
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	for _, args := range [][]string{
+		{"--project", "proj", "--split-by", "chapter", "--out", "/out"},
+		{"--project", "/proj", "--split-by", "chapter", "--out", "beta"},
+	} {
+		c.SetArgs(args)
+		if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+			t.Errorf("args %v: error = %v, want the getwd failure", args, err)
+		}
+	}
+
+	empty := &mockExportIO{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n")}
+	if _, err := runChapterExport(t, empty); err == nil || !strings.Contains(err.Error(), "no entries") {
+		t.Errorf("empty binder: error = %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+// fileProjectIO provides the binder and node file I/O that most commands
+// share, backed by fsio. Embed this in file-IO structs and define only the
+// methods a command does differently.
+type fileProjectIO struct{}
+
+// ReadBinder reads the binder file at path, first finishing or rolling back
+// any file transaction a crash left incomplete in its project, so that no
+// command reads, or writes over, a half-applied one.
+func (fileProjectIO) ReadBinder(_ context.Context, path string) ([]byte, error) {
+	if _, err := fsio.RecoverTxs(filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("recovering interrupted transaction: %w", err)
+	}
+	return fsio.ReadBinder(path)
+}
+
+// ScanProject scans the project directory for .md files.
+func (fileProjectIO) ScanProject(ctx context.Context, binderPath string) (*binder.Project, error) {
+	return fsio.ScanProject(ctx, binderPath)
+}
+
+// WriteBinderAtomic writes data to path atomically via a temp file.
+func (fileProjectIO) WriteBinderAtomic(_ context.Context, path string, data []byte) error {
+	return fsio.WriteBinder(path, data)
+}
+
+// ReadFile reads the file at path as stored.
+func (fileProjectIO) ReadFile(path string) ([]byte, error) {
+	return fsio.ReadFile(path)
+}
+
+// ReadNodeFile reads the node file at path, unlocking a locked body.
+func (fileProjectIO) ReadNodeFile(path string) ([]byte, error) {
+	return fsio.ReadNodeFile(path)
+}
+
+// WriteNodeFileAtomic writes content to path atomically, keeping a locked
+// node locked.
+func (fileProjectIO) WriteNodeFileAtomic(path string, content []byte) error {
+	return fsio.WriteNodeFile(path, content)
+}
+
+// DeleteFile removes the file at path.
+func (fileProjectIO) DeleteFile(path string) error {
+	return fsio.DeleteFile(path)
+}
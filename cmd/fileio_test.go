@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+func TestFileProjectIO_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	var fio fileProjectIO
+
+	binderPath := filepath.Join(dir, "_binder.md")
+	binderSrc := []byte("<!-- prosemark-binder:v1 -->\n- [Chapter](ch1.md)\n")
+	if err := fio.WriteBinderAtomic(ctx, binderPath, binderSrc); err != nil {
+		t.Fatalf("WriteBinderAtomic: %v", err)
+	}
+	if got, err := fio.ReadBinder(ctx, binderPath); err != nil || string(got) != string(binderSrc) {
+		t.Errorf("ReadBinder = %q, %v", got, err)
+	}
+	proj, err := fio.ScanProject(ctx, binderPath)
+	if err != nil || len(proj.Files) != 0 {
+		t.Errorf("ScanProject = %+v, %v; want no node files", proj, err)
+	}
+
+	nodePath := filepath.Join(dir, "ch1.md")
+	content := []byte("---\ntitle: Chapter\n---\n\nBody.\n")
+	if err := fio.WriteNodeFileAtomic(nodePath, content); err != nil {
+		t.Fatalf("WriteNodeFileAtomic: %v", err)
+	}
+	if got, err := fio.ReadNodeFile(nodePath); err != nil || string(got) != string(content) {
+		t.Errorf("ReadNodeFile = %q, %v", got, err)
+	}
+	if got, err := fio.ReadFile(nodePath); err != nil || string(got) != string(content) {
+		t.Errorf("ReadFile = %q, %v", got, err)
+	}
+	if err := fio.DeleteFile(nodePath); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if _, err := os.Stat(nodePath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("node file still present after DeleteFile: %v", err)
+	}
+}
+
+func TestFileProjectIO_ReadBinder_RecoversTransactions(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	if err := os.WriteFile(binderPath, []byte("<!-- prosemark-binder:v1 -->\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// A journal cut short by a crash before its first step.
+	journal := filepath.Join(dir, filepath.FromSlash(fsio.TxDir), "1.json")
+	if err := os.MkdirAll(filepath.Dir(journal), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(journal, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var fio fileProjectIO
+	if _, err := fio.ReadBinder(context.Background(), binderPath); err != nil {
+		t.Fatalf("ReadBinder: %v", err)
+	}
+	if _, err := os.Stat(journal); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("journal still present after ReadBinder: %v", err)
+	}
+
+	// A project whose transactions cannot be listed is not read.
+	if err := os.RemoveAll(filepath.Join(dir, ".prosemark")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".prosemark"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fio.ReadBinder(context.Background(), binderPath); err == nil || !strings.Contains(err.Error(), "recovering interrupted transaction") {
+		t.Errorf("ReadBinder = %v, want the recovery error", err)
+	}
+}
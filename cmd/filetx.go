@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+// fileTxer provides core.TxIO backed by the project's transaction journal.
+// Embed this in file-IO structs whose commands write several files at once.
+type fileTxer struct{}
+
+// BeginTx starts a journaled file transaction in projectDir, first
+// finishing or rolling back any that a crash left incomplete there.
+func (fileTxer) BeginTx(projectDir string) (core.FileTx, error) {
+	tx, err := fsio.BeginTx(projectDir, fsio.SyncAlways)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// mergingTx is the file transaction of add --new and capture. Like
+// fileAddChildIO.WriteBinderAtomic, its binder write merges the incoming
+// binder with the one on disk under the binder lock, which it holds until
+// the transaction ends so no concurrent add slips in between.
+type mergingTx struct {
+	*fsio.Tx
+	// ctx bounds the wait for the binder lock.
+	ctx    context.Context
+	unlock func() error
+	err    error
+}
+
+// WriteBinder stages data merged with the binder at path.
+func (t *mergingTx) WriteBinder(path string, data []byte) {
+	if t.err != nil {
+		return
+	}
+	if t.unlock != nil {
+		t.err = errors.New("writing binder: binder already staged")
+		return
+	}
+	unlock, err := globalBinderLocks.lock(t.ctx, path)
+	if err != nil {
+		t.err = err
+		return
+	}
+	t.unlock = unlock
+	current, _, err := fsio.ReadFileIfExists(path)
+	if err != nil {
+		t.err = fmt.Errorf("reading current binder: %w", err)
+		return
+	}
+	t.Tx.WriteBinder(path, mergeBinderLines(current, data))
+}
+
+// Commit commits the transaction and releases the binder lock.
+func (t *mergingTx) Commit() error {
+	defer t.release()
+	if t.err != nil {
+		return errors.Join(t.err, t.Tx.Abort())
+	}
+	return t.Tx.Commit()
+}
+
+// Abort aborts the transaction and releases the binder lock.
+func (t *mergingTx) Abort() error {
+	defer t.release()
+	return t.Tx.Abort()
+}
+
+// release releases the binder lock, if held.
+func (t *mergingTx) release() {
+	if t.unlock != nil {
+		_ = t.unlock()
+		t.unlock = nil
+	}
+}
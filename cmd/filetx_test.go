@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/core/coretest"
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+// beginMemTx is the BeginTx of the fake IOs: it returns an in-memory
+// transaction applied through io's own methods. A fake that embeds another
+// passes itself, so the methods it overrides are the ones applied.
+func beginMemTx(io coretest.FileIO) (core.FileTx, error) {
+	return coretest.NewTx(context.Background(), io), nil
+}
+
+// assertNoTxLeftovers fails t when a file transaction left its journal,
+// staged files, or backups in the project at dir.
+func assertNoTxLeftovers(t *testing.T, dir string) {
+	t.Helper()
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		if strings.HasPrefix(d.Name(), ".pmk-tx-") || !d.IsDir() && strings.HasPrefix(filepath.ToSlash(rel), ".prosemark/tx/") {
+			t.Errorf("transaction leftover %s", rel)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMergingTx_MergesWithBinderOnDisk(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	if err := os.WriteFile(binderPath, acBinder(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := newDefaultAddChildIO().BeginTx(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Another add lands after this one read the binder.
+	concurrent := string(acBinder()) + "- [Chapter Two](chapter-two.md)\n"
+	if err := os.WriteFile(binderPath, []byte(concurrent), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tx.Create(filepath.Join(dir, "new.md"), []byte("New.\n"))
+	tx.WriteBinder(binderPath, append(acBinder(), "- [New](new.md)\n"...))
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() = %v", err)
+	}
+	got, _ := os.ReadFile(binderPath)
+	for _, line := range []string{"- [Chapter Two](chapter-two.md)\n", "- [New](new.md)\n"} {
+		if !strings.Contains(string(got), line) {
+			t.Errorf("binder = %q, want it to keep %q", got, line)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.md")); err != nil {
+		t.Errorf("node file not created: %v", err)
+	}
+	assertNoTxLeftovers(t, dir)
+
+	// The binder lock is released: a second transaction can stage a binder.
+	tx, err = newDefaultAddChildIO().BeginTx(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.WriteBinder(binderPath, got)
+	tx.WriteBinder(binderPath, got)
+	if err := tx.Commit(); err == nil || !strings.Contains(err.Error(), "binder already staged") {
+		t.Errorf("Commit() = %v, want the second binder write refused", err)
+	}
+	assertNoTxLeftovers(t, dir)
+}
+
+func TestFileTxer_BeginTx_Error(t *testing.T) {
+	// A project whose transactions cannot be listed cannot start one.
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".prosemark"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (fileTxer{}).BeginTx(dir); err == nil {
+		t.Error("fileTxer.BeginTx() = nil error, want one")
+	}
+	if _, err := newDefaultAddChildIO().BeginTx(dir); err == nil {
+		t.Error("fileAddChildIO.BeginTx() = nil error, want one")
+	}
+}
+
+func TestMergingTx_Abort(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	if err := os.WriteFile(binderPath, acBinder(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := newDefaultAddChildIO().BeginTx(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Create(filepath.Join(dir, "new.md"), []byte("New.\n"))
+	tx.WriteBinder(binderPath, append(acBinder(), "- [New](new.md)\n"...))
+	if err := tx.Abort(); err != nil {
+		t.Fatalf("Abort() = %v", err)
+	}
+	if got, _ := os.ReadFile(binderPath); string(got) != string(acBinder()) {
+		t.Errorf("binder = %q, want it unchanged", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.md")); !os.IsNotExist(err) {
+		t.Errorf("node file created: %v", err)
+	}
+	assertNoTxLeftovers(t, dir)
+
+	// The binder lock is released: a second transaction can commit.
+	tx, err = newDefaultAddChildIO().BeginTx(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.WriteBinder(binderPath, acBinder())
+	if err := tx.Commit(); err != nil {
+		t.Errorf("Commit() = %v", err)
+	}
+}
+
+func TestMergingTx_BinderLockTimeout(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	if err := os.WriteFile(binderPath, acBinder(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// Another add holds the binder lock past this transaction's deadline.
+	unlock, err := globalBinderLocks.lock(context.Background(), binderPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = unlock() }()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	fileTx, err := fsio.BeginTx(dir, fsio.SyncNever)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := &mergingTx{Tx: fileTx, ctx: ctx}
+	tx.Create(filepath.Join(dir, "new.md"), []byte("New.\n"))
+	tx.WriteBinder(binderPath, append(acBinder(), "- [New](new.md)\n"...))
+	if err := tx.Commit(); !errors.Is(err, context.Canceled) || !strings.Contains(err.Error(), "acquiring binder lock") {
+		t.Errorf("Commit() = %v, want the binder lock error", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.md")); !os.IsNotExist(err) {
+		t.Errorf("node file created: %v", err)
+	}
+	assertNoTxLeftovers(t, dir)
+}
+
+func TestMergingTx_UnreadableBinder(t *testing.T) {
+	dir := t.TempDir()
+	// A directory where the binder should be cannot be read to merge with.
+	binderPath := filepath.Join(dir, "_binder.md")
+	if err := os.Mkdir(binderPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := newDefaultAddChildIO().BeginTx(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Create(filepath.Join(dir, "new.md"), []byte("New.\n"))
+	tx.WriteBinder(binderPath, acBinder())
+	// Later binder writes are ignored once one has failed.
+	tx.WriteBinder(binderPath, acBinder())
+	if err := tx.Commit(); err == nil || !strings.Contains(err.Error(), "reading current binder") || strings.Contains(err.Error(), "already staged") {
+		t.Errorf("Commit() = %v, want the binder read error alone", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.md")); !os.IsNotExist(err) {
+		t.Errorf("node file created: %v", err)
+	}
+	assertNoTxLeftovers(t, dir)
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Encodings of structured (--json) output, chosen with --format.
+const (
+	formatJSON = "json"
+	formatYAML = "yaml"
+)
+
+// addFormatFlag registers --format on a command with --json output. It
+// chooses how the output is encoded; --format yaml implies --json. The
+// flag is checked before any PreRunE the command already has runs.
+func addFormatFlag(cmd *cobra.Command) {
+	cmd.Flags().String("format", formatJSON, "structured output encoding: json or yaml (--format yaml implies --json)")
+	next := cmd.PreRunE
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := applyFormatFlag(cmd); err != nil {
+			return err
+		}
+		if next != nil {
+			return next(cmd, args)
+		}
+		return nil
+	}
+}
+
+// applyFormatFlag validates cmd's --format and sets --json for yaml.
+func applyFormatFlag(cmd *cobra.Command) error {
+	switch format, _ := cmd.Flags().GetString("format"); format {
+	case formatJSON:
+		return nil
+	case formatYAML:
+		return cmd.Flags().Set("json", "true")
+	default:
+		return fmt.Errorf("unknown --format %q: want json or yaml", format)
+	}
+}
+
+// encodeOutput writes v as cmd's structured output: YAML under --format
+// yaml, otherwise a line of JSON.
+func encodeOutput(cmd *cobra.Command, v any) error {
+	data, err := marshalOutput(cmd, v)
+	if err != nil {
+		return err
+	}
+	_, err = cmd.OutOrStdout().Write(data)
+	return err
+}
+
+// marshalOutput returns v encoded as encodeOutput writes it.
+func marshalOutput(cmd *cobra.Command, v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	if format, _ := cmd.Flags().GetString("format"); format != formatYAML {
+		return buf.Bytes(), nil
+	}
+	return jsonToYAML(buf.Bytes())
+}
+
+// jsonToYAML re-encodes a JSON document as block-style YAML. Going through
+// the JSON encoding keeps the YAML's keys, order, and omitted fields the
+// same as the JSON's, so the two formats cannot drift apart.
+func jsonToYAML(data []byte) ([]byte, error) {
+	// JSON is YAML, so the decoder reads it as is.
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	clearStyle(&doc)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	// A node the decoder built always encodes, and a bytes.Buffer takes
+	// every write.
+	_ = enc.Encode(&doc)
+	_ = enc.Close()
+	return buf.Bytes(), nil
+}
+
+// yaml11Bools are the plain scalars YAML 1.1, unlike YAML 1.2, reads as
+// booleans.
+var yaml11Bools = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true,
+	"n": true, "N": true, "no": true, "No": true, "NO": true,
+	"on": true, "On": true, "ON": true, "off": true, "Off": true, "OFF": true,
+}
+
+// clearStyle resets the flow and quoting styles the decoder kept from the
+// JSON, so the encoder picks YAML's own: block collections, and quotes only
+// where a string would otherwise read as something else. Empty collections
+// stay in flow style, as {} and []. Strings YAML 1.1 reads as booleans stay
+// quoted for the sake of older parsers.
+func clearStyle(n *yaml.Node) {
+	if len(n.Content) > 0 || n.Kind == yaml.ScalarNode && !(n.Tag == "!!str" && yaml11Bools[n.Value]) {
+		n.Style = 0
+	}
+	for _, c := range n.Content {
+		clearStyle(c)
+	}
+}
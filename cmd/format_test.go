@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func TestJSONToYAML(t *testing.T) {
+	got, err := jsonToYAML([]byte(`{"version":"1","changed":true,"count":3,"title":"yes","empty":[],"none":{},"lines":"a\nb","entries":[{"target":"a.md","path":[0,1]}]}` + "\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `version: "1"
+changed: true
+count: 3
+title: "yes"
+empty: []
+none: {}
+lines: |-
+  a
+  b
+entries:
+  - target: a.md
+    path:
+      - 0
+      - 1
+`
+	if string(got) != want {
+		t.Errorf("YAML =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestJSONToYAML_InvalidJSON(t *testing.T) {
+	if _, err := jsonToYAML([]byte("{")); err == nil {
+		t.Error("jsonToYAML of a truncated document: want an error")
+	}
+}
+
+func TestEncodeOutput_Unencodable(t *testing.T) {
+	c := &cobra.Command{}
+	c.SetOut(new(bytes.Buffer))
+	if err := encodeOutput(c, make(chan int)); err == nil {
+		t.Error("encodeOutput of a channel: want an error")
+	}
+}
+
+func TestAddFormatFlag_YAMLImpliesJSON(t *testing.T) {
+	stubVersionEnv(t)
+	c := NewVersionCmd(&mockReleaseIO{})
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetArgs([]string{"--format", "yaml"})
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got versionOutput
+	if err := yaml.Unmarshal(out.Bytes(), &got); err != nil || !strings.HasPrefix(out.String(), "version: 1.2.3\n") {
+		t.Fatalf("output = %q, err = %v; want YAML version info", out, err)
+	}
+}
+
+func TestAddFormatFlag_Unknown(t *testing.T) {
+	stubVersionEnv(t)
+	c := NewVersionCmd(&mockReleaseIO{})
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--json", "--format", "toml"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), `unknown --format "toml": want json or yaml`) {
+		t.Errorf("error = %v, want an unknown format error", err)
+	}
+}
+
+func TestAddFormatFlag_ChainsPreRunE(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantJSON bool
+		wantRun  bool
+		wantErr  string
+	}{
+		{name: "yaml", args: []string{"--format", "yaml"}, wantJSON: true, wantRun: true},
+		{name: "json", args: nil, wantRun: true},
+		{name: "unknown format", args: []string{"--format", "toml"}, wantErr: "unknown --format"},
+		{name: "existing hook fails", args: []string{"--format", "yaml", "--fail"}, wantJSON: true, wantRun: true, wantErr: "hook failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var jsonMode, fail, ran, sawJSON bool
+			c := &cobra.Command{
+				Use: "x",
+				PreRunE: func(*cobra.Command, []string) error {
+					ran, sawJSON = true, jsonMode
+					if fail {
+						return errors.New("hook failed")
+					}
+					return nil
+				},
+				RunE:          func(*cobra.Command, []string) error { return nil },
+				SilenceUsage:  true,
+				SilenceErrors: true,
+			}
+			c.Flags().BoolVar(&jsonMode, "json", false, "")
+			c.Flags().BoolVar(&fail, "fail", false, "")
+			addFormatFlag(c)
+			c.SetArgs(tt.args)
+
+			err := c.Execute()
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if ran != tt.wantRun || sawJSON != tt.wantJSON {
+				t.Errorf("existing PreRunE ran = %v with --json %v, want %v with %v", ran, sawJSON, tt.wantRun, tt.wantJSON)
+			}
+		})
+	}
+}
+
+func TestNewDoctorCmd_FormatYAML(t *testing.T) {
+	for _, args := range [][]string{{"--format", "yaml"}, {"--json", "--format", "yaml"}} {
+		mock := &mockDoctorIO{binderBytes: doctorBinderWithNode(doctorTestNodeUUID)}
+		c := NewDoctorCmd(mock)
+		out := new(bytes.Buffer)
+		c.SetOut(out)
+		c.SetErr(new(bytes.Buffer))
+		c.SetArgs(append([]string{"--project", "."}, args...))
+		_ = c.Execute() // the audit's findings fail the command
+
+		if !strings.HasPrefix(out.String(), "version: \"1\"\ndiagnostics:\n  - severity: error\n    code: AUD001\n") {
+			t.Errorf("%v: output = %q, want YAML diagnostics", args, out)
+		}
+	}
+}
+
+func TestRootCmd_EveryJSONCommandHasFormat(t *testing.T) {
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		if c.Flags().Lookup("json") != nil && c.Flags().Lookup("format") == nil {
+			t.Errorf("%s has --json but no --format", c.CommandPath())
+		}
+		for _, sub := range c.Commands() {
+			walk(sub)
+		}
+	}
+	walk(NewRootCmd())
+}
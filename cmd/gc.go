@@ -0,0 +1,355 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// staleTempAge is how old a WriteFileAtomic temp file must be before gc
+// removes it, so a write still in flight is never disturbed.
+const staleTempAge = time.Hour
+
+// GCIO handles I/O for the gc command.
+type GCIO interface {
+	// ReadFileIfExists returns the contents of path, or nil when it does not exist.
+	ReadFileIfExists(path string) ([]byte, error)
+	WriteFileAtomic(path string, data []byte) error
+	RemoveFile(path string) error
+	// RemoveDir removes the directory at path and everything under it.
+	RemoveDir(path string) error
+	// FindTempFiles returns the stray atomic-write temp files under projectDir.
+	FindTempFiles(projectDir string) ([]fsio.TempFile, error)
+}
+
+// gcOutput is the JSON output schema for gc.
+type gcOutput struct {
+	Version        string   `json:"version"`
+	DryRun         bool     `json:"dryRun"`
+	JournalEntries int      `json:"journalEntries"`
+	CacheEntries   int      `json:"cacheEntries"`
+	TempFiles      []string `json:"tempFiles"`
+	TrashEntries   []string `json:"trashEntries"` // IDs of the pruned deletions
+	ReclaimedBytes int64    `json:"reclaimedBytes"`
+}
+
+// NewGCCmd creates the gc subcommand.
+func NewGCCmd(io GCIO) *cobra.Command {
+	return newGCCmdWithGetCWD(io, os.Getwd)
+}
+
+func newGCCmdWithGetCWD(io GCIO, getwd func() (string, error)) *cobra.Command {
+	var (
+		dryRun        bool
+		jsonMode      bool
+		journalMaxAge time.Duration
+		cacheMaxAge   time.Duration
+		trashMaxAge   time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Prune old entries from the project's .prosemark directory",
+		Long: "Prune old entries from the project's .prosemark directory and report the\n" +
+			"space reclaimed:\n\n" +
+			"  - operation journal entries (--op-id) older than --journal-max-age\n" +
+			"  - check-links cache entries older than --cache-max-age\n" +
+			"  - temp files left by interrupted writes, once they are an hour old\n" +
+			"  - deletions in the trash (pmk delete --archive) older than\n" +
+			"    --trash-max-age, which pmk restore can then no longer bring back\n\n" +
+			"Run gc when no other pmk command is writing to the project.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if journalMaxAge <= 0 || cacheMaxAge <= 0 || trashMaxAge <= 0 {
+				return fmt.Errorf("--journal-max-age, --cache-max-age, and --trash-max-age must be positive")
+			}
+			projectDir, err := resolveProjectDirFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			// nowUTCFunc always returns RFC 3339.
+			now, _ := time.Parse(time.RFC3339, nowUTCFunc())
+
+			out := gcOutput{Version: "1", DryRun: dryRun, TempFiles: []string{}, TrashEntries: []string{}}
+			var reclaimed int64
+			out.JournalEntries, reclaimed, err = gcJournal(io, projectDir, now.Add(-journalMaxAge), dryRun)
+			if err != nil {
+				return err
+			}
+			out.ReclaimedBytes += reclaimed
+			out.CacheEntries, reclaimed, err = gcLinkCache(io, projectDir, now.Add(-cacheMaxAge), dryRun)
+			if err != nil {
+				return err
+			}
+			out.ReclaimedBytes += reclaimed
+			out.TempFiles, reclaimed, err = gcTempFiles(io, projectDir, now.Add(-staleTempAge), dryRun)
+			if err != nil {
+				return err
+			}
+			out.ReclaimedBytes += reclaimed
+			out.TrashEntries, reclaimed, err = gcTrash(io, projectDir, now.Add(-trashMaxAge), dryRun)
+			if err != nil {
+				return err
+			}
+			out.ReclaimedBytes += reclaimed
+
+			if jsonMode {
+				if err := encodeOutput(cmd, out); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+				return nil
+			}
+			return printGCReport(cmd, out)
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory (default: current directory)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report what would be pruned without changing anything")
+	cmd.Flags().DurationVar(&journalMaxAge, "journal-max-age", 30*24*time.Hour, "prune operation journal entries older than this")
+	cmd.Flags().DurationVar(&cacheMaxAge, "cache-max-age", 7*24*time.Hour, "prune link cache entries older than this")
+	cmd.Flags().DurationVar(&trashMaxAge, "trash-max-age", 30*24*time.Hour, "prune trash deletions older than this")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+
+	return cmd
+}
+
+// printGCReport writes the human-readable gc summary.
+func printGCReport(cmd *cobra.Command, out gcOutput) error {
+	verb := "Removed"
+	if out.DryRun {
+		verb = "Would remove"
+	}
+	var buf bytes.Buffer
+	if out.JournalEntries > 0 {
+		fmt.Fprintf(&buf, "%s %d operation journal entries\n", verb, out.JournalEntries)
+	}
+	if out.CacheEntries > 0 {
+		fmt.Fprintf(&buf, "%s %d link cache entries\n", verb, out.CacheEntries)
+	}
+	for _, p := range out.TempFiles {
+		fmt.Fprintf(&buf, "%s temp file %s\n", verb, sanitizePath(p))
+	}
+	for _, id := range out.TrashEntries {
+		fmt.Fprintf(&buf, "%s trash entry %s\n", verb, sanitizePath(id))
+	}
+	if buf.Len() == 0 {
+		buf.WriteString("Nothing to collect\n")
+	} else if out.DryRun {
+		fmt.Fprintf(&buf, "Would reclaim %d bytes\n", out.ReclaimedBytes)
+	} else {
+		fmt.Fprintf(&buf, "Reclaimed %d bytes\n", out.ReclaimedBytes)
+	}
+	if _, err := cmd.OutOrStdout().Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	return nil
+}
+
+// gcJournal drops journal entries applied before cutoff, along with lines
+// that no longer decode, and returns how many lines were dropped and the
+// bytes saved. Kept lines are preserved byte for byte.
+func gcJournal(io GCIO, projectDir string, cutoff time.Time, dryRun bool) (int, int64, error) {
+	path := fsio.JournalPath(projectDir)
+	data, err := io.ReadFileIfExists(path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("reading operation journal: %w", err)
+	}
+	var kept bytes.Buffer
+	dropped := 0
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		e, ok := fsio.ParseJournalLine(line)
+		if applied, err := time.Parse(time.RFC3339, e.Time); !ok || err != nil || applied.Before(cutoff) {
+			dropped++
+			continue
+		}
+		kept.Write(line)
+		kept.WriteByte('\n')
+	}
+	if dropped == 0 {
+		return 0, 0, nil
+	}
+	if !dryRun {
+		if err := io.WriteFileAtomic(path, kept.Bytes()); err != nil {
+			return 0, 0, fmt.Errorf("writing operation journal: %w", err)
+		}
+	}
+	return dropped, int64(len(data) - kept.Len()), nil
+}
+
+// gcLinkCache drops link cache entries checked before cutoff and returns
+// how many were dropped and the bytes saved. A cache that does not decode
+// is removed outright.
+func gcLinkCache(io GCIO, projectDir string, cutoff time.Time, dryRun bool) (int, int64, error) {
+	path := filepath.Join(projectDir, filepath.FromSlash(linkCacheFilename))
+	data, err := io.ReadFileIfExists(path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("reading link cache: %w", err)
+	}
+	if data == nil {
+		return 0, 0, nil
+	}
+	var cache linkCache
+	if json.Unmarshal(data, &cache) != nil {
+		if !dryRun {
+			if err := io.RemoveFile(path); err != nil {
+				return 0, 0, fmt.Errorf("removing link cache: %w", err)
+			}
+		}
+		return 0, int64(len(data)), nil
+	}
+	dropped := 0
+	for u, e := range cache.URLs {
+		if checked, err := time.Parse(time.RFC3339, e.Checked); err != nil || checked.Before(cutoff) {
+			delete(cache.URLs, u)
+			dropped++
+		}
+	}
+	if dropped == 0 {
+		return 0, 0, nil
+	}
+	pruned := encodeLinkCache(cache)
+	if !dryRun {
+		if err := io.WriteFileAtomic(path, pruned); err != nil {
+			return 0, 0, fmt.Errorf("writing link cache: %w", err)
+		}
+	}
+	return dropped, max(int64(len(data)-len(pruned)), 0), nil
+}
+
+// gcTempFiles removes atomic-write temp files last modified before cutoff
+// and returns their project-relative paths and total size.
+func gcTempFiles(io GCIO, projectDir string, cutoff time.Time, dryRun bool) ([]string, int64, error) {
+	found, err := io.FindTempFiles(projectDir)
+	if err != nil {
+		return nil, 0, fmt.Errorf("finding temp files: %w", err)
+	}
+	removed := []string{}
+	var size int64
+	for _, f := range found {
+		if !f.ModTime.Before(cutoff) {
+			continue
+		}
+		if !dryRun {
+			if err := io.RemoveFile(f.Path); err != nil {
+				return nil, 0, fmt.Errorf("removing temp file: %w", err)
+			}
+		}
+		// Found paths lie under projectDir, so Rel cannot fail.
+		rel, _ := filepath.Rel(projectDir, f.Path)
+		removed = append(removed, filepath.ToSlash(rel))
+		size += f.Size
+	}
+	return removed, size, nil
+}
+
+// gcTrash drops the trash deletions made before cutoff, removing their
+// files, and returns their IDs and the bytes saved. A deletion whose time
+// does not decode is kept, as is one whose ID names no directory of the
+// trash, so gc never removes anything outside it.
+func gcTrash(io GCIO, projectDir string, cutoff time.Time, dryRun bool) ([]string, int64, error) {
+	path := filepath.Join(projectDir, filepath.FromSlash(core.TrashManifestFilename))
+	data, err := io.ReadFileIfExists(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading trash manifest: %w", err)
+	}
+	pruned := []string{}
+	if data == nil {
+		return pruned, 0, nil
+	}
+	var manifest core.TrashManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, 0, fmt.Errorf("reading trash manifest: %w", err)
+	}
+	var size int64
+	kept := []core.TrashRecord{}
+	for _, rec := range manifest.Records {
+		deleted, err := time.Parse(time.RFC3339, rec.Deleted)
+		dir, dirErr := trashEntryDir(projectDir, rec.ID)
+		if err != nil || dirErr != nil || !deleted.Before(cutoff) {
+			kept = append(kept, rec)
+			continue
+		}
+		for _, rel := range rec.Files {
+			file, err := safepath.Resolve(dir, rel)
+			if err != nil {
+				continue
+			}
+			content, err := io.ReadFileIfExists(file)
+			if err != nil {
+				return nil, 0, fmt.Errorf("reading trash entry %s: %w", rec.ID, err)
+			}
+			size += int64(len(content))
+		}
+		if !dryRun {
+			if err := io.RemoveDir(dir); err != nil {
+				return nil, 0, fmt.Errorf("removing trash entry %s: %w", rec.ID, err)
+			}
+		}
+		pruned = append(pruned, rec.ID)
+	}
+	if len(pruned) == 0 {
+		return pruned, 0, nil
+	}
+	manifest.Records = kept
+	rewritten := core.MarshalTrash(&manifest)
+	if !dryRun {
+		if err := io.WriteFileAtomic(path, rewritten); err != nil {
+			return nil, 0, fmt.Errorf("writing trash manifest: %w", err)
+		}
+	}
+	return pruned, size + max(int64(len(data)-len(rewritten)), 0), nil
+}
+
+// trashEntryDir returns the directory holding the files of the trash
+// deletion id, which must be named directly under the trash and not lead
+// out of the project through a symlink.
+func trashEntryDir(projectDir, id string) (string, error) {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("invalid trash ID %q", id)
+	}
+	return safepath.Resolve(projectDir, core.TrashDir+"/"+id)
+}
+
+// fileGCIO implements GCIO using OS file operations.
+type fileGCIO struct{}
+
+// ReadFileIfExists reads path, returning nil when it does not exist.
+func (f fileGCIO) ReadFileIfExists(path string) ([]byte, error) {
+	data, _, err := fsio.ReadFileIfExists(path)
+	return data, err
+}
+
+// WriteFileAtomic replaces path atomically.
+func (f fileGCIO) WriteFileAtomic(path string, data []byte) error {
+	return fsio.WriteFileAtomic(path, ".gc", data)
+}
+
+// RemoveFile removes the file at path.
+func (f fileGCIO) RemoveFile(path string) error {
+	return fsio.DeleteFile(path)
+}
+
+// RemoveDir removes the directory at path and everything under it.
+func (f fileGCIO) RemoveDir(path string) error {
+	return fsio.RemoveDir(path)
+}
+
+// FindTempFiles returns the stray temp files under projectDir.
+func (f fileGCIO) FindTempFiles(projectDir string) ([]fsio.TempFile, error) {
+	return fsio.FindTempFiles(projectDir)
+}
@@ -0,0 +1,420 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+// mockGCIO is a test double for GCIO backed by an in-memory file map.
+type mockGCIO struct {
+	files     map[string][]byte
+	temps     []fsio.TempFile
+	readErrs  map[string]error
+	writeErr  error
+	removeErr error
+	rmDirErr  error
+	findErr   error
+	removed   []string
+}
+
+func (m *mockGCIO) ReadFileIfExists(path string) ([]byte, error) {
+	return m.files[path], m.readErrs[path]
+}
+
+func (m *mockGCIO) WriteFileAtomic(path string, data []byte) error {
+	if m.writeErr != nil {
+		return m.writeErr
+	}
+	m.files[path] = data
+	return nil
+}
+
+func (m *mockGCIO) RemoveFile(path string) error {
+	if m.removeErr != nil {
+		return m.removeErr
+	}
+	m.removed = append(m.removed, path)
+	delete(m.files, path)
+	for i, f := range m.temps {
+		if f.Path == path {
+			m.temps = append(m.temps[:i], m.temps[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *mockGCIO) RemoveDir(path string) error {
+	if m.rmDirErr != nil {
+		return m.rmDirErr
+	}
+	m.removed = append(m.removed, path)
+	for f := range m.files {
+		if strings.HasPrefix(f, path+string(filepath.Separator)) {
+			delete(m.files, f)
+		}
+	}
+	return nil
+}
+
+func (m *mockGCIO) FindTempFiles(_ string) ([]fsio.TempFile, error) {
+	return m.temps, m.findErr
+}
+
+const gcNow = "2026-03-01T12:00:00Z"
+
+var (
+	gcJournalPath = fsio.JournalPath("/proj")
+	gcCachePath   = filepath.Join("/proj", filepath.FromSlash(linkCacheFilename))
+	gcTrashPath   = filepath.Join("/proj", filepath.FromSlash(core.TrashManifestFilename))
+	gcTrashDir    = filepath.Join("/proj", filepath.FromSlash(core.TrashDir))
+)
+
+func gcTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
+
+// newGCMock returns a project with two old and one recent journal entry, a
+// truncated journal line, two stale and one fresh cache entry, one old and
+// one recent temp file, and a trash holding an old deletion (with a file
+// gone and one listed outside its directory), a recent one, one with no
+// valid time, and one whose ID leads out of the trash.
+func newGCMock() *mockGCIO {
+	return &mockGCIO{
+		files: map[string][]byte{
+			gcJournalPath: []byte(`{"op_id":"a","command":"add","time":"2026-01-01T00:00:00Z"}` + "\n" +
+				`{"op_id":"b","command":"move","time":"2026-02-28T00:00:00Z","extra":1}` + "\n" +
+				`{"op_id":"c","command":"delete","time":"bogus"}` + "\n" +
+				`{"op_id":"d","comm`),
+			gcCachePath: []byte(`{"version":"1","urls":{` +
+				`"https://old.test/":{"status":200,"checked":"2026-02-01T00:00:00Z"},` +
+				`"https://bad.test/":{"status":200,"checked":"never"},` +
+				`"https://new.test/":{"status":404,"checked":"2026-03-01T00:00:00Z"}}}`),
+			gcTrashPath: []byte(`{"version":"1","records":[` +
+				`{"id":"20260101T000000Z","deleted":"2026-01-01T00:00:00Z","files":["ch1.md","part/gone.md","../../../secret.md"]},` +
+				`{"id":"20260228T000000Z","deleted":"2026-02-28T00:00:00Z","files":["ch2.md"]},` +
+				`{"id":"undated","deleted":"someday","files":[]},` +
+				`{"id":"..","deleted":"2026-01-01T00:00:00Z","files":[]}]}`),
+			filepath.Join(gcTrashDir, "20260101T000000Z", "ch1.md"): []byte("0123456789"),
+			filepath.Join(gcTrashDir, "20260228T000000Z", "ch2.md"): []byte("recent"),
+		},
+		temps: []fsio.TempFile{
+			{Path: "/proj/.binder-123.tmp", Size: 40, ModTime: gcTime("2026-03-01T10:00:00Z")},
+			{Path: "/proj/part/.node-456.tmp", Size: 2, ModTime: gcTime("2026-03-01T11:30:00Z")},
+		},
+	}
+}
+
+func runGC(t *testing.T, mock *mockGCIO, args ...string) (string, error) {
+	t.Helper()
+	orig := nowUTCFunc
+	nowUTCFunc = func() string { return gcNow }
+	t.Cleanup(func() { nowUTCFunc = orig })
+
+	c := NewGCCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(append([]string{"--project", "/proj"}, args...))
+	err := c.Execute()
+	return out.String(), err
+}
+
+func TestGC_PrunesInternals(t *testing.T) {
+	mock := newGCMock()
+	journalBefore := len(mock.files[gcJournalPath])
+
+	out, err := runGC(t, mock, "--json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got gcOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("decoding %q: %v", out, err)
+	}
+	if got.JournalEntries != 3 || got.CacheEntries != 2 || strings.Join(got.TempFiles, ",") != ".binder-123.tmp" ||
+		strings.Join(got.TrashEntries, ",") != "20260101T000000Z" || got.DryRun {
+		t.Errorf("output = %+v", got)
+	}
+
+	journal := string(mock.files[gcJournalPath])
+	if want := `{"op_id":"b","command":"move","time":"2026-02-28T00:00:00Z","extra":1}` + "\n"; journal != want {
+		t.Errorf("journal = %q, want the recent entry kept verbatim", journal)
+	}
+	var cache linkCache
+	if err := json.Unmarshal(mock.files[gcCachePath], &cache); err != nil {
+		t.Fatal(err)
+	}
+	if len(cache.URLs) != 1 || cache.URLs["https://new.test/"].Status != 404 {
+		t.Errorf("cache = %+v, want only the fresh entry", cache.URLs)
+	}
+	if want := "/proj/.binder-123.tmp," + filepath.Join(gcTrashDir, "20260101T000000Z"); strings.Join(mock.removed, ",") != want {
+		t.Errorf("removed = %v, want %s", mock.removed, want)
+	}
+	var trash core.TrashManifest
+	if err := json.Unmarshal(mock.files[gcTrashPath], &trash); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, r := range trash.Records {
+		ids = append(ids, r.ID)
+	}
+	if strings.Join(ids, ",") != "20260228T000000Z,undated,.." {
+		t.Errorf("trash records = %v, want the recent, undated, and escaping ones kept", ids)
+	}
+	if got.ReclaimedBytes < int64(journalBefore-len(journal))+40+10 {
+		t.Errorf("reclaimedBytes = %d, too small", got.ReclaimedBytes)
+	}
+}
+
+func TestGC_TextAndDryRun(t *testing.T) {
+	mock := newGCMock()
+	before := map[string]string{}
+	for k, v := range mock.files {
+		before[k] = string(v)
+	}
+
+	out, err := runGC(t, mock, "--dry-run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"Would remove 3 operation journal entries\n",
+		"Would remove 2 link cache entries\n",
+		"Would remove temp file .binder-123.tmp\n",
+		"Would remove trash entry 20260101T000000Z\n",
+		"Would reclaim ",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("stdout = %q, missing %q", out, want)
+		}
+	}
+	for k, v := range mock.files {
+		if string(v) != before[k] {
+			t.Errorf("dry run changed %s", k)
+		}
+	}
+	if mock.removed != nil {
+		t.Errorf("dry run removed %v", mock.removed)
+	}
+
+	out, _ = runGC(t, mock)
+	if !strings.Contains(out, "Removed temp file .binder-123.tmp\nRemoved trash entry 20260101T000000Z\nReclaimed ") {
+		t.Errorf("stdout = %q", out)
+	}
+
+	out, _ = runGC(t, mock)
+	if out != "Nothing to collect\n" {
+		t.Errorf("second run stdout = %q, want nothing to collect", out)
+	}
+}
+
+func TestGC_MalformedCacheIsRemoved(t *testing.T) {
+	for _, dryRun := range []bool{false, true} {
+		mock := &mockGCIO{files: map[string][]byte{gcCachePath: []byte("{not json")}}
+		args := []string{"--json"}
+		if dryRun {
+			args = append(args, "--dry-run")
+		}
+		out, err := runGC(t, mock, args...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got gcOutput
+		_ = json.Unmarshal([]byte(out), &got)
+		if got.ReclaimedBytes != 9 {
+			t.Errorf("dryRun=%v: reclaimedBytes = %d, want 9", dryRun, got.ReclaimedBytes)
+		}
+		if _, exists := mock.files[gcCachePath]; exists != dryRun {
+			t.Errorf("dryRun=%v: cache exists = %v", dryRun, exists)
+		}
+	}
+}
+
+func TestGC_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(m *mockGCIO)
+		args    []string
+		out     *errWriter
+		wantErr string
+	}{
+		{name: "bad max age", args: []string{"--journal-max-age", "0s"}, wantErr: "must be positive"},
+		{name: "empty project", args: []string{"--project", ""}, wantErr: "--project flag cannot be empty"},
+		{
+			name:    "journal read",
+			setup:   func(m *mockGCIO) { m.readErrs = map[string]error{gcJournalPath: errors.New("denied")} },
+			wantErr: "reading operation journal: denied",
+		},
+		{
+			name:    "cache read",
+			setup:   func(m *mockGCIO) { m.readErrs = map[string]error{gcCachePath: errors.New("denied")} },
+			wantErr: "reading link cache: denied",
+		},
+		{name: "journal write", setup: func(m *mockGCIO) { m.writeErr = errors.New("full") }, wantErr: "writing operation journal: full"},
+		{
+			name: "cache write",
+			setup: func(m *mockGCIO) {
+				delete(m.files, gcJournalPath)
+				m.writeErr = errors.New("full")
+			},
+			wantErr: "writing link cache: full",
+		},
+		{
+			name: "cache remove",
+			setup: func(m *mockGCIO) {
+				m.files[gcCachePath] = []byte("garbage")
+				m.removeErr = errors.New("busy")
+			},
+			wantErr: "removing link cache: busy",
+		},
+		{
+			name: "temp remove",
+			setup: func(m *mockGCIO) {
+				delete(m.files, gcCachePath)
+				m.removeErr = errors.New("busy")
+			},
+			wantErr: "removing temp file: busy",
+		},
+		{name: "find temps", setup: func(m *mockGCIO) { m.findErr = errors.New("loop") }, wantErr: "finding temp files: loop"},
+		{name: "bad trash max age", args: []string{"--trash-max-age", "-1h"}, wantErr: "must be positive"},
+		{
+			name:    "trash manifest read",
+			setup:   func(m *mockGCIO) { m.readErrs = map[string]error{gcTrashPath: errors.New("denied")} },
+			wantErr: "reading trash manifest: denied",
+		},
+		{
+			name:    "trash manifest invalid",
+			setup:   func(m *mockGCIO) { m.files[gcTrashPath] = []byte("{not json") },
+			wantErr: "reading trash manifest: ",
+		},
+		{
+			name: "trash entry read",
+			setup: func(m *mockGCIO) {
+				m.readErrs = map[string]error{filepath.Join(gcTrashDir, "20260101T000000Z", "part", "gone.md"): errors.New("denied")}
+			},
+			wantErr: "reading trash entry 20260101T000000Z: denied",
+		},
+		{name: "trash remove", setup: func(m *mockGCIO) { m.rmDirErr = errors.New("busy") }, wantErr: "removing trash entry 20260101T000000Z: busy"},
+		{
+			name: "trash manifest write",
+			setup: func(m *mockGCIO) {
+				delete(m.files, gcJournalPath)
+				delete(m.files, gcCachePath)
+				m.writeErr = errors.New("full")
+			},
+			wantErr: "writing trash manifest: full",
+		},
+		{name: "json output", args: []string{"--json"}, out: &errWriter{errors.New("closed")}, wantErr: "encoding output"},
+		{name: "text output", out: &errWriter{errors.New("closed")}, wantErr: "writing output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newGCMock()
+			if tt.setup != nil {
+				tt.setup(mock)
+			}
+			c := NewGCCmd(mock)
+			if tt.out != nil {
+				c.SetOut(tt.out)
+			} else {
+				c.SetOut(new(bytes.Buffer))
+			}
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(append([]string{"--project", "/proj"}, tt.args...))
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileGCIO(t *testing.T) {
+	dir := t.TempDir()
+	var fio fileGCIO
+	path := filepath.Join(dir, "data.json")
+
+	if data, err := fio.ReadFileIfExists(path); err != nil || data != nil {
+		t.Errorf("ReadFileIfExists(missing) = %q, %v", data, err)
+	}
+	if err := fio.WriteFileAtomic(path, []byte("x")); err != nil {
+		t.Fatalf("WriteFileAtomic: %v", err)
+	}
+	if data, err := fio.ReadFileIfExists(path); err != nil || string(data) != "x" {
+		t.Errorf("ReadFileIfExists = %q, %v", data, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".binder-1.tmp"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := fio.FindTempFiles(dir); err != nil || len(found) != 1 {
+		t.Errorf("FindTempFiles = %+v, %v", found, err)
+	}
+	if err := fio.RemoveFile(path); err != nil {
+		t.Errorf("RemoveFile: %v", err)
+	}
+}
+
+func TestFileGCIO_PrunesTrash(t *testing.T) {
+	base := t.TempDir()
+	dir := filepath.Join(base, "proj")
+	outside := filepath.Join(base, "outside")
+	trashDir := filepath.Join(dir, filepath.FromSlash(core.TrashDir))
+	for _, d := range []string{filepath.Join(trashDir, "old", "part"), outside} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		filepath.Join(trashDir, "old", "part", "ch1.md"): "deleted long ago\n",
+		filepath.Join(outside, "keep.md"):                "not the trash's\n",
+		filepath.Join(trashDir, "manifest.json"): `{"version":"1","records":[` +
+			`{"id":"old","deleted":"2026-01-01T00:00:00Z","files":["part/ch1.md"]},` +
+			`{"id":"linked","deleted":"2026-01-01T00:00:00Z","files":["keep.md"]}]}`,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(trashDir, "linked")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	orig := nowUTCFunc
+	nowUTCFunc = func() string { return gcNow }
+	t.Cleanup(func() { nowUTCFunc = orig })
+
+	c := NewGCCmd(fileGCIO{})
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--project", dir})
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "Removed trash entry old\n") || strings.Contains(out.String(), "linked") {
+		t.Errorf("stdout = %q, want only the old deletion removed", out)
+	}
+	if _, err := os.Stat(filepath.Join(trashDir, "old")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("old deletion's directory: %v, want it removed", err)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "keep.md")); err != nil {
+		t.Errorf("file outside the trash: %v, want it kept", err)
+	}
+	manifest, err := os.ReadFile(filepath.Join(trashDir, "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(manifest), `"id": "linked"`) || strings.Contains(string(manifest), `"id": "old"`) {
+		t.Errorf("manifest = %s, want only the linked deletion kept", manifest)
+	}
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// GrepIO handles I/O for the grep command.
+type GrepIO interface {
+	ReadBinder(ctx context.Context, path string) ([]byte, error)
+	ReadNodeFile(path string) ([]byte, error)
+}
+
+// grepMatchJSON is the JSON output type for one matching node.
+type grepMatchJSON struct {
+	Selector string           `json:"selector"`
+	Title    string           `json:"title,omitempty"`
+	Lines    []node.BodyMatch `json:"lines,omitempty"`
+}
+
+// grepOutput is the JSON output schema for grep.
+type grepOutput struct {
+	Version string          `json:"version"`
+	Matches []grepMatchJSON `json:"matches"`
+}
+
+// NewGrepCmd creates the grep subcommand.
+func NewGrepCmd(io GrepIO) *cobra.Command {
+	return newGrepCmdWithGetCWD(io, os.Getwd)
+}
+
+func newGrepCmdWithGetCWD(io GrepIO, getwd func() (string, error)) *cobra.Command {
+	var (
+		queries    []string
+		ignoreCase bool
+		showLines  bool
+		jsonMode   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "grep [pattern]",
+		Short: "Find nodes by frontmatter fields and body text",
+		Long: "Find the binder's nodes whose frontmatter satisfies every --frontmatter\n" +
+			"condition and whose body matches the optional regular expression, and\n" +
+			"print one selector per line for use with other commands.\n\n" +
+			"Conditions:\n" +
+			"  key              the field is present\n" +
+			"  !key             the field is absent\n" +
+			"  key=value        also != < <= > >=\n\n" +
+			"Values compare as dates when both sides are dates (2026-01-01 or RFC 3339),\n" +
+			"as numbers when both are numbers, and as text otherwise. A list field\n" +
+			"matches when any item does; a missing field matches only !key and !=.\n\n" +
+			"Example: pmk grep --frontmatter status=draft --frontmatter 'updated>2026-01-01' TODO",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 && len(queries) == 0 {
+				return fmt.Errorf("give a body pattern, --frontmatter conditions, or both")
+			}
+			var preds []node.FrontmatterPredicate
+			for _, q := range queries {
+				p, err := node.ParseFrontmatterPredicate(q)
+				if err != nil {
+					return err
+				}
+				preds = append(preds, p)
+			}
+			var re *regexp.Regexp
+			if len(args) == 1 {
+				expr := args[0]
+				if ignoreCase {
+					expr = "(?i)" + expr
+				}
+				var err error
+				if re, err = regexp.Compile(expr); err != nil {
+					return fmt.Errorf("invalid pattern: %w", err)
+				}
+			}
+
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			binderBytes, err := io.ReadBinder(ctx, binderPath)
+			if err != nil {
+				return fmt.Errorf("reading binder: %w", err)
+			}
+			parsed, _, err := binder.Parse(ctx, binderBytes, nil)
+			if err != nil {
+				return fmt.Errorf("cannot parse binder: %w", err)
+			}
+
+			out := grepOutput{Version: "1", Matches: []grepMatchJSON{}}
+			projectDir := filepath.Dir(binderPath)
+			for _, n := range binderTargetNodes(parsed.Root) {
+				nodePath, ok := resolveTargetPath(cmd, projectDir, n.Target)
+				if !ok {
+					continue
+				}
+				content, err := io.ReadNodeFile(nodePath)
+				if errors.Is(err, node.ErrLockedBody) {
+					return fmt.Errorf("%s: %w", n.Target, err)
+				}
+				if err != nil {
+					continue // missing files are doctor's concern (AUD001)
+				}
+				if m, ok := grepNode(n, content, preds, re); ok {
+					out.Matches = append(out.Matches, m)
+				}
+			}
+
+			if jsonMode {
+				if err := encodeOutput(cmd, out); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+				return nil
+			}
+			w := cmd.OutOrStdout()
+			for _, m := range out.Matches {
+				if !showLines || re == nil {
+					fmt.Fprintln(w, sanitizePath(m.Selector))
+					continue
+				}
+				for _, l := range m.Lines {
+					fmt.Fprintf(w, "%s:%d:%s\n", sanitizePath(m.Selector), l.Line, sanitizePath(l.Text))
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().StringArrayVar(&queries, "frontmatter", nil, "frontmatter condition the node must satisfy (repeatable)")
+	cmd.Flags().BoolVarP(&ignoreCase, "ignore-case", "i", false, "match the body pattern case-insensitively")
+	cmd.Flags().BoolVarP(&showLines, "lines", "n", false, "print each matching body line as selector:line:text")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+
+	return cmd
+}
+
+// grepNode reports whether a node file satisfies every predicate and, when
+// re is set, has a body line re matches.
+func grepNode(n *binder.Node, content []byte, preds []node.FrontmatterPredicate, re *regexp.Regexp) (grepMatchJSON, bool) {
+	fields := node.FrontmatterFields(content)
+	for _, p := range preds {
+		if !p.Match(fields) {
+			return grepMatchJSON{}, false
+		}
+	}
+	m := grepMatchJSON{Selector: n.Target, Title: n.Title}
+	if re != nil {
+		if m.Lines = node.MatchBodyLines(content, re); m.Lines == nil {
+			return grepMatchJSON{}, false
+		}
+	}
+	return m, true
+}
+
+// fileGrepIO implements GrepIO using OS file I/O.
+type fileGrepIO struct {
+	fileProjectIO
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// mockGrepIO is a test double for GrepIO rooted at /proj.
+type mockGrepIO struct {
+	binderBytes []byte
+	binderErr   error
+	files       map[string]string // full path → content; missing paths do not exist
+	readErr     error
+}
+
+func (m *mockGrepIO) ReadBinder(_ context.Context, _ string) ([]byte, error) {
+	return m.binderBytes, m.binderErr
+}
+
+func (m *mockGrepIO) ReadNodeFile(path string) ([]byte, error) {
+	if m.readErr != nil {
+		return nil, m.readErr
+	}
+	content, ok := m.files[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return []byte(content), nil
+}
+
+func newGrepMock() *mockGrepIO {
+	return &mockGrepIO{
+		binderBytes: []byte("<!-- prosemark-binder:v1 -->\n" +
+			"- [One](one.md)\n" +
+			"- [Two](part/two.md)\n" +
+			"- [Three](three.md)\n" +
+			"- [Missing](missing.md)\n" +
+			"- [Escape](/abs.md)\n"),
+		files: map[string]string{
+			"/proj/one.md":      "---\nid: one\nstatus: draft\nupdated: 2026-02-01T00:00:00Z\n---\nTODO: opening\n",
+			"/proj/part/two.md": "---\nid: two\nstatus: final\nupdated: 2025-12-01T00:00:00Z\n---\nNothing to do.\n",
+			"/proj/three.md":    "No frontmatter. todo later.\n",
+		},
+	}
+}
+
+func runGrep(t *testing.T, mock *mockGrepIO, args ...string) (string, error) {
+	t.Helper()
+	c := NewGrepCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(append([]string{"--project", "/proj"}, args...))
+	err := c.Execute()
+	return out.String(), err
+}
+
+func TestGrep(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"frontmatter equality", []string{"--frontmatter", "status=draft"}, "one.md\n"},
+		{"date comparison", []string{"--frontmatter", "updated<2026-01-01"}, "part/two.md\n"},
+		{"absent field", []string{"--frontmatter", "!status"}, "three.md\n"},
+		{"conditions combine", []string{"--frontmatter", "status", "--frontmatter", "updated>2026-01-01"}, "one.md\n"},
+		{"body pattern", []string{"TODO"}, "one.md\n"},
+		{"ignore case", []string{"-i", "todo"}, "one.md\nthree.md\n"},
+		{"pattern and frontmatter", []string{"-i", "todo", "--frontmatter", "!status"}, "three.md\n"},
+		{"lines", []string{"-n", "-i", "to ?do"}, "one.md:6:TODO: opening\npart/two.md:6:Nothing to do.\nthree.md:1:No frontmatter. todo later.\n"},
+		{"lines without pattern", []string{"-n", "--frontmatter", "status=final"}, "part/two.md\n"},
+		{"no match", []string{"--frontmatter", "status=published"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := runGrep(t, newGrepMock(), tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out != tt.want {
+				t.Errorf("stdout = %q, want %q", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrep_JSON(t *testing.T) {
+	out, err := runGrep(t, newGrepMock(), "--json", "-i", "todo", "--frontmatter", "status")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got grepOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("decoding %q: %v", out, err)
+	}
+	if len(got.Matches) != 1 {
+		t.Fatalf("matches = %+v", got.Matches)
+	}
+	m := got.Matches[0]
+	if m.Selector != "one.md" || m.Title != "One" || len(m.Lines) != 1 || m.Lines[0] != (node.BodyMatch{Line: 6, Text: "TODO: opening"}) {
+		t.Errorf("match = %+v", m)
+	}
+}
+
+func TestGrep_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(m *mockGrepIO)
+		args    []string
+		out     *errWriter
+		wantErr string
+	}{
+		{name: "nothing to match", wantErr: "give a body pattern"},
+		{name: "bad condition", args: []string{"--frontmatter", "=x"}, wantErr: `invalid frontmatter query "=x"`},
+		{name: "bad pattern", args: []string{"("}, wantErr: "invalid pattern"},
+		{name: "empty project", args: []string{"x", "--project", ""}, wantErr: "--project flag cannot be empty"},
+		{name: "binder read", args: []string{"x"}, setup: func(m *mockGrepIO) { m.binderErr = errors.New("denied") }, wantErr: "reading binder: denied"},
+		{name: "binder parse", args: []string{"x"}, setup: func(m *mockGrepIO) { m.binderBytes = []byte{0xff} }, wantErr: "cannot parse binder"},
+		{
+			name: "locked node", args: []string{"x"},
+			setup:   func(m *mockGrepIO) { m.readErr = node.ErrLockedBody },
+			wantErr: "one.md: node body is locked",
+		},
+		{name: "json output", args: []string{"x", "--json"}, out: &errWriter{errors.New("closed")}, wantErr: "encoding output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newGrepMock()
+			if tt.setup != nil {
+				tt.setup(mock)
+			}
+			c := NewGrepCmd(mock)
+			if tt.out != nil {
+				c.SetOut(tt.out)
+			} else {
+				c.SetOut(new(bytes.Buffer))
+			}
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(append([]string{"--project", "/proj"}, tt.args...))
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileGrepIO(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	if err := os.WriteFile(binderPath, []byte("- [A](a.md)\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.md"), []byte("---\nstatus: draft\n---\nTODO\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c := NewGrepCmd(fileGrepIO{})
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetArgs([]string{"--project", dir, "--frontmatter", "status=draft", "TODO"})
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "a.md\n" {
+		t.Errorf("stdout = %q", out.String())
+	}
+}
+
+func TestFileGrepIO_SymlinkEscapeReported(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "_binder.md"), []byte("- [A](a.md)\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(t.TempDir(), "secret.md")
+	if err := os.WriteFile(outside, []byte("TODO\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "a.md")); err != nil {
+		t.Fatal(err)
+	}
+	c := NewGrepCmd(fileGrepIO{})
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(errOut)
+	c.SetArgs([]string{"--project", dir, "TODO"})
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Len() != 0 || !strings.Contains(errOut.String(), "outside the project root: a.md (BNDE002)") {
+		t.Errorf("stdout = %q, stderr = %q; want no match and a BNDE002 diagnostic", out, errOut)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 
 	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/fsio"
 )
 
 // InitIO handles I/O for the init command.
@@ -33,7 +35,11 @@ func newInitCmdWithGetCWD(io InitIO, getwd func() (string, error)) *cobra.Comman
 				return err
 			}
 
-			binderPath := filepath.Join(project, "_binder.md")
+			binderName, err := binderNameFromCmd(cmd, project)
+			if err != nil {
+				return err
+			}
+			binderPath := filepath.Join(project, binderName)
 			configPath := filepath.Join(project, ".prosemark.yml")
 
 			binderExists, err := io.StatFile(binderPath)
@@ -41,12 +47,12 @@ func newInitCmdWithGetCWD(io InitIO, getwd func() (string, error)) *cobra.Comman
 				return fmt.Errorf("checking %s: %w", binderPath, err)
 			}
 			if binderExists && !force {
-				return fmt.Errorf("_binder.md already exists in %s; use --force to overwrite", project)
+				return fmt.Errorf("%s already exists in %s; use --force to overwrite", binderName, project)
 			}
 
 			const binderContent = "<!-- prosemark-binder:v1 -->\n"
 			if err := io.WriteFileAtomic(binderPath, binderContent); err != nil {
-				return fmt.Errorf("writing _binder.md: %w", err)
+				return fmt.Errorf("writing %s: %w", binderName, err)
 			}
 
 			configExists, err := io.StatFile(configPath)
@@ -85,50 +91,10 @@ type fileInitIO struct{}
 // StatFile returns true if the file at path exists, false if it does not.
 // Returns an error only for unexpected OS errors.
 func (f fileInitIO) StatFile(path string) (bool, error) {
-	return f.StatFileImpl(path)
-}
-
-// StatFileImpl wraps os.Stat to check file existence.
-func (f fileInitIO) StatFileImpl(path string) (bool, error) {
-	_, err := os.Stat(path)
-	if err == nil {
-		return true, nil
-	}
-	if os.IsNotExist(err) {
-		return false, nil
-	}
-	return false, err
+	return fsio.StatFile(path)
 }
 
 // WriteFileAtomic writes content to path atomically via a temp file with 0600 permissions.
 func (f fileInitIO) WriteFileAtomic(path, content string) error {
-	return f.WriteFileAtomicImpl(path, content)
-}
-
-// WriteFileAtomicImpl performs the atomic write via OS temp file rename.
-func (f fileInitIO) WriteFileAtomicImpl(path, content string) error {
-	dir := filepath.Dir(path)
-	tmp, err := os.CreateTemp(dir, ".init-*.tmp")
-	if err != nil {
-		return fmt.Errorf("creating temp file: %w", err)
-	}
-	tmpName := tmp.Name()
-	if _, err = tmp.Write([]byte(content)); err != nil {
-		_ = tmp.Close()
-		_ = os.Remove(tmpName)
-		return fmt.Errorf("writing temp file: %w", err)
-	}
-	if err = tmp.Close(); err != nil {
-		_ = os.Remove(tmpName)
-		return fmt.Errorf("closing temp file: %w", err)
-	}
-	if err = os.Chmod(tmpName, 0600); err != nil {
-		_ = os.Remove(tmpName)
-		return fmt.Errorf("setting permissions: %w", err)
-	}
-	if err = os.Rename(tmpName, path); err != nil {
-		_ = os.Remove(tmpName)
-		return fmt.Errorf("renaming temp file: %w", err)
-	}
-	return nil
+	return fsio.WriteFileAtomic(path, ".init", []byte(content))
 }
@@ -3,6 +3,7 @@ package cmd
 import (
 	"bytes"
 	"errors"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -258,3 +259,23 @@ func TestNewRootCmd_RegistersInitSubcommand(t *testing.T) {
 		t.Error("expected \"init\" subcommand registered on root command")
 	}
 }
+
+// TestNewInitCmd_InvalidConfig verifies that init fails with the error of a
+// project config too broken to name the binder, writing nothing.
+func TestNewInitCmd_InvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".prosemark.yml"), []byte("id_scheme: bogus\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	mock := newMockInitIO()
+	c := NewInitCmd(mock)
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--project", dir})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), `unknown id_scheme "bogus"`) {
+		t.Errorf("err = %v, want the config error", err)
+	}
+	if len(mock.written) != 0 {
+		t.Errorf("wrote %v after the config error", mock.written)
+	}
+}
@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+// JournalIO handles I/O for the journal command.
+type JournalIO interface {
+	core.BinderIO
+	// ReadJournal returns the entries of projectDir's operation journal,
+	// oldest first.
+	ReadJournal(projectDir string) ([]fsio.JournalEntry, error)
+	// RecordOp appends entry to projectDir's operation journal.
+	RecordOp(projectDir string, entry fsio.JournalEntry) error
+	// ReadReplayFile reads the exported journal at path.
+	ReadReplayFile(path string) ([]byte, error)
+}
+
+// Statuses of a replayed journal entry.
+const (
+	replayApplied = "applied"
+	replaySkipped = "skipped"
+	replayFailed  = "failed"
+)
+
+// replayedOp is the JSON output type for one replayed journal entry.
+type replayedOp struct {
+	OpID        string              `json:"op_id"`
+	Command     string              `json:"command"`
+	Status      string              `json:"status"`
+	Diagnostics []binder.Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// replayOutput is the JSON output schema for journal replay.
+type replayOutput struct {
+	Version    string       `json:"version"`
+	Operations []replayedOp `json:"operations"`
+}
+
+// NewJournalCmd creates the journal command with export and replay
+// subcommands.
+func NewJournalCmd(io JournalIO) *cobra.Command {
+	return newJournalCmdWithGetCWD(io, os.Getwd)
+}
+
+func newJournalCmdWithGetCWD(io JournalIO, getwd func() (string, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "journal",
+		Short: "Export recorded operations and replay them in another copy of the project",
+		Long: "Export recorded operations and replay them in another copy of the project.\n\n" +
+			"Binder operations run with --op-id are recorded in .prosemark/journal.jsonl\n" +
+			"together with the binder they started from and left. export prints the\n" +
+			"journal, and replay applies an exported journal to this copy of the\n" +
+			"project: a crude sync for working on two computers. Only the binder is\n" +
+			"replayed; copy new node files alongside.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.PersistentFlags().String("project", "", "project directory containing the binder (default: current directory)")
+
+	cmd.AddCommand(newJournalExportCmd(io, getwd))
+	cmd.AddCommand(newJournalReplayCmd(io, getwd))
+	return cmd
+}
+
+func newJournalExportCmd(jio JournalIO, getwd func() (string, error)) *cobra.Command {
+	var since string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Print the operation journal as JSON lines",
+		Long: "Print the operation journal as JSON lines, oldest first, for journal\n" +
+			"replay. --since leaves out operations applied before a time, given in\n" +
+			"RFC 3339 (2026-03-01T12:00:00Z) or as a date (2026-03-01, UTC).",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var cutoff time.Time
+			if since != "" {
+				var err error
+				if cutoff, err = parseSince(since); err != nil {
+					return err
+				}
+			}
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			entries, err := jio.ReadJournal(filepath.Dir(binderPath))
+			if err != nil {
+				return fmt.Errorf("reading operation journal: %w", err)
+			}
+
+			var buf bytes.Buffer
+			for _, e := range entries {
+				if applied, err := time.Parse(time.RFC3339, e.Time); err == nil && applied.Before(cutoff) {
+					continue
+				}
+				// Journal entries hold only strings, numbers, and diagnostics.
+				line, _ := json.Marshal(e)
+				buf.Write(line)
+				buf.WriteByte('\n')
+			}
+			if _, err := cmd.OutOrStdout().Write(buf.Bytes()); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "export only operations applied at or after this time")
+	return cmd
+}
+
+// parseSince parses a --since time: RFC 3339, or a date taken as midnight
+// UTC.
+func parseSince(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: want an RFC 3339 time or a YYYY-MM-DD date", s)
+}
+
+func newJournalReplayCmd(jio JournalIO, getwd func() (string, error)) *cobra.Command {
+	var jsonMode, force bool
+
+	cmd := &cobra.Command{
+		Use:   "replay <journal.jsonl>",
+		Short: "Apply exported operations to this copy of the project",
+		Long: "Apply the operations of an exported journal to this copy of the project, in\n" +
+			"order. Each must start from the binder it was recorded against; once the\n" +
+			"binder has diverged, replay stops with PMKE011, and --force applies the\n" +
+			"operations anyway, resolving their selectors against the binder as it is.\n" +
+			"Operations already in this project's journal, or whose result the binder\n" +
+			"already holds, are skipped, so replaying the same export twice is safe.\n" +
+			"Replayed operations are recorded under their original --op-id. Use - to\n" +
+			"read the export from stdin.",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			var data []byte
+			if args[0] == "-" {
+				data, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				data, err = jio.ReadReplayFile(args[0])
+			}
+			if err != nil {
+				return fmt.Errorf("reading journal export: %w", err)
+			}
+			entries, err := parseJournalExport(data)
+			if err != nil {
+				return err
+			}
+
+			out := replayOutput{Version: "1", Operations: []replayedOp{}}
+			replayErr := replayEntries(cmd.Context(), jio, binderPath, entries, force, &out)
+
+			if jsonMode {
+				if err := encodeOutput(cmd, out); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+				return replayErr
+			}
+			w := cmd.OutOrStdout()
+			applied := 0
+			for _, op := range out.Operations {
+				switch op.Status {
+				case replayApplied:
+					applied++
+					fmt.Fprintf(w, "Applied %s (%s)\n", op.OpID, op.Command)
+				case replaySkipped:
+					fmt.Fprintf(w, "Skipped %s (%s): already applied\n", op.OpID, op.Command)
+				default:
+					printDiagnostics(cmd, op.Diagnostics)
+				}
+			}
+			if replayErr != nil {
+				return replayErr
+			}
+			fmt.Fprintf(w, "Replayed %d of %d operations to %s\n", applied, len(out.Operations), sanitizePath(binderPath))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "apply operations even when the binder has diverged from the one they were recorded against")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+	return cmd
+}
+
+// parseJournalExport decodes the JSON lines of a journal export. Unlike the
+// journal itself, an export with a line that does not decode is refused.
+func parseJournalExport(data []byte) ([]fsio.JournalEntry, error) {
+	var entries []fsio.JournalEntry
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		e, ok := fsio.ParseJournalLine(line)
+		if !ok {
+			return nil, fmt.Errorf("journal export line %d is not a journal entry", i+1)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// replayEntries applies entries to the binder at binderPath in order,
+// adding each outcome to out, and stops at the first that cannot be
+// applied.
+func replayEntries(ctx context.Context, jio JournalIO, binderPath string, entries []fsio.JournalEntry, force bool, out *replayOutput) error {
+	projectDir := filepath.Dir(binderPath)
+	local, err := jio.ReadJournal(projectDir)
+	if err != nil {
+		return fmt.Errorf("reading operation journal: %w", err)
+	}
+	known := make(map[string]bool, len(local))
+	for _, e := range local {
+		known[e.OpID] = true
+	}
+
+	for _, e := range entries {
+		op := replayedOp{OpID: e.OpID, Command: e.Command, Status: replaySkipped}
+		if known[e.OpID] {
+			out.Operations = append(out.Operations, op)
+			continue
+		}
+		src, err := jio.ReadBinder(ctx, binderPath)
+		if err != nil {
+			return fmt.Errorf("reading binder: %w", err)
+		}
+		var res *binder.OpResult
+		if e.BinderAfter != "" && e.BinderAfter != e.BinderBefore && core.BinderHash(src) == e.BinderAfter {
+			// The binder already holds the operation's result, say because
+			// it was copied over: record the operation without applying it.
+			res = &binder.OpResult{Version: "1", Diagnostics: []binder.Diagnostic{}, BinderBefore: e.BinderAfter, BinderAfter: e.BinderAfter}
+		} else {
+			if len(e.Ops) == 0 {
+				return fmt.Errorf("cannot replay %s (%s): the journal does not record its operations", e.OpID, e.Command)
+			}
+			before := e.BinderBefore
+			if force {
+				before = ""
+			}
+			res, err = core.Replay(ctx, jio, binderPath, e.Ops, before)
+			if res == nil {
+				return fmt.Errorf("replaying %s (%s): %w", e.OpID, e.Command, err)
+			}
+			if hasDiagnosticError(res.Diagnostics) || err != nil {
+				op.Status, op.Diagnostics = replayFailed, res.Diagnostics
+				out.Operations = append(out.Operations, op)
+				return errors.Join(fmt.Errorf("replay stopped at %s (%s)", e.OpID, e.Command), err)
+			}
+			op.Status = replayApplied
+		}
+
+		result := *res
+		result.Diff = ""
+		entry := fsio.JournalEntry{
+			OpID:         e.OpID,
+			Command:      e.Command,
+			Time:         nowUTCFunc(),
+			ParamsHash:   e.ParamsHash,
+			Result:       result,
+			Ops:          e.Ops,
+			BinderBefore: res.BinderBefore,
+			BinderAfter:  res.BinderAfter,
+		}
+		if err := jio.RecordOp(projectDir, entry); err != nil {
+			return fmt.Errorf("recording operation journal: %w", err)
+		}
+		known[e.OpID] = true
+		out.Operations = append(out.Operations, op)
+	}
+	return nil
+}
+
+// fileJournalIO is the production implementation of JournalIO.
+type fileJournalIO struct {
+	fileProjectIO
+	binderLocker
+	opJournaler
+}
+
+// ReadJournal returns the entries of projectDir's operation journal.
+func (fileJournalIO) ReadJournal(projectDir string) ([]fsio.JournalEntry, error) {
+	return fsio.ReadJournal(projectDir)
+}
+
+// ReadReplayFile reads the exported journal at path.
+func (fileJournalIO) ReadReplayFile(path string) ([]byte, error) {
+	return fsio.ReadFile(path)
+}
@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+// mockJournalIO is a test double for JournalIO.
+type mockJournalIO struct {
+	binderBytes []byte
+	binderErr   error
+	scanErr     error
+	writeErr    error
+	written     []byte
+	memOpJournal
+	journalErr error
+	exported   []byte
+	exportErr  error
+}
+
+func (m *mockJournalIO) ReadBinder(_ context.Context, _ string) ([]byte, error) {
+	return m.binderBytes, m.binderErr
+}
+
+func (m *mockJournalIO) ScanProject(_ context.Context, _ string) (*binder.Project, error) {
+	if m.scanErr != nil {
+		return nil, m.scanErr
+	}
+	return &binder.Project{Files: []string{"chapter-one.md", "chapter-two.md"}, BinderDir: "."}, nil
+}
+
+func (m *mockJournalIO) WriteBinderAtomic(_ context.Context, _ string, data []byte) error {
+	if m.writeErr != nil {
+		return m.writeErr
+	}
+	m.written, m.binderBytes = data, data
+	return nil
+}
+
+func (m *mockJournalIO) ReadJournal(_ string) ([]fsio.JournalEntry, error) {
+	return m.entries, m.journalErr
+}
+
+func (m *mockJournalIO) ReadReplayFile(_ string) ([]byte, error) {
+	return m.exported, m.exportErr
+}
+
+func runJournalCmd(t *testing.T, mock *mockJournalIO, stdin string, args ...string) (string, string, error) {
+	t.Helper()
+	orig := nowUTCFunc
+	nowUTCFunc = func() string { return "2026-03-01T12:00:00Z" }
+	t.Cleanup(func() { nowUTCFunc = orig })
+
+	c := NewJournalCmd(mock)
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(errOut)
+	c.SetIn(strings.NewReader(stdin))
+	c.SetArgs(append(args, "--project", "/proj"))
+	err := c.Execute()
+	return out.String(), errOut.String(), err
+}
+
+// movedBinder is moveBinder with chapter-two moved first.
+const movedBinder = "<!-- prosemark-binder:v1 -->\n- [Chapter Two](chapter-two.md)\n- [Chapter One](chapter-one.md)\n"
+
+// moveEntry is the journal entry of moving chapter-two first in moveBinder.
+func moveEntry(opID string) fsio.JournalEntry {
+	return fsio.JournalEntry{
+		OpID:       opID,
+		Command:    "move",
+		Time:       "2026-02-01T00:00:00Z",
+		ParamsHash: "params",
+		Ops: []binder.OpSpec{binder.NewOpSpec(binder.OpMove, binder.MoveParams{
+			SourceSelector: "chapter-two.md", DestinationParentSelector: ".", Position: "first", Yes: true,
+		})},
+		BinderBefore: core.BinderHash(moveBinder()),
+		BinderAfter:  core.BinderHash([]byte(movedBinder)),
+	}
+}
+
+// exportLines encodes entries as a journal export.
+func exportLines(t *testing.T, entries ...fsio.JournalEntry) string {
+	t.Helper()
+	var b strings.Builder
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func TestJournalExport(t *testing.T) {
+	old, recent := moveEntry("op-1"), moveEntry("op-2")
+	old.Time, recent.Time = "2026-01-01T00:00:00Z", "2026-03-01T00:00:00Z"
+	mock := &mockJournalIO{memOpJournal: memOpJournal{entries: []fsio.JournalEntry{old, recent}}}
+
+	out, _, err := runJournalCmd(t, mock, "", "export")
+	if err != nil || out != exportLines(t, old, recent) {
+		t.Errorf("export = %q, %v", out, err)
+	}
+	for _, since := range []string{"2026-02-01", "2026-02-01T00:00:00Z"} {
+		out, _, err = runJournalCmd(t, mock, "", "export", "--since", since)
+		if err != nil || out != exportLines(t, recent) {
+			t.Errorf("export --since %s = %q, %v", since, out, err)
+		}
+	}
+}
+
+func TestJournalExport_Errors(t *testing.T) {
+	if _, _, err := runJournalCmd(t, &mockJournalIO{}, "", "export", "--since", "last week"); err == nil || !strings.Contains(err.Error(), "invalid --since") {
+		t.Errorf("bad --since: error = %v", err)
+	}
+	if _, _, err := runJournalCmd(t, &mockJournalIO{journalErr: errors.New("boom")}, "", "export"); err == nil || !strings.Contains(err.Error(), "reading operation journal") {
+		t.Errorf("journal error: error = %v", err)
+	}
+}
+
+func TestJournalReplay_AppliesAndRecords(t *testing.T) {
+	mock := &mockJournalIO{binderBytes: moveBinder(), exported: []byte(exportLines(t, moveEntry("op-1")))}
+	out, _, err := runJournalCmd(t, mock, "", "replay", "export.jsonl")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "Applied op-1 (move)\nReplayed 1 of 1 operations to /proj/_binder.md\n"; out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+	if string(mock.written) != movedBinder {
+		t.Errorf("binder = %q, want %q", mock.written, movedBinder)
+	}
+	if len(mock.entries) != 1 {
+		t.Fatalf("journal has %d entries, want 1", len(mock.entries))
+	}
+	got := mock.entries[0]
+	if got.OpID != "op-1" || got.Command != "move" || got.Time != "2026-03-01T12:00:00Z" || got.ParamsHash != "params" || !got.Result.Changed || len(got.Ops) != 1 || got.BinderAfter != core.BinderHash([]byte(movedBinder)) {
+		t.Errorf("recorded entry = %+v", got)
+	}
+
+	// Replaying the same export again skips what is already recorded.
+	mock.written = nil
+	out, _, err = runJournalCmd(t, mock, "", "replay", "export.jsonl")
+	if err != nil || mock.written != nil || !strings.Contains(out, "Skipped op-1 (move): already applied") {
+		t.Errorf("second replay = %q, %v; wrote %q", out, err, mock.written)
+	}
+}
+
+func TestJournalReplay_BinderAlreadyHoldsResult(t *testing.T) {
+	mock := &mockJournalIO{binderBytes: []byte(movedBinder)}
+	out, _, err := runJournalCmd(t, mock, exportLines(t, moveEntry("op-1")), "replay", "-", "--json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got replayOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil || len(got.Operations) != 1 || got.Operations[0].Status != replaySkipped {
+		t.Errorf("output = %s (%v)", out, err)
+	}
+	if mock.written != nil || len(mock.entries) != 1 {
+		t.Errorf("written = %q, journal = %+v; want no write and the op recorded", mock.written, mock.entries)
+	}
+}
+
+func TestJournalReplay_DivergedBinder(t *testing.T) {
+	diverged := "<!-- prosemark-binder:v1 -->\n- [Chapter One](chapter-one.md)\n- [Chapter Two](chapter-two.md)\n- [Three](three.md)\n"
+	mock := &mockJournalIO{binderBytes: []byte(diverged), exported: []byte(exportLines(t, moveEntry("op-1")))}
+	_, errOut, err := runJournalCmd(t, mock, "", "replay", "export.jsonl")
+	if err == nil || !strings.Contains(err.Error(), "replay stopped at op-1 (move)") || !strings.Contains(errOut, core.CodeBinderMismatch) {
+		t.Fatalf("error = %v, stderr = %q; want a %s stop", err, errOut, core.CodeBinderMismatch)
+	}
+	if mock.written != nil || len(mock.entries) != 0 {
+		t.Errorf("diverged replay wrote %q and recorded %+v", mock.written, mock.entries)
+	}
+
+	out, _, err := runJournalCmd(t, mock, "", "replay", "export.jsonl", "--json")
+	var got replayOutput
+	if err == nil || json.Unmarshal([]byte(out), &got) != nil || got.Operations[0].Status != replayFailed || got.Operations[0].Diagnostics[0].Code != core.CodeBinderMismatch {
+		t.Errorf("--json output = %s, error = %v", out, err)
+	}
+
+	if _, _, err := runJournalCmd(t, mock, "", "replay", "export.jsonl", "--force"); err != nil {
+		t.Fatalf("--force: %v", err)
+	}
+	if want := "<!-- prosemark-binder:v1 -->\n- [Chapter Two](chapter-two.md)\n- [Chapter One](chapter-one.md)\n- [Three](three.md)\n"; string(mock.written) != want {
+		t.Errorf("--force binder = %q, want %q", mock.written, want)
+	}
+}
+
+func TestJournalReplay_Errors(t *testing.T) {
+	noOps := moveEntry("op-1")
+	noOps.Ops = nil
+	tests := []struct {
+		name string
+		mock *mockJournalIO
+		want string
+	}{
+		{"unreadable export", &mockJournalIO{exportErr: os.ErrNotExist}, "reading journal export"},
+		{"bad line", &mockJournalIO{exported: []byte("{\"op_id\":\"a\"}\nnot json\n")}, "line 2 is not a journal entry"},
+		{"journal error", &mockJournalIO{exported: []byte(exportLines(t, moveEntry("op-1"))), journalErr: errors.New("boom")}, "reading operation journal"},
+		{"binder error", &mockJournalIO{exported: []byte(exportLines(t, moveEntry("op-1"))), binderErr: errors.New("boom")}, "reading binder"},
+		{"no ops", &mockJournalIO{binderBytes: moveBinder(), exported: []byte(exportLines(t, noOps))}, "does not record its operations"},
+		{"scan error", &mockJournalIO{binderBytes: moveBinder(), exported: []byte(exportLines(t, moveEntry("op-1"))), scanErr: errors.New("boom")}, "replaying op-1 (move)"},
+		{"write error", &mockJournalIO{binderBytes: moveBinder(), exported: []byte(exportLines(t, moveEntry("op-1"))), writeErr: errors.New("disk full")}, "disk full"},
+		{"record error", &mockJournalIO{binderBytes: moveBinder(), exported: []byte(exportLines(t, moveEntry("op-1"))), memOpJournal: memOpJournal{recordErr: errors.New("boom")}}, "recording operation journal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runJournalCmd(t, tt.mock, "", "replay", "export.jsonl")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestJournal_OutputErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		mock *mockJournalIO
+		args []string
+		want string
+	}{
+		{"export", &mockJournalIO{memOpJournal: memOpJournal{entries: []fsio.JournalEntry{moveEntry("op-1")}}}, []string{"export"}, "writing output: closed"},
+		{"replay --json", &mockJournalIO{binderBytes: moveBinder(), exported: []byte(exportLines(t, moveEntry("op-1")))}, []string{"replay", "export.jsonl", "--json"}, "encoding output: closed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewJournalCmd(tt.mock)
+			c.SetOut(&errWriter{errors.New("closed")})
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(append(tt.args, "--project", "/proj"))
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestJournal_NoSubcommandShowsHelp(t *testing.T) {
+	out, _, err := runJournalCmd(t, &mockJournalIO{}, "")
+	if err != nil || !strings.Contains(out, "replay") {
+		t.Errorf("help = %q, %v", out, err)
+	}
+}
+
+func TestJournal_ProjectDirError(t *testing.T) {
+	c := newJournalCmdWithGetCWD(&mockJournalIO{}, func() (string, error) { return "", errors.New("no cwd") })
+	for _, args := range [][]string{{"export"}, {"replay", "-"}} {
+		if _, err := runCmdInCWD(c, args...); err == nil {
+			t.Errorf("%v: want error when the working directory is unknown", args)
+		}
+	}
+}
+
+func TestFileJournalIO(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	io := fileJournalIO{}
+	ctx := context.Background()
+	if err := io.WriteBinderAtomic(ctx, binderPath, moveBinder()); err != nil {
+		t.Fatalf("WriteBinderAtomic: %v", err)
+	}
+	if got, err := io.ReadBinder(ctx, binderPath); err != nil || !bytes.Equal(got, moveBinder()) {
+		t.Errorf("ReadBinder = %q, %v", got, err)
+	}
+	if _, err := io.ScanProject(ctx, binderPath); err != nil {
+		t.Errorf("ScanProject: %v", err)
+	}
+	if err := io.RecordOp(dir, moveEntry("op-1")); err != nil {
+		t.Fatalf("RecordOp: %v", err)
+	}
+	if entries, err := io.ReadJournal(dir); err != nil || len(entries) != 1 || len(entries[0].Ops) != 1 {
+		t.Errorf("ReadJournal = %+v, %v", entries, err)
+	}
+	if got, err := io.ReadReplayFile(binderPath); err != nil || !bytes.Equal(got, moveBinder()) {
+		t.Errorf("ReadReplayFile = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// LockIO handles I/O for the lock and unlock commands.
+type LockIO interface {
+	ReadBinder(ctx context.Context, path string) ([]byte, error)
+	// ReadNodeFile reads the node file at path as stored, without unlocking it.
+	ReadNodeFile(path string) ([]byte, error)
+	WriteNodeFileAtomic(path string, content []byte) error
+	ReadKeyFile(path string) (node.BodyKey, error)
+	// CreateKeyFile writes a new key file, failing if one exists at path.
+	CreateKeyFile(path string, key node.BodyKey) error
+}
+
+// newBodyKey and lockBody generate keys and lock bodies.
+// Override in tests to simulate entropy and cipher failures.
+var (
+	newBodyKey = node.NewBodyKey
+	lockBody   = node.LockBody
+)
+
+// lockOutput is the JSON output schema for lock and unlock.
+type lockOutput struct {
+	Version string   `json:"version"`
+	Files   []string `json:"files"`
+}
+
+// NewLockCmd creates the lock subcommand, which encrypts node bodies.
+func NewLockCmd(io LockIO) *cobra.Command {
+	return newLockStateCmdWithGetCWD(io, os.Getwd, true)
+}
+
+// NewUnlockCmd creates the unlock subcommand, which decrypts node bodies.
+func NewUnlockCmd(io LockIO) *cobra.Command {
+	return newLockStateCmdWithGetCWD(io, os.Getwd, false)
+}
+
+func newLockStateCmdWithGetCWD(io LockIO, getwd func() (string, error), lock bool) *cobra.Command {
+	var (
+		keyFile     string
+		generateKey bool
+		jsonMode    bool
+	)
+
+	use, short, verb := "lock", "Encrypt the bodies of the binder's node and notes files", "Locked"
+	if !lock {
+		use, short, verb = "unlock", "Decrypt the bodies of the binder's node and notes files", "Unlocked"
+	}
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Long: short + ".\n\n" +
+			"lock encrypts the body of every node file the binder references, and of\n" +
+			"each node's notes file, with AES-256-GCM under a project key; frontmatter\n" +
+			"and the binder stay readable, so the outline, doctor, and selectors keep\n" +
+			"working. Commands that read bodies (export, comments, check-links) unlock\n" +
+			"them transparently when $" + fsio.KeyFileEnv + " names the key file and refuse\n" +
+			"otherwise; edit refuses locked nodes and notes. Keep the key file outside\n" +
+			"the project and back it up: locked bodies cannot be recovered without it.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if keyFile == "" {
+				return fmt.Errorf("--key-file or $%s is required", fsio.KeyFileEnv)
+			}
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+
+			var key node.BodyKey
+			if generateKey {
+				if key, err = newBodyKey(); err != nil {
+					return err
+				}
+				if err := io.CreateKeyFile(keyFile, key); err != nil {
+					return fmt.Errorf("creating key file: %w", err)
+				}
+				fmt.Fprintf(cmd.ErrOrStderr(), "Generated key file %s; back it up, locked bodies cannot be recovered without it\n", sanitizePath(keyFile))
+			} else if key, err = io.ReadKeyFile(keyFile); err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			binderBytes, err := io.ReadBinder(ctx, binderPath)
+			if err != nil {
+				return fmt.Errorf("reading binder: %w", err)
+			}
+			parsed, _, err := binder.Parse(ctx, binderBytes, nil)
+			if err != nil {
+				return fmt.Errorf("cannot parse binder: %w", err)
+			}
+
+			// Transform every file in memory before writing any, so a wrong
+			// key or damaged body leaves the project untouched.
+			type pendingWrite struct {
+				target, path string
+				content      []byte
+			}
+			var writes []pendingWrite
+			projectDir := filepath.Dir(binderPath)
+			seen := make(map[string]bool)
+			for _, n := range binderTargetNodes(parsed.Root) {
+				// Each node's notes file is locked and unlocked with it.
+				for _, target := range []string{n.Target, strings.TrimSuffix(n.Target, ".md") + ".notes.md"} {
+					if seen[target] {
+						continue
+					}
+					seen[target] = true
+					nodePath, ok := resolveTargetPath(cmd, projectDir, target)
+					if !ok {
+						continue
+					}
+					content, err := io.ReadNodeFile(nodePath)
+					if err != nil {
+						if errors.Is(err, os.ErrNotExist) {
+							continue // missing files are doctor's concern (AUD001)
+						}
+						return fmt.Errorf("reading node file: %w", err)
+					}
+					if node.BodyLocked(content) == lock {
+						continue
+					}
+					if lock {
+						content, err = lockBody(content, key)
+					} else {
+						content, err = node.UnlockBody(content, key)
+					}
+					if err != nil {
+						return fmt.Errorf("%s: %w", target, err)
+					}
+					writes = append(writes, pendingWrite{target, nodePath, content})
+				}
+			}
+
+			out := lockOutput{Version: "1", Files: []string{}}
+			for _, w := range writes {
+				if err := io.WriteNodeFileAtomic(w.path, w.content); err != nil {
+					return fmt.Errorf("writing %s: %w", w.target, err)
+				}
+				out.Files = append(out.Files, w.target)
+			}
+
+			if jsonMode {
+				if err := encodeOutput(cmd, out); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %d file(s)\n", verb, len(out.Files))
+			return nil
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().StringVar(&keyFile, "key-file", os.Getenv(fsio.KeyFileEnv), "project key file (default: $"+fsio.KeyFileEnv+")")
+	if lock {
+		cmd.Flags().BoolVar(&generateKey, "generate-key", false, "create a new key file at --key-file first")
+	}
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+
+	return cmd
+}
+
+// fileLockIO implements LockIO using OS file I/O.
+type fileLockIO struct {
+	fileProjectIO
+}
+
+// ReadNodeFile reads the node file at path without unlocking it.
+func (f fileLockIO) ReadNodeFile(path string) ([]byte, error) {
+	return fsio.ReadFile(path)
+}
+
+// WriteNodeFileAtomic writes content to path atomically.
+func (f fileLockIO) WriteNodeFileAtomic(path string, content []byte) error {
+	return fsio.WriteFileAtomic(path, ".node", content)
+}
+
+// ReadKeyFile reads the key file at path.
+func (f fileLockIO) ReadKeyFile(path string) (node.BodyKey, error) {
+	return fsio.ReadKeyFile(path)
+}
+
+// CreateKeyFile writes a new key file at path.
+func (f fileLockIO) CreateKeyFile(path string, key node.BodyKey) error {
+	return fsio.CreateKeyFile(path, key)
+}
@@ -0,0 +1,347 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// mockLockIO is a test double for LockIO rooted at /proj.
+type mockLockIO struct {
+	binderBytes []byte
+	binderErr   error
+	files       map[string][]byte // full path → content; missing paths do not exist
+	readErr     error
+	writeErr    error
+	key         node.BodyKey
+	keyErr      error
+	createErr   error
+	createdKey  *node.BodyKey
+}
+
+func (m *mockLockIO) ReadBinder(_ context.Context, _ string) ([]byte, error) {
+	return m.binderBytes, m.binderErr
+}
+
+func (m *mockLockIO) ReadNodeFile(path string) ([]byte, error) {
+	if m.readErr != nil {
+		return nil, m.readErr
+	}
+	content, ok := m.files[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return content, nil
+}
+
+func (m *mockLockIO) WriteNodeFileAtomic(path string, content []byte) error {
+	if m.writeErr != nil {
+		return m.writeErr
+	}
+	m.files[path] = content
+	return nil
+}
+
+func (m *mockLockIO) ReadKeyFile(_ string) (node.BodyKey, error) {
+	return m.key, m.keyErr
+}
+
+func (m *mockLockIO) CreateKeyFile(_ string, key node.BodyKey) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	m.createdKey = &key
+	return nil
+}
+
+const lockSecret = "---\nid: ch\n---\nThe source's name.\n"
+
+// testBodyKey returns a new random key, failing the test on error.
+func testBodyKey(t *testing.T) node.BodyKey {
+	t.Helper()
+	key, err := node.NewBodyKey()
+	if err != nil {
+		t.Fatalf("NewBodyKey: %v", err)
+	}
+	return key
+}
+
+// lockedContent returns content with its body locked under key.
+func lockedContent(t *testing.T, content []byte, key node.BodyKey) []byte {
+	t.Helper()
+	locked, err := node.LockBody(content, key)
+	if err != nil {
+		t.Fatalf("LockBody: %v", err)
+	}
+	return locked
+}
+
+func newLockMock(t *testing.T) *mockLockIO {
+	return &mockLockIO{
+		binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [Ch](ch.md)\n  - [Again](ch.md)\n- [Gone](gone.md)\n- [Out](/out.md)\n- [Notes](notes.md)\n- [Ch notes](ch.notes.md)\n"),
+		files: map[string][]byte{
+			"/proj/ch.md":       []byte(lockSecret),
+			"/proj/ch.notes.md": []byte("Ask about the source.\n"),
+			"/proj/notes.md":    []byte("No frontmatter here.\n"),
+		},
+		key: testBodyKey(t),
+	}
+}
+
+func runLockCmd(t *testing.T, c *cobra.Command, args ...string) (string, string, error) {
+	t.Helper()
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(errOut)
+	c.SetArgs(append([]string{"--project", "/proj", "--key-file", "/keys/proj.key"}, args...))
+	err := c.Execute()
+	return out.String(), errOut.String(), err
+}
+
+func TestLockUnlock_RoundTrip(t *testing.T) {
+	mock := newLockMock(t)
+
+	out, _, err := runLockCmd(t, NewLockCmd(mock))
+	if err != nil || out != "Locked 3 file(s)\n" {
+		t.Fatalf("lock: out = %q, err = %v", out, err)
+	}
+	for path, content := range mock.files {
+		if !node.BodyLocked(content) {
+			t.Errorf("%s not locked: %q", path, content)
+		}
+	}
+	if !bytes.HasPrefix(mock.files["/proj/ch.md"], []byte("---\nid: ch\n---\n")) {
+		t.Error("frontmatter was not kept readable")
+	}
+
+	out, _, err = runLockCmd(t, NewLockCmd(mock), "--json")
+	if err != nil || out != "{\"version\":\"1\",\"files\":[]}\n" {
+		t.Errorf("relock: out = %q, err = %v; want nothing to do", out, err)
+	}
+
+	out, _, err = runLockCmd(t, NewUnlockCmd(mock), "--json")
+	if err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+	var got lockOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil || strings.Join(got.Files, ",") != "ch.md,ch.notes.md,notes.md" {
+		t.Errorf("unlock output = %q (%v)", out, err)
+	}
+	if string(mock.files["/proj/ch.md"]) != lockSecret {
+		t.Errorf("unlocked ch.md = %q", mock.files["/proj/ch.md"])
+	}
+}
+
+func TestLock_GenerateKey(t *testing.T) {
+	mock := newLockMock(t)
+	mock.keyErr = errors.New("ReadKeyFile must not be called")
+	_, errOut, err := runLockCmd(t, NewLockCmd(mock), "--generate-key")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mock.createdKey == nil || !strings.Contains(errOut, "Generated key file /keys/proj.key; back it up") {
+		t.Fatalf("key not generated; stderr = %q", errOut)
+	}
+	if _, err := node.UnlockBody(mock.files["/proj/ch.md"], *mock.createdKey); err != nil {
+		t.Errorf("body not locked with the generated key: %v", err)
+	}
+	if NewUnlockCmd(mock).Flags().Lookup("generate-key") != nil {
+		t.Error("unlock must not offer --generate-key")
+	}
+}
+
+func TestUnlock_WrongKeyWritesNothing(t *testing.T) {
+	mock := newLockMock(t)
+	good := mock.key
+	mock.files["/proj/ch.md"] = lockedContent(t, mock.files["/proj/ch.md"], good)
+	mock.files["/proj/notes.md"] = lockedContent(t, mock.files["/proj/notes.md"], testBodyKey(t))
+	before := string(mock.files["/proj/ch.md"])
+
+	_, _, err := runLockCmd(t, NewUnlockCmd(mock))
+	if !errors.Is(err, node.ErrLockedBody) || !strings.HasPrefix(err.Error(), "notes.md: ") {
+		t.Errorf("err = %v, want a locked-body error naming notes.md", err)
+	}
+	if string(mock.files["/proj/ch.md"]) != before {
+		t.Error("ch.md was unlocked although another file failed")
+	}
+}
+
+func TestLock_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(m *mockLockIO)
+		args  []string
+		noKey bool
+		out   *errWriter
+		// keyGenErr and lockErr make key generation and locking fail.
+		keyGenErr, lockErr error
+		wantErr            string
+	}{
+		{name: "no key file", noKey: true, wantErr: "--key-file or $PMK_KEY_FILE is required"},
+		{name: "empty project", args: []string{"--project", ""}, wantErr: "--project flag cannot be empty"},
+		{name: "create key", args: []string{"--generate-key"}, setup: func(m *mockLockIO) { m.createErr = os.ErrExist }, wantErr: "creating key file: file already exists"},
+		{name: "read key", setup: func(m *mockLockIO) { m.keyErr = errors.New("invalid key file") }, wantErr: "invalid key file"},
+		{name: "binder read", setup: func(m *mockLockIO) { m.binderErr = errors.New("denied") }, wantErr: "reading binder: denied"},
+		{name: "binder parse", setup: func(m *mockLockIO) { m.binderBytes = []byte{0xff} }, wantErr: "cannot parse binder"},
+		{name: "node read", setup: func(m *mockLockIO) { m.readErr = errors.New("io") }, wantErr: "reading node file: io"},
+		{name: "node write", setup: func(m *mockLockIO) { m.writeErr = errors.New("full") }, wantErr: "writing ch.md: full"},
+		{name: "json output", args: []string{"--json"}, out: &errWriter{errors.New("closed")}, wantErr: "encoding output"},
+		{name: "generate key", args: []string{"--generate-key"}, keyGenErr: errors.New("no entropy"), wantErr: "no entropy"},
+		{name: "lock body", lockErr: errors.New("no nonce"), wantErr: "ch.md: no nonce"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(fsio.KeyFileEnv, "")
+			origKey, origLock := newBodyKey, lockBody
+			t.Cleanup(func() { newBodyKey, lockBody = origKey, origLock })
+			if tt.keyGenErr != nil {
+				newBodyKey = func() (node.BodyKey, error) { return node.BodyKey{}, tt.keyGenErr }
+			}
+			if tt.lockErr != nil {
+				lockBody = func([]byte, node.BodyKey) ([]byte, error) { return nil, tt.lockErr }
+			}
+			mock := newLockMock(t)
+			if tt.setup != nil {
+				tt.setup(mock)
+			}
+			c := NewLockCmd(mock)
+			if tt.out != nil {
+				c.SetOut(tt.out)
+			} else {
+				c.SetOut(new(bytes.Buffer))
+			}
+			c.SetErr(new(bytes.Buffer))
+			args := []string{"--project", "/proj"}
+			if !tt.noKey {
+				args = append(args, "--key-file", "/keys/proj.key")
+			}
+			c.SetArgs(append(args, tt.args...))
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEdit_RefusesLockedNode(t *testing.T) {
+	t.Setenv("EDITOR", "vi")
+	mock := &mockEditIO{
+		binderBytes:   editBinderWithNode(),
+		nodeFileBytes: lockedContent(t, validEditNodeContent(), testBodyKey(t)),
+	}
+	c := NewEditCmd(mock)
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--project", ".", editTestNodeUUID})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "is locked — run 'pmk unlock'") {
+		t.Errorf("err = %v, want locked-node refusal", err)
+	}
+	if len(mock.editorCalls) != 0 {
+		t.Error("editor opened on a locked node")
+	}
+}
+
+// TestLockedProject_ReadPaths locks a real project and checks that the
+// commands reading node bodies unlock them with $PMK_KEY_FILE and refuse
+// without it.
+func TestEdit_RefusesLockedNotes(t *testing.T) {
+	t.Setenv("EDITOR", "vi")
+	mock := &mockEditIO{
+		binderBytes: editBinderWithNode(),
+		nodeFiles: map[string][]byte{
+			editTestNodeUUID + ".md":       validEditNodeContent(),
+			editTestNodeUUID + ".notes.md": lockedContent(t, []byte("Ask about the source.\n"), testBodyKey(t)),
+		},
+	}
+	c := NewEditCmd(mock)
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--project", ".", editTestNodeUUID, "--part", "notes"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "are locked — run 'pmk unlock'") {
+		t.Errorf("err = %v, want locked-notes refusal", err)
+	}
+	if len(mock.editorCalls) != 0 || mock.notesCreated != "" {
+		t.Error("editor opened or notes file created for locked notes")
+	}
+}
+
+func TestLockedProject_ReadPaths(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(t.TempDir(), "proj.key")
+	writeProjectFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeProjectFile("_binder.md", "<!-- prosemark-binder:v1 -->\n- [Ch](ch.md)\n")
+	writeProjectFile("ch.md", "---\nid: ch\n---\nSecret [link](missing.md). %% ed: cut %%\n")
+
+	run := func(c *cobra.Command, args ...string) (string, error) {
+		out := new(bytes.Buffer)
+		c.SetOut(out)
+		c.SetErr(new(bytes.Buffer))
+		c.SetArgs(append(args, "--project", dir))
+		err := c.Execute()
+		return out.String(), err
+	}
+
+	t.Setenv(fsio.KeyFileEnv, keyPath)
+	if _, err := run(NewLockCmd(fileLockIO{}), "--generate-key"); err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	raw, _ := os.ReadFile(filepath.Join(dir, "ch.md"))
+	if !node.BodyLocked(raw) {
+		t.Fatalf("ch.md not locked: %q", raw)
+	}
+
+	commands := map[string]func() (string, error){
+		"export":      func() (string, error) { return run(NewExportCmd(fileExportIO{})) },
+		"comments":    func() (string, error) { return run(NewCommentsCmd(fileCommentsIO{}), "list") },
+		"check-links": func() (string, error) { return run(NewCheckLinksCmd(fileCheckLinksIO{})) },
+	}
+	wants := map[string]string{"export": "Secret link.", "comments": "cut", "check-links": "broken link missing.md"}
+	for name, runCmd := range commands {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(fsio.KeyFileEnv, keyPath)
+			if out, _ := runCmd(); !strings.Contains(out, wants[name]) {
+				t.Errorf("with key: stdout = %q, want %q", out, wants[name])
+			}
+			t.Setenv(fsio.KeyFileEnv, "")
+			if _, err := runCmd(); !errors.Is(err, node.ErrLockedBody) || !strings.HasPrefix(err.Error(), "ch.md: ") {
+				t.Errorf("without key: err = %v, want locked-body refusal", err)
+			}
+		})
+	}
+
+	t.Run("comments resolve keeps the node locked", func(t *testing.T) {
+		t.Setenv(fsio.KeyFileEnv, keyPath)
+		if _, err := run(NewCommentsCmd(fileCommentsIO{}), "resolve", "ch.md:4"); err != nil {
+			t.Fatalf("resolve: %v", err)
+		}
+		raw, _ := os.ReadFile(filepath.Join(dir, "ch.md"))
+		if !node.BodyLocked(raw) || strings.Contains(string(raw), "resolved") {
+			t.Errorf("resolved node = %q, want it still locked", raw)
+		}
+		if out, _ := run(NewCommentsCmd(fileCommentsIO{}), "list", "--all"); !strings.Contains(out, "resolved") {
+			t.Errorf("list --all = %q, want the resolved comment", out)
+		}
+	})
+
+	if _, err := run(NewUnlockCmd(fileLockIO{})); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+	if raw, _ := os.ReadFile(filepath.Join(dir, "ch.md")); node.BodyLocked(raw) || !strings.Contains(string(raw), "Secret") {
+		t.Errorf("unlocked ch.md = %q", raw)
+	}
+}
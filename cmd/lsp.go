@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/eykd/prosemark-go/internal/jsonrpc"
+	"github.com/eykd/prosemark-go/internal/lsp"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// LSPIO handles I/O for the lsp command.
+type LSPIO interface {
+	lsp.IO
+}
+
+// NewLSPCmd creates the lsp subcommand, a language server for binder files.
+func NewLSPCmd(io LSPIO) *cobra.Command {
+	return &cobra.Command{
+		Use:   "lsp",
+		Short: "Run a Language Server Protocol server for binder files",
+		Long: "Speak the Language Server Protocol on stdin and stdout, for editors.\n" +
+			"Binders (_binder.md unless .prosemark.yml names another) get their parse\n" +
+			"diagnostics as they are edited, go to definition from an entry to its\n" +
+			"node file, an outline of their entries as document symbols, and rename\n" +
+			"of an entry's node file with every reference to it. pmk doctor findings\n" +
+			"are reported on the files they concern whenever a project file is opened\n" +
+			"or saved.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			s := lsp.New(io, buildInfo.Version)
+			return s.Serve(cmd.Context(), jsonrpc.HeaderFraming(cmd.InOrStdin(), cmd.OutOrStdout()))
+		},
+	}
+}
+
+// fileLSPIO implements LSPIO using OS file I/O.
+type fileLSPIO struct {
+	fileProjectIO
+}
+
+// FindBinder returns the binder in dir or its nearest ancestor, as named by
+// each project's config.
+func (fileLSPIO) FindBinder(dir string) (string, error) {
+	return fsio.FindProjectBinder(dir)
+}
+
+// ReadNodeFile reads the node file at path as stored.
+func (fileLSPIO) ReadNodeFile(path string) ([]byte, error) {
+	return fsio.ReadFile(path)
+}
+
+// Audit runs a doctor audit of the project whose binder is binderPath.
+func (fileLSPIO) Audit(ctx context.Context, binderPath string) ([]node.AuditDiagnostic, error) {
+	return core.Doctor(ctx, fileDoctorIO{}, binderPath, core.DoctorOptions{})
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// lspFrame frames a Language Server Protocol message.
+func lspFrame(msg string) string {
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(msg), msg)
+}
+
+func TestNewLSPCmd(t *testing.T) {
+	c := NewLSPCmd(fileLSPIO{})
+	c.SetIn(strings.NewReader(lspFrame(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`) +
+		lspFrame(`{"jsonrpc":"2.0","id":2,"method":"shutdown"}`) +
+		lspFrame(`{"jsonrpc":"2.0","method":"exit"}`)))
+	out, err := runCmdInCWD(c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, `"renameProvider":true`) || !strings.Contains(out, `{"jsonrpc":"2.0","id":2,"result":null}`) {
+		t.Errorf("stdout = %q", out)
+	}
+
+	c = NewLSPCmd(fileLSPIO{})
+	c.SetIn(strings.NewReader(lspFrame(`{"jsonrpc":"2.0","method":"exit"}`)))
+	if _, err := runCmdInCWD(c); err == nil || !strings.Contains(err.Error(), "before shutdown") {
+		t.Errorf("exit before shutdown: err = %v", err)
+	}
+}
+
+func TestFileLSPIO(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	if err := os.WriteFile(binderPath, moveBinder(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "part"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	fio := fileLSPIO{}
+	if got, err := fio.FindBinder(filepath.Join(dir, "part")); err != nil || got != binderPath {
+		t.Errorf("FindBinder = %q, %v", got, err)
+	}
+	if got, err := fio.ReadNodeFile(binderPath); err != nil || string(got) != string(moveBinder()) {
+		t.Errorf("ReadNodeFile = %q, %v", got, err)
+	}
+	ctx := context.Background()
+	if _, err := fio.ScanProject(ctx, binderPath); err != nil {
+		t.Errorf("ScanProject: %v", err)
+	}
+	if _, err := fio.Audit(ctx, binderPath); err != nil {
+		t.Errorf("Audit: %v", err)
+	}
+}
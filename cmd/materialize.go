@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+// MaterializeIO handles I/O for the materialize command.
+type MaterializeIO interface {
+	core.NewNodeIO
+}
+
+// NewMaterializeCmd creates the materialize subcommand.
+func NewMaterializeCmd(io MaterializeIO) *cobra.Command {
+	return newMaterializeCmdWithGetCWD(io, os.Getwd)
+}
+
+func newMaterializeCmdWithGetCWD(io MaterializeIO, getwd func() (string, error)) *cobra.Command {
+	var jsonMode, forceParse, dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "materialize <selector>",
+		Short: "Turn a placeholder entry into a real node",
+		Long: "Create a node file, named by the project's ID scheme and titled from the\n" +
+			"placeholder, and rewrite the placeholder's binder entry to link to it.\n" +
+			"The selector must match exactly one placeholder: a \"[Title]()\" entry, or a\n" +
+			"plain-text item when text_placeholders is set in .prosemark.yml.",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			selector := args[0]
+
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+
+			if replayed, err := replayRecordedOp(cmd, io, binderPath, jsonMode); replayed || err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			scheme, err := projectIDScheme(ctx, io, binderPath)
+			if err != nil {
+				return err
+			}
+			target, err := nodeIDGenerator(scheme)
+			if err != nil {
+				return fmt.Errorf("generating node ID: %w", err)
+			}
+
+			params := binder.MaterializeParams{Selector: selector, Target: target, ForceParse: forceParse, DryRun: dryRun}
+			res, err := core.Materialize(ctx, io, binderPath, params, nowUTCFunc())
+			var opRes *binder.OpResult
+			if res != nil {
+				opRes = &res.OpResult
+			}
+			if err := finishBinderOp(cmd, io, binderPath, jsonMode, opRes, err); err != nil {
+				return err
+			}
+
+			if !jsonMode && !dryRun {
+				if _, err := fmt.Fprintln(cmd.OutOrStdout(), "Materialized "+sanitizePath(selector)+" as "+sanitizePath(target)+" in "+sanitizePath(binderPath)); err != nil {
+					return fmt.Errorf("writing output: %w", err)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+	addForceParseFlag(cmd, &forceParse)
+	addDryRunFlag(cmd, &dryRun)
+	addDiffFlag(cmd)
+	addOpIDFlag(cmd)
+	return cmd
+}
+
+// fileMaterializeIO implements MaterializeIO using OS file I/O.
+type fileMaterializeIO struct {
+	fileProjectIO
+	binderLocker
+	opJournaler
+	fileTxer
+}
+
+// ReadNodeFile reads the node file at path.
+func (f fileMaterializeIO) ReadNodeFile(path string) ([]byte, error) {
+	return fsio.ReadFile(path)
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+const materializeTestID = "0192f0c1-0000-7000-8000-0000000000aa.md"
+
+func stubMaterializeID(t *testing.T, err error) {
+	t.Helper()
+	orig := nodeIDGenerator
+	t.Cleanup(func() { nodeIDGenerator = orig })
+	nodeIDGenerator = func(node.IDScheme) (string, error) { return materializeTestID, err }
+}
+
+func newMaterializeTestIO() *mockAddChildIOWithNew {
+	return &mockAddChildIOWithNew{mockAddChildIO: mockAddChildIO{
+		binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [Chapter One](chapter-one.md)\n- [Interlude]()\n"),
+	}}
+}
+
+func runMaterializeCmd(t *testing.T, mock *mockAddChildIOWithNew, args ...string) (string, error) {
+	t.Helper()
+	c := NewMaterializeCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(append(args, "--project", "."))
+	err := c.Execute()
+	return out.String(), err
+}
+
+func TestMaterialize_CreatesNodeAndLinksEntry(t *testing.T) {
+	stubMaterializeID(t, nil)
+	mock := newMaterializeTestIO()
+	out, err := runMaterializeCmd(t, mock, "interlude")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "- [Interlude](" + materializeTestID + ")\n"; !strings.HasSuffix(string(mock.writtenBytes), want) {
+		t.Errorf("binder = %q, want it to end with %q", mock.writtenBytes, want)
+	}
+	if mock.nodeWrittenPath != materializeTestID || !strings.Contains(string(mock.nodeWrittenContent), "title: Interlude\n") {
+		t.Errorf("node file %s = %q", mock.nodeWrittenPath, mock.nodeWrittenContent)
+	}
+	if !strings.Contains(out, "Materialized interlude as "+materializeTestID) {
+		t.Errorf("stdout = %q", out)
+	}
+}
+
+func TestMaterialize_JSON(t *testing.T) {
+	stubMaterializeID(t, nil)
+	out, err := runMaterializeCmd(t, newMaterializeTestIO(), "Interlude", "--json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result binder.OpResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if !result.Changed || len(result.Entries) != 1 || result.Entries[0].Target != materializeTestID || result.Entries[0].Line != 3 {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestMaterialize_Errors(t *testing.T) {
+	nodeWriteFails := newMaterializeTestIO()
+	nodeWriteFails.nodeWriteErr = errors.New("full")
+
+	tests := []struct {
+		name    string
+		mock    *mockAddChildIOWithNew
+		idErr   error
+		args    []string
+		wantErr string
+	}{
+		{"not a placeholder", newMaterializeTestIO(), nil, []string{"chapter-one"}, "materialize has errors"},
+		{"id generation", newMaterializeTestIO(), errors.New("entropy exhausted"), []string{"Interlude"}, "generating node ID"},
+		{"id scheme", &mockAddChildIOWithNew{mockAddChildIO: mockAddChildIO{project: &binder.Project{IDScheme: "sequential"}}}, nil, []string{"Interlude"}, `unknown id_scheme "sequential"`},
+		{"read binder", &mockAddChildIOWithNew{mockAddChildIO: mockAddChildIO{binderErr: errors.New("boom")}}, nil, []string{"Interlude"}, "reading binder"},
+		{"node write", nodeWriteFails, nil, []string{"Interlude"}, "creating 0192f0c1-0000-7000-8000-0000000000aa.md: full"},
+		{"missing selector", newMaterializeTestIO(), nil, nil, "accepts 1 arg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubMaterializeID(t, tt.idErr)
+			_, err := runMaterializeCmd(t, tt.mock, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+			if tt.mock.writtenBytes != nil {
+				t.Errorf("binder written on error: %q", tt.mock.writtenBytes)
+			}
+		})
+	}
+}
+
+func TestMaterialize_OutputError(t *testing.T) {
+	stubMaterializeID(t, nil)
+	c := NewMaterializeCmd(newMaterializeTestIO())
+	c.SetOut(&errWriter{err: errors.New("closed")})
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"Interlude", "--project", "."})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "writing output") {
+		t.Errorf("error = %v, want output error", err)
+	}
+}
+
+func TestMaterialize_GetwdError(t *testing.T) {
+	c := newMaterializeCmdWithGetCWD(newMaterializeTestIO(), func() (string, error) {
+		return "", errors.New("getwd failed")
+	})
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"Interlude"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+		t.Errorf("error = %v, want getwd failure", err)
+	}
+}
+
+func TestMaterialize_InvalidOpID(t *testing.T) {
+	c := NewMaterializeCmd(newMaterializeTestIO())
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"Interlude", "--project", ".", "--op-id", "not-a-uuid"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "invalid --op-id") {
+		t.Errorf("error = %v, want invalid --op-id", err)
+	}
+}
+
+func TestFileMaterializeIO_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	fio := fileMaterializeIO{}
+	content := []byte("<!-- prosemark-binder:v1 -->\n- [Interlude]()\n")
+	if err := fio.WriteBinderAtomic(context.Background(), binderPath, content); err != nil {
+		t.Fatalf("WriteBinderAtomic: %v", err)
+	}
+	if got, err := fio.ReadBinder(context.Background(), binderPath); err != nil || !bytes.Equal(got, content) {
+		t.Errorf("ReadBinder = %q, %v", got, err)
+	}
+	nodePath := filepath.Join(dir, "new.md")
+	if err := fio.WriteNodeFileAtomic(nodePath, []byte("body\n")); err != nil {
+		t.Fatalf("WriteNodeFileAtomic: %v", err)
+	}
+	if got, err := fio.ReadNodeFile(nodePath); err != nil || string(got) != "body\n" {
+		t.Errorf("ReadNodeFile = %q, %v", got, err)
+	}
+	if proj, err := fio.ScanProject(context.Background(), binderPath); err != nil || len(proj.Files) != 1 {
+		t.Errorf("ScanProject = %+v, %v", proj, err)
+	}
+	if err := fio.DeleteFile(nodePath); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if _, err := os.Stat(nodePath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("node file still exists: %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+// MergeBinderIO handles I/O for the merge-binder command.
+type MergeBinderIO interface {
+	// ReadFile reads one version of the binder.
+	ReadFile(path string) ([]byte, error)
+	// WriteFileAtomic writes the merged binder to path.
+	WriteFileAtomic(path string, data []byte) error
+	// ScanProject scans the project directory for .md files.
+	ScanProject(ctx context.Context, binderPath string) (*binder.Project, error)
+}
+
+// mergeBinderOutput is the JSON output schema for merge-binder.
+type mergeBinderOutput struct {
+	Version     string              `json:"version"`
+	Path        string              `json:"path,omitempty"`
+	Diagnostics []binder.Diagnostic `json:"diagnostics"`
+}
+
+// NewMergeBinderCmd creates the merge-binder subcommand.
+func NewMergeBinderCmd(io MergeBinderIO) *cobra.Command {
+	return newMergeBinderCmdWithGetCWD(io, os.Getwd)
+}
+
+func newMergeBinderCmdWithGetCWD(mio MergeBinderIO, getwd func() (string, error)) *cobra.Command {
+	var (
+		basePath, oursPath, theirsPath string
+		toStdout, jsonMode             bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "merge-binder --base <file> --ours <file> --theirs <file>",
+		Short: "Merge two edited versions of a binder entry by entry",
+		Long: "Merge two edited versions of a binder, ours and theirs, that descend from\n" +
+			"base, writing the result over the ours file. Entries are matched by target,\n" +
+			"so additions, removals, edits, and moves on either side all carry over, and\n" +
+			"only an entry the two sides changed differently is a conflict (PMKE012):\n" +
+			"divergent edits are left between conflict markers, divergent moves keep\n" +
+			"ours. Conflicts make the command fail, after writing the merge.\n\n" +
+			"To have git merge binders this way, declare the driver once:\n\n" +
+			"  git config merge.pmk-binder.driver 'pmk merge-binder --base %O --ours %A --theirs %B'\n" +
+			"  echo '_binder.md merge=pmk-binder' >> .gitattributes",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if jsonMode && toStdout {
+				return fmt.Errorf("--json conflicts with --stdout")
+			}
+			var srcs [3][]byte
+			for i, path := range []string{basePath, oursPath, theirsPath} {
+				data, err := mio.ReadFile(path)
+				if err != nil {
+					return fmt.Errorf("reading %s: %w", sanitizePath(path), err)
+				}
+				srcs[i] = data
+			}
+
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			proj, err := mio.ScanProject(cmd.Context(), binderPath)
+			if err != nil {
+				// Outside a project, link targets are still compared as
+				// written; only wikilinks go unresolved.
+				proj = &binder.Project{Files: []string{}, BinderDir: "."}
+			}
+
+			merged, diags := ops.Merge(cmd.Context(), srcs[0], srcs[1], srcs[2], proj)
+			conflicts, failed := 0, false
+			for _, d := range diags {
+				switch {
+				case d.Code == ops.CodeMergeConflict:
+					conflicts++
+				case d.Severity == "error":
+					failed = true
+				}
+			}
+
+			out := mergeBinderOutput{Version: "1", Diagnostics: reportedDiagnostics(cmd, diags)}
+			if !failed {
+				if toStdout {
+					if _, err := cmd.OutOrStdout().Write(merged); err != nil {
+						return fmt.Errorf("writing output: %w", err)
+					}
+				} else {
+					if err := mio.WriteFileAtomic(oursPath, merged); err != nil {
+						return fmt.Errorf("writing merged binder: %w", err)
+					}
+					out.Path = oursPath
+				}
+			}
+
+			if jsonMode {
+				if err := encodeOutput(cmd, out); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+			} else {
+				printDiagnostics(cmd, diags)
+				if out.Path != "" && conflicts == 0 {
+					fmt.Fprintf(cmd.OutOrStdout(), "Merged binder into %s\n", sanitizePath(out.Path))
+				}
+			}
+
+			switch {
+			case failed:
+				return fmt.Errorf("cannot merge binders")
+			case conflicts > 0:
+				return fmt.Errorf("binder merge has %d conflict(s)", conflicts)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&basePath, "base", "", "the common ancestor version of the binder")
+	cmd.Flags().StringVar(&oursPath, "ours", "", "our version of the binder; the merge is written here")
+	cmd.Flags().StringVar(&theirsPath, "theirs", "", "their version of the binder")
+	cmd.Flags().String("project", "", "project directory containing the binder, for resolving wikilinks (default: current directory)")
+	cmd.Flags().BoolVar(&toStdout, "stdout", false, "print the merged binder instead of writing it over --ours")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+	for _, name := range []string{"base", "ours", "theirs"} {
+		_ = cmd.MarkFlagRequired(name)
+	}
+	return cmd
+}
+
+// fileMergeBinderIO is the production implementation of MergeBinderIO.
+type fileMergeBinderIO struct {
+	fileProjectIO
+}
+
+// WriteFileAtomic writes data to path atomically.
+func (fileMergeBinderIO) WriteFileAtomic(path string, data []byte) error {
+	return fsio.WriteFileAtomic(path, ".merge", data)
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+)
+
+// mockMergeBinderIO is a test double for MergeBinderIO.
+type mockMergeBinderIO struct {
+	files    map[string]string
+	written  map[string]string
+	writeErr error
+	scanErr  error
+}
+
+func (m *mockMergeBinderIO) ReadFile(path string) ([]byte, error) {
+	data, ok := m.files[path]
+	if !ok {
+		return nil, errors.New("no such file")
+	}
+	return []byte(data), nil
+}
+
+func (m *mockMergeBinderIO) WriteFileAtomic(path string, data []byte) error {
+	if m.writeErr != nil {
+		return m.writeErr
+	}
+	if m.written == nil {
+		m.written = make(map[string]string)
+	}
+	m.written[path] = string(data)
+	return nil
+}
+
+func (m *mockMergeBinderIO) ScanProject(_ context.Context, _ string) (*binder.Project, error) {
+	return &binder.Project{Files: []string{}, BinderDir: "."}, m.scanErr
+}
+
+const mergeTestPragma = "<!-- prosemark-binder:v1 -->\n\n"
+
+// newMergeBinderMock returns a mock whose versions of the binder are base.md,
+// ours.md, and theirs.md.
+func newMergeBinderMock(base, ours, theirs string) *mockMergeBinderIO {
+	return &mockMergeBinderIO{files: map[string]string{
+		"base.md":   mergeTestPragma + base,
+		"ours.md":   mergeTestPragma + ours,
+		"theirs.md": mergeTestPragma + theirs,
+	}}
+}
+
+var mergeTestArgs = []string{"--base", "base.md", "--ours", "ours.md", "--theirs", "theirs.md"}
+
+func TestMergeBinderCmd_CleanMergeWritesOurs(t *testing.T) {
+	mock := newMergeBinderMock(
+		"- [One](ch1.md)\n",
+		"- [One](ch1.md)\n- [Two](ch2.md)\n",
+		"- [Zero](ch0.md)\n- [One](ch1.md)\n",
+	)
+	out, err := runCmdInCWD(NewMergeBinderCmd(mock), mergeTestArgs...)
+	if err != nil {
+		t.Fatalf("merge-binder: %v", err)
+	}
+	want := mergeTestPragma + "- [Zero](ch0.md)\n- [One](ch1.md)\n- [Two](ch2.md)\n"
+	if got := mock.written["ours.md"]; got != want {
+		t.Errorf("merged binder =\n%s\nwant:\n%s", got, want)
+	}
+	if !strings.Contains(out, "Merged binder into ours.md") {
+		t.Errorf("output = %q, want a merged message", out)
+	}
+}
+
+func TestMergeBinderCmd_ConflictWritesMarkersAndFails(t *testing.T) {
+	mock := newMergeBinderMock("- [One](ch1.md)\n", "- [First](ch1.md)\n", "- [Opening](ch1.md)\n")
+	out, err := runCmdInCWD(NewMergeBinderCmd(mock), append(mergeTestArgs, "--json")...)
+	if err == nil || !strings.Contains(err.Error(), "1 conflict") {
+		t.Fatalf("error = %v, want a conflict", err)
+	}
+	if got := mock.written["ours.md"]; !strings.Contains(got, "<<<<<<< ours") {
+		t.Errorf("merged binder should hold conflict markers:\n%s", got)
+	}
+	var res mergeBinderOutput
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	if res.Path != "ours.md" || len(res.Diagnostics) != 1 || res.Diagnostics[0].Code != ops.CodeMergeConflict {
+		t.Errorf("output = %+v, want ours.md with one %s", res, ops.CodeMergeConflict)
+	}
+}
+
+func TestMergeBinderCmd_StdoutLeavesOursAlone(t *testing.T) {
+	mock := newMergeBinderMock("- [One](ch1.md)\n", "- [One](ch1.md)\n", "- [One](ch1.md)\n- [Two](ch2.md)\n")
+	mock.scanErr = errors.New("not a project")
+	out, err := runCmdInCWD(NewMergeBinderCmd(mock), append(mergeTestArgs, "--stdout")...)
+	if err != nil {
+		t.Fatalf("merge-binder --stdout: %v", err)
+	}
+	if want := mergeTestPragma + "- [One](ch1.md)\n- [Two](ch2.md)\n"; out != want {
+		t.Errorf("output =\n%s\nwant:\n%s", out, want)
+	}
+	if len(mock.written) != 0 {
+		t.Errorf("--stdout should not write files, wrote %v", mock.written)
+	}
+}
+
+func TestMergeBinderCmd_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		mock *mockMergeBinderIO
+		args []string
+		want string
+	}{
+		{"missing flag", newMergeBinderMock("", "", ""), []string{"--base", "base.md"}, "required flag"},
+		{"unreadable version", &mockMergeBinderIO{files: map[string]string{}}, mergeTestArgs, "reading base.md"},
+		{"json with stdout", newMergeBinderMock("", "", ""), append(mergeTestArgs, "--json", "--stdout"), "--json conflicts with --stdout"},
+		{"parse errors", newMergeBinderMock("", "", "- [Bad](../out.md)\n"), mergeTestArgs, "cannot merge binders"},
+		{"write failure", &mockMergeBinderIO{files: newMergeBinderMock("", "", "").files, writeErr: errors.New("disk full")}, mergeTestArgs, "writing merged binder"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runCmdInCWD(NewMergeBinderCmd(tt.mock), tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want one mentioning %q", err, tt.want)
+			}
+			if tt.want == "cannot merge binders" && len(tt.mock.written) != 0 {
+				t.Errorf("a failed merge should not write, wrote %v", tt.mock.written)
+			}
+		})
+	}
+}
+
+func TestMergeBinderCmd_OutputErrors(t *testing.T) {
+	for _, args := range [][]string{{"--stdout"}, {"--json"}} {
+		t.Run(args[0], func(t *testing.T) {
+			mock := newMergeBinderMock("- [One](ch1.md)\n", "- [One](ch1.md)\n", "- [One](ch1.md)\n")
+			c := NewMergeBinderCmd(mock)
+			c.SetOut(&errWriter{err: errors.New("closed")})
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(append(mergeTestArgs, args...))
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "closed") {
+				t.Errorf("error = %v, want output failure", err)
+			}
+		})
+	}
+}
+
+func TestMergeBinderCmd_GetwdError(t *testing.T) {
+	c := newMergeBinderCmdWithGetCWD(newMergeBinderMock("", "", ""), func() (string, error) {
+		return "", errors.New("getwd failed")
+	})
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(mergeTestArgs)
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+		t.Errorf("error = %v, want getwd failure", err)
+	}
+}
+
+func TestFileMergeBinderIO_MergesIntoOurs(t *testing.T) {
+	dir := t.TempDir()
+	paths := make([]string, 3)
+	for i, content := range []string{"- [One](ch1.md)\n", "- [One](ch1.md)\n- [Two](ch2.md)\n", "- [Zero](ch0.md)\n- [One](ch1.md)\n"} {
+		paths[i] = filepath.Join(dir, [...]string{"base.md", "ours.md", "theirs.md"}[i])
+		if err := os.WriteFile(paths[i], []byte(mergeTestPragma+content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := runCmdInCWD(NewMergeBinderCmd(fileMergeBinderIO{}), "--base", paths[0], "--ours", paths[1], "--theirs", paths[2], "--project", dir); err != nil {
+		t.Fatalf("merge-binder: %v", err)
+	}
+	got, err := os.ReadFile(paths[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := mergeTestPragma + "- [Zero](ch0.md)\n- [One](ch1.md)\n- [Two](ch2.md)\n"; string(got) != want {
+		t.Errorf("ours =\n%s\nwant:\n%s", got, want)
+	}
+}
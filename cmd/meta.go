@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// MetaIO handles I/O for the meta command.
+type MetaIO interface {
+	ReadBinder(ctx context.Context, path string) ([]byte, error)
+	// ReadFile reads the project file at path as stored, for resolving
+	// @bookmarks, for the frontmatter of a node whose body is locked, and
+	// for the node file meta set edits.
+	ReadFile(path string) ([]byte, error)
+	// ReadNodeFile reads the node file at path, unlocking a locked body.
+	ReadNodeFile(path string) ([]byte, error)
+	// WriteNodeFileAtomic writes content to path atomically, keeping a
+	// locked node locked.
+	WriteNodeFileAtomic(path string, content []byte) error
+}
+
+// metaNodeJSON is the JSON output type for the fields of one node.
+type metaNodeJSON struct {
+	Target string         `json:"target"`
+	Title  string         `json:"title,omitempty"`
+	Fields map[string]any `json:"fields"`
+}
+
+// metaGetOutput is the JSON output schema for meta get.
+type metaGetOutput struct {
+	Version string         `json:"version"`
+	Nodes   []metaNodeJSON `json:"nodes"`
+}
+
+// metaSetOutput is the JSON output schema for meta set.
+type metaSetOutput struct {
+	Version string `json:"version"`
+	Target  string `json:"target"`
+	Field   string `json:"field"`
+	Value   string `json:"value"`
+	Updated string `json:"updated"`
+}
+
+// NewMetaCmd creates the meta command with get and set subcommands.
+func NewMetaCmd(io MetaIO) *cobra.Command {
+	return newMetaCmdWithGetCWD(io, os.Getwd)
+}
+
+func newMetaCmdWithGetCWD(io MetaIO, getwd func() (string, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "meta",
+		Short: "Read and write a node's frontmatter fields",
+		Long: "Read and write a node's frontmatter fields.\n\n" +
+			"Any field can be read, including ones pmk does not know, such as a\n" +
+			"status or POV kept for planning. Setting a field rewrites only its lines,\n" +
+			"so other fields, comments, and formatting are kept, and refreshes the\n" +
+			"node's updated timestamp.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.PersistentFlags().String("project", "", "project directory containing the binder (default: current directory)")
+
+	cmd.AddCommand(newMetaGetCmd(io, getwd))
+	cmd.AddCommand(newMetaSetCmd(io, getwd))
+	return cmd
+}
+
+func newMetaGetCmd(io MetaIO, getwd func() (string, error)) *cobra.Command {
+	var all, jsonMode bool
+	cmd := &cobra.Command{
+		Use:   "get <selector> [field] | --all [field]",
+		Short: "Print frontmatter fields of a node, or of every node",
+		Long: "Print the frontmatter field of the node the selector names, or all its\n" +
+			"fields as \"key: value\" lines when no field is given. A missing field is\n" +
+			"an error.\n\n" +
+			"With --all the field, or every field, of each node file in the binder is\n" +
+			"printed in binder order, prefixed by the node's target; nodes without the\n" +
+			"field are skipped, and --json lists them with no such field.",
+		SilenceUsage: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if all {
+				return cobra.MaximumNArgs(1)(cmd, args)
+			}
+			return cobra.RangeArgs(1, 2)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			binderPath, root, err := readMetaBinder(cmd, io, getwd)
+			if err != nil {
+				return err
+			}
+			projectDir := filepath.Dir(binderPath)
+
+			var field string
+			out := metaGetOutput{Version: "1", Nodes: []metaNodeJSON{}}
+			if all {
+				if len(args) == 1 {
+					field = args[0]
+				}
+				for _, n := range binderTargetNodes(root) {
+					fields, err := readMetaFields(io, projectDir, n.Target)
+					if errors.Is(err, os.ErrNotExist) || errors.Is(err, safepath.ErrTraversal) {
+						continue // missing and escaping targets are doctor's concern
+					}
+					if err != nil {
+						return err
+					}
+					out.Nodes = append(out.Nodes, metaNodeJSON{Target: n.Target, Title: n.Title, Fields: pickMetaField(fields, field)})
+				}
+			} else {
+				if len(args) == 2 {
+					field = args[1]
+				}
+				selector := args[0]
+				if err := resolveBookmarkSelectors(io.ReadFile, projectDir, &selector); err != nil {
+					return err
+				}
+				target, err := selectNodeTarget(root, selector)
+				if err != nil {
+					return err
+				}
+				fields, err := readMetaFields(io, projectDir, target)
+				if err != nil {
+					return err
+				}
+				if _, ok := fields[field]; field != "" && !ok {
+					return fmt.Errorf("%s has no %s field", sanitizePath(target), sanitizePath(field))
+				}
+				out.Nodes = append(out.Nodes, metaNodeJSON{Target: target, Fields: pickMetaField(fields, field)})
+			}
+
+			if jsonMode {
+				if err := encodeOutput(cmd, out); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+				return nil
+			}
+			var b strings.Builder
+			for _, n := range out.Nodes {
+				prefix := ""
+				if all {
+					prefix = sanitizePath(n.Target) + ": "
+				}
+				for _, key := range slices.Sorted(maps.Keys(n.Fields)) {
+					value := sanitizePath(showValue(n.Fields[key]))
+					if field == "" {
+						value = sanitizePath(key) + ": " + value
+					}
+					b.WriteString(prefix + value + "\n")
+				}
+			}
+			if _, err := fmt.Fprint(cmd.OutOrStdout(), b.String()); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "print the fields of every node file in the binder")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+	return cmd
+}
+
+func newMetaSetCmd(io MetaIO, getwd func() (string, error)) *cobra.Command {
+	var jsonMode bool
+	cmd := &cobra.Command{
+		Use:   "set <selector> <field> <value>",
+		Short: "Set a frontmatter field of a node",
+		Long: "Set the frontmatter field of the node the selector names to value, adding\n" +
+			"the field when it is not set, and refresh the node's updated timestamp.\n\n" +
+			"The field name must be a plain name of at most 64 letters, digits, _ and\n" +
+			"-. The fields pmk manages, id, created, and updated, cannot be set; use\n" +
+			"pmk rename to change a node's id. A title may be 500 characters long and\n" +
+			"other values 2000, and no value may contain control characters. A locked\n" +
+			"node stays locked, and setting its fields needs no key.",
+		Args:         cobra.ExactArgs(3),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			field, value := args[1], args[2]
+			if err := node.ValidateMetaField(field, value); err != nil {
+				return err
+			}
+			binderPath, root, err := readMetaBinder(cmd, io, getwd)
+			if err != nil {
+				return err
+			}
+			projectDir := filepath.Dir(binderPath)
+			selector := args[0]
+			if err := resolveBookmarkSelectors(io.ReadFile, projectDir, &selector); err != nil {
+				return err
+			}
+			target, err := selectNodeTarget(root, selector)
+			if err != nil {
+				return err
+			}
+			nodePath, err := safepath.Resolve(projectDir, target)
+			if err != nil {
+				return err
+			}
+
+			// Only the frontmatter changes, and locking leaves it plaintext,
+			// so edit the file as stored: a locked body stays as it is.
+			content, err := io.ReadFile(nodePath)
+			if err != nil {
+				return fmt.Errorf("reading node file: %w", err)
+			}
+			doc, err := node.ReadFrontmatter(content)
+			if err != nil {
+				return fmt.Errorf("%s: %w", target, err)
+			}
+			updated := nowUTCFunc()
+			if err := node.SetField(doc, field, value); err != nil {
+				return fmt.Errorf("%s: %w", target, err)
+			}
+			if err := node.SetField(doc, "updated", updated); err != nil {
+				return fmt.Errorf("%s: %w", target, err)
+			}
+			if err := io.WriteNodeFileAtomic(nodePath, node.WriteFrontmatter(doc)); err != nil {
+				return fmt.Errorf("writing node file: %w", err)
+			}
+
+			if jsonMode {
+				out := metaSetOutput{Version: "1", Target: target, Field: field, Value: value, Updated: updated}
+				if err := encodeOutput(cmd, out); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+				return nil
+			}
+			if _, err := fmt.Fprintf(cmd.OutOrStdout(), "Set %s of %s\n", sanitizePath(field), sanitizePath(target)); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+	return cmd
+}
+
+// readMetaBinder resolves and parses the binder of the project cmd names,
+// returning its path and root.
+func readMetaBinder(cmd *cobra.Command, io MetaIO, getwd func() (string, error)) (string, *binder.Node, error) {
+	binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+	if err != nil {
+		return "", nil, err
+	}
+	binderBytes, err := io.ReadBinder(cmd.Context(), binderPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil, fmt.Errorf("project not initialized — run 'pmk init' first")
+		}
+		return "", nil, fmt.Errorf("reading binder: %w", err)
+	}
+	parsed, _, err := binder.Parse(cmd.Context(), binderBytes, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot parse binder: %w", err)
+	}
+	return binderPath, parsed.Root, nil
+}
+
+// readMetaFields returns the frontmatter fields of the node file at
+// target, relative to projectDir, reading a locked node's frontmatter as
+// stored. A file without frontmatter has no fields, and a missing file's
+// error wraps os.ErrNotExist.
+func readMetaFields(io MetaIO, projectDir, target string) (map[string]any, error) {
+	nodePath, err := safepath.Resolve(projectDir, target)
+	if err != nil {
+		return nil, err
+	}
+	content, err := io.ReadNodeFile(nodePath)
+	if errors.Is(err, node.ErrLockedBody) {
+		content, err = io.ReadFile(nodePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading node file: %w", err)
+	}
+	if fields := node.FrontmatterFields(content); fields != nil {
+		return fields, nil
+	}
+	return map[string]any{}, nil
+}
+
+// pickMetaField returns fields, or only its field named field when that is
+// set.
+func pickMetaField(fields map[string]any, field string) map[string]any {
+	if field == "" {
+		return fields
+	}
+	picked := make(map[string]any)
+	if v, ok := fields[field]; ok {
+		picked[field] = v
+	}
+	return picked
+}
+
+// fileMetaIO implements MetaIO using OS file I/O.
+type fileMetaIO struct {
+	fileProjectIO
+}
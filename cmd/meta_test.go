@@ -0,0 +1,334 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// mockMetaIO is a test double for MetaIO.
+type mockMetaIO struct {
+	binderBytes []byte
+	binderErr   error
+	files       map[string]string
+	readErrs    map[string]error
+	locked      map[string]bool
+	writeErr    error
+	written     map[string][]byte
+}
+
+func (m *mockMetaIO) ReadBinder(_ context.Context, _ string) ([]byte, error) {
+	return m.binderBytes, m.binderErr
+}
+
+func (m *mockMetaIO) ReadFile(path string) ([]byte, error) {
+	if err := m.readErrs[filepath.Base(path)]; err != nil {
+		return nil, err
+	}
+	if content, ok := m.files[filepath.Base(path)]; ok {
+		return []byte(content), nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func (m *mockMetaIO) ReadNodeFile(path string) ([]byte, error) {
+	if m.locked[filepath.Base(path)] {
+		return nil, node.ErrLockedBody
+	}
+	return m.ReadFile(path)
+}
+
+func (m *mockMetaIO) WriteNodeFileAtomic(path string, content []byte) error {
+	if m.written == nil {
+		m.written = make(map[string][]byte)
+	}
+	m.written[path] = content
+	return m.writeErr
+}
+
+const metaTestBinder = "<!-- prosemark-binder:v1 -->\n" +
+	"- [Chapter One](ch1.md)\n" +
+	"  - [Scene](scene.md)\n" +
+	"- [Missing](missing.md)\n" +
+	"- [Plain](plain.md)\n"
+
+const metaTestCh1 = "---\nid: ch1\ntitle: Chapter One\n# planning\nstatus: outline\nsynopsis: The start.\ncreated: 2025-01-01T00:00:00Z\nupdated: 2025-01-01T00:00:00Z\n---\n\nBody.\n"
+
+func newMetaTestIO() *mockMetaIO {
+	return &mockMetaIO{
+		binderBytes: []byte(metaTestBinder),
+		files: map[string]string{
+			"ch1.md":   metaTestCh1,
+			"scene.md": "---\nid: scene\ntitle: Scene\nupdated: 2025-01-01T00:00:00Z\n---\n",
+			"plain.md": "no frontmatter\n",
+		},
+	}
+}
+
+func runMetaCmd(t *testing.T, mock *mockMetaIO, args ...string) (string, error) {
+	t.Helper()
+	orig := nowUTCFunc
+	nowUTCFunc = func() string { return "2026-03-01T12:00:00Z" }
+	t.Cleanup(func() { nowUTCFunc = orig })
+
+	c := newMetaCmdWithGetCWD(mock, func() (string, error) { return "/proj", nil })
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(args)
+	err := c.Execute()
+	return out.String(), err
+}
+
+func TestMetaSet_SetsFieldAndStampsUpdated(t *testing.T) {
+	mock := newMetaTestIO()
+	out, err := runMetaCmd(t, mock, "set", "ch1.md", "status", "drafted")
+	if err != nil {
+		t.Fatalf("meta set: %v", err)
+	}
+	if out != "Set status of ch1.md\n" {
+		t.Errorf("output = %q", out)
+	}
+	want := strings.NewReplacer("status: outline", "status: drafted", "updated: 2025-01-01T00:00:00Z", "updated: 2026-03-01T12:00:00Z").Replace(metaTestCh1)
+	if got := string(mock.written[filepath.Join("/proj", "ch1.md")]); got != want {
+		t.Errorf("written =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMetaSet_AddsNewField(t *testing.T) {
+	mock := newMetaTestIO()
+	if _, err := runMetaCmd(t, mock, "set", "Scene", "pov", "Ada: the narrator"); err != nil {
+		t.Fatalf("meta set: %v", err)
+	}
+	want := "---\nid: scene\ntitle: Scene\nupdated: 2026-03-01T12:00:00Z\npov: 'Ada: the narrator'\n---\n"
+	if got := string(mock.written[filepath.Join("/proj", "scene.md")]); got != want {
+		t.Errorf("written =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMetaSet_LockedNodeKeepsItsBody(t *testing.T) {
+	mock := newMetaTestIO()
+	body := "<!-- pmk:locked v1 key=0123456789abcdef -->\nc2VjcmV0\n"
+	mock.files["scene.md"] = "---\nid: scene\ntitle: Scene\nupdated: 2025-01-01T00:00:00Z\n---\n" + body
+	mock.locked = map[string]bool{"scene.md": true}
+	if _, err := runMetaCmd(t, mock, "set", "Scene", "status", "drafted"); err != nil {
+		t.Fatalf("meta set: %v", err)
+	}
+	want := "---\nid: scene\ntitle: Scene\nupdated: 2026-03-01T12:00:00Z\nstatus: drafted\n---\n" + body
+	if got := string(mock.written[filepath.Join("/proj", "scene.md")]); got != want {
+		t.Errorf("written =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMetaSet_JSON(t *testing.T) {
+	out, err := runMetaCmd(t, newMetaTestIO(), "set", "ch1.md", "status", "drafted", "--json")
+	if err != nil {
+		t.Fatalf("meta set: %v", err)
+	}
+	var got metaSetOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("decoding %q: %v", out, err)
+	}
+	want := metaSetOutput{Version: "1", Target: "ch1.md", Field: "status", Value: "drafted", Updated: "2026-03-01T12:00:00Z"}
+	if got != want {
+		t.Errorf("output = %+v, want %+v", got, want)
+	}
+}
+
+func TestMetaSet_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(m *mockMetaIO)
+		args    []string
+		wantErr string
+	}{
+		{name: "managed field", args: []string{"ch1.md", "updated", "now"}, wantErr: "updated is managed by pmk"},
+		{name: "control character", args: []string{"ch1.md", "status", "a\x01b"}, wantErr: "status must not contain control characters"},
+		{name: "invalid name", args: []string{"ch1.md", "my status", "x"}, wantErr: `invalid field name "my status"`},
+		{name: "no frontmatter", args: []string{"plain.md", "status", "x"}, wantErr: "plain.md: no valid frontmatter block found"},
+		{name: "missing file", args: []string{"missing.md", "status", "x"}, wantErr: "reading node file"},
+		{name: "unknown selector", args: []string{"nope", "status", "x"}, wantErr: "OPE001"},
+		{name: "write failure", setup: func(m *mockMetaIO) { m.writeErr = errors.New("full") }, args: []string{"ch1.md", "status", "x"}, wantErr: "writing node file: full"},
+		{name: "uninitialized", setup: func(m *mockMetaIO) { m.binderErr = os.ErrNotExist }, args: []string{"ch1.md", "status", "x"}, wantErr: "project not initialized"},
+		{name: "unreadable binder", setup: func(m *mockMetaIO) { m.binderErr = errors.New("denied") }, args: []string{"ch1.md", "status", "x"}, wantErr: "reading binder: denied"},
+		{name: "invalid binder", setup: func(m *mockMetaIO) { m.binderBytes = []byte("\xff") }, args: []string{"ch1.md", "status", "x"}, wantErr: "cannot parse binder"},
+		{name: "unknown bookmark", args: []string{"@nope", "status", "x"}, wantErr: "nope"},
+		{name: "escaping target", setup: func(m *mockMetaIO) { m.binderBytes = []byte(metaTestBinder + "- [Out](/out.md)\n") }, args: []string{"Out", "status", "x"}, wantErr: "escapes"},
+		{name: "field breaks an alias", setup: func(m *mockMetaIO) { m.files["ch1.md"] = "---\nstatus: &s outline\npov: *s\n---\n" }, args: []string{"ch1.md", "status", "x"}, wantErr: "unknown anchor"},
+		{name: "updated breaks an alias", setup: func(m *mockMetaIO) { m.files["ch1.md"] = "---\nupdated: &u 2025-01-01T00:00:00Z\nseen: *u\n---\n" }, args: []string{"ch1.md", "status", "x"}, wantErr: "unknown anchor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMetaTestIO()
+			if tt.setup != nil {
+				tt.setup(mock)
+			}
+			_, err := runMetaCmd(t, mock, append([]string{"set"}, tt.args...)...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("meta set error = %v, want %q", err, tt.wantErr)
+			}
+			if tt.name != "write failure" && mock.written != nil {
+				t.Errorf("wrote %v after an error", mock.written)
+			}
+		})
+	}
+}
+
+func TestMetaGet(t *testing.T) {
+	tests := []struct {
+		name   string
+		locked bool
+		args   []string
+		want   string
+	}{
+		{name: "one field", args: []string{"ch1.md", "synopsis"}, want: "The start.\n"},
+		{name: "time field", args: []string{"ch1.md", "updated"}, want: "2025-01-01T00:00:00Z\n"},
+		{
+			name: "all fields",
+			args: []string{"Chapter One"},
+			want: "created: 2025-01-01T00:00:00Z\nid: ch1\nstatus: outline\nsynopsis: The start.\ntitle: Chapter One\nupdated: 2025-01-01T00:00:00Z\n",
+		},
+		{name: "locked", locked: true, args: []string{"ch1.md", "status"}, want: "outline\n"},
+		{name: "whole tree", args: []string{"--all", "title"}, want: "ch1.md: Chapter One\nscene.md: Scene\n"},
+		{name: "whole tree, every field", args: []string{"--all"}, want: "ch1.md: created: 2025-01-01T00:00:00Z\nch1.md: id: ch1\nch1.md: status: outline\n" +
+			"ch1.md: synopsis: The start.\nch1.md: title: Chapter One\nch1.md: updated: 2025-01-01T00:00:00Z\n" +
+			"scene.md: id: scene\nscene.md: title: Scene\nscene.md: updated: 2025-01-01T00:00:00Z\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMetaTestIO()
+			if tt.locked {
+				mock.locked = map[string]bool{"ch1.md": true}
+			}
+			out, err := runMetaCmd(t, mock, append([]string{"get"}, tt.args...)...)
+			if err != nil {
+				t.Fatalf("meta get: %v", err)
+			}
+			if out != tt.want {
+				t.Errorf("output =\n%s\nwant\n%s", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetaGet_AllJSON(t *testing.T) {
+	out, err := runMetaCmd(t, newMetaTestIO(), "get", "--all", "status", "--json")
+	if err != nil {
+		t.Fatalf("meta get: %v", err)
+	}
+	var got metaGetOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("decoding %q: %v", out, err)
+	}
+	want := []metaNodeJSON{
+		{Target: "ch1.md", Title: "Chapter One", Fields: map[string]any{"status": "outline"}},
+		{Target: "scene.md", Title: "Scene", Fields: map[string]any{}},
+		{Target: "plain.md", Title: "Plain", Fields: map[string]any{}},
+	}
+	if len(got.Nodes) != len(want) {
+		t.Fatalf("nodes = %+v, want %+v", got.Nodes, want)
+	}
+	for i, n := range got.Nodes {
+		if n.Target != want[i].Target || n.Title != want[i].Title || len(n.Fields) != len(want[i].Fields) || n.Fields["status"] != want[i].Fields["status"] {
+			t.Errorf("node %d = %+v, want %+v", i, n, want[i])
+		}
+	}
+}
+
+func TestMetaGet_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(m *mockMetaIO)
+		args    []string
+		wantErr string
+	}{
+		{name: "missing field", args: []string{"ch1.md", "pov"}, wantErr: "ch1.md has no pov field"},
+		{name: "missing file", args: []string{"missing.md"}, wantErr: "reading node file"},
+		{name: "no selector", args: nil, wantErr: "accepts between 1 and 2 arg(s)"},
+		{name: "selector with --all", args: []string{"--all", "ch1.md", "status"}, wantErr: "accepts at most 1 arg(s)"},
+		{name: "unknown bookmark", args: []string{"@nope"}, wantErr: "nope"},
+		{name: "unknown selector", args: []string{"nope"}, wantErr: "OPE001"},
+		{name: "escaping target", setup: func(m *mockMetaIO) { m.binderBytes = []byte(metaTestBinder + "- [Out](/out.md)\n") }, args: []string{"Out"}, wantErr: "escapes"},
+		{name: "unreadable node with --all", setup: func(m *mockMetaIO) { m.readErrs = map[string]error{"scene.md": errors.New("denied")} }, args: []string{"--all"}, wantErr: "reading node file: denied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMetaTestIO()
+			if tt.setup != nil {
+				tt.setup(mock)
+			}
+			_, err := runMetaCmd(t, mock, append([]string{"get"}, tt.args...)...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("meta get error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMetaCmd_OutputErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"get", "ch1.md"},
+		{"get", "ch1.md", "--json"},
+		{"set", "ch1.md", "status", "x"},
+		{"set", "ch1.md", "status", "x", "--json"},
+	} {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			c := newMetaCmdWithGetCWD(newMetaTestIO(), func() (string, error) { return "/proj", nil })
+			c.SetOut(&errWriter{err: errors.New("closed")})
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(args)
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "closed") {
+				t.Errorf("error = %v, want output failure", err)
+			}
+		})
+	}
+}
+
+func TestMetaCmd_GetwdError(t *testing.T) {
+	c := newMetaCmdWithGetCWD(newMetaTestIO(), func() (string, error) { return "", errors.New("getwd failed") })
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"get", "ch1.md"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+		t.Errorf("error = %v, want getwd failure", err)
+	}
+}
+
+func TestMetaCmd_NoSubcommandPrintsHelp(t *testing.T) {
+	out, err := runMetaCmd(t, newMetaTestIO())
+	if err != nil || !strings.Contains(out, "set") {
+		t.Errorf("output = %q, %v; want help listing subcommands", out, err)
+	}
+}
+
+func TestFileMetaIO_SetAndGet(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "_binder.md"), []byte(metaTestBinder), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ch1.md"), []byte(metaTestCh1), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	run := func(args ...string) string {
+		t.Helper()
+		c := NewMetaCmd(fileMetaIO{})
+		out := new(bytes.Buffer)
+		c.SetOut(out)
+		c.SetArgs(append(args, "--project", dir))
+		if err := c.Execute(); err != nil {
+			t.Fatalf("meta %v: %v", args, err)
+		}
+		return out.String()
+	}
+	run("set", "ch1.md", "status", "revised")
+	if got := run("get", "ch1.md", "status"); got != "revised\n" {
+		t.Errorf("meta get = %q, want revised", got)
+	}
+}
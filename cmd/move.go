@@ -1,23 +1,21 @@
 package cmd
 
 import (
-	"bytes"
-	"context"
-	"encoding/json"
 	"fmt"
 	"os"
-
-	"github.com/spf13/cobra"
+	"path/filepath"
+	"strings"
 
 	"github.com/eykd/prosemark-go/internal/binder"
-	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/spf13/cobra"
 )
 
-// MoveIO handles I/O for the move command.
+// MoveIO handles I/O for the move command. The node file methods are used
+// only by --to-project.
 type MoveIO interface {
-	ReadBinder(ctx context.Context, path string) ([]byte, error)
-	ScanProject(ctx context.Context, binderPath string) (*binder.Project, error)
-	WriteBinderAtomic(ctx context.Context, path string, data []byte) error
+	core.DeleteFilesIO
 }
 
 // NewMoveCmd creates the move subcommand.
@@ -27,19 +25,24 @@ func NewMoveCmd(io MoveIO) *cobra.Command {
 
 func newMoveCmdWithGetCWD(io MoveIO, getwd func() (string, error)) *cobra.Command {
 	var (
-		source   string
-		dest     string
-		first    bool
-		at       int
-		before   string
-		after    string
-		yes      bool
-		jsonMode bool
+		source     string
+		dest       string
+		pos        positionFlags
+		yes        bool
+		jsonMode   bool
+		preserve   bool
+		forceParse bool
+		dryRun     bool
+		toProject  string
 	)
 
 	cmd := &cobra.Command{
-		Use:          "move",
-		Short:        "Move a node within a binder",
+		Use:   "move",
+		Short: "Move a node within a binder",
+		Long: "Move the selected entry, with its subtree, under --dest. With --to-project\n" +
+			"the entry moves into another project's binder instead, taking its node\n" +
+			"files and their notes files along at the same project-relative paths. If\n" +
+			"any step fails, both binders and all files are restored.",
 		Args:         cobra.NoArgs,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -48,66 +51,37 @@ func newMoveCmdWithGetCWD(io MoveIO, getwd func() (string, error)) *cobra.Comman
 				return err
 			}
 
-			ctx := cmd.Context()
-
-			binderBytes, err := io.ReadBinder(ctx, binderPath)
-			if err != nil {
-				return fmt.Errorf("reading binder: %w", err)
+			if replayed, err := replayRecordedOp(cmd, io, binderPath, jsonMode); replayed || err != nil {
+				return err
 			}
 
-			proj, err := io.ScanProject(ctx, binderPath)
+			point, err := pos.resolve(cmd)
 			if err != nil {
-				return emitOPE009AndError(cmd, jsonMode, err)
-			}
-
-			if err := checkConflictingPositionFlags(cmd, first, before, after); err != nil {
 				return err
 			}
 
-			position := "last"
-			if first {
-				position = "first"
-			}
-
 			params := binder.MoveParams{
 				SourceSelector:            source,
 				DestinationParentSelector: dest,
-				Position:                  position,
-				Before:                    before,
-				After:                     after,
-				Yes:                       yes,
+				Position:                  point.Position,
+				At:                        point.At,
+				Before:                    point.Before,
+				After:                     point.After,
+				Yes:                       yes || dryRun,
+				PreserveExtras:            preserve,
+				ForceParse:                forceParse,
+				DryRun:                    dryRun,
 			}
-			if cmd.Flags().Changed("at") {
-				params.At = &at
+			if toProject != "" {
+				return moveToProject(cmd, io, binderPath, toProject, getwd, params, jsonMode)
 			}
-
-			modifiedBytes, diags := ops.Move(ctx, binderBytes, proj, params)
-			if diags == nil {
-				diags = []binder.Diagnostic{}
-			}
-
-			changed := !bytes.Equal(binderBytes, modifiedBytes)
-
-			if jsonMode {
-				out := binder.OpResult{Version: "1", Changed: changed, Diagnostics: diags}
-				if err := json.NewEncoder(cmd.OutOrStdout()).Encode(out); err != nil {
-					return fmt.Errorf("encoding output: %w", err)
-				}
-			} else {
-				printDiagnostics(cmd, diags)
-			}
-
-			if hasDiagnosticError(diags) {
-				return fmt.Errorf("move has errors")
-			}
-
-			if changed {
-				if err = io.WriteBinderAtomic(ctx, binderPath, modifiedBytes); err != nil {
-					return fmt.Errorf("writing binder: %w", err)
-				}
+			withJournalOps(cmd, binder.NewOpSpec(binder.OpMove, params))
+			res, err := core.Move(cmd.Context(), io, binderPath, params)
+			if err := finishBinderOp(cmd, io, binderPath, jsonMode, res, err); err != nil {
+				return err
 			}
 
-			if !jsonMode {
+			if !jsonMode && !dryRun {
 				if _, err := fmt.Fprintln(cmd.OutOrStdout(), "Moved "+sanitizePath(source)+" in "+sanitizePath(binderPath)); err != nil {
 					return fmt.Errorf("writing output: %w", err)
 				}
@@ -117,42 +91,76 @@ func newMoveCmdWithGetCWD(io MoveIO, getwd func() (string, error)) *cobra.Comman
 		},
 	}
 
-	cmd.Flags().String("project", "", "project directory containing _binder.md (default: current directory)")
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
 	cmd.Flags().StringVar(&source, "source", "", "Source selector")
 	cmd.Flags().StringVar(&dest, "dest", "", "Destination parent selector")
-	cmd.Flags().BoolVar(&first, "first", false, "Insert as first child")
-	cmd.Flags().IntVar(&at, "at", 0, "Zero-based insertion index")
-	cmd.Flags().StringVar(&before, "before", "", "Insert before selector")
-	cmd.Flags().StringVar(&after, "after", "", "Insert after selector")
+	addPositionFlags(cmd, &pos)
 	cmd.Flags().BoolVar(&yes, "yes", false, "Required confirmation flag")
 	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+	addForceParseFlag(cmd, &forceParse)
+	addDryRunFlag(cmd, &dryRun)
+	addDiffFlag(cmd)
+	addOpIDFlag(cmd)
+	cmd.Flags().StringVar(&toProject, "to-project", "", "move the node, with its files, into the project in this directory")
+	cmd.Flags().BoolVar(&preserve, "preserve-extras", false, "Carry strikethrough and other text around the link along with the moved entry (checkboxes and annotate-tree annotations always move)")
 
 	return cmd
 }
 
-// fileMoveIO implements MoveIO using OS file I/O.
-type fileMoveIO struct{ binderLocker }
-
-func newDefaultMoveIO() *fileMoveIO {
-	return &fileMoveIO{}
-}
+// moveToProject runs move --to-project, moving the node into the project in
+// the directory toProject.
+func moveToProject(cmd *cobra.Command, io MoveIO, binderPath, toProject string, getwd func() (string, error), params binder.MoveParams, jsonMode bool) error {
+	destDir, err := absFromCWD(toProject, getwd)
+	if err != nil {
+		return err
+	}
+	destBinderName, err := fsio.BinderFilename(destDir)
+	if err != nil {
+		return err
+	}
+	destBinderPath := filepath.Join(destDir, destBinderName)
+	var res *binder.OpResult
+	mres, err := core.MoveToProject(cmd.Context(), io, binderPath, destBinderPath, params)
+	if mres != nil {
+		res = &mres.OpResult
+	}
+	if err := finishBinderOp(cmd, io, binderPath, jsonMode, res, err); err != nil {
+		return err
+	}
+	if jsonMode {
+		return nil
+	}
 
-// ReadBinder reads the binder file at path.
-func (w *fileMoveIO) ReadBinder(_ context.Context, path string) ([]byte, error) {
-	return readBinderSizeLimitedImpl(path)
+	var b strings.Builder
+	verb := "Moved "
+	if params.DryRun {
+		verb = "Would move "
+	} else {
+		b.WriteString("Moved " + sanitizePath(params.SourceSelector) + " to " + sanitizePath(destBinderPath) + "\n")
+	}
+	for _, f := range mres.Files {
+		b.WriteString(verb + sanitizePath(f) + " to " + sanitizePath(destDir) + "\n")
+	}
+	if _, err := fmt.Fprint(cmd.OutOrStdout(), b.String()); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	return nil
 }
 
-// ScanProject scans the project directory for .md files.
-func (w *fileMoveIO) ScanProject(ctx context.Context, binderPath string) (*binder.Project, error) {
-	return ScanProjectImpl(ctx, binderPath)
+// fileMoveIO implements MoveIO using OS file I/O.
+type fileMoveIO struct {
+	fileProjectIO
+	binderLocker
+	opJournaler
+	fileTxer
 }
 
-// WriteBinderAtomic writes data to path atomically via a temp file.
-func (w *fileMoveIO) WriteBinderAtomic(ctx context.Context, path string, data []byte) error {
-	return w.WriteBinderAtomicImpl(ctx, path, data)
+func newDefaultMoveIO() *fileMoveIO {
+	return &fileMoveIO{}
 }
 
-// WriteBinderAtomicImpl performs the atomic write via OS temp file rename.
-func (w *fileMoveIO) WriteBinderAtomicImpl(_ context.Context, path string, data []byte) error {
-	return writeBinderCheckedImpl(path, data)
+// ReadNodeFile reads the node file at path as stored.
+func (w *fileMoveIO) ReadNodeFile(path string) ([]byte, error) {
+	return fsio.ReadFile(path)
 }
@@ -49,7 +49,7 @@ func TestFileMoveIO_ReadBinder(t *testing.T) {
 	}
 }
 
-func TestFileMoveIO_WriteBinderAtomicImpl(t *testing.T) {
+func TestFileMoveIO_WriteBinderAtomic(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "_binder.md")
 	content := moveBinder()
@@ -68,7 +68,7 @@ func TestFileMoveIO_WriteBinderAtomicImpl(t *testing.T) {
 	}
 }
 
-func TestFileMoveIO_WriteBinderAtomicImpl_RejectsReadOnlyFile(t *testing.T) {
+func TestFileMoveIO_WriteBinderAtomic_RejectsReadOnlyFile(t *testing.T) {
 	if os.Getuid() == 0 {
 		t.Skip("permission check bypassed as root")
 	}
@@ -92,7 +92,7 @@ func TestFileMoveIO_WriteBinderAtomicImpl_RejectsReadOnlyFile(t *testing.T) {
 	}
 }
 
-func TestFileMoveIO_WriteBinderAtomicImpl_LeavesOriginalOnError(t *testing.T) {
+func TestFileMoveIO_WriteBinderAtomic_LeavesOriginalOnError(t *testing.T) {
 	// Write to a path whose directory does not exist → write must fail, original untouched
 	path := filepath.Join(t.TempDir(), "nonexistent-dir", "_binder.md")
 	content := []byte("new content")
@@ -5,10 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
 )
 
 // mockMoveIO is a test double for MoveIO.
@@ -20,9 +23,20 @@ type mockMoveIO struct {
 	writeErr     error
 	writtenBytes []byte
 	writtenPath  string
+	// binders maps binder paths other than the project's to content, and
+	// files maps node file paths to content, for --to-project.
+	binders map[string][]byte
+	files   map[string][]byte
 }
 
-func (m *mockMoveIO) ReadBinder(_ context.Context, _ string) ([]byte, error) {
+func (m *mockMoveIO) BeginTx(string) (core.FileTx, error) {
+	return beginMemTx(m)
+}
+
+func (m *mockMoveIO) ReadBinder(_ context.Context, path string) ([]byte, error) {
+	if data, ok := m.binders[path]; ok {
+		return data, nil
+	}
 	return m.binderBytes, m.binderErr
 }
 
@@ -34,11 +48,36 @@ func (m *mockMoveIO) ScanProject(_ context.Context, _ string) (*binder.Project,
 }
 
 func (m *mockMoveIO) WriteBinderAtomic(_ context.Context, path string, data []byte) error {
+	if _, ok := m.binders[path]; ok {
+		m.binders[path] = data
+		return nil
+	}
 	m.writtenPath = path
 	m.writtenBytes = data
 	return m.writeErr
 }
 
+func (m *mockMoveIO) ReadNodeFile(path string) ([]byte, error) {
+	content, ok := m.files[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return content, nil
+}
+
+func (m *mockMoveIO) WriteNodeFileAtomic(path string, content []byte) error {
+	if m.files == nil {
+		m.files = map[string][]byte{}
+	}
+	m.files[path] = content
+	return nil
+}
+
+func (m *mockMoveIO) DeleteFile(path string) error {
+	delete(m.files, path)
+	return nil
+}
+
 // moveBinder returns a minimal binder with two child nodes for move tests.
 func moveBinder() []byte {
 	return []byte("<!-- prosemark-binder:v1 -->\n- [Chapter One](chapter-one.md)\n- [Chapter Two](chapter-two.md)\n")
@@ -299,6 +338,9 @@ func TestNewMoveCmd_OutputsOpResultJSONOnSuccess(t *testing.T) {
 	if result.Version != "1" {
 		t.Errorf("version = %q, want \"1\"", result.Version)
 	}
+	if len(result.Entries) != 1 || result.Entries[0].Target != "chapter-two.md" || len(result.Entries[0].Path) != 2 {
+		t.Errorf("entries = %+v, want the moved chapter-two.md nested under chapter-one.md", result.Entries)
+	}
 }
 
 func TestNewMoveCmd_JSONEncodeError(t *testing.T) {
@@ -401,3 +443,183 @@ func TestNewRootCmd_RegistersMoveSubcommand(t *testing.T) {
 		t.Error("expected \"move\" subcommand registered on root command")
 	}
 }
+
+func TestNewMoveCmd_DryRunPrintsDiffWithoutWriting(t *testing.T) {
+	mock := &mockMoveIO{
+		binderBytes: moveBinder(),
+		project:     &binder.Project{Files: []string{"chapter-one.md", "chapter-two.md"}, BinderDir: "."},
+	}
+	c := NewMoveCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetArgs([]string{"--source", "chapter-two.md", "--dest", "chapter-one.md", "--dry-run", "--project", "."})
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mock.writtenBytes != nil {
+		t.Errorf("dry run wrote the binder: %q", mock.writtenBytes)
+	}
+	want := "--- a/_binder.md\n+++ b/_binder.md\n"
+	if !strings.HasPrefix(out.String(), want) || !strings.Contains(out.String(), "+  - [Chapter Two](chapter-two.md)\n") {
+		t.Errorf("output = %q, want a unified diff nesting chapter two", out.String())
+	}
+}
+
+func TestNewMoveCmd_DiffFlag(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		args     []string
+		wantDiff bool
+	}{
+		{"text", []string{"--diff"}, true},
+		{"json with diff", []string{"--diff", "--json"}, true},
+		{"json without diff", []string{"--json"}, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockMoveIO{
+				binderBytes: moveBinder(),
+				project:     &binder.Project{Files: []string{"chapter-one.md", "chapter-two.md"}, BinderDir: "."},
+			}
+			c := NewMoveCmd(mock)
+			out := new(bytes.Buffer)
+			c.SetOut(out)
+			c.SetArgs(append([]string{"--source", "chapter-two.md", "--dest", "chapter-one.md", "--yes", "--project", "."}, tt.args...))
+
+			if err := c.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if mock.writtenBytes == nil {
+				t.Error("expected the binder to be written")
+			}
+			if got := strings.Contains(out.String(), "+  - [Chapter Two](chapter-two.md)"); got != tt.wantDiff {
+				t.Errorf("output = %q, want diff %v", out.String(), tt.wantDiff)
+			}
+		})
+	}
+}
+
+func TestNewMoveCmd_DryRunJSONIncludesDiff(t *testing.T) {
+	mock := &mockMoveIO{
+		binderBytes: moveBinder(),
+		project:     &binder.Project{Files: []string{"chapter-one.md", "chapter-two.md"}, BinderDir: "."},
+	}
+	c := NewMoveCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetArgs([]string{"--source", "chapter-two.md", "--dest", "chapter-one.md", "--dry-run", "--json", "--project", "."})
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result binder.OpResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("output is not valid JSON: %v\noutput: %s", err, out.String())
+	}
+	if !result.Changed || result.Diff == "" || mock.writtenBytes != nil {
+		t.Errorf("result = %+v, written = %q", result, mock.writtenBytes)
+	}
+}
+
+// toProjectMoveIO returns a mockMoveIO for moving chapter one of /proj into
+// the empty project /other.
+func toProjectMoveIO() *mockMoveIO {
+	return &mockMoveIO{
+		binderBytes: moveBinder(),
+		project:     &binder.Project{Files: []string{"chapter-one.md", "chapter-two.md"}, BinderDir: "/proj"},
+		binders:     map[string][]byte{"/other/_binder.md": []byte("<!-- prosemark-binder:v1 -->\n")},
+		files:       map[string][]byte{"/proj/chapter-one.md": []byte("One.\n"), "/proj/chapter-two.md": []byte("Two.\n")},
+	}
+}
+
+func TestNewMoveCmd_ToProject(t *testing.T) {
+	mock := toProjectMoveIO()
+	c := newMoveCmdWithGetCWD(mock, func() (string, error) { return "/proj", nil })
+	out, err := runCmdInCWD(c, "--source", "chapter-one.md", "--to-project", "../other", "--yes")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "Moved chapter-one.md to /other/_binder.md\nMoved chapter-one.md to /other\n"; out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+	if want := "<!-- prosemark-binder:v1 -->\n- [Chapter Two](chapter-two.md)\n"; string(mock.writtenBytes) != want {
+		t.Errorf("source binder = %q, want %q", mock.writtenBytes, want)
+	}
+	if !strings.HasSuffix(string(mock.binders["/other/_binder.md"]), "- [Chapter One](chapter-one.md)\n") {
+		t.Errorf("destination binder = %q, want chapter one added", mock.binders["/other/_binder.md"])
+	}
+	if _, ok := mock.files["/proj/chapter-one.md"]; ok || string(mock.files["/other/chapter-one.md"]) != "One.\n" {
+		t.Errorf("files = %q, want chapter-one.md moved", mock.files)
+	}
+}
+
+func TestNewMoveCmd_ToProjectDryRunAndJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "dry run", args: []string{"--dry-run"}, want: "Would move chapter-one.md to /other\n"},
+		{name: "json", args: []string{"--json"}, want: `"changed":true`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := toProjectMoveIO()
+			c := newMoveCmdWithGetCWD(mock, func() (string, error) { return "/proj", nil })
+			out, err := runCmdInCWD(c, append([]string{"--source", "chapter-one.md", "--to-project", "../other", "--yes"}, tt.args...)...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("output = %q, want %q", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMoveCmd_ToProjectErrors(t *testing.T) {
+	badDest := t.TempDir()
+	if err := os.WriteFile(filepath.Join(badDest, ".prosemark.yml"), []byte("id_scheme: bogus\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		getwd   func() (string, error)
+		project string
+		out     *errWriter
+		wantErr string
+	}{
+		{name: "getwd", getwd: func() (string, error) { return "", errors.New("getwd failed") }, project: "../other", wantErr: "getwd failed"},
+		{name: "same project", project: ".", wantErr: "the destination is the source project"},
+		{name: "destination config", project: badDest, wantErr: `unknown id_scheme "bogus"`},
+		{name: "output", project: "../other", out: &errWriter{errors.New("closed")}, wantErr: "writing output: closed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := toProjectMoveIO()
+			c := newMoveCmdWithGetCWD(mock, func() (string, error) { return "/proj", nil })
+			if tt.getwd != nil {
+				c = newMoveCmdWithGetCWD(mock, tt.getwd)
+			}
+			c.SetErr(new(bytes.Buffer))
+			c.SetOut(new(bytes.Buffer))
+			if tt.out != nil {
+				c.SetOut(tt.out)
+			}
+			c.SetArgs([]string{"--source", "chapter-one.md", "--to-project", tt.project, "--yes", "--project", "/proj"})
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileMoveIO_ReadNodeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ch1.md")
+	if err := os.WriteFile(path, []byte("One.\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := newDefaultMoveIO().ReadNodeFile(path); err != nil || string(got) != "One.\r\n" {
+		t.Errorf("ReadNodeFile = %q, %v; want the file as stored", got, err)
+	}
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+// NewProjectIO handles I/O for the new-project command.
+type NewProjectIO interface {
+	core.TxIO
+	StatFile(path string) (bool, error)
+	ReadOutline(path string) ([]byte, error)
+}
+
+// NewNewProjectCmd creates the new-project subcommand.
+func NewNewProjectCmd(io NewProjectIO) *cobra.Command {
+	return newNewProjectCmdWithGetCWD(io, os.Getwd)
+}
+
+func newNewProjectCmdWithGetCWD(io NewProjectIO, getwd func() (string, error)) *cobra.Command {
+	var fromOutline string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "new-project",
+		Short: "Create a prosemark project from a plain nested-list outline",
+		Long: "Create a prosemark project from a plain nested-list outline.\n\n" +
+			"Each outline item becomes a new UUID node file titled from the item text,\n" +
+			"with the original item text as its synopsis. The binder mirrors the\n" +
+			"outline's nesting.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, err := resolveProjectDirFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			if fromOutline == "" {
+				return fmt.Errorf("--from-outline is required")
+			}
+
+			binderName, err := binderNameFromCmd(cmd, project)
+			if err != nil {
+				return err
+			}
+			binderPath := filepath.Join(project, binderName)
+			configPath := filepath.Join(project, ".prosemark.yml")
+
+			binderExists, err := io.StatFile(binderPath)
+			if err != nil {
+				return fmt.Errorf("checking %s: %w", binderPath, err)
+			}
+			if binderExists && !force {
+				return fmt.Errorf("%s already exists in %s; use --force to overwrite", binderName, project)
+			}
+
+			outline, err := io.ReadOutline(fromOutline)
+			if err != nil {
+				return fmt.Errorf("reading outline: %w", err)
+			}
+			items := binder.ParseOutline(outline)
+			if len(items) == 0 {
+				return fmt.Errorf("outline %s contains no list items", sanitizePath(fromOutline))
+			}
+
+			top := binder.AddChildParams{ParentSelector: ".", Position: "last"}
+			ins, err := core.InsertOutline(cmd.Context(), []byte("<!-- prosemark-binder:v1 -->\n"), nil, top, items, nodeIDGenerator, nowUTCFunc())
+			if err != nil {
+				return err
+			}
+			if hasDiagnosticError(ins.Diagnostics) {
+				printDiagnostics(cmd, ins.Diagnostics)
+				return fmt.Errorf("new-project has errors")
+			}
+
+			configExists, err := io.StatFile(configPath)
+			if err != nil {
+				return fmt.Errorf("checking %s: %w", configPath, err)
+			}
+
+			// The node files, binder, and config land together or not at all.
+			tx, err := io.BeginTx(project)
+			if err != nil {
+				return err
+			}
+			for _, n := range ins.Nodes {
+				tx.Create(filepath.Join(project, n.Target), n.Content)
+			}
+			tx.WriteBinder(binderPath, ins.Binder)
+			if !configExists {
+				tx.Create(configPath, []byte("version: \"1\"\n"))
+			}
+			if err := tx.Commit(); err != nil {
+				return err
+			}
+
+			if binderExists {
+				fmt.Fprintln(cmd.ErrOrStderr(), "warning: overwriting existing files")
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Created %s with %d nodes from %s\n",
+				sanitizePath(project), len(ins.Nodes), sanitizePath(fromOutline))
+			return nil
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory (default: current directory)")
+	cmd.Flags().StringVar(&fromOutline, "from-outline", "", "path to a Markdown nested-list outline")
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite an existing binder")
+
+	return cmd
+}
+
+// fileNewProjectIO implements NewProjectIO using OS file I/O.
+type fileNewProjectIO struct {
+	fileInitIO
+	fileTxer
+}
+
+// ReadOutline reads the outline file at path.
+func (f fileNewProjectIO) ReadOutline(path string) ([]byte, error) {
+	return fsio.ReadFile(path)
+}
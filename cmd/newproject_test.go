@@ -0,0 +1,377 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// mockNewProjectIO is a test double for NewProjectIO.
+type mockNewProjectIO struct {
+	existing  map[string]bool // keyed by filepath.Base
+	outline   []byte
+	readErr   error
+	beginErr  error
+	nodeErr   error             // fails writes of node (.md) files
+	statErr   map[string]error  // keyed by filepath.Base
+	writeErr  map[string]error  // keyed by filepath.Base
+	written   map[string]string // keyed by filepath.Base
+	nodeFiles []string          // base names in write order
+}
+
+func newMockNewProjectIO(outline string) *mockNewProjectIO {
+	return &mockNewProjectIO{
+		existing: map[string]bool{},
+		statErr:  map[string]error{},
+		writeErr: map[string]error{},
+		outline:  []byte(outline),
+		written:  map[string]string{},
+	}
+}
+
+func (m *mockNewProjectIO) StatFile(path string) (bool, error) {
+	return m.existing[filepath.Base(path)], m.statErr[filepath.Base(path)]
+}
+
+func (m *mockNewProjectIO) ReadOutline(_ string) ([]byte, error) {
+	return m.outline, m.readErr
+}
+
+func (m *mockNewProjectIO) BeginTx(string) (core.FileTx, error) {
+	if m.beginErr != nil {
+		return nil, m.beginErr
+	}
+	return beginMemTx(m)
+}
+
+// ReadBinder reads a written binder; one not written yet reads as empty,
+// which is what the transaction restores on rollback.
+func (m *mockNewProjectIO) ReadBinder(_ context.Context, path string) ([]byte, error) {
+	return []byte(m.written[filepath.Base(path)]), nil
+}
+
+func (m *mockNewProjectIO) WriteBinderAtomic(_ context.Context, path string, data []byte) error {
+	if err := m.writeErr[filepath.Base(path)]; err != nil {
+		return err
+	}
+	m.written[filepath.Base(path)] = string(data)
+	return nil
+}
+
+func (m *mockNewProjectIO) ReadNodeFile(path string) ([]byte, error) {
+	content, ok := m.written[filepath.Base(path)]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return []byte(content), nil
+}
+
+func (m *mockNewProjectIO) WriteNodeFileAtomic(path string, content []byte) error {
+	base := filepath.Base(path)
+	if err := m.writeErr[base]; err != nil {
+		return err
+	}
+	if m.nodeErr != nil && filepath.Ext(base) == ".md" {
+		return m.nodeErr
+	}
+	m.written[base] = string(content)
+	if filepath.Ext(base) == ".md" {
+		m.nodeFiles = append(m.nodeFiles, base)
+	}
+	return nil
+}
+
+func (m *mockNewProjectIO) DeleteFile(path string) error {
+	delete(m.written, filepath.Base(path))
+	return nil
+}
+
+// sequentialNodeIDs replaces nodeIDGenerator with a deterministic sequence.
+func sequentialNodeIDs(t *testing.T) {
+	t.Helper()
+	origID, origNow := nodeIDGenerator, nowUTCFunc
+	t.Cleanup(func() { nodeIDGenerator, nowUTCFunc = origID, origNow })
+	n := 0
+	nodeIDGenerator = func(node.IDScheme) (string, error) {
+		n++
+		return fmt.Sprintf("01234567-89ab-7def-8000-%012d.md", n), nil
+	}
+	nowUTCFunc = func() string { return "2026-01-01T00:00:00Z" }
+}
+
+func TestNewProjectCmd_FromOutline(t *testing.T) {
+	sequentialNodeIDs(t)
+	mock := newMockNewProjectIO("- Part One\n  - Chapter [1]\n- Part Two\n")
+	c := NewNewProjectCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--project", "/proj", "--from-outline", "outline.md"})
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantBinder := "<!-- prosemark-binder:v1 -->\n\n" +
+		"- [Part One](01234567-89ab-7def-8000-000000000001.md)\n" +
+		"  - [Chapter \\[1\\]](01234567-89ab-7def-8000-000000000002.md)\n" +
+		"- [Part Two](01234567-89ab-7def-8000-000000000003.md)\n"
+	if got := mock.written["_binder.md"]; got != wantBinder {
+		t.Errorf("binder =\n%s\nwant\n%s", got, wantBinder)
+	}
+	if len(mock.nodeFiles) != 3 {
+		t.Fatalf("node files = %v, want 3", mock.nodeFiles)
+	}
+	chapter := mock.written["01234567-89ab-7def-8000-000000000002.md"]
+	for _, want := range []string{"id: 01234567-89ab-7def-8000-000000000002\n", "title: Chapter [1]\n", "synopsis: Chapter [1]\n"} {
+		if !strings.Contains(chapter, want) {
+			t.Errorf("node file missing %q:\n%s", want, chapter)
+		}
+	}
+	if _, ok := mock.written[".prosemark.yml"]; !ok {
+		t.Error("expected .prosemark.yml to be created")
+	}
+	if !strings.Contains(out.String(), "with 3 nodes") {
+		t.Errorf("stdout = %q, want node count", out.String())
+	}
+}
+
+// TestNewProjectCmd_ConfiguredBinder verifies that new-project writes the
+// binder an existing .prosemark.yml names, and fails on an invalid one.
+func TestNewProjectCmd_ConfiguredBinder(t *testing.T) {
+	sequentialNodeIDs(t)
+	dir := t.TempDir()
+	config := filepath.Join(dir, ".prosemark.yml")
+	if err := os.WriteFile(config, []byte("binder: outline.md\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	run := func(mock *mockNewProjectIO) error {
+		c := NewNewProjectCmd(mock)
+		c.SetOut(new(bytes.Buffer))
+		c.SetErr(new(bytes.Buffer))
+		c.SetArgs([]string{"--project", dir, "--from-outline", "outline-src.md"})
+		return c.Execute()
+	}
+
+	mock := newMockNewProjectIO("- Part One\n")
+	mock.existing[".prosemark.yml"] = true
+	if err := run(mock); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := mock.written["outline.md"]; !ok {
+		t.Errorf("written = %v, want the binder at outline.md", mock.written)
+	}
+	if _, ok := mock.written["_binder.md"]; ok {
+		t.Error("wrote _binder.md despite the configured binder")
+	}
+
+	mock = newMockNewProjectIO("- Part One\n")
+	mock.existing["outline.md"] = true
+	if err := run(mock); err == nil || !strings.Contains(err.Error(), "outline.md already exists") {
+		t.Errorf("existing configured binder: err = %v", err)
+	}
+
+	if err := os.WriteFile(config, []byte("binder: outline.md\nid_scheme: bogus\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	mock = newMockNewProjectIO("- Part One\n")
+	if err := run(mock); err == nil || !strings.Contains(err.Error(), `unknown id_scheme "bogus"`) {
+		t.Errorf("invalid config: err = %v, want the config error", err)
+	}
+	if len(mock.written) != 0 {
+		t.Errorf("wrote %v after the config error", mock.written)
+	}
+}
+
+func TestNewProjectCmd_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(m *mockNewProjectIO)
+		args    []string
+		wantErr string
+	}{
+		{"missing flag", nil, []string{"--project", "/proj"}, "--from-outline is required"},
+		{"binder exists", func(m *mockNewProjectIO) { m.existing["_binder.md"] = true }, nil, "already exists"},
+		{"read error", func(m *mockNewProjectIO) { m.readErr = errors.New("nope") }, nil, "reading outline"},
+		{"empty outline", func(m *mockNewProjectIO) { m.outline = []byte("# Title only\n") }, nil, "contains no list items"},
+		{"node write error", func(m *mockNewProjectIO) { m.nodeErr = errors.New("disk") }, nil, "creating /proj/01234567-89ab-7def-8000-000000000001.md: disk"},
+		{"begin transaction error", func(m *mockNewProjectIO) { m.beginErr = errors.New("locked") }, nil, "locked"},
+		{"empty project flag", nil, []string{"--project", "", "--from-outline", "outline.md"}, "--project flag cannot be empty"},
+		{"binder stat error", func(m *mockNewProjectIO) { m.statErr["_binder.md"] = errors.New("denied") }, nil, "checking"},
+		{"binder write error", func(m *mockNewProjectIO) { m.writeErr["_binder.md"] = errors.New("disk") }, nil, "writing binder: disk"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sequentialNodeIDs(t)
+			mock := newMockNewProjectIO("- Item\n")
+			if tt.setup != nil {
+				tt.setup(mock)
+			}
+			args := tt.args
+			if args == nil {
+				args = []string{"--project", "/proj", "--from-outline", "outline.md"}
+			}
+			c := NewNewProjectCmd(mock)
+			c.SetOut(new(bytes.Buffer))
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(args)
+			err := c.Execute()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+			if _, wrote := mock.written["_binder.md"]; wrote {
+				t.Error("binder must not be written on error")
+			}
+		})
+	}
+}
+
+func TestNewProjectCmd_ConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(m *mockNewProjectIO)
+		wantErr string
+	}{
+		{"config stat error", func(m *mockNewProjectIO) { m.statErr[".prosemark.yml"] = errors.New("denied") }, "checking"},
+		{"config write error", func(m *mockNewProjectIO) { m.writeErr[".prosemark.yml"] = errors.New("disk") }, "creating /proj/.prosemark.yml: disk"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sequentialNodeIDs(t)
+			mock := newMockNewProjectIO("- Item\n")
+			tt.setup(mock)
+			c := NewNewProjectCmd(mock)
+			c.SetOut(new(bytes.Buffer))
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs([]string{"--project", "/proj", "--from-outline", "outline.md"})
+			err := c.Execute()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+			// A failed config write rolls back the node file and binder.
+			if len(mock.written) > 1 || mock.written["_binder.md"] != "" {
+				t.Errorf("written = %v, want the transaction rolled back", mock.written)
+			}
+		})
+	}
+}
+
+// TestNewProjectCmd_FailedWriteLeavesNothing verifies that new-project
+// writes its files in one transaction: when one cannot be created, none of
+// the others are left behind.
+func TestNewProjectCmd_FailedWriteLeavesNothing(t *testing.T) {
+	sequentialNodeIDs(t)
+	dir := t.TempDir()
+	outline := filepath.Join(t.TempDir(), "outline.md")
+	if err := os.WriteFile(outline, []byte("- One\n- Two\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// A directory where the second node file goes makes its creation fail.
+	if err := os.Mkdir(filepath.Join(dir, "01234567-89ab-7def-8000-000000000002.md"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewNewProjectCmd(fileNewProjectIO{})
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--project", dir, "--from-outline", outline})
+	if err := c.Execute(); err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, name := range []string{"_binder.md", ".prosemark.yml", "01234567-89ab-7def-8000-000000000001.md"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s left behind: %v", name, err)
+		}
+	}
+	assertNoTxLeftovers(t, dir)
+}
+
+func TestNewProjectCmd_NodeIDErrors(t *testing.T) {
+	t.Run("generator failure", func(t *testing.T) {
+		sequentialNodeIDs(t)
+		nodeIDGenerator = func(node.IDScheme) (string, error) { return "", errors.New("entropy") }
+		c := NewNewProjectCmd(newMockNewProjectIO("- Item\n"))
+		c.SetOut(new(bytes.Buffer))
+		c.SetErr(new(bytes.Buffer))
+		c.SetArgs([]string{"--project", "/proj", "--from-outline", "outline.md"})
+		if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "generating node ID") {
+			t.Errorf("error = %v, want node ID generation error", err)
+		}
+	})
+
+	t.Run("invalid nested target aborts insertion", func(t *testing.T) {
+		sequentialNodeIDs(t)
+		ids := []string{"a.md", "b.md", "c.txt"}
+		nodeIDGenerator = func(node.IDScheme) (string, error) {
+			id := ids[0]
+			ids = ids[1:]
+			return id, nil
+		}
+		mock := newMockNewProjectIO("- A\n  - B\n    - C\n")
+		c := NewNewProjectCmd(mock)
+		errOut := new(bytes.Buffer)
+		c.SetOut(new(bytes.Buffer))
+		c.SetErr(errOut)
+		c.SetArgs([]string{"--project", "/proj", "--from-outline", "outline.md"})
+		if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "new-project has errors") {
+			t.Errorf("error = %v, want diagnostic failure", err)
+		}
+		if len(mock.written) != 0 {
+			t.Errorf("written = %v, want nothing written", mock.written)
+		}
+	})
+}
+
+func TestNewProjectCmd_GetwdError(t *testing.T) {
+	c := newNewProjectCmdWithGetCWD(newMockNewProjectIO("- Item\n"), func() (string, error) {
+		return "", errors.New("getwd failed")
+	})
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--from-outline", "outline.md"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+		t.Errorf("error = %v, want getwd failure", err)
+	}
+}
+
+func TestNewProjectCmd_ForceOverwritesBinder(t *testing.T) {
+	sequentialNodeIDs(t)
+	mock := newMockNewProjectIO("- Item\n")
+	mock.existing["_binder.md"] = true
+	c := NewNewProjectCmd(mock)
+	errOut := new(bytes.Buffer)
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(errOut)
+	c.SetArgs([]string{"--project", "/proj", "--from-outline", "outline.md", "--force"})
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "overwriting") {
+		t.Errorf("stderr = %q, want overwrite warning", errOut.String())
+	}
+}
+
+// Compile-time assertion: fileNewProjectIO satisfies NewProjectIO.
+var _ NewProjectIO = fileNewProjectIO{}
+
+func TestFileNewProjectIO_ReadOutline(t *testing.T) {
+	dir := t.TempDir()
+	outlinePath := filepath.Join(dir, "outline.md")
+	if err := os.WriteFile(outlinePath, []byte("- One\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fio := fileNewProjectIO{}
+	if got, err := fio.ReadOutline(outlinePath); err != nil || string(got) != "- One\n" {
+		t.Errorf("ReadOutline = %q, %v", got, err)
+	}
+}
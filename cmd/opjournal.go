@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+// OpJournal records applied binder operations by idempotency key so a
+// retried command with the same --op-id is not applied twice. Mutating
+// commands discover it on their IO by type assertion, like editDeleter.
+type OpJournal interface {
+	// LookupOp returns the journal entry recorded for opID in projectDir,
+	// or nil when the operation has not been applied.
+	LookupOp(projectDir, opID string) (*fsio.JournalEntry, error)
+	// RecordOp appends entry to projectDir's journal.
+	RecordOp(projectDir string, entry fsio.JournalEntry) error
+}
+
+// opJournaler provides OpJournal backed by the project's journal file.
+// Embed this in file-IO structs alongside binderLocker.
+type opJournaler struct{}
+
+// LookupOp returns the most recent journal entry for opID, or nil.
+func (opJournaler) LookupOp(projectDir, opID string) (*fsio.JournalEntry, error) {
+	entries, err := fsio.ReadJournal(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].OpID == opID {
+			return &entries[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// RecordOp appends entry to the project's journal.
+func (opJournaler) RecordOp(projectDir string, entry fsio.JournalEntry) error {
+	return fsio.AppendJournal(projectDir, entry)
+}
+
+// addOpIDFlag registers --op-id on a mutating command.
+func addOpIDFlag(cmd *cobra.Command) {
+	cmd.Flags().String("op-id", "", "idempotency key (a UUID) to journal this operation under instead of a new one: if it was already applied, report its recorded result instead of applying it again")
+}
+
+// newOpID returns the ID an operation run without --op-id is journaled
+// under. Override in tests to inject specific values.
+var newOpID = uuid.NewString
+
+// opJournalFromCmd returns the ID to journal the command's operation under,
+// the --op-id value or else a new one, and the journal to record it in. The
+// journal is nil when io has none and --op-id was not given.
+func opJournalFromCmd(cmd *cobra.Command, io any) (string, OpJournal, error) {
+	journal, ok := io.(OpJournal)
+	if !cmd.Flags().Changed("op-id") {
+		if !ok {
+			return "", nil, nil
+		}
+		return newOpID(), journal, nil
+	}
+	opID, err := cmd.Flags().GetString("op-id")
+	if err != nil {
+		return "", nil, fmt.Errorf("reading --op-id: %w", err)
+	}
+	if _, err := uuid.Parse(opID); err != nil {
+		return "", nil, fmt.Errorf("invalid --op-id %q: must be a UUID", opID)
+	}
+	if !ok {
+		return "", nil, fmt.Errorf("--op-id is not supported: no operation journal available")
+	}
+	return opID, journal, nil
+}
+
+// opReportingFlags are the flags that change only how a command reports its
+// result or where it finds the project, so a retry may set them differently.
+var opReportingFlags = map[string]bool{
+	"op-id": true, "json": true, "format": true, "diff": true, "dry-run": true,
+	"yes": true, "project": true, "chdir": true, "config": true, "max-diagnostics": true,
+}
+
+// opParamsKey is the context key of the parameter hash replayRecordedOp
+// computes, which recordOp journals.
+type opParamsKey struct{}
+
+// opParamsHash returns a hash of what cmd was asked to do: its name and
+// arguments, the flags set other than opReportingFlags, and the operations
+// set by withJournalOps so far.
+func opParamsHash(cmd *cobra.Command) string {
+	parts := append([]string{cmd.Name()}, cmd.Flags().Args()...)
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if !opReportingFlags[f.Name] {
+			parts = append(parts, "--"+f.Name+"="+f.Value.String())
+		}
+	})
+	for _, spec := range journalOps(cmd) {
+		parts = append(parts, spec.Operation+" "+string(spec.Params))
+	}
+	for i, p := range parts {
+		parts[i] = strconv.Quote(p)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// replayRecordedOp checks the journal for the command's --op-id. When the
+// operation was already applied it reports the recorded result with
+// changed=false, since nothing changes this time, and returns true so the
+// caller stops without applying the operation again. An --op-id already
+// used with other parameters is an error, since the caller would otherwise
+// be told a different operation succeeded.
+func replayRecordedOp(cmd *cobra.Command, io any, binderPath string, jsonMode bool) (bool, error) {
+	opID, journal, err := opJournalFromCmd(cmd, io)
+	if err != nil || journal == nil {
+		return false, err
+	}
+	hash := opParamsHash(cmd)
+	cmd.SetContext(context.WithValue(cmd.Context(), opParamsKey{}, hash))
+	if !cmd.Flags().Changed("op-id") {
+		return false, nil // a new ID, so nothing to replay
+	}
+	entry, err := journal.LookupOp(filepath.Dir(binderPath), opID)
+	if err != nil {
+		return false, fmt.Errorf("reading operation journal: %w", err)
+	}
+	if entry == nil {
+		return false, nil
+	}
+	if entry.Command != cmd.Name() {
+		return false, fmt.Errorf("--op-id %s was already used by %q, not %q", opID, entry.Command, cmd.Name())
+	}
+	if entry.ParamsHash != "" && entry.ParamsHash != hash {
+		return false, fmt.Errorf("--op-id %s was already used by %q with different arguments or flags", opID, entry.Command)
+	}
+	result := entry.Result
+	result.Changed = false
+	if jsonMode {
+		if err := encodeOutput(cmd, result); err != nil {
+			return false, fmt.Errorf("encoding output: %w", err)
+		}
+		return true, nil
+	}
+	if _, err := fmt.Fprintf(cmd.OutOrStdout(), "Operation %s already applied at %s (skipped)\n", opID, entry.Time); err != nil {
+		return false, fmt.Errorf("writing output: %w", err)
+	}
+	return true, nil
+}
+
+// recordOp records a successfully applied operation under the command's
+// --op-id, or a new ID when none was given. It does nothing when io has no
+// journal.
+func recordOp(cmd *cobra.Command, io any, binderPath string, result binder.OpResult) error {
+	opID, journal, err := opJournalFromCmd(cmd, io)
+	if err != nil || journal == nil {
+		return err
+	}
+	result.Diff = ""
+	hash, _ := cmd.Context().Value(opParamsKey{}).(string)
+	entry := fsio.JournalEntry{
+		OpID:         opID,
+		Command:      cmd.Name(),
+		Time:         nowUTCFunc(),
+		ParamsHash:   hash,
+		Result:       result,
+		Ops:          journalOps(cmd),
+		BinderBefore: result.BinderBefore,
+		BinderAfter:  result.BinderAfter,
+	}
+	if err := journal.RecordOp(filepath.Dir(binderPath), entry); err != nil {
+		return fmt.Errorf("recording operation journal: %w", err)
+	}
+	return nil
+}
+
+// journalOpsKey is the context key of the operations a command journals.
+type journalOpsKey struct{}
+
+// withJournalOps sets specs as the operations cmd applies, which recordOp
+// journals so that pmk journal replay can apply them elsewhere. Commands
+// that do not set them are journaled without operations.
+func withJournalOps(cmd *cobra.Command, specs ...binder.OpSpec) {
+	cmd.SetContext(context.WithValue(cmd.Context(), journalOpsKey{}, specs))
+}
+
+// journalOps returns the operations set by withJournalOps, or nil.
+func journalOps(cmd *cobra.Command) []binder.OpSpec {
+	specs, _ := cmd.Context().Value(journalOpsKey{}).([]binder.OpSpec)
+	return specs
+}
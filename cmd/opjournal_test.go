@@ -0,0 +1,387 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+const testOpID = "0192f0c1-3e7a-7000-8000-5a4b3c2d1e0f"
+
+// memOpJournal is an in-memory OpJournal. Embed it beside a command's mock IO
+// to give that mock --op-id support.
+type memOpJournal struct {
+	entries   []fsio.JournalEntry
+	lookupErr error
+	recordErr error
+}
+
+func (j *memOpJournal) LookupOp(_, opID string) (*fsio.JournalEntry, error) {
+	if j.lookupErr != nil {
+		return nil, j.lookupErr
+	}
+	for i := range j.entries {
+		if j.entries[i].OpID == opID {
+			return &j.entries[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (j *memOpJournal) RecordOp(_ string, entry fsio.JournalEntry) error {
+	if j.recordErr != nil {
+		return j.recordErr
+	}
+	j.entries = append(j.entries, entry)
+	return nil
+}
+
+// opIDCommand builds a mutating command whose IO records to j, and returns
+// it with a func reporting the bytes it wrote to the binder.
+type opIDCommand struct {
+	name  string
+	build func(j *memOpJournal) (*cobra.Command, func() []byte)
+	args  []string
+	stdin string
+}
+
+func opIDCommands() []opIDCommand {
+	addCmd := func(j *memOpJournal) (*cobra.Command, func() []byte) {
+		mock := &mockAddChildIOWithNew{mockAddChildIO: mockAddChildIO{binderBytes: emptyBinder()}}
+		return NewAddChildCmd(struct {
+			*mockAddChildIOWithNew
+			*memOpJournal
+		}{mock, j}), func() []byte { return mock.writtenBytes }
+	}
+	return []opIDCommand{
+		{"add", addCmd, []string{"--parent", ".", "--target", "ch.md"}, ""},
+		{"add --new", addCmd, []string{"--parent", ".", "--new", "--title", "Fresh"}, ""},
+		{"add --outline", addCmd, []string{"--parent", ".", "--outline", "-"}, "- a.md\n- b.md\n"},
+		{"delete", func(j *memOpJournal) (*cobra.Command, func() []byte) {
+			mock := &mockDeleteIO{binderBytes: delBinder()}
+			return NewDeleteCmd(struct {
+				*mockDeleteIO
+				*memOpJournal
+			}{mock, j}), func() []byte { return mock.writtenBytes }
+		}, []string{"--selector", "chapter-one.md", "--yes"}, ""},
+		{"move", func(j *memOpJournal) (*cobra.Command, func() []byte) {
+			mock := &mockMoveIO{binderBytes: moveBinder()}
+			return NewMoveCmd(struct {
+				*mockMoveIO
+				*memOpJournal
+			}{mock, j}), func() []byte { return mock.writtenBytes }
+		}, []string{"--source", "chapter-two.md", "--dest", ".", "--first", "--yes"}, ""},
+		{"check", func(j *memOpJournal) (*cobra.Command, func() []byte) {
+			mock := &mockCheckIO{binderBytes: checkBinder("- [Chapter One](chapter-one.md)")}
+			return NewCheckCmd(struct {
+				*mockCheckIO
+				*memOpJournal
+			}{mock, j}), func() []byte { return mock.writtenBytes }
+		}, []string{"chapter-one"}, ""},
+	}
+}
+
+func runOpIDCommand(tc opIDCommand, j *memOpJournal, extra ...string) (string, []byte, error) {
+	c, written := tc.build(j)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetIn(strings.NewReader(tc.stdin))
+	c.SetArgs(append(append([]string{"--project", ".", "--op-id", testOpID}, tc.args...), extra...))
+	err := c.Execute()
+	return out.String(), written(), err
+}
+
+func TestOpID_RetryReplaysRecordedResult(t *testing.T) {
+	for _, tc := range opIDCommands() {
+		t.Run(tc.name, func(t *testing.T) {
+			j := &memOpJournal{}
+			if _, written, err := runOpIDCommand(tc, j); err != nil || written == nil {
+				t.Fatalf("first run: err = %v, written = %q", err, written)
+			}
+			if len(j.entries) != 1 {
+				t.Fatalf("journal has %d entries, want 1", len(j.entries))
+			}
+			entry := j.entries[0]
+			if entry.OpID != testOpID || entry.Time == "" || !entry.Result.Changed || !strings.HasPrefix(tc.name, entry.Command) {
+				t.Errorf("recorded entry = %+v", entry)
+			}
+
+			out, written, err := runOpIDCommand(tc, j, "--json")
+			if err != nil {
+				t.Fatalf("retry: %v", err)
+			}
+			if written != nil {
+				t.Errorf("retry wrote the binder again: %q", written)
+			}
+			var result binder.OpResult
+			if err := json.Unmarshal([]byte(out), &result); err != nil {
+				t.Fatalf("retry output %q: %v", out, err)
+			}
+			if result.Changed || result.Version != "1" {
+				t.Errorf("retry result = %+v, want version 1 and changed:false", result)
+			}
+			if len(j.entries) != 1 {
+				t.Errorf("retry recorded again: %d entries", len(j.entries))
+			}
+		})
+	}
+}
+
+func TestOpID_RetryWithOtherParameters(t *testing.T) {
+	for _, tc := range opIDCommands() {
+		t.Run(tc.name, func(t *testing.T) {
+			j := &memOpJournal{}
+			if _, _, err := runOpIDCommand(tc, j); err != nil {
+				t.Fatalf("first run: %v", err)
+			}
+			if j.entries[0].ParamsHash == "" {
+				t.Error("no parameter hash recorded")
+			}
+			_, written, err := runOpIDCommand(tc, j, "--force-parse")
+			if err == nil || !strings.Contains(err.Error(), "with different arguments or flags") {
+				t.Errorf("err = %v, want the parameter mismatch", err)
+			}
+			if written != nil {
+				t.Errorf("mismatched retry wrote the binder: %q", written)
+			}
+		})
+	}
+
+	// Entries journaled without a hash are replayed as before.
+	tc := opIDCommands()[3] // delete
+	j := &memOpJournal{entries: []fsio.JournalEntry{{OpID: testOpID, Command: "delete"}}}
+	if _, _, err := runOpIDCommand(tc, j, "--force-parse"); err != nil {
+		t.Errorf("replay of an entry without a hash: %v", err)
+	}
+}
+
+func TestOpParamsHash(t *testing.T) {
+	hash := func(args ...string) string {
+		t.Helper()
+		var got string
+		c := &cobra.Command{Use: "move", RunE: func(cmd *cobra.Command, _ []string) error {
+			got = opParamsHash(cmd)
+			return nil
+		}}
+		c.Flags().String("source", "", "")
+		c.Flags().Bool("json", false, "")
+		c.Flags().String("op-id", "", "")
+		c.SetArgs(args)
+		if err := c.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	base := hash("--source", "a.md")
+	if hash("--source", "a.md", "--json", "--op-id", testOpID) != base {
+		t.Error("reporting flags changed the hash")
+	}
+	for _, args := range [][]string{{"--source", "b.md"}, {"--source", "a.md", "x"}, {}} {
+		if hash(args...) == base {
+			t.Errorf("%v hashes like --source a.md", args)
+		}
+	}
+}
+
+func TestOpID_RecordsOpsAndBinderHashes(t *testing.T) {
+	wantOps := map[string][]string{
+		"add":           {binder.OpAdd},
+		"add --new":     {binder.OpAdd},
+		"add --outline": {binder.OpAdd, binder.OpAdd},
+		"delete":        {binder.OpDelete},
+		"move":          {binder.OpMove},
+	}
+	for _, tc := range opIDCommands() {
+		t.Run(tc.name, func(t *testing.T) {
+			j := &memOpJournal{}
+			if _, _, err := runOpIDCommand(tc, j); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			entry := j.entries[0]
+			if !strings.HasPrefix(entry.BinderBefore, "sha256:") || !strings.HasPrefix(entry.BinderAfter, "sha256:") || entry.BinderBefore == entry.BinderAfter {
+				t.Errorf("binder hashes = %q, %q; want two different sha256 hashes", entry.BinderBefore, entry.BinderAfter)
+			}
+			want, replayable := wantOps[tc.name]
+			if !replayable {
+				if entry.Ops != nil {
+					t.Errorf("ops = %+v, want none", entry.Ops)
+				}
+				return
+			}
+			var got []string
+			for _, spec := range entry.Ops {
+				got = append(got, spec.Operation)
+				if _, err := spec.DecodeParams(); err != nil {
+					t.Errorf("recorded op does not decode: %v", err)
+				}
+			}
+			if !slices.Equal(got, want) {
+				t.Fatalf("ops = %+v, want %v", entry.Ops, want)
+			}
+		})
+	}
+}
+
+func TestOpID_RecordFailure(t *testing.T) {
+	for _, tc := range opIDCommands() {
+		t.Run(tc.name, func(t *testing.T) {
+			j := &memOpJournal{recordErr: errors.New("disk full")}
+			_, _, err := runOpIDCommand(tc, j)
+			if err == nil || !strings.Contains(err.Error(), "recording operation journal: disk full") {
+				t.Errorf("err = %v, want journal record error", err)
+			}
+		})
+	}
+}
+
+func TestOpID_GeneratedWhenNotGiven(t *testing.T) {
+	orig := newOpID
+	t.Cleanup(func() { newOpID = orig })
+	newOpID = func() string { return testOpID }
+	for _, tc := range opIDCommands() {
+		t.Run(tc.name, func(t *testing.T) {
+			// A recorded entry under the generated ID is not a retry.
+			j := &memOpJournal{entries: []fsio.JournalEntry{{OpID: testOpID, Command: "other"}}}
+			c, written := tc.build(j)
+			c.SetOut(new(bytes.Buffer))
+			c.SetErr(new(bytes.Buffer))
+			c.SetIn(strings.NewReader(tc.stdin))
+			c.SetArgs(append([]string{"--project", "."}, tc.args...))
+			if err := c.Execute(); err != nil || written() == nil {
+				t.Fatalf("err = %v, written = %q", err, written())
+			}
+			if len(j.entries) != 2 {
+				t.Fatalf("journal has %d entries, want 2", len(j.entries))
+			}
+			if entry := j.entries[1]; entry.OpID != testOpID || entry.ParamsHash == "" || !strings.HasPrefix(tc.name, entry.Command) {
+				t.Errorf("recorded entry = %+v", entry)
+			}
+		})
+	}
+}
+
+func TestOpID_TextReplay(t *testing.T) {
+	tc := opIDCommands()[3] // delete
+	j := &memOpJournal{entries: []fsio.JournalEntry{{OpID: testOpID, Command: "delete", Time: "2026-01-02T03:04:05Z"}}}
+	out, written, err := runOpIDCommand(tc, j)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if written != nil {
+		t.Error("replay must not write the binder")
+	}
+	if want := "Operation " + testOpID + " already applied at 2026-01-02T03:04:05Z (skipped)\n"; out != want {
+		t.Errorf("stdout = %q, want %q", out, want)
+	}
+}
+
+func TestOpID_Errors(t *testing.T) {
+	recorded := []fsio.JournalEntry{{OpID: testOpID, Command: "move"}}
+	tests := []struct {
+		name    string
+		journal *memOpJournal
+		args    []string
+		noIO    bool
+		out     *errWriter
+		wantErr string
+	}{
+		{name: "invalid op-id", journal: &memOpJournal{}, args: []string{"--op-id", "not-a-uuid"}, wantErr: `invalid --op-id "not-a-uuid": must be a UUID`},
+		{name: "empty op-id", journal: &memOpJournal{}, args: []string{"--op-id", ""}, wantErr: "must be a UUID"},
+		{name: "IO without a journal", noIO: true, wantErr: "--op-id is not supported"},
+		{name: "lookup failure", journal: &memOpJournal{lookupErr: errors.New("corrupt")}, wantErr: "reading operation journal: corrupt"},
+		{name: "op-id used by another command", journal: &memOpJournal{entries: recorded}, wantErr: `already used by "move", not "delete"`},
+		{
+			name:    "json replay write failure",
+			journal: &memOpJournal{entries: []fsio.JournalEntry{{OpID: testOpID, Command: "delete"}}},
+			args:    []string{"--json"},
+			out:     &errWriter{errors.New("closed")},
+			wantErr: "encoding output",
+		},
+		{
+			name:    "text replay write failure",
+			journal: &memOpJournal{entries: []fsio.JournalEntry{{OpID: testOpID, Command: "delete"}}},
+			out:     &errWriter{errors.New("closed")},
+			wantErr: "writing output",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockDeleteIO{binderBytes: delBinder()}
+			var c *cobra.Command
+			if tt.noIO {
+				c = NewDeleteCmd(mock)
+			} else {
+				c = NewDeleteCmd(struct {
+					*mockDeleteIO
+					*memOpJournal
+				}{mock, tt.journal})
+			}
+			if tt.out != nil {
+				c.SetOut(tt.out)
+			} else {
+				c.SetOut(new(bytes.Buffer))
+			}
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(append([]string{"--project", ".", "--selector", "chapter-one.md", "--yes", "--op-id", testOpID}, tt.args...))
+			err := c.Execute()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+			if mock.writtenBytes != nil {
+				t.Error("binder must not be written")
+			}
+		})
+	}
+}
+
+func TestOpJournalFromCmd_FlagError(t *testing.T) {
+	c := &cobra.Command{Use: "op"}
+	c.Flags().Int("op-id", 0, "")
+	if err := c.Flags().Set("op-id", "7"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := opJournalFromCmd(c, &memOpJournal{}); err == nil || !strings.Contains(err.Error(), "reading --op-id") {
+		t.Errorf("err = %v, want the flag read failure", err)
+	}
+}
+
+func TestOpJournaler_FileJournal(t *testing.T) {
+	dir := t.TempDir()
+	var j opJournaler
+
+	if got, err := j.LookupOp(dir, testOpID); err != nil || got != nil {
+		t.Fatalf("LookupOp on empty journal = %v, %v", got, err)
+	}
+	for _, e := range []fsio.JournalEntry{
+		{OpID: testOpID, Command: "add", Time: "first"},
+		{OpID: "other", Command: "move"},
+		{OpID: testOpID, Command: "add", Time: "second"},
+	} {
+		if err := j.RecordOp(dir, e); err != nil {
+			t.Fatalf("RecordOp: %v", err)
+		}
+	}
+	got, err := j.LookupOp(dir, testOpID)
+	if err != nil || got == nil || got.Time != "second" {
+		t.Errorf("LookupOp = %+v, %v; want the latest entry", got, err)
+	}
+
+	// A journal path that is a directory cannot be read.
+	broken := t.TempDir()
+	if err := os.MkdirAll(fsio.JournalPath(broken), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.LookupOp(broken, testOpID); err == nil {
+		t.Error("expected error reading a journal that is a directory")
+	}
+}
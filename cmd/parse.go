@@ -2,25 +2,31 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-
-	"github.com/spf13/cobra"
+	"path/filepath"
+	"strings"
 
 	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/spf13/cobra"
 )
 
 // ParseReader reads the binder file and scans the project directory for the parse command.
+// A reader that is also a core.NodeFileReader supports --resolve-titles.
 type ParseReader interface {
-	ReadBinder(ctx context.Context, path string) ([]byte, error)
-	ScanProject(ctx context.Context, binderPath string) (*binder.Project, error)
+	core.ParseIO
 }
 
 // parseOutput is the JSON output schema for the parse command.
 type parseOutput struct {
 	Version     string              `json:"version"`
 	Root        *binder.Node        `json:"root"`
+	Fenced      []*binder.Node      `json:"fenced,omitempty"`
+	Fences      []*binder.Fence     `json:"fences,omitempty"`
 	Diagnostics []binder.Diagnostic `json:"diagnostics"`
 }
 
@@ -31,52 +37,111 @@ func NewParseCmd(reader ParseReader) *cobra.Command {
 
 func newParseCmdWithGetCWD(reader ParseReader, getwd func() (string, error)) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:          "parse",
-		Short:        "Parse a binder file and output JSON",
-		Args:         cobra.NoArgs,
+		Use:   "parse [binder-path]",
+		Short: "Parse a binder file and output JSON",
+		Long: "Parse a binder file and output JSON.\n\n" +
+			"The binder is the project's binder (_binder.md unless .prosemark.yml names\n" +
+			"another) in --project, or the file named by binder-path. With neither, it\n" +
+			"is the binder in the current directory or its nearest ancestor, so parse\n" +
+			"finds the project root from anywhere inside it.\n" +
+			"A binder path takes precedence over --project and its directory is the\n" +
+			"project root; when the two disagree a PMKW001 warning is reported. A\n" +
+			"directory names the binder in it or in its nearest ancestor.\n\n" +
+			"--workspace parses every binder under the project directory, or under the\n" +
+			"directory given, without searching its ancestors.\n\n" +
+			"--format github prints the diagnostics as GitHub Actions workflow commands\n" +
+			"instead, so a CI step annotates binder problems inline on pull requests.\n\n" +
+			"--repair-encoding reads a binder with invalid UTF-8 anyway, as U+FFFD,\n" +
+			"reporting the byte offsets in a PMKE008 error so the corruption can be found.\n\n" +
+			"--resolve-titles reads each entry's node file and adds its frontmatter title\n" +
+			"to the tree as fileTitle, with a PMKW012 warning where it differs from the\n" +
+			"entry's binder title.",
+		Args:         cobra.MaximumNArgs(1),
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			github, err := parseGitHubFormatFromCmd(cmd)
 			if err != nil {
 				return err
 			}
 
-			ctx := cmd.Context()
+			workspace, _ := cmd.Flags().GetBool("workspace")
+			if workspace {
+				if args, err = workspaceArgs(cmd, args, getwd); err != nil {
+					return err
+				}
+			}
 
-			binderBytes, err := reader.ReadBinder(ctx, binderPath)
+			var binderPath string
+			var invocationDiags []binder.Diagnostic
+			if len(args) == 0 && !cmd.Flags().Changed("project") && !workspace {
+				binderPath, err = discoverBinderPath(cmd, getwd)
+			} else {
+				binderPath, invocationDiags, err = resolveBinderPathWithArg(cmd, args, getwd)
+			}
 			if err != nil {
-				return fmt.Errorf("reading binder: %w", err)
+				return err
 			}
 
-			proj, err := reader.ScanProject(ctx, binderPath)
-			if err != nil {
-				return fmt.Errorf("scanning project: %w", err)
+			ctx := cmd.Context()
+			opts := parseOptionsFromCmd(cmd)
+			if resolve, _ := cmd.Flags().GetBool("resolve-titles"); resolve {
+				if workspace {
+					return fmt.Errorf("--resolve-titles conflicts with --workspace")
+				}
+				opts.ResolveTitles = true
 			}
 
-			result, diags, parseErr := binder.Parse(ctx, binderBytes, proj)
-			if parseErr != nil {
-				diags = append(diags, binder.Diagnostic{
-					Severity: "error",
-					Code:     binder.CodeIOOrParseFailure,
-					Message:  fmt.Sprintf("parse error: %v", parseErr),
+			if watchRequested(cmd) {
+				if workspace {
+					return fmt.Errorf("--watch conflicts with --workspace")
+				}
+				if format, _ := cmd.Flags().GetString("format"); format != formatJSON {
+					return fmt.Errorf("--watch conflicts with --format %s", format)
+				}
+				return runWatch(cmd, reader, filepath.Dir(binderPath), func(ctx context.Context) (any, error) {
+					parsed, err := core.Parse(ctx, reader, binderPath, opts)
+					if err != nil {
+						return nil, err
+					}
+					diags := append(append([]binder.Diagnostic{}, invocationDiags...), parsed.Diagnostics...)
+					return reportedDiagnostics(cmd, diags), nil
 				})
 			}
-			if diags == nil {
-				diags = []binder.Diagnostic{}
+
+			if workspace {
+				var annotate func(string) string
+				if github {
+					annotate = func(p string) string { return annotationPath(p, getwd) }
+				}
+				return runParseWorkspace(cmd, reader, binderPath, invocationDiags, annotate)
 			}
 
-			out := parseOutput{
-				Version:     result.Version,
-				Root:        result.Root,
-				Diagnostics: diags,
+			parsed, err := core.Parse(ctx, reader, binderPath, opts)
+			if err != nil {
+				return err
 			}
-			if err = json.NewEncoder(cmd.OutOrStdout()).Encode(out); err != nil {
-				return fmt.Errorf("encoding output: %w", err)
+			diags := append(append([]binder.Diagnostic{}, invocationDiags...), parsed.Diagnostics...)
+
+			if github {
+				if _, err := io.WriteString(cmd.OutOrStdout(), binderAnnotations(annotationPath(binderPath, getwd), reportedDiagnostics(cmd, diags))); err != nil {
+					return fmt.Errorf("writing output: %w", err)
+				}
+			} else {
+				out := parseOutput{
+					Version:     parsed.Result.Version,
+					Root:        parsed.Result.Root,
+					Fenced:      parsed.Result.Fenced,
+					Fences:      parsed.Result.Fences,
+					Diagnostics: reportedDiagnostics(cmd, diags),
+				}
+				if err = encodeOutput(cmd, out); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
 			}
 
 			if hasDiagnosticError(diags) {
-				if parseErr != nil {
-					return fmt.Errorf("binder has parse errors: %w", parseErr)
+				if parsed.Err != nil {
+					return fmt.Errorf("binder has parse errors: %w", parsed.Err)
 				}
 				return fmt.Errorf("binder has parse errors")
 			}
@@ -84,24 +149,173 @@ func newParseCmdWithGetCWD(reader ParseReader, getwd func() (string, error)) *co
 		},
 	}
 
-	cmd.Flags().String("project", "", "project directory containing _binder.md (default: current directory)")
+	cmd.Flags().String("project", "", "project directory containing the binder (default: the nearest directory up from the current one that has one; a binder-path argument takes precedence)")
 	cmd.Flags().Bool("json", false, "Output result as JSON (always enabled for parse)")
+	cmd.Flags().String("format", formatJSON, "output format: json, yaml, or github to print diagnostics as GitHub Actions annotations")
+	cmd.Flags().Bool("workspace", false, "Parse every binder under the project directory and combine diagnostics")
+	cmd.Flags().Bool("resolve-titles", false, "add each node file's frontmatter title to the tree and warn where it differs from the binder title (PMKW012)")
+	addRepairEncodingFlag(cmd)
+	addWatchFlags(cmd)
 
 	return cmd
 }
 
+// parseGitHubFormatFromCmd resolves --format, reporting whether GitHub
+// annotations were requested. --json conflicts with github.
+func parseGitHubFormatFromCmd(cmd *cobra.Command) (bool, error) {
+	format, _ := cmd.Flags().GetString("format")
+	if format != formatJSON && format != formatYAML && format != formatGitHub {
+		return false, fmt.Errorf("unknown --format %q: want json, yaml, or github", format)
+	}
+	if jsonMode, _ := cmd.Flags().GetBool("json"); jsonMode && format == formatGitHub {
+		return false, fmt.Errorf("--json conflicts with --format %s", format)
+	}
+	return format == formatGitHub, nil
+}
+
+// binderAnnotations renders diags as GitHub annotations on file, the binder
+// they were reported for. An empty file leaves them unattached.
+func binderAnnotations(file string, diags []binder.Diagnostic) string {
+	var b strings.Builder
+	for _, d := range diags {
+		line, col := 0, 0
+		if d.Location != nil {
+			line, col = d.Location.Line, d.Location.Column
+		}
+		b.WriteString(githubAnnotation(d.Severity, file, line, col, d.Code, d.Message))
+	}
+	return b.String()
+}
+
+// workspaceArgs returns args with a directory replaced by the binder file in
+// it, present or not, so --workspace parses the binders under that directory
+// instead of under the nearest ancestor with a binder.
+func workspaceArgs(cmd *cobra.Command, args []string, getwd func() (string, error)) ([]string, error) {
+	if len(args) == 0 || !fsio.IsDir(args[0]) {
+		return args, nil
+	}
+	dir, err := absFromCWD(args[0], getwd)
+	if err != nil {
+		return nil, err
+	}
+	name, err := binderNameFromCmd(cmd, dir)
+	if err != nil {
+		return nil, err
+	}
+	return []string{filepath.Join(dir, name)}, nil
+}
+
+// workspaceBinderOutput is one binder's entry in parse --workspace output.
+type workspaceBinderOutput struct {
+	Path        string              `json:"path"`
+	Root        *binder.Node        `json:"root"`
+	Fenced      []*binder.Node      `json:"fenced,omitempty"`
+	Fences      []*binder.Fence     `json:"fences,omitempty"`
+	Diagnostics []binder.Diagnostic `json:"diagnostics"`
+}
+
+// workspaceParseOutput is the JSON output schema for parse --workspace.
+// Diagnostics combines every binder's diagnostics, each message prefixed with
+// the binder path relative to the workspace directory.
+type workspaceParseOutput struct {
+	Version     string                  `json:"version"`
+	Binders     []workspaceBinderOutput `json:"binders"`
+	Diagnostics []binder.Diagnostic     `json:"diagnostics"`
+}
+
+// runParseWorkspace parses the workspace binder at binderPath (when present)
+// and every nested binder ScanProject discovers beneath it, then reports the
+// combined result. It fails if any binder has an error diagnostic.
+// invocationDiags (such as PMKW001) lead the combined diagnostics. When
+// annotate is set, diagnostics are printed as GitHub annotations instead,
+// each on the binder path annotate returns.
+func runParseWorkspace(cmd *cobra.Command, reader ParseReader, binderPath string, invocationDiags []binder.Diagnostic, annotate func(path string) string) error {
+	ctx := cmd.Context()
+	workspaceDir := filepath.Dir(binderPath)
+
+	ws, err := reader.ScanProject(ctx, binderPath)
+	if err != nil {
+		return fmt.Errorf("scanning project: %w", err)
+	}
+
+	var paths []string
+	if _, err := reader.ReadBinder(ctx, binderPath); err == nil {
+		paths = append(paths, ws.ActiveBinderFile())
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading binder: %w", err)
+	}
+	paths = append(paths, ws.AltBinders...)
+	if len(paths) == 0 {
+		return fmt.Errorf("no binders found under %s", sanitizePath(workspaceDir))
+	}
+
+	out := workspaceParseOutput{Version: "1", Binders: []workspaceBinderOutput{}, Diagnostics: append([]binder.Diagnostic{}, invocationDiags...)}
+	annotations := binderAnnotations("", invocationDiags)
+	for _, rel := range paths {
+		path := filepath.Join(workspaceDir, filepath.FromSlash(rel))
+
+		binderBytes, err := reader.ReadBinder(ctx, path)
+		if err != nil {
+			return fmt.Errorf("reading binder %s: %w", rel, err)
+		}
+		proj, err := reader.ScanProject(ctx, path)
+		if err != nil {
+			return fmt.Errorf("scanning project for %s: %w", rel, err)
+		}
+
+		parsed := core.ParseBinder(ctx, binderBytes, proj, parseOptionsFromCmd(cmd))
+		out.Binders = append(out.Binders, workspaceBinderOutput{
+			Path:        rel,
+			Root:        parsed.Result.Root,
+			Fenced:      parsed.Result.Fenced,
+			Fences:      parsed.Result.Fences,
+			Diagnostics: reportedDiagnostics(cmd, parsed.Diagnostics),
+		})
+		for _, d := range parsed.Diagnostics {
+			d.Message = rel + ": " + d.Message
+			out.Diagnostics = append(out.Diagnostics, d)
+		}
+		if annotate != nil {
+			annotations += binderAnnotations(annotate(path), reportedDiagnostics(cmd, parsed.Diagnostics))
+		}
+	}
+
+	hasErrors := hasDiagnosticError(out.Diagnostics)
+	out.Diagnostics = reportedDiagnostics(cmd, out.Diagnostics)
+	if annotate != nil {
+		if _, err := io.WriteString(cmd.OutOrStdout(), annotations); err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+	} else if err := encodeOutput(cmd, out); err != nil {
+		return fmt.Errorf("encoding output: %w", err)
+	}
+
+	if hasErrors {
+		return fmt.Errorf("workspace has parse errors")
+	}
+	return nil
+}
+
 // fileParseReader implements ParseReader using OS file I/O.
-type fileParseReader struct{}
+type fileParseReader struct {
+	projectWatcher
+}
 
 func newDefaultParseReader() *fileParseReader {
 	return &fileParseReader{}
 }
 
 func (r *fileParseReader) ReadBinder(_ context.Context, path string) ([]byte, error) {
-	return readBinderSizeLimitedImpl(path)
+	return fsio.ReadBinder(path)
 }
 
 // ScanProject scans the project directory for .md files.
 func (r *fileParseReader) ScanProject(ctx context.Context, binderPath string) (*binder.Project, error) {
-	return ScanProjectImpl(ctx, binderPath)
+	return fsio.ScanProject(ctx, binderPath)
+}
+
+// ReadNodeFile reads the node file at path as stored; only its frontmatter
+// is read, so a locked body need not be unlocked.
+func (r *fileParseReader) ReadNodeFile(path string) ([]byte, error) {
+	return fsio.ReadFile(path)
 }
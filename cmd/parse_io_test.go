@@ -111,3 +111,20 @@ func TestFileParseReader_ScanProject(t *testing.T) {
 		t.Errorf("project.Files = %v, want [ch1.md]", proj.Files)
 	}
 }
+
+func TestFileParseReader_ReadNodeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ch1.md")
+	content := []byte("---\ntitle: Chapter One\n---\nBody.\n")
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r := newDefaultParseReader()
+	got, err := r.ReadNodeFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("got %q, want %q", got, content)
+	}
+}
@@ -5,10 +5,15 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 
 	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
 )
 
 // mockParseReader is a test double for ParseReader.
@@ -112,8 +117,29 @@ func TestNewParseCmd_OutputsJSONOnSuccess(t *testing.T) {
 	if _, ok := result["root"]; !ok {
 		t.Error("expected \"root\" field in JSON output")
 	}
-	if _, ok := result["diagnostics"]; !ok {
-		t.Error("expected \"diagnostics\" field in JSON output")
+	if diags, ok := result["diagnostics"].([]interface{}); !ok || len(diags) != 0 {
+		t.Errorf("diagnostics = %v, want an empty array", result["diagnostics"])
+	}
+}
+
+func TestNewParseCmd_OutputsFences(t *testing.T) {
+	reader := &mockParseReader{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n```\n- [Example](ex.md)\n```\n")}
+	c := NewParseCmd(reader)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetArgs([]string{"--project", "."})
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got parseOutput
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\noutput: %s", err, out.String())
+	}
+	want := binder.Fence{Marker: "```", StartLine: 2, EndLine: 4, Closed: true}
+	if len(got.Fences) != 1 || *got.Fences[0] != want {
+		t.Errorf("fences = %+v, want [%+v]", got.Fences, want)
 	}
 }
 
@@ -223,6 +249,31 @@ func TestNewParseCmd_ReturnsOPE009OnInvalidUTF8(t *testing.T) {
 	}
 }
 
+func TestNewParseCmd_RepairEncoding(t *testing.T) {
+	reader := &mockParseReader{
+		binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [Ch\xff](ch.md)\n"),
+	}
+	c := NewParseCmd(reader)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--project", ".", "--repair-encoding"})
+
+	if err := c.Execute(); err == nil {
+		t.Error("expected non-zero exit for a repaired binder")
+	}
+	var result parseOutput
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("output is not valid JSON: %v\noutput: %s", err, out.String())
+	}
+	if len(result.Root.Children) != 1 || result.Root.Children[0].Title != "Ch\uFFFD" {
+		t.Errorf("root = %+v, want the repaired node", result.Root)
+	}
+	if len(result.Diagnostics) == 0 || result.Diagnostics[0].Code != "PMKE008" {
+		t.Errorf("diagnostics = %+v, want PMKE008 first", result.Diagnostics)
+	}
+}
+
 // TestNewParseCmd_ParseErrorPropagatedInReturnedError verifies that when
 // binder.Parse returns a non-nil error the error surfaced to the caller
 // (returned by Execute) includes the underlying parse failure message, not
@@ -259,3 +310,547 @@ func TestNewParseCmd_ParseErrorPropagatedInReturnedError(t *testing.T) {
 		})
 	}
 }
+
+// mockWorkspaceReader is a ParseReader keyed by binder path, for --workspace.
+type mockWorkspaceReader struct {
+	binders    map[string][]byte // keyed by slash-separated path
+	altBinders []string
+	readErrs   map[string]error // keyed by slash-separated path
+	scanErrs   map[string]error // keyed by slash-separated path
+}
+
+func (m *mockWorkspaceReader) ReadBinder(_ context.Context, path string) ([]byte, error) {
+	if err := m.readErrs[filepath.ToSlash(path)]; err != nil {
+		return nil, err
+	}
+	if b, ok := m.binders[filepath.ToSlash(path)]; ok {
+		return b, nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func (m *mockWorkspaceReader) ScanProject(_ context.Context, binderPath string) (*binder.Project, error) {
+	proj := &binder.Project{Files: []string{}, BinderDir: "."}
+	if filepath.ToSlash(binderPath) == "ws/_binder.md" {
+		proj.AltBinders = m.altBinders
+	}
+	return proj, m.scanErrs[filepath.ToSlash(binderPath)]
+}
+
+func TestNewParseCmd_Workspace(t *testing.T) {
+	tests := []struct {
+		name      string
+		binders   map[string][]byte
+		wantPaths []string
+		wantErr   string
+	}{
+		{
+			name: "root and nested binders",
+			binders: map[string][]byte{
+				"ws/_binder.md":        []byte("<!-- prosemark-binder:v1 -->\n- [A](a.md)\n"),
+				"ws/book2/_binder.md":  []byte("- [B](b.md)\n"),
+				"ws/series/_binder.md": []byte("<!-- prosemark-binder:v1 -->\n- [Bad](../../out.md)\n"),
+			},
+			wantPaths: []string{"_binder.md", "book2/_binder.md", "series/_binder.md"},
+			wantErr:   "workspace has parse errors",
+		},
+		{
+			name: "no root binder",
+			binders: map[string][]byte{
+				"ws/book2/_binder.md": []byte("<!-- prosemark-binder:v1 -->\n- [B](b.md)\n"),
+			},
+			wantPaths: []string{"book2/_binder.md"},
+		},
+		{
+			name: "clean and invalid UTF-8 binders",
+			binders: map[string][]byte{
+				"ws/_binder.md":     []byte("<!-- prosemark-binder:v1 -->\n"),
+				"ws/bad/_binder.md": []byte("- [B](b\xff.md)\n"),
+			},
+			wantPaths: []string{"_binder.md", "bad/_binder.md"},
+			wantErr:   "workspace has parse errors",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var alts []string
+			for p := range tt.binders {
+				if p != "ws/_binder.md" {
+					alts = append(alts, strings.TrimPrefix(p, "ws/"))
+				}
+			}
+			sort.Strings(alts)
+			reader := &mockWorkspaceReader{binders: tt.binders, altBinders: alts}
+
+			c := NewParseCmd(reader)
+			out := new(bytes.Buffer)
+			c.SetOut(out)
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs([]string{"--project", "ws", "--workspace"})
+			err := c.Execute()
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+
+			var got workspaceParseOutput
+			if jerr := json.Unmarshal(out.Bytes(), &got); jerr != nil {
+				t.Fatalf("invalid JSON: %v\n%s", jerr, out.String())
+			}
+			var paths []string
+			for _, b := range got.Binders {
+				paths = append(paths, b.Path)
+			}
+			if strings.Join(paths, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Errorf("binders = %v, want %v", paths, tt.wantPaths)
+			}
+			for _, d := range got.Diagnostics {
+				if !strings.Contains(d.Message, "_binder.md: ") {
+					t.Errorf("diagnostic %q not prefixed with binder path", d.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestNewParseCmd_Workspace_Errors(t *testing.T) {
+	boom := errors.New("boom")
+	binders := map[string][]byte{
+		"ws/_binder.md":       []byte("<!-- prosemark-binder:v1 -->\n"),
+		"ws/book2/_binder.md": []byte("<!-- prosemark-binder:v1 -->\n"),
+	}
+	tests := []struct {
+		name     string
+		readErrs map[string]error
+		scanErrs map[string]error
+		stdout   io.Writer
+		wantErr  string
+	}{
+		{name: "workspace scan error", scanErrs: map[string]error{"ws/_binder.md": boom}, wantErr: "scanning project: boom"},
+		{name: "root binder unreadable", readErrs: map[string]error{"ws/_binder.md": boom}, wantErr: "reading binder: boom"},
+		{name: "nested binder unreadable", readErrs: map[string]error{"ws/book2/_binder.md": boom}, wantErr: "reading binder book2/_binder.md"},
+		{name: "nested scan error", scanErrs: map[string]error{"ws/book2/_binder.md": boom}, wantErr: "scanning project for book2/_binder.md"},
+		{name: "output error", stdout: &errWriter{err: boom}, wantErr: "encoding output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &mockWorkspaceReader{
+				binders:    binders,
+				altBinders: []string{"book2/_binder.md"},
+				readErrs:   tt.readErrs,
+				scanErrs:   tt.scanErrs,
+			}
+			c := NewParseCmd(reader)
+			if tt.stdout != nil {
+				c.SetOut(tt.stdout)
+			} else {
+				c.SetOut(new(bytes.Buffer))
+			}
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs([]string{"--project", "ws", "--workspace"})
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewParseCmd_Workspace_NoBinders(t *testing.T) {
+	c := NewParseCmd(&mockWorkspaceReader{})
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--project", "ws", "--workspace"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "no binders found") {
+		t.Errorf("error = %v, want no binders found", err)
+	}
+}
+
+func TestNewParseCmd_PositionalBinderPath(t *testing.T) {
+	cwd := func() (string, error) { return "/work", nil }
+	tests := []struct {
+		name     string
+		args     []string
+		wantWarn bool
+	}{
+		{"positional only", []string{"book/_binder.md"}, false},
+		{"agreeing relative project", []string{"book/_binder.md", "--project", "book/"}, false},
+		{"agreeing absolute project", []string{"book/_binder.md", "--project", "/work/book"}, false},
+		{"conflicting project", []string{"book/_binder.md", "--project", "other"}, true},
+		{"conflicting absolute paths", []string{"/work/book/_binder.md", "--project", "/elsewhere"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &mockWorkspaceReader{binders: map[string][]byte{
+				"book/_binder.md":       []byte("<!-- prosemark-binder:v1 -->\n"),
+				"/work/book/_binder.md": []byte("<!-- prosemark-binder:v1 -->\n"),
+			}}
+			c := newParseCmdWithGetCWD(reader, cwd)
+			out := new(bytes.Buffer)
+			c.SetOut(out)
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(tt.args)
+			if err := c.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got parseOutput
+			if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			warned := len(got.Diagnostics) == 1 && got.Diagnostics[0].Code == CodeConflictingProjectRoot &&
+				got.Diagnostics[0].Severity == "warning"
+			if warned != tt.wantWarn {
+				t.Errorf("diagnostics = %+v, want PMKW001 warning: %v", got.Diagnostics, tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestNewParseCmd_PositionalBinderPath_Workspace(t *testing.T) {
+	reader := &mockWorkspaceReader{binders: map[string][]byte{"ws/_binder.md": []byte("<!-- prosemark-binder:v1 -->\n")}}
+	c := NewParseCmd(reader)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"ws/_binder.md", "--project", "/elsewhere", "--workspace"})
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got workspaceParseOutput
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(got.Diagnostics) != 1 || got.Diagnostics[0].Code != CodeConflictingProjectRoot {
+		t.Errorf("diagnostics = %+v, want one PMKW001", got.Diagnostics)
+	}
+}
+
+func TestNewParseCmd_PositionalBinderPath_Errors(t *testing.T) {
+	failCWD := func() (string, error) { return "", errors.New("getwd failed") }
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"empty path", []string{""}, "binder path cannot be empty"},
+		{"empty project", []string{"/b/_binder.md", "--project", ""}, "--project flag cannot be empty"},
+		{"relative binder needs cwd", []string{"b/_binder.md", "--project", "/b"}, "getwd failed"},
+		{"relative project needs cwd", []string{"/b/_binder.md", "--project", "b"}, "getwd failed"},
+		{"too many args", []string{"a.md", "b.md"}, "accepts at most 1 arg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newParseCmdWithGetCWD(&mockWorkspaceReader{}, failCWD)
+			c.SetOut(new(bytes.Buffer))
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(tt.args)
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewParseCmd_DirectoryArgument(t *testing.T) {
+	dir := t.TempDir()
+	book := filepath.Join(dir, "book")
+	if err := os.MkdirAll(filepath.Join(book, "part"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	binderFile := filepath.Join(book, "_binder.md")
+	if err := os.WriteFile(binderFile, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cwd := func() (string, error) { return dir, nil }
+	for _, arg := range []string{book, filepath.Join(book, "part")} {
+		t.Run(filepath.Base(arg), func(t *testing.T) {
+			reader := &mockWorkspaceReader{binders: map[string][]byte{filepath.ToSlash(binderFile): []byte("<!-- prosemark-binder:v1 -->\n- [A]()\n")}}
+			c := newParseCmdWithGetCWD(reader, cwd)
+			out := new(bytes.Buffer)
+			c.SetOut(out)
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs([]string{arg, "--project", book})
+			if err := c.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got parseOutput
+			if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if len(got.Root.Children) != 1 || len(got.Diagnostics) != 0 {
+				t.Errorf("root = %+v, diagnostics = %+v", got.Root, got.Diagnostics)
+			}
+		})
+	}
+
+	t.Run("workspace", func(t *testing.T) {
+		part := filepath.Join(book, "part")
+		reader := &mockWorkspaceReader{binders: map[string][]byte{
+			filepath.ToSlash(binderFile):                        []byte("<!-- prosemark-binder:v1 -->\n- [A]()\n"),
+			filepath.ToSlash(filepath.Join(part, "_binder.md")): []byte("<!-- prosemark-binder:v1 -->\n- [B]()\n- [C]()\n"),
+		}}
+		c := newParseCmdWithGetCWD(reader, cwd)
+		out := new(bytes.Buffer)
+		c.SetOut(out)
+		c.SetErr(new(bytes.Buffer))
+		c.SetArgs([]string{part, "--workspace"})
+		if err := c.Execute(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got workspaceParseOutput
+		if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if len(got.Binders) != 1 || len(got.Binders[0].Root.Children) != 2 {
+			t.Errorf("binders = %+v, want only the binder in %s", got.Binders, part)
+		}
+
+		delete(reader.binders, filepath.ToSlash(filepath.Join(part, "_binder.md")))
+		c = newParseCmdWithGetCWD(reader, cwd)
+		c.SetOut(new(bytes.Buffer))
+		c.SetErr(new(bytes.Buffer))
+		c.SetArgs([]string{part, "--workspace"})
+		if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "no binders found under") {
+			t.Errorf("error = %v, want no binders found instead of the ancestor's", err)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		bad := filepath.Join(dir, "bad")
+		if err := os.MkdirAll(bad, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(bad, ".prosemark.yml"), []byte("binder: [\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		failCWD := func() (string, error) { return "", errors.New("getwd failed") }
+		for arg, wantErr := range map[string]string{".": "getwd failed", bad: "parse project settings"} {
+			c := newParseCmdWithGetCWD(&mockWorkspaceReader{}, failCWD)
+			c.SetOut(new(bytes.Buffer))
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs([]string{arg, "--workspace"})
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), wantErr) {
+				t.Errorf("%s --workspace: error = %v, want to contain %q", arg, err, wantErr)
+			}
+		}
+		for arg, wantErr := range map[string]string{dir: "no _binder.md in", ".": "getwd failed"} {
+			c := newParseCmdWithGetCWD(&mockWorkspaceReader{}, failCWD)
+			c.SetOut(new(bytes.Buffer))
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs([]string{arg})
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), wantErr) {
+				t.Errorf("%s: error = %v, want to contain %q", arg, err, wantErr)
+			}
+		}
+	})
+}
+
+func TestNewParseCmd_DiscoversProject(t *testing.T) {
+	dir := t.TempDir()
+	part := filepath.Join(dir, "part")
+	if err := os.MkdirAll(part, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"_binder.md":   "<!-- prosemark-binder:v1 -->\n- [A](a.md)\n- [Missing](missing.md)\n",
+		"A.md":         "",
+		"part/note.md": "",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, wd := range []string{dir, part} {
+		t.Run(filepath.Base(wd), func(t *testing.T) {
+			c := newParseCmdWithGetCWD(newDefaultParseReader(), func() (string, error) { return wd, nil })
+			out := new(bytes.Buffer)
+			c.SetOut(out)
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(nil)
+			if err := c.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got parseOutput
+			if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			codes := map[string]bool{}
+			for _, d := range got.Diagnostics {
+				codes[d.Code] = true
+			}
+			if !codes["BNDW004"] || !codes["BNDW009"] {
+				t.Errorf("diagnostics = %+v, want BNDW004 and BNDW009", got.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestNewParseCmd_FormatGitHub(t *testing.T) {
+	reader := &mockParseReader{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [A](a.md)\n- [B](b:c.md)\n")}
+	c := newParseCmdWithGetCWD(reader, func() (string, error) { return "/repo", nil })
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--project", "/repo/book", "--format", "github"})
+
+	err := c.Execute()
+	if err == nil || !strings.Contains(err.Error(), "binder has parse errors") {
+		t.Fatalf("error = %v, want parse errors", err)
+	}
+	want := "::warning file=book/_binder.md,line=2,title=BNDW004::Target file a.md is not present in the project\n" +
+		"::error file=book/_binder.md,line=3,col=3,title=BNDE001::Illegal path characters in link target: b:c.md\n"
+	if out.String() != want {
+		t.Errorf("stdout = %q, want %q", out.String(), want)
+	}
+}
+
+func TestNewParseCmd_FormatGitHub_Workspace(t *testing.T) {
+	reader := &mockWorkspaceReader{
+		binders: map[string][]byte{
+			"ws/_binder.md":       []byte("<!-- prosemark-binder:v1 -->\n"),
+			"ws/book2/_binder.md": []byte("<!-- prosemark-binder:v1 -->\n- [B](b.md)\n"),
+		},
+		altBinders: []string{"book2/_binder.md"},
+	}
+	c := newParseCmdWithGetCWD(reader, func() (string, error) { return "/repo", nil })
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"ws/_binder.md", "--project", "other", "--workspace", "--format", "github"})
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "::warning title=PMKW001::"
+	if !strings.HasPrefix(out.String(), want) {
+		t.Errorf("stdout = %q, want prefix %q", out.String(), want)
+	}
+	want = "::warning file=ws/book2/_binder.md,line=2,title=BNDW004::Target file b.md is not present in the project\n"
+	if !strings.HasSuffix(out.String(), want) {
+		t.Errorf("stdout = %q, want suffix %q", out.String(), want)
+	}
+}
+
+func TestNewParseCmd_FormatErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		out     io.Writer
+		wantErr string
+	}{
+		{"unknown format", []string{"--format", "sarif"}, new(bytes.Buffer), `unknown --format "sarif"`},
+		{"json conflicts", []string{"--json", "--format", "github"}, new(bytes.Buffer), "--json conflicts with --format github"},
+		{"write failure", []string{"--format", "github"}, &errWriter{errors.New("closed")}, "writing output"},
+		{"workspace write failure", []string{"--format", "github", "--workspace"}, &errWriter{errors.New("closed")}, "writing output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewParseCmd(&mockParseReader{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n")})
+			c.SetOut(tt.out)
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(append([]string{"--project", "."}, tt.args...))
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewParseCmd_FormatYAML(t *testing.T) {
+	c := NewParseCmd(&mockParseReader{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n")})
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--project", ".", "--json", "--format", "yaml"})
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "version: \"1\"\nroot:\n  type: root\n  children: []\n"; !strings.HasPrefix(out.String(), want) {
+		t.Errorf("output = %q, want prefix %q", out, want)
+	}
+}
+
+func TestNewParseCmd_JSONIncludesSuggestions(t *testing.T) {
+	reader := &mockParseReader{
+		binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [One](one.md)\n"),
+		project:     &binder.Project{Files: []string{"One.md"}, BinderDir: "."},
+	}
+	c := NewParseCmd(reader)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetArgs([]string{"--project", ".", "--json"})
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var res parseOutput
+	if err := json.Unmarshal(out.Bytes(), &res); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	if len(res.Diagnostics) != 1 || res.Diagnostics[0].Suggestion == nil {
+		t.Fatalf("diagnostics = %+v, want one BNDW009 with a suggestion", res.Diagnostics)
+	}
+	want := binder.Suggestion{Message: "replace one.md with One.md", Replacement: "One.md", Range: binder.Range{Start: 37, End: 43}}
+	if got := *res.Diagnostics[0].Suggestion; got != want {
+		t.Errorf("suggestion = %+v, want %+v", got, want)
+	}
+}
+
+// titleParseReader is a mockParseReader that also reads node files, by
+// base name, for --resolve-titles.
+type titleParseReader struct {
+	*mockParseReader
+	files map[string]string
+}
+
+func (r *titleParseReader) ReadNodeFile(path string) ([]byte, error) {
+	if content, ok := r.files[filepath.Base(path)]; ok {
+		return []byte(content), nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func TestParseCmd_ResolveTitles(t *testing.T) {
+	reader := &titleParseReader{
+		mockParseReader: &mockParseReader{
+			binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [Chapter One](ch1.md)\n- [Draft Two](ch2.md)\n"),
+			project:     &binder.Project{Files: []string{"ch1.md", "ch2.md"}, BinderDir: "."},
+		},
+		files: map[string]string{
+			"ch1.md": "---\ntitle: Chapter One\n---\n",
+			"ch2.md": "---\ntitle: Chapter Two\n---\n",
+		},
+	}
+	c := NewParseCmd(reader)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetArgs([]string{"--resolve-titles"})
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got parseOutput
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if kids := got.Root.Children; len(kids) != 2 || kids[0].FileTitle != "Chapter One" || kids[1].FileTitle != "Chapter Two" {
+		t.Errorf("children = %+v, want fileTitle from frontmatter", got.Root.Children)
+	}
+	if len(got.Diagnostics) != 1 || got.Diagnostics[0].Code != core.CodeTitleMismatch || got.Diagnostics[0].Location.Line != 3 {
+		t.Errorf("diagnostics = %+v, want one PMKW012 on line 3", got.Diagnostics)
+	}
+
+	c = NewParseCmd(reader.mockParseReader)
+	c.SetOut(new(bytes.Buffer))
+	c.SetArgs([]string{"--resolve-titles"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "node files cannot be read") {
+		t.Errorf("reader without node files: err = %v", err)
+	}
+
+	c = NewParseCmd(reader)
+	c.SetOut(new(bytes.Buffer))
+	c.SetArgs([]string{"--resolve-titles", "--workspace"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "conflicts with --workspace") {
+		t.Errorf("--workspace: err = %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// positionFlags are the insertion-point flags shared by add and move:
+// --position and the older single-purpose --first, --at, --before, and
+// --after.
+type positionFlags struct {
+	spec   string
+	first  bool
+	at     int
+	before string
+	after  string
+}
+
+// insertionPoint is the position part of binder.AddChildParams and
+// binder.MoveParams.
+type insertionPoint struct {
+	Position string // "first" | "last"
+	At       *int
+	Before   string
+	After    string
+}
+
+// addPositionFlags registers the position flags on cmd.
+func addPositionFlags(cmd *cobra.Command, p *positionFlags) {
+	cmd.Flags().StringVar(&p.spec, "position", "", "Insertion point: first, last, at:N, before:<selector>, or after:<selector> (default last)")
+	cmd.Flags().BoolVar(&p.first, "first", false, "Insert as first child (same as --position first)")
+	cmd.Flags().IntVar(&p.at, "at", 0, "Zero-based insertion index (same as --position at:N)")
+	cmd.Flags().StringVar(&p.before, "before", "", "Insert before selector (same as --position before:<selector>)")
+	cmd.Flags().StringVar(&p.after, "after", "", "Insert after selector (same as --position after:<selector>)")
+}
+
+// resolve returns the insertion point the flags select, defaulting to last.
+// Setting more than one of them is an OPE010 error.
+func (p *positionFlags) resolve(cmd *cobra.Command) (insertionPoint, error) {
+	var set []string
+	for _, name := range []string{"position", "first", "at", "before", "after"} {
+		if cmd.Flags().Changed(name) {
+			set = append(set, "--"+name)
+		}
+	}
+	if len(set) > 1 {
+		return insertionPoint{}, fmt.Errorf("only one of --position, --first, --at, --before, --after may be specified, got %s (%s)", strings.Join(set, " and "), binder.CodeConflictingFlags)
+	}
+
+	switch {
+	case cmd.Flags().Changed("position"):
+		return parsePosition(p.spec)
+	case p.first:
+		return insertionPoint{Position: "first"}, nil
+	case cmd.Flags().Changed("at"):
+		at := p.at
+		return insertionPoint{Position: "last", At: &at}, nil
+	}
+	return insertionPoint{Position: "last", Before: p.before, After: p.after}, nil
+}
+
+// parsePosition parses a --position value: first, last, at:N with N a
+// zero-based index, before:<selector>, or after:<selector>. These mirror
+// the op.json position field, with positionIndex or positionSelector folded
+// in.
+func parsePosition(spec string) (insertionPoint, error) {
+	kind, arg, hasArg := strings.Cut(spec, ":")
+	switch {
+	case (kind == "first" || kind == "last") && !hasArg:
+		return insertionPoint{Position: kind}, nil
+	case kind == "at" && hasArg:
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			return insertionPoint{}, fmt.Errorf("invalid --position %q: at needs a non-negative index", spec)
+		}
+		return insertionPoint{Position: "last", At: &n}, nil
+	case (kind == "before" || kind == "after") && hasArg:
+		if arg == "" {
+			return insertionPoint{}, fmt.Errorf("invalid --position %q: %s needs a selector", spec, kind)
+		}
+		if kind == "before" {
+			return insertionPoint{Position: "last", Before: arg}, nil
+		}
+		return insertionPoint{Position: "last", After: arg}, nil
+	}
+	return insertionPoint{}, fmt.Errorf("invalid --position %q: want first, last, at:N, before:<selector>, or after:<selector>", spec)
+}
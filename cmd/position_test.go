@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestParsePosition(t *testing.T) {
+	three := 3
+	tests := []struct {
+		spec    string
+		want    insertionPoint
+		wantErr string
+	}{
+		{spec: "first", want: insertionPoint{Position: "first"}},
+		{spec: "last", want: insertionPoint{Position: "last"}},
+		{spec: "at:3", want: insertionPoint{Position: "last", At: &three}},
+		{spec: "before:ch1", want: insertionPoint{Position: "last", Before: "ch1"}},
+		{spec: "after:part:ch1", want: insertionPoint{Position: "last", After: "part:ch1"}},
+		{spec: "at:-1", wantErr: "non-negative index"},
+		{spec: "at:x", wantErr: "non-negative index"},
+		{spec: "before:", wantErr: "needs a selector"},
+		{spec: "first:1", wantErr: "want first, last"},
+		{spec: "nth", wantErr: "want first, last"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := parsePosition(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Position != tt.want.Position || got.Before != tt.want.Before || got.After != tt.want.After ||
+				(got.At == nil) != (tt.want.At == nil) || (got.At != nil && *got.At != *tt.want.At) {
+				t.Errorf("parsePosition(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMoveCmd_PositionFlag(t *testing.T) {
+	mock := &mockMoveIO{
+		binderBytes: moveBinder(),
+		project:     &binder.Project{Files: []string{"chapter-one.md", "chapter-two.md"}, BinderDir: "."},
+	}
+	c := NewMoveCmd(mock)
+	c.SetOut(new(bytes.Buffer))
+	c.SetArgs([]string{"--source", "chapter-two.md", "--dest", ".", "--position", "before:chapter-one.md", "--yes", "--project", "."})
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "<!-- prosemark-binder:v1 -->\n- [Chapter Two](chapter-two.md)\n- [Chapter One](chapter-one.md)\n"
+	if string(mock.writtenBytes) != want {
+		t.Errorf("binder = %q, want %q", mock.writtenBytes, want)
+	}
+}
+
+func TestNewMoveCmd_PositionConflictsWithLegacyFlags(t *testing.T) {
+	c := NewMoveCmd(&mockMoveIO{binderBytes: moveBinder()})
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--source", "chapter-two.md", "--dest", ".", "--position", "first", "--at", "0", "--yes", "--project", "."})
+
+	err := c.Execute()
+	if err == nil || !strings.Contains(err.Error(), binder.CodeConflictingFlags) || !strings.Contains(err.Error(), "--position and --at") {
+		t.Errorf("err = %v, want %s naming --position and --at", err, binder.CodeConflictingFlags)
+	}
+}
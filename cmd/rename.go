@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+// RenameIO handles I/O for the rename command.
+type RenameIO interface {
+	core.RenameIO
+}
+
+// NewRenameCmd creates the rename subcommand.
+func NewRenameCmd(io RenameIO) *cobra.Command {
+	return newRenameCmdWithGetCWD(io, os.Getwd)
+}
+
+func newRenameCmdWithGetCWD(io RenameIO, getwd func() (string, error)) *cobra.Command {
+	var jsonMode, forceParse, dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "rename <selector> <new-target>",
+		Short: "Rename a node file and update the binder to match",
+		Long: "Move the node file of the selected entry to new-target and rewrite every\n" +
+			"binder reference to it: inline links, wikilinks, and reference definitions.\n" +
+			"A frontmatter id matching the old filename follows the new one, and the\n" +
+			"node's notes file is renamed with it. Any failure undoes the whole rename.",
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+
+			if replayed, err := replayRecordedOp(cmd, io, binderPath, jsonMode); replayed || err != nil {
+				return err
+			}
+
+			params := binder.RenameParams{Selector: args[0], NewTarget: args[1], ForceParse: forceParse, DryRun: dryRun}
+			res, err := core.Rename(cmd.Context(), io, binderPath, params)
+			var opRes *binder.OpResult
+			if res != nil {
+				opRes = &res.OpResult
+			}
+			if err := finishBinderOp(cmd, io, binderPath, jsonMode, opRes, err); err != nil {
+				return err
+			}
+
+			if !jsonMode && !dryRun {
+				msg := "Nothing to rename: " + sanitizePath(args[0]) + " already has that name"
+				if res.Changed {
+					msg = "Renamed " + sanitizePath(res.OldPath) + " to " + sanitizePath(res.NewPath)
+				}
+				if _, err := fmt.Fprintln(cmd.OutOrStdout(), msg); err != nil {
+					return fmt.Errorf("writing output: %w", err)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+	addForceParseFlag(cmd, &forceParse)
+	addDryRunFlag(cmd, &dryRun)
+	addDiffFlag(cmd)
+	addOpIDFlag(cmd)
+	return cmd
+}
+
+// fileRenameIO implements RenameIO using OS file I/O. Node files are moved
+// as stored, so locked bodies stay locked.
+type fileRenameIO struct {
+	fileProjectIO
+	binderLocker
+	opJournaler
+	fileTxer
+}
+
+// ReadNodeFile reads the node file at path as stored.
+func (f fileRenameIO) ReadNodeFile(path string) ([]byte, error) {
+	return fsio.ReadFile(path)
+}
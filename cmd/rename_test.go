@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+)
+
+// mockRenameIO is a test double for RenameIO backed by an in-memory file map.
+type mockRenameIO struct {
+	mockAddChildIO
+	files map[string][]byte
+}
+
+func (m *mockRenameIO) BeginTx(string) (core.FileTx, error) {
+	return beginMemTx(m)
+}
+
+func newRenameTestIO() *mockRenameIO {
+	return &mockRenameIO{
+		mockAddChildIO: mockAddChildIO{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [Chapter One](ch1.md)\n- [Interlude]()\n")},
+		files:          map[string][]byte{"ch1.md": []byte("---\nid: ch1\n---\nBody.\n")},
+	}
+}
+
+func (m *mockRenameIO) ReadNodeFile(path string) ([]byte, error) {
+	content, ok := m.files[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return content, nil
+}
+
+func (m *mockRenameIO) WriteNodeFileAtomic(path string, content []byte) error {
+	m.files[path] = content
+	return nil
+}
+
+func (m *mockRenameIO) DeleteFile(path string) error {
+	delete(m.files, path)
+	return nil
+}
+
+func runRenameCmd(t *testing.T, mock *mockRenameIO, args ...string) (string, error) {
+	t.Helper()
+	c := NewRenameCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(append(args, "--project", "."))
+	err := c.Execute()
+	return out.String(), err
+}
+
+func TestRename_MovesFileAndUpdatesBinder(t *testing.T) {
+	mock := newRenameTestIO()
+	out, err := runRenameCmd(t, mock, "ch1", "chapter-one.md")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(mock.writtenBytes), "- [Chapter One](chapter-one.md)\n") {
+		t.Errorf("binder = %q", mock.writtenBytes)
+	}
+	if got := string(mock.files["chapter-one.md"]); got != "---\nid: chapter-one\n---\nBody.\n" || len(mock.files) != 1 {
+		t.Errorf("files = %q", mock.files)
+	}
+	if out != "Renamed ch1.md to chapter-one.md\n" {
+		t.Errorf("stdout = %q", out)
+	}
+}
+
+func TestRename_JSON(t *testing.T) {
+	out, err := runRenameCmd(t, newRenameTestIO(), "Chapter One", "chapter-one.md", "--json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result binder.OpResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if !result.Changed || len(result.Entries) != 1 || result.Entries[0].Target != "chapter-one.md" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestRename_SameName(t *testing.T) {
+	mock := newRenameTestIO()
+	out, err := runRenameCmd(t, mock, "ch1", "./ch1.md")
+	if err != nil || out != "Nothing to rename: ch1 already has that name\n" || mock.writtenBytes != nil {
+		t.Errorf("out = %q, err = %v, binder = %q", out, err, mock.writtenBytes)
+	}
+}
+
+func TestRename_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    *mockRenameIO
+		args    []string
+		wantErr string
+	}{
+		{"placeholder", newRenameTestIO(), []string{"Interlude", "new.md"}, "rename has errors"},
+		{"read binder", &mockRenameIO{mockAddChildIO: mockAddChildIO{binderErr: errors.New("boom")}}, []string{"ch1", "new.md"}, "reading binder"},
+		{"node file missing", &mockRenameIO{mockAddChildIO: newRenameTestIO().mockAddChildIO}, []string{"ch1", "new.md"}, "reading node file"},
+		{"missing new target", newRenameTestIO(), []string{"ch1"}, "accepts 2 arg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runRenameCmd(t, tt.mock, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+			if tt.mock.writtenBytes != nil {
+				t.Errorf("binder written on error: %q", tt.mock.writtenBytes)
+			}
+		})
+	}
+}
+
+func TestRename_OutputError(t *testing.T) {
+	c := NewRenameCmd(newRenameTestIO())
+	c.SetOut(&errWriter{err: errors.New("closed")})
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"ch1", "new.md", "--project", "."})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "writing output") {
+		t.Errorf("error = %v, want output error", err)
+	}
+}
+
+func TestRename_GetwdError(t *testing.T) {
+	c := newRenameCmdWithGetCWD(newRenameTestIO(), func() (string, error) {
+		return "", errors.New("getwd failed")
+	})
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"ch1", "new.md"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+		t.Errorf("error = %v, want getwd failure", err)
+	}
+}
+
+func TestRename_InvalidOpID(t *testing.T) {
+	c := NewRenameCmd(newRenameTestIO())
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"ch1", "new.md", "--project", ".", "--op-id", "not-a-uuid"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "invalid --op-id") {
+		t.Errorf("error = %v, want invalid --op-id", err)
+	}
+}
+
+func TestFileRenameIO_RenamesProject(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"_binder.md":     "<!-- prosemark-binder:v1 -->\n- [[ch1|Chapter One]]\n",
+		"ch1.md":         "---\nid: ch1\n---\nBody.\n",
+		"ch1.notes.md":   "Notes.\n",
+		".prosemark.yml": "",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := NewRenameCmd(fileRenameIO{})
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"ch1", "drafts/one.md", "--project", dir})
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fio := fileRenameIO{}
+	if got, _ := fio.ReadBinder(context.Background(), filepath.Join(dir, "_binder.md")); string(got) != "<!-- prosemark-binder:v1 -->\n- [[one|Chapter One]]\n" {
+		t.Errorf("binder = %q", got)
+	}
+	if got, err := fio.ReadNodeFile(filepath.Join(dir, "drafts", "one.md")); err != nil || string(got) != "---\nid: one\n---\nBody.\n" {
+		t.Errorf("node file = %q, %v", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "drafts", "one.notes.md")); err != nil {
+		t.Errorf("notes file not moved: %v", err)
+	}
+	for _, old := range []string{"ch1.md", "ch1.notes.md"} {
+		if _, err := os.Stat(filepath.Join(dir, old)); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s still exists: %v", old, err)
+		}
+	}
+	assertNoTxLeftovers(t, dir)
+}
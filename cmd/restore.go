@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+)
+
+// RestoreIO handles I/O for the restore command.
+type RestoreIO interface {
+	core.DeleteFilesIO
+}
+
+// NewRestoreCmd creates the restore subcommand.
+func NewRestoreCmd(io RestoreIO) *cobra.Command {
+	return newRestoreCmdWithGetCWD(io, os.Getwd)
+}
+
+func newRestoreCmdWithGetCWD(io RestoreIO, getwd func() (string, error)) *cobra.Command {
+	var (
+		jsonMode bool
+		dryRun   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "restore [id]",
+		Short: "Bring a deletion back out of the trash",
+		Long: "Undo a pmk delete --archive (or --cascade trash): move the deletion's\n" +
+			"files back from the project's trash and re-add its entries to the binder\n" +
+			"under their old parent, at their old position. An entry whose parent has\n" +
+			"since been deleted goes back at the top level (PMKW006). Without an id,\n" +
+			"list the deletions in the trash, oldest first.",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				return listTrash(cmd, io, binderPath, jsonMode)
+			}
+
+			if replayed, err := replayRecordedOp(cmd, io, binderPath, jsonMode); replayed || err != nil {
+				return err
+			}
+			rres, err := core.Restore(cmd.Context(), io, binderPath, args[0], dryRun)
+			var res *binder.OpResult
+			var files []string
+			if rres != nil {
+				res, files = &rres.OpResult, rres.Files
+			}
+			if err := finishBinderOp(cmd, io, binderPath, jsonMode, res, err); err != nil {
+				return err
+			}
+			if jsonMode {
+				return nil
+			}
+
+			var b strings.Builder
+			for _, f := range files {
+				if dryRun {
+					b.WriteString("Would restore " + sanitizePath(f) + "\n")
+				} else {
+					b.WriteString("Restored " + sanitizePath(f) + "\n")
+				}
+			}
+			if !dryRun {
+				b.WriteString("Restored " + sanitizePath(args[0]) + " to " + sanitizePath(binderPath) + "\n")
+			}
+			if _, err := fmt.Fprint(cmd.OutOrStdout(), b.String()); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+	addDryRunFlag(cmd, &dryRun)
+	addDiffFlag(cmd)
+	addOpIDFlag(cmd)
+
+	return cmd
+}
+
+// listTrash writes the deletions in the trash: one line each with its ID,
+// time, selector, and file count, or the manifest under --json.
+func listTrash(cmd *cobra.Command, io RestoreIO, binderPath string, jsonMode bool) error {
+	manifest, err := core.ReadTrash(io, binderPath)
+	if err != nil {
+		return err
+	}
+	if jsonMode {
+		if err := encodeOutput(cmd, manifest); err != nil {
+			return fmt.Errorf("encoding output: %w", err)
+		}
+		return nil
+	}
+
+	var b strings.Builder
+	if len(manifest.Records) == 0 {
+		b.WriteString("The trash is empty\n")
+	}
+	for _, r := range manifest.Records {
+		fmt.Fprintf(&b, "%s  %s  %s  (%d files)\n", sanitizePath(r.ID), sanitizePath(r.Deleted), sanitizePath(r.Selector), len(r.Files))
+	}
+	if _, err := fmt.Fprint(cmd.OutOrStdout(), b.String()); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	return nil
+}
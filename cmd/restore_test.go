@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func runCmdInCWD(c *cobra.Command, args ...string) (string, error) {
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(args)
+	err := c.Execute()
+	return out.String(), err
+}
+
+// trashedDeleteIO returns a mockDeleteIO whose chapter-one was deleted with
+// --archive as 20260301T120000Z.
+func trashedDeleteIO(t *testing.T) *mockDeleteIO {
+	t.Helper()
+	stubDeleteNow(t)
+	mock := &mockDeleteIO{
+		binderBytes: delBinder(),
+		project:     &binder.Project{Files: []string{"chapter-one.md"}, BinderDir: "."},
+		files:       map[string][]byte{"chapter-one.md": []byte("One.\n")},
+	}
+	c := newDeleteCmdWithGetCWD(mock, func() (string, error) { return ".", nil })
+	if _, err := runCmdInCWD(c, "--selector", "chapter-one", "--yes", "--archive"); err != nil {
+		t.Fatalf("deleting: %v", err)
+	}
+	mock.binderBytes = mock.writtenBytes
+	mock.project = &binder.Project{Files: []string{}, BinderDir: "."}
+	return mock
+}
+
+func TestNewRestoreCmd_Lists(t *testing.T) {
+	empty := newRestoreCmdWithGetCWD(&mockDeleteIO{binderBytes: delBinder()}, func() (string, error) { return ".", nil })
+	if out, err := runCmdInCWD(empty); err != nil || out != "The trash is empty\n" {
+		t.Errorf("empty trash: output = %q, err = %v", out, err)
+	}
+
+	mock := trashedDeleteIO(t)
+	c := newRestoreCmdWithGetCWD(mock, func() (string, error) { return ".", nil })
+	out, err := runCmdInCWD(c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "20260301T120000Z  2026-03-01T12:00:00Z  chapter-one  (1 files)\n"; out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestNewRestoreCmd_Restores(t *testing.T) {
+	mock := trashedDeleteIO(t)
+	c := newRestoreCmdWithGetCWD(mock, func() (string, error) { return ".", nil })
+	out, err := runCmdInCWD(c, "20260301T120000Z")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "Restored chapter-one.md\nRestored 20260301T120000Z to _binder.md\n"; out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+	if !strings.HasSuffix(string(mock.writtenBytes), "\n- [Chapter One](chapter-one.md)\n") {
+		t.Errorf("binder = %q, want chapter-one back", mock.writtenBytes)
+	}
+	if string(mock.files["chapter-one.md"]) != "One.\n" {
+		t.Errorf("chapter-one.md = %q, want it back", mock.files["chapter-one.md"])
+	}
+}
+
+func TestNewRestoreCmd_DryRunAndJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantOut string
+	}{
+		{"dry run", []string{"20260301T120000Z", "--dry-run"}, "Would restore chapter-one.md\n"},
+		{"json", []string{"20260301T120000Z", "--json"}, `"changed":true`},
+		{"json list", []string{"--json"}, `"id":"20260301T120000Z"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newRestoreCmdWithGetCWD(trashedDeleteIO(t), func() (string, error) { return ".", nil })
+			out, err := runCmdInCWD(c, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(out, tt.wantOut) {
+				t.Errorf("output = %q, want it to contain %q", out, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestNewRestoreCmd_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		getwd   func() (string, error)
+		mock    func(t *testing.T) *mockDeleteIO
+		out     io.Writer
+		args    []string
+		wantErr string
+	}{
+		{"getwd fails", func() (string, error) { return "", errors.New("no cwd") }, trashedDeleteIO, nil, nil, "no cwd"},
+		{"invalid op id", nil, trashedDeleteIO, nil, []string{"20260301T120000Z", "--op-id", "not-a-uuid"}, "invalid --op-id"},
+		{"manifest unreadable", nil, func(t *testing.T) *mockDeleteIO {
+			mock := trashedDeleteIO(t)
+			mock.files[".prosemark/trash/manifest.json"] = []byte("{")
+			return mock
+		}, nil, nil, "reading .prosemark/trash/manifest.json"},
+		{"list output", nil, trashedDeleteIO, &errWriter{err: errors.New("closed")}, nil, "writing output"},
+		{"json list output", nil, trashedDeleteIO, &errWriter{err: errors.New("closed")}, []string{"--json"}, "encoding output"},
+		{"restore output", nil, trashedDeleteIO, &errWriter{err: errors.New("closed")}, []string{"20260301T120000Z"}, "writing output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getwd := tt.getwd
+			if getwd == nil {
+				getwd = func() (string, error) { return ".", nil }
+			}
+			c := newRestoreCmdWithGetCWD(tt.mock(t), getwd)
+			if tt.out == nil {
+				tt.out = new(bytes.Buffer)
+			}
+			c.SetOut(tt.out)
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(tt.args)
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewRestoreCmd_UnknownID(t *testing.T) {
+	mock := trashedDeleteIO(t)
+	c := newRestoreCmdWithGetCWD(mock, func() (string, error) { return ".", nil })
+	if _, err := runCmdInCWD(c, "nope"); err == nil || !strings.Contains(err.Error(), `no deletion "nope" in the trash`) {
+		t.Errorf("error = %v, want an unknown deletion error", err)
+	}
+}
+
+func TestNewRootCmd_RegistersRestoreSubcommand(t *testing.T) {
+	if c, _, err := NewRootCmd().Find([]string{"restore"}); err != nil || c.Name() != "restore" {
+		t.Errorf("restore subcommand not registered: %v", err)
+	}
+}
@@ -2,32 +2,19 @@
 package cmd
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
 	"github.com/spf13/cobra"
 
 	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
 )
 
-// maxBinderFileSize is the maximum allowed size for _binder.md files (10 MB).
-const maxBinderFileSize = 10 * 1024 * 1024
-
-// readBinderSizeLimitedImpl reads the binder file at path, rejecting files that
-// exceed maxBinderFileSize. Excluded from coverage because it wraps OS calls.
-func readBinderSizeLimitedImpl(path string) ([]byte, error) {
-	fi, err := os.Stat(path)
-	if err != nil {
-		return nil, err
-	}
-	if fi.Size() > maxBinderFileSize {
-		return nil, fmt.Errorf("binder file exceeds the 10 MB size limit")
-	}
-	return os.ReadFile(path)
-}
-
 // NewRootCmd creates the root pmk command with all subcommands registered.
 func NewRootCmd() *cobra.Command {
 	root := &cobra.Command{
@@ -36,14 +23,63 @@ func NewRootCmd() *cobra.Command {
 		Args:          cobra.NoArgs,
 		SilenceErrors: true,
 		RunE:          rootRunE,
+		// Runs before every subcommand, so -C applies uniformly: relative
+		// --project values and the default project directory both resolve
+		// against the new working directory, as with git -C and make -C.
+		PersistentPreRunE: rootPersistentPreRunE,
 	}
+	root.PersistentFlags().StringP("chdir", "C", "", "run as if pmk was started in this directory")
+	root.PersistentFlags().String("config", "", "read this file as the project config instead of .prosemark.yml")
+	root.PersistentFlags().String("binder", "", "use this binder file in the project (default: binder in .prosemark.yml, or _binder.md)")
+	root.PersistentFlags().Int("max-diagnostics", 0, "report at most N diagnostics, noting how many were left out (0: no limit); JSON output is capped but not grouped")
 	root.AddCommand(NewParseCmd(newDefaultParseReader()))
 	root.AddCommand(NewAddChildCmd(newDefaultAddChildIO()))
 	root.AddCommand(NewDeleteCmd(newDefaultDeleteIO()))
+	root.AddCommand(NewRestoreCmd(newDefaultDeleteIO()))
 	root.AddCommand(NewMoveCmd(newDefaultMoveIO()))
+	root.AddCommand(NewApplyCmd(&fileApplyIO{}))
+	root.AddCommand(NewBrowseCmd(&fileBrowseIO{}))
+	root.AddCommand(NewServeCmd(&fileServeIO{}))
+	root.AddCommand(NewLSPCmd(fileLSPIO{}))
+	root.AddCommand(NewBookmarkCmd(fileBookmarkIO{}))
+	root.AddCommand(NewAssertCmd(fileAssertIO{}))
+	root.AddCommand(NewShowCmd(fileShowIO{}))
+	root.AddCommand(NewMetaCmd(fileMetaIO{}))
+	root.AddCommand(NewConflictsCmd(fileConflictsIO{}))
+	root.AddCommand(NewJournalCmd(fileJournalIO{}))
+	root.AddCommand(NewMergeBinderCmd(fileMergeBinderIO{}))
+	root.AddCommand(NewPromoteCmd(&fileShiftIO{}))
+	root.AddCommand(NewDemoteCmd(&fileShiftIO{}))
 	root.AddCommand(NewInitCmd(fileInitIO{}))
+	root.AddCommand(NewDemoCmd(fileDemoIO{}))
+	root.AddCommand(NewNewProjectCmd(fileNewProjectIO{}))
 	root.AddCommand(NewEditCmd(fileEditIO{}))
 	root.AddCommand(NewDoctorCmd(fileDoctorIO{}))
+	root.AddCommand(NewCheckCmd(newDefaultCheckIO()))
+	root.AddCommand(NewUncheckCmd(newDefaultCheckIO()))
+	root.AddCommand(NewCommentsCmd(fileCommentsIO{}))
+	root.AddCommand(NewExportCmd(fileExportIO{}))
+	root.AddCommand(NewCheckLinksCmd(fileCheckLinksIO{}))
+	root.AddCommand(NewGCCmd(fileGCIO{}))
+	root.AddCommand(NewLockCmd(fileLockIO{}))
+	root.AddCommand(NewUnlockCmd(fileLockIO{}))
+	root.AddCommand(NewGrepCmd(fileGrepIO{}))
+	root.AddCommand(NewCleanExportsCmd(fileCleanExportsIO{}))
+	root.AddCommand(NewSlugsCmd(fileSlugsIO{}))
+	root.AddCommand(NewCaptureCmd(&fileCaptureIO{}))
+	root.AddCommand(NewAppendCmd(fileAppendIO{}))
+	root.AddCommand(NewPrependCmd(fileAppendIO{}))
+	root.AddCommand(NewCompileCmd(fileCompileIO{}))
+	root.AddCommand(NewAnnotateTreeCmd(fileAnnotateTreeIO{}))
+	root.AddCommand(NewMaterializeCmd(fileMaterializeIO{}))
+	root.AddCommand(NewRenameCmd(fileRenameIO{}))
+	root.AddCommand(NewTitlesCmd(fileTitlesIO{}))
+	root.AddCommand(NewSyncTitlesCmd(fileSyncTitlesIO{}))
+	root.AddCommand(NewTreeCmd(fileTreeIO{}))
+	root.AddCommand(NewWCCmd(fileWCIO{}))
+	root.AddCommand(NewCountChaptersCmd(fileWCIO{}))
+	root.AddCommand(NewVersionCmd(fileSelfUpdateIO{}))
+	root.AddCommand(NewSelfUpdateCmd(fileSelfUpdateIO{}))
 	return root
 }
 
@@ -51,6 +87,34 @@ func rootRunE(cmd *cobra.Command, _ []string) error {
 	return cmd.Help()
 }
 
+// chdirFunc changes the process working directory for -C/--chdir.
+// Override in tests to avoid changing the test process's directory.
+var chdirFunc = os.Chdir
+
+// rootPersistentPreRunE validates --max-diagnostics and --binder and applies -C/--chdir,
+// then --config, before any subcommand runs.
+func rootPersistentPreRunE(cmd *cobra.Command, _ []string) error {
+	if maxDiagnosticsFromCmd(cmd) < 0 {
+		return fmt.Errorf("--max-diagnostics cannot be negative")
+	}
+	if cmd.Flags().Changed("binder") {
+		name, _ := cmd.Flags().GetString("binder")
+		if err := binder.ValidateBinderName(name); err != nil {
+			return fmt.Errorf("--binder: %w", err)
+		}
+	}
+	if cmd.Flags().Changed("chdir") {
+		dir, _ := cmd.Flags().GetString("chdir")
+		if dir == "" {
+			return fmt.Errorf("-C/--chdir flag cannot be empty")
+		}
+		if err := chdirFunc(dir); err != nil {
+			return fmt.Errorf("changing directory: %w", err)
+		}
+	}
+	return applyConfigFlag(cmd)
+}
+
 // resolveProjectDirFromCmd validates the --project flag and resolves the project directory.
 // It returns an error if the flag was explicitly set to an empty string.
 func resolveProjectDirFromCmd(cmd *cobra.Command, getwd func() (string, error)) (string, error) {
@@ -68,27 +132,121 @@ func resolveProjectDirFromCmd(cmd *cobra.Command, getwd func() (string, error))
 	return project, nil
 }
 
-// resolveBinderPathFromCmd validates the --project flag and resolves the binder path.
+// resolveBinderPathFromCmd validates the --project flag and resolves the binder path:
+// the --binder file, or the project's default binder, in the project directory.
 // It returns an error if the flag was explicitly set to an empty string.
 func resolveBinderPathFromCmd(cmd *cobra.Command, getwd func() (string, error)) (string, error) {
 	project, err := resolveProjectDirFromCmd(cmd, getwd)
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(project, "_binder.md"), nil
+	name, err := binderNameFromCmd(cmd, project)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(project, name), nil
 }
 
-// resolveBinderPath derives the binder path from a project directory.
-// If project is empty, getwd is called to determine the current directory.
-func resolveBinderPath(project string, getwd func() (string, error)) (string, error) {
-	if project == "" {
-		cwd, err := getwd()
+// binderNameFromCmd returns the filename of the binder to use in the project
+// in dir: --binder when given, else the project's default binder. It fails
+// when the project config that names the default cannot be read.
+func binderNameFromCmd(cmd *cobra.Command, dir string) (string, error) {
+	if name, _ := cmd.Flags().GetString("binder"); name != "" {
+		return name, nil
+	}
+	return fsio.BinderFilename(dir)
+}
+
+// CodeConflictingProjectRoot is an implementation-specific warning emitted when
+// --project and a positional binder path name different project roots.
+const CodeConflictingProjectRoot = "PMKW001"
+
+// resolveBinderPathWithArg resolves the binder path for commands that accept
+// an optional positional binder path as well as --project. The positional
+// path names an exact file, so it takes precedence, and the project root is
+// always the binder's directory. A directory stands for the binder in it or
+// in its nearest ancestor. When --project names a different directory,
+// a CodeConflictingProjectRoot warning is returned so the mismatch is not
+// silently reported as missing files.
+func resolveBinderPathWithArg(cmd *cobra.Command, args []string, getwd func() (string, error)) (string, []binder.Diagnostic, error) {
+	if len(args) == 0 {
+		path, err := resolveBinderPathFromCmd(cmd, getwd)
+		return path, nil, err
+	}
+	binderPath := args[0]
+	if binderPath == "" {
+		return "", nil, fmt.Errorf("binder path cannot be empty")
+	}
+	if fsio.IsDir(binderPath) {
+		dir, err := absFromCWD(binderPath, getwd)
 		if err != nil {
-			return "", fmt.Errorf("getting working directory: %w", err)
+			return "", nil, err
 		}
-		project = cwd
+		name, err := binderNameFromCmd(cmd, dir)
+		if err != nil {
+			return "", nil, err
+		}
+		if binderPath, err = fsio.FindBinder(dir, name); err != nil {
+			return "", nil, err
+		}
+	}
+	if !cmd.Flags().Changed("project") {
+		return binderPath, nil, nil
+	}
+	project, err := resolveProjectDirFromCmd(cmd, getwd)
+	if err != nil {
+		return "", nil, err
+	}
+	binderDir, err := absFromCWD(filepath.Dir(binderPath), getwd)
+	if err != nil {
+		return "", nil, err
+	}
+	projectDir, err := absFromCWD(project, getwd)
+	if err != nil {
+		return "", nil, err
+	}
+	if binderDir == projectDir {
+		return binderPath, nil, nil
+	}
+	return binderPath, []binder.Diagnostic{{
+		Severity: "warning",
+		Code:     CodeConflictingProjectRoot,
+		Message: fmt.Sprintf("--project %s disagrees with binder path %s; using the binder's directory %s as the project root",
+			sanitizePath(project), sanitizePath(binderPath), sanitizePath(filepath.Dir(binderPath))),
+	}}, nil
+}
+
+// discoverBinderPath resolves the binder for a command run with neither a
+// binder path nor --project: the binder in the working directory or, when it
+// has none, in its nearest ancestor, so running from inside a project finds
+// the project root. Without such an ancestor it falls back to the working
+// directory's binder, leaving the missing file to be reported when read.
+func discoverBinderPath(cmd *cobra.Command, getwd func() (string, error)) (string, error) {
+	fallback, err := resolveBinderPathFromCmd(cmd, getwd)
+	if err != nil {
+		return "", err
 	}
-	return filepath.Join(project, "_binder.md"), nil
+	cwd := filepath.Dir(fallback)
+	if !fsio.IsDir(cwd) {
+		return fallback, nil
+	}
+	if path, err := fsio.FindBinder(cwd, filepath.Base(fallback)); err == nil {
+		return path, nil
+	}
+	return fallback, nil
+}
+
+// absFromCWD returns the cleaned absolute form of path, resolving relative
+// paths against getwd.
+func absFromCWD(path string, getwd func() (string, error)) (string, error) {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path), nil
+	}
+	cwd, err := getwd()
+	if err != nil {
+		return "", fmt.Errorf("getting working directory: %w", err)
+	}
+	return filepath.Join(cwd, path), nil
 }
 
 // emitOPE009AndError writes an OPE009 error diagnostic and returns a non-nil
@@ -99,39 +257,133 @@ func emitOPE009AndError(cmd *cobra.Command, jsonMode bool, origErr error) error
 	if jsonMode {
 		diags := []binder.Diagnostic{{Severity: "error", Code: binder.CodeIOOrParseFailure, Message: origErr.Error()}}
 		out := binder.OpResult{Version: "1", Changed: false, Diagnostics: diags}
-		_ = json.NewEncoder(cmd.OutOrStdout()).Encode(out)
+		_ = encodeOutput(cmd, out)
 	} else {
 		fmt.Fprintf(cmd.ErrOrStderr(), "error: I/O or parse failure: %v (OPE009)\n", origErr)
 	}
 	return fmt.Errorf("operation failed: %w", origErr)
 }
 
-// printDiagnostics writes each diagnostic to stderr in human-readable form.
-func printDiagnostics(cmd *cobra.Command, diags []binder.Diagnostic) {
-	for _, d := range diags {
-		fmt.Fprintf(cmd.ErrOrStderr(), "%s: %s (%s)\n", d.Severity, d.Message, d.Code)
+// finishBinderOp reports the outcome of a core binder operation and records
+// it under --op-id. A nil res means the operation never ran; a scan failure
+// is then reported as OPE009. err is the operation's error, if any. Under
+// --dry-run the diff is printed instead and nothing is recorded; under
+// --diff it is printed after the operation is recorded.
+func finishBinderOp(cmd *cobra.Command, io any, binderPath string, jsonMode bool, res *binder.OpResult, err error) error {
+	if res == nil {
+		var scanErr *core.ScanError
+		if errors.As(err, &scanErr) {
+			return emitOPE009AndError(cmd, jsonMode, scanErr.Err)
+		}
+		return err
+	}
+	if jsonMode {
+		out := *res
+		out.Diagnostics = reportedDiagnostics(cmd, res.Diagnostics)
+		if !showDiff(cmd) {
+			out.Diff = ""
+		}
+		if err := encodeOutput(cmd, out); err != nil {
+			return fmt.Errorf("encoding output: %w", err)
+		}
+	} else {
+		printDiagnostics(cmd, res.Diagnostics)
+	}
+	if hasDiagnosticError(res.Diagnostics) {
+		return errors.Join(fmt.Errorf("%s has errors", cmd.Name()), err)
+	}
+	if err != nil {
+		return err
 	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		if jsonMode {
+			return nil
+		}
+		return printDryRun(cmd, binderPath, res.Diff)
+	}
+	if err := recordOp(cmd, io, binderPath, *res); err != nil {
+		return err
+	}
+	if jsonMode || !showDiff(cmd) {
+		return nil
+	}
+	return printDiff(cmd, res.Diff)
+}
+
+// addDiffFlag registers --diff on a binder-mutating command.
+func addDiffFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("diff", false, "print a unified diff of the binder changes (the diff field with --json)")
+}
+
+// showDiff reports whether cmd reports the binder diff: under --diff or
+// --dry-run.
+func showDiff(cmd *cobra.Command) bool {
+	diff, _ := cmd.Flags().GetBool("diff")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	return diff || dryRun
 }
 
-// checkConflictingPositionFlags returns an error if more than one of the
-// mutually-exclusive positioning flags (--first, --at, --before, --after)
-// is set. Both add and move commands share this validation.
-func checkConflictingPositionFlags(cmd *cobra.Command, first bool, before, after string) error {
-	positionFlagsSet := 0
-	if first {
-		positionFlagsSet++
+// printDiff writes diff, which is empty when the binder did not change.
+func printDiff(cmd *cobra.Command, diff string) error {
+	if _, err := io.WriteString(cmd.OutOrStdout(), diff); err != nil {
+		return fmt.Errorf("writing output: %w", err)
 	}
-	if cmd.Flags().Changed("at") {
-		positionFlagsSet++
+	return nil
+}
+
+// addDryRunFlag registers --dry-run on a binder-mutating command.
+func addDryRunFlag(cmd *cobra.Command, dryRun *bool) {
+	cmd.Flags().BoolVar(dryRun, "dry-run", false, "run the operation and print a diff of the binder changes without writing anything")
+}
+
+// printDryRun writes the diff a dry run computed for the binder at
+// binderPath, or a note that it would not change.
+func printDryRun(cmd *cobra.Command, binderPath, diff string) error {
+	if diff == "" {
+		diff = "No changes to " + sanitizePath(binderPath) + " (dry run)\n"
 	}
-	if before != "" {
-		positionFlagsSet++
+	return printDiff(cmd, diff)
+}
+
+// addForceParseFlag registers --force-parse on a binder-mutating command.
+func addForceParseFlag(cmd *cobra.Command, force *bool) {
+	cmd.Flags().BoolVar(force, "force-parse", false, "modify the binder even though it has parse errors (BNDExxx)")
+}
+
+// addRepairEncodingFlag registers --repair-encoding on a read-only command.
+func addRepairEncodingFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("repair-encoding", false, "read invalid UTF-8 as U+FFFD and report it (PMKE008) instead of failing")
+}
+
+// addRevFlag adds --rev, which reads the project as committed at a git
+// revision instead of from the work tree.
+func addRevFlag(cmd *cobra.Command, rev *string) {
+	cmd.Flags().StringVar(rev, "rev", "", "read the binder and node files as committed at this git revision, without checking it out")
+}
+
+// binderReadError describes a failure to read the binder as of rev ("" for
+// the work tree): a missing binder means an uninitialized project, or a
+// revision from before the project had one.
+func binderReadError(err error, rev string) error {
+	if !errors.Is(err, os.ErrNotExist) {
+		return err
 	}
-	if after != "" {
-		positionFlagsSet++
+	if rev != "" {
+		return fmt.Errorf("no binder at revision %s", rev)
 	}
-	if positionFlagsSet > 1 {
-		return fmt.Errorf("only one of --first, --at, --before, --after may be specified (%s)", binder.CodeConflictingFlags)
+	return fmt.Errorf("project not initialized — run 'pmk init' first")
+}
+
+// parseOptionsFromCmd returns the core.ParseOptions cmd's flags select.
+func parseOptionsFromCmd(cmd *cobra.Command) core.ParseOptions {
+	repair, _ := cmd.Flags().GetBool("repair-encoding")
+	return core.ParseOptions{RepairEncoding: repair}
+}
+
+// printDiagnostics writes diags to stderr in human-readable form, grouped
+// and capped as diagnosticLines describes.
+func printDiagnostics(cmd *cobra.Command, diags []binder.Diagnostic) {
+	for _, line := range diagnosticLines(diags, maxDiagnosticsFromCmd(cmd)) {
+		fmt.Fprintln(cmd.ErrOrStderr(), line)
 	}
-	return nil
 }
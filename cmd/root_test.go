@@ -7,6 +7,12 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/iotest"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/fsio"
 )
 
 func TestNewRootCmd_RegistersParseSubcommand(t *testing.T) {
@@ -35,8 +41,20 @@ func TestBuildCommandTree_AllCommandsHandleNilService(t *testing.T) {
 	}
 }
 
+// resolveBinderPathForArgs parses args into a command carrying the standard
+// --project flag and resolves the binder path from it.
+func resolveBinderPathForArgs(t *testing.T, args []string, getwd func() (string, error)) (string, error) {
+	t.Helper()
+	c := &cobra.Command{Use: "x"}
+	c.Flags().String("project", "", "")
+	if err := c.ParseFlags(args); err != nil {
+		t.Fatal(err)
+	}
+	return resolveBinderPathFromCmd(c, getwd)
+}
+
 func TestResolveBinderPath_UsesProjectWhenSet(t *testing.T) {
-	got, err := resolveBinderPath("/my/project", nil)
+	got, err := resolveBinderPathForArgs(t, []string{"--project", "/my/project"}, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -47,7 +65,7 @@ func TestResolveBinderPath_UsesProjectWhenSet(t *testing.T) {
 }
 
 func TestResolveBinderPath_UsesCWDWhenProjectEmpty(t *testing.T) {
-	got, err := resolveBinderPath("", func() (string, error) { return "/cwd", nil })
+	got, err := resolveBinderPathForArgs(t, nil, func() (string, error) { return "/cwd", nil })
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -73,12 +91,251 @@ func TestRootCmd_NoArgs_ShowsHelp(t *testing.T) {
 }
 
 func TestResolveBinderPath_ReturnsErrorWhenGetCWDFails(t *testing.T) {
-	_, err := resolveBinderPath("", func() (string, error) { return "", errors.New("getwd failed") })
+	_, err := resolveBinderPathForArgs(t, nil, func() (string, error) { return "", errors.New("getwd failed") })
 	if err == nil {
 		t.Error("expected error when getwd fails")
 	}
 }
 
+func TestResolveBinderPath_RejectsEmptyProject(t *testing.T) {
+	_, err := resolveBinderPathForArgs(t, []string{"--project", ""}, nil)
+	if err == nil || !strings.Contains(err.Error(), "--project flag cannot be empty") {
+		t.Errorf("error = %v, want empty --project rejection", err)
+	}
+}
+
+func TestResolveBinderPath_InvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".prosemark.yml"), []byte("binder: outline.md\nid_scheme: bogus\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var cfgErr *fsio.ConfigError
+	if _, err := resolveBinderPathForArgs(t, []string{"--project", dir}, nil); !errors.As(err, &cfgErr) {
+		t.Errorf("--project: err = %v, want the config error rather than a fallback to _binder.md", err)
+	}
+
+	c := &cobra.Command{Use: "x"}
+	c.Flags().String("project", "", "")
+	if _, _, err := resolveBinderPathWithArg(c, []string{dir}, nil); !errors.As(err, &cfgErr) {
+		t.Errorf("directory argument: err = %v, want the config error", err)
+	}
+}
+
+// TestEditorCommands_InvalidConfig verifies that commands which open an
+// editor report an invalid project config, which --binder kept them from
+// reading before, rather than treating the editor as unset.
+func TestEditorCommands_InvalidConfig(t *testing.T) {
+	t.Setenv("EDITOR", "")
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".prosemark.yml"), []byte("id_scheme: bogus\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	getwd := func() (string, error) { return dir, nil }
+	// withBinderFlag gives c the root command's --binder flag, set.
+	withBinderFlag := func(c *cobra.Command) *cobra.Command {
+		c.Flags().String("binder", "", "")
+		if err := c.Flags().Set("binder", "_binder.md"); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	var cfgErr *fsio.ConfigError
+
+	add := withBinderFlag(newAddChildCmdWithGetCWD(&mockAddChildIOWithNew{mockAddChildIO: mockAddChildIO{binderBytes: emptyBinder()}}, getwd))
+	if _, err := runCmdInCWD(add, "--new", "--title", "Node", "--edit", "--parent", "."); !errors.As(err, &cfgErr) {
+		t.Errorf("add --new --edit: err = %v, want the config error", err)
+	}
+
+	edit := withBinderFlag(newEditCmdWithGetCWD(&mockEditIO{binderBytes: editBinderWithNode(), nodeFileBytes: validEditNodeContent()}, getwd))
+	if _, err := runCmdInCWD(edit, editTestNodeUUID); !errors.As(err, &cfgErr) {
+		t.Errorf("edit: err = %v, want the config error", err)
+	}
+
+	mock := &mockBrowseIO{mockMoveIO: mockMoveIO{binderBytes: moveBinder()}}
+	browse := withBinderFlag(newBrowseCmd(mock, getwd, &fakeTerminal{}))
+	browse.SetIn(iotest.OneByteReader(strings.NewReader("eq")))
+	out, err := runCmdInCWD(browse)
+	if err != nil {
+		t.Fatalf("browse: %v", err)
+	}
+	if len(mock.edited) != 0 || !strings.Contains(out, "editor: .prosemark.yml: ") {
+		t.Errorf("browse: edited %v; output %q, want the config error", mock.edited, out)
+	}
+}
+
+// stubChdir replaces chdirFunc for the duration of the test, recording the
+// requested directory and returning err.
+func stubChdir(t *testing.T, err error) *string {
+	t.Helper()
+	orig := chdirFunc
+	t.Cleanup(func() { chdirFunc = orig })
+	var got string
+	chdirFunc = func(dir string) error {
+		got = dir
+		return err
+	}
+	return &got
+}
+
+func TestRootCmd_Chdir(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		chdirErr error
+		wantDir  string
+		wantErr  string
+	}{
+		{name: "short flag", args: []string{"-C", "novel", "comments"}, wantDir: "novel"},
+		{name: "long flag after subcommand", args: []string{"comments", "--chdir=novel"}, wantDir: "novel"},
+		{name: "not set", args: []string{"comments"}},
+		{name: "empty", args: []string{"-C", "", "comments"}, wantErr: "-C/--chdir flag cannot be empty"},
+		{name: "missing directory", args: []string{"-C", "nope", "comments"}, chdirErr: os.ErrNotExist, wantDir: "nope", wantErr: "changing directory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := stubChdir(t, tt.chdirErr)
+			root := NewRootCmd()
+			root.SetOut(new(bytes.Buffer))
+			root.SetErr(new(bytes.Buffer))
+			root.SetArgs(tt.args)
+			err := root.Execute()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+			if *got != tt.wantDir {
+				t.Errorf("chdir = %q, want %q", *got, tt.wantDir)
+			}
+		})
+	}
+}
+
+func TestRootCmd_Config(t *testing.T) {
+	settings := filepath.Join(t.TempDir(), "settings.yml")
+	if err := os.WriteFile(settings, []byte("indent: 4\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{name: "set", args: []string{"--config", settings, "comments"}, want: settings},
+		{name: "not set", args: []string{"comments"}},
+		{name: "empty", args: []string{"--config", "", "comments"}, wantErr: "--config flag cannot be empty"},
+		{name: "missing file", args: []string{"--config", settings + ".missing", "comments"}, wantErr: "--config"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orig := setConfigOverride
+			t.Cleanup(func() { setConfigOverride = orig })
+			var got string
+			setConfigOverride = func(path string) { got = path }
+
+			root := NewRootCmd()
+			root.SetOut(new(bytes.Buffer))
+			root.SetErr(new(bytes.Buffer))
+			root.SetArgs(tt.args)
+			err := root.Execute()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("config override = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRootCmd_ConfigRelativeWithoutWorkingDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "gone")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	if err := os.Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	root := NewRootCmd()
+	root.SetOut(new(bytes.Buffer))
+	root.SetErr(new(bytes.Buffer))
+	root.SetArgs([]string{"--config", "settings.yml", "comments"})
+	if err := root.Execute(); err == nil || !strings.Contains(err.Error(), "--config") {
+		t.Errorf("error = %v, want --config to fail without a working directory", err)
+	}
+}
+
+func TestRootCmd_Binder(t *testing.T) {
+	dir := t.TempDir()
+	run := func(args ...string) (string, error) {
+		root := NewRootCmd()
+		out := new(bytes.Buffer)
+		root.SetOut(out)
+		root.SetErr(new(bytes.Buffer))
+		root.SetArgs(append(args, "--project", dir))
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	if _, err := run("--binder", "_outtakes.md", "init"); err != nil {
+		t.Fatalf("init --binder: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "_outtakes.md")); err != nil {
+		t.Errorf("_outtakes.md not created: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "_binder.md")); err == nil {
+		t.Error("_binder.md created, want only the --binder file")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "_outtakes.md"), []byte("<!-- prosemark-binder:v1 -->\n- [Main](draft.md)\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".prosemark.yml"), []byte("binder: draft.md\nbinders: [_outtakes.md]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run("init"); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "draft.md")); err != nil {
+		t.Errorf("configured binder draft.md not created: %v", err)
+	}
+	out, _ := run("--binder", "_outtakes.md", "parse")
+	if !strings.Contains(out, binder.CodeSelfReferentialLink) {
+		t.Errorf("parse --binder _outtakes.md: want %s for the link to draft.md in\n%s", binder.CodeSelfReferentialLink, out)
+	}
+
+	if _, err := run("--binder", "outtakes.txt", "parse"); err == nil || !strings.Contains(err.Error(), "--binder") {
+		t.Errorf("with a non-Markdown --binder: err = %v", err)
+	}
+}
+
+func TestRootCmd_ChdirRunsCommandInDirectory(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	root := NewRootCmd()
+	root.SetOut(new(bytes.Buffer))
+	root.SetErr(new(bytes.Buffer))
+	root.SetArgs([]string{"-C", dir, "init"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "_binder.md")); err != nil {
+		t.Errorf("_binder.md not created in -C directory: %v", err)
+	}
+}
+
 // TestRootCmd_FileInitIO_ImplementsInitIO is a compile-time assertion that
 // fileInitIO (value, not pointer) satisfies the InitIO interface.
 // Acceptance: NewInitCmd(fileInitIO{}) registered via rootCmd.AddCommand.
@@ -237,3 +494,31 @@ func TestRootCmd_EditCmd_BinderParseError_ShowsCannotParse(t *testing.T) {
 		t.Errorf("expected error to contain %q, got err=%q stderr=%q", want, err.Error(), errOut.String())
 	}
 }
+
+func TestRootCmd_MaxDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	src := "<!-- prosemark-binder:v1 -->\n```\n- [A](a.md)\n- [B](b.md)\n- [C](c.md)\n```\n"
+	if err := os.WriteFile(filepath.Join(dir, "_binder.md"), []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	root := NewRootCmd()
+	out := new(bytes.Buffer)
+	root.SetOut(out)
+	root.SetErr(new(bytes.Buffer))
+	root.SetArgs([]string{"parse", "--project", dir, "--max-diagnostics", "2"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), `"code":"PMKI002","message":"1 more diagnostics not shown (--max-diagnostics 2)"`) {
+		t.Errorf("output = %s, want a truncation notice", out)
+	}
+
+	root = NewRootCmd()
+	root.SetOut(new(bytes.Buffer))
+	root.SetErr(new(bytes.Buffer))
+	root.SetArgs([]string{"parse", "--project", dir, "--max-diagnostics", "-1"})
+	if err := root.Execute(); err == nil || !strings.Contains(err.Error(), "--max-diagnostics cannot be negative") {
+		t.Errorf("error = %v, want negative --max-diagnostics rejection", err)
+	}
+}
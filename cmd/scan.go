@@ -1,43 +1,41 @@
 package cmd
 
 import (
-	"context"
-	"io/fs"
-	"path/filepath"
-	"strings"
+	"github.com/spf13/cobra"
 
 	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/safepath"
 )
 
-// ScanProjectImpl walks the directory containing binderPath recursively,
-// collecting all .md files (excluding _binder.md itself) and returns a
-// *binder.Project. It is an Impl function: it performs OS filesystem
-// operations and is excluded from unit test coverage calculations.
-func ScanProjectImpl(_ context.Context, binderPath string) (*binder.Project, error) {
-	dir := filepath.Dir(binderPath)
-	var files []string
-	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
+// binderTargetNodes returns the nodes under root in reading order, keeping
+// only the first occurrence of each target.
+func binderTargetNodes(root *binder.Node) []*binder.Node {
+	var nodes []*binder.Node
+	seen := make(map[string]bool)
+	var walk func(children []*binder.Node)
+	walk = func(children []*binder.Node) {
+		for _, n := range children {
+			if n.Target != "" && !seen[n.Target] {
+				seen[n.Target] = true
+				nodes = append(nodes, n)
+			}
+			walk(n.Children)
 		}
-		if d.IsDir() {
-			return nil
-		}
-		if !strings.HasSuffix(path, ".md") {
-			return nil
-		}
-		rel, err := filepath.Rel(dir, path)
-		if err != nil {
-			return err
-		}
-		if rel == "_binder.md" {
-			return nil
-		}
-		files = append(files, filepath.ToSlash(rel))
-		return nil
-	})
-	if files == nil {
-		files = []string{}
 	}
-	return &binder.Project{Files: files, BinderDir: "."}, err
+	walk(root.Children)
+	return nodes
+}
+
+// resolveTargetPath resolves the binder target against projectDir for a
+// command that scans node files. A target Resolve refuses is reported on
+// stderr as its BNDE002 diagnostic and ok is false, so the caller skips it:
+// parse only checks the target's text, and a symlink leading outside the
+// project would otherwise be skipped silently.
+func resolveTargetPath(cmd *cobra.Command, projectDir, target string) (path string, ok bool) {
+	path, err := safepath.Resolve(projectDir, target)
+	if err != nil {
+		printDiagnostics(cmd, []binder.Diagnostic{safepath.Diagnostic(target)})
+		return "", false
+	}
+	return path, true
 }
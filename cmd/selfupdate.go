@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/eykd/prosemark-go/internal/release"
+)
+
+// selfUpdateEnabled reports whether pmk self-update may replace the binary.
+// Builds for package managers turn it off with the noselfupdate build tag,
+// so the package manager stays in charge of updates.
+var selfUpdateEnabled = true
+
+// maxReleaseDownload caps the size of any file fetched from the releases
+// feed (100 MB); tests lower it.
+var maxReleaseDownload = 100 * 1024 * 1024
+
+// osExecutable is os.Executable; tests replace it to exercise its failure.
+var osExecutable = os.Executable
+
+// ReleaseIO fetches pmk's release metadata and archives.
+type ReleaseIO interface {
+	// Fetch returns the body of a successful GET of url.
+	Fetch(ctx context.Context, url string) ([]byte, error)
+}
+
+// SelfUpdateIO handles I/O for the self-update command.
+type SelfUpdateIO interface {
+	ReleaseIO
+	// Executable returns the path of the running pmk binary.
+	Executable() (string, error)
+	// ReplaceExecutable atomically replaces the binary at path with data.
+	ReplaceExecutable(path string, data []byte) error
+}
+
+// NewSelfUpdateCmd creates the self-update subcommand.
+func NewSelfUpdateCmd(io SelfUpdateIO) *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "self-update",
+		Short: "Replace pmk with the latest release",
+		Long: "Download the latest pmk release for this platform from GitHub, verify it\n" +
+			"against the release's SHA-256 checksums, and replace the running binary\n" +
+			"with it. Nothing is replaced when pmk is already up to date, unless\n" +
+			"--force is given. Development builds, whose version cannot be compared,\n" +
+			"also need --force. Use pmk version --check to only look.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if !selfUpdateEnabled {
+				return fmt.Errorf("self-update is disabled in this build; update pmk the way it was installed")
+			}
+			ctx := cmd.Context()
+			latest, err := latestRelease(ctx, io)
+			if err != nil {
+				return err
+			}
+
+			if !force {
+				cmp, ok := release.Compare(buildInfo.Version, latest.Version())
+				if !ok {
+					return fmt.Errorf("cannot compare development build %s with release %s; use --force to install it", sanitizePath(buildInfo.Version), sanitizePath(latest.Version()))
+				}
+				if cmp >= 0 {
+					if _, err := fmt.Fprintf(cmd.OutOrStdout(), "pmk %s is up to date\n", sanitizePath(buildInfo.Version)); err != nil {
+						return fmt.Errorf("writing output: %w", err)
+					}
+					return nil
+				}
+			}
+
+			binary, err := downloadBinary(ctx, io, latest)
+			if err != nil {
+				return err
+			}
+			exe, err := io.Executable()
+			if err != nil {
+				return fmt.Errorf("locating pmk: %w", err)
+			}
+			if err := io.ReplaceExecutable(exe, binary); err != nil {
+				return fmt.Errorf("replacing %s: %w", sanitizePath(exe), err)
+			}
+			if _, err := fmt.Fprintf(cmd.OutOrStdout(), "Updated pmk %s to %s\n", sanitizePath(buildInfo.Version), sanitizePath(latest.Version())); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "install the latest release even if it is not newer")
+	return cmd
+}
+
+// latestRelease fetches and decodes the newest release.
+func latestRelease(ctx context.Context, io ReleaseIO) (*release.Release, error) {
+	data, err := io.Fetch(ctx, release.LatestURL)
+	if err != nil {
+		return nil, fmt.Errorf("checking for releases: %w", err)
+	}
+	return release.ParseLatest(data)
+}
+
+// downloadBinary downloads latest's archive for this platform, verifies it
+// against the release checksums, and returns the pmk binary inside.
+func downloadBinary(ctx context.Context, io ReleaseIO, latest *release.Release) ([]byte, error) {
+	name := release.ArchiveName(latest.Version(), runtime.GOOS, runtime.GOARCH)
+	archive := latest.Asset(name)
+	if archive == nil {
+		return nil, fmt.Errorf("release %s has no build for %s/%s", sanitizePath(latest.Version()), runtime.GOOS, runtime.GOARCH)
+	}
+	checksums := latest.Asset(release.ChecksumsAsset)
+	if checksums == nil {
+		return nil, fmt.Errorf("release %s has no %s", sanitizePath(latest.Version()), release.ChecksumsAsset)
+	}
+
+	sums, err := io.Fetch(ctx, checksums.URL)
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", release.ChecksumsAsset, err)
+	}
+	data, err := io.Fetch(ctx, archive.URL)
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", name, err)
+	}
+	if err := release.VerifyChecksum(sums, name, data); err != nil {
+		return nil, err
+	}
+	return release.ExtractBinary(data, release.BinaryName)
+}
+
+// fileSelfUpdateIO implements SelfUpdateIO over HTTPS and the OS.
+type fileSelfUpdateIO struct{}
+
+// Fetch GETs url, failing on a non-2xx status or an oversized body.
+func (fileSelfUpdateIO) Fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "pmk-self-update")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxReleaseDownload)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxReleaseDownload {
+		return nil, fmt.Errorf("GET %s: response exceeds %d bytes", url, maxReleaseDownload)
+	}
+	return data, nil
+}
+
+// Executable returns the running binary's path with symlinks resolved, so
+// the file replaced is the binary rather than a link to it.
+func (fileSelfUpdateIO) Executable() (string, error) {
+	exe, err := osExecutable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(exe)
+}
+
+// ReplaceExecutable replaces the binary at path atomically.
+func (fileSelfUpdateIO) ReplaceExecutable(path string, data []byte) error {
+	return fsio.ReplaceExecutable(path, data)
+}
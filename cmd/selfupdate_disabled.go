@@ -0,0 +1,7 @@
+//go:build noselfupdate
+
+package cmd
+
+func init() {
+	selfUpdateEnabled = false
+}
@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/release"
+)
+
+// mockReleaseIO is a test double for SelfUpdateIO serving files by URL.
+type mockReleaseIO struct {
+	files      map[string][]byte
+	fetchErr   error
+	exeErr     error
+	replaceErr error
+	replaced   map[string][]byte
+}
+
+func (m *mockReleaseIO) Fetch(_ context.Context, url string) ([]byte, error) {
+	if m.fetchErr != nil {
+		return nil, m.fetchErr
+	}
+	data, ok := m.files[url]
+	if !ok {
+		return nil, fmt.Errorf("GET %s: 404 Not Found", url)
+	}
+	return data, nil
+}
+
+func (m *mockReleaseIO) Executable() (string, error) {
+	return "/usr/local/bin/pmk", m.exeErr
+}
+
+func (m *mockReleaseIO) ReplaceExecutable(path string, data []byte) error {
+	if m.replaceErr != nil {
+		return m.replaceErr
+	}
+	if m.replaced == nil {
+		m.replaced = map[string][]byte{}
+	}
+	m.replaced[path] = data
+	return nil
+}
+
+// newReleaseFeed returns a mockReleaseIO publishing version, whose archive
+// for this platform holds binary.
+func newReleaseFeed(t *testing.T, version, binary string) *mockReleaseIO {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: "pmk", Mode: 0o755, Size: int64(len(binary)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte(binary)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archive := buf.Bytes()
+	name := release.ArchiveName(version, runtime.GOOS, runtime.GOARCH)
+	sum := sha256.Sum256(archive)
+
+	feed := fmt.Sprintf(`{"tag_name":"v%s","html_url":"https://example.com/v%s","assets":[`+
+		`{"name":%q,"browser_download_url":"https://example.com/archive"},`+
+		`{"name":"checksums.txt","browser_download_url":"https://example.com/checksums"}]}`, version, version, name)
+	return &mockReleaseIO{files: map[string][]byte{
+		release.LatestURL:               []byte(feed),
+		"https://example.com/archive":   archive,
+		"https://example.com/checksums": []byte(hex.EncodeToString(sum[:]) + "  " + name + "\n"),
+	}}
+}
+
+func runSelfUpdateCmd(t *testing.T, io *mockReleaseIO, args ...string) (string, error) {
+	t.Helper()
+	c := NewSelfUpdateCmd(io)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(args)
+	err := c.Execute()
+	return out.String(), err
+}
+
+func TestSelfUpdate_InstallsNewerRelease(t *testing.T) {
+	stubVersionEnv(t)
+	io := newReleaseFeed(t, "1.3.0", "new binary")
+	out, err := runSelfUpdateCmd(t, io)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := string(io.replaced["/usr/local/bin/pmk"]); got != "new binary" {
+		t.Errorf("replaced binary = %q, want the release's", got)
+	}
+	if out != "Updated pmk 1.2.3 to 1.3.0\n" {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestSelfUpdate_UpToDate(t *testing.T) {
+	stubVersionEnv(t)
+	io := newReleaseFeed(t, "1.2.3", "same binary")
+	out, err := runSelfUpdateCmd(t, io)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if io.replaced != nil {
+		t.Error("binary replaced although up to date")
+	}
+	if out != "pmk 1.2.3 is up to date\n" {
+		t.Errorf("output = %q", out)
+	}
+
+	if _, err := runSelfUpdateCmd(t, io, "--force"); err != nil || io.replaced == nil {
+		t.Errorf("--force: err = %v, replaced = %v; want the binary reinstalled", err, io.replaced != nil)
+	}
+}
+
+func TestSelfUpdate_Errors(t *testing.T) {
+	tampered := func(t *testing.T) *mockReleaseIO {
+		io := newReleaseFeed(t, "1.3.0", "new binary")
+		io.files["https://example.com/archive"] = []byte("tampered")
+		return io
+	}
+	noChecksums := func(t *testing.T) *mockReleaseIO {
+		io := newReleaseFeed(t, "1.3.0", "new binary")
+		io.files[release.LatestURL] = []byte(strings.Replace(string(io.files[release.LatestURL]), "checksums.txt", "notes.txt", 1))
+		return io
+	}
+	otherPlatform := func(t *testing.T) *mockReleaseIO {
+		io := newReleaseFeed(t, "1.3.0", "new binary")
+		io.files[release.LatestURL] = []byte(strings.Replace(string(io.files[release.LatestURL]), runtime.GOOS+"_", "plan9_", 1))
+		return io
+	}
+	tests := []struct {
+		name    string
+		io      func(t *testing.T) *mockReleaseIO
+		version string
+		wantErr string
+	}{
+		{"feed unreachable", func(*testing.T) *mockReleaseIO { return &mockReleaseIO{fetchErr: errors.New("offline")} }, "1.2.3", "checking for releases: offline"},
+		{"development build", func(t *testing.T) *mockReleaseIO { return newReleaseFeed(t, "1.3.0", "x") }, "dev", "use --force"},
+		{"checksum mismatch", tampered, "1.2.3", "checksum mismatch"},
+		{"no checksums", noChecksums, "1.2.3", "has no checksums.txt"},
+		{"no build for platform", otherPlatform, "1.2.3", "has no build for"},
+		{"checksums unreachable", func(t *testing.T) *mockReleaseIO {
+			io := newReleaseFeed(t, "1.3.0", "x")
+			delete(io.files, "https://example.com/checksums")
+			return io
+		}, "1.2.3", "downloading checksums.txt"},
+		{"archive unreachable", func(t *testing.T) *mockReleaseIO {
+			io := newReleaseFeed(t, "1.3.0", "x")
+			delete(io.files, "https://example.com/archive")
+			return io
+		}, "1.2.3", "downloading prosemark-go_"},
+		{"executable unknown", func(t *testing.T) *mockReleaseIO {
+			io := newReleaseFeed(t, "1.3.0", "x")
+			io.exeErr = errors.New("unsupported")
+			return io
+		}, "1.2.3", "locating pmk"},
+		{"replace fails", func(t *testing.T) *mockReleaseIO {
+			io := newReleaseFeed(t, "1.3.0", "x")
+			io.replaceErr = errors.New("permission denied")
+			return io
+		}, "1.2.3", "permission denied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubVersionEnv(t)
+			buildInfo.Version = tt.version
+			io := tt.io(t)
+			_, err := runSelfUpdateCmd(t, io)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+			if io.replaced != nil {
+				t.Error("binary replaced despite the error")
+			}
+		})
+	}
+}
+
+func TestSelfUpdate_DisabledAtBuildTime(t *testing.T) {
+	stubVersionEnv(t)
+	selfUpdateEnabled = false
+
+	io := newReleaseFeed(t, "1.3.0", "new binary")
+	if _, err := runSelfUpdateCmd(t, io); err == nil || !strings.Contains(err.Error(), "disabled in this build") {
+		t.Errorf("error = %v, want self-update disabled", err)
+	}
+	if io.replaced != nil {
+		t.Error("binary replaced although self-update is disabled")
+	}
+}
+
+func TestSelfUpdate_OutputError(t *testing.T) {
+	for _, version := range []string{"1.2.3", "1.3.0"} {
+		stubVersionEnv(t)
+		c := NewSelfUpdateCmd(newReleaseFeed(t, version, "x"))
+		c.SetOut(&errWriter{err: errors.New("closed")})
+		c.SetErr(new(bytes.Buffer))
+		c.SetArgs(nil)
+		if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "writing output") {
+			t.Errorf("release %s: error = %v, want an output error", version, err)
+		}
+	}
+}
+
+func TestFileSelfUpdateIO_Fetch(t *testing.T) {
+	orig := maxReleaseDownload
+	maxReleaseDownload = 8
+	t.Cleanup(func() { maxReleaseDownload = orig })
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			if r.Header.Get("User-Agent") != "pmk-self-update" {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+			_, _ = io.WriteString(w, "release")
+		case "/big":
+			_, _ = io.WriteString(w, "too large a release")
+		case "/short":
+			w.Header().Set("Content-Length", "100")
+			_, _ = io.WriteString(w, "cut")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	if got, err := (fileSelfUpdateIO{}).Fetch(context.Background(), srv.URL+"/ok"); err != nil || string(got) != "release" {
+		t.Errorf("Fetch = %q, %v; want the body", got, err)
+	}
+	tests := []struct {
+		name    string
+		url     string
+		wantErr string
+	}{
+		{"invalid URL", "http://\x7f", "invalid"},
+		{"unreachable", closed.URL, "connect"},
+		{"not found", srv.URL + "/missing", "404 Not Found"},
+		{"oversized", srv.URL + "/big", "exceeds 8 bytes"},
+		{"cut short", srv.URL + "/short", "EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := (fileSelfUpdateIO{}).Fetch(context.Background(), tt.url); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Fetch = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileSelfUpdateIO_Executable(t *testing.T) {
+	var fio fileSelfUpdateIO
+	exe, err := fio.Executable()
+	if err != nil {
+		t.Fatalf("Executable: %v", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err != nil || resolved != exe {
+		t.Errorf("Executable = %q; want a path with symlinks resolved (%q, %v)", exe, resolved, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "pmk")
+	if err := os.WriteFile(path, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fio.ReplaceExecutable(path, []byte("new")); err != nil {
+		t.Fatalf("ReplaceExecutable: %v", err)
+	}
+	if got, err := os.ReadFile(path); err != nil || string(got) != "new" {
+		t.Errorf("binary = %q, %v; want the new one", got, err)
+	}
+
+	orig := osExecutable
+	osExecutable = func() (string, error) { return "", errors.New("unsupported") }
+	t.Cleanup(func() { osExecutable = orig })
+	if _, err := fio.Executable(); err == nil {
+		t.Error("Executable with os.Executable failing: want an error")
+	}
+}
@@ -0,0 +1,372 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/jsonrpc"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/watch"
+)
+
+// ServeIO handles I/O for the serve command.
+type ServeIO interface {
+	core.BinderIO
+	// Audit runs a doctor audit of the project whose binder is binderPath.
+	Audit(ctx context.Context, binderPath string) ([]node.AuditDiagnostic, error)
+	ProjectWatcher
+	// Listen listens on the unix socket at path.
+	Listen(path string) (net.Listener, error)
+}
+
+// Methods answered by pmk serve.
+const (
+	serveMethodParse    = "parse"
+	serveMethodOp       = "op"
+	serveMethodApply    = "apply"
+	serveMethodDoctor   = "doctor"
+	serveMethodShutdown = "shutdown"
+	// serveNotifyInvalidated tells clients that project files changed and
+	// earlier answers may be stale.
+	serveNotifyInvalidated = "invalidated"
+)
+
+// serveParseParams are the params of a parse request.
+type serveParseParams struct {
+	RepairEncoding bool `json:"repairEncoding"`
+}
+
+// serveOpParams are the params of an op request: an op.json object with
+// the flags the matching command takes.
+type serveOpParams struct {
+	binder.OpSpec
+	DryRun     bool `json:"dryRun"`
+	ForceParse bool `json:"forceParse"`
+}
+
+// serveApplyParams are the params of an apply request.
+type serveApplyParams struct {
+	Operations []binder.OpSpec `json:"operations"`
+	DryRun     bool            `json:"dryRun"`
+	ForceParse bool            `json:"forceParse"`
+}
+
+// serveInvalidated is the params of an invalidated notification.
+type serveInvalidated struct {
+	Paths []string `json:"paths"`
+}
+
+// NewServeCmd creates the serve subcommand, a JSON-RPC daemon for editor
+// integrations.
+func NewServeCmd(io ServeIO) *cobra.Command {
+	return newServeCmdWithGetCWD(io, os.Getwd)
+}
+
+func newServeCmdWithGetCWD(sio ServeIO, getwd func() (string, error)) *cobra.Command {
+	var (
+		socket   string
+		interval time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Answer parse, operation, and doctor requests over JSON-RPC",
+		Long: "Run a JSON-RPC 2.0 server for editor integrations, one request per line\n" +
+			"on stdin and stdout, or on each connection to the unix socket --socket.\n" +
+			"The binder and project scan are kept in memory between requests and\n" +
+			"dropped when project files change; clients then get an invalidated\n" +
+			"notification listing the changed paths. Changes are found with file\n" +
+			"notifications, or by checking every --poll-interval where those are\n" +
+			"unavailable, and gathered into bursts that end once --debounce passes\n" +
+			"without another change.\n\n" +
+			"Methods and their params:\n" +
+			"  parse     {repairEncoding}: the pmk parse --json output\n" +
+			"  op        {operation, params, dryRun, forceParse}: one add, delete, or\n" +
+			"            move as in op.json; the op-result, with entries and diff\n" +
+			"  apply     {operations, dryRun, forceParse}: a pmk apply batch\n" +
+			"  doctor    {}: the pmk doctor --json output\n" +
+			"  shutdown  {}: stop the server",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			window, err := debounceFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithCancel(cmd.Context())
+			defer cancel()
+			s := newServeServer(sio, binderPath, cancel)
+			go func() { _ = sio.Watch(ctx, filepath.Dir(binderPath), interval, window, s.invalidate) }()
+
+			if socket == "" {
+				return s.serveConn(ctx, jsonrpc.LineFraming(cmd.InOrStdin(), cmd.OutOrStdout()))
+			}
+			ln, err := sio.Listen(socket)
+			if err != nil {
+				return fmt.Errorf("listening on %s: %w", sanitizePath(socket), err)
+			}
+			stopListening := context.AfterFunc(ctx, func() { _ = ln.Close() })
+			defer stopListening()
+			fmt.Fprintf(cmd.ErrOrStderr(), "pmk serve listening on %s\n", sanitizePath(socket))
+
+			var wg sync.WaitGroup
+			defer wg.Wait()
+			for {
+				c, err := ln.Accept()
+				if err != nil {
+					if ctx.Err() != nil {
+						return nil
+					}
+					return fmt.Errorf("accepting connection: %w", err)
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					stop := context.AfterFunc(ctx, func() { _ = c.Close() })
+					defer stop()
+					defer c.Close()
+					_ = s.serveConn(ctx, jsonrpc.LineFraming(c, c))
+				}()
+			}
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().StringVar(&socket, "socket", "", "listen on this unix socket instead of stdin and stdout")
+	cmd.Flags().DurationVar(&interval, "poll-interval", watch.DefaultPollInterval, "how often to check project files for changes where file notifications are unavailable")
+	addDebounceFlag(cmd, "")
+	return cmd
+}
+
+// serveServer answers requests for one binder, keeping the binder and its
+// project scan between them. It is the core.BinderIO the operations it runs
+// read through, so they share the cache. Requests are answered one at a
+// time across all connections.
+type serveServer struct {
+	io         ServeIO
+	binderPath string
+	shutdown   func()
+
+	mu     sync.Mutex
+	src    []byte
+	proj   *binder.Project
+	parsed map[core.ParseOptions]*core.Parsed
+
+	connMu sync.Mutex
+	conns  map[*jsonrpc.Conn]bool
+}
+
+func newServeServer(io ServeIO, binderPath string, shutdown func()) *serveServer {
+	return &serveServer{
+		io:         io,
+		binderPath: binderPath,
+		shutdown:   shutdown,
+		parsed:     make(map[core.ParseOptions]*core.Parsed),
+		conns:      make(map[*jsonrpc.Conn]bool),
+	}
+}
+
+// serveConn answers the requests on one connection until it ends.
+func (s *serveServer) serveConn(ctx context.Context, framing jsonrpc.Framing) error {
+	conn := jsonrpc.NewConn(framing)
+	s.connMu.Lock()
+	s.conns[conn] = true
+	s.connMu.Unlock()
+	defer func() {
+		s.connMu.Lock()
+		delete(s.conns, conn)
+		s.connMu.Unlock()
+	}()
+	return conn.Serve(ctx, s.handle)
+}
+
+// invalidate drops what the changed project files make stale: the binder
+// when it changed, the project scan when any other file did. Every client is
+// then notified.
+func (s *serveServer) invalidate(paths []string) {
+	s.mu.Lock()
+	binderName := filepath.Base(s.binderPath)
+	for _, p := range paths {
+		if p == binderName {
+			s.src = nil
+		} else {
+			s.proj = nil
+		}
+	}
+	clear(s.parsed)
+	s.mu.Unlock()
+
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	for conn := range s.conns {
+		_ = conn.Notify(serveNotifyInvalidated, serveInvalidated{Paths: paths})
+	}
+}
+
+// handle answers one request.
+func (s *serveServer) handle(ctx context.Context, req *jsonrpc.Request) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch req.Method {
+	case serveMethodParse:
+		var p serveParseParams
+		if err := req.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		parsed, err := s.parse(ctx, core.ParseOptions{RepairEncoding: p.RepairEncoding})
+		if err != nil {
+			return nil, err
+		}
+		out := parseOutput{Version: "1", Diagnostics: parsed.Diagnostics}
+		if parsed.Result != nil {
+			out.Root, out.Fenced, out.Fences = parsed.Result.Root, parsed.Result.Fenced, parsed.Result.Fences
+		}
+		return out, nil
+	case serveMethodOp:
+		var p serveOpParams
+		if err := req.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		return s.op(ctx, p)
+	case serveMethodApply:
+		var p serveApplyParams
+		if err := req.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		for i, spec := range p.Operations {
+			if _, err := spec.DecodeParams(); err != nil {
+				return nil, jsonrpc.Errorf(jsonrpc.CodeInvalidParams, "operation %d: %v", i+1, err)
+			}
+		}
+		return s.result(core.Apply(ctx, s, s.binderPath, p.Operations, p.ForceParse, p.DryRun))
+	case serveMethodDoctor:
+		diags, err := s.io.Audit(ctx, s.binderPath)
+		if err != nil {
+			return nil, err
+		}
+		return newDoctorOutput(diags), nil
+	case serveMethodShutdown:
+		s.shutdown()
+		return nil, nil
+	default:
+		return nil, jsonrpc.Errorf(jsonrpc.CodeMethodNotFound, "unknown method %q", req.Method)
+	}
+}
+
+// parse returns the binder parsed with opts, parsing it only when the
+// binder or project changed since the last request.
+func (s *serveServer) parse(ctx context.Context, opts core.ParseOptions) (*core.Parsed, error) {
+	if parsed := s.parsed[opts]; parsed != nil {
+		return parsed, nil
+	}
+	parsed, err := core.Parse(ctx, s, s.binderPath, opts)
+	if err != nil {
+		return nil, err
+	}
+	s.parsed[opts] = parsed
+	return parsed, nil
+}
+
+// op runs the single operation of an op request.
+func (s *serveServer) op(ctx context.Context, p serveOpParams) (any, error) {
+	params, err := p.DecodeParams()
+	if err != nil {
+		return nil, jsonrpc.Errorf(jsonrpc.CodeInvalidParams, "%v", err)
+	}
+	switch params := params.(type) {
+	case binder.AddChildParams:
+		params.DryRun, params.ForceParse = p.DryRun, params.ForceParse || p.ForceParse
+		return s.result(core.AddChild(ctx, s, s.binderPath, params))
+	case binder.DeleteParams:
+		params.DryRun, params.ForceParse = p.DryRun, params.ForceParse || p.ForceParse
+		return s.result(core.Delete(ctx, s, s.binderPath, params))
+	default: // a MoveParams, the last of the types DecodeParams returns
+		m := params.(binder.MoveParams)
+		m.DryRun, m.ForceParse = p.DryRun, m.ForceParse || p.ForceParse
+		return s.result(core.Move(ctx, s, s.binderPath, m))
+	}
+}
+
+// result returns an operation's result as a handler's. A failed write is
+// an error, though the result describes the change, since the binder is not
+// as the result says.
+func (s *serveServer) result(res *binder.OpResult, err error) (any, error) {
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ReadBinder returns the binder, reading it only when it is not cached.
+func (s *serveServer) ReadBinder(ctx context.Context, path string) ([]byte, error) {
+	if s.src == nil {
+		src, err := s.io.ReadBinder(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		s.src = src
+	}
+	return s.src, nil
+}
+
+// ScanProject returns the project scan, scanning only when it is not cached.
+func (s *serveServer) ScanProject(ctx context.Context, binderPath string) (*binder.Project, error) {
+	if s.proj == nil {
+		proj, err := s.io.ScanProject(ctx, binderPath)
+		if err != nil {
+			return nil, err
+		}
+		s.proj = proj
+	}
+	return s.proj, nil
+}
+
+// WriteBinderAtomic writes the binder and caches what was written.
+func (s *serveServer) WriteBinderAtomic(ctx context.Context, path string, data []byte) error {
+	clear(s.parsed)
+	if err := s.io.WriteBinderAtomic(ctx, path, data); err != nil {
+		s.src = nil
+		return err
+	}
+	s.src = data
+	return nil
+}
+
+// fileServeIO implements ServeIO using OS file I/O.
+type fileServeIO struct {
+	fileProjectIO
+	binderLocker
+	projectWatcher
+}
+
+// Audit runs a doctor audit of the project whose binder is binderPath.
+func (w *fileServeIO) Audit(ctx context.Context, binderPath string) ([]node.AuditDiagnostic, error) {
+	return core.Doctor(ctx, fileDoctorIO{}, binderPath, core.DoctorOptions{})
+}
+
+// Listen listens on the unix socket at path. A socket file left behind by a
+// server that exited without removing it is replaced; a live one is not.
+func (w *fileServeIO) Listen(path string) (net.Listener, error) {
+	if c, err := net.Dial("unix", path); err == nil {
+		_ = c.Close()
+		return nil, errors.New("another server is already listening there")
+	}
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		_ = os.Remove(path)
+	}
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,421 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/jsonrpc"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// mockServeIO is a mockBrowseIO that counts binder reads and records the
+// change callback Watch is given.
+type mockServeIO struct {
+	mockBrowseIO
+	reads     int
+	auditErr  error
+	listenErr error
+	// closed makes Listen return a listener that is already closed.
+	closed bool
+
+	mu      sync.Mutex
+	changed func([]string)
+	window  time.Duration
+}
+
+func (m *mockServeIO) BeginTx(string) (core.FileTx, error) {
+	return beginMemTx(m)
+}
+
+func (m *mockServeIO) ReadBinder(ctx context.Context, path string) ([]byte, error) {
+	m.reads++
+	return m.mockBrowseIO.ReadBinder(ctx, path)
+}
+
+func (m *mockServeIO) Audit(ctx context.Context, binderPath string) ([]node.AuditDiagnostic, error) {
+	if m.auditErr != nil {
+		return nil, m.auditErr
+	}
+	return m.mockBrowseIO.Audit(ctx, binderPath)
+}
+
+func (m *mockServeIO) Watch(_ context.Context, _ string, _, window time.Duration, changed func([]string)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.changed, m.window = changed, window
+	return nil
+}
+
+func (m *mockServeIO) Listen(path string) (net.Listener, error) {
+	if m.listenErr != nil {
+		return nil, m.listenErr
+	}
+	ln, err := net.Listen("unix", path)
+	if err == nil && m.closed {
+		err = ln.Close()
+	}
+	return ln, err
+}
+
+func newServeMock() *mockServeIO {
+	return &mockServeIO{mockBrowseIO: mockBrowseIO{mockMoveIO: mockMoveIO{binderBytes: moveBinder()}}}
+}
+
+// runServe runs pmk serve over stdin and stdout with one request per line,
+// returning the response lines.
+func runServe(t *testing.T, mock *mockServeIO, requests ...string) []string {
+	t.Helper()
+	c := NewServeCmd(mock)
+	c.SetIn(strings.NewReader(strings.Join(requests, "\n") + "\n"))
+	out, err := runCmdInCWD(c, "--project", "/proj")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+}
+
+// serveResponse is a decoded JSON-RPC response.
+type serveResponse struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *jsonrpc.Error  `json:"error"`
+}
+
+func decodeServeResponse(t *testing.T, line string) serveResponse {
+	t.Helper()
+	var resp serveResponse
+	if err := json.Unmarshal([]byte(line), &resp); err != nil {
+		t.Fatalf("response %q: %v", line, err)
+	}
+	return resp
+}
+
+func TestServeCmd_Parse(t *testing.T) {
+	mock := newServeMock()
+	lines := runServe(t, mock,
+		`{"jsonrpc":"2.0","id":1,"method":"parse"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"parse","params":{}}`,
+	)
+	if len(lines) != 2 {
+		t.Fatalf("responses = %q, want 2", lines)
+	}
+	for _, line := range lines {
+		resp := decodeServeResponse(t, line)
+		var out parseOutput
+		if err := json.Unmarshal(resp.Result, &out); err != nil || resp.Error != nil {
+			t.Fatalf("response %q: %v", line, err)
+		}
+		if out.Version != "1" || out.Root == nil || len(out.Root.Children) != 2 {
+			t.Errorf("result = %s", resp.Result)
+		}
+	}
+	if mock.reads != 1 {
+		t.Errorf("binder read %d times, want 1", mock.reads)
+	}
+}
+
+func TestServeCmd_OpAndApply(t *testing.T) {
+	mock := newServeMock()
+	lines := runServe(t, mock,
+		`{"jsonrpc":"2.0","id":1,"method":"op","params":{"version":"1","operation":"add","params":{"parentSelector":".","target":"chapter-three.md","title":"Chapter Three"},"dryRun":true}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"op","params":{"version":"1","operation":"delete","params":{"selector":"chapter-two.md","yes":true}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"op","params":{"version":"1","operation":"move","params":{"sourceSelector":"chapter-one.md","destinationParentSelector":".","position":"last","yes":true}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"apply","params":{"operations":[{"version":"1","operation":"add","params":{"parentSelector":".","target":"chapter-four.md"}}]}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"parse"}`,
+	)
+	for i, line := range lines[:4] {
+		resp := decodeServeResponse(t, line)
+		if resp.Error != nil {
+			t.Fatalf("request %d: %v", i+1, resp.Error)
+		}
+	}
+	var dry binder.OpResult
+	if err := json.Unmarshal(decodeServeResponse(t, lines[0]).Result, &dry); err != nil || !strings.Contains(dry.Diff, "+- [Chapter Three](chapter-three.md)") {
+		t.Errorf("dry run result = %s", lines[0])
+	}
+	want := "<!-- prosemark-binder:v1 -->\n- [Chapter One](chapter-one.md)\n- [chapter-four](chapter-four.md)\n"
+	if string(mock.binderBytes) != want {
+		t.Errorf("binder = %q, want %q", mock.binderBytes, want)
+	}
+	if !strings.Contains(lines[4], "chapter-four.md") {
+		t.Errorf("parse after apply = %s", lines[4])
+	}
+	if mock.reads != 1 {
+		t.Errorf("binder read %d times, want 1", mock.reads)
+	}
+}
+
+func TestServeCmd_Doctor(t *testing.T) {
+	mock := newServeMock()
+	lines := runServe(t, mock, `{"jsonrpc":"2.0","id":1,"method":"doctor"}`)
+	var out doctorOutput
+	if err := json.Unmarshal(decodeServeResponse(t, lines[0]).Result, &out); err != nil || out.Version != "1" {
+		t.Errorf("doctor result = %s", lines[0])
+	}
+	if !mock.audited {
+		t.Error("doctor audit not run")
+	}
+}
+
+func TestServeCmd_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    func() *mockServeIO
+		request string
+		code    int
+	}{
+		{"unknown method", newServeMock, `{"jsonrpc":"2.0","id":1,"method":"frobnicate"}`, jsonrpc.CodeMethodNotFound},
+		{"bad parse params", newServeMock, `{"jsonrpc":"2.0","id":1,"method":"parse","params":[1]}`, jsonrpc.CodeInvalidParams},
+		{"bad op params", newServeMock, `{"jsonrpc":"2.0","id":1,"method":"op","params":[1]}`, jsonrpc.CodeInvalidParams},
+		{"unknown operation", newServeMock, `{"jsonrpc":"2.0","id":1,"method":"op","params":{"version":"1","operation":"frob","params":{}}}`, jsonrpc.CodeInvalidParams},
+		{"bad apply params", newServeMock, `{"jsonrpc":"2.0","id":1,"method":"apply","params":[1]}`, jsonrpc.CodeInvalidParams},
+		{"bad apply operation", newServeMock, `{"jsonrpc":"2.0","id":1,"method":"apply","params":{"operations":[{"version":"1","operation":"frob","params":{}}]}}`, jsonrpc.CodeInvalidParams},
+		{"parse read error", func() *mockServeIO { m := newServeMock(); m.binderErr = errors.New("denied"); return m }, `{"jsonrpc":"2.0","id":1,"method":"parse"}`, jsonrpc.CodeInternalError},
+		{"op write error", func() *mockServeIO { m := newServeMock(); m.writeErr = errors.New("disk full"); return m },
+			`{"jsonrpc":"2.0","id":1,"method":"op","params":{"version":"1","operation":"delete","params":{"selector":"chapter-two.md","yes":true}}}`, jsonrpc.CodeInternalError},
+		{"audit error", func() *mockServeIO { m := newServeMock(); m.auditErr = errors.New("denied"); return m }, `{"jsonrpc":"2.0","id":1,"method":"doctor"}`, jsonrpc.CodeInternalError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := decodeServeResponse(t, runServe(t, tt.mock(), tt.request)[0])
+			if resp.Error == nil || resp.Error.Code != tt.code {
+				t.Errorf("error = %+v, want code %d", resp.Error, tt.code)
+			}
+		})
+	}
+}
+
+func TestServeCmd_WriteErrorRereadsBinder(t *testing.T) {
+	mock := newServeMock()
+	mock.writeErr = errors.New("disk full")
+	runServe(t, mock,
+		`{"jsonrpc":"2.0","id":1,"method":"op","params":{"version":"1","operation":"delete","params":{"selector":"chapter-two.md","yes":true}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"parse"}`,
+	)
+	if mock.reads != 2 {
+		t.Errorf("binder read %d times, want 2", mock.reads)
+	}
+}
+
+func TestServeCmd_ShutdownStopsServing(t *testing.T) {
+	lines := runServe(t, newServeMock(),
+		`{"jsonrpc":"2.0","id":1,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"parse"}`,
+	)
+	if len(lines) != 1 || decodeServeResponse(t, lines[0]).ID != 1 {
+		t.Errorf("responses = %q, want only the shutdown reply", lines)
+	}
+}
+
+func TestServeServer_Invalidate(t *testing.T) {
+	mock := newServeMock()
+	s := newServeServer(mock, "/proj/_binder.md", func() {})
+	ctx := context.Background()
+	parse := func() {
+		t.Helper()
+		if _, err := s.handle(ctx, &jsonrpc.Request{Method: serveMethodParse}); err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+	}
+
+	parse()
+	s.invalidate([]string{"chapter-one.md"})
+	if s.src == nil || s.proj != nil || len(s.parsed) != 0 {
+		t.Errorf("after a node file change: src cached %v, project cached %v, parses cached %d", s.src != nil, s.proj != nil, len(s.parsed))
+	}
+	parse()
+	if mock.reads != 1 {
+		t.Errorf("binder read %d times after a node file change, want 1", mock.reads)
+	}
+	s.invalidate([]string{"_binder.md"})
+	parse()
+	if mock.reads != 2 {
+		t.Errorf("binder read %d times after a binder change, want 2", mock.reads)
+	}
+}
+
+func TestServeServer_ProjectScanError(t *testing.T) {
+	mock := newServeMock()
+	mock.projectErr = errors.New("denied")
+	s := newServeServer(mock, "/proj/_binder.md", func() {})
+	if _, err := s.ScanProject(context.Background(), "/proj/_binder.md"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestServeCmd_Socket(t *testing.T) {
+	mock := newServeMock()
+	socket := filepath.Join(t.TempDir(), "pmk.sock")
+	c := NewServeCmd(mock)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c.SetContext(ctx)
+	done := make(chan error, 1)
+	go func() {
+		_, err := runCmdInCWD(c, "--project", "/proj", "--socket", socket, "--debounce", "2s")
+		done <- err
+	}()
+
+	var conn net.Conn
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		var err error
+		if conn, err = net.Dial("unix", socket); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("dial: %v", err)
+		}
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	if _, err := conn.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"parse"}` + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	line, err := r.ReadString('\n')
+	if err != nil || decodeServeResponse(t, line).Error != nil {
+		t.Fatalf("parse response = %q, %v", line, err)
+	}
+
+	mock.mu.Lock()
+	changed, window := mock.changed, mock.window
+	mock.mu.Unlock()
+	if window != 2*time.Second {
+		t.Errorf("debounce window = %v, want 2s from --debounce", window)
+	}
+	changed([]string{"_binder.md"})
+	line, err = r.ReadString('\n')
+	if err != nil || !strings.Contains(line, `"method":"invalidated"`) || !strings.Contains(line, `"paths":["_binder.md"]`) {
+		t.Errorf("notification = %q, %v", line, err)
+	}
+
+	if _, err := conn.Write([]byte(`{"jsonrpc":"2.0","id":2,"method":"shutdown"}` + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("serve: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not stop after shutdown")
+	}
+}
+
+func TestServeCmd_ListenError(t *testing.T) {
+	mock := newServeMock()
+	mock.listenErr = errors.New("address in use")
+	_, err := runCmdInCWD(NewServeCmd(mock), "--project", "/proj", "--socket", "/tmp/x.sock")
+	if err == nil || !strings.Contains(err.Error(), "listening on /tmp/x.sock: address in use") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestServeCmd_AcceptError(t *testing.T) {
+	mock := newServeMock()
+	mock.closed = true
+	_, err := runCmdInCWD(NewServeCmd(mock), "--project", "/proj", "--socket", filepath.Join(t.TempDir(), "pmk.sock"))
+	if err == nil || !strings.Contains(err.Error(), "accepting connection: ") {
+		t.Errorf("error = %v, want the accept error", err)
+	}
+}
+
+func TestServeCmd_NonPositiveDebounce(t *testing.T) {
+	_, err := runCmdInCWD(NewServeCmd(newServeMock()), "--project", "/proj", "--debounce", "0s")
+	if err == nil || err.Error() != "--debounce must be positive" {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestServeCmd_GetwdError(t *testing.T) {
+	c := newServeCmdWithGetCWD(newServeMock(), func() (string, error) { return "", errors.New("getwd failed") })
+	if _, err := runCmdInCWD(c); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+		t.Errorf("error = %v, want getwd failure", err)
+	}
+}
+
+func TestFileServeIO(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	if err := os.WriteFile(binderPath, moveBinder(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	fio := &fileServeIO{}
+	if err := fio.WriteBinderAtomic(ctx, binderPath, moveBinder()); err != nil {
+		t.Fatalf("WriteBinderAtomic: %v", err)
+	}
+	if got, err := fio.ReadBinder(ctx, binderPath); err != nil || string(got) != string(moveBinder()) {
+		t.Errorf("ReadBinder = %q, %v", got, err)
+	}
+	if _, err := fio.ScanProject(ctx, binderPath); err != nil {
+		t.Errorf("ScanProject: %v", err)
+	}
+	if _, err := fio.Audit(ctx, binderPath); err != nil {
+		t.Errorf("Audit: %v", err)
+	}
+
+	wctx, cancel := context.WithCancel(ctx)
+	changed := make(chan []string, 1)
+	watched := make(chan error, 1)
+	go func() {
+		watched <- fio.Watch(wctx, dir, 10*time.Millisecond, 0, func(paths []string) { changed <- paths })
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(dir, "chapter-one.md"), []byte("Words.\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case paths := <-changed:
+		if strings.Join(paths, ",") != "chapter-one.md" {
+			t.Errorf("changed = %v", paths)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("no change reported")
+	}
+	cancel()
+	<-watched
+}
+
+func TestFileServeIO_Listen(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "pmk.sock")
+	fio := &fileServeIO{}
+	ln, err := fio.Listen(socket)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			c.Close()
+		}
+	}()
+	if _, err := fio.Listen(socket); err == nil || !strings.Contains(err.Error(), "already listening") {
+		t.Errorf("second Listen error = %v", err)
+	}
+
+	// A socket file left behind by a server that exited is replaced.
+	if ul, ok := ln.(*net.UnixListener); ok {
+		ul.SetUnlinkOnClose(false)
+	}
+	ln.Close()
+	stale, err := fio.Listen(socket)
+	if err != nil {
+		t.Fatalf("Listen over stale socket: %v", err)
+	}
+	stale.Close()
+}
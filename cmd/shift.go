@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/spf13/cobra"
+)
+
+// ShiftIO handles I/O for the promote and demote commands.
+type ShiftIO interface {
+	core.BinderIO
+}
+
+// NewPromoteCmd creates the promote subcommand, which moves a node up one
+// level to follow its parent.
+func NewPromoteCmd(io ShiftIO) *cobra.Command {
+	return newShiftCmdWithGetCWD(io, os.Getwd, true)
+}
+
+// NewDemoteCmd creates the demote subcommand, which moves a node down one
+// level under its preceding sibling.
+func NewDemoteCmd(io ShiftIO) *cobra.Command {
+	return newShiftCmdWithGetCWD(io, os.Getwd, false)
+}
+
+func newShiftCmdWithGetCWD(io ShiftIO, getwd func() (string, error), promote bool) *cobra.Command {
+	var jsonMode, forceParse, dryRun bool
+
+	use, short, verb, apply := "demote <selector>", "Move a node down one level, under its preceding sibling", "Demoted", core.Demote
+	if promote {
+		use, short, verb, apply = "promote <selector>", "Move a node up one level, after its parent", "Promoted", core.Promote
+	}
+
+	cmd := &cobra.Command{
+		Use:          use,
+		Short:        short,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			selector := args[0]
+
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+
+			if replayed, err := replayRecordedOp(cmd, io, binderPath, jsonMode); replayed || err != nil {
+				return err
+			}
+
+			params := binder.ShiftParams{Selector: selector, ForceParse: forceParse, DryRun: dryRun}
+			res, err := apply(cmd.Context(), io, binderPath, params)
+			if err := finishBinderOp(cmd, io, binderPath, jsonMode, res, err); err != nil {
+				return err
+			}
+
+			if !jsonMode && !dryRun {
+				if _, err := fmt.Fprintln(cmd.OutOrStdout(), verb+" "+sanitizePath(selector)+" in "+sanitizePath(binderPath)); err != nil {
+					return fmt.Errorf("writing output: %w", err)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+	addForceParseFlag(cmd, &forceParse)
+	addDryRunFlag(cmd, &dryRun)
+	addDiffFlag(cmd)
+	addOpIDFlag(cmd)
+
+	return cmd
+}
+
+// fileShiftIO implements ShiftIO using OS file I/O.
+type fileShiftIO struct {
+	fileProjectIO
+	binderLocker
+	opJournaler
+}
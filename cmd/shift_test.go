@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func shiftBinder(items ...string) []byte {
+	return []byte("<!-- prosemark-binder:v1 -->\n" + strings.Join(items, "\n") + "\n")
+}
+
+func TestNewShiftCmds_MoveOneLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		newCmd  func(ShiftIO) *cobra.Command
+		src     []byte
+		want    []byte
+		wantOut string
+	}{
+		{
+			"promote follows the parent", NewPromoteCmd,
+			shiftBinder("- [Part](part.md)", "  - [Chapter One](chapter-one.md)"),
+			shiftBinder("- [Part](part.md)", "- [Chapter One](chapter-one.md)"),
+			"Promoted chapter-one",
+		},
+		{
+			"demote nests under the preceding sibling", NewDemoteCmd,
+			shiftBinder("- [Part](part.md)", "- [Chapter One](chapter-one.md)"),
+			shiftBinder("- [Part](part.md)", "  - [Chapter One](chapter-one.md)"),
+			"Demoted chapter-one",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockCheckIO{binderBytes: tt.src}
+			c := tt.newCmd(mock)
+			out := new(bytes.Buffer)
+			c.SetOut(out)
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs([]string{"chapter-one", "--project", "."})
+
+			if err := c.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(mock.writtenBytes) != string(tt.want) {
+				t.Errorf("written = %q, want %q", mock.writtenBytes, tt.want)
+			}
+			if !strings.Contains(out.String(), tt.wantOut) {
+				t.Errorf("stdout = %q, want to contain %q", out.String(), tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestNewPromoteCmd_JSON(t *testing.T) {
+	mock := &mockCheckIO{binderBytes: shiftBinder("- [Part](part.md)", "  - [Chapter One](chapter-one.md)")}
+	c := NewPromoteCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetArgs([]string{"chapter-one", "--project", ".", "--json"})
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result binder.OpResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if !result.Changed || len(result.Entries) != 1 || result.Entries[0].Line != 3 {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestNewShiftCmds_Errors(t *testing.T) {
+	src := shiftBinder("- [Chapter One](chapter-one.md)")
+	tests := []struct {
+		name    string
+		newCmd  func(ShiftIO) *cobra.Command
+		mock    *mockCheckIO
+		args    []string
+		wantErr string
+	}{
+		{"promote at top level", NewPromoteCmd, &mockCheckIO{binderBytes: src}, []string{"chapter-one"}, "promote has errors"},
+		{"demote without a preceding sibling", NewDemoteCmd, &mockCheckIO{binderBytes: src}, []string{"chapter-one"}, "demote has errors"},
+		{"read binder error", NewDemoteCmd, &mockCheckIO{binderErr: errors.New("boom")}, []string{"chapter-one"}, "reading binder"},
+		{"missing selector argument", NewPromoteCmd, &mockCheckIO{}, nil, "accepts 1 arg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.newCmd(tt.mock)
+			c.SetOut(new(bytes.Buffer))
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(append(tt.args, "--project", "."))
+			err := c.Execute()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+			if tt.mock.writtenBytes != nil {
+				t.Errorf("binder written on error: %q", tt.mock.writtenBytes)
+			}
+		})
+	}
+}
+
+func TestNewShiftCmds_OutputError(t *testing.T) {
+	c := NewDemoteCmd(&mockCheckIO{binderBytes: shiftBinder("- [Part](part.md)", "- [Chapter One](chapter-one.md)")})
+	c.SetOut(&errWriter{err: errors.New("closed")})
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"chapter-one", "--project", "."})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "writing output") {
+		t.Errorf("error = %v, want output error", err)
+	}
+}
+
+func TestNewShiftCmds_GetwdError(t *testing.T) {
+	c := newShiftCmdWithGetCWD(&mockCheckIO{}, func() (string, error) {
+		return "", errors.New("getwd failed")
+	}, true)
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"chapter-one"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+		t.Errorf("error = %v, want getwd failure", err)
+	}
+}
+
+func TestNewShiftCmds_InvalidOpID(t *testing.T) {
+	c := NewPromoteCmd(&mockCheckIO{})
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"chapter-one", "--project", ".", "--op-id", "not-a-uuid"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "invalid --op-id") {
+		t.Errorf("error = %v, want invalid --op-id", err)
+	}
+}
+
+// Compile-time assertion: *fileShiftIO satisfies ShiftIO.
+var _ ShiftIO = (*fileShiftIO)(nil)
+
+func TestFileShiftIO_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	content := shiftBinder("- [Chapter One](chapter-one.md)")
+	if err := os.WriteFile(filepath.Join(dir, "chapter-one.md"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fio := &fileShiftIO{}
+	if err := fio.WriteBinderAtomic(context.Background(), binderPath, content); err != nil {
+		t.Fatalf("WriteBinderAtomic: %v", err)
+	}
+	if got, err := fio.ReadBinder(context.Background(), binderPath); err != nil || !bytes.Equal(got, content) {
+		t.Errorf("ReadBinder = %q, %v", got, err)
+	}
+	proj, err := fio.ScanProject(context.Background(), binderPath)
+	if err != nil || len(proj.Files) != 1 || proj.Files[0] != "chapter-one.md" {
+		t.Errorf("ScanProject = %+v, %v", proj, err)
+	}
+}
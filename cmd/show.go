@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+	"github.com/eykd/prosemark-go/internal/stats"
+)
+
+// ShowIO handles I/O for the show command.
+type ShowIO interface {
+	core.ParseIO
+	// ReadFile reads the project file at path as stored, for resolving
+	// @bookmarks and for the frontmatter of a node whose body is locked.
+	ReadFile(path string) ([]byte, error)
+	// ReadNodeFile reads the node file at path, unlocking a locked body.
+	ReadNodeFile(path string) ([]byte, error)
+}
+
+// headingJSON is the JSON output type for a heading of a node file.
+type headingJSON struct {
+	Text  string `json:"text"`
+	Level int    `json:"level"`
+	Line  int    `json:"line"`
+}
+
+// showNodeJSON is the JSON output type for a parent or child of the shown
+// node.
+type showNodeJSON struct {
+	Title  string `json:"title"`
+	Target string `json:"target,omitempty"`
+}
+
+// showOutput is the JSON output schema for show.
+type showOutput struct {
+	Version string `json:"version"`
+	Title   string `json:"title"`
+	Target  string `json:"target,omitempty"`
+	Type    string `json:"type"`
+	// Line is the 1-based binder line of the node's list item, and Path
+	// the 0-based child indices leading to it from the root.
+	Line int   `json:"line"`
+	Path []int `json:"path"`
+	// Parents lists the node's ancestors, outermost first.
+	Parents []showNodeJSON `json:"parents"`
+	// Frontmatter holds every field of the node file's frontmatter, and
+	// Words the words of its prose; both are absent when there is no node
+	// file, and Words when its body is locked.
+	Frontmatter map[string]any `json:"frontmatter,omitempty"`
+	Words       *int           `json:"words,omitempty"`
+	Children    []showNodeJSON `json:"children"`
+	Headings    []headingJSON  `json:"headings,omitempty"`
+}
+
+// NewShowCmd creates the show command.
+func NewShowCmd(io ShowIO) *cobra.Command {
+	return newShowCmdWithGetCWD(io, os.Getwd)
+}
+
+func newShowCmdWithGetCWD(io ShowIO, getwd func() (string, error)) *cobra.Command {
+	var headings, jsonMode bool
+	cmd := &cobra.Command{
+		Use:   "show <selector>",
+		Short: "Show the binder node a selector matches",
+		Long: "Show the binder node a selector matches.\n\n" +
+			"The selector is resolved as the binder operations resolve it and must\n" +
+			"match exactly one node. Its title, target, type, binder line and path (the\n" +
+			"child indices from the root), parent chain, the fields of its node file's\n" +
+			"frontmatter, its word count, and its children are printed; --json prints\n" +
+			"them as an object, for scripts and for checking what a selector matches.\n\n" +
+			"With --headings the Markdown headings of its node file are listed as\n" +
+			"file:line instead, so editors and scripts can navigate long chapters.",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			parsed, err := core.Parse(cmd.Context(), io, binderPath, core.ParseOptions{})
+			if err != nil {
+				return binderReadError(err, "")
+			}
+			if parsed.Err != nil {
+				return fmt.Errorf("cannot parse binder: %w", parsed.Err)
+			}
+			selector := args[0]
+			if err := resolveBookmarkSelectors(io.ReadFile, filepath.Dir(binderPath), &selector); err != nil {
+				return err
+			}
+			n, err := selectShowNode(parsed.Result.Root, selector)
+			if err != nil {
+				return err
+			}
+
+			out := showOutput{Version: "1", Title: n.Title, Target: n.Target, Type: n.Type, Line: n.Line, Parents: []showNodeJSON{}, Children: []showNodeJSON{}}
+			parents, path := nodeAncestry(parsed.Result.Root, n)
+			out.Path = path
+			for _, p := range parents {
+				out.Parents = append(out.Parents, showNodeJSON{Title: p.Title, Target: p.Target})
+			}
+			for _, c := range n.Children {
+				out.Children = append(out.Children, showNodeJSON{Title: c.Title, Target: c.Target})
+			}
+			if n.Target != "" && !headings {
+				if err := readShowNodeFile(io, filepath.Dir(binderPath), n.Target, &out); err != nil {
+					return err
+				}
+			}
+			if headings {
+				if n.Target == "" {
+					return fmt.Errorf("%q is a placeholder with no node file", args[0])
+				}
+				nodePath, err := safepath.Resolve(filepath.Dir(binderPath), n.Target)
+				if err != nil {
+					return err
+				}
+				content, err := io.ReadNodeFile(nodePath)
+				if err != nil {
+					if errors.Is(err, node.ErrLockedBody) {
+						return fmt.Errorf("%s: %w", n.Target, err)
+					}
+					return fmt.Errorf("reading node file: %w", err)
+				}
+				out.Headings = []headingJSON{}
+				for _, h := range node.ParseHeadings(content) {
+					out.Headings = append(out.Headings, headingJSON{Text: h.Text, Level: h.Level, Line: h.Line})
+				}
+			}
+
+			if jsonMode {
+				if err := encodeOutput(cmd, out); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+				return nil
+			}
+			w := cmd.OutOrStdout()
+			if !headings {
+				return writeShowText(w, out)
+			}
+			for _, h := range out.Headings {
+				fmt.Fprintf(w, "%s:%d %s %s\n", sanitizePath(n.Target), h.Line, strings.Repeat("#", h.Level), sanitizePath(h.Text))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().BoolVar(&headings, "headings", false, "list the headings of the node file")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "output JSON")
+	addFormatFlag(cmd)
+	return cmd
+}
+
+// readShowNodeFile sets out's frontmatter and word count from the node
+// file at target, relative to projectDir. A missing file sets neither, and
+// a locked body that cannot be unlocked only the frontmatter.
+func readShowNodeFile(io ShowIO, projectDir, target string, out *showOutput) error {
+	nodePath, err := safepath.Resolve(projectDir, target)
+	if err != nil {
+		return err
+	}
+	content, err := io.ReadNodeFile(nodePath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil
+	case errors.Is(err, node.ErrLockedBody):
+		if content, err = io.ReadFile(nodePath); err != nil {
+			return fmt.Errorf("reading node file: %w", err)
+		}
+		out.Frontmatter = node.FrontmatterFields(content)
+		return nil
+	case err != nil:
+		return fmt.Errorf("reading node file: %w", err)
+	}
+	out.Frontmatter = node.FrontmatterFields(content)
+	words := stats.CountNode(content).Words
+	out.Words = &words
+	return nil
+}
+
+// writeShowText writes out as "key: value" lines, "-" standing for an
+// empty value, with the frontmatter fields, in key order, and the children
+// indented below their headers.
+func writeShowText(w io.Writer, out showOutput) error {
+	var b strings.Builder
+	field := func(key, value string) {
+		fmt.Fprintf(&b, "%-9s %s\n", key+":", cmp.Or(value, "-"))
+	}
+	describe := func(n showNodeJSON) string {
+		if n.Target == "" {
+			return sanitizePath(n.Title) + " (placeholder)"
+		}
+		return sanitizePath(n.Title) + " (" + sanitizePath(n.Target) + ")"
+	}
+	field("title", sanitizePath(out.Title))
+	field("target", sanitizePath(out.Target))
+	field("type", out.Type)
+	field("line", fmt.Sprint(out.Line))
+	path := make([]string, len(out.Path))
+	for i, idx := range out.Path {
+		path[i] = fmt.Sprint(idx)
+	}
+	field("path", strings.Join(path, "."))
+	parents := make([]string, len(out.Parents))
+	for i, p := range out.Parents {
+		parents[i] = describe(p)
+	}
+	field("parents", strings.Join(parents, " > "))
+	if out.Words != nil {
+		field("words", fmt.Sprint(*out.Words))
+	}
+	if len(out.Frontmatter) > 0 {
+		b.WriteString("frontmatter:\n")
+		for _, key := range slices.Sorted(maps.Keys(out.Frontmatter)) {
+			fmt.Fprintf(&b, "  %s: %s\n", sanitizePath(key), sanitizePath(showValue(out.Frontmatter[key])))
+		}
+	}
+	fmt.Fprintf(&b, "children: %d\n", len(out.Children))
+	for _, c := range out.Children {
+		b.WriteString("  " + describe(c) + "\n")
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	return nil
+}
+
+// showValue formats a decoded frontmatter value for text output: times as
+// RFC3339, lists and mappings as JSON, and anything else as it prints.
+func showValue(v any) string {
+	switch v := v.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case []any, map[string]any:
+		data, err := json.Marshal(v)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// nodeAncestry returns the ancestors of n under root, outermost first and
+// root excluded, and the child indices leading from root to n.
+func nodeAncestry(root, n *binder.Node) ([]*binder.Node, []int) {
+	for i, c := range root.Children {
+		if c == n {
+			return nil, []int{i}
+		}
+		if parents, path := nodeAncestry(c, n); path != nil {
+			return append([]*binder.Node{c}, parents...), append([]int{i}, path...)
+		}
+	}
+	return nil, nil
+}
+
+// selectShowNode returns the one node selector matches under root.
+func selectShowNode(root *binder.Node, selector string) (*binder.Node, error) {
+	res, diags := binder.FindNodes(selector, root)
+	if len(diags) > 0 {
+		return nil, fmt.Errorf("%s (%s)", diags[0].Message, diags[0].Code)
+	}
+	if len(res.Nodes) > 1 {
+		return nil, fmt.Errorf("selector %q matched %d nodes; use a more specific selector", selector, len(res.Nodes))
+	}
+	if res.Nodes[0] == root {
+		return nil, fmt.Errorf("selector %q is the binder root, not a node", selector)
+	}
+	return res.Nodes[0], nil
+}
+
+// fileShowIO implements ShowIO using OS file I/O.
+type fileShowIO struct {
+	fileProjectIO
+}
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// mockShowIO is a test double for ShowIO.
+type mockShowIO struct {
+	binderBytes []byte
+	binderErr   error
+	files       map[string]string // by base name
+	nodeErr     error
+}
+
+func (m *mockShowIO) ReadBinder(_ context.Context, _ string) ([]byte, error) {
+	return m.binderBytes, m.binderErr
+}
+
+func (m *mockShowIO) ScanProject(_ context.Context, _ string) (*binder.Project, error) {
+	return &binder.Project{Files: []string{"ch1.md", "ch2.md"}, BinderDir: "."}, nil
+}
+
+func (m *mockShowIO) ReadFile(path string) ([]byte, error) {
+	if content, ok := m.files[filepath.Base(path)]; ok {
+		return []byte(content), nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func (m *mockShowIO) ReadNodeFile(path string) ([]byte, error) {
+	if m.nodeErr != nil {
+		return nil, m.nodeErr
+	}
+	return m.ReadFile(path)
+}
+
+func newShowTestIO() *mockShowIO {
+	return &mockShowIO{
+		binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [Chapter One](ch1.md)\n- [Chapter Two](ch2.md)\n- [Chapter Two](ch2.md)\n- [Later]()\n"),
+		files: map[string]string{
+			".prosemark.yml": "bookmarks:\n  one: ch1.md\n",
+			"ch1.md":         "---\ntitle: Chapter One\n---\n# Chapter One\n\n## The Storm\n",
+		},
+	}
+}
+
+func runShowCmd(t *testing.T, mock *mockShowIO, args ...string) (string, error) {
+	t.Helper()
+	c := NewShowCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(append(args, "--project", "/proj"))
+	err := c.Execute()
+	return out.String(), err
+}
+
+func TestShow(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{name: "node", args: []string{"Chapter One"}, want: "" +
+			"title:    Chapter One\n" +
+			"target:   ch1.md\n" +
+			"type:     node\n" +
+			"line:     2\n" +
+			"path:     0\n" +
+			"parents:  -\n" +
+			"words:    4\n" +
+			"frontmatter:\n" +
+			"  title: Chapter One\n" +
+			"children: 0\n"},
+		{name: "missing node file", args: []string{"ch2[0]", "--json"},
+			want: `{"version":"1","title":"Chapter Two","target":"ch2.md","type":"node","line":3,"path":[1],"parents":[],"children":[]}` + "\n"},
+		{name: "headings", args: []string{"@one", "--headings"}, want: "ch1.md:4 # Chapter One\nch1.md:6 ## The Storm\n"},
+		{name: "headings json", args: []string{"ch1", "--headings", "--json"},
+			want: `{"version":"1","title":"Chapter One","target":"ch1.md","type":"node","line":2,"path":[0],"parents":[],"children":[],"headings":[{"text":"Chapter One","level":1,"line":4},{"text":"The Storm","level":2,"line":6}]}` + "\n"},
+		{name: "yaml", args: []string{"ch2[0]", "--format", "yaml"},
+			want: "version: \"1\"\ntitle: Chapter Two\ntarget: ch2.md\ntype: node\nline: 3\npath:\n  - 1\nparents: []\nchildren: []\n"},
+		{name: "unknown format", args: []string{"ch1", "--format", "toml"}, wantErr: `unknown --format "toml"`},
+		{name: "no match", args: []string{"Chapter Nine"}, wantErr: "OPE001"},
+		{name: "several matches", args: []string{"ch2"}, wantErr: "matched 2 nodes"},
+		{name: "root", args: []string{"."}, wantErr: "binder root"},
+		{name: "placeholder headings", args: []string{"Later", "--headings"}, wantErr: "placeholder"},
+		{name: "missing file", args: []string{"ch2[0]", "--headings"}, wantErr: "reading node file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := runShowCmd(t, newShowTestIO(), tt.args...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out != tt.want {
+				t.Errorf("output = %q, want %q", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestShow_Nested(t *testing.T) {
+	mock := newShowTestIO()
+	mock.binderBytes = []byte("<!-- prosemark-binder:v1 -->\n- [Part One](ch1.md)\n  - [Draft]()\n    - [x] [Scene](scene.md)\n    - [Note]()\n")
+	mock.files["scene.md"] = "---\nid: scene\ntags: [storm, night]\ncreated: 2026-03-04T17:05:00Z\n---\nRain fell.\n"
+	out, err := runShowCmd(t, mock, "Scene")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "" +
+		"title:    Scene\n" +
+		"target:   scene.md\n" +
+		"type:     node\n" +
+		"line:     4\n" +
+		"path:     0.0.0\n" +
+		"parents:  Part One (ch1.md) > Draft (placeholder)\n" +
+		"words:    2\n" +
+		"frontmatter:\n" +
+		"  created: 2026-03-04T17:05:00Z\n" +
+		"  id: scene\n" +
+		"  tags: [\"storm\",\"night\"]\n" +
+		"children: 0\n"
+	if out != want {
+		t.Errorf("output =\n%s\nwant\n%s", out, want)
+	}
+
+	out, err = runShowCmd(t, mock, "Draft")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(out, "children: 2\n  Scene (scene.md)\n  Note (placeholder)\n") {
+		t.Errorf("output =\n%s\nwant the children listed", out)
+	}
+
+	out, err = runShowCmd(t, mock, "Draft", "--json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `{"version":"1","title":"Draft","type":"node","line":3,"path":[0,0],"parents":[{"title":"Part One","target":"ch1.md"}],"children":[{"title":"Scene","target":"scene.md"},{"title":"Note"}]}` + "\n"; out != want {
+		t.Errorf("JSON = %s, want %s", out, want)
+	}
+}
+
+func TestShow_LockedNodeMetadata(t *testing.T) {
+	mock := newShowTestIO()
+	mock.nodeErr = node.ErrLockedBody
+	out, err := runShowCmd(t, mock, "ch1", "--json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, `"frontmatter":{"title":"Chapter One"}`) || strings.Contains(out, `"words"`) {
+		t.Errorf("JSON = %s, want the frontmatter without a word count", out)
+	}
+
+	mock.nodeErr = errors.New("denied")
+	if _, err := runShowCmd(t, mock, "ch1"); err == nil || !strings.Contains(err.Error(), "reading node file: denied") {
+		t.Errorf("err = %v, want the read failure", err)
+	}
+
+	mock.nodeErr = node.ErrLockedBody
+	if _, err := runShowCmd(t, mock, "ch2[0]"); err == nil || !strings.Contains(err.Error(), "reading node file") {
+		t.Errorf("err = %v, want the failure to read the locked file's frontmatter", err)
+	}
+}
+
+func TestShow_LockedNode(t *testing.T) {
+	mock := newShowTestIO()
+	mock.nodeErr = node.ErrLockedBody
+	if _, err := runShowCmd(t, mock, "ch1", "--headings"); err == nil || !strings.Contains(err.Error(), "ch1.md") {
+		t.Errorf("err = %v, want the locked file named", err)
+	}
+}
+
+func TestShow_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(m *mockShowIO)
+		args    []string
+		wantErr string
+	}{
+		{name: "unreadable binder", setup: func(m *mockShowIO) { m.binderErr = errors.New("disk error") },
+			args: []string{"ch1"}, wantErr: "disk error"},
+		{name: "missing binder", setup: func(m *mockShowIO) { m.binderErr = os.ErrNotExist },
+			args: []string{"ch1"}, wantErr: "project not initialized"},
+		{name: "invalid binder", setup: func(m *mockShowIO) { m.binderBytes = []byte("\xff") },
+			args: []string{"ch1"}, wantErr: "cannot parse binder"},
+		{name: "unknown bookmark", args: []string{"@nope"}, wantErr: "nope"},
+		{name: "escaping target", setup: func(m *mockShowIO) {
+			m.binderBytes = []byte("<!-- prosemark-binder:v1 -->\n- [Out](/out.md)\n")
+		}, args: []string{"Out"}, wantErr: "escapes"},
+		{name: "escaping target headings", setup: func(m *mockShowIO) {
+			m.binderBytes = []byte("<!-- prosemark-binder:v1 -->\n- [Out](/out.md)\n")
+		}, args: []string{"Out", "--headings"}, wantErr: "escapes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newShowTestIO()
+			if tt.setup != nil {
+				tt.setup(mock)
+			}
+			if _, err := runShowCmd(t, mock, tt.args...); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShow_OutputErrors(t *testing.T) {
+	for _, args := range [][]string{{"ch1"}, {"ch1", "--json"}} {
+		c := NewShowCmd(newShowTestIO())
+		c.SetOut(&errWriter{err: errors.New("broken pipe")})
+		c.SetErr(new(bytes.Buffer))
+		c.SetArgs(append(args, "--project", "/proj"))
+		if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "broken pipe") {
+			t.Errorf("show %v: err = %v, want the write failure", args, err)
+		}
+	}
+}
+
+func TestShow_GetwdError(t *testing.T) {
+	c := newShowCmdWithGetCWD(newShowTestIO(), func() (string, error) { return "", errors.New("getwd failed") })
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"ch1"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+		t.Errorf("err = %v, want the getwd failure", err)
+	}
+}
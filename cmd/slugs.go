@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// slugMapFilename is the slug map's path relative to the project directory.
+const slugMapFilename = ".prosemark/slugs.json"
+
+// slugMap pins the slug each node was given, so deep links into exports
+// survive retitling.
+type slugMap struct {
+	Version string `json:"version"`
+	// Slugs maps binder target paths to slugs.
+	Slugs map[string]string `json:"slugs"`
+}
+
+// SlugsIO handles I/O for the slugs command.
+type SlugsIO interface {
+	ReadBinder(ctx context.Context, path string) ([]byte, error)
+	// ReadNodeFile reads the node file at path as stored; only its
+	// frontmatter is used, which stays readable in locked nodes.
+	ReadNodeFile(path string) ([]byte, error)
+	// ReadSlugMap returns the raw slug map for projectDir, or nil when there
+	// is none.
+	ReadSlugMap(projectDir string) ([]byte, error)
+	// WriteSlugMap replaces the slug map for projectDir.
+	WriteSlugMap(projectDir string, data []byte) error
+}
+
+// slugJSON is the JSON output type for one node's slug.
+type slugJSON struct {
+	Target string `json:"target"`
+	Title  string `json:"title"`
+	Slug   string `json:"slug"`
+}
+
+// slugsOutput is the JSON output schema for slugs.
+type slugsOutput struct {
+	Version string     `json:"version"`
+	Slugs   []slugJSON `json:"slugs"`
+}
+
+// NewSlugsCmd creates the slugs subcommand.
+func NewSlugsCmd(io SlugsIO) *cobra.Command {
+	return newSlugsCmdWithGetCWD(io, os.Getwd)
+}
+
+func newSlugsCmdWithGetCWD(io SlugsIO, getwd func() (string, error)) *cobra.Command {
+	var (
+		dryRun   bool
+		jsonMode bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "slugs",
+		Short: "Assign stable slugs to binder nodes for export filenames and deep links",
+		Long: "Assign every binder node a slug for export filenames and deep links, and\n" +
+			"pin it in " + slugMapFilename + ".\n\n" +
+			"A node's slug comes from its \"slug:\" frontmatter key when set; otherwise\n" +
+			"the slug it was pinned to earlier is kept, so retitling a chapter does not\n" +
+			"break links already published; otherwise it is derived from the title.\n" +
+			"Colliding slugs get a numeric suffix (-2, -3, ...).",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			binderBytes, err := io.ReadBinder(cmd.Context(), binderPath)
+			if err != nil {
+				return fmt.Errorf("reading binder: %w", err)
+			}
+			result, _, err := binder.Parse(cmd.Context(), binderBytes, nil)
+			if err != nil {
+				return fmt.Errorf("cannot parse binder: %w", err)
+			}
+			projectDir := filepath.Dir(binderPath)
+			prev, err := loadSlugMap(io, projectDir)
+			if err != nil {
+				return err
+			}
+
+			entries, slugs, err := assignSlugs(io.ReadNodeFile, projectDir, result.Root, prev.Slugs)
+			if err != nil {
+				return err
+			}
+
+			next := slugMap{Version: "1", Slugs: slugs}
+			if !dryRun {
+				if err := writeSlugMapIfChanged(io, projectDir, prev, next); err != nil {
+					return err
+				}
+			}
+
+			out := slugsOutput{Version: "1", Slugs: make([]slugJSON, 0, len(entries))}
+			for _, e := range entries {
+				out.Slugs = append(out.Slugs, slugJSON{Target: e.Key, Title: e.Title, Slug: next.Slugs[e.Key]})
+			}
+			if jsonMode {
+				if err := encodeOutput(cmd, out); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+				return nil
+			}
+			var buf bytes.Buffer
+			for _, s := range out.Slugs {
+				fmt.Fprintf(&buf, "%s\t%s\n", s.Slug, sanitizePath(s.Target))
+			}
+			if _, err := cmd.OutOrStdout().Write(buf.Bytes()); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the slugs without pinning them")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+	return cmd
+}
+
+// assignSlugs returns the slug of each node linked under root, keyed by
+// target, as pmk slugs pins them: the "slug:" frontmatter of its node file,
+// read with readNodeFile from projectDir, else its slug in prev, else one
+// derived from its title. A target outside projectDir has no file to read.
+// It also returns the nodes' entries, in binder order.
+func assignSlugs(readNodeFile func(path string) ([]byte, error), projectDir string, root *binder.Node, prev map[string]string) ([]node.SlugEntry, map[string]string, error) {
+	nodes := binderTargetNodes(root)
+	entries := make([]node.SlugEntry, 0, len(nodes))
+	for _, n := range nodes {
+		entry := node.SlugEntry{Key: n.Target, Title: exportTitle(n)}
+		if nodePath, err := safepath.Resolve(projectDir, n.Target); err == nil {
+			content, err := readNodeFile(nodePath)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, nil, fmt.Errorf("reading %s: %w", sanitizePath(n.Target), err)
+			}
+			entry.Override = node.FrontmatterSlug(content)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, node.AssignSlugs(entries, prev), nil
+}
+
+// loadSlugMap reads the slug map for projectDir. A missing map is empty; a
+// malformed one is an error, since silently reassigning slugs would break
+// published links.
+func loadSlugMap(io interface {
+	ReadSlugMap(projectDir string) ([]byte, error)
+}, projectDir string) (slugMap, error) {
+	m := slugMap{Version: "1"}
+	data, err := io.ReadSlugMap(projectDir)
+	if err != nil {
+		return m, fmt.Errorf("reading slug map: %w", err)
+	}
+	if data != nil {
+		if err := json.Unmarshal(data, &m); err != nil {
+			return m, fmt.Errorf("cannot decode %s: %w", slugMapFilename, err)
+		}
+	}
+	return m, nil
+}
+
+// writeSlugMapIfChanged writes next as the slug map for projectDir unless it
+// matches prev, so an unchanged project leaves .prosemark untouched.
+func writeSlugMapIfChanged(io interface {
+	WriteSlugMap(projectDir string, data []byte) error
+}, projectDir string, prev, next slugMap) error {
+	// Encoding strings and string maps cannot fail.
+	data, _ := json.MarshalIndent(next, "", "  ")
+	if prevData, _ := json.MarshalIndent(prev, "", "  "); bytes.Equal(prevData, data) {
+		return nil
+	}
+	if err := io.WriteSlugMap(projectDir, append(data, '\n')); err != nil {
+		return fmt.Errorf("writing slug map: %w", err)
+	}
+	return nil
+}
+
+// fileSlugsIO implements SlugsIO using OS file I/O.
+type fileSlugsIO struct {
+	fileProjectIO
+}
+
+// ReadNodeFile reads the node file at path without unlocking it.
+func (f fileSlugsIO) ReadNodeFile(path string) ([]byte, error) {
+	return fsio.ReadFile(path)
+}
+
+// ReadSlugMap reads the project's slug map, returning nil when absent.
+func (f fileSlugsIO) ReadSlugMap(projectDir string) ([]byte, error) {
+	data, _, err := fsio.ReadFileIfExists(filepath.Join(projectDir, filepath.FromSlash(slugMapFilename)))
+	return data, err
+}
+
+// WriteSlugMap writes the project's slug map atomically.
+func (f fileSlugsIO) WriteSlugMap(projectDir string, data []byte) error {
+	return fsio.WriteFileAtomicMkdir(filepath.Join(projectDir, filepath.FromSlash(slugMapFilename)), ".slugs", data)
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mockSlugsIO is a test double for SlugsIO.
+type mockSlugsIO struct {
+	binderBytes []byte
+	binderErr   error
+	files       map[string]string
+	fileErr     error
+	slugMap     []byte
+	mapErr      error
+	mapWriteErr error
+	mapWritten  []byte
+}
+
+func (m *mockSlugsIO) ReadBinder(_ context.Context, _ string) ([]byte, error) {
+	return m.binderBytes, m.binderErr
+}
+
+func (m *mockSlugsIO) ReadNodeFile(path string) ([]byte, error) {
+	if m.fileErr != nil {
+		return nil, m.fileErr
+	}
+	content, ok := m.files[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return []byte(content), nil
+}
+
+func (m *mockSlugsIO) ReadSlugMap(_ string) ([]byte, error) {
+	return m.slugMap, m.mapErr
+}
+
+func (m *mockSlugsIO) WriteSlugMap(_ string, data []byte) error {
+	m.mapWritten = data
+	return m.mapWriteErr
+}
+
+func newSlugsMock() *mockSlugsIO {
+	return &mockSlugsIO{
+		binderBytes: []byte("<!-- prosemark-binder:v1 -->\n" +
+			"- [Opening](one.md)\n" +
+			"  - [Opening](two.md)\n" +
+			"- [Renamed Chapter](three.md)\n"),
+		files: map[string]string{
+			"/proj/two.md": "---\nid: two\nslug: First Light\n---\nBody.\n",
+		},
+		slugMap: []byte(`{"version":"1","slugs":{"three.md":"old-chapter"}}`),
+	}
+}
+
+func runSlugsCmd(t *testing.T, mock *mockSlugsIO, args ...string) (string, error) {
+	t.Helper()
+	c := NewSlugsCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(append(args, "--project", "/proj"))
+	err := c.Execute()
+	return out.String(), err
+}
+
+func TestSlugsCmd(t *testing.T) {
+	mock := newSlugsMock()
+	out, err := runSlugsCmd(t, mock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "opening\tone.md\nfirst-light\ttwo.md\nold-chapter\tthree.md\n"; out != want {
+		t.Errorf("stdout = %q, want %q", out, want)
+	}
+	var got slugMap
+	if err := json.Unmarshal(mock.mapWritten, &got); err != nil {
+		t.Fatalf("slug map %q: %v", mock.mapWritten, err)
+	}
+	if len(got.Slugs) != 3 || got.Slugs["two.md"] != "first-light" || got.Version != "1" {
+		t.Errorf("slug map = %s", mock.mapWritten)
+	}
+
+	// A second run with the pinned map leaves it untouched.
+	mock.slugMap, mock.mapWritten = mock.mapWritten, nil
+	if _, err := runSlugsCmd(t, mock); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mock.mapWritten != nil {
+		t.Errorf("unchanged slug map rewritten: %s", mock.mapWritten)
+	}
+}
+
+func TestSlugsCmd_DryRunJSON(t *testing.T) {
+	mock := newSlugsMock()
+	out, err := runSlugsCmd(t, mock, "--dry-run", "--json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got slugsOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if len(got.Slugs) != 3 || got.Slugs[2] != (slugJSON{Target: "three.md", Title: "Renamed Chapter", Slug: "old-chapter"}) {
+		t.Errorf("output = %+v", got)
+	}
+	if mock.mapWritten != nil {
+		t.Errorf("dry run wrote slug map: %s", mock.mapWritten)
+	}
+}
+
+func TestSlugsCmd_EscapingTarget(t *testing.T) {
+	mock := &mockSlugsIO{
+		binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [Outside](a/../../outside.md)\n"),
+		fileErr:     errors.New("read outside the project"),
+	}
+	out, err := runSlugsCmd(t, mock, "--dry-run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "outside\ta/../../outside.md\n"; out != want {
+		t.Errorf("stdout = %q, want the title slug without reading the file", out)
+	}
+}
+
+func TestSlugsCmd_Errors(t *testing.T) {
+	withMock := func(edit func(m *mockSlugsIO)) *mockSlugsIO {
+		m := newSlugsMock()
+		edit(m)
+		return m
+	}
+	tests := []struct {
+		name    string
+		mock    *mockSlugsIO
+		wantErr string
+	}{
+		{"binder read", &mockSlugsIO{binderErr: errors.New("denied")}, "reading binder: denied"},
+		{"invalid binder", &mockSlugsIO{binderBytes: []byte("- [Bad](b\xff.md)\n")}, "cannot parse binder"},
+		{"slug map read", withMock(func(m *mockSlugsIO) { m.mapErr = errors.New("denied") }), "reading slug map: denied"},
+		{"malformed slug map", withMock(func(m *mockSlugsIO) { m.slugMap = []byte("{") }), "cannot decode .prosemark/slugs.json"},
+		{"node read", withMock(func(m *mockSlugsIO) { m.fileErr = errors.New("denied") }), "reading one.md: denied"},
+		{"slug map write", withMock(func(m *mockSlugsIO) { m.mapWriteErr = errors.New("full") }), "writing slug map: full"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := runSlugsCmd(t, tt.mock); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSlugsCmd_OutputErrors(t *testing.T) {
+	for _, args := range [][]string{nil, {"--json"}} {
+		c := NewSlugsCmd(newSlugsMock())
+		c.SetOut(&errWriter{err: errors.New("closed")})
+		c.SetErr(new(bytes.Buffer))
+		c.SetArgs(append(args, "--project", "/proj", "--dry-run"))
+		if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "closed") {
+			t.Errorf("%v: error = %v, want output failure", args, err)
+		}
+	}
+}
+
+func TestSlugsCmd_GetwdError(t *testing.T) {
+	c := newSlugsCmdWithGetCWD(newSlugsMock(), func() (string, error) {
+		return "", errors.New("getwd failed")
+	})
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+		t.Errorf("error = %v, want getwd failure", err)
+	}
+}
+
+func TestFileSlugsIO(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	if err := os.WriteFile(binderPath, newSlugsMock().binderBytes, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fio := fileSlugsIO{}
+	if _, err := fio.ReadBinder(context.Background(), binderPath); err != nil {
+		t.Errorf("ReadBinder: %v", err)
+	}
+	if got, err := fio.ReadNodeFile(binderPath); err != nil || len(got) == 0 {
+		t.Errorf("ReadNodeFile = %q, %v", got, err)
+	}
+	if got, err := fio.ReadSlugMap(dir); err != nil || got != nil {
+		t.Errorf("ReadSlugMap before write = %q, %v", got, err)
+	}
+	if err := fio.WriteSlugMap(dir, []byte("{}\n")); err != nil {
+		t.Fatalf("WriteSlugMap: %v", err)
+	}
+	if got, err := fio.ReadSlugMap(dir); err != nil || string(got) != "{}\n" {
+		t.Errorf("ReadSlugMap = %q, %v", got, err)
+	}
+}
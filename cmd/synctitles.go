@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+// SyncTitlesIO handles I/O for the sync-titles command.
+type SyncTitlesIO interface {
+	core.SyncTitlesIO
+}
+
+// NewSyncTitlesCmd creates the sync-titles subcommand.
+func NewSyncTitlesCmd(io SyncTitlesIO) *cobra.Command {
+	return newSyncTitlesCmdWithGetCWD(io, os.Getwd)
+}
+
+func newSyncTitlesCmdWithGetCWD(sio SyncTitlesIO, getwd func() (string, error)) *cobra.Command {
+	var (
+		direction  string
+		dryRun     bool
+		jsonMode   bool
+		forceParse bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "sync-titles [--direction file-to-binder|binder-to-file]",
+		Short: "Sync binder titles with node file titles",
+		Long: "Bring entry titles in the binder and the frontmatter titles of their node\n" +
+			"files into line, and print a unified diff of the change. By default each\n" +
+			"entry's link text takes its node file's title, keeping the link's style:\n" +
+			"inline, wikilink alias, or reference. With --direction binder-to-file each\n" +
+			"node file's title takes the link text instead; titles taken from a\n" +
+			"filename are not pushed. Entries left out of sync get a PMKW013 warning.\n" +
+			"With --dry-run the diff is printed and nothing is written.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			switch direction {
+			case ops.SyncFileToBinder, ops.SyncBinderToFile:
+			default:
+				return fmt.Errorf("unknown --direction %q: want %s or %s", direction, ops.SyncFileToBinder, ops.SyncBinderToFile)
+			}
+
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+
+			params := binder.SyncTitlesParams{Direction: direction, ForceParse: forceParse, DryRun: dryRun}
+			res, err := core.SyncTitles(cmd.Context(), sio, binderPath, params)
+			if res == nil {
+				return finishBinderOp(cmd, sio, binderPath, jsonMode, nil, err)
+			}
+
+			if jsonMode {
+				out := *res
+				out.Diagnostics = reportedDiagnostics(cmd, res.Diagnostics)
+				if err := encodeOutput(cmd, out); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+			} else {
+				printDiagnostics(cmd, res.Diagnostics)
+			}
+			if hasDiagnosticError(res.Diagnostics) {
+				return fmt.Errorf("sync-titles has errors")
+			}
+			if err != nil {
+				return err
+			}
+
+			if !jsonMode {
+				synced, where := len(res.Entries), sanitizePath(binderPath)
+				if direction == ops.SyncBinderToFile {
+					synced, where = len(res.Files), "node files"
+				}
+				summary := fmt.Sprintf("Synced %d titles in %s\n", synced, where)
+				switch {
+				case synced == 0:
+					summary = "All titles are already in sync\n"
+				case dryRun:
+					summary = fmt.Sprintf("Would sync %d titles in %s\n", synced, where)
+				}
+				if _, err := io.WriteString(cmd.OutOrStdout(), res.Diff+summary); err != nil {
+					return fmt.Errorf("writing output: %w", err)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().StringVar(&direction, "direction", ops.SyncFileToBinder, "which side wins: file-to-binder or binder-to-file")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+	addForceParseFlag(cmd, &forceParse)
+	addDryRunFlag(cmd, &dryRun)
+	return cmd
+}
+
+// fileSyncTitlesIO implements SyncTitlesIO using OS file I/O. Node files
+// are read and written as stored, so locked bodies stay locked.
+type fileSyncTitlesIO struct {
+	fileProjectIO
+	binderLocker
+	fileTxer
+}
+
+// ReadNodeFile reads the node file at path as stored.
+func (f fileSyncTitlesIO) ReadNodeFile(path string) ([]byte, error) {
+	return fsio.ReadFile(path)
+}
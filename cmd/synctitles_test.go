@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/core"
+)
+
+// syncTitlesProject writes a project whose binder titles disagree with its
+// node files and returns its directory.
+func syncTitlesProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"_binder.md": "<!-- prosemark-binder:v1 -->\n- [one](ch1.md)\n- [[ch2|two]]\n",
+		"ch1.md":     "---\nid: ch1\ntitle: One\n---\nBody.\n",
+		"ch2.md":     "---\nid: ch2\n---\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func runSyncTitlesCmd(t *testing.T, dir string, args ...string) (string, string, error) {
+	t.Helper()
+	c := NewSyncTitlesCmd(fileSyncTitlesIO{})
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(errOut)
+	c.SetArgs(append(args, "--project", dir))
+	err := c.Execute()
+	return out.String(), errOut.String(), err
+}
+
+func readProjectFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestNewSyncTitlesCmd_FileToBinder(t *testing.T) {
+	dir := syncTitlesProject(t)
+	out, errOut, err := runSyncTitlesCmd(t, dir, "--dry-run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "+- [One](ch1.md)") || !strings.HasSuffix(out, "Would sync 1 titles in "+filepath.Join(dir, "_binder.md")+"\n") {
+		t.Errorf("stdout = %q", out)
+	}
+	if !strings.Contains(errOut, "ch2.md has no frontmatter title (PMKW013)") {
+		t.Errorf("stderr = %q, want PMKW013 for ch2.md", errOut)
+	}
+	if got := readProjectFile(t, dir, "_binder.md"); strings.Contains(got, "[One]") {
+		t.Errorf("dry run wrote the binder: %q", got)
+	}
+
+	if _, _, err := runSyncTitlesCmd(t, dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := readProjectFile(t, dir, "_binder.md"), "<!-- prosemark-binder:v1 -->\n- [One](ch1.md)\n- [[ch2|two]]\n"; got != want {
+		t.Errorf("binder = %q, want %q", got, want)
+	}
+	if out, _, _ := runSyncTitlesCmd(t, dir); out != "All titles are already in sync\n" {
+		t.Errorf("second run stdout = %q", out)
+	}
+}
+
+func TestNewSyncTitlesCmd_BinderToFile(t *testing.T) {
+	dir := syncTitlesProject(t)
+	out, _, err := runSyncTitlesCmd(t, dir, "--direction", "binder-to-file", "--json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var res core.SyncTitlesResult
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if res.Changed || strings.Join(res.Files, ",") != "ch1.md,ch2.md" {
+		t.Errorf("result = %+v", res)
+	}
+	for name, want := range map[string]string{
+		"ch1.md": "---\nid: ch1\ntitle: one\n---\nBody.\n",
+		"ch2.md": "---\nid: ch2\ntitle: two\n---\n",
+	} {
+		if got := readProjectFile(t, dir, name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestNewSyncTitlesCmd_Errors(t *testing.T) {
+	dir := syncTitlesProject(t)
+	tests := []struct {
+		name    string
+		dir     string
+		args    []string
+		wantErr string
+	}{
+		{"unknown direction", dir, []string{"--direction", "both"}, `unknown --direction "both"`},
+		{"args", dir, []string{"extra"}, "unknown command"},
+		{"missing binder", t.TempDir(), nil, "reading binder"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := runSyncTitlesCmd(t, tt.dir, tt.args...); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewSyncTitlesCmd_BinderToFileText(t *testing.T) {
+	dir := syncTitlesProject(t)
+	out, _, err := runSyncTitlesCmd(t, dir, "--direction", "binder-to-file")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "+++ b/ch2.md") || !strings.HasSuffix(out, "Synced 2 titles in node files\n") {
+		t.Errorf("stdout = %q", out)
+	}
+}
+
+// txFailingSyncTitlesIO is fileSyncTitlesIO with transactions that cannot
+// begin.
+type txFailingSyncTitlesIO struct{ fileSyncTitlesIO }
+
+func (txFailingSyncTitlesIO) BeginTx(string) (core.FileTx, error) {
+	return nil, errors.New("journal unavailable")
+}
+
+func TestNewSyncTitlesCmd_Failures(t *testing.T) {
+	t.Run("parse errors", func(t *testing.T) {
+		dir := syncTitlesProject(t)
+		if err := os.WriteFile(filepath.Join(dir, "_binder.md"), []byte("<!-- prosemark-binder:v1 -->\n- [Bad](b:c.md)\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := runSyncTitlesCmd(t, dir); err == nil || !strings.Contains(err.Error(), "sync-titles has errors") {
+			t.Errorf("error = %v, want sync-titles has errors", err)
+		}
+	})
+	t.Run("transaction", func(t *testing.T) {
+		dir := syncTitlesProject(t)
+		c := NewSyncTitlesCmd(txFailingSyncTitlesIO{})
+		c.SetOut(new(bytes.Buffer))
+		c.SetErr(new(bytes.Buffer))
+		c.SetArgs([]string{"--direction", "binder-to-file", "--project", dir})
+		if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "journal unavailable") {
+			t.Errorf("error = %v, want the transaction failure", err)
+		}
+		if got := readProjectFile(t, dir, "ch1.md"); got != "---\nid: ch1\ntitle: One\n---\nBody.\n" {
+			t.Errorf("ch1.md = %q, want it untouched", got)
+		}
+	})
+	t.Run("getwd", func(t *testing.T) {
+		c := newSyncTitlesCmdWithGetCWD(fileSyncTitlesIO{}, func() (string, error) { return "", errors.New("getwd failed") })
+		c.SetOut(new(bytes.Buffer))
+		c.SetErr(new(bytes.Buffer))
+		if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+			t.Errorf("error = %v, want getwd failure", err)
+		}
+	})
+	for _, args := range [][]string{nil, {"--json"}} {
+		t.Run(fmt.Sprintf("output %v", args), func(t *testing.T) {
+			c := NewSyncTitlesCmd(fileSyncTitlesIO{})
+			c.SetOut(&errWriter{err: errors.New("closed")})
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(append(args, "--dry-run", "--project", syncTitlesProject(t)))
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "closed") {
+				t.Errorf("error = %v, want output failure", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// tableColumn is a column a tabular command can show under --columns.
+type tableColumn struct {
+	name string
+	// right aligns the column's cells to the right, as for numbers.
+	right bool
+}
+
+// parseColumns returns the columns named in spec, a comma-separated
+// --columns list, in the order given. Names must be among available and
+// appear once.
+func parseColumns(spec string, available []tableColumn) ([]tableColumn, error) {
+	names := make([]string, len(available))
+	for i, c := range available {
+		names[i] = c.name
+	}
+	var cols []tableColumn
+	var seen []string
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		i := slices.Index(names, name)
+		switch {
+		case name == "":
+			return nil, fmt.Errorf("--columns has an empty column name (want a comma-separated list of %s)", strings.Join(names, ", "))
+		case i < 0:
+			return nil, fmt.Errorf("unknown column %q (want %s)", name, strings.Join(names, ", "))
+		case slices.Contains(seen, name):
+			return nil, fmt.Errorf("column %q is listed more than once", name)
+		}
+		seen = append(seen, name)
+		cols = append(cols, available[i])
+	}
+	return cols, nil
+}
+
+// writeTable writes rows under a header of the columns' names, in capitals,
+// each column as wide as its widest cell and two spaces from the next. The
+// last column is not padded, so lines carry no trailing blanks. Empty cells
+// are shown as "-" so that every row has every field. With noHeader the
+// header is left out.
+func writeTable(w io.Writer, cols []tableColumn, rows [][]string, noHeader bool) error {
+	if !noHeader {
+		header := make([]string, len(cols))
+		for i, c := range cols {
+			header[i] = strings.ToUpper(c.name)
+		}
+		rows = append([][]string{header}, rows...)
+	}
+	widths := make([]int, len(cols))
+	for _, row := range rows {
+		for i, cell := range row {
+			if cell == "" {
+				row[i] = "-"
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(row[i]))
+		}
+	}
+	var b strings.Builder
+	for _, row := range rows {
+		for i, cell := range row {
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			switch {
+			case cols[i].right:
+				b.WriteString(pad + cell)
+			case i < len(row)-1:
+				b.WriteString(cell + pad)
+			default:
+				b.WriteString(cell)
+			}
+			if i < len(row)-1 {
+				b.WriteString("  ")
+			}
+		}
+		b.WriteString("\n")
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	return nil
+}
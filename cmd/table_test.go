@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteTable(t *testing.T) {
+	cols := []tableColumn{{name: "title"}, {name: "words", right: true}, {name: "target"}}
+	rows := [][]string{{"Épilogue", "12", "end.md"}, {"Act", "1234", ""}}
+	var b strings.Builder
+	if err := writeTable(&b, cols, rows, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "" +
+		"TITLE     WORDS  TARGET\n" +
+		"Épilogue     12  end.md\n" +
+		"Act        1234  -\n"
+	if b.String() != want {
+		t.Errorf("table =\n%s\nwant\n%s", b.String(), want)
+	}
+}
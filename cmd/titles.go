@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/core"
+)
+
+// TitlesIO handles I/O for the titles command.
+type TitlesIO interface {
+	core.BinderIO
+}
+
+// NewTitlesCmd creates the titles subcommand.
+func NewTitlesCmd(io TitlesIO) *cobra.Command {
+	return newTitlesCmdWithGetCWD(io, os.Getwd)
+}
+
+func newTitlesCmdWithGetCWD(tio TitlesIO, getwd func() (string, error)) *cobra.Command {
+	var (
+		titleCase  string
+		dryRun     bool
+		jsonMode   bool
+		forceParse bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "titles --case title|sentence [selector]",
+		Short: "Rewrite the casing of binder titles",
+		Long: "Rewrite the casing of entry titles throughout the binder, or in the entries\n" +
+			"the selector matches and their subtrees, and print a unified diff of the\n" +
+			"change. Title case capitalizes every word but short articles, conjunctions,\n" +
+			"and prepositions; sentence case capitalizes only the first word. Acronyms\n" +
+			"and words like iPhone keep their capitals. With --dry-run the diff is\n" +
+			"printed and nothing is written.",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch titleCase {
+			case ops.CaseTitle, ops.CaseSentence:
+			case "":
+				return fmt.Errorf("--case is required: want title or sentence")
+			default:
+				return fmt.Errorf("unknown --case %q: want title or sentence", titleCase)
+			}
+
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+
+			params := binder.TitlesParams{Case: titleCase, ForceParse: forceParse, DryRun: dryRun}
+			if len(args) > 0 {
+				params.Selector = args[0]
+			}
+			res, err := core.Titles(cmd.Context(), tio, binderPath, params)
+			if res == nil {
+				return finishBinderOp(cmd, tio, binderPath, jsonMode, nil, err)
+			}
+
+			if jsonMode {
+				out := *res
+				out.Diagnostics = reportedDiagnostics(cmd, res.Diagnostics)
+				if err := encodeOutput(cmd, out); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+			} else {
+				printDiagnostics(cmd, res.Diagnostics)
+			}
+			if hasDiagnosticError(res.Diagnostics) {
+				return fmt.Errorf("titles has errors")
+			}
+			if err != nil {
+				return err
+			}
+
+			if !jsonMode {
+				summary := fmt.Sprintf("Retitled %d entries in %s\n", len(res.Entries), sanitizePath(binderPath))
+				switch {
+				case !res.Changed:
+					summary = "All titles are already in " + titleCase + " case\n"
+				case dryRun:
+					summary = fmt.Sprintf("Would retitle %d entries in %s\n", len(res.Entries), sanitizePath(binderPath))
+				}
+				if _, err := io.WriteString(cmd.OutOrStdout(), res.Diff+summary); err != nil {
+					return fmt.Errorf("writing output: %w", err)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().StringVar(&titleCase, "case", "", "casing to apply: title or sentence")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output result as JSON")
+	addFormatFlag(cmd)
+	addForceParseFlag(cmd, &forceParse)
+	addDryRunFlag(cmd, &dryRun)
+	return cmd
+}
+
+// fileTitlesIO implements TitlesIO using OS file I/O.
+type fileTitlesIO struct {
+	fileProjectIO
+	binderLocker
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+var titlesTestBinder = checkBinder("- [chapter one](chapter-one.md)")
+
+const titlesTestDiff = "--- a/_binder.md\n+++ b/_binder.md\n@@ -1,2 +1,2 @@\n <!-- prosemark-binder:v1 -->\n-- [chapter one](chapter-one.md)\n+- [Chapter One](chapter-one.md)\n"
+
+func runTitlesCmd(t *testing.T, mock *mockCheckIO, args ...string) (string, error) {
+	t.Helper()
+	c := NewTitlesCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(append(args, "--project", "."))
+	err := c.Execute()
+	return out.String(), err
+}
+
+func TestNewTitlesCmd_RewritesAndPrintsDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       []byte
+		args      []string
+		wantOut   string
+		wantWrite bool
+	}{
+		{"write", titlesTestBinder, []string{"--case", "title"}, titlesTestDiff + "Retitled 1 entries in _binder.md\n", true},
+		{"dry run", titlesTestBinder, []string{"--case", "title", "--dry-run"}, titlesTestDiff + "Would retitle 1 entries in _binder.md\n", false},
+		{"selector", titlesTestBinder, []string{"chapter-one", "--case", "title"}, titlesTestDiff + "Retitled 1 entries in _binder.md\n", true},
+		{"nothing to do", checkBinder("- [Chapter one](chapter-one.md)"), []string{"--case", "sentence"}, "All titles are already in sentence case\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockCheckIO{binderBytes: tt.src}
+			out, err := runTitlesCmd(t, mock, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out != tt.wantOut {
+				t.Errorf("stdout = %q, want %q", out, tt.wantOut)
+			}
+			if (mock.writtenBytes != nil) != tt.wantWrite {
+				t.Errorf("written = %q, want write: %v", mock.writtenBytes, tt.wantWrite)
+			}
+		})
+	}
+}
+
+func TestNewTitlesCmd_JSON(t *testing.T) {
+	out, err := runTitlesCmd(t, &mockCheckIO{binderBytes: titlesTestBinder}, "--case", "title", "--dry-run", "--json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result binder.OpResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if !result.Changed || result.Diff != titlesTestDiff || len(result.Entries) != 1 || result.Entries[0].Title != "Chapter One" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestNewTitlesCmd_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    *mockCheckIO
+		args    []string
+		wantErr string
+	}{
+		{"missing case", &mockCheckIO{}, nil, "--case is required"},
+		{"unknown case", &mockCheckIO{}, []string{"--case", "upper"}, `unknown --case "upper"`},
+		{"selector no match", &mockCheckIO{binderBytes: titlesTestBinder}, []string{"missing", "--case", "title"}, "titles has errors"},
+		{"read binder error", &mockCheckIO{binderErr: errors.New("boom")}, []string{"--case", "title"}, "reading binder"},
+		{"scan project error", &mockCheckIO{binderBytes: titlesTestBinder, projectErr: errors.New("scan")}, []string{"--case", "title"}, "operation failed"},
+		{"write error", &mockCheckIO{binderBytes: titlesTestBinder, writeErr: errors.New("disk")}, []string{"--case", "title"}, "writing binder"},
+		{"too many args", &mockCheckIO{}, []string{"a", "b", "--case", "title"}, "accepts at most 1 arg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runTitlesCmd(t, tt.mock, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewTitlesCmd_OutputErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"json encode error", []string{"--case", "title", "--json"}, "encoding output"},
+		{"text write error", []string{"--case", "title"}, "writing output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewTitlesCmd(&mockCheckIO{binderBytes: titlesTestBinder})
+			c.SetOut(&errWriter{err: errors.New("closed")})
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(append(tt.args, "--project", "."))
+			if err := c.Execute(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewTitlesCmd_GetwdError(t *testing.T) {
+	c := newTitlesCmdWithGetCWD(&mockCheckIO{}, func() (string, error) {
+		return "", errors.New("getwd failed")
+	})
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--case", "title"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+		t.Errorf("error = %v, want getwd failure", err)
+	}
+}
+
+// Compile-time assertion: fileTitlesIO satisfies TitlesIO.
+var _ TitlesIO = fileTitlesIO{}
+
+func TestFileTitlesIO_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	if err := os.WriteFile(filepath.Join(dir, "chapter-one.md"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fio := fileTitlesIO{}
+	if err := fio.WriteBinderAtomic(context.Background(), binderPath, titlesTestBinder); err != nil {
+		t.Fatalf("WriteBinderAtomic: %v", err)
+	}
+	if got, err := fio.ReadBinder(context.Background(), binderPath); err != nil || !bytes.Equal(got, titlesTestBinder) {
+		t.Errorf("ReadBinder = %q, %v", got, err)
+	}
+	proj, err := fio.ScanProject(context.Background(), binderPath)
+	if err != nil || len(proj.Files) != 1 || proj.Files[0] != "chapter-one.md" {
+		t.Errorf("ScanProject = %+v, %v", proj, err)
+	}
+}
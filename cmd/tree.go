@@ -0,0 +1,563 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/diff"
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+	"github.com/eykd/prosemark-go/internal/stats"
+)
+
+// TreeIO handles I/O for the tree command.
+type TreeIO interface {
+	core.ParseIO
+	// ReadFile reads the file at path, for node frontmatter and the project
+	// config.
+	ReadFile(path string) ([]byte, error)
+	// ReadNodeFile reads the node file at path, unlocking a locked body
+	// when $PMK_KEY_FILE names its key.
+	ReadNodeFile(path string) ([]byte, error)
+	// AtRevision returns a TreeIO that reads the project as committed at
+	// the git revision rev.
+	AtRevision(rev string) TreeIO
+}
+
+// treeNodeJSON is the JSON output type for one node of the tree.
+type treeNodeJSON struct {
+	Type     string          `json:"type"`
+	NodeType string          `json:"node_type,omitempty"` // frontmatter type, e.g. "scene"
+	Title    string          `json:"title"`
+	Target   string          `json:"target,omitempty"`
+	Checked  *bool           `json:"checked,omitempty"` // GFM task state; absent without a checkbox
+	Depth    int             `json:"depth"`
+	Line     int             `json:"line"`
+	Created  string          `json:"created,omitempty"` // RFC3339 UTC, with --dates
+	Updated  string          `json:"updated,omitempty"` // RFC3339 UTC, with --dates
+	Children []*treeNodeJSON `json:"children"`
+}
+
+// treeOutput is the JSON output schema for tree.
+type treeOutput struct {
+	Version  string          `json:"version"`
+	Binder   string          `json:"binder"`
+	Revision string          `json:"revision,omitempty"`
+	Nodes    []*treeNodeJSON `json:"nodes"`
+}
+
+// NewTreeCmd creates the tree subcommand.
+func NewTreeCmd(io TreeIO) *cobra.Command {
+	return newTreeCmdWithGetCWD(io, os.Getwd)
+}
+
+func newTreeCmdWithGetCWD(io TreeIO, getwd func() (string, error)) *cobra.Command {
+	var (
+		jsonMode, diffMode, datesMode, noHeader bool
+		depth                                   int
+		rev, dateFormat, timezone, columnSpec   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "tree",
+		Short: "Print the binder's node hierarchy as a tree",
+		Long: "Print the binder's outline as an ASCII tree, one node per line with its\n" +
+			"title and target, after its task checkbox ([x] or [ ]) and the icon of its\n" +
+			"node type (the type: in its frontmatter; see types: in .prosemark.yml).\n" +
+			"--depth limits how many levels are shown. Use pmk parse for the binder's\n" +
+			"diagnostics. --repair-encoding shows a binder containing invalid UTF-8,\n" +
+			"reporting where it is and exiting non-zero.\n\n" +
+			"--rev shows the binder as committed at a git revision instead, read with\n" +
+			"git show without checking anything out; add --diff for a unified diff of\n" +
+			"the tree from that revision to the work tree.\n\n" +
+			"--dates adds each node's created and updated times, shown in the format\n" +
+			"and time zone set under dates: in .prosemark.yml (default: datetime in the\n" +
+			"local zone) or by --date-format and --timezone. Node files always store\n" +
+			"RFC3339 UTC, which --json reports unchanged.\n\n" +
+			"--columns prints a table instead, one row per node in outline order, with\n" +
+			"the columns named, in that order: title, target, type (the node type),\n" +
+			"label (the node type's label), status (done or todo from a task\n" +
+			"checkbox), words, comments (unresolved review comments), created,\n" +
+			"updated, depth, and line. Each column is as wide as its widest cell,\n" +
+			"empty cells show as -, and --no-header leaves out the header row, for\n" +
+			"sort, awk, and the like.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if depth < 0 {
+				return fmt.Errorf("--depth must not be negative")
+			}
+			if diffMode && rev == "" {
+				return fmt.Errorf("--diff needs --rev")
+			}
+			if diffMode && jsonMode {
+				return fmt.Errorf("--diff cannot be used with --json")
+			}
+			var cols []tableColumn
+			if columnSpec != "" {
+				switch {
+				case jsonMode:
+					return fmt.Errorf("--columns cannot be used with --json")
+				case diffMode:
+					return fmt.Errorf("--columns cannot be used with --diff")
+				case datesMode:
+					return fmt.Errorf("--columns cannot be used with --dates; add the created and updated columns instead")
+				}
+				var err error
+				if cols, err = parseColumns(columnSpec, treeColumns); err != nil {
+					return err
+				}
+			} else if noHeader {
+				return fmt.Errorf("--no-header needs --columns")
+			}
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			treeIO := io
+			if rev != "" {
+				treeIO = io.AtRevision(rev)
+			}
+			root, encodingErr, err := parseTree(cmd, treeIO, binderPath, rev)
+			if err != nil {
+				return err
+			}
+			name := filepath.Base(binderPath)
+			if cols != nil {
+				rows, err := treeTable(cmd, treeIO, filepath.Dir(binderPath), root, cols, depth, dateFormat, timezone)
+				if err != nil {
+					return err
+				}
+				return cmp.Or(writeTable(cmd.OutOrStdout(), cols, rows, noHeader), encodingErr)
+			}
+			// labels returns how to label the tree under root, read through
+			// tio, and its nodes' frontmatter.
+			labels := func(tio TreeIO, root *binder.Node) (func(*binder.Node) string, map[string]node.Frontmatter, error) {
+				projectDir := filepath.Dir(binderPath)
+				fms := readNodeFrontmatter(tio, projectDir, root)
+				types, err := readTreeNodeTypes(tio, projectDir, fms)
+				if err != nil {
+					return nil, nil, err
+				}
+				label := func(n *binder.Node) string { return taskMarker(n) + typeIcon(types, fms[n.Target].Type) + treeLabel(n) }
+				if !datesMode {
+					return label, fms, nil
+				}
+				format, err := treeDateFormat(cmd, tio, projectDir, dateFormat, timezone)
+				if err != nil {
+					return nil, nil, err
+				}
+				return func(n *binder.Node) string { return label(n) + datesLabel(fms[n.Target], format) }, fms, nil
+			}
+			label, fms, err := labels(treeIO, root)
+			if err != nil {
+				return err
+			}
+
+			if diffMode {
+				current, currentEncodingErr, err := parseTree(cmd, io, binderPath, "")
+				if err != nil {
+					return err
+				}
+				var from, to strings.Builder
+				currentLabel, _, err := labels(io, current)
+				if err != nil {
+					return err
+				}
+				writeTree(&from, root.Children, "", 1, depth, label)
+				writeTree(&to, current.Children, "", 1, depth, currentLabel)
+				d := diff.Unified("a/"+sanitizePath(name+"@"+rev), "b/"+sanitizePath(name), []byte(from.String()), []byte(to.String()))
+				if _, err := fmt.Fprint(cmd.OutOrStdout(), d); err != nil {
+					return fmt.Errorf("writing output: %w", err)
+				}
+				return cmp.Or(encodingErr, currentEncodingErr)
+			}
+
+			if jsonMode {
+				out := treeOutput{Version: "1", Binder: name, Revision: rev, Nodes: treeJSON(root.Children, 1, depth, fms, datesMode)}
+				if err := encodeOutput(cmd, out); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+				return encodingErr
+			}
+
+			var b strings.Builder
+			if rev != "" {
+				name += "@" + rev
+			}
+			b.WriteString(sanitizePath(name) + "\n")
+			writeTree(&b, root.Children, "", 1, depth, label)
+			if _, err := fmt.Fprint(cmd.OutOrStdout(), b.String()); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+			return encodingErr
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output the tree as JSON")
+	addFormatFlag(cmd)
+	cmd.Flags().IntVar(&depth, "depth", 0, "show at most this many levels (0 = all)")
+	addRevFlag(cmd, &rev)
+	cmd.Flags().BoolVar(&diffMode, "diff", false, "with --rev, print a unified diff of the tree from the revision to the work tree")
+	cmd.Flags().BoolVar(&datesMode, "dates", false, "show each node's created and updated times")
+	cmd.Flags().StringVar(&dateFormat, "date-format", "", "with --dates or date columns, show times as date, datetime, long, rfc3339, or a Go layout (default: dates.format in .prosemark.yml, else datetime)")
+	cmd.Flags().StringVar(&timezone, "timezone", "", "with --dates or date columns, show times in this IANA time zone, UTC, or local (default: dates.timezone in .prosemark.yml, else local)")
+	cmd.Flags().StringVar(&columnSpec, "columns", "", "print a table of these comma-separated columns: title, target, type, label, status, words, comments, created, updated, depth, line")
+	cmd.Flags().BoolVar(&noHeader, "no-header", false, "with --columns, leave out the header row")
+	addRepairEncodingFlag(cmd)
+	return cmd
+}
+
+// treeColumns are the columns tree --columns can show.
+var treeColumns = []tableColumn{
+	{name: "title"},
+	{name: "target"},
+	{name: "type"},
+	{name: "label"},
+	{name: "status"},
+	{name: "words", right: true},
+	{name: "comments", right: true},
+	{name: "created"},
+	{name: "updated"},
+	{name: "depth", right: true},
+	{name: "line", right: true},
+}
+
+// treeTable returns the cells of cols for each node under root, in outline
+// order down to maxDepth (0 for no limit). Node files are read through io
+// from projectDir only for the columns that need them, and times are shown
+// as --dates would show them.
+func treeTable(cmd *cobra.Command, io TreeIO, projectDir string, root *binder.Node, cols []tableColumn, maxDepth int, dateFormat, timezone string) ([][]string, error) {
+	var fms map[string]node.Frontmatter
+	var types node.FrontmatterSchema
+	var format node.DateFormat
+	var words, comments map[string]string
+	for _, c := range cols {
+		if fms == nil && (c.name == "type" || c.name == "label" || c.name == "created" || c.name == "updated") {
+			fms = readNodeFrontmatter(io, projectDir, root)
+		}
+		switch c.name {
+		case "label":
+			var err error
+			if types, err = readTreeNodeTypes(io, projectDir, fms); err != nil {
+				return nil, err
+			}
+		case "created", "updated":
+			var err error
+			if format, err = treeDateFormat(cmd, io, projectDir, dateFormat, timezone); err != nil {
+				return nil, err
+			}
+		case "words":
+			words = readNodeCounts(io, projectDir, root, func(content []byte) int { return stats.CountNode(content).Words })
+		case "comments":
+			comments = readNodeCounts(io, projectDir, root, node.CountUnresolvedComments)
+		}
+	}
+	stamp := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return sanitizePath(format.Format(s))
+	}
+
+	var rows [][]string
+	var walk func(nodes []*binder.Node, level int)
+	walk = func(nodes []*binder.Node, level int) {
+		for _, n := range nodes {
+			row := make([]string, len(cols))
+			for i, c := range cols {
+				switch c.name {
+				case "title":
+					row[i] = sanitizePath(n.Title)
+				case "target":
+					row[i] = sanitizePath(n.Target)
+				case "type":
+					row[i] = sanitizePath(fms[n.Target].Type)
+				case "label":
+					row[i] = sanitizePath(typeLabel(types, fms[n.Target].Type))
+				case "status":
+					row[i] = nodeStatus(n)
+				case "words":
+					row[i] = words[n.Target]
+				case "comments":
+					row[i] = comments[n.Target]
+				case "created":
+					row[i] = stamp(fms[n.Target].Created)
+				case "updated":
+					row[i] = stamp(fms[n.Target].Updated)
+				case "depth":
+					row[i] = strconv.Itoa(level)
+				case "line":
+					row[i] = strconv.Itoa(n.Line)
+				}
+			}
+			rows = append(rows, row)
+			if maxDepth == 0 || level < maxDepth {
+				walk(n.Children, level+1)
+			}
+		}
+	}
+	walk(root.Children, 1)
+	return rows, nil
+}
+
+// nodeStatus describes the task checkbox of n: "done", "todo", or "" when
+// it has none.
+func nodeStatus(n *binder.Node) string {
+	switch {
+	case n.Checked == nil:
+		return ""
+	case *n.Checked:
+		return "done"
+	default:
+		return "todo"
+	}
+}
+
+// taskMarker returns the task checkbox of n as the binder writes it, "[x] "
+// or "[ ] ", or "" when it has none.
+func taskMarker(n *binder.Node) string {
+	switch {
+	case n.Checked == nil:
+		return ""
+	case *n.Checked:
+		return "[x] "
+	default:
+		return "[ ] "
+	}
+}
+
+// readTreeFile reads the file at target, relative to projectDir, with read;
+// a target escaping the project directory is not read.
+func readTreeFile(read func(path string) ([]byte, error), projectDir, target string) ([]byte, error) {
+	path, err := safepath.Resolve(projectDir, target)
+	if err != nil {
+		return nil, err
+	}
+	return read(path)
+}
+
+// readNodeCounts applies count, such as a word count, to the node files
+// linked under root, keyed by target. Files that are missing, or have a
+// locked body and no key to unlock it, are left out, so their nodes show no
+// count.
+func readNodeCounts(io TreeIO, projectDir string, root *binder.Node, count func(content []byte) int) map[string]string {
+	counts := make(map[string]string)
+	var walk func(n *binder.Node)
+	walk = func(n *binder.Node) {
+		for _, c := range n.Children {
+			if _, done := counts[c.Target]; c.Target != "" && !done {
+				if content, err := readTreeFile(io.ReadNodeFile, projectDir, c.Target); err == nil {
+					counts[c.Target] = strconv.Itoa(count(content))
+				}
+			}
+			walk(c)
+		}
+	}
+	walk(root)
+	return counts
+}
+
+// parseTree parses the binder at binderPath, read through io as of rev (""
+// for the work tree). Invalid UTF-8 is reported on stderr and returned as
+// encodingErr, leaving the caller to show the tree before failing.
+func parseTree(cmd *cobra.Command, io TreeIO, binderPath, rev string) (root *binder.Node, encodingErr, err error) {
+	parsed, err := core.Parse(cmd.Context(), io, binderPath, parseOptionsFromCmd(cmd))
+	if err != nil {
+		return nil, nil, binderReadError(err, rev)
+	}
+	if parsed.Err != nil {
+		return nil, nil, fmt.Errorf("cannot parse binder: %w", parsed.Err)
+	}
+	for _, d := range parsed.Diagnostics {
+		if d.Code == binder.CodeInvalidUTF8 {
+			printDiagnostics(cmd, []binder.Diagnostic{d})
+			encodingErr = fmt.Errorf("binder contains invalid UTF-8 (%s)", binder.CodeInvalidUTF8)
+		}
+	}
+	return parsed.Result.Root, encodingErr, nil
+}
+
+// treeDateFormat returns the format --dates shows times in: the flags
+// format and timezone where set, else the dates: settings of the project
+// config in projectDir, read through io.
+func treeDateFormat(cmd *cobra.Command, io TreeIO, projectDir, format, timezone string) (node.DateFormat, error) {
+	if !cmd.Flags().Changed("date-format") || !cmd.Flags().Changed("timezone") {
+		settings, err := readProjectSettings(io.ReadFile, projectDir)
+		if err != nil {
+			return node.DateFormat{}, err
+		}
+		format = cmp.Or(format, settings.DateFormat)
+		timezone = cmp.Or(timezone, settings.Timezone)
+	}
+	f, err := node.ParseDateFormat(format, timezone)
+	if err != nil {
+		return node.DateFormat{}, fmt.Errorf("--dates: %w", err)
+	}
+	return f, nil
+}
+
+// readNodeFrontmatter reads the frontmatter of the node files linked under
+// root, keyed by target. Files that are missing or have no frontmatter are
+// left out, so their nodes show no type or times.
+func readNodeFrontmatter(io TreeIO, projectDir string, root *binder.Node) map[string]node.Frontmatter {
+	fms := make(map[string]node.Frontmatter)
+	var walk func(n *binder.Node)
+	walk = func(n *binder.Node) {
+		for _, c := range n.Children {
+			if _, done := fms[c.Target]; c.Target != "" && !done {
+				if content, err := readTreeFile(io.ReadFile, projectDir, c.Target); err == nil {
+					if fm, _, err := node.ParseFrontmatter(content); err == nil {
+						fms[c.Target] = fm
+					}
+				}
+			}
+			walk(c)
+		}
+	}
+	walk(root)
+	return fms
+}
+
+// readTreeNodeTypes returns the node types of the project in projectDir,
+// read through io. The config is only read when a node in fms has a type,
+// so an untyped outline shows even when the config is broken.
+func readTreeNodeTypes(io TreeIO, projectDir string, fms map[string]node.Frontmatter) (node.FrontmatterSchema, error) {
+	for _, fm := range fms {
+		if fm.Type != "" {
+			return readNodeTypes(io.ReadFile, projectDir)
+		}
+	}
+	return nil, nil
+}
+
+// typeIcon returns the icon of the node type name in types followed by a
+// space, or "" when name is empty or unknown or its type has no icon.
+func typeIcon(types node.FrontmatterSchema, name string) string {
+	if ts, ok := types.NodeType(name); ok && ts.Icon != "" {
+		return sanitizePath(ts.Icon) + " "
+	}
+	return ""
+}
+
+// typeLabel returns the label of the node type name in types, or "" when
+// name is empty or unknown.
+func typeLabel(types node.FrontmatterSchema, name string) string {
+	ts, _ := types.NodeType(name)
+	return ts.Label
+}
+
+// datesLabel describes fm's created and updated times in format, or returns
+// "" when it has neither.
+func datesLabel(fm node.Frontmatter, format node.DateFormat) string {
+	var parts []string
+	if fm.Created != "" {
+		parts = append(parts, "created "+sanitizePath(format.Format(fm.Created)))
+	}
+	if fm.Updated != "" {
+		parts = append(parts, "updated "+sanitizePath(format.Format(fm.Updated)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(parts, ", ") + "]"
+}
+
+// writeTree writes nodes at the given 1-based level, and their descendants
+// down to maxDepth (0 for no limit), as ASCII tree lines prefixed by indent
+// and described by label.
+func writeTree(b *strings.Builder, nodes []*binder.Node, indent string, level, maxDepth int, label func(*binder.Node) string) {
+	for i, n := range nodes {
+		branch, nextIndent := "|-- ", indent+"|   "
+		if i == len(nodes)-1 {
+			branch, nextIndent = "`-- ", indent+"    "
+		}
+		b.WriteString(indent + branch + label(n) + "\n")
+		if maxDepth == 0 || level < maxDepth {
+			writeTree(b, n.Children, nextIndent, level+1, maxDepth, label)
+		}
+	}
+}
+
+// treeLabel describes n as its title followed by its target, or by
+// "(placeholder)" when it has none.
+func treeLabel(n *binder.Node) string {
+	if n.Target == "" {
+		return sanitizePath(n.Title) + " (placeholder)"
+	}
+	return sanitizePath(n.Title) + " (" + sanitizePath(n.Target) + ")"
+}
+
+// treeJSON converts nodes at the given 1-based level, and their descendants
+// down to maxDepth (0 for no limit), to their JSON form, with the type of
+// their node files' frontmatter in fms and, when dates is set, its times.
+func treeJSON(nodes []*binder.Node, level, maxDepth int, fms map[string]node.Frontmatter, dates bool) []*treeNodeJSON {
+	out := make([]*treeNodeJSON, 0, len(nodes))
+	for _, n := range nodes {
+		fm := fms[n.Target]
+		j := &treeNodeJSON{Type: n.Type, NodeType: fm.Type, Title: n.Title, Target: n.Target, Checked: n.Checked, Depth: level, Line: n.Line, Children: []*treeNodeJSON{}}
+		if dates {
+			j.Created, j.Updated = fm.Created, fm.Updated
+		}
+		if maxDepth == 0 || level < maxDepth {
+			j.Children = treeJSON(n.Children, level+1, maxDepth, fms, dates)
+		}
+		out = append(out, j)
+	}
+	return out
+}
+
+// fileTreeIO implements TreeIO using OS file I/O, reading the work tree or,
+// when rev is set, that git revision.
+type fileTreeIO struct {
+	rev string
+}
+
+// ReadBinder reads the binder file at path.
+func (f fileTreeIO) ReadBinder(ctx context.Context, path string) ([]byte, error) {
+	if f.rev != "" {
+		return fsio.ReadBinderAtRevision(ctx, f.rev, path)
+	}
+	return fsio.ReadBinder(path)
+}
+
+// ScanProject scans the project directory for .md files.
+func (f fileTreeIO) ScanProject(ctx context.Context, binderPath string) (*binder.Project, error) {
+	if f.rev != "" {
+		return fsio.ScanProjectAtRevision(ctx, f.rev, binderPath)
+	}
+	return fsio.ScanProject(ctx, binderPath)
+}
+
+// ReadFile reads the file at path.
+func (f fileTreeIO) ReadFile(path string) ([]byte, error) {
+	if f.rev != "" {
+		return fsio.ReadFileAtRevision(context.Background(), f.rev, path)
+	}
+	return fsio.ReadFile(path)
+}
+
+// ReadNodeFile reads the node file at path, unlocking a locked body with
+// the key file named by $PMK_KEY_FILE.
+func (f fileTreeIO) ReadNodeFile(path string) ([]byte, error) {
+	if f.rev != "" {
+		return fsio.ReadNodeFileAtRevision(context.Background(), f.rev, path)
+	}
+	return fsio.ReadNodeFile(path)
+}
+
+// AtRevision returns a fileTreeIO reading the git revision rev.
+func (f fileTreeIO) AtRevision(rev string) TreeIO {
+	return fileTreeIO{rev: rev}
+}
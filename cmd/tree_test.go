@@ -0,0 +1,553 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// mockTreeIO is a test double for TreeIO.
+type mockTreeIO struct {
+	binderBytes []byte
+	binderErr   error
+	projectErr  error
+	// revs holds the project as of each git revision; others have no binder.
+	revs map[string]*mockTreeIO
+	// files holds other project files by base name.
+	files   map[string]string
+	fileErr error
+	// configErr fails reading .prosemark.yml alone.
+	configErr error
+}
+
+func (m *mockTreeIO) ReadBinder(_ context.Context, _ string) ([]byte, error) {
+	return m.binderBytes, m.binderErr
+}
+
+func (m *mockTreeIO) ScanProject(_ context.Context, _ string) (*binder.Project, error) {
+	return &binder.Project{Files: []string{}, BinderDir: "."}, m.projectErr
+}
+
+func (m *mockTreeIO) ReadFile(path string) ([]byte, error) {
+	if m.fileErr != nil {
+		return nil, m.fileErr
+	}
+	if m.configErr != nil && filepath.Base(path) == ".prosemark.yml" {
+		return nil, m.configErr
+	}
+	content, ok := m.files[filepath.Base(path)]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return []byte(content), nil
+}
+
+// ReadNodeFile reads path as ReadFile does, refusing a locked body as
+// fsio.ReadNodeFile does without a key.
+func (m *mockTreeIO) ReadNodeFile(path string) ([]byte, error) {
+	content, err := m.ReadFile(path)
+	if err == nil && node.BodyLocked(content) {
+		return nil, node.ErrLockedBody
+	}
+	return content, err
+}
+
+func (m *mockTreeIO) AtRevision(rev string) TreeIO {
+	if r, ok := m.revs[rev]; ok {
+		return r
+	}
+	return &mockTreeIO{binderErr: os.ErrNotExist}
+}
+
+const treeTestBinder = "<!-- prosemark-binder:v1 -->\n" +
+	"- [Part One](part1.md)\n" +
+	"  - [Chapter One](ch1.md)\n" +
+	"    - [Scene \x1b[31m](scene.md)\n" +
+	"  - [Draft]()\n" +
+	"- [Part Two](part2.md)\n"
+
+func runTreeCmd(t *testing.T, mock *mockTreeIO, args ...string) (string, error) {
+	t.Helper()
+	c := NewTreeCmd(mock)
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(append(args, "--project", "."))
+	err := c.Execute()
+	return out.String(), err
+}
+
+func TestTree_PrintsHierarchy(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"all levels", nil, "_binder.md\n" +
+			"|-- Part One (part1.md)\n" +
+			"|   |-- Chapter One (ch1.md)\n" +
+			"|   |   `-- Scene ?[31m (scene.md)\n" +
+			"|   `-- Draft (placeholder)\n" +
+			"`-- Part Two (part2.md)\n"},
+		{"depth 2", []string{"--depth", "2"}, "_binder.md\n" +
+			"|-- Part One (part1.md)\n" +
+			"|   |-- Chapter One (ch1.md)\n" +
+			"|   `-- Draft (placeholder)\n" +
+			"`-- Part Two (part2.md)\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := runTreeCmd(t, &mockTreeIO{binderBytes: []byte(treeTestBinder)}, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out != tt.want {
+				t.Errorf("tree =\n%s\nwant\n%s", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestTree_JSON(t *testing.T) {
+	out, err := runTreeCmd(t, &mockTreeIO{binderBytes: []byte(treeTestBinder)}, "--json", "--depth", "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got treeOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if got.Binder != "_binder.md" || len(got.Nodes) != 2 {
+		t.Fatalf("output = %+v", got)
+	}
+	first := got.Nodes[0]
+	if first.Title != "Part One" || first.Target != "part1.md" || first.Depth != 1 || first.Line != 2 || len(first.Children) != 0 {
+		t.Errorf("first node = %+v", first)
+	}
+
+	out, _ = runTreeCmd(t, &mockTreeIO{binderBytes: []byte(treeTestBinder)}, "--json")
+	if !strings.Contains(out, `{"type":"node","title":"Draft","depth":2,"line":5,"children":[]}`) {
+		t.Errorf("output = %s, want the placeholder at depth 2", out)
+	}
+}
+
+func TestTree_Revision(t *testing.T) {
+	old := "<!-- prosemark-binder:v1 -->\n- [Part One](part1.md)\n- [Old Part](old.md)\n"
+	mock := &mockTreeIO{binderBytes: []byte(treeTestBinder), revs: map[string]*mockTreeIO{"v1": {binderBytes: []byte(old)}}}
+
+	out, err := runTreeCmd(t, mock, "--rev", "v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "_binder.md@v1\n|-- Part One (part1.md)\n`-- Old Part (old.md)\n"; out != want {
+		t.Errorf("tree =\n%s\nwant\n%s", out, want)
+	}
+
+	out, _ = runTreeCmd(t, mock, "--rev", "v1", "--json")
+	if !strings.Contains(out, `"binder":"_binder.md","revision":"v1"`) {
+		t.Errorf("output = %s, want the revision", out)
+	}
+
+	out, err = runTreeCmd(t, mock, "--rev", "v1", "--diff", "--depth", "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "--- a/_binder.md@v1\n+++ b/_binder.md\n@@ -1,2 +1,2 @@\n" +
+		" |-- Part One (part1.md)\n" +
+		"-`-- Old Part (old.md)\n" +
+		"+`-- Part Two (part2.md)\n"
+	if out != want {
+		t.Errorf("diff =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestTree_Dates(t *testing.T) {
+	files := map[string]string{
+		"part1.md": "---\nid: p1\ncreated: 2026-03-04T17:05:00Z\nupdated: 2026-03-05T09:30:00Z\n---\n",
+		"part2.md": "no frontmatter\n",
+	}
+	binderSrc := []byte("<!-- prosemark-binder:v1 -->\n- [Part One](part1.md)\n- [Part Two](part2.md)\n- [Gone](gone.md)\n")
+	tests := []struct {
+		name   string
+		config string
+		args   []string
+		want   string
+	}{
+		{"configured", "dates:\n  format: long\n  timezone: America/New_York\n", nil, "Part One (part1.md) [created March 4, 2026 12:05 PM, updated March 5, 2026 4:30 AM]"},
+		{"flags override", "dates:\n  format: long\n  timezone: America/New_York\n", []string{"--date-format", "date", "--timezone", "Asia/Tokyo"}, "Part One (part1.md) [created 2026-03-05, updated 2026-03-05]"},
+		{"timezone flag keeps configured format", "dates:\n  format: date\n", []string{"--timezone", "UTC"}, "Part One (part1.md) [created 2026-03-04, updated 2026-03-05]"},
+		{"default format", "", []string{"--timezone", "UTC"}, "Part One (part1.md) [created 2026-03-04 17:05, updated 2026-03-05 09:30]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockTreeIO{binderBytes: binderSrc, files: map[string]string{}}
+			for k, v := range files {
+				mock.files[k] = v
+			}
+			if tt.config != "" {
+				mock.files[".prosemark.yml"] = tt.config
+			}
+			out, err := runTreeCmd(t, mock, append([]string{"--dates"}, tt.args...)...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(out, "|-- "+tt.want+"\n|-- Part Two (part2.md)\n`-- Gone (gone.md)\n") {
+				t.Errorf("tree =\n%s\nwant the line %q", out, tt.want)
+			}
+		})
+	}
+
+	// JSON keeps the stored RFC3339 UTC times.
+	mock := &mockTreeIO{binderBytes: binderSrc, files: files}
+	out, err := runTreeCmd(t, mock, "--dates", "--json", "--timezone", "Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, `"created":"2026-03-04T17:05:00Z","updated":"2026-03-05T09:30:00Z"`) {
+		t.Errorf("JSON = %s, want the stored times", out)
+	}
+
+	// A diff compares the times at the revision with the work tree's.
+	mock = &mockTreeIO{binderBytes: binderSrc, files: files, revs: map[string]*mockTreeIO{"v1": {
+		binderBytes: binderSrc,
+		files:       map[string]string{"part1.md": "---\ncreated: 2026-03-04T17:05:00Z\nupdated: 2026-03-04T17:05:00Z\n---\n"},
+	}}}
+	out, err = runTreeCmd(t, mock, "--rev", "v1", "--diff", "--dates", "--date-format", "date", "--timezone", "UTC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "-|-- Part One (part1.md) [created 2026-03-04, updated 2026-03-04]\n+|-- Part One (part1.md) [created 2026-03-04, updated 2026-03-05]\n") {
+		t.Errorf("diff =\n%s\nwant the updated time changed", out)
+	}
+}
+
+func TestTree_Columns(t *testing.T) {
+	mock := &mockTreeIO{
+		binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [x] [Part One](part1.md)\n  - [ ] [Chapter One](ch1.md)\n- [Draft]()\n- [Out](/out.md)\n"),
+		files: map[string]string{
+			"part1.md": "---\nid: p1\ncreated: 2026-03-04T17:05:00Z\nupdated: 2026-03-05T09:30:00Z\n---\nIt was a dark and stormy night.\n" +
+				"%% editor: which night? %%\n<!-- bob (resolved): done -->\n`%% editor: code %%`\n",
+			"out.md": "outside the project\n",
+			"ch1.md": "---\nid: ch1\n---\n<!-- pmk:locked v1 key=0123456789abcdef -->\nciphertext\n",
+		},
+	}
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"chosen order", []string{"--columns", "status,words,title"}, "" +
+			"STATUS  WORDS  TITLE\n" +
+			"done        7  Part One\n" +
+			"todo        -  Chapter One\n" +
+			"-           -  Draft\n" +
+			"-           -  Out\n"},
+		{"comments", []string{"--columns", "title,comments"}, "" +
+			"TITLE        COMMENTS\n" +
+			"Part One            1\n" +
+			"Chapter One         -\n" +
+			"Draft               -\n" +
+			"Out                 -\n"},
+		{"no header", []string{"--columns", "Target, depth,type", "--no-header"}, "" +
+			"part1.md  1  -\n" +
+			"ch1.md    2  -\n" +
+			"-         1  -\n" +
+			"/out.md   1  -\n"},
+		{"depth and dates", []string{"--columns", "title,created,updated,line", "--depth", "1", "--date-format", "date", "--timezone", "UTC"}, "" +
+			"TITLE     CREATED     UPDATED     LINE\n" +
+			"Part One  2026-03-04  2026-03-05     2\n" +
+			"Draft     -           -              4\n" +
+			"Out       -           -              5\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := runTreeCmd(t, mock, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out != tt.want {
+				t.Errorf("table =\n%s\nwant\n%s", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestTree_TaskState(t *testing.T) {
+	mock := &mockTreeIO{
+		binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [x] [Part One](part1.md)\n  - [ ] [Chapter One](ch1.md)\n- [Draft]()\n"),
+		files:       map[string]string{"part1.md": "---\nid: p1\ntype: chapter\n---\n"},
+	}
+
+	out, err := runTreeCmd(t, mock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "_binder.md\n" +
+		"|-- [x] § Part One (part1.md)\n" +
+		"|   `-- [ ] Chapter One (ch1.md)\n" +
+		"`-- Draft (placeholder)\n"
+	if out != want {
+		t.Errorf("tree =\n%s\nwant\n%s", out, want)
+	}
+
+	out, err = runTreeCmd(t, mock, "--json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got treeOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	part, chapter, draft := got.Nodes[0], got.Nodes[0].Children[0], got.Nodes[1]
+	if part.Checked == nil || !*part.Checked || chapter.Checked == nil || *chapter.Checked || draft.Checked != nil {
+		t.Errorf("checked = %v, %v, %v; want true, false, and absent", part.Checked, chapter.Checked, draft.Checked)
+	}
+	if !strings.Contains(out, `"title":"Draft","depth":1`) {
+		t.Errorf("JSON = %s, want no checked field without a checkbox", out)
+	}
+}
+
+func TestTree_NodeTypes(t *testing.T) {
+	mock := &mockTreeIO{
+		binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [Part One](part1.md)\n  - [Kim](kim.md)\n  - [Odd](odd.md)\n- [Draft]()\n"),
+		files: map[string]string{
+			".prosemark.yml": "types:\n  character:\n    icon: \"*\"\n  monster:\n    label: Monster\n",
+			"part1.md":       "---\nid: p1\ntype: chapter\n---\n",
+			"kim.md":         "---\nid: kim\ntype: character\n---\n",
+			"odd.md":         "---\nid: odd\ntype: monster\n---\n",
+		},
+	}
+
+	out, err := runTreeCmd(t, mock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "_binder.md\n" +
+		"|-- § Part One (part1.md)\n" +
+		"|   |-- * Kim (kim.md)\n" +
+		"|   `-- Odd (odd.md)\n" +
+		"`-- Draft (placeholder)\n"
+	if out != want {
+		t.Errorf("tree =\n%s\nwant\n%s", out, want)
+	}
+
+	out, err = runTreeCmd(t, mock, "--columns", "title,type,label")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want = "" +
+		"TITLE     TYPE       LABEL\n" +
+		"Part One  chapter    Chapter\n" +
+		"Kim       character  Character\n" +
+		"Odd       monster    Monster\n" +
+		"Draft     -          -\n"
+	if out != want {
+		t.Errorf("table =\n%s\nwant\n%s", out, want)
+	}
+
+	out, _ = runTreeCmd(t, mock, "--json")
+	if !strings.Contains(out, `{"type":"node","node_type":"character","title":"Kim",`) {
+		t.Errorf("JSON = %s, want the node type", out)
+	}
+
+	mock.files[".prosemark.yml"] = "types:\n  character:\n    required: {age: integer}\n"
+	for _, args := range [][]string{nil, {"--columns", "label"}} {
+		if _, err := runTreeCmd(t, mock, args...); err == nil || !strings.Contains(err.Error(), ".prosemark.yml: ") {
+			t.Errorf("%v: err = %v, want the invalid types config reported", args, err)
+		}
+	}
+	mock.configErr = errors.New("denied")
+	if _, err := runTreeCmd(t, mock); err == nil || !strings.Contains(err.Error(), "reading .prosemark.yml: denied") {
+		t.Errorf("err = %v, want the unreadable config reported", err)
+	}
+}
+
+func TestTree_ColumnsLockedWords(t *testing.T) {
+	dir := t.TempDir()
+	key := testBodyKey(t)
+	keyPath := filepath.Join(t.TempDir(), "project.key")
+	if err := os.WriteFile(keyPath, key.Encode(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		"_binder.md": []byte("<!-- prosemark-binder:v1 -->\n- [Secret](secret.md)\n"),
+		"secret.md":  lockedContent(t, []byte("---\nid: s\n---\nThree locked words.\n"), key),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct{ keyFile, want string }{
+		{keyPath, "TITLE   WORDS\nSecret      3\n"},
+		{"", "TITLE   WORDS\nSecret      -\n"},
+	} {
+		t.Setenv(fsio.KeyFileEnv, tt.keyFile)
+		c := NewTreeCmd(fileTreeIO{})
+		out := new(bytes.Buffer)
+		c.SetOut(out)
+		c.SetErr(new(bytes.Buffer))
+		c.SetArgs([]string{"--project", dir, "--columns", "title,words"})
+		if err := c.Execute(); err != nil {
+			t.Fatalf("key file %q: %v", tt.keyFile, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("key file %q: table =\n%s\nwant\n%s", tt.keyFile, out, tt.want)
+		}
+	}
+}
+
+func TestTree_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    *mockTreeIO
+		args    []string
+		wantErr string
+	}{
+		{"negative depth", &mockTreeIO{}, []string{"--depth", "-1"}, "--depth must not be negative"},
+		{"binder missing", &mockTreeIO{binderErr: os.ErrNotExist}, nil, "project not initialized"},
+		{"binder unreadable", &mockTreeIO{binderErr: errors.New("io")}, nil, "reading binder"},
+		{"scan fails", &mockTreeIO{binderBytes: []byte(treeTestBinder), projectErr: errors.New("scan")}, nil, "scanning project"},
+		{"invalid binder", &mockTreeIO{binderBytes: []byte("\xff\xfe")}, nil, "cannot parse binder"},
+		{"no binder at revision", &mockTreeIO{}, []string{"--rev", "v0"}, "no binder at revision v0"},
+		{"diff without revision", &mockTreeIO{}, []string{"--diff"}, "--diff needs --rev"},
+		{"diff with JSON", &mockTreeIO{}, []string{"--rev", "v0", "--diff", "--json"}, "--diff cannot be used with --json"},
+		{"bad date format", &mockTreeIO{binderBytes: []byte(treeTestBinder)}, []string{"--dates", "--date-format", "fancy"}, `--dates: unknown date format "fancy"`},
+		{"bad configured time zone", &mockTreeIO{binderBytes: []byte(treeTestBinder), files: map[string]string{".prosemark.yml": "dates:\n  timezone: Mars/Olympus\n"}}, []string{"--dates"}, `.prosemark.yml: dates: unknown time zone`},
+		{"unreadable config", &mockTreeIO{binderBytes: []byte(treeTestBinder), fileErr: errors.New("denied")}, []string{"--dates"}, "reading .prosemark.yml: denied"},
+		{"dates diff with bad work tree config", &mockTreeIO{binderBytes: []byte(treeTestBinder), files: map[string]string{".prosemark.yml": "dates: [\n"}, revs: map[string]*mockTreeIO{"v1": {binderBytes: []byte(treeTestBinder)}}}, []string{"--rev", "v1", "--diff", "--dates"}, ".prosemark.yml: parse project settings"},
+		{"unknown column", &mockTreeIO{}, []string{"--columns", "title,mood"}, `unknown column "mood"`},
+		{"repeated column", &mockTreeIO{}, []string{"--columns", "title,title"}, `column "title" is listed more than once`},
+		{"empty column", &mockTreeIO{}, []string{"--columns", "title,"}, "--columns has an empty column name"},
+		{"columns with JSON", &mockTreeIO{}, []string{"--columns", "title", "--json"}, "--columns cannot be used with --json"},
+		{"columns with diff", &mockTreeIO{}, []string{"--columns", "title", "--rev", "v0", "--diff"}, "--columns cannot be used with --diff"},
+		{"columns with dates", &mockTreeIO{}, []string{"--columns", "title", "--dates"}, "--columns cannot be used with --dates"},
+		{"no header without columns", &mockTreeIO{}, []string{"--no-header"}, "--no-header needs --columns"},
+		{"columns with bad date format", &mockTreeIO{binderBytes: []byte(treeTestBinder)}, []string{"--columns", "created", "--date-format", "fancy"}, `unknown date format "fancy"`},
+		{"diff with unreadable work tree", &mockTreeIO{binderErr: os.ErrNotExist, revs: map[string]*mockTreeIO{"v1": {binderBytes: []byte(treeTestBinder)}}}, []string{"--rev", "v1", "--diff"}, "project not initialized"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runTreeCmd(t, tt.mock, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTree_RepairEncoding(t *testing.T) {
+	c := NewTreeCmd(&mockTreeIO{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [Ch\xff](ch.md)\n")})
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(errOut)
+	c.SetArgs([]string{"--project", ".", "--repair-encoding"})
+	err := c.Execute()
+	if err == nil || !strings.Contains(err.Error(), "PMKE008") {
+		t.Errorf("error = %v, want a PMKE008 error", err)
+	}
+	if !strings.Contains(out.String(), "Ch\uFFFD (ch.md)") {
+		t.Errorf("stdout = %q, want the repaired tree", out.String())
+	}
+	if !strings.Contains(errOut.String(), "byte offset 34") {
+		t.Errorf("stderr = %q, want the byte offset", errOut.String())
+	}
+}
+
+func TestTree_OutputErrors(t *testing.T) {
+	for _, args := range [][]string{nil, {"--json"}, {"--rev", "v1", "--diff"}, {"--columns", "title"}} {
+		c := NewTreeCmd(&mockTreeIO{binderBytes: []byte(treeTestBinder), revs: map[string]*mockTreeIO{"v1": {binderBytes: []byte(treeTestBinder)}}})
+		c.SetOut(&errWriter{err: errors.New("closed")})
+		c.SetErr(new(bytes.Buffer))
+		c.SetArgs(append(args, "--project", "."))
+		if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "output") {
+			t.Errorf("args %v: error = %v, want an output error", args, err)
+		}
+	}
+}
+
+func TestTree_GetwdError(t *testing.T) {
+	c := newTreeCmdWithGetCWD(&mockTreeIO{}, func() (string, error) { return "", errors.New("getwd failed") })
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(nil)
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+		t.Errorf("error = %v, want getwd failure", err)
+	}
+}
+
+func TestFileTreeIO_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	if err := os.WriteFile(binderPath, []byte(treeTestBinder), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fio := fileTreeIO{}
+	if got, err := fio.ReadBinder(context.Background(), binderPath); err != nil || string(got) != treeTestBinder {
+		t.Errorf("ReadBinder = %q, %v", got, err)
+	}
+	if _, err := fio.ScanProject(context.Background(), binderPath); err != nil {
+		t.Errorf("ScanProject: %v", err)
+	}
+	if got, err := fio.ReadFile(binderPath); err != nil || string(got) != treeTestBinder {
+		t.Errorf("ReadFile = %q, %v", got, err)
+	}
+	if got, err := fio.ReadNodeFile(binderPath); err != nil || string(got) != treeTestBinder {
+		t.Errorf("ReadNodeFile = %q, %v", got, err)
+	}
+}
+
+// gitCommitAll makes dir a git repository whose first commit holds the
+// files already in it.
+func gitCommitAll(t *testing.T, dir string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "-m", "first"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func TestFileTreeIO_AtRevision(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	if err := os.WriteFile(binderPath, []byte(treeTestBinder), 0600); err != nil {
+		t.Fatal(err)
+	}
+	gitCommitAll(t, dir)
+	if err := os.WriteFile(binderPath, []byte("changed\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fio := fileTreeIO{}.AtRevision("HEAD")
+	if got, err := fio.ReadBinder(context.Background(), binderPath); err != nil || string(got) != treeTestBinder {
+		t.Errorf("ReadBinder = %q, %v; want the committed binder", got, err)
+	}
+	if got, err := fio.ReadFile(binderPath); err != nil || string(got) != treeTestBinder {
+		t.Errorf("ReadFile = %q, %v; want the committed binder", got, err)
+	}
+	if got, err := fio.ReadNodeFile(binderPath); err != nil || string(got) != treeTestBinder {
+		t.Errorf("ReadNodeFile = %q, %v; want the committed binder", got, err)
+	}
+	if _, err := fio.ScanProject(context.Background(), binderPath); err != nil {
+		t.Errorf("ScanProject: %v", err)
+	}
+}
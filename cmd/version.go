@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/release"
+)
+
+// BuildInfo describes the running pmk binary. The fields are injected into
+// package main at build time and handed over with SetBuildInfo.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+}
+
+// buildInfo is the BuildInfo reported by the version command.
+var buildInfo = BuildInfo{Version: "dev", Commit: "none", BuildDate: "unknown"}
+
+// SetBuildInfo records the build information reported by pmk version.
+func SetBuildInfo(info BuildInfo) {
+	buildInfo = info
+}
+
+// lookPathFunc finds optional external tools for feature detection.
+// Override in tests to control which tools appear installed.
+var lookPathFunc = exec.LookPath
+
+// supportedPragmaVersions lists the binder pragma versions the parser reads.
+var supportedPragmaVersions = []string{"v1"}
+
+// schemaVersions maps each JSON document pmk emits or accepts to the
+// "version" value it carries.
+var schemaVersions = map[string]string{
+	"diagnostics":  "1",
+	"doctor":       "1",
+	"op-result":    "1",
+	"op-spec":      "1",
+	"parse-result": "1",
+	"project":      "1",
+}
+
+// optionalTools are the external programs whose presence is reported as
+// features.
+var optionalTools = []string{"git", "pandoc"}
+
+// versionOutput is the JSON output of pmk version --json.
+type versionOutput struct {
+	BuildInfo
+	GoVersion      string            `json:"goVersion"`
+	Platform       string            `json:"platform"`
+	BinderPragmas  []string          `json:"binderPragmaVersions"`
+	SchemaVersions map[string]string `json:"schemaVersions"`
+	Features       map[string]bool   `json:"features"`
+	Latest         *latestOutput     `json:"latest,omitempty"`
+}
+
+// latestOutput reports the newest release, for pmk version --check.
+type latestOutput struct {
+	Version string `json:"version"`
+	URL     string `json:"url,omitempty"`
+	// UpdateAvailable is nil when the running version cannot be compared,
+	// as for a development build.
+	UpdateAvailable *bool `json:"updateAvailable"`
+}
+
+// NewVersionCmd creates the version subcommand. io is used only by --check.
+func NewVersionCmd(io ReleaseIO) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print build information and supported formats",
+		Long: "Print build information, the binder pragma and JSON schema versions this\n" +
+			"pmk supports, and which optional external tools were found. Integrators\n" +
+			"should use --json for capability detection rather than parsing the\n" +
+			"version string. --check also asks GitHub for the latest release and\n" +
+			"reports whether it is newer; pmk self-update installs it.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := versionOutput{
+				BuildInfo:      buildInfo,
+				GoVersion:      runtime.Version(),
+				Platform:       runtime.GOOS + "/" + runtime.GOARCH,
+				BinderPragmas:  supportedPragmaVersions,
+				SchemaVersions: schemaVersions,
+				Features:       make(map[string]bool, len(optionalTools)),
+			}
+			for _, tool := range optionalTools {
+				_, err := lookPathFunc(tool)
+				out.Features[tool] = err == nil
+			}
+			if check, _ := cmd.Flags().GetBool("check"); check {
+				latest, err := latestRelease(cmd.Context(), io)
+				if err != nil {
+					return err
+				}
+				out.Latest = &latestOutput{Version: latest.Version(), URL: latest.HTMLURL}
+				if cmp, ok := release.Compare(buildInfo.Version, latest.Version()); ok {
+					newer := cmp < 0
+					out.Latest.UpdateAvailable = &newer
+				}
+			}
+
+			if jsonMode, _ := cmd.Flags().GetBool("json"); jsonMode {
+				if err := encodeOutput(cmd, out); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+				return nil
+			}
+			if _, err := fmt.Fprint(cmd.OutOrStdout(), renderVersionText(out)); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("json", false, "output version information as JSON")
+	addFormatFlag(cmd)
+	cmd.Flags().Bool("check", false, "report whether a newer release is available")
+	return cmd
+}
+
+// renderVersionText renders out for humans, one fact per line.
+func renderVersionText(out versionOutput) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "pmk %s (commit %s, built %s)\n", out.Version, out.Commit, out.BuildDate)
+	fmt.Fprintf(&b, "go: %s %s\n", out.GoVersion, out.Platform)
+	fmt.Fprintf(&b, "binder pragma: %s\n", strings.Join(out.BinderPragmas, ", "))
+
+	names := make([]string, 0, len(out.SchemaVersions))
+	for name := range out.SchemaVersions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	schemas := make([]string, len(names))
+	for i, name := range names {
+		schemas[i] = name + " v" + out.SchemaVersions[name]
+	}
+	fmt.Fprintf(&b, "schemas: %s\n", strings.Join(schemas, ", "))
+
+	features := make([]string, len(optionalTools))
+	for i, tool := range optionalTools {
+		state := "not found"
+		if out.Features[tool] {
+			state = "found"
+		}
+		features[i] = tool + " " + state
+	}
+	fmt.Fprintf(&b, "features: %s\n", strings.Join(features, ", "))
+
+	if l := out.Latest; l != nil {
+		state := "cannot compare with this build"
+		switch {
+		case l.UpdateAvailable == nil:
+		case *l.UpdateAvailable && selfUpdateEnabled:
+			state = "update available; run pmk self-update"
+		case *l.UpdateAvailable:
+			state = "update available"
+		default:
+			state = "up to date"
+		}
+		fmt.Fprintf(&b, "latest: %s (%s)\n", sanitizePath(l.Version), state)
+	}
+	return b.String()
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// stubVersionEnv fixes the build info, enables self-update, and reports
+// only git as installed.
+func stubVersionEnv(t *testing.T) {
+	t.Helper()
+	origInfo, origLookPath, origSelfUpdate := buildInfo, lookPathFunc, selfUpdateEnabled
+	t.Cleanup(func() { buildInfo, lookPathFunc, selfUpdateEnabled = origInfo, origLookPath, origSelfUpdate })
+	selfUpdateEnabled = true
+	SetBuildInfo(BuildInfo{Version: "1.2.3", Commit: "abc123", BuildDate: "2026-01-02"})
+	lookPathFunc = func(name string) (string, error) {
+		if name == "git" {
+			return "/usr/bin/git", nil
+		}
+		return "", exec.ErrNotFound
+	}
+}
+
+func TestNewVersionCmd_Text(t *testing.T) {
+	stubVersionEnv(t)
+	c := NewVersionCmd(&mockReleaseIO{})
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetArgs(nil)
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"pmk 1.2.3 (commit abc123, built 2026-01-02)\n",
+		"binder pragma: v1\n",
+		"schemas: diagnostics v1, doctor v1, op-result v1, op-spec v1, parse-result v1, project v1\n",
+		"features: git found, pandoc not found\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestNewVersionCmd_JSON(t *testing.T) {
+	stubVersionEnv(t)
+	c := NewVersionCmd(&mockReleaseIO{})
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetArgs([]string{"--json"})
+	if err := c.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got versionOutput
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if got.Version != "1.2.3" || got.Commit != "abc123" || got.BuildDate != "2026-01-02" {
+		t.Errorf("build info = %+v", got.BuildInfo)
+	}
+	if len(got.BinderPragmas) != 1 || got.BinderPragmas[0] != "v1" {
+		t.Errorf("binderPragmaVersions = %v", got.BinderPragmas)
+	}
+	if got.SchemaVersions["parse-result"] != "1" || got.SchemaVersions["op-result"] != "1" {
+		t.Errorf("schemaVersions = %v", got.SchemaVersions)
+	}
+	if !got.Features["git"] || got.Features["pandoc"] {
+		t.Errorf("features = %v, want git only", got.Features)
+	}
+	if got.GoVersion == "" || got.Platform == "" {
+		t.Errorf("goVersion/platform missing: %+v", got)
+	}
+}
+
+func TestNewVersionCmd_OutputErrors(t *testing.T) {
+	stubVersionEnv(t)
+	for _, args := range [][]string{nil, {"--json"}} {
+		c := NewVersionCmd(&mockReleaseIO{})
+		c.SetOut(&errWriter{err: errors.New("write error")})
+		c.SetErr(new(bytes.Buffer))
+		c.SetArgs(args)
+		if err := c.Execute(); err == nil {
+			t.Errorf("args %v: expected error on write failure", args)
+		}
+	}
+}
+
+func TestNewVersionCmd_Check(t *testing.T) {
+	tests := []struct {
+		name       string
+		latest     string
+		version    string
+		disabled   bool
+		wantText   string
+		wantUpdate string
+	}{
+		{"newer release", "1.3.0", "1.2.3", false, "latest: 1.3.0 (update available; run pmk self-update)\n", "true"},
+		{"self-update disabled", "1.3.0", "1.2.3", true, "latest: 1.3.0 (update available)\n", "true"},
+		{"up to date", "1.2.3", "1.2.3", false, "latest: 1.2.3 (up to date)\n", "false"},
+		{"development build", "1.3.0", "dev", false, "latest: 1.3.0 (cannot compare with this build)\n", "null"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubVersionEnv(t)
+			buildInfo.Version = tt.version
+			selfUpdateEnabled = !tt.disabled
+			io := newReleaseFeed(t, tt.latest, "x")
+
+			c := NewVersionCmd(io)
+			out := new(bytes.Buffer)
+			c.SetOut(out)
+			c.SetArgs([]string{"--check"})
+			if err := c.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.HasSuffix(out.String(), tt.wantText) {
+				t.Errorf("output = %q, want it to end with %q", out, tt.wantText)
+			}
+
+			c = NewVersionCmd(io)
+			out.Reset()
+			c.SetOut(out)
+			c.SetArgs([]string{"--check", "--json"})
+			if err := c.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			want := `"latest":{"version":"` + tt.latest + `","url":"https://example.com/v` + tt.latest + `","updateAvailable":` + tt.wantUpdate + `}`
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("JSON = %s, want it to contain %s", out, want)
+			}
+		})
+	}
+}
+
+func TestNewVersionCmd_CheckFails(t *testing.T) {
+	stubVersionEnv(t)
+	c := NewVersionCmd(&mockReleaseIO{fetchErr: errors.New("offline")})
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--check"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "checking for releases") {
+		t.Errorf("error = %v, want a release check error", err)
+	}
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/eykd/prosemark-go/internal/watch"
+)
+
+// ProjectWatcher reports changes to a project's files. Commands with
+// --watch discover it on their IO by type assertion, like OpJournal.
+type ProjectWatcher interface {
+	// Watch calls changed with the project-relative slash paths of the files
+	// under dir that change until ctx is done, once per burst of changes
+	// ended by window without another (0 for the project config's
+	// watch.debounce), checking every interval where file notifications are
+	// unavailable.
+	Watch(ctx context.Context, dir string, interval, window time.Duration, changed func(paths []string)) error
+}
+
+// projectWatcher provides ProjectWatcher with file notifications, polling
+// the project directory where they are unavailable.
+// Embed this in file-IO structs alongside binderLocker.
+type projectWatcher struct{}
+
+// newDebouncer creates the Debouncer a projectWatcher reports through.
+// Override in tests to see the window chosen.
+var newDebouncer = watch.NewDebouncer
+
+// Watch watches dir for changed files, reporting them in debounced bursts.
+// A window of 0 is taken from the config of the project in dir, or is
+// watch.DefaultWindow when the config sets none or cannot be read: watching
+// goes on, and doctor reports the config (AUD008).
+func (projectWatcher) Watch(ctx context.Context, dir string, interval, window time.Duration, changed func(paths []string)) error {
+	if window == 0 {
+		settings, _ := readProjectSettings(fsio.ReadFile, dir)
+		window = settings.WatchDebounce
+	}
+	d := newDebouncer(window, changed)
+	defer d.Stop()
+	return watch.Watch(ctx, dir, interval, d)
+}
+
+// watchEvent is one line of --watch output: the diagnostics of one run, and
+// the files whose change triggered it. The first run has no changed files;
+// a run that fails reports its error instead of diagnostics.
+type watchEvent struct {
+	Version     string   `json:"version"`
+	Changed     []string `json:"changed,omitempty"`
+	Diagnostics any      `json:"diagnostics,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// addWatchFlags registers --watch, --poll-interval, and --debounce.
+func addWatchFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("watch", false, "re-run whenever project files change, streaming one line of JSON diagnostics per run")
+	cmd.Flags().Duration("poll-interval", watch.DefaultPollInterval, "with --watch, how often to check project files for changes where file notifications are unavailable")
+	addDebounceFlag(cmd, "with --watch, ")
+}
+
+// addDebounceFlag registers --debounce, its usage led by prefix.
+func addDebounceFlag(cmd *cobra.Command, prefix string) {
+	cmd.Flags().Duration("debounce", 0, prefix+"how long a burst of changes must be quiet before re-running (default: watch.debounce in .prosemark.yml, else 250ms)")
+}
+
+// debounceFromCmd returns the --debounce window, or 0 when it was not given
+// and the project config decides.
+func debounceFromCmd(cmd *cobra.Command) (time.Duration, error) {
+	window, _ := cmd.Flags().GetDuration("debounce")
+	if cmd.Flags().Changed("debounce") && window <= 0 {
+		return 0, fmt.Errorf("--debounce must be positive")
+	}
+	return window, nil
+}
+
+// watchRequested reports whether --watch was given.
+func watchRequested(cmd *cobra.Command) bool {
+	on, _ := cmd.Flags().GetBool("watch")
+	return on
+}
+
+// runWatch runs check once, then again after each burst of changes to the
+// files under dir, writing each run's diagnostics as one line of JSON. It
+// returns when the command's context is done. A failed run is reported and
+// watching goes on, since the next change may fix it.
+func runWatch(cmd *cobra.Command, io any, dir string, check func(ctx context.Context) (any, error)) error {
+	watcher, ok := io.(ProjectWatcher)
+	if !ok {
+		return fmt.Errorf("--watch is not supported: no file watcher available")
+	}
+	interval, _ := cmd.Flags().GetDuration("poll-interval")
+	if interval <= 0 {
+		return fmt.Errorf("--poll-interval must be positive")
+	}
+	window, err := debounceFromCmd(cmd)
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	w := cmd.OutOrStdout()
+	emit := func(changed []string) {
+		event := watchEvent{Version: "1", Changed: changed}
+		if diags, err := check(ctx); err != nil {
+			event.Error = err.Error()
+		} else {
+			event.Diagnostics = diags
+		}
+		// Diagnostics are plain strings and numbers, which always encode.
+		line, _ := json.Marshal(event)
+		_, _ = w.Write(append(line, '\n'))
+	}
+	emit(nil)
+	return watcher.Watch(ctx, dir, interval, window, emit)
+}
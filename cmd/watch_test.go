@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eykd/prosemark-go/internal/watch"
+)
+
+// mockWatcher is a ProjectWatcher that reports each of bursts in turn, then
+// returns. before, when set, runs ahead of each burst, so a test can change
+// the project the next run sees.
+type mockWatcher struct {
+	bursts   [][]string
+	before   func(i int)
+	dir      string
+	interval time.Duration
+	window   time.Duration
+}
+
+func (w *mockWatcher) Watch(_ context.Context, dir string, interval, window time.Duration, changed func([]string)) error {
+	w.dir, w.interval, w.window = dir, interval, window
+	for i, paths := range w.bursts {
+		if w.before != nil {
+			w.before(i)
+		}
+		changed(paths)
+	}
+	return nil
+}
+
+// watchingParseReader is a ParseReader that can watch.
+type watchingParseReader struct {
+	*mockParseReader
+	*mockWatcher
+}
+
+// watchingDoctorIO is a DoctorIO that can watch.
+type watchingDoctorIO struct {
+	*mockDoctorIO
+	*mockWatcher
+}
+
+// decodeWatchEvents decodes the JSON lines of --watch output.
+func decodeWatchEvents(t *testing.T, out string) []map[string]json.RawMessage {
+	t.Helper()
+	var events []map[string]json.RawMessage
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		var e map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("watch line is not JSON: %v\nline: %q", err, line)
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestParseCmd_Watch_StreamsDiagnosticsPerRun(t *testing.T) {
+	reader := &mockParseReader{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [Missing](missing.md)\n")}
+	watcher := &mockWatcher{
+		bursts: [][]string{{"_binder.md"}},
+		before: func(int) { reader.binderBytes = []byte("<!-- prosemark-binder:v1 -->\n") },
+	}
+	c := newParseCmdWithGetCWD(watchingParseReader{reader, watcher}, func() (string, error) { return "/project", nil })
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetArgs([]string{"--watch", "--poll-interval", "50ms", "--debounce", "2s"})
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("parse --watch: %v", err)
+	}
+	events := decodeWatchEvents(t, out.String())
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2 (initial run and one change):\n%s", len(events), out)
+	}
+	if _, ok := events[0]["changed"]; ok {
+		t.Errorf("initial run should report no changed files: %s", out)
+	}
+	if !strings.Contains(string(events[0]["diagnostics"]), "missing.md") {
+		t.Errorf("initial run should report the missing node: %s", events[0]["diagnostics"])
+	}
+	if got := string(events[1]["changed"]); got != `["_binder.md"]` {
+		t.Errorf("changed = %s, want [\"_binder.md\"]", got)
+	}
+	if got := string(events[1]["diagnostics"]); got != "[]" {
+		t.Errorf("diagnostics after the fix = %s, want []", got)
+	}
+	if watcher.dir != "/project" || watcher.interval != 50*time.Millisecond || watcher.window != 2*time.Second {
+		t.Errorf("watched %q every %v debounced by %v, want /project every 50ms debounced by 2s", watcher.dir, watcher.interval, watcher.window)
+	}
+}
+
+func TestParseCmd_Watch_ReportsRunErrorAndContinues(t *testing.T) {
+	reader := &mockParseReader{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n"), binderErr: errors.New("read failed")}
+	watcher := &mockWatcher{
+		bursts: [][]string{{"_binder.md"}},
+		before: func(int) { reader.binderErr = nil },
+	}
+	c := NewParseCmd(watchingParseReader{reader, watcher})
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetArgs([]string{"--watch"})
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("parse --watch: %v", err)
+	}
+	events := decodeWatchEvents(t, out.String())
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2:\n%s", len(events), out)
+	}
+	if _, ok := events[0]["error"]; !ok {
+		t.Errorf("failed run should report its error: %s", out)
+	}
+	if _, ok := events[1]["error"]; ok {
+		t.Errorf("run after the fix should not report an error: %s", out)
+	}
+}
+
+func TestParseCmd_Watch_Rejections(t *testing.T) {
+	reader := &mockParseReader{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n")}
+	watching := watchingParseReader{reader, &mockWatcher{}}
+	tests := []struct {
+		name   string
+		reader ParseReader
+		args   []string
+		want   string
+	}{
+		{"no watcher", reader, []string{"--watch"}, "not supported"},
+		{"workspace", watching, []string{"--watch", "--workspace"}, "--workspace"},
+		{"github format", watching, []string{"--watch", "--format", "github"}, "--format github"},
+		{"yaml format", watching, []string{"--watch", "--format", "yaml"}, "--format yaml"},
+		{"zero interval", watching, []string{"--watch", "--poll-interval", "0s"}, "--poll-interval"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewParseCmd(tt.reader)
+			c.SetOut(new(bytes.Buffer))
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(tt.args)
+			err := c.Execute()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoctorCmd_Watch_StreamsDiagnosticsPerRun(t *testing.T) {
+	mock := &mockDoctorIO{
+		binderBytes: doctorBinderWithNode(doctorTestNodeUUID),
+		nodeFiles: map[string]nodeFileEntry{
+			".prosemark.yml": {content: []byte("version: \"1\"\n"), exists: true},
+		},
+	}
+	nodeFile := doctorTestNodeUUID + ".md"
+	watcher := &mockWatcher{
+		bursts: [][]string{{nodeFile}},
+		before: func(int) {
+			mock.nodeFiles[nodeFile] = nodeFileEntry{content: validDoctorNodeContent(doctorTestNodeUUID), exists: true}
+		},
+	}
+	c := NewDoctorCmd(watchingDoctorIO{mock, watcher})
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--project", ".", "--watch"})
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("doctor --watch should not fail on diagnostics: %v", err)
+	}
+	events := decodeWatchEvents(t, out.String())
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2:\n%s", len(events), out)
+	}
+	var first []DoctorDiagnosticJSON
+	if err := json.Unmarshal(events[0]["diagnostics"], &first); err != nil || len(first) == 0 {
+		t.Errorf("initial run should report the missing node file: %s", events[0]["diagnostics"])
+	}
+	if got := string(events[1]["diagnostics"]); got != "[]" {
+		t.Errorf("diagnostics after the fix = %s, want []", got)
+	}
+}
+
+func TestDoctorCmd_Watch_ReportsRunErrorAndContinues(t *testing.T) {
+	mock := &mockDoctorIO{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n"), binderErr: errors.New("read failed")}
+	watcher := &mockWatcher{
+		bursts: [][]string{{"_binder.md"}},
+		before: func(int) { mock.binderErr = nil },
+	}
+	c := NewDoctorCmd(watchingDoctorIO{mock, watcher})
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs([]string{"--project", ".", "--watch"})
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("doctor --watch: %v", err)
+	}
+	events := decodeWatchEvents(t, out.String())
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2:\n%s", len(events), out)
+	}
+	if _, ok := events[0]["error"]; !ok {
+		t.Errorf("failed run should report its error: %s", out)
+	}
+	if _, ok := events[1]["error"]; ok {
+		t.Errorf("run after the fix should not report an error: %s", out)
+	}
+	if watcher.window != 0 {
+		t.Errorf("debounce window = %v, want 0 so the project config decides", watcher.window)
+	}
+}
+
+func TestDoctorCmd_Watch_Rejections(t *testing.T) {
+	mock := &mockDoctorIO{binderBytes: doctorBinderWithNode(doctorTestNodeUUID)}
+	watching := watchingDoctorIO{mock, &mockWatcher{}}
+	tests := []struct {
+		name string
+		io   DoctorIO
+		args []string
+		want string
+	}{
+		{"no watcher", mock, []string{"--watch"}, "not supported"},
+		{"out", watching, []string{"--watch", "--out", "report.md"}, "--out"},
+		{"markdown format", watching, []string{"--watch", "--format", "markdown"}, "--format markdown"},
+		{"non-positive debounce", watching, []string{"--watch", "--debounce", "-1s"}, "--debounce must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewDoctorCmd(tt.io)
+			c.SetOut(new(bytes.Buffer))
+			c.SetErr(new(bytes.Buffer))
+			c.SetArgs(append([]string{"--project", "."}, tt.args...))
+			err := c.Execute()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectWatcher_ReturnsWhenContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- projectWatcher{}.Watch(ctx, t.TempDir(), time.Millisecond, 0, func([]string) {})
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch did not return after its context was cancelled")
+	}
+}
+
+func TestProjectWatcher_DebounceWindow(t *testing.T) {
+	orig := newDebouncer
+	t.Cleanup(func() { newDebouncer = orig })
+	var got time.Duration
+	newDebouncer = func(window time.Duration, flush func([]string)) *watch.Debouncer {
+		got = window
+		return orig(window, flush)
+	}
+	tests := []struct {
+		name   string
+		config string
+		window time.Duration
+		want   time.Duration
+	}{
+		{"from config", "watch:\n  debounce: 750ms\n", 0, 750 * time.Millisecond},
+		{"flag over config", "watch:\n  debounce: 750ms\n", 2 * time.Second, 2 * time.Second},
+		{"no config", "", 0, 0},
+		{"invalid config", "watch:\n  debounce: soon\n", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.config != "" {
+				if err := os.WriteFile(filepath.Join(dir, ".prosemark.yml"), []byte(tt.config), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			got = -1
+			_ = projectWatcher{}.Watch(ctx, dir, time.Millisecond, tt.window, func([]string) {})
+			if got != tt.want {
+				t.Errorf("window = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+var _ ProjectWatcher = projectWatcher{}
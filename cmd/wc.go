@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/stats"
+)
+
+// WCIO handles I/O for the wc command.
+type WCIO interface {
+	core.ParseIO
+	// ReadNodeFile reads the node file at path, unlocking a locked body.
+	ReadNodeFile(path string) ([]byte, error)
+	// AtRevision returns a WCIO that reads the project as committed at the
+	// git revision rev.
+	AtRevision(rev string) WCIO
+}
+
+// wcOutput is the JSON output schema for wc.
+type wcOutput struct {
+	Version  string              `json:"version"`
+	Nodes    []*stats.NodeCounts `json:"nodes"`
+	Total    stats.Counts        `json:"total"`
+	Progress *stats.Progress     `json:"progress,omitempty"`
+}
+
+// NewWCCmd creates the wc subcommand.
+func NewWCCmd(io WCIO) *cobra.Command {
+	return newWCCmdWithGetCWD(io, os.Getwd)
+}
+
+func newWCCmdWithGetCWD(io WCIO, getwd func() (string, error)) *cobra.Command {
+	var (
+		jsonMode bool
+		goal     int
+		rev      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "wc",
+		Short: "Count the words of every node in the binder",
+		Long: "Count the words of every binder node's prose (frontmatter and comments\n" +
+			"excluded), with each node's own count and the total of its subtree. A node\n" +
+			"file listed more than once is counted where it first appears. A locked\n" +
+			"body is counted only when $" + fsio.KeyFileEnv + " names the key file.\n" +
+			"--target N also reports progress toward a goal of N words. --rev counts the\n" +
+			"project as committed at a git revision, read without checking it out.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if cmd.Flags().Changed("target") && goal <= 0 {
+				return fmt.Errorf("--target must be a positive word count")
+			}
+			binderPath, err := resolveBinderPathFromCmd(cmd, getwd)
+			if err != nil {
+				return err
+			}
+			if rev != "" {
+				io = io.AtRevision(rev)
+			}
+			parsed, err := core.Parse(cmd.Context(), io, binderPath, core.ParseOptions{})
+			if err != nil {
+				return binderReadError(err, rev)
+			}
+			if parsed.Err != nil {
+				return fmt.Errorf("cannot parse binder: %w", parsed.Err)
+			}
+
+			projectDir := filepath.Dir(binderPath)
+			nodes, total, err := stats.Tally(parsed.Result.Root, func(target string) (stats.Counts, error) {
+				return countNodeFile(cmd, io, projectDir, target)
+			})
+			if err != nil {
+				return err
+			}
+			var progress *stats.Progress
+			if goal > 0 {
+				p := stats.NewProgress(total.Words, goal)
+				progress = &p
+			}
+
+			if jsonMode {
+				out := wcOutput{Version: "1", Nodes: nodes, Total: total, Progress: progress}
+				if err := encodeOutput(cmd, out); err != nil {
+					return fmt.Errorf("encoding output: %w", err)
+				}
+				return nil
+			}
+
+			var b strings.Builder
+			fmt.Fprintf(&b, "%7s %7s  %s\n", "WORDS", "TOTAL", "NODE")
+			writeWCRows(&b, nodes, "")
+			fmt.Fprintf(&b, "%7s %7d  total\n", "", total.Words)
+			if progress != nil {
+				fmt.Fprintf(&b, "%d / %d words (%d%%), %d to go\n", progress.Words, progress.Goal, progress.Percent, progress.Remaining)
+			}
+			if _, err := fmt.Fprint(cmd.OutOrStdout(), b.String()); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("project", "", "project directory containing the binder (default: current directory)")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Output counts as JSON")
+	addFormatFlag(cmd)
+	cmd.Flags().IntVar(&goal, "target", 0, "report progress toward a goal of this many words")
+	addRevFlag(cmd, &rev)
+	return cmd
+}
+
+// countNodeFile counts the node file of target. Missing files count as
+// empty; files that cannot be read otherwise are reported and count as empty.
+// A locked body that cannot be unlocked is an error, since counting it as
+// empty would report a wrong total.
+func countNodeFile(cmd *cobra.Command, io WCIO, projectDir, target string) (stats.Counts, error) {
+	nodePath, ok := resolveTargetPath(cmd, projectDir, target)
+	if !ok {
+		return stats.Counts{}, nil
+	}
+	content, err := io.ReadNodeFile(nodePath)
+	if errors.Is(err, node.ErrLockedBody) {
+		return stats.Counts{}, fmt.Errorf("%s: %w", target, err)
+	}
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: skipping unreadable node file %s\n", sanitizePath(target))
+		}
+		return stats.Counts{}, nil
+	}
+	return stats.CountNode(content), nil
+}
+
+// writeWCRows writes one row per node, its title indented by depth.
+func writeWCRows(b *strings.Builder, nodes []*stats.NodeCounts, indent string) {
+	for _, n := range nodes {
+		fmt.Fprintf(b, "%7d %7d  %s%s\n", n.Own.Words, n.Total.Words, indent, sanitizePath(n.Title))
+		writeWCRows(b, n.Children, indent+"  ")
+	}
+}
+
+// fileWCIO implements WCIO using OS file I/O, reading the work tree or,
+// when rev is set, that git revision.
+type fileWCIO struct {
+	rev string
+}
+
+// ReadBinder reads the binder file at path.
+func (f fileWCIO) ReadBinder(ctx context.Context, path string) ([]byte, error) {
+	if f.rev != "" {
+		return fsio.ReadBinderAtRevision(ctx, f.rev, path)
+	}
+	return fsio.ReadBinder(path)
+}
+
+// ScanProject scans the project directory for .md files.
+func (f fileWCIO) ScanProject(ctx context.Context, binderPath string) (*binder.Project, error) {
+	if f.rev != "" {
+		return fsio.ScanProjectAtRevision(ctx, f.rev, binderPath)
+	}
+	return fsio.ScanProject(ctx, binderPath)
+}
+
+// ReadNodeFile reads the node file at path, unlocking a locked body.
+func (f fileWCIO) ReadNodeFile(path string) ([]byte, error) {
+	if f.rev != "" {
+		return fsio.ReadNodeFileAtRevision(context.Background(), f.rev, path)
+	}
+	return fsio.ReadNodeFile(path)
+}
+
+// AtRevision returns a fileWCIO reading the git revision rev.
+func (f fileWCIO) AtRevision(rev string) WCIO {
+	return fileWCIO{rev: rev}
+}
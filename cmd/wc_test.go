@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// mockWCIO is a test double for WCIO.
+type mockWCIO struct {
+	mockTreeIO
+	files   map[string]string
+	readErr map[string]error
+	// revs holds the project as of each git revision; others have no binder.
+	revs map[string]*mockWCIO
+}
+
+func (m *mockWCIO) AtRevision(rev string) WCIO {
+	if r, ok := m.revs[rev]; ok {
+		return r
+	}
+	return &mockWCIO{mockTreeIO: mockTreeIO{binderErr: os.ErrNotExist}}
+}
+
+func (m *mockWCIO) ReadNodeFile(path string) ([]byte, error) {
+	if err := m.readErr[path]; err != nil {
+		return nil, err
+	}
+	content, ok := m.files[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return []byte(content), nil
+}
+
+const wcTestBinder = "<!-- prosemark-binder:v1 -->\n" +
+	"- [Part One](part1.md)\n" +
+	"  - [Chapter One](ch1.md)\n" +
+	"  - [Draft]()\n" +
+	"- [Part Two](part2.md)\n" +
+	"- [Chapter One again](ch1.md)\n"
+
+func newWCTestIO() *mockWCIO {
+	return &mockWCIO{
+		mockTreeIO: mockTreeIO{binderBytes: []byte(wcTestBinder)},
+		files: map[string]string{
+			"part1.md": "---\ntitle: Part One\n---\nTwo words.\n",
+			"ch1.md":   "It was a dark night. %% todo %%\n",
+		},
+	}
+}
+
+func runWCCmd(t *testing.T, mock *mockWCIO, args ...string) (string, string, error) {
+	t.Helper()
+	c := NewWCCmd(mock)
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(errOut)
+	c.SetArgs(append(args, "--project", "."))
+	err := c.Execute()
+	return out.String(), errOut.String(), err
+}
+
+func TestWC_PrintsCounts(t *testing.T) {
+	out, stderr, err := runWCCmd(t, newWCTestIO(), "--target", "20")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "  WORDS   TOTAL  NODE\n" +
+		"      2       7  Part One\n" +
+		"      5       5    Chapter One\n" +
+		"      0       0    Draft\n" +
+		"      0       0  Part Two\n" +
+		"      0       0  Chapter One again\n" +
+		"              7  total\n" +
+		"7 / 20 words (35%), 13 to go\n"
+	if out != want {
+		t.Errorf("wc =\n%s\nwant\n%s", out, want)
+	}
+	if stderr != "" {
+		t.Errorf("stderr = %q, want missing files skipped silently", stderr)
+	}
+}
+
+func TestWC_Revision(t *testing.T) {
+	mock := newWCTestIO()
+	mock.revs = map[string]*mockWCIO{"v1": {
+		mockTreeIO: mockTreeIO{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [Part One](part1.md)\n")},
+		files:      map[string]string{"part1.md": "Three short words.\n"},
+	}}
+	out, _, err := runWCCmd(t, mock, "--rev", "v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "  WORDS   TOTAL  NODE\n      3       3  Part One\n              3  total\n"; out != want {
+		t.Errorf("wc =\n%s\nwant\n%s", out, want)
+	}
+	if _, _, err := runWCCmd(t, mock, "--rev", "v0"); err == nil || !strings.Contains(err.Error(), "no binder at revision v0") {
+		t.Errorf("error = %v, want no binder at revision", err)
+	}
+}
+
+func TestWC_JSON(t *testing.T) {
+	out, _, err := runWCCmd(t, newWCTestIO(), "--json", "--target", "5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got wcOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if got.Total.Words != 7 || got.Total.Characters != 25 || len(got.Nodes) != 3 || len(got.Nodes[0].Children) != 2 {
+		t.Errorf("output = %s", out)
+	}
+	if got.Progress == nil || got.Progress.Percent != 140 || got.Progress.Remaining != 0 {
+		t.Errorf("progress = %+v", got.Progress)
+	}
+
+	out, _, _ = runWCCmd(t, newWCTestIO(), "--json")
+	if strings.Contains(out, "progress") {
+		t.Errorf("output = %s, want no progress without --target", out)
+	}
+}
+
+func TestWC_UnreadableNodeFile(t *testing.T) {
+	mock := newWCTestIO()
+	mock.readErr = map[string]error{"ch1.md": errors.New("permission denied")}
+	out, stderr, err := runWCCmd(t, mock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stderr, "warning: skipping unreadable node file ch1.md") {
+		t.Errorf("stderr = %q", stderr)
+	}
+	if !strings.Contains(out, "      2  total\n") {
+		t.Errorf("stdout = %q", out)
+	}
+}
+
+func TestWC_LockedBodyWithoutKey(t *testing.T) {
+	t.Setenv(fsio.KeyFileEnv, "")
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "_binder.md"), []byte(wcTestBinder), 0600); err != nil {
+		t.Fatal(err)
+	}
+	locked := lockedContent(t, []byte("---\nid: ch1\n---\nIt was a dark night.\n"), testBodyKey(t))
+	if err := os.WriteFile(filepath.Join(dir, "ch1.md"), locked, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, c := range map[string]*cobra.Command{"wc": NewWCCmd(fileWCIO{}), "count-chapters": NewCountChaptersCmd(fileWCIO{})} {
+		t.Run(name, func(t *testing.T) {
+			out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+			c.SetOut(out)
+			c.SetErr(errOut)
+			c.SetArgs([]string{"--project", dir})
+			err := c.Execute()
+			if !errors.Is(err, node.ErrLockedBody) || !strings.HasPrefix(err.Error(), "ch1.md: ") || !strings.Contains(err.Error(), fsio.KeyFileEnv) {
+				t.Errorf("err = %v, want a locked-body error naming ch1.md and %s", err, fsio.KeyFileEnv)
+			}
+			if out.Len() != 0 || strings.Contains(errOut.String(), "warning") {
+				t.Errorf("stdout = %q, stderr = %q; want no report and no skip warning", out, errOut)
+			}
+		})
+	}
+}
+
+func TestWC_EscapingTargetNotRead(t *testing.T) {
+	mock := &mockWCIO{mockTreeIO: mockTreeIO{binderBytes: []byte("<!-- prosemark-binder:v1 -->\n- [Escapes](a/../../outside.md)\n")}}
+	mock.readErr = map[string]error{"a/../../outside.md": errors.New("should not be read")}
+	_, stderr, err := runWCCmd(t, mock)
+	if err != nil || stderr != "error: Link target resolves outside the project root: a/../../outside.md (BNDE002)\n" {
+		t.Errorf("err = %v, stderr = %q; want the BNDE002 diagnostic", err, stderr)
+	}
+}
+
+func TestWC_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    *mockWCIO
+		args    []string
+		wantErr string
+	}{
+		{"zero target", newWCTestIO(), []string{"--target", "0"}, "--target must be a positive word count"},
+		{"binder missing", &mockWCIO{mockTreeIO: mockTreeIO{binderErr: os.ErrNotExist}}, nil, "project not initialized"},
+		{"binder unreadable", &mockWCIO{mockTreeIO: mockTreeIO{binderErr: errors.New("io")}}, nil, "reading binder"},
+		{"invalid binder", &mockWCIO{mockTreeIO: mockTreeIO{binderBytes: []byte("\xff\xfe")}}, nil, "cannot parse binder"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runWCCmd(t, tt.mock, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWC_OutputErrors(t *testing.T) {
+	for _, args := range [][]string{nil, {"--json"}} {
+		c := NewWCCmd(newWCTestIO())
+		c.SetOut(&errWriter{err: errors.New("closed")})
+		c.SetErr(new(bytes.Buffer))
+		c.SetArgs(append(args, "--project", "."))
+		if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "output") {
+			t.Errorf("args %v: error = %v, want an output error", args, err)
+		}
+	}
+}
+
+func TestWC_GetwdError(t *testing.T) {
+	c := newWCCmdWithGetCWD(newWCTestIO(), func() (string, error) { return "", errors.New("getwd failed") })
+	c.SetOut(new(bytes.Buffer))
+	c.SetErr(new(bytes.Buffer))
+	c.SetArgs(nil)
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "getwd failed") {
+		t.Errorf("error = %v, want getwd failure", err)
+	}
+}
+
+func TestFileWCIO_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	if err := os.WriteFile(binderPath, []byte(wcTestBinder), 0600); err != nil {
+		t.Fatal(err)
+	}
+	nodePath := filepath.Join(dir, "ch1.md")
+	if err := os.WriteFile(nodePath, []byte("body\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fio := fileWCIO{}
+	if got, err := fio.ReadBinder(context.Background(), binderPath); err != nil || string(got) != wcTestBinder {
+		t.Errorf("ReadBinder = %q, %v", got, err)
+	}
+	if _, err := fio.ScanProject(context.Background(), binderPath); err != nil {
+		t.Errorf("ScanProject: %v", err)
+	}
+	if got, err := fio.ReadNodeFile(nodePath); err != nil || string(got) != "body\n" {
+		t.Errorf("ReadNodeFile = %q, %v", got, err)
+	}
+}
+
+func TestFileWCIO_AtRevision(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	if err := os.WriteFile(binderPath, []byte(wcTestBinder), 0600); err != nil {
+		t.Fatal(err)
+	}
+	nodePath := filepath.Join(dir, "ch1.md")
+	if err := os.WriteFile(nodePath, []byte("body\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	gitCommitAll(t, dir)
+	if err := os.WriteFile(nodePath, []byte("changed\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fio := fileWCIO{}.AtRevision("HEAD")
+	if got, err := fio.ReadBinder(context.Background(), binderPath); err != nil || string(got) != wcTestBinder {
+		t.Errorf("ReadBinder = %q, %v; want the committed binder", got, err)
+	}
+	if _, err := fio.ScanProject(context.Background(), binderPath); err != nil {
+		t.Errorf("ScanProject: %v", err)
+	}
+	if got, err := fio.ReadNodeFile(nodePath); err != nil || string(got) != "body\n" {
+		t.Errorf("ReadNodeFile = %q, %v; want the committed node file", got, err)
+	}
+}
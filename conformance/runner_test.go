@@ -19,6 +19,8 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
 )
 
 // pmkBinary is the absolute path to the compiled pmk binary, set by TestMain.
@@ -129,10 +131,14 @@ func runParseFixture(t *testing.T, fixturePath string) {
 	if err != nil {
 		t.Fatalf("read expected-parse.json: %v", err)
 	}
-	actualParseRaw, err := json.Marshal(map[string]interface{}{
+	actualParse := map[string]interface{}{
 		"version": actual.Version,
 		"root":    actual.Root,
-	})
+	}
+	if len(actual.Fenced) > 0 {
+		actualParse["fenced"] = actual.Fenced
+	}
+	actualParseRaw, err := json.Marshal(actualParse)
 	if err != nil {
 		t.Fatalf("marshal actual parse output: %v", err)
 	}
@@ -235,7 +241,7 @@ func runOpsFixture(t *testing.T, fixturePath string) {
 	if err != nil {
 		t.Fatalf("read op.json: %v", err)
 	}
-	var spec opSpec
+	var spec binder.OpSpec
 	if err := json.Unmarshal(opRaw, &spec); err != nil {
 		t.Fatalf("parse op.json: %v", err)
 	}
@@ -367,68 +373,26 @@ func runStabilityFixture(t *testing.T, fixturePath, binderPath string, inputBind
 // op.json type definitions and CLI argument builders
 // ---------------------------------------------------------------------------
 
-// opSpec is the top-level structure of op.json.
-type opSpec struct {
-	Version   string          `json:"version"`
-	Operation string          `json:"operation"`
-	Params    json.RawMessage `json:"params"`
-}
-
-type addChildParamsJSON struct {
-	ParentSelector   string `json:"parentSelector"`
-	Target           string `json:"target"`
-	Title            string `json:"title"`
-	Position         string `json:"position"`
-	At               *int   `json:"at"`
-	PositionIndex    *int   `json:"positionIndex"`
-	Before           string `json:"before"`
-	After            string `json:"after"`
-	PositionSelector string `json:"positionSelector"`
-	Force            bool   `json:"force"`
-}
-
-type deleteParamsJSON struct {
-	Selector string `json:"selector"`
-	Yes      bool   `json:"yes"`
-}
-
-type moveParamsJSON struct {
-	SourceSelector            string `json:"sourceSelector"`
-	DestinationParentSelector string `json:"destinationParentSelector"`
-	Position                  string `json:"position"`
-	At                        *int   `json:"at"`
-	Before                    string `json:"before"`
-	After                     string `json:"after"`
-	Yes                       bool   `json:"yes"`
-}
-
-// buildOpArgs converts an opSpec into CLI flag strings for the named operation.
-func buildOpArgs(spec opSpec) ([]string, error) {
-	switch spec.Operation {
-	case "add":
-		var p addChildParamsJSON
-		if err := json.Unmarshal(spec.Params, &p); err != nil {
-			return nil, fmt.Errorf("parse add params: %w", err)
-		}
+// buildOpArgs converts an op.json spec into CLI flag strings for the named
+// operation.
+func buildOpArgs(spec binder.OpSpec) ([]string, error) {
+	params, err := spec.DecodeParams()
+	if err != nil {
+		return nil, err
+	}
+	switch p := params.(type) {
+	case binder.AddChildParams:
 		return buildAddChildArgs(p), nil
-	case "delete":
-		var p deleteParamsJSON
-		if err := json.Unmarshal(spec.Params, &p); err != nil {
-			return nil, fmt.Errorf("parse delete params: %w", err)
-		}
+	case binder.DeleteParams:
 		return buildDeleteArgs(p), nil
-	case "move":
-		var p moveParamsJSON
-		if err := json.Unmarshal(spec.Params, &p); err != nil {
-			return nil, fmt.Errorf("parse move params: %w", err)
-		}
+	case binder.MoveParams:
 		return buildMoveArgs(p), nil
 	default:
-		return nil, fmt.Errorf("unknown operation %q", spec.Operation)
+		return nil, fmt.Errorf("unsupported operation %q", spec.Operation)
 	}
 }
 
-func buildAddChildArgs(p addChildParamsJSON) []string {
+func buildAddChildArgs(p binder.AddChildParams) []string {
 	args := []string{"--parent", p.ParentSelector, "--target", p.Target}
 	if p.Title != "" {
 		args = append(args, "--title", p.Title)
@@ -436,30 +400,14 @@ func buildAddChildArgs(p addChildParamsJSON) []string {
 	if p.Position == "first" {
 		args = append(args, "--first")
 	}
-	// Support both "at" and "positionIndex" field names.
-	atVal := p.At
-	if atVal == nil {
-		atVal = p.PositionIndex
-	}
-	if atVal != nil {
-		args = append(args, "--at", strconv.Itoa(*atVal))
-	}
-	// Support both "before"/"after" and "positionSelector" + "position" field names.
-	before := p.Before
-	after := p.After
-	if before == "" && after == "" && p.PositionSelector != "" {
-		switch p.Position {
-		case "before":
-			before = p.PositionSelector
-		case "after":
-			after = p.PositionSelector
-		}
+	if p.At != nil {
+		args = append(args, "--at", strconv.Itoa(*p.At))
 	}
-	if before != "" {
-		args = append(args, "--before", before)
+	if p.Before != "" {
+		args = append(args, "--before", p.Before)
 	}
-	if after != "" {
-		args = append(args, "--after", after)
+	if p.After != "" {
+		args = append(args, "--after", p.After)
 	}
 	if p.Force {
 		args = append(args, "--force")
@@ -467,7 +415,7 @@ func buildAddChildArgs(p addChildParamsJSON) []string {
 	return args
 }
 
-func buildDeleteArgs(p deleteParamsJSON) []string {
+func buildDeleteArgs(p binder.DeleteParams) []string {
 	args := []string{"--selector", p.Selector}
 	if p.Yes {
 		args = append(args, "--yes")
@@ -475,7 +423,7 @@ func buildDeleteArgs(p deleteParamsJSON) []string {
 	return args
 }
 
-func buildMoveArgs(p moveParamsJSON) []string {
+func buildMoveArgs(p binder.MoveParams) []string {
 	args := []string{"--source", p.SourceSelector, "--dest", p.DestinationParentSelector}
 	if p.Position == "first" {
 		args = append(args, "--first")
@@ -492,6 +440,9 @@ func buildMoveArgs(p moveParamsJSON) []string {
 	if p.Yes {
 		args = append(args, "--yes")
 	}
+	if p.PreserveExtras {
+		args = append(args, "--preserve-extras")
+	}
 	return args
 }
 
@@ -503,6 +454,7 @@ func buildMoveArgs(p moveParamsJSON) []string {
 type parseJSONOutput struct {
 	Version     string           `json:"version"`
 	Root        json.RawMessage  `json:"root"`
+	Fenced      json.RawMessage  `json:"fenced,omitempty"`
 	Diagnostics []diagnosticItem `json:"diagnostics"`
 }
 
@@ -703,7 +655,7 @@ func copyFile(src, dst string) error {
 // copyFixtureStubs walks fixtureDir recursively and creates zero-byte stub
 // files in tmpDir for every .md file found, preserving relative paths, except
 // those whose base name appears in skip. This populates the temp working
-// directory with stub files that ScanProjectImpl will discover when resolving
+// directory with stub files that fsio.ScanProject will discover when resolving
 // wikilinks.
 func copyFixtureStubs(t *testing.T, fixtureDir, tmpDir string, skip ...string) {
 	t.Helper()
@@ -876,7 +828,7 @@ func validateChangedField(t *testing.T, fixturePath string) {
 	if err != nil {
 		t.Fatalf("read op.json: %v", err)
 	}
-	var spec opSpec
+	var spec binder.OpSpec
 	if err := json.Unmarshal(opRaw, &spec); err != nil {
 		t.Fatalf("parse op.json: %v", err)
 	}
@@ -219,7 +219,7 @@ func Test_Moving_a_chapter_with_non_structural_text_destroys_that_text_with_a_wa
 	writeFile(t, dir, "_binder.md",
 		"<!-- prosemark-binder:v1 -->\n\n"+
 			"- [Part One](part1.md)\n"+
-			"  - [ ] [Chapter One](ch1.md)\n"+
+			"  - [Chapter One](ch1.md) (first draft)\n"+
 			"- [Part Two](part2.md)\n")
 	writeFile(t, dir, "part1.md", "")
 	writeFile(t, dir, "ch1.md", "")
@@ -237,9 +237,10 @@ func Test_Moving_a_chapter_with_non_structural_text_destroys_that_text_with_a_wa
 	if !strings.Contains(content, "ch1.md") {
 		t.Fatalf("expected ch1.md at destination\ncontent: %s", content)
 	}
-	// The checkbox prefix [ ] should be destroyed
-	if strings.Contains(content, "[ ] [Chapter One]") {
-		t.Errorf("expected non-structural '[ ]' prefix to be removed\ncontent: %s", content)
+	// The trailing text should be destroyed. (A checkbox prefix is node
+	// metadata and survives the move, so it no longer serves here.)
+	if strings.Contains(content, "(first draft)") {
+		t.Errorf("expected non-structural trailing text to be removed\ncontent: %s", content)
 	}
 	// THEN a "non-structural content destroyed" warning is included in the result.
 	if !strings.Contains(result.Stderr, "OPW003") {
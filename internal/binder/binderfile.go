@@ -0,0 +1,73 @@
+package binder
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// DefaultBinderFilename is the binder filename used when a project does not
+// name its active binder explicitly.
+const DefaultBinderFilename = "_binder.md"
+
+// ActiveBinderFile returns the active binder path relative to the project
+// root. A nil project or an empty BinderFile yields DefaultBinderFilename.
+func (p *Project) ActiveBinderFile() string {
+	if p == nil || p.BinderFile == "" {
+		return DefaultBinderFilename
+	}
+	return normalizeBinderPath(p.BinderFile)
+}
+
+// ValidateBinderName checks that name can name a binder file: a Markdown
+// filename directly in the project directory.
+func ValidateBinderName(name string) error {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == ".md" || !strings.HasSuffix(name, ".md") {
+		return fmt.Errorf("binder %q must be a .md filename in the project directory", name)
+	}
+	return nil
+}
+
+// ValidateBinderPath checks that name can name one of a project's further
+// binders: a binder filename, which marks every file of that name in the
+// project as a binder, or the slash-separated path of one binder file in a
+// subdirectory, relative to the project directory.
+func ValidateBinderPath(name string) error {
+	if !strings.Contains(name, "/") {
+		return ValidateBinderName(name)
+	}
+	if !strings.HasSuffix(name, ".md") || path.Base(name) == ".md" || strings.Contains(name, `\`) ||
+		path.IsAbs(name) || !fs.ValidPath(normalizeBinderPath(name)) {
+		return fmt.Errorf("binder %q must be a .md file in the project directory or one of its subdirectories", name)
+	}
+	return nil
+}
+
+// IsBinderTarget reports whether target refers to a binder file rather than
+// a content node: the active binder or any of the project's alternate binders.
+// It is the single check behind BNDW008 (SelfReferentialLink) and OPE005
+// (TargetIsBinder). A "./" prefix and redundant path segments are ignored.
+func IsBinderTarget(target string, project *Project) bool {
+	if target == "" {
+		return false
+	}
+	target = normalizeBinderPath(target)
+	if target == project.ActiveBinderFile() {
+		return true
+	}
+	if project == nil {
+		return false
+	}
+	for _, alt := range project.AltBinders {
+		if target == normalizeBinderPath(alt) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeBinderPath cleans p for binder-path comparison.
+func normalizeBinderPath(p string) string {
+	return path.Clean(strings.TrimPrefix(p, "./"))
+}
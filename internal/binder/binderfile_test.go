@@ -0,0 +1,71 @@
+package binder_test
+
+import (
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestIsBinderTarget(t *testing.T) {
+	nested := &binder.Project{BinderFile: "book.md", AltBinders: []string{"part-two/_binder.md"}}
+	tests := []struct {
+		name    string
+		target  string
+		project *binder.Project
+		want    bool
+	}{
+		{"default binder, nil project", "_binder.md", nil, true},
+		{"dot-slash prefix", "./_binder.md", nil, true},
+		{"redundant segments", "sub/../_binder.md", nil, true},
+		{"content file", "chapter.md", nil, false},
+		{"empty target", "", nil, false},
+		{"nested default name without project", "part-two/_binder.md", nil, false},
+		{"configured binder filename", "book.md", nested, true},
+		{"default name not active when configured", "_binder.md", nested, false},
+		{"alternate binder", "part-two/_binder.md", nested, true},
+		{"alternate binder dot-slash", "./part-two/_binder.md", nested, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := binder.IsBinderTarget(tt.target, tt.project); got != tt.want {
+				t.Errorf("IsBinderTarget(%q) = %v, want %v", tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProject_ActiveBinderFile(t *testing.T) {
+	var nilProject *binder.Project
+	if got := nilProject.ActiveBinderFile(); got != binder.DefaultBinderFilename {
+		t.Errorf("nil project ActiveBinderFile() = %q, want %q", got, binder.DefaultBinderFilename)
+	}
+	if got := (&binder.Project{BinderFile: "./book.md"}).ActiveBinderFile(); got != "book.md" {
+		t.Errorf("ActiveBinderFile() = %q, want %q", got, "book.md")
+	}
+}
+
+func TestValidateBinderName(t *testing.T) {
+	for _, name := range []string{"_binder.md", "_outtakes.md", "draft.md"} {
+		if err := binder.ValidateBinderName(name); err != nil {
+			t.Errorf("ValidateBinderName(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", ".md", "binder.txt", "part/_binder.md", `part\_binder.md`} {
+		if err := binder.ValidateBinderName(name); err == nil {
+			t.Errorf("ValidateBinderName(%q) = nil, want error", name)
+		}
+	}
+}
+
+func TestValidateBinderPath(t *testing.T) {
+	for _, name := range []string{"_outtakes.md", "part/x_binder.md", "./part/act/_binder.md"} {
+		if err := binder.ValidateBinderPath(name); err != nil {
+			t.Errorf("ValidateBinderPath(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "binder.txt", "part/x.txt", "part/.md", `part/a\b.md`, "/abs/x.md", "../x.md", "part/../../x.md"} {
+		if err := binder.ValidateBinderPath(name); err == nil {
+			t.Errorf("ValidateBinderPath(%q) = nil, want error", name)
+		}
+	}
+}
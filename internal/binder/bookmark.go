@@ -0,0 +1,37 @@
+package binder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// BookmarkPrefix starts a selector that names a bookmark: "@act2" stands
+// for the selector the project's bookmarks give act2.
+const BookmarkPrefix = "@"
+
+// bookmarkNameRE matches a valid bookmark name.
+var bookmarkNameRE = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ValidBookmarkName reports whether name can name a bookmark: letters,
+// digits, "_", ".", and "-", starting with a letter or digit.
+func ValidBookmarkName(name string) bool {
+	return bookmarkNameRE.MatchString(name)
+}
+
+// ResolveBookmark returns the selector that a bookmark selector ("@name")
+// stands for in project's bookmarks, and any other selector unchanged. A
+// bookmark the project does not define is an OPE001 error.
+func ResolveBookmark(selector string, project *Project) (string, *Diagnostic) {
+	name, ok := strings.CutPrefix(selector, BookmarkPrefix)
+	if !ok {
+		return selector, nil
+	}
+	if project != nil {
+		if resolved, ok := project.Bookmarks[name]; ok {
+			return resolved, nil
+		}
+	}
+	d := newSelectorDiag("error", CodeSelectorNoMatch, fmt.Sprintf("bookmark %q is not defined", selector))
+	return selector, &d
+}
@@ -0,0 +1,46 @@
+package binder_test
+
+import (
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestValidBookmarkName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"act2": true, "Act_2.b-c": true, "2nd": true,
+		"": false, "-act": false, "act 2": false, "@act2": false, "act:2": false,
+	} {
+		if got := binder.ValidBookmarkName(name); got != want {
+			t.Errorf("ValidBookmarkName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestResolveBookmark(t *testing.T) {
+	project := &binder.Project{Bookmarks: map[string]string{"act2": "part-two:ch3"}}
+	tests := []struct {
+		name     string
+		selector string
+		project  *binder.Project
+		want     string
+		wantCode string
+	}{
+		{"plain selector", "ch3", project, "ch3", ""},
+		{"bookmark", "@act2", project, "part-two:ch3", ""},
+		{"undefined bookmark", "@act3", project, "@act3", binder.CodeSelectorNoMatch},
+		{"nil project", "@act2", nil, "@act2", binder.CodeSelectorNoMatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diag := binder.ResolveBookmark(tt.selector, tt.project)
+			code := ""
+			if diag != nil {
+				code = diag.Code
+			}
+			if got != tt.want || code != tt.wantCode {
+				t.Errorf("ResolveBookmark(%q) = %q, %q; want %q, %q", tt.selector, got, code, tt.want, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package binder
+
+import "testing"
+
+// TestCaseFixSuggestion_NoFix tests the BNDW009 links that get no fix
+// because their destination cannot be respelled in place.
+func TestCaseFixSuggestion_NoFix(t *testing.T) {
+	tests := []struct {
+		name         string
+		line, opener string
+		wrong, right string
+	}{
+		{"case folding changes the length", "- [Intro](i̇ntro.md)", "](", "i̇ntro.md", "İntro.md"},
+		{"spelled right", "- [Intro](intro.md)", "](", "intro.md", "intro.md"},
+		{"percent-encoded destination", "- [Chapter](my%20chapter.md)", "](", "my chapter.md", "My chapter.md"},
+		{"no opener on the line", "[c]: chapter.md", "](", "chapter.md", "Chapter.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ParseResult{Lines: []string{tt.line}, LineEnds: []string{"\n"}}
+			if got := caseFixSuggestion(r, 1, tt.opener, tt.wrong, tt.right); got != nil {
+				t.Errorf("caseFixSuggestion() = %+v, want nil", got)
+			}
+		})
+	}
+}
+
+// TestWikilinkCaseFix_NoFix tests the BNDW009 wikilinks that get no fix: a
+// line without one, and a stem the target does not end with.
+func TestWikilinkCaseFix_NoFix(t *testing.T) {
+	tests := []struct {
+		name, line, target string
+	}{
+		{"no wikilink", "- [Chapter](chapter.md)", "Chapter.md"},
+		{"stem longer than the target", "- [[Part/Chapter]]", "c.md"},
+		{"stem not the target's", "- [[Chapter]]", "part/Section.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ParseResult{Lines: []string{tt.line}, LineEnds: []string{"\n"}}
+			if got := wikilinkCaseFix(r, 1, tt.target); got != nil {
+				t.Errorf("wikilinkCaseFix() = %+v, want nil", got)
+			}
+		})
+	}
+}
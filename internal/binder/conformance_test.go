@@ -18,11 +18,12 @@ import (
 // parseFixturesDir is the path to the parse conformance fixtures, relative to this file.
 const parseFixturesDir = "../../docs/conformance/v1/parse/fixtures"
 
-// parseConformanceOutput mirrors the JSON shape emitted by cmd.parseOutput (version + root),
-// used to marshal the actual ParseResult for fixture comparison.
+// parseConformanceOutput mirrors the JSON shape emitted by cmd.parseOutput (version + root
+// + fenced), used to marshal the actual ParseResult for fixture comparison.
 type parseConformanceOutput struct {
-	Version string       `json:"version"`
-	Root    *binder.Node `json:"root"`
+	Version string         `json:"version"`
+	Root    *binder.Node   `json:"root"`
+	Fenced  []*binder.Node `json:"fenced,omitempty"`
 }
 
 // diagnosticsConformanceOutput mirrors the expected-diagnostics.json wrapper schema.
@@ -85,6 +86,7 @@ func runParseFixture(t *testing.T, fixturePath string) {
 	actualParseOutput := parseConformanceOutput{
 		Version: result.Version,
 		Root:    result.Root,
+		Fenced:  result.Fenced,
 	}
 	actualParseBytes, err := json.Marshal(actualParseOutput)
 	if err != nil {
@@ -156,15 +158,17 @@ func TestConformance_ParseStability(t *testing.T) {
 
 // scanFixtureProject scans fixtureDir recursively for .md files, excluding
 // those whose base names are listed in skip, and returns a *binder.Project
-// with BinderDir set to ".". This mirrors what ScanProjectImpl does at
-// runtime, allowing unit-level conformance tests to run without project.json.
+// with BinderDir set to ".". Files named binder.DefaultBinderFilename are
+// recorded as alternate (nested) binders. This mirrors what fsio.ScanProject
+// does at runtime, allowing unit-level conformance tests to run without
+// project.json.
 func scanFixtureProject(t *testing.T, fixtureDir string, skip ...string) *binder.Project {
 	t.Helper()
 	skipSet := make(map[string]bool, len(skip))
 	for _, s := range skip {
 		skipSet[s] = true
 	}
-	var files []string
+	var files, altBinders []string
 	err := filepath.WalkDir(fixtureDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -182,6 +186,10 @@ func scanFixtureProject(t *testing.T, fixtureDir string, skip ...string) *binder
 		if skipSet[filepath.Base(rel)] {
 			return nil
 		}
+		if d.Name() == binder.DefaultBinderFilename {
+			altBinders = append(altBinders, filepath.ToSlash(rel))
+			return nil
+		}
 		files = append(files, filepath.ToSlash(rel))
 		return nil
 	})
@@ -191,7 +199,7 @@ func scanFixtureProject(t *testing.T, fixtureDir string, skip ...string) *binder
 	if files == nil {
 		files = []string{}
 	}
-	return &binder.Project{Files: files, BinderDir: "."}
+	return &binder.Project{Files: files, BinderDir: ".", AltBinders: altBinders}
 }
 
 // --- JSON comparison helpers ---
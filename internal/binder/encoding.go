@@ -0,0 +1,68 @@
+package binder
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// CodeInvalidUTF8 is emitted when a binder containing invalid UTF-8 is read
+// in encoding-repair mode: the bad bytes are replaced with U+FFFD so the
+// binder can be inspected, but it must not be rewritten.
+const CodeInvalidUTF8 = "PMKE008"
+
+// maxReportedOffsets caps how many byte offsets the CodeInvalidUTF8 message
+// lists.
+const maxReportedOffsets = 10
+
+// RepairUTF8 replaces each run of invalid UTF-8 bytes in src with U+FFFD.
+// Valid input is returned unchanged with a nil diagnostic. Otherwise the
+// diagnostic is a CodeInvalidUTF8 error listing the 0-based byte offsets of
+// the runs in src and located at the first of them.
+func RepairUTF8(src []byte) ([]byte, *Diagnostic) {
+	if utf8.Valid(src) {
+		return src, nil
+	}
+
+	var out bytes.Buffer
+	var offsets []int
+	inRun := false
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRune(src[i:])
+		if r == utf8.RuneError && size == 1 {
+			if !inRun {
+				offsets = append(offsets, i)
+				out.WriteRune(utf8.RuneError)
+			}
+			inRun = true
+			i++
+			continue
+		}
+		inRun = false
+		out.Write(src[i : i+size])
+		i += size
+	}
+
+	listed := make([]string, 0, maxReportedOffsets)
+	for _, off := range offsets {
+		if len(listed) == maxReportedOffsets {
+			break
+		}
+		listed = append(listed, strconv.Itoa(off))
+	}
+	msg := fmt.Sprintf("binder contains invalid UTF-8 at byte offset %s", strings.Join(listed, ", "))
+	if len(offsets) > 1 {
+		msg = fmt.Sprintf("binder contains %d invalid UTF-8 sequences at byte offsets %s", len(offsets), strings.Join(listed, ", "))
+	}
+	if extra := len(offsets) - len(listed); extra > 0 {
+		msg += fmt.Sprintf(", and %d more", extra)
+	}
+	msg += "; they were read as U+FFFD, so fix them before modifying the binder"
+
+	first := offsets[0]
+	lineStart := bytes.LastIndexByte(src[:first], '\n') + 1
+	loc := &Location{Line: bytes.Count(src[:first], []byte("\n")) + 1, Column: first - lineStart + 1, ByteOffset: first}
+	return out.Bytes(), &Diagnostic{Severity: "error", Code: CodeInvalidUTF8, Message: msg, Location: loc}
+}
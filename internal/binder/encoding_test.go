@@ -0,0 +1,44 @@
+package binder
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRepairUTF8(t *testing.T) {
+	valid := []byte("- [Café](cafe.md)\n")
+	if out, diag := RepairUTF8(valid); string(out) != string(valid) || diag != nil {
+		t.Errorf("valid input = %q, %+v; want it unchanged and no diagnostic", out, diag)
+	}
+
+	tests := []struct {
+		name     string
+		src      string
+		wantOut  string
+		wantMsg  string
+		wantLine int
+		wantCol  int
+	}{
+		{"single byte", "ab\n- [x\xffy](x.md)\n", "ab\n- [x�y](x.md)\n", "invalid UTF-8 at byte offset 7;", 2, 5},
+		{"run collapses", "\xfe\xff\xfeok", "�ok", "invalid UTF-8 at byte offset 0;", 1, 1},
+		{"several runs", "a\xffb\xffc", "a�b�c", "2 invalid UTF-8 sequences at byte offsets 1, 3;", 1, 2},
+		{"offsets capped", strings.Repeat("a\xff", 12), strings.Repeat("a�", 12), "12 invalid UTF-8 sequences at byte offsets 1, 3, 5, 7, 9, 11, 13, 15, 17, 19, and 2 more;", 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, diag := RepairUTF8([]byte(tt.src))
+			if string(out) != tt.wantOut {
+				t.Errorf("output = %q, want %q", out, tt.wantOut)
+			}
+			if diag == nil {
+				t.Fatal("diagnostic = nil")
+			}
+			if diag.Code != CodeInvalidUTF8 || diag.Severity != "error" || !strings.Contains(diag.Message, tt.wantMsg) {
+				t.Errorf("diagnostic = %+v, want a PMKE008 error containing %q", diag, tt.wantMsg)
+			}
+			if diag.Location == nil || diag.Location.Line != tt.wantLine || diag.Location.Column != tt.wantCol {
+				t.Errorf("location = %+v, want line %d column %d", diag.Location, tt.wantLine, tt.wantCol)
+			}
+		})
+	}
+}
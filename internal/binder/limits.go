@@ -0,0 +1,59 @@
+package binder
+
+import "fmt"
+
+// CodeParseLimitExceeded is an implementation-specific error emitted when a
+// binder exceeds one of its ParseLimits. Parsing stops at the limit, so the
+// returned tree is incomplete and operations refuse to write it back.
+const CodeParseLimitExceeded = "PMKE001"
+
+// ParseLimits caps the resources Parse spends on one binder, so a corrupted
+// or hostile input yields a diagnostic instead of unbounded memory use in a
+// long-running process. A zero field uses the DefaultParseLimits value.
+type ParseLimits struct {
+	MaxFileSize   int `json:"maxFileSize,omitempty"`   // bytes of binder source
+	MaxLineLength int `json:"maxLineLength,omitempty"` // bytes in a single line
+	MaxNodes      int `json:"maxNodes,omitempty"`      // nodes, including fenced pseudo-nodes
+	MaxRefDefs    int `json:"maxRefDefs,omitempty"`    // distinct reference definitions
+}
+
+// DefaultParseLimits are the limits Parse applies when the project sets none.
+// MaxFileSize matches the size limit the CLI enforces when reading binders.
+var DefaultParseLimits = ParseLimits{
+	MaxFileSize:   10 * 1024 * 1024,
+	MaxLineLength: 256 * 1024,
+	MaxNodes:      100_000,
+	MaxRefDefs:    100_000,
+}
+
+// parseLimits returns the effective limits for project, which may be nil.
+func (p *Project) parseLimits() ParseLimits {
+	l := DefaultParseLimits
+	if p == nil || p.Limits == nil {
+		return l
+	}
+	for _, f := range []struct{ set, dst *int }{
+		{&p.Limits.MaxFileSize, &l.MaxFileSize},
+		{&p.Limits.MaxLineLength, &l.MaxLineLength},
+		{&p.Limits.MaxNodes, &l.MaxNodes},
+		{&p.Limits.MaxRefDefs, &l.MaxRefDefs},
+	} {
+		if *f.set > 0 {
+			*f.dst = *f.set
+		}
+	}
+	return l
+}
+
+// limitDiagnostic reports that setting (its .prosemark.yml key) was exceeded.
+func limitDiagnostic(what, setting string, limit, line int) Diagnostic {
+	d := Diagnostic{
+		Severity: "error",
+		Code:     CodeParseLimitExceeded,
+		Message:  fmt.Sprintf("%s exceeds the limit of %d (limits.%s); parsing stopped", what, limit, setting),
+	}
+	if line > 0 {
+		d.Location = &Location{Line: line}
+	}
+	return d
+}
@@ -4,16 +4,12 @@ import (
 	"context"
 	"fmt"
 	"net/url"
-	"regexp"
 	"sort"
 	"strings"
 
 	"github.com/eykd/prosemark-go/internal/binder"
 )
 
-// opsInlineLinkRE matches inline markdown links anywhere in a line.
-var opsInlineLinkRE = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s"]+)`)
-
 // parseBinderFn is the parse function used by AddChild. It may be replaced in
 // tests to simulate parse failures.
 var parseBinderFn = binder.Parse
@@ -23,26 +19,51 @@ var parseBinderFn = binder.Parse
 // and diagnostics. On validation or logical error the returned bytes are equal to
 // src (no mutation). Parse errors are surfaced as diagnostics, not as a returned error.
 func AddChild(ctx context.Context, src []byte, project *binder.Project, params binder.AddChildParams) ([]byte, []binder.Diagnostic) {
-	result, parseDiags, err := parseBinderFn(ctx, src, project)
+	p := parseSrc(ctx, parseBinderFn, src, project)
+	edited, _, diags := addChild(p, project, params)
+	return p.output(edited), diags
+}
+
+// AddChildEntries is AddChild that also locates the inserted entries in the
+// returned bytes, in document order.
+func AddChildEntries(ctx context.Context, src []byte, project *binder.Project, params binder.AddChildParams) ([]byte, []binder.AffectedEntry, []binder.Diagnostic) {
+	p := parseSrc(ctx, parseBinderFn, src, project)
+	edited, added, diags := addChild(p, project, params)
+	out := p.output(edited)
+	return out, locateEntries(ctx, out, project, added), diags
+}
+
+// addChild implements AddChild by editing the parsed binder p in place,
+// reporting whether it did and the 0-based line indices of the inserted
+// entries in the edited lines.
+func addChild(p parsed, project *binder.Project, params binder.AddChildParams) (bool, []int, []binder.Diagnostic) {
+	result, parseDiags, err := p.result, p.diags, p.err
 	if err != nil {
-		return src, append(parseDiags, binder.Diagnostic{
+		return false, nil, append(parseDiags, binder.Diagnostic{
 			Severity: "error",
 			Code:     binder.CodeIOOrParseFailure,
 			Message:  fmt.Sprintf("parse error: %v", err),
 		})
 	}
 
-	params.Target = normalizeTargetInput(params.Target)
+	if diag := guardParseErrors(parseDiags, params.ForceParse); diag != nil {
+		return false, nil, append(parseDiags, *diag)
+	}
+	if diag := resolveBookmarks(project, &params.ParentSelector, &params.Before, &params.After); diag != nil {
+		return false, nil, append(parseDiags, *diag)
+	}
+
+	params.Target = NormalizeTarget(params.Target)
 
 	// Validate target path (OPE004, OPE005) before touching the selector.
-	if diag := validateOpTarget(params.Target); diag != nil {
-		return src, append(parseDiags, *diag)
+	if diag := validateOpTarget(params.Target, project); diag != nil {
+		return false, nil, append(parseDiags, *diag)
 	}
 
 	// Evaluate the parent selector (supports deep tree search for non-colon selectors).
-	parents, selDiags := addChildEvalParentSelector(params.ParentSelector, result.Root, result.Lines)
+	parents, selDiags := addChildEvalParentSelector(params.ParentSelector, result.Root, result.Fenced)
 	if len(parents) == 0 {
-		return src, append(parseDiags, selDiags...)
+		return false, nil, append(parseDiags, selDiags...)
 	}
 
 	var allDiags []binder.Diagnostic
@@ -57,7 +78,14 @@ func AddChild(ctx context.Context, src []byte, project *binder.Project, params b
 	if title == "" {
 		title = opStemFromPath(decodedTarget)
 	}
-	title = escapeTitle(title)
+	refs := newRefDefAllocator(result.RefDefs)
+
+	// refAnchor is the current index of the last reference definition (-1 if
+	// none); new definitions are inserted after it.
+	refAnchor := -1
+	for _, d := range result.RefDefs {
+		refAnchor = max(refAnchor, d.Line-1)
+	}
 
 	// Determine line ending from the file's majority style.
 	lineEnd := majorityLineEnding(result.LineEnds)
@@ -68,6 +96,7 @@ func AddChild(ctx context.Context, src []byte, project *binder.Project, params b
 		return parents[i].Line > parents[j].Line
 	})
 
+	var added []int
 	for _, parent := range parents {
 		// Idempotency check (OPW002): skip if target already exists as a direct child.
 		if !params.Force {
@@ -91,34 +120,49 @@ func AddChild(ctx context.Context, src []byte, project *binder.Project, params b
 		// Resolve insertion index among parent's children.
 		insertIdx, diagErr := resolveInsertionIndex(parent, params)
 		if diagErr != nil {
-			return src, append(allDiags, *diagErr)
+			return false, nil, append(allDiags, *diagErr)
 		}
 
 		// Build the new list-item line.
-		indentStr, marker := inferMarkerAndIndent(parent, insertIdx)
-		newLine := indentStr + marker + " [" + title + "](" + decodedTarget + ")"
+		indentStr, marker := inferMarkerAndIndent(parent, insertIdx, project)
+		style := resolveLinkStyle(params.Style, parent, result.Root)
+		link, linkDiag := formatChildLink(style, title, decodedTarget, refs, project)
+		if linkDiag != nil {
+			allDiags = append(allDiags, *linkDiag)
+		}
+		newLine := indentStr + marker + " " + link
 
 		// Find the 0-based position in result.Lines at which to insert.
 		lineIdx := insertionLineIdx(parent, insertIdx, result)
 
 		// For the first child of an empty root, prepend a blank separator line.
 		if parent.Type == "root" && len(parent.Children) == 0 {
-			result.Lines = sliceInsert(result.Lines, lineIdx, "")
-			result.LineEnds = sliceInsert(result.LineEnds, lineIdx, lineEnd)
+			insertLine(result, lineIdx, "", lineEnd)
+			shiftLines(added, lineIdx, 1)
 			lineIdx++
 		}
 
 		// Splice the new line into the ParseResult.
-		result.Lines = sliceInsert(result.Lines, lineIdx, newLine)
-		result.LineEnds = sliceInsert(result.LineEnds, lineIdx, lineEnd)
+		insertLine(result, lineIdx, newLine, lineEnd)
+		shiftLines(added, lineIdx, 1)
+		added = append(added, lineIdx)
+		if lineIdx <= refAnchor {
+			refAnchor++
+		}
+	}
+
+	insertRefDefs(result, refs.added, refAnchor, lineEnd)
+	if refAnchor >= 0 {
+		shiftLines(added, refAnchor+1, len(refs.added))
 	}
 
-	return binder.Serialize(result), allDiags
+	return true, added, allDiags
 }
 
 // validateOpTarget checks OPE004 (absolute path, path escapes root, illegal chars,
-// non-.md extension) and OPE005 (target is binder).
-func validateOpTarget(target string) *binder.Diagnostic {
+// non-.md extension) and OPE005 (target is the active binder or an alternate
+// binder known to project).
+func validateOpTarget(target string, project *binder.Project) *binder.Diagnostic {
 	if isAbsolutePath(target) {
 		return &binder.Diagnostic{
 			Severity: "error",
@@ -147,7 +191,7 @@ func validateOpTarget(target string) *binder.Diagnostic {
 			Message:  fmt.Sprintf("target %q must have a .md extension", target),
 		}
 	}
-	if target == "_binder.md" {
+	if binder.IsBinderTarget(target, project) {
 		return &binder.Diagnostic{
 			Severity: "error",
 			Code:     binder.CodeTargetIsBinder,
@@ -183,28 +227,25 @@ func opEscapesRoot(path string) bool {
 }
 
 // addChildEvalParentSelector finds parent nodes matching selector for AddChild.
-// "." always returns the root node. Selectors containing ":" or "[" use
-// binder.EvalSelector (path/index navigation). All other selectors use a
-// deep-tree search supporting bare stems, paths with "/" and title matching.
-func addChildEvalParentSelector(selector string, root *binder.Node, lines []string) ([]*binder.Node, []binder.Diagnostic) {
+// "." always returns the root node. Path selectors (see binder.IsPathSelector)
+// use binder.EvalSelector (path/index/position navigation). All other
+// selectors use a deep-tree search supporting bare stems, paths with "/",
+// globs, and title matching.
+func addChildEvalParentSelector(selector string, root *binder.Node, fenced []*binder.Node) ([]*binder.Node, []binder.Diagnostic) {
 	if selector == "." {
 		return []*binder.Node{root}, nil
 	}
-	if strings.Contains(selector, ":") || strings.Contains(selector, "[") {
+	if binder.IsPathSelector(selector) {
 		selResult, errDiags := binder.EvalSelector(selector, root)
 		allDiags := append(selResult.Warnings, errDiags...)
 		return selResult.Nodes, allDiags
 	}
-	// Deep search (bare stem, relative path with "/", title match).
+	// Deep search (bare stem, relative path with "/", glob, title match).
 	var matches []*binder.Node
 	deleteSearchTree(root, selector, &matches)
 	if len(matches) == 0 {
-		if isSelectorInCodeFence(lines, selector) {
-			return nil, []binder.Diagnostic{{
-				Severity: "error",
-				Code:     binder.CodeNodeInCodeFence,
-				Message:  fmt.Sprintf("selector %q matches a node inside a code fence", selector),
-			}}
+		if n := fencedMatch(fenced, selector); n != nil {
+			return nil, []binder.Diagnostic{codeFenceDiag(n, selector)}
 		}
 		return nil, []binder.Diagnostic{{
 			Severity: "error",
@@ -222,10 +263,10 @@ func addChildEvalParentSelector(selector string, root *binder.Node, lines []stri
 	return matches, nil
 }
 
-// normalizeTargetInput strips wikilink bracket syntax ([[...]]) and leading "./"
+// NormalizeTarget strips wikilink bracket syntax ([[...]]) and leading "./"
 // from a raw target string so that "./a.md", "[[a.md]]", and "a.md" all
 // produce the same canonical target before validation and storage.
-func normalizeTargetInput(target string) string {
+func NormalizeTarget(target string) string {
 	if strings.HasPrefix(target, "[[") && strings.HasSuffix(target, "]]") {
 		target = target[2 : len(target)-2]
 	}
@@ -241,40 +282,31 @@ func percentDecodeOpTarget(target string) string {
 	return decoded
 }
 
-// isSelectorInCodeFence reports whether any fenced code block in lines contains
-// an inline link whose target stem matches the bare-stem selector.
-func isSelectorInCodeFence(lines []string, selector string) bool {
-	inFence := false
-	fenceMarker := ""
-	for _, line := range lines {
-		if !inFence {
-			if strings.HasPrefix(line, "```") {
-				inFence, fenceMarker = true, "```"
-			} else if strings.HasPrefix(line, "~~~") {
-				inFence, fenceMarker = true, "~~~"
-			}
-		} else {
-			if strings.HasPrefix(line, fenceMarker) {
-				inFence, fenceMarker = false, ""
-				continue
-			}
-			if fencedLineMatchesSelector(line, selector) {
-				return true
-			}
+// fencedMatch returns the first fenced pseudo-node matching the bare-stem
+// selector, or nil.
+func fencedMatch(fenced []*binder.Node, selector string) *binder.Node {
+	for _, n := range fenced {
+		if deleteNodeMatchesSelector(n, selector) {
+			return n
 		}
 	}
-	return false
+	return nil
 }
 
-// fencedLineMatchesSelector checks whether a line inside a code fence contains an
-// inline link whose target stem equals the bare-stem selector.
-func fencedLineMatchesSelector(line, selector string) bool {
-	m := opsInlineLinkRE.FindStringSubmatch(line)
-	if m == nil {
-		return false
+// codeFenceDiag returns the OPE006 diagnostic for a selector that only matches
+// the fenced pseudo-node n. The message names the fence the parser saw, and
+// the location is n's line.
+func codeFenceDiag(n *binder.Node, selector string) binder.Diagnostic {
+	d := binder.Diagnostic{
+		Severity: "error",
+		Code:     binder.CodeNodeInCodeFence,
+		Message:  fmt.Sprintf("selector %q matches a node inside a code fence", selector),
+		Location: &binder.Location{Line: n.Line},
+	}
+	if f := n.Fence; f != nil {
+		d.Message = fmt.Sprintf("selector %q matches a node inside the %s code fence at lines %d-%d", selector, f.Marker, f.StartLine, f.EndLine)
 	}
-	target := m[2]
-	return opStemFromPath(target) == selector || target == selector || target == selector+".md"
+	return d
 }
 
 // resolveInsertionIndex returns the 0-based index in parent.Children at which to
@@ -346,8 +378,9 @@ func siblingMatchesSelector(child *binder.Node, selector string) bool {
 // inferMarkerAndIndent returns the indentation string and list marker to use for a new
 // child of parent at the given insertIdx (0-based children index). For ordered list
 // markers, when insertIdx > 0 the ordinal is the preceding sibling's ordinal + 1;
-// when insertIdx == 0 the ordinal is maxOrdinal(children) + 1.
-func inferMarkerAndIndent(parent *binder.Node, insertIdx int) (indentStr, marker string) {
+// when insertIdx == 0 the ordinal is maxOrdinal(children) + 1. Without siblings
+// to copy, project's ListMarker and IndentWidth apply.
+func inferMarkerAndIndent(parent *binder.Node, insertIdx int, project *binder.Project) (indentStr, marker string) {
 	if len(parent.Children) > 0 {
 		sibling := parent.Children[0]
 		indentStr = rawIndent(sibling)
@@ -364,8 +397,17 @@ func inferMarkerAndIndent(parent *binder.Node, insertIdx int) (indentStr, marker
 		return
 	}
 
+	marker, width := "-", 2
+	if project != nil {
+		if project.ListMarker != "" {
+			marker = project.ListMarker
+		}
+		if project.IndentWidth > 0 {
+			width = project.IndentWidth
+		}
+	}
 	if parent.Type == "root" {
-		return "", "-"
+		return "", marker
 	}
 
 	// First child of a non-root node: one indent level deeper than parent.
@@ -373,9 +415,9 @@ func inferMarkerAndIndent(parent *binder.Node, insertIdx int) (indentStr, marker
 	if len(pIndent) > 0 && pIndent[0] == '\t' {
 		indentStr = pIndent + "\t"
 	} else {
-		indentStr = pIndent + "  "
+		indentStr = pIndent + strings.Repeat(" ", width)
 	}
-	return indentStr, "-"
+	return indentStr, marker
 }
 
 // rawIndent extracts the leading whitespace characters from a node's source line.
@@ -505,6 +547,37 @@ func opStemFromPath(p string) string {
 	return p
 }
 
+// insertRefDefs adds reference definition lines after the line at anchor, or
+// at the end of the file (after a blank separator) when anchor is -1.
+func insertRefDefs(result *binder.ParseResult, defs []string, anchor int, lineEnd string) {
+	if len(defs) == 0 {
+		return
+	}
+	if anchor < 0 {
+		n := len(result.Lines)
+		if n > 0 && strings.TrimSpace(result.Lines[n-1]) != "" {
+			insertLine(result, n, "", lineEnd)
+		}
+		anchor = len(result.Lines) - 1
+	}
+	for i, def := range defs {
+		insertLine(result, anchor+1+i, def, lineEnd)
+	}
+}
+
+// insertLine splices line into result at idx. When appending after a final
+// line that has no terminator, that line gains lineEnd and the new line takes
+// over the missing terminator, so the two are never joined.
+func insertLine(result *binder.ParseResult, idx int, line, lineEnd string) {
+	end := lineEnd
+	if idx > 0 && idx == len(result.Lines) && result.LineEnds[idx-1] == "" {
+		result.LineEnds[idx-1] = lineEnd
+		end = ""
+	}
+	result.Lines = sliceInsert(result.Lines, idx, line)
+	result.LineEnds = sliceInsert(result.LineEnds, idx, end)
+}
+
 // sliceInsert returns a new slice with v inserted at position idx.
 func sliceInsert(s []string, idx int, v string) []string {
 	out := make([]string, len(s)+1)
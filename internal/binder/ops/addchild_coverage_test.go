@@ -45,27 +45,31 @@ func TestAddChild_ParseError_OPE009(t *testing.T) {
 }
 
 // ──────────────────────────────────────────────────────────────────────────────
-// isSelectorInCodeFence: tilde fences and fence-close detection
-// ──────────────────────────────────────────────────────────────────────────────
-
-// TestIsSelectorInCodeFence_TildeAndBacktick verifies that tilde-delimited code
-// fences are recognised and that the closing-fence detection allows the scanner
-// to continue past a closed fence into a subsequent one.
-// This covers:
-//   - the "~~~" opening branch
-//   - the closing-fence branch (inFence → prefix matches fenceMarker)
-//   - fencedLineMatchesSelector returning false (non-link line inside fence)
-func TestIsSelectorInCodeFence_TildeAndBacktick(t *testing.T) {
-	lines := []string{
+// fencedMatch: fenced pseudo-node matching
+// ──────────────────────────────────────────────────────────────────────────────
+
+// TestFencedMatch_MatchesFencedPseudoNodes verifies that the OPE006
+// check consults the parser's fenced pseudo-nodes by stem, target and title.
+func TestFencedMatch_MatchesFencedPseudoNodes(t *testing.T) {
+	src := binderSrc(
 		"~~~",
 		"not a link line",
 		"~~~",
 		"```",
-		"- [selector](selector.md)",
+		"- [Selector Title](selector.md)",
 		"```",
-	}
-	if !isSelectorInCodeFence(lines, "selector") {
-		t.Error("expected true: selector is inside the backtick fence after the tilde fence")
+	)
+	result, _, err := binder.Parse(context.Background(), src, nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	for _, sel := range []string{"selector", "selector.md", "Selector Title"} {
+		if fencedMatch(result.Fenced, sel) == nil {
+			t.Errorf("fencedMatch(%q) = nil, want the fenced node", sel)
+		}
+	}
+	if n := fencedMatch(result.Fenced, "other"); n != nil {
+		t.Errorf("fencedMatch(\"other\") = %+v, want nil", n)
 	}
 }
 
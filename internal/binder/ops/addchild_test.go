@@ -304,6 +304,22 @@ func TestAddChild_TabIndentationInherited(t *testing.T) {
 	}
 }
 
+// TestAddChild_ProjectListStyle verifies that the project's list marker and
+// indent width style a first child, while existing siblings still win.
+func TestAddChild_ProjectListStyle(t *testing.T) {
+	project := &binder.Project{Files: []string{"root.md", "one.md", "two.md"}, BinderDir: ".", ListMarker: "*", IndentWidth: 4}
+	src := []byte("<!-- prosemark-binder:v1 -->\n\n- [Root](root.md)\n")
+	out, diags := AddChild(context.Background(), src, project, binder.AddChildParams{ParentSelector: "root", Target: "one.md", Title: "One"})
+	if !bytes.Contains(out, []byte("\n    * [One](one.md)\n")) {
+		t.Fatalf("expected a four-space, starred first child:\n%s\n%v", out, diags)
+	}
+
+	out, _ = AddChild(context.Background(), out, project, binder.AddChildParams{ParentSelector: ".", Target: "two.md", Title: "Two"})
+	if !bytes.HasSuffix(out, []byte("\n- [Two](two.md)\n")) {
+		t.Errorf("expected the new root child to follow its sibling's style:\n%s", out)
+	}
+}
+
 // ──────────────────────────────────────────────────────────────────────────────
 // Title escaping
 // ──────────────────────────────────────────────────────────────────────────────
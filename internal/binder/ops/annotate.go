@@ -0,0 +1,93 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// annotationRE matches an entry annotation written by Annotate, with the
+// whitespace around it, at the end of a list item line.
+var annotationRE = regexp.MustCompile(`\s*<!-- \d[\d,]* words?(?:, (?:[^-]|-[^-])*)? -->\s*$`)
+
+// EntryAnnotation is the progress summary Annotate writes after an entry.
+type EntryAnnotation struct {
+	Words int
+	// Status is the node's frontmatter status; it is omitted when empty.
+	Status string
+}
+
+// String renders a as annotation text, such as "2,314 words, revised".
+func (a EntryAnnotation) String() string {
+	digits := strconv.Itoa(a.Words)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	if a.Words == 1 {
+		b.WriteString(" word")
+	} else {
+		b.WriteString(" words")
+	}
+	// Comments cannot contain "--" (nor end in "-" before the closing
+	// "-->"), and an annotation fits on one line.
+	status := strings.Join(strings.Fields(a.Status), " ")
+	for strings.Contains(status, "--") {
+		status = strings.ReplaceAll(status, "--", "-")
+	}
+	status = strings.TrimRight(status, "-")
+	if status != "" {
+		b.WriteString(", " + status)
+	}
+	return b.String()
+}
+
+// AnnotateParams are parameters for Annotate.
+type AnnotateParams struct {
+	// Notes maps entry targets to their annotations. Entries whose target is
+	// missing lose any annotation they had.
+	Notes map[string]EntryAnnotation
+	// ForceParse proceeds even though the binder has parse errors.
+	ForceParse bool
+}
+
+// Annotate ends the list item of every binder entry with an HTML comment
+// holding its annotation from params.Notes, such as
+// "<!-- 2,314 words, revised -->", replacing the annotation the item already
+// has. Other trailing comments are left alone, and the parser ignores all of
+// them, so annotating never changes the binder's structure and repeating it
+// with the same notes changes nothing. Returns the modified bytes and
+// diagnostics.
+func Annotate(ctx context.Context, src []byte, project *binder.Project, params AnnotateParams) ([]byte, []binder.Diagnostic) {
+	result, parseDiags, err := binder.Parse(ctx, src, project)
+	if err != nil {
+		return src, append(parseDiags, binder.Diagnostic{
+			Severity: "error",
+			Code:     binder.CodeIOOrParseFailure,
+			Message:  fmt.Sprintf("parse error: %v", err),
+		})
+	}
+	if diag := guardParseErrors(parseDiags, params.ForceParse); diag != nil {
+		return src, append(parseDiags, *diag)
+	}
+
+	for _, n := range collectAllNodes(result.Root) {
+		if n.Target == "" {
+			continue
+		}
+		idx := n.Line - 1
+		line := annotationRE.ReplaceAllString(result.Lines[idx], "")
+		if note, ok := params.Notes[n.Target]; ok {
+			line = strings.TrimRight(line, " \t") + " <!-- " + note.String() + " -->"
+		}
+		result.Lines[idx] = line
+	}
+	return binder.Serialize(result), parseDiags
+}
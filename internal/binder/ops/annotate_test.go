@@ -0,0 +1,111 @@
+package ops
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestEntryAnnotation_String(t *testing.T) {
+	tests := []struct {
+		note EntryAnnotation
+		want string
+	}{
+		{EntryAnnotation{Words: 2314, Status: "revised"}, "2,314 words, revised"},
+		{EntryAnnotation{Words: 1}, "1 word"},
+		{EntryAnnotation{Words: 0}, "0 words"},
+		{EntryAnnotation{Words: 1234567}, "1,234,567 words"},
+		{EntryAnnotation{Words: 999, Status: "  first\n draft "}, "999 words, first draft"},
+		{EntryAnnotation{Words: 5, Status: "wip---done-"}, "5 words, wip-done"},
+	}
+	for _, tt := range tests {
+		if got := tt.note.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.note, got, tt.want)
+		}
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	ctx := context.Background()
+	src := binderSrc(
+		"- [Part](part.md) <!-- 1 word -->",
+		"  - [[ch1]] <!-- editor: tighten -->",
+		"  - [Draft]()",
+		"  - [Two](ch2.md)",
+	)
+	notes := map[string]EntryAnnotation{
+		"ch1.md": {Words: 2314, Status: "revised"},
+		"ch2.md": {Words: 7, Status: "draft -- rough"},
+	}
+	want := binderSrc(
+		"- [Part](part.md)",
+		"  - [[ch1]] <!-- editor: tighten --> <!-- 2,314 words, revised -->",
+		"  - [Draft]()",
+		"  - [Two](ch2.md) <!-- 7 words, draft - rough -->",
+	)
+
+	out, diags := Annotate(ctx, src, nil, AnnotateParams{Notes: notes})
+	if len(diags) != 0 {
+		t.Fatalf("diags = %v", diags)
+	}
+	if !bytes.Equal(out, want) {
+		t.Fatalf("Annotate() =\n%s\nwant\n%s", out, want)
+	}
+
+	again, _ := Annotate(ctx, out, nil, AnnotateParams{Notes: notes})
+	if !bytes.Equal(again, out) {
+		t.Errorf("second Annotate() changed the binder:\n%s", again)
+	}
+
+	if got, want := entrySummary(ctx, out), entrySummary(ctx, src); got != want {
+		t.Errorf("annotating changed the entries: %s, want %s", got, want)
+	}
+
+	removed, _ := Annotate(ctx, out, nil, AnnotateParams{})
+	wantRemoved := binderSrc(
+		"- [Part](part.md)",
+		"  - [[ch1]] <!-- editor: tighten -->",
+		"  - [Draft]()",
+		"  - [Two](ch2.md)",
+	)
+	if !bytes.Equal(removed, wantRemoved) {
+		t.Errorf("Annotate() without notes =\n%s\nwant\n%s", removed, wantRemoved)
+	}
+}
+
+func TestAnnotate_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	out, diags := Annotate(ctx, []byte("\xff\xfe"), nil, AnnotateParams{})
+	if !hasDiagCode(diags, binder.CodeIOOrParseFailure) || string(out) != "\xff\xfe" {
+		t.Errorf("invalid UTF-8: out = %q, diags = %v", out, diags)
+	}
+
+	out, diags = Annotate(ctx, brokenBinder, nil, AnnotateParams{Notes: map[string]EntryAnnotation{"ch1.md": {Words: 3}}})
+	if !hasDiagCode(diags, CodeBinderHasParseErrors) || !bytes.Equal(out, brokenBinder) {
+		t.Errorf("parse errors: out = %q, diags = %v", out, diags)
+	}
+	out, _ = Annotate(ctx, brokenBinder, nil, AnnotateParams{Notes: map[string]EntryAnnotation{"ch1.md": {Words: 3}}, ForceParse: true})
+	if !bytes.Contains(out, []byte("- [One](ch1.md) <!-- 3 words -->")) {
+		t.Errorf("--force-parse did not annotate:\n%s", out)
+	}
+}
+
+// entrySummary lists the depth, target, and title of every entry in src.
+func entrySummary(ctx context.Context, src []byte) string {
+	result, _, _ := binder.Parse(ctx, src, nil)
+	var b strings.Builder
+	var walk func(n *binder.Node, depth int)
+	walk = func(n *binder.Node, depth int) {
+		for _, c := range n.Children {
+			fmt.Fprintf(&b, "%d:%s:%s;", depth, c.Target, c.Title)
+			walk(c, depth+1)
+		}
+	}
+	walk(result.Root, 0)
+	return b.String()
+}
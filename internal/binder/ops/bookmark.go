@@ -0,0 +1,17 @@
+package ops
+
+import "github.com/eykd/prosemark-go/internal/binder"
+
+// resolveBookmarks replaces each bookmark selector ("@name") among
+// selectors with the selector it stands for in project's bookmarks. It
+// returns the diagnostic of the first bookmark that is not defined.
+func resolveBookmarks(project *binder.Project, selectors ...*string) *binder.Diagnostic {
+	for _, sel := range selectors {
+		resolved, diag := binder.ResolveBookmark(*sel, project)
+		if diag != nil {
+			return diag
+		}
+		*sel = resolved
+	}
+	return nil
+}
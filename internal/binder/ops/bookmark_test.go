@@ -0,0 +1,88 @@
+package ops
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// TestOps_ResolveBookmarks verifies that operations accept "@name" for a
+// bookmarked selector and reject a bookmark the project does not define.
+func TestOps_ResolveBookmarks(t *testing.T) {
+	src := binderSrc(
+		"- [Part One](part-one.md)",
+		"  - [Chapter One](chapter-one.md)",
+		"- [Part Two](part-two.md)",
+	)
+	project := &binder.Project{Bookmarks: map[string]string{"ch1": "part-one:chapter-one", "p2": "part-two"}}
+
+	out, diags := Move(context.Background(), src, project, binder.MoveParams{
+		SourceSelector: "@ch1", DestinationParentSelector: "@p2", Yes: true,
+	})
+	if hasDiagCode(diags, "error") {
+		t.Fatalf("Move: unexpected error diagnostic: %v", diags)
+	}
+	if !bytes.Contains(out, []byte("- [Part Two](part-two.md)\n  - [Chapter One](chapter-one.md)")) {
+		t.Errorf("Move: chapter-one should now be under part-two:\n%s", out)
+	}
+
+	_, diags = Delete(context.Background(), src, project, binder.DeleteParams{Selector: "@ch9", Yes: true})
+	if !hasDiagCode(diags, binder.CodeSelectorNoMatch) {
+		t.Errorf("Delete of an undefined bookmark: want %s, got %v", binder.CodeSelectorNoMatch, diags)
+	}
+}
+
+// TestOps_UndefinedBookmark verifies that every operation taking a selector
+// refuses a bookmark the project does not define, leaving the binder alone.
+func TestOps_UndefinedBookmark(t *testing.T) {
+	ctx := context.Background()
+	src := binderSrc("- [Part One](part-one.md)", "- [Later]()")
+	project := &binder.Project{Files: []string{"part-one.md"}}
+	ops := []struct {
+		name string
+		run  func() ([]byte, []binder.Diagnostic)
+	}{
+		{"add", func() ([]byte, []binder.Diagnostic) {
+			return AddChild(ctx, src, project, binder.AddChildParams{ParentSelector: "@nope", Target: "new.md"})
+		}},
+		{"move", func() ([]byte, []binder.Diagnostic) {
+			return Move(ctx, src, project, binder.MoveParams{SourceSelector: "@nope", DestinationParentSelector: ".", Yes: true})
+		}},
+		{"check", func() ([]byte, []binder.Diagnostic) {
+			return SetChecked(ctx, src, project, binder.SetCheckedParams{Selector: "@nope", Checked: true})
+		}},
+		{"insert subtree", func() ([]byte, []binder.Diagnostic) {
+			return InsertSubtree(ctx, src, project, InsertSubtreeParams{ParentSelector: "@nope", Subtree: Subtree{Lines: []string{"- [New](new.md)"}}})
+		}},
+		{"materialize", func() ([]byte, []binder.Diagnostic) {
+			out, _, diags := Materialize(ctx, src, project, binder.MaterializeParams{Selector: "@nope", Target: "later.md"})
+			return out, diags
+		}},
+		{"rename", func() ([]byte, []binder.Diagnostic) {
+			out, _, _, diags := Rename(ctx, src, project, binder.RenameParams{Selector: "@nope", NewTarget: "one.md"})
+			return out, diags
+		}},
+		{"retitle", func() ([]byte, []binder.Diagnostic) {
+			out, _, diags := Retitle(ctx, src, project, binder.RetitleParams{Selector: "@nope", Title: "One"})
+			return out, diags
+		}},
+		{"demote", func() ([]byte, []binder.Diagnostic) {
+			out, _, diags := Demote(ctx, src, project, binder.ShiftParams{Selector: "@nope"})
+			return out, diags
+		}},
+		{"titles", func() ([]byte, []binder.Diagnostic) {
+			out, _, diags := Titles(ctx, src, project, binder.TitlesParams{Selector: "@nope", Case: "title"})
+			return out, diags
+		}},
+	}
+	for _, op := range ops {
+		t.Run(op.name, func(t *testing.T) {
+			out, diags := op.run()
+			if !bytes.Equal(out, src) || !hasDiagCode(diags, binder.CodeSelectorNoMatch) {
+				t.Errorf("binder = %q, diags = %v; want it unchanged with %s", out, diags, binder.CodeSelectorNoMatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// checkLinePrefixRE splits a list item line into its indent+marker prefix and
+// an optional GFM task-list checkbox (with trailing whitespace). The prefix
+// mirrors the parser's list-item pattern, so it matches every node line.
+var checkLinePrefixRE = regexp.MustCompile(`^(\s*(?:[-*+]|\d+[.)])\s+)(\[[xX ]\]\s+)?`)
+
+// checkParseBinderFn is the parse function used by SetChecked. It may be
+// replaced in tests to simulate parse failures.
+var checkParseBinderFn = binder.Parse
+
+// SetChecked sets the GFM task-list state of the node(s) selected by
+// params.Selector, rewriting an existing "[ ]"/"[x]" checkbox or inserting one
+// after the list marker. Nodes already in the requested state are left
+// untouched. Returns the modified bytes and diagnostics. Source bytes are
+// unchanged on error (atomic abort semantics). Parse errors are surfaced as
+// diagnostics, not as a returned error.
+func SetChecked(ctx context.Context, src []byte, project *binder.Project, params binder.SetCheckedParams) ([]byte, []binder.Diagnostic) {
+	result, parseDiags, err := checkParseBinderFn(ctx, src, project)
+	if err != nil {
+		return src, append(parseDiags, binder.Diagnostic{
+			Severity: "error",
+			Code:     binder.CodeIOOrParseFailure,
+			Message:  fmt.Sprintf("parse error: %v", err),
+		})
+	}
+
+	if diag := guardParseErrors(parseDiags, params.ForceParse); diag != nil {
+		return src, append(parseDiags, *diag)
+	}
+	if diag := resolveBookmarks(project, &params.Selector); diag != nil {
+		return src, append(parseDiags, *diag)
+	}
+
+	nodes, selDiags := moveEvalSourceSelector(params.Selector, result.Root, result.Fenced)
+	if len(nodes) == 0 {
+		return src, append(parseDiags, selDiags...)
+	}
+
+	var allDiags []binder.Diagnostic
+	allDiags = append(allDiags, parseDiags...)
+	allDiags = append(allDiags, selDiags...)
+
+	box := "[ ] "
+	if params.Checked {
+		box = "[x] "
+	}
+
+	for _, n := range nodes {
+		if n.Checked != nil && *n.Checked == params.Checked {
+			continue
+		}
+		idx := n.Line - 1
+		m := checkLinePrefixRE.FindStringSubmatchIndex(result.Lines[idx])
+		line := result.Lines[idx]
+		rest := line[m[3]:]
+		if m[4] >= 0 {
+			rest = line[m[5]:]
+		}
+		result.Lines[idx] = line[:m[3]] + box + rest
+	}
+
+	return binder.Serialize(result), allDiags
+}
@@ -0,0 +1,80 @@
+package ops
+
+// Tests for the check/uncheck (task-state) operation.
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// TestSetChecked rewrites, inserts, or leaves checkbox prefixes according to
+// the requested state.
+func TestSetChecked(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		selector string
+		checked  bool
+		wantLine string
+	}{
+		{"check unchecked item", "- [ ] [Ch](ch.md)", "ch", true, "- [x] [Ch](ch.md)"},
+		{"uncheck checked item", "- [X] [Ch](ch.md)", "ch", false, "- [ ] [Ch](ch.md)"},
+		{"check item without checkbox", "- [Ch](ch.md)", "ch", true, "- [x] [Ch](ch.md)"},
+		{"uncheck item without checkbox adds empty box", "- [Ch](ch.md)", "ch", false, "- [ ] [Ch](ch.md)"},
+		{"already checked is untouched", "- [x] [Ch](ch.md)", "ch", true, "- [x] [Ch](ch.md)"},
+		{"ordered marker and indentation preserved", "1.  [Ch](ch.md)", "ch.md", true, "1.  [x] [Ch](ch.md)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := binderSrc(tt.line)
+			out, diags := SetChecked(context.Background(), src, nil, binder.SetCheckedParams{Selector: tt.selector, Checked: tt.checked})
+			if hasDiagCode(diags, "error") {
+				t.Fatalf("unexpected error diagnostic: %v", diags)
+			}
+			if want := string(binderSrc(tt.wantLine)); string(out) != want {
+				t.Errorf("output = %q, want %q", out, want)
+			}
+		})
+	}
+}
+
+// TestSetChecked_Errors verifies selector and parse failures abort without mutation.
+func TestSetChecked_Errors(t *testing.T) {
+	src := binderSrc("- [Ch](ch.md)")
+
+	t.Run("no match returns OPE001", func(t *testing.T) {
+		out, diags := SetChecked(context.Background(), src, nil, binder.SetCheckedParams{Selector: "missing", Checked: true})
+		if !hasDiagCode(diags, binder.CodeSelectorNoMatch) {
+			t.Errorf("expected OPE001, got %v", diags)
+		}
+		if string(out) != string(src) {
+			t.Errorf("source must be unchanged on error")
+		}
+	})
+
+	t.Run("root selector returns OPE001", func(t *testing.T) {
+		_, diags := SetChecked(context.Background(), src, nil, binder.SetCheckedParams{Selector: ".", Checked: true})
+		if !hasDiagCode(diags, binder.CodeSelectorNoMatch) {
+			t.Errorf("expected OPE001, got %v", diags)
+		}
+	})
+
+	t.Run("parse failure returns OPE009", func(t *testing.T) {
+		orig := checkParseBinderFn
+		defer func() { checkParseBinderFn = orig }()
+		checkParseBinderFn = func(context.Context, []byte, *binder.Project) (*binder.ParseResult, []binder.Diagnostic, error) {
+			return nil, nil, errors.New("boom")
+		}
+		out, diags := SetChecked(context.Background(), src, nil, binder.SetCheckedParams{Selector: "ch", Checked: true})
+		if !hasDiagCode(diags, binder.CodeIOOrParseFailure) {
+			t.Errorf("expected OPE009, got %v", diags)
+		}
+		if string(out) != string(src) {
+			t.Errorf("source must be unchanged on error")
+		}
+	})
+}
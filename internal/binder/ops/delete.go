@@ -3,8 +3,9 @@ package ops
 import (
 	"context"
 	"fmt"
-	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/eykd/prosemark-go/internal/binder"
@@ -14,39 +15,81 @@ import (
 // in tests to simulate parse failures.
 var deleteParseBinderFn = binder.Parse
 
-// deleteInlineLinkRE matches a complete inline markdown link [text](url).
-var deleteInlineLinkRE = regexp.MustCompile(`\[[^\]]*\]\([^)]*\)`)
-
 // Delete removes the node selected by params.Selector and its entire subtree
 // from the binder source. Returns the modified bytes and diagnostics. Source
 // bytes are unchanged on error (atomic abort semantics). Parse errors are
 // surfaced as diagnostics, not as a returned error.
 func Delete(ctx context.Context, src []byte, project *binder.Project, params binder.DeleteParams) ([]byte, []binder.Diagnostic) {
+	out, _, diags := DeleteEntries(ctx, src, project, params)
+	return out, diags
+}
+
+// DeleteEntries is Delete that also reports the removed entries, in document
+// order, each with the line of the returned bytes where it stood.
+func DeleteEntries(ctx context.Context, src []byte, project *binder.Project, params binder.DeleteParams) ([]byte, []binder.AffectedEntry, []binder.Diagnostic) {
+	out, entries, _, diags := DeleteSubtrees(ctx, src, project, params)
+	return out, entries, diags
+}
+
+// RemovedEntry is an entry removed by DeleteSubtrees, as it stood in the
+// source binder.
+type RemovedEntry struct {
+	// Node is the entry, with its descendants.
+	Node *binder.Node
+	// Parent is the entry's parent: the root node for a top-level entry.
+	Parent *binder.Node
+	// Index is the entry's position among Parent's children.
+	Index int
+	// ParentPath is Parent's child index path from the root: empty for the
+	// root itself.
+	ParentPath []int
+	// Subtree is the entry's source, for putting it back with InsertSubtree.
+	Subtree Subtree
+}
+
+// DeleteSubtrees is DeleteEntries that also returns the removed subtrees, in
+// document order. A selected entry inside another selected entry's subtree is
+// part of that subtree rather than reported on its own.
+func DeleteSubtrees(ctx context.Context, src []byte, project *binder.Project, params binder.DeleteParams) ([]byte, []binder.AffectedEntry, []RemovedEntry, []binder.Diagnostic) {
+	p := parseSrc(ctx, deleteParseBinderFn, src, project)
+	edited, entries, removed, diags := deleteSubtrees(p, project, params)
+	return p.output(edited), entries, removed, diags
+}
+
+// deleteSubtrees implements DeleteSubtrees by editing the parsed binder p
+// in place, reporting whether it did.
+func deleteSubtrees(p parsed, project *binder.Project, params binder.DeleteParams) (bool, []binder.AffectedEntry, []RemovedEntry, []binder.Diagnostic) {
 	// Require --yes confirmation (OPE009).
 	if !params.Yes {
-		return src, []binder.Diagnostic{{
+		return false, nil, nil, []binder.Diagnostic{{
 			Severity: "error",
 			Code:     binder.CodeIOOrParseFailure,
 			Message:  "delete requires --yes confirmation",
 		}}
 	}
 
-	// Parse the source.
-	result, parseDiags, err := deleteParseBinderFn(ctx, src, project)
+	result, parseDiags, err := p.result, p.diags, p.err
 	if err != nil {
-		return src, append(parseDiags, binder.Diagnostic{
+		return false, nil, nil, append(parseDiags, binder.Diagnostic{
 			Severity: "error",
 			Code:     binder.CodeIOOrParseFailure,
 			Message:  fmt.Sprintf("parse error: %v", err),
 		})
 	}
 
+	if diag := guardParseErrors(parseDiags, params.ForceParse); diag != nil {
+		return false, nil, nil, append(parseDiags, *diag)
+	}
+	if diag := resolveBookmarks(project, &params.Selector); diag != nil {
+		return false, nil, nil, append(parseDiags, *diag)
+	}
+
 	// Evaluate selector: supports path navigation (colon), index qualifiers ([N]),
 	// flat deep search (bare stem), and code-fence detection.
-	nodes, selDiags := deleteEvalSelector(params.Selector, result.Root, result.Lines, project)
+	nodes, selDiags := deleteEvalSelector(params.Selector, result.Root, result.Fenced, project)
 	if len(nodes) == 0 {
-		// Fatal selector error (OPE001/OPE002/OPE006): return src unchanged.
-		return src, append(parseDiags, selDiags...)
+		// Fatal selector error (OPE001/OPE002/OPE006): leave the binder unchanged.
+		return false, nil, nil, append(parseDiags, selDiags...)
 	}
 
 	// Collect diagnostics: parse warnings + selector warnings (OPW001).
@@ -54,15 +97,18 @@ func Delete(ctx context.Context, src []byte, project *binder.Project, params bin
 	allDiags = append(allDiags, parseDiags...)
 	allDiags = append(allDiags, selDiags...)
 
-	// Emit OPW003 if any node's list-item line has non-structural content.
+	// Emit OPW003 for each node whose list-item line has non-structural content,
+	// quoting the exact text that was destroyed. A leading GFM checkbox is
+	// task-state metadata of the entry itself, so it is not part of it.
 	for _, node := range nodes {
-		if deleteNodeHasNonStructuralContent(node.RawLine) {
+		prefix, suffix := nonStructuralExtras(node.RawLine)
+		prefix = strings.TrimSpace(moveCheckboxPrefixRE.ReplaceAllString(prefix, ""))
+		if prefix != "" || suffix != "" {
 			allDiags = append(allDiags, binder.Diagnostic{
 				Severity: "warning",
 				Code:     binder.CodeNonStructuralDestroyed,
-				Message:  "non-structural content in the deleted list item was destroyed",
+				Message:  "non-structural content in the deleted list item was destroyed: " + describeExtras(prefix, suffix),
 			})
-			break
 		}
 	}
 
@@ -90,17 +136,30 @@ func Delete(ctx context.Context, src []byte, project *binder.Project, params bin
 		}
 	}
 
+	// The removed subtrees are taken while the lines are still in place.
+	removed := removedSubtrees(result, nodes)
+
 	// Sort nodes by Line descending so deletions are applied bottom-to-top,
 	// keeping earlier line numbers valid across iterations.
 	sort.Slice(nodes, func(i, j int) bool {
 		return nodes[i].Line > nodes[j].Line
 	})
 
-	for _, node := range nodes {
+	// stood holds each node's index once every node has been removed; the
+	// removals above a node shift it up.
+	stood := make([]int, len(nodes))
+	for i, node := range nodes {
 		startIdx := node.Line - 1
 		endIdx := deleteComputeSubtreeEnd(node) - 1
 		result.Lines = deleteRemoveRange(result.Lines, startIdx, endIdx)
 		result.LineEnds = deleteRemoveRange(result.LineEnds, startIdx, endIdx)
+		stood[i] = startIdx
+		for j := range i {
+			stood[j] -= endIdx - startIdx + 1
+		}
+	}
+	for i := range stood {
+		stood[i] = collapsedLineIdx(result.Lines, stood[i])
 	}
 
 	// Collapse consecutive blank lines (no \n\n\n or more in output).
@@ -109,17 +168,41 @@ func Delete(ctx context.Context, src []byte, project *binder.Project, params bin
 	// Strip trailing blank lines at EOF.
 	result.Lines, result.LineEnds = deleteStripTrailingBlanks(result.Lines, result.LineEnds)
 
-	return binder.Serialize(result), allDiags
+	entries := make([]binder.AffectedEntry, len(nodes))
+	for i, node := range nodes {
+		// nodes runs bottom-to-top; entries runs in document order.
+		entries[len(nodes)-1-i] = binder.AffectedEntry{Target: node.Target, Title: node.Title, Line: min(stood[i], len(result.Lines)) + 1}
+	}
+	return true, entries, removed, allDiags
+}
+
+// removedSubtrees returns the RemovedEntry of each of nodes that is not a
+// descendant of another, in document order, from result before any line
+// is removed.
+func removedSubtrees(result *binder.ParseResult, nodes []*binder.Node) []RemovedEntry {
+	var removed []RemovedEntry
+	var walk func(parent *binder.Node, path []int)
+	walk = func(parent *binder.Node, path []int) {
+		for i, child := range parent.Children {
+			if slices.Contains(nodes, child) {
+				removed = append(removed, RemovedEntry{Node: child, Parent: parent, Index: i, ParentPath: path, Subtree: subtreeOf(result, child)})
+				continue
+			}
+			walk(child, append(slices.Clip(path), i))
+		}
+	}
+	walk(result.Root, []int{})
+	return removed
 }
 
 // deleteEvalSelector evaluates a selector for the delete operation.
 //
-// For selectors containing ":" or "[", path navigation via binder.EvalSelector
-// is used. Otherwise a deep-tree flat search is performed.
+// For path selectors (see binder.IsPathSelector), path navigation via
+// binder.EvalSelector is used. Otherwise a deep-tree flat search is performed.
 //
 // When no match is found, the function checks for project-level ambiguity
 // (OPE002) and code-fence presence (OPE006) before returning OPE001.
-func deleteEvalSelector(selector string, root *binder.Node, lines []string, project *binder.Project) ([]*binder.Node, []binder.Diagnostic) {
+func deleteEvalSelector(selector string, root *binder.Node, fenced []*binder.Node, project *binder.Project) ([]*binder.Node, []binder.Diagnostic) {
 	// "." refers to the root node, which cannot be deleted.
 	if selector == "." {
 		return nil, []binder.Diagnostic{{
@@ -129,8 +212,9 @@ func deleteEvalSelector(selector string, root *binder.Node, lines []string, proj
 		}}
 	}
 
-	// Delegate to EvalSelector for path (colon) or index ([N]) selectors.
-	if strings.Contains(selector, ":") || strings.Contains(selector, "[") {
+	// Delegate to EvalSelector for path (colon), index ([N]) or position (#N)
+	// selectors.
+	if binder.IsPathSelector(selector) {
 		selResult, errDiags := binder.EvalSelector(selector, root)
 		allDiags := append(selResult.Warnings, errDiags...)
 		return selResult.Nodes, allDiags
@@ -158,12 +242,8 @@ func deleteEvalSelector(selector string, root *binder.Node, lines []string, proj
 			}
 		}
 		// Check for code-fence presence (OPE006).
-		if isSelectorInCodeFence(lines, selector) {
-			return nil, []binder.Diagnostic{{
-				Severity: "error",
-				Code:     binder.CodeNodeInCodeFence,
-				Message:  fmt.Sprintf("selector %q matches a node inside a code fence", selector),
-			}}
+		if n := fencedMatch(fenced, selector); n != nil {
+			return nil, []binder.Diagnostic{codeFenceDiag(n, selector)}
 		}
 		return nil, []binder.Diagnostic{{
 			Severity: "error",
@@ -195,14 +275,9 @@ func deleteSearchTree(n *binder.Node, selector string, matches *[]*binder.Node)
 }
 
 // deleteNodeMatchesSelector reports whether child matches selector by stem,
-// direct path, stem+".md", or case-insensitive title.
+// direct path, stem+".md", glob, or case-insensitive title.
 func deleteNodeMatchesSelector(child *binder.Node, selector string) bool {
-	if strings.Contains(selector, "/") {
-		return child.Target == selector || child.Target == selector+".md"
-	}
-	return opStemFromPath(child.Target) == selector ||
-		child.Target == selector ||
-		strings.EqualFold(child.Title, selector)
+	return binder.SelectorMatches(selector, child)
 }
 
 // deleteComputeSubtreeEnd returns the 1-based line number of the last line in
@@ -228,21 +303,31 @@ func deleteFindParentNode(root *binder.Node, target *binder.Node) *binder.Node {
 	return nil
 }
 
-// deleteNodeHasNonStructuralContent reports whether rawLine contains content
-// beyond the structural inline link (OPW003 trigger). Checks both prefix
-// (e.g. GFM checkbox) and suffix.
-func deleteNodeHasNonStructuralContent(rawLine string) bool {
-	loc := deleteInlineLinkRE.FindStringIndex(rawLine)
+// nonStructuralExtras returns the non-structural text of a list item's first
+// line (OPW003 trigger): prefix is the content between the list marker and the
+// structural link (e.g. a GFM checkbox or strikethrough fragment) and suffix
+// is the content after the link (e.g. a trailing annotation). Both are
+// trimmed, and both are empty when the line has no structural link.
+func nonStructuralExtras(rawLine string) (prefix, suffix string) {
+	loc := structuralLinkSpan(rawLine)
 	if loc == nil {
-		return false
+		return "", ""
 	}
-	// Check suffix (content after the link).
-	if strings.TrimSpace(rawLine[loc[1]:]) != "" {
-		return true
+	prefix = strings.TrimSpace(moveListMarkerRE.ReplaceAllString(rawLine[:loc[0]], ""))
+	suffix = strings.TrimSpace(rawLine[loc[1]:])
+	return prefix, suffix
+}
+
+// describeExtras formats the non-empty extras as a comma-separated list of
+// quoted strings for inclusion in an OPW003 message.
+func describeExtras(prefix, suffix string) string {
+	var parts []string
+	for _, part := range []string{prefix, suffix} {
+		if part != "" {
+			parts = append(parts, strconv.Quote(part))
+		}
 	}
-	// Check prefix (between list marker and link) — catches GFM checkboxes.
-	prefix := moveListMarkerRE.ReplaceAllString(rawLine[:loc[0]], "")
-	return strings.TrimSpace(prefix) != ""
+	return strings.Join(parts, ", ")
 }
 
 // deleteRemoveRange returns a copy of s with elements [startIdx, endIdx] removed
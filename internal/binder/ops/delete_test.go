@@ -60,6 +60,41 @@ func TestDelete_LeafNode_Removed(t *testing.T) {
 	}
 }
 
+// TestDelete_ExtendedSelectors verifies that delete resolves glob,
+// positional and title selectors.
+func TestDelete_ExtendedSelectors(t *testing.T) {
+	src := binderSrc(
+		"- [Part](part.md)",
+		"  - [Chapter One](chapters/one.md)",
+		"  - [Chapter Two: Return](chapters/two.md)",
+		"- [Notes](notes.md)",
+	)
+
+	tests := []struct {
+		selector string
+		want     []byte
+	}{
+		{"chapters/*", binderSrc("- [Part](part.md)", "- [Notes](notes.md)")},
+		{".#2", binderSrc("- [Part](part.md)", "  - [Chapter One](chapters/one.md)", "  - [Chapter Two: Return](chapters/two.md)")},
+		{"part:#last", binderSrc("- [Part](part.md)", "  - [Chapter One](chapters/one.md)", "- [Notes](notes.md)")},
+		{`title:"Chapter Two: Return"`, binderSrc("- [Part](part.md)", "  - [Chapter One](chapters/one.md)", "- [Notes](notes.md)")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			out, diags := Delete(context.Background(), src, nil, binder.DeleteParams{Selector: tt.selector, Yes: true})
+			for _, d := range diags {
+				if d.Severity == "error" {
+					t.Fatalf("unexpected error diagnostic: %v", diags)
+				}
+			}
+			if !bytes.Equal(out, tt.want) {
+				t.Errorf("got:\n%s\nwant:\n%s", out, tt.want)
+			}
+		})
+	}
+}
+
 // TestDelete_NodeWithChildren_RemovesEntireSubtree verifies that deleting a
 // non-leaf node removes the node and all its nested descendants.
 func TestDelete_NodeWithChildren_RemovesEntireSubtree(t *testing.T) {
@@ -226,6 +261,11 @@ func TestDelete_InlineProse_OPW003(t *testing.T) {
 	if !hasDiagCode(diags, binder.CodeNonStructuralDestroyed) {
 		t.Errorf("expected OPW003 (non-structural content destroyed), got: %v", diags)
 	}
+	for _, d := range diags {
+		if d.Code == binder.CodeNonStructuralDestroyed && !strings.Contains(d.Message, `"— some extra prose content"`) {
+			t.Errorf("OPW003 message should quote the destroyed text, got %q", d.Message)
+		}
+	}
 	if bytes.Contains(out, []byte("chapter-one.md")) {
 		t.Errorf("deleted node should not appear in output:\n%s", out)
 	}
@@ -267,6 +307,37 @@ func TestDelete_ReferenceDefinition_Preserved(t *testing.T) {
 	}
 }
 
+// TestDelete_NonStructuralContent_AllLinkStyles verifies that OPW003 fires
+// for annotations beside wikilink and reference entries, but not for a
+// wikilink's trailing title, a bare shortcut reference, or a task checkbox.
+func TestDelete_NonStructuralContent_AllLinkStyles(t *testing.T) {
+	tests := []struct {
+		line     string
+		wantWarn bool
+	}{
+		{"- [[chapter-one|One]] draft", true},
+		{"- [One][ch1] draft", true},
+		{"- [[chapter-one]] Chapter One", false},
+		{"- [ch1]", false},
+		{"- [x] [One](chapter-one.md)", false},
+		{"- [ ] [[chapter-one]]", false},
+		{"- [x] ~~old~~ [One](chapter-one.md)", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			src := []byte("<!-- prosemark-binder:v1 -->\n\n" + tt.line + "\n\n[ch1]: chapter-one.md\n")
+			proj := &binder.Project{Files: []string{"chapter-one.md"}, BinderDir: "."}
+			out, diags := Delete(context.Background(), src, proj, binder.DeleteParams{Selector: "chapter-one", Yes: true})
+			if bytes.Contains(out, []byte("\n- ")) {
+				t.Fatalf("list item should be deleted:\n%s", out)
+			}
+			if got := hasDiagCode(diags, binder.CodeNonStructuralDestroyed); got != tt.wantWarn {
+				t.Errorf("OPW003 = %v, want %v (diags %v)", got, tt.wantWarn, diags)
+			}
+		})
+	}
+}
+
 // ──────────────────────────────────────────────────────────────────────────────
 // Error abort cases: OPE001, root-selector guard, missing --yes
 // ──────────────────────────────────────────────────────────────────────────────
@@ -304,6 +375,15 @@ func TestDelete_ErrorCodesAbortMutation(t *testing.T) {
 			},
 			wantCode: binder.CodeSelectorNoMatch,
 		},
+		{
+			name: "OPE011_invalid_selector",
+			src:  baseSrc,
+			params: binder.DeleteParams{
+				Selector: "#0",
+				Yes:      true,
+			},
+			wantCode: binder.CodeInvalidSelector,
+		},
 		{
 			name: "OPE009_missing_yes_confirmation",
 			src:  baseSrc,
@@ -417,6 +497,12 @@ func TestDelete_OPE006_NodeInCodeFence(t *testing.T) {
 	if !hasDiagCode(diags, binder.CodeNodeInCodeFence) {
 		t.Errorf("expected OPE006 (node in code fence), got: %v", diags)
 	}
+	want := "selector \"fenced\" matches a node inside the ``` code fence at lines 3-5"
+	for _, d := range diags {
+		if d.Code == binder.CodeNodeInCodeFence && (d.Message != want || d.Location == nil || d.Location.Line != 4) {
+			t.Errorf("OPE006 = %+v, want %q at line 4", d, want)
+		}
+	}
 }
 
 // TestDelete_PathSelector_WithSlash verifies that a selector containing "/"
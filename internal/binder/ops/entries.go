@@ -0,0 +1,66 @@
+package ops
+
+import (
+	"context"
+	"slices"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// locateEntries describes the entries whose list items start at the 0-based
+// line indices lines of out, a rewritten binder, in document order. A line
+// that holds no entry once out is parsed, as when out exceeds the project's
+// parse limits, is skipped.
+func locateEntries(ctx context.Context, out []byte, project *binder.Project, lines []int) []binder.AffectedEntry {
+	if len(lines) == 0 {
+		return nil
+	}
+	result, _, _ := binder.Parse(ctx, out, project)
+	return entriesAt(result.Root, lines)
+}
+
+// entriesAt describes the entries of the tree under root whose list items
+// start at the 0-based line indices lines, in document order.
+func entriesAt(root *binder.Node, lines []int) []binder.AffectedEntry {
+	byLine := make(map[int]binder.AffectedEntry)
+	var walk func(n *binder.Node, path []int)
+	walk = func(n *binder.Node, path []int) {
+		for i, c := range n.Children {
+			p := append(slices.Clip(path), i)
+			byLine[c.Line] = binder.AffectedEntry{Target: c.Target, Title: c.Title, Line: c.Line, Path: p}
+			walk(c, p)
+		}
+	}
+	walk(root, nil)
+
+	lines = slices.Sorted(slices.Values(lines))
+	var entries []binder.AffectedEntry
+	for _, idx := range lines {
+		if e, ok := byLine[idx+1]; ok {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// shiftLines adds n to each line index in lines at or after at, following
+// the insertion of n lines at index at.
+func shiftLines(lines []int, at, n int) {
+	for i, idx := range lines {
+		if idx >= at {
+			lines[i] = idx + n
+		}
+	}
+}
+
+// collapsedLineIdx returns the index that line index idx of lines has after
+// deleteCollapseBlankLines, which drops each blank line that follows another.
+func collapsedLineIdx(lines []string, idx int) int {
+	removed := 0
+	for i := 1; i < idx && i < len(lines); i++ {
+		if lines[i] == "" && lines[i-1] == "" {
+			removed++
+		}
+	}
+	return idx - removed
+}
@@ -0,0 +1,195 @@
+package ops
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestAddChildEntries(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		src    []byte
+		params binder.AddChildParams
+		want   []binder.AffectedEntry
+	}{
+		{
+			"nested first",
+			binderSrc(
+				"- [Part](part.md)",
+				"  - [One](ch1.md)",
+				"- [Coda](coda.md)",
+			),
+			binder.AddChildParams{ParentSelector: "part.md", Target: "new.md", Title: "New", Position: "first"},
+			[]binder.AffectedEntry{{Target: "new.md", Title: "New", Line: 4, Path: []int{0, 0}}},
+		},
+		{
+			"empty root",
+			[]byte("<!-- prosemark-binder:v1 -->\n"),
+			binder.AddChildParams{ParentSelector: ".", Target: "new.md", Position: "last"},
+			[]binder.AffectedEntry{{Target: "new.md", Title: "new", Line: 3, Path: []int{0}}},
+		},
+		{
+			"every match",
+			binderSrc(
+				"- [Part](a/part.md)",
+				"- [Part](b/part.md)",
+			),
+			binder.AddChildParams{ParentSelector: "part", Target: "x.md", Title: "X", Position: "last"},
+			[]binder.AffectedEntry{
+				{Target: "x.md", Title: "X", Line: 4, Path: []int{0, 0}},
+				{Target: "x.md", Title: "X", Line: 6, Path: []int{1, 0}},
+			},
+		},
+		{
+			"before reference definitions",
+			binderSrc(
+				"- [One][one]",
+				"",
+				"[one]: ch1.md",
+			),
+			binder.AddChildParams{ParentSelector: ".", Target: "ch2.md", Title: "Two", Position: "last"},
+			[]binder.AffectedEntry{{Target: "ch2.md", Title: "Two", Line: 4, Path: []int{1}}},
+		},
+		{
+			"duplicate skipped",
+			binderSrc("- [One](ch1.md)"),
+			binder.AddChildParams{ParentSelector: ".", Target: "ch1.md", Position: "last"},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got, _ := AddChildEntries(ctx, tt.src, nil, tt.params)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("entries = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddChildEntries_ReferenceStyleShiftsPastNewDefinitions(t *testing.T) {
+	src := binderSrc(
+		"- [One][one]",
+		"",
+		"[one]: ch1.md",
+		"",
+		"- [Two](ch2.md)",
+	)
+	params := binder.AddChildParams{ParentSelector: ".", Target: "ch3.md", Title: "Three", Position: "last", Style: "reference"}
+	out, got, _ := AddChildEntries(context.Background(), src, nil, params)
+	// The new definition lands after [one], above the new entry.
+	want := []binder.AffectedEntry{{Target: "ch3.md", Title: "Three", Line: 9, Path: []int{2}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %+v, want %+v\n%s", got, want, out)
+	}
+}
+
+func TestAddChildEntries_OverParseLimit(t *testing.T) {
+	src := binderSrc("- [One](ch1.md)")
+	proj := &binder.Project{Limits: &binder.ParseLimits{MaxFileSize: len(src) + 1}}
+	out, got, _ := AddChildEntries(context.Background(), src, proj, binder.AddChildParams{ParentSelector: ".", Target: "ch2.md", Position: "last"})
+	if len(out) <= len(src) || got != nil {
+		t.Errorf("entries = %+v, want none once the binder outgrows the limit", got)
+	}
+}
+
+func TestMoveEntries(t *testing.T) {
+	ctx := context.Background()
+	src := binderSrc(
+		"- [One](ch1.md)",
+		"  - [Scene](scene.md)",
+		"",
+		"",
+		"- [Two](ch2.md)",
+		"- [Part](part.md)",
+	)
+	tests := []struct {
+		name   string
+		params binder.MoveParams
+		want   []binder.AffectedEntry
+	}{
+		{
+			"down past collapsed blanks",
+			binder.MoveParams{SourceSelector: "ch1.md", DestinationParentSelector: "part.md", Position: "last", Yes: true},
+			[]binder.AffectedEntry{{Target: "ch1.md", Title: "One", Line: 5, Path: []int{1, 0}}},
+		},
+		{
+			"up to the root",
+			binder.MoveParams{SourceSelector: "scene.md", DestinationParentSelector: ".", Position: "first", Yes: true},
+			[]binder.AffectedEntry{{Target: "scene.md", Title: "Scene", Line: 3, Path: []int{0}}},
+		},
+		{
+			"appended at the end",
+			binder.MoveParams{SourceSelector: "ch2.md", DestinationParentSelector: ".", Position: "last", Yes: true},
+			[]binder.AffectedEntry{{Target: "ch2.md", Title: "Two", Line: 7, Path: []int{2}}},
+		},
+		{
+			"refused",
+			binder.MoveParams{SourceSelector: "ch1.md", DestinationParentSelector: "part.md", Position: "last"},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, got, _ := MoveEntries(ctx, src, nil, tt.params)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("entries = %+v, want %+v\n%s", got, tt.want, out)
+			}
+		})
+	}
+}
+
+func TestMoveEntries_SeveralSources(t *testing.T) {
+	src := binderSrc(
+		"- [Scene](a/scene.md)",
+		"- [Scene](b/scene.md)",
+		"- [Part](part.md)",
+	)
+	params := binder.MoveParams{SourceSelector: "scene", DestinationParentSelector: "part.md", Position: "last", Yes: true}
+	out, got, _ := MoveEntries(context.Background(), src, nil, params)
+	want := []binder.AffectedEntry{
+		{Target: "a/scene.md", Title: "Scene", Line: 4, Path: []int{0, 0}},
+		{Target: "b/scene.md", Title: "Scene", Line: 5, Path: []int{0, 1}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %+v, want %+v\n%s", got, want, out)
+	}
+}
+
+func TestDeleteEntries(t *testing.T) {
+	ctx := context.Background()
+	src := binderSrc(
+		"- [One](ch1.md)",
+		"  - [Scene](a/scene.md)",
+		"",
+		"- [Two](ch2.md)",
+		"  - [Scene](b/scene.md)",
+		"- [Three](ch3.md)",
+	)
+	tests := []struct {
+		name     string
+		selector string
+		want     []binder.AffectedEntry
+	}{
+		{"middle", "ch2.md", []binder.AffectedEntry{{Target: "ch2.md", Title: "Two", Line: 6}}},
+		{"last", "ch3.md", []binder.AffectedEntry{{Target: "ch3.md", Title: "Three", Line: 8}}},
+		{"blank line collapsed", "ch1.md", []binder.AffectedEntry{{Target: "ch1.md", Title: "One", Line: 3}}},
+		{"several", "scene", []binder.AffectedEntry{
+			{Target: "a/scene.md", Title: "Scene", Line: 4},
+			{Target: "b/scene.md", Title: "Scene", Line: 6},
+		}},
+		{"no match", "nope.md", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, got, _ := DeleteEntries(ctx, src, nil, binder.DeleteParams{Selector: tt.selector, Yes: true})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("entries = %+v, want %+v\n%s", got, tt.want, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package ops
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// CodeBinderHasParseErrors is an implementation-specific operation error
+// (outside the conformance code space): the binder already has error-severity
+// parse diagnostics, and mutating it could compound the damage.
+const CodeBinderHasParseErrors = "PMKE004"
+
+// guardParseErrors vets a binder's parse diagnostics before a mutation.
+// Without force it returns an error diagnostic listing every error-severity
+// parse diagnostic, or nil when there are none. With force it downgrades
+// those diagnostics to warnings in place instead, so the mutation is written;
+// an exceeded parse limit is never forgiven, because the parse it cuts short
+// is incomplete.
+func guardParseErrors(parseDiags []binder.Diagnostic, force bool) *binder.Diagnostic {
+	var found []string
+	for i, d := range parseDiags {
+		if d.Severity != "error" {
+			continue
+		}
+		if force && d.Code != binder.CodeParseLimitExceeded {
+			parseDiags[i].Severity = "warning"
+			continue
+		}
+		where := ""
+		if d.Location != nil {
+			where = fmt.Sprintf(" (line %d)", d.Location.Line)
+		}
+		found = append(found, d.Code+where+": "+d.Message)
+	}
+	if len(found) == 0 {
+		return nil
+	}
+	return &binder.Diagnostic{
+		Severity: "error",
+		Code:     CodeBinderHasParseErrors,
+		Message:  "binder has parse errors; fix them first or pass --force-parse to modify it anyway: " + strings.Join(found, "; "),
+	}
+}
@@ -0,0 +1,88 @@
+package ops
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// brokenBinder has a BNDE001 (illegal path characters) finding on line 4.
+var brokenBinder = binderSrc(
+	"- [One](ch1.md)",
+	"- [Bad](b<c.md)",
+	"- [Two](ch2.md)",
+)
+
+func TestOps_RefuseBinderWithParseErrors(t *testing.T) {
+	ctx := context.Background()
+	ops := []struct {
+		name string
+		run  func(force bool) ([]byte, []binder.Diagnostic)
+	}{
+		{"add", func(force bool) ([]byte, []binder.Diagnostic) {
+			return AddChild(ctx, brokenBinder, nil, binder.AddChildParams{ParentSelector: ".", Target: "ch3.md", Position: "last", ForceParse: force})
+		}},
+		{"delete", func(force bool) ([]byte, []binder.Diagnostic) {
+			return Delete(ctx, brokenBinder, nil, binder.DeleteParams{Selector: "ch1.md", Yes: true, ForceParse: force})
+		}},
+		{"move", func(force bool) ([]byte, []binder.Diagnostic) {
+			return Move(ctx, brokenBinder, nil, binder.MoveParams{SourceSelector: "ch1.md", DestinationParentSelector: "ch2.md", Position: "last", Yes: true, ForceParse: force})
+		}},
+		{"demote", func(force bool) ([]byte, []binder.Diagnostic) {
+			out, _, diags := Demote(ctx, brokenBinder, nil, binder.ShiftParams{Selector: "ch2.md", ForceParse: force})
+			return out, diags
+		}},
+		{"retitle", func(force bool) ([]byte, []binder.Diagnostic) {
+			out, _, diags := Retitle(ctx, brokenBinder, nil, binder.RetitleParams{Selector: "ch1.md", Title: "First", ForceParse: force})
+			return out, diags
+		}},
+		{"check", func(force bool) ([]byte, []binder.Diagnostic) {
+			return SetChecked(ctx, brokenBinder, nil, binder.SetCheckedParams{Selector: "ch1.md", Checked: true, ForceParse: force})
+		}},
+		{"insert subtree", func(force bool) ([]byte, []binder.Diagnostic) {
+			return InsertSubtree(ctx, brokenBinder, nil, InsertSubtreeParams{ParentSelector: "ch2.md", ForceParse: force, Subtree: Subtree{Lines: []string{"- [Three](ch3.md)"}}})
+		}},
+	}
+	for _, op := range ops {
+		t.Run(op.name, func(t *testing.T) {
+			out, diags := op.run(false)
+			if !bytes.Equal(out, brokenBinder) {
+				t.Errorf("binder changed without ForceParse:\n%s", out)
+			}
+			last := diags[len(diags)-1]
+			if last.Code != CodeBinderHasParseErrors || last.Severity != "error" || !strings.Contains(last.Message, "BNDE001 (line 4): ") {
+				t.Errorf("last diagnostic = %+v, want %s listing BNDE001", last, CodeBinderHasParseErrors)
+			}
+
+			out, diags = op.run(true)
+			if bytes.Equal(out, brokenBinder) {
+				t.Error("binder unchanged with ForceParse")
+			}
+			for _, d := range diags {
+				if d.Severity == "error" {
+					t.Errorf("error diagnostic with ForceParse: %+v", d)
+				}
+			}
+		})
+	}
+}
+
+func TestGuardParseErrors(t *testing.T) {
+	limit := binder.Diagnostic{Severity: "error", Code: binder.CodeParseLimitExceeded, Message: "too big"}
+	warning := binder.Diagnostic{Severity: "warning", Code: binder.CodeMissingPragma, Message: "no pragma"}
+
+	if diag := guardParseErrors([]binder.Diagnostic{warning}, false); diag != nil {
+		t.Errorf("warnings only: got %+v, want nil", diag)
+	}
+	diags := []binder.Diagnostic{limit}
+	diag := guardParseErrors(diags, true)
+	if diag == nil || !strings.HasSuffix(diag.Message, binder.CodeParseLimitExceeded+": too big") {
+		t.Errorf("parse limit with force: got %+v, want it refused", diag)
+	}
+	if diags[0].Severity != "error" {
+		t.Errorf("parse limit was downgraded to %q", diags[0].Severity)
+	}
+}
@@ -0,0 +1,190 @@
+package ops
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// Link styles accepted by binder.AddChildParams.Style.
+const (
+	LinkStyleInline    = "inline"    // - [Title](target.md)
+	LinkStyleReference = "reference" // - [Title][label] plus a "[label]: target.md" definition
+	LinkStyleWikilink  = "wikilink"  // - [[target|Title]]
+	LinkStyleAuto      = "auto"      // the dominant style among siblings
+)
+
+// CodeWikilinkStemNotUnique is an implementation-specific warning emitted when
+// a new wikilink entry's bare stem would match more than one project file, so
+// the entry is written path-qualified instead.
+const CodeWikilinkStemNotUnique = "PMKW002"
+
+var (
+	inlineLinkStartRE = regexp.MustCompile(`^\[(?:[^\]\\]|\\.)*\]\(`)
+	wikilinkStartRE   = regexp.MustCompile(`^!?\[\[`)
+
+	// structuralLinkRE matches a wikilink (group 1 is its "|alias", if any),
+	// an inline link, or a full reference link.
+	structuralLinkRE = regexp.MustCompile(`!?\[\[[^\]|]+(\|[^\]]*)?\]\]|\[[^\]]*\]\([^)]*\)|\[(?:[^\]\\]|\\.)*\]\[[^\]]*\]`)
+)
+
+// structuralLinkSpan returns the [start, end) byte span of the first
+// structural link in line, or nil when there is none. A wikilink without a
+// "|alias" takes its title from the text that follows it, up to any trailing
+// HTML comments, so that text is part of the span.
+func structuralLinkSpan(line string) []int {
+	m := structuralLinkRE.FindStringSubmatchIndex(line)
+	if m == nil {
+		return nil
+	}
+	if titled := binder.StripTrailingComments(line); strings.HasSuffix(line[m[0]:m[1]], "]]") && m[2] < 0 && strings.TrimSpace(titled[m[1]:]) != "" {
+		return []int{m[0], len(strings.TrimRight(titled, " \t"))}
+	}
+	return m[:2]
+}
+
+// nodeLinkStyle classifies the structural link of n's list item.
+func nodeLinkStyle(n *binder.Node) string {
+	content := n.RawLine
+	if m := checkLinePrefixRE.FindStringIndex(content); m != nil {
+		content = content[m[1]:]
+	}
+	switch {
+	case wikilinkStartRE.MatchString(content):
+		return LinkStyleWikilink
+	case inlineLinkStartRE.MatchString(content):
+		return LinkStyleInline
+	default:
+		return LinkStyleReference
+	}
+}
+
+// resolveLinkStyle returns the concrete link style for a new child of parent.
+// Auto picks the most common style among parent's children, falling back to
+// the whole binder when parent has none; ties and empty binders prefer inline.
+// Unknown styles are treated as inline.
+func resolveLinkStyle(style string, parent, root *binder.Node) string {
+	switch style {
+	case LinkStyleReference, LinkStyleWikilink:
+		return style
+	case LinkStyleAuto:
+	default:
+		return LinkStyleInline
+	}
+	nodes := parent.Children
+	if len(nodes) == 0 {
+		nodes = collectAllNodes(root)
+	}
+	counts := make(map[string]int)
+	for _, n := range nodes {
+		counts[nodeLinkStyle(n)]++
+	}
+	best := LinkStyleInline
+	for _, s := range []string{LinkStyleReference, LinkStyleWikilink} {
+		if counts[s] > counts[best] {
+			best = s
+		}
+	}
+	return best
+}
+
+// collectAllNodes returns every node below root in document order.
+func collectAllNodes(root *binder.Node) []*binder.Node {
+	var out []*binder.Node
+	for _, c := range root.Children {
+		out = append(out, c)
+		out = append(out, collectAllNodes(c)...)
+	}
+	return out
+}
+
+// refDefAllocator assigns reference labels to new targets, reusing an
+// existing definition for the same target and collecting the definition
+// lines that must be added for the rest.
+type refDefAllocator struct {
+	defs  map[string]binder.RefDef // keyed by lowercase label
+	added []string                 // new definition lines, in allocation order
+}
+
+func newRefDefAllocator(defs map[string]binder.RefDef) *refDefAllocator {
+	a := &refDefAllocator{defs: make(map[string]binder.RefDef, len(defs))}
+	for k, v := range defs {
+		a.defs[k] = v
+	}
+	return a
+}
+
+// label returns the reference label for target.
+func (a *refDefAllocator) label(target string) string {
+	for label, d := range a.defs {
+		if d.Target == target || percentDecodeOpTarget(d.Target) == target {
+			return label
+		}
+	}
+	base := strings.NewReplacer("[", "-", "]", "-").Replace(opStemFromPath(target))
+	label := base
+	for i := 2; ; i++ {
+		if _, taken := a.defs[strings.ToLower(label)]; !taken {
+			break
+		}
+		label = base + "-" + strconv.Itoa(i)
+	}
+	a.defs[strings.ToLower(label)] = binder.RefDef{Label: strings.ToLower(label), Target: target}
+	a.added = append(a.added, "["+label+"]: "+strings.ReplaceAll(target, " ", "%20"))
+	return label
+}
+
+// formatChildLink renders the structural link for a new entry in style.
+// title is the display title (unescaped); wikilinks whose title cannot be
+// represented as an alias fall back to inline form.
+func formatChildLink(style, title, target string, refs *refDefAllocator, proj *binder.Project) (string, *binder.Diagnostic) {
+	switch style {
+	case LinkStyleReference:
+		return "[" + escapeTitle(title) + "][" + refs.label(target) + "]", nil
+	case LinkStyleWikilink:
+		if !strings.ContainsAny(title, "]|") {
+			return formatWikilink(title, target, proj)
+		}
+	}
+	return "[" + escapeTitle(title) + "](" + target + ")", nil
+}
+
+// formatWikilink renders [[stem|Title]] for target, omitting the alias when it
+// equals the stem. The bare stem is used only when it resolves to target
+// alone; otherwise the link is path-qualified, with a warning when other
+// project files share the stem.
+func formatWikilink(title, target string, proj *binder.Project) (string, *binder.Diagnostic) {
+	stem := opStemFromPath(target)
+	link := strings.TrimSuffix(target, ".md")
+	var diag *binder.Diagnostic
+	if proj != nil {
+		var others []string
+		known := false
+		for _, f := range proj.Files {
+			switch {
+			case f == target:
+				known = true
+			case strings.EqualFold(strings.TrimSuffix(path.Base(f), ".md"), stem):
+				others = append(others, f)
+			}
+		}
+		switch {
+		case len(others) > 0:
+			diag = &binder.Diagnostic{
+				Severity: "warning",
+				Code:     CodeWikilinkStemNotUnique,
+				Message:  fmt.Sprintf("wikilink stem %q also matches %s; wrote [[%s]]", stem, strings.Join(others, ", "), link),
+			}
+		case known:
+			link = stem
+		}
+	}
+	if title == stem {
+		return "[[" + link + "]]", diag
+	}
+	return "[[" + link + "|" + title + "]]", diag
+}
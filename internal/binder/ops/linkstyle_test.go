@@ -0,0 +1,262 @@
+package ops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestAddChild_LinkStyles(t *testing.T) {
+	const header = "<!-- prosemark-binder:v1 -->\n\n"
+	tests := []struct {
+		name   string
+		src    string
+		parent string
+		target string
+		title  string
+		style  string
+		files  []string
+		want   string
+		warn   string
+	}{
+		{
+			name:   "default is inline",
+			src:    header + "- [One][one]\n\n[one]: one.md\n",
+			target: "two.md",
+			title:  "Two",
+			want:   header + "- [One][one]\n- [Two](two.md)\n\n[one]: one.md\n",
+		},
+		{
+			name:   "reference appends definition after existing ones",
+			src:    header + "- [One][one]\n\n[one]: one.md\n",
+			target: "two.md",
+			title:  "Two",
+			style:  LinkStyleReference,
+			want:   header + "- [One][one]\n- [Two][two]\n\n[one]: one.md\n[two]: two.md\n",
+		},
+		{
+			name:   "reference creates a definition section",
+			src:    header + "- [One](one.md)",
+			target: "sub dir/two.md",
+			title:  "Two",
+			style:  LinkStyleReference,
+			want:   header + "- [One](one.md)\n- [Two][two]\n\n[two]: sub%20dir/two.md",
+		},
+		{
+			name:   "inline after an unterminated last line",
+			src:    header + "- [One](one.md)",
+			target: "two.md",
+			title:  "Two",
+			want:   header + "- [One](one.md)\n- [Two](two.md)",
+		},
+		{
+			name:   "reference reuses a definition for the same target",
+			src:    header + "- [One](one.md)\n\n[ch2]: two.md\n",
+			target: "two.md",
+			title:  "Two",
+			style:  LinkStyleReference,
+			want:   header + "- [One](one.md)\n- [Two][ch2]\n\n[ch2]: two.md\n",
+		},
+		{
+			name:   "reference label collision gets a suffix",
+			src:    header + "- [One][two]\n\n[two]: one.md\n",
+			target: "two.md",
+			title:  "Two",
+			style:  LinkStyleReference,
+			want:   header + "- [One][two]\n- [Two][two-2]\n\n[two]: one.md\n[two-2]: two.md\n",
+		},
+		{
+			name:   "reference definitions above the list shift with insertions",
+			src:    header + "[one]: one.md\n\n- [One][one]\n",
+			target: "two.md",
+			title:  "Two",
+			style:  LinkStyleReference,
+			want:   header + "[one]: one.md\n[two]: two.md\n\n- [One][one]\n- [Two][two]\n",
+		},
+		{
+			name:   "reference into an empty binder",
+			src:    header,
+			target: "two.md",
+			title:  "Two",
+			style:  LinkStyleReference,
+			want:   header + "\n- [Two][two]\n\n[two]: two.md\n",
+		},
+		{
+			name:   "wikilink with alias",
+			src:    header + "- [One](one.md)\n",
+			target: "part/two.md",
+			title:  "Two",
+			style:  LinkStyleWikilink,
+			want:   header + "- [One](one.md)\n- [[part/two|Two]]\n",
+		},
+		{
+			name:   "wikilink uses the bare stem when it is unique",
+			src:    header + "- [One](one.md)\n",
+			target: "part/two.md",
+			title:  "Two",
+			style:  LinkStyleWikilink,
+			files:  []string{"one.md", "part/two.md"},
+			want:   header + "- [One](one.md)\n- [[two|Two]]\n",
+		},
+		{
+			name:   "wikilink qualifies a shared stem and warns",
+			src:    header + "- [One](one.md)\n",
+			target: "part/two.md",
+			title:  "Two",
+			style:  LinkStyleWikilink,
+			files:  []string{"one.md", "part/two.md", "other/Two.md"},
+			want:   header + "- [One](one.md)\n- [[part/two|Two]]\n",
+			warn:   CodeWikilinkStemNotUnique,
+		},
+		{
+			name:   "wikilink omits alias equal to stem",
+			src:    header + "- [One](one.md)\n",
+			target: "two.md",
+			style:  LinkStyleWikilink,
+			want:   header + "- [One](one.md)\n- [[two]]\n",
+		},
+		{
+			name:   "wikilink falls back to inline for unrepresentable titles",
+			src:    header + "- [One](one.md)\n",
+			target: "two.md",
+			title:  "A|B",
+			style:  LinkStyleWikilink,
+			want:   header + "- [One](one.md)\n- [A|B](two.md)\n",
+		},
+		{
+			name:   "auto follows sibling majority",
+			src:    header + "- [[one]]\n- [[two]]\n- [Three](three.md)\n",
+			target: "four.md",
+			title:  "Four",
+			style:  LinkStyleAuto,
+			want:   header + "- [[one]]\n- [[two]]\n- [Three](three.md)\n- [[four|Four]]\n",
+		},
+		{
+			name:   "auto with no siblings uses the whole binder",
+			src:    header + "- [One][one]\n- [Two][two]\n\n[one]: one.md\n[two]: two.md\n",
+			parent: "one.md",
+			target: "child.md",
+			title:  "Child",
+			style:  LinkStyleAuto,
+			want:   header + "- [One][one]\n  - [Child][child]\n- [Two][two]\n\n[one]: one.md\n[two]: two.md\n[child]: child.md\n",
+		},
+		{
+			name:   "auto ties prefer inline",
+			src:    header + "- [ ] [One][one]\n- [Two](two.md)\n\n[one]: one.md\n",
+			target: "three.md",
+			title:  "Three",
+			style:  LinkStyleAuto,
+			want:   header + "- [ ] [One][one]\n- [Two](two.md)\n- [Three](three.md)\n\n[one]: one.md\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := tt.parent
+			if parent == "" {
+				parent = "."
+			}
+			params := binder.AddChildParams{ParentSelector: parent, Target: tt.target, Title: tt.title, Position: "last", Style: tt.style}
+			var proj *binder.Project
+			if tt.files != nil {
+				proj = &binder.Project{Files: tt.files, BinderDir: "."}
+			}
+			out, diags := AddChild(context.Background(), []byte(tt.src), proj, params)
+			for _, d := range diags {
+				if d.Severity == "error" {
+					t.Fatalf("unexpected error diagnostic: %v", d)
+				}
+			}
+			if got := hasDiagCode(diags, tt.warn); got != (tt.warn != "") {
+				t.Errorf("diagnostics %v, want warning %q", diags, tt.warn)
+			}
+			if string(out) != tt.want {
+				t.Errorf("output:\n%q\nwant:\n%q", out, tt.want)
+			}
+			// The new entry must parse back to the requested target.
+			result, _, err := binder.Parse(context.Background(), out, proj)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if findNodeByTargetForTest(result.Root, percentDecodeOpTarget(tt.target)) == nil {
+				t.Errorf("new entry for %s not found after re-parse:\n%s", tt.target, out)
+			}
+		})
+	}
+}
+
+func findNodeByTargetForTest(n *binder.Node, target string) *binder.Node {
+	if n.Target == target {
+		return n
+	}
+	for _, c := range n.Children {
+		if found := findNodeByTargetForTest(c, target); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func TestResolveLinkStyle_UnknownIsInline(t *testing.T) {
+	root := &binder.Node{Type: "root"}
+	if got := resolveLinkStyle("fancy", root, root); got != LinkStyleInline {
+		t.Errorf("resolveLinkStyle(fancy) = %q, want inline", got)
+	}
+}
+
+func TestStructuralLinkSpan(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"- [A](a.md) note", "[A](a.md)"},
+		{"- [A][a] note", "[A][a]"},
+		{"- [[a|A]] note", "[[a|A]]"},
+		{"- ~~x~~ [[a]] Trailing Title  ", "[[a]] Trailing Title"},
+		{"- [[a]]", "[[a]]"},
+		{"- plain text", ""},
+	}
+	for _, tt := range tests {
+		loc := structuralLinkSpan(tt.line)
+		got := ""
+		if loc != nil {
+			got = tt.line[loc[0]:loc[1]]
+		}
+		if got != tt.want {
+			t.Errorf("structuralLinkSpan(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestMove_KeepsWikilinkAndReferenceFormatting(t *testing.T) {
+	src := "<!-- prosemark-binder:v1 -->\n\n" +
+		"- [Part](part.md)\n" +
+		"- [[a|Alpha]] draft\n" +
+		"- [[b]] Beta\n" +
+		"- [Gamma][c] todo\n\n" +
+		"[c]: c.md\n"
+	proj := &binder.Project{Files: []string{"part.md", "a.md", "b.md", "c.md"}, BinderDir: "."}
+	for _, sel := range []string{"a.md", "b.md", "c.md"} {
+		out, diags := Move(context.Background(), []byte(src), proj, binder.MoveParams{
+			SourceSelector:            sel,
+			DestinationParentSelector: "part.md",
+			Position:                  "last",
+			Yes:                       true,
+		})
+		for _, d := range diags {
+			if d.Severity == "error" {
+				t.Fatalf("move %s: unexpected error diagnostic: %v", sel, d)
+			}
+		}
+		src = string(out)
+	}
+	want := "<!-- prosemark-binder:v1 -->\n\n" +
+		"- [Part](part.md)\n" +
+		"  - [[a|Alpha]]\n" +
+		"  - [[b]] Beta\n" +
+		"  - [Gamma][c]\n\n" +
+		"[c]: c.md\n"
+	if src != want {
+		t.Errorf("output:\n%q\nwant:\n%q", src, want)
+	}
+}
@@ -0,0 +1,85 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// CodeNotPlaceholder is an implementation-specific error emitted when
+// materialize selects an entry that already links to a file.
+const CodeNotPlaceholder = "PMKE005"
+
+// emptyLinkRE matches an inline link with an empty target, "[Title]()".
+// Group 1 spans its parentheses.
+var emptyLinkRE = regexp.MustCompile(`\[(?:[^\]\\]|\\.)*\](\(\s*\))`)
+
+// Materialize turns the placeholder entry selected by params.Selector into
+// an entry linking to params.Target: the empty target of "[Title]()" is
+// filled in, and a plain-text placeholder becomes "[text](target)". The
+// selector must match exactly one entry. Returns the modified bytes, the
+// materialized entry, and diagnostics. Source bytes are unchanged on error.
+func Materialize(ctx context.Context, src []byte, project *binder.Project, params binder.MaterializeParams) ([]byte, []binder.AffectedEntry, []binder.Diagnostic) {
+	result, parseDiags, err := binder.Parse(ctx, src, project)
+	if err != nil {
+		return src, nil, append(parseDiags, binder.Diagnostic{
+			Severity: "error",
+			Code:     binder.CodeIOOrParseFailure,
+			Message:  fmt.Sprintf("parse error: %v", err),
+		})
+	}
+	if diag := guardParseErrors(parseDiags, params.ForceParse); diag != nil {
+		return src, nil, append(parseDiags, *diag)
+	}
+	if diag := resolveBookmarks(project, &params.Selector); diag != nil {
+		return src, nil, append(parseDiags, *diag)
+	}
+
+	target := NormalizeTarget(params.Target)
+	if diag := validateOpTarget(target, project); diag != nil {
+		return src, nil, append(parseDiags, *diag)
+	}
+
+	nodes, selDiags := moveEvalSourceSelector(params.Selector, result.Root, result.Fenced)
+	if len(nodes) == 0 {
+		return src, nil, append(parseDiags, selDiags...)
+	}
+	if len(nodes) > 1 {
+		return src, nil, append(parseDiags, binder.Diagnostic{
+			Severity: "error",
+			Code:     binder.CodeAmbiguousBareStem,
+			Message:  fmt.Sprintf("selector %q matched %d entries; materialize needs exactly one", params.Selector, len(nodes)),
+		})
+	}
+	n := nodes[0]
+	if n.Target != "" {
+		return src, nil, append(parseDiags, binder.Diagnostic{
+			Severity: "error",
+			Code:     CodeNotPlaceholder,
+			Message:  fmt.Sprintf("%q is not a placeholder: it already links to %s", n.Title, n.Target),
+			Location: &binder.Location{Line: n.Line},
+		})
+	}
+
+	idx := n.Line - 1
+	if n.Type == "placeholder" {
+		line := result.Lines[idx]
+		prefixEnd := checkLinePrefixRE.FindStringIndex(line)[1]
+		text := strings.TrimRight(binder.StripTrailingComments(line[prefixEnd:]), " \t")
+		result.Lines[idx] = line[:prefixEnd] + "[" + escapeTitle(text) + "](" + target + ")" + line[prefixEnd+len(text):]
+	} else {
+		// The link may sit on the item's continuation line.
+		if !emptyLinkRE.MatchString(result.Lines[idx]) {
+			idx++
+		}
+		line := result.Lines[idx]
+		m := emptyLinkRE.FindStringSubmatchIndex(line)
+		result.Lines[idx] = line[:m[2]] + "(" + target + ")" + line[m[3]:]
+	}
+
+	out := binder.Serialize(result)
+	return out, locateEntries(ctx, out, project, []int{n.Line - 1}), append(parseDiags, selDiags...)
+}
@@ -0,0 +1,88 @@
+package ops
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestMaterialize(t *testing.T) {
+	textProject := &binder.Project{Files: []string{"ch1.md", "ch2.md"}, BinderDir: ".", TextPlaceholders: true}
+	tests := []struct {
+		name      string
+		src       []byte
+		project   *binder.Project
+		selector  string
+		want      []byte
+		wantEntry binder.AffectedEntry
+	}{
+		{
+			name:      "link placeholder",
+			src:       binderSrc("- [One](ch1.md)", "  - [x] [Draft \\[2\\]]() <!-- note -->"),
+			selector:  "Draft [2]",
+			want:      binderSrc("- [One](ch1.md)", "  - [x] [Draft \\[2\\]](new.md) <!-- note -->"),
+			wantEntry: binder.AffectedEntry{Target: "new.md", Title: "Draft [2]", Line: 4, Path: []int{0, 0}},
+		},
+		{
+			name:      "link placeholder on continuation line",
+			src:       binderSrc("- Part one", "  [Part One]( )", "- [Other]()"),
+			selector:  "Part One",
+			want:      binderSrc("- Part one", "  [Part One](new.md)", "- [Other]()"),
+			wantEntry: binder.AffectedEntry{Target: "new.md", Title: "Part One", Line: 3, Path: []int{0}},
+		},
+		{
+			name:      "text placeholder",
+			project:   textProject,
+			src:       binderSrc("- [One](ch1.md)", "- [ ] Act [II] <!-- 0 words -->", "  - [[ch2]]"),
+			selector:  "Act [II]",
+			want:      binderSrc("- [One](ch1.md)", "- [ ] [Act \\[II\\]](new.md) <!-- 0 words -->", "  - [[ch2]]"),
+			wantEntry: binder.AffectedEntry{Target: "new.md", Title: "Act [II]", Line: 4, Path: []int{1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, entries, diags := Materialize(context.Background(), tt.src, tt.project, binder.MaterializeParams{Selector: tt.selector, Target: "./new.md"})
+			if len(diags) != 0 {
+				t.Fatalf("diags = %v", diags)
+			}
+			if string(out) != string(tt.want) {
+				t.Errorf("Materialize() =\n%s\nwant\n%s", out, tt.want)
+			}
+			if len(entries) != 1 || !reflect.DeepEqual(entries[0], tt.wantEntry) {
+				t.Errorf("entries = %+v, want %+v", entries, tt.wantEntry)
+			}
+		})
+	}
+}
+
+func TestMaterialize_Errors(t *testing.T) {
+	src := binderSrc("- [One](ch1.md)", "- [Draft]()", "- [Draft]()")
+	tests := []struct {
+		name     string
+		src      []byte
+		selector string
+		target   string
+		wantCode string
+	}{
+		{"invalid UTF-8", []byte("\xff\xfe"), "Draft", "new.md", binder.CodeIOOrParseFailure},
+		{"parse errors", brokenBinder, "One", "new.md", CodeBinderHasParseErrors},
+		{"invalid target", src, "Draft", "../new.md", binder.CodeInvalidTargetPath},
+		{"no match", src, "Missing", "new.md", binder.CodeSelectorNoMatch},
+		{"several matches", src, "Draft", "new.md", binder.CodeAmbiguousBareStem},
+		{"not a placeholder", src, "One", "new.md", CodeNotPlaceholder},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, entries, diags := Materialize(context.Background(), tt.src, nil, binder.MaterializeParams{Selector: tt.selector, Target: tt.target})
+			if !hasDiagCode(diags, tt.wantCode) {
+				t.Errorf("diags = %v, want %s", diags, tt.wantCode)
+			}
+			if string(out) != string(tt.src) || entries != nil {
+				t.Errorf("out = %q, entries = %v; want the source unchanged", out, entries)
+			}
+		})
+	}
+}
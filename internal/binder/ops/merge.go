@@ -0,0 +1,558 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// CodeMergeConflict is an implementation-specific error emitted when a
+// three-way binder merge finds the same entry changed divergently.
+const CodeMergeConflict = "PMKE012"
+
+// CodeMergeReparented is an implementation-specific warning emitted when a
+// three-way binder merge keeps an entry whose parent one side deleted,
+// placing it under the nearest surviving ancestor.
+const CodeMergeReparented = "PMKW007"
+
+// Merge performs a structural three-way merge of two binders, ours and
+// theirs, that both descend from base (pmk merge-binder). Entries are
+// matched by target, so each side's additions, removals, edits, and moves
+// (to a new parent or a new place among their siblings) carry over unless
+// the other side changed the same entry another way. Such divergent changes
+// are reported as PMKE012 errors: conflicting edits to an entry's line are
+// left between conflict markers, and conflicting moves keep ours.
+//
+// The text around the list, and the lines that sit between entries, travel
+// with the entry they precede. Merging a binder with itself returns it
+// unchanged. Parse errors in any version abort the merge, returning ours.
+func Merge(ctx context.Context, base, ours, theirs []byte, project *binder.Project) ([]byte, []binder.Diagnostic) {
+	var diags []binder.Diagnostic
+	versions := make([]*mergeVersion, 3)
+	for i, src := range [][]byte{base, ours, theirs} {
+		name := [...]string{"base", "ours", "theirs"}[i]
+		v, vDiags := newMergeVersion(ctx, name, src, project)
+		diags = append(diags, vDiags...)
+		versions[i] = v
+	}
+	if hasErrorDiag(diags) {
+		return ours, diags
+	}
+
+	m := &merger{base: versions[0], ours: versions[1], theirs: versions[2], nodes: make(map[string]*mergedNode)}
+	m.mergeEntries()
+	m.adoptOrphans()
+	m.breakCycles()
+	out := m.render()
+	return out, append(diags, m.diags...)
+}
+
+// hasErrorDiag reports whether diags include an error.
+func hasErrorDiag(diags []binder.Diagnostic) bool {
+	return slices.ContainsFunc(diags, func(d binder.Diagnostic) bool { return d.Severity == "error" })
+}
+
+// mergeNode is one entry of one version of the binder.
+type mergeNode struct {
+	key    string
+	parent string // key of the parent entry; "" at the top level
+	// lines are the entry's source lines: those between the previous entry
+	// and this one, the item line itself at index item, and its indented
+	// continuation lines.
+	lines   []string
+	ends    []string
+	item    int
+	indent  string
+	marker  string
+	content string // lines without indentation or list marker, for comparison
+}
+
+// mergeVersion is one parsed version of the binder.
+type mergeVersion struct {
+	nodes    map[string]*mergeNode
+	order    []string            // keys in document order
+	children map[string][]string // parent key to child keys, in order
+	// prelude and postlude are the lines before the first entry and after
+	// the last.
+	prelude, preludeEnds   []string
+	postlude, postludeEnds []string
+	lastEnd                string // the file's final line ending, if any
+	bom                    bool
+}
+
+// newMergeVersion parses src into a mergeVersion named name.
+func newMergeVersion(ctx context.Context, name string, src []byte, project *binder.Project) (*mergeVersion, []binder.Diagnostic) {
+	result, parseDiags, err := binder.Parse(ctx, src, project)
+	if err != nil {
+		return nil, []binder.Diagnostic{{
+			Severity: "error",
+			Code:     binder.CodeIOOrParseFailure,
+			Message:  fmt.Sprintf("%s: parse error: %v", name, err),
+		}}
+	}
+	if d := guardParseErrors(parseDiags, false); d != nil {
+		d.Message = name + ": " + d.Message
+		return nil, []binder.Diagnostic{*d}
+	}
+
+	v := &mergeVersion{nodes: make(map[string]*mergeNode), children: make(map[string][]string), bom: result.HasBOM}
+	if n := len(result.LineEnds); n > 0 {
+		v.lastEnd = result.LineEnds[n-1]
+	}
+	seen := make(map[string]int)
+	var flat []*binder.Node
+	var parents []string
+	var walk func(n *binder.Node, parent string)
+	walk = func(n *binder.Node, parent string) {
+		for _, c := range n.Children {
+			// Placeholders, [Title]() or plain text, have no target and
+			// are matched by title.
+			key := c.Target
+			if key == "" {
+				key = "placeholder:" + c.Title
+			}
+			if seen[key]++; seen[key] > 1 {
+				key = fmt.Sprintf("%s#%d", key, seen[key])
+			}
+			flat = append(flat, c)
+			parents = append(parents, parent)
+			v.order = append(v.order, key)
+			v.children[parent] = append(v.children[parent], key)
+			walk(c, key)
+		}
+	}
+	walk(result.Root, "")
+
+	lines, ends := result.Lines, result.LineEnds
+	if len(flat) == 0 {
+		v.prelude, v.preludeEnds = lines, ends
+		return v, nil
+	}
+	v.prelude, v.preludeEnds = lines[:flat[0].Line-1], ends[:flat[0].Line-1]
+	start := flat[0].Line - 1
+	for i, n := range flat {
+		item := n.Line - 1
+		next := len(lines)
+		if i+1 < len(flat) {
+			next = flat[i+1].Line - 1
+		}
+		end := item + 1
+		for end < next && isContinuationLine(lines[end]) {
+			end++
+		}
+		key := v.order[i]
+		mn := &mergeNode{
+			key:    key,
+			parent: parents[i],
+			lines:  lines[start:end],
+			ends:   ends[start:end],
+			item:   item - start,
+			indent: rawIndent(n),
+			marker: n.ListMarker,
+		}
+		mn.content = mergeContent(mn)
+		v.nodes[key] = mn
+		start = end
+	}
+	v.postlude, v.postludeEnds = lines[start:], ends[start:]
+	return v, nil
+}
+
+// isContinuationLine reports whether line continues the list item above it:
+// it is indented and not blank.
+func isContinuationLine(line string) bool {
+	return strings.TrimSpace(line) != "" && (line[0] == ' ' || line[0] == '\t')
+}
+
+// mergeContent returns the comparable text of n: its non-blank lines with
+// indentation removed, and the item line without its list marker.
+func mergeContent(n *mergeNode) string {
+	var parts []string
+	for i, line := range n.lines {
+		line = strings.TrimSpace(line)
+		if i == n.item {
+			if loc := moveFirstLineMarkerRE.FindStringIndex(line); loc != nil {
+				line = line[loc[1]:]
+			}
+		}
+		if line != "" {
+			parts = append(parts, line)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// mergedNode is an entry of the merged binder.
+type mergedNode struct {
+	key    string
+	parent string
+	// from supplies the entry's lines; conflict, when set, is theirs' version
+	// of an entry whose lines both sides changed, from being ours'.
+	from, conflict *mergeNode
+	fromVersion    *mergeVersion
+	// theirsParent records that parent was taken from theirs, against ours.
+	theirsParent bool
+}
+
+// merger holds the state of one three-way merge.
+type merger struct {
+	base, ours, theirs *mergeVersion
+	nodes              map[string]*mergedNode
+	keys               []string // merged keys in a stable order
+	diags              []binder.Diagnostic
+}
+
+// conflict records a PMKE012 error.
+func (m *merger) conflict(format string, args ...any) {
+	m.diags = append(m.diags, binder.Diagnostic{Severity: "error", Code: CodeMergeConflict, Message: fmt.Sprintf(format, args...)})
+}
+
+// mergeEntries decides which entries the merged binder has, where each is
+// placed, and which version's lines it takes.
+func (m *merger) mergeEntries() {
+	var all []string
+	seen := make(map[string]bool)
+	for _, v := range []*mergeVersion{m.ours, m.theirs, m.base} {
+		for _, k := range v.order {
+			if !seen[k] {
+				seen[k] = true
+				all = append(all, k)
+			}
+		}
+	}
+
+	for _, k := range all {
+		b, o, t := m.base.nodes[k], m.ours.nodes[k], m.theirs.nodes[k]
+		var n *mergedNode
+		switch {
+		case o == nil && t == nil:
+			continue
+		case o == nil:
+			if b != nil && t.content == b.content {
+				continue // deleted in ours
+			}
+			if b != nil {
+				m.conflict("%s was deleted in ours but edited in theirs; kept theirs' edit", displayKey(k))
+			}
+			n = &mergedNode{key: k, parent: t.parent, from: t, fromVersion: m.theirs, theirsParent: true}
+		case t == nil:
+			if b != nil && o.content == b.content {
+				continue // deleted in theirs
+			}
+			if b != nil {
+				m.conflict("%s was deleted in theirs but edited in ours; kept ours' edit", displayKey(k))
+			}
+			n = &mergedNode{key: k, parent: o.parent, from: o, fromVersion: m.ours}
+		default:
+			n = &mergedNode{key: k, from: o, fromVersion: m.ours}
+			baseParent, baseContent := "\x00", "\x00" // added on both sides
+			if b != nil {
+				baseParent, baseContent = b.parent, b.content
+			}
+			parent, ok := pick3(baseParent, o.parent, t.parent)
+			if !ok {
+				m.conflict("%s was moved under %s in ours but under %s in theirs; kept ours", displayKey(k), displayParent(o.parent), displayParent(t.parent))
+			}
+			n.parent = parent
+			n.theirsParent = parent != o.parent
+			switch content, ok := pick3(baseContent, o.content, t.content); {
+			case !ok:
+				n.conflict = t
+				m.conflict("%s was edited differently in ours and theirs", displayKey(k))
+			case content != o.content:
+				n.from, n.fromVersion = t, m.theirs
+			}
+		}
+		m.nodes[k] = n
+		m.keys = append(m.keys, k)
+	}
+}
+
+// pick3 merges one value three ways: it takes the side that changed it from
+// base, or ours when neither did or both changed it alike. ok is false when
+// the sides changed it differently; the value is then ours.
+func pick3(base, ours, theirs string) (v string, ok bool) {
+	switch {
+	case ours == theirs || theirs == base:
+		return ours, true
+	case ours == base:
+		return theirs, true
+	default:
+		return ours, false
+	}
+}
+
+// displayKey returns k as diagnostics name it.
+func displayKey(k string) string {
+	return strings.TrimPrefix(k, "placeholder:")
+}
+
+// displayParent returns parent key k as diagnostics name it.
+func displayParent(k string) string {
+	if k == "" {
+		return "the top level"
+	}
+	return displayKey(k)
+}
+
+// parentIn returns the parent of k in the first version that has k, or ""
+// when none does.
+func (m *merger) parentIn(k string, versions ...*mergeVersion) (string, bool) {
+	for _, v := range versions {
+		if n := v.nodes[k]; n != nil {
+			return n.parent, true
+		}
+	}
+	return "", false
+}
+
+// adoptOrphans places each entry whose parent did not survive the merge
+// under its nearest surviving ancestor.
+func (m *merger) adoptOrphans() {
+	for _, k := range m.keys {
+		n := m.nodes[k]
+		if n.parent == "" || m.nodes[n.parent] != nil {
+			continue
+		}
+		gone := n.parent
+		p := gone
+		for p != "" && m.nodes[p] == nil {
+			p, _ = m.parentIn(p, n.fromVersion, m.base, m.ours, m.theirs)
+		}
+		n.parent = p
+		m.diags = append(m.diags, binder.Diagnostic{
+			Severity: "warning",
+			Code:     CodeMergeReparented,
+			Message:  fmt.Sprintf("%s was deleted, so %s was placed under %s", displayKey(gone), displayKey(k), displayParent(p)),
+		})
+	}
+}
+
+// breakCycles undoes moves that, combined, would make an entry its own
+// ancestor: ours moved one entry under another while theirs moved them the
+// other way round. The entry theirs placed goes back to ours' parent, or
+// the top level.
+func (m *merger) breakCycles() {
+	for {
+		cycle := m.findCycle()
+		if cycle == nil {
+			return
+		}
+		n := m.nodes[cycle[0]]
+		for _, k := range cycle {
+			if m.nodes[k].theirsParent {
+				n = m.nodes[k]
+				break
+			}
+		}
+		parent := ""
+		if p, ok := m.parentIn(n.key, m.ours); ok && n.theirsParent && m.nodes[p] != nil {
+			parent = p
+		}
+		m.conflict("%s and %s were moved under each other; kept %s under %s", displayKey(cycle[0]), displayKey(cycle[len(cycle)-1]), displayKey(n.key), displayParent(parent))
+		n.parent, n.theirsParent = parent, false
+	}
+}
+
+// findCycle returns the keys of a cycle of merged parents, or nil.
+func (m *merger) findCycle() []string {
+	for _, k := range m.keys {
+		var path []string
+		onPath := make(map[string]int)
+		for p := k; p != ""; p = m.nodes[p].parent {
+			if i, ok := onPath[p]; ok {
+				return path[i:]
+			}
+			onPath[p] = len(path)
+			path = append(path, p)
+		}
+	}
+	return nil
+}
+
+// orderChildren returns the keys of the merged entries under parent in
+// order. When one side reordered them, its order wins; entries only the
+// other side has are placed after the entry they follow there.
+func (m *merger) orderChildren(parent string) []string {
+	kids := make(map[string]bool)
+	for _, k := range m.keys {
+		if m.nodes[k].parent == parent {
+			kids[k] = true
+		}
+	}
+	seq := func(v *mergeVersion) []string {
+		return slices.DeleteFunc(slices.Clone(v.children[parent]), func(k string) bool { return !kids[k] })
+	}
+	sb, so, st := seq(m.base), seq(m.ours), seq(m.theirs)
+	oursMoved := !slices.Equal(commonOrder(so, sb), commonOrder(sb, so))
+	theirsMoved := !slices.Equal(commonOrder(st, sb), commonOrder(sb, st))
+	primary, secondary := so, st
+	if theirsMoved && !oursMoved {
+		primary, secondary = st, so
+	}
+	if oursMoved && theirsMoved && !slices.Equal(commonOrder(so, st), commonOrder(st, so)) {
+		m.conflict("entries under %s were reordered differently in ours and theirs; kept ours' order", displayParent(parent))
+	}
+
+	out := slices.Clone(primary)
+	for i, k := range secondary {
+		if slices.Contains(out, k) {
+			continue
+		}
+		at := 0
+		for j := i - 1; j >= 0; j-- {
+			if idx := slices.Index(out, secondary[j]); idx >= 0 {
+				at = idx + 1
+				break
+			}
+		}
+		out = slices.Insert(out, at, k)
+	}
+	for _, k := range m.keys {
+		if kids[k] && !slices.Contains(out, k) {
+			out = append(out, k) // adopted orphans
+		}
+	}
+	return out
+}
+
+// commonOrder returns the keys of a that are also in b, in a's order.
+func commonOrder(a, b []string) []string {
+	return slices.DeleteFunc(slices.Clone(a), func(k string) bool { return !slices.Contains(b, k) })
+}
+
+// mergeOutput accumulates the lines of the merged binder.
+type mergeOutput struct {
+	lines, ends []string
+}
+
+func (o *mergeOutput) add(lines, ends []string) {
+	o.lines = append(o.lines, lines...)
+	o.ends = append(o.ends, ends...)
+}
+
+// addMarker adds a conflict marker line.
+func (o *mergeOutput) addMarker(marker string) {
+	o.add([]string{marker}, []string{"\n"})
+}
+
+// render writes out the merged binder.
+func (m *merger) render() []byte {
+	var out mergeOutput
+	m.renderText(&out, "the text before the list", func(v *mergeVersion) ([]string, []string) { return v.prelude, v.preludeEnds })
+	indents := map[string]string{"": ""}
+	markers := map[string]string{"": ""}
+	var walk func(parent string)
+	walk = func(parent string) {
+		prevMarker := ""
+		for _, k := range m.orderChildren(parent) {
+			n := m.nodes[k]
+			indent, marker := n.from.indent, n.from.marker
+			placed := n.from.parent == n.parent && n.conflict == nil
+			if placed && parent != "" {
+				pn := n.fromVersion.nodes[parent]
+				placed = pn != nil && indents[parent] == pn.indent
+			}
+			if !placed {
+				indent, marker = childIndent(indents[parent], markers[parent]), siblingMarker(prevMarker)
+			}
+			if n.conflict != nil {
+				out.addMarker("<<<<<<< ours")
+				out.add(placeLines(n.from, indent, marker))
+				out.addMarker("=======")
+				out.add(placeLines(n.conflict, indent, marker))
+				out.addMarker(">>>>>>> theirs")
+			} else if placed {
+				out.add(n.from.lines, n.from.ends)
+			} else {
+				out.add(placeLines(n.from, indent, marker))
+			}
+			indents[k], markers[k], prevMarker = indent, marker, marker
+			walk(k)
+		}
+	}
+	walk("")
+	m.renderText(&out, "the text after the list", func(v *mergeVersion) ([]string, []string) { return v.postlude, v.postludeEnds })
+
+	// Lines taken from the end of a file may lack a line ending, and the
+	// file ends as ours does.
+	eol := "\n"
+	if len(m.ours.preludeEnds) > 0 && m.ours.preludeEnds[0] != "" {
+		eol = m.ours.preludeEnds[0]
+	}
+	for i := range out.ends {
+		if out.ends[i] == "" {
+			out.ends[i] = eol
+		}
+	}
+	if n := len(out.ends); n > 0 {
+		out.ends[n-1] = m.ours.lastEnd
+	}
+	return binder.Serialize(&binder.ParseResult{Lines: out.lines, LineEnds: out.ends, HasBOM: m.ours.bom})
+}
+
+// renderText merges the text outside the list, which part selects, as a
+// whole: the side that changed it wins, and divergent changes are left
+// between conflict markers.
+func (m *merger) renderText(out *mergeOutput, what string, part func(v *mergeVersion) ([]string, []string)) {
+	bl, _ := part(m.base)
+	ol, oe := part(m.ours)
+	tl, te := part(m.theirs)
+	b, o, t := strings.Join(bl, "\n"), strings.Join(ol, "\n"), strings.Join(tl, "\n")
+	switch v, ok := pick3(b, o, t); {
+	case !ok:
+		m.conflict("%s was edited differently in ours and theirs", what)
+		out.addMarker("<<<<<<< ours")
+		out.add(ol, oe)
+		out.addMarker("=======")
+		out.add(tl, te)
+		out.addMarker(">>>>>>> theirs")
+	case v == o:
+		out.add(ol, oe)
+	default:
+		out.add(tl, te)
+	}
+}
+
+// placeLines returns n's lines with its item re-indented to indent and
+// given marker, and its continuation lines shifted along with it.
+func placeLines(n *mergeNode, indent, marker string) ([]string, []string) {
+	lines := slices.Clone(n.lines)
+	for i := n.item; i < len(lines); i++ {
+		if i == n.item {
+			lines[i] = moveReindentFirstLine(lines[i], indent, marker, true)
+		} else {
+			lines[i] = moveReindentLine(lines[i], len(n.indent), indent)
+		}
+	}
+	return lines, n.ends
+}
+
+// childIndent returns the indentation of an entry placed under a parent
+// with the given indentation and list marker: deep enough to nest under the
+// marker, in tabs when the parent is indented with tabs.
+func childIndent(parentIndent, parentMarker string) string {
+	if parentMarker == "" {
+		return "" // the top level
+	}
+	if strings.HasPrefix(parentIndent, "\t") {
+		return parentIndent + "\t"
+	}
+	return parentIndent + strings.Repeat(" ", len(parentMarker)+1)
+}
+
+// siblingMarker returns the list marker of an entry placed after a sibling
+// with marker prev, continuing an ordered list's numbering.
+func siblingMarker(prev string) string {
+	switch {
+	case prev == "":
+		return "-"
+	case isOrderedMarker(prev):
+		return fmt.Sprintf("%d%s", ordinalValue(prev)+1, orderedStyle(prev))
+	default:
+		return prev
+	}
+}
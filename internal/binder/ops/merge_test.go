@@ -0,0 +1,264 @@
+package ops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestMerge(t *testing.T) {
+	base := binderSrc(
+		"- [Part](part.md)",
+		"  - [One](ch1.md)",
+		"  - [Two](ch2.md)",
+		"- [Coda](coda.md)",
+	)
+	tests := []struct {
+		name         string
+		ours, theirs []byte
+		want         []byte
+		wantCodes    []string
+	}{
+		{
+			name:   "additions on both sides",
+			ours:   binderSrc("- [Part](part.md)", "  - [One](ch1.md)", "  - [Two](ch2.md)", "  - [Three](ch3.md)", "- [Coda](coda.md)"),
+			theirs: binderSrc("- [Prologue](pro.md)", "- [Part](part.md)", "  - [One](ch1.md)", "  - [Two](ch2.md)", "- [Coda](coda.md)"),
+			want:   binderSrc("- [Prologue](pro.md)", "- [Part](part.md)", "  - [One](ch1.md)", "  - [Two](ch2.md)", "  - [Three](ch3.md)", "- [Coda](coda.md)"),
+		},
+		{
+			name:   "removal in ours, retitle of another entry in theirs",
+			ours:   binderSrc("- [Part](part.md)", "  - [Two](ch2.md)", "- [Coda](coda.md)"),
+			theirs: binderSrc("- [Part](part.md)", "  - [One](ch1.md)", "  - [Second](ch2.md)", "- [Coda](coda.md)"),
+			want:   binderSrc("- [Part](part.md)", "  - [Second](ch2.md)", "- [Coda](coda.md)"),
+		},
+		{
+			name:   "move in theirs under another parent is re-indented",
+			ours:   binderSrc("- [Part](part.md)", "  - [One](ch1.md)", "  - [Two](ch2.md)", "- [Coda](coda.md)", "- [New](new.md)"),
+			theirs: binderSrc("- [Part](part.md)", "  - [Two](ch2.md)", "- [Coda](coda.md)", "  - [One](ch1.md)"),
+			want:   binderSrc("- [Part](part.md)", "  - [Two](ch2.md)", "- [Coda](coda.md)", "  - [One](ch1.md)", "- [New](new.md)"),
+		},
+		{
+			name:   "reorder in ours with an addition in theirs",
+			ours:   binderSrc("- [Part](part.md)", "  - [Two](ch2.md)", "  - [One](ch1.md)", "- [Coda](coda.md)"),
+			theirs: binderSrc("- [Part](part.md)", "  - [One](ch1.md)", "  - [One and a half](ch1b.md)", "  - [Two](ch2.md)", "- [Coda](coda.md)"),
+			want:   binderSrc("- [Part](part.md)", "  - [Two](ch2.md)", "  - [One](ch1.md)", "  - [One and a half](ch1b.md)", "- [Coda](coda.md)"),
+		},
+		{
+			name:      "divergent retitles conflict",
+			ours:      binderSrc("- [Part](part.md)", "  - [First](ch1.md)", "  - [Two](ch2.md)", "- [Coda](coda.md)"),
+			theirs:    binderSrc("- [Part](part.md)", "  - [Opening](ch1.md)", "  - [Two](ch2.md)", "- [Coda](coda.md)"),
+			want:      binderSrc("- [Part](part.md)", "<<<<<<< ours", "  - [First](ch1.md)", "=======", "  - [Opening](ch1.md)", ">>>>>>> theirs", "  - [Two](ch2.md)", "- [Coda](coda.md)"),
+			wantCodes: []string{CodeMergeConflict},
+		},
+		{
+			name:      "divergent moves keep ours",
+			ours:      binderSrc("- [Part](part.md)", "  - [Two](ch2.md)", "- [Coda](coda.md)", "  - [One](ch1.md)"),
+			theirs:    binderSrc("- [Part](part.md)", "  - [Two](ch2.md)", "    - [One](ch1.md)", "- [Coda](coda.md)"),
+			want:      binderSrc("- [Part](part.md)", "  - [Two](ch2.md)", "- [Coda](coda.md)", "  - [One](ch1.md)"),
+			wantCodes: []string{CodeMergeConflict},
+		},
+		{
+			name:      "deletion against an edit keeps the edit",
+			ours:      binderSrc("- [Part](part.md)", "  - [Two](ch2.md)", "- [Coda](coda.md)"),
+			theirs:    binderSrc("- [Part](part.md)", "  - [x] [One](ch1.md)", "  - [Two](ch2.md)", "- [Coda](coda.md)"),
+			want:      binderSrc("- [Part](part.md)", "  - [x] [One](ch1.md)", "  - [Two](ch2.md)", "- [Coda](coda.md)"),
+			wantCodes: []string{CodeMergeConflict},
+		},
+		{
+			name:      "addition under a parent the other side deleted",
+			ours:      binderSrc("- [Coda](coda.md)"),
+			theirs:    binderSrc("- [Part](part.md)", "  - [One](ch1.md)", "    - [Scene](s1.md)", "  - [Two](ch2.md)", "- [Coda](coda.md)"),
+			want:      binderSrc("- [Coda](coda.md)", "- [Scene](s1.md)"),
+			wantCodes: []string{CodeMergeReparented},
+		},
+		{
+			name:      "moves under each other are broken",
+			ours:      binderSrc("- [Part](part.md)", "  - [One](ch1.md)", "    - [Two](ch2.md)", "- [Coda](coda.md)"),
+			theirs:    binderSrc("- [Part](part.md)", "  - [Two](ch2.md)", "    - [One](ch1.md)", "- [Coda](coda.md)"),
+			want:      binderSrc("- [Part](part.md)", "  - [One](ch1.md)", "    - [Two](ch2.md)", "- [Coda](coda.md)"),
+			wantCodes: []string{CodeMergeConflict},
+		},
+		{
+			name:   "text around the list merges as a whole",
+			ours:   []byte("<!-- prosemark-binder:v1 -->\n\n# Novel\n\n- [Part](part.md)\n  - [One](ch1.md)\n  - [Two](ch2.md)\n- [Coda](coda.md)\n"),
+			theirs: binderSrc("- [Part](part.md)", "  - [One](ch1.md)", "  - [Two](ch2.md)", "- [Coda](coda.md)", "- [Appendix](app.md)"),
+			want:   []byte("<!-- prosemark-binder:v1 -->\n\n# Novel\n\n- [Part](part.md)\n  - [One](ch1.md)\n  - [Two](ch2.md)\n- [Coda](coda.md)\n- [Appendix](app.md)\n"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diags := Merge(context.Background(), base, tt.ours, tt.theirs, nil)
+			if string(got) != string(tt.want) {
+				t.Errorf("Merge() =\n%s\nwant:\n%s", got, tt.want)
+			}
+			if len(diags) != len(tt.wantCodes) {
+				t.Fatalf("diagnostics = %v, want codes %v", diags, tt.wantCodes)
+			}
+			for i, code := range tt.wantCodes {
+				if diags[i].Code != code {
+					t.Errorf("diagnostic %d = %s, want %s", i, diags[i].Code, code)
+				}
+			}
+		})
+	}
+}
+
+func TestMerge_UnchangedSidesReproduceBytes(t *testing.T) {
+	src := []byte("\xEF\xBB\xBF<!-- prosemark-binder:v1 -->\r\n# Book\r\n\r\n1. [One](ch1.md)\r\n   notes on one\r\n\r\n## Act II\r\n\r\n2. [Two](ch2.md)\r\n\r\n[ref]: x.md")
+	got, diags := Merge(context.Background(), src, src, src, nil)
+	if string(got) != string(src) {
+		t.Errorf("Merge(x, x, x) =\n%q\nwant:\n%q", got, src)
+	}
+	if len(diags) != 0 {
+		t.Errorf("unexpected diagnostics: %v", diags)
+	}
+}
+
+func TestMerge_ParseErrorReturnsOurs(t *testing.T) {
+	ours := binderSrc("- [One](ch1.md)")
+	bad := binderSrc("- [Bad](../outside.md)")
+	got, diags := Merge(context.Background(), ours, ours, bad, nil)
+	if string(got) != string(ours) {
+		t.Errorf("Merge() = %q, want ours unchanged", got)
+	}
+	if !hasDiagCode(diags, CodeBinderHasParseErrors) {
+		t.Errorf("diagnostics = %v, want %s", diags, CodeBinderHasParseErrors)
+	}
+}
+
+func TestMerge_Shapes(t *testing.T) {
+	tests := []struct {
+		name               string
+		base, ours, theirs []byte
+		want               []byte
+		wantCodes          []string
+	}{
+		{
+			name:   "placeholders are matched by title",
+			base:   binderSrc("- [Idea]()", "- [A](a.md)"),
+			ours:   binderSrc("- [Idea]()", "- [A](a.md)", "- [B](b.md)"),
+			theirs: binderSrc("- [x] [Idea]()", "- [A](a.md)"),
+			want:   binderSrc("- [x] [Idea]()", "- [A](a.md)", "- [B](b.md)"),
+		},
+		{
+			name:   "repeated targets are matched in order",
+			base:   binderSrc("- [A](a.md)", "- [A](a.md)"),
+			ours:   binderSrc("- [A](a.md)", "- [A](a.md)"),
+			theirs: binderSrc("- [A](a.md)", "- [A](a.md)", "- [C](c.md)"),
+			want:   binderSrc("- [A](a.md)", "- [A](a.md)", "- [C](c.md)"),
+		},
+		{
+			name:   "a list added to an empty binder",
+			base:   binderSrc(),
+			ours:   binderSrc(),
+			theirs: binderSrc("- [A](a.md)"),
+			want:   binderSrc("- [A](a.md)"),
+		},
+		{
+			name:   "deletions in theirs",
+			base:   binderSrc("- [A](a.md)", "- [B](b.md)", "- [C](c.md)"),
+			ours:   binderSrc("- [A](a.md)", "- [B](b.md)", "- [C](c.md)"),
+			theirs: binderSrc("- [A](a.md)"),
+			want:   binderSrc("- [A](a.md)"),
+		},
+		{
+			name:   "deletion on both sides",
+			base:   binderSrc("- [A](a.md)", "- [B](b.md)"),
+			ours:   binderSrc("- [A](a.md)"),
+			theirs: binderSrc("- [A](a.md)"),
+			want:   binderSrc("- [A](a.md)"),
+		},
+		{
+			name:      "deletion in theirs against an edit in ours keeps the edit",
+			base:      binderSrc("- [A](a.md)", "- [B](b.md)"),
+			ours:      binderSrc("- [A](a.md)", "- [x] [B](b.md)"),
+			theirs:    binderSrc("- [A](a.md)"),
+			want:      binderSrc("- [A](a.md)", "- [x] [B](b.md)"),
+			wantCodes: []string{CodeMergeConflict},
+		},
+		{
+			name:   "reorder in theirs",
+			base:   binderSrc("- [A](a.md)", "- [B](b.md)", "- [C](c.md)"),
+			ours:   binderSrc("- [A](a.md)", "- [B](b.md)", "- [C](c.md)"),
+			theirs: binderSrc("- [C](c.md)", "- [A](a.md)", "- [B](b.md)"),
+			want:   binderSrc("- [C](c.md)", "- [A](a.md)", "- [B](b.md)"),
+		},
+		{
+			name:      "divergent reorders keep ours",
+			base:      binderSrc("- [A](a.md)", "- [B](b.md)", "- [C](c.md)"),
+			ours:      binderSrc("- [B](b.md)", "- [A](a.md)", "- [C](c.md)"),
+			theirs:    binderSrc("- [C](c.md)", "- [A](a.md)", "- [B](b.md)"),
+			want:      binderSrc("- [B](b.md)", "- [A](a.md)", "- [C](c.md)"),
+			wantCodes: []string{CodeMergeConflict},
+		},
+		{
+			name:   "text before the list edited in theirs",
+			base:   []byte("<!-- prosemark-binder:v1 -->\n# Title\n- [A](a.md)\n"),
+			ours:   []byte("<!-- prosemark-binder:v1 -->\n# Title\n- [A](a.md)\n"),
+			theirs: []byte("<!-- prosemark-binder:v1 -->\n# Theirs\n- [A](a.md)\n"),
+			want:   []byte("<!-- prosemark-binder:v1 -->\n# Theirs\n- [A](a.md)\n"),
+		},
+		{
+			name:      "text before the list edited differently",
+			base:      []byte("<!-- prosemark-binder:v1 -->\n# Title\n- [A](a.md)\n"),
+			ours:      []byte("<!-- prosemark-binder:v1 -->\n# Ours\n- [A](a.md)\n"),
+			theirs:    []byte("<!-- prosemark-binder:v1 -->\n# Theirs\n- [A](a.md)\n"),
+			want:      []byte("<<<<<<< ours\n<!-- prosemark-binder:v1 -->\n# Ours\n=======\n<!-- prosemark-binder:v1 -->\n# Theirs\n>>>>>>> theirs\n- [A](a.md)\n"),
+			wantCodes: []string{CodeMergeConflict},
+		},
+		{
+			name:   "continuation lines move with their entry",
+			base:   binderSrc("- [A](a.md)", "- [B](b.md)", "  notes on b"),
+			ours:   binderSrc("- [A](a.md)", "- [B](b.md)", "  notes on b", "- [N](n.md)"),
+			theirs: binderSrc("- [A](a.md)", "  - [B](b.md)", "    notes on b"),
+			want:   binderSrc("- [A](a.md)", "  - [B](b.md)", "    notes on b", "- [N](n.md)"),
+		},
+		{
+			name:   "entry placed under a tab-indented parent is indented with tabs",
+			base:   binderSrc("- [A](a.md)", "\t- [B](b.md)", "\t- [C](c.md)"),
+			ours:   binderSrc("- [A](a.md)", "\t- [B](b.md)", "\t\t- [C](c.md)"),
+			theirs: binderSrc("- [A](a.md)", "\t- [B](b.md)", "\t- [Cee](c.md)"),
+			want:   binderSrc("- [A](a.md)", "\t- [B](b.md)", "\t\t- [Cee](c.md)"),
+		},
+		{
+			name:   "entry placed after an ordered sibling continues the numbering",
+			base:   binderSrc("1. [A](a.md)", "   1. [B](b.md)"),
+			ours:   binderSrc("1. [A](a.md)", "2. [B](b.md)"),
+			theirs: binderSrc("1. [A](a.md)", "   1. [Bee](b.md)"),
+			want:   binderSrc("1. [A](a.md)", "2. [Bee](b.md)"),
+		},
+		{
+			name:      "cycle through an entry only theirs has goes to the top level",
+			base:      binderSrc("- [R](r.md)", "- [P](p.md)", "- [Q](q.md)"),
+			ours:      binderSrc("- [R](r.md)", "- [P](p.md)", "  - [Q](q.md)"),
+			theirs:    binderSrc("- [Q](q.md)", "  - [N](n.md)", "    - [P](p.md)", "    - [R](r.md)"),
+			want:      binderSrc("- [N](n.md)", "  - [P](p.md)", "    - [Q](q.md)", "  - [R](r.md)"),
+			wantCodes: []string{CodeMergeConflict},
+		},
+		{
+			name:      "invalid UTF-8 aborts the merge",
+			base:      binderSrc("- [A](a.md)"),
+			ours:      []byte("\xff"),
+			theirs:    binderSrc("- [A](a.md)"),
+			want:      []byte("\xff"),
+			wantCodes: []string{binder.CodeIOOrParseFailure},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diags := Merge(context.Background(), tt.base, tt.ours, tt.theirs, nil)
+			if string(got) != string(tt.want) {
+				t.Errorf("Merge() =\n%s\nwant:\n%s", got, tt.want)
+			}
+			if len(diags) != len(tt.wantCodes) {
+				t.Fatalf("diagnostics = %v, want codes %v", diags, tt.wantCodes)
+			}
+			for i, code := range tt.wantCodes {
+				if diags[i].Code != code {
+					t.Errorf("diagnostic %d = %s, want %s", i, diags[i].Code, code)
+				}
+			}
+		})
+	}
+}
@@ -18,6 +18,10 @@ var moveFirstLineMarkerRE = regexp.MustCompile(`^(?:[-*+]|\d+[.)]) `)
 // moveOpsCheckboxRE matches a GFM task-list checkbox at the start of content.
 var moveOpsCheckboxRE = regexp.MustCompile(`^\[[xX ]\]\s+`)
 
+// moveCheckboxPrefixRE matches a GFM task-list checkbox at the start of a
+// trimmed prefix, which may end immediately after the closing bracket.
+var moveCheckboxPrefixRE = regexp.MustCompile(`^\[[xX ]\](?:\s+|$)`)
+
 // moveParseBinderFn is the parse function used by Move. It may be replaced
 // in tests to simulate parse failures.
 var moveParseBinderFn = binder.Parse
@@ -27,29 +31,53 @@ var moveParseBinderFn = binder.Parse
 // (atomic abort semantics). Parse errors are surfaced as diagnostics, not as a
 // returned error.
 func Move(ctx context.Context, src []byte, project *binder.Project, params binder.MoveParams) ([]byte, []binder.Diagnostic) {
+	p := parseSrc(ctx, moveParseBinderFn, src, project)
+	edited, _, diags := move(p, project, params)
+	return p.output(edited), diags
+}
+
+// MoveEntries is Move that also locates the moved entries in the returned
+// bytes, in document order.
+func MoveEntries(ctx context.Context, src []byte, project *binder.Project, params binder.MoveParams) ([]byte, []binder.AffectedEntry, []binder.Diagnostic) {
+	p := parseSrc(ctx, moveParseBinderFn, src, project)
+	edited, moved, diags := move(p, project, params)
+	out := p.output(edited)
+	return out, locateEntries(ctx, out, project, moved), diags
+}
+
+// move implements Move by editing the parsed binder p in place, reporting
+// whether it did and the 0-based line indices of the moved entries in the
+// edited lines.
+func move(p parsed, project *binder.Project, params binder.MoveParams) (bool, []int, []binder.Diagnostic) {
 	// Require --yes confirmation (OPE009).
 	if !params.Yes {
-		return src, []binder.Diagnostic{{
+		return false, nil, []binder.Diagnostic{{
 			Severity: "error",
 			Code:     binder.CodeIOOrParseFailure,
 			Message:  "move requires --yes confirmation",
 		}}
 	}
 
-	// Parse the source.
-	result, parseDiags, err := moveParseBinderFn(ctx, src, project)
+	result, parseDiags, err := p.result, p.diags, p.err
 	if err != nil {
-		return src, append(parseDiags, binder.Diagnostic{
+		return false, nil, append(parseDiags, binder.Diagnostic{
 			Severity: "error",
 			Code:     binder.CodeIOOrParseFailure,
 			Message:  fmt.Sprintf("parse error: %v", err),
 		})
 	}
 
+	if diag := guardParseErrors(parseDiags, params.ForceParse); diag != nil {
+		return false, nil, append(parseDiags, *diag)
+	}
+	if diag := resolveBookmarks(project, &params.SourceSelector, &params.DestinationParentSelector, &params.Before, &params.After); diag != nil {
+		return false, nil, append(parseDiags, *diag)
+	}
+
 	// Find source nodes.
-	sourceNodes, selDiags := moveEvalSourceSelector(params.SourceSelector, result.Root, result.Lines)
+	sourceNodes, selDiags := moveEvalSourceSelector(params.SourceSelector, result.Root, result.Fenced)
 	if len(sourceNodes) == 0 {
-		return src, append(parseDiags, selDiags...)
+		return false, nil, append(parseDiags, selDiags...)
 	}
 
 	var allDiags []binder.Diagnostic
@@ -57,16 +85,16 @@ func Move(ctx context.Context, src []byte, project *binder.Project, params binde
 	allDiags = append(allDiags, selDiags...)
 
 	// Find destination parent.
-	destNode, destDiags := moveEvalDestSelector(params.DestinationParentSelector, result.Root)
+	destNode, destDiags := moveEvalDestSelector(params.DestinationParentSelector, result.Root, result.Fenced)
 	if destNode == nil {
-		return src, append(allDiags, destDiags...)
+		return false, nil, append(allDiags, destDiags...)
 	}
 	allDiags = append(allDiags, destDiags...)
 
 	// Cycle detection: destination must not be a descendant of any source node.
 	for _, srcNode := range sourceNodes {
 		if srcNode == destNode || moveIsDescendant(srcNode, destNode) {
-			return src, append(allDiags, binder.Diagnostic{
+			return false, nil, append(allDiags, binder.Diagnostic{
 				Severity: "error",
 				Code:     binder.CodeCycleDetected,
 				Message:  "destination is a descendant of source: cycle detected",
@@ -74,15 +102,16 @@ func Move(ctx context.Context, src []byte, project *binder.Project, params binde
 		}
 	}
 
-	// OPW003: warn if any source node has non-structural content.
-	for _, srcNode := range sourceNodes {
-		if moveNodeHasNonStructuralContent(srcNode.RawLine) {
-			allDiags = append(allDiags, binder.Diagnostic{
-				Severity: "warning",
-				Code:     binder.CodeNonStructuralDestroyed,
-				Message:  "non-structural content in source list item will be destroyed",
-			})
-			break
+	// OPW003: warn for each source node whose non-structural content will be
+	// destroyed, quoting the exact text. A GFM checkbox is task-state metadata
+	// and an annotation written by Annotate is generated, so both always
+	// travel with the node. With PreserveExtras the remaining content is
+	// carried along to the destination instead.
+	if !params.PreserveExtras {
+		for _, srcNode := range sourceNodes {
+			if diag := moveExtrasDiag(srcNode.RawLine); diag != nil {
+				allDiags = append(allDiags, *diag)
+			}
 		}
 	}
 
@@ -101,10 +130,11 @@ func Move(ctx context.Context, src []byte, project *binder.Project, params binde
 	// because that's what the user sees when specifying --before/--after/--at.
 	moveInsertIdx, diagErr := moveResolveInsertionIndex(destNode, sourceNodes, params)
 	if diagErr != nil {
-		return src, append(allDiags, *diagErr)
+		return false, nil, append(allDiags, *diagErr)
 	}
-	targetIndentStr, targetMarker := inferMarkerAndIndent(destNode, moveInsertIdx)
-	return moveRebuildDocument(result, sourceNodes, destNode, moveInsertIdx, targetIndentStr, targetMarker), allDiags
+	targetIndentStr, targetMarker := inferMarkerAndIndent(destNode, moveInsertIdx, project)
+	moved := moveRebuildDocument(result, sourceNodes, destNode, moveInsertIdx, targetIndentStr, targetMarker, params.PreserveExtras)
+	return true, moved, allDiags
 }
 
 // moveResolveInsertionIndex returns the 0-based index in destNode.Children at
@@ -181,23 +211,28 @@ func moveResolveInsertionIndex(destNode *binder.Node, sourceNodes []*binder.Node
 // moveRebuildDocument removes sourceNodes from their current positions,
 // re-indents them to match targetIndentStr (and replaces their list marker
 // with targetMarker), and inserts them under destNode at insertIdx (0-based
-// index into destNode.Children). Returns the serialized result.
-func moveRebuildDocument(result *binder.ParseResult, sourceNodes []*binder.Node, destNode *binder.Node, insertIdx int, targetIndentStr, targetMarker string) []byte {
+// index into destNode.Children). Non-structural content on each moved first
+// line is dropped unless preserveExtras is set. The lines of result are
+// rewritten in place; the 0-based line indices of the moved entries in them
+// are returned.
+func moveRebuildDocument(result *binder.ParseResult, sourceNodes []*binder.Node, destNode *binder.Node, insertIdx int, targetIndentStr, targetMarker string, preserveExtras bool) []int {
 	// Collect re-indented lines and mark source indices for removal.
 	var movedLines []string
 	var movedLineEnds []string
+	var movedFirsts []int // offsets of each moved entry's first line in movedLines
 	skipSet := make(map[int]bool)
 
 	for _, srcNode := range sourceNodes {
 		startIdx := srcNode.Line - 1
 		endIdx := deleteComputeSubtreeEnd(srcNode) - 1
 		srcIndentLen := srcNode.Indent
+		movedFirsts = append(movedFirsts, len(movedLines))
 
 		for i := startIdx; i <= endIdx; i++ {
 			var reindented string
 			if i == startIdx {
-				// Replace marker and strip any GFM checkbox on the first line.
-				reindented = moveReindentFirstLine(result.Lines[i], srcIndentLen, targetIndentStr, targetMarker)
+				// Replace marker and strip non-structural content on the first line.
+				reindented = moveReindentFirstLine(result.Lines[i], targetIndentStr, targetMarker, preserveExtras)
 			} else {
 				reindented = moveReindentLine(result.Lines[i], srcIndentLen, targetIndentStr)
 			}
@@ -224,12 +259,14 @@ func moveRebuildDocument(result *binder.ParseResult, sourceNodes []*binder.Node,
 	newLineEnds := make([]string, 0, len(result.LineEnds)+len(movedLineEnds))
 	inserted := false
 	pos := 0
+	base := 0 // index of the first moved line in newLines
 
 	for i, line := range result.Lines {
 		if skipSet[i] {
 			continue
 		}
 		if !inserted && pos == adjustedInsertIdx {
+			base = len(newLines)
 			newLines = append(newLines, movedLines...)
 			newLineEnds = append(newLineEnds, movedLineEnds...)
 			inserted = true
@@ -239,10 +276,16 @@ func moveRebuildDocument(result *binder.ParseResult, sourceNodes []*binder.Node,
 		pos++
 	}
 	if !inserted {
+		base = len(newLines)
 		newLines = append(newLines, movedLines...)
 		newLineEnds = append(newLineEnds, movedLineEnds...)
 	}
 
+	moved := make([]int, len(movedFirsts))
+	for i, off := range movedFirsts {
+		moved[i] = collapsedLineIdx(newLines, base+off)
+	}
+
 	result.Lines = newLines
 	result.LineEnds = newLineEnds
 
@@ -250,14 +293,15 @@ func moveRebuildDocument(result *binder.ParseResult, sourceNodes []*binder.Node,
 	result.Lines, result.LineEnds = deleteCollapseBlankLines(result.Lines, result.LineEnds)
 	result.Lines, result.LineEnds = deleteStripTrailingBlanks(result.Lines, result.LineEnds)
 
-	return binder.Serialize(result)
+	return moved
 }
 
 // moveEvalSourceSelector finds source nodes matching selector.
-// For selectors containing ":", path navigation via binder.EvalSelector is used.
+// For path selectors (see moveIsPathSelector), path navigation via
+// binder.EvalSelector is used.
 // Otherwise a deep-tree search is performed with OPE006 code-fence detection.
 // The root node is never a valid source: returns OPE001 with an explicit message.
-func moveEvalSourceSelector(selector string, root *binder.Node, lines []string) ([]*binder.Node, []binder.Diagnostic) {
+func moveEvalSourceSelector(selector string, root *binder.Node, fenced []*binder.Node) ([]*binder.Node, []binder.Diagnostic) {
 	const rootGuardMsg = "root node is not a valid target for this operation"
 	if selector == "." {
 		return nil, []binder.Diagnostic{{
@@ -266,7 +310,7 @@ func moveEvalSourceSelector(selector string, root *binder.Node, lines []string)
 			Message:  rootGuardMsg,
 		}}
 	}
-	if strings.Contains(selector, ":") {
+	if moveIsPathSelector(selector) {
 		selResult, errDiags := binder.EvalSelector(selector, root)
 		allDiags := append(selResult.Warnings, errDiags...)
 		for _, n := range selResult.Nodes {
@@ -284,12 +328,8 @@ func moveEvalSourceSelector(selector string, root *binder.Node, lines []string)
 	deleteSearchTree(root, selector, &matches)
 	if len(matches) == 0 {
 		// Check for code-fence presence (OPE006).
-		if isSelectorInCodeFence(lines, selector) {
-			return nil, []binder.Diagnostic{{
-				Severity: "error",
-				Code:     binder.CodeNodeInCodeFence,
-				Message:  fmt.Sprintf("selector %q matches a node inside a code fence", selector),
-			}}
+		if n := fencedMatch(fenced, selector); n != nil {
+			return nil, []binder.Diagnostic{codeFenceDiag(n, selector)}
 		}
 		return nil, []binder.Diagnostic{{
 			Severity: "error",
@@ -308,13 +348,22 @@ func moveEvalSourceSelector(selector string, root *binder.Node, lines []string)
 	return matches, diags
 }
 
+// moveIsPathSelector reports whether move navigates selector as a path.
+// Unlike binder.IsPathSelector, a single segment with an [N] index is left
+// to the deep search, so bracketed titles such as "Draft [2]" still match.
+func moveIsPathSelector(selector string) bool {
+	return binder.IsPathSelector(selector) &&
+		(strings.Contains(selector, ":") || !strings.HasSuffix(selector, "]"))
+}
+
 // moveEvalDestSelector finds the destination parent node for a move operation.
-// "." always returns the root node. Selectors containing ":" use path navigation.
-func moveEvalDestSelector(selector string, root *binder.Node) (*binder.Node, []binder.Diagnostic) {
+// "." always returns the root node. Path selectors use path navigation.
+// A bare selector matching only a fenced pseudo-node returns OPE006.
+func moveEvalDestSelector(selector string, root *binder.Node, fenced []*binder.Node) (*binder.Node, []binder.Diagnostic) {
 	if selector == "." {
 		return root, nil
 	}
-	if strings.Contains(selector, ":") {
+	if moveIsPathSelector(selector) {
 		selResult, errDiags := binder.EvalSelector(selector, root)
 		var node *binder.Node
 		if len(selResult.Nodes) > 0 {
@@ -325,6 +374,9 @@ func moveEvalDestSelector(selector string, root *binder.Node) (*binder.Node, []b
 	var matches []*binder.Node
 	deleteSearchTree(root, selector, &matches)
 	if len(matches) == 0 {
+		if n := fencedMatch(fenced, selector); n != nil {
+			return nil, []binder.Diagnostic{codeFenceDiag(n, selector)}
+		}
 		return nil, []binder.Diagnostic{{
 			Severity: "error",
 			Code:     binder.CodeSelectorNoMatch,
@@ -357,21 +409,30 @@ func moveAnyParentLosesAllChildren(root *binder.Node, sourceNodes []*binder.Node
 	return false
 }
 
-// moveNodeHasNonStructuralContent reports whether rawLine contains content
-// before the structural inline link (after the list marker).
-func moveNodeHasNonStructuralContent(rawLine string) bool {
-	loc := deleteInlineLinkRE.FindStringIndex(rawLine)
-	if loc == nil {
-		return false
+// moveExtrasDiag returns the OPW003 diagnostic quoting the non-structural
+// content of the list item line that moving it without PreserveExtras
+// destroys, or nil when there is none. The checkbox and the annotation are
+// not part of it.
+func moveExtrasDiag(line string) *binder.Diagnostic {
+	prefix, suffix := nonStructuralExtras(annotationRE.ReplaceAllString(line, ""))
+	prefix = strings.TrimSpace(moveCheckboxPrefixRE.ReplaceAllString(prefix, ""))
+	if prefix == "" && suffix == "" {
+		return nil
+	}
+	return &binder.Diagnostic{
+		Severity: "warning",
+		Code:     binder.CodeNonStructuralDestroyed,
+		Message:  "non-structural content in source list item will be destroyed: " + describeExtras(prefix, suffix),
 	}
-	prefix := moveListMarkerRE.ReplaceAllString(rawLine[:loc[0]], "")
-	return strings.TrimSpace(prefix) != ""
 }
 
 // moveReindentFirstLine adjusts the first line of a moved node: strips the
-// original leading whitespace, original list marker, and any GFM checkbox,
-// then prepends the target indent and target marker.
-func moveReindentFirstLine(line string, srcIndentLen int, targetIndentStr, targetMarker string) string {
+// original leading whitespace and list marker, then prepends the target indent
+// and target marker. Unless preserveExtras is set, non-structural content
+// around the structural inline link (strikethrough fragments, trailing
+// annotations) is dropped. A leading GFM checkbox is task-state metadata and
+// an annotation written by Annotate is generated, so both are always kept.
+func moveReindentFirstLine(line, targetIndentStr, targetMarker string, preserveExtras bool) string {
 	// Strip leading whitespace.
 	w := 0
 	for w < len(line) && (line[w] == ' ' || line[w] == '\t') {
@@ -384,9 +445,15 @@ func moveReindentFirstLine(line string, srcIndentLen int, targetIndentStr, targe
 		rest = rest[loc[1]:]
 	}
 
-	// Strip GFM task-list checkbox if present.
-	if m := moveOpsCheckboxRE.FindString(rest); m != "" {
-		rest = rest[len(m):]
+	if !preserveExtras {
+		checkbox := moveOpsCheckboxRE.FindString(rest)
+		note := strings.TrimSpace(annotationRE.FindString(rest))
+		if loc := structuralLinkSpan(rest[len(checkbox):]); loc != nil {
+			rest = checkbox + rest[len(checkbox):][loc[0]:loc[1]]
+		}
+		if note != "" {
+			rest += " " + note
+		}
 	}
 
 	return targetIndentStr + targetMarker + " " + rest
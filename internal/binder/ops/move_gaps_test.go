@@ -178,3 +178,25 @@ func TestMove_OPE006_SourceInCodeFence(t *testing.T) {
 		t.Errorf("expected OPE006 (node in code fence), got: %v", diags)
 	}
 }
+
+// TestMove_OPE006_DestinationInCodeFence verifies that when the destination
+// selector matches only a fenced pseudo-node, OPE006 is emitted and the binder
+// is left unchanged.
+func TestMove_OPE006_DestinationInCodeFence(t *testing.T) {
+	src := []byte("<!-- prosemark-binder:v1 -->\n\n- [Source](source.md)\n```\n- [Fenced](fenced.md)\n```\n")
+	params := binder.MoveParams{
+		SourceSelector:            "source",
+		DestinationParentSelector: "fenced",
+		Position:                  "last",
+		Yes:                       true,
+	}
+
+	out, diags := Move(context.Background(), src, nil, params)
+
+	if !hasDiagCode(diags, binder.CodeNodeInCodeFence) {
+		t.Errorf("expected OPE006 (node in code fence), got: %v", diags)
+	}
+	if !bytes.Equal(out, src) {
+		t.Errorf("expected src unchanged, got %q", out)
+	}
+}
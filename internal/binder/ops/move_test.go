@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/eykd/prosemark-go/internal/binder"
@@ -267,13 +268,14 @@ func TestMove_TooltipPreserved(t *testing.T) {
 // ──────────────────────────────────────────────────────────────────────────────
 
 // TestMove_NonStructuralContent_OPW003 verifies that when the source node's
-// list item contains non-structural inline content (e.g., a task checkbox),
-// OPW003 is emitted and the move proceeds, stripping the non-structural part.
+// list item contains non-structural inline content (e.g., a trailing
+// annotation), OPW003 is emitted and the move proceeds, stripping the
+// non-structural part while keeping the task checkbox.
 func TestMove_NonStructuralContent_OPW003(t *testing.T) {
-	// "[ ] " before the link is non-structural; it should be destroyed on move.
+	// "— draft" after the link is non-structural; it should be destroyed on move.
 	src := []byte("<!-- prosemark-binder:v1 -->\n\n" +
 		"- [Part One](part1.md)\n" +
-		"  - [ ] [Chapter One](ch1.md)\n" +
+		"  - [ ] [Chapter One](ch1.md) — draft\n" +
 		"- [Part Two](part2.md)\n")
 	params := binder.MoveParams{
 		SourceSelector:            "ch1.md",
@@ -287,9 +289,9 @@ func TestMove_NonStructuralContent_OPW003(t *testing.T) {
 	if !hasDiagCode(diags, binder.CodeNonStructuralDestroyed) {
 		t.Errorf("expected OPW003 (non-structural content destroyed), got: %v", diags)
 	}
-	// ch1.md should still appear in output.
-	if !bytes.Contains(out, []byte("ch1.md")) {
-		t.Errorf("moved node ch1.md should appear in output:\n%s", out)
+	// ch1.md should still appear in output, keeping its checkbox.
+	if !bytes.Contains(out, []byte("  - [ ] [Chapter One](ch1.md)\n")) {
+		t.Errorf("moved node ch1.md should appear with its checkbox in output:\n%s", out)
 	}
 	// OPW004 because part1.md lost its only child.
 	if !hasDiagCode(diags, binder.CodeEmptySublistPruned) {
@@ -725,3 +727,121 @@ func TestMove_ParseError_OPE009(t *testing.T) {
 		t.Error("expected src unchanged on parse error")
 	}
 }
+
+// ──────────────────────────────────────────────────────────────────────────────
+// Preserving non-structural content: PreserveExtras (fixture 136)
+// ──────────────────────────────────────────────────────────────────────────────
+
+// TestMove_NonStructuralContent_Extras verifies that non-structural content on
+// the moved line is destroyed (with the exact text quoted in OPW003) by default
+// and carried along unchanged when PreserveExtras is set.
+func TestMove_NonStructuralContent_Extras(t *testing.T) {
+	src := []byte("<!-- prosemark-binder:v1 -->\n\n" +
+		"- [Part One](part1.md)\n" +
+		"  - [x] ~~old~~ [Chapter One](ch1.md) ← needs revision\n" +
+		"  - [Chapter Two](ch2.md)\n" +
+		"- [Part Two](part2.md)\n")
+
+	tests := []struct {
+		name       string
+		preserve   bool
+		wantLine   string
+		wantOPW003 bool
+	}{
+		{
+			name:       "default destroys extras but keeps the checkbox",
+			wantLine:   "  - [x] [Chapter One](ch1.md)\n",
+			wantOPW003: true,
+		},
+		{
+			name:     "preserve carries extras along",
+			preserve: true,
+			wantLine: "  - [x] ~~old~~ [Chapter One](ch1.md) ← needs revision\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := binder.MoveParams{
+				SourceSelector:            "ch1.md",
+				DestinationParentSelector: "part2.md",
+				Position:                  "last",
+				Yes:                       true,
+				PreserveExtras:            tt.preserve,
+			}
+			out, diags := Move(context.Background(), src, nil, params)
+			if !bytes.HasSuffix(out, []byte("- [Part Two](part2.md)\n"+tt.wantLine)) {
+				t.Errorf("moved line mismatch, want suffix %q:\n%s", tt.wantLine, out)
+			}
+			var opw003 *binder.Diagnostic
+			for i := range diags {
+				if diags[i].Code == binder.CodeNonStructuralDestroyed {
+					opw003 = &diags[i]
+				}
+			}
+			if (opw003 != nil) != tt.wantOPW003 {
+				t.Fatalf("OPW003 present = %v, want %v; diags: %v", opw003 != nil, tt.wantOPW003, diags)
+			}
+			if opw003 != nil && !strings.Contains(opw003.Message, `"~~old~~", "← needs revision"`) {
+				t.Errorf("OPW003 message should quote destroyed text, got %q", opw003.Message)
+			}
+		})
+	}
+}
+
+// TestMove_KeepsEntryAnnotation verifies that an annotation written by
+// Annotate travels with the moved entry without an OPW003, while other
+// extras on the line are still destroyed.
+func TestMove_KeepsEntryAnnotation(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		wantLine   string
+		wantOPW003 bool
+	}{
+		{
+			name:     "annotation only",
+			line:     "  - [Chapter One](ch1.md) <!-- 2,314 words, revised -->\n",
+			wantLine: "  - [Chapter One](ch1.md) <!-- 2,314 words, revised -->\n",
+		},
+		{
+			name:       "annotation after other extras",
+			line:       "  - [ ] [Chapter One](ch1.md) ← needs revision <!-- 1 word -->\n",
+			wantLine:   "  - [ ] [Chapter One](ch1.md) <!-- 1 word -->\n",
+			wantOPW003: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := []byte("<!-- prosemark-binder:v1 -->\n\n" +
+				"- [Part One](part1.md)\n" +
+				tt.line +
+				"  - [Chapter Two](ch2.md)\n" +
+				"- [Part Two](part2.md)\n")
+			params := binder.MoveParams{
+				SourceSelector:            "ch1.md",
+				DestinationParentSelector: "part2.md",
+				Position:                  "last",
+				Yes:                       true,
+			}
+			out, diags := Move(context.Background(), src, nil, params)
+			if !bytes.HasSuffix(out, []byte("- [Part Two](part2.md)\n"+tt.wantLine)) {
+				t.Errorf("moved line mismatch, want suffix %q:\n%s", tt.wantLine, out)
+			}
+			var opw003 []string
+			for _, d := range diags {
+				if d.Code == binder.CodeNonStructuralDestroyed {
+					opw003 = append(opw003, d.Message)
+				}
+			}
+			if (len(opw003) > 0) != tt.wantOPW003 {
+				t.Fatalf("OPW003 = %v, want present %v", opw003, tt.wantOPW003)
+			}
+			for _, m := range opw003 {
+				if strings.Contains(m, "words") || strings.Contains(m, "word -->") {
+					t.Errorf("OPW003 should not quote the annotation: %q", m)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// CodeCannotRename is an implementation-specific error emitted when rename
+// selects an entry with no file, or would overwrite an existing project file.
+const CodeCannotRename = "PMKE006"
+
+var (
+	// renameLinkRE matches a wikilink (group 1 is its link part, before any
+	// "|alias"; group 2 is the "|alias") or an inline link (group 3 is its
+	// parenthesized destination).
+	renameLinkRE = regexp.MustCompile(`!?\[\[([^\]|]+)(\|[^\]]*)?\]\]|\[(?:[^\]\\]|\\.)*\]\(([^)]*)\)`)
+
+	// refDefTargetRE matches a reference definition; group 1 spans its target.
+	refDefTargetRE = regexp.MustCompile(`^\[[^\]]+\]:\s+(\S+)`)
+)
+
+// Rename points every binder reference to the file of the entry selected by
+// params.Selector at params.NewTarget instead: inline links and wikilinks on
+// the entries' lines, and reference definitions. Titles that were derived
+// from the old filename are kept by writing them out. The selector must
+// match entries of exactly one file, and NewTarget must not name another
+// project file. Returns the modified bytes, the old target, the rewritten
+// entries, and diagnostics. Source bytes are unchanged on error, and when
+// NewTarget is the old target.
+func Rename(ctx context.Context, src []byte, project *binder.Project, params binder.RenameParams) ([]byte, string, []binder.AffectedEntry, []binder.Diagnostic) {
+	result, parseDiags, err := binder.Parse(ctx, src, project)
+	if err != nil {
+		return src, "", nil, append(parseDiags, binder.Diagnostic{
+			Severity: "error",
+			Code:     binder.CodeIOOrParseFailure,
+			Message:  fmt.Sprintf("parse error: %v", err),
+		})
+	}
+	if diag := guardParseErrors(parseDiags, params.ForceParse); diag != nil {
+		return src, "", nil, append(parseDiags, *diag)
+	}
+	if diag := resolveBookmarks(project, &params.Selector); diag != nil {
+		return src, "", nil, append(parseDiags, *diag)
+	}
+
+	newTarget := NormalizeTarget(params.NewTarget)
+	if diag := validateOpTarget(newTarget, project); diag != nil {
+		return src, "", nil, append(parseDiags, *diag)
+	}
+
+	nodes, selDiags := moveEvalSourceSelector(params.Selector, result.Root, result.Fenced)
+	if len(nodes) == 0 {
+		return src, "", nil, append(parseDiags, selDiags...)
+	}
+	oldTarget := strings.TrimPrefix(nodes[0].Target, "./")
+	for _, n := range nodes {
+		if t := strings.TrimPrefix(n.Target, "./"); t != oldTarget {
+			return src, "", nil, append(parseDiags, binder.Diagnostic{
+				Severity: "error",
+				Code:     binder.CodeAmbiguousBareStem,
+				Message:  fmt.Sprintf("selector %q matched entries of both %s and %s; rename needs one file", params.Selector, oldTarget, t),
+			})
+		}
+	}
+	if oldTarget == "" {
+		return src, "", nil, append(parseDiags, binder.Diagnostic{
+			Severity: "error",
+			Code:     CodeCannotRename,
+			Message:  fmt.Sprintf("%q is a placeholder: it has no file to rename", nodes[0].Title),
+			Location: &binder.Location{Line: nodes[0].Line},
+		})
+	}
+	if newTarget == oldTarget {
+		return src, oldTarget, nil, append(parseDiags, selDiags...)
+	}
+	if project != nil && slices.Contains(project.Files, newTarget) {
+		return src, "", nil, append(parseDiags, binder.Diagnostic{
+			Severity: "error",
+			Code:     CodeCannotRename,
+			Message:  fmt.Sprintf("%s already exists", newTarget),
+		})
+	}
+
+	renamed := renamedProject(project, oldTarget, newTarget)
+	var lines []int
+	for _, n := range collectAllNodes(result.Root) {
+		if strings.TrimPrefix(n.Target, "./") != oldTarget {
+			continue
+		}
+		// The link may sit on the item's continuation line.
+		for _, idx := range []int{n.Line - 1, n.Line} {
+			if idx >= len(result.Lines) {
+				break
+			}
+			if line, ok := renameLink(result.Lines[idx], n.Title, oldTarget, newTarget, renamed); ok {
+				result.Lines[idx] = line
+				break
+			}
+		}
+		lines = append(lines, n.Line-1)
+	}
+	for _, d := range result.RefDefs {
+		if strings.TrimPrefix(percentDecodeOpTarget(d.Target), "./") == oldTarget {
+			line := result.Lines[d.Line-1]
+			m := refDefTargetRE.FindStringSubmatchIndex(line)
+			result.Lines[d.Line-1] = line[:m[2]] + strings.ReplaceAll(newTarget, " ", "%20") + line[m[3]:]
+		}
+	}
+
+	out := binder.Serialize(result)
+	return out, oldTarget, locateEntries(ctx, out, renamed, lines), append(parseDiags, selDiags...)
+}
+
+// renameLink rewrites the first inline link or wikilink in line that refers
+// to oldTarget, reporting whether there was one. title is the entry's title,
+// kept when the old link derived it from the filename.
+func renameLink(line, title, oldTarget, newTarget string, project *binder.Project) (string, bool) {
+	for _, m := range renameLinkRE.FindAllStringSubmatchIndex(line, -1) {
+		if m[2] >= 0 {
+			if link, ok := renameWikilink(line, m, title, oldTarget, newTarget, project); ok {
+				return line[:m[2]] + link + line[m[3]:], true
+			}
+			continue
+		}
+		dest := line[m[6]:m[7]]
+		target, rest := dest, ""
+		if i := strings.IndexAny(dest, " \t"); i >= 0 {
+			target, rest = dest[:i], dest[i:]
+		}
+		if strings.TrimPrefix(percentDecodeOpTarget(target), "./") != oldTarget {
+			continue
+		}
+		prefix := line[:m[6]]
+		if strings.HasSuffix(prefix, "[](") { // the title came from the filename
+			prefix = prefix[:len(prefix)-2] + escapeTitle(title) + "]("
+		}
+		return prefix + strings.ReplaceAll(newTarget, " ", "%20") + rest + line[m[7]:], true
+	}
+	return line, false
+}
+
+// renameWikilink returns the replacement for the link part, and any alias
+// it needs, of the wikilink matched by m in line, or false when the link
+// does not name oldTarget (it may resolve through a frontmatter alias, which
+// moves with the file).
+func renameWikilink(line string, m []int, title, oldTarget, newTarget string, project *binder.Project) (string, bool) {
+	base, fragment, _ := strings.Cut(line[m[2]:m[3]], "#")
+	ext := ""
+	if strings.HasSuffix(base, ".md") {
+		base, ext = strings.TrimSuffix(base, ".md"), ".md"
+	}
+	if !strings.EqualFold(opStemFromPath(base+".md"), opStemFromPath(oldTarget)) {
+		return "", false
+	}
+	link := renamedWikilinkPath(newTarget, project) + ext
+	if fragment != "" {
+		link += "#" + fragment
+	}
+	titled := strings.TrimSpace(binder.StripTrailingComments(line[m[1]:])) != ""
+	if m[4] < 0 && !titled && title != opStemFromPath(newTarget) {
+		link += "|" + title
+	}
+	return link, true
+}
+
+// renamedWikilinkPath returns how a wikilink names target: by its bare stem
+// when no other project file shares it, and path-qualified otherwise.
+func renamedWikilinkPath(target string, project *binder.Project) string {
+	stem := opStemFromPath(target)
+	if project != nil {
+		for _, f := range project.Files {
+			if f != target && strings.EqualFold(opStemFromPath(f), stem) {
+				return strings.TrimSuffix(target, ".md")
+			}
+		}
+	}
+	return stem
+}
+
+// renamedProject returns a copy of project listing newTarget in place of
+// oldTarget, or nil when project is nil.
+func renamedProject(project *binder.Project, oldTarget, newTarget string) *binder.Project {
+	if project == nil {
+		return nil
+	}
+	renamed := *project
+	renamed.Files = make([]string, 0, len(project.Files))
+	for _, f := range project.Files {
+		if f != oldTarget {
+			renamed.Files = append(renamed.Files, f)
+		}
+	}
+	renamed.Files = append(renamed.Files, newTarget)
+	slices.Sort(renamed.Files)
+	return &renamed
+}
@@ -0,0 +1,135 @@
+package ops
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestRename(t *testing.T) {
+	project := &binder.Project{
+		Files:     []string{"ch1.md", "ch2.md", "notes/ch3.md", "other/new.md"},
+		BinderDir: ".",
+		Aliases:   map[string][]string{"ch1.md": {"Opening"}},
+	}
+	tests := []struct {
+		name      string
+		src       []byte
+		project   *binder.Project
+		selector  string
+		newTarget string
+		want      []byte
+		wantLines []int
+	}{
+		{
+			name:      "inline links and continuation line",
+			src:       binderSrc("- [One](ch1.md \"tip\") <!-- 5 words -->", "  - [Two](ch2.md)", "- Again", "  [](./ch1.md)", "- [cover](cover.png) [Three](ch1.md)"),
+			selector:  "ch1",
+			newTarget: "./part one.md",
+			want:      binderSrc("- [One](part%20one.md \"tip\") <!-- 5 words -->", "  - [Two](ch2.md)", "- Again", "  [ch1](part%20one.md)", "- [cover](cover.png) [Three](part%20one.md)"),
+			wantLines: []int{3, 5, 7},
+		},
+		{
+			name:      "reference definition",
+			src:       binderSrc("[c1]: ch1.md \"tip\"", "", "- [Two](ch2.md)", "- [One][c1]"),
+			selector:  "One",
+			newTarget: "one.md",
+			want:      binderSrc("[c1]: one.md \"tip\"", "", "- [Two](ch2.md)", "- [One][c1]"),
+			wantLines: []int{6},
+		},
+		{
+			name:      "wikilinks",
+			project:   project,
+			src:       binderSrc("- [[ch1]]", "- [[ch1|One]]", "- [[ch1.md#Start]] The Start", "- [[ch3]]", "- [[Opening]]"),
+			selector:  "ch1",
+			newTarget: "new.md",
+			want:      binderSrc("- [[new|ch1]]", "- [[new|One]]", "- [[new.md#Start]] The Start", "- [[ch3]]", "- [[Opening]]"),
+			wantLines: []int{3, 4, 5, 7},
+		},
+		{
+			name:      "wikilink kept when the stem is unchanged",
+			project:   project,
+			src:       binderSrc("- [[ch3]]"),
+			selector:  "ch3",
+			newTarget: "ch3.md",
+			want:      binderSrc("- [[ch3]]"),
+			wantLines: []int{3},
+		},
+		{
+			name:      "wikilink to a stem shared with another file",
+			project:   project,
+			src:       binderSrc("- [[ch3]]"),
+			selector:  "ch3",
+			newTarget: "drafts/new.md",
+			want:      binderSrc("- [[drafts/new|ch3]]"),
+			wantLines: []int{3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := binder.RenameParams{Selector: tt.selector, NewTarget: tt.newTarget}
+			out, oldTarget, entries, diags := Rename(context.Background(), tt.src, tt.project, params)
+			for _, d := range diags {
+				if d.Severity == "error" {
+					t.Fatalf("diags = %v", diags)
+				}
+			}
+			if string(out) != string(tt.want) {
+				t.Errorf("Rename() =\n%s\nwant\n%s", out, tt.want)
+			}
+			if oldTarget == "" {
+				t.Error("old target not reported")
+			}
+			var lines []int
+			for _, e := range entries {
+				lines = append(lines, e.Line)
+			}
+			if !slices.Equal(lines, tt.wantLines) {
+				t.Errorf("entry lines = %v, want %v", lines, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestRename_Errors(t *testing.T) {
+	src := binderSrc("- [One](ch1.md)", "- [Two](ch2.md)", "- [Draft]()", "- [Same](ch1.md)", "- [Same](ch2.md)")
+	project := &binder.Project{Files: []string{"ch1.md", "ch2.md"}, BinderDir: "."}
+	tests := []struct {
+		name      string
+		src       []byte
+		selector  string
+		newTarget string
+		wantCode  string
+	}{
+		{"invalid UTF-8", []byte("\xff\xfe"), "One", "new.md", binder.CodeIOOrParseFailure},
+		{"parse errors", brokenBinder, "One", "new.md", CodeBinderHasParseErrors},
+		{"invalid target", src, "One", "../new.md", binder.CodeInvalidTargetPath},
+		{"no match", src, "Missing", "new.md", binder.CodeSelectorNoMatch},
+		{"several files", src, "Same", "new.md", binder.CodeAmbiguousBareStem},
+		{"placeholder", src, "Draft", "new.md", CodeCannotRename},
+		{"existing file", src, "One", "ch2.md", CodeCannotRename},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := binder.RenameParams{Selector: tt.selector, NewTarget: tt.newTarget}
+			out, _, entries, diags := Rename(context.Background(), tt.src, project, params)
+			if !hasDiagCode(diags, tt.wantCode) {
+				t.Errorf("diags = %v, want %s", diags, tt.wantCode)
+			}
+			if string(out) != string(tt.src) || entries != nil {
+				t.Errorf("out = %q, entries = %v; want the source unchanged", out, entries)
+			}
+		})
+	}
+}
+
+func TestRename_SameTarget(t *testing.T) {
+	src := binderSrc("- [One](ch1.md)")
+	out, oldTarget, entries, diags := Rename(context.Background(), src, nil, binder.RenameParams{Selector: "One", NewTarget: "./ch1.md"})
+	if string(out) != string(src) || oldTarget != "ch1.md" || entries != nil || len(diags) != 0 {
+		t.Errorf("Rename() = %q, %q, %v, %v; want the source unchanged", out, oldTarget, entries, diags)
+	}
+}
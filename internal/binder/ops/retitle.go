@@ -0,0 +1,98 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// CodeInvalidTitle is an implementation-specific error emitted when retitle
+// is given a title the entry's link cannot hold.
+const CodeInvalidTitle = "PMKE010"
+
+// Retitle sets the title of the entries params.Selector matches to
+// params.Title, keeping each link's style, target, checkbox, and trailing
+// annotations. Brackets are escaped in Markdown link text, as add escapes
+// them; a wikilink alias cannot hold "|" or "]", and no title can be empty
+// or span lines (PMKE010). Placeholders without a link are left alone.
+// Returns the modified bytes, the retitled entries, and diagnostics. Source
+// bytes are unchanged on error.
+func Retitle(ctx context.Context, src []byte, project *binder.Project, params binder.RetitleParams) ([]byte, []binder.AffectedEntry, []binder.Diagnostic) {
+	result, parseDiags, err := binder.Parse(ctx, src, project)
+	if err != nil {
+		return src, nil, append(parseDiags, binder.Diagnostic{
+			Severity: "error",
+			Code:     binder.CodeIOOrParseFailure,
+			Message:  fmt.Sprintf("parse error: %v", err),
+		})
+	}
+	if diag := guardParseErrors(parseDiags, params.ForceParse); diag != nil {
+		return src, nil, append(parseDiags, *diag)
+	}
+	if diag := resolveBookmarks(project, &params.Selector); diag != nil {
+		return src, nil, append(parseDiags, *diag)
+	}
+	if strings.TrimSpace(params.Title) == "" || strings.ContainsAny(params.Title, "\r\n") {
+		return src, nil, append(parseDiags, binder.Diagnostic{
+			Severity: "error",
+			Code:     CodeInvalidTitle,
+			Message:  fmt.Sprintf("title %q must be one non-blank line", params.Title),
+		})
+	}
+
+	nodes, selDiags := moveEvalSourceSelector(params.Selector, result.Root, result.Fenced)
+	diags := append(parseDiags, selDiags...)
+	var changed []int
+	for _, n := range nodes {
+		updated, err := setEntryTitle(result.Lines, n, params.Title)
+		if err != nil {
+			return src, nil, append(diags, binder.Diagnostic{
+				Severity: "error",
+				Code:     CodeInvalidTitle,
+				Message:  err.Error(),
+				Location: &binder.Location{Line: n.Line},
+			})
+		}
+		if updated {
+			changed = append(changed, n.Line-1)
+		}
+	}
+	if len(changed) == 0 {
+		return src, nil, diags
+	}
+	out := binder.Serialize(result)
+	return out, locateEntries(ctx, out, project, changed), diags
+}
+
+// setEntryTitle sets the title of n's structural link, on the first line of
+// n that has one, to title, keeping the link's style, target, checkbox, and
+// trailing annotations, and reports whether lines changed. It is an error
+// for title to be a wikilink alias containing "|" or "]"; lines are then
+// left alone.
+func setEntryTitle(lines []string, n *binder.Node, title string) (bool, error) {
+	for idx := n.Line - 1; idx < max(n.Line, n.EndLine); idx++ {
+		line := lines[idx]
+		lo, hi, style, ok := linkTitleSpan(line)
+		if !ok {
+			continue
+		}
+		text := title
+		switch {
+		case style != LinkStyleWikilink:
+			text = escapeTitle(title)
+		case lo > 0 && line[lo-1] == '|':
+			if strings.ContainsAny(title, "|]") {
+				return false, fmt.Errorf("title %q cannot be a wikilink alias: it contains | or ]", title)
+			}
+		case lo == hi:
+			// A bare wikilink takes its title from the text after it.
+			text = " " + title
+		}
+		updated := line[:lo] + text + line[hi:]
+		lines[idx] = updated
+		return updated != line, nil
+	}
+	return false, nil
+}
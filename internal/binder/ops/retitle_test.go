@@ -0,0 +1,78 @@
+package ops
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestRetitle(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		title string
+		want  string
+	}{
+		{"inline", "- [One](ch1.md)", "First", "- [First](ch1.md)"},
+		{"inline escapes brackets", "- [One](ch1.md)", "The [Draft]", `- [The \[Draft\]](ch1.md)`},
+		{"empty inline text", "- [](ch1.md)", "First", "- [First](ch1.md)"},
+		{"checkbox and annotation kept", "- [x] [One](ch1.md) <!-- done -->", "First", "- [x] [First](ch1.md) <!-- done -->"},
+		{"reference", "- [One][c1]\n\n[c1]: ch1.md", "First", "- [First][c1]\n\n[c1]: ch1.md"},
+		{"wikilink alias", "- [[ch1|One]]", "First", "- [[ch1|First]]"},
+		{"bare wikilink with text", "- [[ch1]] One", "First", "- [[ch1]] First"},
+		{"bare wikilink", "- [[ch1]]", "First", "- [[ch1]] First"},
+		{"link on a continuation line", "- Chapter\n  [One](ch1.md)", "First", "- Chapter\n  [First](ch1.md)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := binderSrc(tt.line)
+			proj := &binder.Project{Files: []string{"ch1.md"}, BinderDir: "."}
+			got, entries, diags := Retitle(context.Background(), src, proj, binder.RetitleParams{Selector: "ch1", Title: tt.title})
+			if want := binderSrc(tt.want); !bytes.Equal(got, want) {
+				t.Errorf("binder = %q, want %q (diags %+v)", got, want, diags)
+			}
+			if len(entries) != 1 || entries[0].Title != tt.title {
+				t.Errorf("entries = %+v, want the retitled entry", entries)
+			}
+		})
+	}
+}
+
+func TestRetitle_InvalidTitle(t *testing.T) {
+	for _, tt := range []struct{ line, title string }{
+		{"- [One](ch1.md)", " "},
+		{"- [One](ch1.md)", "Two\nlines"},
+		{"- [[ch1|One]]", "A|B"},
+	} {
+		src := binderSrc(tt.line)
+		got, _, diags := Retitle(context.Background(), src, nil, binder.RetitleParams{Selector: "ch1", Title: tt.title})
+		if !bytes.Equal(got, src) || len(diags) == 0 || diags[len(diags)-1].Code != CodeInvalidTitle {
+			t.Errorf("%q to %q: binder = %q, diags = %+v; want unchanged with PMKE010", tt.line, tt.title, got, diags)
+		}
+	}
+}
+
+func TestRetitle_NoMatch(t *testing.T) {
+	src := binderSrc("- [One](ch1.md)")
+	got, _, diags := Retitle(context.Background(), src, nil, binder.RetitleParams{Selector: "ch9", Title: "Nine"})
+	if !bytes.Equal(got, src) || len(diags) == 0 || diags[len(diags)-1].Code != binder.CodeSelectorNoMatch {
+		t.Errorf("binder = %q, diags = %+v; want unchanged with OPE001", got, diags)
+	}
+}
+
+func TestRetitle_Unparsable(t *testing.T) {
+	src := []byte("- [One](ch1.md)\n\xff\n")
+	got, _, diags := Retitle(context.Background(), src, nil, binder.RetitleParams{Selector: "ch1", Title: "First"})
+	if !bytes.Equal(got, src) || len(diags) == 0 || diags[len(diags)-1].Code != binder.CodeIOOrParseFailure {
+		t.Errorf("binder = %q, diags = %+v; want unchanged with OPE009", got, diags)
+	}
+}
+
+func TestSetEntryTitle_NoLink(t *testing.T) {
+	lines := []string{"- Chapter one"}
+	if updated, err := setEntryTitle(lines, &binder.Node{Line: 1}, "First"); updated || err != nil || lines[0] != "- Chapter one" {
+		t.Errorf("setEntryTitle() = %v, %v, lines %q; want no change", updated, err, lines)
+	}
+}
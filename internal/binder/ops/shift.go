@@ -0,0 +1,106 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// CodeCannotShift is an implementation-specific error emitted when promote
+// selects a top-level entry or demote selects an entry with no preceding
+// sibling.
+const CodeCannotShift = "PMKE007"
+
+// Promote moves the entry selected by params.Selector, with its subtree, up
+// one level: it becomes the sibling immediately after its former parent.
+// Demote moves it down one level: it becomes the last child of its preceding
+// sibling. Both are moves, with Move's atomic-abort and diagnostic
+// semantics, except that the entry's line is carried along unchanged. The
+// selector must match exactly one entry.
+func Promote(ctx context.Context, src []byte, project *binder.Project, params binder.ShiftParams) ([]byte, []binder.AffectedEntry, []binder.Diagnostic) {
+	return shift(ctx, src, project, params, true)
+}
+
+// Demote moves the selected entry down one level; see Promote.
+func Demote(ctx context.Context, src []byte, project *binder.Project, params binder.ShiftParams) ([]byte, []binder.AffectedEntry, []binder.Diagnostic) {
+	return shift(ctx, src, project, params, false)
+}
+
+// shift implements Promote (promote == true) and Demote.
+func shift(ctx context.Context, src []byte, project *binder.Project, params binder.ShiftParams, promote bool) ([]byte, []binder.AffectedEntry, []binder.Diagnostic) {
+	opName := "demote"
+	if promote {
+		opName = "promote"
+	}
+
+	result, parseDiags, err := moveParseBinderFn(ctx, src, project)
+	if err != nil {
+		return src, nil, append(parseDiags, binder.Diagnostic{
+			Severity: "error",
+			Code:     binder.CodeIOOrParseFailure,
+			Message:  fmt.Sprintf("parse error: %v", err),
+		})
+	}
+	if diag := guardParseErrors(parseDiags, params.ForceParse); diag != nil {
+		return src, nil, append(parseDiags, *diag)
+	}
+	if diag := resolveBookmarks(project, &params.Selector); diag != nil {
+		return src, nil, append(parseDiags, *diag)
+	}
+
+	nodes, selDiags := moveEvalSourceSelector(params.Selector, result.Root, result.Fenced)
+	if len(nodes) == 0 {
+		return src, nil, append(parseDiags, selDiags...)
+	}
+	if len(nodes) > 1 {
+		return src, nil, append(parseDiags, binder.Diagnostic{
+			Severity: "error",
+			Code:     binder.CodeAmbiguousBareStem,
+			Message:  fmt.Sprintf("selector %q matched %d entries; %s needs exactly one", params.Selector, len(nodes), opName),
+		})
+	}
+	n := nodes[0]
+	parent := deleteFindParentNode(result.Root, n)
+	diags := append(parseDiags, selDiags...)
+
+	var dest *binder.Node
+	var insertIdx int
+	if promote {
+		if parent.Type == "root" {
+			return src, nil, append(diags, binder.Diagnostic{
+				Severity: "error",
+				Code:     CodeCannotShift,
+				Message:  fmt.Sprintf("%q is already at the top level", n.Title),
+				Location: &binder.Location{Line: n.Line},
+			})
+		}
+		dest = deleteFindParentNode(result.Root, parent)
+		insertIdx = slices.Index(dest.Children, parent) + 1
+		if len(parent.Children) == 1 {
+			diags = append(diags, binder.Diagnostic{
+				Severity: "warning",
+				Code:     binder.CodeEmptySublistPruned,
+				Message:  "empty sublist was pruned after moving sole child",
+			})
+		}
+	} else {
+		i := slices.Index(parent.Children, n)
+		if i == 0 {
+			return src, nil, append(diags, binder.Diagnostic{
+				Severity: "error",
+				Code:     CodeCannotShift,
+				Message:  fmt.Sprintf("%q has no preceding sibling to nest under", n.Title),
+				Location: &binder.Location{Line: n.Line},
+			})
+		}
+		dest = parent.Children[i-1]
+		insertIdx = len(dest.Children)
+	}
+
+	indentStr, marker := inferMarkerAndIndent(dest, insertIdx, project)
+	moved := moveRebuildDocument(result, []*binder.Node{n}, dest, insertIdx, indentStr, marker, true)
+	out := binder.Serialize(result)
+	return out, locateEntries(ctx, out, project, moved), diags
+}
@@ -0,0 +1,131 @@
+package ops
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestPromoteDemote(t *testing.T) {
+	tree := binderSrc(
+		"- [Part](part.md)",
+		"  - [One](ch1.md) <!-- draft -->",
+		"    - [Scene](s1.md)",
+		"  - [Two](ch2.md)",
+		"- [Coda](coda.md)",
+	)
+	tests := []struct {
+		name      string
+		promote   bool
+		src       []byte
+		selector  string
+		want      []byte
+		wantPath  []int
+		wantPrune bool
+	}{
+		{
+			name:     "promote after the parent, with the subtree",
+			promote:  true,
+			src:      tree,
+			selector: "ch1",
+			want: binderSrc(
+				"- [Part](part.md)",
+				"  - [Two](ch2.md)",
+				"- [One](ch1.md) <!-- draft -->",
+				"  - [Scene](s1.md)",
+				"- [Coda](coda.md)",
+			),
+			wantPath: []int{1},
+		},
+		{
+			name:      "promote a sole child",
+			promote:   true,
+			src:       tree,
+			selector:  "s1",
+			want:      binderSrc("- [Part](part.md)", "  - [One](ch1.md) <!-- draft -->", "  - [Scene](s1.md)", "  - [Two](ch2.md)", "- [Coda](coda.md)"),
+			wantPath:  []int{0, 1},
+			wantPrune: true,
+		},
+		{
+			name:     "demote under the preceding sibling's children",
+			src:      tree,
+			selector: "ch2",
+			want:     binderSrc("- [Part](part.md)", "  - [One](ch1.md) <!-- draft -->", "    - [Scene](s1.md)", "    - [Two](ch2.md)", "- [Coda](coda.md)"),
+			wantPath: []int{0, 0, 1},
+		},
+		{
+			name:     "demote under a leaf",
+			src:      binderSrc("1. [One](ch1.md)", "2. [Two](ch2.md)"),
+			selector: "Two",
+			want:     binderSrc("1. [One](ch1.md)", "  - [Two](ch2.md)"),
+			wantPath: []int{0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shiftFn := Demote
+			if tt.promote {
+				shiftFn = Promote
+			}
+			out, entries, diags := shiftFn(context.Background(), tt.src, nil, binder.ShiftParams{Selector: tt.selector})
+			if string(out) != string(tt.want) {
+				t.Errorf("got\n%s\nwant\n%s", out, tt.want)
+			}
+			if len(entries) != 1 || !slices.Equal(entries[0].Path, tt.wantPath) {
+				t.Errorf("entries = %+v, want path %v", entries, tt.wantPath)
+			}
+			if hasDiagCode(diags, binder.CodeEmptySublistPruned) != tt.wantPrune || len(diags) > 1 {
+				t.Errorf("diags = %v, want OPW004: %v", diags, tt.wantPrune)
+			}
+		})
+	}
+}
+
+func TestPromoteDemote_Errors(t *testing.T) {
+	src := binderSrc("- [One](ch1.md)", "  - [Scene](s1.md)", "- [Same](a.md)", "- [Same](b.md)")
+	tests := []struct {
+		name     string
+		promote  bool
+		src      []byte
+		selector string
+		wantCode string
+	}{
+		{"parse errors", false, brokenBinder, "ch2.md", CodeBinderHasParseErrors},
+		{"no match", true, src, "Missing", binder.CodeSelectorNoMatch},
+		{"several matches", false, src, "Same", binder.CodeAmbiguousBareStem},
+		{"promote at top level", true, src, "One", CodeCannotShift},
+		{"demote a first child", false, src, "Scene", CodeCannotShift},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shiftFn := Demote
+			if tt.promote {
+				shiftFn = Promote
+			}
+			out, entries, diags := shiftFn(context.Background(), tt.src, nil, binder.ShiftParams{Selector: tt.selector})
+			if !hasDiagCode(diags, tt.wantCode) {
+				t.Errorf("diags = %v, want %s", diags, tt.wantCode)
+			}
+			if string(out) != string(tt.src) || entries != nil {
+				t.Errorf("out = %q, entries = %v; want the source unchanged", out, entries)
+			}
+		})
+	}
+}
+
+func TestPromote_ParseError(t *testing.T) {
+	orig := moveParseBinderFn
+	t.Cleanup(func() { moveParseBinderFn = orig })
+	moveParseBinderFn = func(context.Context, []byte, *binder.Project) (*binder.ParseResult, []binder.Diagnostic, error) {
+		return nil, nil, errors.New("mock parse failure")
+	}
+
+	src := binderSrc("- [One](ch1.md)")
+	out, _, diags := Promote(context.Background(), src, nil, binder.ShiftParams{Selector: "One"})
+	if !hasDiagCode(diags, binder.CodeIOOrParseFailure) || string(out) != string(src) {
+		t.Errorf("out = %q, diags = %v; want OPE009 and the source unchanged", out, diags)
+	}
+}
@@ -0,0 +1,195 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// CodeRefLabelConflict is the error diagnostic for inserting a subtree
+// whose reference-style links use a label the binder already defines for
+// another target.
+const CodeRefLabelConflict = "PMKE014"
+
+// Subtree is a binder entry and its descendants as they stood in a binder's
+// source, so that it can be put back, in that binder or another, with its
+// placeholders, checkboxes, link styles, and annotations.
+type Subtree struct {
+	// Lines are the source lines from the entry's list item through its
+	// last descendant's.
+	Lines []string `json:"lines"`
+	// Indent is the width of the first line's indentation.
+	Indent int `json:"indent"`
+	// Defs are the reference definition lines of the reference-style links
+	// in Lines, keyed by lowercase label.
+	Defs map[string]string `json:"defs,omitempty"`
+}
+
+// subtreeOf returns the Subtree of n, an entry of result.
+func subtreeOf(result *binder.ParseResult, n *binder.Node) Subtree {
+	s := Subtree{Lines: slices.Clone(result.Lines[n.Line-1 : deleteComputeSubtreeEnd(n)]), Indent: n.Indent}
+	targets := make(map[string]bool)
+	for _, c := range append([]*binder.Node{n}, collectAllNodes(n)...) {
+		targets[c.Target] = true
+	}
+	text := strings.ToLower(strings.Join(s.Lines, "\n"))
+	for label, d := range result.RefDefs {
+		if targets[d.Target] && strings.Contains(text, "["+label+"]") {
+			if s.Defs == nil {
+				s.Defs = make(map[string]string)
+			}
+			s.Defs[label] = result.Lines[d.Line-1]
+		}
+	}
+	return s
+}
+
+// InsertSubtreeParams places a Subtree for InsertSubtree.
+type InsertSubtreeParams struct {
+	// ParentSelector selects the new parent as a move's destination
+	// selector does, unless ParentPath is set.
+	ParentSelector string
+	// ParentPath, when not nil, is the new parent's child index path from
+	// the root: empty for the root itself.
+	ParentPath []int
+	// Position, At, Before, and After place the subtree among the parent's
+	// children as they place a new child in AddChild.
+	Position      string
+	At            *int
+	Before, After string
+	// PreserveExtras keeps the non-structural text around the entry's link,
+	// as in Move.
+	PreserveExtras bool
+	// ForceParse proceeds even though the binder has parse errors.
+	ForceParse bool
+	// Subtree is the subtree to insert.
+	Subtree Subtree
+}
+
+// InsertSubtree inserts params.Subtree into the binder where params places
+// it. Its lines keep their text and take the indentation and list marker of
+// their new place, as in Move: unless params.PreserveExtras is set, the
+// entry's own non-structural text other than its checkbox and annotation is
+// dropped with an OPW003 warning quoting it. Reference definitions its links use that the binder lacks are added,
+// and one whose label the binder defines for another target is a PMKE014
+// error. Returns the modified binder bytes and diagnostics; on error the
+// returned bytes are equal to src.
+func InsertSubtree(ctx context.Context, src []byte, project *binder.Project, params InsertSubtreeParams) ([]byte, []binder.Diagnostic) {
+	p := parseSrc(ctx, parseBinderFn, src, project)
+	edited, _, diags := insertSubtree(p, project, params)
+	return p.output(edited), diags
+}
+
+// insertSubtree implements InsertSubtree by editing the parsed binder p in
+// place, reporting whether it did and the 0-based line index of the
+// inserted entry in the edited lines.
+func insertSubtree(p parsed, project *binder.Project, params InsertSubtreeParams) (bool, []int, []binder.Diagnostic) {
+	result, parseDiags, err := p.result, p.diags, p.err
+	if err != nil {
+		return false, nil, append(parseDiags, binder.Diagnostic{
+			Severity: "error",
+			Code:     binder.CodeIOOrParseFailure,
+			Message:  fmt.Sprintf("parse error: %v", err),
+		})
+	}
+	if diag := guardParseErrors(parseDiags, params.ForceParse); diag != nil {
+		return false, nil, append(parseDiags, *diag)
+	}
+	if diag := resolveBookmarks(project, &params.ParentSelector, &params.Before, &params.After); diag != nil {
+		return false, nil, append(parseDiags, *diag)
+	}
+
+	parent, selDiags := insertSubtreeParent(result, params)
+	allDiags := append(parseDiags, selDiags...)
+	if parent == nil {
+		return false, nil, allDiags
+	}
+	insertIdx, diag := resolveInsertionIndex(parent, binder.AddChildParams{Position: params.Position, At: params.At, Before: params.Before, After: params.After})
+	if diag != nil {
+		return false, nil, append(allDiags, *diag)
+	}
+	defs, diag := missingRefDefs(result.RefDefs, params.Subtree.Defs)
+	if diag != nil {
+		return false, nil, append(allDiags, *diag)
+	}
+
+	if len(params.Subtree.Lines) > 0 && !params.PreserveExtras {
+		if diag := moveExtrasDiag(params.Subtree.Lines[0]); diag != nil {
+			allDiags = append(allDiags, *diag)
+		}
+	}
+
+	refAnchor := -1
+	for _, d := range result.RefDefs {
+		refAnchor = max(refAnchor, d.Line-1)
+	}
+	lineEnd := majorityLineEnding(result.LineEnds)
+	indentStr, marker := inferMarkerAndIndent(parent, insertIdx, project)
+	lineIdx := insertionLineIdx(parent, insertIdx, result)
+	at := lineIdx
+	if parent.Type == "root" && len(parent.Children) == 0 {
+		insertLine(result, at, "", lineEnd)
+		lineIdx++
+	}
+	for i, line := range params.Subtree.Lines {
+		switch {
+		case i == 0:
+			line = moveReindentFirstLine(line, indentStr, marker, params.PreserveExtras)
+		case strings.TrimSpace(line) != "":
+			line = moveReindentLine(line, params.Subtree.Indent, indentStr)
+		}
+		insertLine(result, lineIdx+i, line, lineEnd)
+	}
+	if at <= refAnchor {
+		refAnchor += lineIdx - at + len(params.Subtree.Lines)
+	}
+	insertRefDefs(result, defs, refAnchor, lineEnd)
+	return true, []int{lineIdx}, allDiags
+}
+
+// insertSubtreeParent returns the parent params selects in result, or nil
+// with an error diagnostic.
+func insertSubtreeParent(result *binder.ParseResult, params InsertSubtreeParams) (*binder.Node, []binder.Diagnostic) {
+	if params.ParentPath == nil {
+		return moveEvalDestSelector(params.ParentSelector, result.Root, result.Fenced)
+	}
+	n := result.Root
+	for _, i := range params.ParentPath {
+		if i < 0 || i >= len(n.Children) {
+			return nil, []binder.Diagnostic{{
+				Severity: "error",
+				Code:     binder.CodeSelectorNoMatch,
+				Message:  fmt.Sprintf("no entry at child index path %v", params.ParentPath),
+			}}
+		}
+		n = n.Children[i]
+	}
+	return n, nil
+}
+
+// missingRefDefs returns the lines of defs whose labels existing lacks, in
+// label order, or a PMKE014 diagnostic for one that existing defines for
+// another target.
+func missingRefDefs(existing map[string]binder.RefDef, defs map[string]string) ([]string, *binder.Diagnostic) {
+	var lines []string
+	for _, label := range slices.Sorted(maps.Keys(defs)) {
+		line := defs[label]
+		d, ok := existing[label]
+		if !ok {
+			lines = append(lines, line)
+			continue
+		}
+		if m := refDefTargetRE.FindStringSubmatch(line); m == nil || m[1] != d.Target {
+			return nil, &binder.Diagnostic{
+				Severity: "error",
+				Code:     CodeRefLabelConflict,
+				Message:  fmt.Sprintf("reference label %q already names %s", label, d.Target),
+			}
+		}
+	}
+	return lines, nil
+}
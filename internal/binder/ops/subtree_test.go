@@ -0,0 +1,160 @@
+package ops
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// TestDeleteSubtrees_RemovedSource verifies that a removed entry carries
+// its parent's index path and its lines as they stood, with the reference
+// definitions its links use.
+func TestDeleteSubtrees_RemovedSource(t *testing.T) {
+	src := binderSrc(
+		"- [Part](part.md)",
+		"  - [x] [One][c1] <!-- 900 words -->",
+		"    - [Later]()",
+		"",
+		"      - [Two](ch2.md)",
+		"- [Three][c3]",
+		"",
+		"[c1]: ch1.md",
+		"[c3]: ch3.md",
+	)
+	_, _, removed, diags := DeleteSubtrees(context.Background(), src, nil, binder.DeleteParams{Selector: "ch1.md", Yes: true})
+	if hasDiagCode(diags, "error") || len(removed) != 1 {
+		t.Fatalf("removed = %+v, diags = %v", removed, diags)
+	}
+	r := removed[0]
+	if !reflect.DeepEqual(r.ParentPath, []int{0}) || r.Index != 0 {
+		t.Errorf("ParentPath, Index = %v, %d; want [0], 0", r.ParentPath, r.Index)
+	}
+	want := Subtree{
+		Lines:  []string{"  - [x] [One][c1] <!-- 900 words -->", "    - [Later]()", "", "      - [Two](ch2.md)"},
+		Indent: 2,
+		Defs:   map[string]string{"c1": "[c1]: ch1.md"},
+	}
+	if !reflect.DeepEqual(r.Subtree, want) {
+		t.Errorf("Subtree = %+v, want %+v", r.Subtree, want)
+	}
+}
+
+func TestInsertSubtree(t *testing.T) {
+	sub := Subtree{
+		Lines:  []string{"  - [x] [One][c1] <!-- 900 words -->", "    - [Later]()", "", "      - [Two](ch2.md)"},
+		Indent: 2,
+		Defs:   map[string]string{"c1": "[c1]: ch1.md"},
+	}
+	at := 0
+	tests := []struct {
+		name   string
+		src    []byte
+		params InsertSubtreeParams
+		want   []byte
+	}{
+		{
+			name:   "under a selected parent, adding the reference definition",
+			src:    binderSrc("1. [Part](part.md)", "   1. [Zero](ch0.md)"),
+			params: InsertSubtreeParams{ParentSelector: "part", PreserveExtras: true, Subtree: sub},
+			want: binderSrc(
+				"1. [Part](part.md)",
+				"   1. [Zero](ch0.md)",
+				"   2. [x] [One][c1] <!-- 900 words -->",
+				"     - [Later]()",
+				"",
+				"       - [Two](ch2.md)",
+				"",
+				"[c1]: ch1.md",
+			),
+		},
+		{
+			name:   "at an index path, before existing definitions",
+			src:    binderSrc("- [Part](part.md)", "  - [Zero](ch0.md)", "- [Three][c3]", "", "[c1]: ch1.md", "[c3]: ch3.md"),
+			params: InsertSubtreeParams{ParentPath: []int{0}, At: &at, PreserveExtras: true, Subtree: sub},
+			want: binderSrc(
+				"- [Part](part.md)",
+				"  - [x] [One][c1] <!-- 900 words -->",
+				"    - [Later]()",
+				"",
+				"      - [Two](ch2.md)",
+				"  - [Zero](ch0.md)",
+				"- [Three][c3]",
+				"",
+				"[c1]: ch1.md",
+				"[c3]: ch3.md",
+			),
+		},
+		{
+			name:   "into an empty binder",
+			src:    []byte("<!-- prosemark-binder:v1 -->\n"),
+			params: InsertSubtreeParams{ParentPath: []int{}, Subtree: Subtree{Lines: []string{"    * [ ] [Draft]()"}, Indent: 4}},
+			want:   binderSrc("- [ ] [Draft]()"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, diags := InsertSubtree(context.Background(), tt.src, nil, tt.params)
+			if len(diags) != 0 {
+				t.Fatalf("diags = %v", diags)
+			}
+			if !bytes.Equal(out, tt.want) {
+				t.Errorf("binder = %q, want %q", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsertSubtree_Refused(t *testing.T) {
+	src := binderSrc("- [Part](part.md)", "  - [Three][c1]", "", "[c1]: ch3.md")
+	sub := Subtree{Lines: []string{"- [One][c1]"}, Defs: map[string]string{"c1": "[c1]: ch1.md"}}
+	at := 5
+	tests := []struct {
+		name   string
+		src    []byte
+		params InsertSubtreeParams
+		code   string
+	}{
+		{"binder not UTF-8", []byte("\xff"), InsertSubtreeParams{ParentSelector: "."}, binder.CodeIOOrParseFailure},
+		{"no such parent", src, InsertSubtreeParams{ParentSelector: "nope"}, binder.CodeSelectorNoMatch},
+		{"no entry at the path", src, InsertSubtreeParams{ParentPath: []int{0, 3}}, binder.CodeSelectorNoMatch},
+		{"index out of bounds", src, InsertSubtreeParams{ParentSelector: ".", At: &at}, binder.CodeIndexOutOfBounds},
+		{"label names another target", src, InsertSubtreeParams{ParentSelector: ".", Subtree: sub}, CodeRefLabelConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, diags := InsertSubtree(context.Background(), tt.src, nil, tt.params)
+			if !bytes.Equal(out, tt.src) || !hasDiagCode(diags, tt.code) {
+				t.Errorf("binder = %q, diags = %v; want it unchanged with %s", out, diags, tt.code)
+			}
+		})
+	}
+}
+
+// TestInsertSubtree_DropsExtras verifies that without PreserveExtras the
+// entry's own non-structural text is dropped with an OPW003 warning quoting
+// it, and its checkbox and annotation kept.
+func TestInsertSubtree_DropsExtras(t *testing.T) {
+	sub := Subtree{Lines: []string{"- [x] [One](ch1.md) ← revise <!-- 900 words -->", "  - [Two](ch2.md) ← cut <!-- 80 words -->"}}
+	out, diags := InsertSubtree(context.Background(), binderSrc("- [Part](part.md)"), nil, InsertSubtreeParams{ParentSelector: ".", Subtree: sub})
+	if want := binderSrc("- [Part](part.md)", "- [x] [One](ch1.md) <!-- 900 words -->", "  - [Two](ch2.md) ← cut <!-- 80 words -->"); !bytes.Equal(out, want) {
+		t.Errorf("binder = %q, want %q", out, want)
+	}
+	if len(diags) != 1 || diags[0].Code != binder.CodeNonStructuralDestroyed || !strings.HasSuffix(diags[0].Message, `"← revise"`) {
+		t.Errorf("diags = %v, want OPW003 quoting only the text before the annotation", diags)
+	}
+}
+
+// TestInsertSubtree_SharedDefinition verifies that a definition the binder
+// already has for the same target is not added again.
+func TestInsertSubtree_SharedDefinition(t *testing.T) {
+	src := binderSrc("- [One][c1]", "", "[c1]: ch1.md")
+	sub := Subtree{Lines: []string{"- [One again][c1]"}, Defs: map[string]string{"c1": "[c1]: ch1.md"}}
+	out, diags := InsertSubtree(context.Background(), src, nil, InsertSubtreeParams{ParentSelector: ".", Subtree: sub})
+	if want := binderSrc("- [One][c1]", "- [One again][c1]", "", "[c1]: ch1.md"); !bytes.Equal(out, want) || len(diags) != 0 {
+		t.Errorf("binder = %q, diags = %v; want %q", out, diags, want)
+	}
+}
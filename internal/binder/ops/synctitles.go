@@ -0,0 +1,124 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// Directions accepted by binder.SyncTitlesParams.Direction.
+const (
+	SyncFileToBinder = "file-to-binder" // link text takes the node file's title
+	SyncBinderToFile = "binder-to-file" // the node file takes the link text
+)
+
+// CodeTitleNotSynced is an implementation-specific warning emitted when
+// sync-titles leaves an entry's titles as they are.
+const CodeTitleNotSynced = "PMKW013"
+
+// SyncTitles brings entry titles and the frontmatter titles of their node
+// files into line (pmk sync-titles). fileTitle returns the title of the
+// node file at a target, "" when it has none.
+//
+// With SyncFileToBinder the link text of each entry is set to its file's
+// title, as Retitle sets it, and the retitled entries are returned. With
+// SyncBinderToFile the binder is left alone and the entries returned are
+// those whose node file should take the entry's title; titles taken from a
+// filename are not pushed, and where entries sharing a target disagree the
+// first one wins.
+//
+// Each entry left out of sync gets a PMKW013 warning: its file cannot be
+// read, has no title to take, cannot hold the title as a wikilink alias, or
+// lost to an earlier entry. Source bytes are unchanged on error.
+func SyncTitles(ctx context.Context, src []byte, project *binder.Project, params binder.SyncTitlesParams, fileTitle func(target string) (string, error)) ([]byte, []binder.AffectedEntry, []binder.Diagnostic) {
+	result, parseDiags, err := binder.Parse(ctx, src, project)
+	if err != nil {
+		return src, nil, append(parseDiags, binder.Diagnostic{
+			Severity: "error",
+			Code:     binder.CodeIOOrParseFailure,
+			Message:  fmt.Sprintf("parse error: %v", err),
+		})
+	}
+	if diag := guardParseErrors(parseDiags, params.ForceParse); diag != nil {
+		return src, nil, append(parseDiags, *diag)
+	}
+
+	type fileResult struct {
+		title string
+		err   error
+	}
+	files := make(map[string]fileResult)
+	pushed := make(map[string]*binder.Node)
+	diags := parseDiags
+	warn := func(n *binder.Node, format string, args ...any) {
+		diags = append(diags, binder.Diagnostic{
+			Severity: "warning",
+			Code:     CodeTitleNotSynced,
+			Message:  fmt.Sprintf(format, args...),
+			Location: &binder.Location{Line: n.Line},
+		})
+	}
+	var changed []int
+	for _, n := range collectAllNodes(result.Root) {
+		if n.Target == "" {
+			continue
+		}
+		f, ok := files[n.Target]
+		if !ok {
+			f.title, f.err = fileTitle(n.Target)
+			files[n.Target] = f
+		}
+		if f.err != nil {
+			warn(n, "cannot read the title of %s: %v", n.Target, f.err)
+			continue
+		}
+
+		if params.Direction == SyncFileToBinder {
+			if f.title == "" {
+				warn(n, "%s has no frontmatter title", n.Target)
+				continue
+			}
+			if n.Title == f.title {
+				continue
+			}
+			if updated, err := setEntryTitle(result.Lines, n, f.title); err != nil {
+				warn(n, "%v", err)
+			} else if updated {
+				changed = append(changed, n.Line-1)
+			}
+			continue
+		}
+
+		if !explicitTitle(result.Lines, n) {
+			continue
+		}
+		if first, ok := pushed[n.Target]; ok {
+			if first.Title != n.Title {
+				warn(n, "%s takes the title %q from line %d, not %q", n.Target, first.Title, first.Line, n.Title)
+			}
+			continue
+		}
+		pushed[n.Target] = n
+		if f.title != n.Title {
+			changed = append(changed, n.Line-1)
+		}
+	}
+
+	if params.Direction == SyncBinderToFile || len(changed) == 0 {
+		return src, entriesAt(result.Root, changed), diags
+	}
+	out := binder.Serialize(result)
+	return out, locateEntries(ctx, out, project, changed), diags
+}
+
+// explicitTitle reports whether n's title is written in its link rather
+// than taken from the target's filename.
+func explicitTitle(lines []string, n *binder.Node) bool {
+	for idx := n.Line - 1; idx < max(n.Line, n.EndLine); idx++ {
+		if lo, hi, _, ok := linkTitleSpan(lines[idx]); ok {
+			return lo < hi
+		}
+	}
+	return false
+}
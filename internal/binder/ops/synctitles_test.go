@@ -0,0 +1,113 @@
+package ops
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestSyncTitles_FileToBinder(t *testing.T) {
+	src := binderSrc("- [One](ch1.md)\n- [[ch2|Two]]\n- [Three][c3]\n- [[ch4]]\n- [Same](ch5.md)\n- [Gone](ch6.md)\n- [Untitled](ch7.md)\n\n[c3]: ch3.md")
+	want := binderSrc("- [First](ch1.md)\n- [[ch2|Second]]\n- [Third][c3]\n- [[ch4]] Fourth\n- [Same](ch5.md)\n- [Gone](ch6.md)\n- [Untitled](ch7.md)\n\n[c3]: ch3.md")
+	titles := map[string]string{"ch1.md": "First", "ch2.md": "Second", "ch3.md": "Third", "ch4.md": "Fourth", "ch5.md": "Same", "ch7.md": ""}
+	var reads []string
+	fileTitle := func(target string) (string, error) {
+		reads = append(reads, target)
+		title, ok := titles[target]
+		if !ok {
+			return "", errors.New("file does not exist")
+		}
+		return title, nil
+	}
+	proj := &binder.Project{Files: []string{"ch1.md", "ch2.md", "ch3.md", "ch4.md", "ch5.md", "ch7.md"}, BinderDir: "."}
+
+	got, entries, diags := SyncTitles(context.Background(), src, proj, binder.SyncTitlesParams{Direction: SyncFileToBinder}, fileTitle)
+	if !bytes.Equal(got, want) {
+		t.Errorf("binder = %q, want %q", got, want)
+	}
+	var retitled []string
+	for _, e := range entries {
+		retitled = append(retitled, e.Title)
+	}
+	if strings.Join(retitled, ",") != "First,Second,Third,Fourth" {
+		t.Errorf("entries = %+v", entries)
+	}
+	var warnings []string
+	for _, d := range diags {
+		if d.Code == CodeTitleNotSynced {
+			warnings = append(warnings, d.Message)
+		}
+	}
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "ch6.md") || !strings.Contains(warnings[1], "ch7.md has no frontmatter title") {
+		t.Errorf("warnings = %q", warnings)
+	}
+	if len(reads) != 7 {
+		t.Errorf("read %v, want each target once", reads)
+	}
+}
+
+func TestSyncTitles_FileToBinder_AliasCannotHoldTitle(t *testing.T) {
+	src := binderSrc("- [[ch1|One]]")
+	got, _, diags := SyncTitles(context.Background(), src, nil, binder.SyncTitlesParams{Direction: SyncFileToBinder},
+		func(string) (string, error) { return "A|B", nil })
+	if !bytes.Equal(got, src) || len(diags) != 1 || diags[0].Code != CodeTitleNotSynced || diags[0].Severity != "warning" {
+		t.Errorf("binder = %q, diags = %+v; want unchanged with a PMKW013 warning", got, diags)
+	}
+}
+
+func TestSyncTitles_BinderToFile(t *testing.T) {
+	src := binderSrc("- [First](ch1.md)\n- [[ch2]]\n- [Same](ch3.md)\n- [Again](ch1.md)\n- [First](ch1.md)")
+	titles := map[string]string{"ch1.md": "One", "ch2.md": "Two", "ch3.md": "Same"}
+	fileTitle := func(target string) (string, error) { return titles[target], nil }
+
+	got, entries, diags := SyncTitles(context.Background(), src, nil, binder.SyncTitlesParams{Direction: SyncBinderToFile}, fileTitle)
+	if !bytes.Equal(got, src) {
+		t.Errorf("binder changed to %q", got)
+	}
+	if len(entries) != 1 || entries[0].Target != "ch1.md" || entries[0].Title != "First" || entries[0].Line != 3 {
+		t.Errorf("entries = %+v, want ch1.md to take First", entries)
+	}
+	if d := diags[len(diags)-1]; d.Code != CodeTitleNotSynced || d.Location.Line != 6 {
+		t.Errorf("diags = %+v, want PMKW013 for the disagreeing entry", diags)
+	}
+}
+
+func TestSyncTitles_ParseErrors(t *testing.T) {
+	src := binderSrc("- [One](b:c.md)")
+	got, _, diags := SyncTitles(context.Background(), src, nil, binder.SyncTitlesParams{Direction: SyncFileToBinder},
+		func(string) (string, error) { return "First", nil })
+	if !bytes.Equal(got, src) || diags[len(diags)-1].Code != CodeBinderHasParseErrors {
+		t.Errorf("binder = %q, diags = %+v; want the parse-error guard", got, diags)
+	}
+}
+
+func TestSyncTitles_Unparsable(t *testing.T) {
+	src := []byte("- [One](ch1.md)\n\xff\n")
+	got, _, diags := SyncTitles(context.Background(), src, nil, binder.SyncTitlesParams{Direction: SyncFileToBinder},
+		func(string) (string, error) { return "First", nil })
+	if !bytes.Equal(got, src) || diags[len(diags)-1].Code != binder.CodeIOOrParseFailure {
+		t.Errorf("binder = %q, diags = %+v; want unchanged with OPE009", got, diags)
+	}
+}
+
+func TestSyncTitles_SkipsPlaceholders(t *testing.T) {
+	src := binderSrc("- [Idea]()\n- [One](ch1.md)")
+	var reads []string
+	fileTitle := func(target string) (string, error) {
+		reads = append(reads, target)
+		return "First", nil
+	}
+	if _, entries, _ := SyncTitles(context.Background(), src, nil, binder.SyncTitlesParams{Direction: SyncFileToBinder}, fileTitle); len(entries) != 1 || len(reads) != 1 {
+		t.Errorf("entries = %+v, reads = %v; want only ch1.md synced", entries, reads)
+	}
+}
+
+func TestExplicitTitle_NoLink(t *testing.T) {
+	if explicitTitle([]string{"- Chapter one"}, &binder.Node{Line: 1}) {
+		t.Error("explicitTitle() = true for a line without a link")
+	}
+}
@@ -0,0 +1,148 @@
+package ops
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Casing styles accepted by binder.TitlesParams.Case.
+const (
+	CaseTitle    = "title"    // The Fall of the House of Usher
+	CaseSentence = "sentence" // The fall of the house of Usher
+)
+
+// titleSmallWords are the articles, conjunctions, and short prepositions
+// that title case leaves in lower case unless they start or end a phrase.
+var titleSmallWords = map[string]bool{
+	"a": true, "an": true, "and": true, "as": true, "at": true, "but": true,
+	"by": true, "en": true, "for": true, "if": true, "in": true, "nor": true,
+	"of": true, "on": true, "or": true, "per": true, "so": true, "the": true,
+	"to": true, "up": true, "via": true, "vs": true, "yet": true,
+}
+
+// TitleCase capitalizes every word of s except small words in the middle
+// of a phrase. Each part of a hyphenated word is capitalized except small
+// words between its first and last parts (State-of-the-Art).
+func TitleCase(s string) string {
+	return recase(s, func(word string, first, last bool) string {
+		if titleSmallWords[letters(word)] && !first && !last {
+			return strings.ToLower(word)
+		}
+		parts := strings.Split(word, "-")
+		for i, p := range parts {
+			p = strings.ToLower(p)
+			if i == 0 || i == len(parts)-1 || !titleSmallWords[letters(p)] {
+				p = capitalize(p)
+			}
+			parts[i] = p
+		}
+		return strings.Join(parts, "-")
+	})
+}
+
+// SentenceCase lowercases every word of s except the first word of each
+// phrase and the pronoun "I".
+func SentenceCase(s string) string {
+	return recase(s, func(word string, first, _ bool) string {
+		lower := strings.ToLower(word)
+		if first || letters(word) == "i" || strings.HasPrefix(lower, "i'") || strings.HasPrefix(lower, "i’") {
+			return capitalize(lower)
+		}
+		return lower
+	})
+}
+
+// recase applies fn to each space-separated word of s, keeping the spacing.
+// A word is first when it starts s or follows a colon, question mark,
+// exclamation mark, or dash, and last when it ends s or precedes one. Words that already carry capitals after
+// their first letter (NASA, iPhone, McCoy) or look like paths or numbers
+// with dots (v2.0, example.com) are kept as written, unless the whole
+// title is in capitals.
+func recase(s string, fn func(word string, first, last bool) string) string {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return s
+	}
+	shouted := len(fields) > 1 && s == strings.ToUpper(s)
+	var sb strings.Builder
+	rest := s
+	for i, word := range fields {
+		at := strings.Index(rest, word)
+		sb.WriteString(rest[:at])
+		rest = rest[at+len(word):]
+
+		first := i == 0 || endsPhrase(fields[i-1])
+		last := i == len(fields)-1 || endsPhrase(word) || isDash(fields[i+1])
+		if keepsCase(word, shouted) {
+			sb.WriteString(word)
+		} else {
+			sb.WriteString(fn(word, first, last))
+		}
+	}
+	sb.WriteString(rest)
+	return sb.String()
+}
+
+// endsPhrase reports whether word ends a phrase: it ends with a colon,
+// question mark, or exclamation mark, perhaps inside closing quotes or
+// brackets, or is a dash.
+func endsPhrase(word string) bool {
+	return strings.ContainsAny(lastRune(strings.TrimRight(word, ")]\"'”’")), ":?!") || isDash(word)
+}
+
+// lastRune returns the last character of s, or "" when s is empty.
+func lastRune(s string) string {
+	_, n := utf8.DecodeLastRuneInString(s)
+	return s[len(s)-n:]
+}
+
+// isDash reports whether word is a free-standing dash.
+func isDash(word string) bool {
+	return word == "-" || word == "--" || word == "–" || word == "—"
+}
+
+// keepsCase reports whether word must keep its casing: it has a dot,
+// slash, or @ inside it, or, unless shouted, an upper-case letter after its
+// first letter.
+func keepsCase(word string, shouted bool) bool {
+	core := strings.TrimRight(word, ".,;:!?)\"'”’")
+	if strings.ContainsAny(core, "./@") {
+		return true
+	}
+	if shouted {
+		return false
+	}
+	seen := false
+	for _, r := range core {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if seen && unicode.IsUpper(r) {
+			return true
+		}
+		seen = true
+	}
+	return false
+}
+
+// capitalize upper-cases the first letter of word, skipping any leading
+// punctuation such as quotes or brackets.
+func capitalize(word string) string {
+	for i, r := range word {
+		if unicode.IsLetter(r) {
+			return word[:i] + string(unicode.ToUpper(r)) + word[i+utf8.RuneLen(r):]
+		}
+	}
+	return word
+}
+
+// letters returns the lower-cased letters of word.
+func letters(word string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, word)
+}
@@ -0,0 +1,50 @@
+package ops
+
+import "testing"
+
+func TestTitleCase(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"the fall of the house of usher", "The Fall of the House of Usher"},
+		{"A TALE OF TWO CITIES", "A Tale of Two Cities"},
+		{"NASA", "NASA"},
+		{"a tale Of two cities", "A Tale of Two Cities"},
+		{"what the storm is for", "What the Storm Is For"},
+		{"part one: the flood", "Part One: The Flood"},
+		{"who goes there? a stranger", "Who Goes There? A Stranger"},
+		{"run! the flood is coming", "Run! The Flood Is Coming"},
+		{`he said "stop!" the end`, `He Said "Stop!" The End`},
+		{"what is it for?", "What Is It For?"},
+		{"wait?!", "Wait?!"},
+		{"before — and after", "Before — And After"},
+		{"the self-made man", "The Self-Made Man"},
+		{"a state-of-the-art machine", "A State-of-the-Art Machine"},
+		{"UP-TO-DATE NEWS", "Up-to-Date News"},
+		{"the follow-up", "The Follow-Up"},
+		{"my iPhone and NASA", "My iPhone and NASA"},
+		{`"quoted" words, v2.0 at example.com`, `"Quoted" Words, v2.0 at example.com`},
+		{`the \[draft\] chapter`, `The \[Draft\] Chapter`},
+		{"  spaced   out  ", "  Spaced   Out  "},
+		{"", ""},
+		{"42", "42"},
+	}
+	for _, tt := range tests {
+		if got := TitleCase(tt.in); got != tt.want {
+			t.Errorf("TitleCase(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSentenceCase(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"The Fall Of The House", "The fall of the house"},
+		{"what I Saw and i'm Sure", "What I saw and I'm sure"},
+		{"Part One: The Flood", "Part one: The flood"},
+		{"Who Goes There? A Stranger", "Who goes there? A stranger"},
+		{"Meeting NASA In McCall", "Meeting NASA in McCall"},
+	}
+	for _, tt := range tests {
+		if got := SentenceCase(tt.in); got != tt.want {
+			t.Errorf("SentenceCase(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// linkTextRE matches the bracketed text at the start of an inline or
+// reference link. Group 1 is the text, escapes included.
+var linkTextRE = regexp.MustCompile(`^\[((?:[^\]\\]|\\.)*)\]`)
+
+// Titles rewrites the casing of entry titles (pmk titles): every entry's,
+// or those of the entries params.Selector matches and their subtrees.
+// params.Case is CaseTitle or CaseSentence. Only the title text changes:
+// link syntax, targets, escapes, and annotations are kept. Titles taken
+// from a filename (a bare [[stem]] or an inline link with empty text) and
+// plain-text placeholders are left alone. Returns the modified bytes, the
+// retitled entries, and diagnostics. Source bytes are unchanged on error.
+func Titles(ctx context.Context, src []byte, project *binder.Project, params binder.TitlesParams) ([]byte, []binder.AffectedEntry, []binder.Diagnostic) {
+	result, parseDiags, err := binder.Parse(ctx, src, project)
+	if err != nil {
+		return src, nil, append(parseDiags, binder.Diagnostic{
+			Severity: "error",
+			Code:     binder.CodeIOOrParseFailure,
+			Message:  fmt.Sprintf("parse error: %v", err),
+		})
+	}
+	if diag := guardParseErrors(parseDiags, params.ForceParse); diag != nil {
+		return src, nil, append(parseDiags, *diag)
+	}
+	if diag := resolveBookmarks(project, &params.Selector); diag != nil {
+		return src, nil, append(parseDiags, *diag)
+	}
+
+	nodes := collectAllNodes(result.Root)
+	var selDiags []binder.Diagnostic
+	if params.Selector != "" {
+		var selected []*binder.Node
+		if selected, selDiags = moveEvalSourceSelector(params.Selector, result.Root, result.Fenced); len(selected) == 0 {
+			return src, nil, append(parseDiags, selDiags...)
+		}
+		nodes = nil
+		for _, n := range selected {
+			nodes = append(nodes, n)
+			nodes = append(nodes, collectAllNodes(n)...)
+		}
+	}
+
+	recaseFn := TitleCase
+	if params.Case == CaseSentence {
+		recaseFn = SentenceCase
+	}
+	seen := make(map[*binder.Node]bool)
+	var changed []int
+	for _, n := range nodes {
+		if seen[n] || n.Type == "placeholder" {
+			continue
+		}
+		seen[n] = true
+		for idx := n.Line - 1; idx < max(n.Line, n.EndLine); idx++ {
+			if line, ok := retitleLine(result.Lines[idx], recaseFn); ok {
+				if line != result.Lines[idx] {
+					result.Lines[idx] = line
+					changed = append(changed, n.Line-1)
+				}
+				break
+			}
+		}
+	}
+	if len(changed) == 0 {
+		return src, nil, append(parseDiags, selDiags...)
+	}
+	out := binder.Serialize(result)
+	return out, locateEntries(ctx, out, project, changed), append(parseDiags, selDiags...)
+}
+
+// retitleLine applies recaseFn to the title of the structural link on line,
+// reporting false when line has no link.
+func retitleLine(line string, recaseFn func(string) string) (string, bool) {
+	lo, hi, _, ok := linkTitleSpan(line)
+	if !ok {
+		return line, false
+	}
+	return line[:lo] + recaseFn(line[lo:hi]) + line[hi:], true
+}
+
+// linkTitleSpan returns the [lo, hi) byte span of the title of the
+// structural link on line and the link's style, reporting false when line
+// has no link. A list item line keeps its marker and checkbox; a
+// continuation line is searched from its first non-blank. The title of a
+// wikilink without an alias is the text after it, so its span is empty,
+// just past the "]]", when there is none.
+func linkTitleSpan(line string) (lo, hi int, style string, ok bool) {
+	start := len(line) - len(strings.TrimLeft(line, " \t"))
+	if m := checkLinePrefixRE.FindStringIndex(line); m != nil {
+		start = m[1]
+	}
+	span := structuralLinkSpan(line[start:])
+	if span == nil {
+		// A shortcut reference link, "[Title]", is not a structural link
+		// pattern of its own.
+		span = linkTextRE.FindStringIndex(line[start:])
+	}
+	if span == nil {
+		return 0, 0, "", false
+	}
+	base := start + span[0]
+	link := line[base : start+span[1]]
+
+	switch {
+	case strings.HasPrefix(strings.TrimPrefix(link, "!"), "[["):
+		end := strings.Index(link, "]]")
+		if bar := strings.Index(link[:end], "|"); bar >= 0 {
+			return base + bar + 1, base + end, LinkStyleWikilink, true
+		}
+		textLo := end + 2
+		textLo += len(link[textLo:]) - len(strings.TrimLeft(link[textLo:], " \t"))
+		return base + textLo, base + len(link), LinkStyleWikilink, true
+	case strings.HasSuffix(link, ")"):
+		m := linkTextRE.FindStringSubmatchIndex(link)
+		return base + m[2], base + m[3], LinkStyleInline, true
+	default:
+		m := linkTextRE.FindStringSubmatchIndex(link)
+		return base + m[2], base + m[3], LinkStyleReference, true
+	}
+}
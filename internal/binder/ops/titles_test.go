@@ -0,0 +1,120 @@
+package ops
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestTitles(t *testing.T) {
+	project := &binder.Project{Files: []string{"ch1.md", "ch2.md", "ch3.md", "ch4.md", "ch5.md", "ch6.md", "ch7.md"}, BinderDir: "."}
+	src := binderSrc(
+		"[c4]: ch4.md",
+		"",
+		"- [x] [the \\[draft\\] opening](ch1.md \"tip\") <!-- keep me -->",
+		"  - [[ch2|a night at the opera]]",
+		"  - [[ch3]] the long way home",
+		"- [the end of it][c4]",
+		"- Wrapped entry",
+		"  [one more thing](ch5.md)",
+		"- [[ch6]]",
+		"- [](ch7.md)",
+		"- [a draft to come]()",
+	)
+	tests := []struct {
+		name      string
+		params    binder.TitlesParams
+		want      []byte
+		wantLines []int
+	}{
+		{
+			name:   "title case everywhere",
+			params: binder.TitlesParams{Case: CaseTitle},
+			want: binderSrc(
+				"[c4]: ch4.md",
+				"",
+				"- [x] [The \\[Draft\\] Opening](ch1.md \"tip\") <!-- keep me -->",
+				"  - [[ch2|A Night at the Opera]]",
+				"  - [[ch3]] The Long Way Home",
+				"- [The End of It][c4]",
+				"- Wrapped entry",
+				"  [One More Thing](ch5.md)",
+				"- [[ch6]]",
+				"- [](ch7.md)",
+				"- [A Draft to Come]()",
+			),
+			wantLines: []int{5, 6, 7, 8, 9, 13},
+		},
+		{
+			name:   "sentence case in a subtree",
+			params: binder.TitlesParams{Case: CaseSentence, Selector: "ch1"},
+			want: binderSrc(
+				"[c4]: ch4.md",
+				"",
+				"- [x] [The \\[draft\\] opening](ch1.md \"tip\") <!-- keep me -->",
+				"  - [[ch2|A night at the opera]]",
+				"  - [[ch3]] The long way home",
+				"- [the end of it][c4]",
+				"- Wrapped entry",
+				"  [one more thing](ch5.md)",
+				"- [[ch6]]",
+				"- [](ch7.md)",
+				"- [a draft to come]()",
+			),
+			wantLines: []int{5, 6, 7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, entries, diags := Titles(context.Background(), src, project, tt.params)
+			if len(diags) != 0 {
+				t.Fatalf("diags = %v", diags)
+			}
+			if string(out) != string(tt.want) {
+				t.Errorf("Titles() =\n%s\nwant\n%s", out, tt.want)
+			}
+			var lines []int
+			for _, e := range entries {
+				lines = append(lines, e.Line)
+			}
+			if !slices.Equal(lines, tt.wantLines) {
+				t.Errorf("entry lines = %v, want %v", lines, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestTitles_PlainTextPlaceholder(t *testing.T) {
+	src := binderSrc("- the missing chapter", "- [Already Fine](ch1.md)")
+	project := &binder.Project{Files: []string{"ch1.md"}, BinderDir: ".", TextPlaceholders: true}
+	out, entries, diags := Titles(context.Background(), src, project, binder.TitlesParams{Case: CaseTitle})
+	if string(out) != string(src) || entries != nil || len(diags) != 0 {
+		t.Errorf("Titles() = %q, %v, %v; want the source unchanged", out, entries, diags)
+	}
+}
+
+func TestTitles_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      []byte
+		selector string
+		wantCode string
+	}{
+		{"invalid UTF-8", []byte("\xff\xfe"), "", binder.CodeIOOrParseFailure},
+		{"parse errors", brokenBinder, "", CodeBinderHasParseErrors},
+		{"no match", binderSrc("- [one](ch1.md)"), "Missing", binder.CodeSelectorNoMatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, entries, diags := Titles(context.Background(), tt.src, nil, binder.TitlesParams{Case: CaseTitle, Selector: tt.selector})
+			if !hasDiagCode(diags, tt.wantCode) {
+				t.Errorf("diags = %v, want %s", diags, tt.wantCode)
+			}
+			if string(out) != string(tt.src) || entries != nil {
+				t.Errorf("out = %q, entries = %v; want the source unchanged", out, entries)
+			}
+		})
+	}
+}
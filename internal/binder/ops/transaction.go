@@ -0,0 +1,172 @@
+package ops
+
+import (
+	"context"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// parsed is a binder source and the result of parsing it. Operations edit
+// the result in place, so a parsed serves a single operation, or the
+// operations of one Transaction in turn.
+type parsed struct {
+	src    []byte
+	result *binder.ParseResult
+	diags  []binder.Diagnostic
+	err    error
+}
+
+// parseSrc parses src with parse, one of the operations' replaceable parse
+// functions.
+func parseSrc(ctx context.Context, parse func(context.Context, []byte, *binder.Project) (*binder.ParseResult, []binder.Diagnostic, error), src []byte, project *binder.Project) parsed {
+	result, diags, err := parse(ctx, src, project)
+	return parsed{src: src, result: result, diags: diags, err: err}
+}
+
+// output returns the binder an operation on p left: the edited result when
+// edited is set, and the source otherwise.
+func (p parsed) output(edited bool) []byte {
+	if edited {
+		return binder.Serialize(p.result)
+	}
+	return p.src
+}
+
+// Transaction applies a sequence of AddChild, Move, Delete, and
+// InsertSubtree operations to one binder, all or nothing. Each operation
+// runs against the binder the one before it left; once one reports an error
+// diagnostic the transaction has failed, later operations do nothing, and
+// Bytes returns the binder as it began.
+//
+// The binder is parsed once and the operations edit that one parse result
+// in turn, each rebuilding its tree from the edited lines for the next (see
+// binder.Reparse). Nothing is serialized until Bytes.
+type Transaction struct {
+	ctx     context.Context
+	project *binder.Project
+	src     []byte
+	cur     parsed
+	edited  bool
+	failed  bool
+	seen    map[diagnosticKey]bool
+}
+
+// diagnosticKey is a comparable form of a diagnostic: the whole of it,
+// location and suggestion included.
+type diagnosticKey struct {
+	severity, code, message string
+	location                binder.Location
+	hasLocation             bool
+	suggestion              binder.Suggestion
+	hasSuggestion           bool
+}
+
+// keyOf returns the diagnosticKey of d.
+func keyOf(d binder.Diagnostic) diagnosticKey {
+	k := diagnosticKey{severity: d.Severity, code: d.Code, message: d.Message}
+	if d.Location != nil {
+		k.location, k.hasLocation = *d.Location, true
+	}
+	if d.Suggestion != nil {
+		k.suggestion, k.hasSuggestion = *d.Suggestion, true
+	}
+	return k
+}
+
+// NewTransaction starts a transaction on the binder src.
+func NewTransaction(ctx context.Context, src []byte, project *binder.Project) *Transaction {
+	return &Transaction{
+		ctx:     ctx,
+		project: project,
+		src:     src,
+		cur:     parseSrc(ctx, binder.Parse, src, project),
+		seen:    make(map[diagnosticKey]bool),
+	}
+}
+
+// AddChild applies AddChild to the binder, returning the inserted entries
+// and the operation's diagnostics.
+func (t *Transaction) AddChild(params binder.AddChildParams) ([]binder.AffectedEntry, []binder.Diagnostic) {
+	return t.apply(func(p parsed) (bool, []int, []binder.AffectedEntry, []binder.Diagnostic) {
+		edited, lines, diags := addChild(p, t.project, params)
+		return edited, lines, nil, diags
+	})
+}
+
+// Move applies Move to the binder, returning the moved entries and the
+// operation's diagnostics.
+func (t *Transaction) Move(params binder.MoveParams) ([]binder.AffectedEntry, []binder.Diagnostic) {
+	return t.apply(func(p parsed) (bool, []int, []binder.AffectedEntry, []binder.Diagnostic) {
+		edited, lines, diags := move(p, t.project, params)
+		return edited, lines, nil, diags
+	})
+}
+
+// Delete applies Delete to the binder, returning the removed entries and
+// the operation's diagnostics.
+func (t *Transaction) Delete(params binder.DeleteParams) ([]binder.AffectedEntry, []binder.Diagnostic) {
+	return t.apply(func(p parsed) (bool, []int, []binder.AffectedEntry, []binder.Diagnostic) {
+		edited, entries, _, diags := deleteSubtrees(p, t.project, params)
+		return edited, nil, entries, diags
+	})
+}
+
+// InsertSubtree applies InsertSubtree to the binder, returning the
+// inserted entry and the operation's diagnostics.
+func (t *Transaction) InsertSubtree(params InsertSubtreeParams) ([]binder.AffectedEntry, []binder.Diagnostic) {
+	return t.apply(func(p parsed) (bool, []int, []binder.AffectedEntry, []binder.Diagnostic) {
+		edited, lines, diags := insertSubtree(p, t.project, params)
+		return edited, lines, nil, diags
+	})
+}
+
+// apply runs op, which edits the parse result in place and reports whether
+// it did, with either the line indices of the entries it affected or the
+// entries themselves. The diagnostics returned leave out any an earlier
+// operation reported, such as a parse warning about a line neither touched;
+// one at another location is reported again.
+func (t *Transaction) apply(op func(parsed) (bool, []int, []binder.AffectedEntry, []binder.Diagnostic)) ([]binder.AffectedEntry, []binder.Diagnostic) {
+	if t.failed {
+		return nil, nil
+	}
+	edited, lines, entries, opDiags := op(t.cur)
+	var diags []binder.Diagnostic
+	for _, d := range opDiags {
+		if d.Severity == "error" {
+			t.failed = true
+		}
+		if key := keyOf(d); !t.seen[key] {
+			t.seen[key] = true
+			diags = append(diags, d)
+		}
+	}
+	if t.failed || !edited {
+		return nil, diags
+	}
+	t.edited = true
+	t.cur.diags, t.cur.err = binder.Reparse(t.ctx, t.cur.result, t.project)
+	if len(lines) > 0 {
+		entries = entriesAt(t.cur.result.Root, lines)
+	}
+	return entries, diags
+}
+
+// ParseDiagnostics returns the diagnostics of parsing the binder as the
+// operations so far have left it.
+func (t *Transaction) ParseDiagnostics() []binder.Diagnostic {
+	return t.cur.diags
+}
+
+// Failed reports whether an operation has reported an error diagnostic.
+func (t *Transaction) Failed() bool {
+	return t.failed
+}
+
+// Bytes returns the binder as the operations left it, or as it began when
+// the transaction has failed.
+func (t *Transaction) Bytes() []byte {
+	if t.failed || !t.edited {
+		return t.src
+	}
+	return binder.Serialize(t.cur.result)
+}
@@ -0,0 +1,172 @@
+package ops
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+const txBinder = "<!-- prosemark-binder:v1 -->\n\n- [One](ch1.md)\n- [Two](ch2.md)\n"
+
+func txProject() *binder.Project {
+	return &binder.Project{Files: []string{"ch1.md", "ch2.md", "ch3.md"}, BinderDir: "."}
+}
+
+func TestTransaction(t *testing.T) {
+	ctx := context.Background()
+	tx := NewTransaction(ctx, []byte(txBinder), txProject())
+
+	added, diags := tx.AddChild(binder.AddChildParams{ParentSelector: "ch1.md", Target: "ch3.md", Title: "Three"})
+	if len(diags) != 0 {
+		t.Fatalf("AddChild diags = %v", diags)
+	}
+	if want := []binder.AffectedEntry{{Target: "ch3.md", Title: "Three", Line: 4, Path: []int{0, 0}}}; !reflect.DeepEqual(added, want) {
+		t.Errorf("AddChild entries = %+v, want %+v", added, want)
+	}
+
+	moved, diags := tx.Move(binder.MoveParams{SourceSelector: "ch3.md", DestinationParentSelector: ".", Position: "first", Yes: true})
+	if len(diags) != 1 || diags[0].Code != binder.CodeEmptySublistPruned {
+		t.Fatalf("Move diags = %v, want OPW004", diags)
+	}
+	if want := []binder.AffectedEntry{{Target: "ch3.md", Title: "Three", Line: 3, Path: []int{0}}}; !reflect.DeepEqual(moved, want) {
+		t.Errorf("Move entries = %+v, want %+v", moved, want)
+	}
+
+	deleted, diags := tx.Delete(binder.DeleteParams{Selector: "ch2.md", Yes: true})
+	if len(diags) != 0 || len(deleted) != 1 || deleted[0].Target != "ch2.md" {
+		t.Errorf("Delete = %+v, %v", deleted, diags)
+	}
+
+	if tx.Failed() {
+		t.Fatal("Failed after successful operations")
+	}
+	want := "<!-- prosemark-binder:v1 -->\n\n- [Three](ch3.md)\n- [One](ch1.md)\n"
+	if got := string(tx.Bytes()); got != want {
+		t.Errorf("Bytes = %q, want %q", got, want)
+	}
+
+	// The transaction matches the operations run one after another.
+	seq, _ := AddChild(ctx, []byte(txBinder), txProject(), binder.AddChildParams{ParentSelector: "ch1.md", Target: "ch3.md", Title: "Three"})
+	seq, _ = Move(ctx, seq, txProject(), binder.MoveParams{SourceSelector: "ch3.md", DestinationParentSelector: ".", Position: "first", Yes: true})
+	seq, _ = Delete(ctx, seq, txProject(), binder.DeleteParams{Selector: "ch2.md", Yes: true})
+	if string(seq) != want {
+		t.Errorf("sequential operations = %q, want %q", seq, want)
+	}
+}
+
+func TestTransaction_FailureIsAllOrNothing(t *testing.T) {
+	tx := NewTransaction(context.Background(), []byte(txBinder), txProject())
+	if _, diags := tx.Delete(binder.DeleteParams{Selector: "ch1.md", Yes: true}); len(diags) != 0 {
+		t.Fatalf("Delete diags = %v", diags)
+	}
+	_, diags := tx.Move(binder.MoveParams{SourceSelector: "ch1.md", DestinationParentSelector: ".", Yes: true})
+	if len(diags) != 1 || diags[0].Code != binder.CodeSelectorNoMatch {
+		t.Errorf("Move diags = %v, want OPE001", diags)
+	}
+	if !tx.Failed() {
+		t.Error("Failed = false after an error")
+	}
+	if entries, diags := tx.AddChild(binder.AddChildParams{ParentSelector: ".", Target: "ch3.md"}); entries != nil || diags != nil {
+		t.Errorf("AddChild after failure = %v, %v; want nothing", entries, diags)
+	}
+	if got := string(tx.Bytes()); got != txBinder {
+		t.Errorf("Bytes = %q, want the binder unchanged", got)
+	}
+}
+
+func TestTransaction_ReportsRepeatedDiagnosticsOnce(t *testing.T) {
+	src := txBinder + "- [Gone](gone.md)\n"
+	tx := NewTransaction(context.Background(), []byte(src), txProject())
+	_, first := tx.Move(binder.MoveParams{SourceSelector: "ch2.md", DestinationParentSelector: ".", Position: "first", Yes: true})
+	_, second := tx.Move(binder.MoveParams{SourceSelector: "ch1.md", DestinationParentSelector: ".", Position: "first", Yes: true})
+	if len(first) != 1 || first[0].Code != binder.CodeMissingTargetFile {
+		t.Errorf("first diags = %v, want the missing file warning", first)
+	}
+	if len(second) != 0 {
+		t.Errorf("second diags = %v, want the warning not repeated", second)
+	}
+}
+
+func TestTransaction_ReportsDiagnosticsAtEachLocation(t *testing.T) {
+	src := txBinder + "\nSee [One](ch1.md).\n\nAnd [Two](ch2.md).\n"
+	tx := NewTransaction(context.Background(), []byte(src), txProject())
+	_, first := tx.Move(binder.MoveParams{SourceSelector: "ch2.md", DestinationParentSelector: ".", Position: "first", Yes: true})
+	var lines []int
+	for _, d := range first {
+		if d.Code == binder.CodeLinkOutsideList && d.Location != nil {
+			lines = append(lines, d.Location.Line)
+		}
+	}
+	if want := []int{6, 8}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("first diags = %v, want the link warning on lines %v", first, want)
+	}
+
+	// An operation reports the warnings of the binder it ran against, where
+	// the move left the links where they were.
+	if _, second := tx.AddChild(binder.AddChildParams{ParentSelector: ".", Target: "ch3.md", Position: "first"}); len(second) != 0 {
+		t.Errorf("second diags = %v, want the link warnings not repeated", second)
+	}
+	if _, third := tx.Delete(binder.DeleteParams{Selector: "ch2.md", Yes: true}); len(third) != 2 {
+		t.Errorf("third diags = %v, want the link warnings again at their new lines", third)
+	}
+}
+
+func TestTransaction_NoEdit(t *testing.T) {
+	tx := NewTransaction(context.Background(), []byte(txBinder), txProject())
+	entries, diags := tx.AddChild(binder.AddChildParams{ParentSelector: ".", Target: "ch1.md"})
+	if entries != nil || len(diags) != 1 || diags[0].Code != binder.CodeDuplicateSkipped {
+		t.Errorf("AddChild = %v, %v; want only OPW002", entries, diags)
+	}
+	if tx.Failed() {
+		t.Error("Failed after a skipped add")
+	}
+	if got := string(tx.Bytes()); got != txBinder {
+		t.Errorf("Bytes = %q, want the binder unchanged", got)
+	}
+}
+
+func TestKeyOf(t *testing.T) {
+	base := binder.Diagnostic{Severity: "warning", Code: binder.CodeCaseInsensitiveMatch, Message: "case"}
+	at := func(line int) *binder.Location { return &binder.Location{Line: line} }
+	fix := func(to string) *binder.Suggestion { return &binder.Suggestion{Replacement: to} }
+	tests := []struct {
+		name string
+		a, b binder.Diagnostic
+		same bool
+	}{
+		{"equal", base, base, true},
+		{"equal locations", binder.Diagnostic{Location: at(3)}, binder.Diagnostic{Location: at(3)}, true},
+		{"other location", binder.Diagnostic{Location: at(3)}, binder.Diagnostic{Location: at(4)}, false},
+		{"no location", binder.Diagnostic{}, binder.Diagnostic{Location: &binder.Location{}}, false},
+		{"equal suggestions", binder.Diagnostic{Suggestion: fix("a")}, binder.Diagnostic{Suggestion: fix("a")}, true},
+		{"other suggestion", binder.Diagnostic{Suggestion: fix("a")}, binder.Diagnostic{Suggestion: fix("b")}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keyOf(tt.a) == keyOf(tt.b); got != tt.same {
+				t.Errorf("keyOf(%+v) == keyOf(%+v) is %v, want %v", tt.a, tt.b, got, tt.same)
+			}
+		})
+	}
+}
+
+func TestTransaction_InsertSubtree(t *testing.T) {
+	tx := NewTransaction(context.Background(), []byte(txBinder), txProject())
+	sub := Subtree{Lines: []string{"- [Three](ch3.md)", "  - [Gone](gone.md)"}}
+	inserted, diags := tx.InsertSubtree(InsertSubtreeParams{ParentPath: []int{1}, Subtree: sub})
+	if len(diags) != 0 {
+		t.Fatalf("InsertSubtree diags = %v", diags)
+	}
+	if want := []binder.AffectedEntry{{Target: "ch3.md", Title: "Three", Line: 5, Path: []int{1, 0}}}; !reflect.DeepEqual(inserted, want) {
+		t.Errorf("InsertSubtree entries = %+v, want %+v", inserted, want)
+	}
+	if got := tx.ParseDiagnostics(); len(got) != 1 || got[0].Code != binder.CodeMissingTargetFile {
+		t.Errorf("ParseDiagnostics = %v, want BNDW004 for gone.md", got)
+	}
+	want := txBinder + "  - [Three](ch3.md)\n    - [Gone](gone.md)\n"
+	if got := string(tx.Bytes()); got != want {
+		t.Errorf("Bytes = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,111 @@
+package binder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Operation names in OpSpec.Operation.
+const (
+	OpAdd    = "add"
+	OpDelete = "delete"
+	OpMove   = "move"
+)
+
+// opSpecPosition holds the op.json spellings of an insertion point that
+// the params types spell differently: position "at" with positionIndex, and
+// position "before" or "after" with positionSelector.
+type opSpecPosition struct {
+	PositionIndex    *int   `json:"positionIndex"`
+	PositionSelector string `json:"positionSelector"`
+}
+
+// apply sets the params fields position, at, before, and after from a's
+// spellings, unless the params already spell them their own way.
+func (a opSpecPosition) apply(position *string, at **int, before, after *string) {
+	if *at == nil && a.PositionIndex != nil {
+		*at = a.PositionIndex
+		if *position == "at" {
+			*position = ""
+		}
+	}
+	if *before != "" || *after != "" || a.PositionSelector == "" {
+		return
+	}
+	switch *position {
+	case "before":
+		*before, *position = a.PositionSelector, ""
+	case "after":
+		*after, *position = a.PositionSelector, ""
+	}
+}
+
+// NewOpSpec returns the op.json spec of operation with params, an
+// AddChildParams, DeleteParams, or MoveParams.
+func NewOpSpec(operation string, params any) OpSpec {
+	// The params types hold only strings, bools, and ints, which always encode.
+	data, _ := json.Marshal(params)
+	return OpSpec{Version: "1", Operation: operation, Params: data}
+}
+
+// ParseOpSpecs decodes a batch of operations: a JSON array of op.json
+// objects. Each spec's parameters are checked as DecodeParams does.
+func ParseOpSpecs(data []byte) ([]OpSpec, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '[' {
+		return nil, fmt.Errorf("want a JSON array of operations")
+	}
+	var specs []OpSpec
+	if err := json.Unmarshal(data, &specs); err != nil {
+		return nil, err
+	}
+	for i, spec := range specs {
+		if _, err := spec.DecodeParams(); err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i+1, err)
+		}
+	}
+	return specs, nil
+}
+
+// DecodeParams decodes s.Params into the parameters of s.Operation: an
+// AddChildParams, DeleteParams, or MoveParams.
+func (s OpSpec) DecodeParams() (any, error) {
+	if s.Version != "" && s.Version != "1" {
+		return nil, fmt.Errorf("unsupported op.json version %q", s.Version)
+	}
+	params := s.Params
+	if len(params) == 0 {
+		params = json.RawMessage("{}")
+	}
+	switch s.Operation {
+	case OpAdd:
+		var p struct {
+			AddChildParams
+			opSpecPosition
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, fmt.Errorf("parsing %s params: %w", s.Operation, err)
+		}
+		p.apply(&p.Position, &p.At, &p.Before, &p.After)
+		return p.AddChildParams, nil
+	case OpDelete:
+		var p DeleteParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, fmt.Errorf("parsing %s params: %w", s.Operation, err)
+		}
+		return p, nil
+	case OpMove:
+		var p struct {
+			MoveParams
+			opSpecPosition
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, fmt.Errorf("parsing %s params: %w", s.Operation, err)
+		}
+		p.apply(&p.Position, &p.At, &p.Before, &p.After)
+		return p.MoveParams, nil
+	default:
+		return nil, fmt.Errorf("unknown operation %q", s.Operation)
+	}
+}
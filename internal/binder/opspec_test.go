@@ -0,0 +1,98 @@
+package binder_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestOpSpec_DecodeParams(t *testing.T) {
+	two := 2
+	tests := []struct {
+		name string
+		spec string
+		want any
+	}{
+		{
+			"add",
+			`{"version":"1","operation":"add","params":{"parentSelector":".","target":"new.md","title":"New","position":"first"}}`,
+			binder.AddChildParams{ParentSelector: ".", Target: "new.md", Title: "New", Position: "first"},
+		},
+		{
+			"add positionIndex",
+			`{"version":"1","operation":"add","params":{"parentSelector":".","target":"new.md","position":"at","positionIndex":2}}`,
+			binder.AddChildParams{ParentSelector: ".", Target: "new.md", At: &two},
+		},
+		{
+			"add positionSelector",
+			`{"version":"1","operation":"add","params":{"parentSelector":".","target":"new.md","position":"before","positionSelector":"ch3.md"}}`,
+			binder.AddChildParams{ParentSelector: ".", Target: "new.md", Before: "ch3.md"},
+		},
+		{
+			"delete",
+			`{"version":"1","operation":"delete","params":{"selector":"ch1.md","yes":true}}`,
+			binder.DeleteParams{Selector: "ch1.md", Yes: true},
+		},
+		{
+			"move positionSelector",
+			`{"version":"1","operation":"move","params":{"sourceSelector":"ch1.md","destinationParentSelector":".","position":"after","positionSelector":"ch2.md","yes":true}}`,
+			binder.MoveParams{SourceSelector: "ch1.md", DestinationParentSelector: ".", After: "ch2.md", Yes: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			specs, err := binder.ParseOpSpecs([]byte("[" + tt.spec + "]"))
+			if err != nil {
+				t.Fatalf("ParseOpSpecs: %v", err)
+			}
+			got, err := specs[0].DecodeParams()
+			if err != nil {
+				t.Fatalf("DecodeParams: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("params = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOpSpecs_Errors(t *testing.T) {
+	tests := []struct {
+		name, data, wantErr string
+	}{
+		{"not an array", `{"version":"1","operation":"add"}`, "want a JSON array of operations"},
+		{"malformed", `[{"version":`, "unexpected end of JSON input"},
+		{"unknown operation", `[{"version":"1","operation":"add","params":{}},{"version":"1","operation":"shred"}]`, `operation 2: unknown operation "shred"`},
+		{"version", `[{"version":"2","operation":"add"}]`, `operation 1: unsupported op.json version "2"`},
+		{"bad params", `[{"version":"1","operation":"delete","params":{"yes":"sure"}}]`, "operation 1: parsing delete params"},
+		{"bad add params", `[{"version":"1","operation":"add","params":{"force":"sure"}}]`, "operation 1: parsing add params"},
+		{"bad move params", `[{"version":"1","operation":"move","params":{"yes":"sure"}}]`, "operation 1: parsing move params"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := binder.ParseOpSpecs([]byte(tt.data)); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewOpSpec_RoundTrips(t *testing.T) {
+	at := 2
+	tests := []struct {
+		op     string
+		params any
+	}{
+		{binder.OpAdd, binder.AddChildParams{ParentSelector: ".", Target: "ch.md", Title: "Ch", Position: "first", At: &at, Style: "inline"}},
+		{binder.OpDelete, binder.DeleteParams{Selector: "ch.md", Yes: true}},
+		{binder.OpMove, binder.MoveParams{SourceSelector: "ch.md", DestinationParentSelector: ".", Before: "b.md", Yes: true, PreserveExtras: true}},
+	}
+	for _, tt := range tests {
+		got, err := binder.NewOpSpec(tt.op, tt.params).DecodeParams()
+		if err != nil || !reflect.DeepEqual(got, tt.params) {
+			t.Errorf("NewOpSpec(%s).DecodeParams() = %+v, %v; want %+v", tt.op, got, err, tt.params)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package binder
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// outlineLinkRE matches inline links and wikilinks inside outline item text
+	// so titles can be reduced to their display text.
+	outlineLinkRE = regexp.MustCompile(`\[\[([^\]|]+)(?:\|([^\]]*))?\]\]|\[((?:[^\]\\]|\\.)*)\]\([^)]*\)`)
+	// outlineTargetRE matches an inline link at the start of outline item text.
+	outlineTargetRE = regexp.MustCompile(`^\[((?:[^\]\\]|\\.)*)\]\(([^)\s]+)\)`)
+	// outlineBarePathRE matches outline item text that is just a .md path.
+	outlineBarePathRE = regexp.MustCompile(`^\S+\.md$`)
+)
+
+// OutlineItem is one entry of a plain nested-list outline (see ParseOutline).
+type OutlineItem struct {
+	Text     string         // original item text, checkbox removed, continuation lines joined
+	Children []*OutlineItem // nested items, in document order
+}
+
+// Title returns the item's text with inline links and wikilinks reduced to
+// their display text, suitable for use as a node title.
+func (o *OutlineItem) Title() string {
+	return strings.TrimSpace(outlineLinkRE.ReplaceAllStringFunc(o.Text, func(m string) string {
+		sm := outlineLinkRE.FindStringSubmatch(m)
+		switch {
+		case sm[2] != "":
+			return sm[2]
+		case sm[1] != "":
+			return sm[1]
+		default:
+			return unescapeTitle(sm[3])
+		}
+	}))
+}
+
+// Link returns the target and title an item names explicitly: a leading inline
+// link yields its destination and text, and text that is a bare .md path
+// yields that path with an empty title. Other items return ("", "").
+func (o *OutlineItem) Link() (target, title string) {
+	if m := outlineTargetRE.FindStringSubmatch(o.Text); m != nil {
+		return m[2], unescapeTitle(m[1])
+	}
+	if outlineBarePathRE.MatchString(o.Text) {
+		return o.Text, ""
+	}
+	return "", ""
+}
+
+// ParseOutline reads a plain Markdown outline — nested list items of arbitrary
+// text, with no pragma or links required — and returns its top-level items.
+// Nesting follows indentation (a tab counts as four columns). Indented
+// non-list lines continue the preceding item; other non-list lines, blank
+// lines, and fenced code blocks are ignored.
+func ParseOutline(src []byte) []*OutlineItem {
+	type stackEntry struct {
+		indent int
+		item   *OutlineItem
+	}
+	root := &OutlineItem{}
+	stack := []stackEntry{{indent: -1, item: root}}
+	var last *OutlineItem
+	lastIndent := -1
+	fenceMarker := ""
+
+	for _, line := range strings.Split(strings.ReplaceAll(string(src), "\r\n", "\n"), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if fenceMarker != "" {
+			if strings.HasPrefix(strings.TrimSpace(line), fenceMarker) {
+				fenceMarker = ""
+			}
+			continue
+		}
+		if marker := openFenceMarker(strings.TrimSpace(line)); marker != "" {
+			fenceMarker, last = marker, nil
+			continue
+		}
+
+		m := listItemRE.FindStringSubmatch(line)
+		if m == nil {
+			text := strings.TrimSpace(line)
+			if last != nil && text != "" && outlineIndentWidth(line) > lastIndent {
+				last.Text += " " + text
+				continue
+			}
+			last = nil
+			continue
+		}
+
+		text := strings.TrimSpace(checkboxRE.ReplaceAllString(strings.TrimSpace(m[3]), ""))
+		if text == "" {
+			last = nil
+			continue
+		}
+		indent := outlineIndentWidth(m[1])
+		for len(stack) > 1 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		item := &OutlineItem{Text: text}
+		parent := stack[len(stack)-1].item
+		parent.Children = append(parent.Children, item)
+		stack = append(stack, stackEntry{indent: indent, item: item})
+		last, lastIndent = item, indent
+	}
+	return root.Children
+}
+
+// outlineIndentWidth returns the column width of s's leading whitespace,
+// counting a tab as four columns.
+func outlineIndentWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+	return width
+}
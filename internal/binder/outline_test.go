@@ -0,0 +1,77 @@
+package binder_test
+
+import (
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestParseOutline_Hierarchy(t *testing.T) {
+	src := []byte("# My Novel\n\n" +
+		"- Part One\n" +
+		"  - [ ] Chapter 1: the *storm*\n" +
+		"    continues here\n" +
+		"  - Chapter 2\n" +
+		"\t- Scene in tabs\n" +
+		"1. Part Two\n" +
+		"```\n- not an item\n```\n" +
+		"- \n" +
+		"-  \n" +
+		"Closing paragraph.\n")
+
+	items := binder.ParseOutline(src)
+
+	if len(items) != 2 {
+		t.Fatalf("top-level items = %d, want 2", len(items))
+	}
+	partOne := items[0]
+	if partOne.Text != "Part One" || len(partOne.Children) != 2 {
+		t.Fatalf("Part One = %+v", partOne)
+	}
+	if got, want := partOne.Children[0].Text, "Chapter 1: the *storm* continues here"; got != want {
+		t.Errorf("continuation text = %q, want %q", got, want)
+	}
+	ch2 := partOne.Children[1]
+	if len(ch2.Children) != 1 || ch2.Children[0].Text != "Scene in tabs" {
+		t.Errorf("Chapter 2 children = %+v, want [Scene in tabs]", ch2.Children)
+	}
+	if items[1].Text != "Part Two" || len(items[1].Children) != 0 {
+		t.Errorf("Part Two = %+v, want leaf", items[1])
+	}
+}
+
+func TestOutlineItem_Title(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Plain title", "Plain title"},
+		{"See [the map](map.png) first", "See the map first"},
+		{"Visit [[Harbor|the harbor]] and [[Town]]", "Visit the harbor and Town"},
+		{`Escaped [a \] b](x.md)`, "Escaped a ] b"},
+	}
+	for _, tt := range tests {
+		if got := (&binder.OutlineItem{Text: tt.text}).Title(); got != tt.want {
+			t.Errorf("Title(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestOutlineItem_Link(t *testing.T) {
+	tests := []struct {
+		text       string
+		wantTarget string
+		wantTitle  string
+	}{
+		{"[Chapter \\[1\\]](ch1.md) extra words", "ch1.md", "Chapter [1]"},
+		{"notes/scene.md", "notes/scene.md", ""},
+		{"Plain words", "", ""},
+		{"two words.md", "", ""},
+	}
+	for _, tt := range tests {
+		target, title := (&binder.OutlineItem{Text: tt.text}).Link()
+		if target != tt.wantTarget || title != tt.wantTitle {
+			t.Errorf("Link(%q) = (%q, %q), want (%q, %q)", tt.text, target, title, tt.wantTarget, tt.wantTitle)
+		}
+	}
+}
@@ -22,6 +22,8 @@ func TestParseLink(t *testing.T) {
 		{"regular inline link with tooltip", `[T](x.md "Tip")`, "x.md", "T", true, 0},
 		{"unrecognised text", "just text", "", "", false, 0},
 		{"ref link absent", "[Title][nonexistent-ref]", "", "", false, 0},
+		{"inline link with trailing comment", "[T](x.md) <!-- 12 words -->", "x.md", "T", true, 0},
+		{"wikilink with trailing comments", "[[x]] <!-- 12 words --> <!-- todo -->", "x.md", "x", true, 0},
 	}
 
 	for _, tt := range tests {
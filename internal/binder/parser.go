@@ -3,15 +3,24 @@ package binder
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
+	"path"
 	"regexp"
+	"sort"
 	"strings"
 	"unicode/utf8"
 )
 
 var (
-	pragmaRE             = regexp.MustCompile(`<\\?!--\s*prosemark-binder:v1\s*-->`)
+	pragmaRE = regexp.MustCompile(`<\\?!--\s*prosemark-binder:v1\s*-->`)
+	// pragmaNearMissRE matches comments that look like a binder pragma but are
+	// not the exact v1 form (other casing, separators, spacing, or version).
+	pragmaNearMissRE = regexp.MustCompile(`(?i)<\\?!--\s*prosemark[-_ ]?binder\b(.*?)-->`)
+	pragmaVersionRE  = regexp.MustCompile(`(?i)\bv(\d+)\b`)
+	// atxHeadingRE matches an ATX heading: group 1 is the marker, group 2 the text.
+	atxHeadingRE         = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
 	linkRE               = regexp.MustCompile(`\[[^\]]*\]\([^)]*\)`)
 	listItemRE           = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.+)`)
 	emptyTargetLinkRE    = regexp.MustCompile(`^\[((?:[^\]\\]|\\.)*)\]\(\s*\)`)
@@ -29,60 +38,180 @@ var (
 	allInlineLinkRE = regexp.MustCompile(`\[((?:[^\]\\]|\\.)*)\]\(([^)"]+)(?:\s+"[^"]*")?\s*\)`)
 )
 
+// trailingCommentsRE matches the HTML comments, and the whitespace around
+// them, that end a line.
+var trailingCommentsRE = regexp.MustCompile(`(?:\s*<!--(?:[^-]|-[^-])*-->)+\s*$`)
+
+// StripTrailingComments returns line without the HTML comments that end it.
+// Such comments annotate a list item (pmk annotate-tree writes word counts
+// there) and are never part of its structural link or title.
+func StripTrailingComments(line string) string {
+	return trailingCommentsRE.ReplaceAllString(line, "")
+}
+
 // wikilinkEntry holds a project file path and its directory depth (number of "/" separators).
+// alias is set for entries indexed under a frontmatter alias rather than a path stem;
+// nearBinder is set for files in the binder's own directory when binder-relative
+// resolution is in effect.
 type wikilinkEntry struct {
-	file  string
-	depth int
+	file       string
+	depth      int
+	alias      bool
+	nearBinder bool
 }
 
 // Parse parses a binder file and returns a ParseResult, diagnostics, and any fatal error.
-// project may be nil.
+// project may be nil; its SeverityOverrides are applied to the diagnostics.
 func Parse(ctx context.Context, src []byte, project *Project) (*ParseResult, []Diagnostic, error) {
-	_ = ctx
+	result, diags, err := parse(ctx, src, project)
+	if project != nil {
+		diags = ApplySeverityOverrides(diags, project.SeverityOverrides)
+	}
+	return result, diags, err
+}
 
+// Reparse rebuilds the tree of r from its Lines and LineEnds once an
+// operation has edited them in place. The result and diagnostics are those
+// Parse gives for Serialize(r), without serializing and splitting the lines
+// again when they are already as Parse would split them.
+func Reparse(ctx context.Context, r *ParseResult, project *Project) ([]Diagnostic, error) {
+	if !splitCanonical(r.Lines, r.LineEnds) {
+		fresh, diags, err := Parse(ctx, Serialize(r), project)
+		*r = *fresh
+		return diags, err
+	}
+	size := 0
+	if r.HasBOM {
+		size = len(utf8BOM)
+	}
+	valid := true
+	for i, line := range r.Lines {
+		size += len(line) + len(r.LineEnds[i])
+		valid = valid && utf8.ValidString(line)
+	}
+	fresh := newParseResult()
+	limits := project.parseLimits()
 	var diags []Diagnostic
+	var err error
+	switch {
+	case size > limits.MaxFileSize:
+		diags = []Diagnostic{limitDiagnostic(fmt.Sprintf("binder size (%d bytes)", size), "max_file_size", limits.MaxFileSize, 0)}
+	case !valid:
+		err = errInvalidUTF8
+	default:
+		fresh.HasBOM, fresh.Lines, fresh.LineEnds = r.HasBOM, r.Lines, r.LineEnds
+		if r.HasBOM {
+			diags = append(diags, bomDiagnostic())
+		}
+		diags = parseLines(fresh, diags, project, limits)
+	}
+	*r = *fresh
+	if project != nil {
+		diags = ApplySeverityOverrides(diags, project.SeverityOverrides)
+	}
+	return diags, err
+}
+
+// splitCanonical reports whether lines and ends are as splitLines splits
+// the bytes they serialize to: no line holds a line break, every line but
+// the last has an ending, the last is not empty without one, and no "\r"
+// ending is followed by an empty line the "\n" of which would join it.
+func splitCanonical(lines, ends []string) bool {
+	for i, line := range lines {
+		if strings.ContainsAny(line, "\r\n") {
+			return false
+		}
+		last := i == len(lines)-1
+		if ends[i] == "" && (!last || line == "") {
+			return false
+		}
+		if ends[i] == "\r" && !last && lines[i+1] == "" && strings.HasPrefix(ends[i+1], "\n") {
+			return false
+		}
+	}
+	return true
+}
+
+// errInvalidUTF8 is the error for a binder that is not valid UTF-8.
+var errInvalidUTF8 = errors.New("binder file contains invalid UTF-8 content")
 
-	result := &ParseResult{
+// newParseResult returns a ParseResult with an empty tree.
+func newParseResult() *ParseResult {
+	return &ParseResult{
 		Version: "1",
 		Root: &Node{
 			Type:     "root",
 			Children: []*Node{},
 		},
 	}
+}
+
+// bomDiagnostic is the BNDW005 warning about a UTF-8 byte order mark.
+func bomDiagnostic() Diagnostic {
+	return Diagnostic{
+		Severity: "warning",
+		Code:     CodeBOMPresence,
+		Message:  "UTF-8 BOM detected",
+		Suggestion: &Suggestion{
+			Message: "remove the byte order mark",
+			Range:   Range{Start: 0, End: len(utf8BOM)},
+		},
+	}
+}
+
+// parse implements Parse before severity overrides.
+func parse(ctx context.Context, src []byte, project *Project) (*ParseResult, []Diagnostic, error) {
+	_ = ctx
+
+	var diags []Diagnostic
+
+	result := newParseResult()
+
+	limits := project.parseLimits()
+	if len(src) > limits.MaxFileSize {
+		return result, []Diagnostic{limitDiagnostic(fmt.Sprintf("binder size (%d bytes)", len(src)), "max_file_size", limits.MaxFileSize, 0)}, nil
+	}
 
 	// Reject non-UTF-8 content before any processing.
 	if !utf8.Valid(src) {
-		return result, nil, fmt.Errorf("binder file contains invalid UTF-8 content")
+		return result, nil, errInvalidUTF8
 	}
 
 	// Strip UTF-8 BOM if present.
 	if bytes.HasPrefix(src, []byte(utf8BOM)) {
 		result.HasBOM = true
 		src = src[3:]
-		diags = append(diags, Diagnostic{
-			Severity: "warning",
-			Code:     CodeBOMPresence,
-			Message:  "UTF-8 BOM detected",
-		})
+		diags = append(diags, bomDiagnostic())
 	}
 
 	// Split into lines, recording endings per line.
 	result.Lines, result.LineEnds = splitLines(src)
+	return result, parseLines(result, diags, project, limits), nil
+}
+
+// parseLines builds the tree of result from its Lines, appending its
+// diagnostics to diags.
+func parseLines(result *ParseResult, diags []Diagnostic, project *Project, limits ParseLimits) []Diagnostic {
+	for i, line := range result.Lines {
+		if len(line) > limits.MaxLineLength {
+			diags = append(diags, limitDiagnostic(fmt.Sprintf("line length (%d bytes)", len(line)), "max_line_length", limits.MaxLineLength, i+1))
+			return diags
+		}
+	}
 
 	// Pass 1: track fences, detect pragma, collect ref defs, warn on links in fences.
-	p1 := pass1Scan(result.Lines)
+	p1 := pass1Scan(result.Lines, limits.MaxRefDefs)
 	result.HasPragma = p1.hasPragma
 	result.PragmaLine = p1.pragmaLine
 	result.RefDefs = p1.refDefs
 	diags = append(diags, p1.diags...)
+	if p1.refDefLimitLine > 0 {
+		diags = append(diags, limitDiagnostic("reference definition count", "max_ref_defs", limits.MaxRefDefs, p1.refDefLimitLine))
+	}
 
 	// Emit BNDW001 if no effective pragma found and file has content (or no project context).
 	if !result.HasPragma && (project == nil || len(result.Lines) > 0) {
-		diags = append(diags, Diagnostic{
-			Severity: "warning",
-			Code:     CodeMissingPragma,
-			Message:  "Missing binder pragma: file has content but does not begin with <!-- prosemark-binder:v1 -->",
-		})
+		diags = append(diags, missingPragmaDiagnostic(p1))
 	}
 
 	// Build O(1) wikilink basename index and project file set.
@@ -91,9 +220,15 @@ func Parse(ctx context.Context, src []byte, project *Project) (*ParseResult, []D
 	projectFilesLower := buildProjectFilesLower(project)
 
 	binderDir := ""
+	refSections := make(map[string]bool)
 	if project != nil {
 		binderDir = project.BinderDir
+		for _, h := range project.ReferenceSections {
+			refSections[strings.ToLower(strings.TrimSpace(h))] = true
+		}
 	}
+	// refLevel is the heading level of the enclosing reference section (0 when none).
+	refLevel := 0
 
 	// Pass 2: scan list items, build node tree, emit structural diagnostics.
 	type stackEntry struct {
@@ -103,12 +238,23 @@ func Parse(ctx context.Context, src []byte, project *Project) (*ParseResult, []D
 	stack := []stackEntry{{indent: -1, node: result.Root}}
 	seenTargets := make(map[string]bool)
 
-	inFence := false
-	fenceMarker := ""
+	// fence is the open fenced code block, nil outside one.
+	var fence *Fence
 
 	// consumed tracks lines already processed as list item continuations.
 	consumed := make(map[int]bool)
 
+	// nodeCount counts tree nodes and fenced pseudo-nodes against MaxNodes.
+	nodeCount := 0
+	nodeLimitHit := func(lineNum int) bool {
+		if nodeCount < limits.MaxNodes {
+			nodeCount++
+			return false
+		}
+		diags = append(diags, limitDiagnostic("node count", "max_nodes", limits.MaxNodes, lineNum))
+		return true
+	}
+
 	for i, line := range result.Lines {
 		lineNum := i + 1
 
@@ -116,28 +262,55 @@ func Parse(ctx context.Context, src []byte, project *Project) (*ParseResult, []D
 			continue
 		}
 
-		if !inFence {
+		if fence == nil {
 			if marker := openFenceMarker(line); marker != "" {
-				inFence, fenceMarker = true, marker
+				fence = &Fence{Marker: marker, StartLine: lineNum, EndLine: len(result.Lines)}
+				result.Fences = append(result.Fences, fence)
 				continue
 			}
 		} else {
-			if strings.HasPrefix(line, fenceMarker) {
-				inFence, fenceMarker = false, ""
+			if strings.HasPrefix(line, fence.Marker) {
+				fence.EndLine, fence.Closed = lineNum, true
+				fence = nil
+			} else if fenced := parseFencedPseudoNode(line, lineNum, result.RefDefs, wikiIndex, binderDir); fenced != nil {
+				if nodeLimitHit(lineNum) {
+					break
+				}
+				fenced.Fence = fence
+				result.Fenced = append(result.Fenced, fenced)
 			}
 			continue
 		}
 
 		m := listItemRE.FindStringSubmatch(line)
 		if m == nil {
+			// A heading ends any reference section of the same or a higher
+			// level, and may open a new one.
+			if h := atxHeadingRE.FindStringSubmatch(line); h != nil {
+				if level := len(h[1]); refLevel == 0 || level <= refLevel {
+					refLevel = 0
+					if refSections[strings.ToLower(h[2])] {
+						refLevel = level
+					}
+				}
+			}
 			// Not a list item: check for .md inline links or placeholder links outside lists (BNDW006).
 			if mdInlineLinkRE.MatchString(line) || anyEmptyTargetLinkRE.MatchString(line) {
-				diags = append(diags, Diagnostic{
-					Severity: "warning",
-					Code:     CodeLinkOutsideList,
-					Message:  "markdown link to .md file found outside list item",
-					Location: &Location{Line: lineNum},
-				})
+				if refLevel > 0 {
+					diags = append(diags, Diagnostic{
+						Severity: SeverityInfo,
+						Code:     CodeReferenceSectionLink,
+						Message:  "markdown link to .md file in a reference section is a prose cross-reference, not a binder entry",
+						Location: &Location{Line: lineNum},
+					})
+				} else {
+					diags = append(diags, Diagnostic{
+						Severity: "warning",
+						Code:     CodeLinkOutsideList,
+						Message:  "markdown link to .md file found outside list item",
+						Location: &Location{Line: lineNum},
+					})
+				}
 			}
 			continue
 		}
@@ -150,21 +323,30 @@ func Parse(ctx context.Context, src []byte, project *Project) (*ParseResult, []D
 		// Column of the content start within the line (1-based).
 		listItemColumn := len(m[0]) - len(rawContent) + 1
 
+		checked := parseCheckbox(content)
 		content = normalizeListContent(content)
 
 		target, title, found, linkDiags := parseLink(content, result.RefDefs, wikiIndex, binderDir, lineNum, listItemColumn)
 
+		// Diagnostics about the link are reported where the link was found,
+		// which is the continuation line when that line is consumed for it.
+		linkLine, linkColumn, linkContent := lineNum, listItemColumn, content
+		endLine := lineNum
+
 		// If no link found in content, check the immediately following continuation line.
 		if !found && i+1 < len(result.Lines) {
 			nextLine := result.Lines[i+1]
 			// A continuation line has more indentation than the list marker level.
 			if countLeadingWhitespace(nextLine) > indent && !listItemRE.MatchString(nextLine) {
 				contContent := normalizeListContent(strings.TrimSpace(nextLine))
-				t, ti, tFound, ld := parseLink(contContent, result.RefDefs, wikiIndex, binderDir, i+2, 0)
+				contColumn := countLeadingWhitespace(nextLine) + 1
+				t, ti, tFound, ld := parseLink(contContent, result.RefDefs, wikiIndex, binderDir, i+2, contColumn)
 				consumed[i+1] = true
+				endLine = i + 2
+				linkDiags = append(linkDiags, ld...)
 				if tFound {
 					found = true
-					linkDiags = ld
+					linkLine, linkColumn, linkContent = i+2, contColumn, contContent
 					if t != "" {
 						target, title = t, ti
 					} else {
@@ -175,14 +357,25 @@ func Parse(ctx context.Context, src []byte, project *Project) (*ParseResult, []D
 		}
 
 		// Emit link-resolution diagnostics (BNDE003, BNDW009).
+		for j, d := range linkDiags {
+			if d.Code == CodeCaseInsensitiveMatch && d.Location != nil {
+				linkDiags[j].Suggestion = wikilinkCaseFix(result, d.Location.Line, target)
+			}
+		}
 		diags = append(diags, linkDiags...)
 
-		// Skip items with no resolved target.
+		// Skip items with no resolved target, unless the project reads
+		// plain-text items as placeholders.
+		nodeType := "node"
 		if !found {
-			continue
+			text := strings.TrimSpace(StripTrailingComments(content))
+			if project == nil || !project.TextPlaceholders || len(linkDiags) > 0 || text == "" {
+				continue
+			}
+			nodeType, title = "placeholder", text
 		}
 
-		// Placeholder node: found=true with empty target (e.g. [Title]() or []()).
+		// Placeholder node: empty target (e.g. [Title](), [](), or plain text).
 		isPlaceholder := target == ""
 
 		// Handle non-md first link: if target is non-md, look for an md link elsewhere.
@@ -192,10 +385,11 @@ func Parse(ctx context.Context, src []byte, project *Project) (*ParseResult, []D
 				Severity: "warning",
 				Code:     CodeNonMarkdownTarget,
 				Message:  "Link target is not a .md file",
-				Location: &Location{Line: lineNum},
+				Location: &Location{Line: linkLine},
+				Target:   target,
 			})
 			// Search for an md link elsewhere in the content.
-			mdTarget, mdTitle := findFirstMdLink(content)
+			mdTarget, mdTitle := findFirstMdLink(linkContent)
 			if mdTarget == "" {
 				continue
 			}
@@ -210,7 +404,8 @@ func Parse(ctx context.Context, src []byte, project *Project) (*ParseResult, []D
 					Severity: "error",
 					Code:     CodeIllegalPathChars,
 					Message:  fmt.Sprintf("Illegal path characters in link target: %s", target),
-					Location: &Location{Line: lineNum, Column: listItemColumn},
+					Location: &Location{Line: linkLine, Column: linkColumn},
+					Target:   target,
 				})
 				continue
 			}
@@ -219,20 +414,40 @@ func Parse(ctx context.Context, src []byte, project *Project) (*ParseResult, []D
 
 		// Validate target path.
 		if !isPlaceholder {
-			if diag := validateTarget(target, lineNum, listItemColumn); diag != nil {
+			if diag := validateTarget(target, linkLine, linkColumn); diag != nil {
 				diags = append(diags, *diag)
 				continue
 			}
 		}
 
-		// Check for self-referential link (BNDW008).
-		if !isPlaceholder && target == "_binder.md" {
-			diags = append(diags, Diagnostic{
-				Severity: "warning",
-				Code:     CodeSelfReferentialLink,
-				Message:  "link targets the binder file itself",
-				Location: &Location{Line: lineNum},
-			})
+		// Check for self-referential link (BNDW008). A link to another of
+		// the project's binders is also recorded for ExpandSubBinders, and
+		// is no self-reference when the project expands nested binders.
+		if !isPlaceholder && IsBinderTarget(target, project) {
+			nested := normalizeBinderPath(target) != project.ActiveBinderFile()
+			if !nested || !project.SubBinders {
+				diags = append(diags, Diagnostic{
+					Severity: "warning",
+					Code:     CodeSelfReferentialLink,
+					Message:  "link targets the binder file itself",
+					Location: &Location{Line: linkLine},
+					Target:   target,
+				})
+			}
+			if nested {
+				s := stack
+				for len(s) > 1 && s[len(s)-1].indent >= indent {
+					s = s[:len(s)-1]
+				}
+				parent := s[len(s)-1].node
+				result.SubBinders = append(result.SubBinders, &SubBinderLink{
+					Parent: parent,
+					Index:  len(parent.Children),
+					Target: normalizeBinderPath(target),
+					Title:  title,
+					Line:   linkLine,
+				})
+			}
 			continue // skip node creation for self-referential links
 		}
 
@@ -243,7 +458,8 @@ func Parse(ctx context.Context, src []byte, project *Project) (*ParseResult, []D
 					Severity: "warning",
 					Code:     CodeDuplicateFileRef,
 					Message:  fmt.Sprintf("Duplicate file reference: %s appears as more than one node in the binder tree", target),
-					Location: &Location{Line: lineNum},
+					Location: &Location{Line: linkLine},
+					Target:   target,
 				})
 			}
 			seenTargets[target] = true
@@ -257,17 +473,20 @@ func Parse(ctx context.Context, src []byte, project *Project) (*ParseResult, []D
 				// Check for case-insensitive match (BNDW009).
 				if lowerMatch := projectFilesLower[strings.ToLower(lookupTarget)]; lowerMatch != "" {
 					diags = append(diags, Diagnostic{
-						Severity: "warning",
-						Code:     CodeCaseInsensitiveMatch,
-						Message:  fmt.Sprintf("case-insensitive match found: %s → %s", target, lowerMatch),
-						Location: &Location{Line: lineNum},
+						Severity:   "warning",
+						Code:       CodeCaseInsensitiveMatch,
+						Message:    fmt.Sprintf("case-insensitive match found: %s → %s", target, lowerMatch),
+						Location:   &Location{Line: linkLine},
+						Suggestion: caseFixSuggestion(result, linkLine, "](", lookupTarget, lowerMatch),
+						Target:     target,
 					})
 				} else {
 					diags = append(diags, Diagnostic{
 						Severity: "warning",
 						Code:     CodeMissingTargetFile,
 						Message:  fmt.Sprintf("Target file %s is not present in the project", target),
-						Location: &Location{Line: lineNum},
+						Location: &Location{Line: linkLine},
+						Target:   target,
 					})
 				}
 			}
@@ -275,22 +494,28 @@ func Parse(ctx context.Context, src []byte, project *Project) (*ParseResult, []D
 
 		// Check for multiple structural .md links in one list item (BNDW002).
 		if !isPlaceholder {
-			if allMd := mdInlineLinkRE.FindAllString(content, -1); len(allMd) > 1 {
+			if allMd := mdInlineLinkRE.FindAllString(linkContent, -1); len(allMd) > 1 {
 				diags = append(diags, Diagnostic{
 					Severity: "warning",
 					Code:     CodeMultipleStructLinks,
 					Message:  "list item contains multiple structural links; only the first is used",
-					Location: &Location{Line: lineNum},
+					Location: &Location{Line: linkLine},
 				})
 			}
 		}
 
+		if nodeLimitHit(lineNum) {
+			break
+		}
+
 		node := &Node{
-			Type:       "node",
+			Type:       nodeType,
 			Children:   []*Node{},
 			Target:     target,
 			Title:      title,
+			Checked:    checked,
 			Line:       lineNum,
+			EndLine:    endLine,
 			Indent:     indent,
 			ListMarker: marker,
 			RawLine:    line,
@@ -305,20 +530,85 @@ func Parse(ctx context.Context, src []byte, project *Project) (*ParseResult, []D
 		stack = append(stack, stackEntry{indent: indent, node: node})
 	}
 
-	return result, diags, nil
+	return diags
+}
+
+// parseFencedPseudoNode returns a pseudo-node for a list item with a structural
+// link inside a fenced code block, or nil if line is not such an item. Pseudo-nodes
+// never join the tree; they are recorded so operations can refuse them (OPE006).
+func parseFencedPseudoNode(line string, lineNum int, refDefs map[string]RefDef, wikiIndex map[string][]wikilinkEntry, binderDir string) *Node {
+	m := listItemRE.FindStringSubmatch(line)
+	if m == nil {
+		return nil
+	}
+	content := normalizeListContent(strings.TrimSpace(m[3]))
+	target, title, found, _ := parseLink(content, refDefs, wikiIndex, binderDir, lineNum, 0)
+	if !found {
+		return nil
+	}
+	if decoded, ok := percentDecodeTarget(target); ok {
+		target = decoded
+	}
+	return &Node{
+		Type:        "node",
+		Children:    []*Node{},
+		Target:      target,
+		Title:       title,
+		Line:        lineNum,
+		Indent:      len(m[1]),
+		ListMarker:  m[2],
+		RawLine:     line,
+		InCodeFence: true,
+	}
 }
 
 // pass1Data holds the results of the first-pass scan over source lines.
 type pass1Data struct {
 	hasPragma  bool
 	pragmaLine int
-	refDefs    map[string]RefDef
-	diags      []Diagnostic // fence-link diagnostics only
+	// nearMiss is the first pragma-like comment that is not the exact pragma,
+	// and nearMissLine its 1-based line.
+	nearMiss     string
+	nearMissLine int
+	refDefs      map[string]RefDef
+	// refDefLimitLine is the 1-based line of the first reference definition
+	// dropped because maxRefDefs was reached (0 when none was).
+	refDefLimitLine int
+	diags           []Diagnostic // fence-link diagnostics only
 }
 
-// pass1Scan scans lines to detect the pragma, collect reference definitions, and warn
-// on structural links inside fenced code blocks.
-func pass1Scan(lines []string) pass1Data {
+// missingPragmaDiagnostic describes why no effective pragma was found. A
+// pragma-like comment naming another binder version yields
+// CodeUnsupportedBinderVersion; any other near miss keeps BNDW001 but points at
+// the malformed line.
+func missingPragmaDiagnostic(p1 pass1Data) Diagnostic {
+	if p1.nearMissLine == 0 {
+		return Diagnostic{
+			Severity: "warning",
+			Code:     CodeMissingPragma,
+			Message:  "Missing binder pragma: file has content but does not begin with <!-- prosemark-binder:v1 -->",
+		}
+	}
+	loc := &Location{Line: p1.nearMissLine}
+	if m := pragmaVersionRE.FindStringSubmatch(p1.nearMiss); m != nil && strings.TrimLeft(m[1], "0") != "1" {
+		return Diagnostic{
+			Severity: "warning",
+			Code:     CodeUnsupportedBinderVersion,
+			Message:  fmt.Sprintf("Unsupported binder version: %s declares v%s, but this pmk reads v1 binders", p1.nearMiss, m[1]),
+			Location: loc,
+		}
+	}
+	return Diagnostic{
+		Severity: "warning",
+		Code:     CodeMissingPragma,
+		Message:  fmt.Sprintf("Malformed binder pragma: %s must be exactly <!-- prosemark-binder:v1 -->", p1.nearMiss),
+		Location: loc,
+	}
+}
+
+// pass1Scan scans lines to detect the pragma, collect up to maxRefDefs reference
+// definitions, and warn on structural links inside fenced code blocks.
+func pass1Scan(lines []string, maxRefDefs int) pass1Data {
 	result := pass1Data{refDefs: make(map[string]RefDef)}
 	inFence := false
 	fenceMarker := ""
@@ -332,9 +622,19 @@ func pass1Scan(lines []string) pass1Data {
 				if !result.hasPragma && pragmaRE.MatchString(line) {
 					result.hasPragma = true
 					result.pragmaLine = lineNum
+				} else if result.nearMissLine == 0 {
+					if m := pragmaNearMissRE.FindString(line); m != "" {
+						result.nearMiss, result.nearMissLine = m, lineNum
+					}
 				}
 				if m := refDefRE.FindStringSubmatch(line); m != nil {
 					label := strings.ToLower(m[1])
+					if _, seen := result.refDefs[label]; !seen && len(result.refDefs) >= maxRefDefs {
+						if result.refDefLimitLine == 0 {
+							result.refDefLimitLine = lineNum
+						}
+						continue
+					}
 					result.refDefs[label] = RefDef{
 						Label:  label,
 						Target: m[2],
@@ -360,10 +660,82 @@ func pass1Scan(lines []string) pass1Data {
 	return result
 }
 
+// caseFixSuggestion returns the fix for a BNDW009 link on the 1-based line
+// whose destination, after opener, spells wrong: a project path that matches
+// the file right only when case is ignored. The fix respells the
+// destination from the first path component that differs. It returns nil
+// when the destination is not spelled out on the line, as with reference
+// links and percent-encoded paths.
+func caseFixSuggestion(r *ParseResult, line int, opener, wrong, right string) *Suggestion {
+	if len(wrong) != len(right) {
+		return nil
+	}
+	i := 0
+	for i < len(wrong) && wrong[i] == right[i] {
+		i++
+	}
+	if i == len(wrong) {
+		return nil
+	}
+	i = strings.LastIndex(wrong[:i], "/") + 1
+	wrong, right = wrong[i:], right[i:]
+
+	raw := r.Lines[line-1]
+	open := strings.Index(raw, opener)
+	if open < 0 {
+		return nil
+	}
+	at := strings.Index(raw[open:], wrong)
+	if at < 0 {
+		return nil
+	}
+	start := lineOffset(r, line) + open + at
+	return &Suggestion{
+		Message:     fmt.Sprintf("replace %s with %s", wrong, right),
+		Replacement: right,
+		Range:       Range{Start: start, End: start + len(wrong)},
+	}
+}
+
+// wikilinkCaseFix returns the fix for a BNDW009 wikilink on the 1-based line
+// that resolved to target: the stem, respelled as target spells it.
+func wikilinkCaseFix(r *ParseResult, line int, target string) *Suggestion {
+	raw := r.Lines[line-1]
+	open := strings.Index(raw, "[[")
+	if open < 0 {
+		return nil
+	}
+	stem := raw[open+2:]
+	if end := strings.IndexAny(stem, "|]"); end >= 0 {
+		stem = stem[:end]
+	}
+	if !strings.HasSuffix(strings.ToLower(stem), ".md") {
+		target = strings.TrimSuffix(target, ".md")
+	}
+	if len(target) < len(stem) || !strings.EqualFold(target[len(target)-len(stem):], stem) {
+		return nil
+	}
+	return caseFixSuggestion(r, line, "[[", stem, target[len(target)-len(stem):])
+}
+
+// lineOffset returns the byte offset of the start of the 1-based line in the
+// file r was parsed from.
+func lineOffset(r *ParseResult, line int) int {
+	off := 0
+	if r.HasBOM {
+		off = len(utf8BOM)
+	}
+	for i := 0; i < line-1; i++ {
+		off += len(r.Lines[i]) + len(r.LineEnds[i])
+	}
+	return off
+}
+
 // parseLink parses the content portion of a list item and returns (target, title, found, diags).
 // found is true when a link structure was resolved (including placeholder nodes with empty target).
 // Returns ("", "", false, nil) if no link can be resolved.
 func parseLink(content string, refDefs map[string]RefDef, wikiIndex map[string][]wikilinkEntry, binderDir string, lineNum, column int) (target, title string, found bool, diags []Diagnostic) {
+	content = StripTrailingComments(content)
 	if m := emptyTargetLinkRE.FindStringSubmatch(content); m != nil {
 		title = strings.TrimSpace(unescapeTitle(m[1]))
 		found = true
@@ -452,7 +824,14 @@ func resolveWikilink(rawStem, alias string, wikiIndex map[string][]wikilinkEntry
 	}
 
 	stemFile := stem + ".md"
-	entries := wikiIndex[strings.ToLower(stem)]
+	var entries, aliasEntries []wikilinkEntry
+	for _, e := range wikiIndex[strings.ToLower(stem)] {
+		if e.alias {
+			aliasEntries = append(aliasEntries, e)
+		} else {
+			entries = append(entries, e)
+		}
+	}
 
 	// Case-sensitive: exact path match OR basename match.
 	var exactEntries, basenameEntries []wikilinkEntry
@@ -484,6 +863,17 @@ func resolveWikilink(rawStem, alias string, wikiIndex map[string][]wikilinkEntry
 		return
 	}
 
+	// Binder-relative preference: a file next to the binder beats any other match.
+	var near []wikilinkEntry
+	for _, e := range matchEntries {
+		if e.nearBinder {
+			near = append(near, e)
+		}
+	}
+	if len(near) > 0 {
+		matchEntries = near
+	}
+
 	// Proximity tiebreak: prefer shallowest path (fewest "/" separators).
 	minDepth := -1
 	for _, e := range matchEntries {
@@ -508,6 +898,7 @@ func resolveWikilink(rawStem, alias string, wikiIndex map[string][]wikilinkEntry
 				Code:     CodeCaseInsensitiveMatch,
 				Message:  "wikilink resolved by case-insensitive match",
 				Location: &Location{Line: lineNum},
+				Target:   target,
 			})
 		}
 		if alias == "" {
@@ -522,6 +913,27 @@ func resolveWikilink(rawStem, alias string, wikiIndex map[string][]wikilinkEntry
 			Message:  fmt.Sprintf("Ambiguous wikilink: [[%s]] matches %s", rawStem, joinWikilinkFiles(atMinDepth)),
 			Location: &Location{Line: lineNum, Column: column},
 		})
+	case len(aliasEntries) > 1:
+		diags = append(diags, Diagnostic{
+			Severity: "error",
+			Code:     CodeAmbiguousWikilink,
+			Message:  fmt.Sprintf("Ambiguous wikilink: [[%s]] is an alias of %s", rawStem, joinWikilinkFiles(aliasEntries)),
+			Location: &Location{Line: lineNum, Column: column},
+		})
+	case len(aliasEntries) == 1:
+		// No file has this stem, but one declares it as a frontmatter alias.
+		target = aliasEntries[0].file
+		diags = append(diags, Diagnostic{
+			Severity: "warning",
+			Code:     CodeWikilinkAliasMatch,
+			Message:  fmt.Sprintf("wikilink [[%s]] resolved through the frontmatter alias of %s", stem, target),
+			Location: &Location{Line: lineNum, Column: column},
+			Target:   target,
+		})
+		if alias == "" {
+			alias = stem
+		}
+		title = alias
 	default:
 		// Zero matches: create node with derived filename (BNDW004 emitted by caller).
 		target = stemFile
@@ -533,6 +945,17 @@ func resolveWikilink(rawStem, alias string, wikiIndex map[string][]wikilinkEntry
 	return
 }
 
+// sortedAliasFiles returns the keys of aliases in sorted order so that
+// ambiguity messages are deterministic.
+func sortedAliasFiles(aliases map[string][]string) []string {
+	files := make([]string, 0, len(aliases))
+	for f := range aliases {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files
+}
+
 // joinWikilinkFiles formats a list of wikilink entries as "file1 and file2".
 func joinWikilinkFiles(entries []wikilinkEntry) string {
 	names := make([]string, len(entries))
@@ -545,14 +968,21 @@ func joinWikilinkFiles(entries []wikilinkEntry) string {
 // buildWikilinkIndex builds a lowercase-stem → []wikilinkEntry map for O(1) lookup.
 // Each file is indexed by its basename stem; files in subdirectories are also indexed
 // by their full path stem so that [[subdir/file]] wikilinks resolve via the index too.
+// Frontmatter aliases from project.Aliases are indexed as alias entries, once per file.
+// Unless project.WikilinkResolution is WikilinkResolutionShortest, files in
+// project.BinderDir are marked nearBinder.
 func buildWikilinkIndex(project *Project) map[string][]wikilinkEntry {
 	index := make(map[string][]wikilinkEntry)
 	if project == nil {
 		return index
 	}
+	nearDir := ""
+	if project.BinderDir != "" && project.WikilinkResolution != WikilinkResolutionShortest {
+		nearDir = path.Clean(project.BinderDir)
+	}
 	for _, f := range project.Files {
 		depth := strings.Count(f, "/")
-		e := wikilinkEntry{file: f, depth: depth}
+		e := wikilinkEntry{file: f, depth: depth, nearBinder: path.Dir(f) == nearDir}
 		lowBase := strings.ToLower(strings.TrimSuffix(baseName(f), ".md"))
 		index[lowBase] = append(index[lowBase], e)
 		if depth > 0 {
@@ -560,6 +990,17 @@ func buildWikilinkIndex(project *Project) map[string][]wikilinkEntry {
 			index[lowPath] = append(index[lowPath], e)
 		}
 	}
+	for _, f := range sortedAliasFiles(project.Aliases) {
+		seen := make(map[string]bool)
+		for _, a := range project.Aliases[f] {
+			low := strings.ToLower(a)
+			if seen[low] {
+				continue
+			}
+			seen[low] = true
+			index[low] = append(index[low], wikilinkEntry{file: f, depth: strings.Count(f, "/"), alias: true})
+		}
+	}
 	return index
 }
 
@@ -616,6 +1057,7 @@ func validateTarget(target string, lineNum, column int) *Diagnostic {
 			Code:     CodeIllegalPathChars,
 			Message:  fmt.Sprintf("Illegal path characters in link target: %s", target),
 			Location: &Location{Line: lineNum, Column: column},
+			Target:   target,
 		}
 	case hasTrailingDotSegment(target):
 		return &Diagnostic{
@@ -623,6 +1065,7 @@ func validateTarget(target string, lineNum, column int) *Diagnostic {
 			Code:     CodeIllegalPathChars,
 			Message:  fmt.Sprintf("Illegal path characters in link target: %s", target),
 			Location: &Location{Line: lineNum, Column: column},
+			Target:   target,
 		}
 	case escapesRoot(target):
 		return &Diagnostic{
@@ -630,6 +1073,7 @@ func validateTarget(target string, lineNum, column int) *Diagnostic {
 			Code:     CodePathEscapesRoot,
 			Message:  "Link target resolves outside the project root",
 			Location: &Location{Line: lineNum, Column: column},
+			Target:   target,
 		}
 	}
 	return nil
@@ -677,6 +1121,17 @@ func isMarkdownTarget(path string) bool {
 	return strings.HasSuffix(strings.ToLower(path), ".md")
 }
 
+// parseCheckbox returns the GFM task-list state of list item content: a pointer
+// to true for "[x]"/"[X]", to false for "[ ]", or nil when there is no checkbox.
+func parseCheckbox(s string) *bool {
+	m := checkboxRE.FindString(s)
+	if m == "" {
+		return nil
+	}
+	checked := m[1] != ' '
+	return &checked
+}
+
 // normalizeListContent strips GFM task-list checkbox prefixes and strikethrough markup
 // from list item content, trimming surrounding whitespace.
 func normalizeListContent(s string) string {
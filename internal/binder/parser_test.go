@@ -2,6 +2,9 @@ package binder_test
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/eykd/prosemark-go/internal/binder"
@@ -126,6 +129,43 @@ func TestParse_LineEndings_LastLineNoEnding(t *testing.T) {
 	}
 }
 
+// TestParse_PragmaNearMiss tests that pragma-like comments which are not the
+// exact v1 pragma get a precise diagnostic pointing at their line: another
+// version is PMKW004, anything else a BNDW001 "malformed" warning.
+func TestParse_PragmaNearMiss(t *testing.T) {
+	tests := []struct {
+		pragma      string
+		wantCode    string
+		wantMessage string
+	}{
+		{"<!-- prosemark-binder:v2 -->", binder.CodeUnsupportedBinderVersion, "Unsupported binder version"},
+		{"<!-- prosemark-binder:v0 -->", binder.CodeUnsupportedBinderVersion, "declares v0"},
+		{"<!--prosemark-binder v1-->", binder.CodeMissingPragma, "Malformed binder pragma"},
+		{"<!-- Prosemark-Binder:V1 -->", binder.CodeMissingPragma, "Malformed binder pragma"},
+		{"<!-- prosemark-binder -->", binder.CodeMissingPragma, "Malformed binder pragma"},
+		{"<!-- unrelated comment -->", binder.CodeMissingPragma, "Missing binder pragma"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pragma, func(t *testing.T) {
+			src := []byte("# Title\n" + tt.pragma + "\n\n- [A](a.md)\n")
+			_, diags, err := binder.Parse(context.Background(), src, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(diags) != 1 {
+				t.Fatalf("diags = %+v, want exactly one", diags)
+			}
+			d := diags[0]
+			if d.Code != tt.wantCode || d.Severity != "warning" || !strings.Contains(d.Message, tt.wantMessage) {
+				t.Errorf("diag = %+v, want %s warning containing %q", d, tt.wantCode, tt.wantMessage)
+			}
+			if tt.wantMessage != "Missing binder pragma" && (d.Location == nil || d.Location.Line != 2) {
+				t.Errorf("location = %+v, want line 2", d.Location)
+			}
+		})
+	}
+}
+
 // TestParse_MissingPragma_EmitsBNDW001 tests that BNDW001 is emitted when pragma is absent.
 func TestParse_MissingPragma_EmitsBNDW001(t *testing.T) {
 	src := []byte("- [Chapter](chapter.md)\n")
@@ -1010,7 +1050,7 @@ func TestParse_AmbiguousWikilink_EmitsBNDE003(t *testing.T) {
 // an exact-path match coexists with a basename match and no binderDir context is
 // available (BinderDir == ""), the wikilink is treated as ambiguous (BNDE003).
 // This covers the early-exit ambiguity check in resolveWikilink for callers that
-// pass a Project without a BinderDir (e.g., direct API usage without ScanProjectImpl).
+// pass a Project without a BinderDir (e.g., direct API usage without fsio.ScanProject).
 func TestParse_AmbiguousWikilink_NoBinder_ExactPlusBasename_EmitsBNDE003(t *testing.T) {
 	// "deep.md" at root (exact match) and "sub/deep.md" (basename match), no binderDir.
 	project := &binder.Project{
@@ -1072,6 +1112,288 @@ func TestParse_WikilinkCaseInsensitiveMatch_EmitsBNDW009(t *testing.T) {
 	}
 }
 
+// TestParse_WikilinkFrontmatterAlias tests that a wikilink matching no file
+// stem resolves through a frontmatter alias (PMKW003 instead of BNDW004), that
+// a real stem wins over an alias, and that an alias shared by two files is
+// ambiguous (BNDE003).
+func TestParse_WikilinkFrontmatterAlias(t *testing.T) {
+	project := &binder.Project{
+		Files: []string{"people/samwise.md", "frodo.md", "pippin.md", "merry.md"},
+		Aliases: map[string][]string{
+			"people/samwise.md": {"Sam", "sam"},
+			"pippin.md":         {"Frodo", "Hobbits"},
+			"merry.md":          {"Hobbits"},
+		},
+	}
+	tests := []struct {
+		line       string
+		wantTarget string
+		wantTitle  string
+		wantCode   string
+	}{
+		{"- [[Sam]]", "people/samwise.md", "Sam", binder.CodeWikilinkAliasMatch},
+		{"- [[SAM|Gardener]]", "people/samwise.md", "Gardener", binder.CodeWikilinkAliasMatch},
+		{"- [[frodo]]", "frodo.md", "frodo", ""},
+		{"- [[Hobbits]]", "", "", binder.CodeAmbiguousWikilink},
+		{"- [[Gandalf]]", "Gandalf.md", "Gandalf", binder.CodeMissingTargetFile},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			src := []byte("<!-- prosemark-binder:v1 -->\n" + tt.line + "\n")
+			result, diags, err := binder.Parse(context.Background(), src, project)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var codes []string
+			for _, d := range diags {
+				codes = append(codes, d.Code)
+			}
+			if tt.wantCode == "" && len(codes) != 0 || tt.wantCode != "" && (len(codes) != 1 || codes[0] != tt.wantCode) {
+				t.Errorf("diagnostic codes = %v, want [%s]", codes, tt.wantCode)
+			}
+			if tt.wantTarget == "" {
+				if len(result.Root.Children) != 0 {
+					t.Errorf("ambiguous alias should not create a node, got %+v", result.Root.Children[0])
+				}
+				return
+			}
+			if len(result.Root.Children) != 1 {
+				t.Fatalf("Root.Children len = %d, want 1", len(result.Root.Children))
+			}
+			n := result.Root.Children[0]
+			if n.Target != tt.wantTarget || n.Title != tt.wantTitle {
+				t.Errorf("node = %q/%q, want %q/%q", n.Target, n.Title, tt.wantTarget, tt.wantTitle)
+			}
+		})
+	}
+}
+
+// TestParse_WikilinkBinderRelativeResolution tests that a file in the binder's
+// directory wins over other matches, including same-depth ones that were
+// previously ambiguous (BNDE003), and that "shortest" resolution restores the
+// project-root proximity tiebreak.
+func TestParse_WikilinkBinderRelativeResolution(t *testing.T) {
+	tests := []struct {
+		name       string
+		binderDir  string
+		resolution string
+		files      []string
+		wantTarget string // "" means BNDE003
+	}{
+		{"same depth next to binder", "part", "", []string{"notes/x.md", "part/x.md"}, "part/x.md"},
+		{"next to binder beats shallower", "part", "binder", []string{"x.md", "part/x.md"}, "part/x.md"},
+		{"no file next to binder falls back to shallowest", "part", "", []string{"a/b/x.md", "notes/x.md"}, "notes/x.md"},
+		{"no file next to binder stays ambiguous", "part", "", []string{"a/x.md", "notes/x.md"}, ""},
+		{"root binder unchanged", ".", "", []string{"a/x.md", "notes/x.md"}, ""},
+		{"shortest same depth is ambiguous", "part", "shortest", []string{"notes/x.md", "part/x.md"}, ""},
+		{"shortest prefers shallower", "part", "shortest", []string{"x.md", "part/x.md"}, "x.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := &binder.Project{Files: tt.files, BinderDir: tt.binderDir, WikilinkResolution: tt.resolution}
+			src := []byte("<!-- prosemark-binder:v1 -->\n- [[x]]\n")
+			result, diags, err := binder.Parse(context.Background(), src, project)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantTarget == "" {
+				if len(diags) != 1 || diags[0].Code != binder.CodeAmbiguousWikilink {
+					t.Errorf("diags = %+v, want BNDE003", diags)
+				}
+				return
+			}
+			if len(diags) != 0 {
+				t.Errorf("unexpected diagnostics: %+v", diags)
+			}
+			if len(result.Root.Children) != 1 || result.Root.Children[0].Target != tt.wantTarget {
+				t.Errorf("children = %+v, want target %q", result.Root.Children, tt.wantTarget)
+			}
+		})
+	}
+}
+
+// TestParse_ReferenceSections tests that .md links outside lists under a
+// configured reference-section heading get PMKI001 (info) instead of BNDW006,
+// and that the section ends at the next heading of the same or higher level.
+func TestParse_ReferenceSections(t *testing.T) {
+	src := []byte("<!-- prosemark-binder:v1 -->\n\n" +
+		"- [A](a.md)\n\n" +
+		"Intro [B](b.md).\n\n" + // line 5: BNDW006
+		"## See Also ##\n\n" +
+		"Compare [C](c.md).\n\n" + // line 9: PMKI001
+		"### Details\n\n" +
+		"Also [D](d.md).\n\n" + // line 13: PMKI001 (subsection)
+		"## Appendix\n\n" +
+		"Then [E](e.md).\n") // line 17: BNDW006
+	project := &binder.Project{
+		Files:             []string{"a.md", "b.md", "c.md", "d.md", "e.md"},
+		BinderDir:         ".",
+		ReferenceSections: []string{"see also"},
+	}
+	_, diags, err := binder.Parse(context.Background(), src, project)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []struct {
+		line     int
+		code     string
+		severity string
+	}{
+		{5, binder.CodeLinkOutsideList, "warning"},
+		{9, binder.CodeReferenceSectionLink, binder.SeverityInfo},
+		{13, binder.CodeReferenceSectionLink, binder.SeverityInfo},
+		{17, binder.CodeLinkOutsideList, "warning"},
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("diags = %+v, want %d", diags, len(want))
+	}
+	for i, w := range want {
+		d := diags[i]
+		if d.Code != w.code || d.Severity != w.severity || d.Location == nil || d.Location.Line != w.line {
+			t.Errorf("diag[%d] = %+v, want %s %s at line %d", i, d, w.severity, w.code, w.line)
+		}
+	}
+}
+
+func TestParse_TextPlaceholders(t *testing.T) {
+	src := []byte("<!-- prosemark-binder:v1 -->\n\n" +
+		"- Part One <!-- 0 words -->\n" +
+		"  - [Chapter One](ch1.md)\n" +
+		"  - [ ] Chapter Two\n" +
+		"- \n" +
+		"- Notes\n" +
+		"  [Appendix](appendix.md)\n")
+	project := &binder.Project{Files: []string{"ch1.md", "appendix.md"}, BinderDir: ".", TextPlaceholders: true}
+
+	result, diags, err := binder.Parse(context.Background(), src, project)
+	if err != nil || len(diags) != 0 {
+		t.Fatalf("Parse = %v, %v", diags, err)
+	}
+	var got []string
+	var walk func(n *binder.Node, depth int)
+	walk = func(n *binder.Node, depth int) {
+		for _, c := range n.Children {
+			got = append(got, fmt.Sprintf("%d %s %q %q", depth, c.Type, c.Title, c.Target))
+			walk(c, depth+1)
+		}
+	}
+	walk(result.Root, 0)
+	want := []string{
+		`0 placeholder "Part One" ""`,
+		`1 node "Chapter One" "ch1.md"`,
+		`1 placeholder "Chapter Two" ""`,
+		`0 node "Appendix" "appendix.md"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("nodes = %q, want %q", got, want)
+	}
+
+	project.TextPlaceholders = false
+	result, _, _ = binder.Parse(context.Background(), src, project)
+	if n := len(result.Root.Children); n != 2 || result.Root.Children[0].Target != "ch1.md" {
+		t.Errorf("without TextPlaceholders, root has %d children, want ch1.md and appendix.md", n)
+	}
+}
+
+func TestParse_Limits(t *testing.T) {
+	const pragma = "<!-- prosemark-binder:v1 -->\n"
+	tests := []struct {
+		name      string
+		src       string
+		limits    *binder.ParseLimits
+		wantLine  int // 0: no limit diagnostic expected; -1: one without a location
+		wantNodes int
+		wantDefs  int
+	}{
+		{
+			name:      "defaults accept a normal binder",
+			src:       pragma + "- [A](a.md)\n- [B](b.md)\n",
+			wantNodes: 2,
+		},
+		{
+			name:      "zero fields keep the defaults",
+			src:       pragma + "- [A](a.md)\n",
+			limits:    &binder.ParseLimits{},
+			wantNodes: 1,
+		},
+		{
+			name:     "file size",
+			src:      pragma + "- [A](a.md)\n",
+			limits:   &binder.ParseLimits{MaxFileSize: 10},
+			wantLine: -1,
+		},
+		{
+			name:     "line length",
+			src:      pragma + "- [A](a.md)\n" + strings.Repeat("x", 41) + "\n- [B](b.md)\n",
+			limits:   &binder.ParseLimits{MaxLineLength: 40},
+			wantLine: 3,
+		},
+		{
+			name:      "node count stops the tree",
+			src:       pragma + "- [A](a.md)\n  - [B](b.md)\n- [C](c.md)\n",
+			limits:    &binder.ParseLimits{MaxNodes: 2},
+			wantLine:  4,
+			wantNodes: 2,
+		},
+		{
+			name:      "fenced pseudo-nodes count too",
+			src:       pragma + "- [A](a.md)\n```\n- [B](b.md)\n```\n- [C](c.md)\n",
+			limits:    &binder.ParseLimits{MaxNodes: 1},
+			wantLine:  4,
+			wantNodes: 1,
+		},
+		{
+			name:      "reference definitions",
+			src:       pragma + "- [A][a]\n\n[a]: a.md\n[a]: again.md\n[b]: b.md\n[c]: c.md\n",
+			limits:    &binder.ParseLimits{MaxRefDefs: 1},
+			wantLine:  6,
+			wantNodes: 1,
+			wantDefs:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := &binder.Project{Files: []string{"a.md", "b.md", "c.md", "again.md"}, BinderDir: ".", Limits: tt.limits}
+			result, diags, err := binder.Parse(context.Background(), []byte(tt.src), project)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var limitDiags []binder.Diagnostic
+			for _, d := range diags {
+				if d.Code == binder.CodeParseLimitExceeded {
+					limitDiags = append(limitDiags, d)
+				}
+			}
+			switch {
+			case tt.wantLine == 0:
+				if len(limitDiags) != 0 {
+					t.Errorf("unexpected limit diagnostics: %+v", limitDiags)
+				}
+			case len(limitDiags) != 1 || limitDiags[0].Severity != "error":
+				t.Errorf("limit diagnostics = %+v, want one error", limitDiags)
+			case tt.wantLine == -1 && limitDiags[0].Location != nil,
+				tt.wantLine > 0 && (limitDiags[0].Location == nil || limitDiags[0].Location.Line != tt.wantLine):
+				t.Errorf("limit diagnostic location = %+v, want line %d", limitDiags[0].Location, tt.wantLine)
+			}
+			if got := countNodes(result.Root) + len(result.Fenced) - 1; got != tt.wantNodes {
+				t.Errorf("parsed %d nodes, want %d", got, tt.wantNodes)
+			}
+			if tt.wantDefs > 0 && len(result.RefDefs) != tt.wantDefs {
+				t.Errorf("kept %d ref defs, want %d", len(result.RefDefs), tt.wantDefs)
+			}
+		})
+	}
+}
+
+// countNodes counts n and all of its descendants.
+func countNodes(n *binder.Node) int {
+	c := 1
+	for _, child := range n.Children {
+		c += countNodes(child)
+	}
+	return c
+}
+
 // --- Secondary Diagnostics: Phase-A step 9 (BNDW002–BNDW008) ---
 
 // TestParse_MultipleStructLinks_EmitsBNDW002 tests that a list item containing more
@@ -1565,3 +1887,342 @@ func TestParse_PlaceholderContinuationLine(t *testing.T) {
 		})
 	}
 }
+
+// TestParse_ContinuationLineDiagnostics verifies that diagnostics about a link
+// found on a consumed continuation line are located on that line, not on the
+// list item's first line, and that the node still starts on the first line.
+func TestParse_ContinuationLineDiagnostics(t *testing.T) {
+	pragma := "<!-- prosemark-binder:v1 -->\n\n"
+	project := &binder.Project{Files: []string{"a.md", "b.md", "one/x.md", "two/x.md"}, BinderDir: "."}
+	tests := []struct {
+		name       string
+		src        string
+		wantCode   string
+		wantLine   int
+		wantColumn int
+		wantNodes  int
+	}{
+		{"illegal path characters", pragma + "- Draft\n  [A](a%zz.md)\n", binder.CodeIllegalPathChars, 4, 3, 0},
+		{"path escapes root", pragma + "- Draft\n    [A](../a.md)\n", binder.CodePathEscapesRoot, 4, 5, 0},
+		{"non-markdown target", pragma + "- Draft\n  [Cover](cover.png)\n", binder.CodeNonMarkdownTarget, 4, 0, 0},
+		{"missing target file", pragma + "- Draft\n  [C](c.md)\n", binder.CodeMissingTargetFile, 4, 0, 1},
+		{"duplicate file reference", pragma + "- [A](a.md)\n- Draft\n  [A again](a.md)\n", binder.CodeDuplicateFileRef, 5, 0, 2},
+		{"multiple structural links", pragma + "- Draft\n  [A](a.md) [B](b.md)\n", binder.CodeMultipleStructLinks, 4, 0, 1},
+		{"unresolved wikilink", pragma + "- Draft\n  [[x]]\n", binder.CodeAmbiguousWikilink, 4, 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, diags, err := binder.Parse(context.Background(), []byte(tt.src), project)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var found bool
+			for _, d := range diags {
+				if d.Code != tt.wantCode {
+					continue
+				}
+				found = true
+				if d.Location == nil || d.Location.Line != tt.wantLine || d.Location.Column != tt.wantColumn {
+					t.Errorf("%s location = %+v, want line %d column %d", d.Code, d.Location, tt.wantLine, tt.wantColumn)
+				}
+			}
+			if !found {
+				t.Fatalf("diags = %v, want %s", diags, tt.wantCode)
+			}
+			if len(result.Root.Children) != tt.wantNodes {
+				t.Fatalf("root children = %d, want %d", len(result.Root.Children), tt.wantNodes)
+			}
+			if tt.wantNodes > 0 {
+				last := result.Root.Children[tt.wantNodes-1]
+				if last.Line != tt.wantLine-1 || last.EndLine != tt.wantLine {
+					t.Errorf("node lines = %d-%d, want %d-%d", last.Line, last.EndLine, tt.wantLine-1, tt.wantLine)
+				}
+			}
+		})
+	}
+}
+
+// TestParse_CheckboxState verifies that a GFM task-list checkbox is parsed into
+// Node.Checked (true for [x]/[X], false for [ ], nil when absent) and that the
+// structural link after it is still resolved.
+func TestParse_CheckboxState(t *testing.T) {
+	pragma := "<!-- prosemark-binder:v1 -->\n\n"
+	tests := []struct {
+		name        string
+		src         string
+		wantChecked *bool
+	}{
+		{name: "checked lowercase", src: pragma + "- [x] [Ch](ch.md)\n", wantChecked: boolPtr(true)},
+		{name: "checked uppercase", src: pragma + "- [X] [Ch](ch.md)\n", wantChecked: boolPtr(true)},
+		{name: "unchecked", src: pragma + "- [ ] [Ch](ch.md)\n", wantChecked: boolPtr(false)},
+		{name: "no checkbox", src: pragma + "- [Ch](ch.md)\n", wantChecked: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := binder.Parse(context.Background(), []byte(tt.src), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Root.Children) != 1 {
+				t.Fatalf("root children = %d, want 1", len(result.Root.Children))
+			}
+			n := result.Root.Children[0]
+			if n.Target != "ch.md" {
+				t.Errorf("node.Target = %q, want %q", n.Target, "ch.md")
+			}
+			switch {
+			case tt.wantChecked == nil && n.Checked != nil:
+				t.Errorf("node.Checked = %v, want nil", *n.Checked)
+			case tt.wantChecked != nil && n.Checked == nil:
+				t.Errorf("node.Checked = nil, want %v", *tt.wantChecked)
+			case tt.wantChecked != nil && *n.Checked != *tt.wantChecked:
+				t.Errorf("node.Checked = %v, want %v", *n.Checked, *tt.wantChecked)
+			}
+		})
+	}
+}
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestParse_FencedPseudoNodes(t *testing.T) {
+	src := []byte("<!-- prosemark-binder:v1 -->\n- [Real](real.md)\n~~~\n- [Fenced](fenced.md)\nplain text\n- no link here\n~~~\n```\n* [[wiki]]\n```\n")
+
+	result, _, err := binder.Parse(context.Background(), src, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Root.Children) != 1 || result.Root.Children[0].Target != "real.md" {
+		t.Fatalf("tree children = %+v, want only real.md", result.Root.Children)
+	}
+	if result.Root.Children[0].InCodeFence {
+		t.Error("tree node must not be marked InCodeFence")
+	}
+	want := []struct {
+		target string
+		line   int
+	}{{"fenced.md", 4}, {"wiki.md", 9}}
+	if len(result.Fenced) != len(want) {
+		t.Fatalf("len(Fenced) = %d, want %d: %+v", len(result.Fenced), len(want), result.Fenced)
+	}
+	for i, w := range want {
+		got := result.Fenced[i]
+		if got.Target != w.target || got.Line != w.line || !got.InCodeFence {
+			t.Errorf("Fenced[%d] = {Target:%q Line:%d InCodeFence:%v}, want {%q %d true}",
+				i, got.Target, got.Line, got.InCodeFence, w.target, w.line)
+		}
+	}
+}
+
+func TestParse_Fences(t *testing.T) {
+	src := []byte("<!-- prosemark-binder:v1 -->\n~~~\n- [Fenced](fenced.md)\n~~~\n- [Real](real.md)\n```go\n- [Open](open.md)\n")
+
+	result, _, err := binder.Parse(context.Background(), src, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []binder.Fence{
+		{Marker: "~~~", StartLine: 2, EndLine: 4, Closed: true},
+		{Marker: "```", StartLine: 6, EndLine: 7},
+	}
+	if len(result.Fences) != len(want) {
+		t.Fatalf("Fences = %+v, want %+v", result.Fences, want)
+	}
+	for i, w := range want {
+		if *result.Fences[i] != w {
+			t.Errorf("Fences[%d] = %+v, want %+v", i, *result.Fences[i], w)
+		}
+	}
+	if result.Fenced[0].Fence != result.Fences[0] || result.Fenced[1].Fence != result.Fences[1] {
+		t.Errorf("pseudo-nodes not linked to their fences: %+v", result.Fenced)
+	}
+	if f := result.FenceAt(3); f != result.Fences[0] {
+		t.Errorf("FenceAt(3) = %+v, want the first fence", f)
+	}
+	if f := result.FenceAt(5); f != nil {
+		t.Errorf("FenceAt(5) = %+v, want nil", f)
+	}
+}
+
+// TestParse_Suggestions tests the fixes attached to BNDW009 and BNDW010: each
+// replaces the diagnosed bytes of the source with corrected text.
+func TestParse_Suggestions(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		files   []string
+		code    string
+		want    *binder.Suggestion
+		wantFix string
+	}{
+		{
+			name:    "byte order mark",
+			src:     "\xef\xbb\xbf<!-- prosemark-binder:v1 -->\n- [One](one.md)\n",
+			code:    binder.CodeBOMPresence,
+			want:    &binder.Suggestion{Message: "remove the byte order mark", Range: binder.Range{Start: 0, End: 3}},
+			wantFix: "<!-- prosemark-binder:v1 -->\n- [One](one.md)\n",
+		},
+		{
+			name:    "inline link case",
+			src:     "\xef\xbb\xbf<!-- prosemark-binder:v1 -->\r\n- [Intro](intro.md)\r\n- [Chapter](chapter.md)\r\n",
+			files:   []string{"intro.md", "Chapter.md"},
+			code:    binder.CodeCaseInsensitiveMatch,
+			want:    &binder.Suggestion{Message: "replace chapter.md with Chapter.md", Replacement: "Chapter.md", Range: binder.Range{Start: 66, End: 76}},
+			wantFix: "\xef\xbb\xbf<!-- prosemark-binder:v1 -->\r\n- [Intro](intro.md)\r\n- [Chapter](Chapter.md)\r\n",
+		},
+		{
+			name:    "inline link case of a directory",
+			src:     "<!-- prosemark-binder:v1 -->\n- [One](./drafts/one.md)\n",
+			files:   []string{"Drafts/one.md"},
+			code:    binder.CodeCaseInsensitiveMatch,
+			wantFix: "<!-- prosemark-binder:v1 -->\n- [One](./Drafts/one.md)\n",
+		},
+		{
+			name:    "inline link case of a file in a directory",
+			src:     "<!-- prosemark-binder:v1 -->\n- [One](drafts/one.md)\n",
+			files:   []string{"drafts/One.md"},
+			code:    binder.CodeCaseInsensitiveMatch,
+			want:    &binder.Suggestion{Message: "replace one.md with One.md", Replacement: "One.md", Range: binder.Range{Start: 44, End: 50}},
+			wantFix: "<!-- prosemark-binder:v1 -->\n- [One](drafts/One.md)\n",
+		},
+		{
+			name:    "wikilink case",
+			src:     "<!-- prosemark-binder:v1 -->\n- [[Chapter|The Chapter]]\n",
+			files:   []string{"part/chapter.md"},
+			code:    binder.CodeCaseInsensitiveMatch,
+			wantFix: "<!-- prosemark-binder:v1 -->\n- [[chapter|The Chapter]]\n",
+		},
+		{
+			name:  "reference link has no fix",
+			src:   "<!-- prosemark-binder:v1 -->\n- [Chapter][c]\n\n[c]: chapter.md\n",
+			files: []string{"Chapter.md"},
+			code:  binder.CodeCaseInsensitiveMatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var project *binder.Project
+			if tt.files != nil {
+				project = &binder.Project{Files: tt.files}
+			}
+			_, diags, err := binder.Parse(context.Background(), []byte(tt.src), project)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			var got *binder.Diagnostic
+			for i := range diags {
+				if diags[i].Code == tt.code {
+					got = &diags[i]
+				}
+			}
+			if got == nil {
+				t.Fatalf("no %s in %v", tt.code, diags)
+			}
+			fix := got.Suggestion
+			if tt.wantFix == "" {
+				if fix != nil {
+					t.Errorf("Suggestion = %+v, want none", fix)
+				}
+				return
+			}
+			if fix == nil {
+				t.Fatal("Suggestion = nil, want a fix")
+			}
+			if tt.want != nil && *fix != *tt.want {
+				t.Errorf("Suggestion = %+v, want %+v", *fix, *tt.want)
+			}
+			if applied := tt.src[:fix.Range.Start] + fix.Replacement + tt.src[fix.Range.End:]; applied != tt.wantFix {
+				t.Errorf("applying the fix gives %q, want %q", applied, tt.wantFix)
+			}
+		})
+	}
+}
+
+// TestReparse verifies that rebuilding the tree of edited lines gives what
+// parsing the serialized lines does.
+func TestReparse(t *testing.T) {
+	const pragma = "<!-- prosemark-binder:v1 -->"
+	tests := []struct {
+		name   string
+		src    string
+		lines  []string
+		ends   []string
+		limits *binder.ParseLimits
+	}{
+		{
+			name:  "inserted entry",
+			src:   pragma + "\n- [A](a.md)\n",
+			lines: []string{pragma, "- [A](a.md)", "  - [B](b.md)", "- [Gone](gone.md)"},
+			ends:  []string{"\n", "\n", "\n", "\n"},
+		},
+		{
+			name:  "byte order mark",
+			src:   "\xEF\xBB\xBF" + pragma + "\n",
+			lines: []string{pragma, "- [A](a.md)"},
+			ends:  []string{"\n", ""},
+		},
+		{
+			name:  "line holding a break",
+			src:   pragma + "\n",
+			lines: []string{pragma, "- [A](a.md)\n- [B](b.md)"},
+			ends:  []string{"\n", "\n"},
+		},
+		{
+			name:  "inner line without an ending",
+			src:   pragma + "\n",
+			lines: []string{pragma, "- [A](a.md)", "- [B](b.md)"},
+			ends:  []string{"", "\n", "\n"},
+		},
+		{
+			name:  "empty last line without an ending",
+			src:   pragma + "\n",
+			lines: []string{pragma, "- [A](a.md)", ""},
+			ends:  []string{"\n", "\n", ""},
+		},
+		{
+			name:  "carriage return joining the next ending",
+			src:   pragma + "\n",
+			lines: []string{pragma, "- [A](a.md)", "", "- [B](b.md)"},
+			ends:  []string{"\n", "\r", "\n", "\n"},
+		},
+		{
+			name:   "file size",
+			src:    pragma + "\n",
+			lines:  []string{pragma, "- [A](a.md)"},
+			ends:   []string{"\n", "\n"},
+			limits: &binder.ParseLimits{MaxFileSize: 40},
+		},
+		{
+			name:  "invalid UTF-8",
+			src:   pragma + "\n",
+			lines: []string{pragma, "- [A](a\xff.md)"},
+			ends:  []string{"\n", "\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			project := &binder.Project{
+				Files:             []string{"a.md", "b.md"},
+				BinderDir:         ".",
+				Limits:            tt.limits,
+				SeverityOverrides: map[string]string{binder.CodeMissingTargetFile: "error"},
+			}
+			r, _, err := binder.Parse(ctx, []byte(tt.src), project)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			r.Lines, r.LineEnds = tt.lines, tt.ends
+			want, wantDiags, wantErr := binder.Parse(ctx, binder.Serialize(r), project)
+
+			diags, err := binder.Reparse(ctx, r, project)
+			if !reflect.DeepEqual(r, want) {
+				t.Errorf("Reparse result = %+v, want %+v", r, want)
+			}
+			if !reflect.DeepEqual(diags, wantDiags) || fmt.Sprint(err) != fmt.Sprint(wantErr) {
+				t.Errorf("Reparse = %+v, %v; want %+v, %v", diags, err, wantDiags, wantErr)
+			}
+		})
+	}
+}
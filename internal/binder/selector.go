@@ -1,8 +1,11 @@
 package binder
 
 import (
+	"errors"
 	"fmt"
+	"path"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -10,16 +13,28 @@ import (
 var selectorIndexRE = regexp.MustCompile(`^(.*)\[(\d+)\]$`)
 
 // EvalSelector evaluates a selector expression against the given root node.
-// Fatal errors (OPE001, OPE002) are returned in the []Diagnostic return value.
-// Warnings (OPW001) are returned in SelectorResult.Warnings.
+// Fatal errors (OPE001, OPE002, OPE011) are returned in the []Diagnostic
+// return value. Warnings (OPW001) are returned in SelectorResult.Warnings.
 func EvalSelector(selector string, root *Node) (SelectorResult, []Diagnostic) {
-	segments := strings.Split(selector, ":")
+	segments, errDiags := parseSelector(selector)
+	if errDiags != nil {
+		return SelectorResult{}, errDiags
+	}
 
 	var result SelectorResult
 	currentNodes := []*Node{root}
 
 	for _, seg := range segments {
-		if seg == "." {
+		if seg.kind == segmentRef && seg.ref == "." {
+			continue
+		}
+
+		if seg.kind == segmentPosition {
+			nodes, errDiags := selectPosition(seg, currentNodes)
+			if errDiags != nil {
+				return SelectorResult{}, errDiags
+			}
+			currentNodes = nodes
 			continue
 		}
 
@@ -28,14 +43,13 @@ func EvalSelector(selector string, root *Node) (SelectorResult, []Diagnostic) {
 			children = append(children, n.Children...)
 		}
 
-		fileRef, idx := parseSegment(seg)
-		matches, warnings, errDiags := selectorMatchNodes(fileRef, children)
+		matches, warnings, errDiags := selectorMatchNodes(seg, children)
 		if errDiags != nil {
 			return SelectorResult{}, errDiags
 		}
 		result.Warnings = append(result.Warnings, warnings...)
 
-		nodes, errDiags := applyIndex(fileRef, matches, idx)
+		nodes, errDiags := applyIndex(seg.text, matches, seg.index)
 		if errDiags != nil {
 			return SelectorResult{}, errDiags
 		}
@@ -46,18 +60,260 @@ func EvalSelector(selector string, root *Node) (SelectorResult, []Diagnostic) {
 	return result, nil
 }
 
-// parseSegment splits a selector segment into its file-ref and optional 0-based index.
-// Returns idx == -1 if no [N] qualifier is present.
-func parseSegment(seg string) (string, int) {
-	m := selectorIndexRE.FindStringSubmatch(seg)
-	if m == nil {
-		return seg, -1
+// IsPathSelector reports whether selector navigates down from the root with
+// EvalSelector rather than searching the whole tree: it has more than one
+// segment, an [N] index, or a #N position. A selector that does not parse
+// is a path selector too, so that EvalSelector reports its OPE011.
+func IsPathSelector(selector string) bool {
+	segments, errDiags := parseSelector(selector)
+	if errDiags != nil {
+		return true
+	}
+	return len(segments) > 1 || segments[0].index >= 0 || segments[0].kind == segmentPosition
+}
+
+// SelectorMatches reports whether selector, taken whole as a single
+// segment, matches n: a stem, relative path, target, or case-insensitive
+// title, a glob over targets and stems, or a title:"..." title. Only a
+// literal match counts for a selector with an index or position, so a title
+// such as "Draft [2]" still matches itself.
+func SelectorMatches(selector string, n *Node) bool {
+	if nodeMatchesSelector(selector, n) {
+		return true
+	}
+	segments, errDiags := parseSelector(selector)
+	if errDiags != nil || len(segments) != 1 || segments[0].index >= 0 || segments[0].kind == segmentPosition {
+		return false
+	}
+	return segments[0].matches(n)
+}
+
+// FindNodes resolves selector the way the binder operations do: "." is
+// the root, path selectors (see IsPathSelector) navigate with EvalSelector,
+// and anything else searches the whole tree with SelectorMatches. No match
+// is OPE001; several matches are all returned with an OPW001 warning.
+func FindNodes(selector string, root *Node) (SelectorResult, []Diagnostic) {
+	if selector == "." {
+		return SelectorResult{Nodes: []*Node{root}}, nil
+	}
+	if IsPathSelector(selector) {
+		return EvalSelector(selector, root)
+	}
+	segments, _ := parseSelector(selector)
+	var result SelectorResult
+	var search func(n *Node)
+	search = func(n *Node) {
+		for _, c := range n.Children {
+			if segments[0].matches(c) {
+				result.Nodes = append(result.Nodes, c)
+			}
+			search(c)
+		}
+	}
+	search(root)
+	switch {
+	case len(result.Nodes) == 0:
+		return SelectorResult{}, []Diagnostic{newSelectorDiag("error", CodeSelectorNoMatch,
+			fmt.Sprintf("selector %q matched no nodes", selector))}
+	case len(result.Nodes) > 1:
+		result.Warnings = []Diagnostic{newSelectorDiag("warning", CodeMultiMatch,
+			fmt.Sprintf("selector %q matched %d nodes", selector, len(result.Nodes)))}
+	}
+	return result, nil
+}
+
+// PathSelector returns a selector that matches exactly the node at path,
+// the 0-based child indices leading to it from root: one indexed segment
+// per level after a leading ".", such as ".:part.md[0]:ch1.md[1]". The "."
+// keeps even a top-level selector a path, which operations resolve from the
+// root rather than by searching the whole tree. It reports false when path
+// leads nowhere or passes through a node no segment can single out: a
+// placeholder, a target containing ":", "[" or a quote, or a target that
+// also matches a sibling with a different target.
+func PathSelector(root *Node, path []int) (string, bool) {
+	segments := []string{"."}
+	cur := root
+	for _, i := range path {
+		if i < 0 || i >= len(cur.Children) {
+			return "", false
+		}
+		n := cur.Children[i]
+		if n.Target == "" || strings.ContainsAny(n.Target, `:["`) {
+			return "", false
+		}
+		idx := 0
+		for j, sib := range cur.Children {
+			if !nodeMatchesSelector(n.Target, sib) {
+				continue
+			}
+			if sib.Target != n.Target {
+				return "", false
+			}
+			if j < i {
+				idx++
+			}
+		}
+		segments = append(segments, fmt.Sprintf("%s[%d]", n.Target, idx))
+		cur = n
+	}
+	if len(path) == 0 {
+		return "", false
+	}
+	return strings.Join(segments, ":"), true
+}
+
+// Kinds of selector segment.
+const (
+	segmentRef      = iota // a stem, relative path, target, or title
+	segmentGlob            // a glob pattern over targets and stems
+	segmentTitle           // a title:"..." title
+	segmentPosition        // a #N or #last child position
+)
+
+// selectorSegment is one parsed ":"-separated segment of a selector.
+type selectorSegment struct {
+	kind  int
+	text  string // the segment as written, without its [N] index
+	ref   string // the stem, path, glob, or unquoted title
+	index int    // the 0-based [N] index, or -1 when there is none
+	pos   int    // the 1-based #N position, or 0 for #last
+}
+
+// parseSelector splits selector into its segments and parses each one,
+// returning OPE011 for a selector that is not well formed.
+func parseSelector(selector string) ([]selectorSegment, []Diagnostic) {
+	invalid := func(err error) []Diagnostic {
+		return []Diagnostic{newSelectorDiag("error", CodeInvalidSelector,
+			fmt.Sprintf("invalid selector %q: %v", selector, err))}
 	}
-	idx := 0
-	for i := 0; i < len(m[2]); i++ {
-		idx = idx*10 + int(m[2][i]-'0')
+	parts, err := splitSelector(selector)
+	if err != nil {
+		return nil, invalid(err)
 	}
-	return m[1], idx
+	segments := make([]selectorSegment, 0, len(parts))
+	for _, part := range parts {
+		seg, err := parseSegment(part)
+		if err != nil {
+			return nil, invalid(err)
+		}
+		segments = append(segments, seg)
+	}
+	return segments, nil
+}
+
+// splitSelector splits selector at the colons outside double quotes. The
+// colon of a title:"..." segment does not split it.
+func splitSelector(selector string) ([]string, error) {
+	var parts []string
+	start, quoted := 0, false
+	for i := 0; i < len(selector); i++ {
+		switch {
+		case selector[i] == '"':
+			quoted = !quoted
+		case selector[i] == ':' && !quoted:
+			if selector[start:i] == "title" && strings.HasPrefix(selector[i+1:], `"`) {
+				continue
+			}
+			parts = append(parts, selector[start:i])
+			start = i + 1
+		}
+	}
+	if quoted {
+		return nil, errors.New("unterminated quote")
+	}
+	return append(parts, selector[start:]), nil
+}
+
+// parseSegment parses one selector segment. An [N] index qualifier is
+// split off first; a segment starting with "#" (or ".#") is a position, one
+// starting with title: a quoted title, and one containing *, ? or [ a glob.
+func parseSegment(seg string) (selectorSegment, error) {
+	if seg == "" {
+		return selectorSegment{}, errors.New("empty segment")
+	}
+	if rest, ok := strings.CutPrefix(strings.TrimPrefix(seg, "."), "#"); ok {
+		return parsePosition(seg, rest)
+	}
+	s := selectorSegment{kind: segmentRef, text: seg, ref: seg, index: -1}
+	if m := selectorIndexRE.FindStringSubmatch(seg); m != nil {
+		idx, err := strconv.Atoi(m[2])
+		if err != nil {
+			return selectorSegment{}, fmt.Errorf("index [%s] out of range", m[2])
+		}
+		s.text, s.ref, s.index = m[1], m[1], idx
+		if s.ref == "" {
+			return selectorSegment{}, fmt.Errorf("index [%d] has nothing to qualify", idx)
+		}
+	}
+	if quoted, ok := strings.CutPrefix(s.ref, "title:"); ok {
+		title, err := strconv.Unquote(quoted)
+		if err != nil || !strings.HasPrefix(quoted, `"`) {
+			return selectorSegment{}, fmt.Errorf("title %s must be a double-quoted string", quoted)
+		}
+		if title == "" {
+			return selectorSegment{}, errors.New("empty title")
+		}
+		s.kind, s.ref = segmentTitle, title
+		return s, nil
+	}
+	if strings.Contains(s.ref, `"`) {
+		return selectorSegment{}, fmt.Errorf("unexpected quote in %q", s.ref)
+	}
+	if strings.ContainsAny(s.ref, "*?[") {
+		if _, err := path.Match(s.ref, ""); err != nil {
+			return selectorSegment{}, fmt.Errorf("bad glob %q", s.ref)
+		}
+		s.kind = segmentGlob
+	}
+	return s, nil
+}
+
+// parsePosition parses the #N or #last segment seg, rest being what
+// follows its "#".
+func parsePosition(seg, rest string) (selectorSegment, error) {
+	s := selectorSegment{kind: segmentPosition, text: seg, index: -1}
+	if rest == "last" {
+		return s, nil
+	}
+	n, err := strconv.Atoi(rest)
+	if err != nil || n < 1 || strings.HasPrefix(rest, "+") {
+		return selectorSegment{}, fmt.Errorf("position %q must be #N, counting from 1, or #last", seg)
+	}
+	s.pos = n
+	return s, nil
+}
+
+// matches reports whether n matches the non-positional segment s.
+func (s selectorSegment) matches(n *Node) bool {
+	switch s.kind {
+	case segmentTitle:
+		return strings.EqualFold(n.Title, s.ref)
+	case segmentGlob:
+		return nodeMatchesSelector(s.ref, n) || nodeMatchesGlob(s.ref, n)
+	default:
+		return nodeMatchesSelector(s.ref, n)
+	}
+}
+
+// selectPosition returns the child at the position of seg under each of
+// nodes, skipping nodes with too few children. Returns OPE001 if none has
+// a child there.
+func selectPosition(seg selectorSegment, nodes []*Node) ([]*Node, []Diagnostic) {
+	var selected []*Node
+	for _, n := range nodes {
+		switch {
+		case len(n.Children) == 0:
+		case seg.pos == 0:
+			selected = append(selected, n.Children[len(n.Children)-1])
+		case seg.pos <= len(n.Children):
+			selected = append(selected, n.Children[seg.pos-1])
+		}
+	}
+	if len(selected) == 0 {
+		return nil, []Diagnostic{newSelectorDiag("error", CodeSelectorNoMatch,
+			fmt.Sprintf("selector %q matched no nodes", seg.text))}
+	}
+	return selected, nil
 }
 
 // applyIndex returns the Nth match (0-based) when idx >= 0, or all matches when idx == -1.
@@ -73,12 +329,15 @@ func applyIndex(fileRef string, matches []*Node, idx int) ([]*Node, []Diagnostic
 	return []*Node{matches[idx]}, nil
 }
 
-// selectorMatchNodes finds all nodes in the flat list whose target matches fileRef.
-// Returns matching nodes, OPW001 warnings, and any fatal OPE001/OPE002 diagnostics.
-func selectorMatchNodes(fileRef string, nodes []*Node) ([]*Node, []Diagnostic, []Diagnostic) {
+// selectorMatchNodes finds all nodes in the flat list that match seg.
+// Returns matching nodes, OPW001 warnings, and any fatal OPE001/OPE002
+// diagnostics. Only a plain file reference is ambiguous when it matches
+// nodes with different targets; globs and titles may match several files.
+func selectorMatchNodes(seg selectorSegment, nodes []*Node) ([]*Node, []Diagnostic, []Diagnostic) {
+	fileRef := seg.text
 	var matches []*Node
 	for _, n := range nodes {
-		if nodeMatchesSelector(fileRef, n) {
+		if seg.matches(n) {
 			matches = append(matches, n)
 		}
 	}
@@ -90,7 +349,7 @@ func selectorMatchNodes(fileRef string, nodes []*Node) ([]*Node, []Diagnostic, [
 
 	firstTarget := matches[0].Target
 	for _, m := range matches[1:] {
-		if m.Target != firstTarget {
+		if seg.kind == segmentRef && m.Target != firstTarget {
 			return nil, nil, []Diagnostic{newSelectorDiag("error", CodeAmbiguousBareStem,
 				fmt.Sprintf("selector %q is ambiguous: matches nodes with different targets", fileRef))}
 		}
@@ -98,7 +357,7 @@ func selectorMatchNodes(fileRef string, nodes []*Node) ([]*Node, []Diagnostic, [
 
 	if len(matches) > 1 {
 		w := newSelectorDiag("warning", CodeMultiMatch,
-			fmt.Sprintf("selector %q matched %d nodes with the same target", fileRef, len(matches)))
+			fmt.Sprintf("selector %q matched %d nodes", fileRef, len(matches)))
 		return matches, []Diagnostic{w}, nil
 	}
 
@@ -117,6 +376,26 @@ func nodeMatchesSelector(fileRef string, n *Node) bool {
 		strings.EqualFold(n.Title, fileRef)
 }
 
+// nodeMatchesGlob reports whether n's target file matches the glob
+// pattern: with a "/", its relative path with or without .md; otherwise its
+// stem or file name. Fragments are ignored and placeholders never match.
+func nodeMatchesGlob(pattern string, n *Node) bool {
+	if n.Target == "" {
+		return false
+	}
+	file, _, _ := strings.Cut(n.Target, "#")
+	candidates := []string{stemFromPath(file), path.Base(file)}
+	if strings.Contains(pattern, "/") {
+		candidates = []string{file, strings.TrimSuffix(file, ".md")}
+	}
+	for _, c := range candidates {
+		if ok, err := path.Match(pattern, c); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
 // newSelectorDiag constructs a Diagnostic with no source location.
 func newSelectorDiag(severity, code, message string) Diagnostic {
 	return Diagnostic{Severity: severity, Code: code, Message: message}
@@ -346,3 +346,171 @@ func TestEvalSelector_IndexQualified_WithColonDescent(t *testing.T) {
 		t.Errorf("got target %q, want %q", result.Nodes[0].Target, "p1/section.md")
 	}
 }
+
+func TestFindNodes(t *testing.T) {
+	deep := makeTestNode("part/scene.md", "The Scene")
+	chapter := makeTestNode("chapter.md", "Chapter One", deep)
+	root := makeTestRoot(chapter, makeTestNode("notes.md", "Notes"), makeTestNode("other/take.md", "The Scene"))
+
+	tests := []struct {
+		name        string
+		selector    string
+		wantTargets []string
+		wantWarn    string
+		wantErr     string
+	}{
+		{"root", ".", []string{""}, "", ""},
+		{"nested stem", "scene", []string{"part/scene.md"}, "", ""},
+		{"nested path", "part/scene", []string{"part/scene.md"}, "", ""},
+		{"title ignores case", "chapter one", []string{"chapter.md"}, "", ""},
+		{"path navigation", "chapter:part/scene.md", []string{"part/scene.md"}, "", ""},
+		{"index", "notes[0]", []string{"notes.md"}, "", ""},
+		{"no match", "missing", nil, "", binder.CodeSelectorNoMatch},
+		{"several matches", "the scene", []string{"part/scene.md", "other/take.md"}, binder.CodeMultiMatch, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, diags := binder.FindNodes(tt.selector, root)
+			if got := firstDiagCode(diags, "error"); got != tt.wantErr {
+				t.Fatalf("error code = %q, want %q", got, tt.wantErr)
+			}
+			if got := firstDiagCode(res.Warnings, "warning"); got != tt.wantWarn {
+				t.Errorf("warning code = %q, want %q", got, tt.wantWarn)
+			}
+			var targets []string
+			for _, n := range res.Nodes {
+				targets = append(targets, n.Target)
+			}
+			if len(targets) != len(tt.wantTargets) {
+				t.Fatalf("targets = %q, want %q", targets, tt.wantTargets)
+			}
+			for i := range targets {
+				if targets[i] != tt.wantTargets[i] {
+					t.Errorf("targets = %q, want %q", targets, tt.wantTargets)
+				}
+			}
+		})
+	}
+}
+
+func TestFindNodes_ExtendedSyntax(t *testing.T) {
+	part := makeTestNode("part1.md", "Part One",
+		makeTestNode("chapters/one.md", "Chapter One"),
+		makeTestNode("chapters/two.md", "Chapter Two: Return"),
+	)
+	root := makeTestRoot(part, makeTestNode("notes.md", "Notes"), makeTestNode("chapters/three.md", "Chapter Three"), makeTestNode("", "Later"))
+
+	tests := []struct {
+		name        string
+		selector    string
+		wantTargets []string
+		wantErr     string
+	}{
+		{"glob path", "chapters/*", []string{"chapters/one.md", "chapters/two.md", "chapters/three.md"}, ""},
+		{"glob stem", "t*", []string{"chapters/two.md", "chapters/three.md"}, ""},
+		{"glob under parent", "part1:chapters/*", []string{"chapters/one.md", "chapters/two.md"}, ""},
+		{"glob with index", "part1:chapters/*[1]", []string{"chapters/two.md"}, ""},
+		{"root position", ".#2", []string{"notes.md"}, ""},
+		{"position under parent", "part1:#1", []string{"chapters/one.md"}, ""},
+		{"last position", "part1:#last", []string{"chapters/two.md"}, ""},
+		{"position out of range", "part1:#3", nil, binder.CodeSelectorNoMatch},
+		{"position under a leaf", "notes:#1", nil, binder.CodeSelectorNoMatch},
+		{"glob skips placeholders", "L*", nil, binder.CodeSelectorNoMatch},
+		{"title", `title:"chapter one"`, []string{"chapters/one.md"}, ""},
+		{"title with colon", `title:"Chapter Two: Return"`, []string{"chapters/two.md"}, ""},
+		{"title under parent", `part1:title:"Chapter One"`, []string{"chapters/one.md"}, ""},
+		{"title no match", `title:"Chapter"`, nil, binder.CodeSelectorNoMatch},
+		{"position zero", "part1:#0", nil, binder.CodeInvalidSelector},
+		{"bad position", "#first", nil, binder.CodeInvalidSelector},
+		{"empty segment", "part1::one", nil, binder.CodeInvalidSelector},
+		{"unterminated quote", `title:"Chapter`, nil, binder.CodeInvalidSelector},
+		{"text after title", `title:"Chapter" One`, nil, binder.CodeInvalidSelector},
+		{"empty title", `title:""`, nil, binder.CodeInvalidSelector},
+		{"bad glob", "chapters/[a", nil, binder.CodeInvalidSelector},
+		{"bare index", "[0]", nil, binder.CodeInvalidSelector},
+		{"index overflow", "notes[99999999999999999999]", nil, binder.CodeInvalidSelector},
+		{"stray quote", `"notes"`, nil, binder.CodeInvalidSelector},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, diags := binder.FindNodes(tt.selector, root)
+			if got := firstDiagCode(diags, "error"); got != tt.wantErr {
+				t.Fatalf("error code = %q, want %q (%v)", got, tt.wantErr, diags)
+			}
+			var targets []string
+			for _, n := range res.Nodes {
+				targets = append(targets, n.Target)
+			}
+			if len(targets) != len(tt.wantTargets) {
+				t.Fatalf("targets = %q, want %q", targets, tt.wantTargets)
+			}
+			for i := range targets {
+				if targets[i] != tt.wantTargets[i] {
+					t.Errorf("targets = %q, want %q", targets, tt.wantTargets)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	n := makeTestNode("chapters/one.md#intro", "Draft [2]")
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"one", true},
+		{"chapters/o*", true},
+		{"o?e", true},
+		{`title:"draft [2]"`, true},
+		{"Draft [2]", true},
+		{"two", false},
+		{"#1", false},
+		{`title:"`, false},
+	}
+
+	for _, tt := range tests {
+		if got := binder.SelectorMatches(tt.selector, n); got != tt.want {
+			t.Errorf("SelectorMatches(%q) = %v, want %v", tt.selector, got, tt.want)
+		}
+	}
+}
+
+func TestPathSelector(t *testing.T) {
+	ch1 := makeTestNode("ch1.md", "One")
+	again := makeTestNode("ch1.md", "Again")
+	// other.md's title matches ch2.md's target, so ch2.md cannot be singled out.
+	part := makeTestNode("part.md", "Part", makeTestNode("ch2.md", "Two"), ch1, makeTestNode("other.md", "ch2.md"))
+	root := &binder.Node{Type: "root", Children: []*binder.Node{part, again, makeTestNode("", "Placeholder"), makeTestNode("ch1.md", "Third")}}
+	tests := []struct {
+		path   []int
+		want   string
+		wantOK bool
+	}{
+		{[]int{0, 1}, ".:part.md[0]:ch1.md[0]", true},
+		{[]int{1}, ".:ch1.md[0]", true},
+		{[]int{3}, ".:ch1.md[1]", true},
+		{[]int{2}, "", false},
+		{[]int{4}, "", false},
+		{[]int{0, 0}, "", false},
+		{[]int{0, 5}, "", false},
+		{nil, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := binder.PathSelector(root, tt.path)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("PathSelector(%v) = %q, %v; want %q, %v", tt.path, got, ok, tt.want, tt.wantOK)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		res, diags := binder.FindNodes(got, root)
+		if len(diags) != 0 || len(res.Nodes) != 1 {
+			t.Errorf("FindNodes(%q) = %+v, %+v; want one node", got, res.Nodes, diags)
+		}
+	}
+}
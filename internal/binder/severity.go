@@ -0,0 +1,26 @@
+package binder
+
+// SeverityOff is a severity override that drops a diagnostic entirely.
+const SeverityOff = "off"
+
+// ApplySeverityOverrides returns diags with each diagnostic whose code has an
+// entry in overrides reported at that severity: "error" promotes a warning
+// so it fails the command, "warning" or "info" sets the level it is shown
+// at, and SeverityOff drops it. Error-severity diagnostics are never overridden,
+// since they mark a binder the tools cannot trust. diags is not modified.
+func ApplySeverityOverrides(diags []Diagnostic, overrides map[string]string) []Diagnostic {
+	if len(overrides) == 0 {
+		return diags
+	}
+	out := make([]Diagnostic, 0, len(diags))
+	for _, d := range diags {
+		if sev, ok := overrides[d.Code]; ok && d.Severity != "error" {
+			if sev == SeverityOff {
+				continue
+			}
+			d.Severity = sev
+		}
+		out = append(out, d)
+	}
+	return out
+}
@@ -0,0 +1,55 @@
+package binder_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestApplySeverityOverrides(t *testing.T) {
+	diags := []binder.Diagnostic{
+		{Severity: "warning", Code: binder.CodeMissingTargetFile},
+		{Severity: "warning", Code: binder.CodeLinkOutsideList},
+		{Severity: "error", Code: binder.CodePathEscapesRoot},
+		{Severity: binder.SeverityInfo, Code: binder.CodeReferenceSectionLink},
+	}
+	overrides := map[string]string{
+		binder.CodeMissingTargetFile:    "error",
+		binder.CodeLinkOutsideList:      binder.SeverityOff,
+		binder.CodePathEscapesRoot:      binder.SeverityOff,
+		binder.CodeReferenceSectionLink: "warning",
+	}
+	got := binder.ApplySeverityOverrides(diags, overrides)
+	want := []binder.Diagnostic{
+		{Severity: "error", Code: binder.CodeMissingTargetFile},
+		{Severity: "error", Code: binder.CodePathEscapesRoot},
+		{Severity: "warning", Code: binder.CodeReferenceSectionLink},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ApplySeverityOverrides() = %+v, want %+v", got, want)
+	}
+	if diags[0].Severity != "warning" {
+		t.Error("ApplySeverityOverrides modified its input")
+	}
+}
+
+func TestParse_SeverityOverrides(t *testing.T) {
+	src := []byte("<!-- prosemark-binder:v1 -->\n- [Gone](gone.md)\n\nSee [notes](notes.md).\n")
+	project := &binder.Project{
+		Files:     []string{"notes.md"},
+		BinderDir: ".",
+		SeverityOverrides: map[string]string{
+			binder.CodeMissingTargetFile: "error",
+			binder.CodeLinkOutsideList:   binder.SeverityOff,
+		},
+	}
+	_, diags, err := binder.Parse(context.Background(), src, project)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(diags) != 1 || diags[0].Code != binder.CodeMissingTargetFile || diags[0].Severity != "error" {
+		t.Errorf("diagnostics = %+v, want only BNDW004 as an error", diags)
+	}
+}
@@ -0,0 +1,125 @@
+package binder
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// ExpandSubBinders puts the tree of each binder that result links to
+// (result.SubBinders) in place of its link: a placeholder titled by the link,
+// with Binder set to the linked binder, whose children are that binder's
+// entries. Nested binders are read through read by project-relative path,
+// parsed against the part of project under their directory, and expanded in
+// turn; their targets are rebased onto the project root.
+//
+// diags are result's parse diagnostics; the nested binders' diagnostics are
+// added to them with their binder's path prefixed to the message. A nested binder that links back to a binder
+// it is nested in (CodeSubBinderCycle) or that cannot be read
+// (CodeUnreadableSubBinder) is left empty.
+func ExpandSubBinders(ctx context.Context, result *ParseResult, diags []Diagnostic, project *Project, read func(target string) ([]byte, error)) []Diagnostic {
+	return expandSubBinders(ctx, result, diags, project, read, ".", []string{project.ActiveBinderFile()})
+}
+
+// expandSubBinders is ExpandSubBinders for the binder in dir, a directory
+// relative to the project root; stack lists the project-relative paths of
+// the binders being expanded, outermost first.
+func expandSubBinders(ctx context.Context, result *ParseResult, diags []Diagnostic, project *Project, read func(string) ([]byte, error), dir string, stack []string) []Diagnostic {
+	if len(result.SubBinders) == 0 {
+		return diags
+	}
+	nested := make([][]Diagnostic, len(result.SubBinders))
+	for i := len(result.SubBinders) - 1; i >= 0; i-- {
+		link := result.SubBinders[i]
+		group := &Node{Type: "placeholder", Title: link.Title, Binder: link.Target, Children: []*Node{}, Line: link.Line}
+		group.Children, nested[i] = readSubBinder(ctx, link, project, read, dir, stack)
+		link.Parent.Children = slices.Insert(link.Parent.Children, link.Index, group)
+	}
+	return append(diags, slices.Concat(nested...)...)
+}
+
+// readSubBinder returns the entries of the binder link names, with targets
+// relative to the binder in dir, and the diagnostics of reading it.
+func readSubBinder(ctx context.Context, link *SubBinderLink, project *Project, read func(string) ([]byte, error), dir string, stack []string) ([]*Node, []Diagnostic) {
+	full := path.Join(dir, link.Target)
+	warn := func(code, message string) []Diagnostic {
+		return []Diagnostic{{Severity: "warning", Code: code, Message: message, Location: &Location{Line: link.Line}}}
+	}
+	if slices.Contains(stack, full) {
+		chain := strings.Join(append(slices.Clone(stack), full), " → ")
+		return []*Node{}, warn(CodeSubBinderCycle, fmt.Sprintf("nested binder %s is already being expanded (%s)", link.Target, chain))
+	}
+	src, err := read(full)
+	if err != nil {
+		return []*Node{}, warn(CodeUnreadableSubBinder, fmt.Sprintf("cannot read nested binder %s: %v", link.Target, err))
+	}
+	sub := project.nested(link.Target)
+	result, diags, err := Parse(ctx, src, sub)
+	if err != nil {
+		return []*Node{}, warn(CodeUnreadableSubBinder, fmt.Sprintf("cannot parse nested binder %s: %v", link.Target, err))
+	}
+	diags = expandSubBinders(ctx, result, diags, sub, read, path.Dir(full), append(slices.Clone(stack), full))
+	for i := range diags {
+		diags[i].Message = link.Target + ": " + diags[i].Message
+	}
+	rebaseTargets(result.Root.Children, path.Dir(link.Target))
+	return result.Root.Children, diags
+}
+
+// rebaseTargets prefixes dir to the targets and nested binders of nodes and
+// their descendants.
+func rebaseTargets(nodes []*Node, dir string) {
+	if dir == "." {
+		return
+	}
+	for _, n := range nodes {
+		if n.Target != "" {
+			n.Target = path.Join(dir, n.Target)
+		}
+		if n.Binder != "" {
+			n.Binder = path.Join(dir, n.Binder)
+		}
+		rebaseTargets(n.Children, dir)
+	}
+}
+
+// nested returns p as seen by the binder at target, a project-relative
+// path: only files under target's directory, relative to it, with target the
+// active binder and the project's other binders there its alternates.
+func (p *Project) nested(target string) *Project {
+	dir := path.Dir(target)
+	rel := func(f string) (string, bool) {
+		if dir == "." {
+			return f, true
+		}
+		return strings.CutPrefix(f, dir+"/")
+	}
+	sub := *p
+	sub.BinderFile = path.Base(target)
+	sub.Files = []string{}
+	for _, f := range p.Files {
+		if r, ok := rel(f); ok {
+			sub.Files = append(sub.Files, r)
+		}
+	}
+	sub.AltBinders = nil
+	for _, b := range append([]string{p.ActiveBinderFile()}, p.AltBinders...) {
+		if b = normalizeBinderPath(b); b == target {
+			continue
+		}
+		if r, ok := rel(b); ok {
+			sub.AltBinders = append(sub.AltBinders, r)
+		}
+	}
+	if p.Aliases != nil {
+		sub.Aliases = make(map[string][]string)
+		for f, a := range p.Aliases {
+			if r, ok := rel(f); ok {
+				sub.Aliases[r] = a
+			}
+		}
+	}
+	return &sub
+}
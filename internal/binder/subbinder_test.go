@@ -0,0 +1,178 @@
+package binder_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// outlineLines renders nodes one per line as "title target [binder]",
+// indented two spaces per level.
+func outlineLines(nodes []*binder.Node, depth int) []string {
+	var lines []string
+	for _, n := range nodes {
+		line := strings.Repeat("  ", depth) + n.Title
+		if n.Target != "" {
+			line += " " + n.Target
+		}
+		if n.Binder != "" {
+			line += " [" + n.Binder + "]"
+		}
+		lines = append(lines, line)
+		lines = append(lines, outlineLines(n.Children, depth+1)...)
+	}
+	return lines
+}
+
+func TestExpandSubBinders(t *testing.T) {
+	const pragma = "<!-- prosemark-binder:v1 -->\n"
+	binders := map[string]string{
+		"_binder.md": pragma +
+			"- [Chapter One](ch1.md)\n" +
+			"  - [Part Two](part-two/_binder.md)\n" +
+			"- [Extra](./_extra_binder.md)\n" +
+			"- [Gone](gone/_binder.md)\n",
+		"_extra_binder.md":        pragma + "- [Main](_binder.md)\n",
+		"part-two/_binder.md":     pragma + "- [Chapter Two](ch2.md)\n- [Act](act/_binder.md)\n- [Chapter Four](ch4.md)\n",
+		"part-two/act/_binder.md": pragma + "- [Chapter Three](ch3.md)\n",
+	}
+	read := func(target string) ([]byte, error) {
+		if src, ok := binders[target]; ok {
+			return []byte(src), nil
+		}
+		return nil, errors.New("no such file")
+	}
+	project := &binder.Project{
+		Files:      []string{"ch1.md", "part-two/ch2.md", "part-two/act/ch3.md"},
+		BinderDir:  ".",
+		AltBinders: []string{"_extra_binder.md", "part-two/_binder.md", "part-two/act/_binder.md", "gone/_binder.md"},
+		SubBinders: true,
+	}
+	ctx := context.Background()
+	result, diags, err := binder.Parse(ctx, []byte(binders["_binder.md"]), project)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(result.SubBinders) != 3 {
+		t.Fatalf("SubBinders = %d links, want 3", len(result.SubBinders))
+	}
+
+	diags = binder.ExpandSubBinders(ctx, result, diags, project, read)
+
+	want := []string{
+		"Chapter One ch1.md",
+		"  Part Two [part-two/_binder.md]",
+		"    Chapter Two part-two/ch2.md",
+		"    Act [part-two/act/_binder.md]",
+		"      Chapter Three part-two/act/ch3.md",
+		"    Chapter Four part-two/ch4.md",
+		"Extra [_extra_binder.md]",
+		"  Main [_binder.md]",
+		"Gone [gone/_binder.md]",
+	}
+	if got := outlineLines(result.Root.Children, 0); !reflect.DeepEqual(got, want) {
+		t.Errorf("expanded tree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	var got []string
+	for _, d := range diags {
+		got = append(got, fmt.Sprintf("%s %s", d.Code, d.Message))
+	}
+	wantDiags := []string{
+		"BNDW004 part-two/_binder.md: Target file ch4.md is not present in the project",
+		"PMKW010 _extra_binder.md: nested binder _binder.md is already being expanded (_binder.md → _extra_binder.md → _binder.md)",
+		"PMKW011 cannot read nested binder gone/_binder.md: no such file",
+	}
+	if !reflect.DeepEqual(got, wantDiags) {
+		t.Errorf("diagnostics =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(wantDiags, "\n"))
+	}
+}
+
+func TestExpandSubBinders_NestedAliasesAndParseErrors(t *testing.T) {
+	const pragma = "<!-- prosemark-binder:v1 -->\n"
+	binders := map[string]string{
+		"part/_binder.md": pragma + "- [[Second]]\n",
+		"bad/_binder.md":  "\xff",
+	}
+	read := func(target string) ([]byte, error) { return []byte(binders[target]), nil }
+	project := &binder.Project{
+		Files:      []string{"ch1.md", "part/ch2.md"},
+		BinderDir:  ".",
+		AltBinders: []string{"part/_binder.md", "bad/_binder.md"},
+		SubBinders: true,
+		Aliases:    map[string][]string{"ch1.md": {"First"}, "part/ch2.md": {"Second"}},
+	}
+	ctx := context.Background()
+	src := pragma + "- [Part](part/_binder.md)\n- [Bad](bad/_binder.md)\n"
+	result, diags, err := binder.Parse(ctx, []byte(src), project)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	diags = binder.ExpandSubBinders(ctx, result, diags, project, read)
+
+	want := []string{
+		"Part [part/_binder.md]",
+		"  Second part/ch2.md",
+		"Bad [bad/_binder.md]",
+	}
+	if got := outlineLines(result.Root.Children, 0); !reflect.DeepEqual(got, want) {
+		t.Errorf("expanded tree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	var got []string
+	for _, d := range diags {
+		got = append(got, fmt.Sprintf("%s %s", d.Code, d.Message))
+	}
+	wantDiags := []string{
+		"PMKW003 part/_binder.md: wikilink [[Second]] resolved through the frontmatter alias of ch2.md",
+		"PMKW011 cannot parse nested binder bad/_binder.md: binder file contains invalid UTF-8 content",
+	}
+	if !reflect.DeepEqual(got, wantDiags) {
+		t.Errorf("diagnostics =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(wantDiags, "\n"))
+	}
+}
+
+func TestExpandSubBinders_SelfLinkStays(t *testing.T) {
+	src := "<!-- prosemark-binder:v1 -->\n- [Chapter One](ch1.md)\n- [Itself](./_binder.md)\n"
+	project := &binder.Project{Files: []string{"ch1.md"}, BinderDir: "."}
+	ctx := context.Background()
+	result, diags, err := binder.Parse(ctx, []byte(src), project)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	diags = binder.ExpandSubBinders(ctx, result, diags, project, func(string) ([]byte, error) {
+		t.Fatal("read called for a self-link")
+		return nil, nil
+	})
+	if len(result.Root.Children) != 1 || len(diags) != 1 || diags[0].Code != binder.CodeSelfReferentialLink {
+		t.Errorf("children = %d, diagnostics = %v; want 1 child and BNDW008", len(result.Root.Children), diags)
+	}
+}
+
+func TestParse_SubBinderLinksAreNotSelfReferences(t *testing.T) {
+	src := "<!-- prosemark-binder:v1 -->\n- [Part](part/x_binder.md)\n- [Itself](_binder.md)\n"
+	for _, subBinders := range []bool{false, true} {
+		project := &binder.Project{BinderDir: ".", AltBinders: []string{"part/x_binder.md"}, SubBinders: subBinders}
+		result, diags, err := binder.Parse(context.Background(), []byte(src), project)
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		var lines []int
+		for _, d := range diags {
+			if d.Code == binder.CodeSelfReferentialLink {
+				lines = append(lines, d.Location.Line)
+			}
+		}
+		want := []int{2, 3}
+		if subBinders {
+			want = []int{3}
+		}
+		if !reflect.DeepEqual(lines, want) || len(result.SubBinders) != 1 {
+			t.Errorf("sub_binders %v: BNDW008 on lines %v, %d sub-binder links; want %v and 1", subBinders, lines, len(result.SubBinders), want)
+		}
+	}
+}
@@ -4,33 +4,59 @@ package binder
 import "encoding/json"
 
 // Node is a structural node in the binder tree.
-// Root nodes have Type "root"; leaf/branch nodes have Type "node".
+// Root nodes have Type "root"; leaf/branch nodes have Type "node", or
+// "placeholder" for plain-text items parsed under Project.TextPlaceholders.
 type Node struct {
 	// JSON-exported fields (match parse-result.schema.json)
-	Type     string  `json:"type"`             // "root" | "node"
-	Target   string  `json:"target,omitempty"` // resolved relative path (absent on root)
-	Title    string  `json:"title,omitempty"`  // display text (absent on root)
-	Children []*Node `json:"children"`         // ordered children; never nil (use empty slice)
+	Type        string  `json:"type"`                  // "root" | "node" | "placeholder"
+	Target      string  `json:"target,omitempty"`      // resolved relative path (absent on root)
+	Title       string  `json:"title,omitempty"`       // display text (absent on root)
+	Checked     *bool   `json:"checked,omitempty"`     // GFM task state: nil when the item has no checkbox
+	InCodeFence bool    `json:"inCodeFence,omitempty"` // true only for ParseResult.Fenced pseudo-nodes (BNDW005)
+	Children    []*Node `json:"children"`              // ordered children; never nil (use empty slice)
+	Binder      string  `json:"binder,omitempty"`      // nested binder whose entries are the children (set by ExpandSubBinders)
+	FileTitle   string  `json:"fileTitle,omitempty"`   // frontmatter title of the node file (set by pmk parse --resolve-titles)
 
 	// Source metadata (not serialized to JSON)
-	Line        int    `json:"-"` // 1-based line number of list item
-	Column      int    `json:"-"` // 1-based column of link start
-	ByteOffset  int    `json:"-"` // byte offset from file start
-	EndLine     int    `json:"-"` // last line of this item's content (before children)
-	SubtreeEnd  int    `json:"-"` // last line of this item's subtree (inclusive)
-	Indent      int    `json:"-"` // number of leading whitespace chars (spaces or tabs)
-	IndentChar  byte   `json:"-"` // ' ' or '\t'
-	ListMarker  string `json:"-"` // "-", "*", "+", "1.", "2.", "1)", etc.
-	RawLine     string `json:"-"` // original source line bytes (excluding line ending)
-	InCodeFence bool   `json:"-"` // true if inside a fenced code block (BNDW005)
+	Line       int    `json:"-"` // 1-based line number of list item
+	Column     int    `json:"-"` // 1-based column of link start
+	ByteOffset int    `json:"-"` // byte offset from file start
+	EndLine    int    `json:"-"` // last line of this item's content (before children)
+	SubtreeEnd int    `json:"-"` // last line of this item's subtree (inclusive)
+	Indent     int    `json:"-"` // number of leading whitespace chars (spaces or tabs)
+	IndentChar byte   `json:"-"` // ' ' or '\t'
+	ListMarker string `json:"-"` // "-", "*", "+", "1.", "2.", "1)", etc.
+	RawLine    string `json:"-"` // original source line bytes (excluding line ending)
+	Fence      *Fence `json:"-"` // enclosing fence; set only on ParseResult.Fenced pseudo-nodes
 }
 
 // Diagnostic is a structured error or warning record emitted during parse or operations.
 type Diagnostic struct {
-	Severity string    `json:"severity"` // "error" | "warning"
+	Severity string    `json:"severity"` // "error" | "warning" | "info"
 	Code     string    `json:"code"`     // e.g. "BNDE001", "OPW002"
 	Message  string    `json:"message"`
 	Location *Location `json:"location,omitempty"` // nil if no source location
+	// Suggestion, when set, is a fix tools can apply without asking the
+	// author anything, such as BNDW009's corrected case.
+	Suggestion *Suggestion `json:"suggestion,omitempty"`
+	// Target is the link target the diagnostic concerns, if any, so
+	// human-readable output can list the targets of a group of diagnostics.
+	Target string `json:"-"`
+}
+
+// Suggestion is a machine-applicable fix for a diagnostic: the binder's
+// bytes in Range are replaced with Replacement.
+type Suggestion struct {
+	Message     string `json:"message"`     // the fix, for display: "replace ch1.md with Ch1.md"
+	Replacement string `json:"replacement"` // empty deletes the range
+	Range       Range  `json:"range"`
+}
+
+// Range is a half-open span [Start, End) of byte offsets from the start of
+// the binder file as read, byte order mark included.
+type Range struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
 }
 
 // Location identifies a source position within a binder file.
@@ -51,8 +77,10 @@ type RefDef struct {
 // ParseResult is the structured output of parsing a binder file.
 // Matches parse-result.schema.json when marshaled to JSON (diagnostics are merged at CLI layer).
 type ParseResult struct {
-	Version string `json:"version"` // always "1"
-	Root    *Node  `json:"root"`
+	Version string   `json:"version"`          // always "1"
+	Root    *Node    `json:"root"`             // structural tree
+	Fenced  []*Node  `json:"fenced,omitempty"` // pseudo-nodes inside fenced code blocks; never part of the tree
+	Fences  []*Fence `json:"fences,omitempty"` // fenced code blocks in source order
 
 	// Source metadata (not in JSON schema)
 	Lines      []string          `json:"-"` // original source lines (without endings)
@@ -61,37 +89,126 @@ type ParseResult struct {
 	HasBOM     bool              `json:"-"` // true if input had UTF-8 BOM
 	HasPragma  bool              `json:"-"` // true if pragma line found
 	PragmaLine int               `json:"-"` // 1-based line of pragma (0 if absent)
+	SubBinders []*SubBinderLink  `json:"-"` // links to nested binders, in source order (see ExpandSubBinders)
+}
+
+// SubBinderLink is a binder entry linking to another of the project's
+// binders. The parser makes no node of it, reporting it as BNDW008 unless
+// the project sets SubBinders; ExpandSubBinders puts the linked binder's
+// tree in its place.
+type SubBinderLink struct {
+	Parent *Node  // node the entry would have been a child of
+	Index  int    // position among Parent's children the entry would have had
+	Target string // linked binder, relative to the project root
+	Title  string // link text
+	Line   int    // 1-based line of the link
+}
+
+// Fence is a fenced code block as the parser saw it. Lines from StartLine
+// through EndLine are never part of the tree.
+type Fence struct {
+	Marker    string `json:"marker"`    // opening marker: "```" or "~~~"
+	StartLine int    `json:"startLine"` // 1-based line of the opening marker
+	EndLine   int    `json:"endLine"`   // 1-based line of the closing marker, or the last line when unclosed
+	Closed    bool   `json:"closed"`    // false when the fence runs to the end of the file
+}
+
+// FenceAt returns the fence containing the 1-based line, or nil if the line
+// is not fenced.
+func (r *ParseResult) FenceAt(line int) *Fence {
+	for _, f := range r.Fences {
+		if line >= f.StartLine && line <= f.EndLine {
+			return f
+		}
+	}
+	return nil
 }
 
 // Project holds the set of .md files in the project, populated by filesystem scanning.
 type Project struct {
-	Files     []string `json:"files"`     // relative paths to .md files in the project
-	BinderDir string   `json:"binderDir"` // directory containing the binder file (enables proximity tiebreak)
+	Files      []string `json:"files"`                // relative paths to .md files in the project
+	BinderDir  string   `json:"binderDir"`            // directory containing the binder file (enables proximity tiebreak)
+	BinderFile string   `json:"binderFile,omitempty"` // active binder path relative to the project root (default DefaultBinderFilename)
+	AltBinders []string `json:"altBinders,omitempty"` // other binder files in the project (e.g. nested binders), relative paths
+	// Aliases maps a project file to the alternative names declared in its
+	// frontmatter (Obsidian "aliases:"); wikilinks that match no file stem
+	// resolve through them.
+	Aliases map[string][]string `json:"aliases,omitempty"`
+	// WikilinkResolution selects how a wikilink stem matching several files
+	// is resolved: WikilinkResolutionBinder (the default when empty) or
+	// WikilinkResolutionShortest.
+	WikilinkResolution string `json:"wikilinkResolution,omitempty"`
+	// ReferenceSections lists headings (matched case-insensitively) whose
+	// sections hold intentional prose cross-references: .md links outside
+	// lists there get CodeReferenceSectionLink instead of BNDW006.
+	ReferenceSections []string `json:"referenceSections,omitempty"`
+	// Limits, when non-nil, overrides DefaultParseLimits for this project.
+	Limits *ParseLimits `json:"limits,omitempty"`
+	// IDScheme names the scheme new node files are named by (id_scheme in
+	// .prosemark.yml); empty selects the default, UUIDv7.
+	IDScheme string `json:"idScheme,omitempty"`
+	// TextPlaceholders, when set (text_placeholders in .prosemark.yml),
+	// parses list items with no structural link as placeholder entries of
+	// Type "placeholder" titled by their text, instead of ignoring them.
+	TextPlaceholders bool `json:"textPlaceholders,omitempty"`
+	// SubBinders, when set (sub_binders in .prosemark.yml), has pmk expand
+	// the binders this one links to into its tree; see ExpandSubBinders.
+	// Parse then reports only links to the binder itself as BNDW008.
+	SubBinders bool `json:"subBinders,omitempty"`
+	// Bookmarks maps bookmark names (bookmarks in .prosemark.yml) to the
+	// selectors they stand for; see ResolveBookmark.
+	Bookmarks map[string]string `json:"bookmarks,omitempty"`
+	// SeverityOverrides maps diagnostic codes (diagnostics in
+	// .prosemark.yml) to the severity they are reported at; see
+	// ApplySeverityOverrides.
+	SeverityOverrides map[string]string `json:"severityOverrides,omitempty"`
+	// ListMarker and IndentWidth (list_marker and indent in .prosemark.yml)
+	// style new entries where no sibling shows the binder's own style;
+	// empty and zero select "-" and two spaces.
+	ListMarker  string `json:"listMarker,omitempty"`
+	IndentWidth int    `json:"indentWidth,omitempty"`
 }
 
-// OpSpec is the parsed operation specification from op.json.
+// Wikilink resolution modes for Project.WikilinkResolution.
+const (
+	// WikilinkResolutionBinder prefers a file in the binder's own directory,
+	// then the shallowest path, as Obsidian resolves links from a note.
+	WikilinkResolutionBinder = "binder"
+	// WikilinkResolutionShortest prefers the shallowest path from the project
+	// root regardless of where the binder lives.
+	WikilinkResolutionShortest = "shortest"
+)
+
+// OpSpec is the parsed operation specification from op.json. The
+// conformance runner reads one per fixture; pmk apply reads a batch of them
+// (see ParseOpSpecs).
 type OpSpec struct {
 	Version   string          `json:"version"`   // "1"
 	Operation string          `json:"operation"` // "add" | "delete" | "move"
-	Params    json.RawMessage `json:"params"`    // decoded to specific *Params below
+	Params    json.RawMessage `json:"params"`    // decoded to specific *Params below by DecodeParams
 }
 
 // AddChildParams are parameters for the add-child operation.
 type AddChildParams struct {
-	ParentSelector string `json:"parentSelector"`   // selector for the parent node
-	Target         string `json:"target"`           // relative path of new child file
-	Title          string `json:"title"`            // display title (empty = derive from stem)
-	Position       string `json:"position"`         // "last" | "first" (default: "last")
-	At             *int   `json:"at,omitempty"`     // zero-based index insertion point
-	Before         string `json:"before,omitempty"` // selector of sibling to insert before
-	After          string `json:"after,omitempty"`  // selector of sibling to insert after
-	Force          bool   `json:"force"`            // allow duplicate target
+	ParentSelector string `json:"parentSelector"`       // selector for the parent node
+	Target         string `json:"target"`               // relative path of new child file
+	Title          string `json:"title"`                // display title (empty = derive from stem)
+	Position       string `json:"position"`             // "last" | "first" (default: "last")
+	At             *int   `json:"at,omitempty"`         // zero-based index insertion point
+	Before         string `json:"before,omitempty"`     // selector of sibling to insert before
+	After          string `json:"after,omitempty"`      // selector of sibling to insert after
+	Force          bool   `json:"force"`                // allow duplicate target
+	Style          string `json:"style,omitempty"`      // link style: "inline" (default), "reference", "wikilink", or "auto"
+	ForceParse     bool   `json:"forceParse,omitempty"` // proceed even though the binder has parse errors
+	DryRun         bool   `json:"-"`                    // compute the result and diff without writing
 }
 
 // DeleteParams are parameters for the delete operation.
 type DeleteParams struct {
-	Selector string `json:"selector"` // selector for node(s) to delete
-	Yes      bool   `json:"yes"`      // required confirmation flag
+	Selector   string `json:"selector"`             // selector for node(s) to delete
+	Yes        bool   `json:"yes"`                  // required confirmation flag
+	ForceParse bool   `json:"forceParse,omitempty"` // proceed even though the binder has parse errors
+	DryRun     bool   `json:"-"`                    // compute the result and diff without writing
 }
 
 // MoveParams are parameters for the move operation.
@@ -102,15 +219,114 @@ type MoveParams struct {
 	At                        *int   `json:"at,omitempty"`
 	Before                    string `json:"before,omitempty"`
 	After                     string `json:"after,omitempty"`
-	Yes                       bool   `json:"yes"` // required confirmation flag
+	Yes                       bool   `json:"yes"`                      // required confirmation flag
+	PreserveExtras            bool   `json:"preserveExtras,omitempty"` // carry checkbox/annotation text along instead of destroying it
+	ForceParse                bool   `json:"forceParse,omitempty"`     // proceed even though the binder has parse errors
+	DryRun                    bool   `json:"-"`                        // compute the result and diff without writing
+}
+
+// SetCheckedParams are parameters for the check/uncheck operation.
+type SetCheckedParams struct {
+	Selector   string `json:"selector"`             // selector for node(s) to update
+	Checked    bool   `json:"checked"`              // desired task state: true → "[x]", false → "[ ]"
+	ForceParse bool   `json:"forceParse,omitempty"` // proceed even though the binder has parse errors
+	DryRun     bool   `json:"-"`                    // compute the result and diff without writing
+}
+
+// MaterializeParams are parameters for the materialize operation.
+type MaterializeParams struct {
+	Selector   string `json:"selector"`             // selector for the placeholder to materialize
+	Target     string `json:"target"`               // relative path of the node file the entry links to
+	ForceParse bool   `json:"forceParse,omitempty"` // proceed even though the binder has parse errors
+	DryRun     bool   `json:"-"`                    // compute the result and diff without writing
+}
+
+// RenameParams are parameters for the rename operation.
+type RenameParams struct {
+	Selector   string `json:"selector"`             // selector for the entry whose file is renamed
+	NewTarget  string `json:"newTarget"`            // new relative path of the node file
+	ForceParse bool   `json:"forceParse,omitempty"` // proceed even though the binder has parse errors
+	DryRun     bool   `json:"-"`                    // compute the result and diff without writing
+}
+
+// ShiftParams are parameters for the promote/demote operation.
+type ShiftParams struct {
+	Selector   string `json:"selector"`             // selector for the node to shift one level
+	ForceParse bool   `json:"forceParse,omitempty"` // proceed even though the binder has parse errors
+	DryRun     bool   `json:"-"`                    // compute the result and diff without writing
+}
+
+// TitlesParams are parameters for the titles operation.
+type TitlesParams struct {
+	Selector   string `json:"selector,omitempty"`   // selector for the entries to retitle, with their subtrees; empty for all
+	Case       string `json:"case"`                 // "title" | "sentence"
+	ForceParse bool   `json:"forceParse,omitempty"` // proceed even though the binder has parse errors
+	DryRun     bool   `json:"-"`                    // compute the result and diff without writing
+}
+
+// RetitleParams are parameters for the retitle operation.
+type RetitleParams struct {
+	Selector   string `json:"selector"`             // selector for the entries to retitle
+	Title      string `json:"title"`                // the new title
+	ForceParse bool   `json:"forceParse,omitempty"` // proceed even though the binder has parse errors
+	DryRun     bool   `json:"-"`                    // compute the result and diff without writing
+}
+
+// SyncTitlesParams are parameters for the sync-titles operation.
+type SyncTitlesParams struct {
+	Direction  string `json:"direction"`            // "file-to-binder" | "binder-to-file"
+	ForceParse bool   `json:"forceParse,omitempty"` // proceed even though the binder has parse errors
+	DryRun     bool   `json:"-"`                    // compute the result and diff without writing
 }
 
 // OpResult is the CLI JSON output of any mutation operation.
 // Matches op-result.schema.json.
 type OpResult struct {
 	Version     string       `json:"version"`     // "1"
-	Changed     bool         `json:"changed"`     // true if binder bytes were modified
+	Changed     bool         `json:"changed"`     // true if binder content (not just blank lines or line endings) was modified
 	Diagnostics []Diagnostic `json:"diagnostics"` // merged parse + op diagnostics
+	// Normalized lists the incidental normalizations that came with the
+	// edit, NormalizedBlankLines and NormalizedLineEndings, so a GUI can
+	// tell cosmetic side effects from the edit itself. An edit that only
+	// normalizes is not Changed and is not written.
+	Normalized []string `json:"normalized,omitempty"`
+	// Entries locates the entries the operation inserted, moved, or removed
+	// in the rewritten binder, so an editor can place the cursor without
+	// re-parsing. Absent for operations that do not report them.
+	Entries []AffectedEntry `json:"entries,omitempty"`
+	// Diff is the unified diff of the binder change, set whenever the binder
+	// changed or, for a dry run, would change. Commands report it only under
+	// --diff or --dry-run, except titles, which always shows its changes.
+	Diff string `json:"diff,omitempty"`
+	// BinderBefore and BinderAfter identify the binder content the
+	// operation started from and left. The operation journal records them as
+	// replay preconditions; they are not part of the JSON output.
+	BinderBefore string `json:"-"`
+	BinderAfter  string `json:"-"`
+}
+
+// Incidental normalizations reported in OpResult.Normalized.
+const (
+	// NormalizedBlankLines: blank lines were added or removed, as when runs
+	// of blank lines left by a removed entry are collapsed.
+	NormalizedBlankLines = "blank-lines"
+	// NormalizedLineEndings: a kept line's ending changed, as when a final
+	// line without one gains the binder's usual ending on insert.
+	NormalizedLineEndings = "line-endings"
+)
+
+// AffectedEntry is an entry touched by a mutation, located in the binder the
+// mutation produced.
+type AffectedEntry struct {
+	Target string `json:"target"`
+	Title  string `json:"title"`
+	// Line is the 1-based line of the entry's list item. For a removed entry
+	// it is the line where the entry stood, which now holds whatever followed
+	// it, or one past the last line.
+	Line int `json:"line"`
+	// Path holds the 0-based child indices leading from the root to the
+	// entry. It is absent for removed entries.
+	Path []int `json:"path,omitempty"`
 }
 
 // SelectorResult holds the nodes matched by a selector evaluation.
@@ -140,6 +356,35 @@ const (
 	CodeBOMPresence          = "BNDW010"
 )
 
+// SeverityInfo marks implementation-specific diagnostics that report expected
+// conditions; like warnings they never fail a command.
+const SeverityInfo = "info"
+
+// CodeReferenceSectionLink is an implementation-specific informational
+// diagnostic emitted instead of BNDW006 for a .md link outside a list inside
+// one of Project.ReferenceSections.
+const CodeReferenceSectionLink = "PMKI001"
+
+// Implementation-specific parse warnings (outside the conformance code space).
+const (
+	// CodeWikilinkAliasMatch is emitted when a wikilink resolves through a
+	// file's frontmatter alias instead of its stem.
+	CodeWikilinkAliasMatch = "PMKW003"
+	// CodeUnsupportedBinderVersion is emitted instead of BNDW001 when the
+	// binder's pragma names a version other than v1.
+	CodeUnsupportedBinderVersion = "PMKW004"
+	// CodeRestoredAtTopLevel is emitted by pmk restore when a restored
+	// entry's parent is no longer in the binder, so it goes back at the top
+	// level instead.
+	CodeRestoredAtTopLevel = "PMKW006"
+	// CodeSubBinderCycle is emitted by ExpandSubBinders for a nested binder
+	// that links back to a binder it is nested in.
+	CodeSubBinderCycle = "PMKW010"
+	// CodeUnreadableSubBinder is emitted by ExpandSubBinders for a nested
+	// binder that cannot be read.
+	CodeUnreadableSubBinder = "PMKW011"
+)
+
 // Operation errors (non-zero exit; abort mutation).
 const (
 	CodeSelectorNoMatch   = "OPE001"
@@ -152,6 +397,7 @@ const (
 	CodeIndexOutOfBounds  = "OPE008"
 	CodeIOOrParseFailure  = "OPE009"
 	CodeConflictingFlags  = "OPE010"
+	CodeInvalidSelector   = "OPE011"
 )
 
 // Operation warnings (exit 0; mutation proceeds).
@@ -0,0 +1,445 @@
+// Package browse is the interactive outliner behind pmk browse. A Model
+// holds the binder tree as displayed and turns key presses into the same
+// core operations the CLI runs, re-reading the binder after each one; View
+// renders it as text. The terminal itself (raw mode, reading keys, drawing
+// frames, suspending for the editor) is the caller's concern, so a Model can
+// be driven and inspected in tests without one.
+package browse
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// IO handles I/O for browsing: reading the binder and project, and writing
+// the binder back after an operation.
+type IO interface {
+	core.BinderIO
+}
+
+// Options supply the optional parts of a Model.
+type Options struct {
+	// Edit opens the node file at path in the user's editor, returning once
+	// the editor exits. Nil disables editing.
+	Edit func(path string) error
+	// Audit runs a doctor audit of the project. Its findings are shown
+	// beside the nodes whose files they concern. Nil shows only the
+	// binder's own diagnostics.
+	Audit func(ctx context.Context) ([]node.AuditDiagnostic, error)
+}
+
+// row is one visible line of the outline.
+type row struct {
+	node  *binder.Node
+	path  []int // 0-based child indices from the root
+	depth int
+}
+
+// Model is the state of a browse session.
+type Model struct {
+	ctx        context.Context
+	io         IO
+	binderPath string
+	opts       Options
+
+	root      *binder.Node
+	rows      []row
+	cursor    int
+	collapsed map[string]bool // keyed by pathKey
+	// lineDiags and fileDiags are the diagnostics to show, by binder line
+	// and by project-relative node file.
+	lineDiags map[int][]string
+	fileDiags map[string][]string
+
+	status  string
+	editing bool   // retitling the selected node
+	input   []rune // the title being typed
+	done    bool
+}
+
+// New loads the binder at binderPath and returns a Model with the cursor on
+// its first node.
+func New(ctx context.Context, io IO, binderPath string, opts Options) (*Model, error) {
+	m := &Model{ctx: ctx, io: io, binderPath: binderPath, opts: opts, collapsed: map[string]bool{}}
+	if err := m.reload(nil); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Done reports whether the user has quit.
+func (m *Model) Done() bool { return m.done }
+
+// reload re-reads the binder and project, keeping the cursor on the node
+// at path, or on the same row when path is nil or gone.
+func (m *Model) reload(path []int) error {
+	parsed, err := core.Parse(m.ctx, m.io, m.binderPath, core.ParseOptions{})
+	if err != nil {
+		return err
+	}
+	if parsed.Err != nil {
+		return fmt.Errorf("parsing binder: %w", parsed.Err)
+	}
+	m.root = parsed.Result.Root
+	m.lineDiags = map[int][]string{}
+	for _, d := range parsed.Diagnostics {
+		if d.Location != nil {
+			m.lineDiags[d.Location.Line] = append(m.lineDiags[d.Location.Line], d.Code+" "+d.Message)
+		}
+	}
+	m.fileDiags = map[string][]string{}
+	if m.opts.Audit != nil {
+		audit, err := m.opts.Audit(m.ctx)
+		if err != nil {
+			m.status = "doctor: " + err.Error()
+		}
+		for _, d := range audit {
+			rel := filepath.ToSlash(d.Path)
+			m.fileDiags[rel] = append(m.fileDiags[rel], string(d.Code)+" "+d.Message)
+		}
+	}
+	m.layout()
+	if path != nil {
+		if i := m.rowAt(path); i >= 0 {
+			m.cursor = i
+		}
+	}
+	m.cursor = min(m.cursor, max(len(m.rows)-1, 0))
+	return nil
+}
+
+// layout rebuilds the visible rows from the tree, skipping the children of
+// collapsed nodes.
+func (m *Model) layout() {
+	m.rows = m.rows[:0]
+	var walk func(n *binder.Node, path []int)
+	walk = func(n *binder.Node, path []int) {
+		for i, c := range n.Children {
+			p := append(slices.Clone(path), i)
+			m.rows = append(m.rows, row{node: c, path: p, depth: len(path)})
+			if !m.collapsed[pathKey(p)] {
+				walk(c, p)
+			}
+		}
+	}
+	walk(m.root, nil)
+}
+
+// rowAt returns the index of the visible row at path, or -1.
+func (m *Model) rowAt(path []int) int {
+	for i, r := range m.rows {
+		if slices.Equal(r.path, path) {
+			return i
+		}
+	}
+	return -1
+}
+
+// pathKey is path as a map key.
+func pathKey(path []int) string {
+	return fmt.Sprint(path)
+}
+
+// selected returns the row under the cursor, or false when the binder has
+// no nodes.
+func (m *Model) selected() (row, bool) {
+	if len(m.rows) == 0 {
+		return row{}, false
+	}
+	return m.rows[m.cursor], true
+}
+
+// Update applies a key press.
+func (m *Model) Update(k Key) {
+	if m.editing {
+		m.updateTitle(k)
+		return
+	}
+	m.status = ""
+	switch k {
+	case KeyCtrlC, KeyEsc, "q":
+		m.done = true
+	case KeyUp, "k":
+		m.cursor = max(m.cursor-1, 0)
+	case KeyDown, "j":
+		m.cursor = min(m.cursor+1, max(len(m.rows)-1, 0))
+	case KeyRight, "l":
+		if r, ok := m.selected(); ok {
+			delete(m.collapsed, pathKey(r.path))
+			m.layout()
+		}
+	case KeyLeft, "h":
+		m.collapse()
+	case "K":
+		m.moveSibling(-1)
+	case "J":
+		m.moveSibling(1)
+	case "<":
+		m.shift(core.Promote)
+	case ">":
+		m.shift(core.Demote)
+	case "r":
+		if r, ok := m.selected(); ok {
+			m.editing, m.input = true, []rune(r.node.Title)
+		}
+	case KeyEnter, "e":
+		m.edit()
+	case "R":
+		m.report(m.reload(m.currentPath()), "Reloaded")
+	}
+}
+
+// currentPath is the selected row's path, or nil.
+func (m *Model) currentPath() []int {
+	if r, ok := m.selected(); ok {
+		return r.path
+	}
+	return nil
+}
+
+// collapse hides the selected node's children, or when they are already
+// hidden, or it has none, moves to its parent.
+func (m *Model) collapse() {
+	r, ok := m.selected()
+	if !ok {
+		return
+	}
+	key := pathKey(r.path)
+	if len(r.node.Children) > 0 && !m.collapsed[key] {
+		m.collapsed[key] = true
+		m.layout()
+		return
+	}
+	if len(r.path) > 1 {
+		m.cursor = m.rowAt(r.path[:len(r.path)-1])
+	}
+}
+
+// updateTitle applies a key press while retitling.
+func (m *Model) updateTitle(k Key) {
+	switch k {
+	case KeyEsc, KeyCtrlC:
+		m.editing = false
+	case KeyBackspace:
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+	case KeyEnter:
+		m.editing = false
+		r, ok := m.selected()
+		if !ok {
+			return
+		}
+		sel, ok := binder.PathSelector(m.root, r.path)
+		if !ok {
+			m.status = "This node cannot be selected for retitling"
+			return
+		}
+		res, err := core.Retitle(m.ctx, m.io, m.binderPath, binder.RetitleParams{Selector: sel, Title: string(m.input)})
+		m.afterOp(res, err, r.path, "Retitled")
+	default:
+		if len([]rune(string(k))) == 1 && k >= " " {
+			m.input = append(m.input, []rune(string(k))...)
+		}
+	}
+}
+
+// moveSibling moves the selected node by delta places among its siblings.
+func (m *Model) moveSibling(delta int) {
+	r, ok := m.selected()
+	if !ok {
+		return
+	}
+	parentPath := r.path[:len(r.path)-1]
+	parent := m.root
+	for _, i := range parentPath {
+		parent = parent.Children[i]
+	}
+	at := r.path[len(r.path)-1] + delta
+	if at < 0 || at >= len(parent.Children) {
+		return
+	}
+	sel, ok := binder.PathSelector(m.root, r.path)
+	parentSel, parentOK := ".", true
+	if len(parentPath) > 0 {
+		parentSel, parentOK = binder.PathSelector(m.root, parentPath)
+	}
+	if !ok || !parentOK {
+		m.status = "This node cannot be selected for moving"
+		return
+	}
+	params := binder.MoveParams{SourceSelector: sel, DestinationParentSelector: parentSel, At: &at, Yes: true}
+	res, err := core.Move(m.ctx, m.io, m.binderPath, params)
+	m.afterOp(res, err, append(slices.Clone(parentPath), at), "Moved")
+}
+
+// shift promotes or demotes the selected node.
+func (m *Model) shift(op func(context.Context, core.BinderIO, string, binder.ShiftParams) (*binder.OpResult, error)) {
+	r, ok := m.selected()
+	if !ok {
+		return
+	}
+	sel, ok := binder.PathSelector(m.root, r.path)
+	if !ok {
+		m.status = "This node cannot be selected for moving"
+		return
+	}
+	res, err := op(m.ctx, m.io, m.binderPath, binder.ShiftParams{Selector: sel})
+	m.afterOp(res, err, r.path, "Moved")
+}
+
+// afterOp reports an operation's outcome and reloads the binder, moving the
+// cursor to the entry the operation reports, else to path.
+func (m *Model) afterOp(res *binder.OpResult, err error, path []int, verb string) {
+	if err != nil {
+		m.status = err.Error()
+		return
+	}
+	for _, d := range res.Diagnostics {
+		if d.Severity == "error" {
+			m.status = d.Code + " " + d.Message
+			return
+		}
+	}
+	if len(res.Entries) > 0 && res.Entries[0].Path != nil {
+		path = res.Entries[0].Path
+	}
+	if res.Changed {
+		// An entry moved into a collapsed parent would vanish from view.
+		for i := 1; i < len(path); i++ {
+			delete(m.collapsed, pathKey(path[:i]))
+		}
+	}
+	m.report(m.reload(path), verb)
+}
+
+// edit opens the selected node's file in the editor.
+func (m *Model) edit() {
+	r, ok := m.selected()
+	if !ok || m.opts.Edit == nil {
+		return
+	}
+	if r.node.Target == "" {
+		m.status = "A placeholder has no file to edit; materialize it first"
+		return
+	}
+	path, err := safepath.Resolve(filepath.Dir(m.binderPath), r.node.Target)
+	if err != nil {
+		m.status = err.Error()
+		return
+	}
+	if err := m.opts.Edit(path); err != nil {
+		m.status = "editor: " + err.Error()
+		return
+	}
+	m.report(m.reload(r.path), "")
+}
+
+// report sets the status line to err, or to msg when err is nil.
+func (m *Model) report(err error, msg string) {
+	if err != nil {
+		m.status = err.Error()
+		return
+	}
+	m.status = msg
+}
+
+// help is the key summary shown at the bottom of the screen.
+const help = "↑↓ select  ←→ fold  K/J move  </> promote/demote  r retitle  e edit  R reload  q quit"
+
+// View renders the outline to fit width columns and height lines: the rows
+// around the cursor, then the selected node's diagnostics, the status line,
+// and the key summary. Nodes with diagnostics are marked with "!".
+func (m *Model) View(width, height int) string {
+	var footer []string
+	if r, ok := m.selected(); ok {
+		for _, d := range m.diagnostics(r.node) {
+			footer = append(footer, "! "+d)
+		}
+	}
+	switch {
+	case m.editing:
+		footer = append(footer, "Title: "+string(m.input)+"█")
+	case m.status != "":
+		footer = append(footer, m.status)
+	}
+	footer = append(footer, help)
+	if len(footer) > height/2 {
+		footer = footer[len(footer)-height/2:]
+	}
+
+	avail := max(height-len(footer), 1)
+	top := max(min(m.cursor-avail/2, len(m.rows)-avail), 0)
+	var b strings.Builder
+	if len(m.rows) == 0 {
+		b.WriteString("(the binder is empty)\n")
+		avail--
+	}
+	for i := top; i < min(top+avail, len(m.rows)); i++ {
+		b.WriteString(truncate(m.rowText(i), width) + "\n")
+	}
+	for _, f := range footer {
+		b.WriteString(truncate(f, width) + "\n")
+	}
+	return b.String()
+}
+
+// rowText renders row i: cursor, fold marker, checkbox, title, target, and
+// a "!" when the node has diagnostics.
+func (m *Model) rowText(i int) string {
+	r := m.rows[i]
+	var b strings.Builder
+	if i == m.cursor {
+		b.WriteString("> ")
+	} else {
+		b.WriteString("  ")
+	}
+	b.WriteString(strings.Repeat("  ", r.depth))
+	switch {
+	case len(r.node.Children) == 0:
+		b.WriteString("  ")
+	case m.collapsed[pathKey(r.path)]:
+		b.WriteString("▸ ")
+	default:
+		b.WriteString("▾ ")
+	}
+	if r.node.Checked != nil {
+		if *r.node.Checked {
+			b.WriteString("[x] ")
+		} else {
+			b.WriteString("[ ] ")
+		}
+	}
+	b.WriteString(r.node.Title)
+	if r.node.Target != "" {
+		b.WriteString("  (" + r.node.Target + ")")
+	} else {
+		b.WriteString("  (placeholder)")
+	}
+	if len(m.diagnostics(r.node)) > 0 {
+		b.WriteString("  !")
+	}
+	return b.String()
+}
+
+// diagnostics returns the diagnostics shown for n: the binder's, on its
+// line, and the doctor's, on its file.
+func (m *Model) diagnostics(n *binder.Node) []string {
+	return append(slices.Clone(m.lineDiags[n.Line]), m.fileDiags[n.Target]...)
+}
+
+// truncate shortens s to at most width runes.
+func truncate(s string, width int) string {
+	if r := []rune(s); width > 0 && len(r) > width {
+		return string(r[:width-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,381 @@
+package browse
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// memIO is an in-memory IO whose binder writes are read back.
+type memIO struct {
+	binder   string
+	readErr  error
+	writeErr error
+	writes   int
+}
+
+func (f *memIO) ReadBinder(_ context.Context, _ string) ([]byte, error) {
+	if f.readErr != nil {
+		return nil, f.readErr
+	}
+	return []byte(f.binder), nil
+}
+
+func (f *memIO) ScanProject(_ context.Context, _ string) (*binder.Project, error) {
+	return &binder.Project{Files: []string{"a.md", "b.md", "c.md", "a1.md"}, BinderDir: "/proj"}, nil
+}
+
+func (f *memIO) WriteBinderAtomic(_ context.Context, _ string, data []byte) error {
+	if f.writeErr != nil {
+		return f.writeErr
+	}
+	f.binder = string(data)
+	f.writes++
+	return nil
+}
+
+const testBinder = "<!-- prosemark-binder:v1 -->\n" +
+	"- [A](a.md)\n" +
+	"  - [A1](a1.md)\n" +
+	"- [B](b.md)\n" +
+	"- [C](c.md)\n"
+
+func newTestModel(t *testing.T, io *memIO, opts Options) *Model {
+	t.Helper()
+	m, err := New(context.Background(), io, "/proj/_binder.md", opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return m
+}
+
+func press(m *Model, keys ...Key) {
+	for _, k := range keys {
+		m.Update(k)
+	}
+}
+
+// selectedTitle is the title of the node under the cursor.
+func selectedTitle(m *Model) string {
+	r, _ := m.selected()
+	return r.node.Title
+}
+
+func TestModel_Navigation(t *testing.T) {
+	m := newTestModel(t, &memIO{binder: testBinder}, Options{})
+	steps := []struct {
+		key  Key
+		want string
+	}{
+		{KeyDown, "A1"},
+		{"j", "B"},
+		{KeyUp, "A1"},
+		{KeyLeft, "A"}, // A1 is a leaf: go to its parent
+		{KeyLeft, "A"}, // collapse A
+		{KeyDown, "B"}, // A1 is hidden
+		{"k", "A"},
+		{KeyRight, "A"},
+		{KeyDown, "A1"},
+		{"k", "A"},
+		{KeyUp, "A"},
+	}
+	for i, s := range steps {
+		m.Update(s.key)
+		if got := selectedTitle(m); got != s.want {
+			t.Fatalf("step %d (%s): selected %q, want %q", i, s.key, got, s.want)
+		}
+	}
+	if m.Done() {
+		t.Fatal("Done before quitting")
+	}
+	m.Update("q")
+	if !m.Done() {
+		t.Error("q did not quit")
+	}
+}
+
+func TestModel_Move(t *testing.T) {
+	io := &memIO{binder: testBinder}
+	m := newTestModel(t, io, Options{})
+	press(m, "j", "j", "J") // move B below C
+	want := "<!-- prosemark-binder:v1 -->\n- [A](a.md)\n  - [A1](a1.md)\n- [C](c.md)\n- [B](b.md)\n"
+	if io.binder != want {
+		t.Errorf("binder = %q, want %q", io.binder, want)
+	}
+	if got := selectedTitle(m); got != "B" {
+		t.Errorf("selected %q after move, want B", got)
+	}
+
+	press(m, "J") // already last
+	if io.writes != 1 {
+		t.Errorf("writes = %d, want 1", io.writes)
+	}
+
+	press(m, "K", "K", "K", ">") // B to the top, then under nothing: demote fails
+	if got := selectedTitle(m); got != "B" {
+		t.Errorf("selected %q, want B", got)
+	}
+	if m.status == "" || m.status == "Moved" {
+		t.Errorf("status = %q, want demote error", m.status)
+	}
+
+	press(m, "j", ">") // demote A under B
+	want = "<!-- prosemark-binder:v1 -->\n- [B](b.md)\n  - [A](a.md)\n    - [A1](a1.md)\n- [C](c.md)\n"
+	if io.binder != want {
+		t.Errorf("binder = %q, want %q", io.binder, want)
+	}
+	if got := selectedTitle(m); got != "A" {
+		t.Errorf("selected %q after demote, want A", got)
+	}
+	press(m, "<")
+	if got := selectedTitle(m); got != "A" || !strings.HasPrefix(io.binder, "<!-- prosemark-binder:v1 -->\n- [B](b.md)\n- [A](a.md)") {
+		t.Errorf("after promote: selected %q, binder %q", got, io.binder)
+	}
+}
+
+func TestModel_MoveWriteError(t *testing.T) {
+	io := &memIO{binder: testBinder, writeErr: errors.New("disk full")}
+	m := newTestModel(t, io, Options{})
+	press(m, "J")
+	if !strings.Contains(m.status, "disk full") {
+		t.Errorf("status = %q, want the write error", m.status)
+	}
+	if got := selectedTitle(m); got != "A" {
+		t.Errorf("selected %q, want A", got)
+	}
+}
+
+func TestModel_Retitle(t *testing.T) {
+	io := &memIO{binder: testBinder}
+	m := newTestModel(t, io, Options{})
+	press(m, "j", "r", KeyBackspace, KeyBackspace)
+	for _, k := range ParseKeys([]byte("Épilogue")) {
+		m.Update(k)
+	}
+	if view := m.View(80, 24); !strings.Contains(view, "Title: Épilogue█") {
+		t.Errorf("view does not show the title being typed:\n%s", view)
+	}
+	press(m, KeyEnter)
+	if !strings.Contains(io.binder, "  - [Épilogue](a1.md)\n") {
+		t.Errorf("binder = %q, want A1 retitled", io.binder)
+	}
+	if m.status != "Retitled" {
+		t.Errorf("status = %q, want Retitled", m.status)
+	}
+
+	press(m, "r", "x", KeyEsc)
+	if strings.Contains(io.binder, "Épiloguex") {
+		t.Error("escape did not cancel retitling")
+	}
+	press(m, "r", KeyBackspace, KeyBackspace, KeyBackspace, KeyBackspace, KeyBackspace, KeyBackspace, KeyBackspace, KeyBackspace, KeyEnter)
+	if !strings.Contains(m.status, "PMKE010") {
+		t.Errorf("status = %q, want an invalid title error", m.status)
+	}
+}
+
+func TestModel_Edit(t *testing.T) {
+	io := &memIO{binder: testBinder + "- [Later]()\n"}
+	var edited []string
+	m := newTestModel(t, io, Options{Edit: func(path string) error {
+		edited = append(edited, path)
+		return nil
+	}})
+	press(m, "j", KeyEnter)
+	if len(edited) != 1 || edited[0] != "/proj/a1.md" {
+		t.Errorf("edited %v, want [/proj/a1.md]", edited)
+	}
+	press(m, "j", "j", "j", "e")
+	if len(edited) != 1 || !strings.Contains(m.status, "placeholder") {
+		t.Errorf("edited %v, status %q; want the placeholder refused", edited, m.status)
+	}
+
+	m.opts.Edit = func(string) error { return errors.New("no such editor") }
+	press(m, "k", "e")
+	if m.status != "editor: no such editor" {
+		t.Errorf("status = %q", m.status)
+	}
+}
+
+func TestModel_Diagnostics(t *testing.T) {
+	io := &memIO{binder: testBinder + "- [Missing](missing.md)\n"}
+	m := newTestModel(t, io, Options{Audit: func(context.Context) ([]node.AuditDiagnostic, error) {
+		return []node.AuditDiagnostic{{Code: node.AUD001, Path: "b.md", Message: "title is missing"}}, nil
+	}})
+	view := m.View(80, 24)
+	for _, want := range []string{"▾ A  (a.md)\n", "  B  (b.md)  !\n", "Missing  (missing.md)  !\n"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+	press(m, "j", "j")
+	if view := m.View(80, 24); !strings.Contains(view, "! "+string(node.AUD001)+" title is missing\n") {
+		t.Errorf("view does not list B's diagnostics:\n%s", view)
+	}
+	press(m, "j", "j")
+	if view := m.View(80, 24); !strings.Contains(view, "! BNDW") {
+		t.Errorf("view does not list the missing target's diagnostics:\n%s", view)
+	}
+}
+
+func TestModel_View(t *testing.T) {
+	m := newTestModel(t, &memIO{binder: testBinder}, Options{})
+	want := "> ▾ A  (a.md)\n" +
+		"      A1  (a1.md)\n" +
+		"    B  (b.md)\n" +
+		"    C  (c.md)\n" +
+		help + "\n"
+	if got := m.View(120, 24); got != want {
+		t.Errorf("View =\n%s\nwant\n%s", got, want)
+	}
+
+	press(m, "j", "j", "j")
+	got := m.View(10, 4)
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[len(lines)-2], "> ") {
+		t.Errorf("View(10, 4) =\n%s\nwant 3 rows ending with the cursor, then help", got)
+	}
+	for _, l := range lines {
+		if n := len([]rune(l)); n > 10 {
+			t.Errorf("line %q is %d runes wide, want at most 10", l, n)
+		}
+	}
+
+	empty := newTestModel(t, &memIO{binder: "<!-- prosemark-binder:v1 -->\n"}, Options{})
+	press(empty, "j", "J", "r", "e")
+	if got := empty.View(80, 24); !strings.HasPrefix(got, "(the binder is empty)\n") {
+		t.Errorf("empty View = %q", got)
+	}
+}
+
+func TestModel_EditOutsideProject(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Symlink(t.TempDir(), filepath.Join(dir, "out")); err != nil {
+		t.Fatal(err)
+	}
+	io := &memIO{binder: "<!-- prosemark-binder:v1 -->\n- [Outside](out/outside.md)\n"}
+	var edited []string
+	m, err := New(context.Background(), io, filepath.Join(dir, "_binder.md"), Options{Edit: func(path string) error {
+		edited = append(edited, path)
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	press(m, "e")
+	if edited != nil || !strings.Contains(m.status, safepath.ErrTraversal.Error()) {
+		t.Errorf("edited %v, status %q; want the escaping target refused", edited, m.status)
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		io      *memIO
+		wantErr string
+	}{
+		{"unreadable", &memIO{readErr: errors.New("denied")}, "denied"},
+		{"unparsable", &memIO{binder: "- [A](a.md)\n\xff\n"}, "parsing binder: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(context.Background(), tt.io, "/proj/_binder.md", Options{}); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestModel_Reload(t *testing.T) {
+	io := &memIO{binder: testBinder}
+	m := newTestModel(t, io, Options{Audit: func(context.Context) ([]node.AuditDiagnostic, error) {
+		return nil, errors.New("no project")
+	}})
+	if m.status != "doctor: no project" {
+		t.Errorf("status = %q, want the audit error", m.status)
+	}
+
+	press(m, "j", "j", "j") // C, removed behind the model's back
+	io.binder = "<!-- prosemark-binder:v1 -->\n- [A](a.md)\n- [B](b.md)\n"
+	press(m, "R")
+	if got := selectedTitle(m); got != "B" || m.status != "Reloaded" {
+		t.Errorf("selected %q, status %q; want B and Reloaded", got, m.status)
+	}
+	if view := m.View(120, 2); view != ">   B  (b.md)\n"+help+"\n" {
+		t.Errorf("View(120, 2) = %q, want the cursor row and the help line alone", view)
+	}
+
+	io.readErr = errors.New("denied")
+	press(m, "R")
+	if !strings.Contains(m.status, "denied") {
+		t.Errorf("status = %q, want the read error", m.status)
+	}
+	if view := m.View(80, 24); !strings.Contains(view, m.status+"\n") {
+		t.Errorf("view does not show the status:\n%s", view)
+	}
+}
+
+func TestModel_EmptyBinder(t *testing.T) {
+	io := &memIO{binder: "<!-- prosemark-binder:v1 -->\n"}
+	m := newTestModel(t, io, Options{})
+	press(m, "h", "<", "R")
+	if m.status != "Reloaded" || io.writes != 0 {
+		t.Errorf("status %q, %d writes; want Reloaded and none", m.status, io.writes)
+	}
+	// The binder cannot empty while a title is typed, but a stray Enter
+	// is harmless.
+	m.editing = true
+	press(m, KeyEnter)
+	if m.editing || io.writes != 0 {
+		t.Errorf("editing %v, %d writes; want neither", m.editing, io.writes)
+	}
+}
+
+func TestModel_Unselectable(t *testing.T) {
+	io := &memIO{binder: testBinder + "- [Later]()\n"}
+	m := newTestModel(t, io, Options{})
+	press(m, "j", "j", "j", "j") // the placeholder
+	for _, keys := range [][]Key{{"K"}, {"<"}, {"r", KeyEnter}} {
+		press(m, keys...)
+		if !strings.Contains(m.status, "cannot be selected") {
+			t.Errorf("%q: status %q, want the placeholder refused", keys, m.status)
+		}
+	}
+	if io.writes != 0 {
+		t.Errorf("writes = %d, want 0", io.writes)
+	}
+}
+
+func TestModel_MoveNested(t *testing.T) {
+	io := &memIO{binder: "<!-- prosemark-binder:v1 -->\n- [A](a.md)\n  - [A1](a1.md)\n  - [B](b.md)\n"}
+	m := newTestModel(t, io, Options{})
+	press(m, "j", "J")
+	if want := "<!-- prosemark-binder:v1 -->\n- [A](a.md)\n  - [B](b.md)\n  - [A1](a1.md)\n"; io.binder != want {
+		t.Errorf("binder = %q, want %q", io.binder, want)
+	}
+	if got := selectedTitle(m); got != "A1" {
+		t.Errorf("selected %q, want A1", got)
+	}
+}
+
+func TestModel_RowText(t *testing.T) {
+	io := &memIO{binder: "<!-- prosemark-binder:v1 -->\n- [x] [A](a.md)\n  - [ ] [A1](a1.md)\n- [Later]()\n"}
+	m := newTestModel(t, io, Options{})
+	press(m, "h")
+	want := "> ▸ [x] A  (a.md)\n" +
+		"    Later  (placeholder)\n" +
+		help + "\n"
+	if got := m.View(120, 24); got != want {
+		t.Errorf("View =\n%s\nwant\n%s", got, want)
+	}
+	press(m, "l", "j")
+	if view := m.View(120, 24); !strings.Contains(view, ">     [ ] A1  (a1.md)\n") {
+		t.Errorf("view does not show the open checkbox:\n%s", view)
+	}
+}
@@ -0,0 +1,80 @@
+package browse
+
+import "unicode/utf8"
+
+// Key is a key press: a typed character as itself, or one of the named
+// keys below.
+type Key string
+
+// Named keys.
+const (
+	KeyUp        Key = "up"
+	KeyDown      Key = "down"
+	KeyLeft      Key = "left"
+	KeyRight     Key = "right"
+	KeyEnter     Key = "enter"
+	KeyEsc       Key = "esc"
+	KeyBackspace Key = "backspace"
+	KeyCtrlC     Key = "ctrl+c"
+)
+
+// escapeKeys maps the escape sequences terminals send for the named keys,
+// in both normal and application cursor mode.
+var escapeKeys = map[string]Key{
+	"\x1b[A": KeyUp, "\x1bOA": KeyUp,
+	"\x1b[B": KeyDown, "\x1bOB": KeyDown,
+	"\x1b[C": KeyRight, "\x1bOC": KeyRight,
+	"\x1b[D": KeyLeft, "\x1bOD": KeyLeft,
+}
+
+// ParseKeys decodes the bytes a raw-mode terminal sent into key presses.
+// Escape sequences it does not know are dropped; an escape byte not
+// starting a sequence is KeyEsc.
+func ParseKeys(b []byte) []Key {
+	var keys []Key
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			n := escapeLen(b)
+			if n == 1 {
+				keys = append(keys, KeyEsc)
+			} else if k, ok := escapeKeys[string(b[:n])]; ok {
+				keys = append(keys, k)
+			}
+			b = b[n:]
+			continue
+		case c == '\r' || c == '\n':
+			keys = append(keys, KeyEnter)
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, KeyBackspace)
+		case c == 0x03:
+			keys = append(keys, KeyCtrlC)
+		case c < 0x20:
+			// Other control characters are not bound.
+		default:
+			r, n := utf8.DecodeRune(b)
+			if r != utf8.RuneError {
+				keys = append(keys, Key(b[:n]))
+			}
+			b = b[n:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// escapeLen returns the length of the escape sequence at the start of b:
+// ESC [ or ESC O, any parameter bytes, and a final byte; or 1 for a lone
+// ESC.
+func escapeLen(b []byte) int {
+	if len(b) < 2 || (b[1] != '[' && b[1] != 'O') {
+		return 1
+	}
+	for i := 2; i < len(b); i++ {
+		if b[i] >= 0x40 && b[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return len(b)
+}
@@ -0,0 +1,28 @@
+package browse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []Key
+	}{
+		{"", nil},
+		{"jk", []Key{"j", "k"}},
+		{"\x1b[A\x1b[B\x1bOC\x1bOD", []Key{KeyUp, KeyDown, KeyRight, KeyLeft}},
+		{"\x1b", []Key{KeyEsc}},
+		{"\x1bq", []Key{KeyEsc, "q"}},
+		{"\x1b[1;5A\x1b[3~x", []Key{"x"}},
+		{"\x1b[1;", nil},
+		{"\r\n\x7f\x08\x03\x01", []Key{KeyEnter, KeyEnter, KeyBackspace, KeyBackspace, KeyCtrlC}},
+		{"é\xff", []Key{"é"}},
+	}
+	for _, tt := range tests {
+		if got := ParseKeys([]byte(tt.in)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseKeys(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Package capture turns fetched web pages into node content: it finds a
+// page's main content the way reader modes do and converts it to Markdown.
+package capture
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Page is the readable content extracted from an HTML document.
+type Page struct {
+	// Title is the page title, or "" when the page has none.
+	Title string
+	// Markdown is the main content as Markdown, ending in a newline, or ""
+	// when no content was found.
+	Markdown string
+}
+
+// boilerplateTags are elements that never hold a page's main content.
+var boilerplateTags = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true, "nav": true, "header": true,
+	"footer": true, "aside": true, "form": true, "button": true, "iframe": true, "svg": true,
+	"select": true, "input": true, "textarea": true, "dialog": true, "head": true,
+}
+
+// boilerplateRE matches class and id values of navigation, sharing,
+// advertising, and similar chrome.
+var boilerplateRE = regexp.MustCompile(`(?i)(^|[\s_-])(nav|navbar|navigation|menu|breadcrumbs?|footer|sidebar|comments?|share|sharing|social|related|ads?|advert|advertisement|promo|cookie|banner|subscribe|newsletter|popup|modal)($|[\s_-])`)
+
+// Extract finds the main content of the HTML document src and converts it
+// to Markdown. Relative link and image URLs are resolved against base, which
+// may be nil.
+//
+// The main content is the first <article>, else the <main> element (or
+// role="main"), else the element whose paragraphs hold the most text, as in
+// reader modes. Navigation, headers, footers, forms, and elements whose class
+// or id marks them as chrome (menus, sharing links, ads) are dropped first.
+func Extract(src []byte, base *url.URL) Page {
+	doc := parseHTML(string(src))
+	page := Page{Title: pageTitle(doc)}
+	removeBoilerplate(doc)
+	r := renderer{base: base}
+	if blocks := r.blocks(mainContent(doc)); len(blocks) > 0 {
+		page.Markdown = strings.Join(blocks, "\n\n") + "\n"
+	}
+	return page
+}
+
+// pageTitle returns the og:title meta value, else the <title> text, else
+// the first <h1> text, with whitespace collapsed.
+func pageTitle(doc *htmlNode) string {
+	var og, title, h1 string
+	walk(doc, func(n *htmlNode) bool {
+		switch {
+		case n.tag == "meta" && og == "" && (n.attrs["property"] == "og:title" || n.attrs["name"] == "og:title"):
+			og = collapseSpace(n.attrs["content"])
+		case n.tag == "title" && title == "":
+			title = collapseSpace(textContent(n))
+		case n.tag == "h1" && h1 == "":
+			h1 = collapseSpace(textContent(n))
+		}
+		return true
+	})
+	for _, t := range []string{og, title, h1} {
+		if t != "" {
+			return t
+		}
+	}
+	return ""
+}
+
+// removeBoilerplate detaches chrome elements from the tree.
+func removeBoilerplate(n *htmlNode) {
+	kept := n.children[:0]
+	for _, c := range n.children {
+		if c.tag != "" && (boilerplateTags[c.tag] || boilerplateRE.MatchString(c.attrs["class"]) || boilerplateRE.MatchString(c.attrs["id"])) {
+			continue
+		}
+		removeBoilerplate(c)
+		kept = append(kept, c)
+	}
+	n.children = kept
+}
+
+// mainContent returns the element holding the page's main content.
+func mainContent(doc *htmlNode) *htmlNode {
+	var article, main *htmlNode
+	scores := map[*htmlNode]int{}
+	walk(doc, func(n *htmlNode) bool {
+		switch {
+		case n.tag == "article" && article == nil:
+			article = n
+		case (n.tag == "main" || n.attrs["role"] == "main") && main == nil:
+			main = n
+		case n.tag == "p" || n.tag == "pre":
+			// Credit a paragraph's text to its parent, and half to its
+			// grandparent, so the container of the prose wins.
+			size := len(strings.TrimSpace(textContent(n)))
+			scores[n.parent] += size
+			if n.parent.parent != nil {
+				scores[n.parent.parent] += size / 2
+			}
+		}
+		return true
+	})
+	if article != nil {
+		return article
+	}
+	if main != nil {
+		return main
+	}
+	best, bestScore := doc, 0
+	walk(doc, func(n *htmlNode) bool {
+		if s := scores[n]; s > bestScore {
+			best, bestScore = n, s
+		}
+		return true
+	})
+	return best
+}
+
+// spaceRE matches runs of HTML whitespace.
+var spaceRE = regexp.MustCompile(`[ \t\n\r\f]+`)
+
+// collapseSpace trims s and collapses its whitespace runs to single spaces.
+func collapseSpace(s string) string {
+	return strings.TrimSpace(spaceRE.ReplaceAllString(s, " "))
+}
@@ -0,0 +1,79 @@
+package capture
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	base, _ := url.Parse("https://example.com/blog/post")
+	src := `<!DOCTYPE html><html><head><title>Ignored</title>
+<meta property="og:title" content="The &amp; Post"><script>var x = "<p>no</p>";</script></head>
+<body><nav class="main-nav"><a href="/">Home</a></nav>
+<div class="content"><h1>The Post</h1>
+<p>First <b>bold </b>para with <a href="../other">a link</a>.
+<p>Second para.
+<div class="share-buttons">Share!</div>
+<div id="comments"><p>Nice post, a long comment that should never be captured.</p></div>
+</div><footer>Copyright</footer></body></html>`
+	got := Extract([]byte(src), base)
+	if got.Title != "The & Post" {
+		t.Errorf("Title = %q", got.Title)
+	}
+	want := "# The Post\n\nFirst **bold** para with [a link](https://example.com/other).\n\nSecond para.\n"
+	if got.Markdown != want {
+		t.Errorf("Markdown = %q, want %q", got.Markdown, want)
+	}
+}
+
+func TestExtract_Empty(t *testing.T) {
+	got := Extract([]byte("<html><body><nav>Menu</nav></body></html>"), nil)
+	if got != (Page{}) {
+		t.Errorf("Extract = %+v, want empty page", got)
+	}
+}
+
+func TestPageTitle(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"og meta by name", `<meta name="og:title" content=" A  B "><title>T</title>`, "A B"},
+		{"title element", "<title>\n Two\n Lines </title><h1>H</h1>", "Two Lines"},
+		{"first h1", "<h1>First</h1><h1>Second</h1>", "First"},
+		{"none", "<p>Text</p>", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pageTitle(parseHTML(tt.src)); got != tt.want {
+				t.Errorf("pageTitle = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMainContent(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"first article", "<p>Intro text</p><article>One</article><article>Two</article>", "One\n"},
+		{"main element", "<p>Outside</p><main><p>Inside</p></main>", "Inside\n"},
+		{"role main", `<p>Outside</p><div role="main"><p>Inside</p></div>`, "Inside\n"},
+		{
+			"densest paragraphs",
+			"<div><p>Short teaser.</p></div><div><div><p>A much longer paragraph of real prose.</p><p>And another one.</p></div></div>",
+			"A much longer paragraph of real prose.\n\nAnd another one.\n",
+		},
+		{"no paragraphs", "Just text", "Just text\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Extract([]byte(tt.src), nil).Markdown; got != tt.want {
+				t.Errorf("Markdown = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package capture
+
+import (
+	"html"
+	"strings"
+)
+
+// htmlNode is an element or text node of a parsed HTML document.
+type htmlNode struct {
+	// tag is the lowercase element name, or "" for a text node.
+	tag string
+	// text is the decoded content of a text node.
+	text     string
+	attrs    map[string]string
+	parent   *htmlNode
+	children []*htmlNode
+}
+
+// voidElements never have content or an end tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// rawTextElements hold unparsed text up to their end tag.
+var rawTextElements = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true, "noscript": true, "template": true,
+}
+
+// autoCloseScopes lists, for elements whose end tag may be omitted, the
+// elements that bound the search for an open one to close implicitly when
+// another starts (a new <li> closes the open <li> of the same list).
+var autoCloseScopes = map[string][]string{
+	"p":  {"div", "article", "section", "main", "blockquote", "li", "td", "th", "body"},
+	"li": {"ul", "ol"},
+	"dt": {"dl"},
+	"dd": {"dl"},
+	"tr": {"table", "thead", "tbody", "tfoot"},
+	"td": {"tr"},
+	"th": {"tr"},
+}
+
+// parseHTML builds a tree from src. It is a forgiving parser, not an HTML5
+// one: unmatched end tags are ignored, unclosed elements end with their
+// parent, and omitted end tags are inferred only for the common cases in
+// autoCloseScopes. That is enough to find and convert an article's prose.
+func parseHTML(src string) *htmlNode {
+	root := &htmlNode{tag: "#document"}
+	cur := root
+	for len(src) > 0 {
+		lt := strings.IndexByte(src, '<')
+		if lt < 0 {
+			appendText(cur, src)
+			break
+		}
+		if lt > 0 {
+			appendText(cur, src[:lt])
+			src = src[lt:]
+			continue
+		}
+		switch {
+		case strings.HasPrefix(src, "<!--"):
+			src = skipPast(src[4:], "-->")
+		case strings.HasPrefix(src, "<!"), strings.HasPrefix(src, "<?"):
+			src = skipPast(src, ">")
+		case strings.HasPrefix(src, "</"):
+			name, rest := readName(src[2:])
+			src = skipPast(rest, ">")
+			if name != "" {
+				cur = closeElement(cur, name)
+			}
+		default:
+			name, rest := readName(src[1:])
+			if name == "" {
+				appendText(cur, "<")
+				src = src[1:]
+				continue
+			}
+			attrs, selfClosing, rest := readAttrs(rest)
+			src = rest
+			cur = implicitlyClose(cur, name)
+			el := &htmlNode{tag: name, attrs: attrs, parent: cur}
+			cur.children = append(cur.children, el)
+			switch {
+			case voidElements[name] || selfClosing:
+			case rawTextElements[name]:
+				end := indexFold(src, "</"+name)
+				if end < 0 {
+					end = len(src)
+				}
+				el.children = []*htmlNode{{text: html.UnescapeString(src[:end]), parent: el}}
+				src = skipPast(src[end:], ">")
+			default:
+				cur = el
+			}
+		}
+	}
+	return root
+}
+
+// appendText adds decoded text to n, merging it with a preceding text node.
+func appendText(n *htmlNode, raw string) {
+	text := html.UnescapeString(raw)
+	if last := len(n.children) - 1; last >= 0 && n.children[last].tag == "" {
+		n.children[last].text += text
+		return
+	}
+	n.children = append(n.children, &htmlNode{text: text, parent: n})
+}
+
+// closeElement returns the parent of the innermost open element named name,
+// or cur unchanged when no such element is open.
+func closeElement(cur *htmlNode, name string) *htmlNode {
+	for n := cur; n.parent != nil; n = n.parent {
+		if n.tag == name {
+			return n.parent
+		}
+	}
+	return cur
+}
+
+// implicitlyClose closes an open element whose end tag is implied by the
+// start of a name element, returning the new current element.
+func implicitlyClose(cur *htmlNode, name string) *htmlNode {
+	scopes, ok := autoCloseScopes[name]
+	if !ok {
+		return cur
+	}
+	for n := cur; n.parent != nil; n = n.parent {
+		if n.tag == name {
+			return n.parent
+		}
+		for _, s := range scopes {
+			if n.tag == s {
+				return cur
+			}
+		}
+	}
+	return cur
+}
+
+// readName reads a tag or attribute name at the start of s, lowercased.
+func readName(s string) (string, string) {
+	i := 0
+	for i < len(s) && !strings.ContainsRune(" \t\n\r\f/>=", rune(s[i])) {
+		i++
+	}
+	return strings.ToLower(s[:i]), s[i:]
+}
+
+// readAttrs reads the attributes of a start tag up to and including its
+// closing '>', reporting whether the tag ends with "/>".
+func readAttrs(s string) (map[string]string, bool, string) {
+	attrs := map[string]string{}
+	for {
+		s = strings.TrimLeft(s, " \t\n\r\f")
+		switch {
+		case s == "":
+			return attrs, false, s
+		case s[0] == '>':
+			return attrs, false, s[1:]
+		case strings.HasPrefix(s, "/>"):
+			return attrs, true, s[2:]
+		case s[0] == '/' || s[0] == '=':
+			s = s[1:]
+			continue
+		}
+		var name string
+		name, s = readName(s)
+		value := ""
+		if rest := strings.TrimLeft(s, " \t\n\r\f"); strings.HasPrefix(rest, "=") {
+			value, s = readAttrValue(strings.TrimLeft(rest[1:], " \t\n\r\f"))
+		}
+		if _, dup := attrs[name]; !dup {
+			attrs[name] = html.UnescapeString(value)
+		}
+	}
+}
+
+// readAttrValue reads a quoted or unquoted attribute value at the start of s.
+func readAttrValue(s string) (string, string) {
+	if s != "" && (s[0] == '"' || s[0] == '\'') {
+		end := strings.IndexByte(s[1:], s[0])
+		if end < 0 {
+			return s[1:], ""
+		}
+		return s[1 : end+1], s[end+2:]
+	}
+	i := 0
+	for i < len(s) && !strings.ContainsRune(" \t\n\r\f>", rune(s[i])) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// skipPast returns s after the first occurrence of sep, or "" when absent.
+func skipPast(s, sep string) string {
+	i := strings.Index(s, sep)
+	if i < 0 {
+		return ""
+	}
+	return s[i+len(sep):]
+}
+
+// indexFold is strings.Index ignoring case; substr must be ASCII.
+func indexFold(s, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// textContent returns the concatenated text beneath n.
+func textContent(n *htmlNode) string {
+	if n.tag == "" {
+		return n.text
+	}
+	var b strings.Builder
+	for _, c := range n.children {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
+
+// walk calls visit for n and its descendants in document order, skipping
+// the children of nodes for which visit returns false.
+func walk(n *htmlNode, visit func(*htmlNode) bool) {
+	if !visit(n) {
+		return
+	}
+	for _, c := range n.children {
+		walk(c, visit)
+	}
+}
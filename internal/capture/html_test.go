@@ -0,0 +1,73 @@
+package capture
+
+import (
+	"strings"
+	"testing"
+)
+
+// dump renders a parsed tree compactly: elements as tag(children), text quoted.
+func dump(n *htmlNode) string {
+	if n.tag == "" {
+		return "'" + n.text + "'"
+	}
+	parts := make([]string, 0, len(n.children))
+	for _, c := range n.children {
+		parts = append(parts, dump(c))
+	}
+	return n.tag + "(" + strings.Join(parts, " ") + ")"
+}
+
+func TestParseHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"nesting and entities", "<div><p>a &amp; b</p></div>", "#document(div(p('a & b')))"},
+		{"case-insensitive tags", "<DIV>x</Div>", "#document(div('x'))"},
+		{"comments and doctype", "<!DOCTYPE html><?xml x?><!-- <p>no</p> --><p>x</p>", "#document(p('x'))"},
+		{"unterminated comment", "<p>x</p><!-- open", "#document(p('x'))"},
+		{"unterminated declaration", "<p>x</p><!DOCTYPE", "#document(p('x'))"},
+		{"void and self-closing", "<p>a<br>b<img src=x /><span/>c</p>", "#document(p('a' br() 'b' img() span() 'c'))"},
+		{"raw text", "<script>if (a<b) { x = '</p>' }</SCRIPT><p>y</p>", "#document(script('if (a<b) { x = '</p>' }') p('y'))"},
+		{"unterminated raw text", "<style>p{}", "#document(style('p{}'))"},
+		{"unmatched end tag", "<p>a</span>b</p>", "#document(p('ab'))"},
+		{"empty end tag", "<p>a</>b</p>", "#document(p('ab'))"},
+		{"stray less-than", "<p>a < b</p>", "#document(p('a < b'))"},
+		{"implied p end", "<div><p>a<p>b</div>", "#document(div(p('a') p('b')))"},
+		{"p scope", "<p>a<div><p>b</div>", "#document(p('a' div(p('b'))))"},
+		{"implied li end", "<ul><li>a<li>b<ul><li>c</ul></ul>", "#document(ul(li('a') li('b' ul(li('c')))))"},
+		{"unclosed elements", "<div><p>a", "#document(div(p('a')))"},
+		{"trailing text", "a<b>b</b>c", "#document('a' b('b') 'c')"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dump(parseHTML(tt.src)); got != tt.want {
+				t.Errorf("parseHTML = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHTML_Attributes(t *testing.T) {
+	doc := parseHTML(`<a href="/x?a=1&amp;b=2" title='it"s' data-n=3 hidden class = "c" href="dup" / =junk>x</a><img alt="unterminated`)
+	a := doc.children[0]
+	want := map[string]string{"href": "/x?a=1&b=2", "title": `it"s`, "data-n": "3", "hidden": "", "class": "c", "junk": ""}
+	for k, v := range want {
+		if a.attrs[k] != v {
+			t.Errorf("attr %s = %q, want %q", k, a.attrs[k], v)
+		}
+	}
+	if len(a.attrs) != len(want) {
+		t.Errorf("attrs = %v", a.attrs)
+	}
+	if img := doc.children[1]; img.attrs["alt"] != "unterminated" {
+		t.Errorf("img attrs = %v", img.attrs)
+	}
+}
+
+func TestParseHTML_UnterminatedTag(t *testing.T) {
+	if got := dump(parseHTML("<p>x</p><div class=a")); got != "#document(p('x') div())" {
+		t.Errorf("parseHTML = %s", got)
+	}
+}
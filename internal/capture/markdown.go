@@ -0,0 +1,297 @@
+package capture
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// blockTags are elements rendered as Markdown blocks rather than inline.
+var blockTags = map[string]bool{
+	"address": true, "article": true, "blockquote": true, "body": true, "dd": true, "details": true,
+	"div": true, "dl": true, "dt": true, "figcaption": true, "figure": true, "h1": true, "h2": true,
+	"h3": true, "h4": true, "h5": true, "h6": true, "hr": true, "html": true, "li": true, "main": true,
+	"ol": true, "p": true, "pre": true, "section": true, "summary": true, "table": true, "ul": true,
+}
+
+// markdownEscaper escapes text that Markdown would read as markup.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`,
+)
+
+// renderer converts an HTML tree to Markdown.
+type renderer struct {
+	// base resolves relative link and image URLs; nil leaves them as given.
+	base *url.URL
+}
+
+// blocks renders the children of n as Markdown blocks, gathering runs of
+// inline content into paragraphs.
+func (r renderer) blocks(n *htmlNode) []string {
+	var out []string
+	var inline strings.Builder
+	flush := func() {
+		if p := tidyInline(inline.String()); p != "" {
+			out = append(out, p)
+		}
+		inline.Reset()
+	}
+	for _, c := range n.children {
+		if c.tag != "" && blockTags[c.tag] {
+			flush()
+			out = append(out, r.block(c)...)
+			continue
+		}
+		r.inline(&inline, c)
+	}
+	flush()
+	return out
+}
+
+// block renders the block element n.
+func (r renderer) block(n *htmlNode) []string {
+	switch n.tag {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level, _ := strconv.Atoi(n.tag[1:])
+		if text := r.inlineText(n); text != "" {
+			return []string{strings.Repeat("#", level) + " " + strings.ReplaceAll(text, "\\\n", " ")}
+		}
+		return nil
+	case "pre":
+		code := strings.Trim(textContent(n), "\n")
+		if code == "" {
+			return nil
+		}
+		fence := "```"
+		for strings.Contains(code, fence) {
+			fence += "`"
+		}
+		return []string{fence + "\n" + code + "\n" + fence}
+	case "hr":
+		return []string{"---"}
+	case "ul", "ol":
+		return r.list(n)
+	case "blockquote":
+		inner := strings.Join(r.blocks(n), "\n\n")
+		if inner == "" {
+			return nil
+		}
+		return []string{prefixLines(inner, "> ", "> ")}
+	case "table":
+		return r.table(n)
+	default:
+		return r.blocks(n)
+	}
+}
+
+// list renders a ul or ol element as a Markdown list.
+func (r renderer) list(n *htmlNode) []string {
+	var items []string
+	num := 1
+	if start, err := strconv.Atoi(n.attrs["start"]); err == nil {
+		num = start
+	}
+	for _, li := range n.children {
+		if li.tag != "li" {
+			continue
+		}
+		marker := "- "
+		if n.tag == "ol" {
+			marker = fmt.Sprintf("%d. ", num)
+			num++
+		}
+		inner := joinItemBlocks(r.blocks(li))
+		if inner == "" {
+			continue
+		}
+		items = append(items, prefixLines(inner, marker, strings.Repeat(" ", len(marker))))
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return []string{strings.Join(items, "\n")}
+}
+
+// listMarkerRE matches the start of a rendered Markdown list.
+var listMarkerRE = regexp.MustCompile(`^(- |\d+\. )`)
+
+// joinItemBlocks joins the blocks of one list item, keeping a nested list
+// directly under the text it belongs to so the outer list stays tight.
+func joinItemBlocks(blocks []string) string {
+	var b strings.Builder
+	for i, block := range blocks {
+		if i > 0 {
+			b.WriteString("\n")
+			if !listMarkerRE.MatchString(block) {
+				b.WriteString("\n")
+			}
+		}
+		b.WriteString(block)
+	}
+	return b.String()
+}
+
+// table renders a table element as a Markdown pipe table whose first row is
+// the header.
+func (r renderer) table(n *htmlNode) []string {
+	var rows []string
+	walk(n, func(tr *htmlNode) bool {
+		if tr.tag != "tr" {
+			return true
+		}
+		var cells []string
+		for _, c := range tr.children {
+			if c.tag == "td" || c.tag == "th" {
+				text := strings.ReplaceAll(r.inlineText(c), "\\\n", " ")
+				cells = append(cells, strings.ReplaceAll(text, "|", `\|`))
+			}
+		}
+		if len(cells) > 0 {
+			rows = append(rows, "| "+strings.Join(cells, " | ")+" |")
+			if len(rows) == 1 {
+				rows = append(rows, "|"+strings.Repeat(" --- |", len(cells)))
+			}
+		}
+		return false
+	})
+	if len(rows) == 0 {
+		return nil
+	}
+	return []string{strings.Join(rows, "\n")}
+}
+
+// inlineText renders the content of n as one tidied line of inline Markdown.
+func (r renderer) inlineText(n *htmlNode) string {
+	var b strings.Builder
+	for _, c := range n.children {
+		r.inline(&b, c)
+	}
+	return tidyInline(b.String())
+}
+
+// inline renders n as inline Markdown into b.
+func (r renderer) inline(b *strings.Builder, n *htmlNode) {
+	switch n.tag {
+	case "":
+		text := strings.ReplaceAll(n.text, hardBreak, "")
+		b.WriteString(markdownEscaper.Replace(spaceRE.ReplaceAllString(text, " ")))
+	case "br":
+		b.WriteString(hardBreak)
+	case "strong", "b":
+		b.WriteString(wrapInline(r.inlineRaw(n), "**"))
+	case "em", "i":
+		b.WriteString(wrapInline(r.inlineRaw(n), "*"))
+	case "code", "kbd", "samp":
+		code := collapseSpace(textContent(n))
+		if code == "" {
+			return
+		}
+		tick := "`"
+		for strings.Contains(code, tick) {
+			tick += "`"
+		}
+		if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+			code = " " + code + " "
+		}
+		b.WriteString(tick + code + tick)
+	case "a":
+		text := r.inlineRaw(n)
+		href := r.resolve(n.attrs["href"])
+		if href == "" || strings.TrimSpace(text) == "" {
+			b.WriteString(text)
+			return
+		}
+		b.WriteString(wrapInline(text, "[") + "(" + href + ")")
+	case "img":
+		if src := r.resolve(n.attrs["src"]); src != "" {
+			b.WriteString("![" + markdownEscaper.Replace(collapseSpace(n.attrs["alt"])) + "](" + src + ")")
+		}
+	default:
+		if blockTags[n.tag] {
+			b.WriteString(" ")
+		}
+		for _, c := range n.children {
+			r.inline(b, c)
+		}
+		if blockTags[n.tag] {
+			b.WriteString(" ")
+		}
+	}
+}
+
+// inlineRaw renders the children of n as inline Markdown without tidying.
+func (r renderer) inlineRaw(n *htmlNode) string {
+	var b strings.Builder
+	for _, c := range n.children {
+		r.inline(&b, c)
+	}
+	return b.String()
+}
+
+// resolve returns href resolved against the base URL, or "" for links that
+// mean nothing outside the page: empty, in-page fragments, and scripts.
+// Spaces and parentheses are escaped so the URL cannot end a Markdown link
+// early.
+func (r renderer) resolve(href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return ""
+	}
+	if u, err := url.Parse(href); err == nil && r.base != nil {
+		href = r.base.ResolveReference(u).String()
+	}
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(href)
+}
+
+// wrapInline wraps s in the delimiter mark (its mirror for "["), keeping
+// surrounding whitespace outside the delimiters so the markup stays valid.
+// Blank content is returned unwrapped.
+func wrapInline(s, mark string) string {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	closing := mark
+	if mark == "[" {
+		closing = "]"
+	}
+	lead := s[:strings.Index(s, trimmed)]
+	trail := s[len(lead)+len(trimmed):]
+	return lead + mark + trimmed + closing + trail
+}
+
+// hardBreak marks a <br> in rendered inline Markdown until tidyInline
+// turns it into a Markdown hard line break.
+const hardBreak = "\x00"
+
+// tidyInline collapses the whitespace left where adjacent nodes met and
+// turns line-break markers into Markdown hard breaks, dropping any at the
+// start or end of the text.
+func tidyInline(s string) string {
+	var lines []string
+	for _, line := range strings.Split(s, hardBreak) {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\\\n")
+}
+
+// prefixLines prefixes the first line of s with first and the rest with
+// rest; blank lines get rest without trailing spaces.
+func prefixLines(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		p := rest
+		if i == 0 {
+			p = first
+		}
+		if line == "" {
+			p = strings.TrimRight(p, " ")
+		}
+		lines[i] = p + line
+	}
+	return strings.Join(lines, "\n")
+}
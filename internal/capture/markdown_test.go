@@ -0,0 +1,49 @@
+package capture
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	base, _ := url.Parse("https://example.com/docs/page")
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"headings", "<h2>Sub <em>title</em></h2><h3>A<br>B</h3><h4> </h4>", "## Sub *title*\n\n### A B\n"},
+		{"escaping", "<p>*a* _b_ `c` [d] &lt;e&gt; \\</p>", "\\*a\\* \\_b\\_ \\`c\\` \\[d\\] \\<e> \\\\\n"},
+		{"line breaks", "<p><br>one<br><br>two\x00<br></p>", "one\\\ntwo\n"},
+		{"emphasis whitespace", "<p>a<strong> b </strong>c<i></i></p>", "a **b** c\n"},
+		{"code spans", "<p><code>x_y</code> <kbd>a`b</kbd> <samp>`q</samp><code> </code></p>", "`x_y` ``a`b`` `` `q ``\n"},
+		{"links", `<p><a href="a b(1)">rel</a> <a href="#top">anchor</a> <a href="javascript:go()">js</a> <a href="/x"> </a> <a>bare</a></p>`,
+			"[rel](https://example.com/docs/a%20b%281%29) anchor js bare\n"},
+		{"images", `<p><img src="i.png" alt="An [image]"><img alt="no src"></p>`, "![An \\[image\\]](https://example.com/docs/i.png)\n"},
+		{"pre", "<pre>\n<code>a ```\n  b</code>\n</pre><pre>\n</pre>", "````\na ```\n  b\n````\n"},
+		{"hr", "<p>a</p><hr><p>b</p>", "a\n\n---\n\nb\n"},
+		{"lists", `<ul>
+<li>a<ul><li>b</li></ul></li><li></li><li><p>c</p><p>d</p></li></ul><ol start="x"><li>one<li>two</ol><ul></ul>`,
+			"- a\n  - b\n- c\n\n  d\n\n1. one\n2. two\n"},
+		{"ordered start", `<ol start="7"><li>seven</ol>`, "7. seven\n"},
+		{"blockquote", "<blockquote><p>a</p><p>b</p></blockquote><blockquote> </blockquote>", "> a\n>\n> b\n"},
+		{"table", "<table><thead><tr><th>A</th><th>B|C</th></tr></thead><tr><td>1<br>2</td><td>3</td></tr><tr></tr></table><table></table>",
+			"| A | B\\|C |\n| --- | --- |\n| 1 2 | 3 |\n"},
+		{"inline blocks", "<p><span>a</span><div>b</div></p>", "a\n\nb\n"},
+		{"block inside inline", "<span>a<div>b</div>c</span>", "a b c\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The article wrapper keeps every test element in the main content.
+			if got := Extract([]byte("<article>"+tt.src+"</article>"), base).Markdown; got != tt.want {
+				t.Errorf("Markdown = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve_NoBase(t *testing.T) {
+	if got := (renderer{}).resolve(" /x y "); got != "/x%20y" {
+		t.Errorf("resolve = %q", got)
+	}
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+)
+
+// CodeBinderMismatch is the diagnostic code for replayed operations whose
+// binder is not the one they were recorded against.
+const CodeBinderMismatch = "PMKE011"
+
+// Apply runs a batch of operations against the binder at binderPath
+// (pmk apply), each against the binder the one before it left. It is
+// all or nothing: when any operation reports an error diagnostic the binder
+// is not written. With forceParse every operation proceeds despite parse
+// errors; with dryRun nothing is written.
+//
+// Each operation's diagnostics are prefixed with its 1-based number and
+// name. A diagnostic one operation repeats from an earlier one, such as a
+// parse warning about a line neither touched, is reported once. The result
+// reports no entries, since later operations move the lines of earlier
+// ones.
+func Apply(ctx context.Context, io BinderIO, binderPath string, specs []binder.OpSpec, forceParse, dryRun bool) (*binder.OpResult, error) {
+	return applyBatch(ctx, io, binderPath, specs, forceParse, dryRun, "")
+}
+
+// Replay applies a batch of operations recorded in another copy of the
+// project's operation journal (pmk journal replay) to the binder at
+// binderPath. The batch was recorded against the binder content hashing to
+// before (see BinderHash); a binder with other content is left alone and
+// reported as PMKE011. An empty before skips the check. Otherwise it is
+// Apply.
+func Replay(ctx context.Context, io BinderIO, binderPath string, specs []binder.OpSpec, before string) (*binder.OpResult, error) {
+	return applyBatch(ctx, io, binderPath, specs, false, false, before)
+}
+
+// applyBatch is Apply for a binder that must hash to before, unless before
+// is empty.
+func applyBatch(ctx context.Context, io BinderIO, binderPath string, specs []binder.OpSpec, forceParse, dryRun bool, before string) (*binder.OpResult, error) {
+	steps := make([]txOp, len(specs))
+	for i, spec := range specs {
+		step, err := specOp(spec, forceParse)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i+1, err)
+		}
+		steps[i] = step
+	}
+	return applyEntryOp(ctx, io, binderPath, dryRun, func(ctx context.Context, src []byte, proj *binder.Project) ([]byte, []binder.AffectedEntry, []binder.Diagnostic) {
+		if before != "" && BinderHash(src) != before {
+			return src, nil, []binder.Diagnostic{{
+				Severity: "error",
+				Code:     CodeBinderMismatch,
+				Message:  "the binder has changed since the operations were recorded",
+			}}
+		}
+		var diags []binder.Diagnostic
+		tx := ops.NewTransaction(ctx, src, proj)
+		for i, step := range steps {
+			_, stepDiags := step(tx)
+			for _, d := range stepDiags {
+				d.Message = fmt.Sprintf("operation %d (%s): %s", i+1, specs[i].Operation, d.Message)
+				diags = append(diags, d)
+			}
+			if tx.Failed() {
+				break
+			}
+		}
+		return tx.Bytes(), nil, diags
+	})
+}
+
+// txOp is one operation of a batch, applied within a transaction.
+type txOp func(tx *ops.Transaction) ([]binder.AffectedEntry, []binder.Diagnostic)
+
+// specOp returns the operation spec describes.
+func specOp(spec binder.OpSpec, forceParse bool) (txOp, error) {
+	params, err := spec.DecodeParams()
+	if err != nil {
+		return nil, err
+	}
+	switch p := params.(type) {
+	case binder.AddChildParams:
+		p.ForceParse = p.ForceParse || forceParse
+		return func(tx *ops.Transaction) ([]binder.AffectedEntry, []binder.Diagnostic) { return tx.AddChild(p) }, nil
+	case binder.DeleteParams:
+		p.ForceParse = p.ForceParse || forceParse
+		return func(tx *ops.Transaction) ([]binder.AffectedEntry, []binder.Diagnostic) { return tx.Delete(p) }, nil
+	default: // a MoveParams, the last of the types DecodeParams returns
+		m := p.(binder.MoveParams)
+		m.ForceParse = m.ForceParse || forceParse
+		return func(tx *ops.Transaction) ([]binder.AffectedEntry, []binder.Diagnostic) { return tx.Move(m) }, nil
+	}
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func applySpecs(t *testing.T, io BinderIO, data string, dryRun bool) (*binder.OpResult, error) {
+	t.Helper()
+	specs, err := binder.ParseOpSpecs([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseOpSpecs: %v", err)
+	}
+	return Apply(context.Background(), io, binderPath, specs, false, dryRun)
+}
+
+const applyTestOps = `[
+	{"version":"1","operation":"add","params":{"parentSelector":".","target":"part.md","title":"Part"}},
+	{"version":"1","operation":"move","params":{"sourceSelector":"ch1.md","destinationParentSelector":"part.md","yes":true}}
+]`
+
+func TestApply(t *testing.T) {
+	io := &fakeBinderIO{binder: []byte(oneChild)}
+	res, err := applySpecs(t, io, applyTestOps, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Changed || res.Diff == "" || res.Entries != nil {
+		t.Errorf("result = %+v", res)
+	}
+	if want := "<!-- prosemark-binder:v1 -->\n- [Part](part.md)\n  - [Chapter One](ch1.md)\n"; string(io.binder) != want {
+		t.Errorf("binder = %q, want %q", io.binder, want)
+	}
+	if len(io.written) != 1 {
+		t.Errorf("binder written %d times, want once", len(io.written))
+	}
+}
+
+func TestApply_DryRun(t *testing.T) {
+	io := &fakeBinderIO{binder: []byte(oneChild)}
+	res, err := applySpecs(t, io, applyTestOps, true)
+	if err != nil || !res.Changed || res.Diff == "" {
+		t.Fatalf("result = %+v, err = %v", res, err)
+	}
+	if io.written != nil {
+		t.Errorf("dry run wrote %q", io.written)
+	}
+}
+
+func TestApply_AllOrNothing(t *testing.T) {
+	io := &fakeBinderIO{binder: []byte(oneChild)}
+	res, err := applySpecs(t, io, `[
+		{"version":"1","operation":"add","params":{"parentSelector":".","target":"ch2.md","title":"Two"}},
+		{"version":"1","operation":"delete","params":{"selector":"missing.md","yes":true}}
+	]`, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Changed || !hasError(res.Diagnostics) {
+		t.Errorf("result = %+v, want unchanged with an error", res)
+	}
+	last := res.Diagnostics[len(res.Diagnostics)-1]
+	if !strings.HasPrefix(last.Message, "operation 2 (delete): ") {
+		t.Errorf("diagnostic = %+v, want it attributed to operation 2", last)
+	}
+	if io.written != nil {
+		t.Errorf("wrote %q despite the failed operation", io.written)
+	}
+}
+
+func TestApply_UndecodableOperation(t *testing.T) {
+	io := &fakeBinderIO{binder: []byte(oneChild)}
+	specs := []binder.OpSpec{{Version: "1", Operation: "shred"}}
+	if _, err := Apply(context.Background(), io, binderPath, specs, false, false); err == nil || !strings.Contains(err.Error(), `operation 1: unknown operation "shred"`) {
+		t.Errorf("err = %v, want the unknown operation", err)
+	}
+	if io.written != nil {
+		t.Errorf("wrote %q", io.written)
+	}
+}
+
+func TestApply_RepeatedDiagnosticsOnce(t *testing.T) {
+	// Every operation re-parses the binder and sees the same duplicate
+	// target warning.
+	io := &fakeBinderIO{binder: []byte(oneChild + "- [Again](ch1.md)\n")}
+	res, err := applySpecs(t, io, `[
+		{"version":"1","operation":"add","params":{"parentSelector":".","target":"a.md","title":"A"}},
+		{"version":"1","operation":"add","params":{"parentSelector":".","target":"b.md","title":"B"}}
+	]`, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var dups []binder.Diagnostic
+	for _, d := range res.Diagnostics {
+		if d.Code == "BNDW003" {
+			dups = append(dups, d)
+		}
+	}
+	if len(dups) != 1 || !strings.HasPrefix(dups[0].Message, "operation 1 (add): ") {
+		t.Errorf("BNDW003 diagnostics = %+v, want the warning once, from operation 1", dups)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	specs, err := binder.ParseOpSpecs([]byte(applyTestOps))
+	if err != nil {
+		t.Fatalf("ParseOpSpecs: %v", err)
+	}
+
+	io := &fakeBinderIO{binder: []byte(oneChild)}
+	res, err := Replay(context.Background(), io, binderPath, specs, BinderHash([]byte(oneChild)))
+	if err != nil || !res.Changed || res.BinderBefore != BinderHash([]byte(oneChild)) || res.BinderAfter != BinderHash(io.binder) {
+		t.Fatalf("result = %+v, err = %v", res, err)
+	}
+
+	io = &fakeBinderIO{binder: []byte(oneChild)}
+	res, err = Replay(context.Background(), io, binderPath, specs, BinderHash([]byte("something else")))
+	if err != nil || res.Changed || len(res.Diagnostics) != 1 || res.Diagnostics[0].Code != CodeBinderMismatch {
+		t.Errorf("diverged binder: result = %+v, err = %v; want %s", res, err, CodeBinderMismatch)
+	}
+	if io.written != nil {
+		t.Errorf("diverged binder was written: %q", io.written)
+	}
+
+	res, err = Replay(context.Background(), io, binderPath, specs, "")
+	if err != nil || !res.Changed {
+		t.Errorf("without a precondition: result = %+v, err = %v", res, err)
+	}
+}
+
+func TestBinderHash(t *testing.T) {
+	if got, want := BinderHash(nil), "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"; got != want {
+		t.Errorf("BinderHash(nil) = %q, want %q", got, want)
+	}
+}
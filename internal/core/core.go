@@ -0,0 +1,219 @@
+// Package core implements pmk's commands as plain functions over IO
+// interfaces, independent of cobra. The CLI in package cmd is one frontend;
+// a language server, daemon, or GUI can call the same functions directly
+// instead of building commands and fake argv.
+//
+// Functions take a context, an IO implementation, and parameters, and return
+// a result. They never print: rendering results is the caller's concern.
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/diff"
+)
+
+// BinderIO handles I/O for operations that rewrite the binder.
+type BinderIO interface {
+	ReadBinder(ctx context.Context, path string) ([]byte, error)
+	ScanProject(ctx context.Context, binderPath string) (*binder.Project, error)
+	WriteBinderAtomic(ctx context.Context, path string, data []byte) error
+}
+
+// ScanError reports that the project directory could not be scanned. The CLI
+// reports it as OPE009.
+type ScanError struct {
+	Err error
+}
+
+func (e *ScanError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying scan failure.
+func (e *ScanError) Unwrap() error { return e.Err }
+
+// BinderOp computes an edit of the binder source src. On error diagnostics
+// it returns src unchanged.
+type BinderOp func(ctx context.Context, src []byte, proj *binder.Project) ([]byte, []binder.Diagnostic)
+
+// ApplyBinderOp reads the binder at binderPath, applies op, and writes the
+// result back when it changed and op reported no error diagnostics. The
+// result carries the diff of the change; with dryRun the binder is not
+// written.
+//
+// The result is nil when the binder cannot be read or the project cannot be
+// scanned (a *ScanError). When the write fails the result is returned
+// together with the error, so callers can still report the diagnostics.
+func ApplyBinderOp(ctx context.Context, io BinderIO, binderPath string, dryRun bool, op BinderOp) (*binder.OpResult, error) {
+	return applyEntryOp(ctx, io, binderPath, dryRun, func(ctx context.Context, src []byte, proj *binder.Project) ([]byte, []binder.AffectedEntry, []binder.Diagnostic) {
+		modified, diags := op(ctx, src, proj)
+		return modified, nil, diags
+	})
+}
+
+// entryOp is a BinderOp that also locates the entries it touched.
+type entryOp func(ctx context.Context, src []byte, proj *binder.Project) ([]byte, []binder.AffectedEntry, []binder.Diagnostic)
+
+// applyEntryOp is ApplyBinderOp for an op that reports its entries in the
+// result. The result carries the diff of the change; with dryRun the binder
+// is not written. When op finds no node for one of selectors because it lives
+// in a nested binder, a PMKE013 error says so.
+func applyEntryOp(ctx context.Context, io BinderIO, binderPath string, dryRun bool, op entryOp, selectors ...string) (*binder.OpResult, error) {
+	src, proj, err := readProject(ctx, io, binderPath)
+	if err != nil {
+		return nil, err
+	}
+	modified, entries, diags := op(ctx, src, proj)
+	if slices.ContainsFunc(diags, func(d binder.Diagnostic) bool { return d.Code == binder.CodeSelectorNoMatch }) {
+		diags = append(diags, crossBinderDiags(ctx, io, binderPath, src, proj, selectors)...)
+	}
+	diags = binder.ApplySeverityOverrides(diags, proj.SeverityOverrides)
+	res := newOpResult(src, modified, entries, diags)
+	if hasError(diags) || !res.Changed {
+		return res, nil
+	}
+	res.Diff = BinderDiff(binderPath, src, modified)
+	if dryRun {
+		return res, nil
+	}
+	if err := io.WriteBinderAtomic(ctx, binderPath, modified); err != nil {
+		return res, fmt.Errorf("writing binder: %w", err)
+	}
+	return res, nil
+}
+
+// AddChild adds a child node to the binder at binderPath (pmk add).
+func AddChild(ctx context.Context, io BinderIO, binderPath string, params binder.AddChildParams) (*binder.OpResult, error) {
+	return applyEntryOp(ctx, io, binderPath, params.DryRun, func(ctx context.Context, src []byte, proj *binder.Project) ([]byte, []binder.AffectedEntry, []binder.Diagnostic) {
+		return ops.AddChildEntries(ctx, src, proj, params)
+	}, params.ParentSelector)
+}
+
+// Delete removes a node from the binder at binderPath (pmk delete).
+func Delete(ctx context.Context, io BinderIO, binderPath string, params binder.DeleteParams) (*binder.OpResult, error) {
+	return applyEntryOp(ctx, io, binderPath, params.DryRun, func(ctx context.Context, src []byte, proj *binder.Project) ([]byte, []binder.AffectedEntry, []binder.Diagnostic) {
+		return ops.DeleteEntries(ctx, src, proj, params)
+	}, params.Selector)
+}
+
+// Move moves a node within the binder at binderPath (pmk move).
+func Move(ctx context.Context, io BinderIO, binderPath string, params binder.MoveParams) (*binder.OpResult, error) {
+	return applyEntryOp(ctx, io, binderPath, params.DryRun, func(ctx context.Context, src []byte, proj *binder.Project) ([]byte, []binder.AffectedEntry, []binder.Diagnostic) {
+		return ops.MoveEntries(ctx, src, proj, params)
+	}, params.SourceSelector, params.DestinationParentSelector)
+}
+
+// Promote moves a node up one level in the binder at binderPath
+// (pmk promote).
+func Promote(ctx context.Context, io BinderIO, binderPath string, params binder.ShiftParams) (*binder.OpResult, error) {
+	return applyEntryOp(ctx, io, binderPath, params.DryRun, func(ctx context.Context, src []byte, proj *binder.Project) ([]byte, []binder.AffectedEntry, []binder.Diagnostic) {
+		return ops.Promote(ctx, src, proj, params)
+	}, params.Selector)
+}
+
+// Demote moves a node down one level in the binder at binderPath
+// (pmk demote).
+func Demote(ctx context.Context, io BinderIO, binderPath string, params binder.ShiftParams) (*binder.OpResult, error) {
+	return applyEntryOp(ctx, io, binderPath, params.DryRun, func(ctx context.Context, src []byte, proj *binder.Project) ([]byte, []binder.AffectedEntry, []binder.Diagnostic) {
+		return ops.Demote(ctx, src, proj, params)
+	}, params.Selector)
+}
+
+// Retitle sets the title of a node in the binder at binderPath.
+func Retitle(ctx context.Context, io BinderIO, binderPath string, params binder.RetitleParams) (*binder.OpResult, error) {
+	return applyEntryOp(ctx, io, binderPath, params.DryRun, func(ctx context.Context, src []byte, proj *binder.Project) ([]byte, []binder.AffectedEntry, []binder.Diagnostic) {
+		return ops.Retitle(ctx, src, proj, params)
+	}, params.Selector)
+}
+
+// SetChecked sets a node's task checkbox in the binder at binderPath
+// (pmk check and pmk uncheck).
+func SetChecked(ctx context.Context, io BinderIO, binderPath string, params binder.SetCheckedParams) (*binder.OpResult, error) {
+	return applyEntryOp(ctx, io, binderPath, params.DryRun, func(ctx context.Context, src []byte, proj *binder.Project) ([]byte, []binder.AffectedEntry, []binder.Diagnostic) {
+		modified, diags := ops.SetChecked(ctx, src, proj, params)
+		return modified, nil, diags
+	}, params.Selector)
+}
+
+// readProject reads the binder at binderPath and scans its project.
+func readProject(ctx context.Context, io BinderIO, binderPath string) ([]byte, *binder.Project, error) {
+	src, err := io.ReadBinder(ctx, binderPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading binder: %w", err)
+	}
+	proj, err := io.ScanProject(ctx, binderPath)
+	if err != nil {
+		return nil, nil, &ScanError{Err: err}
+	}
+	return src, proj, nil
+}
+
+// newOpResult builds the result of an edit from src to modified that touched
+// entries.
+func newOpResult(src, modified []byte, entries []binder.AffectedEntry, diags []binder.Diagnostic) *binder.OpResult {
+	if diags == nil {
+		diags = []binder.Diagnostic{}
+	}
+	changed, normalized := ClassifyEdit(src, modified)
+	if !changed {
+		modified = src // a normalization alone is not written
+	}
+	return &binder.OpResult{
+		Version:      "1",
+		Changed:      changed,
+		Normalized:   normalized,
+		Diagnostics:  diags,
+		Entries:      entries,
+		BinderBefore: BinderHash(src),
+		BinderAfter:  BinderHash(modified),
+	}
+}
+
+// ClassifyEdit reports whether the edit from src to modified changed the
+// binder's content, rather than only its blank lines or line endings, and
+// lists the incidental normalizations it made for OpResult.Normalized.
+func ClassifyEdit(src, modified []byte) (bool, []string) {
+	if bytes.Equal(src, modified) {
+		return false, nil
+	}
+	c := diff.Compare(src, modified)
+	var normalized []string
+	if c.BlankLines {
+		normalized = append(normalized, binder.NormalizedBlankLines)
+	}
+	if c.LineEndings {
+		normalized = append(normalized, binder.NormalizedLineEndings)
+	}
+	return c.Content, normalized
+}
+
+// BinderHash returns the hash that identifies binder content in the
+// operation journal: "sha256:" and the hex SHA-256 digest of src.
+func BinderHash(src []byte) string {
+	sum := sha256.Sum256(src)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// BinderDiff returns the unified diff from src to modified, the binder at
+// binderPath before and after an edit, labelled git-style.
+func BinderDiff(binderPath string, src, modified []byte) string {
+	name := filepath.Base(binderPath)
+	return diff.Unified("a/"+name, "b/"+name, src, modified)
+}
+
+// hasError reports whether any diagnostic in diags has error severity.
+func hasError(diags []binder.Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == "error" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,403 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core/coretest"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// fakeBinderIO is an in-memory NewNodeIO for a project rooted at /proj.
+type fakeBinderIO struct {
+	binder      []byte
+	readErr     error
+	scanErr     error
+	writeErr    error
+	written     [][]byte
+	files       map[string][]byte
+	nodeReadErr error
+	fileErr     error
+	deleteErr   error
+	deleted     []string
+	txErr       error
+	// overrides are the scanned project's SeverityOverrides.
+	overrides map[string]string
+}
+
+func (f *fakeBinderIO) ReadBinder(_ context.Context, _ string) ([]byte, error) {
+	return f.binder, f.readErr
+}
+
+func (f *fakeBinderIO) ScanProject(_ context.Context, _ string) (*binder.Project, error) {
+	if f.scanErr != nil {
+		return nil, f.scanErr
+	}
+	return &binder.Project{Files: []string{}, BinderDir: "/proj", SeverityOverrides: f.overrides}, nil
+}
+
+func (f *fakeBinderIO) WriteBinderAtomic(_ context.Context, _ string, data []byte) error {
+	if f.writeErr != nil {
+		return f.writeErr
+	}
+	f.written = append(f.written, data)
+	f.binder = data
+	return nil
+}
+
+func (f *fakeBinderIO) ReadNodeFile(path string) ([]byte, error) {
+	if f.nodeReadErr != nil {
+		return nil, f.nodeReadErr
+	}
+	content, ok := f.files[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return content, nil
+}
+
+func (f *fakeBinderIO) WriteNodeFileAtomic(path string, content []byte) error {
+	if f.fileErr != nil {
+		return f.fileErr
+	}
+	if f.files == nil {
+		f.files = map[string][]byte{}
+	}
+	f.files[path] = content
+	return nil
+}
+
+func (f *fakeBinderIO) DeleteFile(path string) error {
+	f.deleted = append(f.deleted, path)
+	delete(f.files, path)
+	return f.deleteErr
+}
+
+func (f *fakeBinderIO) BeginTx(string) (FileTx, error) {
+	if f.txErr != nil {
+		return nil, f.txErr
+	}
+	return coretest.NewTx(context.Background(), f), nil
+}
+
+// escapingDir returns a project directory whose subdirectory out is a
+// symlink to a directory outside it, holding an outside.md, so targets
+// under out/ escape the project only once the symlink is followed.
+func escapingDir(t *testing.T) string {
+	t.Helper()
+	dir, outside := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "outside.md"), []byte("Outside.\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "out")); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+const (
+	binderPath = "/proj/_binder.md"
+	oneChild   = "<!-- prosemark-binder:v1 -->\n- [Chapter One](ch1.md)\n"
+)
+
+func TestBinderOps(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		run  func(io BinderIO) (*binder.OpResult, error)
+		want string
+	}{
+		{
+			"add",
+			func(io BinderIO) (*binder.OpResult, error) {
+				return AddChild(ctx, io, binderPath, binder.AddChildParams{ParentSelector: ".", Target: "ch2.md", Title: "Chapter Two", Position: "last"})
+			},
+			oneChild + "- [Chapter Two](ch2.md)\n",
+		},
+		{
+			"delete",
+			func(io BinderIO) (*binder.OpResult, error) {
+				return Delete(ctx, io, binderPath, binder.DeleteParams{Selector: "ch1.md", Yes: true})
+			},
+			"<!-- prosemark-binder:v1 -->\n",
+		},
+		{
+			"retitle",
+			func(io BinderIO) (*binder.OpResult, error) {
+				return Retitle(ctx, io, binderPath, binder.RetitleParams{Selector: "ch1.md", Title: "Prologue"})
+			},
+			"<!-- prosemark-binder:v1 -->\n- [Prologue](ch1.md)\n",
+		},
+		{
+			"check",
+			func(io BinderIO) (*binder.OpResult, error) {
+				return SetChecked(ctx, io, binderPath, binder.SetCheckedParams{Selector: "ch1.md", Checked: true})
+			},
+			"<!-- prosemark-binder:v1 -->\n- [x] [Chapter One](ch1.md)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			io := &fakeBinderIO{binder: []byte(oneChild)}
+			res, err := tt.run(io)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !res.Changed || res.Version != "1" || res.Diagnostics == nil {
+				t.Errorf("result = %+v", res)
+			}
+			if got := string(io.binder); got != tt.want {
+				t.Errorf("binder = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMove(t *testing.T) {
+	io := &fakeBinderIO{binder: []byte(oneChild + "- [Part](part.md)\n")}
+	res, err := Move(context.Background(), io, binderPath, binder.MoveParams{SourceSelector: "ch1.md", DestinationParentSelector: "part.md", Position: "last", Yes: true})
+	if err != nil || !res.Changed {
+		t.Fatalf("Move = %+v, %v", res, err)
+	}
+	if want := "<!-- prosemark-binder:v1 -->\n- [Part](part.md)\n  - [Chapter One](ch1.md)\n"; string(io.binder) != want {
+		t.Errorf("binder = %q, want %q", io.binder, want)
+	}
+	if want := []binder.AffectedEntry{{Target: "ch1.md", Title: "Chapter One", Line: 3, Path: []int{0, 0}}}; !reflect.DeepEqual(res.Entries, want) {
+		t.Errorf("entries = %+v, want %+v", res.Entries, want)
+	}
+}
+
+func TestPromoteDemote(t *testing.T) {
+	flat := oneChild + "- [Part](part.md)\n"
+	io := &fakeBinderIO{binder: []byte(flat)}
+	res, err := Demote(context.Background(), io, binderPath, binder.ShiftParams{Selector: "part.md"})
+	if err != nil || !res.Changed || len(res.Entries) != 1 {
+		t.Fatalf("Demote = %+v, %v", res, err)
+	}
+	if want := oneChild + "  - [Part](part.md)\n"; string(io.binder) != want {
+		t.Errorf("binder = %q, want %q", io.binder, want)
+	}
+
+	res, err = Promote(context.Background(), io, binderPath, binder.ShiftParams{Selector: "part.md"})
+	if err != nil || !res.Changed || len(res.Entries) != 1 {
+		t.Fatalf("Promote = %+v, %v", res, err)
+	}
+	if string(io.binder) != flat {
+		t.Errorf("binder = %q, want %q", io.binder, flat)
+	}
+}
+
+func TestApplyBinderOp_NoWrite(t *testing.T) {
+	tests := []struct {
+		name   string
+		params binder.AddChildParams
+	}{
+		{"error diagnostics", binder.AddChildParams{ParentSelector: "missing", Target: "ch2.md", Position: "last"}},
+		{"unchanged", binder.AddChildParams{ParentSelector: ".", Target: "ch1.md", Position: "last"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			io := &fakeBinderIO{binder: []byte(oneChild)}
+			res, err := AddChild(context.Background(), io, binderPath, tt.params)
+			if err != nil || res == nil || res.Changed {
+				t.Fatalf("AddChild = %+v, %v", res, err)
+			}
+			if len(res.Diagnostics) == 0 {
+				t.Error("want diagnostics")
+			}
+			if io.written != nil {
+				t.Errorf("binder written: %q", io.written)
+			}
+		})
+	}
+}
+
+func TestApplyBinderOp_Errors(t *testing.T) {
+	add := binder.AddChildParams{ParentSelector: ".", Target: "ch2.md", Position: "last"}
+	tests := []struct {
+		name       string
+		io         *fakeBinderIO
+		wantResult bool
+		wantErr    string
+	}{
+		{"read", &fakeBinderIO{readErr: errors.New("denied")}, false, "reading binder: denied"},
+		{"scan", &fakeBinderIO{binder: []byte(oneChild), scanErr: errors.New("denied")}, false, "denied"},
+		{"write", &fakeBinderIO{binder: []byte(oneChild), writeErr: errors.New("full")}, true, "writing binder: full"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := AddChild(context.Background(), tt.io, binderPath, add)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+			if (res != nil) != tt.wantResult {
+				t.Errorf("result = %+v, want result %v", res, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestScanError(t *testing.T) {
+	io := &fakeBinderIO{binder: []byte(oneChild), scanErr: os.ErrPermission}
+	_, err := Delete(context.Background(), io, binderPath, binder.DeleteParams{Selector: "ch1.md", Yes: true})
+	var scanErr *ScanError
+	if !errors.As(err, &scanErr) {
+		t.Fatalf("err = %v, want *ScanError", err)
+	}
+	if !errors.Is(err, os.ErrPermission) || err.Error() != os.ErrPermission.Error() {
+		t.Errorf("err = %v, want the scan failure", err)
+	}
+}
+
+func TestApplyBinderOp_CustomOp(t *testing.T) {
+	io := &fakeBinderIO{binder: []byte(oneChild)}
+	res, err := ApplyBinderOp(context.Background(), io, binderPath, false, func(_ context.Context, src []byte, _ *binder.Project) ([]byte, []binder.Diagnostic) {
+		return append(src, "- [Extra](extra.md)\n"...), nil
+	})
+	if err != nil || !res.Changed || res.Diagnostics == nil || len(res.Diagnostics) != 0 {
+		t.Fatalf("ApplyBinderOp = %+v, %v", res, err)
+	}
+	if want := oneChild + "- [Extra](extra.md)\n"; string(io.binder) != want {
+		t.Errorf("binder = %q, want %q", io.binder, want)
+	}
+	if want := "+- [Extra](extra.md)\n"; !strings.HasSuffix(res.Diff, want) {
+		t.Errorf("diff = %q, want it to end with %q", res.Diff, want)
+	}
+}
+
+func TestApplyBinderOp_Normalized(t *testing.T) {
+	// Collapsing blank lines alone is not a change and is not written.
+	padded := oneChild + "\n\n\n"
+	io := &fakeBinderIO{binder: []byte(padded)}
+	res, err := ApplyBinderOp(context.Background(), io, binderPath, false, func(_ context.Context, _ []byte, _ *binder.Project) ([]byte, []binder.Diagnostic) {
+		return []byte(oneChild), nil
+	})
+	if err != nil || res.Changed || !reflect.DeepEqual(res.Normalized, []string{binder.NormalizedBlankLines}) {
+		t.Fatalf("ApplyBinderOp = %+v, %v; want unchanged with blank-lines normalized", res, err)
+	}
+	if len(io.written) != 0 || res.BinderAfter != res.BinderBefore {
+		t.Errorf("a normalization alone was written")
+	}
+
+	// Appending after a final line without an ending is a change that also
+	// normalizes that line's ending.
+	unterminated := strings.TrimSuffix(oneChild, "\n")
+	io = &fakeBinderIO{binder: []byte(unterminated)}
+	res, err = AddChild(context.Background(), io, binderPath, binder.AddChildParams{ParentSelector: ".", Target: "ch2.md", Title: "Two"})
+	if err != nil || !res.Changed || !reflect.DeepEqual(res.Normalized, []string{binder.NormalizedLineEndings}) {
+		t.Errorf("AddChild = %+v, %v; want changed with line-endings normalized", res, err)
+	}
+}
+
+func TestClassifyEdit(t *testing.T) {
+	if changed, normalized := ClassifyEdit([]byte(oneChild), []byte(oneChild)); changed || normalized != nil {
+		t.Errorf("identical binders: ClassifyEdit = %v, %v", changed, normalized)
+	}
+	crlf := strings.ReplaceAll(oneChild, "\n", "\r\n")
+	if changed, normalized := ClassifyEdit([]byte(oneChild), []byte(crlf+"\r\n")); changed || !reflect.DeepEqual(normalized, []string{binder.NormalizedBlankLines, binder.NormalizedLineEndings}) {
+		t.Errorf("CRLF and a blank line: ClassifyEdit = %v, %v", changed, normalized)
+	}
+}
+
+func TestApplyBinderOp_SeverityOverrides(t *testing.T) {
+	addExtra := func(_ context.Context, src []byte, _ *binder.Project) ([]byte, []binder.Diagnostic) {
+		return append(src, "- [Extra](extra.md)\n"...), []binder.Diagnostic{{Severity: "warning", Code: binder.CodeCascadeDelete}}
+	}
+	io := &fakeBinderIO{binder: []byte(oneChild), overrides: map[string]string{binder.CodeCascadeDelete: "error"}}
+	res, err := ApplyBinderOp(context.Background(), io, binderPath, false, addExtra)
+	if err != nil || len(res.Diagnostics) != 1 || res.Diagnostics[0].Severity != "error" {
+		t.Fatalf("ApplyBinderOp = %+v, %v; want the warning promoted", res, err)
+	}
+	if len(io.written) != 0 {
+		t.Error("binder written despite a promoted error")
+	}
+
+	io.overrides = map[string]string{binder.CodeCascadeDelete: binder.SeverityOff}
+	if res, err = ApplyBinderOp(context.Background(), io, binderPath, false, addExtra); err != nil || len(res.Diagnostics) != 0 || len(io.written) != 1 {
+		t.Errorf("ApplyBinderOp = %+v, %v; want the warning silenced and the binder written", res, err)
+	}
+
+	// Parse diagnostics promoted to errors block mutations like any other.
+	io = &fakeBinderIO{binder: []byte(oneChild), overrides: map[string]string{binder.CodeMissingTargetFile: "error"}}
+	res, err = AddChild(context.Background(), io, binderPath, binder.AddChildParams{ParentSelector: ".", Target: "ch2.md", Title: "Two"})
+	if err != nil || !hasError(res.Diagnostics) || len(io.written) != 0 {
+		t.Errorf("AddChild = %+v, %v; want a parse error blocking the write", res, err)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	ctx := context.Background()
+	const uuidTarget = "0192f0c1-0000-7000-8000-000000000004.md"
+	tests := []struct {
+		name     string
+		run      func(io *fakeBinderIO) (*binder.OpResult, error)
+		wantDiff string
+	}{
+		{
+			"delete",
+			func(io *fakeBinderIO) (*binder.OpResult, error) {
+				return Delete(ctx, io, binderPath, binder.DeleteParams{Selector: "ch1.md", Yes: true, DryRun: true})
+			},
+			"-- [Chapter One](ch1.md)\n",
+		},
+		{
+			"add new node",
+			func(io *fakeBinderIO) (*binder.OpResult, error) {
+				params := binder.AddChildParams{ParentSelector: ".", Target: uuidTarget, Title: "Two", Position: "last", DryRun: true}
+				res, err := AddNewNode(ctx, io, binderPath, params, node.Frontmatter{Title: "Two"}, nil, newNodeNow)
+				return &res.OpResult, err
+			},
+			"+- [Two](" + uuidTarget + ")\n",
+		},
+		{
+			"materialize",
+			func(io *fakeBinderIO) (*binder.OpResult, error) {
+				io.binder = append(io.binder, "- [Interlude]()\n"...)
+				res, err := Materialize(ctx, io, binderPath, binder.MaterializeParams{Selector: "Interlude", Target: uuidTarget, DryRun: true}, newNodeNow)
+				return &res.OpResult, err
+			},
+			"+- [Interlude](" + uuidTarget + ")\n",
+		},
+		{
+			"rename",
+			func(io *fakeBinderIO) (*binder.OpResult, error) {
+				res, err := Rename(ctx, io, binderPath, binder.RenameParams{Selector: "ch1", NewTarget: "one.md", DryRun: true})
+				return &res.OpResult, err
+			},
+			"+- [Chapter One](one.md)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			io := &fakeBinderIO{binder: []byte(oneChild), files: map[string][]byte{"/proj/ch1.md": []byte("Body.\n")}}
+			res, err := tt.run(io)
+			if err != nil || !res.Changed {
+				t.Fatalf("result = %+v, %v", res, err)
+			}
+			if !strings.HasPrefix(res.Diff, "--- a/_binder.md\n+++ b/_binder.md\n") || !strings.Contains(res.Diff, tt.wantDiff) {
+				t.Errorf("diff = %q, want it to contain %q", res.Diff, tt.wantDiff)
+			}
+			if io.written != nil || len(io.files) != 1 || io.deleted != nil {
+				t.Errorf("dry run wrote: binder %q, files %q, deleted %v", io.written, io.files, io.deleted)
+			}
+		})
+	}
+}
+
+func TestDryRun_ErrorDiagnostics(t *testing.T) {
+	io := &fakeBinderIO{binder: []byte(oneChild)}
+	params := binder.AddChildParams{ParentSelector: "missing", Target: "ch2.md", Position: "last", DryRun: true}
+	res, err := AddNewNode(context.Background(), io, binderPath, params, node.Frontmatter{}, nil, newNodeNow)
+	if err != nil || res.Changed || res.Diff != "" || !hasError(res.Diagnostics) {
+		t.Errorf("result = %+v, %v", res, err)
+	}
+	if io.files != nil || io.deleted != nil {
+		t.Errorf("dry run touched files: %q, deleted %v", io.files, io.deleted)
+	}
+}
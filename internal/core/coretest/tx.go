@@ -0,0 +1,148 @@
+// Package coretest provides an in-memory file transaction for the fake IOs
+// that test package core and the commands over it, which keep their files
+// in memory rather than in a project the journaled transactions of package
+// fsio could write to.
+package coretest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// FileIO is the IO a Tx applies its steps through. Undoing the write of a
+// new file needs DeleteFile too, which most fakes have.
+type FileIO interface {
+	ReadBinder(ctx context.Context, path string) ([]byte, error)
+	WriteBinderAtomic(ctx context.Context, path string, data []byte) error
+	ReadNodeFile(path string) ([]byte, error)
+	WriteNodeFileAtomic(path string, content []byte) error
+}
+
+// NewTx returns a Tx that applies its steps through io.
+func NewTx(ctx context.Context, io FileIO) *Tx {
+	return &Tx{ctx: ctx, io: io}
+}
+
+// Tx is an in-memory core.FileTx over a FileIO. Commit applies each step
+// through the IO, first reading any content it replaces, and on failure
+// writes that content back, last step first.
+type Tx struct {
+	ctx   context.Context
+	io    FileIO
+	steps []step
+}
+
+// step is one step of a Tx: a write of data to path, or its removal.
+type step struct {
+	path                   string
+	data                   []byte
+	binder, create, remove bool
+}
+
+// Write stages writing data to path.
+func (t *Tx) Write(path string, data []byte) {
+	t.steps = append(t.steps, step{path: path, data: data})
+}
+
+// Create stages writing data to the new file at path.
+func (t *Tx) Create(path string, data []byte) {
+	t.steps = append(t.steps, step{path: path, data: data, create: true})
+}
+
+// WriteBinder stages writing binder data to path.
+func (t *Tx) WriteBinder(path string, data []byte) {
+	t.steps = append(t.steps, step{path: path, data: data, binder: true})
+}
+
+// Remove stages removing the file at path.
+func (t *Tx) Remove(path string) {
+	t.steps = append(t.steps, step{path: path, remove: true})
+}
+
+// Commit applies t's steps in order, undoing them when one fails.
+func (t *Tx) Commit() error {
+	var undo []func() error
+	for _, s := range t.steps {
+		u, err := t.apply(s)
+		if err != nil {
+			var errs []error
+			for i := len(undo) - 1; i >= 0; i-- {
+				if err := undo[i](); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			return withRollback(err, errors.Join(errs...))
+		}
+		undo = append(undo, u)
+	}
+	t.steps = nil
+	return nil
+}
+
+// Abort discards t's steps.
+func (t *Tx) Abort() error {
+	t.steps = nil
+	return nil
+}
+
+// apply applies step s and returns the function that undoes it. The file
+// of a create step was checked to be absent by the operation, and is not
+// read again.
+func (t *Tx) apply(s step) (func() error, error) {
+	if s.create {
+		if err := t.io.WriteNodeFileAtomic(s.path, s.data); err != nil {
+			return nil, fmt.Errorf("creating %s: %w", s.path, err)
+		}
+		return func() error { return t.remove(s.path) }, nil
+	}
+	if s.binder {
+		original, err := t.io.ReadBinder(t.ctx, s.path)
+		if err != nil {
+			return nil, fmt.Errorf("reading binder: %w", err)
+		}
+		if err := t.io.WriteBinderAtomic(t.ctx, s.path, s.data); err != nil {
+			return nil, fmt.Errorf("writing binder: %w", err)
+		}
+		return func() error { return t.io.WriteBinderAtomic(t.ctx, s.path, original) }, nil
+	}
+	original, err := t.io.ReadNodeFile(s.path)
+	existed := err == nil
+	if err != nil && (s.remove || !errors.Is(err, os.ErrNotExist)) {
+		return nil, fmt.Errorf("reading %s: %w", s.path, err)
+	}
+	restore := func() error {
+		if existed {
+			return t.io.WriteNodeFileAtomic(s.path, original)
+		}
+		return t.remove(s.path)
+	}
+	if s.remove {
+		if err := t.remove(s.path); err != nil {
+			return nil, fmt.Errorf("removing %s: %w", s.path, err)
+		}
+		return restore, nil
+	}
+	if err := t.io.WriteNodeFileAtomic(s.path, s.data); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", s.path, err)
+	}
+	return restore, nil
+}
+
+// remove deletes the file at path through t's IO.
+func (t *Tx) remove(path string) error {
+	d, ok := t.io.(interface{ DeleteFile(path string) error })
+	if !ok {
+		return fmt.Errorf("cannot remove %s", path)
+	}
+	return d.DeleteFile(path)
+}
+
+// withRollback adds a failed rollback to err.
+func withRollback(err, rollbackErr error) error {
+	if rollbackErr != nil {
+		return fmt.Errorf("%w; rollback also failed: %v", err, rollbackErr)
+	}
+	return err
+}
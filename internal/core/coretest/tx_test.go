@@ -0,0 +1,181 @@
+package coretest_test
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/core/coretest"
+)
+
+// memIO is an in-memory FileIO that can fail particular paths.
+type memIO struct {
+	binder              []byte
+	binderReadErr       error
+	files               map[string][]byte
+	readErrs, writeErrs map[string]error
+	deleteErrs          map[string]error
+}
+
+func (m *memIO) ReadBinder(context.Context, string) ([]byte, error) {
+	return m.binder, m.binderReadErr
+}
+
+func (m *memIO) WriteBinderAtomic(_ context.Context, path string, data []byte) error {
+	if err := m.writeErrs[path]; err != nil {
+		return err
+	}
+	m.binder = data
+	return nil
+}
+
+func (m *memIO) ReadNodeFile(path string) ([]byte, error) {
+	if err := m.readErrs[path]; err != nil {
+		return nil, err
+	}
+	content, ok := m.files[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return content, nil
+}
+
+func (m *memIO) WriteNodeFileAtomic(path string, content []byte) error {
+	if err := m.writeErrs[path]; err != nil {
+		return err
+	}
+	m.files[path] = content
+	return nil
+}
+
+func (m *memIO) DeleteFile(path string) error {
+	if err := m.deleteErrs[path]; err != nil {
+		return err
+	}
+	delete(m.files, path)
+	return nil
+}
+
+// writeOnlyIO is a memIO without DeleteFile.
+type writeOnlyIO struct{ coretest.FileIO }
+
+func TestTx(t *testing.T) {
+	failed := errors.New("failed")
+	original := map[string]string{"a.md": "A", "b.md": "B"}
+	tests := []struct {
+		name       string
+		setup      func(m *memIO)
+		io         func(m *memIO) coretest.FileIO
+		wantErr    string
+		wantFiles  map[string]string
+		wantBinder string
+	}{
+		{
+			name:       "commit",
+			wantFiles:  map[string]string{"a.md": "A2", "c.md": "C", "d.md": "D", "e.md": "E"},
+			wantBinder: "binder2",
+		},
+		{
+			name:    "binder write undoes the files",
+			setup:   func(m *memIO) { m.writeErrs = map[string]error{"_binder.md": failed} },
+			wantErr: "writing binder: failed",
+		},
+		{
+			name:    "write after the binder undoes it",
+			setup:   func(m *memIO) { m.writeErrs = map[string]error{"e.md": failed} },
+			wantErr: "writing e.md: failed",
+		},
+		{
+			name:    "binder read",
+			setup:   func(m *memIO) { m.binderReadErr = failed },
+			wantErr: "reading binder: failed",
+		},
+		{
+			name:    "create",
+			setup:   func(m *memIO) { m.writeErrs = map[string]error{"c.md": failed} },
+			wantErr: "creating c.md: failed",
+		},
+		{
+			name:    "write",
+			setup:   func(m *memIO) { m.writeErrs = map[string]error{"a.md": failed} },
+			wantErr: "writing a.md: failed",
+		},
+		{
+			name:    "unreadable file",
+			setup:   func(m *memIO) { m.readErrs = map[string]error{"a.md": failed} },
+			wantErr: "reading a.md: failed",
+		},
+		{
+			name:      "missing file to remove",
+			setup:     func(m *memIO) { delete(m.files, "b.md") },
+			wantErr:   "reading b.md: file does not exist",
+			wantFiles: map[string]string{"a.md": "A"},
+		},
+		{
+			name:    "removal",
+			setup:   func(m *memIO) { m.deleteErrs = map[string]error{"b.md": failed} },
+			wantErr: "removing b.md: failed",
+		},
+		{
+			name:      "removal without DeleteFile",
+			io:        func(m *memIO) coretest.FileIO { return writeOnlyIO{m} },
+			wantErr:   "removing b.md: cannot remove b.md; rollback also failed: cannot remove d.md\ncannot remove c.md",
+			wantFiles: map[string]string{"a.md": "A", "b.md": "B", "c.md": "C", "d.md": "D"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &memIO{binder: []byte("binder"), files: map[string][]byte{}}
+			for path, content := range original {
+				m.files[path] = []byte(content)
+			}
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+			var io coretest.FileIO = m
+			if tt.io != nil {
+				io = tt.io(m)
+			}
+			tx := coretest.NewTx(context.Background(), io)
+			tx.Create("c.md", []byte("C"))
+			tx.Write("a.md", []byte("A2"))
+			tx.Write("d.md", []byte("D"))
+			tx.Remove("b.md")
+			tx.WriteBinder("_binder.md", []byte("binder2"))
+			tx.Write("e.md", []byte("E"))
+			err := tx.Commit()
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Commit() = %v, want %q", err, tt.wantErr)
+			}
+			wantFiles, wantBinder := tt.wantFiles, tt.wantBinder
+			if wantFiles == nil {
+				wantFiles = original
+			}
+			if wantBinder == "" {
+				wantBinder = "binder"
+			}
+			got := map[string]string{}
+			for path, content := range m.files {
+				got[path] = string(content)
+			}
+			if !maps.Equal(got, wantFiles) || string(m.binder) != wantBinder {
+				t.Errorf("files = %v, binder = %q; want %v, %q", got, m.binder, wantFiles, wantBinder)
+			}
+		})
+	}
+}
+
+func TestTx_Abort(t *testing.T) {
+	m := &memIO{files: map[string][]byte{}}
+	tx := coretest.NewTx(context.Background(), m)
+	tx.Create("c.md", []byte("C"))
+	if err := tx.Abort(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil || len(m.files) != 0 {
+		t.Errorf("Commit() after Abort = %v with files %v, want nothing written", err, m.files)
+	}
+}
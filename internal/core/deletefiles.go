@@ -0,0 +1,178 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// Cascade modes for DeleteWithFiles.
+const (
+	// CascadeFiles removes the deleted entries' files.
+	CascadeFiles = "files"
+	// CascadeTrash moves the deleted entries' files into the trash, from
+	// which Restore can bring the deletion back.
+	CascadeTrash = "trash"
+)
+
+// DeleteFilesIO handles I/O for deleting entries along with their files.
+type DeleteFilesIO interface {
+	BinderIO
+	TxIO
+	ReadNodeFile(path string) ([]byte, error)
+}
+
+// DeleteResult is the outcome of DeleteWithFiles.
+type DeleteResult struct {
+	binder.OpResult
+	// Files are the project-relative slash paths of the files removed with
+	// the entries, in binder order.
+	Files []string
+	// TrashID is the ID of the deletion's trash record, under CascadeTrash.
+	TrashID string
+}
+
+// DeleteWithFiles is Delete that also removes the files of the deleted
+// entries and their descendants (pmk delete --cascade): each node file no
+// longer referenced anywhere in the binder, and its companion notes file
+// ({stem}.notes.md). Only files found by the project scan are touched. With
+// CascadeTrash the files are moved into the trash rather than removed, and
+// the deletion is recorded in the trash manifest, stamped with now (RFC
+// 3339), for Restore.
+//
+// Trash copies are written first, then the manifest, then the binder, and
+// the files are removed last, in one FileTx as in Rename. A dry run lists
+// the files but touches nothing.
+func DeleteWithFiles(ctx context.Context, io DeleteFilesIO, binderPath string, params binder.DeleteParams, mode, now string) (*DeleteResult, error) {
+	if mode != CascadeFiles && mode != CascadeTrash {
+		return nil, fmt.Errorf("unknown cascade mode %q: want %s or %s", mode, CascadeFiles, CascadeTrash)
+	}
+	src, proj, err := readProject(ctx, io, binderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	modified, entries, removed, diags := ops.DeleteSubtrees(ctx, src, proj, params)
+	diags = binder.ApplySeverityOverrides(diags, proj.SeverityOverrides)
+	res := &DeleteResult{OpResult: *newOpResult(src, modified, entries, diags)}
+	if hasError(diags) || !res.Changed {
+		return res, nil
+	}
+	res.Diff = BinderDiff(binderPath, src, modified)
+	res.Files = unreferencedFiles(ctx, src, modified, proj)
+
+	var manifest *TrashManifest
+	if mode == CascadeTrash {
+		if manifest, err = ReadTrash(io, binderPath); err != nil {
+			return nil, err
+		}
+		rec := newTrashRecord(manifest, now, params.Selector, removed, res.Files)
+		manifest.Records = append(manifest.Records, rec)
+		res.TrashID = rec.ID
+	}
+
+	dir := filepath.Dir(binderPath)
+	moves := make([]fileMove, 0, len(res.Files))
+	for _, rel := range res.Files {
+		var m fileMove
+		if m.from, err = safepath.Resolve(dir, rel); err != nil {
+			return nil, err
+		}
+		if m.content, err = io.ReadNodeFile(m.from); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", rel, err)
+		}
+		if mode == CascadeTrash {
+			m.to = trashPath(binderPath, res.TrashID, rel)
+			if _, err := io.ReadNodeFile(m.to); err == nil {
+				return nil, fmt.Errorf("%s already exists", m.to)
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("checking %s: %w", m.to, err)
+			}
+		}
+		moves = append(moves, m)
+	}
+	if params.DryRun {
+		return res, nil
+	}
+
+	tx, err := beginTx(io, binderPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range moves {
+		if m.to != "" {
+			tx.Create(m.to, m.content)
+		}
+	}
+	if manifest != nil {
+		tx.Write(trashManifestPath(binderPath), MarshalTrash(manifest))
+	}
+	tx.WriteBinder(binderPath, modified)
+	for _, m := range moves {
+		tx.Remove(m.from)
+	}
+	return res, tx.Commit()
+}
+
+// unreferencedFiles returns the project files that the binder src
+// references and modified no longer does, each followed by its notes file
+// when the project has one. It returns none if either binder fails to
+// parse.
+func unreferencedFiles(ctx context.Context, src, modified []byte, proj *binder.Project) []string {
+	before, okBefore := binderTargets(ctx, src, proj)
+	after, okAfter := binderTargets(ctx, modified, proj)
+	if !okBefore || !okAfter {
+		return nil
+	}
+	inProject := make(map[string]bool, len(proj.Files))
+	for _, f := range proj.Files {
+		inProject[f] = true
+	}
+	kept := make(map[string]bool, len(after))
+	for _, t := range after {
+		kept[t] = true
+	}
+
+	var files []string
+	seen := make(map[string]bool)
+	for _, t := range before {
+		if kept[t] || seen[t] || !inProject[t] {
+			continue
+		}
+		seen[t] = true
+		files = append(files, t)
+		if notes := strings.TrimSuffix(t, ".md") + ".notes.md"; inProject[notes] && !kept[notes] && !seen[notes] {
+			seen[notes] = true
+			files = append(files, notes)
+		}
+	}
+	return files
+}
+
+// binderTargets returns the cleaned targets of the entries in the binder
+// src, in document order. ok is false when src cannot be parsed.
+func binderTargets(ctx context.Context, src []byte, proj *binder.Project) (targets []string, ok bool) {
+	result, _, err := binder.Parse(ctx, src, proj)
+	if err != nil {
+		return nil, false
+	}
+	var walk func(nodes []*binder.Node)
+	walk = func(nodes []*binder.Node) {
+		for _, n := range nodes {
+			if n.Target != "" {
+				targets = append(targets, path.Clean(n.Target))
+			}
+			walk(n.Children)
+		}
+	}
+	walk(result.Root.Children)
+	return targets, true
+}
@@ -0,0 +1,284 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// deleteTestBinder nests ch2 under ch1 but also lists it on its own, so
+// deleting ch1 leaves ch2 referenced.
+const deleteTestBinder = "<!-- prosemark-binder:v1 -->\n" +
+	"- [One](ch1.md)\n" +
+	"  - [Two](ch2.md)\n" +
+	"  - [Three](ch3.md)\n" +
+	"- [Two again](ch2.md)\n"
+
+// deleteTestIO is a renameTestIO whose project scan lists its files.
+type deleteTestIO struct {
+	*renameTestIO
+}
+
+func newDeleteTestIO() deleteTestIO {
+	io := newRenameTestIO()
+	io.binder = []byte(deleteTestBinder)
+	io.files["/proj/ch2.md"] = []byte("Two.\n")
+	io.files["/proj/ch3.md"] = []byte("Three.\n")
+	return deleteTestIO{io}
+}
+
+func (d deleteTestIO) ScanProject(_ context.Context, _ string) (*binder.Project, error) {
+	var files []string
+	for p := range d.files {
+		if !strings.HasPrefix(p, "/proj/.prosemark/") {
+			files = append(files, strings.TrimPrefix(p, "/proj/"))
+		}
+	}
+	slices.Sort(files)
+	return &binder.Project{Files: files, BinderDir: "/proj"}, nil
+}
+
+// fileNames returns the sorted paths of io's files.
+func fileNames(io deleteTestIO) []string {
+	var got []string
+	for p := range io.files {
+		got = append(got, p)
+	}
+	slices.Sort(got)
+	return got
+}
+
+// deleteTestNow is when deleteCh1 deletes, so its trash ID is
+// "20260501T093000Z".
+const deleteTestNow = "2026-05-01T09:30:00Z"
+
+// Where deleteCh1 puts the trash.
+const (
+	trashedDir    = "/proj/.prosemark/trash/20260501T093000Z/"
+	trashManifest = "/proj/.prosemark/trash/manifest.json"
+)
+
+func deleteCh1(io deleteTestIO, mode string, dryRun bool) (*DeleteResult, error) {
+	params := binder.DeleteParams{Selector: "ch1", Yes: true, DryRun: dryRun}
+	return DeleteWithFiles(context.Background(), io, binderPath, params, mode, deleteTestNow)
+}
+
+func TestDeleteWithFiles(t *testing.T) {
+	const wantBinder = "<!-- prosemark-binder:v1 -->\n- [Two again](ch2.md)\n"
+	tests := []struct {
+		mode      string
+		wantFiles []string
+	}{
+		{CascadeFiles, []string{"/proj/ch2.md"}},
+		{CascadeTrash, []string{trashedDir + "ch1.md", trashedDir + "ch1.notes.md", trashedDir + "ch3.md", trashManifest, "/proj/ch2.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			io := newDeleteTestIO()
+			res, err := deleteCh1(io, tt.mode, false)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := []string{"ch1.md", "ch1.notes.md", "ch3.md"}; !slices.Equal(res.Files, want) {
+				t.Errorf("Files = %v, want %v", res.Files, want)
+			}
+			if string(io.binder) != wantBinder {
+				t.Errorf("binder = %q, want %q", io.binder, wantBinder)
+			}
+			if got := fileNames(io); !slices.Equal(got, tt.wantFiles) {
+				t.Errorf("files = %v, want %v", got, tt.wantFiles)
+			}
+			if tt.mode == CascadeTrash && string(io.files[trashedDir+"ch1.notes.md"]) != "Notes.\n" {
+				t.Errorf("trashed notes = %q", io.files[trashedDir+"ch1.notes.md"])
+			}
+		})
+	}
+}
+
+func TestDeleteWithFiles_DryRun(t *testing.T) {
+	io := newDeleteTestIO()
+	res, err := deleteCh1(io, CascadeTrash, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Files) != 3 || res.Diff == "" {
+		t.Errorf("result = %+v, want the files and diff", res)
+	}
+	if io.written != nil || len(io.files) != 4 {
+		t.Errorf("dry run wrote: binder %v, files %v", io.written != nil, fileNames(io))
+	}
+}
+
+func TestDeleteWithFiles_NothingDeleted(t *testing.T) {
+	io := newDeleteTestIO()
+	res, err := DeleteWithFiles(context.Background(), io, binderPath, binder.DeleteParams{Selector: "missing", Yes: true}, CascadeFiles, deleteTestNow)
+	if err != nil || res == nil || !hasError(res.Diagnostics) || res.Files != nil || len(io.files) != 4 {
+		t.Errorf("result = %+v, err = %v, files = %v", res, err, fileNames(io))
+	}
+}
+
+func TestDeleteWithFiles_Errors(t *testing.T) {
+	failed := errors.New("failed")
+	all := []string{"/proj/ch1.md", "/proj/ch1.notes.md", "/proj/ch2.md", "/proj/ch3.md"}
+	tests := []struct {
+		name       string
+		mode       string
+		setup      func(io deleteTestIO)
+		wantResult bool
+		wantErr    string
+		wantFiles  []string
+		wantBinder string
+	}{
+		{
+			name:    "unknown mode",
+			mode:    "shred",
+			setup:   func(deleteTestIO) {},
+			wantErr: `unknown cascade mode "shred"`,
+		},
+		{
+			name:      "binder unreadable",
+			mode:      CascadeFiles,
+			setup:     func(io deleteTestIO) { io.readErr = failed },
+			wantErr:   "reading binder: failed",
+			wantFiles: all,
+		},
+		{
+			name:      "file unreadable",
+			mode:      CascadeFiles,
+			setup:     func(io deleteTestIO) { io.readErrs = map[string]error{"/proj/ch3.md": failed} },
+			wantErr:   "reading ch3.md: failed",
+			wantFiles: all,
+		},
+		{
+			name:      "trash copy exists",
+			mode:      CascadeTrash,
+			setup:     func(io deleteTestIO) { io.files[trashedDir+"ch3.md"] = []byte("Old.\n") },
+			wantErr:   trashedDir + "ch3.md already exists",
+			wantFiles: append([]string{trashedDir + "ch3.md"}, all...),
+		},
+		{
+			name:      "trash uncheckable",
+			mode:      CascadeTrash,
+			setup:     func(io deleteTestIO) { io.readErrs = map[string]error{trashedDir + "ch1.md": failed} },
+			wantErr:   "checking " + trashedDir + "ch1.md: failed",
+			wantFiles: all,
+		},
+		{
+			name:       "trash write",
+			mode:       CascadeTrash,
+			setup:      func(io deleteTestIO) { io.writeErrs = map[string]error{trashedDir + "ch3.md": failed} },
+			wantResult: true,
+			wantErr:    "creating " + trashedDir + "ch3.md: failed",
+			wantFiles:  all,
+		},
+		{
+			name:      "manifest unreadable",
+			mode:      CascadeTrash,
+			setup:     func(io deleteTestIO) { io.files[trashManifest] = []byte("{") },
+			wantErr:   "reading .prosemark/trash/manifest.json",
+			wantFiles: append([]string{trashManifest}, all...),
+		},
+		{
+			name:       "manifest write",
+			mode:       CascadeTrash,
+			setup:      func(io deleteTestIO) { io.writeErrs = map[string]error{trashManifest: failed} },
+			wantResult: true,
+			wantErr:    "writing " + trashManifest + ": failed",
+			wantFiles:  all,
+		},
+		{
+			name:       "binder write",
+			mode:       CascadeTrash,
+			setup:      func(io deleteTestIO) { io.writeErr = failed },
+			wantResult: true,
+			wantErr:    "writing binder: failed",
+			wantFiles:  all,
+		},
+		{
+			name:       "file removal",
+			mode:       CascadeFiles,
+			setup:      func(io deleteTestIO) { io.deleteErrs = map[string]error{"/proj/ch3.md": failed} },
+			wantResult: true,
+			wantErr:    "removing /proj/ch3.md: failed",
+			wantFiles:  all,
+			wantBinder: deleteTestBinder,
+		},
+		{
+			name: "file removal and rollback",
+			mode: CascadeFiles,
+			setup: func(io deleteTestIO) {
+				io.deleteErrs = map[string]error{"/proj/ch3.md": failed}
+				io.writeErrs = map[string]error{"/proj/ch1.md": errors.New("restore")}
+			},
+			wantResult: true,
+			wantErr:    "removing /proj/ch3.md: failed; rollback also failed: restore",
+			wantFiles:  []string{"/proj/ch1.notes.md", "/proj/ch2.md", "/proj/ch3.md"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			io := newDeleteTestIO()
+			tt.setup(io)
+			res, err := deleteCh1(io, tt.mode, false)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+			if (res != nil) != tt.wantResult {
+				t.Errorf("result = %+v, want result %v", res, tt.wantResult)
+			}
+			if tt.wantFiles != nil {
+				if got := fileNames(io); !slices.Equal(got, tt.wantFiles) {
+					t.Errorf("files = %v, want %v", got, tt.wantFiles)
+				}
+			}
+			if tt.wantBinder != "" && string(io.binder) != tt.wantBinder {
+				t.Errorf("binder = %q, want %q", io.binder, tt.wantBinder)
+			}
+		})
+	}
+}
+
+func TestUnreferencedFiles_UnparsableBinder(t *testing.T) {
+	proj := &binder.Project{Files: []string{"ch1.md"}, BinderDir: "."}
+	src := []byte("<!-- prosemark-binder:v1 -->\n- [One](ch1.md)\n")
+	for _, tt := range []struct{ src, modified []byte }{
+		{[]byte("\xff"), src},
+		{src, []byte("\xff")},
+	} {
+		if got := unreferencedFiles(context.Background(), tt.src, tt.modified, proj); got != nil {
+			t.Errorf("unreferencedFiles(%q, %q) = %v, want none", tt.src, tt.modified, got)
+		}
+	}
+}
+
+// escapingDeleteIO is a fakeBinderIO whose project has a node file under a
+// symlink out of the project.
+type escapingDeleteIO struct {
+	*fakeBinderIO
+}
+
+func (escapingDeleteIO) ScanProject(_ context.Context, _ string) (*binder.Project, error) {
+	return &binder.Project{Files: []string{"out/outside.md"}, BinderDir: "."}, nil
+}
+
+func TestDeleteWithFiles_EscapingFile(t *testing.T) {
+	dir := escapingDir(t)
+	io := escapingDeleteIO{&fakeBinderIO{
+		binder: []byte("<!-- prosemark-binder:v1 -->\n- [Outside](out/outside.md)\n"),
+		files:  map[string][]byte{filepath.Join(dir, "out", "outside.md"): []byte("Outside.\n")},
+	}}
+	params := binder.DeleteParams{Selector: "Outside", Yes: true}
+	res, err := DeleteWithFiles(context.Background(), io, filepath.Join(dir, "_binder.md"), params, CascadeFiles, deleteTestNow)
+	if res != nil || !errors.Is(err, safepath.ErrTraversal) {
+		t.Errorf("DeleteWithFiles = %+v, %v; want a traversal error", res, err)
+	}
+	if io.written != nil || io.deleted != nil {
+		t.Errorf("wrote %q and deleted %v", io.written, io.deleted)
+	}
+}
@@ -0,0 +1,181 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// DoctorIO handles I/O for a doctor audit.
+type DoctorIO interface {
+	// ReadBinder reads the raw binder file at path.
+	ReadBinder(path string) ([]byte, error)
+	// ListNodeFiles returns the node filenames of scheme found in dir
+	// (non-recursive).
+	ListNodeFiles(dir string, scheme node.IDScheme) ([]string, error)
+	// ReadNodeFile reads the file at path. The bool reports whether the file exists.
+	ReadNodeFile(path string) ([]byte, bool, error)
+	// ScanProject lists the project's files and frontmatter aliases.
+	ScanProject(ctx context.Context, binderPath string) (*binder.Project, error)
+	// WriteCache replaces the cache file at path, creating its directory.
+	WriteCache(path string, data []byte) error
+}
+
+// DoctorCacheFilename is the doctor audit cache's path relative to the
+// project directory.
+const DoctorCacheFilename = ".prosemark/cache/doctor.json"
+
+// DoctorOptions adjust a Doctor audit.
+type DoctorOptions struct {
+	// Subset, when non-nil, limits the diagnostics returned to these
+	// project-relative slash paths, though the whole binder is still read so
+	// orphan and duplicate checks keep their project-wide context.
+	Subset map[string]bool
+	// NoCache re-validates every node file's frontmatter instead of reusing
+	// the results cached in DoctorCacheFilename for unchanged files.
+	NoCache bool
+}
+
+// Doctor audits the project whose binder is binderPath (pmk doctor).
+// Frontmatter results are cached by file content in DoctorCacheFilename;
+// failing to read or write the cache only costs speed.
+func Doctor(ctx context.Context, io DoctorIO, binderPath string, opts DoctorOptions) ([]node.AuditDiagnostic, error) {
+	subset := opts.Subset
+	projectDir := filepath.Dir(binderPath)
+
+	// Read binder — distinguish not-found from permission errors.
+	binderBytes, err := io.ReadBinder(binderPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("project not initialized — run 'pmk init' first")
+		}
+		return nil, fmt.Errorf("cannot read binder: %w", err)
+	}
+
+	schema, scheme, overrides, configDiags := checkProjectConfig(io, projectDir)
+
+	cachePath := filepath.Join(projectDir, filepath.FromSlash(DoctorCacheFilename))
+	var cache *node.AuditCache
+	if !opts.NoCache {
+		cached, _, _ := io.ReadNodeFile(cachePath)
+		cache = node.LoadAuditCache(cached, schema)
+	}
+
+	// List node files in project root.
+	uuidFiles, err := io.ListNodeFiles(projectDir, scheme)
+	if err != nil {
+		uuidFiles = []string{}
+	}
+
+	// The project scan backs the body link audit, which is skipped when the
+	// scan fails.
+	proj, err := io.ScanProject(ctx, binderPath)
+	if err != nil {
+		proj = nil
+	}
+
+	// Collect binder refs and binder-level diagnostics (escape warnings, duplicates).
+	// This is the sole binder.Parse call per doctor invocation.
+	refs, refDiags := node.CollectBinderRefs(ctx, filepath.Base(binderPath), binderBytes)
+
+	// Build FileContents map: one entry per unique referenced filename.
+	fileContents := make(map[string][]byte, len(refs))
+	escaping := make(map[string]node.AuditDiagnostic)
+	for _, ref := range refs {
+		if subset != nil && !subset[ref] {
+			continue
+		}
+		path, err := safepath.Resolve(projectDir, ref)
+		if err != nil {
+			d := safepath.Diagnostic(ref)
+			escaping[ref] = node.AuditDiagnostic{Code: node.AuditCode(d.Code), Severity: node.AuditSeverity(d.Severity), Message: d.Message, Path: ref}
+			continue
+		}
+		fileContents[ref] = doctorReadFile(io, path)
+	}
+
+	data := node.DoctorData{
+		BinderSrc:      binderBytes,
+		UUIDFiles:      uuidFiles,
+		FileContents:   fileContents,
+		Escaping:       escaping,
+		BinderRefs:     refs,
+		BinderRefDiags: refDiags,
+		Schema:         schema,
+		Project:        proj,
+		IDScheme:       scheme,
+		Cache:          cache,
+		Subset:         subset,
+	}
+
+	if subset != nil {
+		configDiags = node.FilterAuditDiagnostics(configDiags, subset)
+	}
+	diags := node.RunDoctor(ctx, data)
+	if cache != nil && cache.Changed() {
+		_ = io.WriteCache(cachePath, cache.Marshal())
+	}
+	return node.ApplyAuditSeverityOverrides(append(diags, configDiags...), overrides), nil
+}
+
+// checkProjectConfig validates .prosemark.yml existence and YAML integrity,
+// and returns the frontmatter schema it declares (nil when none), the node ID
+// scheme it selects (DefaultIDScheme when the config is unusable), and its
+// diagnostic severity overrides.
+// Returns an AUD008 error diagnostic if the file is missing, unreadable, contains
+// invalid YAML, declares an invalid types schema, or has invalid binder
+// settings (wikilinks.resolution, reference_sections, limits, id_scheme,
+// diagnostics).
+func checkProjectConfig(io DoctorIO, projectDir string) (node.FrontmatterSchema, node.IDScheme, map[string]string, []node.AuditDiagnostic) {
+	configPath := node.ConfigPath(projectDir)
+	content, exists, err := io.ReadNodeFile(configPath)
+
+	var msg string
+	var schema node.FrontmatterSchema
+	if err != nil || !exists {
+		msg = ".prosemark.yml is missing or unreadable"
+	} else {
+		var cfg interface{}
+		if err := yaml.Unmarshal(content, &cfg); err != nil {
+			msg = ".prosemark.yml contains invalid YAML"
+		} else if schema, err = node.ParseFrontmatterSchema(content); err != nil {
+			msg = fmt.Sprintf(".prosemark.yml has an invalid types schema: %v", err)
+		} else if settings, err := node.ParseProjectConfig(content); err != nil {
+			msg = fmt.Sprintf(".prosemark.yml has an invalid setting: %v", err)
+		} else {
+			// ParseProjectConfig has already rejected unknown schemes.
+			scheme, _ := node.LookupIDScheme(settings.IDScheme)
+			return schema, scheme, settings.SeverityOverrides, nil
+		}
+	}
+
+	return nil, node.DefaultIDScheme, nil, []node.AuditDiagnostic{{
+		Code:     node.AUD008,
+		Severity: node.SeverityError,
+		Message:  msg,
+		Path:     ".prosemark.yml",
+	}}
+}
+
+// doctorReadFile reads a binder-referenced file, already resolved to path,
+// for doctor analysis. Returns nil if the file does not exist or cannot be read.
+// Returns []byte{} (empty, non-nil) for files exceeding 1 MB, causing RunDoctor
+// to emit AUD007 (frontmatter parse failure) rather than AUD001 (file not found).
+func doctorReadFile(io DoctorIO, path string) []byte {
+	content, exists, err := io.ReadNodeFile(path)
+	if err != nil || !exists {
+		return nil
+	}
+	if len(content) > 1024*1024 {
+		return []byte{}
+	}
+	return content
+}
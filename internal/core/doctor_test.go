@@ -0,0 +1,318 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// fakeDoctorIO is an in-memory DoctorIO. files maps filepath.Base(path) to
+// content; missing keys do not exist.
+type fakeDoctorIO struct {
+	binder    []byte
+	binderErr error
+	uuidFiles []string
+	uuidErr   error
+	files     map[string][]byte
+	fileErr   error
+	// listScheme records the scheme ListNodeFiles was called with.
+	listScheme node.IDScheme
+	// project is returned by ScanProject; nil makes the scan fail.
+	project *binder.Project
+	// cache records WriteCache calls by filepath.Base(path).
+	cache map[string][]byte
+}
+
+func (f *fakeDoctorIO) ReadBinder(_ string) ([]byte, error) {
+	return f.binder, f.binderErr
+}
+
+func (f *fakeDoctorIO) ListNodeFiles(_ string, scheme node.IDScheme) ([]string, error) {
+	f.listScheme = scheme
+	return f.uuidFiles, f.uuidErr
+}
+
+func (f *fakeDoctorIO) ReadNodeFile(path string) ([]byte, bool, error) {
+	content, ok := f.files[filepath.Base(path)]
+	return content, ok, f.fileErr
+}
+
+func (f *fakeDoctorIO) ScanProject(_ context.Context, _ string) (*binder.Project, error) {
+	if f.project == nil {
+		return nil, errors.New("no project")
+	}
+	return f.project, nil
+}
+
+func (f *fakeDoctorIO) WriteCache(path string, data []byte) error {
+	if f.cache == nil {
+		f.cache = map[string][]byte{}
+	}
+	f.cache[filepath.Base(path)] = data
+	return nil
+}
+
+const validConfig = "version: \"1\"\n"
+
+func auditCodes(diags []node.AuditDiagnostic) string {
+	var codes []string
+	for _, d := range diags {
+		codes = append(codes, string(d.Code)+":"+d.Path)
+	}
+	return strings.Join(codes, " ")
+}
+
+func TestDoctor(t *testing.T) {
+	io := &fakeDoctorIO{
+		binder:  []byte("<!-- prosemark-binder:v1 -->\n- [A](a.md)\n- [B](b.md)\n"),
+		uuidErr: errors.New("denied"),
+		files:   map[string][]byte{".prosemark.yml": []byte(validConfig)},
+	}
+	diags, err := Doctor(context.Background(), io, binderPath, DoctorOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := auditCodes(diags); got != "AUD001:a.md AUD001:b.md AUDW001:a.md AUDW001:b.md" {
+		t.Errorf("diagnostics = %s", got)
+	}
+
+	diags, err = Doctor(context.Background(), io, binderPath, DoctorOptions{Subset: map[string]bool{"b.md": true}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := auditCodes(diags); got != "AUD001:b.md AUDW001:b.md" {
+		t.Errorf("subset diagnostics = %s", got)
+	}
+}
+
+func TestDoctor_BodyLinks(t *testing.T) {
+	io := &fakeDoctorIO{
+		binder: []byte("<!-- prosemark-binder:v1 -->\n- [A](a.md)\n"),
+		files: map[string][]byte{
+			".prosemark.yml": []byte(validConfig),
+			"a.md":           []byte("See [B](b.md), [gone](gone.md), and [[Missing]].\n"),
+		},
+		project: &binder.Project{Files: []string{"a.md", "b.md"}, BinderDir: "."},
+	}
+	diags, err := Doctor(context.Background(), io, binderPath, DoctorOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := auditCodes(diags); got != "AUDW001:a.md AUD011:a.md AUD011:a.md" {
+		t.Errorf("diagnostics = %s", got)
+	}
+
+	io.project = nil
+	diags, err = Doctor(context.Background(), io, binderPath, DoctorOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := auditCodes(diags); got != "AUDW001:a.md" {
+		t.Errorf("without a project scan, diagnostics = %s", got)
+	}
+}
+
+func TestDoctor_SymlinkEscape(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Symlink(filepath.Join(t.TempDir(), "outside.md"), filepath.Join(dir, "a.md")); err != nil {
+		t.Fatal(err)
+	}
+	io := &fakeDoctorIO{
+		binder: []byte("<!-- prosemark-binder:v1 -->\n- [A](a.md)\n"),
+		files: map[string][]byte{
+			".prosemark.yml": []byte(validConfig),
+			"a.md":           []byte("See [gone](gone.md).\n"),
+		},
+		project: &binder.Project{Files: []string{"a.md"}, BinderDir: "."},
+	}
+	diags, err := Doctor(context.Background(), io, filepath.Join(dir, "_binder.md"), DoctorOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := auditCodes(diags); got != "BNDE002:a.md" {
+		t.Errorf("diagnostics = %s, want only BNDE002 for the symlinked file", got)
+	}
+}
+
+func TestDoctor_Cache(t *testing.T) {
+	const ref = "0192f0c1-3e7a-7000-8000-5a4b3c2d1e0f.md"
+	io := &fakeDoctorIO{
+		binder: []byte("<!-- prosemark-binder:v1 -->\n- [A](" + ref + ")\n"),
+		files: map[string][]byte{
+			".prosemark.yml": []byte(validConfig),
+			ref:              []byte("---\nid: [unclosed\n---\n"),
+		},
+	}
+	diags, err := Doctor(context.Background(), io, binderPath, DoctorOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cached, ok := io.cache["doctor.json"]
+	if got := auditCodes(diags); got != "AUD007:"+ref || !ok {
+		t.Fatalf("diagnostics = %s, cache written = %v; want AUD007 and a cache", got, ok)
+	}
+
+	// The next run reuses the cached result, here doctored to tell it apart.
+	io.files["doctor.json"] = bytes.Replace(cached, []byte(`"AUD007"`), []byte(`"AUD004"`), 1)
+	io.cache = nil
+	diags, _ = Doctor(context.Background(), io, binderPath, DoctorOptions{})
+	if got := auditCodes(diags); got != "AUD004:"+ref {
+		t.Errorf("cached diagnostics = %s, want the cached AUD004", got)
+	}
+	if io.cache != nil {
+		t.Error("unchanged cache rewritten")
+	}
+
+	diags, _ = Doctor(context.Background(), io, binderPath, DoctorOptions{NoCache: true})
+	if got := auditCodes(diags); got != "AUD007:"+ref || io.cache != nil {
+		t.Errorf("--no-cache diagnostics = %s, cache written = %v; want a fresh AUD007 and no write", got, io.cache != nil)
+	}
+}
+
+func TestDoctor_ProjectIDScheme(t *testing.T) {
+	io := &fakeDoctorIO{
+		binder:    []byte("<!-- prosemark-binder:v1 -->\n- [A](0192f0c1-3e7a-7000-8000-5a4b3c2d1e0f.md)\n"),
+		uuidFiles: []string{"01J5Z3V0A4Q8W2E6R9T1Y3X5H7.md"},
+		files:     map[string][]byte{".prosemark.yml": []byte("id_scheme: ulid\n")},
+	}
+	diags, err := Doctor(context.Background(), io, binderPath, DoctorOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if io.listScheme != node.ULIDIDs {
+		t.Errorf("ListNodeFiles scheme = %v, want ulid", io.listScheme)
+	}
+	var msgs []string
+	for _, d := range diags {
+		msgs = append(msgs, d.Message)
+	}
+	got := strings.Join(msgs, "\n")
+	for _, want := range []string{"non-ULID filename linked in binder", "orphaned ULID file not referenced in binder: 01J5Z3V0A4Q8W2E6R9T1Y3X5H7.md"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("diagnostics %q missing %q", got, want)
+		}
+	}
+}
+
+func TestDoctor_SeverityOverrides(t *testing.T) {
+	io := &fakeDoctorIO{
+		binder: []byte("<!-- prosemark-binder:v1 -->\n- [A](a.md)\n- [B](b.md)\n"),
+		files: map[string][]byte{
+			".prosemark.yml": []byte("diagnostics:\n  AUDW001: error\n  AUD001: off\n"),
+			"a.md":           []byte("Prose.\n"),
+		},
+	}
+	diags, err := Doctor(context.Background(), io, binderPath, DoctorOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Errors cannot be silenced; warnings can be promoted.
+	var got []string
+	for _, d := range diags {
+		got = append(got, string(d.Code)+":"+string(d.Severity))
+	}
+	if want := "AUD001:error AUDW001:error AUDW001:error"; strings.Join(got, " ") != want {
+		t.Errorf("diagnostics = %v, want %s", got, want)
+	}
+
+	io.files[".prosemark.yml"] = []byte("diagnostics:\n  AUDW001: off\n")
+	diags, _ = Doctor(context.Background(), io, binderPath, DoctorOptions{})
+	if got := auditCodes(diags); got != "AUD001:b.md" {
+		t.Errorf("with AUDW001 off, diagnostics = %s", got)
+	}
+}
+
+func TestDoctor_BinderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr string
+	}{
+		{"missing", os.ErrNotExist, "project not initialized — run 'pmk init' first"},
+		{"unreadable", errors.New("denied"), "cannot read binder: denied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Doctor(context.Background(), &fakeDoctorIO{binderErr: tt.err}, binderPath, DoctorOptions{})
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckProjectConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *string
+		fileErr error
+		wantMsg string
+	}{
+		{name: "valid", config: ptr(validConfig)},
+		{name: "missing", wantMsg: "missing or unreadable"},
+		{name: "unreadable", config: ptr(validConfig), fileErr: errors.New("denied"), wantMsg: "missing or unreadable"},
+		{name: "invalid YAML", config: ptr("a: [\n"), wantMsg: "invalid YAML"},
+		{name: "invalid types", config: ptr("types: [scene]\n"), wantMsg: "invalid types schema"},
+		{name: "invalid setting", config: ptr("wikilinks:\n  resolution: nearest\n"), wantMsg: "invalid setting"},
+		{name: "unknown id scheme", config: ptr("id_scheme: serial\n"), wantMsg: "unknown id_scheme"},
+		{name: "unknown severity", config: ptr("diagnostics:\n  BNDW004: fatal\n"), wantMsg: "unknown severity"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			io := &fakeDoctorIO{fileErr: tt.fileErr}
+			if tt.config != nil {
+				io.files = map[string][]byte{".prosemark.yml": []byte(*tt.config)}
+			}
+			_, scheme, _, diags := checkProjectConfig(io, "/proj")
+			if scheme != node.DefaultIDScheme {
+				t.Errorf("scheme = %v, want the default", scheme)
+			}
+			if tt.wantMsg == "" {
+				if diags != nil {
+					t.Errorf("diagnostics = %+v", diags)
+				}
+				return
+			}
+			if len(diags) != 1 || diags[0].Code != node.AUD008 || !strings.Contains(diags[0].Message, tt.wantMsg) {
+				t.Errorf("diagnostics = %+v, want AUD008 %q", diags, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func ptr(s string) *string { return &s }
+
+func TestDoctorReadFile_NotExists(t *testing.T) {
+	got := doctorReadFile(&fakeDoctorIO{}, "missing.md")
+	if got != nil {
+		t.Errorf("expected nil for non-existing file, got %q", got)
+	}
+}
+
+func TestDoctorReadFile_Oversized(t *testing.T) {
+	oversized := bytes.Repeat([]byte("x"), 1024*1024+1)
+	io := &fakeDoctorIO{files: map[string][]byte{"big.md": oversized}}
+	got := doctorReadFile(io, "big.md")
+	if got == nil {
+		t.Fatal("expected non-nil sentinel for oversized file")
+	}
+	if len(got) != 0 {
+		t.Errorf("expected empty slice sentinel for oversized file, got len=%d", len(got))
+	}
+}
+
+func TestDoctorReadFile_Normal(t *testing.T) {
+	content := []byte("---\nid: test\n---\nbody\n")
+	io := &fakeDoctorIO{files: map[string][]byte{"node.md": content}}
+	got := doctorReadFile(io, "node.md")
+	if !bytes.Equal(got, content) {
+		t.Errorf("got %q, want %q", got, content)
+	}
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// Materialize links the placeholder selected by params.Selector to a new
+// node file named params.Target, which must already be set, titled from the
+// placeholder (pmk materialize). The node's ID and timestamps are set from
+// the target and now.
+//
+// The binder edit is checked before anything is written, and a dry run
+// stops there. The node file and the binder are then written in one FileTx.
+// As with AddNewNode, the result is nil when nothing was attempted.
+func Materialize(ctx context.Context, io NewNodeIO, binderPath string, params binder.MaterializeParams, now string) (*NewNodeResult, error) {
+	src, proj, err := readProject(ctx, io, binderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	nodePath, err := safepath.Resolve(filepath.Dir(binderPath), params.Target)
+	if err != nil {
+		return nil, err
+	}
+	modified, entries, diags := ops.Materialize(ctx, src, proj, params)
+	diags = binder.ApplySeverityOverrides(diags, proj.SeverityOverrides)
+	res := &NewNodeResult{OpResult: *newOpResult(src, modified, entries, diags), NodePath: nodePath, PrevBinder: src}
+	if hasError(diags) {
+		return res, nil
+	}
+	res.Diff = BinderDiff(binderPath, src, modified)
+	if params.DryRun {
+		return res, nil
+	}
+
+	fm := node.Frontmatter{ID: strings.TrimSuffix(params.Target, ".md"), Created: now, Updated: now}
+	if len(entries) > 0 {
+		fm.Title = entries[0].Title
+	}
+	tx, err := beginTx(io, binderPath)
+	if err != nil {
+		return nil, err
+	}
+	tx.Create(nodePath, node.SerializeFrontmatter(fm))
+	tx.WriteBinder(binderPath, modified)
+	return res, tx.Commit()
+}
@@ -0,0 +1,206 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/diff"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// CodeDuplicateNodeID is the error diagnostic for moving a node file into a
+// project that already has a node with its frontmatter id.
+const CodeDuplicateNodeID = "PMKE009"
+
+// MoveToProjectResult is the outcome of MoveToProject. Its diagnostics
+// cover both binders; those from the destination binder say so.
+type MoveToProjectResult struct {
+	binder.OpResult
+	// Files are the project-relative slash paths of the files moved, the same
+	// in both projects.
+	Files []string
+}
+
+// MoveToProject moves the entry selected by params.SourceSelector, with its
+// subtree, its node files, and their notes files, from the project whose
+// binder is at binderPath to the one whose binder is at destBinderPath
+// (pmk move --to-project). It is added under
+// params.DestinationParentSelector ("." or empty for the top level) at the
+// position params gives, as its lines read, so placeholders, checkboxes,
+// and link styles go along, and the files keep their project-relative
+// paths. The entry's own annotations go along only with
+// params.PreserveExtras, as in a move within a binder. The destination
+// binder is checked with the incoming files in it. One IO serves both
+// projects.
+//
+// The move is refused when a file of the subtree is still referenced
+// elsewhere in the source binder, when a file already exists in the
+// destination project, or, as a PMKE009 diagnostic, when the destination
+// project already has a node with a moved file's frontmatter id.
+//
+// The new files are written first, then the destination binder, then the
+// source binder, and the old files are removed last, in one FileTx begun in
+// the source project. A dry run checks everything but writes nothing. The
+// result's diff covers both binders.
+func MoveToProject(ctx context.Context, io DeleteFilesIO, binderPath, destBinderPath string, params binder.MoveParams) (*MoveToProjectResult, error) {
+	dir, destDir := filepath.Dir(binderPath), filepath.Dir(destBinderPath)
+	if filepath.Clean(dir) == filepath.Clean(destDir) {
+		return nil, fmt.Errorf("the destination is the source project; move within it without --to-project")
+	}
+	src, proj, err := readProject(ctx, io, binderPath)
+	if err != nil {
+		return nil, err
+	}
+	dest, destProj, err := readProject(ctx, io, destBinderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	modified, _, removed, diags := ops.DeleteSubtrees(ctx, src, proj, binder.DeleteParams{Selector: params.SourceSelector, Yes: true, ForceParse: params.ForceParse})
+	// The moved lines go to the destination; what they lose there is
+	// reported with the destination's diagnostics.
+	diags = withoutCode(withoutCode(diags, binder.CodeCascadeDelete), binder.CodeNonStructuralDestroyed)
+	diags = binder.ApplySeverityOverrides(diags, proj.SeverityOverrides)
+	if hasError(diags) {
+		return &MoveToProjectResult{OpResult: *newOpResult(src, src, nil, diags)}, nil
+	}
+	if len(removed) != 1 {
+		return nil, fmt.Errorf("selector %q matched %d entries; move them one at a time", params.SourceSelector, len(removed))
+	}
+	tree := subtreeNode(removed[0].Node)
+
+	files := unreferencedFiles(ctx, src, modified, proj)
+	if shared := sharedTarget(tree, files, proj); shared != "" {
+		return nil, fmt.Errorf("%s is still referenced elsewhere in the source binder", shared)
+	}
+
+	parent := params.DestinationParentSelector
+	if parent == "" {
+		parent = "."
+	}
+	// The destination is checked with the incoming files in it.
+	incoming := *destProj
+	incoming.Files = append(slices.Clone(destProj.Files), files...)
+	destModified, destDiags := ops.InsertSubtree(ctx, dest, &incoming, ops.InsertSubtreeParams{
+		ParentSelector: parent,
+		Position:       params.Position,
+		At:             params.At,
+		Before:         params.Before,
+		After:          params.After,
+		PreserveExtras: params.PreserveExtras,
+		ForceParse:     params.ForceParse,
+		Subtree:        removed[0].Subtree,
+	})
+	for _, d := range destDiags {
+		d.Message = "destination: " + d.Message
+		diags = append(diags, d)
+	}
+
+	moves := make([]fileMove, 0, len(files))
+	for _, rel := range files {
+		m := fileMove{from: filepath.Join(dir, filepath.FromSlash(rel)), to: filepath.Join(destDir, filepath.FromSlash(rel))}
+		if m.content, err = io.ReadNodeFile(m.from); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", rel, err)
+		}
+		if _, err := io.ReadNodeFile(m.to); err == nil {
+			return nil, fmt.Errorf("%s already exists", m.to)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("checking %s: %w", m.to, err)
+		}
+		moves = append(moves, m)
+	}
+	diags = append(diags, duplicateNodeIDs(io, destDir, destProj, moves)...)
+
+	if hasError(diags) {
+		return &MoveToProjectResult{OpResult: *newOpResult(src, src, nil, diags)}, nil
+	}
+	res := &MoveToProjectResult{OpResult: *newOpResult(src, modified, nil, diags), Files: files}
+	res.Diff = projectBinderDiff(binderPath, src, modified) + projectBinderDiff(destBinderPath, dest, destModified)
+	if params.DryRun {
+		return res, nil
+	}
+
+	tx, err := beginTx(io, binderPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range moves {
+		tx.Create(m.to, m.content)
+	}
+	tx.WriteBinder(destBinderPath, destModified)
+	tx.WriteBinder(binderPath, modified)
+	for _, m := range moves {
+		tx.Remove(m.from)
+	}
+	return res, tx.Commit()
+}
+
+// withoutCode returns diags without those with code.
+func withoutCode(diags []binder.Diagnostic, code string) []binder.Diagnostic {
+	kept := make([]binder.Diagnostic, 0, len(diags))
+	for _, d := range diags {
+		if d.Code != code {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+// sharedTarget returns a project file the subtree n references that is not
+// among the files leaving with it, or "" if there is none.
+func sharedTarget(n SubtreeNode, files []string, proj *binder.Project) string {
+	target := path.Clean(n.Target)
+	if !slices.Contains(files, target) && slices.Contains(proj.Files, target) {
+		return target
+	}
+	for _, c := range n.Children {
+		if shared := sharedTarget(c, files, proj); shared != "" {
+			return shared
+		}
+	}
+	return ""
+}
+
+// duplicateNodeIDs returns a PMKE009 diagnostic for each moved file whose
+// frontmatter id a file of the destination project already has. Unreadable
+// destination files are skipped.
+func duplicateNodeIDs(io DeleteFilesIO, destDir string, destProj *binder.Project, moves []fileMove) []binder.Diagnostic {
+	ids := make(map[string]string, len(moves))
+	for _, m := range moves {
+		if fm, _, err := node.ParseFrontmatter(m.content); err == nil && fm.ID != "" {
+			ids[fm.ID] = filepath.Base(m.to)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	var diags []binder.Diagnostic
+	for _, f := range destProj.Files {
+		content, err := io.ReadNodeFile(filepath.Join(destDir, filepath.FromSlash(f)))
+		if err != nil {
+			continue
+		}
+		if fm, _, err := node.ParseFrontmatter(content); err == nil && ids[fm.ID] != "" {
+			diags = append(diags, binder.Diagnostic{
+				Severity: "error",
+				Code:     CodeDuplicateNodeID,
+				Message:  fmt.Sprintf("destination: %s already has node id %s of %s", f, fm.ID, ids[fm.ID]),
+			})
+		}
+	}
+	return diags
+}
+
+// projectBinderDiff is BinderDiff labelled with the binder's directory
+// name too, so the diffs of two projects' binders can be told apart.
+func projectBinderDiff(binderPath string, src, modified []byte) string {
+	name := path.Join(filepath.Base(filepath.Dir(binderPath)), filepath.Base(binderPath))
+	return diff.Unified("a/"+name, "b/"+name, src, modified)
+}
@@ -0,0 +1,345 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core/coretest"
+)
+
+const destBinderPath = "/dest/_binder.md"
+
+// moveTestIO is a renameTestIO holding the binders of two projects, /proj
+// and /dest, whose scans list their files.
+type moveTestIO struct {
+	*renameTestIO
+	binders        map[string][]byte
+	binderReadErrs map[string]error
+	binderErrs     map[string]error
+	binderSaves    int
+}
+
+func newMoveTestIO() *moveTestIO {
+	io := newRenameTestIO()
+	io.files["/proj/ch2.md"] = []byte("---\nid: ch2\n---\nTwo.\n")
+	io.files["/dest/intro.md"] = []byte("---\nid: intro\n---\nIntro.\n")
+	return &moveTestIO{renameTestIO: io, binders: map[string][]byte{
+		binderPath:     []byte("<!-- prosemark-binder:v1 -->\n- [One](ch1.md)\n  - [Two](ch2.md)\n- [Intro](intro.md)\n"),
+		destBinderPath: []byte("<!-- prosemark-binder:v1 -->\n- [Intro](intro.md)\n"),
+	}}
+}
+
+func (m *moveTestIO) ReadBinder(_ context.Context, path string) ([]byte, error) {
+	if err := m.binderReadErrs[path]; err != nil {
+		return nil, err
+	}
+	return m.binders[path], nil
+}
+
+func (m *moveTestIO) WriteBinderAtomic(_ context.Context, path string, data []byte) error {
+	if err := m.binderErrs[path]; err != nil {
+		return err
+	}
+	m.binderSaves++
+	m.binders[path] = data
+	return nil
+}
+
+func (m *moveTestIO) BeginTx(string) (FileTx, error) {
+	if m.txErr != nil {
+		return nil, m.txErr
+	}
+	return coretest.NewTx(context.Background(), m), nil
+}
+
+func (m *moveTestIO) ScanProject(_ context.Context, binderPath string) (*binder.Project, error) {
+	dir := filepath.Dir(binderPath) + "/"
+	var files []string
+	for p := range m.files {
+		if rel, ok := strings.CutPrefix(p, dir); ok {
+			files = append(files, rel)
+		}
+	}
+	slices.Sort(files)
+	return &binder.Project{Files: files, BinderDir: dir}, nil
+}
+
+func moveCh1(io *moveTestIO, dryRun bool) (*MoveToProjectResult, error) {
+	params := binder.MoveParams{SourceSelector: "ch1.md", Position: "first", Yes: true, DryRun: dryRun}
+	return MoveToProject(context.Background(), io, binderPath, destBinderPath, params)
+}
+
+func TestMoveToProject(t *testing.T) {
+	io := newMoveTestIO()
+	res, err := moveCh1(io, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"ch1.md", "ch1.notes.md", "ch2.md"}; !res.Changed || !slices.Equal(res.Files, want) {
+		t.Errorf("result = %+v, want files %v", res, want)
+	}
+	if want := "<!-- prosemark-binder:v1 -->\n- [Intro](intro.md)\n"; string(io.binders[binderPath]) != want {
+		t.Errorf("source binder = %q, want %q", io.binders[binderPath], want)
+	}
+	if want := "<!-- prosemark-binder:v1 -->\n- [One](ch1.md)\n  - [Two](ch2.md)\n- [Intro](intro.md)\n"; string(io.binders[destBinderPath]) != want {
+		t.Errorf("destination binder = %q, want %q", io.binders[destBinderPath], want)
+	}
+	want := []string{"/dest/ch1.md", "/dest/ch1.notes.md", "/dest/ch2.md", "/dest/intro.md"}
+	if got := moveFileNames(io); !slices.Equal(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+	if string(io.files["/dest/ch1.notes.md"]) != "Notes.\n" {
+		t.Errorf("notes = %q", io.files["/dest/ch1.notes.md"])
+	}
+	for _, label := range []string{"a/proj/_binder.md", "a/dest/_binder.md"} {
+		if !strings.Contains(res.Diff, label) {
+			t.Errorf("diff lacks %s:\n%s", label, res.Diff)
+		}
+	}
+}
+
+// TestMoveToProject_SourceLines verifies that the moved subtree keeps its
+// placeholders, checkboxes, and annotations, and, with PreserveExtras, other
+// text around its links, and that the destination binder is checked with the
+// moved files in it.
+func TestMoveToProject_SourceLines(t *testing.T) {
+	tests := []struct {
+		name      string
+		preserve  bool
+		wantLine  string
+		wantCodes []string
+	}{
+		{name: "extras dropped", wantLine: "- [x] [One](ch1.md) <!-- 900 words -->\n", wantCodes: []string{binder.CodeNonStructuralDestroyed}},
+		{name: "extras preserved", preserve: true, wantLine: "- [x] [One](ch1.md) ← revise <!-- 900 words -->\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			io := newMoveTestIO()
+			io.binders[binderPath] = []byte("<!-- prosemark-binder:v1 -->\n- [x] [One](ch1.md) ← revise <!-- 900 words -->\n  - [Later]()\n    - [ ] [Two](ch2.md)\n")
+			params := binder.MoveParams{SourceSelector: "ch1.md", Yes: true, PreserveExtras: tt.preserve}
+			res, err := MoveToProject(context.Background(), io, binderPath, destBinderPath, params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var codes []string
+			for _, d := range res.Diagnostics {
+				codes = append(codes, d.Code)
+			}
+			if !slices.Equal(codes, tt.wantCodes) {
+				t.Errorf("diagnostics = %+v, want codes %v", res.Diagnostics, tt.wantCodes)
+			}
+			want := "<!-- prosemark-binder:v1 -->\n- [Intro](intro.md)\n" + tt.wantLine + "  - [Later]()\n    - [ ] [Two](ch2.md)\n"
+			if string(io.binders[destBinderPath]) != want {
+				t.Errorf("destination binder = %q, want %q", io.binders[destBinderPath], want)
+			}
+		})
+	}
+}
+
+func moveFileNames(io *moveTestIO) []string {
+	var got []string
+	for p := range io.files {
+		got = append(got, p)
+	}
+	slices.Sort(got)
+	return got
+}
+
+func TestMoveToProject_DryRun(t *testing.T) {
+	io := newMoveTestIO()
+	before := moveFileNames(io)
+	res, err := moveCh1(io, true)
+	if err != nil || res.Diff == "" {
+		t.Fatalf("result = %+v, err = %v; want the diff", res, err)
+	}
+	if io.binderSaves != 0 || !slices.Equal(moveFileNames(io), before) {
+		t.Errorf("dry run wrote: %d binders, files %v", io.binderSaves, moveFileNames(io))
+	}
+}
+
+func TestMoveToProject_Diagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector string
+		dest     string
+		setup    func(io *moveTestIO)
+		wantCode string
+		wantMsg  string
+	}{
+		{name: "no source match", selector: "missing.md", setup: func(*moveTestIO) {}, wantCode: binder.CodeSelectorNoMatch},
+		{name: "no destination match", selector: "ch1.md", dest: "missing.md", setup: func(*moveTestIO) {}, wantCode: binder.CodeSelectorNoMatch, wantMsg: "destination: "},
+		{
+			name:     "duplicate node id",
+			selector: "ch1.md",
+			setup:    func(io *moveTestIO) { io.files["/dest/intro.md"] = []byte("---\nid: ch2\n---\nIntro.\n") },
+			wantCode: CodeDuplicateNodeID,
+			wantMsg:  "destination: intro.md already has node id ch2 of ch2.md",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			io := newMoveTestIO()
+			tt.setup(io)
+			before := moveFileNames(io)
+			params := binder.MoveParams{SourceSelector: tt.selector, DestinationParentSelector: tt.dest, Yes: true}
+			res, err := MoveToProject(context.Background(), io, binderPath, destBinderPath, params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Changed || len(res.Diagnostics) == 0 {
+				t.Fatalf("result = %+v, want an unchanged result with diagnostics", res)
+			}
+			d := res.Diagnostics[len(res.Diagnostics)-1]
+			if d.Code != tt.wantCode || !strings.Contains(d.Message, tt.wantMsg) {
+				t.Errorf("diagnostic = %+v, want %s %q", d, tt.wantCode, tt.wantMsg)
+			}
+			if io.binderSaves != 0 || !slices.Equal(moveFileNames(io), before) {
+				t.Errorf("wrote despite errors: %d binders, files %v", io.binderSaves, moveFileNames(io))
+			}
+		})
+	}
+}
+
+func TestMoveToProject_Errors(t *testing.T) {
+	failed := errors.New("failed")
+	all := []string{"/dest/intro.md", "/proj/ch1.md", "/proj/ch1.notes.md", "/proj/ch2.md"}
+	tests := []struct {
+		name       string
+		dest       string
+		setup      func(io *moveTestIO)
+		wantResult bool
+		wantErr    string
+		wantFiles  []string
+	}{
+		{name: "same project", dest: "/proj/_binder.md", setup: func(*moveTestIO) {}, wantErr: "the destination is the source project", wantFiles: all},
+		{
+			name: "shared file",
+			setup: func(io *moveTestIO) {
+				io.binders[binderPath] = append(io.binders[binderPath], "- [Two again](ch2.md)\n"...)
+			},
+			wantErr:   "ch2.md is still referenced elsewhere in the source binder",
+			wantFiles: all,
+		},
+		{
+			name:      "destination file exists",
+			setup:     func(io *moveTestIO) { io.files["/dest/ch2.md"] = []byte("Other.\n") },
+			wantErr:   "/dest/ch2.md already exists",
+			wantFiles: append([]string{"/dest/ch2.md"}, all...),
+		},
+		{
+			name:      "source binder unreadable",
+			setup:     func(io *moveTestIO) { io.binderReadErrs = map[string]error{binderPath: failed} },
+			wantErr:   "failed",
+			wantFiles: all,
+		},
+		{
+			name:      "destination binder unreadable",
+			setup:     func(io *moveTestIO) { io.binderReadErrs = map[string]error{destBinderPath: failed} },
+			wantErr:   "failed",
+			wantFiles: all,
+		},
+		{
+			name: "several entries",
+			setup: func(io *moveTestIO) {
+				io.binders[binderPath] = append(io.binders[binderPath], "- [One again](ch1.md)\n"...)
+			},
+			wantErr:   `selector "ch1.md" matched 2 entries; move them one at a time`,
+			wantFiles: all,
+		},
+		{
+			name:      "destination file uncheckable",
+			setup:     func(io *moveTestIO) { io.readErrs = map[string]error{"/dest/ch2.md": failed} },
+			wantErr:   "checking /dest/ch2.md: failed",
+			wantFiles: all,
+		},
+		{
+			name:      "file unreadable",
+			setup:     func(io *moveTestIO) { io.readErrs = map[string]error{"/proj/ch2.md": failed} },
+			wantErr:   "reading ch2.md: failed",
+			wantFiles: all,
+		},
+		{
+			name:       "file write",
+			setup:      func(io *moveTestIO) { io.writeErrs = map[string]error{"/dest/ch2.md": failed} },
+			wantResult: true,
+			wantErr:    "creating /dest/ch2.md: failed",
+			wantFiles:  all,
+		},
+		{
+			name:       "destination binder write",
+			setup:      func(io *moveTestIO) { io.binderErrs = map[string]error{destBinderPath: failed} },
+			wantResult: true,
+			wantErr:    "writing binder: failed",
+			wantFiles:  all,
+		},
+		{
+			name:       "source binder write",
+			setup:      func(io *moveTestIO) { io.binderErrs = map[string]error{binderPath: failed} },
+			wantResult: true,
+			wantErr:    "writing binder: failed",
+			wantFiles:  all,
+		},
+		{
+			name:       "file removal",
+			setup:      func(io *moveTestIO) { io.deleteErrs = map[string]error{"/proj/ch2.md": failed} },
+			wantResult: true,
+			wantErr:    "removing /proj/ch2.md: failed",
+			wantFiles:  all,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			io := newMoveTestIO()
+			tt.setup(io)
+			src, dest := string(io.binders[binderPath]), string(io.binders[destBinderPath])
+			destPath := destBinderPath
+			if tt.dest != "" {
+				destPath = tt.dest
+			}
+			params := binder.MoveParams{SourceSelector: "ch1.md", Yes: true}
+			res, err := MoveToProject(context.Background(), io, binderPath, destPath, params)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+			if (res != nil) != tt.wantResult {
+				t.Errorf("result = %+v, want result %v", res, tt.wantResult)
+			}
+			if got := moveFileNames(io); !slices.Equal(got, tt.wantFiles) {
+				t.Errorf("files = %v, want %v", got, tt.wantFiles)
+			}
+			if string(io.binders[binderPath]) != src || string(io.binders[destBinderPath]) != dest {
+				t.Errorf("binders = %q, %q; want both unchanged", io.binders[binderPath], io.binders[destBinderPath])
+			}
+		})
+	}
+}
+
+func TestDuplicateNodeIDs_Skips(t *testing.T) {
+	destProj := &binder.Project{Files: []string{"intro.md"}}
+	tests := []struct {
+		name  string
+		moves []fileMove
+		setup func(io *moveTestIO)
+	}{
+		{name: "no ids", moves: []fileMove{{to: "/dest/ch1.md", content: []byte("Body.\n")}}, setup: func(*moveTestIO) {}},
+		{
+			name:  "destination file unreadable",
+			moves: []fileMove{{to: "/dest/ch1.md", content: []byte("---\nid: intro\n---\n")}},
+			setup: func(io *moveTestIO) { io.readErrs = map[string]error{"/dest/intro.md": errors.New("failed")} },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			io := newMoveTestIO()
+			tt.setup(io)
+			if diags := duplicateNodeIDs(io, "/dest", destProj, tt.moves); diags != nil {
+				t.Errorf("duplicateNodeIDs = %+v, want none", diags)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// NewNodeIO handles I/O for creating a node file along with its binder entry.
+type NewNodeIO interface {
+	BinderIO
+	TxIO
+	ReadNodeFile(path string) ([]byte, error)
+}
+
+// NewNodeResult is the outcome of AddNewNode.
+type NewNodeResult struct {
+	binder.OpResult
+	// NodePath is the path of the created node file.
+	NodePath string
+	// PrevBinder is the binder content before the node was added, so a
+	// caller can undo the addition.
+	PrevBinder []byte
+}
+
+// AddNewNode creates a node file named params.Target, which must already be
+// set, and adds it to the binder at binderPath (pmk add --new). fm supplies
+// the title, type, and synopsis; its ID and timestamps are set from the
+// target and now. The node's body is body when non-nil, else its type's
+// template.
+//
+// The node file and the binder are written in one FileTx, and neither when
+// the binder edit has error diagnostics. A dry run writes neither. As with
+// ApplyBinderOp, the result is nil when nothing was attempted.
+func AddNewNode(ctx context.Context, io NewNodeIO, binderPath string, params binder.AddChildParams, fm node.Frontmatter, body []byte, now string) (*NewNodeResult, error) {
+	src, proj, err := readProject(ctx, io, binderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	binderDir := filepath.Dir(binderPath)
+	nodePath, err := safepath.Resolve(binderDir, params.Target)
+	if err != nil {
+		return nil, err
+	}
+	fm.ID = strings.TrimSuffix(params.Target, ".md")
+	fm.Created, fm.Updated = now, now
+
+	var types node.FrontmatterSchema
+	if fm.Type != "" {
+		if types, err = loadNodeTypes(io, binderDir); err != nil {
+			return nil, err
+		}
+	}
+	content, err := types.NewNodeContent(fm)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		content = append(node.SerializeFrontmatter(fm), body...)
+	}
+
+	if params.DryRun {
+		modified, entries, diags := ops.AddChildEntries(ctx, src, proj, params)
+		diags = binder.ApplySeverityOverrides(diags, proj.SeverityOverrides)
+		res := &NewNodeResult{OpResult: *newOpResult(src, modified, entries, diags), NodePath: nodePath, PrevBinder: src}
+		if !hasError(diags) && res.Changed {
+			res.Diff = BinderDiff(binderPath, src, modified)
+		}
+		return res, nil
+	}
+	modified, entries, diags := ops.AddChildEntries(ctx, src, proj, params)
+	diags = binder.ApplySeverityOverrides(diags, proj.SeverityOverrides)
+	res := &NewNodeResult{OpResult: *newOpResult(src, modified, entries, diags), NodePath: nodePath, PrevBinder: src}
+	if hasError(diags) {
+		return res, nil
+	}
+	tx, err := beginTx(io, binderPath)
+	if err != nil {
+		return nil, err
+	}
+	tx.Create(nodePath, content)
+	if res.Changed {
+		res.Diff = BinderDiff(binderPath, src, modified)
+		tx.WriteBinder(binderPath, modified)
+	}
+	return res, tx.Commit()
+}
+
+// loadNodeTypes reads the node type declarations from the project's
+// .prosemark.yml. A missing config leaves only the built-in types.
+func loadNodeTypes(io NewNodeIO, projectDir string) (node.FrontmatterSchema, error) {
+	content, err := io.ReadNodeFile(node.ConfigPath(projectDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading .prosemark.yml: %w", err)
+	}
+	types, err := node.ParseFrontmatterSchema(content)
+	if err != nil {
+		return nil, fmt.Errorf(".prosemark.yml: %w", err)
+	}
+	return types, nil
+}
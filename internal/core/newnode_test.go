@@ -0,0 +1,213 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+const newNodeNow = "2026-01-02T03:04:05Z"
+
+func addNewNode(io *fakeBinderIO, parent string, fm node.Frontmatter) (*NewNodeResult, error) {
+	params := binder.AddChildParams{ParentSelector: parent, Target: "0192f0c1-0000-7000-8000-000000000001.md", Title: fm.Title, Position: "last"}
+	return AddNewNode(context.Background(), io, binderPath, params, fm, nil, newNodeNow)
+}
+
+func TestAddNewNode(t *testing.T) {
+	io := &fakeBinderIO{
+		binder: []byte(oneChild),
+		files:  map[string][]byte{"/proj/.prosemark.yml": []byte("types:\n  scene:\n    template: \"## Beats\"\n")},
+	}
+	res, err := addNewNode(io, ".", node.Frontmatter{Title: "Opening", Type: "scene"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	nodePath := "/proj/0192f0c1-0000-7000-8000-000000000001.md"
+	if res.NodePath != nodePath || !res.Changed || string(res.PrevBinder) != oneChild {
+		t.Errorf("result = %+v", res)
+	}
+	content := string(io.files[nodePath])
+	for _, want := range []string{"id: 0192f0c1-0000-7000-8000-000000000001\n", "title: Opening\n", "created: " + newNodeNow, "updated: " + newNodeNow, "## Beats\n"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("node file %q missing %q", content, want)
+		}
+	}
+	if !strings.Contains(string(io.binder), "- [Opening](0192f0c1-0000-7000-8000-000000000001.md)\n") {
+		t.Errorf("binder = %q", io.binder)
+	}
+}
+
+func TestAddNewNode_Body(t *testing.T) {
+	io := &fakeBinderIO{binder: []byte(oneChild)}
+	params := binder.AddChildParams{ParentSelector: ".", Target: "0192f0c1-0000-7000-8000-000000000002.md", Title: "Clipping", Position: "last"}
+	fm := node.Frontmatter{Title: "Clipping", Type: node.TypeReference, Source: "https://example.com/"}
+	res, err := AddNewNode(context.Background(), io, binderPath, params, fm, []byte("Captured text.\n"), newNodeNow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content := string(io.files[res.NodePath])
+	if !strings.Contains(content, "source: https://example.com/\n") || !strings.HasSuffix(content, "---\nCaptured text.\n") {
+		t.Errorf("node file = %q", content)
+	}
+}
+
+func TestAddNewNode_ErrorDiagnosticsWriteNothing(t *testing.T) {
+	io := &fakeBinderIO{binder: []byte(oneChild)}
+	res, err := addNewNode(io, "missing", node.Frontmatter{Title: "Opening"})
+	if res == nil || res.Changed || len(res.Diagnostics) == 0 {
+		t.Fatalf("result = %+v", res)
+	}
+	if err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+	if io.files != nil || io.deleted != nil || io.written != nil {
+		t.Errorf("files = %v, deleted = %v, written = %q", io.files, io.deleted, io.written)
+	}
+}
+
+func TestAddNewNode_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		io         *fakeBinderIO
+		fm         node.Frontmatter
+		wantResult bool
+		wantErr    string
+	}{
+		{name: "binder read", io: &fakeBinderIO{readErr: errors.New("denied")}, wantErr: "reading binder: denied"},
+		{
+			name:    "config read",
+			io:      &fakeBinderIO{binder: []byte(oneChild), nodeReadErr: errors.New("denied")},
+			fm:      node.Frontmatter{Type: "scene"},
+			wantErr: "reading .prosemark.yml: denied",
+		},
+		{name: "unknown type", io: &fakeBinderIO{binder: []byte(oneChild)}, fm: node.Frontmatter{Type: "saga"}, wantErr: `unknown node type "saga"`},
+		{name: "node write", io: &fakeBinderIO{binder: []byte(oneChild), fileErr: errors.New("full")}, wantResult: true, wantErr: "creating /proj/0192f0c1-0000-7000-8000-000000000001.md: full"},
+		{name: "binder write", io: &fakeBinderIO{binder: []byte(oneChild), writeErr: errors.New("full")}, wantResult: true, wantErr: "writing binder: full"},
+		{
+			name:       "binder write and rollback",
+			io:         &fakeBinderIO{binder: []byte(oneChild), writeErr: errors.New("full"), deleteErr: errors.New("busy")},
+			wantResult: true,
+			wantErr:    "writing binder: full; rollback also failed: busy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := addNewNode(tt.io, ".", tt.fm)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+			if (res != nil) != tt.wantResult {
+				t.Errorf("result = %+v, want result %v", res, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestLoadNodeTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string][]byte
+		wantErr string
+	}{
+		{name: "missing config"},
+		{name: "invalid schema", files: map[string][]byte{"/proj/.prosemark.yml": []byte("types: [scene]\n")}, wantErr: ".prosemark.yml: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			types, err := loadNodeTypes(&fakeBinderIO{files: tt.files}, "/proj")
+			if tt.wantErr == "" {
+				if err != nil || types != nil {
+					t.Errorf("loadNodeTypes = %v, %v", types, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMaterialize(t *testing.T) {
+	io := &fakeBinderIO{binder: []byte(oneChild + "- [Interlude]()\n")}
+	params := binder.MaterializeParams{Selector: "Interlude", Target: "0192f0c1-0000-7000-8000-000000000003.md"}
+	res, err := Materialize(context.Background(), io, binderPath, params, newNodeNow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	nodePath := "/proj/0192f0c1-0000-7000-8000-000000000003.md"
+	if res.NodePath != nodePath || !res.Changed || len(res.Entries) != 1 {
+		t.Errorf("result = %+v", res)
+	}
+	content := string(io.files[nodePath])
+	for _, want := range []string{"id: 0192f0c1-0000-7000-8000-000000000003\n", "title: Interlude\n", "created: " + newNodeNow} {
+		if !strings.Contains(content, want) {
+			t.Errorf("node file %q missing %q", content, want)
+		}
+	}
+	if want := oneChild + "- [Interlude](0192f0c1-0000-7000-8000-000000000003.md)\n"; string(io.binder) != want {
+		t.Errorf("binder = %q, want %q", io.binder, want)
+	}
+}
+
+func TestMaterialize_Errors(t *testing.T) {
+	src := []byte(oneChild + "- [Interlude]()\n")
+	tests := []struct {
+		name       string
+		io         *fakeBinderIO
+		selector   string
+		wantResult bool
+		wantErr    string
+	}{
+		{name: "binder read", io: &fakeBinderIO{readErr: errors.New("denied")}, selector: "Interlude", wantErr: "reading binder: denied"},
+		{name: "not a placeholder", io: &fakeBinderIO{binder: src}, selector: "ch1", wantResult: true},
+		{name: "node write", io: &fakeBinderIO{binder: src, fileErr: errors.New("full")}, selector: "Interlude", wantResult: true, wantErr: "creating /proj/new.md: full"},
+		{name: "binder write", io: &fakeBinderIO{binder: src, writeErr: errors.New("full")}, selector: "Interlude", wantResult: true, wantErr: "writing binder: full"},
+		{
+			name:       "binder write and rollback",
+			io:         &fakeBinderIO{binder: src, writeErr: errors.New("full"), deleteErr: errors.New("busy")},
+			selector:   "Interlude",
+			wantResult: true,
+			wantErr:    "writing binder: full; rollback also failed: busy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := binder.MaterializeParams{Selector: tt.selector, Target: "new.md"}
+			res, err := Materialize(context.Background(), tt.io, binderPath, params, newNodeNow)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+			if (res != nil) != tt.wantResult {
+				t.Errorf("result = %+v, want result %v", res, tt.wantResult)
+			}
+			if tt.wantErr == "writing binder: full" && len(tt.io.deleted) != 1 {
+				t.Errorf("deleted = %v, want the node file rolled back", tt.io.deleted)
+			}
+		})
+	}
+}
+
+func TestNewNode_EscapingTarget(t *testing.T) {
+	dir := escapingDir(t)
+	io := &fakeBinderIO{binder: []byte(oneChild + "- [Interlude]()\n")}
+	binderAt := filepath.Join(dir, "_binder.md")
+
+	params := binder.AddChildParams{ParentSelector: ".", Target: "out/new.md", Position: "last"}
+	if res, err := AddNewNode(context.Background(), io, binderAt, params, node.Frontmatter{Title: "New"}, nil, newNodeNow); res != nil || !errors.Is(err, safepath.ErrTraversal) {
+		t.Errorf("AddNewNode = %+v, %v; want a traversal error", res, err)
+	}
+	mparams := binder.MaterializeParams{Selector: "Interlude", Target: "out/new.md"}
+	if res, err := Materialize(context.Background(), io, binderAt, mparams, newNodeNow); res != nil || !errors.Is(err, safepath.ErrTraversal) {
+		t.Errorf("Materialize = %+v, %v; want a traversal error", res, err)
+	}
+	if io.files != nil || io.written != nil {
+		t.Errorf("wrote files %v and binders %q", io.files, io.written)
+	}
+}
@@ -0,0 +1,259 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// OutlineParams are the parameters of AddOutline (pmk add --outline).
+type OutlineParams struct {
+	// Params places the first top-level item, as in AddChild; its Target
+	// and Title are unused. DryRun writes nothing.
+	Params binder.AddChildParams
+	// Outline is the nested Markdown list to add (see binder.ParseOutline).
+	Outline []byte
+	// NewID, when set, names a new node file for every item in the
+	// project's ID scheme (pmk add --outline --new). Otherwise each item
+	// must name its target (see OutlineItem.Link).
+	NewID func(node.IDScheme) (string, error)
+	// Now is the created and updated time of new node files.
+	Now string
+}
+
+// OutlineResult is the outcome of AddOutline.
+type OutlineResult struct {
+	binder.OpResult
+	// Ops are the add operations that inserted the items, in order, for
+	// the operation journal.
+	Ops []binder.OpSpec
+	// Count is the number of items in the outline.
+	Count int
+}
+
+// OutlineNode is a node file to be created for an outline item.
+type OutlineNode struct {
+	Target  string
+	Content []byte
+}
+
+// OutlineInsert is the outcome of InsertOutline.
+type OutlineInsert struct {
+	// Binder is the binder source with the items inserted, or the source
+	// unchanged after an error diagnostic.
+	Binder []byte
+	// Nodes are the node files to create for the items when NewID is set.
+	Nodes []OutlineNode
+	// Entries locates the inserted entries in Binder, in document order.
+	Entries []binder.AffectedEntry
+	// Ops are the add operations that inserted the items, in order.
+	Ops         []binder.OpSpec
+	Diagnostics []binder.Diagnostic
+}
+
+// AddOutline inserts every item of params.Outline into the binder at
+// binderPath as a subtree, in one binder write. With params.NewID each item
+// gets a new node file, titled from the item text with the original text as
+// its synopsis; the node files and the binder are written in one FileTx, and
+// neither when the edit has error diagnostics or is a dry run.
+//
+// As with ApplyBinderOp, the result is nil when nothing was attempted: the
+// binder cannot be read, the project cannot be scanned (a *ScanError), or
+// the outline has no items or names no target for one.
+func AddOutline(ctx context.Context, io NewNodeIO, binderPath string, params OutlineParams) (*OutlineResult, error) {
+	src, proj, err := readProject(ctx, io, binderPath)
+	if err != nil {
+		return nil, err
+	}
+	items := binder.ParseOutline(params.Outline)
+	if len(items) == 0 {
+		return nil, fmt.Errorf("outline contains no list items")
+	}
+	ins, err := InsertOutline(ctx, src, proj, params.Params, items, params.NewID, params.Now)
+	if err != nil {
+		return nil, err
+	}
+	diags := binder.ApplySeverityOverrides(ins.Diagnostics, proj.SeverityOverrides)
+	res := &OutlineResult{OpResult: *newOpResult(src, ins.Binder, ins.Entries, diags), Ops: ins.Ops, Count: countOutlineItems(items)}
+	if hasError(diags) {
+		return res, nil
+	}
+	if res.Changed {
+		res.Diff = BinderDiff(binderPath, src, ins.Binder)
+	}
+	if params.Params.DryRun || (!res.Changed && len(ins.Nodes) == 0) {
+		return res, nil
+	}
+
+	binderDir := filepath.Dir(binderPath)
+	paths := make([]string, len(ins.Nodes))
+	for i, n := range ins.Nodes {
+		if paths[i], err = safepath.Resolve(binderDir, n.Target); err != nil {
+			return res, err
+		}
+	}
+	// The node files and the binder land together or not at all.
+	tx, err := beginTx(io, binderPath)
+	if err != nil {
+		return res, err
+	}
+	for i, n := range ins.Nodes {
+		tx.Create(paths[i], n.Content)
+	}
+	if res.Changed {
+		tx.WriteBinder(binderPath, ins.Binder)
+	}
+	return res, tx.Commit()
+}
+
+// InsertOutline inserts items as a subtree of the binder source src in
+// memory. The first top-level item is placed according to top; later
+// top-level items follow their preceding sibling, and nested items are
+// appended under their parent's target. Each insertion goes through
+// ops.AddChild, so serialization matches pmk add.
+//
+// When newID is set, every item gets a new node, named by newID in proj's
+// ID scheme, whose file is returned in Nodes, titled from the item text with
+// the original text as its synopsis and stamped with now. Otherwise an item
+// that names no target is an error.
+//
+// On the first error diagnostic the result holds src unchanged, with the
+// diagnostics collected so far.
+func InsertOutline(ctx context.Context, src []byte, proj *binder.Project, top binder.AddChildParams, items []*binder.OutlineItem, newID func(node.IDScheme) (string, error), now string) (*OutlineInsert, error) {
+	ins := &OutlineInsert{Binder: src}
+	var nodes []OutlineNode
+	var specs []binder.OpSpec
+	current := src
+	scheme := node.DefaultIDScheme
+	if newID != nil {
+		var err error
+		if scheme, err = ProjectIDScheme(proj); err != nil {
+			return nil, err
+		}
+		// Nodes created earlier in the outline exist once the transaction
+		// commits; list them so later inserts don't report them as missing.
+		if proj != nil {
+			scanned := *proj
+			scanned.Files = slices.Clone(proj.Files)
+			proj = &scanned
+		}
+	}
+
+	var add func(params binder.AddChildParams, items []*binder.OutlineItem) (bool, error)
+	add = func(params binder.AddChildParams, items []*binder.OutlineItem) (bool, error) {
+		for _, item := range items {
+			var target, title string
+			if newID != nil {
+				id, err := newID(scheme)
+				if err != nil {
+					return false, fmt.Errorf("generating node ID: %w", err)
+				}
+				target, title = id, item.Title()
+			} else {
+				target, title = item.Link()
+				if target == "" {
+					return false, fmt.Errorf("outline item %q has no .md target; use --new to create node files", item.Text)
+				}
+			}
+
+			params.Target, params.Title = target, title
+			out, diags := ops.AddChild(ctx, current, proj, params)
+			ins.Diagnostics = append(ins.Diagnostics, diags...)
+			if hasError(diags) {
+				return false, nil
+			}
+			current = out
+			specs = append(specs, binder.NewOpSpec(binder.OpAdd, params))
+
+			if newID != nil {
+				if proj != nil {
+					proj.Files = append(proj.Files, target)
+				}
+				nodes = append(nodes, OutlineNode{
+					Target: target,
+					Content: node.SerializeFrontmatter(node.Frontmatter{
+						ID:       strings.TrimSuffix(target, ".md"),
+						Title:    title,
+						Synopsis: item.Text,
+						Created:  now,
+						Updated:  now,
+					}),
+				})
+			}
+
+			childParams := binder.AddChildParams{ParentSelector: target, Position: "last", Force: params.Force, Style: params.Style, ForceParse: params.ForceParse}
+			if ok, err := add(childParams, item.Children); !ok || err != nil {
+				return ok, err
+			}
+
+			// Following siblings go directly after this item.
+			params = binder.AddChildParams{ParentSelector: params.ParentSelector, After: target, Force: params.Force, Style: params.Style, ForceParse: params.ForceParse}
+		}
+		return true, nil
+	}
+
+	ok, err := add(top, items)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		ins.Binder, ins.Nodes, ins.Ops = current, nodes, specs
+		ins.Entries = outlineEntries(ctx, src, current, proj)
+	}
+	return ins, nil
+}
+
+// outlineEntries locates the entries that inserting an outline into src
+// added to out, in document order. Inserting only adds entries, so each
+// node's children in src appear in order among its children in out; the
+// others, and all their descendants, are new.
+func outlineEntries(ctx context.Context, src, out []byte, proj *binder.Project) []binder.AffectedEntry {
+	before, _, _ := binder.Parse(ctx, src, proj)
+	after, _, _ := binder.Parse(ctx, out, proj)
+	var entries []binder.AffectedEntry
+	var walk func(old, n *binder.Node, path []int)
+	walk = func(old, n *binder.Node, path []int) {
+		j := 0
+		for i, c := range n.Children {
+			p := append(slices.Clip(path), i)
+			if old != nil && j < len(old.Children) && old.Children[j].Target == c.Target && old.Children[j].Title == c.Title {
+				walk(old.Children[j], c, p)
+				j++
+				continue
+			}
+			entries = append(entries, binder.AffectedEntry{Target: c.Target, Title: c.Title, Line: c.Line, Path: p})
+			walk(nil, c, p)
+		}
+	}
+	walk(before.Root, after.Root, nil)
+	return entries
+}
+
+// countOutlineItems returns the total number of items in an outline tree.
+func countOutlineItems(items []*binder.OutlineItem) int {
+	n := len(items)
+	for _, item := range items {
+		n += countOutlineItems(item.Children)
+	}
+	return n
+}
+
+// ProjectIDScheme returns proj's node ID scheme, or an error naming an
+// unknown one. A nil project uses the default scheme.
+func ProjectIDScheme(proj *binder.Project) (node.IDScheme, error) {
+	if proj == nil {
+		return node.DefaultIDScheme, nil
+	}
+	scheme, err := node.LookupIDScheme(proj.IDScheme)
+	if err != nil {
+		return nil, fmt.Errorf(".prosemark.yml: %w", err)
+	}
+	return scheme, nil
+}
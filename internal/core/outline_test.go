@@ -0,0 +1,243 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// sequentialIDs returns a NewID that names nodes n1.md, n2.md, and so on.
+func sequentialIDs() func(node.IDScheme) (string, error) {
+	n := 0
+	return func(node.IDScheme) (string, error) {
+		n++
+		return fmt.Sprintf("n%d.md", n), nil
+	}
+}
+
+func addOutline(io *fakeBinderIO, outline string, params OutlineParams) (*OutlineResult, error) {
+	if params.Params.ParentSelector == "" {
+		params.Params.ParentSelector = "."
+	}
+	params.Outline = []byte(outline)
+	params.Now = newNodeNow
+	return AddOutline(context.Background(), io, binderPath, params)
+}
+
+func TestAddOutline(t *testing.T) {
+	io := &fakeBinderIO{binder: []byte(oneChild)}
+	res, err := addOutline(io, "- [Part](part.md)\n  - scene.md\n- ch2.md\n", OutlineParams{Params: binder.AddChildParams{Before: "ch1.md"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "<!-- prosemark-binder:v1 -->\n" +
+		"- [Part](part.md)\n" +
+		"  - [scene](scene.md)\n" +
+		"- [ch2](ch2.md)\n" +
+		"- [Chapter One](ch1.md)\n"
+	if string(io.binder) != want {
+		t.Errorf("binder =\n%s\nwant\n%s", io.binder, want)
+	}
+	if !res.Changed || res.Count != 3 || res.Diff == "" {
+		t.Errorf("result = %+v, want a changed binder, 3 items, and a diff", res)
+	}
+	wantEntries := []binder.AffectedEntry{
+		{Target: "part.md", Title: "Part", Line: 2, Path: []int{0}},
+		{Target: "scene.md", Title: "scene", Line: 3, Path: []int{0, 0}},
+		{Target: "ch2.md", Title: "ch2", Line: 4, Path: []int{1}},
+	}
+	if !reflect.DeepEqual(res.Entries, wantEntries) {
+		t.Errorf("entries = %+v, want %+v", res.Entries, wantEntries)
+	}
+	var ops []string
+	for _, spec := range res.Ops {
+		p, err := spec.DecodeParams()
+		if err != nil {
+			t.Fatalf("op does not decode: %v", err)
+		}
+		a := p.(binder.AddChildParams)
+		ops = append(ops, a.ParentSelector+" "+a.Target)
+	}
+	if want := []string{". part.md", "part.md scene.md", ". ch2.md"}; !reflect.DeepEqual(ops, want) {
+		t.Errorf("ops = %v, want %v", ops, want)
+	}
+}
+
+func TestAddOutline_NewNodes(t *testing.T) {
+	io := &fakeBinderIO{binder: []byte("<!-- prosemark-binder:v1 -->\n")}
+	res, err := addOutline(io, "- Act I\n\t- Opening image\n", OutlineParams{NewID: sequentialIDs()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Diagnostics) != 0 {
+		t.Errorf("diagnostics = %v, want none for freshly created nodes", res.Diagnostics)
+	}
+	content := string(io.files["/proj/n2.md"])
+	for _, want := range []string{"id: n2\n", "title: Opening image\n", "synopsis: Opening image\n", "created: " + newNodeNow} {
+		if !strings.Contains(content, want) {
+			t.Errorf("node file %q missing %q", content, want)
+		}
+	}
+	if !strings.HasSuffix(string(io.binder), "- [Act I](n1.md)\n  - [Opening image](n2.md)\n") {
+		t.Errorf("binder = %q", io.binder)
+	}
+	if len(res.Entries) != 2 || res.Entries[1].Target != "n2.md" {
+		t.Errorf("entries = %+v", res.Entries)
+	}
+}
+
+func TestAddOutline_NewNodeTitleWithColon(t *testing.T) {
+	io := &fakeBinderIO{binder: []byte("<!-- prosemark-binder:v1 -->\n")}
+	if _, err := addOutline(io, "- Part one: the **beginning**\n", OutlineParams{NewID: sequentialIDs()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fm, _, err := node.ParseFrontmatter(io.files["/proj/n1.md"])
+	if err != nil {
+		t.Fatalf("node file %q does not parse: %v", io.files["/proj/n1.md"], err)
+	}
+	if fm.Title != "Part one: the **beginning**" || fm.Synopsis != "Part one: the **beginning**" {
+		t.Errorf("title = %q, synopsis = %q", fm.Title, fm.Synopsis)
+	}
+}
+
+func TestAddOutline_ForcedDuplicateReportsTheNewEntry(t *testing.T) {
+	io := &fakeBinderIO{binder: []byte(oneChild)}
+	res, err := addOutline(io, "- ch1.md\n", OutlineParams{Params: binder.AddChildParams{Position: "first", Force: true}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []binder.AffectedEntry{{Target: "ch1.md", Title: "ch1", Line: 2, Path: []int{0}}}
+	if !reflect.DeepEqual(res.Entries, want) {
+		t.Errorf("entries = %+v, want %+v", res.Entries, want)
+	}
+}
+
+func TestAddOutline_WritesNothing(t *testing.T) {
+	tests := []struct {
+		name    string
+		binder  string
+		outline string
+		params  OutlineParams
+		check   func(t *testing.T, res *OutlineResult)
+	}{
+		{
+			name:    "error diagnostics",
+			binder:  oneChild,
+			outline: "- a.md\n",
+			params:  OutlineParams{Params: binder.AddChildParams{ParentSelector: "missing"}, NewID: sequentialIDs()},
+			check: func(t *testing.T, res *OutlineResult) {
+				if res.Changed || !hasError(res.Diagnostics) || res.Diff != "" || res.Ops != nil {
+					t.Errorf("result = %+v, want unchanged with an error and no ops", res)
+				}
+			},
+		},
+		{
+			name:    "dry run",
+			binder:  oneChild,
+			outline: "- a.md\n",
+			params:  OutlineParams{Params: binder.AddChildParams{DryRun: true}, NewID: sequentialIDs()},
+			check: func(t *testing.T, res *OutlineResult) {
+				if !res.Changed || res.Diff == "" {
+					t.Errorf("result = %+v, want the change and its diff", res)
+				}
+			},
+		},
+		{
+			name:    "already present",
+			binder:  oneChild,
+			outline: "- ch1.md\n",
+			check: func(t *testing.T, res *OutlineResult) {
+				if res.Changed || res.Entries != nil {
+					t.Errorf("result = %+v, want unchanged with no entries", res)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			io := &fakeBinderIO{binder: []byte(tt.binder)}
+			res, err := addOutline(io, tt.outline, tt.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.check(t, res)
+			if io.written != nil || io.files != nil {
+				t.Errorf("wrote binder %q, files %v", io.written, io.files)
+			}
+		})
+	}
+}
+
+func TestAddOutline_Errors(t *testing.T) {
+	failingID := func(node.IDScheme) (string, error) { return "", errors.New("no entropy") }
+	tests := []struct {
+		name       string
+		io         *fakeBinderIO
+		outline    string
+		newID      func(node.IDScheme) (string, error)
+		wantResult bool
+		wantErr    string
+	}{
+		{name: "binder read", io: &fakeBinderIO{readErr: errors.New("denied")}, outline: "- a.md\n", wantErr: "reading binder: denied"},
+		{name: "scan", io: &fakeBinderIO{binder: []byte(oneChild), scanErr: errors.New("denied")}, outline: "- a.md\n", wantErr: "denied"},
+		{name: "no items", io: &fakeBinderIO{binder: []byte(oneChild)}, outline: "just text\n", wantErr: "outline contains no list items"},
+		{name: "no target", io: &fakeBinderIO{binder: []byte(oneChild)}, outline: "- Plain words\n", wantErr: `outline item "Plain words" has no .md target`},
+		{name: "nested item without target", io: &fakeBinderIO{binder: []byte(oneChild)}, outline: "- a.md\n  - Plain words\n", wantErr: `outline item "Plain words" has no .md target`},
+		{name: "node ID", io: &fakeBinderIO{binder: []byte(oneChild)}, outline: "- One\n", newID: failingID, wantErr: "generating node ID: no entropy"},
+		{name: "transaction", io: &fakeBinderIO{binder: []byte(oneChild), txErr: errors.New("journal locked")}, outline: "- One\n", newID: sequentialIDs(), wantResult: true, wantErr: "journal locked"},
+		{name: "node write", io: &fakeBinderIO{binder: []byte(oneChild), fileErr: errors.New("full")}, outline: "- One\n", newID: sequentialIDs(), wantResult: true, wantErr: "creating /proj/n1.md: full"},
+		{name: "binder write", io: &fakeBinderIO{binder: []byte(oneChild), writeErr: errors.New("full")}, outline: "- One\n", newID: sequentialIDs(), wantResult: true, wantErr: "writing binder: full"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := addOutline(tt.io, tt.outline, OutlineParams{NewID: tt.newID})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+			if (res != nil) != tt.wantResult {
+				t.Errorf("result = %+v, want result %v", res, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestAddOutline_EscapingNodeFile(t *testing.T) {
+	dir := escapingDir(t)
+	io := &fakeBinderIO{binder: []byte(oneChild)}
+	newID := func(node.IDScheme) (string, error) { return "out/outside.md", nil }
+	res, err := AddOutline(context.Background(), io, filepath.Join(dir, "_binder.md"), OutlineParams{Params: binder.AddChildParams{ParentSelector: "."}, Outline: []byte("- One\n"), NewID: newID})
+	if !errors.Is(err, safepath.ErrTraversal) || res == nil {
+		t.Fatalf("res = %v, err = %v; want a result and a traversal error", res, err)
+	}
+	if io.written != nil || io.files != nil {
+		t.Errorf("wrote binder %q, files %v", io.written, io.files)
+	}
+}
+
+func TestInsertOutline_UnknownIDScheme(t *testing.T) {
+	items := binder.ParseOutline([]byte("- One\n"))
+	proj := &binder.Project{IDScheme: "sequential"}
+	_, err := InsertOutline(context.Background(), []byte(oneChild), proj, binder.AddChildParams{ParentSelector: "."}, items, sequentialIDs(), newNodeNow)
+	if err == nil || !strings.Contains(err.Error(), `.prosemark.yml: unknown id_scheme "sequential"`) {
+		t.Errorf("err = %v, want the unknown id_scheme", err)
+	}
+}
+
+func TestInsertOutline_NilProject(t *testing.T) {
+	items := binder.ParseOutline([]byte("- One\n  - Two\n"))
+	ins, err := InsertOutline(context.Background(), []byte("<!-- prosemark-binder:v1 -->\n"), nil, binder.AddChildParams{ParentSelector: ".", Position: "last"}, items, sequentialIDs(), newNodeNow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ins.Nodes) != 2 || !strings.HasSuffix(string(ins.Binder), "- [One](n1.md)\n  - [Two](n2.md)\n") {
+		t.Errorf("insert = %+v", ins)
+	}
+}
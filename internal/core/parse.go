@@ -0,0 +1,152 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// ParseIO reads the binder file and scans its project directory.
+type ParseIO interface {
+	ReadBinder(ctx context.Context, path string) ([]byte, error)
+	ScanProject(ctx context.Context, binderPath string) (*binder.Project, error)
+}
+
+// Parsed is a parsed binder and its diagnostics.
+type Parsed struct {
+	Result      *binder.ParseResult
+	Diagnostics []binder.Diagnostic
+	// Err is the parser's failure, if any. It is also reported in
+	// Diagnostics as OPE009.
+	Err error
+}
+
+// CodeTitleMismatch is the diagnostic code for a binder entry whose title
+// differs from the frontmatter title of its node file.
+const CodeTitleMismatch = "PMKW012"
+
+// ParseOptions adjust Parse and ParseBinder.
+type ParseOptions struct {
+	// RepairEncoding reads invalid UTF-8 as U+FFFD instead of failing the
+	// parse, and reports it as a binder.CodeInvalidUTF8 error. The result is
+	// for inspection only and must not be written back.
+	RepairEncoding bool
+	// ResolveTitles has Parse resolve entry titles from the frontmatter of
+	// their node files (see ResolveTitles), read through the ParseIO's
+	// NodeFileReader.
+	ResolveTitles bool
+}
+
+// NodeFileReader is implemented by a ParseIO that can read node files, as
+// ParseOptions.ResolveTitles requires.
+type NodeFileReader interface {
+	ReadNodeFile(path string) ([]byte, error)
+}
+
+// Parse reads and parses the binder at binderPath (pmk parse). When the
+// project sets SubBinders, the binders it links to are expanded into the
+// tree (see binder.ExpandSubBinders). With opts.ResolveTitles, entry titles
+// are resolved from node files.
+func Parse(ctx context.Context, io ParseIO, binderPath string, opts ParseOptions) (*Parsed, error) {
+	src, err := io.ReadBinder(ctx, binderPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading binder: %w", err)
+	}
+	proj, err := io.ScanProject(ctx, binderPath)
+	if err != nil {
+		return nil, fmt.Errorf("scanning project: %w", err)
+	}
+	parsed := ParseBinder(ctx, src, proj, opts)
+	if proj.SubBinders && parsed.Err == nil && len(parsed.Result.SubBinders) > 0 {
+		parsed.Diagnostics = binder.ExpandSubBinders(ctx, parsed.Result, parsed.Diagnostics, proj, subBinderReader(ctx, io, binderPath))
+	}
+	if opts.ResolveTitles && parsed.Err == nil {
+		r, ok := io.(NodeFileReader)
+		if !ok {
+			return nil, fmt.Errorf("resolving titles: node files cannot be read")
+		}
+		read := func(target string) ([]byte, error) {
+			path, err := safepath.Resolve(filepath.Dir(binderPath), target)
+			if err != nil {
+				return nil, err
+			}
+			return r.ReadNodeFile(path)
+		}
+		titleDiags := ResolveTitles(parsed.Result.Root, read)
+		parsed.Diagnostics = append(parsed.Diagnostics, binder.ApplySeverityOverrides(titleDiags, proj.SeverityOverrides)...)
+	}
+	return parsed, nil
+}
+
+// ParseBinder parses binder source src against proj. Diagnostics is never
+// nil.
+func ParseBinder(ctx context.Context, src []byte, proj *binder.Project, opts ParseOptions) *Parsed {
+	var repairDiag *binder.Diagnostic
+	if opts.RepairEncoding {
+		src, repairDiag = binder.RepairUTF8(src)
+	}
+	result, diags, err := binder.Parse(ctx, src, proj)
+	if repairDiag != nil {
+		diags = append([]binder.Diagnostic{*repairDiag}, diags...)
+	}
+	if err != nil {
+		diags = append(diags, binder.Diagnostic{
+			Severity: "error",
+			Code:     binder.CodeIOOrParseFailure,
+			Message:  fmt.Sprintf("parse error: %v", err),
+		})
+	}
+	if diags == nil {
+		diags = []binder.Diagnostic{}
+	}
+	return &Parsed{Result: result, Diagnostics: diags, Err: err}
+}
+
+// ResolveTitles sets the FileTitle of each node under root to the
+// frontmatter title of its node file, read through read by target, and
+// returns a PMKW012 warning for each node whose binder title differs from
+// it. Placeholders, files that cannot be read, and files with no
+// frontmatter title are skipped.
+func ResolveTitles(root *binder.Node, read func(target string) ([]byte, error)) []binder.Diagnostic {
+	titles := make(map[string]string)
+	var diags []binder.Diagnostic
+	resolve := func(n *binder.Node) {
+		if n.Target == "" {
+			return
+		}
+		title, ok := titles[n.Target]
+		if !ok {
+			if content, err := read(n.Target); err == nil {
+				fm, _, _ := node.ParseFrontmatter(content)
+				title = strings.TrimSpace(fm.Title)
+			}
+			titles[n.Target] = title
+		}
+		if title == "" {
+			return
+		}
+		n.FileTitle = title
+		if strings.TrimSpace(n.Title) != title {
+			diags = append(diags, binder.Diagnostic{
+				Severity: "warning",
+				Code:     CodeTitleMismatch,
+				Message:  fmt.Sprintf("binder title %q differs from the title %q in %s", n.Title, title, n.Target),
+				Location: &binder.Location{Line: n.Line},
+			})
+		}
+	}
+	var walk func(n *binder.Node)
+	walk = func(n *binder.Node) {
+		for _, c := range n.Children {
+			resolve(c)
+			walk(c)
+		}
+	}
+	walk(root)
+	return diags
+}
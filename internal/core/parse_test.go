@@ -0,0 +1,134 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestParse(t *testing.T) {
+	parsed, err := Parse(context.Background(), &fakeBinderIO{binder: []byte(oneChild)}, binderPath, ParseOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.Err != nil || len(parsed.Result.Root.Children) != 1 || parsed.Result.Root.Children[0].Target != "ch1.md" {
+		t.Errorf("parsed = %+v", parsed)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		io      *fakeBinderIO
+		wantErr string
+	}{
+		{"read", &fakeBinderIO{readErr: errors.New("denied")}, "reading binder: denied"},
+		{"scan", &fakeBinderIO{binder: []byte(oneChild), scanErr: errors.New("denied")}, "scanning project: denied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(context.Background(), tt.io, binderPath, ParseOptions{}); err == nil || err.Error() != tt.wantErr {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseBinder(t *testing.T) {
+	clean := ParseBinder(context.Background(), []byte("<!-- prosemark-binder:v1 -->\n"), nil, ParseOptions{})
+	if clean.Err != nil || clean.Diagnostics == nil || len(clean.Diagnostics) != 0 {
+		t.Errorf("clean binder = %+v", clean)
+	}
+
+	bad := ParseBinder(context.Background(), []byte{0xff}, nil, ParseOptions{})
+	if bad.Err == nil || bad.Result == nil {
+		t.Fatalf("invalid UTF-8 = %+v", bad)
+	}
+	last := bad.Diagnostics[len(bad.Diagnostics)-1]
+	if last.Code != binder.CodeIOOrParseFailure || !strings.Contains(last.Message, "invalid UTF-8") {
+		t.Errorf("diagnostic = %+v, want OPE009", last)
+	}
+}
+
+func TestParseBinder_RepairEncoding(t *testing.T) {
+	src := []byte("<!-- prosemark-binder:v1 -->\n- [Ch\xff](ch.md)\n")
+	parsed := ParseBinder(context.Background(), src, nil, ParseOptions{RepairEncoding: true})
+	if parsed.Err != nil {
+		t.Fatalf("Err = %v, want the repaired binder to parse", parsed.Err)
+	}
+	if len(parsed.Diagnostics) == 0 || parsed.Diagnostics[0].Code != binder.CodeInvalidUTF8 {
+		t.Fatalf("diagnostics = %+v, want PMKE008 first", parsed.Diagnostics)
+	}
+	if got := parsed.Result.Root.Children[0].Title; got != "Ch\uFFFD" {
+		t.Errorf("title = %q, want the invalid byte read as U+FFFD", got)
+	}
+}
+
+func TestResolveTitles(t *testing.T) {
+	src := "<!-- prosemark-binder:v1 -->\n- [Chapter One](ch1.md)\n  - [Old Title](ch2.md)\n- [Untitled](ch3.md)\n- [Gone](gone.md)\n- [Later]()\n- [Old Title](ch2.md)\n"
+	result, _, err := binder.Parse(context.Background(), []byte(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"ch1.md": "---\ntitle: Chapter One\n---\n",
+		"ch2.md": "---\ntitle: ' Chapter Two '\n---\n",
+		"ch3.md": "No frontmatter.\n",
+	}
+	reads := 0
+	diags := ResolveTitles(result.Root, func(target string) ([]byte, error) {
+		reads++
+		if content, ok := files[target]; ok {
+			return []byte(content), nil
+		}
+		return nil, errors.New("missing")
+	})
+	var got []string
+	var walk func(n *binder.Node)
+	walk = func(n *binder.Node) {
+		for _, c := range n.Children {
+			got = append(got, c.Title+"="+c.FileTitle)
+			walk(c)
+		}
+	}
+	walk(result.Root)
+	if want := "Chapter One=Chapter One Old Title=Chapter Two Untitled= Gone= Later= Old Title=Chapter Two"; strings.Join(got, " ") != want {
+		t.Errorf("titles = %q, want %q", strings.Join(got, " "), want)
+	}
+	if reads != 4 {
+		t.Errorf("read %d files, want each target once (4)", reads)
+	}
+	if len(diags) != 2 || diags[0].Code != CodeTitleMismatch || diags[0].Location.Line != 3 || diags[1].Location.Line != 7 {
+		t.Errorf("diags = %+v, want PMKW012 on lines 3 and 7", diags)
+	}
+}
+
+func TestParse_ResolveTitles(t *testing.T) {
+	dir := escapingDir(t)
+	io := &fakeBinderIO{
+		binder: []byte(oneChild + "- [Outside](out/outside.md)\n"),
+		files: map[string][]byte{
+			filepath.Join(dir, "ch1.md"):            []byte("---\ntitle: The Beginning\n---\n"),
+			filepath.Join(dir, "out", "outside.md"): []byte("---\ntitle: Elsewhere\n---\n"),
+		},
+	}
+	parsed, err := Parse(context.Background(), io, filepath.Join(dir, "_binder.md"), ParseOptions{ResolveTitles: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	children := parsed.Result.Root.Children
+	if children[0].FileTitle != "The Beginning" || children[1].FileTitle != "" {
+		t.Errorf("file titles = %q, %q; want only the one inside the project", children[0].FileTitle, children[1].FileTitle)
+	}
+	if last := parsed.Diagnostics[len(parsed.Diagnostics)-1]; last.Code != CodeTitleMismatch || last.Location.Line != 2 {
+		t.Errorf("diagnostics = %+v, want PMKW012 for ch1.md last", parsed.Diagnostics)
+	}
+
+	if _, err := Parse(context.Background(), &nestedBinderIO{}, binderPath, ParseOptions{ResolveTitles: true}); err == nil || !strings.Contains(err.Error(), "node files cannot be read") {
+		t.Errorf("err = %v, want node files cannot be read", err)
+	}
+}
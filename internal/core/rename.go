@@ -0,0 +1,119 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// RenameIO handles I/O for renaming a node file along with its binder
+// references. Node files are read and written as stored, so a locked body
+// is moved without being unlocked.
+type RenameIO interface {
+	BinderIO
+	TxIO
+	ReadNodeFile(path string) ([]byte, error)
+}
+
+// RenameResult is the outcome of Rename.
+type RenameResult struct {
+	binder.OpResult
+	// OldPath and NewPath are the node file's paths before and after.
+	OldPath string
+	NewPath string
+}
+
+// fileMove is one file Rename or DeleteWithFiles moves, with the content
+// written to its new path, if it has one.
+type fileMove struct {
+	from, to string
+	content  []byte
+}
+
+// Rename moves the node file of the entry selected by params.Selector to
+// params.NewTarget and points every binder reference at it (pmk rename). A
+// frontmatter id equal to the old filename stem follows the new one, and a
+// companion notes file ({stem}.notes.md) is moved along.
+//
+// The new files are written first, then the binder, and the old files are
+// removed last, in one FileTx: a failure at any step undoes the steps
+// before it. A dry run checks the files but writes nothing.
+func Rename(ctx context.Context, io RenameIO, binderPath string, params binder.RenameParams) (*RenameResult, error) {
+	src, proj, err := readProject(ctx, io, binderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	modified, oldTarget, entries, diags := ops.Rename(ctx, src, proj, params)
+	diags = binder.ApplySeverityOverrides(diags, proj.SeverityOverrides)
+	res := &RenameResult{OpResult: *newOpResult(src, modified, entries, diags)}
+	if hasError(diags) || !res.Changed {
+		return res, nil
+	}
+
+	dir := filepath.Dir(binderPath)
+	oldStem := strings.TrimSuffix(oldTarget, ".md")
+	newStem := strings.TrimSuffix(ops.NormalizeTarget(params.NewTarget), ".md")
+	if res.OldPath, err = safepath.Resolve(dir, oldTarget); err != nil {
+		return nil, err
+	}
+	if res.NewPath, err = safepath.Resolve(dir, newStem+".md"); err != nil {
+		return nil, err
+	}
+
+	original, err := io.ReadNodeFile(res.OldPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading node file: %w", err)
+	}
+	content, _ := node.RenameID(original, filepath.Base(oldStem), filepath.Base(newStem))
+	moves := []fileMove{{from: res.OldPath, to: res.NewPath, content: content}}
+	notesPath, err := safepath.Resolve(dir, oldStem+".notes.md")
+	if err != nil {
+		return nil, err
+	}
+	newNotesPath, err := safepath.Resolve(dir, newStem+".notes.md")
+	if err != nil {
+		return nil, err
+	}
+	notes, err := io.ReadNodeFile(notesPath)
+	switch {
+	case err == nil:
+		moves = append(moves, fileMove{from: notesPath, to: newNotesPath, content: notes})
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("reading notes file: %w", err)
+	}
+
+	for _, m := range moves {
+		if _, err := io.ReadNodeFile(m.to); err == nil {
+			return nil, fmt.Errorf("%s already exists", m.to)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("checking %s: %w", m.to, err)
+		}
+	}
+
+	res.Diff = BinderDiff(binderPath, src, modified)
+	if params.DryRun {
+		return res, nil
+	}
+
+	tx, err := beginTx(io, binderPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range moves {
+		tx.Create(m.to, m.content)
+	}
+	tx.WriteBinder(binderPath, modified)
+	for _, m := range moves {
+		tx.Remove(m.from)
+	}
+	return res, tx.Commit()
+}
@@ -0,0 +1,256 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core/coretest"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// renameTestIO is a fakeBinderIO that can fail reads, writes, and deletes
+// of particular paths, and binder writes after the first.
+type renameTestIO struct {
+	*fakeBinderIO
+	readErrs, writeErrs, deleteErrs map[string]error
+	rewriteErr                      error
+	binderWrites                    int
+}
+
+func newRenameTestIO() *renameTestIO {
+	return &renameTestIO{fakeBinderIO: &fakeBinderIO{
+		binder: []byte(oneChild),
+		files: map[string][]byte{
+			"/proj/ch1.md":       []byte("---\nid: ch1\nmood: tense\n---\nBody.\n"),
+			"/proj/ch1.notes.md": []byte("Notes.\n"),
+		},
+	}}
+}
+
+func (r *renameTestIO) ReadNodeFile(path string) ([]byte, error) {
+	if err := r.readErrs[path]; err != nil {
+		return nil, err
+	}
+	return r.fakeBinderIO.ReadNodeFile(path)
+}
+
+func (r *renameTestIO) WriteNodeFileAtomic(path string, content []byte) error {
+	if err := r.writeErrs[path]; err != nil {
+		return err
+	}
+	return r.fakeBinderIO.WriteNodeFileAtomic(path, content)
+}
+
+func (r *renameTestIO) DeleteFile(path string) error {
+	if err := r.deleteErrs[path]; err != nil {
+		return err
+	}
+	return r.fakeBinderIO.DeleteFile(path)
+}
+
+func (r *renameTestIO) WriteBinderAtomic(ctx context.Context, path string, data []byte) error {
+	r.binderWrites++
+	if r.binderWrites > 1 && r.rewriteErr != nil {
+		return r.rewriteErr
+	}
+	return r.fakeBinderIO.WriteBinderAtomic(ctx, path, data)
+}
+
+func (r *renameTestIO) BeginTx(string) (FileTx, error) {
+	if r.txErr != nil {
+		return nil, r.txErr
+	}
+	return coretest.NewTx(context.Background(), r), nil
+}
+
+func renameCh1(io RenameIO, newTarget string) (*RenameResult, error) {
+	return Rename(context.Background(), io, binderPath, binder.RenameParams{Selector: "ch1", NewTarget: newTarget})
+}
+
+func TestRename(t *testing.T) {
+	io := newRenameTestIO()
+	res, err := renameCh1(io, "./part-one.md")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Changed || res.OldPath != "/proj/ch1.md" || res.NewPath != "/proj/part-one.md" || len(res.Entries) != 1 {
+		t.Errorf("result = %+v", res)
+	}
+	if want := "<!-- prosemark-binder:v1 -->\n- [Chapter One](part-one.md)\n"; string(io.binder) != want {
+		t.Errorf("binder = %q, want %q", io.binder, want)
+	}
+	want := map[string]string{
+		"/proj/part-one.md":       "---\nid: part-one\nmood: tense\n---\nBody.\n",
+		"/proj/part-one.notes.md": "Notes.\n",
+	}
+	if len(io.files) != len(want) {
+		t.Errorf("files = %q, want %q", io.files, want)
+	}
+	for path, content := range want {
+		if string(io.files[path]) != content {
+			t.Errorf("%s = %q, want %q", path, io.files[path], content)
+		}
+	}
+}
+
+func TestRename_NothingToDo(t *testing.T) {
+	for _, newTarget := range []string{"ch1.md", "../out.md"} {
+		io := newRenameTestIO()
+		res, err := renameCh1(io, newTarget)
+		if err != nil || res == nil || res.Changed || io.written != nil || len(io.files) != 2 {
+			t.Errorf("%s: result = %+v, err = %v, files = %q", newTarget, res, err, io.files)
+		}
+	}
+}
+
+func TestRename_Errors(t *testing.T) {
+	failed := errors.New("failed")
+	tests := []struct {
+		name       string
+		setup      func(io *renameTestIO)
+		wantResult bool
+		wantErr    string
+		wantFiles  []string
+		wantBinder string
+	}{
+		{
+			name:    "binder read",
+			setup:   func(io *renameTestIO) { io.readErr = failed },
+			wantErr: "reading binder: failed",
+		},
+		{
+			name:      "node file missing",
+			setup:     func(io *renameTestIO) { delete(io.files, "/proj/ch1.md") },
+			wantErr:   "reading node file",
+			wantFiles: []string{"/proj/ch1.notes.md"},
+		},
+		{
+			name:      "notes file unreadable",
+			setup:     func(io *renameTestIO) { io.readErrs = map[string]error{"/proj/ch1.notes.md": failed} },
+			wantErr:   "reading notes file: failed",
+			wantFiles: []string{"/proj/ch1.md", "/proj/ch1.notes.md"},
+		},
+		{
+			name:      "new notes file exists",
+			setup:     func(io *renameTestIO) { io.files["/proj/new.notes.md"] = []byte("Other.\n") },
+			wantErr:   "/proj/new.notes.md already exists",
+			wantFiles: []string{"/proj/ch1.md", "/proj/ch1.notes.md", "/proj/new.notes.md"},
+		},
+		{
+			name:      "new file uncheckable",
+			setup:     func(io *renameTestIO) { io.readErrs = map[string]error{"/proj/new.md": failed} },
+			wantErr:   "checking /proj/new.md: failed",
+			wantFiles: []string{"/proj/ch1.md", "/proj/ch1.notes.md"},
+		},
+		{
+			name:       "notes write",
+			setup:      func(io *renameTestIO) { io.writeErrs = map[string]error{"/proj/new.notes.md": failed} },
+			wantResult: true,
+			wantErr:    "creating /proj/new.notes.md: failed",
+			wantFiles:  []string{"/proj/ch1.md", "/proj/ch1.notes.md"},
+		},
+		{
+			name: "notes write and rollback",
+			setup: func(io *renameTestIO) {
+				io.writeErrs = map[string]error{"/proj/new.notes.md": failed}
+				io.deleteErrs = map[string]error{"/proj/new.md": errors.New("busy")}
+			},
+			wantResult: true,
+			wantErr:    "creating /proj/new.notes.md: failed; rollback also failed: busy",
+			wantFiles:  []string{"/proj/ch1.md", "/proj/ch1.notes.md", "/proj/new.md"},
+		},
+		{
+			name:       "binder write",
+			setup:      func(io *renameTestIO) { io.writeErr = failed },
+			wantResult: true,
+			wantErr:    "writing binder: failed",
+			wantFiles:  []string{"/proj/ch1.md", "/proj/ch1.notes.md"},
+		},
+		{
+			name:       "old notes removal",
+			setup:      func(io *renameTestIO) { io.deleteErrs = map[string]error{"/proj/ch1.notes.md": failed} },
+			wantResult: true,
+			wantErr:    "removing /proj/ch1.notes.md: failed",
+			wantFiles:  []string{"/proj/ch1.md", "/proj/ch1.notes.md"},
+			wantBinder: oneChild,
+		},
+		{
+			name: "old notes removal and rollback",
+			setup: func(io *renameTestIO) {
+				io.deleteErrs = map[string]error{"/proj/ch1.notes.md": failed}
+				io.writeErrs = map[string]error{"/proj/ch1.md": errors.New("restore")}
+				io.rewriteErr = errors.New("rewrite")
+			},
+			wantResult: true,
+			wantErr:    "rollback also failed: restore\nrewrite",
+			wantFiles:  []string{"/proj/ch1.notes.md"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			io := newRenameTestIO()
+			tt.setup(io)
+			res, err := renameCh1(io, "new.md")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+			if (res != nil) != tt.wantResult {
+				t.Errorf("result = %+v, want result %v", res, tt.wantResult)
+			}
+			if tt.wantFiles != nil {
+				var got []string
+				for path := range io.files {
+					got = append(got, path)
+				}
+				if slices.Sort(got); !slices.Equal(got, tt.wantFiles) {
+					t.Errorf("files = %v, want %v", got, tt.wantFiles)
+				}
+			}
+			if tt.wantBinder != "" && string(io.binder) != tt.wantBinder {
+				t.Errorf("binder = %q, want %q", io.binder, tt.wantBinder)
+			}
+		})
+	}
+}
+
+func TestRename_EscapingPaths(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string // the entry's target
+		newTarget string
+		symlink   string // a project file linked to out/outside.md
+	}{
+		{name: "old file", target: "out/outside.md", newTarget: "new.md"},
+		{name: "new file", target: "ch1.md", newTarget: "out/new.md"},
+		{name: "old notes file", target: "ch1.md", newTarget: "new.md", symlink: "ch1.notes.md"},
+		{name: "new notes file", target: "ch1.md", newTarget: "new.md", symlink: "new.notes.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := escapingDir(t)
+			if tt.symlink != "" {
+				if err := os.Symlink(filepath.Join(dir, "out", "outside.md"), filepath.Join(dir, tt.symlink)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			io := &fakeBinderIO{
+				binder: []byte("<!-- prosemark-binder:v1 -->\n- [Chapter One](" + tt.target + ")\n"),
+				files:  map[string][]byte{filepath.Join(dir, "ch1.md"): []byte("Body.\n")},
+			}
+			params := binder.RenameParams{Selector: "Chapter One", NewTarget: tt.newTarget}
+			res, err := Rename(context.Background(), io, filepath.Join(dir, "_binder.md"), params)
+			if res != nil || !errors.Is(err, safepath.ErrTraversal) {
+				t.Errorf("Rename = %+v, %v; want a traversal error", res, err)
+			}
+			if io.written != nil || len(io.files) != 1 {
+				t.Errorf("wrote files %v and binders %q", io.files, io.written)
+			}
+		})
+	}
+}
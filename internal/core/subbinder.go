@@ -0,0 +1,81 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// CodeCrossesBinder is the diagnostic code for an operation on the binder at
+// hand whose selector names a node that lives in a nested binder.
+const CodeCrossesBinder = "PMKE013"
+
+// subBinderReader returns a reader of nested binders by path relative to the
+// directory of the binder at binderPath, for binder.ExpandSubBinders.
+func subBinderReader(ctx context.Context, io ParseIO, binderPath string) func(string) ([]byte, error) {
+	return func(target string) ([]byte, error) {
+		path, err := safepath.Resolve(filepath.Dir(binderPath), target)
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadBinder(ctx, path)
+	}
+}
+
+// crossBinderDiags explains the selectors an operation on binder source src
+// found no node for: in a project that sets SubBinders, each one that matches a node once the binders src links
+// to are expanded gets a PMKE013 error naming the nested binder to edit
+// instead. Operations never edit nested binders through the binder that
+// links to them.
+func crossBinderDiags(ctx context.Context, io ParseIO, binderPath string, src []byte, proj *binder.Project, selectors []string) []binder.Diagnostic {
+	if !proj.SubBinders {
+		return nil
+	}
+	result, diags, err := binder.Parse(ctx, src, proj)
+	if err != nil || len(result.SubBinders) == 0 {
+		return nil
+	}
+	binder.ExpandSubBinders(ctx, result, diags, proj, subBinderReader(ctx, io, binderPath))
+	var out []binder.Diagnostic
+	for _, sel := range selectors {
+		if sel == "" {
+			continue
+		}
+		found, errs := binder.FindNodes(sel, result.Root)
+		if len(errs) > 0 || len(found.Nodes) == 0 {
+			continue
+		}
+		if nested, _ := enclosingBinder(result.Root, found.Nodes[0], ""); nested != "" {
+			out = append(out, binder.Diagnostic{
+				Severity: "error",
+				Code:     CodeCrossesBinder,
+				Message:  fmt.Sprintf("selector %q matches a node in the nested binder %s; run the command against that binder instead (--project %s --binder %s)", sel, nested, path.Dir(nested), path.Base(nested)),
+			})
+		}
+	}
+	return out
+}
+
+// enclosingBinder returns the nested binder that target belongs to within
+// the expanded tree under n, where nodes belong to nested unless they or an
+// ancestor below n carry a Binder of their own. It reports false when target
+// is not under n.
+func enclosingBinder(n, target *binder.Node, nested string) (string, bool) {
+	for _, c := range n.Children {
+		b := nested
+		if c.Binder != "" {
+			b = c.Binder
+		}
+		if c == target {
+			return b, true
+		}
+		if b, ok := enclosingBinder(c, target, b); ok {
+			return b, true
+		}
+	}
+	return "", false
+}
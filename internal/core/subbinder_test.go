@@ -0,0 +1,154 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// nestedBinderIO is a BinderIO for a project at /proj whose binder links
+// to the nested binder part-two/_binder.md.
+type nestedBinderIO struct {
+	subBinders bool
+	written    int
+}
+
+func (n *nestedBinderIO) ReadBinder(_ context.Context, path string) ([]byte, error) {
+	switch path {
+	case binderPath:
+		return []byte("<!-- prosemark-binder:v1 -->\n- [Chapter One](ch1.md)\n- [Part Two](part-two/_binder.md)\n"), nil
+	case "/proj/part-two/_binder.md":
+		return []byte("<!-- prosemark-binder:v1 -->\n- [Chapter Two](ch2.md)\n"), nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func (n *nestedBinderIO) ScanProject(_ context.Context, _ string) (*binder.Project, error) {
+	return &binder.Project{
+		Files:      []string{"ch1.md", "part-two/ch2.md"},
+		BinderDir:  ".",
+		AltBinders: []string{"part-two/_binder.md"},
+		SubBinders: n.subBinders,
+	}, nil
+}
+
+func (n *nestedBinderIO) WriteBinderAtomic(_ context.Context, _ string, _ []byte) error {
+	n.written++
+	return nil
+}
+
+func TestParse_SubBinders(t *testing.T) {
+	for _, subBinders := range []bool{false, true} {
+		parsed, err := Parse(context.Background(), &nestedBinderIO{subBinders: subBinders}, binderPath, ParseOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		children := parsed.Result.Root.Children
+		if !subBinders {
+			if len(children) != 1 || len(parsed.Diagnostics) != 1 || parsed.Diagnostics[0].Code != binder.CodeSelfReferentialLink {
+				t.Errorf("without sub_binders: %d children, diagnostics %v; want 1 and BNDW008", len(children), parsed.Diagnostics)
+			}
+			continue
+		}
+		if len(children) != 2 || children[1].Binder != "part-two/_binder.md" || len(children[1].Children) != 1 ||
+			children[1].Children[0].Target != "part-two/ch2.md" || len(parsed.Diagnostics) != 0 {
+			t.Errorf("with sub_binders: children %+v, diagnostics %v", children, parsed.Diagnostics)
+		}
+	}
+}
+
+func TestBinderOps_CrossBinder(t *testing.T) {
+	io := &nestedBinderIO{subBinders: true}
+	res, err := Delete(context.Background(), io, binderPath, binder.DeleteParams{Selector: "Chapter Two", Yes: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var crossing []binder.Diagnostic
+	for _, d := range res.Diagnostics {
+		if d.Code == CodeCrossesBinder {
+			crossing = append(crossing, d)
+		}
+	}
+	if len(crossing) != 1 || !strings.Contains(crossing[0].Message, "part-two/_binder.md") ||
+		!strings.Contains(crossing[0].Message, "--project part-two --binder _binder.md") || io.written != 0 {
+		t.Errorf("diagnostics = %v, written %d; want one PMKE013 naming part-two/_binder.md and how to edit it, and no write", res.Diagnostics, io.written)
+	}
+
+	res, err = Delete(context.Background(), io, binderPath, binder.DeleteParams{Selector: "Chapter Nine", Yes: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, d := range res.Diagnostics {
+		if d.Code == CodeCrossesBinder {
+			t.Errorf("unmatched selector reported as %v", d)
+		}
+	}
+}
+
+func TestCrossBinderDiags_NothingToExplain(t *testing.T) {
+	io := &nestedBinderIO{subBinders: true}
+	proj, err := io.ScanProject(context.Background(), "/proj")
+	if err != nil {
+		t.Fatalf("ScanProject: %v", err)
+	}
+	src, err := io.ReadBinder(context.Background(), binderPath)
+	if err != nil {
+		t.Fatalf("ReadBinder: %v", err)
+	}
+	cases := []struct {
+		name      string
+		src       []byte
+		selectors []string
+	}{
+		{"no nested binders", []byte("<!-- prosemark-binder:v1 -->\n- [Chapter One](ch1.md)\n"), []string{"Chapter Two"}},
+		{"unparsable binder", []byte("\xff"), []string{"Chapter Two"}},
+		{"empty selector", src, []string{""}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diags := crossBinderDiags(context.Background(), io, binderPath, tc.src, proj, tc.selectors); len(diags) != 0 {
+				t.Errorf("crossBinderDiags() = %v, want none", diags)
+			}
+		})
+	}
+}
+
+// escapingSubBinderIO is a BinderIO whose binder links to a nested binder
+// under a symlink out of the project, recording the paths it reads.
+type escapingSubBinderIO struct {
+	read []string
+}
+
+func (e *escapingSubBinderIO) ReadBinder(_ context.Context, path string) ([]byte, error) {
+	e.read = append(e.read, path)
+	return []byte("<!-- prosemark-binder:v1 -->\n- [Part](out/_binder.md)\n"), nil
+}
+
+func (e *escapingSubBinderIO) ScanProject(_ context.Context, _ string) (*binder.Project, error) {
+	return &binder.Project{BinderDir: ".", AltBinders: []string{"out/_binder.md"}, SubBinders: true}, nil
+}
+
+func (e *escapingSubBinderIO) WriteBinderAtomic(_ context.Context, _ string, _ []byte) error {
+	return nil
+}
+
+func TestParse_SubBinderEscapes(t *testing.T) {
+	io := &escapingSubBinderIO{}
+	binderAt := filepath.Join(escapingDir(t), "_binder.md")
+	parsed, err := Parse(context.Background(), io, binderAt, ParseOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(io.read) != 1 || io.read[0] != binderAt {
+		t.Errorf("read %v, want only the project binder", io.read)
+	}
+	if len(parsed.Diagnostics) != 1 || parsed.Diagnostics[0].Code != binder.CodeUnreadableSubBinder ||
+		!strings.Contains(parsed.Diagnostics[0].Message, safepath.ErrTraversal.Error()) {
+		t.Errorf("diagnostics = %+v, want the nested binder unreadable", parsed.Diagnostics)
+	}
+}
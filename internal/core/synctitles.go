@@ -0,0 +1,111 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/diff"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// SyncTitlesIO handles I/O for syncing entry titles with the frontmatter
+// titles of their node files.
+type SyncTitlesIO interface {
+	BinderIO
+	TxIO
+	ReadNodeFile(path string) ([]byte, error)
+}
+
+// SyncTitlesResult is the outcome of SyncTitles.
+type SyncTitlesResult struct {
+	binder.OpResult
+	// Files lists the targets of the node files given a new title, or that
+	// would be under a dry run.
+	Files []string `json:"files,omitempty"`
+}
+
+// SyncTitles brings entry titles in the binder at binderPath and the
+// frontmatter titles of their node files into line (pmk sync-titles; see
+// ops.SyncTitles). With ops.SyncFileToBinder the binder is rewritten; with
+// ops.SyncBinderToFile each node file's title line is, all in one FileTx. The result always carries the diff
+// of the change; with params.DryRun nothing is written.
+func SyncTitles(ctx context.Context, io SyncTitlesIO, binderPath string, params binder.SyncTitlesParams) (*SyncTitlesResult, error) {
+	switch params.Direction {
+	case ops.SyncFileToBinder, ops.SyncBinderToFile:
+	default:
+		return nil, fmt.Errorf("unknown direction %q: want %s or %s", params.Direction, ops.SyncFileToBinder, ops.SyncBinderToFile)
+	}
+	src, proj, err := readProject(ctx, io, binderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(binderPath)
+	contents := make(map[string][]byte)
+	paths := make(map[string]string)
+	fileTitle := func(target string) (string, error) {
+		path, err := safepath.Resolve(dir, target)
+		if err != nil {
+			return "", err
+		}
+		content, err := io.ReadNodeFile(path)
+		if err != nil {
+			return "", err
+		}
+		fm, _, err := node.ParseFrontmatter(content)
+		if err != nil {
+			return "", err
+		}
+		contents[target], paths[target] = content, path
+		return strings.TrimSpace(fm.Title), nil
+	}
+	modified, entries, diags := ops.SyncTitles(ctx, src, proj, params, fileTitle)
+	diags = binder.ApplySeverityOverrides(diags, proj.SeverityOverrides)
+	res := &SyncTitlesResult{OpResult: *newOpResult(src, modified, entries, diags)}
+	if hasError(diags) {
+		return res, nil
+	}
+	if params.Direction == ops.SyncFileToBinder {
+		if !res.Changed {
+			return res, nil
+		}
+		res.Diff = BinderDiff(binderPath, src, modified)
+		if params.DryRun {
+			return res, nil
+		}
+		if err := io.WriteBinderAtomic(ctx, binderPath, modified); err != nil {
+			return res, fmt.Errorf("writing binder: %w", err)
+		}
+		return res, nil
+	}
+
+	writes := map[string][]byte{}
+	var diffs strings.Builder
+	for _, e := range entries {
+		original := contents[e.Target]
+		content, changed, err := node.SetTitle(original, e.Title)
+		if err != nil || !changed {
+			continue
+		}
+		writes[e.Target] = content
+		res.Files = append(res.Files, e.Target)
+		diffs.WriteString(diff.Unified("a/"+e.Target, "b/"+e.Target, original, content))
+	}
+	res.Diff = diffs.String()
+	if params.DryRun || len(res.Files) == 0 {
+		return res, nil
+	}
+	tx, err := beginTx(io, binderPath)
+	if err != nil {
+		return res, err
+	}
+	for _, target := range res.Files {
+		tx.Write(paths[target], writes[target])
+	}
+	return res, tx.Commit()
+}
@@ -0,0 +1,187 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+const twoChildren = "<!-- prosemark-binder:v1 -->\n- [Chapter One](ch1.md)\n- [Chapter Two](ch2.md)\n"
+
+func newSyncTitlesTestIO() *renameTestIO {
+	return &renameTestIO{fakeBinderIO: &fakeBinderIO{
+		binder: []byte(twoChildren),
+		files: map[string][]byte{
+			"/proj/ch1.md": []byte("---\nid: ch1\ntitle: The Beginning\n---\nBody.\n"),
+			"/proj/ch2.md": []byte("---\nid: ch2\n---\nBody.\n"),
+		},
+	}}
+}
+
+func syncTitles(io SyncTitlesIO, direction string, dryRun bool) (*SyncTitlesResult, error) {
+	return SyncTitles(context.Background(), io, binderPath, binder.SyncTitlesParams{Direction: direction, DryRun: dryRun})
+}
+
+func TestSyncTitles_FileToBinder(t *testing.T) {
+	for _, dryRun := range []bool{false, true} {
+		io := newSyncTitlesTestIO()
+		res, err := syncTitles(io, ops.SyncFileToBinder, dryRun)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !res.Changed || len(res.Entries) != 1 || !strings.Contains(res.Diff, "+- [The Beginning](ch1.md)") {
+			t.Errorf("result = %+v", res)
+		}
+		if d := res.Diagnostics[len(res.Diagnostics)-1]; d.Code != ops.CodeTitleNotSynced || !strings.Contains(d.Message, "ch2.md") {
+			t.Errorf("diagnostics = %+v, want PMKW013 for ch2.md", res.Diagnostics)
+		}
+		want := "<!-- prosemark-binder:v1 -->\n- [The Beginning](ch1.md)\n- [Chapter Two](ch2.md)\n"
+		if dryRun {
+			want = twoChildren
+		}
+		if string(io.binder) != want {
+			t.Errorf("dry run %v: binder = %q, want %q", dryRun, io.binder, want)
+		}
+	}
+}
+
+func TestSyncTitles_BinderToFile(t *testing.T) {
+	io := newSyncTitlesTestIO()
+	res, err := syncTitles(io, ops.SyncBinderToFile, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Changed || strings.Join(res.Files, ",") != "ch1.md,ch2.md" || !strings.Contains(res.Diff, "+++ b/ch2.md") {
+		t.Errorf("result = %+v", res)
+	}
+	if string(io.binder) != twoChildren {
+		t.Errorf("binder changed to %q", io.binder)
+	}
+	for path, want := range map[string]string{
+		"/proj/ch1.md": "---\nid: ch1\ntitle: Chapter One\n---\nBody.\n",
+		"/proj/ch2.md": "---\nid: ch2\ntitle: Chapter Two\n---\nBody.\n",
+	} {
+		if got := string(io.files[path]); got != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestSyncTitles_BinderToFile_RollsBack(t *testing.T) {
+	io := newSyncTitlesTestIO()
+	original := string(io.files["/proj/ch1.md"])
+	io.writeErrs = map[string]error{"/proj/ch2.md": errors.New("disk full")}
+	if _, err := syncTitles(io, ops.SyncBinderToFile, false); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("error = %v, want the write failure", err)
+	}
+	if got := string(io.files["/proj/ch1.md"]); got != original {
+		t.Errorf("ch1.md = %q, want it restored to %q", got, original)
+	}
+}
+
+func TestSyncTitles_Errors(t *testing.T) {
+	if _, err := syncTitles(newSyncTitlesTestIO(), "sideways", false); err == nil || !strings.Contains(err.Error(), "unknown direction") {
+		t.Errorf("error = %v, want unknown direction", err)
+	}
+	io := newSyncTitlesTestIO()
+	io.readErr = errors.New("gone")
+	if res, err := syncTitles(io, ops.SyncFileToBinder, false); res != nil || err == nil {
+		t.Errorf("result = %+v, error = %v; want a read failure", res, err)
+	}
+}
+
+func TestSyncTitles_EscapingTarget(t *testing.T) {
+	dir := escapingDir(t)
+	for _, direction := range []string{ops.SyncFileToBinder, ops.SyncBinderToFile} {
+		io := &fakeBinderIO{
+			binder: []byte("<!-- prosemark-binder:v1 -->\n- [Outside](out/outside.md)\n"),
+			files:  map[string][]byte{filepath.Join(dir, "out", "outside.md"): []byte("---\ntitle: Elsewhere\n---\n")},
+		}
+		params := binder.SyncTitlesParams{Direction: direction}
+		res, err := SyncTitles(context.Background(), io, filepath.Join(dir, "_binder.md"), params)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", direction, err)
+		}
+		last := res.Diagnostics[len(res.Diagnostics)-1]
+		if res.Changed || res.Files != nil || last.Code != ops.CodeTitleNotSynced || !strings.Contains(last.Message, safepath.ErrTraversal.Error()) {
+			t.Errorf("%s: result = %+v, want the title unreadable", direction, res)
+		}
+		if io.written != nil {
+			t.Errorf("%s: wrote %q", direction, io.written)
+		}
+	}
+}
+
+func TestSyncTitles_FileToBinder_Unchanged(t *testing.T) {
+	io := newSyncTitlesTestIO()
+	io.binder = []byte("<!-- prosemark-binder:v1 -->\n- [The Beginning](ch1.md)\n")
+	res, err := syncTitles(io, ops.SyncFileToBinder, false)
+	if err != nil || res.Changed || res.Diff != "" || io.written != nil {
+		t.Errorf("result = %+v, error = %v, wrote %q; want nothing to do", res, err, io.written)
+	}
+}
+
+func TestSyncTitles_FileToBinder_WriteError(t *testing.T) {
+	io := newSyncTitlesTestIO()
+	io.writeErr = errors.New("disk full")
+	if _, err := syncTitles(io, ops.SyncFileToBinder, false); err == nil || !strings.Contains(err.Error(), "writing binder: disk full") {
+		t.Errorf("error = %v, want the binder write failure", err)
+	}
+}
+
+func TestSyncTitles_UnreadableTitles(t *testing.T) {
+	io := newSyncTitlesTestIO()
+	io.files["/proj/ch1.md"] = []byte("---\ntitle: [unclosed\n---\n")
+	io.readErrs = map[string]error{"/proj/ch2.md": errors.New("denied")}
+	res, err := syncTitles(io, ops.SyncBinderToFile, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var warned []string
+	for _, d := range res.Diagnostics {
+		if d.Code == ops.CodeTitleNotSynced {
+			warned = append(warned, d.Message)
+		}
+	}
+	if len(warned) != 2 || !strings.Contains(warned[0], "ch1.md") || !strings.Contains(warned[1], "denied") || res.Files != nil {
+		t.Errorf("result = %+v, want both files reported unreadable", res)
+	}
+}
+
+func TestSyncTitles_BinderToFile_SkipsTitleThatCannotBeSet(t *testing.T) {
+	io := newSyncTitlesTestIO()
+	const aliased = "---\nid: ch2\ntitle: &t Old\nsubtitle: *t\n---\n"
+	io.files["/proj/ch2.md"] = []byte(aliased)
+	res, err := syncTitles(io, ops.SyncBinderToFile, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(res.Files, ",") != "ch1.md" || string(io.files["/proj/ch2.md"]) != aliased {
+		t.Errorf("files = %v, ch2.md = %q; want only ch1.md retitled", res.Files, io.files["/proj/ch2.md"])
+	}
+}
+
+func TestSyncTitles_ParseErrorsWriteNothing(t *testing.T) {
+	for _, direction := range []string{ops.SyncFileToBinder, ops.SyncBinderToFile} {
+		io := newSyncTitlesTestIO()
+		io.binder = []byte("<!-- prosemark-binder:v1 -->\n- [Bad](b:c.md)\n")
+		res, err := syncTitles(io, direction, false)
+		if err != nil || !hasError(res.Diagnostics) || io.written != nil {
+			t.Errorf("%s: result = %+v, error = %v; want error diagnostics and no writes", direction, res, err)
+		}
+	}
+}
+
+func TestSyncTitles_BinderToFile_BeginTxError(t *testing.T) {
+	io := newSyncTitlesTestIO()
+	io.txErr = errors.New("locked")
+	if _, err := syncTitles(io, ops.SyncBinderToFile, false); err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Errorf("error = %v, want the transaction failure", err)
+	}
+}
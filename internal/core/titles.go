@@ -0,0 +1,34 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+)
+
+// Titles rewrites the casing of entry titles in the binder at binderPath
+// (pmk titles). The result always carries the diff of the rewrite; with
+// params.DryRun the binder is not written.
+func Titles(ctx context.Context, io BinderIO, binderPath string, params binder.TitlesParams) (*binder.OpResult, error) {
+	src, proj, err := readProject(ctx, io, binderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	modified, entries, diags := ops.Titles(ctx, src, proj, params)
+	diags = binder.ApplySeverityOverrides(diags, proj.SeverityOverrides)
+	res := newOpResult(src, modified, entries, diags)
+	if hasError(diags) || !res.Changed {
+		return res, nil
+	}
+	res.Diff = BinderDiff(binderPath, src, modified)
+	if params.DryRun {
+		return res, nil
+	}
+	if err := io.WriteBinderAtomic(ctx, binderPath, modified); err != nil {
+		return res, fmt.Errorf("writing binder: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestTitles(t *testing.T) {
+	lower := "<!-- prosemark-binder:v1 -->\n- [chapter one](ch1.md)\n"
+	params := binder.TitlesParams{Case: "title"}
+	wantDiff := "--- a/_binder.md\n+++ b/_binder.md\n@@ -1,2 +1,2 @@\n <!-- prosemark-binder:v1 -->\n-- [chapter one](ch1.md)\n+- [Chapter One](ch1.md)\n"
+
+	io := &fakeBinderIO{binder: []byte(lower)}
+	res, err := Titles(context.Background(), io, binderPath, binder.TitlesParams{Case: "title", DryRun: true})
+	if err != nil || !res.Changed || res.Diff != wantDiff || len(res.Entries) != 1 {
+		t.Fatalf("dry run = %+v, %v", res, err)
+	}
+	if io.written != nil {
+		t.Errorf("dry run wrote the binder: %q", io.written)
+	}
+
+	res, err = Titles(context.Background(), io, binderPath, params)
+	if err != nil || res.Diff != wantDiff || string(io.binder) != oneChild {
+		t.Errorf("Titles = %+v, %v; binder = %q", res, err, io.binder)
+	}
+
+	res, err = Titles(context.Background(), io, binderPath, params)
+	if err != nil || res.Changed || res.Diff != "" {
+		t.Errorf("already title case: %+v, %v", res, err)
+	}
+}
+
+func TestTitles_Errors(t *testing.T) {
+	lower := []byte("<!-- prosemark-binder:v1 -->\n- [chapter one](ch1.md)\n")
+	failed := errors.New("failed")
+	tests := []struct {
+		name       string
+		io         *fakeBinderIO
+		selector   string
+		wantResult bool
+		wantErr    string
+	}{
+		{"read", &fakeBinderIO{readErr: failed}, "", false, "reading binder"},
+		{"no match", &fakeBinderIO{binder: lower}, "Missing", true, ""},
+		{"write", &fakeBinderIO{binder: lower, writeErr: failed}, "", true, "writing binder"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Titles(context.Background(), tt.io, binderPath, binder.TitlesParams{Case: "title", Selector: tt.selector})
+			if (res != nil) != tt.wantResult {
+				t.Errorf("result = %+v, want result %v", res, tt.wantResult)
+			}
+			if tt.wantErr == "" {
+				if err != nil || !hasError(res.Diagnostics) || res.Diff != "" {
+					t.Errorf("res = %+v, err = %v; want error diagnostics only", res, err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
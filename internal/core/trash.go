@@ -0,0 +1,311 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// TrashDir is the directory, relative to the project directory, holding
+// the files of deleted entries: each deletion's files sit under a
+// subdirectory named by its trash ID, at their project-relative paths.
+const TrashDir = ".prosemark/trash"
+
+// TrashManifestFilename is the trash manifest, relative to the project
+// directory.
+const TrashManifestFilename = ".prosemark/trash/manifest.json"
+
+// TrashManifest records the deletions whose files are in the trash, oldest
+// first.
+type TrashManifest struct {
+	Version string        `json:"version"`
+	Records []TrashRecord `json:"records"`
+}
+
+// TrashRecord is one deletion in the trash.
+type TrashRecord struct {
+	// ID names the deletion: its UTC time, suffixed when several deletions
+	// share a second.
+	ID       string `json:"id"`
+	Deleted  string `json:"deleted"`  // RFC 3339 time of the deletion
+	Selector string `json:"selector"` // the selector that was deleted
+	// Entries are the removed entries, in document order.
+	Entries []TrashedEntry `json:"entries"`
+	// Files are the project-relative slash paths of the trashed files.
+	Files []string `json:"files"`
+}
+
+// TrashedEntry is a removed entry and where it stood.
+type TrashedEntry struct {
+	// Parent is the target of the entry's parent, or "." for a top-level
+	// entry or one whose parent had no target.
+	Parent string `json:"parent"`
+	// ParentPath is the parent's child index path from the root: empty for
+	// a top-level entry.
+	ParentPath []int `json:"parentPath"`
+	// Index is the entry's position among its parent's children.
+	Index int `json:"index"`
+	SubtreeNode
+	// Source is the entry's subtree as it stood in the binder, which
+	// Restore puts back.
+	Source ops.Subtree `json:"source"`
+}
+
+// SubtreeNode is an entry of a subtree taken out of a binder. A placeholder
+// has no target.
+type SubtreeNode struct {
+	Target   string        `json:"target"`
+	Title    string        `json:"title"`
+	Checked  *bool         `json:"checked,omitempty"`
+	Children []SubtreeNode `json:"children,omitempty"`
+}
+
+// RestoreResult is the outcome of Restore.
+type RestoreResult struct {
+	binder.OpResult
+	// Files are the project-relative slash paths of the files moved back
+	// out of the trash.
+	Files []string
+}
+
+// ReadTrash returns the trash manifest of the project whose binder is at
+// binderPath; a missing manifest is an empty one.
+func ReadTrash(io DeleteFilesIO, binderPath string) (*TrashManifest, error) {
+	data, err := io.ReadNodeFile(trashManifestPath(binderPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return &TrashManifest{Version: "1", Records: []TrashRecord{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", TrashManifestFilename, err)
+	}
+	var m TrashManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", TrashManifestFilename, err)
+	}
+	if m.Records == nil {
+		m.Records = []TrashRecord{}
+	}
+	return &m, nil
+}
+
+func trashManifestPath(binderPath string) string {
+	return filepath.Join(filepath.Dir(binderPath), filepath.FromSlash(TrashManifestFilename))
+}
+
+// trashPath returns where the trash keeps the project file rel deleted
+// under id.
+func trashPath(binderPath, id, rel string) string {
+	return filepath.Join(filepath.Dir(binderPath), filepath.FromSlash(path.Join(TrashDir, id, rel)))
+}
+
+// MarshalTrash returns m's stored form.
+func MarshalTrash(m *TrashManifest) []byte {
+	// Plain strings and slices cannot fail to encode.
+	data, _ := json.MarshalIndent(m, "", "  ")
+	return append(data, '\n')
+}
+
+// newTrashRecord returns the record of deleting selector at now, which
+// removed the subtrees removed and the files. Its ID is unique in m.
+func newTrashRecord(m *TrashManifest, now, selector string, removed []ops.RemovedEntry, files []string) TrashRecord {
+	base := strings.NewReplacer("-", "", ":", "").Replace(now)
+	id := base
+	for n := 2; m.find(id) >= 0; n++ {
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+	rec := TrashRecord{ID: id, Deleted: now, Selector: selector, Entries: []TrashedEntry{}, Files: files}
+	for _, r := range removed {
+		parent := r.Parent.Target
+		if parent == "" {
+			parent = "."
+		}
+		rec.Entries = append(rec.Entries, TrashedEntry{Parent: parent, ParentPath: r.ParentPath, Index: r.Index, SubtreeNode: subtreeNode(r.Node), Source: r.Subtree})
+	}
+	return rec
+}
+
+// subtreeNode returns n's subtree.
+func subtreeNode(n *binder.Node) SubtreeNode {
+	t := SubtreeNode{Target: n.Target, Title: n.Title, Checked: n.Checked}
+	for _, c := range n.Children {
+		t.Children = append(t.Children, subtreeNode(c))
+	}
+	return t
+}
+
+// find returns the index of the record with id, or -1.
+func (m *TrashManifest) find(id string) int {
+	for i, r := range m.Records {
+		if r.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Restore brings the deletion id back out of the trash (pmk restore): its
+// entries are re-added to the binder where they stood, under the same
+// parent at the same position and as their lines read, and its files are
+// moved back. An entry whose parent is no longer in the binder is appended
+// at the top level, with a warning. The restored binder is checked with
+// the files back in the project. Restore refuses to overwrite a file that
+// has since been recreated.
+//
+// The files are written first, then the manifest without the record, then
+// the binder, and the trash copies are removed last, in one FileTx as in
+// Rename. A dry run checks the files but writes nothing.
+func Restore(ctx context.Context, io DeleteFilesIO, binderPath, id string, dryRun bool) (*RestoreResult, error) {
+	src, proj, err := readProject(ctx, io, binderPath)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := ReadTrash(io, binderPath)
+	if err != nil {
+		return nil, err
+	}
+	i := manifest.find(id)
+	if i < 0 {
+		return nil, fmt.Errorf("no deletion %q in the trash", id)
+	}
+	rec := manifest.Records[i]
+
+	restored := *proj
+	restored.Files = append(slices.Clone(proj.Files), rec.Files...)
+	modified, diags := restoreEntries(ctx, src, &restored, rec.Entries)
+	diags = binder.ApplySeverityOverrides(diags, proj.SeverityOverrides)
+	res := &RestoreResult{OpResult: *newOpResult(src, modified, nil, diags), Files: rec.Files}
+	if hasError(diags) {
+		return res, nil
+	}
+	res.Diff = BinderDiff(binderPath, src, modified)
+
+	dir := filepath.Dir(binderPath)
+	moves := make([]fileMove, 0, len(rec.Files))
+	for _, rel := range rec.Files {
+		m := fileMove{from: trashPath(binderPath, id, rel)}
+		if m.to, err = safepath.Resolve(dir, rel); err != nil {
+			return nil, err
+		}
+		if m.content, err = io.ReadNodeFile(m.from); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", m.from, err)
+		}
+		if _, err := io.ReadNodeFile(m.to); err == nil {
+			return nil, fmt.Errorf("%s already exists", m.to)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("checking %s: %w", m.to, err)
+		}
+		moves = append(moves, m)
+	}
+	if dryRun {
+		return res, nil
+	}
+
+	tx, err := beginTx(io, binderPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range moves {
+		tx.Create(m.to, m.content)
+	}
+	manifest.Records = append(manifest.Records[:i:i], manifest.Records[i+1:]...)
+	tx.Write(trashManifestPath(binderPath), MarshalTrash(manifest))
+	if res.Changed {
+		tx.WriteBinder(binderPath, modified)
+	}
+	for _, m := range moves {
+		tx.Remove(m.from)
+	}
+	return res, tx.Commit()
+}
+
+// restoreEntries re-adds entries to the binder src, returning the new
+// binder and the diagnostics, among them any error parsing the new binder
+// reports. On an error diagnostic src is returned.
+func restoreEntries(ctx context.Context, src []byte, proj *binder.Project, entries []TrashedEntry) ([]byte, []binder.Diagnostic) {
+	tx := ops.NewTransaction(ctx, src, proj)
+	var diags []binder.Diagnostic
+	for _, e := range entries {
+		params := ops.InsertSubtreeParams{ParentPath: []int{}, Position: "last", PreserveExtras: true, Subtree: e.Source}
+		if p, n, ok := restoreParent(ctx, tx.Bytes(), proj, e); ok {
+			at := min(e.Index, n)
+			params.ParentPath, params.At = p, &at
+		} else {
+			diags = append(diags, binder.Diagnostic{
+				Severity: "warning",
+				Code:     binder.CodeRestoredAtTopLevel,
+				Message:  fmt.Sprintf("parent %q of %q is no longer in the binder; restored at the top level", e.Parent, cmp.Or(e.Target, e.Title)),
+			})
+		}
+		_, d := tx.InsertSubtree(params)
+		diags = append(diags, d...)
+	}
+	if !tx.Failed() {
+		for _, d := range tx.ParseDiagnostics() {
+			if d.Severity == "error" {
+				diags = append(diags, d)
+			}
+		}
+	}
+	if hasError(diags) {
+		return src, diags
+	}
+	return tx.Bytes(), diags
+}
+
+// restoreParent returns the child index path in the binder src of the
+// parent e was removed from, and its number of children: the entry at
+// e.ParentPath when it still has the parent's target, or else the first
+// with that target. ok is false when there is none. A binder that cannot
+// be parsed gives e's own place, for the insertion to report the error.
+func restoreParent(ctx context.Context, src []byte, proj *binder.Project, e TrashedEntry) (p []int, n int, ok bool) {
+	result, _, err := binder.Parse(ctx, src, proj)
+	if err != nil {
+		return e.ParentPath, e.Index, true
+	}
+	if node := nodeAtPath(result.Root, e.ParentPath); node != nil && (node.Target == "" && e.Parent == "." || node.Target != "" && path.Clean(node.Target) == path.Clean(e.Parent)) {
+		return e.ParentPath, len(node.Children), true
+	}
+	if e.Parent == "." {
+		return []int{}, len(result.Root.Children), true
+	}
+	var walk func(parent *binder.Node, at []int) bool
+	walk = func(parent *binder.Node, at []int) bool {
+		for i, c := range parent.Children {
+			cp := append(slices.Clip(at), i)
+			if c.Target != "" && path.Clean(c.Target) == path.Clean(e.Parent) {
+				p, n, ok = cp, len(c.Children), true
+				return true
+			}
+			if walk(c, cp) {
+				return true
+			}
+		}
+		return false
+	}
+	walk(result.Root, []int{})
+	return p, n, ok
+}
+
+// nodeAtPath returns the entry at the child index path p below root, or nil
+// when there is none.
+func nodeAtPath(root *binder.Node, p []int) *binder.Node {
+	n := root
+	for _, i := range p {
+		if i < 0 || i >= len(n.Children) {
+			return nil
+		}
+		n = n.Children[i]
+	}
+	return n
+}
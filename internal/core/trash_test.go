@@ -0,0 +1,377 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// trashCh1 deletes ch1 into the trash and returns the deletion's trash ID.
+func trashCh1(t *testing.T, io deleteTestIO) string {
+	t.Helper()
+	res, err := deleteCh1(io, CascadeTrash, false)
+	if err != nil {
+		t.Fatalf("deleting: %v", err)
+	}
+	return res.TrashID
+}
+
+func TestDeleteWithFiles_TrashRecord(t *testing.T) {
+	io := newDeleteTestIO()
+	if id := trashCh1(t, io); id != "20260501T093000Z" {
+		t.Errorf("TrashID = %q", id)
+	}
+	m, err := ReadTrash(io, binderPath)
+	if err != nil {
+		t.Fatalf("ReadTrash: %v", err)
+	}
+	if len(m.Records) != 1 {
+		t.Fatalf("records = %+v, want one", m.Records)
+	}
+	rec := m.Records[0]
+	if rec.Deleted != deleteTestNow || rec.Selector != "ch1" || !slices.Equal(rec.Files, []string{"ch1.md", "ch1.notes.md", "ch3.md"}) {
+		t.Errorf("record = %+v", rec)
+	}
+	want := TrashedEntry{
+		Parent: ".", ParentPath: []int{}, Index: 0,
+		SubtreeNode: SubtreeNode{Target: "ch1.md", Title: "One", Children: []SubtreeNode{
+			{Target: "ch2.md", Title: "Two"},
+			{Target: "ch3.md", Title: "Three"},
+		}},
+		Source: ops.Subtree{Lines: []string{"- [One](ch1.md)", "  - [Two](ch2.md)", "  - [Three](ch3.md)"}},
+	}
+	if !reflect.DeepEqual(rec.Entries, []TrashedEntry{want}) {
+		t.Errorf("entries = %+v, want [%+v]", rec.Entries, want)
+	}
+
+	// A second deletion in the same second gets its own ID.
+	io.binder = []byte(deleteTestBinder)
+	io.files["/proj/ch1.md"] = []byte("Again.\n")
+	if id := trashCh1(t, io); id != "20260501T093000Z-2" {
+		t.Errorf("second TrashID = %q", id)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	io := newDeleteTestIO()
+	id := trashCh1(t, io)
+
+	res, err := Restore(context.Background(), io, binderPath, id, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Changed || !slices.Equal(res.Files, []string{"ch1.md", "ch1.notes.md", "ch3.md"}) {
+		t.Errorf("result = %+v", res)
+	}
+	if string(io.binder) != deleteTestBinder {
+		t.Errorf("binder = %q, want %q", io.binder, deleteTestBinder)
+	}
+	want := []string{"/proj/.prosemark/trash/manifest.json", "/proj/ch1.md", "/proj/ch1.notes.md", "/proj/ch2.md", "/proj/ch3.md"}
+	if got := fileNames(io); !slices.Equal(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+	if m, _ := ReadTrash(io, binderPath); len(m.Records) != 0 {
+		t.Errorf("records = %+v, want the restored one gone", m.Records)
+	}
+}
+
+func TestRestore_ParentGone(t *testing.T) {
+	io := newDeleteTestIO()
+	io.binder = []byte("<!-- prosemark-binder:v1 -->\n- [Part](part.md)\n  - [One](ch1.md)\n")
+	io.files["/proj/part.md"] = []byte("Part.\n")
+	id := trashCh1(t, io)
+	io.binder = []byte("<!-- prosemark-binder:v1 -->\n- [Other](other.md)\n")
+	io.files["/proj/other.md"] = []byte("Other.\n")
+
+	res, err := Restore(context.Background(), io, binderPath, id, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Diagnostics) != 1 || res.Diagnostics[0].Code != binder.CodeRestoredAtTopLevel {
+		t.Errorf("diagnostics = %+v, want PMKW006", res.Diagnostics)
+	}
+	if want := "<!-- prosemark-binder:v1 -->\n- [Other](other.md)\n- [One](ch1.md)\n"; string(io.binder) != want {
+		t.Errorf("binder = %q, want %q", io.binder, want)
+	}
+}
+
+// TestRestore_RoundTrip verifies that restoring a deletion gives back the
+// binder as it was: placeholders and what is under them, checkboxes, link
+// styles, and annotations, without warnings about the files still in the
+// trash.
+func TestRestore_RoundTrip(t *testing.T) {
+	io := newDeleteTestIO()
+	src := "<!-- prosemark-binder:v1 -->\n" +
+		"- [x] [One][c1] <!-- 900 words -->\n" +
+		"  - [Epilogue]()\n" +
+		"    - [[ch2|Two]]\n" +
+		"  - [ ] [Three](ch3.md)\n" +
+		"- [Two again](ch2.md)\n" +
+		"\n" +
+		"[c1]: ch1.md\n"
+	io.binder = []byte(src)
+	id := trashCh1(t, io)
+
+	res, err := Restore(context.Background(), io, binderPath, id, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Diagnostics) != 0 {
+		t.Errorf("diagnostics = %+v, want none", res.Diagnostics)
+	}
+	if string(io.binder) != src {
+		t.Errorf("binder = %q, want %q", io.binder, src)
+	}
+}
+
+// TestRestore_Parent verifies where an entry goes back when the binder has
+// changed since its deletion.
+func TestRestore_Parent(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string // the binder ch1 is deleted from
+		changed string // the binder it is restored into
+		want    string
+	}{
+		{
+			name:    "second of two entries with the parent's target",
+			src:     "- [Part](part.md)\n  - [Zero](ch0.md)\n- [Part again](part.md)\n  - [One](ch1.md)\n",
+			changed: "- [Part](part.md)\n  - [Zero](ch0.md)\n- [Part again](part.md)\n",
+			want:    "- [Part](part.md)\n  - [Zero](ch0.md)\n- [Part again](part.md)\n  - [One](ch1.md)\n",
+		},
+		{
+			name:    "parent moved",
+			src:     "- [Part](part.md)\n  - [One](ch1.md)\n",
+			changed: "- [Zero](ch0.md)\n- [Book](book.md)\n  - [Part](part.md)\n",
+			want:    "- [Zero](ch0.md)\n- [Book](book.md)\n  - [Part](part.md)\n    - [One](ch1.md)\n",
+		},
+		{
+			name:    "placeholder parent",
+			src:     "- [Later]()\n  - [One](ch1.md)\n",
+			changed: "- [Later]()\n",
+			want:    "- [Later]()\n  - [One](ch1.md)\n",
+		},
+		{
+			name:    "placeholder parent replaced",
+			src:     "- [Later]()\n  - [One](ch1.md)\n",
+			changed: "- [Zero](ch0.md)\n",
+			want:    "- [One](ch1.md)\n- [Zero](ch0.md)\n",
+		},
+		{
+			name:    "placeholder parent gone",
+			src:     "- [Later]()\n  - [One](ch1.md)\n",
+			changed: "",
+			want:    "\n- [One](ch1.md)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			io := newDeleteTestIO()
+			io.binder = []byte("<!-- prosemark-binder:v1 -->\n" + tt.src)
+			for _, f := range []string{"part", "book", "ch0"} {
+				io.files["/proj/"+f+".md"] = []byte(f + "\n")
+			}
+			id := trashCh1(t, io)
+			io.binder = []byte("<!-- prosemark-binder:v1 -->\n" + tt.changed)
+
+			res, err := Restore(context.Background(), io, binderPath, id, false)
+			if err != nil || hasError(res.Diagnostics) {
+				t.Fatalf("result = %+v, err = %v", res, err)
+			}
+			if want := "<!-- prosemark-binder:v1 -->\n" + tt.want; string(io.binder) != want {
+				t.Errorf("binder = %q, want %q", io.binder, want)
+			}
+		})
+	}
+}
+
+func TestRestore_UnderParent(t *testing.T) {
+	io := newDeleteTestIO()
+	src := "<!-- prosemark-binder:v1 -->\n- [Book](book.md)\n  - [Part](part.md)\n    - [Zero](ch0.md)\n    - [One](ch1.md)\n    - [Four](ch4.md)\n"
+	io.binder = []byte(src)
+	for _, f := range []string{"book", "part", "ch0", "ch4"} {
+		io.files["/proj/"+f+".md"] = []byte(f + "\n")
+	}
+	id := trashCh1(t, io)
+
+	if _, err := Restore(context.Background(), io, binderPath, id, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(io.binder) != src {
+		t.Errorf("binder = %q, want %q", io.binder, src)
+	}
+}
+
+func TestRestore_Unrestorable(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(io deleteTestIO)
+	}{
+		{"binder not UTF-8", func(io deleteTestIO) { io.binder = []byte("\xff") }},
+		{"invalid child target", func(io deleteTestIO) {
+			io.files[trashManifest] = []byte(strings.Replace(string(io.files[trashManifest]), `(ch2.md)`, `(../ch2.md)`, 1))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			io := newDeleteTestIO()
+			id := trashCh1(t, io)
+			tt.setup(io)
+			binderBefore := string(io.binder)
+
+			res, err := Restore(context.Background(), io, binderPath, id, false)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !hasError(res.Diagnostics) || res.Changed {
+				t.Errorf("result = %+v, want an error diagnostic and no change", res)
+			}
+			if string(io.binder) != binderBefore {
+				t.Errorf("binder = %q, want it untouched", io.binder)
+			}
+		})
+	}
+}
+
+func TestDeleteWithFiles_TrashKeepsPlaceholders(t *testing.T) {
+	io := newDeleteTestIO()
+	io.binder = []byte("<!-- prosemark-binder:v1 -->\n- [Draft]()\n- [One](ch1.md)\n")
+	params := binder.DeleteParams{Selector: "Draft", Yes: true}
+	res, err := DeleteWithFiles(context.Background(), io, binderPath, params, CascadeTrash, deleteTestNow)
+	if err != nil || !res.Changed {
+		t.Fatalf("result = %+v, err = %v; want the placeholder deleted", res, err)
+	}
+	m, err := ReadTrash(io, binderPath)
+	if err != nil || len(m.Records) != 1 || len(m.Records[0].Entries) != 1 || m.Records[0].Entries[0].Title != "Draft" {
+		t.Fatalf("manifest = %+v, %v; want one record of the placeholder", m, err)
+	}
+	if _, err := Restore(context.Background(), io, binderPath, m.Records[0].ID, false); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if want := "<!-- prosemark-binder:v1 -->\n- [Draft]()\n- [One](ch1.md)\n"; string(io.binder) != want {
+		t.Errorf("binder = %q, want %q", io.binder, want)
+	}
+}
+
+func TestReadTrash_NoRecords(t *testing.T) {
+	io := newDeleteTestIO()
+	io.files[trashManifest] = []byte(`{"version":"1"}`)
+	m, err := ReadTrash(io, binderPath)
+	if err != nil || m.Records == nil || len(m.Records) != 0 {
+		t.Errorf("ReadTrash = %+v, %v; want an empty, non-nil record list", m, err)
+	}
+}
+
+func TestRestore_DryRun(t *testing.T) {
+	io := newDeleteTestIO()
+	id := trashCh1(t, io)
+	before := fileNames(io)
+	binderBefore := string(io.binder)
+
+	res, err := Restore(context.Background(), io, binderPath, id, true)
+	if err != nil || res.Diff == "" {
+		t.Fatalf("result = %+v, err = %v; want the diff", res, err)
+	}
+	if string(io.binder) != binderBefore || !slices.Equal(fileNames(io), before) {
+		t.Errorf("dry run wrote: binder %q, files %v", io.binder, fileNames(io))
+	}
+}
+
+func TestRestore_Errors(t *testing.T) {
+	failed := errors.New("failed")
+	tests := []struct {
+		name       string
+		id         string
+		setup      func(io deleteTestIO)
+		wantResult bool
+		wantErr    string
+	}{
+		{name: "unknown id", id: "nope", setup: func(deleteTestIO) {}, wantErr: `no deletion "nope" in the trash`},
+		{name: "binder unreadable", setup: func(io deleteTestIO) { io.readErr = failed }, wantErr: "reading binder: failed"},
+		{name: "manifest unreadable", setup: func(io deleteTestIO) { io.readErrs = map[string]error{trashManifest: failed} }, wantErr: "reading .prosemark/trash/manifest.json: failed"},
+		{name: "manifest undecodable", setup: func(io deleteTestIO) { io.files[trashManifest] = []byte("{") }, wantErr: "reading .prosemark/trash/manifest.json"},
+		{
+			name:    "file recreated",
+			setup:   func(io deleteTestIO) { io.files["/proj/ch3.md"] = []byte("New.\n") },
+			wantErr: "/proj/ch3.md already exists",
+		},
+		{
+			name: "file escapes the project",
+			setup: func(io deleteTestIO) {
+				// Files come after Entries, so the last ch3.md is the trashed file's.
+				m := string(io.files[trashManifest])
+				i := strings.LastIndex(m, `"ch3.md"`)
+				io.files[trashManifest] = []byte(m[:i] + `"../ch3.md"` + m[i+len(`"ch3.md"`):])
+			},
+			wantErr: safepath.ErrTraversal.Error(),
+		},
+		{
+			name:    "file uncheckable",
+			setup:   func(io deleteTestIO) { io.readErrs = map[string]error{"/proj/ch3.md": failed} },
+			wantErr: "checking /proj/ch3.md: failed",
+		},
+		{
+			name:    "trash copy missing",
+			setup:   func(io deleteTestIO) { delete(io.files, trashedDir+"ch3.md") },
+			wantErr: "reading " + trashedDir + "ch3.md",
+		},
+		{
+			name:       "file write",
+			setup:      func(io deleteTestIO) { io.writeErrs = map[string]error{"/proj/ch3.md": failed} },
+			wantResult: true,
+			wantErr:    "creating /proj/ch3.md: failed",
+		},
+		{
+			name:       "manifest write",
+			setup:      func(io deleteTestIO) { io.writeErrs = map[string]error{trashManifest: failed} },
+			wantResult: true,
+			wantErr:    "writing " + trashManifest + ": failed",
+		},
+		{
+			name:       "binder write",
+			setup:      func(io deleteTestIO) { io.writeErr = failed },
+			wantResult: true,
+			wantErr:    "writing binder: failed",
+		},
+		{
+			name:       "trash removal",
+			setup:      func(io deleteTestIO) { io.deleteErrs = map[string]error{trashedDir + "ch3.md": failed} },
+			wantResult: true,
+			wantErr:    "removing " + trashedDir + "ch3.md: failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			io := newDeleteTestIO()
+			id := trashCh1(t, io)
+			if tt.id != "" {
+				id = tt.id
+			}
+			tt.setup(io)
+			before := fileNames(io)
+			binderBefore := string(io.binder)
+			manifestBefore := string(io.files[trashManifest])
+
+			res, err := Restore(context.Background(), io, binderPath, id, false)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+			if (res != nil) != tt.wantResult {
+				t.Errorf("result = %+v, want result %v", res, tt.wantResult)
+			}
+			if got := fileNames(io); !slices.Equal(got, before) {
+				t.Errorf("files = %v, want %v", got, before)
+			}
+			if string(io.binder) != binderBefore || string(io.files[trashManifest]) != manifestBefore {
+				t.Errorf("binder = %q, manifest = %q; want both rolled back", io.binder, io.files[trashManifest])
+			}
+		})
+	}
+}
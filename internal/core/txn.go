@@ -0,0 +1,36 @@
+package core
+
+import "path/filepath"
+
+// FileTx is a file transaction: writes and removals of project files that
+// land together or not at all. Steps are staged in order and applied by
+// Commit, which undoes the steps before a failing one and returns its
+// error, noting any failure to undo them. A step that cannot be staged is
+// held and returned by Commit, which then applies nothing.
+type FileTx interface {
+	// Write stages writing data to path, creating its directory if needed.
+	Write(path string, data []byte)
+	// Create is Write for a file that must not exist yet.
+	Create(path string, data []byte)
+	// WriteBinder stages writing binder data to path.
+	WriteBinder(path string, data []byte)
+	// Remove stages removing the file at path.
+	Remove(path string)
+	Commit() error
+	// Abort discards the staged steps.
+	Abort() error
+}
+
+// TxIO is implemented by the IOs of operations that write several files
+// at once. pmk journals its transactions on disk, so a transaction that a
+// crash interrupts is finished or rolled back before the project is next
+// read.
+type TxIO interface {
+	// BeginTx starts a file transaction in the project at projectDir.
+	BeginTx(projectDir string) (FileTx, error)
+}
+
+// beginTx starts a file transaction in the project of binderPath.
+func beginTx(io TxIO, binderPath string) (FileTx, error) {
+	return io.BeginTx(filepath.Dir(binderPath))
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// stubTxIO is a fakeBinderIO that records the project its transaction
+// began in.
+type stubTxIO struct {
+	*fakeBinderIO
+	began string
+}
+
+func (s *stubTxIO) BeginTx(projectDir string) (FileTx, error) {
+	s.began = projectDir
+	return s.fakeBinderIO.BeginTx(projectDir)
+}
+
+func TestBeginTx(t *testing.T) {
+	io := &stubTxIO{fakeBinderIO: &fakeBinderIO{}}
+	if _, err := beginTx(io, binderPath); err != nil {
+		t.Fatal(err)
+	}
+	if io.began != "/proj" {
+		t.Errorf("BeginTx(%q), want /proj", io.began)
+	}
+}
+
+func TestOps_BeginTxError(t *testing.T) {
+	ctx := context.Background()
+	failed := errors.New("no transactions")
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{"add new", func() error {
+			_, err := addNewNode(&fakeBinderIO{binder: []byte(oneChild), txErr: failed}, ".", node.Frontmatter{Title: "New"})
+			return err
+		}},
+		{"materialize", func() error {
+			io := &fakeBinderIO{binder: []byte(oneChild + "- [Interlude]()\n"), txErr: failed}
+			_, err := Materialize(ctx, io, binderPath, binder.MaterializeParams{Selector: "Interlude", Target: "interlude.md"}, newNodeNow)
+			return err
+		}},
+		{"rename", func() error {
+			io := newRenameTestIO()
+			io.txErr = failed
+			_, err := renameCh1(io, "new.md")
+			return err
+		}},
+		{"delete", func() error {
+			io := newDeleteTestIO()
+			io.txErr = failed
+			_, err := deleteCh1(io, CascadeFiles, false)
+			return err
+		}},
+		{"restore", func() error {
+			io := newDeleteTestIO()
+			id := trashCh1(t, io)
+			io.txErr = failed
+			_, err := Restore(ctx, io, binderPath, id, false)
+			return err
+		}},
+		{"move to project", func() error {
+			io := newMoveTestIO()
+			io.txErr = failed
+			_, err := moveCh1(io, false)
+			return err
+		}},
+		{"sync titles", func() error {
+			io := newSyncTitlesTestIO()
+			io.txErr = failed
+			_, err := syncTitles(io, ops.SyncBinderToFile, false)
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); !errors.Is(err, failed) {
+				t.Errorf("err = %v, want the BeginTx failure", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+// Package demo holds the example project pmk demo writes out: a short story
+// in two parts, with its binder, node files, a notes file, and a project
+// config, so a first run has something to try commands on.
+package demo
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed all:example
+var example embed.FS
+
+// Title is the title of the example project's manuscript.
+const Title = "The Lighthouse Keeper"
+
+// Project returns the files of the example project, rooted at the project
+// directory.
+func Project() (fs.FS, error) {
+	return fs.Sub(example, "example")
+}
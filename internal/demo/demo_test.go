@@ -0,0 +1,74 @@
+package demo_test
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/demo"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+func TestProject(t *testing.T) {
+	project, err := demo.Project()
+	if err != nil {
+		t.Fatalf("Project() error = %v", err)
+	}
+	var files []string
+	err = fs.WalkDir(project, ".", func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && path.Ext(p) == ".md" && p != binder.DefaultBinderFilename {
+			files = append(files, p)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Stat(project, node.ConfigFilename); err != nil {
+		t.Errorf("config: %v", err)
+	}
+
+	src, err := fs.ReadFile(project, binder.DefaultBinderFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), "# "+demo.Title+"\n") {
+		t.Errorf("binder does not carry the title %q", demo.Title)
+	}
+	result, diags, err := binder.Parse(context.Background(), src, &binder.Project{Files: files, BinderDir: "."})
+	if err != nil || len(diags) != 0 {
+		t.Fatalf("Parse() diagnostics = %+v, err = %v; want a clean binder", diags, err)
+	}
+
+	var walk func(n *binder.Node)
+	walk = func(n *binder.Node) {
+		for _, c := range n.Children {
+			if c.Target != "" {
+				content, err := fs.ReadFile(project, c.Target)
+				if err != nil {
+					t.Fatalf("node file: %v", err)
+				}
+				fm, body, err := node.ParseFrontmatter(content)
+				if err != nil {
+					t.Fatalf("%s: %v", c.Target, err)
+				}
+				if fm.Title != c.Title {
+					t.Errorf("%s: title %q, binder says %q", c.Target, fm.Title, c.Title)
+				}
+				if audits := node.ValidateNode(strings.TrimSuffix(c.Target, ".md"), fm, body); len(audits) != 0 {
+					t.Errorf("%s: %+v", c.Target, audits)
+				}
+				files = slices.DeleteFunc(files, func(f string) bool { return f == c.Target })
+			}
+			walk(c)
+		}
+	}
+	walk(result.Root)
+	if len(files) != 1 || !strings.HasSuffix(files[0], ".notes.md") {
+		t.Errorf("files outside the binder = %v, want only the notes file", files)
+	}
+}
@@ -0,0 +1,284 @@
+// Package diff renders line-based unified diffs, as printed by diff -u and
+// git diff, for previewing binder rewrites.
+package diff
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ContextLines is the number of unchanged lines shown around each change.
+const ContextLines = 3
+
+// edit is one line of an edit script: an unchanged line (' '), a line
+// deleted from a ('-'), or a line inserted from b ('+'). a and b index the
+// line in the respective input.
+type edit struct {
+	kind byte
+	a, b int
+}
+
+// Unified returns the unified diff turning a into b, labelled with fromName
+// and toName, or "" when they are equal.
+func Unified(fromName, toName string, a, b []byte) string {
+	if string(a) == string(b) {
+		return ""
+	}
+	al, bl := splitLines(a), splitLines(b)
+	edits := editScript(al, bl)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+	for start := 0; start < len(edits); {
+		first := slices.IndexFunc(edits[start:], func(e edit) bool { return e.kind != ' ' })
+		if first < 0 {
+			break
+		}
+		first += start
+		last := first
+		for i := first + 1; i < len(edits) && i-last <= 2*ContextLines+1; i++ {
+			if edits[i].kind != ' ' {
+				last = i
+			}
+		}
+		lo, hi := max(first-ContextLines, start), min(last+1+ContextLines, len(edits))
+		writeHunk(&sb, edits[lo:hi], al, bl, lineBefore(edits, lo))
+		start = hi
+	}
+	return sb.String()
+}
+
+// lineBefore returns how many lines of a and of b precede edits[i].
+func lineBefore(edits []edit, i int) [2]int {
+	var n [2]int
+	for _, e := range edits[:i] {
+		if e.kind != '+' {
+			n[0]++
+		}
+		if e.kind != '-' {
+			n[1]++
+		}
+	}
+	return n
+}
+
+// writeHunk writes one hunk: its header and its lines.
+func writeHunk(sb *strings.Builder, hunk []edit, a, b []string, before [2]int) {
+	aLen, bLen := 0, 0
+	for _, e := range hunk {
+		if e.kind != '+' {
+			aLen++
+		}
+		if e.kind != '-' {
+			bLen++
+		}
+	}
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(before[0], aLen), hunkRange(before[1], bLen))
+	for _, e := range hunk {
+		var line string
+		if e.kind == '+' {
+			line = b[e.b]
+		} else {
+			line = a[e.a]
+		}
+		sb.WriteByte(e.kind)
+		sb.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats a hunk header range: the 1-based first line and the
+// line count, omitted when it is 1. An empty range names the line before it.
+func hunkRange(before, n int) string {
+	switch n {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, n)
+}
+
+// splitLines splits s into lines that keep their line endings.
+func splitLines(s []byte) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(s), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// editScript returns a shortest edit script from a to b, using the
+// linear-space refinement of Myers' O(ND) algorithm: rather than keeping a
+// frontier for every edit distance to backtrack through, it finds the
+// middle snake of an optimal path and recurses on either side of it, so
+// memory stays O(N+M) however much a and b differ. Within each run of
+// changes the deletions come before the insertions, as diff -u prints a
+// replaced region.
+func editScript(a, b []string) []edit {
+	size := 2*((len(a)+len(b)+1)/2+1) + 1
+	s := &script{a: a, b: b, vf: make([]int, size), vb: make([]int, size)}
+	s.compare(0, len(a), 0, len(b))
+	for i := 0; i < len(s.edits); {
+		j := i
+		for j < len(s.edits) && s.edits[j].kind != ' ' {
+			j++
+		}
+		slices.SortStableFunc(s.edits[i:j], func(x, y edit) int {
+			return cmp.Compare(changeOrder(x.kind), changeOrder(y.kind))
+		})
+		i = j + 1
+	}
+	return s.edits
+}
+
+// changeOrder ranks deletions before insertions.
+func changeOrder(kind byte) int {
+	if kind == '-' {
+		return 0
+	}
+	return 1
+}
+
+// script builds the edit script turning a into b. vf and vb hold the
+// forward and backward frontiers of the middle snake search, reused across
+// the recursion.
+type script struct {
+	a, b   []string
+	vf, vb []int
+	edits  []edit
+}
+
+// compare appends the edit script turning a[a0:a1] into b[b0:b1].
+func (s *script) compare(a0, a1, b0, b1 int) {
+	for a0 < a1 && b0 < b1 && s.a[a0] == s.b[b0] {
+		s.edits = append(s.edits, edit{kind: ' ', a: a0, b: b0})
+		a0, b0 = a0+1, b0+1
+	}
+	endA := a1
+	for a1 > a0 && b1 > b0 && s.a[a1-1] == s.b[b1-1] {
+		a1, b1 = a1-1, b1-1
+	}
+	switch {
+	case a0 == a1:
+		for y := b0; y < b1; y++ {
+			s.edits = append(s.edits, edit{kind: '+', a: a0, b: y})
+		}
+	case b0 == b1:
+		for x := a0; x < a1; x++ {
+			s.edits = append(s.edits, edit{kind: '-', a: x, b: b0})
+		}
+	default:
+		x, y, u, v := s.middleSnake(a0, a1, b0, b1)
+		s.compare(a0, x, b0, y)
+		for ; x < u; x, y = x+1, y+1 {
+			s.edits = append(s.edits, edit{kind: ' ', a: x, b: y})
+		}
+		s.compare(u, a1, v, b1)
+	}
+	for ; a1 < endA; a1, b1 = a1+1, b1+1 {
+		s.edits = append(s.edits, edit{kind: ' ', a: a1, b: b1})
+	}
+}
+
+// middleSnake returns the middle snake of a shortest edit script turning
+// a[a0:a1] into b[b0:b1], which differ in their first and last lines: the
+// run of matching lines from (x, y) to (u, v) where the forward search from
+// the start and the backward search from the end overlap. Both halves of
+// the script around it are shorter than the whole.
+func (s *script) middleSnake(a0, a1, b0, b1 int) (x, y, u, v int) {
+	n, m := a1-a0, b1-b0
+	delta := n - m
+	odd := delta%2 != 0
+	off := (n+m+1)/2 + 1
+	vf, vb := s.vf, s.vb
+	vf[off+1], vb[off+1] = 0, 0
+	for d := 0; ; d++ {
+		for k := -d; k <= d; k += 2 {
+			px := vf[off+k-1] + 1
+			if k == -d || (k != d && vf[off+k-1] < vf[off+k+1]) {
+				px = vf[off+k+1]
+			}
+			py := px - k
+			sx, sy := px, py
+			for px < n && py < m && s.a[a0+px] == s.b[b0+py] {
+				px, py = px+1, py+1
+			}
+			vf[off+k] = px
+			if odd && delta-k >= -(d-1) && delta-k <= d-1 && px+vb[off+delta-k] >= n {
+				return a0 + sx, b0 + sy, a0 + px, b0 + py
+			}
+		}
+		for k := -d; k <= d; k += 2 {
+			px := vb[off+k-1] + 1
+			if k == -d || (k != d && vb[off+k-1] < vb[off+k+1]) {
+				px = vb[off+k+1]
+			}
+			py := px - k
+			sx, sy := px, py
+			for px < n && py < m && s.a[a1-1-px] == s.b[b1-1-py] {
+				px, py = px+1, py+1
+			}
+			vb[off+k] = px
+			if !odd && delta-k >= -d && delta-k <= d && px+vf[off+delta-k] >= n {
+				return a1 - px, b1 - py, a1 - sx, b1 - sy
+			}
+		}
+	}
+}
+
+// Comparison summarizes how one text differs from another line by line.
+type Comparison struct {
+	// Content reports whether a line with non-blank content was inserted or
+	// deleted.
+	Content bool
+	// BlankLines reports whether blank lines were inserted or deleted.
+	BlankLines bool
+	// LineEndings reports whether a line present in both texts ends
+	// differently, such as a final line gaining its missing line ending.
+	LineEndings bool
+}
+
+// Compare matches the lines of a and b regardless of how they end and
+// reports which kinds of change turn a into b.
+func Compare(a, b []byte) Comparison {
+	al, bl := splitLines(a), splitLines(b)
+	strip := func(lines []string) []string {
+		out := make([]string, len(lines))
+		for i, l := range lines {
+			out[i] = strings.TrimRight(l, "\r\n")
+		}
+		return out
+	}
+	as, bs := strip(al), strip(bl)
+	var c Comparison
+	for _, e := range editScript(as, bs) {
+		switch e.kind {
+		case ' ':
+			if al[e.a] != bl[e.b] {
+				c.LineEndings = true
+			}
+		case '-':
+			c.noteLine(as[e.a])
+		case '+':
+			c.noteLine(bs[e.b])
+		}
+	}
+	return c
+}
+
+// noteLine records the insertion or deletion of line.
+func (c *Comparison) noteLine(line string) {
+	if strings.TrimSpace(line) == "" {
+		c.BlankLines = true
+	} else {
+		c.Content = true
+	}
+}
@@ -0,0 +1,189 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// numbered returns lines "1\n" … "n\n", with the lines in replace swapped
+// for their values.
+func numbered(n int, replace map[int]string) []byte {
+	var sb strings.Builder
+	for i := 1; i <= n; i++ {
+		if r, ok := replace[i]; ok {
+			sb.WriteString(r + "\n")
+			continue
+		}
+		fmt.Fprintf(&sb, "%d\n", i)
+	}
+	return []byte(sb.String())
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []byte
+		want string
+	}{
+		{"equal", []byte("a\nb\n"), []byte("a\nb\n"), ""},
+		{
+			"changed line",
+			[]byte("a\nb\nc\n"), []byte("a\nB\nc\n"),
+			"--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{"from empty", nil, []byte("x\n"), "--- old\n+++ new\n@@ -0,0 +1 @@\n+x\n"},
+		{"to empty", []byte("x\ny\n"), nil, "--- old\n+++ new\n@@ -1,2 +0,0 @@\n-x\n-y\n"},
+		{
+			"missing final newline",
+			[]byte("a\n"), []byte("a\nb"),
+			"--- old\n+++ new\n@@ -1 +1,2 @@\n a\n+b\n\\ No newline at end of file\n",
+		},
+		{
+			"separate hunks",
+			numbered(20, nil), numbered(20, map[int]string{1: "x", 9: "y"}),
+			"--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n@@ -6,7 +6,7 @@\n 6\n 7\n 8\n-9\n+y\n 10\n 11\n 12\n",
+		},
+		{
+			"hunks within twice the context merge",
+			numbered(9, nil), numbered(9, map[int]string{1: "x", 8: "y"}),
+			"--- old\n+++ new\n@@ -1,9 +1,9 @@\n-1\n+x\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+y\n 9\n",
+		},
+		{
+			"deletions before insertions",
+			[]byte("1\n2\n3\n"), []byte("4\n5\n"),
+			"--- old\n+++ new\n@@ -1,3 +1,2 @@\n-1\n-2\n-3\n+4\n+5\n",
+		},
+		{
+			"deletions before insertions around a kept line",
+			[]byte("a\nb\nc\nd\n"), []byte("x\nb\ny\n"),
+			"--- old\n+++ new\n@@ -1,4 +1,3 @@\n-a\n+x\n b\n-c\n-d\n+y\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("old", "new", tt.a, tt.b); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditScript_IsShortest(t *testing.T) {
+	a := strings.Split("a b c a b b a", " ")
+	b := strings.Split("c b a b a c", " ")
+	edits := editScript(a, b)
+	changes := 0
+	var gotA, gotB []string
+	for _, e := range edits {
+		if e.kind != ' ' {
+			changes++
+		}
+		if e.kind != '+' {
+			gotA = append(gotA, a[e.a])
+		}
+		if e.kind != '-' {
+			gotB = append(gotB, b[e.b])
+		}
+	}
+	if changes != 5 {
+		t.Errorf("edit script has %d changes, want 5: %v", changes, edits)
+	}
+	if strings.Join(gotA, " ") != strings.Join(a, " ") || strings.Join(gotB, " ") != strings.Join(b, " ") {
+		t.Errorf("edit script does not rebuild the inputs: %v", edits)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want Comparison
+	}{
+		{"equal", "- [A](a.md)\n", "- [A](a.md)\n", Comparison{}},
+		{"inserted entry", "- [A](a.md)\n", "- [A](a.md)\n- [B](b.md)\n", Comparison{Content: true}},
+		{"final line ending added", "- [A](a.md)", "- [A](a.md)\n- [B](b.md)", Comparison{Content: true, LineEndings: true}},
+		{"blank lines collapsed", "- [A](a.md)\n\n\n- [B](b.md)\n", "- [A](a.md)\n\n- [B](b.md)\n", Comparison{BlankLines: true}},
+		{"whitespace-only line removed", "- [A](a.md)\n  \n", "- [A](a.md)\n", Comparison{BlankLines: true}},
+		{"crlf to lf", "- [A](a.md)\r\n", "- [A](a.md)\n", Comparison{LineEndings: true}},
+		{"reindented", "- [A](a.md)\n- [B](b.md)\n", "- [A](a.md)\n  - [B](b.md)\n", Comparison{Content: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compare([]byte(tt.a), []byte(tt.b)); got != tt.want {
+				t.Errorf("Compare() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// lcsLen returns the length of the longest common subsequence of a and b.
+func lcsLen(a, b []string) int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(cur[j], prev[j+1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func TestEditScript_MatchesLCS(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := range 500 {
+		gen := func() []string {
+			lines := make([]string, rng.IntN(12))
+			for j := range lines {
+				lines[j] = string(rune('a' + rng.IntN(3)))
+			}
+			return lines
+		}
+		a, b := gen(), gen()
+		edits := editScript(a, b)
+		var gotA, gotB []string
+		changes := 0
+		for _, e := range edits {
+			if e.kind != ' ' {
+				changes++
+			}
+			if e.kind != '+' {
+				gotA = append(gotA, a[e.a])
+			}
+			if e.kind != '-' {
+				gotB = append(gotB, b[e.b])
+			}
+		}
+		if want := len(a) + len(b) - 2*lcsLen(a, b); changes != want {
+			t.Fatalf("case %d: %v -> %v: %d changes, want %d", i, a, b, changes, want)
+		}
+		if strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+			t.Fatalf("case %d: edit script %v does not rebuild %v -> %v", i, edits, a, b)
+		}
+	}
+}
+
+func TestUnified_LargeRewriteUsesLinearMemory(t *testing.T) {
+	const n = 5000
+	var a, b strings.Builder
+	for i := range n {
+		fmt.Fprintf(&a, "- [Chapter %d](old-%d.md)\n", i, i)
+		fmt.Fprintf(&b, "- [Chapter %d](new-%d.md)\n", i, i)
+	}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	out := Unified("old", "new", []byte(a.String()), []byte(b.String()))
+	runtime.ReadMemStats(&after)
+	if got := strings.Count(out, "\n-- ["); got != n {
+		t.Errorf("diff deletes %d lines, want %d", got, n)
+	}
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 64<<20 {
+		t.Errorf("diffing %d changed lines allocated %d MiB, want under 64", n, alloc>>20)
+	}
+}
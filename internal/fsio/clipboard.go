@@ -0,0 +1,63 @@
+package fsio
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ClipboardCommands lists the commands that copy their stdin to the system
+// clipboard on goos, most preferred first. On Linux and the BSDs, wl-copy
+// leads under Wayland (WAYLAND_DISPLAY set, read with getenv).
+func ClipboardCommands(goos string, getenv func(string) string) [][]string {
+	switch goos {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip"}}
+	}
+	x11 := [][]string{{"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}}
+	if getenv("WAYLAND_DISPLAY") != "" {
+		return append([][]string{{"wl-copy"}}, x11...)
+	}
+	return append(x11, []string{"wl-copy"})
+}
+
+// CopyToClipboard puts data on the system clipboard with the first of
+// ClipboardCommands installed.
+func CopyToClipboard(data []byte) error {
+	cmds := ClipboardCommands(runtime.GOOS, os.Getenv)
+	var names []string
+	for _, argv := range cmds {
+		path, err := exec.LookPath(argv[0])
+		if err != nil {
+			names = append(names, argv[0])
+			continue
+		}
+		c := exec.Command(path, argv[1:]...)
+		c.Stdin = bytes.NewReader(data)
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			return fmt.Errorf("%s: %w", argv[0], err)
+		}
+		return nil
+	}
+	return fmt.Errorf("no clipboard command found: install %s", strings.Join(names, " or "))
+}
+
+// Page shows data in pager with the terminal attached, as OpenEditor runs
+// an editor: pager is split on whitespace, so values like "less -R" work.
+func Page(pager string, data []byte) error {
+	parts := strings.Fields(pager)
+	if len(parts) == 0 {
+		return fmt.Errorf("PAGER is empty")
+	}
+	c := exec.Command(parts[0], parts[1:]...)
+	c.Stdin = bytes.NewReader(data)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Run()
+}
@@ -0,0 +1,112 @@
+package fsio_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+func TestClipboardCommands(t *testing.T) {
+	noEnv := func(string) string { return "" }
+	wayland := func(k string) string {
+		if k == "WAYLAND_DISPLAY" {
+			return "wayland-0"
+		}
+		return ""
+	}
+	tests := []struct {
+		goos   string
+		getenv func(string) string
+		want   []string // first command of each candidate
+	}{
+		{"darwin", noEnv, []string{"pbcopy"}},
+		{"windows", noEnv, []string{"clip"}},
+		{"linux", noEnv, []string{"xclip", "xsel", "wl-copy"}},
+		{"linux", wayland, []string{"wl-copy", "xclip", "xsel"}},
+		{"freebsd", noEnv, []string{"xclip", "xsel", "wl-copy"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, argv := range fsio.ClipboardCommands(tt.goos, tt.getenv) {
+			got = append(got, argv[0])
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ClipboardCommands(%s) = %v, want %v", tt.goos, got, tt.want)
+		}
+	}
+}
+
+// catInto returns a shell command that copies stdin into file, resolving cat
+// before fakeCommand narrows PATH.
+func catInto(t *testing.T, file string) string {
+	t.Helper()
+	cat, err := exec.LookPath("cat")
+	if err != nil {
+		t.Skip("cat not available")
+	}
+	return cat + ` > "` + file + `"`
+}
+
+// fakeCommand puts an executable name on a PATH of its own that runs script.
+func fakeCommand(t *testing.T, name, script string) {
+	t.Helper()
+	bin := t.TempDir()
+	writeFile(t, filepath.Join(bin, name), "#!/bin/sh\n"+script+"\n")
+	if err := os.Chmod(filepath.Join(bin, name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+}
+
+func TestCopyToClipboard(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("fakes a Linux clipboard command")
+	}
+	t.Setenv("WAYLAND_DISPLAY", "")
+	got := filepath.Join(t.TempDir(), "clipboard")
+	fakeCommand(t, "xsel", catInto(t, got))
+
+	if err := fsio.CopyToClipboard([]byte("It was a dark night.\n")); err != nil {
+		t.Fatalf("CopyToClipboard: %v", err)
+	}
+	if data, _ := os.ReadFile(got); string(data) != "It was a dark night.\n" {
+		t.Errorf("clipboard = %q", data)
+	}
+}
+
+func TestCopyToClipboard_Errors(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("fakes a Linux clipboard command")
+	}
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("PATH", t.TempDir())
+	if err := fsio.CopyToClipboard(nil); err == nil || !strings.Contains(err.Error(), "install xclip or xsel or wl-copy") {
+		t.Errorf("with no clipboard command: err = %v", err)
+	}
+
+	fakeCommand(t, "xclip", "exit 1")
+	if err := fsio.CopyToClipboard(nil); err == nil || !strings.Contains(err.Error(), "xclip") {
+		t.Errorf("with a failing clipboard command: err = %v", err)
+	}
+}
+
+func TestPage(t *testing.T) {
+	got := filepath.Join(t.TempDir(), "paged")
+	fakeCommand(t, "fakepager", catInto(t, got))
+
+	if err := fsio.Page("   ", nil); err == nil {
+		t.Error("expected error for whitespace-only pager")
+	}
+	if err := fsio.Page("fakepager -R", []byte("Chapter three.\n")); err != nil {
+		t.Fatalf("Page: %v", err)
+	}
+	if data, _ := os.ReadFile(got); string(data) != "Chapter three.\n" {
+		t.Errorf("paged = %q", data)
+	}
+}
@@ -0,0 +1,479 @@
+// Package fsio implements the filesystem operations shared by pmk commands:
+// size-limited binder reads, atomic writes, project scanning, and editor
+// launching. Commands wrap these in their own IO interfaces so command logic
+// can be tested with in-memory doubles; this package is the single OS-backed
+// implementation behind them.
+package fsio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// MaxBinderSize is the largest binder file ReadBinder accepts (10 MB).
+const MaxBinderSize = 10 * 1024 * 1024
+
+// ReadBinder reads the binder file at path, rejecting files larger than
+// MaxBinderSize. OS errors are returned unwrapped so callers can test them
+// with errors.Is(err, os.ErrNotExist).
+func ReadBinder(path string) ([]byte, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if fi.Size() > MaxBinderSize {
+		return nil, fmt.Errorf("binder file exceeds the 10 MB size limit")
+	}
+	return os.ReadFile(path)
+}
+
+// ReadFile reads the file at path.
+func ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+// ReadFileIfExists reads the file at path. A missing file is not an error:
+// it is reported as exists=false with nil content.
+func ReadFileIfExists(path string) (content []byte, exists bool, err error) {
+	content, err = os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return content, true, nil
+}
+
+// StatFile reports whether a file exists at path. It returns an error only
+// for unexpected OS errors, not for a missing file.
+func StatFile(path string) (bool, error) {
+	_, err := os.Stat(path)
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// IsDir reports whether path names an existing directory.
+func IsDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// FindBinder returns the path of the file called name in dir or in the
+// nearest ancestor of dir that has one. dir should be absolute so that the
+// search can climb past it.
+func FindBinder(dir, name string) (string, error) {
+	path, err := findBinder(dir, func(string) (string, error) { return name, nil })
+	if path == "" && err == nil {
+		err = fmt.Errorf("no %s in %s or any parent directory", name, dir)
+	}
+	return path, err
+}
+
+// FindProjectBinder returns the path of the binder of the project in dir or
+// in the nearest ancestor of dir that has one, where each directory's binder
+// is the one its config names (see BinderFilename). dir should be absolute
+// so that the search can climb past it.
+func FindProjectBinder(dir string) (string, error) {
+	path, err := findBinder(dir, BinderFilename)
+	if path == "" && err == nil {
+		err = fmt.Errorf("no binder in %s or any parent directory", dir)
+	}
+	return path, err
+}
+
+// findBinder climbs from dir looking for the file name returns for each
+// directory. It returns "" and no error when no directory has one.
+func findBinder(dir string, name func(dir string) (string, error)) (string, error) {
+	for d := filepath.Clean(dir); ; {
+		n, err := name(d)
+		if err != nil {
+			return "", err
+		}
+		path := filepath.Join(d, n)
+		exists, err := StatFile(path)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return path, nil
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return "", nil
+		}
+		d = parent
+	}
+}
+
+// WriteFileAtomic writes data to path via a temp file in the same directory
+// and a rename, so readers never observe a partial file. tmpPrefix names the
+// temp file (e.g. ".binder") to make stray temp files recognisable. The
+// written file has 0600 permissions.
+func WriteFileAtomic(path, tmpPrefix string, data []byte) error {
+	tmpName, err := stageTempFileImpl(filepath.Dir(path), tmpPrefix, data)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("renaming temp file: %w", err)
+	}
+	return nil
+}
+
+// stageTempFileImpl writes data to a new 0600 temp file in dir and returns
+// its name; on failure the temp file is removed. Its failure paths need OS
+// fault injection, so it is excluded from coverage.
+func stageTempFileImpl(dir, tmpPrefix string, data []byte) (string, error) {
+	tmp, err := os.CreateTemp(dir, tmpPrefix+"-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("creating temp file: %w", err)
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpName)
+		return "", fmt.Errorf("writing temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return "", fmt.Errorf("closing temp file: %w", err)
+	}
+	if err := os.Chmod(tmpName, 0600); err != nil {
+		_ = os.Remove(tmpName)
+		return "", fmt.Errorf("setting permissions: %w", err)
+	}
+	return tmpName, nil
+}
+
+// WriteFileAtomicMkdir is WriteFileAtomic after creating path's parent
+// directories.
+func WriteFileAtomicMkdir(path, tmpPrefix string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	return WriteFileAtomic(path, tmpPrefix, data)
+}
+
+// RenderPDF converts the Markdown document markdown to a PDF at path with
+// pandoc, titled title, creating parent directories. The PDF is written to
+// a temporary file in the same directory first and renamed into place.
+func RenderPDF(path string, markdown []byte, title string) error {
+	pandoc, err := exec.LookPath("pandoc")
+	if err != nil {
+		return fmt.Errorf("PDF output needs pandoc on PATH: %w", err)
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	tmp := filepath.Join(dir, ".export-"+filepath.Base(path)+".tmp.pdf")
+	c := exec.Command(pandoc, "--from", "markdown", "--metadata", "title="+title, "--output", tmp)
+	c.Stdin = bytes.NewReader(markdown)
+	if out, err := c.CombinedOutput(); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("pandoc: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("renaming temp file: %w", err)
+	}
+	return nil
+}
+
+// ReplaceExecutable atomically replaces the executable at path with data,
+// keeping the file's permissions. The running process is unaffected; the new
+// binary is used from the next invocation.
+func ReplaceExecutable(path string, data []byte) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return replaceFileImpl(path, fi.Mode().Perm(), data)
+}
+
+// replaceFileImpl atomically replaces the file at path with data, giving it
+// perm. Its failure paths need OS fault injection, so it is excluded from
+// coverage.
+func replaceFileImpl(path string, perm os.FileMode, data []byte) error {
+	tmpName, err := stageTempFileImpl(filepath.Dir(path), ".pmk", data)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("setting permissions: %w", err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("renaming temp file: %w", err)
+	}
+	return nil
+}
+
+// CheckWritable returns an error if a file exists at path and is read-only.
+// A missing file is writable.
+func CheckWritable(path string) error {
+	if fi, err := os.Stat(path); err == nil && fi.Mode().Perm()&0200 == 0 {
+		return fmt.Errorf("binder file is read-only")
+	}
+	return nil
+}
+
+// WriteBinder writes binder data to path atomically, refusing to replace a
+// read-only binder.
+func WriteBinder(path string, data []byte) error {
+	if err := CheckWritable(path); err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, ".binder", data)
+}
+
+// CreateExclusive creates an empty file at path, failing if it already exists.
+func CreateExclusive(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// DeleteFile removes the file at path.
+func DeleteFile(path string) error {
+	return os.Remove(path)
+}
+
+// RemoveDir removes the directory at path and everything under it; a
+// missing directory is not an error.
+func RemoveDir(path string) error {
+	return os.RemoveAll(path)
+}
+
+// EditorCommand returns the command to open files in the project in dir
+// with: $EDITOR, or the editor set in the project config when $EDITOR is
+// unset or blank. It is "" when neither is set, and fails when the config
+// it falls back to is unreadable or invalid.
+func EditorCommand(dir string) (string, error) {
+	if editor := os.Getenv("EDITOR"); strings.TrimSpace(editor) != "" {
+		return editor, nil
+	}
+	settings, err := readProjectConfig(dir)
+	if err != nil {
+		return "", err
+	}
+	return settings.Editor, nil
+}
+
+// ConfigError reports a project config that cannot be read or is invalid.
+type ConfigError struct {
+	// Dir is the project directory.
+	Dir string
+	Err error
+}
+
+func (e *ConfigError) Error() string { return e.Err.Error() }
+
+func (e *ConfigError) Unwrap() error { return e.Err }
+
+// readProjectConfig reads and parses the config of the project in dir. A
+// project without a config has the zero settings; an unreadable or invalid
+// one is a *ConfigError.
+func readProjectConfig(dir string) (node.ProjectConfig, error) {
+	path := node.ConfigPath(dir)
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return node.ProjectConfig{}, &ConfigError{Dir: dir, Err: fmt.Errorf("reading %s: %w", filepath.Base(path), err)}
+	}
+	settings, err := node.ParseProjectConfig(content)
+	if err != nil {
+		return node.ProjectConfig{}, &ConfigError{Dir: dir, Err: fmt.Errorf("%s: %w", filepath.Base(path), err)}
+	}
+	return settings, nil
+}
+
+// BinderFilename returns the filename of the default binder of the project
+// in dir: the binder named in its config, else binder.DefaultBinderFilename.
+// An unreadable or invalid config is an error rather than a fallback, since
+// the binder it names cannot be known.
+func BinderFilename(dir string) (string, error) {
+	settings, err := readProjectConfig(dir)
+	if err != nil {
+		return "", err
+	}
+	return settings.BinderName(), nil
+}
+
+// OpenEditor runs editor on path with the terminal attached. editor is split
+// on whitespace: the first field is the executable and the rest are passed as
+// arguments before path, so values like "code --wait" work.
+func OpenEditor(editor, path string) error {
+	parts := strings.Fields(editor)
+	if len(parts) == 0 {
+		return fmt.Errorf("EDITOR is empty")
+	}
+	c := exec.Command(parts[0], append(parts[1:], path)...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+// ListFiles returns the names of regular files in dir (non-recursive) for
+// which match returns true.
+func ListFiles(dir string, match func(name string) bool) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && match(e.Name()) {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+// tempFileRE matches the names WriteFileAtomic gives its temp files
+// (tmpPrefix + "-" + random digits + ".tmp").
+var tempFileRE = regexp.MustCompile(`^\..+-[0-9]+\.tmp$`)
+
+// TempFile is a temp file left behind by an interrupted WriteFileAtomic.
+type TempFile struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// FindTempFiles returns the WriteFileAtomic temp files under root, in walk
+// order. The .git directory is not searched.
+func FindTempFiles(root string) ([]TempFile, error) {
+	var found []TempFile
+	err := filepath.Walk(root, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Mode().IsRegular() && tempFileRE.MatchString(info.Name()) {
+			found = append(found, TempFile{Path: path, Size: info.Size(), ModTime: info.ModTime()})
+		}
+		return nil
+	})
+	return found, err
+}
+
+// ScanProject walks the directory containing binderPath recursively,
+// collecting all .md files (excluding the binder itself) into a
+// *binder.Project. The project's other binders, named or given by path in
+// .prosemark.yml, and files in subdirectories sharing a binder's filename
+// (nested binders) are recorded in AltBinders rather than Files. The
+// .prosemark bookkeeping directory, which holds the trash, is skipped.
+// Frontmatter aliases declared by project files are collected into Aliases;
+// unreadable files simply contribute none. The wikilink resolution mode,
+// reference sections, node ID scheme, bookmarks, and diagnostic severity
+// overrides come from the project's .prosemark.yml; a missing config leaves
+// the defaults, and an invalid one fails the scan.
+func ScanProject(_ context.Context, binderPath string) (*binder.Project, error) {
+	dir := filepath.Dir(binderPath)
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".prosemark" && path != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path) // WalkDir only visits paths under dir, so Rel cannot fail
+		paths = append(paths, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	settings, err := readProjectConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+	read := func(rel string) []byte {
+		content, _ := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		return content
+	}
+	return newProject(filepath.Base(binderPath), paths, settings, read), nil
+}
+
+// newProject builds the Project for the binder named binderName from the
+// project-relative slash paths of the files in its directory tree and the
+// project's settings, reading node files' aliases through read.
+func newProject(binderName string, paths []string, settings node.ProjectConfig, read func(rel string) []byte) *binder.Project {
+	binderNames := append(settings.BinderNames(), binderName)
+	var files, altBinders []string
+	aliases := make(map[string][]string)
+	for _, rel := range paths {
+		if !strings.HasSuffix(rel, ".md") || rel == binderName {
+			continue
+		}
+		if slices.Contains(binderNames, path.Base(rel)) || slices.Contains(binderNames, rel) {
+			altBinders = append(altBinders, rel)
+			continue
+		}
+		files = append(files, rel)
+		if a := node.FrontmatterAliases(read(rel)); len(a) > 0 {
+			aliases[rel] = a
+		}
+	}
+	if files == nil {
+		files = []string{}
+	}
+	if len(aliases) == 0 {
+		aliases = nil
+	}
+	var limits *binder.ParseLimits
+	if settings.Limits != (binder.ParseLimits{}) {
+		limits = &settings.Limits
+	}
+	return &binder.Project{
+		Files:              files,
+		BinderDir:          ".",
+		BinderFile:         binderName,
+		AltBinders:         altBinders,
+		Aliases:            aliases,
+		WikilinkResolution: settings.WikilinkResolution,
+		ReferenceSections:  settings.ReferenceSections,
+		Limits:             limits,
+		IDScheme:           settings.IDScheme,
+		TextPlaceholders:   settings.TextPlaceholders,
+		SubBinders:         settings.SubBinders,
+		Bookmarks:          settings.Bookmarks,
+		SeverityOverrides:  settings.SeverityOverrides,
+		ListMarker:         settings.ListMarker,
+		IndentWidth:        settings.IndentWidth,
+	}
+}
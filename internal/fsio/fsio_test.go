@@ -0,0 +1,480 @@
+package fsio_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadBinder(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "_binder.md")
+
+	if _, err := fsio.ReadBinder(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing binder: err = %v, want os.ErrNotExist", err)
+	}
+
+	writeFile(t, path, "<!-- prosemark-binder:v1 -->\n")
+	if got, err := fsio.ReadBinder(path); err != nil || string(got) != "<!-- prosemark-binder:v1 -->\n" {
+		t.Errorf("ReadBinder = %q, %v", got, err)
+	}
+
+	if err := os.Truncate(path, fsio.MaxBinderSize+1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fsio.ReadBinder(path); err == nil || !strings.Contains(err.Error(), "10 MB") {
+		t.Errorf("oversized binder: err = %v, want size limit error", err)
+	}
+}
+
+func TestReadFileIfExistsAndStatFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.md")
+
+	if content, exists, err := fsio.ReadFileIfExists(path); content != nil || exists || err != nil {
+		t.Errorf("missing: got %q, %v, %v; want nil, false, nil", content, exists, err)
+	}
+	if exists, err := fsio.StatFile(path); exists || err != nil {
+		t.Errorf("StatFile missing: got %v, %v", exists, err)
+	}
+
+	writeFile(t, path, "hi")
+	if content, exists, err := fsio.ReadFileIfExists(path); string(content) != "hi" || !exists || err != nil {
+		t.Errorf("present: got %q, %v, %v", content, exists, err)
+	}
+	if content, err := fsio.ReadFile(path); string(content) != "hi" || err != nil {
+		t.Errorf("ReadFile: got %q, %v", content, err)
+	}
+	if exists, err := fsio.StatFile(path); !exists || err != nil {
+		t.Errorf("StatFile present: got %v, %v", exists, err)
+	}
+
+	// Errors other than "not found" are reported.
+	if _, _, err := fsio.ReadFileIfExists(dir); err == nil {
+		t.Error("ReadFileIfExists on a directory: expected error")
+	}
+	if _, err := fsio.StatFile(filepath.Join(path, "child")); err == nil {
+		t.Error("StatFile below a regular file: expected error")
+	}
+}
+
+func TestFindBinder(t *testing.T) {
+	dir := t.TempDir()
+	book := filepath.Join(dir, "book")
+	writeFile(t, filepath.Join(book, "_binder.md"), "")
+	writeFile(t, filepath.Join(book, "part", "scenes", "a.md"), "")
+
+	if !fsio.IsDir(book) || fsio.IsDir(filepath.Join(book, "_binder.md")) || fsio.IsDir(filepath.Join(dir, "missing")) {
+		t.Error("IsDir: want true only for the directory")
+	}
+	for _, start := range []string{book, filepath.Join(book, "part", "scenes")} {
+		if got, err := fsio.FindBinder(start, "_binder.md"); err != nil || got != filepath.Join(book, "_binder.md") {
+			t.Errorf("FindBinder(%s) = %q, %v", start, got, err)
+		}
+	}
+	if _, err := fsio.FindBinder(dir, "_no-such-binder.md"); err == nil || !strings.Contains(err.Error(), "any parent directory") {
+		t.Errorf("FindBinder without a binder: err = %v", err)
+	}
+	if _, err := fsio.FindBinder(filepath.Join(book, "part", "scenes", "a.md"), "_binder.md"); err == nil {
+		t.Error("FindBinder below a regular file: expected error")
+	}
+}
+
+func TestFindProjectBinder(t *testing.T) {
+	dir := t.TempDir()
+	book := filepath.Join(dir, "book")
+	writeFile(t, filepath.Join(book, ".prosemark.yml"), "binder: outline.md\n")
+	writeFile(t, filepath.Join(book, "outline.md"), "")
+	writeFile(t, filepath.Join(book, "part", "a.md"), "")
+
+	if got, err := fsio.FindProjectBinder(filepath.Join(book, "part")); err != nil || got != filepath.Join(book, "outline.md") {
+		t.Errorf("FindProjectBinder = %q, %v; want the configured binder", got, err)
+	}
+	if _, err := fsio.FindProjectBinder(filepath.Join(dir, "empty")); err == nil || !strings.Contains(err.Error(), "no binder in") {
+		t.Errorf("without a binder: err = %v", err)
+	}
+	writeFile(t, filepath.Join(book, ".prosemark.yml"), "id_scheme: bogus\n")
+	var cfgErr *fsio.ConfigError
+	if _, err := fsio.FindProjectBinder(filepath.Join(book, "part")); !errors.As(err, &cfgErr) || cfgErr.Dir != book {
+		t.Errorf("invalid config: err = %v, want a *ConfigError for %s", err, book)
+	}
+	if _, err := fsio.FindProjectBinder(filepath.Join(book, "part", "a.md")); err == nil {
+		t.Error("below a regular file: expected error")
+	}
+}
+
+func TestReplaceExecutable(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pmk")
+	writeFile(t, path, "old")
+	if err := os.Chmod(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fsio.ReplaceExecutable(path, []byte("new")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, _ := os.ReadFile(path)
+	if string(got) != "new" {
+		t.Errorf("content = %q, want %q", got, "new")
+	}
+	fi, _ := os.Stat(path)
+	if fi.Mode().Perm() != 0o755 {
+		t.Errorf("mode = %v, want 0755", fi.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("temp files left behind: %v", entries)
+	}
+
+	if err := fsio.ReplaceExecutable(filepath.Join(dir, "missing"), nil); err == nil {
+		t.Error("expected error replacing a missing executable")
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.md")
+	writeFile(t, path, "old")
+
+	if err := fsio.WriteFileAtomic(path, ".node", []byte("new")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, _ := os.ReadFile(path)
+	if string(got) != "new" {
+		t.Errorf("content = %q, want %q", got, "new")
+	}
+	fi, _ := os.Stat(path)
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", fi.Mode().Perm())
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("temp files left behind: %v", entries)
+	}
+
+	if err := fsio.WriteFileAtomic(filepath.Join(dir, "missing", "a.md"), ".node", nil); err == nil {
+		t.Error("expected error writing into a missing directory")
+	}
+	if err := fsio.WriteFileAtomic(dir, ".node", []byte("x")); err == nil {
+		t.Error("expected error renaming over a directory")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("temp file not cleaned up after failed rename: %v", entries)
+	}
+
+	nested := filepath.Join(dir, "out", "deep", "a.txt")
+	if err := fsio.WriteFileAtomicMkdir(nested, ".export", []byte("x")); err != nil {
+		t.Errorf("WriteFileAtomicMkdir: %v", err)
+	}
+	if err := fsio.WriteFileAtomicMkdir(filepath.Join(path, "sub", "a.txt"), ".export", nil); err == nil {
+		t.Error("expected error creating a directory below a regular file")
+	}
+}
+
+func TestWriteBinder_RejectsReadOnlyFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "_binder.md")
+	writeFile(t, path, "original")
+	if err := os.Chmod(path, 0400); err != nil {
+		t.Fatal(err)
+	}
+
+	err := fsio.WriteBinder(path, []byte("changed"))
+	if err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("err = %v, want read-only error", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "original" {
+		t.Errorf("read-only binder was modified: %q", got)
+	}
+	if err := fsio.WriteBinder(filepath.Join(dir, "new.md"), []byte("x")); err != nil {
+		t.Errorf("new binder: %v", err)
+	}
+}
+
+func TestCreateExclusiveAndDeleteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.notes.md")
+	if err := fsio.CreateExclusive(path); err != nil {
+		t.Fatalf("first create: %v", err)
+	}
+	if err := fsio.CreateExclusive(path); !errors.Is(err, os.ErrExist) {
+		t.Errorf("second create: err = %v, want os.ErrExist", err)
+	}
+	if err := fsio.DeleteFile(path); err != nil {
+		t.Errorf("DeleteFile: %v", err)
+	}
+	if err := fsio.DeleteFile(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("DeleteFile missing: err = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestRemoveDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "trash")
+	writeFile(t, filepath.Join(dir, "part", "a.md"), "draft")
+	for range 2 {
+		if err := fsio.RemoveDir(dir); err != nil {
+			t.Errorf("RemoveDir: %v", err)
+		}
+	}
+	if _, err := os.Stat(dir); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Stat after RemoveDir: err = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestOpenEditor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.md")
+	writeFile(t, path, "draft")
+
+	if err := fsio.OpenEditor("   ", path); err == nil {
+		t.Error("expected error for whitespace-only editor")
+	}
+	if err := fsio.OpenEditor("true --extra-arg", path); err != nil {
+		t.Errorf("multi-word editor: %v", err)
+	}
+}
+
+func TestBinderFilename(t *testing.T) {
+	dir := t.TempDir()
+	if got, err := fsio.BinderFilename(dir); got != "_binder.md" || err != nil {
+		t.Errorf("without a config: BinderFilename = %q, %v", got, err)
+	}
+	writeFile(t, filepath.Join(dir, ".prosemark.yml"), "binder: draft.md\n")
+	if got, err := fsio.BinderFilename(dir); got != "draft.md" || err != nil {
+		t.Errorf("from config: BinderFilename = %q, %v; want draft.md", got, err)
+	}
+	writeFile(t, filepath.Join(dir, ".prosemark.yml"), "binder: draft.md\nid_scheme: bogus\n")
+	if got, err := fsio.BinderFilename(dir); err == nil || got != "" || !strings.Contains(err.Error(), `.prosemark.yml: unknown id_scheme "bogus"`) {
+		t.Errorf("invalid config: BinderFilename = %q, %v; want the config error, not a fallback", got, err)
+	}
+	if _, err := fsio.BinderFilename(dir); errors.Unwrap(err) == nil {
+		t.Errorf("invalid config: err = %v, want it to wrap the parse error", err)
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("EDITOR", "")
+	if got, err := fsio.EditorCommand(dir); got != "" || err != nil {
+		t.Errorf("with no editor anywhere: EditorCommand = %q, %v", got, err)
+	}
+
+	writeFile(t, filepath.Join(dir, ".prosemark.yml"), "editor: code --wait\n")
+	if got, err := fsio.EditorCommand(dir); got != "code --wait" || err != nil {
+		t.Errorf("from config: EditorCommand = %q, %v; want %q", got, err, "code --wait")
+	}
+
+	writeFile(t, filepath.Join(dir, ".prosemark.yml"), "editor: code --wait\nid_scheme: bogus\n")
+	if _, err := fsio.EditorCommand(dir); err == nil || !strings.Contains(err.Error(), ".prosemark.yml") {
+		t.Errorf("invalid config: err = %v, want the config error", err)
+	}
+
+	t.Setenv("EDITOR", "vi")
+	if got, err := fsio.EditorCommand(dir); got != "vi" || err != nil {
+		t.Errorf("$EDITOR should win over the config: EditorCommand = %q, %v", got, err)
+	}
+}
+
+// fakePandoc puts a pandoc on PATH that runs script with its arguments.
+func fakePandoc(t *testing.T, script string) {
+	t.Helper()
+	bin := t.TempDir()
+	writeFile(t, filepath.Join(bin, "pandoc"), "#!/bin/sh\n"+script+"\n")
+	if err := os.Chmod(filepath.Join(bin, "pandoc"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+}
+
+func TestRenderPDF(t *testing.T) {
+	cat, err := exec.LookPath("cat")
+	if err != nil {
+		t.Skip("cat not installed")
+	}
+	// copyToOutput copies stdin to the --output argument, the last.
+	copyToOutput := `for a; do out="$a"; done; ` + cat + ` > "$out"`
+	path := filepath.Join(t.TempDir(), "out", "01-one.pdf")
+
+	fakePandoc(t, copyToOutput)
+	if err := fsio.RenderPDF(path, []byte("# One\n"), "One"); err != nil {
+		t.Fatalf("RenderPDF: %v", err)
+	}
+	if got, err := os.ReadFile(path); err != nil || string(got) != "# One\n" {
+		t.Errorf("PDF = %q, %v", got, err)
+	}
+
+	fakePandoc(t, `echo "no LaTeX" >&2; exit 43`)
+	if err := fsio.RenderPDF(path, nil, "One"); err == nil || !strings.Contains(err.Error(), "pandoc: exit status 43: no LaTeX") {
+		t.Errorf("failing pandoc: err = %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("files = %v, want the temp file removed", entries)
+	}
+
+	fakePandoc(t, copyToOutput)
+	occupied := filepath.Join(t.TempDir(), "occupied.pdf")
+	writeFile(t, filepath.Join(occupied, "x"), "")
+	if err := fsio.RenderPDF(occupied, nil, "One"); err == nil || !strings.Contains(err.Error(), "renaming temp file") {
+		t.Errorf("rename failure: err = %v", err)
+	}
+
+	blocker := filepath.Join(t.TempDir(), "file")
+	writeFile(t, blocker, "")
+	if err := fsio.RenderPDF(filepath.Join(blocker, "a.pdf"), nil, "A"); err == nil || !strings.Contains(err.Error(), "creating directory") {
+		t.Errorf("unwritable directory: err = %v", err)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if err := fsio.RenderPDF(path, nil, "One"); err == nil || !strings.Contains(err.Error(), "PDF output needs pandoc on PATH") {
+		t.Errorf("no pandoc: err = %v", err)
+	}
+}
+
+func TestListFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.md"), "")
+	writeFile(t, filepath.Join(dir, "b.txt"), "")
+	writeFile(t, filepath.Join(dir, "sub", "c.md"), "")
+
+	got, err := fsio.ListFiles(dir, func(name string) bool { return strings.HasSuffix(name, ".md") })
+	if err != nil || !reflect.DeepEqual(got, []string{"a.md"}) {
+		t.Errorf("ListFiles = %v, %v; want [a.md]", got, err)
+	}
+	if _, err := fsio.ListFiles(filepath.Join(dir, "missing"), nil); err == nil {
+		t.Error("expected error for missing directory")
+	}
+}
+
+func TestFindTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".binder-123.tmp"), "partial")
+	writeFile(t, filepath.Join(dir, "part", ".node-456.tmp"), "")
+	writeFile(t, filepath.Join(dir, "notes.tmp"), "")
+	writeFile(t, filepath.Join(dir, ".git", ".pack-1.tmp"), "")
+	if err := os.Mkdir(filepath.Join(dir, ".dir-7.tmp"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := fsio.FindTempFiles(dir)
+	if err != nil {
+		t.Fatalf("FindTempFiles: %v", err)
+	}
+	var names []string
+	for _, f := range got {
+		rel, _ := filepath.Rel(dir, f.Path)
+		names = append(names, filepath.ToSlash(rel))
+	}
+	if !reflect.DeepEqual(names, []string{".binder-123.tmp", "part/.node-456.tmp"}) {
+		t.Errorf("FindTempFiles = %v", names)
+	}
+	if got[0].Size != 7 || got[0].ModTime.IsZero() {
+		t.Errorf("first temp file = %+v, want size 7 and a mod time", got[0])
+	}
+	if _, err := fsio.FindTempFiles(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected error for missing directory")
+	}
+}
+
+func TestScanProject(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"_binder.md", "a.md", "notes.txt", "part/b.md", "part/_binder.md", ".prosemark/trash/x/c.md"} {
+		writeFile(t, filepath.Join(dir, f), "")
+	}
+	writeFile(t, filepath.Join(dir, "part/b.md"), "---\naliases: [Bee]\n---\n")
+
+	proj, err := fsio.ScanProject(context.Background(), filepath.Join(dir, "_binder.md"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sort.Strings(proj.Files)
+	if !reflect.DeepEqual(proj.Files, []string{"a.md", "part/b.md"}) {
+		t.Errorf("Files = %v", proj.Files)
+	}
+	if !reflect.DeepEqual(proj.AltBinders, []string{"part/_binder.md"}) {
+		t.Errorf("AltBinders = %v", proj.AltBinders)
+	}
+	if !reflect.DeepEqual(proj.Aliases, map[string][]string{"part/b.md": {"Bee"}}) {
+		t.Errorf("Aliases = %v", proj.Aliases)
+	}
+	if proj.WikilinkResolution != "" || proj.Limits != nil {
+		t.Errorf("WikilinkResolution/Limits = %q/%v, want defaults", proj.WikilinkResolution, proj.Limits)
+	}
+	if proj.BinderDir != "." || proj.BinderFile != "_binder.md" {
+		t.Errorf("BinderDir/BinderFile = %q/%q", proj.BinderDir, proj.BinderFile)
+	}
+
+	writeFile(t, filepath.Join(dir, ".prosemark.yml"), "wikilinks:\n  resolution: shortest\nreference_sections: [See also]\nlimits:\n  max_nodes: 50\nid_scheme: date\ntext_placeholders: true\nsub_binders: true\nbookmarks:\n  bee: part/b.md\n")
+	proj, _ = fsio.ScanProject(context.Background(), filepath.Join(dir, "_binder.md"))
+	if proj.WikilinkResolution != "shortest" || !reflect.DeepEqual(proj.ReferenceSections, []string{"See also"}) {
+		t.Errorf("settings = %q/%v, want shortest and [See also] from .prosemark.yml", proj.WikilinkResolution, proj.ReferenceSections)
+	}
+	if proj.Limits == nil || proj.Limits.MaxNodes != 50 {
+		t.Errorf("Limits = %+v, want max_nodes 50 from .prosemark.yml", proj.Limits)
+	}
+	if proj.IDScheme != "date" || !proj.TextPlaceholders || !proj.SubBinders {
+		t.Errorf("IDScheme/TextPlaceholders/SubBinders = %q/%v/%v, want date, true, and true from .prosemark.yml", proj.IDScheme, proj.TextPlaceholders, proj.SubBinders)
+	}
+	if !reflect.DeepEqual(proj.Bookmarks, map[string]string{"bee": "part/b.md"}) {
+		t.Errorf("Bookmarks = %v, want bee from .prosemark.yml", proj.Bookmarks)
+	}
+
+	writeFile(t, filepath.Join(dir, "_outtakes.md"), "")
+	writeFile(t, filepath.Join(dir, ".prosemark.yml"), "binders: [_outtakes.md]\n")
+	proj, _ = fsio.ScanProject(context.Background(), filepath.Join(dir, "_outtakes.md"))
+	sort.Strings(proj.AltBinders)
+	if proj.BinderFile != "_outtakes.md" || !reflect.DeepEqual(proj.AltBinders, []string{"_binder.md", "part/_binder.md"}) {
+		t.Errorf("from _outtakes.md: BinderFile/AltBinders = %q/%v", proj.BinderFile, proj.AltBinders)
+	}
+	proj, _ = fsio.ScanProject(context.Background(), filepath.Join(dir, "_binder.md"))
+	if !reflect.DeepEqual(proj.AltBinders, []string{"_outtakes.md", "part/_binder.md"}) {
+		t.Errorf("from _binder.md: AltBinders = %v, want the configured binder too", proj.AltBinders)
+	}
+
+	writeFile(t, filepath.Join(dir, "part/x_binder.md"), "")
+	writeFile(t, filepath.Join(dir, "x_binder.md"), "")
+	writeFile(t, filepath.Join(dir, ".prosemark.yml"), "binders: [part/x_binder.md]\n")
+	proj, _ = fsio.ScanProject(context.Background(), filepath.Join(dir, "_binder.md"))
+	sort.Strings(proj.AltBinders)
+	if !reflect.DeepEqual(proj.AltBinders, []string{"part/_binder.md", "part/x_binder.md"}) || !slices.Contains(proj.Files, "x_binder.md") {
+		t.Errorf("binder by path: AltBinders/Files = %v/%v, want part/x_binder.md a binder and x_binder.md a node", proj.AltBinders, proj.Files)
+	}
+
+	if _, err := fsio.ScanProject(context.Background(), filepath.Join(dir, "missing", "_binder.md")); err == nil {
+		t.Error("expected error scanning a missing directory")
+	}
+
+	writeFile(t, filepath.Join(dir, ".prosemark.yml"), "id_scheme: bogus\n")
+	if _, err := fsio.ScanProject(context.Background(), filepath.Join(dir, "_binder.md")); err == nil || !strings.Contains(err.Error(), "bogus") {
+		t.Errorf("invalid config: err = %v, want the config error", err)
+	}
+
+	unreadable := t.TempDir()
+	if err := os.Mkdir(filepath.Join(unreadable, ".prosemark.yml"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fsio.ScanProject(context.Background(), filepath.Join(unreadable, "_binder.md")); err == nil || !strings.Contains(err.Error(), "reading .prosemark.yml") {
+		t.Errorf("unreadable config: err = %v, want a read error", err)
+	}
+
+	empty, err := fsio.ScanProject(context.Background(), filepath.Join(t.TempDir(), "_binder.md"))
+	if err != nil || empty.Files == nil || len(empty.Files) != 0 || empty.Aliases != nil {
+		t.Errorf("empty project: Files = %#v, err = %v; want empty non-nil slice", empty.Files, err)
+	}
+}
@@ -0,0 +1,109 @@
+package fsio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// The *AtRevision functions read a project as it was committed at a git
+// revision, with git show and git ls-tree, leaving the work tree and index
+// alone. Paths are OS paths inside a git work tree, as for the other
+// functions here; git resolves them against the revision's tree.
+
+// ReadBinderAtRevision reads the binder file at path as of rev, rejecting
+// files larger than MaxBinderSize. A path absent from rev is reported with
+// an error wrapping os.ErrNotExist.
+func ReadBinderAtRevision(ctx context.Context, rev, path string) ([]byte, error) {
+	content, err := ReadFileAtRevision(ctx, rev, path)
+	if err == nil && len(content) > MaxBinderSize {
+		return nil, fmt.Errorf("binder file exceeds the 10 MB size limit")
+	}
+	return content, err
+}
+
+// ReadFileAtRevision reads the file at path as of rev. A path absent from
+// rev is reported with an error wrapping os.ErrNotExist.
+func ReadFileAtRevision(ctx context.Context, rev, path string) ([]byte, error) {
+	if err := checkRevision(rev); err != nil {
+		return nil, err
+	}
+	out, err := git(ctx, filepath.Dir(path), "show", rev+":./"+filepath.Base(path))
+	if err != nil && (strings.Contains(err.Error(), "does not exist in") || strings.Contains(err.Error(), "exists on disk, but not in")) {
+		return nil, fmt.Errorf("%s at %s: %w", path, rev, os.ErrNotExist)
+	}
+	return out, err
+}
+
+// ReadNodeFileAtRevision reads the node file at path as of rev, unlocking a
+// locked body as ReadNodeFile does.
+func ReadNodeFileAtRevision(ctx context.Context, rev, path string) ([]byte, error) {
+	content, err := ReadFileAtRevision(ctx, rev, path)
+	if err != nil {
+		return nil, err
+	}
+	return unlockNodeContent(content)
+}
+
+// ScanProjectAtRevision is ScanProject for the project as of rev: the files
+// are those committed under the binder's directory, and aliases and the
+// project config are read from the same revision.
+func ScanProjectAtRevision(ctx context.Context, rev, binderPath string) (*binder.Project, error) {
+	if err := checkRevision(rev); err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(binderPath)
+	out, err := git(ctx, dir, "ls-tree", "-r", "-z", "--name-only", rev)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, rel := range strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00") {
+		if rel == "" || strings.HasPrefix(rel, ".prosemark/") || strings.Contains(rel, "/.prosemark/") {
+			continue
+		}
+		paths = append(paths, rel)
+	}
+	read := func(rel string) []byte {
+		content, _ := ReadFileAtRevision(ctx, rev, filepath.Join(dir, filepath.FromSlash(rel)))
+		return content
+	}
+	settings, err := node.ParseProjectConfig(read(node.ConfigFilename))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", node.ConfigFilename, err)
+	}
+	return newProject(filepath.Base(binderPath), paths, settings, read), nil
+}
+
+// checkRevision rejects revisions git would take for an option.
+func checkRevision(rev string) error {
+	if rev == "" || strings.HasPrefix(rev, "-") {
+		return fmt.Errorf("invalid git revision %q", rev)
+	}
+	return nil
+}
+
+// git runs git with args in dir and returns its output. A failure is
+// reported with git's own message.
+func git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	c := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if msg := strings.TrimSpace(stderr.String()); errors.As(err, &exitErr) && msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], strings.TrimPrefix(msg, "fatal: "))
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
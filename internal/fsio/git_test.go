@@ -0,0 +1,113 @@
+package fsio_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+// gitRepo creates a repository in a temp dir whose first commit holds
+// files, and returns its path.
+func gitRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	for name, content := range files {
+		writeFile(t, filepath.Join(dir, filepath.FromSlash(name)), content)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "-m", "first"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestReadFileAtRevision(t *testing.T) {
+	dir := gitRepo(t, map[string]string{"book/_binder.md": "old\n", "book/ch1.md": "one\n"})
+	writeFile(t, filepath.Join(dir, "book", "_binder.md"), "new\n")
+	ctx := context.Background()
+	binderPath := filepath.Join(dir, "book", "_binder.md")
+
+	if got, err := fsio.ReadBinderAtRevision(ctx, "HEAD", binderPath); err != nil || string(got) != "old\n" {
+		t.Errorf("ReadBinderAtRevision = %q, %v; want the committed binder", got, err)
+	}
+	if got, err := fsio.ReadNodeFileAtRevision(ctx, "HEAD", filepath.Join(dir, "book", "ch1.md")); err != nil || string(got) != "one\n" {
+		t.Errorf("ReadNodeFileAtRevision = %q, %v", got, err)
+	}
+	if _, err := fsio.ReadFileAtRevision(ctx, "HEAD", filepath.Join(dir, "book", "ch2.md")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file: err = %v, want os.ErrNotExist", err)
+	}
+	if _, err := fsio.ReadFileAtRevision(ctx, "nosuchrev", binderPath); err == nil || !strings.Contains(err.Error(), "git show") {
+		t.Errorf("unknown revision: err = %v, want a git show error", err)
+	}
+	if _, err := fsio.ReadFileAtRevision(ctx, "--output=x", binderPath); err == nil || !strings.Contains(err.Error(), "invalid git revision") {
+		t.Errorf("option-like revision: err = %v, want it rejected", err)
+	}
+	if _, err := fsio.ReadNodeFileAtRevision(ctx, "HEAD", filepath.Join(dir, "book", "ch2.md")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing node file: err = %v, want os.ErrNotExist", err)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := fsio.ReadFileAtRevision(cancelled, "HEAD", binderPath); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled: err = %v, want context.Canceled", err)
+	}
+}
+
+func TestReadBinderAtRevision_TooLarge(t *testing.T) {
+	dir := gitRepo(t, map[string]string{"_binder.md": strings.Repeat("x", fsio.MaxBinderSize+1)})
+	if _, err := fsio.ReadBinderAtRevision(context.Background(), "HEAD", filepath.Join(dir, "_binder.md")); err == nil || !strings.Contains(err.Error(), "exceeds the 10 MB size limit") {
+		t.Errorf("err = %v, want the size limit", err)
+	}
+}
+
+func TestScanProjectAtRevision(t *testing.T) {
+	dir := gitRepo(t, map[string]string{
+		"_binder.md":              "<!-- prosemark-binder:v1 -->\n",
+		"ch1.md":                  "---\naliases: [One]\n---\n",
+		"part/_binder.md":         "",
+		"part/ch2.md":             "",
+		"notes.txt":               "",
+		".prosemark/trash/old.md": "",
+	})
+	writeFile(t, filepath.Join(dir, "uncommitted.md"), "")
+
+	p, err := fsio.ScanProjectAtRevision(context.Background(), "HEAD", filepath.Join(dir, "_binder.md"))
+	if err != nil {
+		t.Fatalf("ScanProjectAtRevision: %v", err)
+	}
+	if want := []string{"ch1.md", "part/ch2.md"}; !reflect.DeepEqual(p.Files, want) {
+		t.Errorf("Files = %v, want %v", p.Files, want)
+	}
+	if want := []string{"part/_binder.md"}; !reflect.DeepEqual(p.AltBinders, want) {
+		t.Errorf("AltBinders = %v, want %v", p.AltBinders, want)
+	}
+	if want := map[string][]string{"ch1.md": {"One"}}; !reflect.DeepEqual(p.Aliases, want) {
+		t.Errorf("Aliases = %v, want %v", p.Aliases, want)
+	}
+
+	if _, err := fsio.ScanProjectAtRevision(context.Background(), "nosuchrev", filepath.Join(dir, "_binder.md")); err == nil {
+		t.Error("unknown revision: want an error")
+	}
+	if _, err := fsio.ScanProjectAtRevision(context.Background(), "-n", filepath.Join(dir, "_binder.md")); err == nil || !strings.Contains(err.Error(), "invalid git revision") {
+		t.Errorf("option-like revision: err = %v, want it rejected", err)
+	}
+
+	bad := gitRepo(t, map[string]string{"_binder.md": "", ".prosemark.yml": "id_scheme: bogus\n"})
+	if _, err := fsio.ScanProjectAtRevision(context.Background(), "HEAD", filepath.Join(bad, "_binder.md")); err == nil || !strings.Contains(err.Error(), ".prosemark.yml") {
+		t.Errorf("invalid config at the revision: err = %v, want the config error", err)
+	}
+}
@@ -0,0 +1,99 @@
+package fsio
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// JournalFilename is the operation journal's path relative to the project
+// directory.
+const JournalFilename = ".prosemark/journal.jsonl"
+
+// JournalEntry is one applied operation in the project's journal, stored as
+// a single JSON line.
+type JournalEntry struct {
+	// OpID is the caller-supplied idempotency key (--op-id).
+	OpID string `json:"op_id"`
+	// Command is the pmk subcommand that applied the operation.
+	Command string `json:"command"`
+	// Time is the UTC RFC 3339 time the operation was applied.
+	Time string `json:"time"`
+	// ParamsHash identifies the arguments and flags the command was run
+	// with, so a retry under the same OpID with others is refused. Entries
+	// journaled before it was recorded have none.
+	ParamsHash string `json:"params_hash,omitempty"`
+	// Result is the OpResult the command reported when it applied the operation.
+	Result binder.OpResult `json:"result"`
+	// Ops are the operations the command applied, as op.json specs, for the
+	// commands that have them (add, delete, move, and apply). pmk journal
+	// replay re-applies them to another copy of the project.
+	Ops []binder.OpSpec `json:"ops,omitempty"`
+	// BinderBefore and BinderAfter identify the binder content the operation
+	// started from and left (see binder.OpResult).
+	BinderBefore string `json:"binder_before,omitempty"`
+	BinderAfter  string `json:"binder_after,omitempty"`
+}
+
+// JournalPath returns the journal file path for projectDir.
+func JournalPath(projectDir string) string {
+	return filepath.Join(projectDir, filepath.FromSlash(JournalFilename))
+}
+
+// ReadJournal returns the entries in projectDir's journal, oldest first. A
+// missing journal has no entries. Lines that do not decode, such as one
+// truncated by a crash mid-append, are skipped.
+func ReadJournal(projectDir string) ([]JournalEntry, error) {
+	data, exists, err := ReadFileIfExists(JournalPath(projectDir))
+	if err != nil || !exists {
+		return nil, err
+	}
+	var entries []JournalEntry
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if e, ok := ParseJournalLine(line); ok {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// ParseJournalLine decodes one journal line, reporting false for a blank,
+// truncated, or otherwise undecodable line.
+func ParseJournalLine(line []byte) (JournalEntry, bool) {
+	var e JournalEntry
+	if json.Unmarshal(line, &e) != nil || e.OpID == "" {
+		return JournalEntry{}, false
+	}
+	return e, true
+}
+
+// AppendJournal appends entry to projectDir's journal, creating the journal
+// and its directory if needed.
+func AppendJournal(projectDir string, entry JournalEntry) error {
+	// Encoding plain strings and diagnostics cannot fail.
+	line, _ := json.Marshal(entry)
+	path := JournalPath(projectDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating journal directory: %w", err)
+	}
+	return appendFileImpl(path, append(line, '\n'))
+}
+
+// appendFileImpl appends data to path in a single write, creating the file
+// with 0600 permissions. Its failure paths need OS fault injection, so it is
+// excluded from coverage.
+func appendFileImpl(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("opening journal: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing journal: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,57 @@
+package fsio_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+func TestJournal_AppendAndRead(t *testing.T) {
+	dir := t.TempDir()
+
+	entries, err := fsio.ReadJournal(dir)
+	if err != nil || entries != nil {
+		t.Fatalf("ReadJournal on a missing journal = %v, %v; want nil, nil", entries, err)
+	}
+
+	first := fsio.JournalEntry{OpID: "a", Command: "add", Time: "t1", Result: binder.OpResult{Version: "1", Changed: true}}
+	second := fsio.JournalEntry{OpID: "b", Command: "delete", Time: "t2", Result: binder.OpResult{Version: "1"}}
+	for _, e := range []fsio.JournalEntry{first, second} {
+		if err := fsio.AppendJournal(dir, e); err != nil {
+			t.Fatalf("AppendJournal: %v", err)
+		}
+	}
+
+	// A truncated trailing line, as left by a crash mid-append, is skipped.
+	f, err := os.OpenFile(fsio.JournalPath(dir), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"op_id":"c","comm`); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	entries, err = fsio.ReadJournal(dir)
+	if err != nil {
+		t.Fatalf("ReadJournal: %v", err)
+	}
+	if len(entries) != 2 || entries[0].OpID != "a" || entries[1].OpID != "b" || !entries[0].Result.Changed {
+		t.Errorf("ReadJournal = %+v, want entries a then b", entries)
+	}
+	if got, want := fsio.JournalPath(dir), filepath.Join(dir, ".prosemark", "journal.jsonl"); got != want {
+		t.Errorf("JournalPath = %q, want %q", got, want)
+	}
+}
+
+func TestAppendJournal_DirectoryError(t *testing.T) {
+	dir := t.TempDir()
+	// A file where the journal directory should be blocks MkdirAll.
+	writeFile(t, filepath.Join(dir, ".prosemark"), "")
+	if err := fsio.AppendJournal(dir, fsio.JournalEntry{OpID: "a"}); err == nil {
+		t.Error("expected error when the journal directory cannot be created")
+	}
+}
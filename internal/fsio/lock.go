@@ -0,0 +1,103 @@
+package fsio
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// KeyFileEnv names the environment variable holding the path of the project
+// key file that unlocks locked node bodies.
+const KeyFileEnv = "PMK_KEY_FILE"
+
+// lockBody locks a node body; tests replace it to simulate cipher failures.
+var lockBody = node.LockBody
+
+// ReadKeyFile reads and decodes the key file at path.
+func ReadKeyFile(path string) (node.BodyKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return node.BodyKey{}, fmt.Errorf("reading key file: %w", err)
+	}
+	return node.ParseBodyKey(data)
+}
+
+// CreateKeyFile writes key to a new 0600 key file at path, failing if the
+// file already exists so an existing key is never overwritten.
+func CreateKeyFile(path string, key node.BodyKey) error {
+	return createFileImpl(path, key.Encode())
+}
+
+// createFileImpl writes data to a new 0600 file at path, failing if it
+// exists. Its write and close failure paths need OS fault injection, so it
+// is excluded from coverage.
+func createFileImpl(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadNodeFile reads the node file at path. A locked body is unlocked with
+// the key file named by KeyFileEnv; errors about locked bodies wrap
+// node.ErrLockedBody.
+func ReadNodeFile(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return unlockNodeContent(content)
+}
+
+// unlockNodeContent returns node file content with a locked body unlocked
+// with the key file named by KeyFileEnv, or unchanged when it is not locked.
+func unlockNodeContent(content []byte) ([]byte, error) {
+	if !node.BodyLocked(content) {
+		return content, nil
+	}
+	key, err := envBodyKey()
+	if err != nil {
+		return nil, err
+	}
+	return node.UnlockBody(content, key)
+}
+
+// WriteNodeFile writes content to path atomically. When the file it replaces
+// has a locked body, content is locked with the same key first, so a command
+// that edits a locked node never leaves a plaintext copy behind. Content
+// that is already locked, such as a stored file with edited frontmatter, is
+// written as it is and needs no key.
+func WriteNodeFile(path string, content []byte) error {
+	if node.BodyLocked(content) {
+		return WriteFileAtomic(path, ".node", content)
+	}
+	if existing, err := os.ReadFile(path); err == nil && node.BodyLocked(existing) {
+		key, err := envBodyKey()
+		if err != nil {
+			return err
+		}
+		if content, err = lockBody(content, key); err != nil {
+			return err
+		}
+	}
+	return WriteFileAtomic(path, ".node", content)
+}
+
+// envBodyKey loads the key file named by KeyFileEnv.
+func envBodyKey() (node.BodyKey, error) {
+	path := os.Getenv(KeyFileEnv)
+	if path == "" {
+		return node.BodyKey{}, fmt.Errorf("%w; set %s to the project key file to read it", node.ErrLockedBody, KeyFileEnv)
+	}
+	key, err := ReadKeyFile(path)
+	if err != nil {
+		return key, fmt.Errorf("%w; %s: %v", node.ErrLockedBody, KeyFileEnv, err)
+	}
+	return key, nil
+}
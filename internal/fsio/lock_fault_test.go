@@ -0,0 +1,40 @@
+package fsio
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+func TestWriteNodeFile_LockFails(t *testing.T) {
+	dir := t.TempDir()
+	var key node.BodyKey
+	keyPath := filepath.Join(dir, "project.key")
+	if err := CreateKeyFile(keyPath, key); err != nil {
+		t.Fatal(err)
+	}
+	locked, err := node.LockBody([]byte("---\nid: l\n---\nsecret\n"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "locked.md")
+	if err := os.WriteFile(path, locked, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(KeyFileEnv, keyPath)
+
+	errCipher := errors.New("no cipher")
+	orig := lockBody
+	lockBody = func([]byte, node.BodyKey) ([]byte, error) { return nil, errCipher }
+	t.Cleanup(func() { lockBody = orig })
+
+	if err := WriteNodeFile(path, []byte("---\nid: l\n---\nplain\n")); !errors.Is(err, errCipher) {
+		t.Errorf("WriteNodeFile err = %v, want cipher error", err)
+	}
+	if raw, _ := os.ReadFile(path); string(raw) != string(locked) {
+		t.Error("WriteNodeFile changed the file although locking failed")
+	}
+}
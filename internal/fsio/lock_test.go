@@ -0,0 +1,117 @@
+package fsio_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// newBodyKey returns a new random key, failing the test on error.
+func newBodyKey(t *testing.T) node.BodyKey {
+	t.Helper()
+	key, err := node.NewBodyKey()
+	if err != nil {
+		t.Fatalf("NewBodyKey: %v", err)
+	}
+	return key
+}
+
+func TestKeyFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "project.key")
+	key := newBodyKey(t)
+
+	if _, err := fsio.ReadKeyFile(path); err == nil || !strings.Contains(err.Error(), "reading key file") {
+		t.Errorf("ReadKeyFile(missing) err = %v", err)
+	}
+	if err := fsio.CreateKeyFile(path, key); err != nil {
+		t.Fatalf("CreateKeyFile: %v", err)
+	}
+	if fi, _ := os.Stat(path); fi.Mode().Perm() != 0600 {
+		t.Errorf("key file mode = %v, want 0600", fi.Mode().Perm())
+	}
+	if err := fsio.CreateKeyFile(path, newBodyKey(t)); err == nil {
+		t.Error("CreateKeyFile overwrote an existing key file")
+	}
+	if got, err := fsio.ReadKeyFile(path); err != nil || got != key {
+		t.Errorf("ReadKeyFile = %v, %v", got, err)
+	}
+}
+
+func TestReadAndWriteNodeFile_Locked(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "project.key")
+	key := newBodyKey(t)
+	if err := fsio.CreateKeyFile(keyPath, key); err != nil {
+		t.Fatal(err)
+	}
+	plainPath := filepath.Join(dir, "plain.md")
+	lockedPath := filepath.Join(dir, "locked.md")
+	writeFile(t, plainPath, "---\nid: p\n---\nopen text\n")
+	locked, err := node.LockBody([]byte("---\nid: l\n---\nsecret text\n"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, lockedPath, string(locked))
+
+	t.Run("no key", func(t *testing.T) {
+		t.Setenv(fsio.KeyFileEnv, "")
+		if got, err := fsio.ReadNodeFile(plainPath); err != nil || !strings.Contains(string(got), "open text") {
+			t.Errorf("ReadNodeFile(plain) = %q, %v", got, err)
+		}
+		_, err := fsio.ReadNodeFile(lockedPath)
+		if !errors.Is(err, node.ErrLockedBody) || !strings.Contains(err.Error(), "set "+fsio.KeyFileEnv) {
+			t.Errorf("ReadNodeFile(locked) err = %v", err)
+		}
+		if err := fsio.WriteNodeFile(lockedPath, []byte("clobber\n")); !errors.Is(err, node.ErrLockedBody) {
+			t.Errorf("WriteNodeFile(locked) err = %v", err)
+		}
+		retitled := strings.Replace(string(locked), "id: l\n", "id: l\ntitle: Kept\n", 1)
+		if err := fsio.WriteNodeFile(lockedPath, []byte(retitled)); err != nil {
+			t.Errorf("WriteNodeFile(locked content) err = %v, want it written without a key", err)
+		}
+		if raw, _ := os.ReadFile(lockedPath); string(raw) != retitled {
+			t.Errorf("stored = %q, want %q", raw, retitled)
+		}
+		writeFile(t, lockedPath, string(locked))
+		if _, err := fsio.ReadNodeFile(filepath.Join(dir, "missing.md")); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("ReadNodeFile(missing) err = %v", err)
+		}
+	})
+
+	t.Run("unreadable key file", func(t *testing.T) {
+		t.Setenv(fsio.KeyFileEnv, filepath.Join(dir, "nope.key"))
+		if _, err := fsio.ReadNodeFile(lockedPath); !errors.Is(err, node.ErrLockedBody) || !strings.Contains(err.Error(), "reading key file") {
+			t.Errorf("err = %v", err)
+		}
+	})
+
+	t.Run("with key", func(t *testing.T) {
+		t.Setenv(fsio.KeyFileEnv, keyPath)
+		got, err := fsio.ReadNodeFile(lockedPath)
+		if err != nil || string(got) != "---\nid: l\n---\nsecret text\n" {
+			t.Fatalf("ReadNodeFile(locked) = %q, %v", got, err)
+		}
+		if err := fsio.WriteNodeFile(lockedPath, []byte("---\nid: l\n---\nrevised\n")); err != nil {
+			t.Fatalf("WriteNodeFile(locked): %v", err)
+		}
+		raw, _ := os.ReadFile(lockedPath)
+		if !node.BodyLocked(raw) || strings.Contains(string(raw), "revised") {
+			t.Errorf("rewritten locked node = %q, want it still locked", raw)
+		}
+		if got, _ := fsio.ReadNodeFile(lockedPath); !strings.Contains(string(got), "revised") {
+			t.Errorf("re-read = %q", got)
+		}
+		if err := fsio.WriteNodeFile(plainPath, []byte("---\nid: p\n---\nstill open\n")); err != nil {
+			t.Fatal(err)
+		}
+		if raw, _ := os.ReadFile(plainPath); node.BodyLocked(raw) {
+			t.Error("WriteNodeFile locked a plain node")
+		}
+	})
+}
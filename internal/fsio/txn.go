@@ -0,0 +1,516 @@
+package fsio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TxDir is the directory, relative to the project directory, holding the
+// journals of file transactions in progress.
+const TxDir = ".prosemark/tx"
+
+// SyncPolicy says whether a transaction flushes its files to stable
+// storage before reporting them written.
+type SyncPolicy int
+
+const (
+	// SyncAlways fsyncs each staged file and journal and the directories
+	// of renamed files, so a committed transaction survives a power loss
+	// and an interrupted one can always be rolled back.
+	SyncAlways SyncPolicy = iota
+	// SyncNever leaves flushing to the OS. A process crash is still
+	// recovered, but a power loss can lose renames the journal records.
+	SyncNever
+)
+
+// Tx is a file transaction: writes and removals of project files, staged
+// in order and applied by Commit so that either all of them land or none
+// do. Each write is staged in a temp file beside its target, and Commit
+// moves every file it replaces or removes to a backup before renaming the
+// staged file into place, so it can undo any step. The steps are recorded
+// in a journal under TxDir; when the process dies mid-commit, the next
+// RecoverTxs in the project finishes the transaction or rolls it back.
+// Until it finishes, a Tx holds a lock on a file beside its journal, which
+// tells recovery in any process that it is still running.
+//
+// A staging failure is held and returned by Commit, which then applies
+// nothing, so callers stage every step and check one error.
+type Tx struct {
+	id, journal string
+	lock        *os.File
+	sync        SyncPolicy
+	state       txJournal
+	err         error
+	done        bool
+}
+
+// txJournal is the journal of a Tx. Committed is set once every step is in
+// place, and tells recovery to finish the transaction instead of undoing it.
+type txJournal struct {
+	Committed bool     `json:"committed"`
+	Steps     []txStep `json:"steps"`
+}
+
+// txStep is one step of a Tx. Staged is the temp file holding the content
+// to write to Path, Create is set when no file may be there, and Remove is
+// set instead of Staged for a removal. Backup is
+// where Commit moves the file Path held, if any. Started is set once Commit
+// reaches the step, with Existed recording whether Path held a file then.
+type txStep struct {
+	Path    string `json:"path"`
+	Staged  string `json:"staged,omitempty"`
+	Backup  string `json:"backup"`
+	Create  bool   `json:"create,omitempty"`
+	Remove  bool   `json:"remove,omitempty"`
+	Binder  bool   `json:"binder,omitempty"`
+	Started bool   `json:"started,omitempty"`
+	Existed bool   `json:"existed,omitempty"`
+}
+
+// BeginTx starts a file transaction in the project at projectDir, after
+// recovering any transaction there that a crash left incomplete.
+func BeginTx(projectDir string, sync SyncPolicy) (*Tx, error) {
+	if _, err := RecoverTxs(projectDir); err != nil {
+		return nil, fmt.Errorf("recovering interrupted transaction: %w", err)
+	}
+	dir := filepath.Join(projectDir, filepath.FromSlash(TxDir))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating transaction directory: %w", err)
+	}
+	// The lock comes before the journal, which the first step writes, so
+	// recovery never finds a journal whose lock file is missing while its
+	// transaction runs.
+	lock, err := createTxLock(dir)
+	if err != nil {
+		return nil, err
+	}
+	id := strings.TrimSuffix(filepath.Base(lock.Name()), ".lock")
+	return &Tx{id: id, journal: filepath.Join(dir, id+".json"), lock: lock, sync: sync}, nil
+}
+
+// createTxLock is createTxLockImpl; tests replace it to exercise its
+// failure.
+var createTxLock = createTxLockImpl
+
+// createTxLockImpl creates a new lock file in dir and locks it. Nothing
+// else has opened the new file, so the lock is always free; its failure
+// paths need OS fault injection, so it is excluded from coverage.
+func createTxLockImpl(dir string) (*os.File, error) {
+	f, err := os.CreateTemp(dir, "*.lock")
+	if err != nil {
+		return nil, fmt.Errorf("creating transaction lock: %w", err)
+	}
+	if _, err := tryLock(f); err != nil {
+		_ = f.Close() // the lock error is the one worth reporting
+		_ = os.Remove(f.Name())
+		return nil, fmt.Errorf("locking transaction: %w", err)
+	}
+	return f, nil
+}
+
+// Write stages writing data to path, creating its directory if needed.
+func (t *Tx) Write(path string, data []byte) {
+	t.stage(txStep{Path: path}, data)
+}
+
+// Create stages writing data to a new file at path, creating its directory
+// if needed. A file that exists at path when Commit reaches it fails the
+// transaction.
+func (t *Tx) Create(path string, data []byte) {
+	t.stage(txStep{Path: path, Create: true}, data)
+}
+
+// WriteBinder stages writing binder data to path, refusing to replace a
+// read-only binder as WriteBinder does.
+func (t *Tx) WriteBinder(path string, data []byte) {
+	if t.err == nil {
+		if err := CheckWritable(path); err != nil {
+			t.err = fmt.Errorf("writing binder: %w", err)
+			return
+		}
+	}
+	t.stage(txStep{Path: path, Binder: true}, data)
+}
+
+// Remove stages removing the file at path. Removing a file that does not
+// exist when Commit reaches it fails the transaction.
+func (t *Tx) Remove(path string) {
+	t.add(txStep{Path: path, Remove: true})
+}
+
+// stage writes data to a temp file beside step's path and adds the step.
+func (t *Tx) stage(step txStep, data []byte) {
+	if t.err != nil || t.done {
+		return
+	}
+	dir := filepath.Dir(step.Path)
+	err := os.MkdirAll(dir, 0o755)
+	if err == nil {
+		step.Staged, err = writeTempFileImpl(dir, t.name(".*.stage"), data, t.sync == SyncAlways)
+	}
+	if err != nil {
+		t.err = step.fail(err)
+		return
+	}
+	t.add(step)
+}
+
+// add records step in the journal, naming its backup.
+func (t *Tx) add(step txStep) {
+	if t.err != nil || t.done {
+		return
+	}
+	step.Backup = filepath.Join(filepath.Dir(step.Path), t.name(fmt.Sprintf(".%d.bak", len(t.state.Steps))))
+	t.state.Steps = append(t.state.Steps, step)
+	t.err = t.save()
+}
+
+// name returns the name of one of t's files beside a step's target, made
+// from t's id and suffix.
+func (t *Tx) name(suffix string) string {
+	return ".pmk-tx-" + t.id + suffix
+}
+
+// Commit applies t's steps in order. When a step fails, or one could not
+// be staged, the steps before it are undone and its error is returned,
+// noting any failure to undo them; the journal is then kept so that the
+// next RecoverTxs retries the rollback.
+func (t *Tx) Commit() error {
+	if t.done {
+		return errors.New("transaction already finished")
+	}
+	if t.err != nil {
+		return withRollback(t.err, t.Abort())
+	}
+	t.done = true
+	defer t.release()
+	for i := range t.state.Steps {
+		if err := t.apply(&t.state.Steps[i]); err != nil {
+			return withRollback(err, t.rollback())
+		}
+	}
+	if t.sync == SyncAlways {
+		if err := t.syncDirs(); err != nil {
+			return withRollback(err, t.rollback())
+		}
+	}
+	t.state.Committed = true
+	if err := t.save(); err != nil {
+		t.state.Committed = false
+		return withRollback(err, t.rollback())
+	}
+	// The transaction has landed. Leftover backups are removed by the
+	// next recovery, which finds the committed journal kept for them.
+	_ = finishTx(t.journal, t.state)
+	return nil
+}
+
+// Abort discards t's staged files and journal without applying any step.
+// It does nothing once Commit has run.
+func (t *Tx) Abort() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	defer t.release()
+	return t.rollback()
+}
+
+// release unlocks t and removes its lock file. A journal left behind, by a
+// failed rollback or finish, is then recovered by the next RecoverTxs.
+func (t *Tx) release() {
+	// Closing a file only fails on an invalid descriptor, and a lock
+	// file left behind is harmless, so neither error is worth reporting.
+	_ = t.lock.Close()
+	_ = removeIfExists(txLockPath(t.journal))
+}
+
+// apply moves the file at step's path to its backup and the staged file
+// into its place, recording in the journal whether there was one first.
+func (t *Tx) apply(step *txStep) error {
+	_, err := os.Lstat(step.Path)
+	step.Started = true
+	switch {
+	case err == nil && step.Create:
+		return step.fail(fs.ErrExist)
+	case err == nil:
+		step.Existed = true
+	case errors.Is(err, fs.ErrNotExist) && !step.Remove:
+	default:
+		return step.fail(err)
+	}
+	if err := t.save(); err != nil {
+		return err
+	}
+	if step.Existed {
+		if err := os.Rename(step.Path, step.Backup); err != nil {
+			return step.fail(err)
+		}
+	}
+	if !step.Remove {
+		if err := os.Rename(step.Staged, step.Path); err != nil {
+			return step.fail(err)
+		}
+	}
+	return nil
+}
+
+// rollback undoes t's steps, last first, and removes the journal, if the
+// first step wrote one, when every step was undone.
+func (t *Tx) rollback() error {
+	if err := undoSteps(t.state.Steps); err != nil {
+		return err
+	}
+	return removeIfExists(t.journal)
+}
+
+// syncDirs fsyncs the directories of t's steps.
+func (t *Tx) syncDirs() error {
+	seen := map[string]bool{}
+	for _, s := range t.state.Steps {
+		if dir := filepath.Dir(s.Path); !seen[dir] {
+			seen[dir] = true
+			if err := syncDir(dir); err != nil {
+				return fmt.Errorf("syncing %s: %w", dir, err)
+			}
+		}
+	}
+	return nil
+}
+
+// save writes t's journal atomically.
+func (t *Tx) save() error {
+	// Encoding strings and bools cannot fail.
+	data, _ := json.Marshal(t.state)
+	dir := filepath.Dir(t.journal)
+	tmp, err := writeTempFileImpl(dir, "."+filepath.Base(t.journal)+"-*.tmp", data, t.sync == SyncAlways)
+	if err == nil {
+		if err = os.Rename(tmp, t.journal); err != nil {
+			_ = os.Remove(tmp)
+		}
+	}
+	if err == nil && t.sync == SyncAlways {
+		err = syncDir(dir)
+	}
+	if err != nil {
+		return fmt.Errorf("writing transaction journal: %w", err)
+	}
+	return nil
+}
+
+// fail returns err as the failure of step.
+func (s txStep) fail(err error) error {
+	switch {
+	case s.Binder:
+		return fmt.Errorf("writing binder: %w", err)
+	case s.Create:
+		return fmt.Errorf("creating %s: %w", s.Path, err)
+	case s.Remove:
+		return fmt.Errorf("removing %s: %w", s.Path, err)
+	}
+	return fmt.Errorf("writing %s: %w", s.Path, err)
+}
+
+// undoStep puts back the file at step's path as it was before the step,
+// whether Commit finished it, stopped partway, or never reached it, and
+// removes the step's staged file. Running it twice is harmless.
+func undoStep(step txStep) error {
+	var errs []error
+	if step.Staged != "" {
+		_, err := os.Lstat(step.Staged)
+		switch {
+		case err == nil:
+			errs = append(errs, os.Remove(step.Staged))
+		case errors.Is(err, fs.ErrNotExist) && step.Started && !step.Existed:
+			// The staged file was renamed into a place that was empty.
+			errs = append(errs, removeIfExists(step.Path))
+		}
+	}
+	if _, err := os.Lstat(step.Backup); err == nil {
+		errs = append(errs, os.Rename(step.Backup, step.Path))
+	}
+	return errors.Join(errs...)
+}
+
+// undoSteps undoes steps, last first.
+func undoSteps(steps []txStep) error {
+	var errs []error
+	for i := len(steps) - 1; i >= 0; i-- {
+		if err := undoStep(steps[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// finishTx removes the backups of the committed transaction state and
+// then its journal, which is kept when a backup could not be removed.
+func finishTx(journal string, state txJournal) error {
+	var errs []error
+	for _, s := range state.Steps {
+		errs = append(errs, removeIfExists(s.Backup))
+		if s.Staged != "" {
+			errs = append(errs, removeIfExists(s.Staged))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	return os.Remove(journal)
+}
+
+// RecoverTxs finishes the file transactions in projectDir that a crash left
+// incomplete: a committed one by removing the backups it left, and any other
+// by undoing its steps. A transaction still running, in this process or
+// another, holds the lock beside its journal and is left alone, however
+// long it takes. It returns the journals recovered, relative to projectDir
+// and slash-separated. A journal that cannot be decoded was cut short
+// before any step, and is removed.
+func RecoverTxs(projectDir string) ([]string, error) {
+	dir := filepath.Join(projectDir, filepath.FromSlash(TxDir))
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var recovered []string
+	var errs []error
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		ok, err := recoverTx(filepath.Join(dir, e.Name()))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.Name(), err))
+			continue
+		}
+		if ok {
+			recovered = append(recovered, TxDir+"/"+e.Name())
+		}
+	}
+	return recovered, errors.Join(errs...)
+}
+
+// recoverTx finishes or undoes the transaction whose journal is at path,
+// reporting false when it is still running or finished meanwhile.
+func recoverTx(path string) (bool, error) {
+	release, live, err := claimTx(path)
+	if err != nil || live {
+		return false, err
+	}
+	defer release()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	var state txJournal
+	if json.Unmarshal(data, &state) != nil {
+		return true, os.Remove(path)
+	}
+	if state.Committed {
+		return true, finishTx(path, state)
+	}
+	if err := undoSteps(state.Steps); err != nil {
+		return true, err
+	}
+	return true, os.Remove(path)
+}
+
+// claimTx takes the lock of the transaction whose journal is at journal,
+// reporting it live when another open file holds it. The release it
+// returns unlocks the transaction and removes its lock file. A missing lock
+// file means the transaction's process is gone, since a Tx creates its lock
+// before its journal and removes it after.
+func claimTx(journal string) (release func(), live bool, err error) {
+	path := txLockPath(journal)
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if errors.Is(err, fs.ErrNotExist) {
+		return func() {}, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	ok, err := tryLock(f)
+	if err != nil || !ok {
+		_ = f.Close() // the lock was not taken, so there is nothing to release
+		return nil, err == nil, err
+	}
+	return func() {
+		// As in Tx.release, neither failure is worth reporting.
+		_ = f.Close()
+		_ = removeIfExists(path)
+	}, false, nil
+}
+
+// txLockPath returns the path of the lock file of the transaction whose
+// journal is at journal.
+func txLockPath(journal string) string {
+	return strings.TrimSuffix(journal, ".json") + ".lock"
+}
+
+// removeIfExists removes the file at path, if there is one.
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// withRollback adds a failed rollback to err.
+func withRollback(err, rollbackErr error) error {
+	if rollbackErr != nil {
+		return fmt.Errorf("%w; rollback also failed: %v", err, rollbackErr)
+	}
+	return err
+}
+
+// writeTempFileImpl writes data to a new 0600 file in dir named from pattern,
+// as os.CreateTemp does, fsyncing it when sync is set, and returns its
+// path; on failure the file is removed. Its failure paths need OS fault
+// injection, so it is excluded from coverage.
+func writeTempFileImpl(dir, pattern string, data []byte, sync bool) (string, error) {
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", fmt.Errorf("creating temp file: %w", err)
+	}
+	name := f.Name()
+	if _, err = f.Write(data); err == nil && sync {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(name)
+		return "", fmt.Errorf("writing temp file: %w", err)
+	}
+	return name, nil
+}
+
+// syncDir is syncDirImpl; tests replace it to exercise its failure.
+var syncDir = syncDirImpl
+
+// syncDirImpl fsyncs the directory at path, so the renames in it are
+// durable. Its failure paths need OS fault injection, so it is excluded
+// from coverage.
+func syncDirImpl(path string) error {
+	d, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cerr := d.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
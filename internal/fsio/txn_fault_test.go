@@ -0,0 +1,107 @@
+package fsio
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// failSyncDir makes the nth call of syncDir, counting from 1, fail until
+// the test ends.
+func failSyncDir(t *testing.T, nth int) {
+	t.Helper()
+	orig := syncDir
+	calls := 0
+	syncDir = func(path string) error {
+		if calls++; calls == nth {
+			return errors.New("sync failed")
+		}
+		return orig(path)
+	}
+	t.Cleanup(func() { syncDir = orig })
+}
+
+func TestTx_SyncFailures(t *testing.T) {
+	// With one step, SyncAlways syncs the journal's directory when the
+	// step is staged and when it is applied, the step's directory, and
+	// the journal's directory when the transaction is committed.
+	tests := []struct {
+		name    string
+		nth     int
+		wantErr string
+	}{
+		{name: "staging", nth: 1, wantErr: "writing transaction journal: sync failed"},
+		{name: "applying", nth: 2, wantErr: "writing transaction journal: sync failed"},
+		{name: "step directory", nth: 3, wantErr: "syncing "},
+		{name: "committing", nth: 4, wantErr: "writing transaction journal: sync failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "a.md")
+			if err := os.WriteFile(path, []byte("A"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			failSyncDir(t, tt.nth)
+			tx, err := BeginTx(dir, SyncAlways)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tx.Write(path, []byte("A2"))
+			if err := tx.Commit(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Commit() = %v, want %q", err, tt.wantErr)
+			}
+			if data, err := os.ReadFile(path); err != nil || string(data) != "A" {
+				t.Errorf("a.md = %q, %v; want it rolled back", data, err)
+			}
+		})
+	}
+}
+
+func TestBeginTx_LockFailure(t *testing.T) {
+	orig := createTxLock
+	createTxLock = func(string) (*os.File, error) { return nil, errors.New("no locks") }
+	t.Cleanup(func() { createTxLock = orig })
+	if _, err := BeginTx(t.TempDir(), SyncNever); err == nil || err.Error() != "no locks" {
+		t.Errorf("BeginTx() = %v, want the lock error", err)
+	}
+}
+
+func TestTryLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "1.lock")
+	open := func() *os.File {
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	f := open()
+	if ok, err := tryLock(f); !ok || err != nil {
+		t.Fatalf("tryLock() = %v, %v; want the lock", ok, err)
+	}
+	g := open()
+	defer g.Close()
+	if ok, err := tryLock(g); ok || err != nil {
+		t.Errorf("tryLock() of a held lock = %v, %v; want false, nil", ok, err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := tryLock(f); ok || err == nil {
+		t.Errorf("tryLock() of a closed file = %v, %v; want an error", ok, err)
+	}
+	if ok, err := tryLock(g); !ok || err != nil {
+		t.Errorf("tryLock() after release = %v, %v; want the lock", ok, err)
+	}
+}
+
+func TestRecoverTx_Finished(t *testing.T) {
+	// A transaction that finished after its journal was listed has
+	// nothing left to recover.
+	if ok, err := recoverTx(filepath.Join(t.TempDir(), "1.json")); ok || err != nil {
+		t.Errorf("recoverTx() = %v, %v; want false, nil", ok, err)
+	}
+}
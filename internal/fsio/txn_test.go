@@ -0,0 +1,569 @@
+package fsio_test
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+// projectFiles returns the files under dir, relative and slash-separated,
+// with their contents.
+func projectFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		rel, _ := filepath.Rel(dir, path)
+		files[filepath.ToSlash(rel)] = string(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// txProject returns a project directory holding a binder and two node files.
+func txProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range map[string]string{"_binder.md": "binder", "a.md": "A", "b.md": "B"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestTx_Commit(t *testing.T) {
+	for _, sync := range []fsio.SyncPolicy{fsio.SyncAlways, fsio.SyncNever} {
+		dir := txProject(t)
+		tx, err := fsio.BeginTx(dir, sync)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx.Write(filepath.Join(dir, "sub", "c.md"), []byte("C"))
+		tx.Write(filepath.Join(dir, "a.md"), []byte("A2"))
+		tx.WriteBinder(filepath.Join(dir, "_binder.md"), []byte("binder2"))
+		tx.Remove(filepath.Join(dir, "b.md"))
+		tx.Create(filepath.Join(dir, "d.md"), []byte("D"))
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("Commit() = %v", err)
+		}
+		want := map[string]string{"_binder.md": "binder2", "a.md": "A2", "sub/c.md": "C", "d.md": "D"}
+		if got := projectFiles(t, dir); !maps.Equal(got, want) {
+			t.Errorf("sync %d: files = %v, want %v", sync, got, want)
+		}
+		if err := tx.Commit(); err == nil {
+			t.Error("second Commit() = nil, want error")
+		}
+		if got := txJournals(t, dir); got != nil {
+			t.Errorf("transaction files %v left", got)
+		}
+	}
+}
+
+func TestTx_StepFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		stage   func(tx *fsio.Tx, dir string)
+		wantErr func(dir string) string
+	}{
+		{
+			name:    "write",
+			stage:   func(tx *fsio.Tx, dir string) { tx.Write(filepath.Join(dir, "a.md", "x.md"), []byte("X")) },
+			wantErr: func(dir string) string { return "writing " + filepath.Join(dir, "a.md", "x.md") },
+		},
+		{
+			name:    "create",
+			stage:   func(tx *fsio.Tx, dir string) { tx.Create(filepath.Join(dir, "b.md"), []byte("C")) },
+			wantErr: func(dir string) string { return "creating " + filepath.Join(dir, "b.md") + ": file already exists" },
+		},
+		{
+			name:    "remove",
+			stage:   func(tx *fsio.Tx, dir string) { tx.Remove(filepath.Join(dir, "missing.md")) },
+			wantErr: func(dir string) string { return "removing " + filepath.Join(dir, "missing.md") },
+		},
+		{
+			name: "read-only binder",
+			stage: func(tx *fsio.Tx, dir string) {
+				binderPath := filepath.Join(dir, "_binder.md")
+				if err := os.Chmod(binderPath, 0o400); err != nil {
+					t.Fatal(err)
+				}
+				tx.WriteBinder(binderPath, []byte("binder2"))
+			},
+			wantErr: func(string) string { return "writing binder: binder file is read-only" },
+		},
+		{
+			name: "staged file gone",
+			stage: func(tx *fsio.Tx, dir string) {
+				tx.Write(filepath.Join(dir, "b.md"), []byte("B2"))
+				if err := os.Remove(stagedWith(t, dir, "B2")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: func(dir string) string { return "writing " + filepath.Join(dir, "b.md") + ": rename " },
+		},
+		{
+			name: "staged binder gone",
+			stage: func(tx *fsio.Tx, dir string) {
+				tx.WriteBinder(filepath.Join(dir, "_binder.md"), []byte("binder2"))
+				if err := os.Remove(stagedWith(t, dir, "binder2")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: func(string) string { return "writing binder: rename " },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := txProject(t)
+			before := projectFiles(t, dir)
+			tx, err := fsio.BeginTx(dir, fsio.SyncNever)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tx.Write(filepath.Join(dir, "c.md"), []byte("C"))
+			tx.Write(filepath.Join(dir, "a.md"), []byte("A2"))
+			tx.Remove(filepath.Join(dir, "_binder.md"))
+			tt.stage(tx, dir)
+			// Steps after a failed one are ignored.
+			tx.Write(filepath.Join(dir, "d.md"), []byte("D"))
+			tx.Remove(filepath.Join(dir, "b.md"))
+			err = tx.Commit()
+			if want := tt.wantErr(dir); err == nil || !strings.Contains(err.Error(), want) || strings.Contains(err.Error(), "rollback") {
+				t.Fatalf("Commit() = %v, want %q alone", err, want)
+			}
+			if err := os.Chmod(filepath.Join(dir, "_binder.md"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if got := projectFiles(t, dir); !maps.Equal(got, before) {
+				t.Errorf("files = %v, want %v", got, before)
+			}
+			if got := txJournals(t, dir); got != nil {
+				t.Errorf("transaction files %v left", got)
+			}
+		})
+	}
+}
+
+func TestTx_RollbackFailure(t *testing.T) {
+	tests := []struct {
+		name  string
+		block func(t *testing.T, dir string) (unblock string)
+	}{
+		{
+			// A directory in place of the staged file takes a.md's place,
+			// and then blocks putting a.md back.
+			name: "staged file replaced",
+			block: func(t *testing.T, dir string) string {
+				staged := stagedWith(t, dir, "A2")
+				if err := os.Remove(staged); err != nil {
+					t.Fatal(err)
+				}
+				mkdirAll(t, filepath.Join(staged, "x"))
+				return filepath.Join(dir, "a.md")
+			},
+		},
+		{
+			// A directory in place of a.md's backup blocks moving a.md
+			// there, and then is taken for the backup.
+			name: "backup taken",
+			block: func(t *testing.T, dir string) string {
+				backup := filepath.Join(dir, ".pmk-tx-"+txID(t, dir)+".0.bak")
+				mkdirAll(t, filepath.Join(backup, "x"))
+				return backup
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := txProject(t)
+			before := projectFiles(t, dir)
+			tx, err := fsio.BeginTx(dir, fsio.SyncNever)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tx.Write(filepath.Join(dir, "a.md"), []byte("A2"))
+			unblock := tt.block(t, dir)
+			tx.Remove(filepath.Join(dir, "missing.md"))
+			if err := tx.Commit(); err == nil || !strings.Contains(err.Error(), "rollback also failed") {
+				t.Fatalf("Commit() = %v, want the rollback's failure too", err)
+			}
+			// The journal is kept, and recovery retries the rollback.
+			if err := os.RemoveAll(unblock); err != nil {
+				t.Fatal(err)
+			}
+			recovered, err := fsio.RecoverTxs(dir)
+			if err != nil || len(recovered) != 1 {
+				t.Errorf("RecoverTxs() = %v, %v; want the kept journal", recovered, err)
+			}
+			if got := projectFiles(t, dir); !maps.Equal(got, before) {
+				t.Errorf("files = %v, want %v", got, before)
+			}
+			if got := txJournals(t, dir); got != nil {
+				t.Errorf("transaction files %v left", got)
+			}
+		})
+	}
+}
+
+func TestTx_JournalFailure(t *testing.T) {
+	dir := txProject(t)
+	before := projectFiles(t, dir)
+	tx, err := fsio.BeginTx(dir, fsio.SyncNever)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A directory where the journal goes cannot be replaced by it.
+	journal := filepath.Join(dir, filepath.FromSlash(fsio.TxDir), txID(t, dir)+".json")
+	mkdirAll(t, filepath.Join(journal, "x"))
+	tx.Write(filepath.Join(dir, "a.md"), []byte("A2"))
+	if err := tx.Commit(); err == nil || !strings.Contains(err.Error(), "writing transaction journal") {
+		t.Fatalf("Commit() = %v, want the journal's failure", err)
+	}
+	if got := projectFiles(t, dir); !maps.Equal(got, before) {
+		t.Errorf("files = %v, want %v", got, before)
+	}
+}
+
+func TestTx_Abort(t *testing.T) {
+	dir := txProject(t)
+	before := projectFiles(t, dir)
+	tx, err := fsio.BeginTx(dir, fsio.SyncNever)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Write(filepath.Join(dir, "a.md"), []byte("A2"))
+	if err := tx.Abort(); err != nil {
+		t.Fatalf("Abort() = %v", err)
+	}
+	if got := projectFiles(t, dir); !maps.Equal(got, before) {
+		t.Errorf("files = %v, want %v", got, before)
+	}
+	if err := tx.Abort(); err != nil {
+		t.Errorf("second Abort() = %v, want nil", err)
+	}
+	if got := txJournals(t, dir); got != nil {
+		t.Errorf("transaction files %v left", got)
+	}
+}
+
+// txJournals returns the names of the files in the transaction directory
+// of the project at dir.
+func txJournals(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(filepath.Join(dir, filepath.FromSlash(fsio.TxDir)))
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+// stagedWith returns the path of the file a transaction staged in dir
+// with content.
+func stagedWith(t *testing.T, dir, content string) string {
+	t.Helper()
+	staged, err := filepath.Glob(filepath.Join(dir, ".pmk-tx-*.stage"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range staged {
+		if data, err := os.ReadFile(path); err == nil && string(data) == content {
+			return path
+		}
+	}
+	t.Fatalf("no file staged with %q among %v", content, staged)
+	return ""
+}
+
+// txID returns the id of the one transaction running in the project at
+// dir, which names its lock file.
+func txID(t *testing.T, dir string) string {
+	t.Helper()
+	for _, name := range txJournals(t, dir) {
+		if id, ok := strings.CutSuffix(name, ".lock"); ok {
+			return id
+		}
+	}
+	t.Fatal("no transaction lock")
+	return ""
+}
+
+// mkdirAll creates the directory at path and its parents.
+func mkdirAll(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeTxJournal writes an interrupted transaction's journal and files to
+// dir as a crash would leave them: a.md already replaced by its staged
+// content and its original in the backup, c.md still staged. With lock
+// set it leaves the transaction's lock file too, unlocked, as the death of
+// its process does. It returns the journal's path.
+func writeTxJournal(t *testing.T, dir string, committed, lock bool) string {
+	t.Helper()
+	files := map[string]string{
+		"a.md":              "A2",
+		".pmk-tx-1.0.bak":   "A",
+		".pmk-tx-1.x.stage": "C",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	type step struct {
+		Path    string `json:"path"`
+		Staged  string `json:"staged,omitempty"`
+		Backup  string `json:"backup"`
+		Started bool   `json:"started,omitempty"`
+		Existed bool   `json:"existed,omitempty"`
+	}
+	journal, _ := json.Marshal(struct {
+		Committed bool   `json:"committed"`
+		Steps     []step `json:"steps"`
+	}{committed, []step{
+		{Path: filepath.Join(dir, "a.md"), Staged: filepath.Join(dir, ".pmk-tx-1.y.stage"), Backup: filepath.Join(dir, ".pmk-tx-1.0.bak"), Started: true, Existed: true},
+		{Path: filepath.Join(dir, "c.md"), Staged: filepath.Join(dir, ".pmk-tx-1.x.stage"), Backup: filepath.Join(dir, ".pmk-tx-1.1.bak")},
+	}})
+	txDir := filepath.Join(dir, filepath.FromSlash(fsio.TxDir))
+	if err := os.MkdirAll(txDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if lock {
+		if err := os.WriteFile(filepath.Join(txDir, "1.lock"), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(txDir, "1.json")
+	if err := os.WriteFile(path, journal, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRecoverTxs(t *testing.T) {
+	original := map[string]string{"_binder.md": "binder", "a.md": "A", "b.md": "B"}
+	tests := []struct {
+		name      string
+		committed bool
+		lock      bool
+		want      map[string]string
+	}{
+		{name: "interrupted", want: original},
+		{name: "interrupted with its lock file", lock: true, want: original},
+		{
+			name:      "committed",
+			committed: true,
+			lock:      true,
+			want:      map[string]string{"_binder.md": "binder", "a.md": "A2", "b.md": "B"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := txProject(t)
+			writeTxJournal(t, dir, tt.committed, tt.lock)
+			recovered, err := fsio.RecoverTxs(dir)
+			if err != nil {
+				t.Fatalf("RecoverTxs() = %v", err)
+			}
+			if want := []string{".prosemark/tx/1.json"}; !slices.Equal(recovered, want) {
+				t.Errorf("recovered = %v, want %v", recovered, want)
+			}
+			if got := projectFiles(t, dir); !maps.Equal(got, tt.want) {
+				t.Errorf("files = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecoverTxs_LeavesLiveTransactions(t *testing.T) {
+	dir := txProject(t)
+	tx, err := fsio.BeginTx(dir, fsio.SyncNever)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Write(filepath.Join(dir, "a.md"), []byte("A2"))
+	// However long ago the transaction last wrote its journal, its lock
+	// shows it is running.
+	journals, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(fsio.TxDir), "*.json"))
+	if err != nil || len(journals) != 1 {
+		t.Fatalf("journals = %v, %v; want one", journals, err)
+	}
+	journal := journals[0]
+	old := time.Now().Add(-24 * time.Hour)
+	if err := os.Chtimes(journal, old, old); err != nil {
+		t.Fatal(err)
+	}
+	recovered, err := fsio.RecoverTxs(dir)
+	if err != nil || recovered != nil {
+		t.Fatalf("RecoverTxs() = %v, %v; want nil, nil", recovered, err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() = %v", err)
+	}
+	want := map[string]string{"_binder.md": "binder", "a.md": "A2", "b.md": "B"}
+	if got := projectFiles(t, dir); !maps.Equal(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
+
+func TestRecoverTxs_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		committed bool
+		setup     func(t *testing.T, dir, journal string)
+		wantErr   string
+	}{
+		{
+			name:      "backup left in place",
+			committed: true,
+			setup: func(t *testing.T, dir, _ string) {
+				backup := filepath.Join(dir, ".pmk-tx-1.0.bak")
+				if err := os.Remove(backup); err != nil {
+					t.Fatal(err)
+				}
+				mkdirAll(t, filepath.Join(backup, "x"))
+			},
+			wantErr: "1.json: remove ",
+		},
+		{
+			name: "unreadable journal",
+			setup: func(t *testing.T, dir, journal string) {
+				if err := os.Remove(journal); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Symlink(dir, journal); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: "1.json: read ",
+		},
+		{
+			name: "unopenable lock",
+			setup: func(t *testing.T, _, journal string) {
+				if err := os.Mkdir(strings.TrimSuffix(journal, ".json")+".lock", 0o755); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: "1.json: open ",
+		},
+		{
+			name: "undo fails",
+			setup: func(t *testing.T, dir, _ string) {
+				// a.md's original cannot be put back over a directory.
+				if err := os.Remove(filepath.Join(dir, "a.md")); err != nil {
+					t.Fatal(err)
+				}
+				mkdirAll(t, filepath.Join(dir, "a.md", "x"))
+			},
+			wantErr: "1.json: rename ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := txProject(t)
+			journal := writeTxJournal(t, dir, tt.committed, false)
+			tt.setup(t, dir, journal)
+			recovered, err := fsio.RecoverTxs(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || recovered != nil {
+				t.Errorf("RecoverTxs() = %v, %v; want %q", recovered, err, tt.wantErr)
+			}
+			if _, err := os.Lstat(journal); err != nil {
+				t.Errorf("journal not kept: %v", err)
+			}
+		})
+	}
+}
+
+func TestRecoverTxs_UndecodableJournal(t *testing.T) {
+	dir := txProject(t)
+	journal := writeTxJournal(t, dir, false, false)
+	if err := os.WriteFile(journal, []byte(`{"steps": [`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	recovered, err := fsio.RecoverTxs(dir)
+	if err != nil || len(recovered) != 1 {
+		t.Errorf("RecoverTxs() = %v, %v; want the journal", recovered, err)
+	}
+	if got := txJournals(t, dir); got != nil {
+		t.Errorf("transaction files %v left", got)
+	}
+}
+
+func TestRecoverTxs_NoJournals(t *testing.T) {
+	recovered, err := fsio.RecoverTxs(t.TempDir())
+	if err != nil || recovered != nil {
+		t.Errorf("RecoverTxs() = %v, %v; want nil, nil", recovered, err)
+	}
+}
+
+func TestRecoverTxs_UnreadableDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".prosemark"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fsio.RecoverTxs(dir); err == nil {
+		t.Error("RecoverTxs() = nil, want an error")
+	}
+	if _, err := fsio.BeginTx(dir, fsio.SyncNever); err == nil || !strings.Contains(err.Error(), "recovering interrupted transaction") {
+		t.Errorf("BeginTx() = %v, want the recovery error", err)
+	}
+}
+
+func TestBeginTx_DirectoryError(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, ".prosemark"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// A dangling symlink reads as no directory but cannot be made one.
+	if err := os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, filepath.FromSlash(fsio.TxDir))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fsio.BeginTx(dir, fsio.SyncNever); err == nil || !strings.Contains(err.Error(), "creating transaction directory") {
+		t.Errorf("BeginTx() = %v, want the directory error", err)
+	}
+}
+
+func TestBeginTx_RecoversInterruptedTransactions(t *testing.T) {
+	dir := txProject(t)
+	writeTxJournal(t, dir, false, true)
+	tx, err := fsio.BeginTx(dir, fsio.SyncNever)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A write after recovery is not undone by a later one: the journal
+	// the crash left, however recent, is gone before anything is written.
+	tx.Write(filepath.Join(dir, "a.md"), []byte("A3"))
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if recovered, err := fsio.RecoverTxs(dir); err != nil || recovered != nil {
+		t.Errorf("RecoverTxs() = %v, %v; want nil, nil", recovered, err)
+	}
+	want := map[string]string{"_binder.md": "binder", "a.md": "A3", "b.md": "B"}
+	if got := projectFiles(t, dir); !maps.Equal(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+	if got := txJournals(t, dir); got != nil {
+		t.Errorf("transaction files %v left", got)
+	}
+}
@@ -0,0 +1,315 @@
+// Package jsonrpc implements the server side of JSON-RPC 2.0 for pmk's
+// long-running modes: reading requests from a stream, handing them to a
+// Handler one at a time, and writing back responses and notifications.
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Version is the protocol version every message carries.
+const Version = "2.0"
+
+// Error codes defined by the JSON-RPC 2.0 specification.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Error is a JSON-RPC error object. A Handler returns one to choose the
+// code; any other error is reported as CodeInternalError.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string { return fmt.Sprintf("jsonrpc error %d: %s", e.Code, e.Message) }
+
+// Errorf returns an Error with code and a formatted message.
+func Errorf(code int, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Request is a request or, when ID is absent, a notification.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// IsNotification reports whether r expects no response.
+func (r *Request) IsNotification() bool { return r.ID == nil }
+
+// DecodeParams unmarshals r's params into v, reporting a malformed value as
+// CodeInvalidParams. Absent params leave v unchanged.
+func (r *Request) DecodeParams(v any) error {
+	if len(r.Params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(r.Params, v); err != nil {
+		return Errorf(CodeInvalidParams, "invalid params for %s: %v", r.Method, err)
+	}
+	return nil
+}
+
+// response is a reply to a request. Result is always present on success,
+// as null when the handler returned nil.
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// notification is a message from the server that expects no reply.
+type notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+// Framing reads and writes whole messages on a stream.
+type Framing interface {
+	// ReadMessage returns the next message, or io.EOF at the end of the
+	// stream.
+	ReadMessage() ([]byte, error)
+	// WriteMessage writes one message.
+	WriteMessage(msg []byte) error
+}
+
+// lineFraming delimits messages with newlines.
+type lineFraming struct {
+	r *bufio.Reader
+	w io.Writer
+}
+
+// LineFraming returns a Framing for newline-delimited JSON on r and w.
+// Blank lines between messages are skipped.
+func LineFraming(r io.Reader, w io.Writer) Framing {
+	return &lineFraming{r: bufio.NewReader(r), w: w}
+}
+
+func (f *lineFraming) ReadMessage() ([]byte, error) {
+	for {
+		line, err := f.r.ReadBytes('\n')
+		if msg := bytes.TrimSpace(line); len(msg) > 0 {
+			return msg, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (f *lineFraming) WriteMessage(msg []byte) error {
+	_, err := f.w.Write(append(msg, '\n'))
+	return err
+}
+
+// MaxHeaderMessageSize is the largest Content-Length HeaderFraming accepts.
+const MaxHeaderMessageSize = 64 << 20
+
+// headerFraming precedes each message with a Content-Length header, as the
+// Language Server Protocol does.
+type headerFraming struct {
+	r *bufio.Reader
+	w io.Writer
+}
+
+// HeaderFraming returns a Framing for messages preceded by HTTP-style
+// headers on r and w. Only Content-Length is read; other headers are
+// ignored.
+func HeaderFraming(r io.Reader, w io.Writer) Framing {
+	return &headerFraming{r: bufio.NewReader(r), w: w}
+}
+
+func (f *headerFraming) ReadMessage() ([]byte, error) {
+	length := -1
+	for first := true; ; first = false {
+		line, err := f.r.ReadString('\n')
+		if errors.Is(err, io.EOF) && first && line == "" {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading header: %w", noEOF(err))
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("malformed header %q", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 0 || n > MaxHeaderMessageSize {
+				return nil, fmt.Errorf("invalid Content-Length %q", strings.TrimSpace(value))
+			}
+			length = n
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("message has no Content-Length header")
+	}
+	msg := make([]byte, length)
+	if _, err := io.ReadFull(f.r, msg); err != nil {
+		return nil, fmt.Errorf("reading message: %w", noEOF(err))
+	}
+	return msg, nil
+}
+
+func (f *headerFraming) WriteMessage(msg []byte) error {
+	_, err := fmt.Fprintf(f.w, "Content-Length: %d\r\n\r\n%s", len(msg), msg)
+	return err
+}
+
+// noEOF reports an end of stream inside a message as unexpected, so that
+// Serve does not mistake it for the stream ending between messages.
+func noEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// Handler answers a request with a result to encode as JSON, or an error.
+// Its result is ignored for notifications.
+type Handler func(ctx context.Context, req *Request) (any, error)
+
+// Conn is one JSON-RPC connection. Writes are serialized, so notifications
+// may be sent from other goroutines while Serve runs.
+type Conn struct {
+	framing Framing
+	mu      sync.Mutex
+}
+
+// NewConn returns a Conn exchanging messages through framing.
+func NewConn(framing Framing) *Conn {
+	return &Conn{framing: framing}
+}
+
+// Notify sends a notification to the client.
+func (c *Conn) Notify(method string, params any) error {
+	data, err := json.Marshal(notification{JSONRPC: Version, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("encoding %s notification: %w", method, err)
+	}
+	return c.write(data)
+}
+
+// write sends one message.
+func (c *Conn) write(msg []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.framing.WriteMessage(msg)
+}
+
+// Serve reads requests and answers them with h, in order, until the stream
+// ends (returning nil), a read or write fails, or ctx is done once the
+// current request is answered. Batches (arrays of requests) are answered
+// with an array of responses.
+func (c *Conn) Serve(ctx context.Context, h Handler) error {
+	for ctx.Err() == nil {
+		msg, err := c.framing.ReadMessage()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading request: %w", err)
+		}
+		if reply := c.handleMessage(ctx, h, msg); reply != nil {
+			if err := c.write(reply); err != nil {
+				return fmt.Errorf("writing response: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// handleMessage answers a request or batch, returning the encoded reply or
+// nil when there is nothing to send.
+func (c *Conn) handleMessage(ctx context.Context, h Handler, msg []byte) []byte {
+	if !bytes.HasPrefix(msg, []byte("[")) {
+		resp := c.handleRequest(ctx, h, msg)
+		if resp == nil {
+			return nil
+		}
+		data, _ := json.Marshal(resp)
+		return data
+	}
+	var batch []json.RawMessage
+	if err := json.Unmarshal(msg, &batch); err != nil {
+		data, _ := json.Marshal(errorResponse(nil, Errorf(CodeParseError, "invalid JSON: %v", err)))
+		return data
+	}
+	if len(batch) == 0 {
+		data, _ := json.Marshal(errorResponse(nil, Errorf(CodeInvalidRequest, "empty batch")))
+		return data
+	}
+	var resps []*response
+	for _, m := range batch {
+		if resp := c.handleRequest(ctx, h, m); resp != nil {
+			resps = append(resps, resp)
+		}
+	}
+	if len(resps) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(resps)
+	return data
+}
+
+// handleRequest answers one request, returning nil for a notification.
+func (c *Conn) handleRequest(ctx context.Context, h Handler, msg []byte) *response {
+	var req Request
+	if err := json.Unmarshal(msg, &req); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return errorResponse(nil, Errorf(CodeParseError, "invalid JSON: %v", err))
+		}
+		return errorResponse(nil, Errorf(CodeInvalidRequest, "invalid request: %v", err))
+	}
+	if req.JSONRPC != Version || req.Method == "" {
+		return errorResponse(req.ID, Errorf(CodeInvalidRequest, "invalid request: want jsonrpc %q and a method", Version))
+	}
+	result, err := h(ctx, &req)
+	if req.IsNotification() {
+		return nil
+	}
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+		return errorResponse(req.ID, rpcErr)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return errorResponse(req.ID, Errorf(CodeInternalError, "encoding result: %v", err))
+	}
+	return &response{JSONRPC: Version, ID: req.ID, Result: data}
+}
+
+// errorResponse returns the error reply to the request with id, or with a
+// null id when the request could not be read.
+func errorResponse(id json.RawMessage, err *Error) *response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &response{JSONRPC: Version, ID: id, Error: err}
+}
@@ -0,0 +1,185 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// echo answers "echo" with its params, fails "fail" with a plain error and
+// "bad" with an invalid-params error, and returns an unencodable result for
+// "chan".
+func echo(_ context.Context, req *Request) (any, error) {
+	switch req.Method {
+	case "echo":
+		var v any
+		if err := req.DecodeParams(&v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	case "fail":
+		return nil, errors.New("boom")
+	case "bad":
+		var n int
+		return nil, req.DecodeParams(&n)
+	case "chan":
+		return make(chan int), nil
+	}
+	return nil, Errorf(CodeMethodNotFound, "unknown method %q", req.Method)
+}
+
+func serve(t *testing.T, input string) string {
+	t.Helper()
+	var out bytes.Buffer
+	if err := NewConn(LineFraming(strings.NewReader(input), &out)).Serve(context.Background(), echo); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	return out.String()
+}
+
+func TestServe(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{"result", `{"jsonrpc":"2.0","id":1,"method":"echo","params":{"a":1}}`, `{"jsonrpc":"2.0","id":1,"result":{"a":1}}`},
+		{"null result", `{"jsonrpc":"2.0","id":"x","method":"echo"}`, `{"jsonrpc":"2.0","id":"x","result":null}`},
+		{"notification", `{"jsonrpc":"2.0","method":"echo"}`, ``},
+		{"failed notification", `{"jsonrpc":"2.0","method":"fail"}`, ``},
+		{"internal error", `{"jsonrpc":"2.0","id":2,"method":"fail"}`, `{"jsonrpc":"2.0","id":2,"error":{"code":-32603,"message":"boom"}}`},
+		{"invalid params", `{"jsonrpc":"2.0","id":3,"method":"bad","params":"x"}`, `"code":-32602`},
+		{"unknown method", `{"jsonrpc":"2.0","id":4,"method":"nope"}`, `{"jsonrpc":"2.0","id":4,"error":{"code":-32601,"message":"unknown method \"nope\""}}`},
+		{"unencodable result", `{"jsonrpc":"2.0","id":5,"method":"chan"}`, `"code":-32603,"message":"encoding result`},
+		{"parse error", `{"jsonrpc":`, `{"jsonrpc":"2.0","id":null,"error":{"code":-32700`},
+		{"not an object", `"hello"`, `"code":-32600`},
+		{"wrong version", `{"jsonrpc":"1.0","id":6,"method":"echo"}`, `{"jsonrpc":"2.0","id":6,"error":{"code":-32600`},
+		{"batch", `[{"jsonrpc":"2.0","id":1,"method":"echo","params":1},{"jsonrpc":"2.0","method":"echo"},{"jsonrpc":"2.0","id":2,"method":"echo","params":2}]`,
+			`[{"jsonrpc":"2.0","id":1,"result":1},{"jsonrpc":"2.0","id":2,"result":2}]`},
+		{"batch of notifications", `[{"jsonrpc":"2.0","method":"echo"}]`, ``},
+		{"empty batch", `[]`, `"code":-32600,"message":"empty batch"`},
+		{"broken batch", `[{]`, `"code":-32700`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := serve(t, tt.input+"\n")
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("output = %q, want none", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) || !strings.HasSuffix(got, "\n") {
+				t.Errorf("output = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServe_Sequence(t *testing.T) {
+	input := "\n" + `{"jsonrpc":"2.0","id":1,"method":"echo","params":"a"}` + "\n\n" +
+		`{"jsonrpc":"2.0","id":2,"method":"echo","params":"b"}` // no final newline
+	want := `{"jsonrpc":"2.0","id":1,"result":"a"}` + "\n" + `{"jsonrpc":"2.0","id":2,"result":"b"}` + "\n"
+	if got := serve(t, input); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestServe_StopsWhenContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var out bytes.Buffer
+	h := func(_ context.Context, req *Request) (any, error) {
+		cancel()
+		return "bye", nil
+	}
+	input := `{"jsonrpc":"2.0","id":1,"method":"shutdown"}` + "\n" + `{"jsonrpc":"2.0","id":2,"method":"echo"}` + "\n"
+	if err := NewConn(LineFraming(strings.NewReader(input), &out)).Serve(ctx, h); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	if want := `{"jsonrpc":"2.0","id":1,"result":"bye"}` + "\n"; out.String() != want {
+		t.Errorf("output = %q, want only the first reply", out.String())
+	}
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) { return 0, errors.New("closed") }
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("reset") }
+
+func TestServe_StreamErrors(t *testing.T) {
+	conn := NewConn(LineFraming(strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"echo"}`+"\n"), errWriter{}))
+	if err := conn.Serve(context.Background(), echo); err == nil || !strings.Contains(err.Error(), "writing response: closed") {
+		t.Errorf("write failure: err = %v", err)
+	}
+	conn = NewConn(LineFraming(errReader{}, io.Discard))
+	if err := conn.Serve(context.Background(), echo); err == nil || !strings.Contains(err.Error(), "reading request: reset") {
+		t.Errorf("read failure: err = %v", err)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	var out bytes.Buffer
+	conn := NewConn(LineFraming(strings.NewReader(""), &out))
+	if err := conn.Notify("changed", map[string]int{"n": 1}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if want := `{"jsonrpc":"2.0","method":"changed","params":{"n":1}}` + "\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+	if err := conn.Notify("bad", make(chan int)); err == nil || !strings.Contains(err.Error(), "encoding bad notification") {
+		t.Errorf("unencodable params: err = %v", err)
+	}
+}
+
+func TestError(t *testing.T) {
+	if got := Errorf(CodeInvalidParams, "need %s", "x").Error(); got != "jsonrpc error -32602: need x" {
+		t.Errorf("Error() = %q", got)
+	}
+}
+
+func TestHeaderFraming(t *testing.T) {
+	body := `{"jsonrpc":"2.0","id":1,"method":"echo","params":"é"}`
+	input := "Content-Length: " + fmt.Sprint(len(body)) + "\r\nContent-Type: application/vscode-jsonrpc; charset=utf-8\r\n\r\n" + body +
+		"content-length: 0\r\n\r\n"
+	var out bytes.Buffer
+	if err := NewConn(HeaderFraming(strings.NewReader(input), &out)).Serve(context.Background(), echo); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	reply := `{"jsonrpc":"2.0","id":1,"result":"é"}`
+	empty := `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"invalid JSON: unexpected end of JSON input"}}`
+	want := fmt.Sprintf("Content-Length: %d\r\n\r\n%sContent-Length: %d\r\n\r\n%s", len(reply), reply, len(empty), empty)
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestHeaderFraming_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"no length", "Content-Type: x\r\n\r\n{}", "no Content-Length header"},
+		{"malformed header", "Content-Length 2\r\n\r\n{}", `malformed header "Content-Length 2"`},
+		{"bad length", "Content-Length: two\r\n\r\n{}", `invalid Content-Length "two"`},
+		{"too long", "Content-Length: 999999999999\r\n\r\n{}", "invalid Content-Length"},
+		{"truncated headers", "Content-Length: 2\r\n", "reading header: unexpected EOF"},
+		{"truncated message", "Content-Length: 20\r\n\r\n{}", "reading message: unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := HeaderFraming(strings.NewReader(tt.input), io.Discard).ReadMessage()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+	if _, err := HeaderFraming(errReader{}, io.Discard).ReadMessage(); err == nil || !strings.Contains(err.Error(), "reading header: reset") {
+		t.Errorf("read failure: err = %v", err)
+	}
+}
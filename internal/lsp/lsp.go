@@ -0,0 +1,522 @@
+// Package lsp is the Language Server Protocol server behind pmk lsp. It
+// keeps the text of the documents an editor has open and answers from the
+// same parser, operations, and doctor audit the CLI uses:
+//
+//   - diagnostics: a binder's parse diagnostics as it is edited, and the
+//     project's doctor findings, on the files they concern, when a document
+//     is opened or saved
+//   - go to definition: from a binder entry or reference definition to its
+//     node file
+//   - document symbols: the binder's outline, or a node file's headings
+//   - rename: of a binder entry's node file, with every reference to it, as
+//     pmk rename does
+//
+// A document is a binder when it is named _binder.md; any other file
+// belongs to the project of the nearest binder above it.
+package lsp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/jsonrpc"
+	"github.com/eykd/prosemark-go/internal/node"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+// diagnosticSource names the server in the diagnostics it publishes.
+const diagnosticSource = "pmk"
+
+// IO handles I/O for the language server. Documents the editor has open are
+// read from the editor, not through IO.
+type IO interface {
+	// FindBinder returns the path of the binder in dir or its nearest
+	// ancestor that has one.
+	FindBinder(dir string) (string, error)
+	// ScanProject scans the project whose binder is binderPath.
+	ScanProject(ctx context.Context, binderPath string) (*binder.Project, error)
+	// ReadNodeFile reads the file at path as stored; a missing file is
+	// os.ErrNotExist.
+	ReadNodeFile(path string) ([]byte, error)
+	// Audit runs a doctor audit of the project whose binder is binderPath.
+	Audit(ctx context.Context, binderPath string) ([]node.AuditDiagnostic, error)
+}
+
+// document is an open text document.
+type document struct {
+	path    string
+	version int
+	text    string
+}
+
+// Server is a language server for one client. Requests are answered one at
+// a time, so its state needs no locking.
+type Server struct {
+	io      IO
+	version string
+
+	conn      *jsonrpc.Conn
+	exit      func()
+	initDone  bool
+	shutdown  bool
+	exitEarly bool
+
+	docs map[string]*document // by URI
+	// audits holds each project's latest doctor findings, and published the
+	// URIs it last published diagnostics for, both by binder path.
+	audits    map[string][]node.AuditDiagnostic
+	published map[string]map[string]bool
+}
+
+// New returns a Server; version is reported to the client.
+func New(io IO, version string) *Server {
+	return &Server{
+		io:        io,
+		version:   version,
+		docs:      make(map[string]*document),
+		audits:    make(map[string][]node.AuditDiagnostic),
+		published: make(map[string]map[string]bool),
+	}
+}
+
+// Serve answers the client on framing until it sends exit or the stream
+// ends. An exit before shutdown is an error, as the protocol requires.
+func (s *Server) Serve(ctx context.Context, framing jsonrpc.Framing) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.conn = jsonrpc.NewConn(framing)
+	s.exit = cancel
+	if err := s.conn.Serve(ctx, s.handle); err != nil {
+		return err
+	}
+	if s.exitEarly {
+		return errors.New("exit requested before shutdown")
+	}
+	return nil
+}
+
+// handle answers one request or notification.
+func (s *Server) handle(ctx context.Context, req *jsonrpc.Request) (any, error) {
+	switch {
+	case req.Method == methodExit:
+		s.exitEarly = !s.shutdown
+		s.exit()
+		return nil, nil
+	case req.Method == methodInitialize:
+		s.initDone = true
+		return initializeResult{
+			Capabilities: serverCapabilities{
+				TextDocumentSync:       textDocumentSyncOptions{OpenClose: true, Change: textDocumentSyncFull, Save: true},
+				DefinitionProvider:     true,
+				DocumentSymbolProvider: true,
+				RenameProvider:         true,
+			},
+			ServerInfo: serverInfo{Name: "pmk", Version: s.version},
+		}, nil
+	case !s.initDone:
+		return nil, jsonrpc.Errorf(codeServerNotInitialized, "initialize first")
+	case s.shutdown:
+		return nil, jsonrpc.Errorf(jsonrpc.CodeInvalidRequest, "the server is shutting down")
+	}
+
+	switch req.Method {
+	case methodInitialized:
+		return nil, nil
+	case methodShutdown:
+		s.shutdown = true
+		return nil, nil
+	case methodDidOpen:
+		var p didOpenParams
+		if err := req.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		path, err := URIToPath(p.TextDocument.URI)
+		if err != nil {
+			return nil, nil // not a file the server can know about
+		}
+		s.docs[p.TextDocument.URI] = &document{path: path, version: p.TextDocument.Version, text: p.TextDocument.Text}
+		s.publish(ctx, path, true)
+		return nil, nil
+	case methodDidChange:
+		var p didChangeParams
+		if err := req.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		doc := s.docs[p.TextDocument.URI]
+		if doc == nil || len(p.ContentChanges) == 0 {
+			return nil, nil
+		}
+		doc.text = p.ContentChanges[len(p.ContentChanges)-1].Text
+		if p.TextDocument.Version != nil {
+			doc.version = *p.TextDocument.Version
+		}
+		s.publish(ctx, doc.path, false)
+		return nil, nil
+	case methodDidSave, methodDidClose:
+		var p documentParams
+		if err := req.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		doc := s.docs[p.TextDocument.URI]
+		if doc == nil {
+			return nil, nil
+		}
+		if req.Method == methodDidClose {
+			delete(s.docs, p.TextDocument.URI)
+		}
+		s.publish(ctx, doc.path, req.Method == methodDidSave)
+		return nil, nil
+	case methodDefinition:
+		var p positionParams
+		if err := req.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		return s.definition(ctx, p)
+	case methodDocumentSymbol:
+		var p documentParams
+		if err := req.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		return s.symbols(ctx, p.TextDocument.URI)
+	case methodRename:
+		var p renameParams
+		if err := req.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		return s.rename(ctx, p)
+	}
+	if req.IsNotification() {
+		return nil, nil // unhandled notifications, such as $/cancelRequest, are ignored
+	}
+	return nil, jsonrpc.Errorf(jsonrpc.CodeMethodNotFound, "unsupported method %q", req.Method)
+}
+
+// isBinder reports whether the file at path is the binder of its project.
+func (s *Server) isBinder(path string) bool {
+	return s.binderFor(path) == path
+}
+
+// binderFor returns the binder path of the project path belongs to, or ""
+// when it belongs to none.
+func (s *Server) binderFor(path string) string {
+	binderPath, err := s.io.FindBinder(filepath.Dir(path))
+	if err != nil {
+		return ""
+	}
+	return binderPath
+}
+
+// parseDoc parses the open binder document at uri against its project.
+func (s *Server) parseDoc(ctx context.Context, uri string) (*document, *core.Parsed, *binder.Project, error) {
+	doc := s.docs[uri]
+	if doc == nil {
+		return nil, nil, nil, jsonrpc.Errorf(jsonrpc.CodeInvalidParams, "document %s is not open", uri)
+	}
+	if !s.isBinder(doc.path) {
+		return doc, nil, nil, nil
+	}
+	proj, err := s.io.ScanProject(ctx, doc.path)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("scanning project: %w", err)
+	}
+	return doc, core.ParseBinder(ctx, []byte(doc.text), proj, core.ParseOptions{}), proj, nil
+}
+
+// publish sends the diagnostics of the project the file at path belongs to:
+// the parse diagnostics of its open binder and its doctor findings, audited
+// afresh when audit is set. Documents that no longer have diagnostics are
+// sent an empty list.
+func (s *Server) publish(ctx context.Context, path string, audit bool) {
+	binderPath := s.binderFor(path)
+	if binderPath == "" {
+		return
+	}
+	if audit {
+		diags, err := s.io.Audit(ctx, binderPath)
+		if err != nil {
+			s.logError(fmt.Sprintf("pmk doctor %s: %v", binderPath, err))
+		} else {
+			s.audits[binderPath] = diags
+		}
+	}
+
+	byURI := make(map[string][]Diagnostic)
+	binderURI := PathToURI(binderPath)
+	if _, open := s.docs[binderURI]; open {
+		byURI[binderURI] = []Diagnostic{}
+		if _, parsed, _, err := s.parseDoc(ctx, binderURI); err != nil {
+			s.logError(err.Error())
+		} else {
+			lines := parsed.Result.Lines
+			for _, d := range parsed.Diagnostics {
+				byURI[binderURI] = append(byURI[binderURI], binderDiagnostic(lines, d))
+			}
+		}
+	}
+	dir := filepath.Dir(binderPath)
+	for _, d := range s.audits[binderPath] {
+		uri := PathToURI(filepath.Join(dir, filepath.FromSlash(d.Path)))
+		// A finding about the whole file sits at its start.
+		at := Position{Line: max(d.Line-1, 0)}
+		byURI[uri] = append(byURI[uri], Diagnostic{
+			Range:    Range{at, at},
+			Severity: auditSeverity(d.Severity),
+			Code:     string(d.Code),
+			Source:   diagnosticSource,
+			Message:  d.Message,
+		})
+	}
+
+	published := s.published[binderPath]
+	for uri := range published {
+		if _, ok := byURI[uri]; !ok {
+			byURI[uri] = []Diagnostic{}
+		}
+	}
+	s.published[binderPath] = make(map[string]bool)
+	uris := make([]string, 0, len(byURI))
+	for uri := range byURI {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	for _, uri := range uris {
+		if len(byURI[uri]) > 0 {
+			s.published[binderPath][uri] = true
+		}
+		_ = s.conn.Notify(methodPublishDiagnostics, publishDiagnosticsParams{URI: uri, Diagnostics: byURI[uri]})
+	}
+}
+
+// logError shows msg in the client's log.
+func (s *Server) logError(msg string) {
+	_ = s.conn.Notify(methodLogMessage, logMessageParams{Type: messageTypeError, Message: msg})
+}
+
+// binderDiagnostic converts a parse diagnostic, spanning from its column to
+// the end of its line.
+func binderDiagnostic(lines []string, d binder.Diagnostic) Diagnostic {
+	out := Diagnostic{Code: d.Code, Source: diagnosticSource, Message: d.Message, Severity: severityInformation}
+	switch d.Severity {
+	case "error":
+		out.Severity = severityError
+	case "warning":
+		out.Severity = severityWarning
+	}
+	if d.Location != nil && d.Location.Line >= 1 && d.Location.Line <= len(lines) {
+		out.Range = Range{position(lines, d.Location.Line, d.Location.Column), lineEnd(lines, d.Location.Line)}
+	}
+	return out
+}
+
+// auditSeverity converts a doctor finding's severity.
+func auditSeverity(sev node.AuditSeverity) int {
+	if sev == node.SeverityError {
+		return severityError
+	}
+	return severityWarning
+}
+
+// targetAt returns the node file target of the binder entry or reference
+// definition on the 0-based line, or "" when there is none.
+func targetAt(parsed *core.Parsed, line int) string {
+	line++
+	var found string
+	var walk func(n *binder.Node)
+	walk = func(n *binder.Node) {
+		for _, c := range n.Children {
+			if c.Target != "" && c.Line <= line && line <= max(c.EndLine, c.Line) {
+				found = c.Target
+			}
+			walk(c)
+		}
+	}
+	walk(parsed.Result.Root)
+	for _, def := range parsed.Result.RefDefs {
+		if def.Line == line {
+			found = def.Target
+		}
+	}
+	return found
+}
+
+// definition answers go to definition: the node file of the entry at the
+// position.
+func (s *Server) definition(ctx context.Context, p positionParams) (any, error) {
+	doc, parsed, _, err := s.parseDoc(ctx, p.TextDocument.URI)
+	if err != nil || parsed == nil {
+		return nil, err
+	}
+	target := targetAt(parsed, p.Position.Line)
+	if target == "" {
+		return nil, nil
+	}
+	path, err := safepath.Resolve(filepath.Dir(doc.path), target)
+	if err != nil {
+		return nil, nil // a target outside the project has no definition to go to
+	}
+	return []Location{{URI: PathToURI(path)}}, nil
+}
+
+// symbols answers document symbols: the binder's outline, or the headings
+// of any other document.
+func (s *Server) symbols(ctx context.Context, uri string) (any, error) {
+	doc, parsed, _, err := s.parseDoc(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	if parsed == nil {
+		return headingSymbols(doc.text), nil
+	}
+	lines := parsed.Result.Lines
+	var outline func(n *binder.Node) []DocumentSymbol
+	outline = func(n *binder.Node) []DocumentSymbol {
+		syms := []DocumentSymbol{}
+		for _, c := range n.Children {
+			name, detail := c.Title, c.Target
+			if c.Target == "" {
+				detail = "placeholder"
+			}
+			if name == "" {
+				name = orDefault(c.Target, "(untitled)")
+			}
+			syms = append(syms, DocumentSymbol{
+				Name:           name,
+				Detail:         detail,
+				Kind:           symbolKindFile,
+				Range:          Range{position(lines, c.Line, 1), lineEnd(lines, lastLine(c))},
+				SelectionRange: Range{position(lines, c.Line, c.Indent+1), lineEnd(lines, c.Line)},
+				Children:       outline(c),
+			})
+		}
+		return syms
+	}
+	return outline(parsed.Result.Root), nil
+}
+
+// headingSymbols returns the headings of a node file's text, each nesting
+// the deeper headings after it and spanning the lines up to the next heading
+// at its level or above.
+func headingSymbols(text string) []DocumentSymbol {
+	lines := strings.Split(text, "\n")
+	headings := node.ParseHeadings([]byte(text))
+	var build func(i, level int) ([]DocumentSymbol, int)
+	build = func(i, level int) ([]DocumentSymbol, int) {
+		syms := []DocumentSymbol{}
+		for i < len(headings) && headings[i].Level > level {
+			h := headings[i]
+			children, next := build(i+1, h.Level)
+			last := len(lines)
+			if next < len(headings) {
+				last = headings[next].Line - 1
+			}
+			syms = append(syms, DocumentSymbol{
+				Name:           h.Text,
+				Detail:         strings.Repeat("#", h.Level),
+				Kind:           symbolKindString,
+				Range:          Range{position(lines, h.Line, 1), lineEnd(lines, max(last, h.Line))},
+				SelectionRange: Range{position(lines, h.Line, 1), lineEnd(lines, h.Line)},
+				Children:       children,
+			})
+			i = next
+		}
+		return syms, i
+	}
+	syms, _ := build(0, 0)
+	return syms
+}
+
+// lastLine returns the last line of n's subtree.
+func lastLine(n *binder.Node) int {
+	last := max(n.Line, n.EndLine)
+	if len(n.Children) > 0 {
+		last = max(last, lastLine(n.Children[len(n.Children)-1]))
+	}
+	return last
+}
+
+// orDefault returns s, or def when s is empty.
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// rename answers rename: the node file of the entry at the position moves
+// to the new name, with its notes file, and every binder reference follows
+// it. A frontmatter id equal to the old filename stem is changed first.
+func (s *Server) rename(ctx context.Context, p renameParams) (any, error) {
+	doc, parsed, proj, err := s.parseDoc(ctx, p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	var target string
+	if parsed != nil {
+		target = targetAt(parsed, p.Position.Line)
+	}
+	if target == "" {
+		return nil, jsonrpc.Errorf(codeRequestFailed, "there is no binder entry here to rename")
+	}
+
+	params := binder.RenameParams{Selector: target, NewTarget: p.NewName}
+	modified, oldTarget, _, diags := ops.Rename(ctx, []byte(doc.text), proj, params)
+	var errs []string
+	for _, d := range diags {
+		if d.Severity == "error" {
+			errs = append(errs, d.Code+": "+d.Message)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, jsonrpc.Errorf(codeRequestFailed, "%s", strings.Join(errs, "; "))
+	}
+	edit := WorkspaceEdit{DocumentChanges: []any{}}
+	if string(modified) == doc.text {
+		return edit, nil
+	}
+
+	dir := filepath.Dir(doc.path)
+	oldStem := strings.TrimSuffix(oldTarget, ".md")
+	newStem := strings.TrimSuffix(ops.NormalizeTarget(p.NewName), ".md")
+	oldPath, newPath := filepath.Join(dir, oldStem+".md"), filepath.Join(dir, newStem+".md")
+	moves := [][2]string{{oldPath, newPath}}
+	notesPath := filepath.Join(dir, oldStem+".notes.md")
+	if _, err := s.io.ReadNodeFile(notesPath); err == nil {
+		moves = append(moves, [2]string{notesPath, filepath.Join(dir, newStem+".notes.md")})
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading notes file: %w", err)
+	}
+	for _, m := range moves {
+		if _, err := s.io.ReadNodeFile(m[1]); err == nil {
+			return nil, jsonrpc.Errorf(codeRequestFailed, "%s already exists", m[1])
+		}
+	}
+
+	original, err := s.io.ReadNodeFile(oldPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading node file: %w", err)
+	}
+	if content, changed := node.RenameID(original, filepath.Base(oldStem), filepath.Base(newStem)); changed {
+		edit.DocumentChanges = append(edit.DocumentChanges, replaceAll(PathToURI(oldPath), nil, string(original), string(content)))
+	}
+	for _, m := range moves {
+		edit.DocumentChanges = append(edit.DocumentChanges, renameFile{Kind: "rename", OldURI: PathToURI(m[0]), NewURI: PathToURI(m[1])})
+	}
+	edit.DocumentChanges = append(edit.DocumentChanges, replaceAll(p.TextDocument.URI, &doc.version, doc.text, string(modified)))
+	return edit, nil
+}
+
+// replaceAll returns the edit replacing all of a document's text.
+func replaceAll(uri string, version *int, text, newText string) textDocumentEdit {
+	return textDocumentEdit{
+		TextDocument: versionedDocument{URI: uri, Version: version},
+		Edits:        []TextEdit{{Range: Range{End: endOf(text)}, NewText: newText}},
+	}
+}
@@ -0,0 +1,601 @@
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/jsonrpc"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// fakeIO is a project at /proj, or at root when it is set, whose binder is
+// _binder.md unless binderName is set.
+type fakeIO struct {
+	root       string
+	binderName string
+	files      map[string]string // by path
+	audit      []node.AuditDiagnostic
+	auditErr   error
+	scanErr    error
+	readErr    error
+}
+
+func (f *fakeIO) FindBinder(dir string) (string, error) {
+	root := f.root
+	if root == "" {
+		root = "/proj"
+	}
+	name := f.binderName
+	if name == "" {
+		name = "_binder.md"
+	}
+	if strings.HasPrefix(dir, root) {
+		return filepath.Join(root, name), nil
+	}
+	return "", errors.New("no binder")
+}
+
+func (f *fakeIO) ScanProject(_ context.Context, _ string) (*binder.Project, error) {
+	if f.scanErr != nil {
+		return nil, f.scanErr
+	}
+	proj := &binder.Project{BinderDir: ".", Files: []string{}}
+	for path := range f.files {
+		if rel := strings.TrimPrefix(path, "/proj/"); rel != "_binder.md" {
+			proj.Files = append(proj.Files, rel)
+		}
+	}
+	return proj, nil
+}
+
+func (f *fakeIO) ReadNodeFile(path string) ([]byte, error) {
+	if f.readErr != nil {
+		return nil, f.readErr
+	}
+	if content, ok := f.files[path]; ok {
+		return []byte(content), nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func (f *fakeIO) Audit(_ context.Context, _ string) ([]node.AuditDiagnostic, error) {
+	return f.audit, f.auditErr
+}
+
+const testBinder = "<!-- prosemark-binder:v1 -->\n" +
+	"- [Partie é](part.md)\n" +
+	"  - [Chapter One](chapter-one.md)\n" +
+	"- [Nine][c9]\n" +
+	"\n" +
+	"[c9]: chapter-9.md\n"
+
+func newFakeIO() *fakeIO {
+	return &fakeIO{files: map[string]string{
+		"/proj/_binder.md":           testBinder,
+		"/proj/part.md":              "",
+		"/proj/chapter-one.md":       "---\nid: chapter-one\n---\nWords.\n",
+		"/proj/chapter-9.md":         "",
+		"/proj/chapter-one.notes.md": "Notes.\n",
+	}}
+}
+
+const binderURI = "file:///proj/_binder.md"
+
+// message is a decoded message from the server.
+type message struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *jsonrpc.Error  `json:"error"`
+}
+
+// request frames a request with id, or a notification when id is 0.
+func request(id int, method string, params any) string {
+	msg := map[string]any{"jsonrpc": "2.0", "method": method}
+	if id != 0 {
+		msg["id"] = id
+	}
+	if params != nil {
+		msg["params"] = params
+	}
+	data, _ := json.Marshal(msg)
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(data), data)
+}
+
+// session frames an initialize request, then msgs.
+func session(msgs ...string) string {
+	return request(1000, methodInitialize, map[string]any{}) + strings.Join(msgs, "")
+}
+
+// run serves input with a new Server on io, returning its messages and
+// Serve's error.
+func run(t *testing.T, io IO, input string) ([]message, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := New(io, "1.2.3").Serve(context.Background(), jsonrpc.HeaderFraming(strings.NewReader(input), &out))
+	var msgs []message
+	framing := jsonrpc.HeaderFraming(&out, nil)
+	for {
+		data, rerr := framing.ReadMessage()
+		if rerr != nil {
+			break
+		}
+		var m message
+		if jerr := json.Unmarshal(data, &m); jerr != nil {
+			t.Fatalf("message %s: %v", data, jerr)
+		}
+		msgs = append(msgs, m)
+	}
+	return msgs, err
+}
+
+// reply returns the response to the request with id.
+func reply(t *testing.T, msgs []message, id int) message {
+	t.Helper()
+	for _, m := range msgs {
+		if m.ID != nil && *m.ID == id {
+			return m
+		}
+	}
+	t.Fatalf("no reply to request %d", id)
+	return message{}
+}
+
+// published returns the diagnostics of the publishDiagnostics notifications
+// in msgs, in order.
+func published(t *testing.T, msgs []message) []publishDiagnosticsParams {
+	t.Helper()
+	var out []publishDiagnosticsParams
+	for _, m := range msgs {
+		if m.Method == methodPublishDiagnostics {
+			var p publishDiagnosticsParams
+			if err := json.Unmarshal(m.Params, &p); err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func openBinder(text string) string {
+	return request(0, methodDidOpen, map[string]any{"textDocument": map[string]any{"uri": binderURI, "version": 1, "text": text}})
+}
+
+func TestServe_Lifecycle(t *testing.T) {
+	msgs, err := run(t, newFakeIO(),
+		request(1, methodShutdown, nil)+
+			request(0, methodDidOpen, nil)+
+			session(
+				request(0, methodInitialized, map[string]any{}),
+				request(0, "$/cancelRequest", map[string]any{"id": 1}),
+				request(2, "workspace/symbol", map[string]any{}),
+				request(3, methodShutdown, nil),
+				request(4, methodDefinition, map[string]any{}),
+				request(0, methodExit, nil),
+				request(5, methodShutdown, nil),
+			))
+	if err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	if e := reply(t, msgs, 1).Error; e == nil || e.Code != codeServerNotInitialized {
+		t.Errorf("request before initialize: error = %+v", e)
+	}
+	var init initializeResult
+	if err := json.Unmarshal(reply(t, msgs, 1000).Result, &init); err != nil {
+		t.Fatal(err)
+	}
+	caps := init.Capabilities
+	if !caps.DefinitionProvider || !caps.DocumentSymbolProvider || !caps.RenameProvider || caps.TextDocumentSync.Change != textDocumentSyncFull || init.ServerInfo.Version != "1.2.3" {
+		t.Errorf("initialize result = %+v", init)
+	}
+	if e := reply(t, msgs, 2).Error; e == nil || e.Code != jsonrpc.CodeMethodNotFound {
+		t.Errorf("unsupported method: error = %+v", e)
+	}
+	if m := reply(t, msgs, 3); m.Error != nil || string(m.Result) != "null" {
+		t.Errorf("shutdown reply = %+v", m)
+	}
+	if e := reply(t, msgs, 4).Error; e == nil || e.Code != jsonrpc.CodeInvalidRequest {
+		t.Errorf("request after shutdown: error = %+v", e)
+	}
+	if len(msgs) != 5 {
+		t.Errorf("got %d messages, want 5: requests after exit are not read", len(msgs))
+	}
+}
+
+func TestServe_ExitBeforeShutdown(t *testing.T) {
+	if _, err := run(t, newFakeIO(), session(request(0, methodExit, nil))); err == nil || !strings.Contains(err.Error(), "before shutdown") {
+		t.Errorf("err = %v", err)
+	}
+	if _, err := run(t, newFakeIO(), "Content-Length: x\r\n\r\n"); err == nil {
+		t.Error("expected framing error")
+	}
+}
+
+func TestServe_Diagnostics(t *testing.T) {
+	fio := newFakeIO()
+	delete(fio.files, "/proj/chapter-9.md")
+	fio.audit = []node.AuditDiagnostic{{Code: "AUD001", Severity: node.SeverityError, Message: "orphan", Path: "orphan.md", Line: 2}}
+	fixed := strings.Replace(testBinder, "- [Nine][c9]\n\n[c9]: chapter-9.md\n", "", 1)
+	msgs, err := run(t, fio, session(
+		openBinder(testBinder),
+		request(0, methodDidChange, map[string]any{
+			"textDocument":   map[string]any{"uri": binderURI, "version": 2},
+			"contentChanges": []map[string]any{{"text": fixed}},
+		}),
+		request(0, methodDidClose, map[string]any{"textDocument": map[string]any{"uri": binderURI}}),
+		openBinder(testBinder),
+		request(0, methodDidClose, map[string]any{"textDocument": map[string]any{"uri": binderURI}}),
+	))
+	if err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	got := published(t, msgs)
+	if len(got) != 9 {
+		t.Fatalf("published %d times, want 9: %+v", len(got), got)
+	}
+	opened, orphan := got[0], got[1]
+	if opened.URI != binderURI || len(opened.Diagnostics) == 0 {
+		t.Fatalf("binder diagnostics on open = %+v", opened)
+	}
+	d := opened.Diagnostics[0]
+	if !strings.HasPrefix(d.Code, "BNDW") || d.Severity != severityWarning || d.Source != "pmk" || d.Range != (Range{Position{3, 0}, Position{3, 12}}) {
+		t.Errorf("missing file diagnostic = %+v", d)
+	}
+	if orphan.URI != "file:///proj/orphan.md" || len(orphan.Diagnostics) != 1 || orphan.Diagnostics[0].Severity != severityError || orphan.Diagnostics[0].Code != "AUD001" || orphan.Diagnostics[0].Range != (Range{Position{1, 0}, Position{1, 0}}) {
+		t.Errorf("audit diagnostics = %+v", orphan)
+	}
+	if changed := got[2]; changed.URI != binderURI || len(changed.Diagnostics) != 0 {
+		t.Errorf("binder diagnostics after fix = %+v", changed)
+	}
+	if kept := got[4]; kept.URI != "file:///proj/orphan.md" || len(kept.Diagnostics) != 1 {
+		t.Errorf("audit diagnostics after close = %+v", kept)
+	}
+	if closed := got[7]; closed.URI != binderURI || len(closed.Diagnostics) != 0 {
+		t.Errorf("binder diagnostics after closing with warnings = %+v", closed)
+	}
+}
+
+func TestServe_DiagnosticsOfNodeFiles(t *testing.T) {
+	fio := newFakeIO()
+	fio.audit = []node.AuditDiagnostic{{Code: "AUD002", Severity: node.SeverityWarning, Message: "stale", Path: "part.md"}}
+	nodeURI := "file:///proj/part.md"
+	msgs, err := run(t, fio, session(
+		request(0, methodDidOpen, map[string]any{"textDocument": map[string]any{"uri": nodeURI, "version": 1, "text": ""}}),
+		request(0, methodDidOpen, map[string]any{"textDocument": map[string]any{"uri": "file:///elsewhere/a.md", "version": 1, "text": ""}}),
+		request(0, methodDidOpen, map[string]any{"textDocument": map[string]any{"uri": "untitled:Untitled-1", "version": 1, "text": ""}}),
+		request(0, methodDidSave, map[string]any{"textDocument": map[string]any{"uri": nodeURI}}),
+		request(0, methodDidSave, map[string]any{"textDocument": map[string]any{"uri": "file:///proj/closed.md"}}),
+		request(0, methodDidChange, map[string]any{"textDocument": map[string]any{"uri": "file:///proj/closed.md"}, "contentChanges": []any{}}),
+	))
+	if err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	got := published(t, msgs)
+	if len(got) != 2 || got[0].URI != nodeURI || got[0].Diagnostics[0].Severity != severityWarning || got[1].URI != nodeURI {
+		t.Errorf("published = %+v", got)
+	}
+}
+
+func TestServe_DiagnosticsOfInvalidBinder(t *testing.T) {
+	msgs, err := run(t, newFakeIO(), session(openBinder("<!-- prosemark-binder:v1 -->\n- [A](../a.md)\n")))
+	if err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	got := published(t, msgs)
+	if len(got) != 1 || len(got[0].Diagnostics) == 0 || got[0].Diagnostics[len(got[0].Diagnostics)-1].Severity != severityError {
+		t.Errorf("published = %+v", got)
+	}
+}
+
+func TestServe_DiagnosticsOfConfiguredBinder(t *testing.T) {
+	fio := newFakeIO()
+	fio.binderName = "outline.md"
+	uri := "file:///proj/outline.md"
+	msgs, err := run(t, fio, session(
+		request(0, methodDidOpen, map[string]any{"textDocument": map[string]any{"uri": uri, "version": 1, "text": "<!-- prosemark-binder:v1 -->\n- [A](../a.md)\n"}}),
+	))
+	if err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	got := published(t, msgs)
+	if len(got) == 0 || got[0].URI != uri || len(got[0].Diagnostics) == 0 {
+		t.Errorf("published = %+v, want the parse diagnostics of the configured binder", got)
+	}
+}
+
+func TestServe_DiagnosticsErrors(t *testing.T) {
+	fio := newFakeIO()
+	fio.auditErr = errors.New("denied")
+	fio.scanErr = errors.New("gone")
+	msgs, err := run(t, fio, session(
+		openBinder(testBinder),
+		request(1, methodDocumentSymbol, map[string]any{"textDocument": map[string]any{"uri": binderURI}}),
+	))
+	if err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	if e := reply(t, msgs, 1).Error; e == nil || !strings.Contains(e.Message, "scanning project: gone") {
+		t.Errorf("document symbols: error = %+v", e)
+	}
+	var logged []string
+	for _, m := range msgs {
+		if m.Method == methodLogMessage {
+			var p logMessageParams
+			_ = json.Unmarshal(m.Params, &p)
+			logged = append(logged, p.Message)
+		}
+	}
+	if want := []string{"pmk doctor /proj/_binder.md: denied", "scanning project: gone"}; strings.Join(logged, "|") != strings.Join(want, "|") {
+		t.Errorf("logged = %q, want %q", logged, want)
+	}
+}
+
+func TestServe_InvalidParams(t *testing.T) {
+	methods := []string{methodDidOpen, methodDidChange, methodDidSave, methodDefinition, methodDocumentSymbol, methodRename}
+	var reqs []string
+	for i, m := range methods {
+		reqs = append(reqs, request(i+1, m, []int{1}))
+	}
+	msgs, err := run(t, newFakeIO(), session(reqs...))
+	if err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	for i, m := range methods {
+		if e := reply(t, msgs, i+1).Error; e == nil || e.Code != jsonrpc.CodeInvalidParams {
+			t.Errorf("%s: error = %+v", m, e)
+		}
+	}
+}
+
+func at(uri string, line, char int) map[string]any {
+	return map[string]any{"textDocument": map[string]any{"uri": uri}, "position": map[string]any{"line": line, "character": char}}
+}
+
+func TestServe_Definition(t *testing.T) {
+	msgs, err := run(t, newFakeIO(), session(
+		openBinder(testBinder),
+		request(0, methodDidOpen, map[string]any{"textDocument": map[string]any{"uri": "file:///proj/part.md", "version": 1, "text": ""}}),
+		request(1, methodDefinition, at(binderURI, 2, 5)),
+		request(2, methodDefinition, at(binderURI, 5, 0)),
+		request(3, methodDefinition, at(binderURI, 4, 0)),
+		request(4, methodDefinition, at("file:///proj/part.md", 0, 0)),
+		request(5, methodDefinition, at("file:///proj/shut.md", 0, 0)),
+	))
+	if err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	for id, want := range map[int]string{1: "file:///proj/chapter-one.md", 2: "file:///proj/chapter-9.md"} {
+		var locs []Location
+		if err := json.Unmarshal(reply(t, msgs, id).Result, &locs); err != nil || len(locs) != 1 || locs[0].URI != want {
+			t.Errorf("definition %d = %s, want %s", id, reply(t, msgs, id).Result, want)
+		}
+	}
+	for _, id := range []int{3, 4} {
+		if m := reply(t, msgs, id); m.Error != nil || string(m.Result) != "null" {
+			t.Errorf("definition %d = %+v, want null", id, m)
+		}
+	}
+	if e := reply(t, msgs, 5).Error; e == nil || e.Code != jsonrpc.CodeInvalidParams {
+		t.Errorf("definition in a closed document: error = %+v", e)
+	}
+}
+
+func TestServe_DocumentSymbols(t *testing.T) {
+	text := strings.Replace(testBinder, "- [Nine][c9]", "- [][c9]\n- [Placeholder]()\n- []()", 1)
+	msgs, err := run(t, newFakeIO(), session(
+		openBinder(text),
+		request(1, methodDocumentSymbol, map[string]any{"textDocument": map[string]any{"uri": binderURI}}),
+	))
+	if err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	var syms []DocumentSymbol
+	if err := json.Unmarshal(reply(t, msgs, 1).Result, &syms); err != nil {
+		t.Fatal(err)
+	}
+	if len(syms) != 4 {
+		t.Fatalf("symbols = %+v", syms)
+	}
+	part := syms[0]
+	if part.Name != "Partie é" || part.Detail != "part.md" || part.Kind != symbolKindFile ||
+		part.Range != (Range{Position{1, 0}, Position{2, 33}}) ||
+		part.SelectionRange != (Range{Position{1, 0}, Position{1, 21}}) {
+		t.Errorf("part symbol = %+v", part)
+	}
+	if len(part.Children) != 1 || part.Children[0].Name != "Chapter One" || part.Children[0].SelectionRange.Start != (Position{2, 2}) {
+		t.Errorf("part children = %+v", part.Children)
+	}
+	if syms[1].Name != "chapter-9" && syms[1].Name != "chapter-9.md" {
+		t.Errorf("untitled symbol = %+v", syms[1])
+	}
+	if syms[2].Detail != "placeholder" || syms[2].Name != "Placeholder" {
+		t.Errorf("placeholder symbol = %+v", syms[2])
+	}
+	if syms[3].Name != "(untitled)" {
+		t.Errorf("untitled placeholder symbol = %+v", syms[3])
+	}
+}
+
+func TestServe_Rename(t *testing.T) {
+	msgs, err := run(t, newFakeIO(), session(
+		openBinder(testBinder),
+		request(1, methodRename, map[string]any{"textDocument": map[string]any{"uri": binderURI}, "position": map[string]any{"line": 2, "character": 8}, "newName": "chapter-1.md"}),
+	))
+	if err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	var edit struct {
+		DocumentChanges []json.RawMessage `json:"documentChanges"`
+	}
+	if err := json.Unmarshal(reply(t, msgs, 1).Result, &edit); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`{"textDocument":{"uri":"file:///proj/chapter-one.md","version":null},"edits":[{"range":{"start":{"line":0,"character":0},"end":{"line":4,"character":0}},"newText":"---\nid: chapter-1\n---\nWords.\n"}]}`,
+		`{"kind":"rename","oldUri":"file:///proj/chapter-one.md","newUri":"file:///proj/chapter-1.md"}`,
+		`{"kind":"rename","oldUri":"file:///proj/chapter-one.notes.md","newUri":"file:///proj/chapter-1.notes.md"}`,
+		`{"textDocument":{"uri":"file:///proj/_binder.md","version":1},"edits":[{"range":{"start":{"line":0,"character":0},"end":{"line":6,"character":0}},"newText":` +
+			mustJSON(strings.Replace(testBinder, "(chapter-one.md)", "(chapter-1.md)", 1)) + `}]}`,
+	}
+	if len(edit.DocumentChanges) != len(want) {
+		t.Fatalf("documentChanges = %s", reply(t, msgs, 1).Result)
+	}
+	for i, w := range want {
+		if string(edit.DocumentChanges[i]) != w {
+			t.Errorf("change %d = %s, want %s", i, edit.DocumentChanges[i], w)
+		}
+	}
+}
+
+func mustJSON(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func TestServe_RenameWithoutIDOrNotes(t *testing.T) {
+	fio := newFakeIO()
+	fio.files["/proj/part.md"] = "Words.\n"
+	msgs, err := run(t, fio, session(
+		openBinder(testBinder),
+		request(1, methodRename, map[string]any{"textDocument": map[string]any{"uri": binderURI}, "position": map[string]any{"line": 1}, "newName": "partie.md"}),
+		request(2, methodRename, map[string]any{"textDocument": map[string]any{"uri": binderURI}, "position": map[string]any{"line": 1}, "newName": "part.md"}),
+	))
+	if err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	var edit WorkspaceEdit
+	if err := json.Unmarshal(reply(t, msgs, 1).Result, &edit); err != nil || len(edit.DocumentChanges) != 2 {
+		t.Errorf("rename = %s", reply(t, msgs, 1).Result)
+	}
+	if string(reply(t, msgs, 2).Result) != `{"documentChanges":[]}` {
+		t.Errorf("rename to the same name = %s", reply(t, msgs, 2).Result)
+	}
+}
+
+func TestServe_RenameErrors(t *testing.T) {
+	renameAt := func(id, line int, newName string) string {
+		return request(id, methodRename, map[string]any{"textDocument": map[string]any{"uri": binderURI}, "position": map[string]any{"line": line}, "newName": newName})
+	}
+	tests := []struct {
+		name string
+		io   func() *fakeIO
+		req  string
+		code int
+		want string
+	}{
+		{"no entry", newFakeIO, renameAt(1, 0, "x"), codeRequestFailed, "no binder entry here"},
+		{"operation error", newFakeIO, renameAt(1, 1, "_binder.md"), codeRequestFailed, "OPE"},
+		{"target exists", newFakeIO, renameAt(1, 1, "chapter-9.md"), codeRequestFailed, "PMKE006: chapter-9.md already exists"},
+		{"notes exist", func() *fakeIO { f := newFakeIO(); f.files["/proj/chapter-1.notes.md"] = ""; return f }, renameAt(1, 2, "chapter-1.md"), codeRequestFailed, "chapter-1.notes.md already exists"},
+		{"read error", func() *fakeIO { f := newFakeIO(); f.readErr = errors.New("denied"); return f }, renameAt(1, 1, "partie.md"), jsonrpc.CodeInternalError, "reading notes file: denied"},
+		{"node file missing", func() *fakeIO { f := newFakeIO(); delete(f.files, "/proj/part.md"); return f }, renameAt(1, 1, "partie.md"), jsonrpc.CodeInternalError, "reading node file"},
+		{"scan error", func() *fakeIO { f := newFakeIO(); f.scanErr = errors.New("gone"); return f }, renameAt(1, 1, "partie.md"), jsonrpc.CodeInternalError, "scanning project: gone"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msgs, err := run(t, tt.io(), session(openBinder(testBinder), tt.req))
+			if err != nil {
+				t.Fatalf("Serve: %v", err)
+			}
+			if e := reply(t, msgs, 1).Error; e == nil || e.Code != tt.code || !strings.Contains(e.Message, tt.want) {
+				t.Errorf("error = %+v, want code %d containing %q", e, tt.code, tt.want)
+			}
+		})
+	}
+}
+
+func TestURIs(t *testing.T) {
+	path := filepath.FromSlash("/proj/a b/é.md")
+	uri := PathToURI(path)
+	if uri != "file:///proj/a%20b/%C3%A9.md" {
+		t.Errorf("PathToURI = %q", uri)
+	}
+	if got, err := URIToPath(uri); err != nil || got != path {
+		t.Errorf("URIToPath = %q, %v", got, err)
+	}
+	for _, bad := range []string{"untitled:1", "%zz"} {
+		if _, err := URIToPath(bad); err == nil {
+			t.Errorf("URIToPath(%q): expected error", bad)
+		}
+	}
+}
+
+func TestPositions(t *testing.T) {
+	lines := []string{"a😀b", "é"}
+	if got := position(lines, 1, 6); got != (Position{0, 3}) {
+		t.Errorf("position after an astral rune = %+v", got)
+	}
+	if got := position(lines, 3, 1); got != (Position{2, 0}) {
+		t.Errorf("position past the end = %+v", got)
+	}
+	if got := lineEnd(lines, 0); got != (Position{0, 0}) {
+		t.Errorf("lineEnd before the start = %+v", got)
+	}
+	if got := endOf("a\nb\xff"); got != (Position{1, 2}) {
+		t.Errorf("endOf = %+v", got)
+	}
+}
+
+func TestServe_DocumentSymbols_Headings(t *testing.T) {
+	text := "---\ntitle: Part\n---\n# Part\n\n## Scene One\nText.\n### Beat\n## Scene Two\n# Coda\n"
+	msgs, err := run(t, newFakeIO(), session(
+		request(0, methodDidOpen, map[string]any{"textDocument": map[string]any{"uri": "file:///proj/part.md", "version": 1, "text": text}}),
+		request(1, methodDocumentSymbol, map[string]any{"textDocument": map[string]any{"uri": "file:///proj/part.md"}}),
+	))
+	if err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	var syms []DocumentSymbol
+	if err := json.Unmarshal(reply(t, msgs, 1).Result, &syms); err != nil {
+		t.Fatal(err)
+	}
+	if len(syms) != 2 || syms[0].Name != "Part" || syms[1].Name != "Coda" {
+		t.Fatalf("symbols = %+v", syms)
+	}
+	part := syms[0]
+	if part.Kind != symbolKindString || part.Detail != "#" ||
+		part.Range != (Range{Position{3, 0}, Position{8, 12}}) ||
+		part.SelectionRange != (Range{Position{3, 0}, Position{3, 6}}) {
+		t.Errorf("part symbol = %+v", part)
+	}
+	if len(part.Children) != 2 || part.Children[0].Name != "Scene One" || len(part.Children[0].Children) != 1 ||
+		part.Children[0].Children[0].Name != "Beat" || part.Children[0].Range.End != (Position{7, 8}) {
+		t.Errorf("part children = %+v", part.Children)
+	}
+}
+
+func TestServe_DefinitionOutsideProject(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Symlink(t.TempDir(), filepath.Join(dir, "out")); err != nil {
+		t.Fatal(err)
+	}
+	uri := PathToURI(filepath.Join(dir, "_binder.md"))
+	text := "<!-- prosemark-binder:v1 -->\n- [Outside](out/outside.md)\n- [Inside](inside.md)\n"
+	io := newFakeIO()
+	io.root = dir
+	msgs, err := run(t, io, session(
+		request(0, methodDidOpen, map[string]any{"textDocument": map[string]any{"uri": uri, "version": 1, "text": text}}),
+		request(1, methodDefinition, at(uri, 1, 5)),
+		request(2, methodDefinition, at(uri, 2, 5)),
+	))
+	if err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	if m := reply(t, msgs, 1); m.Error != nil || string(m.Result) != "null" {
+		t.Errorf("definition through a symlink out of the project = %+v, want null", m)
+	}
+	var locs []Location
+	if err := json.Unmarshal(reply(t, msgs, 2).Result, &locs); err != nil || len(locs) != 1 || locs[0].URI != PathToURI(filepath.Join(dir, "inside.md")) {
+		t.Errorf("definition inside the project = %s", reply(t, msgs, 2).Result)
+	}
+}
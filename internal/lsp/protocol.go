@@ -0,0 +1,246 @@
+package lsp
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// The subset of the Language Server Protocol 3.17 the server speaks.
+
+// Methods and notifications the server handles or sends.
+const (
+	methodInitialize         = "initialize"
+	methodInitialized        = "initialized"
+	methodShutdown           = "shutdown"
+	methodExit               = "exit"
+	methodDidOpen            = "textDocument/didOpen"
+	methodDidChange          = "textDocument/didChange"
+	methodDidSave            = "textDocument/didSave"
+	methodDidClose           = "textDocument/didClose"
+	methodDefinition         = "textDocument/definition"
+	methodDocumentSymbol     = "textDocument/documentSymbol"
+	methodRename             = "textDocument/rename"
+	methodPublishDiagnostics = "textDocument/publishDiagnostics"
+	methodLogMessage         = "window/logMessage"
+)
+
+// Error codes the protocol adds to JSON-RPC's.
+const (
+	codeServerNotInitialized = -32002
+	codeRequestFailed        = -32803
+)
+
+// DiagnosticSeverity values.
+const (
+	severityError       = 1
+	severityWarning     = 2
+	severityInformation = 3
+)
+
+// SymbolKind values: a binder entry is a file and a node file's heading a
+// string, as Markdown servers commonly report headings.
+const (
+	symbolKindFile   = 1
+	symbolKindString = 15
+)
+
+// textDocumentSyncFull asks the client to send whole documents on change.
+const textDocumentSyncFull = 1
+
+// messageTypeError is the MessageType of a logged failure.
+const messageTypeError = 1
+
+// Position is a zero-based line and UTF-16 character offset.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a span between two positions, end exclusive.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location is a range in a document.
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// Diagnostic is a problem reported against a range of a document.
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"`
+	Code     string `json:"code,omitempty"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+// publishDiagnosticsParams replace the diagnostics of one document.
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// DocumentSymbol is one entry of a document's outline.
+type DocumentSymbol struct {
+	Name           string           `json:"name"`
+	Detail         string           `json:"detail,omitempty"`
+	Kind           int              `json:"kind"`
+	Range          Range            `json:"range"`
+	SelectionRange Range            `json:"selectionRange"`
+	Children       []DocumentSymbol `json:"children,omitempty"`
+}
+
+// TextEdit replaces a range of a document.
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// textDocumentEdit is a set of edits to one version of a document; a nil
+// version means the document as it is on disk.
+type textDocumentEdit struct {
+	TextDocument versionedDocument `json:"textDocument"`
+	Edits        []TextEdit        `json:"edits"`
+}
+
+// renameFile is the resource operation that moves a file.
+type renameFile struct {
+	Kind   string `json:"kind"` // always "rename"
+	OldURI string `json:"oldUri"`
+	NewURI string `json:"newUri"`
+}
+
+// WorkspaceEdit is a sequence of document edits and file operations,
+// applied in order.
+type WorkspaceEdit struct {
+	DocumentChanges []any `json:"documentChanges"`
+}
+
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type versionedDocument struct {
+	URI     string `json:"uri"`
+	Version *int   `json:"version"`
+}
+
+type textDocumentItem struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+	Text    string `json:"text"`
+}
+
+type didOpenParams struct {
+	TextDocument textDocumentItem `json:"textDocument"`
+}
+
+type didChangeParams struct {
+	TextDocument   versionedDocument `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type documentParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+type positionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+type renameParams struct {
+	positionParams
+	NewName string `json:"newName"`
+}
+
+type initializeResult struct {
+	Capabilities serverCapabilities `json:"capabilities"`
+	ServerInfo   serverInfo         `json:"serverInfo"`
+}
+
+type serverCapabilities struct {
+	TextDocumentSync       textDocumentSyncOptions `json:"textDocumentSync"`
+	DefinitionProvider     bool                    `json:"definitionProvider"`
+	DocumentSymbolProvider bool                    `json:"documentSymbolProvider"`
+	RenameProvider         bool                    `json:"renameProvider"`
+}
+
+type textDocumentSyncOptions struct {
+	OpenClose bool `json:"openClose"`
+	Change    int  `json:"change"`
+	Save      bool `json:"save"`
+}
+
+type serverInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type logMessageParams struct {
+	Type    int    `json:"type"`
+	Message string `json:"message"`
+}
+
+// URIToPath returns the file path of a file: URI.
+func URIToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("not a file URI: %s", uri)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
+
+// PathToURI returns the file: URI of an absolute path.
+func PathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// position returns the position of the 1-based line and byte column of
+// lines, which is clamped to the line.
+func position(lines []string, line, col int) Position {
+	if line < 1 || line > len(lines) {
+		return Position{Line: max(line-1, 0)}
+	}
+	text := lines[line-1]
+	return Position{Line: line - 1, Character: utf16Len(text[:min(max(col-1, 0), len(text))])}
+}
+
+// lineEnd returns the position of the end of the 1-based line.
+func lineEnd(lines []string, line int) Position {
+	if line < 1 || line > len(lines) {
+		return position(lines, line, 1)
+	}
+	return position(lines, line, len(lines[line-1])+1)
+}
+
+// endOf returns the position just past the end of text.
+func endOf(text string) Position {
+	last := strings.LastIndexByte(text, '\n')
+	return Position{Line: strings.Count(text, "\n"), Character: utf16Len(text[last+1:])}
+}
+
+// utf16Len returns the length of s in UTF-16 code units.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r == utf8.RuneError {
+			n++
+			continue
+		}
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
@@ -0,0 +1,41 @@
+package node
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FrontmatterAliases returns the alternative names a note declares for itself
+// in its frontmatter, as Obsidian does: "aliases:" (a list or a single
+// string) and the older singular "alias:". Blank entries are dropped. Content
+// without a readable frontmatter block declares no aliases.
+func FrontmatterAliases(content []byte) []string {
+	m := frontmatterRE.FindSubmatch(content)
+	if m == nil {
+		return nil
+	}
+	var fields struct {
+		Aliases yaml.Node `yaml:"aliases"`
+		Alias   yaml.Node `yaml:"alias"`
+	}
+	if err := yaml.Unmarshal(m[1], &fields); err != nil {
+		return nil
+	}
+	var aliases []string
+	for _, n := range []*yaml.Node{&fields.Aliases, &fields.Alias} {
+		items := []*yaml.Node{n}
+		if n.Kind == yaml.SequenceNode {
+			items = n.Content
+		}
+		for _, item := range items {
+			if item.Kind != yaml.ScalarNode {
+				continue
+			}
+			if a := strings.TrimSpace(item.Value); a != "" {
+				aliases = append(aliases, a)
+			}
+		}
+	}
+	return aliases
+}
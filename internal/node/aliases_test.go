@@ -0,0 +1,30 @@
+package node
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFrontmatterAliases(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"list", "---\naliases:\n  - Sam\n  - \"Samwise G.\"\n---\nbody\n", []string{"Sam", "Samwise G."}},
+		{"flow list", "---\naliases: [Sam, Gamgee]\n---\n", []string{"Sam", "Gamgee"}},
+		{"single string", "---\naliases: Sam\n---\n", []string{"Sam"}},
+		{"legacy alias key", "---\nalias: Sam\naliases: [Gamgee]\n---\n", []string{"Gamgee", "Sam"}},
+		{"blank and nested entries dropped", "---\naliases:\n  - \"  \"\n  - {a: b}\n  - Sam\n---\n", []string{"Sam"}},
+		{"no aliases", "---\ntitle: Sam\n---\n", nil},
+		{"no frontmatter", "# Sam\n", nil},
+		{"invalid yaml", "---\naliases: [unclosed\n---\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FrontmatterAliases([]byte(tt.content)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FrontmatterAliases() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
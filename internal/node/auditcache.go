@@ -0,0 +1,117 @@
+package node
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// auditRulesVersion identifies the frontmatter checks AuditCache stores the
+// results of. Bump it whenever auditFrontmatter can report differently for
+// the same file, so caches written by older builds are discarded.
+const auditRulesVersion = "1"
+
+// AuditCache remembers the frontmatter diagnostics (AUD004–AUD007, AUD010)
+// of node files by content hash, so a doctor run skips re-validating files
+// that have not changed. A cache is only valid for the audit rules and
+// frontmatter schema it was built with; LoadAuditCache discards one built
+// with others. A nil *AuditCache caches nothing.
+type AuditCache struct {
+	fingerprint string
+	files       map[string]auditCacheEntry
+	changed     bool
+}
+
+// auditCacheFile is the cache's on-disk JSON form.
+type auditCacheFile struct {
+	Version     string                     `json:"version"`
+	Fingerprint string                     `json:"fingerprint"`
+	Files       map[string]auditCacheEntry `json:"files"`
+}
+
+// auditCacheEntry is the cached result for one file.
+type auditCacheEntry struct {
+	Hash        string           `json:"hash"` // SHA-256 of the file's content
+	Diagnostics []auditCacheDiag `json:"diagnostics"`
+}
+
+// auditCacheDiag is one cached diagnostic; its Path is the entry's file.
+type auditCacheDiag struct {
+	Code     AuditCode     `json:"code"`
+	Severity AuditSeverity `json:"severity"`
+	Message  string        `json:"message"`
+}
+
+// LoadAuditCache returns the cache stored in data for audits under schema.
+// A missing, unreadable, or outdated cache yields an empty one.
+func LoadAuditCache(data []byte, schema FrontmatterSchema) *AuditCache {
+	c := &AuditCache{fingerprint: auditFingerprint(schema), files: map[string]auditCacheEntry{}}
+	var f auditCacheFile
+	if json.Unmarshal(data, &f) == nil && f.Version == "1" && f.Fingerprint == c.fingerprint && f.Files != nil {
+		c.files = f.Files
+	} else {
+		c.changed = len(data) > 0
+	}
+	return c
+}
+
+// auditFingerprint identifies the audit rules and schema a cache is valid for.
+func auditFingerprint(schema FrontmatterSchema) string {
+	// Maps marshal with sorted keys, so equal schemas hash equally.
+	s, _ := json.Marshal(schema)
+	sum := sha256.Sum256(append([]byte(auditRulesVersion+"\n"), s...))
+	return hex.EncodeToString(sum[:])
+}
+
+// audit returns auditFrontmatter's diagnostics for the file at ref, from
+// the cache when content is unchanged.
+func (c *AuditCache) audit(ref string, content []byte, schema FrontmatterSchema) []AuditDiagnostic {
+	if c == nil {
+		return auditFrontmatter(ref, content, schema)
+	}
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	if e, ok := c.files[ref]; ok && e.Hash == hash {
+		diags := make([]AuditDiagnostic, len(e.Diagnostics))
+		for i, d := range e.Diagnostics {
+			diags[i] = AuditDiagnostic{Code: d.Code, Severity: d.Severity, Message: d.Message, Path: ref}
+		}
+		return diags
+	}
+
+	diags := auditFrontmatter(ref, content, schema)
+	e := auditCacheEntry{Hash: hash, Diagnostics: make([]auditCacheDiag, len(diags))}
+	for i, d := range diags {
+		e.Diagnostics[i] = auditCacheDiag{Code: d.Code, Severity: d.Severity, Message: d.Message}
+	}
+	c.files[ref] = e
+	c.changed = true
+	return diags
+}
+
+// retain drops the entries for files not in refs.
+func (c *AuditCache) retain(refs []string) {
+	keep := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		keep[ref] = true
+	}
+	for ref := range c.files {
+		if !keep[ref] {
+			delete(c.files, ref)
+			c.changed = true
+		}
+	}
+}
+
+// Changed reports whether the cache differs from what LoadAuditCache read,
+// so it needs saving.
+func (c *AuditCache) Changed() bool {
+	return c.changed
+}
+
+// Marshal returns the cache's JSON form, for LoadAuditCache.
+func (c *AuditCache) Marshal() []byte {
+	// Plain strings and slices cannot fail to encode.
+	data, _ := json.Marshal(auditCacheFile{Version: "1", Fingerprint: c.fingerprint, Files: c.files})
+	return data
+}
@@ -0,0 +1,102 @@
+package node
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// cacheTestUUID names the node file in the audit cache tests.
+const cacheTestUUID = "0192f0c1-3e7a-7000-8000-5a4b3c2d1e0f"
+
+// cacheTestNode is a valid node file for cacheTestUUID.
+const cacheTestNode = "---\nid: " + cacheTestUUID + "\ntitle: A\ncreated: 2026-01-01T00:00:00Z\nupdated: 2026-01-01T00:00:00Z\n---\nBody.\n"
+
+func TestAuditCache(t *testing.T) {
+	ref := cacheTestUUID + ".md"
+	bad := []byte("---\nid: [unclosed\n---\n")
+
+	c := LoadAuditCache(nil, nil)
+	if c.Changed() {
+		t.Error("empty cache reports changes")
+	}
+	first := c.audit(ref, bad, nil)
+	if len(first) != 1 || first[0].Code != AUD007 || first[0].Path != ref {
+		t.Fatalf("audit = %+v, want AUD007", first)
+	}
+	if !c.Changed() {
+		t.Error("cache not changed after a miss")
+	}
+
+	// A reloaded cache answers from the stored entry without re-validating:
+	// doctoring the entry shows the stored result is what comes back.
+	data := strings.Replace(string(c.Marshal()), "frontmatter YAML", "cached YAML", 1)
+	reloaded := LoadAuditCache([]byte(data), nil)
+	got := reloaded.audit(ref, bad, nil)
+	if len(got) != 1 || got[0].Path != ref || !strings.HasPrefix(got[0].Message, "cached YAML") {
+		t.Errorf("cached audit = %+v, want the stored diagnostic", got)
+	}
+	if reloaded.Changed() {
+		t.Error("cache changed by a hit")
+	}
+
+	// Changed content misses.
+	if got := reloaded.audit(ref, []byte(cacheTestNode), nil); len(got) != 0 {
+		t.Errorf("audit of fixed file = %+v, want none", got)
+	}
+	if !reloaded.Changed() {
+		t.Error("cache not changed after content changed")
+	}
+}
+
+func TestLoadAuditCache_Invalidation(t *testing.T) {
+	ref := cacheTestUUID + ".md"
+	schema := FrontmatterSchema{"scene": {Required: map[string]FieldType{"pov": "string"}}}
+	c := LoadAuditCache(nil, schema)
+	c.audit(ref, []byte(cacheTestNode), schema)
+	data := c.Marshal()
+
+	tests := []struct {
+		name   string
+		data   []byte
+		schema FrontmatterSchema
+	}{
+		{"schema changed", data, nil},
+		{"rules changed", []byte(strings.Replace(string(data), `"version":"1"`, `"version":"0"`, 1)), schema},
+		{"corrupt", []byte("{"), schema},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := LoadAuditCache(tt.data, tt.schema)
+			if len(c.files) != 0 || !c.Changed() {
+				t.Errorf("cache = %d entries, changed %v; want it discarded", len(c.files), c.Changed())
+			}
+		})
+	}
+	if c := LoadAuditCache(data, schema); len(c.files) != 1 || c.Changed() {
+		t.Errorf("matching cache = %d entries, changed %v; want it kept", len(c.files), c.Changed())
+	}
+}
+
+func TestRunDoctor_CacheRetainsBinderFiles(t *testing.T) {
+	c := LoadAuditCache(nil, nil)
+	c.audit("gone.md", []byte("x"), nil)
+	ref := cacheTestUUID + ".md"
+	data := DoctorData{
+		BinderSrc:    []byte("<!-- prosemark-binder:v1 -->\n- [A](" + ref + ")\n"),
+		FileContents: map[string][]byte{ref: []byte(cacheTestNode)},
+		Cache:        c,
+	}
+
+	data.Subset = map[string]bool{ref: true}
+	RunDoctor(context.Background(), data)
+	if _, ok := c.files["gone.md"]; !ok {
+		t.Error("subset audit pruned the cache")
+	}
+
+	data.Subset = nil
+	RunDoctor(context.Background(), data)
+	if _, ok := c.files["gone.md"]; ok || len(c.files) != 1 {
+		t.Errorf("cache files = %v, want only %s", c.files, ref)
+	}
+}
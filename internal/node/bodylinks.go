@@ -0,0 +1,57 @@
+package node
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// AuditBodyLinks returns an AUD011 warning for each cross-reference in the
+// body of the node file at ref (a project-relative slash path) whose target
+// is not in project: inline links to .md files, resolved against the node
+// file's directory, and wikilinks, matched case-insensitively against file
+// names, project-relative paths, and frontmatter aliases.
+func AuditBodyLinks(ref string, content []byte, project *binder.Project) []AuditDiagnostic {
+	files := make(map[string]bool, len(project.Files)+1)
+	names := make(map[string]bool)
+	addName := func(f string) {
+		files[f] = true
+		stem := strings.ToLower(strings.TrimSuffix(f, ".md"))
+		names[stem] = true
+		names[path.Base(stem)] = true
+	}
+	for _, f := range project.Files {
+		addName(f)
+	}
+	addName(project.ActiveBinderFile())
+	for _, aliases := range project.Aliases {
+		for _, a := range aliases {
+			names[strings.ToLower(a)] = true
+		}
+	}
+
+	var diags []AuditDiagnostic
+	for _, l := range ParseBodyLinks(content) {
+		if !l.Local() {
+			continue
+		}
+		rel := l.LocalPath()
+		if !strings.HasSuffix(rel, ".md") || files[path.Join(path.Dir(ref), rel)] {
+			continue
+		}
+		d := warnDiag(AUD011, ref, fmt.Sprintf("line %d: link to %s, which does not exist in the project", l.Line, l.Target))
+		d.Line = l.Line
+		diags = append(diags, d)
+	}
+	for _, l := range ParseBodyWikilinks(content) {
+		if names[strings.ToLower(strings.TrimSuffix(l.Target, ".md"))] {
+			continue
+		}
+		d := warnDiag(AUD011, ref, fmt.Sprintf("line %d: wikilink [[%s]] matches no file in the project", l.Line, l.Target))
+		d.Line = l.Line
+		diags = append(diags, d)
+	}
+	return diags
+}
@@ -0,0 +1,35 @@
+package node_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+func TestAuditBodyLinks(t *testing.T) {
+	project := &binder.Project{
+		Files:   []string{"part/a.md", "part/b.md", "c.md", "Notes/World.md"},
+		Aliases: map[string][]string{"c.md": {"The Villain"}},
+	}
+	content := []byte("---\nid: a\n---\n" +
+		"[b](b.md) [c](../c.md) [bin](../_binder.md) [img](missing.png) [web](https://x.test/gone.md)\n" +
+		"[[C]] [[the villain]] [[notes/world]] [[World.md]] [[_binder]]\n" +
+		"[gone](gone.md) [[Nobody]]\n")
+
+	diags := node.AuditBodyLinks("part/a.md", content, project)
+
+	var got []string
+	for _, d := range diags {
+		if d.Code != node.AUD011 || d.Severity != node.SeverityWarning || d.Path != "part/a.md" || d.Line != 6 {
+			t.Errorf("diagnostic = %+v, want an AUD011 warning on line 6 of part/a.md", d)
+		}
+		got = append(got, d.Message)
+	}
+	want := "line 6: link to gone.md, which does not exist in the project\n" +
+		"line 6: wikilink [[Nobody]] matches no file in the project"
+	if strings.Join(got, "\n") != want {
+		t.Errorf("messages =\n%s\nwant\n%s", strings.Join(got, "\n"), want)
+	}
+}
@@ -0,0 +1,100 @@
+package node
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SetBookmark returns the project config with bookmark name pointing at
+// selector, adding the bookmarks section if it is missing. The rest of the
+// config, comments included, is kept.
+func SetBookmark(config []byte, name, selector string) ([]byte, error) {
+	if err := ValidateBookmark(name, selector); err != nil {
+		return nil, err
+	}
+	doc, bookmarks, err := bookmarksMapping(config, true)
+	if err != nil {
+		return nil, err
+	}
+	if _, value := mappingEntry(bookmarks, name); value != nil {
+		*value = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: selector, LineComment: value.LineComment}
+	} else {
+		bookmarks.Content = append(bookmarks.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: selector},
+		)
+	}
+	return encodeConfig(doc), nil
+}
+
+// RemoveBookmark returns the project config without bookmark name and
+// whether the config defined it. The rest of the config is kept.
+func RemoveBookmark(config []byte, name string) ([]byte, bool, error) {
+	doc, bookmarks, err := bookmarksMapping(config, false)
+	if err != nil || bookmarks == nil {
+		return config, false, err
+	}
+	i, _ := mappingEntry(bookmarks, name)
+	if i < 0 {
+		return config, false, nil
+	}
+	bookmarks.Content = append(bookmarks.Content[:i], bookmarks.Content[i+2:]...)
+	return encodeConfig(doc), true, nil
+}
+
+// bookmarksMapping decodes config and returns it with its bookmarks
+// mapping, which is nil when the config has none unless create is set.
+func bookmarksMapping(config []byte, create bool) (*yaml.Node, *yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(config, &doc); err != nil {
+		return nil, nil, fmt.Errorf("parse project settings: %w", err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("project settings are not a mapping")
+	}
+	_, bookmarks := mappingEntry(root, "bookmarks")
+	switch {
+	case bookmarks == nil && !create:
+		return &doc, nil, nil
+	case bookmarks == nil:
+		bookmarks = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "bookmarks"}, bookmarks)
+	case bookmarks.Kind == yaml.ScalarNode && bookmarks.Tag == "!!null":
+		*bookmarks = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	case bookmarks.Kind != yaml.MappingNode:
+		return nil, nil, fmt.Errorf("bookmarks is not a mapping")
+	}
+	// An empty mapping read as {} would otherwise stay in flow style.
+	bookmarks.Style = 0
+	return &doc, bookmarks, nil
+}
+
+// mappingEntry returns the content index of key in mapping and its value,
+// or -1 and nil.
+func mappingEntry(mapping *yaml.Node, key string) (int, *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i, mapping.Content[i+1]
+		}
+	}
+	return -1, nil
+}
+
+// encodeConfig encodes a project config document with the two-space
+// indentation of a hand-written one.
+func encodeConfig(doc *yaml.Node) []byte {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	// A document the decoder built, with scalar and mapping nodes added,
+	// always encodes, and a bytes.Buffer takes every write.
+	_ = enc.Encode(doc)
+	_ = enc.Close()
+	return buf.Bytes()
+}
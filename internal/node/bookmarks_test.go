@@ -0,0 +1,60 @@
+package node
+
+import "testing"
+
+func TestSetBookmark(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    string
+		wantErr bool
+	}{
+		{"empty config", "", "bookmarks:\n  act2: ch2.md\n", false},
+		{"adds a section", "version: \"1\"\n", "version: \"1\"\nbookmarks:\n  act2: ch2.md\n", false},
+		{"adds to a section", "bookmarks:\n  act1: ch1.md\n", "bookmarks:\n  act1: ch1.md\n  act2: ch2.md\n", false},
+		{"replaces and keeps comments", "# settings\nbookmarks:\n  act2: old.md # the middle\n", "# settings\nbookmarks:\n  act2: ch2.md # the middle\n", false},
+		{"null section", "bookmarks:\n", "bookmarks:\n  act2: ch2.md\n", false},
+		{"flow section", "bookmarks: {}\n", "bookmarks:\n  act2: ch2.md\n", false},
+		{"section not a mapping", "bookmarks: [a]\n", "", true},
+		{"config not a mapping", "- a\n", "", true},
+		{"invalid yaml", "bookmarks: [\n", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SetBookmark([]byte(tt.config), "act2", "ch2.md")
+			if (err != nil) != tt.wantErr || string(got) != tt.want {
+				t.Errorf("SetBookmark() = %q, %v; want %q, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetBookmark_Invalid(t *testing.T) {
+	if _, err := SetBookmark(nil, "act 2", "ch2.md"); err == nil {
+		t.Error("SetBookmark() with an invalid name: want error")
+	}
+}
+
+func TestRemoveBookmark(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		want      string
+		wantFound bool
+		wantErr   bool
+	}{
+		{"removes", "version: \"1\"\nbookmarks:\n  act1: ch1.md\n  act2: ch2.md\n", "version: \"1\"\nbookmarks:\n  act1: ch1.md\n", true, false},
+		{"not defined", "bookmarks:\n  act1: ch1.md\n", "bookmarks:\n  act1: ch1.md\n", false, false},
+		{"no section", "version: \"1\"\n", "version: \"1\"\n", false, false},
+		{"empty config", "", "", false, false},
+		{"invalid yaml", "bookmarks: [\n", "bookmarks: [\n", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found, err := RemoveBookmark([]byte(tt.config), "act2")
+			if (err != nil) != tt.wantErr || found != tt.wantFound || string(got) != tt.want {
+				t.Errorf("RemoveBookmark() = %q, %v, %v; want %q, %v, error %v", got, found, err, tt.want, tt.wantFound, tt.wantErr)
+			}
+		})
+	}
+}
@@ -12,13 +12,14 @@ import (
 // binderLinkTargetRE finds markdown inline link targets in binder source.
 var binderLinkTargetRE = regexp.MustCompile(`\]\(([^)]+)\)`)
 
-// CollectBinderRefs parses raw binder source and returns:
+// CollectBinderRefs parses raw binder source, the binder named binderName,
+// and returns:
 //   - refs: the deduplicated list of valid (non-escaping) file references from the parsed binder tree
 //   - diags: diagnostics for path-escaping links (AUDW001) and duplicate references (AUD003)
 //
 // binder.Parse already rejects escaping paths from the parse tree, so the raw-byte
 // regex scan is required to surface those targets as diagnostics.
-func CollectBinderRefs(ctx context.Context, binderSrc []byte) ([]string, []AuditDiagnostic) {
+func CollectBinderRefs(ctx context.Context, binderName string, binderSrc []byte) ([]string, []AuditDiagnostic) {
 	var diags []AuditDiagnostic
 
 	// Scan raw bytes for path-escaping links that binder.Parse rejects from the tree.
@@ -35,15 +36,20 @@ func CollectBinderRefs(ctx context.Context, binderSrc []byte) ([]string, []Audit
 	}
 
 	// Parse binder tree to collect valid (non-escaping) refs and detect duplicates.
-	// Capture parse-level diagnostics (BNDW*) so the doctor command can surface them.
-	parseResult, parseDiags, _ := binder.Parse(ctx, binderSrc, nil)
-	for _, d := range parseDiags {
+	// Capture parse-level diagnostics (BNDE*, BNDW*) so the doctor command can
+	// surface them; a fatal parse error becomes AUD009.
+	parseResult, parseDiags, parseErr := binder.Parse(ctx, binderSrc, nil)
+	if parseErr != nil {
 		diags = append(diags, AuditDiagnostic{
-			Code:     AuditCode(d.Code),
-			Severity: AuditSeverity(d.Severity),
-			Message:  d.Message,
+			Code:     AUD009,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("binder file could not be parsed: %v", parseErr),
+			Path:     binderName,
 		})
 	}
+	for _, d := range parseDiags {
+		diags = append(diags, binderParseAuditDiag(binderName, d))
+	}
 
 	visited := make(map[string]bool)
 	duplicated := make(map[string]bool)
@@ -77,3 +83,20 @@ func CollectBinderRefs(ctx context.Context, binderSrc []byte) ([]string, []Audit
 
 	return refs, diags
 }
+
+// binderParseAuditDiag maps a binder parse diagnostic onto an AuditDiagnostic.
+// The code and severity are carried over unchanged; the path is binderName
+// and the source line, when known, is both set and prefixed to the message.
+func binderParseAuditDiag(binderName string, d binder.Diagnostic) AuditDiagnostic {
+	diag := AuditDiagnostic{
+		Code:     AuditCode(d.Code),
+		Severity: AuditSeverity(d.Severity),
+		Message:  d.Message,
+		Path:     binderName,
+	}
+	if d.Location != nil && d.Location.Line > 0 {
+		diag.Line = d.Location.Line
+		diag.Message = fmt.Sprintf("line %d: %s", d.Location.Line, d.Message)
+	}
+	return diag
+}
@@ -2,6 +2,7 @@ package node_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/eykd/prosemark-go/internal/node"
@@ -73,7 +74,7 @@ func TestCollectBinderRefs(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			refs, diags := node.CollectBinderRefs(context.Background(), tt.binderSrc)
+			refs, diags := node.CollectBinderRefs(context.Background(), "_binder.md", tt.binderSrc)
 
 			// Check refs.
 			got := make(map[string]struct{}, len(refs))
@@ -108,7 +109,7 @@ func TestCollectBinderRefs(t *testing.T) {
 
 // TestCollectBinderRefs_EscapingLink_IsWarning verifies the AUDW001 severity.
 func TestCollectBinderRefs_EscapingLink_IsWarning(t *testing.T) {
-	_, diags := node.CollectBinderRefs(context.Background(), []byte("- [X](../../etc/passwd)\n"))
+	_, diags := node.CollectBinderRefs(context.Background(), "_binder.md", []byte("- [X](../../etc/passwd)\n"))
 	for _, d := range diags {
 		if d.Code == node.AUDW001 && d.Severity != node.SeverityWarning {
 			t.Errorf("AUDW001 severity = %q, want %q", d.Severity, node.SeverityWarning)
@@ -122,7 +123,7 @@ func TestCollectBinderRefs_EscapingLink_IsWarning(t *testing.T) {
 // TestCollectBinderRefs_EscapingLink_PathIsTarget verifies the AUDW001 Path matches the target.
 func TestCollectBinderRefs_EscapingLink_PathIsTarget(t *testing.T) {
 	target := "../../etc/passwd"
-	_, diags := node.CollectBinderRefs(context.Background(), []byte("- [X]("+target+")\n"))
+	_, diags := node.CollectBinderRefs(context.Background(), "_binder.md", []byte("- [X]("+target+")\n"))
 	for _, d := range diags {
 		if d.Code == node.AUDW001 {
 			if d.Path != target {
@@ -138,7 +139,7 @@ func TestCollectBinderRefs_EscapingLink_PathIsTarget(t *testing.T) {
 func TestCollectBinderRefs_NoPanic_CancelledContext(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
-	_, _ = node.CollectBinderRefs(ctx, binderWithRefs(testDoctorUUID1+".md"))
+	_, _ = node.CollectBinderRefs(ctx, "_binder.md", binderWithRefs(testDoctorUUID1+".md"))
 }
 
 // TestCollectBinderRefs_BinderParseDiagnosticsIncluded verifies that parse-level
@@ -167,7 +168,7 @@ func TestCollectBinderRefs_BinderParseDiagnosticsIncluded(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, diags := node.CollectBinderRefs(context.Background(), tt.binderSrc)
+			_, diags := node.CollectBinderRefs(context.Background(), "_binder.md", tt.binderSrc)
 			codes := diagCodesOf(diags)
 			for _, want := range tt.wantCodes {
 				if _, ok := codes[want]; !ok {
@@ -187,7 +188,7 @@ func TestCollectBinderRefs_BinderParseDiagnosticsIncluded(t *testing.T) {
 // is surfaced with warning severity so doctor exits 0 for pragma-only issues.
 func TestCollectBinderRefs_BNDW001_IsWarning(t *testing.T) {
 	// Binder without pragma: binder.Parse will emit BNDW001.
-	_, diags := node.CollectBinderRefs(context.Background(), binderWithRefs(testDoctorUUID1+".md"))
+	_, diags := node.CollectBinderRefs(context.Background(), "_binder.md", binderWithRefs(testDoctorUUID1+".md"))
 	for _, d := range diags {
 		if d.Code == node.BNDW001 {
 			if d.Severity != node.SeverityWarning {
@@ -198,3 +199,64 @@ func TestCollectBinderRefs_BNDW001_IsWarning(t *testing.T) {
 	}
 	t.Errorf("expected BNDW001 diagnostic in output; got %v", diags)
 }
+
+// TestCollectBinderRefs_BinderParseErrors verifies that error-severity binder
+// parse diagnostics (BNDE*) and fatal parse failures are surfaced with error
+// severity, the binder path, and the source line so doctor reports the full
+// health picture without a separate parse run.
+func TestCollectBinderRefs_BinderParseErrors(t *testing.T) {
+	pragma := "<!-- prosemark-binder:v1 -->\n"
+	tests := []struct {
+		name      string
+		binderSrc []byte
+		wantCode  node.AuditCode
+		wantInMsg string
+		wantLine  int
+	}{
+		{
+			name:      "illegal path characters emit BNDE001",
+			binderSrc: []byte(pragma + "- [Bad](bad|name.md)\n"),
+			wantCode:  node.BNDE001,
+			wantInMsg: "line 2:",
+			wantLine:  2,
+		},
+		{
+			name:      "root escape emits BNDE002",
+			binderSrc: []byte(pragma + "- [Up](../up.md)\n"),
+			wantCode:  node.BNDE002,
+			wantInMsg: "line 2:",
+			wantLine:  2,
+		},
+		{
+			name:      "invalid UTF-8 emits AUD009",
+			binderSrc: []byte(pragma + "- [Bad](\xff.md)\n"),
+			wantCode:  node.AUD009,
+			wantInMsg: "could not be parsed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, diags := node.CollectBinderRefs(context.Background(), "outline.md", tt.binderSrc)
+			for _, d := range diags {
+				if d.Code != tt.wantCode {
+					continue
+				}
+				if d.Severity != node.SeverityError {
+					t.Errorf("%s severity = %q, want %q", d.Code, d.Severity, node.SeverityError)
+				}
+				if d.Path != "outline.md" {
+					t.Errorf("%s path = %q, want the binder's name outline.md", d.Code, d.Path)
+				}
+				if !strings.Contains(d.Message, tt.wantInMsg) {
+					t.Errorf("%s message = %q, want to contain %q", d.Code, d.Message, tt.wantInMsg)
+				}
+				if d.Line != tt.wantLine {
+					t.Errorf("%s line = %d, want %d", d.Code, d.Line, tt.wantLine)
+				}
+				return
+			}
+			t.Errorf("expected %s diagnostic; got %v", tt.wantCode, diags)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package node
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// commentRE matches a review comment in a node body. Two forms are recognised:
+//
+//	%% editor: tighten this %%        (author optional: %% tighten this %%)
+//	<!-- editor: tighten this -->     (author required)
+//
+// A resolved comment carries "(resolved)" after the author, e.g.
+// "%% editor (resolved): tighten this %%". Comments may span lines.
+var commentRE = regexp.MustCompile(
+	`(?s)%%[ \t]*(?:([\w.-]+)?[ \t]*(\(resolved\))?[ \t]*:)?[ \t]*(.*?)[ \t]*%%` +
+		`|<!--[ \t]*([\w.-]+)[ \t]*(\(resolved\))?[ \t]*:[ \t]*(.*?)[ \t]*-->`,
+)
+
+// resolvedMarker is inserted after a comment's author to mark it resolved.
+const resolvedMarker = "(resolved)"
+
+// Comment is a review comment found in a node file.
+type Comment struct {
+	// Author is the tag before the colon; empty for unattributed %% comments.
+	Author string
+	// Text is the comment body with surrounding whitespace trimmed.
+	Text string
+	// Resolved reports whether the comment carries the "(resolved)" marker.
+	Resolved bool
+	// Line is the 1-based line of the comment's opening delimiter.
+	Line int
+	// Column is the 1-based byte column of the opening delimiter.
+	Column int
+
+	markerAt   int  // byte offset at which the resolved marker is inserted
+	authorless bool // true when the marker needs its own ":" separator
+}
+
+// ParseComments returns the review comments in a node file's content, in
+// document order. Locations are relative to the whole file, so the
+// frontmatter block is skipped but still counted for line numbers. Fenced
+// code blocks and inline code spans are skipped too, so a comment delimiter
+// shown as code is never taken for a comment, nor resolved.
+func ParseComments(content []byte) []Comment {
+	offset := 0
+	if loc := frontmatterRE.FindIndex(content); loc != nil {
+		offset = loc[1]
+	}
+
+	var comments []Comment
+	for _, m := range commentRE.FindAllSubmatchIndex(blankCode(content[offset:]), -1) {
+		for i := range m {
+			if m[i] >= 0 {
+				m[i] += offset
+			}
+		}
+		var c Comment
+		if m[8] >= 0 { // HTML form
+			c.Author = string(content[m[8]:m[9]])
+			if c.Author == includeAuthor {
+				continue // an include directive, not a comment
+			}
+			c.Resolved = m[10] >= 0
+			c.Text = string(content[m[12]:m[13]])
+			c.markerAt = m[9]
+		} else {
+			if m[2] >= 0 {
+				c.Author = string(content[m[2]:m[3]])
+				c.markerAt = m[3]
+			} else {
+				c.authorless = true
+				c.markerAt = m[0] + len("%%")
+			}
+			c.Resolved = m[4] >= 0
+			c.Text = string(content[m[6]:m[7]])
+		}
+		if c.Text == "" {
+			continue
+		}
+		c.Line = bytes.Count(content[:m[0]], []byte("\n")) + 1
+		c.Column = m[0] - bytes.LastIndexByte(content[:m[0]], '\n')
+		comments = append(comments, c)
+	}
+	return comments
+}
+
+// blankCode returns a copy of body with its fenced code blocks and inline
+// code spans blanked out, keeping offsets and line breaks.
+func blankCode(body []byte) []byte {
+	var b strings.Builder
+	fence := ""
+	for _, line := range strings.SplitAfter(string(body), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			line = blankLine(line)
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+			line = blankLine(line)
+		default:
+			line = blankCodeSpans(line)
+		}
+		b.WriteString(line)
+	}
+	return []byte(b.String())
+}
+
+// blankLine blanks out line, keeping its length and any trailing newline.
+func blankLine(line string) string {
+	text := strings.TrimSuffix(line, "\n")
+	return strings.Repeat(" ", len(text)) + line[len(text):]
+}
+
+// ResolveComment returns content with c marked resolved. c must come from
+// ParseComments(content); an already-resolved comment is returned unchanged.
+func ResolveComment(content []byte, c Comment) []byte {
+	if c.Resolved {
+		return content
+	}
+	marker := " " + resolvedMarker
+	if c.authorless {
+		marker = " " + resolvedMarker + ":"
+	}
+	var buf bytes.Buffer
+	buf.Write(content[:c.markerAt])
+	buf.WriteString(marker)
+	buf.Write(content[c.markerAt:])
+	return buf.Bytes()
+}
+
+// CountUnresolvedComments returns the number of unresolved review comments in
+// a node file's content.
+func CountUnresolvedComments(content []byte) int {
+	n := 0
+	for _, c := range ParseComments(content) {
+		if !c.Resolved {
+			n++
+		}
+	}
+	return n
+}
+
+// FormatCommentText flattens a multi-line comment body to a single line for
+// list output.
+func FormatCommentText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,96 @@
+package node_test
+
+import (
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+const commentsNode = "---\nid: x\ncreated: 2026-01-01T00:00:00Z\nupdated: 2026-01-01T00:00:00Z\n---\n" +
+	"The storm broke. %% editor: tighten this %%\n" +
+	"<!-- alice (resolved): done -->\n" +
+	"%% a loose note\nacross lines %%\n" +
+	"<!-- plain html comment, no author tag -->\n" +
+	"%%%%\n"
+
+func TestParseComments(t *testing.T) {
+	got := node.ParseComments([]byte(commentsNode))
+
+	want := []struct {
+		author   string
+		text     string
+		resolved bool
+		line     int
+		column   int
+	}{
+		{"editor", "tighten this", false, 6, 18},
+		{"alice", "done", true, 7, 1},
+		{"", "a loose note\nacross lines", false, 8, 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d comments, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		c := got[i]
+		if c.Author != w.author || c.Text != w.text || c.Resolved != w.resolved || c.Line != w.line || c.Column != w.column {
+			t.Errorf("comment %d = {%q %q %v %d:%d}, want {%q %q %v %d:%d}",
+				i, c.Author, c.Text, c.Resolved, c.Line, c.Column, w.author, w.text, w.resolved, w.line, w.column)
+		}
+	}
+	if n := node.CountUnresolvedComments([]byte(commentsNode)); n != 2 {
+		t.Errorf("CountUnresolvedComments = %d, want 2", n)
+	}
+}
+
+func TestParseComments_IgnoresFrontmatter(t *testing.T) {
+	src := []byte("---\nid: x\ntitle: '%% not: a comment %%'\ncreated: a\nupdated: b\n---\nbody\n")
+	if got := node.ParseComments(src); len(got) != 0 {
+		t.Errorf("got %+v, want no comments", got)
+	}
+}
+
+func TestParseComments_SkipsCode(t *testing.T) {
+	src := []byte("Write `%% editor: like this %%` or ``<!-- bob: this -->``.\n" +
+		"```markdown\n%% editor: shown as code %%\n```\n" +
+		"  ~~~\n<!-- bob: also code -->\n  ~~~\n" +
+		"%% editor: a real one %%\n")
+	got := node.ParseComments(src)
+	if len(got) != 1 || got[0].Text != "a real one" || got[0].Line != 8 || got[0].Column != 1 {
+		t.Errorf("got %+v, want only the comment on line 8", got)
+	}
+}
+
+func TestResolveComment(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"percent with author", "x %% editor: tighten %% y", "x %% editor (resolved): tighten %% y"},
+		{"percent without author", "%% tighten %%", "%% (resolved): tighten %%"},
+		{"html", "<!-- bob: check date -->", "<!-- bob (resolved): check date -->"},
+		{"already resolved", "%% ed (resolved): ok %%", "%% ed (resolved): ok %%"},
+		{"beside a code span", "`%% ed: code %%` %% ed: fix %%", "`%% ed: code %%` %% ed (resolved): fix %%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comments := node.ParseComments([]byte(tt.src))
+			if len(comments) != 1 {
+				t.Fatalf("got %d comments, want 1", len(comments))
+			}
+			got := string(node.ResolveComment([]byte(tt.src), comments[0]))
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if again := node.ParseComments([]byte(got)); len(again) != 1 || !again[0].Resolved {
+				t.Errorf("re-parsed %q = %+v, want one resolved comment", got, again)
+			}
+		})
+	}
+}
+
+func TestFormatCommentText(t *testing.T) {
+	if got := node.FormatCommentText("a loose\n  note "); got != "a loose note" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,292 @@
+package node
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// ConfigFilename is the project config file in a project directory.
+const ConfigFilename = ".prosemark.yml"
+
+// configOverride, when set, is read in place of every project's
+// ConfigFilename.
+var configOverride string
+
+// SetConfigOverride makes ConfigPath return path for every project, as
+// pmk --config does; "" restores the default.
+func SetConfigOverride(path string) {
+	configOverride = path
+}
+
+// ConfigPath returns the config file of the project in dir: its
+// ConfigFilename unless SetConfigOverride named another file.
+func ConfigPath(dir string) string {
+	if configOverride != "" {
+		return configOverride
+	}
+	return filepath.Join(dir, ConfigFilename)
+}
+
+// ProjectConfig holds the binder-parsing settings of a project config file.
+type ProjectConfig struct {
+	// WikilinkResolution is the wikilink resolution mode ("" when unset).
+	WikilinkResolution string
+	// ReferenceSections lists headings under which .md links outside lists
+	// are expected prose cross-references rather than misplaced entries.
+	ReferenceSections []string
+	// Limits holds parse resource limits; zero fields keep the defaults.
+	Limits binder.ParseLimits
+	// IDScheme is the node ID scheme name ("" when unset; see
+	// LookupIDScheme).
+	IDScheme string
+	// TextPlaceholders parses plain-text list items as placeholder entries.
+	TextPlaceholders bool
+	// SubBinders expands the binders a binder links to into its tree.
+	SubBinders bool
+	// DateFormat and Timezone set how timestamps are shown ("" when unset;
+	// see ParseDateFormat).
+	DateFormat string
+	Timezone   string
+	// Bookmarks maps bookmark names to the selectors they stand for.
+	Bookmarks map[string]string
+	// SeverityOverrides maps diagnostic codes to error, warning, info, or
+	// off (see binder.ApplySeverityOverrides).
+	SeverityOverrides map[string]string
+	// ListMarker and IndentWidth style the entries pmk adds where no
+	// sibling shows the binder's own style ("" and 0 when unset: "-" and
+	// two spaces).
+	ListMarker  string
+	IndentWidth int
+	// Editor is the command files are opened with when $EDITOR is unset.
+	Editor string
+	// CompileSeparator is put between node bodies by compile (nil when
+	// unset: a blank line).
+	CompileSeparator *string
+	// Binder is the binder commands use by default ("" when unset:
+	// binder.DefaultBinderFilename), and Binders the project's other
+	// binders: filenames, chosen with --binder, or paths of nested binders
+	// in subdirectories.
+	Binder  string
+	Binders []string
+	// WatchDebounce is how long --watch and pmk serve wait for a burst of
+	// changes to end (0 when unset: watch.DefaultWindow).
+	WatchDebounce time.Duration
+}
+
+// BinderName returns the filename of the project's default binder.
+func (c ProjectConfig) BinderName() string {
+	if c.Binder == "" {
+		return binder.DefaultBinderFilename
+	}
+	return c.Binder
+}
+
+// BinderNames returns the filenames of all the project's binders, the
+// default first.
+func (c ProjectConfig) BinderNames() []string {
+	names := []string{c.BinderName()}
+	for _, name := range c.Binders {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// envRefRE matches ${NAME} environment variable references.
+var envRefRE = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces each ${NAME} in config with the value of the
+// environment variable NAME, or nothing when it is unset. A bare $ is left
+// alone, so values such as prices and regular expressions need no escaping.
+func expandEnv(config []byte) []byte {
+	return envRefRE.ReplaceAllFunc(config, func(ref []byte) []byte {
+		return []byte(os.Getenv(string(ref[2 : len(ref)-1])))
+	})
+}
+
+// ParseProjectConfig reads the binder-parsing settings of a project config
+// file:
+//
+//	wikilinks:
+//	  resolution: shortest   # or binder (the default)
+//	reference_sections:
+//	  - See also
+//	limits:
+//	  max_file_size: 10485760
+//	  max_line_length: 262144
+//	  max_nodes: 100000
+//	  max_ref_defs: 100000
+//	id_scheme: ulid        # or uuidv7 (the default) or date
+//	text_placeholders: true
+//	sub_binders: true        # expand links to other binders into the tree
+//	dates:
+//	  format: long           # or date, datetime (the default), rfc3339, or a Go layout
+//	  timezone: Europe/Paris # or UTC, or local (the default)
+//	bookmarks:
+//	  act2: part-two.md      # used as @act2 wherever a selector is accepted
+//	diagnostics:
+//	  BNDW004: error         # or warning, info, or off to silence the code
+//	list_marker: "*"         # or - (the default) or +
+//	indent: 4                # spaces per level (default 2)
+//	editor: code --wait      # when $EDITOR is unset
+//	compile:
+//	  separator: "\n\n* * *\n\n"
+//	binder: draft.md         # the default binder (default _binder.md)
+//	binders:                 # other binders, chosen with --binder
+//	  - _outtakes.md
+//	watch:
+//	  debounce: 500ms        # quiet period before --watch re-runs (default 250ms)
+//
+// ${NAME} anywhere in the file is replaced with the environment variable
+// NAME first. Unknown resolution modes, ID schemes, date formats, time
+// zones, diagnostic severities, and list markers, blank section headings,
+// negative limits, indent widths outside 1-8, invalid bookmarks, binder
+// names that are not .md filenames, and debounce windows that are not
+// positive durations are reported as errors.
+func ParseProjectConfig(config []byte) (ProjectConfig, error) {
+	var cfg struct {
+		Wikilinks struct {
+			Resolution string `yaml:"resolution"`
+		} `yaml:"wikilinks"`
+		ReferenceSections []string `yaml:"reference_sections"`
+		Limits            struct {
+			MaxFileSize   int `yaml:"max_file_size"`
+			MaxLineLength int `yaml:"max_line_length"`
+			MaxNodes      int `yaml:"max_nodes"`
+			MaxRefDefs    int `yaml:"max_ref_defs"`
+		} `yaml:"limits"`
+		IDScheme         string `yaml:"id_scheme"`
+		TextPlaceholders bool   `yaml:"text_placeholders"`
+		SubBinders       bool   `yaml:"sub_binders"`
+		Dates            struct {
+			Format   string `yaml:"format"`
+			Timezone string `yaml:"timezone"`
+		} `yaml:"dates"`
+		Bookmarks   map[string]string `yaml:"bookmarks"`
+		Diagnostics map[string]string `yaml:"diagnostics"`
+		ListMarker  string            `yaml:"list_marker"`
+		Indent      int               `yaml:"indent"`
+		Editor      string            `yaml:"editor"`
+		Compile     struct {
+			Separator *string `yaml:"separator"`
+		} `yaml:"compile"`
+		Binder  string   `yaml:"binder"`
+		Binders []string `yaml:"binders"`
+		Watch   struct {
+			Debounce string `yaml:"debounce"`
+		} `yaml:"watch"`
+	}
+	if err := yaml.Unmarshal(expandEnv(config), &cfg); err != nil {
+		return ProjectConfig{}, fmt.Errorf("parse project settings: %w", err)
+	}
+	switch mode := cfg.Wikilinks.Resolution; mode {
+	case "", binder.WikilinkResolutionBinder, binder.WikilinkResolutionShortest:
+	default:
+		return ProjectConfig{}, fmt.Errorf("unknown wikilinks.resolution %q (want binder or shortest)", mode)
+	}
+	for _, heading := range cfg.ReferenceSections {
+		if strings.TrimSpace(heading) == "" {
+			return ProjectConfig{}, fmt.Errorf("reference_sections contains a blank heading")
+		}
+	}
+	if _, err := LookupIDScheme(cfg.IDScheme); err != nil {
+		return ProjectConfig{}, err
+	}
+	if _, err := ParseDateFormat(cfg.Dates.Format, cfg.Dates.Timezone); err != nil {
+		return ProjectConfig{}, fmt.Errorf("dates: %w", err)
+	}
+	for name, selector := range cfg.Bookmarks {
+		if err := ValidateBookmark(name, selector); err != nil {
+			return ProjectConfig{}, fmt.Errorf("bookmarks: %w", err)
+		}
+	}
+	for code, sev := range cfg.Diagnostics {
+		switch sev {
+		case "error", "warning", binder.SeverityInfo, binder.SeverityOff:
+		default:
+			return ProjectConfig{}, fmt.Errorf("diagnostics: unknown severity %q for %s (want error, warning, info, or off)", sev, code)
+		}
+	}
+	switch cfg.ListMarker {
+	case "", "-", "*", "+":
+	default:
+		return ProjectConfig{}, fmt.Errorf("unknown list_marker %q (want -, *, or +)", cfg.ListMarker)
+	}
+	if cfg.Indent < 0 || cfg.Indent > 8 {
+		return ProjectConfig{}, fmt.Errorf("indent must be between 1 and 8, not %d", cfg.Indent)
+	}
+	if cfg.Binder != "" {
+		if err := binder.ValidateBinderName(cfg.Binder); err != nil {
+			return ProjectConfig{}, err
+		}
+	}
+	for i, name := range cfg.Binders {
+		if err := binder.ValidateBinderPath(name); err != nil {
+			return ProjectConfig{}, fmt.Errorf("binders: %w", err)
+		}
+		cfg.Binders[i] = path.Clean(name)
+	}
+	var debounce time.Duration
+	if cfg.Watch.Debounce != "" {
+		var err error
+		if debounce, err = time.ParseDuration(cfg.Watch.Debounce); err != nil || debounce <= 0 {
+			return ProjectConfig{}, fmt.Errorf("watch.debounce must be a positive duration such as 500ms, not %q", cfg.Watch.Debounce)
+		}
+	}
+	limits := binder.ParseLimits(cfg.Limits)
+	for key, v := range map[string]int{
+		"max_file_size":   limits.MaxFileSize,
+		"max_line_length": limits.MaxLineLength,
+		"max_nodes":       limits.MaxNodes,
+		"max_ref_defs":    limits.MaxRefDefs,
+	} {
+		if v < 0 {
+			return ProjectConfig{}, fmt.Errorf("limits.%s must not be negative", key)
+		}
+	}
+	return ProjectConfig{
+		WikilinkResolution: cfg.Wikilinks.Resolution,
+		ReferenceSections:  cfg.ReferenceSections,
+		Limits:             limits,
+		IDScheme:           cfg.IDScheme,
+		TextPlaceholders:   cfg.TextPlaceholders,
+		SubBinders:         cfg.SubBinders,
+		DateFormat:         cfg.Dates.Format,
+		Timezone:           cfg.Dates.Timezone,
+		Bookmarks:          cfg.Bookmarks,
+		SeverityOverrides:  cfg.Diagnostics,
+		ListMarker:         cfg.ListMarker,
+		IndentWidth:        cfg.Indent,
+		Editor:             cfg.Editor,
+		CompileSeparator:   cfg.Compile.Separator,
+		Binder:             cfg.Binder,
+		Binders:            cfg.Binders,
+		WatchDebounce:      debounce,
+	}, nil
+}
+
+// ValidateBookmark reports whether name and selector make a valid bookmark:
+// a valid name (see binder.ValidBookmarkName) for a selector that is not
+// blank and is not itself a bookmark.
+func ValidateBookmark(name, selector string) error {
+	switch {
+	case !binder.ValidBookmarkName(name):
+		return fmt.Errorf("invalid bookmark name %q (use letters, digits, _, ., and -)", name)
+	case strings.TrimSpace(selector) == "":
+		return fmt.Errorf("bookmark %q has a blank selector", name)
+	case strings.HasPrefix(selector, binder.BookmarkPrefix):
+		return fmt.Errorf("bookmark %q cannot point at another bookmark (%s)", name, selector)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package node
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestParseProjectConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    ProjectConfig
+		wantErr bool
+	}{
+		{"unset", "types: {}\n", ProjectConfig{}, false},
+		{"binder", "wikilinks:\n  resolution: binder\n", ProjectConfig{WikilinkResolution: "binder"}, false},
+		{"shortest", "wikilinks:\n  resolution: shortest\n", ProjectConfig{WikilinkResolution: "shortest"}, false},
+		{"reference sections", "reference_sections: [See also, References]\n", ProjectConfig{ReferenceSections: []string{"See also", "References"}}, false},
+		{"unknown resolution", "wikilinks:\n  resolution: nearest\n", ProjectConfig{}, true},
+		{"blank reference section", "reference_sections: [\" \"]\n", ProjectConfig{}, true},
+		{"invalid yaml", "wikilinks: [\n", ProjectConfig{}, true},
+		{"limits", "limits:\n  max_nodes: 500\n  max_line_length: 4096\n", ProjectConfig{Limits: binder.ParseLimits{MaxNodes: 500, MaxLineLength: 4096}}, false},
+		{"negative limit", "limits:\n  max_ref_defs: -1\n", ProjectConfig{}, true},
+		{"id scheme", "id_scheme: ulid\n", ProjectConfig{IDScheme: "ulid"}, false},
+		{"unknown id scheme", "id_scheme: serial\n", ProjectConfig{}, true},
+		{"text placeholders", "text_placeholders: true\n", ProjectConfig{TextPlaceholders: true}, false},
+		{"sub-binders", "sub_binders: true\n", ProjectConfig{SubBinders: true}, false},
+		{"dates", "dates:\n  format: long\n  timezone: Europe/Paris\n", ProjectConfig{DateFormat: "long", Timezone: "Europe/Paris"}, false},
+		{"unknown date format", "dates:\n  format: fancy\n", ProjectConfig{}, true},
+		{"unknown time zone", "dates:\n  timezone: Mars/Olympus\n", ProjectConfig{}, true},
+		{"bookmarks", "bookmarks:\n  act2: part-two.md\n", ProjectConfig{Bookmarks: map[string]string{"act2": "part-two.md"}}, false},
+		{"invalid bookmark name", "bookmarks:\n  act two: part-two.md\n", ProjectConfig{}, true},
+		{"blank bookmark selector", "bookmarks:\n  act2: \" \"\n", ProjectConfig{}, true},
+		{"bookmark of a bookmark", "bookmarks:\n  act2: \"@act1\"\n", ProjectConfig{}, true},
+		{"diagnostics", "diagnostics:\n  BNDW004: error\n  BNDW006: \"off\"\n", ProjectConfig{SeverityOverrides: map[string]string{"BNDW004": "error", "BNDW006": "off"}}, false},
+		{"unknown severity", "diagnostics:\n  BNDW004: fatal\n", ProjectConfig{}, true},
+		{"list style", "list_marker: \"*\"\nindent: 4\n", ProjectConfig{ListMarker: "*", IndentWidth: 4}, false},
+		{"unknown list marker", "list_marker: \"1.\"\n", ProjectConfig{}, true},
+		{"indent too wide", "indent: 12\n", ProjectConfig{}, true},
+		{"editor", "editor: code --wait\n", ProjectConfig{Editor: "code --wait"}, false},
+		{"compile separator", "compile:\n  separator: \"\\n\\n* * *\\n\\n\"\n", ProjectConfig{CompileSeparator: ptr("\n\n* * *\n\n")}, false},
+		{"empty compile separator", "compile:\n  separator: \"\"\n", ProjectConfig{CompileSeparator: ptr("")}, false},
+		{"binders", "binder: draft.md\nbinders: [_outtakes.md]\n", ProjectConfig{Binder: "draft.md", Binders: []string{"_outtakes.md"}}, false},
+		{"binder not markdown", "binder: draft.txt\n", ProjectConfig{}, true},
+		{"binder in a subdirectory", "binders: [./part/x_binder.md]\n", ProjectConfig{Binders: []string{"part/x_binder.md"}}, false},
+		{"default binder in a subdirectory", "binder: part/_binder.md\n", ProjectConfig{}, true},
+		{"binder outside the project", "binders: [../x_binder.md]\n", ProjectConfig{}, true},
+		{"watch debounce", "watch:\n  debounce: 500ms\n", ProjectConfig{WatchDebounce: 500 * time.Millisecond}, false},
+		{"watch debounce not a duration", "watch:\n  debounce: soon\n", ProjectConfig{}, true},
+		{"watch debounce not positive", "watch:\n  debounce: 0s\n", ProjectConfig{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseProjectConfig([]byte(tt.config))
+			if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseProjectConfig() = %+v, %v; want %+v, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func ptr(s string) *string { return &s }
+
+func TestParseProjectConfig_ExpandsEnv(t *testing.T) {
+	t.Setenv("PMK_TEST_EDITOR", "nano -w")
+	t.Setenv("PMK_TEST_UNSET", "")
+	got, err := ParseProjectConfig([]byte("editor: ${PMK_TEST_EDITOR}\nbookmarks:\n  cost: \"$5${PMK_TEST_UNSET}.md\"\n"))
+	if err != nil {
+		t.Fatalf("ParseProjectConfig: %v", err)
+	}
+	if got.Editor != "nano -w" || got.Bookmarks["cost"] != "$5.md" {
+		t.Errorf("ParseProjectConfig() = %+v, want ${...} expanded and a bare $ kept", got)
+	}
+}
+
+func TestConfigPath(t *testing.T) {
+	if got, want := ConfigPath("/proj"), filepath.Join("/proj", ConfigFilename); got != want {
+		t.Errorf("ConfigPath() = %q, want %q", got, want)
+	}
+	SetConfigOverride("/etc/ci.yml")
+	defer SetConfigOverride("")
+	if got := ConfigPath("/proj"); got != "/etc/ci.yml" {
+		t.Errorf("overridden ConfigPath() = %q, want /etc/ci.yml", got)
+	}
+}
+
+func TestProjectConfig_BinderNames(t *testing.T) {
+	if got := (ProjectConfig{}).BinderNames(); !reflect.DeepEqual(got, []string{"_binder.md"}) {
+		t.Errorf("default BinderNames() = %v", got)
+	}
+	c := ProjectConfig{Binder: "draft.md", Binders: []string{"_outtakes.md", "draft.md"}}
+	if got := c.BinderNames(); !reflect.DeepEqual(got, []string{"draft.md", "_outtakes.md"}) {
+		t.Errorf("BinderNames() = %v, want draft.md then _outtakes.md", got)
+	}
+}
@@ -0,0 +1,42 @@
+package node
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+)
+
+var (
+	// dropboxConflictRE matches a Dropbox conflicted copy such as
+	// "chapter (conflicted copy 2026-03-01).md" or
+	// "chapter (Ann's conflicted copy 2026-03-01 (1)).md".
+	dropboxConflictRE = regexp.MustCompile(`^(.+?) \([^()]*conflicted copy[^()]*(?:\(\d+\))?\)(\.[^.]+)?$`)
+	// syncthingConflictRE matches a Syncthing conflict copy such as
+	// "chapter.sync-conflict-20260301-120000-ABCDEF7.md".
+	syncthingConflictRE = regexp.MustCompile(`^(.+?)\.sync-conflict-\d{8}-\d{6}(?:-[A-Z0-9]+)?(\.[^.]+)?$`)
+)
+
+// ConflictCopy reports whether the file at name, a slash path, is a copy a
+// cloud-sync client (Dropbox or Syncthing) left beside a file changed in two
+// places at once, and returns the path of the file it is a copy of.
+func ConflictCopy(name string) (string, bool) {
+	dir, base := path.Split(name)
+	for _, re := range []*regexp.Regexp{dropboxConflictRE, syncthingConflictRE} {
+		if m := re.FindStringSubmatch(base); m != nil {
+			return dir + m[1] + m[2], true
+		}
+	}
+	return "", false
+}
+
+// AuditConflictCopies returns an AUD012 warning for each cloud-sync
+// conflict copy among files, project-relative slash paths.
+func AuditConflictCopies(files []string) []AuditDiagnostic {
+	var diags []AuditDiagnostic
+	for _, f := range files {
+		if canonical, ok := ConflictCopy(f); ok {
+			diags = append(diags, warnDiag(AUD012, f, fmt.Sprintf("cloud-sync conflict copy of %s; compare and merge or discard it with pmk conflicts resolve", canonical)))
+		}
+	}
+	return diags
+}
@@ -0,0 +1,38 @@
+package node_test
+
+import (
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+func TestConflictCopy(t *testing.T) {
+	tests := []struct {
+		name      string
+		canonical string
+		ok        bool
+	}{
+		{"chapter (conflicted copy 2026-03-01).md", "chapter.md", true},
+		{"part/chapter one (Ann's conflicted copy 2026-03-01 (1)).md", "part/chapter one.md", true},
+		{"_binder (conflicted copy 2026-03-01).md", "_binder.md", true},
+		{"chapter.sync-conflict-20260301-120000-ABCDEF7.md", "chapter.md", true},
+		{"notes/ch1.notes.sync-conflict-20260301-120000.md", "notes/ch1.notes.md", true},
+		{"chapter (draft).md", "", false},
+		{"chapter.md", "", false},
+		{"sync-conflict.md", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := node.ConflictCopy(tt.name)
+		if got != tt.canonical || ok != tt.ok {
+			t.Errorf("ConflictCopy(%q) = %q, %v; want %q, %v", tt.name, got, ok, tt.canonical, tt.ok)
+		}
+	}
+}
+
+func TestAuditConflictCopies(t *testing.T) {
+	diags := node.AuditConflictCopies([]string{"ch1.md", "ch1 (conflicted copy 2026-03-01).md"})
+	if len(diags) != 1 || diags[0].Code != node.AUD012 || diags[0].Severity != node.SeverityWarning ||
+		diags[0].Path != "ch1 (conflicted copy 2026-03-01).md" {
+		t.Errorf("diags = %+v, want one AUD012 warning for the copy", diags)
+	}
+}
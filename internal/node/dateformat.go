@@ -0,0 +1,65 @@
+package node
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Named date formats for the dates.format setting and --date-format flags.
+var dateFormatLayouts = map[string]string{
+	"date":     "2006-01-02",
+	"datetime": "2006-01-02 15:04",
+	"long":     "January 2, 2006 3:04 PM",
+	"rfc3339":  time.RFC3339,
+}
+
+// DefaultDateFormat is the named format timestamps are shown in when none is
+// configured.
+const DefaultDateFormat = "datetime"
+
+// DateFormat renders the RFC3339 UTC timestamps of node frontmatter for
+// people. Files always keep RFC3339 UTC; only displays are converted.
+type DateFormat struct {
+	// Layout is the Go time layout timestamps are formatted with.
+	Layout string
+	// Location is the time zone timestamps are shown in.
+	Location *time.Location
+}
+
+// ParseDateFormat returns the DateFormat for format, one of the named
+// formats (date, datetime, long, rfc3339) or a Go time layout such as
+// "02 Jan 2006", and timezone, an IANA zone name, "UTC", or "local". Empty
+// values select DefaultDateFormat and the local time zone.
+func ParseDateFormat(format, timezone string) (DateFormat, error) {
+	layout, ok := dateFormatLayouts[strings.ToLower(format)]
+	switch {
+	case format == "":
+		layout = dateFormatLayouts[DefaultDateFormat]
+	case !ok:
+		// A layout without any reference-time element formats every time
+		// as itself.
+		if time.Date(1999, 11, 30, 3, 7, 9, 0, time.UTC).Format(format) == format {
+			return DateFormat{}, fmt.Errorf("unknown date format %q (want date, datetime, long, rfc3339, or a Go layout like \"2006-01-02\")", format)
+		}
+		layout = format
+	}
+	loc := time.Local
+	if timezone != "" && !strings.EqualFold(timezone, "local") {
+		var err error
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return DateFormat{}, fmt.Errorf("unknown time zone %q", timezone)
+		}
+	}
+	return DateFormat{Layout: layout, Location: loc}, nil
+}
+
+// Format renders the RFC3339 timestamp ts in f's layout and time zone. A
+// value that is not RFC3339, including "", is returned unchanged.
+func (f DateFormat) Format(ts string) string {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return ts
+	}
+	return t.In(f.Location).Format(f.Layout)
+}
@@ -0,0 +1,51 @@
+package node
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDateFormat(t *testing.T) {
+	tests := []struct {
+		format, timezone string
+		ts               string
+		want             string
+	}{
+		{"", "UTC", "2026-03-04T17:05:00Z", "2026-03-04 17:05"},
+		{"date", "UTC", "2026-03-04T17:05:00Z", "2026-03-04"},
+		{"Long", "America/New_York", "2026-03-04T17:05:00Z", "March 4, 2026 12:05 PM"},
+		{"rfc3339", "Asia/Tokyo", "2026-03-04T17:05:00Z", "2026-03-05T02:05:00+09:00"},
+		{"02 Jan 2006", "UTC", "2026-03-04T17:05:00Z", "04 Mar 2026"},
+		{"date", "UTC", "not a time", "not a time"},
+		{"date", "UTC", "", ""},
+	}
+	for _, tt := range tests {
+		f, err := ParseDateFormat(tt.format, tt.timezone)
+		if err != nil {
+			t.Errorf("ParseDateFormat(%q, %q): %v", tt.format, tt.timezone, err)
+			continue
+		}
+		if got := f.Format(tt.ts); got != tt.want {
+			t.Errorf("ParseDateFormat(%q, %q).Format(%q) = %q, want %q", tt.format, tt.timezone, tt.ts, got, tt.want)
+		}
+	}
+}
+
+func TestParseDateFormat_Local(t *testing.T) {
+	for _, tz := range []string{"", "local", "Local"} {
+		f, err := ParseDateFormat("", tz)
+		if err != nil || f.Location != time.Local {
+			t.Errorf("ParseDateFormat(\"\", %q) = %v, %v; want the local zone", tz, f.Location, err)
+		}
+	}
+}
+
+func TestParseDateFormat_Errors(t *testing.T) {
+	if _, err := ParseDateFormat("fancy", ""); err == nil || !strings.Contains(err.Error(), `unknown date format "fancy"`) {
+		t.Errorf("bad format: err = %v", err)
+	}
+	if _, err := ParseDateFormat("", "Mars/Olympus"); err == nil || !strings.Contains(err.Error(), `unknown time zone "Mars/Olympus"`) {
+		t.Errorf("bad zone: err = %v", err)
+	}
+}
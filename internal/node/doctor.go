@@ -15,17 +15,40 @@ import (
 type DoctorData struct {
 	// BinderSrc is the raw content of the project's _binder.md file.
 	BinderSrc []byte
-	// UUIDFiles is the list of UUID-pattern .md filenames found in the project root.
+	// UUIDFiles is the list of node filenames (see IDScheme) found in the
+	// project root.
 	UUIDFiles []string
+	// IDScheme decides which filenames are node files; nil means
+	// DefaultIDScheme.
+	IDScheme IDScheme
 	// FileContents maps each filename to its raw bytes.
 	// A nil value indicates the file does not exist on disk.
 	FileContents map[string][]byte
+	// Escaping holds, keyed by ref, the diagnostic for each referenced file
+	// that leads outside the project, such as through a symlink. It is
+	// reported in place of the file's other checks, since it was not read.
+	Escaping map[string]AuditDiagnostic
 	// BinderRefs, when non-nil, provides deduplicated refs pre-computed by CollectBinderRefs.
 	// RunDoctor uses these directly and skips re-parsing BinderSrc.
 	BinderRefs []string
 	// BinderRefDiags holds diagnostics produced by CollectBinderRefs (escape warnings, AUD003).
 	// Ignored unless BinderRefs is non-nil.
 	BinderRefDiags []AuditDiagnostic
+	// Schema, when non-nil, declares per-type frontmatter fields checked by AUD010.
+	Schema FrontmatterSchema
+	// Project, when non-nil, supplies the files and aliases that links in
+	// node bodies are checked against (AUD011), and the files checked for
+	// cloud-sync conflict copies (AUD012).
+	Project *binder.Project
+	// Cache, when non-nil, supplies and records the frontmatter results of
+	// unchanged node files. Entries for files no longer in the binder are
+	// dropped unless Subset is set.
+	Cache *AuditCache
+	// Subset, when non-nil, restricts the audit to these project-relative paths.
+	// The full binder and UUID file list still supply orphan and duplicate
+	// context, but only diagnostics whose Path is in Subset are reported, and
+	// only the subset's files need entries in FileContents.
+	Subset map[string]bool
 }
 
 // RunDoctor performs all audit checks on the provided pre-loaded project data
@@ -36,6 +59,10 @@ type DoctorData struct {
 // and skips re-parsing BinderSrc, ensuring binder.Parse is called at most once
 // per doctor invocation.
 func RunDoctor(ctx context.Context, data DoctorData) []AuditDiagnostic {
+	scheme := data.IDScheme
+	if scheme == nil {
+		scheme = DefaultIDScheme
+	}
 	var diags []AuditDiagnostic
 	var refs []string
 	visited := make(map[string]bool)
@@ -72,11 +99,20 @@ func RunDoctor(ctx context.Context, data DoctorData) []AuditDiagnostic {
 
 	// Check each uniquely referenced file.
 	for _, ref := range refs {
-		isUUID := IsUUIDFilename(ref)
+		if data.Subset != nil && !data.Subset[ref] {
+			continue
+		}
+		// BNDE002: the file leads outside the project, so it was not read.
+		if d, ok := data.Escaping[ref]; ok {
+			diags = append(diags, d)
+			continue
+		}
+
+		isUUID := scheme.MatchFilename(ref)
 
-		// AUDW001: non-UUID filename linked in binder.
+		// AUDW001: non-node filename linked in binder.
 		if !isUUID {
-			diags = append(diags, warnDiag(AUDW001, ref, fmt.Sprintf("non-UUID filename linked in binder: %s", ref)))
+			diags = append(diags, warnDiag(AUDW001, ref, fmt.Sprintf("non-%s filename linked in binder: %s", scheme.Label(), ref)))
 		}
 
 		// AUD001: referenced file does not exist.
@@ -86,33 +122,38 @@ func RunDoctor(ctx context.Context, data DoctorData) []AuditDiagnostic {
 			continue
 		}
 
+		// AUD011: links in the body to files that do not exist.
+		if data.Project != nil {
+			diags = append(diags, AuditBodyLinks(ref, content, data.Project)...)
+		}
+
 		// No frontmatter checks for non-UUID files.
 		if !isUUID {
 			continue
 		}
 
-		// AUD007: parse frontmatter.
-		stem := strings.TrimSuffix(ref, ".md")
-		fm, body, err := ParseFrontmatter(content)
-		if err != nil {
-			diags = append(diags, errDiag(AUD007, ref, fmt.Sprintf("frontmatter YAML is syntactically invalid: %v", err)))
-			continue
-		}
+		diags = append(diags, data.Cache.audit(ref, content, data.Schema)...)
+	}
+	if data.Cache != nil && data.Subset == nil {
+		data.Cache.retain(refs)
+	}
 
-		// AUD004, AUD005, AUD006 via ValidateNode.
-		for _, d := range ValidateNode(stem, fm, body) {
-			d.Path = ref
-			diags = append(diags, d)
-		}
+	// AUD012: cloud-sync conflict copies anywhere in the project.
+	if data.Project != nil {
+		diags = append(diags, AuditConflictCopies(data.Project.Files)...)
 	}
 
 	// Detect orphaned UUID files (AUD002).
 	for _, uuidFile := range data.UUIDFiles {
 		if !visited[uuidFile] {
-			diags = append(diags, warnDiag(AUD002, uuidFile, fmt.Sprintf("orphaned UUID file not referenced in binder: %s", uuidFile)))
+			diags = append(diags, warnDiag(AUD002, uuidFile, fmt.Sprintf("orphaned %s file not referenced in binder: %s", scheme.Label(), uuidFile)))
 		}
 	}
 
+	if data.Subset != nil {
+		diags = FilterAuditDiagnostics(diags, data.Subset)
+	}
+
 	// Sort: errors before warnings, then alphabetically by path within each tier.
 	sort.SliceStable(diags, func(i, j int) bool {
 		si := severityRank(diags[i].Severity)
@@ -126,6 +167,55 @@ func RunDoctor(ctx context.Context, data DoctorData) []AuditDiagnostic {
 	return diags
 }
 
+// auditFrontmatter runs the frontmatter checks on the node file at ref:
+// AUD007, then AUD004–AUD006 via ValidateNode and AUD010 against schema.
+// Its result depends only on its arguments, so AuditCache can store it.
+func auditFrontmatter(ref string, content []byte, schema FrontmatterSchema) []AuditDiagnostic {
+	stem := strings.TrimSuffix(ref, ".md")
+	fm, body, err := ParseFrontmatter(content)
+	if err != nil {
+		return []AuditDiagnostic{errDiag(AUD007, ref, fmt.Sprintf("frontmatter YAML is syntactically invalid: %v", err))}
+	}
+
+	var diags []AuditDiagnostic
+	for _, d := range ValidateNode(stem, fm, body) {
+		d.Path = ref
+		diags = append(diags, d)
+	}
+	return append(diags, ValidateFrontmatterSchema(ref, content, schema)...)
+}
+
+// FilterAuditDiagnostics returns the diagnostics in diags whose Path is in paths.
+func FilterAuditDiagnostics(diags []AuditDiagnostic, paths map[string]bool) []AuditDiagnostic {
+	var kept []AuditDiagnostic
+	for _, d := range diags {
+		if paths[d.Path] {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+// ApplyAuditSeverityOverrides is binder.ApplySeverityOverrides for audit
+// diagnostics: a non-error diagnostic whose code has an entry in overrides
+// is reported at that severity, or dropped for binder.SeverityOff.
+func ApplyAuditSeverityOverrides(diags []AuditDiagnostic, overrides map[string]string) []AuditDiagnostic {
+	if len(overrides) == 0 {
+		return diags
+	}
+	out := make([]AuditDiagnostic, 0, len(diags))
+	for _, d := range diags {
+		if sev, ok := overrides[string(d.Code)]; ok && d.Severity != SeverityError {
+			if sev == binder.SeverityOff {
+				continue
+			}
+			d.Severity = AuditSeverity(sev)
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
 // severityRank returns a numeric rank for sorting: errors (0) sort before warnings (1).
 func severityRank(s AuditSeverity) int {
 	if s == SeverityError {
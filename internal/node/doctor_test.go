@@ -2,8 +2,10 @@ package node_test
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
+	"github.com/eykd/prosemark-go/internal/binder"
 	"github.com/eykd/prosemark-go/internal/node"
 )
 
@@ -586,6 +588,68 @@ func TestRunDoctor_SortOrder(t *testing.T) {
 	}
 }
 
+// TestRunDoctor_Subset verifies that a subset restricts reported diagnostics
+// to the listed paths while the full binder still decides which files are
+// orphaned or duplicated, and that unlisted files need no FileContents entry.
+func TestRunDoctor_Subset(t *testing.T) {
+	data := node.DoctorData{
+		BinderSrc: binderWithRefs(testDoctorUUID1+".md", testDoctorUUID2+".md", testDoctorUUID2+".md"),
+		UUIDFiles: []string{testDoctorUUID1 + ".md", testDoctorUUID2 + ".md", testDoctorUUID3 + ".md"},
+		FileContents: map[string][]byte{
+			testDoctorUUID2 + ".md": nodeFileBytes(testDoctorUUID2),
+		},
+		Subset: map[string]bool{testDoctorUUID2 + ".md": true, testDoctorUUID3 + ".md": true},
+	}
+
+	diags := node.RunDoctor(context.Background(), data)
+
+	want := []string{"AUD003 " + testDoctorUUID2 + ".md", "AUD002 " + testDoctorUUID3 + ".md"}
+	if len(diags) != len(want) {
+		t.Fatalf("got %d diagnostics %v, want %v", len(diags), diags, want)
+	}
+	for i, d := range diags {
+		if got := string(d.Code) + " " + d.Path; got != want[i] {
+			t.Errorf("diags[%d] = %s, want %s", i, got, want[i])
+		}
+	}
+}
+
+// TestRunDoctor_Escaping verifies that a referenced file leading outside the
+// project is reported with its Escaping diagnostic alone, not read or checked.
+func TestRunDoctor_Escaping(t *testing.T) {
+	ref := testDoctorUUID1 + ".md"
+	escape := node.AuditDiagnostic{Code: "BNDE002", Severity: node.SeverityError, Message: "Link target resolves outside the project root: " + ref, Path: ref}
+	data := node.DoctorData{
+		BinderSrc: binderWithRefs(ref),
+		UUIDFiles: []string{ref},
+		Escaping:  map[string]node.AuditDiagnostic{ref: escape},
+	}
+
+	diags := node.RunDoctor(context.Background(), data)
+
+	if len(diags) != 1 || diags[0] != escape {
+		t.Errorf("diags = %v, want only %v", diags, escape)
+	}
+}
+
+// TestRunDoctor_ConflictCopies verifies that cloud-sync conflict copies among
+// the project's files are reported as AUD012 warnings.
+func TestRunDoctor_ConflictCopies(t *testing.T) {
+	copyName := testDoctorUUID1 + " (conflicted copy 2026-03-01).md"
+	data := node.DoctorData{
+		BinderSrc:    binderWithRefs(testDoctorUUID1 + ".md"),
+		UUIDFiles:    []string{testDoctorUUID1 + ".md"},
+		FileContents: map[string][]byte{testDoctorUUID1 + ".md": nodeFileBytes(testDoctorUUID1)},
+		Project:      &binder.Project{Files: []string{testDoctorUUID1 + ".md", copyName}, BinderDir: "."},
+	}
+
+	diags := node.RunDoctor(context.Background(), data)
+
+	if len(diags) != 1 || diags[0].Code != node.AUD012 || diags[0].Path != copyName {
+		t.Errorf("diags = %v, want one AUD012 for %s", diags, copyName)
+	}
+}
+
 // TestRunDoctor_CycleGuard verifies that the visited-map prevents duplicate AUD001 checks
 // and correctly emits exactly one AUD003 per duplicated target regardless of how many
 // times it appears in the binder tree.
@@ -758,3 +822,33 @@ func TestRunDoctor_NoPanic_CancelledContext(t *testing.T) {
 	// Must not panic regardless of cancellation state.
 	_ = node.RunDoctor(ctx, data)
 }
+
+func TestApplyAuditSeverityOverrides(t *testing.T) {
+	diags := []node.AuditDiagnostic{
+		{Code: node.AUDW001, Severity: node.SeverityWarning, Path: "a.md"},
+		{Code: node.AUD011, Severity: node.SeverityWarning, Path: "b.md"},
+		{Code: node.AUD001, Severity: node.SeverityError, Path: "c.md"},
+		{Code: node.AUD003, Severity: node.SeverityWarning, Path: "d.md"},
+	}
+	overrides := map[string]string{
+		string(node.AUDW001): "error",
+		string(node.AUD011):  binder.SeverityOff,
+		string(node.AUD001):  binder.SeverityOff,
+		"AUD999":             "error",
+	}
+	got := node.ApplyAuditSeverityOverrides(diags, overrides)
+	want := []node.AuditDiagnostic{
+		{Code: node.AUDW001, Severity: node.SeverityError, Path: "a.md"},
+		{Code: node.AUD001, Severity: node.SeverityError, Path: "c.md"},
+		{Code: node.AUD003, Severity: node.SeverityWarning, Path: "d.md"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ApplyAuditSeverityOverrides() = %+v, want %+v", got, want)
+	}
+	if diags[0].Severity != node.SeverityWarning {
+		t.Error("ApplyAuditSeverityOverrides modified its input")
+	}
+	if got := node.ApplyAuditSeverityOverrides(diags, nil); !reflect.DeepEqual(got, diags) {
+		t.Errorf("ApplyAuditSeverityOverrides(nil overrides) = %+v, want input unchanged", got)
+	}
+}
@@ -5,20 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
-// uuidV7FilenameRE matches lowercase UUIDv7 filenames with a .md extension.
-var uuidV7FilenameRE = regexp.MustCompile(
-	`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[0-9a-f]{4}-[0-9a-f]{12}\.md$`,
-)
-
-// IsUUIDFilename reports whether filename is a lowercase UUIDv7 .md filename.
+// IsUUIDFilename reports whether filename is a lowercase UUIDv7 .md filename,
+// the node filenames of the default ID scheme.
 func IsUUIDFilename(filename string) bool {
-	return uuidV7FilenameRE.MatchString(filename)
+	return UUIDv7IDs.MatchFilename(filename)
 }
 
 // frontmatterRE matches a complete YAML frontmatter block at the start of a
@@ -47,7 +44,7 @@ func ParseFrontmatter(content []byte) (Frontmatter, []byte, error) {
 		return Frontmatter{}, nil, fmt.Errorf("parse frontmatter: %w", err)
 	}
 
-	for _, field := range []string{fm.ID, fm.Title, fm.Synopsis, fm.Created, fm.Updated} {
+	for _, field := range []string{fm.ID, fm.Title, fm.Type, fm.Synopsis, fm.Source, fm.Captured, fm.Created, fm.Updated} {
 		if containsControlChars(field) {
 			return Frontmatter{}, nil, errors.New("frontmatter field contains invalid control character")
 		}
@@ -56,6 +53,53 @@ func ParseFrontmatter(content []byte) (Frontmatter, []byte, error) {
 	return fm, bytes.Clone(content[loc[1]:]), nil
 }
 
+// Body returns content without its frontmatter block. Content without
+// frontmatter is returned whole.
+func Body(content []byte) []byte {
+	_, body := splitBody(content)
+	return body
+}
+
+// frontmatterIDLineRE matches the top-level "id:" line of a frontmatter block.
+var frontmatterIDLineRE = regexp.MustCompile(`(?m)^id:.*$`)
+
+// RenameID returns content with its frontmatter id changed to newID when it
+// is oldID, and reports whether it was. Only the id line is rewritten, so
+// unknown keys, formatting, and a locked body are left as they were.
+func RenameID(content []byte, oldID, newID string) ([]byte, bool) {
+	fm, _, err := ParseFrontmatter(content)
+	if err != nil || fm.ID != oldID {
+		return content, false
+	}
+	head, body := splitBody(content)
+	loc := frontmatterIDLineRE.FindIndex(head)
+	out := slices.Concat(head[:loc[0]], []byte("id: "+yamlScalar(newID)), head[loc[1]:], body)
+	return out, true
+}
+
+// SetTitle returns content with its frontmatter title set to title, and
+// reports whether it changed. Only the title is rewritten, so unknown keys,
+// formatting, and a locked body are left as they were; a block without a
+// title gets one after its id, or first when it has no id. Content without
+// frontmatter is an error.
+func SetTitle(content []byte, title string) ([]byte, bool, error) {
+	fm, _, err := ParseFrontmatter(content)
+	if err != nil {
+		return content, false, err
+	}
+	if fm.Title == title {
+		return content, false, nil
+	}
+	doc, err := ReadFrontmatter(content)
+	if err != nil {
+		return content, false, err
+	}
+	if err := doc.setLine("title", "title: "+fieldScalar(title), "id"); err != nil {
+		return content, false, err
+	}
+	return WriteFrontmatter(doc), true, nil
+}
+
 // containsControlChars reports whether s contains any control characters that
 // are not permitted in frontmatter field values. The range 0x09–0x0D (TAB,
 // LF, VT, FF, CR) is allowed; all other characters below U+0020 and the DEL
@@ -70,18 +114,28 @@ func containsControlChars(s string) bool {
 }
 
 // SerializeFrontmatter serializes fm into a canonical frontmatter block.
-// Field order: id → title → synopsis → created → updated.
-// Empty optional fields (title, synopsis) are omitted.
+// Field order: id → title → type → synopsis → source → captured → created → updated.
+// Empty optional fields (title, type, synopsis, source, captured) are omitted,
+// and text fields are written as scalars that decode back to their values.
 // The output is wrapped in "---\n" delimiters.
 func SerializeFrontmatter(fm Frontmatter) []byte {
 	var buf bytes.Buffer
 	buf.WriteString("---\n")
 	buf.WriteString("id: " + fm.ID + "\n")
 	if fm.Title != "" {
-		buf.WriteString("title: " + yamlScalar(fm.Title) + "\n")
+		buf.WriteString("title: " + fieldScalar(fm.Title) + "\n")
+	}
+	if fm.Type != "" {
+		buf.WriteString("type: " + fieldScalar(fm.Type) + "\n")
 	}
 	if fm.Synopsis != "" {
-		buf.WriteString("synopsis: " + yamlScalar(fm.Synopsis) + "\n")
+		buf.WriteString("synopsis: " + fieldScalar(fm.Synopsis) + "\n")
+	}
+	if fm.Source != "" {
+		buf.WriteString("source: " + fieldScalar(fm.Source) + "\n")
+	}
+	if fm.Captured != "" {
+		buf.WriteString("captured: " + fm.Captured + "\n")
 	}
 	buf.WriteString("created: " + fm.Created + "\n")
 	buf.WriteString("updated: " + fm.Updated + "\n")
@@ -100,11 +154,22 @@ func yamlScalar(s string) string {
 }
 
 // yamlNeedsQuoting reports whether s must be quoted when used as an inline YAML
-// scalar value. In block context, quoting is required when the value starts with
-// a flow indicator ([ or {) or contains an inline comment marker (space + #).
+// scalar value. In block context, quoting is required when the value starts
+// with an indicator character, with "-", "?", or ":" followed by a space, or
+// with whitespace; ends with whitespace or a colon; or contains a mapping
+// separator (": ") or an inline comment marker (" #").
 // s must be non-empty; callers are responsible for guarding empty strings.
 func yamlNeedsQuoting(s string) bool {
-	return s[0] == '[' || s[0] == '{' || strings.Contains(s, " #")
+	if strings.ContainsRune("[]{},#&*!|>'\"%@`", rune(s[0])) {
+		return true
+	}
+	if strings.ContainsRune("-?:", rune(s[0])) && (len(s) == 1 || s[1] == ' ' || s[1] == '\t') {
+		return true
+	}
+	if strings.TrimSpace(s) != s || strings.HasSuffix(s, ":") {
+		return true
+	}
+	return strings.Contains(s, ": ") || strings.Contains(s, ":\t") || strings.Contains(s, " #") || strings.Contains(s, "\t#")
 }
 
 // ValidateNode checks the given node for AUD004, AUD005, and AUD006 violations.
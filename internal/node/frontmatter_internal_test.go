@@ -0,0 +1,56 @@
+package node
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestYAMLNeedsQuoting(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"Chapter One", false},
+		{"Part one: the **beginning**", true},
+		{"Note:", true},
+		{"key:value", false},
+		{"**Bold** start", true},
+		{"&anchor", true},
+		{"!tag", true},
+		{"| pipe", true},
+		{"> folded", true},
+		{"'quoted' start", true},
+		{`"quoted" start`, true},
+		{"%directive", true},
+		{"@handle", true},
+		{"`code`", true},
+		{"- a dash", true},
+		{"-", true},
+		{"-dash", false},
+		{"? maybe", true},
+		{"?maybe", false},
+		{": lead", true},
+		{"#hash", true},
+		{"Act 1 # Opening", true},
+		{", comma", true},
+		{" leading space", true},
+		{"trailing space ", true},
+		{"C# in a week", false},
+		{"Rock & Roll!", false},
+	}
+	for _, tt := range tests {
+		if got := yamlNeedsQuoting(tt.in); got != tt.want {
+			t.Errorf("yamlNeedsQuoting(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+		if tt.want {
+			continue
+		}
+		var v struct {
+			V string `yaml:"v"`
+		}
+		if err := yaml.Unmarshal([]byte("v: "+tt.in), &v); err != nil || v.V != tt.in {
+			t.Errorf("plain %q decodes as %q, %v", tt.in, v.V, err)
+		}
+	}
+}
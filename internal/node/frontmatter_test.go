@@ -272,6 +272,74 @@ func TestParseFrontmatter(t *testing.T) {
 // TestSerializeFrontmatter verifies that SerializeFrontmatter produces a
 // canonical frontmatter block with the specified field order:
 // id → title → synopsis → created → updated, omitting optional empty fields.
+func TestBody(t *testing.T) {
+	for content, want := range map[string]string{
+		"---\ntitle: A\n---\n\nText.\n": "\nText.\n",
+		"Just text.\n":                  "Just text.\n",
+		"":                              "",
+	} {
+		if got := string(node.Body([]byte(content))); got != want {
+			t.Errorf("Body(%q) = %q, want %q", content, got, want)
+		}
+	}
+}
+
+func TestRenameID(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+		renamed bool
+	}{
+		{"---\nid: old\nmood: tense # keep\n---\nBody id: old\n", "---\nid: new\nmood: tense # keep\n---\nBody id: old\n", true},
+		{"---\nid: other\n---\n", "---\nid: other\n---\n", false},
+		{"No frontmatter.\n", "No frontmatter.\n", false},
+	}
+	for _, tt := range tests {
+		got, renamed := node.RenameID([]byte(tt.content), "old", "new")
+		if string(got) != tt.want || renamed != tt.renamed {
+			t.Errorf("RenameID(%q) = %q, %v; want %q, %v", tt.content, got, renamed, tt.want, tt.renamed)
+		}
+	}
+}
+
+func TestSetTitle(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		title   string
+		want    string
+		changed bool
+	}{
+		{"replaces", "---\nid: x\ntitle: Old\nmood: tense # keep\n---\nBody\n", "New", "---\nid: x\ntitle: New\nmood: tense # keep\n---\nBody\n", true},
+		{"block scalar", "---\nid: x\ntitle: >\n  Old\n  title\ncreated: a\n---\n", "New", "---\nid: x\ntitle: New\ncreated: a\n---\n", true},
+		{"after id", "---\nid: x\ncreated: a\n---\n", "New", "---\nid: x\ntitle: New\ncreated: a\n---\n", true},
+		{"no id", "---\ncreated: a\n---\n", "New", "---\ntitle: New\ncreated: a\n---\n", true},
+		{"quotes", "---\nid: x\ntitle: Old\n---\n", "Act: One", "---\nid: x\ntitle: 'Act: One'\n---\n", true},
+		{"unchanged", "---\nid: x\ntitle: Same\n---\n", "Same", "---\nid: x\ntitle: Same\n---\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed, err := node.SetTitle([]byte(tt.content), tt.title)
+			if err != nil || string(got) != tt.want || changed != tt.changed {
+				t.Errorf("SetTitle() = %q, %v, %v; want %q, %v", got, changed, err, tt.want, tt.changed)
+			}
+			if fm, _, err := node.ParseFrontmatter(got); err != nil || fm.Title != tt.title {
+				t.Errorf("title = %q, %v; want %q", fm.Title, err, tt.title)
+			}
+		})
+	}
+
+	for _, content := range []string{
+		"No frontmatter.\n",
+		"---\n~\n---\n",                      // not a mapping
+		"---\ntitle: &t Old\nsub: *t\n---\n", // replacing the title drops an anchor
+	} {
+		if got, changed, err := node.SetTitle([]byte(content), "T"); err == nil || changed || string(got) != content {
+			t.Errorf("SetTitle(%q) = %q, %v, %v; want content unchanged and an error", content, got, changed, err)
+		}
+	}
+}
+
 func TestSerializeFrontmatter(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -300,6 +368,41 @@ func TestSerializeFrontmatter(t *testing.T) {
 			wantPrefix: "---\n",
 			wantSuffix: "\n---\n",
 		},
+		{
+			name: "type follows title",
+			fm: node.Frontmatter{
+				ID:      "0192f0c1-3e7a-7000-8000-5a4b3c2d1e0f",
+				Title:   "Arrival",
+				Type:    "scene",
+				Created: "2026-02-28T15:04:05Z",
+				Updated: "2026-02-28T15:04:05Z",
+			},
+			wantFields: []string{
+				"id: 0192f0c1-3e7a-7000-8000-5a4b3c2d1e0f",
+				"title: Arrival",
+				"type: scene",
+				"created: 2026-02-28T15:04:05Z",
+			},
+			wantPrefix: "---\n",
+			wantSuffix: "\n---\n",
+		},
+		{
+			name: "source and captured precede timestamps",
+			fm: node.Frontmatter{
+				ID:       "0192f0c1-3e7a-7000-8000-5a4b3c2d1e0f",
+				Type:     "reference",
+				Source:   "https://example.com/a?b=1#c",
+				Captured: "2026-02-28T15:04:05Z",
+				Created:  "2026-02-28T15:04:05Z",
+				Updated:  "2026-02-28T15:04:05Z",
+			},
+			wantFields: []string{
+				"type: reference",
+				"source: https://example.com/a?b=1#c",
+				"captured: 2026-02-28T15:04:05Z",
+				"created: 2026-02-28T15:04:05Z",
+			},
+		},
 		{
 			name: "minimal fields omit empty title and synopsis",
 			fm: node.Frontmatter{
@@ -312,7 +415,7 @@ func TestSerializeFrontmatter(t *testing.T) {
 				"created: 2026-02-28T15:04:05Z",
 				"updated: 2026-02-28T15:04:05Z",
 			},
-			wantAbsent: []string{"title:", "synopsis:"},
+			wantAbsent: []string{"title:", "type:", "synopsis:"},
 			wantPrefix: "---\n",
 			wantSuffix: "\n---\n",
 		},
@@ -407,6 +510,16 @@ func TestSerializeFrontmatter_RoundTrip(t *testing.T) {
 				Updated: validTS,
 			},
 		},
+		{
+			name: "source with inline comment marker",
+			fm: node.Frontmatter{
+				ID:       validID,
+				Source:   "https://example.com/notes #draft",
+				Captured: validTS,
+				Created:  validTS,
+				Updated:  validTS,
+			},
+		},
 		{
 			name: "title with hash character",
 			fm: node.Frontmatter{
@@ -434,6 +547,34 @@ func TestSerializeFrontmatter_RoundTrip(t *testing.T) {
 				Updated:  validTS,
 			},
 		},
+		{
+			name: "title and synopsis with a colon and emphasis",
+			fm: node.Frontmatter{
+				ID:       validID,
+				Title:    "Part one: the **beginning**",
+				Synopsis: "*Part one*: the beginning",
+				Created:  validTS,
+				Updated:  validTS,
+			},
+		},
+		{
+			name: "title that reads as another type",
+			fm: node.Frontmatter{
+				ID:      validID,
+				Title:   "1984",
+				Created: validTS,
+				Updated: validTS,
+			},
+		},
+		{
+			name: "title starting with a sequence indicator",
+			fm: node.Frontmatter{
+				ID:      validID,
+				Title:   "- and then",
+				Created: validTS,
+				Updated: validTS,
+			},
+		},
 	}
 
 	for _, tt := range tests {
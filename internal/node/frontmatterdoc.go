@@ -0,0 +1,187 @@
+package node
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FrontmatterDoc is a node file held for reading and editing its
+// frontmatter fields. Unlike the Frontmatter ParseFrontmatter returns, it
+// knows every field, and setting one rewrites only that field's lines, so
+// unknown fields, key order, comments, and formatting round-trip unchanged.
+type FrontmatterDoc struct {
+	head, body []byte
+	fields     *yaml.Node // the frontmatter mapping
+}
+
+// ReadFrontmatter reads content's frontmatter for editing. Content without
+// a frontmatter block, or whose block is not a YAML mapping, is an error.
+// The body, locked or not, is kept as it is.
+func ReadFrontmatter(content []byte) (*FrontmatterDoc, error) {
+	head, body := splitBody(content)
+	if head == nil {
+		return nil, errors.New("no valid frontmatter block found")
+	}
+	fields, err := decodeFields(head)
+	if err != nil {
+		return nil, err
+	}
+	return &FrontmatterDoc{head: bytes.Clone(head), body: bytes.Clone(body), fields: fields}, nil
+}
+
+// decodeFields decodes the frontmatter block head into its mapping node.
+func decodeFields(head []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(frontmatterRE.FindSubmatch(head)[1], &doc); err != nil {
+		return nil, fmt.Errorf("parse frontmatter: %w", err)
+	}
+	if doc.Kind == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode}, nil
+	}
+	if m := doc.Content[0]; m.Kind == yaml.MappingNode {
+		return m, nil
+	}
+	return nil, errors.New("parse frontmatter: not a mapping")
+}
+
+// Keys returns the names of doc's fields in file order.
+func (d *FrontmatterDoc) Keys() []string {
+	keys := make([]string, 0, len(d.fields.Content)/2)
+	for i := 0; i < len(d.fields.Content); i += 2 {
+		keys = append(keys, d.fields.Content[i].Value)
+	}
+	return keys
+}
+
+// Field returns the decoded value of doc's field key, as FrontmatterFields
+// decodes it, and reports whether the field is set.
+func (d *FrontmatterDoc) Field(key string) (any, bool) {
+	for i := 0; i+1 < len(d.fields.Content); i += 2 {
+		if d.fields.Content[i].Value != key {
+			continue
+		}
+		var v any
+		if d.fields.Content[i+1].Decode(&v) != nil {
+			return nil, true
+		}
+		return v, true
+	}
+	return nil, false
+}
+
+// fieldKeyRE matches the field names SetField writes: plain YAML keys that
+// need no quoting.
+var fieldKeyRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// SetField sets doc's field key to the string value, replacing the field's
+// lines where it is set and appending it to the block where it is not.
+// The value is written as a plain scalar when that reads back as the same
+// text, so "drafted" and RFC3339 times stay unquoted, and quoted otherwise.
+// A key that is not a plain name, or a value with control characters, is an
+// error.
+func SetField(doc *FrontmatterDoc, key, value string) error {
+	if !fieldKeyRE.MatchString(key) {
+		return fmt.Errorf("invalid field name %q", key)
+	}
+	if containsControlChars(value) {
+		return fmt.Errorf("field %s contains invalid control character", key)
+	}
+	return doc.setLine(key, key+": "+fieldScalar(value), "")
+}
+
+// WriteFrontmatter returns doc's content: its frontmatter block, with any
+// fields set, followed by its body.
+func WriteFrontmatter(doc *FrontmatterDoc) []byte {
+	return slices.Concat(doc.head, doc.body)
+}
+
+// setLine replaces the lines of field key in doc's block with line,
+// keeping a comment that trailed the field's first line. A field not yet
+// set is inserted at the end of the block when after is empty, and
+// otherwise after the lines of field after, or first when that is unset
+// too.
+func (d *FrontmatterDoc) setLine(key, line, after string) error {
+	head := d.head
+	if loc := fieldLinesRE(key).FindIndex(head); loc != nil {
+		old, _, _ := strings.Cut(string(head[loc[0]:loc[1]]), "\n")
+		if comment := d.trailingComment(key, old); comment != "" {
+			first, rest, multiline := strings.Cut(line, "\n")
+			line = first + comment
+			if multiline {
+				line += "\n" + rest
+			}
+		}
+		head = slices.Concat(head[:loc[0]], []byte(line), head[loc[1]:])
+	} else {
+		at := len(head) - len("---\n")
+		if after != "" {
+			at = len("---\n")
+			if loc := fieldLinesRE(after).FindIndex(head); loc != nil {
+				at = loc[1] + 1
+			}
+		}
+		head = slices.Concat(head[:at], []byte(line+"\n"), head[at:])
+	}
+	fields, err := decodeFields(head)
+	if err != nil {
+		return err
+	}
+	d.head, d.fields = head, fields
+	return nil
+}
+
+// trailingComment returns the comment ending first, the first line of
+// field key, with the spacing before it, or "" when the line has none.
+func (d *FrontmatterDoc) trailingComment(key, first string) string {
+	var comment string
+	for i := 0; i+1 < len(d.fields.Content); i += 2 {
+		if k, v := d.fields.Content[i], d.fields.Content[i+1]; k.Value == key {
+			comment = k.LineComment
+			if v.LineComment != "" && v.Line == k.Line {
+				comment = v.LineComment
+			}
+			break
+		}
+	}
+	trimmed := strings.TrimRight(first, " \t")
+	if comment == "" || !strings.HasSuffix(trimmed, comment) {
+		return ""
+	}
+	before := strings.TrimSuffix(trimmed, comment)
+	return before[len(strings.TrimRight(before, " \t")):] + comment
+}
+
+// fieldLinesRE returns a regexp matching the top-level line of field key
+// with the lines that continue its value: indented ones, and the "- "
+// items of a sequence written at the key's own indent.
+func fieldLinesRE(key string) *regexp.Regexp {
+	return regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(key) + `:.*(?:\n(?:[ \t]|-(?:[ \t]|$)).*)*$`)
+}
+
+// fieldScalar returns value as a YAML scalar that decodes back to value:
+// yamlScalar's form when it does, else a single-quoted one, else (for
+// values with line breaks) the form yaml.v3 marshals.
+func fieldScalar(value string) string {
+	decodes := func(scalar string) bool {
+		var v struct {
+			V string `yaml:"v"`
+		}
+		return yaml.Unmarshal([]byte("v: "+scalar), &v) == nil && v.V == value
+	}
+	if value != "" {
+		if plain := yamlScalar(value); decodes(plain) {
+			return plain
+		}
+	}
+	if quoted := "'" + strings.ReplaceAll(value, "'", "''") + "'"; decodes(quoted) {
+		return quoted
+	}
+	data, _ := yaml.Marshal(map[string]string{"v": value})
+	return strings.TrimSuffix(strings.TrimPrefix(string(data), "v: "), "\n")
+}
@@ -0,0 +1,194 @@
+package node_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	node "github.com/eykd/prosemark-go/internal/node"
+)
+
+const docContent = "---\n" +
+	"id: x\n" +
+	"# working notes\n" +
+	"status: outline # revisit\n" +
+	"tags:\n" +
+	"- draft\n" +
+	"- act1\n" +
+	"synopsis: >\n" +
+	"  A long\n" +
+	"  synopsis.\n" +
+	"updated: 2026-03-01T12:00:00Z\n" +
+	"---\n" +
+	"Body.\n"
+
+func TestReadFrontmatter(t *testing.T) {
+	doc, err := node.ReadFrontmatter([]byte(docContent))
+	if err != nil {
+		t.Fatalf("ReadFrontmatter() error = %v", err)
+	}
+	if got, want := doc.Keys(), []string{"id", "status", "tags", "synopsis", "updated"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %q, want %q", got, want)
+	}
+	fields := []struct {
+		key  string
+		want any
+		ok   bool
+	}{
+		{"status", "outline", true},
+		{"tags", []any{"draft", "act1"}, true},
+		{"synopsis", "A long synopsis.\n", true},
+		{"updated", time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), true},
+		{"missing", nil, false},
+	}
+	for _, f := range fields {
+		if got, ok := doc.Field(f.key); !reflect.DeepEqual(got, f.want) || ok != f.ok {
+			t.Errorf("Field(%q) = %#v, %v; want %#v, %v", f.key, got, ok, f.want, f.ok)
+		}
+	}
+	if got := string(node.WriteFrontmatter(doc)); got != docContent {
+		t.Errorf("WriteFrontmatter() unedited = %q, want the content read", got)
+	}
+
+	for _, content := range []string{"No frontmatter.\n", "---\n- a list\n---\n", "---\nid: [\n---\n"} {
+		if _, err := node.ReadFrontmatter([]byte(content)); err == nil {
+			t.Errorf("ReadFrontmatter(%q): want error", content)
+		}
+	}
+}
+
+func TestReadFrontmatter_EmptyBlock(t *testing.T) {
+	doc, err := node.ReadFrontmatter([]byte("---\n# nothing yet\n---\nBody.\n"))
+	if err != nil {
+		t.Fatalf("ReadFrontmatter() error = %v", err)
+	}
+	if keys := doc.Keys(); len(keys) != 0 {
+		t.Errorf("Keys() = %q, want none", keys)
+	}
+	if err := node.SetField(doc, "status", "draft"); err != nil {
+		t.Fatalf("SetField() error = %v", err)
+	}
+	if got, want := string(node.WriteFrontmatter(doc)), "---\n# nothing yet\nstatus: draft\n---\nBody.\n"; got != want {
+		t.Errorf("WriteFrontmatter() = %q, want %q", got, want)
+	}
+}
+
+func TestFrontmatterDoc_FieldThatCannotBeDecoded(t *testing.T) {
+	doc, err := node.ReadFrontmatter([]byte("---\ncount: !!int many\n---\n"))
+	if err != nil {
+		t.Fatalf("ReadFrontmatter() error = %v", err)
+	}
+	if got, ok := doc.Field("count"); got != nil || !ok {
+		t.Errorf("Field(count) = %#v, %v; want nil, true", got, ok)
+	}
+}
+
+func TestSetField(t *testing.T) {
+	tests := []struct {
+		name, key, value string
+		want             string
+	}{
+		{"replaces keeping comments", "status", "drafted",
+			"---\nid: x\n# working notes\nstatus: drafted # revisit\ntags:\n- draft\n- act1\nsynopsis: >\n  A long\n  synopsis.\nupdated: 2026-03-01T12:00:00Z\n---\nBody.\n"},
+		{"replaces sequence", "tags", "none",
+			"---\nid: x\n# working notes\nstatus: outline # revisit\ntags: none\nsynopsis: >\n  A long\n  synopsis.\nupdated: 2026-03-01T12:00:00Z\n---\nBody.\n"},
+		{"replaces block scalar", "synopsis", "Short.",
+			"---\nid: x\n# working notes\nstatus: outline # revisit\ntags:\n- draft\n- act1\nsynopsis: Short.\nupdated: 2026-03-01T12:00:00Z\n---\nBody.\n"},
+		{"timestamp stays plain", "updated", "2026-04-01T08:00:00Z",
+			"---\nid: x\n# working notes\nstatus: outline # revisit\ntags:\n- draft\n- act1\nsynopsis: >\n  A long\n  synopsis.\nupdated: 2026-04-01T08:00:00Z\n---\nBody.\n"},
+		{"appends with quoting", "pov", "Ann: the keeper",
+			"---\nid: x\n# working notes\nstatus: outline # revisit\ntags:\n- draft\n- act1\nsynopsis: >\n  A long\n  synopsis.\nupdated: 2026-03-01T12:00:00Z\npov: 'Ann: the keeper'\n---\nBody.\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := node.ReadFrontmatter([]byte(docContent))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := node.SetField(doc, tt.key, tt.value); err != nil {
+				t.Fatalf("SetField() error = %v", err)
+			}
+			if got := string(node.WriteFrontmatter(doc)); got != tt.want {
+				t.Errorf("WriteFrontmatter() = %q, want %q", got, tt.want)
+			}
+			if got := node.FrontmatterFields(node.WriteFrontmatter(doc))[tt.key]; got == nil {
+				t.Errorf("field %s not readable after SetField", tt.key)
+			}
+		})
+	}
+}
+
+func TestSetField_KeepsTrailingComment(t *testing.T) {
+	tests := []struct {
+		name, content, value string
+		want                 string
+	}{
+		{"spacing kept", "---\ntitle: \"A\"   # trailing\nid: x\n---\n", "B",
+			"---\ntitle: B   # trailing\nid: x\n---\n"},
+		{"on a block value's key", "---\ntitle: # trailing\n  - a\nid: x\n---\n", "B",
+			"---\ntitle: B # trailing\nid: x\n---\n"},
+		{"multi-line value", "---\ntitle: A # trailing\n---\n", "two\nlines",
+			"---\ntitle: |- # trailing\n    two\n    lines\n---\n"},
+		{"hash inside the value", "---\ntitle: 'A # not a comment'\n---\n", "B",
+			"---\ntitle: B\n---\n"},
+		{"comment on the next line", "---\ntitle: >\n  A # folded\n---\n", "B",
+			"---\ntitle: B\n---\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := node.ReadFrontmatter([]byte(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := node.SetField(doc, "title", tt.value); err != nil {
+				t.Fatalf("SetField() error = %v", err)
+			}
+			got := node.WriteFrontmatter(doc)
+			if string(got) != tt.want {
+				t.Errorf("WriteFrontmatter() = %q, want %q", got, tt.want)
+			}
+			if v, _ := node.FrontmatterFields(got)["title"].(string); v != tt.value {
+				t.Errorf("title reads back as %q, want %q", v, tt.value)
+			}
+		})
+	}
+}
+
+func TestSetField_RoundTripsValues(t *testing.T) {
+	for _, value := range []string{"true", "12", "", "it's", "[draft]", "two\nlines", "# not a comment"} {
+		doc, err := node.ReadFrontmatter([]byte("---\nid: x\n---\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := node.SetField(doc, "note", value); err != nil {
+			t.Fatalf("SetField(%q) error = %v", value, err)
+		}
+		reread, err := node.ReadFrontmatter(node.WriteFrontmatter(doc))
+		if err != nil {
+			t.Fatalf("value %q: reread error = %v", value, err)
+		}
+		if got, _ := reread.Field("note"); fmt.Sprint(got) != value {
+			t.Errorf("value %q read back as %q", value, got)
+		}
+	}
+}
+
+func TestSetField_Errors(t *testing.T) {
+	doc, err := node.ReadFrontmatter([]byte("---\nid: x\n---\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct{ key, value string }{
+		{"bad key", "v"},
+		{"", "v"},
+		{"note", "bell\a"},
+	} {
+		if err := node.SetField(doc, tt.key, tt.value); err == nil {
+			t.Errorf("SetField(%q, %q): want error", tt.key, tt.value)
+		}
+	}
+	if got := string(node.WriteFrontmatter(doc)); got != "---\nid: x\n---\n" {
+		t.Errorf("failed SetField changed content: %q", got)
+	}
+}
@@ -61,6 +61,14 @@ func TestAuditCode_GoDocComments(t *testing.T) {
 			"AUD007",
 			"node file YAML frontmatter block is syntactically unparseable",
 		},
+		{
+			"AUD009",
+			"binder file itself could not be parsed",
+		},
+		{
+			"AUD010",
+			"frontmatter does not match the schema declared for its type",
+		},
 		{
 			"AUDW001",
 			"non-UUID filename linked in binder (backward-compatibility warning for Feature 001 projects)",
@@ -0,0 +1,58 @@
+package node
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Heading is a Markdown heading in a node file.
+type Heading struct {
+	// Text is the heading text, without its markers.
+	Text string
+	// Level is 1 to 6: the number of #s, or 1 for a === underline and 2
+	// for a --- underline.
+	Level int
+	// Line is the 1-based line of the heading text within the whole file.
+	Line int
+}
+
+var (
+	// atxHeadingRE matches an ATX heading line: up to three spaces, one to
+	// six #s, and the text after a space, with any closing #s.
+	atxHeadingRE = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	// setextUnderlineRE matches a setext heading underline.
+	setextUnderlineRE = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	// nonParagraphRE matches a line that cannot be the text of a setext
+	// heading: a list item, block quote, or indented code.
+	nonParagraphRE = regexp.MustCompile(`^(?: {4}|\t| {0,3}(?:[-*+][ \t]|\d+[.)][ \t]|>))`)
+)
+
+// ParseHeadings returns the ATX (# Title) and setext (Title over ===)
+// headings in a node file's content, in document order. Frontmatter and
+// fenced code blocks are skipped, headings with no text are left out, and
+// lines are relative to the whole file.
+func ParseHeadings(content []byte) []Heading {
+	prev, prevLine := "", 0
+	return scanBody(content, func(line string, lineNum int) []Heading {
+		line = strings.TrimSuffix(line, "\r")
+		text, textLine := prev, prevLine
+		prev, prevLine = line, lineNum
+		if m := atxHeadingRE.FindStringSubmatch(line); m != nil {
+			prev = ""
+			if m[2] == "" {
+				return nil
+			}
+			return []Heading{{Text: m[2], Level: len(m[1]), Line: lineNum}}
+		}
+		m := setextUnderlineRE.FindStringSubmatch(line)
+		if m == nil || textLine != lineNum-1 || strings.TrimSpace(text) == "" || nonParagraphRE.MatchString(text) {
+			return nil
+		}
+		prev = ""
+		level := 1
+		if m[1][0] == '-' {
+			level = 2
+		}
+		return []Heading{{Text: strings.TrimSpace(text), Level: level, Line: textLine}}
+	})
+}
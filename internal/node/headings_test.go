@@ -0,0 +1,40 @@
+package node_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+func TestParseHeadings(t *testing.T) {
+	content := "---\n# not a heading: frontmatter\ntitle: One\n---\n" +
+		"# Chapter One\n" +
+		"\n" +
+		"It began.\n" +
+		"## The Storm ##\n" +
+		"```\n# code\n```\n" +
+		"The Calm\n" +
+		"========\n" +
+		"\n" +
+		"Aftermath\n" +
+		"---\n" +
+		"- a list item\n" +
+		"---\n" +
+		"#hashtag\n" +
+		"#\n" +
+		"   ###### Deep\r\n"
+	want := []node.Heading{
+		{Text: "Chapter One", Level: 1, Line: 5},
+		{Text: "The Storm", Level: 2, Line: 8},
+		{Text: "The Calm", Level: 1, Line: 12},
+		{Text: "Aftermath", Level: 2, Line: 15},
+		{Text: "Deep", Level: 6, Line: 21},
+	}
+	if got := node.ParseHeadings([]byte(content)); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseHeadings =\n%+v\nwant\n%+v", got, want)
+	}
+	if got := node.ParseHeadings(nil); got != nil {
+		t.Errorf("ParseHeadings(nil) = %+v, want none", got)
+	}
+}
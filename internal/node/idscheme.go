@@ -0,0 +1,154 @@
+package node
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IDScheme generates and recognizes node identifiers. A node's file is named
+// after its identifier (<id>.md), so the scheme also decides which project
+// files doctor treats as node files.
+type IDScheme interface {
+	// Name is the scheme's id_scheme value in .prosemark.yml.
+	Name() string
+	// Label names the scheme's identifiers in messages ("UUID").
+	Label() string
+	// NewID returns a fresh identifier for a node created at now.
+	NewID(now time.Time) (string, error)
+	// ValidID reports whether id is an identifier of this scheme.
+	ValidID(id string) bool
+	// MatchFilename reports whether filename is a node filename of this
+	// scheme: a valid identifier followed by ".md".
+	MatchFilename(filename string) bool
+}
+
+// Node ID schemes selectable with id_scheme in .prosemark.yml.
+var (
+	// UUIDv7IDs names nodes by lowercase UUIDv7, the default.
+	UUIDv7IDs IDScheme = &patternIDScheme{
+		name:     "uuidv7",
+		label:    "UUID",
+		pattern:  regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[0-9a-f]{4}-[0-9a-f]{12}$`),
+		generate: func(time.Time) (string, error) { return newUUIDv7Impl() },
+	}
+	// ULIDIDs names nodes by ULID in canonical uppercase Crockford base32.
+	ULIDIDs IDScheme = &patternIDScheme{
+		name:     "ulid",
+		label:    "ULID",
+		pattern:  regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`),
+		generate: newULID,
+	}
+	// DateIDs names nodes by creation date and a random suffix, such as
+	// 2026-03-01-9f86d081, so files sort chronologically.
+	DateIDs IDScheme = &patternIDScheme{
+		name:     "date",
+		label:    "date-prefixed ID",
+		pattern:  regexp.MustCompile(`^\d{4}-\d{2}-\d{2}-[0-9a-f]{8}$`),
+		generate: newDateID,
+		check: func(id string) bool {
+			_, err := time.Parse("2006-01-02", id[:10])
+			return err == nil
+		},
+	}
+)
+
+// DefaultIDScheme is the scheme of projects that do not set id_scheme.
+var DefaultIDScheme = UUIDv7IDs
+
+// idSchemes lists the selectable schemes in the order help text shows them.
+var idSchemes = []IDScheme{UUIDv7IDs, ULIDIDs, DateIDs}
+
+// LookupIDScheme returns the scheme named name, or DefaultIDScheme when name
+// is empty. An unknown name is an error; DefaultIDScheme is returned with it
+// so callers that tolerate bad config can carry on.
+func LookupIDScheme(name string) (IDScheme, error) {
+	if name == "" {
+		return DefaultIDScheme, nil
+	}
+	var names []string
+	for _, s := range idSchemes {
+		if s.Name() == name {
+			return s, nil
+		}
+		names = append(names, s.Name())
+	}
+	return DefaultIDScheme, fmt.Errorf("unknown id_scheme %q (want %s)", name, strings.Join(names, ", "))
+}
+
+// patternIDScheme is an IDScheme whose identifiers match a regular
+// expression, optionally refined by check.
+type patternIDScheme struct {
+	name, label string
+	pattern     *regexp.Regexp
+	generate    func(now time.Time) (string, error)
+	check       func(id string) bool
+}
+
+func (s *patternIDScheme) Name() string  { return s.name }
+func (s *patternIDScheme) Label() string { return s.label }
+
+func (s *patternIDScheme) NewID(now time.Time) (string, error) { return s.generate(now) }
+
+func (s *patternIDScheme) ValidID(id string) bool {
+	return s.pattern.MatchString(id) && (s.check == nil || s.check(id))
+}
+
+func (s *patternIDScheme) MatchFilename(filename string) bool {
+	id, ok := strings.CutSuffix(filename, ".md")
+	return ok && s.ValidID(id)
+}
+
+// newUUIDv7Impl returns a new UUIDv7 string.
+// Excluded from coverage because it wraps an external entropy source.
+func newUUIDv7Impl() (string, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+// crockfordBase32 is the ULID alphabet.
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newULID returns a ULID for now: 48 bits of Unix milliseconds followed by 80
+// random bits.
+func newULID(now time.Time) (string, error) {
+	var b [16]byte
+	ms := uint64(now.UnixMilli())
+	for i := 0; i < 6; i++ {
+		b[i] = byte(ms >> (40 - 8*i))
+	}
+	rand.Read(b[6:]) // crypto/rand.Read never fails
+	return encodeULID(b), nil
+}
+
+// encodeULID encodes the 128 bits of b as 26 Crockford base32 digits, the
+// first of which holds only three bits.
+func encodeULID(b [16]byte) string {
+	out := make([]byte, 26)
+	for i := range out {
+		var v byte
+		for j := 0; j < 5; j++ {
+			v <<= 1
+			if bit := i*5 + j - 2; bit >= 0 && b[bit/8]&(0x80>>(bit%8)) != 0 {
+				v |= 1
+			}
+		}
+		out[i] = crockfordBase32[v]
+	}
+	return string(out)
+}
+
+// newDateID returns now's UTC date followed by eight random hex digits.
+func newDateID(now time.Time) (string, error) {
+	var b [4]byte
+	rand.Read(b[:]) // crypto/rand.Read never fails
+	return now.UTC().Format("2006-01-02") + "-" + hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,83 @@
+package node
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLookupIDScheme(t *testing.T) {
+	for name, want := range map[string]IDScheme{"": UUIDv7IDs, "uuidv7": UUIDv7IDs, "ulid": ULIDIDs, "date": DateIDs} {
+		got, err := LookupIDScheme(name)
+		if err != nil || got != want {
+			t.Errorf("LookupIDScheme(%q) = %v, %v; want %s", name, got, err, want.Name())
+		}
+	}
+	got, err := LookupIDScheme("serial")
+	if err == nil || !strings.Contains(err.Error(), "want uuidv7, ulid, date") {
+		t.Errorf("unknown scheme error = %v", err)
+	}
+	if got != DefaultIDScheme {
+		t.Errorf("unknown scheme returned %v, want the default", got)
+	}
+}
+
+func TestIDSchemes_NewIDIsValid(t *testing.T) {
+	now := time.Date(2026, 3, 1, 23, 30, 0, 0, time.FixedZone("X", -3600))
+	for _, s := range idSchemes {
+		id, err := s.NewID(now)
+		if err != nil {
+			t.Fatalf("%s: NewID: %v", s.Name(), err)
+		}
+		if !s.ValidID(id) || !s.MatchFilename(id+".md") {
+			t.Errorf("%s: generated %q is not valid", s.Name(), id)
+		}
+		if s.MatchFilename(id) {
+			t.Errorf("%s: %q matched without .md", s.Name(), id)
+		}
+		if other, _ := s.NewID(now); other == id {
+			t.Errorf("%s: two NewID calls both returned %q", s.Name(), id)
+		}
+	}
+	if id, _ := DateIDs.NewID(now); !strings.HasPrefix(id, "2026-03-02-") {
+		t.Errorf("date ID %q, want the UTC date 2026-03-02", id)
+	}
+}
+
+func TestULID_Encoding(t *testing.T) {
+	// The timestamp of the example ULID 01ARYZ6S41TSV4RRFFQ69G5FAV.
+	id, _ := newULID(time.UnixMilli(1469918176385))
+	if !strings.HasPrefix(id, "01ARYZ6S41") {
+		t.Errorf("ULID = %q, want timestamp prefix 01ARYZ6S41", id)
+	}
+	var max [16]byte
+	for i := range max {
+		max[i] = 0xff
+	}
+	if got := encodeULID(max); got != "7ZZZZZZZZZZZZZZZZZZZZZZZZZ" {
+		t.Errorf("encodeULID(max) = %q", got)
+	}
+}
+
+func TestIDSchemes_ValidID(t *testing.T) {
+	tests := []struct {
+		scheme IDScheme
+		id     string
+		want   bool
+	}{
+		{UUIDv7IDs, "0192f0c1-3e7a-7000-8000-5a4b3c2d1e0f", true},
+		{UUIDv7IDs, "0192F0C1-3E7A-7000-8000-5A4B3C2D1E0F", false},
+		{UUIDv7IDs, "0192f0c1-3e7a-4000-8000-5a4b3c2d1e0f", false},
+		{ULIDIDs, "01ARYZ6S41TSV4RRFFQ69G5FAV", true},
+		{ULIDIDs, "01aryz6s41tsv4rrffq69g5fav", false},
+		{ULIDIDs, "81ARYZ6S41TSV4RRFFQ69G5FAV", false},
+		{DateIDs, "2026-03-01-9f86d081", true},
+		{DateIDs, "2026-13-01-9f86d081", false},
+		{DateIDs, "2026-03-01-chapter1", false},
+	}
+	for _, tt := range tests {
+		if got := tt.scheme.ValidID(tt.id); got != tt.want {
+			t.Errorf("%s.ValidID(%q) = %v, want %v", tt.scheme.Name(), tt.id, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package node
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// Include diagnostics, reported by ExpandIncludes.
+const (
+	// CodeIncludeCycle marks an include that would expand a file inside its
+	// own expansion.
+	CodeIncludeCycle = "PMKW008"
+	// CodeUnreadableInclude marks an include naming a file that cannot be
+	// read.
+	CodeUnreadableInclude = "PMKW009"
+)
+
+// includeAuthor is the review-comment author an include directive would
+// otherwise be taken for.
+const includeAuthor = "pmk-include"
+
+// includeDirectiveRE matches an include directive alone on its line:
+//
+//	<!-- pmk-include: epigraph.md -->
+var includeDirectiveRE = regexp.MustCompile(`(?m)^[ \t]*<!--[ \t]*` + includeAuthor + `:[ \t]*(.*?)[ \t]*-->[ \t]*$`)
+
+// FrontmatterIncludes returns the files a node's frontmatter "includes:" key
+// lists (a list or a single string), in order. Blank entries are dropped.
+func FrontmatterIncludes(content []byte) []string {
+	m := frontmatterRE.FindSubmatch(content)
+	if m == nil {
+		return nil
+	}
+	var fields struct {
+		Includes yaml.Node `yaml:"includes"`
+	}
+	if err := yaml.Unmarshal(m[1], &fields); err != nil {
+		return nil
+	}
+	items := []*yaml.Node{&fields.Includes}
+	if fields.Includes.Kind == yaml.SequenceNode {
+		items = fields.Includes.Content
+	}
+	var includes []string
+	for _, item := range items {
+		if item.Kind != yaml.ScalarNode {
+			continue
+		}
+		if s := strings.TrimSpace(item.Value); s != "" {
+			includes = append(includes, s)
+		}
+	}
+	return includes
+}
+
+// ExpandIncludes returns the body of the node file at target (a
+// project-relative slash path) with its includes expanded: the bodies of the
+// files its frontmatter "includes:" lists go first, and each include
+// directive line is replaced by the body of the file it names. Included
+// paths are relative to the including file, and included files' own
+// includes are expanded in turn. read reads a file by project-relative
+// path.
+//
+// An include that would expand a file within itself (CodeIncludeCycle) or
+// that cannot be read (CodeUnreadableInclude) is dropped with a warning. A
+// locked body that read cannot unlock is an error.
+func ExpandIncludes(target string, content []byte, read func(target string) ([]byte, error)) ([]byte, []binder.Diagnostic, error) {
+	e := includeExpander{read: read}
+	body, err := e.expand([]string{path.Clean(target)}, content)
+	return body, e.diags, err
+}
+
+// includeExpander carries the state of one ExpandIncludes call.
+type includeExpander struct {
+	read  func(target string) ([]byte, error)
+	diags []binder.Diagnostic
+}
+
+// expand returns the expanded body of content, the file at the end of
+// stack; stack holds the chain of files being expanded, outermost first.
+func (e *includeExpander) expand(stack []string, content []byte) ([]byte, error) {
+	file := stack[len(stack)-1]
+	var parts [][]byte
+	for _, ref := range FrontmatterIncludes(content) {
+		included, err := e.include(stack, ref)
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(included)) > 0 {
+			parts = append(parts, included)
+		}
+	}
+
+	body := Body(content)
+	var out []byte
+	last := 0
+	for _, m := range includeDirectiveRE.FindAllSubmatchIndex(body, -1) {
+		out = append(out, body[last:m[0]]...)
+		last = m[1]
+		ref := string(body[m[2]:m[3]])
+		if ref == "" {
+			e.warn(CodeUnreadableInclude, fmt.Sprintf("%s: include directive names no file", file))
+			continue
+		}
+		included, err := e.include(stack, ref)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, included...)
+	}
+	out = append(out, body[last:]...)
+	if len(parts) == 0 {
+		return out, nil
+	}
+	if trimmed := bytes.Trim(out, "\r\n"); len(bytes.TrimSpace(trimmed)) > 0 {
+		parts = append(parts, trimmed)
+	}
+	return bytes.Join(parts, []byte("\n\n")), nil
+}
+
+// include returns the expanded body of the file ref names, relative to the
+// file at the end of stack, with its surrounding blank lines trimmed.
+func (e *includeExpander) include(stack []string, ref string) ([]byte, error) {
+	file := stack[len(stack)-1]
+	target := path.Join(path.Dir(file), ref)
+	if slices.Contains(stack, target) {
+		chain := strings.Join(slices.Concat(stack, []string{target}), " → ")
+		e.warn(CodeIncludeCycle, fmt.Sprintf("%s: include of %s forms a cycle (%s)", file, ref, chain))
+		return nil, nil
+	}
+	content, err := e.read(target)
+	if errors.Is(err, ErrLockedBody) {
+		return nil, fmt.Errorf("%s: %w", target, err)
+	}
+	if err != nil {
+		e.warn(CodeUnreadableInclude, fmt.Sprintf("%s: cannot include %s", file, ref))
+		return nil, nil
+	}
+	body, err := e.expand(slices.Concat(stack, []string{target}), content)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.Trim(body, "\r\n"), nil
+}
+
+// warn records a warning diagnostic.
+func (e *includeExpander) warn(code, message string) {
+	e.diags = append(e.diags, binder.Diagnostic{Severity: "warning", Code: code, Message: message})
+}
@@ -0,0 +1,126 @@
+package node_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// includeFiles is a project of node files read by project-relative path.
+type includeFiles map[string]string
+
+func (f includeFiles) read(target string) ([]byte, error) {
+	if content, ok := f[target]; ok {
+		return []byte(content), nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func TestFrontmatterIncludes(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"---\nincludes: [epigraph.md, ' ', notice.md]\n---\nBody.\n", []string{"epigraph.md", "notice.md"}},
+		{"---\nincludes: epigraph.md\n---\n", []string{"epigraph.md"}},
+		{"---\ntitle: x\n---\n", nil},
+		{"No frontmatter.\n", nil},
+		{"---\nincludes: [\n---\n", nil},
+	}
+	for _, tt := range tests {
+		if got := node.FrontmatterIncludes([]byte(tt.content)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FrontmatterIncludes(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestExpandIncludes(t *testing.T) {
+	files := includeFiles{
+		"shared/epigraph.md": "---\ntitle: Epigraph\n---\n\n> All is flux.\n\n",
+		"shared/notice.md":   "<!-- pmk-include: epigraph.md -->\n\nAll rights reserved.\n",
+		"loop-a.md":          "A.\n<!-- pmk-include: loop-b.md -->\n",
+		"loop-b.md":          "B.\n<!-- pmk-include: loop-a.md -->\n",
+	}
+	tests := []struct {
+		name      string
+		content   string
+		want      string
+		wantCodes []string
+	}{
+		{
+			name:    "directive",
+			content: "---\ntitle: One\n---\nIt began.\n  <!-- pmk-include: shared/epigraph.md -->\nIt went on.\n",
+			want:    "It began.\n> All is flux.\nIt went on.\n",
+		},
+		{
+			name:    "frontmatter list, nested",
+			content: "---\nincludes: [shared/notice.md]\n---\nIt began.\n",
+			want:    "> All is flux.\n\nAll rights reserved.\n\nIt began.",
+		},
+		{
+			name:      "cycle",
+			content:   "Start.\n<!-- pmk-include: loop-a.md -->\n",
+			want:      "Start.\nA.\nB.\n",
+			wantCodes: []string{node.CodeIncludeCycle},
+		},
+		{
+			name:      "missing and empty",
+			content:   "<!-- pmk-include: gone.md -->\n<!-- pmk-include: -->\nText.\n",
+			want:      "\n\nText.\n",
+			wantCodes: []string{node.CodeUnreadableInclude, node.CodeUnreadableInclude},
+		},
+		{
+			name:    "no includes",
+			content: "Inline <!-- pmk-include: shared/epigraph.md --> is not a directive.\n",
+			want:    "Inline <!-- pmk-include: shared/epigraph.md --> is not a directive.\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diags, err := node.ExpandIncludes("start.md", []byte(tt.content), files.read)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ExpandIncludes = %q, want %q", got, tt.want)
+			}
+			var codes []string
+			for _, d := range diags {
+				codes = append(codes, d.Code)
+			}
+			if !reflect.DeepEqual(codes, tt.wantCodes) {
+				t.Errorf("diagnostic codes = %v, want %v (%v)", codes, tt.wantCodes, diags)
+			}
+		})
+	}
+}
+
+func TestExpandIncludes_LockedInclude(t *testing.T) {
+	read := func(target string) ([]byte, error) {
+		if target == "outer.md" {
+			return []byte("<!-- pmk-include: secret.md -->\n"), nil
+		}
+		return nil, node.ErrLockedBody
+	}
+	for _, content := range []string{
+		"<!-- pmk-include: secret.md -->\n",
+		"---\nincludes: secret.md\n---\nBody.\n",
+		"<!-- pmk-include: outer.md -->\n",
+	} {
+		_, _, err := node.ExpandIncludes("a.md", []byte(content), read)
+		if !errors.Is(err, node.ErrLockedBody) || !strings.Contains(err.Error(), "secret.md") {
+			t.Errorf("ExpandIncludes(%q) err = %v, want ErrLockedBody naming secret.md", content, err)
+		}
+	}
+}
+
+func TestParseComments_SkipsIncludeDirectives(t *testing.T) {
+	content := "<!-- pmk-include: epigraph.md -->\n<!-- alice: keep -->\n"
+	if got := node.ParseComments([]byte(content)); len(got) != 1 || got[0].Author != "alice" {
+		t.Errorf("ParseComments = %+v, want only alice's comment", got)
+	}
+}
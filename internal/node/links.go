@@ -0,0 +1,156 @@
+package node
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// bodyInlineLinkRE matches an inline link or image; group 1 is the target,
+	// with optional angle brackets and title excluded.
+	bodyInlineLinkRE = regexp.MustCompile(`!?\[[^\]]*\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+	// bodyAutolinkRE matches an angle-bracket autolink; group 1 is the URL.
+	bodyAutolinkRE = regexp.MustCompile(`<(https?://[^>\s]+)>`)
+	// bodyBareURLRE matches a bare http(s) URL in prose.
+	bodyBareURLRE = regexp.MustCompile(`https?://[^\s<>()\[\]]+`)
+	// inlineCodeRE matches an inline code span, whose contents are not links.
+	inlineCodeRE = regexp.MustCompile("`+[^`]*`+")
+	// urlSchemeRE matches a URI scheme prefix such as "https:" or "mailto:".
+	urlSchemeRE = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:`)
+	// bodyWikilinkRE matches a wikilink or wikilink embed; group 1 is the
+	// linked name, without any #heading or |display text.
+	bodyWikilinkRE = regexp.MustCompile(`!?\[\[([^\]|#]*)(?:#[^\]|]*)?(?:\|[^\]]*)?\]\]`)
+)
+
+// BodyLink is a link found in a node body.
+type BodyLink struct {
+	// Target is the link destination as written.
+	Target string
+	// Line is the 1-based line of the link within the whole file.
+	Line int
+	// Column is the 1-based byte column where the link starts.
+	Column int
+}
+
+// External reports whether the link targets an http or https URL.
+func (l BodyLink) External() bool {
+	lower := strings.ToLower(l.Target)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// Local reports whether the link targets a file in the project: a relative
+// path with no URI scheme that is not a bare #fragment.
+func (l BodyLink) Local() bool {
+	return !urlSchemeRE.MatchString(l.Target) && !strings.HasPrefix(l.Target, "#") && !strings.HasPrefix(l.Target, "/")
+}
+
+// LocalPath returns a Local link's target with any #fragment or ?query
+// stripped and percent-decoding applied. Undecodable targets are returned as
+// written.
+func (l BodyLink) LocalPath() string {
+	target := l.Target
+	if i := strings.IndexAny(target, "#?"); i >= 0 {
+		target = target[:i]
+	}
+	if decoded, err := url.PathUnescape(target); err == nil {
+		return decoded
+	}
+	return target
+}
+
+// ParseBodyLinks returns the inline links, autolinks, and bare http(s) URLs
+// in a node file's content, in document order. Frontmatter, fenced code
+// blocks, and inline code spans are skipped; locations are relative to the
+// whole file.
+func ParseBodyLinks(content []byte) []BodyLink {
+	return scanBody(content, lineLinks)
+}
+
+// ParseBodyWikilinks returns the wikilinks in a node file's content, in
+// document order, skipping what ParseBodyLinks skips. Each Target is the
+// linked name as written, without any #heading or |display text.
+func ParseBodyWikilinks(content []byte) []BodyLink {
+	return scanBody(content, func(line string, lineNum int) []BodyLink {
+		line = blankCodeSpans(line)
+		var links []BodyLink
+		for _, m := range bodyWikilinkRE.FindAllStringSubmatchIndex(line, -1) {
+			if name := strings.TrimSpace(line[m[2]:m[3]]); name != "" {
+				links = append(links, BodyLink{Target: name, Line: lineNum, Column: m[0] + 1})
+			}
+		}
+		return links
+	})
+}
+
+// scanBody applies lineFn to each body line of content outside fenced code
+// blocks and collects what it returns.
+func scanBody[T any](content []byte, lineFn func(line string, lineNum int) []T) []T {
+	skip := 0
+	if loc := frontmatterRE.FindIndex(content); loc != nil {
+		skip = strings.Count(string(content[:loc[1]]), "\n")
+	}
+
+	var found []T
+	fence := ""
+	for i, line := range strings.Split(string(content), "\n") {
+		if i < skip {
+			continue
+		}
+		trimmed := strings.TrimLeft(line, " ")
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		found = append(found, lineFn(line, i+1)...)
+	}
+	return found
+}
+
+// lineLinks returns the links on one line of a node body.
+func lineLinks(line string, lineNum int) []BodyLink {
+	line = blankCodeSpans(line)
+
+	var links []BodyLink
+	var spans [][]int
+	for _, re := range []*regexp.Regexp{bodyInlineLinkRE, bodyAutolinkRE} {
+		for _, m := range re.FindAllStringSubmatchIndex(line, -1) {
+			spans = append(spans, m[:2])
+			links = append(links, BodyLink{Target: line[m[2]:m[3]], Line: lineNum, Column: m[0] + 1})
+		}
+	}
+	for _, m := range bodyBareURLRE.FindAllStringIndex(line, -1) {
+		if insideSpan(m[0], spans) {
+			continue
+		}
+		url := strings.TrimRight(line[m[0]:m[1]], ".,;:!?'\"*_")
+		links = append(links, BodyLink{Target: url, Line: lineNum, Column: m[0] + 1})
+	}
+	sort.SliceStable(links, func(i, j int) bool { return links[i].Column < links[j].Column })
+	return links
+}
+
+// blankCodeSpans blanks out the code spans in line so their contents never
+// match, keeping offsets.
+func blankCodeSpans(line string) string {
+	return inlineCodeRE.ReplaceAllStringFunc(line, func(s string) string {
+		return strings.Repeat(" ", len(s))
+	})
+}
+
+// insideSpan reports whether offset falls within any of spans.
+func insideSpan(offset int, spans [][]int) bool {
+	for _, s := range spans {
+		if offset >= s[0] && offset < s[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package node_test
+
+import (
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+const linksNode = "---\nid: x\ntitle: '[not](a-link.md)'\n---\n" +
+	"See [notes](notes.md \"Notes\") and ![map](<img/map.png>).\n" +
+	"Visit https://example.com/a_(b), or <https://auto.test/x>.\n" +
+	"Code `[no](code.md)` and [inline https://in.test/](https://out.test/).\n" +
+	"```go\n[fenced](fenced.md)\n```\n" +
+	"  ~~~\nhttps://tilde.test/\n  ~~~\n" +
+	"Trailing http://end.test/path."
+
+func TestParseBodyLinks(t *testing.T) {
+	got := node.ParseBodyLinks([]byte(linksNode))
+
+	want := []node.BodyLink{
+		{Target: "notes.md", Line: 5, Column: 5},
+		{Target: "img/map.png", Line: 5, Column: 35},
+		{Target: "https://example.com/a", Line: 6, Column: 7},
+		{Target: "https://auto.test/x", Line: 6, Column: 37},
+		{Target: "https://out.test/", Line: 7, Column: 26},
+		{Target: "http://end.test/path", Line: 14, Column: 10},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d links, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i] != w {
+			t.Errorf("link %d = %+v, want %+v", i, got[i], w)
+		}
+	}
+}
+
+func TestParseBodyWikilinks(t *testing.T) {
+	content := "---\ntitle: '[[not]]'\n---\nSee [[Chapter One#Scene|the start]] and ![[map]].\n" +
+		"`[[code]]` [[ ]]\n```\n[[fenced]]\n```\n"
+	got := node.ParseBodyWikilinks([]byte(content))
+
+	want := []node.BodyLink{
+		{Target: "Chapter One", Line: 4, Column: 5},
+		{Target: "map", Line: 4, Column: 41},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d links, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i] != w {
+			t.Errorf("link %d = %+v, want %+v", i, got[i], w)
+		}
+	}
+}
+
+func TestBodyLink_LocalPath(t *testing.T) {
+	for target, want := range map[string]string{
+		"notes.md":            "notes.md",
+		"part/my%20ch.md#top": "part/my ch.md",
+		"a.md?x=1":            "a.md",
+		"bad%zz.md":           "bad%zz.md",
+	} {
+		if got := (node.BodyLink{Target: target}).LocalPath(); got != want {
+			t.Errorf("LocalPath(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
+
+func TestParseBodyLinks_NoFrontmatter(t *testing.T) {
+	got := node.ParseBodyLinks([]byte("[a](a.md)\n"))
+	if len(got) != 1 || got[0].Line != 1 {
+		t.Errorf("got %+v, want one link on line 1", got)
+	}
+}
+
+func TestBodyLink_Kind(t *testing.T) {
+	tests := []struct {
+		target          string
+		external, local bool
+	}{
+		{"HTTPS://x.test/", true, false},
+		{"http://x.test/", true, false},
+		{"notes.md", false, true},
+		{"../up.md#part", false, true},
+		{"mailto:a@b.c", false, false},
+		{"#top", false, false},
+		{"/abs/path.md", false, false},
+	}
+	for _, tt := range tests {
+		l := node.BodyLink{Target: tt.target}
+		if l.External() != tt.external || l.Local() != tt.local {
+			t.Errorf("%q: External=%v Local=%v, want %v %v", tt.target, l.External(), l.Local(), tt.external, tt.local)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package node
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestNewAESGCM_KeySize(t *testing.T) {
+	for _, size := range []int{16, 24, 32} {
+		if _, err := newAESGCM(make([]byte, size)); err != nil {
+			t.Errorf("newAESGCM(%d-byte key): %v", size, err)
+		}
+	}
+	if _, err := newAESGCM(make([]byte, 31)); err == nil || !strings.Contains(err.Error(), "invalid key size") {
+		t.Errorf("newAESGCM(31-byte key) err = %v, want invalid key size", err)
+	}
+}
+
+func TestBodyKey_RandomSourceFails(t *testing.T) {
+	errEntropy := errors.New("no entropy")
+	orig := randReader
+	randReader = iotest.ErrReader(errEntropy)
+	t.Cleanup(func() { randReader = orig })
+
+	if _, err := NewBodyKey(); !errors.Is(err, errEntropy) || !strings.Contains(err.Error(), "generating key") {
+		t.Errorf("NewBodyKey err = %v, want wrapped entropy error", err)
+	}
+	if _, err := LockBody([]byte("body\n"), BodyKey{}); !errors.Is(err, errEntropy) || !strings.Contains(err.Error(), "generating nonce") {
+		t.Errorf("LockBody err = %v, want wrapped entropy error", err)
+	}
+}
+
+func TestLockBody_CipherFails(t *testing.T) {
+	var key BodyKey
+	locked, err := LockBody([]byte("---\nid: x\n---\nbody\n"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errCipher := errors.New("no cipher")
+	orig := newAEAD
+	newAEAD = func([]byte) (cipher.AEAD, error) { return nil, errCipher }
+	t.Cleanup(func() { newAEAD = orig })
+
+	if _, err := LockBody([]byte("body\n"), key); !errors.Is(err, errCipher) {
+		t.Errorf("LockBody err = %v, want cipher error", err)
+	}
+	if _, err := UnlockBody(locked, key); !errors.Is(err, errCipher) {
+		t.Errorf("UnlockBody err = %v, want cipher error", err)
+	}
+	if got, err := LockBody(locked, key); err != nil || !bytes.Equal(got, locked) {
+		t.Errorf("LockBody(locked) = %q, %v; want it unchanged", got, err)
+	}
+}
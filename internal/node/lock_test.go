@@ -0,0 +1,120 @@
+package node_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// newKey returns a new random key, failing the test on error.
+func newKey(t *testing.T) node.BodyKey {
+	t.Helper()
+	key, err := node.NewBodyKey()
+	if err != nil {
+		t.Fatalf("NewBodyKey: %v", err)
+	}
+	return key
+}
+
+// lockBody locks content under key, failing the test on error.
+func lockBody(t *testing.T, content string, key node.BodyKey) []byte {
+	t.Helper()
+	locked, err := node.LockBody([]byte(content), key)
+	if err != nil {
+		t.Fatalf("LockBody: %v", err)
+	}
+	return locked
+}
+
+func TestLockBody_RoundTrip(t *testing.T) {
+	key := newKey(t)
+	tests := map[string]struct{ head, body string }{
+		"with frontmatter": {"---\nid: x\ntitle: Secret\n---\n", strings.Repeat("The source said so. ", 20) + "\n"},
+		"no frontmatter":   {"", "Just a body.\n"},
+		"empty body":       {"---\nid: x\n---\n", ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			plain := tt.head + tt.body
+			locked := lockBody(t, plain, key)
+			if !node.BodyLocked(locked) || node.BodyLocked([]byte(plain)) {
+				t.Fatalf("BodyLocked mismatch; locked = %q", locked)
+			}
+			if !bytes.HasPrefix(locked, []byte(tt.head+"<!-- pmk:locked v1 key="+key.ID()+" -->\n")) {
+				t.Errorf("locked = %q, want frontmatter then the locked header", locked)
+			}
+			if bytes.Contains(locked, []byte("source said")) {
+				t.Error("locked content contains plaintext")
+			}
+			for _, line := range strings.Split(string(locked), "\n") {
+				if len(line) > 76 && !strings.HasPrefix(line, "<!--") {
+					t.Errorf("line longer than 76 columns: %q", line)
+				}
+			}
+			if again := lockBody(t, string(locked), key); !bytes.Equal(again, locked) {
+				t.Error("locking a locked body changed it")
+			}
+			got, err := node.UnlockBody(locked, key)
+			if err != nil || string(got) != plain {
+				t.Errorf("UnlockBody = %q, %v; want %q", got, err, plain)
+			}
+		})
+	}
+}
+
+func TestUnlockBody_Errors(t *testing.T) {
+	key := newKey(t)
+	locked := string(lockBody(t, "---\nid: x\n---\nbody\n", key))
+	header := locked[:strings.Index(locked, "-->\n")+4]
+
+	if got, err := node.UnlockBody([]byte("plain\n"), key); err != nil || string(got) != "plain\n" {
+		t.Errorf("UnlockBody(plain) = %q, %v", got, err)
+	}
+
+	other := newKey(t)
+	_, err := node.UnlockBody([]byte(locked), other)
+	if !errors.Is(err, node.ErrLockedBody) || !strings.Contains(err.Error(), "with key "+key.ID()+", but the key file holds key "+other.ID()) {
+		t.Errorf("wrong key: err = %v", err)
+	}
+
+	// Flip a bit of the sealed bytes themselves, not of the base64 text: a
+	// changed padding bit can decode to the same bytes.
+	sealed, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(locked[len(header):], "\n", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed[len(sealed)-1] ^= 1
+	tampered := header + base64.StdEncoding.EncodeToString(sealed) + "\n"
+	for name, content := range map[string]string{
+		"bad base64": header + "!!!\n",
+		"too short":  header + "AAAA\n",
+		"tampered":   tampered,
+	} {
+		if _, err := node.UnlockBody([]byte(content), key); !errors.Is(err, node.ErrLockedBody) || !strings.Contains(err.Error(), "damaged") {
+			t.Errorf("%s: err = %v, want damaged-ciphertext error", name, err)
+		}
+	}
+}
+
+func TestBodyKey_Encoding(t *testing.T) {
+	key := newKey(t)
+	if key == newKey(t) {
+		t.Error("NewBodyKey returned the same key twice")
+	}
+	parsed, err := node.ParseBodyKey(append([]byte("  "), key.Encode()...))
+	if err != nil || parsed != key {
+		t.Errorf("ParseBodyKey(Encode()) = %v, %v", parsed, err)
+	}
+	if len(key.ID()) != 16 || key.ID() == newKey(t).ID() {
+		t.Errorf("ID = %q, want a distinct 16-digit fingerprint", key.ID())
+	}
+	for _, bad := range []string{"", "not base64!", "c2hvcnQ="} {
+		if _, err := node.ParseBodyKey([]byte(bad)); err == nil {
+			t.Errorf("ParseBodyKey(%q) succeeded", bad)
+		}
+	}
+}
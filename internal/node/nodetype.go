@@ -0,0 +1,81 @@
+package node
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Built-in node types. Projects may declare further types, or override the
+// label, icon, and template of these, in the "types" section of .prosemark.yml.
+const (
+	TypeChapter   = "chapter"
+	TypeScene     = "scene"
+	TypeNote      = "note"
+	TypeCharacter = "character"
+	TypePlace     = "place"
+	TypeReference = "reference"
+)
+
+// builtinNodeTypes holds the default presentation of the built-in types.
+var builtinNodeTypes = FrontmatterSchema{
+	TypeChapter:   {Label: "Chapter", Icon: "§"},
+	TypeScene:     {Label: "Scene", Icon: "¶"},
+	TypeNote:      {Label: "Note", Icon: "✎"},
+	TypeCharacter: {Label: "Character", Icon: "@"},
+	TypePlace:     {Label: "Place", Icon: "⌂"},
+	TypeReference: {Label: "Reference", Icon: "↗"},
+}
+
+// NodeType returns the definition of the named node type: the project's
+// declaration merged over the built-in defaults, so a project that only adds
+// a schema to "scene" keeps its label and icon. The bool reports whether the
+// type is built in or declared by the project.
+func (s FrontmatterSchema) NodeType(name string) (TypeSchema, bool) {
+	builtin, isBuiltin := builtinNodeTypes[name]
+	declared, isDeclared := s[name]
+	if !isDeclared {
+		return builtin, isBuiltin
+	}
+	if declared.Label == "" {
+		declared.Label = builtin.Label
+	}
+	if declared.Icon == "" {
+		declared.Icon = builtin.Icon
+	}
+	return declared, true
+}
+
+// NodeTypeNames returns the built-in and project-declared type names in
+// lexical order.
+func (s FrontmatterSchema) NodeTypeNames() []string {
+	all := make(map[string]bool, len(builtinNodeTypes)+len(s))
+	for name := range builtinNodeTypes {
+		all[name] = true
+	}
+	for name := range s {
+		all[name] = true
+	}
+	return sortedKeys(all)
+}
+
+// NewNodeContent returns the content of a new node file: fm's frontmatter
+// followed by the template body of fm.Type, if any. It fails when fm.Type is
+// set but is neither built in nor declared in s.
+func (s FrontmatterSchema) NewNodeContent(fm Frontmatter) ([]byte, error) {
+	content := SerializeFrontmatter(fm)
+	if fm.Type == "" {
+		return content, nil
+	}
+	ts, ok := s.NodeType(fm.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown node type %q (known types: %s)", fm.Type, strings.Join(s.NodeTypeNames(), ", "))
+	}
+	if ts.Template == "" {
+		return content, nil
+	}
+	body := ts.Template
+	if !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	return append(content, body...), nil
+}
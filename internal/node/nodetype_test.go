@@ -0,0 +1,88 @@
+package node_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+func TestFrontmatterSchema_NodeType(t *testing.T) {
+	schema, err := node.ParseFrontmatterSchema([]byte("types:\n" +
+		"  scene:\n    required: {pov: string}\n    template: \"## Beats\"\n" +
+		"  place:\n    label: Location\n    icon: \"#\"\n" +
+		"  letter:\n    label: Letter\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		wantLabel string
+		wantIcon  string
+		wantOK    bool
+	}{
+		{node.TypeChapter, "Chapter", "§", true},
+		{node.TypeScene, "Scene", "¶", true},
+		{node.TypePlace, "Location", "#", true},
+		{"letter", "Letter", "", true},
+		{"spaceship", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, ok := schema.NodeType(tt.name)
+			if ok != tt.wantOK || ts.Label != tt.wantLabel || ts.Icon != tt.wantIcon {
+				t.Errorf("NodeType(%q) = {Label:%q Icon:%q}, %v; want {%q %q}, %v",
+					tt.name, ts.Label, ts.Icon, ok, tt.wantLabel, tt.wantIcon, tt.wantOK)
+			}
+		})
+	}
+	if ts, _ := schema.NodeType(node.TypeScene); ts.Required["pov"] != node.FieldString {
+		t.Error("declared schema must be kept when merging built-in defaults")
+	}
+
+	if got := strings.Join(schema.NodeTypeNames(), ","); got != "chapter,character,letter,note,place,reference,scene" {
+		t.Errorf("NodeTypeNames() = %s", got)
+	}
+	if _, ok := node.FrontmatterSchema(nil).NodeType(node.TypeNote); !ok {
+		t.Error("built-in types must be known without a project schema")
+	}
+}
+
+func TestFrontmatterSchema_NewNodeContent(t *testing.T) {
+	schema := node.FrontmatterSchema{
+		"scene":     {Template: "## Beats\n"},
+		"character": {Template: "## Background"},
+	}
+	base := node.Frontmatter{ID: "a", Title: "T", Created: "c", Updated: "u"}
+	tests := []struct {
+		name     string
+		nodeType string
+		wantBody string
+		wantErr  string
+	}{
+		{"untyped", "", "", ""},
+		{"template", "scene", "## Beats\n", ""},
+		{"template gains trailing newline", "character", "## Background\n", ""},
+		{"built-in without template", "note", "", ""},
+		{"unknown type", "spaceship", "", `unknown node type "spaceship" (known types: chapter, character, note, place, reference, scene)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm := base
+			fm.Type = tt.nodeType
+			got, err := schema.NewNodeContent(fm)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := string(node.SerializeFrontmatter(fm)) + tt.wantBody; string(got) != want {
+				t.Errorf("content = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package node
+
+// Prose returns a node file's body without the text a reader never sees:
+// frontmatter, review comments, and HTML comments are removed.
+func Prose(content []byte) []byte {
+	body := commentRE.ReplaceAll(Body(content), nil)
+	return raHTMLCommentRE.ReplaceAll(body, nil)
+}
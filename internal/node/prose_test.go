@@ -0,0 +1,19 @@
+package node
+
+import "testing"
+
+func TestProse(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"---\ntitle: Many words in the title\n---\n\n# Chapter One\n", "\n# Chapter One\n"},
+		{"Keep %% editor: cut this %% going <!-- note:\nand this --> on.\n", "Keep  going  on.\n"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := string(Prose([]byte(tt.content))); got != tt.want {
+			t.Errorf("Prose(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
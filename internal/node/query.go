@@ -0,0 +1,161 @@
+package node
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FrontmatterPredicate is one condition of a node query on frontmatter.
+type FrontmatterPredicate struct {
+	Key string
+	// Op is "exists", "absent", or a comparison: = != < <= > >=.
+	Op    string
+	Value string
+}
+
+var (
+	// predicateKeyRE matches a frontmatter key in a query.
+	predicateKeyRE = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	// predicateCompareRE splits "key<op>value"; longer operators come first.
+	predicateCompareRE = regexp.MustCompile(`^([A-Za-z0-9_.-]+)\s*(!=|>=|<=|=|<|>)\s*(.*)$`)
+)
+
+// ParseFrontmatterPredicate parses a query expression: "key" (present),
+// "!key" (absent), or "key<op>value" with op one of = != < <= > >=.
+func ParseFrontmatterPredicate(expr string) (FrontmatterPredicate, error) {
+	expr = strings.TrimSpace(expr)
+	switch {
+	case predicateKeyRE.MatchString(expr):
+		return FrontmatterPredicate{Key: expr, Op: "exists"}, nil
+	case strings.HasPrefix(expr, "!") && predicateKeyRE.MatchString(expr[1:]):
+		return FrontmatterPredicate{Key: expr[1:], Op: "absent"}, nil
+	}
+	if m := predicateCompareRE.FindStringSubmatch(expr); m != nil {
+		return FrontmatterPredicate{Key: m[1], Op: m[2], Value: strings.TrimSpace(m[3])}, nil
+	}
+	return FrontmatterPredicate{}, fmt.Errorf("invalid frontmatter query %q: want key, !key, or key<op>value with op one of = != < <= > >=", expr)
+}
+
+// Match reports whether fields satisfy p. Values compare as dates when both
+// sides are dates, as numbers when both are numbers, and as strings
+// otherwise. A list matches when any element does. A missing or null field
+// satisfies only "absent" and "!=".
+func (p FrontmatterPredicate) Match(fields map[string]interface{}) bool {
+	v, present := fields[p.Key]
+	present = present && v != nil
+	switch {
+	case p.Op == "exists":
+		return present
+	case p.Op == "absent":
+		return !present
+	case !present:
+		return p.Op == "!="
+	}
+	if list, ok := v.([]interface{}); ok {
+		if p.Op == "!=" {
+			for _, item := range list {
+				if !p.matchScalar(item) {
+					return false
+				}
+			}
+			return true
+		}
+		for _, item := range list {
+			if p.matchScalar(item) {
+				return true
+			}
+		}
+		return false
+	}
+	return p.matchScalar(v)
+}
+
+// matchScalar applies p's comparison to one decoded YAML value.
+func (p FrontmatterPredicate) matchScalar(v interface{}) bool {
+	c := compareQueryValue(v, p.Value)
+	switch p.Op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default: // ">="
+		return c >= 0
+	}
+}
+
+// compareQueryValue compares a decoded YAML value with a query operand,
+// returning -1, 0, or +1.
+func compareQueryValue(v interface{}, want string) int {
+	got := fmt.Sprint(v)
+	if t, ok := v.(time.Time); ok {
+		got = t.Format(time.RFC3339)
+	}
+	if a, ok := parseQueryDate(got); ok {
+		if b, ok := parseQueryDate(want); ok {
+			return a.Compare(b)
+		}
+	}
+	if a, err := strconv.ParseFloat(got, 64); err == nil {
+		if b, err := strconv.ParseFloat(want, 64); err == nil {
+			return cmp.Compare(a, b)
+		}
+	}
+	return strings.Compare(got, want)
+}
+
+// parseQueryDate parses a YYYY-MM-DD date or an RFC3339 timestamp.
+func parseQueryDate(s string) (time.Time, bool) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, true
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	return t, err == nil
+}
+
+// FrontmatterFields decodes a node file's frontmatter into a generic map,
+// including keys the Frontmatter struct does not know. It returns nil when
+// the file has no frontmatter or the YAML does not decode to a mapping.
+func FrontmatterFields(content []byte) map[string]interface{} {
+	m := frontmatterRE.FindSubmatch(content)
+	if m == nil {
+		return nil
+	}
+	var fields map[string]interface{}
+	if yaml.Unmarshal(m[1], &fields) != nil {
+		return nil
+	}
+	return fields
+}
+
+// BodyMatch is a body line matched by a node query.
+type BodyMatch struct {
+	// Line is the 1-based line number within the whole file.
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// MatchBodyLines returns the body lines of a node file that re matches.
+// Frontmatter lines are never matched.
+func MatchBodyLines(content []byte, re *regexp.Regexp) []BodyMatch {
+	head, body := splitBody(content)
+	offset := strings.Count(string(head), "\n")
+	var matches []BodyMatch
+	for i, line := range strings.Split(strings.TrimSuffix(string(body), "\n"), "\n") {
+		if re.MatchString(line) {
+			matches = append(matches, BodyMatch{Line: offset + i + 1, Text: line})
+		}
+	}
+	return matches
+}
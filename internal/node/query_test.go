@@ -0,0 +1,95 @@
+package node_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+const queryNode = "---\n" +
+	"id: x\n" +
+	"status: draft\n" +
+	"words: 1200\n" +
+	"updated: 2026-02-01T10:00:00Z\n" +
+	"due: 2026-03-15\n" +
+	"pov: \"2026-ish\"\n" +
+	"tags: [war, love]\n" +
+	"empty: ~\n" +
+	"---\n" +
+	"First line.\nTODO: fix the ending\n\nlast todo\n"
+
+func TestFrontmatterPredicate_Match(t *testing.T) {
+	fields := node.FrontmatterFields([]byte(queryNode))
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"status", true},
+		{"synopsis", false},
+		{"empty", false},
+		{"!synopsis", true},
+		{"!status", false},
+		{"status=draft", true},
+		{"status = draft", true},
+		{"status!=draft", false},
+		{"status!=final", true},
+		{"synopsis!=x", true},
+		{"synopsis=x", false},
+		{"words>1000", true},
+		{"words>=1200", true},
+		{"words<1000", false},
+		{"words<=1200", true},
+		{"words<900.5", false},
+		{"updated>2026-01-01", true},
+		{"updated<2026-01-01", false},
+		{"updated>=2026-02-01T10:00:00Z", true},
+		{"due<2026-04-01", true},
+		{"due=2026-03-15", true},
+		{"pov<2027", true},
+		{"tags=love", true},
+		{"tags=peace", false},
+		{"tags!=peace", true},
+		{"tags!=war", false},
+		{"status>c", true},
+	}
+	for _, tt := range tests {
+		p, err := node.ParseFrontmatterPredicate(tt.expr)
+		if err != nil {
+			t.Errorf("%q: %v", tt.expr, err)
+			continue
+		}
+		if got := p.Match(fields); got != tt.want {
+			t.Errorf("%q: Match = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseFrontmatterPredicate_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "!", "=draft", "bad key=1", "!=x"} {
+		if _, err := node.ParseFrontmatterPredicate(expr); err == nil {
+			t.Errorf("%q: expected error", expr)
+		}
+	}
+}
+
+func TestFrontmatterFields_None(t *testing.T) {
+	for _, content := range []string{"no frontmatter\n", "---\n- a list\n---\nbody\n"} {
+		if got := node.FrontmatterFields([]byte(content)); got != nil {
+			t.Errorf("%q: got %v, want nil", content, got)
+		}
+	}
+}
+
+func TestMatchBodyLines(t *testing.T) {
+	got := node.MatchBodyLines([]byte(queryNode), regexp.MustCompile(`(?i)todo|^$|status`))
+	want := []node.BodyMatch{{Line: 12, Text: "TODO: fix the ending"}, {Line: 13, Text: ""}, {Line: 14, Text: "last todo"}}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("match %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
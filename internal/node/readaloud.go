@@ -0,0 +1,209 @@
+package node
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Read-aloud prose cleanup patterns. Markdown markup is reduced to the words a
+// listener should hear: link text is kept, URLs, images, and emphasis markers
+// are dropped.
+var (
+	raHTMLCommentRE = regexp.MustCompile(`(?s)<!--.*?-->`)
+	raImageRE       = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	raLinkRE        = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	raWikilinkRE    = regexp.MustCompile(`\[\[(?:[^\]|]*\|)?([^\]]*)\]\]`)
+	raEmphasisRE    = regexp.MustCompile("[*_`~]+")
+	raHeadingRE     = regexp.MustCompile(`^#{1,6}[ \t]+`)
+	raSceneBreakRE  = regexp.MustCompile(`^(?:[-*_#~][ \t]*){3,}$|^#$`)
+	raFenceRE       = regexp.MustCompile("^(```|~~~)")
+)
+
+// raAbbreviations are words ending in "." that do not end a sentence.
+var raAbbreviations = map[string]bool{
+	"mr.": true, "mrs.": true, "ms.": true, "dr.": true, "st.": true,
+	"jr.": true, "sr.": true, "vs.": true, "etc.": true, "e.g.": true, "i.e.": true,
+}
+
+// ReadAloudSection is one binder node's prose, prepared for read-aloud export.
+type ReadAloudSection struct {
+	// Title is the node's display title, announced as a scene marker.
+	Title string
+	// Paragraphs holds the node's paragraphs, each split into sentences.
+	Paragraphs [][]string
+}
+
+// ReadAloudChunk is a slice of one section sized for a TTS request. A chunk
+// never spans two nodes and never splits a sentence.
+type ReadAloudChunk struct {
+	// Title is the title of the section the chunk belongs to.
+	Title string
+	// Part is the 1-based index of the chunk within its section.
+	Part int
+	// Paragraphs holds the chunk's sentences grouped by source paragraph.
+	Paragraphs [][]string
+}
+
+// NewReadAloudSection builds a section from a node file's content, dropping
+// frontmatter, review comments, code blocks, and Markdown markup.
+func NewReadAloudSection(title string, content []byte) ReadAloudSection {
+	if loc := frontmatterRE.FindIndex(content); loc != nil {
+		content = content[loc[1]:]
+	}
+	content = commentRE.ReplaceAll(content, nil)
+	content = raHTMLCommentRE.ReplaceAll(content, nil)
+
+	sec := ReadAloudSection{Title: title}
+	var para []string
+	flush := func() {
+		if len(para) > 0 {
+			sec.Paragraphs = append(sec.Paragraphs, SplitSentences(strings.Join(para, " ")))
+			para = nil
+		}
+	}
+	inFence := false
+	for _, line := range strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if raFenceRE.MatchString(trimmed) {
+			inFence = !inFence
+			flush()
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if trimmed == "" || raSceneBreakRE.MatchString(trimmed) {
+			flush()
+			continue
+		}
+		if raHeadingRE.MatchString(trimmed) {
+			flush()
+			para = append(para, plainProse(raHeadingRE.ReplaceAllString(trimmed, "")))
+			flush()
+			continue
+		}
+		if text := plainProse(trimmed); text != "" {
+			para = append(para, text)
+		}
+	}
+	flush()
+	return sec
+}
+
+// plainProse strips inline Markdown markup from a single line.
+func plainProse(line string) string {
+	line = raImageRE.ReplaceAllString(line, "")
+	line = raWikilinkRE.ReplaceAllString(line, "$1")
+	line = raLinkRE.ReplaceAllString(line, "$1")
+	line = raEmphasisRE.ReplaceAllString(line, "")
+	line = strings.TrimLeft(line, "> ")
+	return strings.Join(strings.Fields(line), " ")
+}
+
+// SplitSentences splits text at sentence-ending punctuation (".", "!", "?",
+// optionally followed by closing quotes or brackets) that is followed by
+// whitespace. Common abbreviations such as "Mr." do not end a sentence.
+func SplitSentences(text string) []string {
+	words := strings.Fields(text)
+	var sentences []string
+	start := 0
+	for i, w := range words {
+		if i == len(words)-1 || !endsSentence(w) {
+			continue
+		}
+		sentences = append(sentences, strings.Join(words[start:i+1], " "))
+		start = i + 1
+	}
+	if start < len(words) {
+		sentences = append(sentences, strings.Join(words[start:], " "))
+	}
+	return sentences
+}
+
+// endsSentence reports whether word closes a sentence.
+func endsSentence(word string) bool {
+	if raAbbreviations[strings.ToLower(word)] {
+		return false
+	}
+	w := strings.TrimRight(word, `"')]”’»`)
+	return strings.HasSuffix(w, ".") || strings.HasSuffix(w, "!") || strings.HasSuffix(w, "?") ||
+		strings.HasSuffix(w, "…")
+}
+
+// ChunkReadAloud splits sections into chunks of at most maxChars characters of
+// spoken text. Every section starts a new chunk, and a sentence longer than
+// maxChars becomes a chunk of its own rather than being cut. A maxChars of
+// zero or less yields one chunk per section.
+func ChunkReadAloud(sections []ReadAloudSection, maxChars int) []ReadAloudChunk {
+	var chunks []ReadAloudChunk
+	for _, sec := range sections {
+		cur := ReadAloudChunk{Title: sec.Title, Part: 1}
+		size := 0
+		for _, para := range sec.Paragraphs {
+			newPara := true
+			for _, s := range para {
+				if maxChars > 0 && size > 0 && size+1+len(s) > maxChars {
+					chunks = append(chunks, cur)
+					cur = ReadAloudChunk{Title: sec.Title, Part: cur.Part + 1}
+					size = 0
+					newPara = true
+				}
+				if newPara {
+					cur.Paragraphs = append(cur.Paragraphs, nil)
+					newPara = false
+				}
+				last := len(cur.Paragraphs) - 1
+				cur.Paragraphs[last] = append(cur.Paragraphs[last], s)
+				if size > 0 {
+					size++
+				}
+				size += len(s)
+			}
+		}
+		chunks = append(chunks, cur)
+	}
+	return chunks
+}
+
+// sceneMarker returns the spoken marker that opens a chunk.
+func (c ReadAloudChunk) sceneMarker() string {
+	if c.Part > 1 {
+		return fmt.Sprintf("%s (continued)", c.Title)
+	}
+	return c.Title
+}
+
+// RenderText renders the chunk as plain text: a bracketed scene marker line
+// followed by its paragraphs separated by blank lines.
+func (c ReadAloudChunk) RenderText() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "[Scene: %s]\n", c.sceneMarker())
+	for _, p := range c.Paragraphs {
+		buf.WriteString("\n" + strings.Join(p, " ") + "\n")
+	}
+	return buf.Bytes()
+}
+
+// RenderSSML renders the chunk as a standalone SSML <speak> document. The
+// scene marker is emitted as an SSML <mark> and, on a section's first chunk,
+// spoken as a heading followed by a pause.
+func (c ReadAloudChunk) RenderSSML() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("<speak>\n")
+	fmt.Fprintf(&buf, "  <mark name=\"%s\"/>\n", html.EscapeString(c.sceneMarker()))
+	if c.Part == 1 && c.Title != "" {
+		fmt.Fprintf(&buf, "  <p><s>%s</s></p>\n  <break time=\"1s\"/>\n", html.EscapeString(c.Title))
+	}
+	for _, p := range c.Paragraphs {
+		buf.WriteString("  <p>")
+		for _, s := range p {
+			fmt.Fprintf(&buf, "<s>%s</s>", html.EscapeString(s))
+		}
+		buf.WriteString("</p>\n")
+	}
+	buf.WriteString("</speak>\n")
+	return buf.Bytes()
+}
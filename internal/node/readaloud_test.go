@@ -0,0 +1,105 @@
+package node_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"One. Two! Three?", []string{"One.", "Two!", "Three?"}},
+		{`Mr. Smith left. "Why?" she asked.`, []string{"Mr. Smith left.", `"Why?"`, "she asked."}},
+		{"No terminal punctuation", []string{"No terminal punctuation"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := node.SplitSentences(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitSentences(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNewReadAloudSection_StripsMarkup(t *testing.T) {
+	content := "---\nid: x\ncreated: a\nupdated: b\n---\n" +
+		"# Arrival\n\n" +
+		"She *ran* to the [station](x.md). %% ed: cut? %%\n" +
+		"The train was late.\n\n" +
+		"* * *\n\n" +
+		"```\ncode is skipped\n```\n" +
+		"See [[notes|the notes]]. <!-- hidden -->\n"
+
+	got := node.NewReadAloudSection("Chapter One", []byte(content))
+
+	want := [][]string{
+		{"Arrival"},
+		{"She ran to the station.", "The train was late."},
+		{"See the notes."},
+	}
+	if got.Title != "Chapter One" || !reflect.DeepEqual(got.Paragraphs, want) {
+		t.Errorf("section = %+v, want paragraphs %q", got, want)
+	}
+}
+
+func TestChunkReadAloud_SplitsOnNodeAndSentenceBoundaries(t *testing.T) {
+	sections := []node.ReadAloudSection{
+		{Title: "A", Paragraphs: [][]string{{"One two.", "Three four."}, {"Five six."}}},
+		{Title: "B", Paragraphs: [][]string{{"Seven."}}},
+	}
+
+	chunks := node.ChunkReadAloud(sections, 20)
+
+	want := []node.ReadAloudChunk{
+		{Title: "A", Part: 1, Paragraphs: [][]string{{"One two.", "Three four."}}},
+		{Title: "A", Part: 2, Paragraphs: [][]string{{"Five six."}}},
+		{Title: "B", Part: 1, Paragraphs: [][]string{{"Seven."}}},
+	}
+	if !reflect.DeepEqual(chunks, want) {
+		t.Errorf("chunks = %+v, want %+v", chunks, want)
+	}
+}
+
+func TestChunkReadAloud_NoLimitAndOversizedSentence(t *testing.T) {
+	sections := []node.ReadAloudSection{{Title: "A", Paragraphs: [][]string{{"A very long sentence indeed.", "Short."}}}}
+
+	if got := node.ChunkReadAloud(sections, 0); len(got) != 1 {
+		t.Errorf("unlimited: got %d chunks, want 1", len(got))
+	}
+	got := node.ChunkReadAloud(sections, 5)
+	if len(got) != 2 || got[0].Paragraphs[0][0] != "A very long sentence indeed." {
+		t.Errorf("oversized sentence should form its own chunk, got %+v", got)
+	}
+}
+
+func TestReadAloudChunk_Render(t *testing.T) {
+	first := node.ReadAloudChunk{Title: "Tom & Jerry", Part: 1, Paragraphs: [][]string{{"Hi <there>.", "Bye."}}}
+	cont := node.ReadAloudChunk{Title: "Tom & Jerry", Part: 2, Paragraphs: [][]string{{"More."}}}
+
+	if got, want := string(first.RenderText()), "[Scene: Tom & Jerry]\n\nHi <there>. Bye.\n"; got != want {
+		t.Errorf("RenderText = %q, want %q", got, want)
+	}
+	if got := string(cont.RenderText()); !strings.HasPrefix(got, "[Scene: Tom & Jerry (continued)]\n") {
+		t.Errorf("continued RenderText = %q", got)
+	}
+
+	ssml := string(first.RenderSSML())
+	for _, want := range []string{
+		"<speak>\n",
+		`<mark name="Tom &amp; Jerry"/>`,
+		"<p><s>Tom &amp; Jerry</s></p>\n  <break time=\"1s\"/>",
+		"<p><s>Hi &lt;there&gt;.</s><s>Bye.</s></p>",
+		"</speak>\n",
+	} {
+		if !strings.Contains(ssml, want) {
+			t.Errorf("RenderSSML missing %q:\n%s", want, ssml)
+		}
+	}
+	if strings.Contains(string(cont.RenderSSML()), "<break") {
+		t.Error("continued chunk should not repeat the spoken title")
+	}
+}
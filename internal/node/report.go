@@ -0,0 +1,136 @@
+package node
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// auditCodeExplanations holds one-sentence explanations of each diagnostic
+// code doctor can report, written for readers who do not run the CLI.
+var auditCodeExplanations = map[AuditCode]string{
+	AUD001:  "The binder links to a file that does not exist on disk.",
+	AUD002:  "A UUID-named node file exists in the project root but the binder never links to it (orphaned node).",
+	AUD003:  "The same file is linked more than once in the binder.",
+	AUD004:  "A node file's frontmatter id does not match its filename.",
+	AUD005:  "A required frontmatter field (id, created, or updated) is missing or malformed.",
+	AUD006:  "A node file has valid frontmatter but no body text yet.",
+	AUD007:  "A node file's YAML frontmatter cannot be parsed.",
+	AUD008:  "The project config .prosemark.yml is missing, unreadable, or not valid YAML.",
+	AUD009:  "The binder file itself cannot be parsed, so no other binder checks could run.",
+	AUD010:  "A node's frontmatter is missing a field, or has a field of the wrong type, for the node type declared in the project config.",
+	AUD011:  "A node's body links to another file, or names a wikilink, that does not exist in the project.",
+	AUD012:  "A file is a conflict copy a sync client such as Dropbox or Syncthing made when the file changed in two places; merge or discard it with pmk conflicts resolve.",
+	AUDW001: "The binder links to a file whose name is not a UUID; older projects may do this on purpose.",
+	BNDE001: "A binder link target contains characters that are not allowed in file paths.",
+	BNDE002: "A binder link target points outside the project directory.",
+	BNDE003: "A wikilink in the binder matches more than one project file.",
+	BNDW001: "The binder is missing its <!-- prosemark-binder:v1 --> pragma comment, or the pragma is malformed.",
+	AuditCode(binder.CodeMultipleStructLinks):      "A binder list item contains more than one structural link; only the first is used.",
+	AuditCode(binder.CodeDuplicateFileRef):         "Two binder entries reference the same file.",
+	AuditCode(binder.CodeMissingTargetFile):        "A binder link target is not present in the project.",
+	AuditCode(binder.CodeLinkInCodeFence):          "A binder link sits inside a fenced code block and is ignored.",
+	AuditCode(binder.CodeLinkOutsideList):          "A link to a .md file appears outside a list item and is ignored.",
+	AuditCode(binder.CodeNonMarkdownTarget):        "A binder list item links to a non-.md file.",
+	AuditCode(binder.CodeSelfReferentialLink):      "A binder link targets a binder file rather than a content node.",
+	AuditCode(binder.CodeCaseInsensitiveMatch):     "A binder link target matches a project file only when case is ignored.",
+	AuditCode(binder.CodeBOMPresence):              "The binder starts with a UTF-8 byte order mark.",
+	AuditCode(binder.CodeWikilinkAliasMatch):       "A binder wikilink matches no file name and was resolved through a file's frontmatter alias.",
+	AuditCode(binder.CodeUnsupportedBinderVersion): "The binder's pragma declares a binder format version this pmk does not read.",
+	AuditCode(binder.CodeParseLimitExceeded):       "The binder exceeds a parse limit (size, line length, node count, or reference definitions); raise it under limits: in .prosemark.yml if the binder is legitimate.",
+}
+
+// Explanation returns a short plain-language explanation of the code, or ""
+// when the code is unknown.
+func (c AuditCode) Explanation() string {
+	return auditCodeExplanations[c]
+}
+
+// RenderMarkdownReport renders doctor diagnostics as a shareable Markdown
+// report: a summary line, then findings grouped by severity (errors first) with
+// links to the affected files, then an explanation of every code that appears.
+// Paths are linked relative to the project root, so the report works when
+// saved there or pasted alongside the project.
+func RenderMarkdownReport(projectName string, diags []AuditDiagnostic) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# pmk doctor report: %s\n\n", projectName)
+
+	var errs, warns []AuditDiagnostic
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			errs = append(errs, d)
+		} else {
+			warns = append(warns, d)
+		}
+	}
+	fmt.Fprintf(&buf, "%s, %s.\n", plural(len(errs), "error"), plural(len(warns), "warning"))
+
+	if len(diags) == 0 {
+		buf.WriteString("\nNo problems found.\n")
+		return buf.Bytes()
+	}
+
+	writeReportSection(&buf, "Errors", errs)
+	writeReportSection(&buf, "Warnings", warns)
+
+	seen := make(map[AuditCode]bool)
+	var codes []AuditCode
+	for _, d := range diags {
+		if !seen[d.Code] {
+			seen[d.Code] = true
+			codes = append(codes, d.Code)
+		}
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+
+	buf.WriteString("\n## What the codes mean\n\n")
+	for _, c := range codes {
+		explanation := c.Explanation()
+		if explanation == "" {
+			explanation = "No explanation available."
+		}
+		fmt.Fprintf(&buf, "- **%s**: %s\n", c, explanation)
+	}
+	return buf.Bytes()
+}
+
+// writeReportSection writes one severity group; empty groups are omitted.
+func writeReportSection(buf *bytes.Buffer, heading string, diags []AuditDiagnostic) {
+	if len(diags) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "\n## %s (%d)\n\n", heading, len(diags))
+	for _, d := range diags {
+		fmt.Fprintf(buf, "- **%s**", d.Code)
+		if d.Path != "" {
+			fmt.Fprintf(buf, " [`%s`](%s)", strings.ReplaceAll(d.Path, "`", "'"), reportLink(d.Path))
+		}
+		fmt.Fprintf(buf, ": %s\n", escapeReportText(d.Message))
+	}
+}
+
+// reportLink returns a relative Markdown link destination for a project path.
+func reportLink(p string) string {
+	u := url.URL{Path: path.Clean(strings.ReplaceAll(p, "\\", "/"))}
+	return u.EscapedPath()
+}
+
+// escapeReportText escapes characters that would start Markdown markup in a
+// diagnostic message, and flattens newlines so each finding stays one bullet.
+func escapeReportText(s string) string {
+	s = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+	return strings.NewReplacer(`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`).Replace(s)
+}
+
+// plural formats n with noun, adding "s" unless n is 1.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,70 @@
+package node_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+func TestRenderMarkdownReport_GroupsBySeverity(t *testing.T) {
+	diags := []node.AuditDiagnostic{
+		{Code: node.AUD006, Severity: node.SeverityWarning, Message: "node file has no body content: a.md", Path: "a.md"},
+		{Code: node.AUD001, Severity: node.SeverityError, Message: "referenced file does not exist: my notes.md", Path: "my notes.md"},
+		{Code: node.AUD001, Severity: node.SeverityError, Message: "referenced file does not exist: b_*.md", Path: "b.md"},
+	}
+
+	got := string(node.RenderMarkdownReport("novel", diags))
+
+	for _, want := range []string{
+		"# pmk doctor report: novel\n",
+		"2 errors, 1 warning.\n",
+		"## Errors (2)\n\n- **AUD001** [`my notes.md`](my%20notes.md): referenced file does not exist: my notes.md\n",
+		`referenced file does not exist: b\_\*.md`,
+		"## Warnings (1)\n\n- **AUD006** [`a.md`](a.md): ",
+		"## What the codes mean\n\n- **AUD001**: " + node.AUD001.Explanation() + "\n- **AUD006**: ",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Index(got, "## Errors") > strings.Index(got, "## Warnings") {
+		t.Error("errors must be listed before warnings")
+	}
+}
+
+func TestRenderMarkdownReport_Clean(t *testing.T) {
+	got := string(node.RenderMarkdownReport("novel", nil))
+	if !strings.Contains(got, "0 errors, 0 warnings.\n\nNo problems found.\n") {
+		t.Errorf("clean report = %q", got)
+	}
+	if strings.Contains(got, "## ") {
+		t.Errorf("clean report must not contain sections: %q", got)
+	}
+}
+
+func TestRenderMarkdownReport_WarningsOnlyAndUnknownCode(t *testing.T) {
+	diags := []node.AuditDiagnostic{{Code: "XYZ999", Severity: node.SeverityWarning, Message: "odd"}}
+
+	got := string(node.RenderMarkdownReport("novel", diags))
+
+	if strings.Contains(got, "## Errors") {
+		t.Errorf("empty error group must be omitted:\n%s", got)
+	}
+	for _, want := range []string{"- **XYZ999**: odd\n", "- **XYZ999**: No explanation available.\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestAuditCode_Explanation(t *testing.T) {
+	for _, c := range []node.AuditCode{node.AUD001, node.AUD009, node.AUDW001, node.BNDE003, node.BNDW001, "BNDW004"} {
+		if c.Explanation() == "" {
+			t.Errorf("%s has no explanation", c)
+		}
+	}
+	if got := node.AuditCode("XYZ999").Explanation(); got != "" {
+		t.Errorf("unknown code explanation = %q, want empty", got)
+	}
+}
@@ -0,0 +1,173 @@
+package node
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldType names the value type a frontmatter field must have.
+type FieldType string
+
+// Field types accepted in a type schema.
+const (
+	FieldString FieldType = "string"
+	FieldNumber FieldType = "number"
+	FieldBool   FieldType = "bool"
+	FieldList   FieldType = "list"
+	FieldDate   FieldType = "date" // YYYY-MM-DD or RFC3339
+	FieldAny    FieldType = "any"
+)
+
+// TypeSchema declares the frontmatter fields and presentation of one node type.
+type TypeSchema struct {
+	// Required maps field names that must be present to their value types.
+	Required map[string]FieldType `yaml:"required"`
+	// Optional maps field names that may be present to their value types.
+	Optional map[string]FieldType `yaml:"optional"`
+	// Label is the human-readable name shown in listings (e.g. "Scene").
+	Label string `yaml:"label"`
+	// Icon is a short marker shown beside nodes of this type in listings.
+	Icon string `yaml:"icon"`
+	// Template is the body written into new node files of this type.
+	Template string `yaml:"template"`
+}
+
+// FrontmatterSchema maps a node's frontmatter "type:" value to its schema.
+type FrontmatterSchema map[string]TypeSchema
+
+// ParseFrontmatterSchema reads the "types" section of a project config file:
+//
+//	types:
+//	  scene:
+//	    required: {pov: string}
+//	    optional: {location: string}
+//	  character:
+//	    required: {aliases: list}
+//	    label: Character
+//	    icon: "@"
+//	    template: |
+//	      ## Background
+//
+// A config without a "types" section yields a nil schema. Unknown field types
+// are reported as errors so typos don't silently disable a check.
+func ParseFrontmatterSchema(config []byte) (FrontmatterSchema, error) {
+	var cfg struct {
+		Types FrontmatterSchema `yaml:"types"`
+	}
+	if err := yaml.Unmarshal(config, &cfg); err != nil {
+		return nil, fmt.Errorf("parse types: %w", err)
+	}
+	for _, name := range sortedKeys(cfg.Types) {
+		ts := cfg.Types[name]
+		for _, fields := range []map[string]FieldType{ts.Required, ts.Optional} {
+			for _, field := range sortedKeys(fields) {
+				if !fields[field].valid() {
+					return nil, fmt.Errorf("type %q field %q: unknown field type %q (want string, number, bool, list, date, or any)",
+						name, field, fields[field])
+				}
+			}
+		}
+	}
+	return cfg.Types, nil
+}
+
+// valid reports whether t is a known field type.
+func (t FieldType) valid() bool {
+	switch t {
+	case FieldString, FieldNumber, FieldBool, FieldList, FieldDate, FieldAny:
+		return true
+	}
+	return false
+}
+
+// ValidateFrontmatterSchema checks a node file's frontmatter against the
+// schema for its "type:" value and returns one AUD010 diagnostic per missing
+// required field or mistyped field. Nodes without a type, or whose type is
+// not declared in schema, are not checked.
+func ValidateFrontmatterSchema(path string, content []byte, schema FrontmatterSchema) []AuditDiagnostic {
+	if len(schema) == 0 {
+		return nil
+	}
+	m := frontmatterRE.FindSubmatch(content)
+	if m == nil {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := yaml.Unmarshal(m[1], &fields); err != nil {
+		return nil // AUD007 covers unparseable frontmatter
+	}
+	nodeType, _ := fields["type"].(string)
+	ts, ok := schema[nodeType]
+	if !ok {
+		return nil
+	}
+
+	var diags []AuditDiagnostic
+	for _, name := range sortedKeys(ts.Required) {
+		v, present := fields[name]
+		if !present || v == nil {
+			diags = append(diags, errDiag(AUD010, path,
+				fmt.Sprintf("%s: type %q requires frontmatter field %q", path, nodeType, name)))
+			continue
+		}
+		diags = append(diags, checkFieldType(path, name, ts.Required[name], v)...)
+	}
+	for _, name := range sortedKeys(ts.Optional) {
+		if v, present := fields[name]; present && v != nil {
+			diags = append(diags, checkFieldType(path, name, ts.Optional[name], v)...)
+		}
+	}
+	return diags
+}
+
+// checkFieldType returns an AUD010 diagnostic when v is not of type want.
+func checkFieldType(path, name string, want FieldType, v interface{}) []AuditDiagnostic {
+	got := yamlValueType(v)
+	if want == FieldAny || got == want || (want == FieldDate && got == FieldString && isDate(v.(string))) {
+		return nil
+	}
+	return []AuditDiagnostic{errDiag(AUD010, path,
+		fmt.Sprintf("%s: frontmatter field %q must be %s, got %s", path, name, want, got))}
+}
+
+// yamlValueType classifies a decoded YAML value. Maps report as "map", which
+// only FieldAny accepts.
+func yamlValueType(v interface{}) FieldType {
+	switch v.(type) {
+	case string:
+		return FieldString
+	case int, int64, uint64, float64:
+		return FieldNumber
+	case bool:
+		return FieldBool
+	case []interface{}:
+		return FieldList
+	case time.Time:
+		return FieldDate
+	default:
+		return "map"
+	}
+}
+
+// isDate reports whether s is a YYYY-MM-DD date or an RFC3339 timestamp.
+func isDate(s string) bool {
+	if _, err := time.Parse("2006-01-02", s); err == nil {
+		return true
+	}
+	_, err := time.Parse(time.RFC3339, strings.TrimSpace(s))
+	return err == nil
+}
+
+// sortedKeys returns m's keys in lexical order for deterministic output.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
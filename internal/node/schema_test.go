@@ -0,0 +1,102 @@
+package node_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+const schemaTestConfig = `version: "1"
+types:
+  scene:
+    required:
+      pov: string
+    optional:
+      words: number
+      due: date
+  character:
+    required:
+      aliases: list
+`
+
+func TestParseFrontmatterSchema(t *testing.T) {
+	schema, err := node.ParseFrontmatterSchema([]byte(schemaTestConfig))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if schema["scene"].Required["pov"] != node.FieldString || schema["character"].Required["aliases"] != node.FieldList {
+		t.Errorf("schema = %+v", schema)
+	}
+
+	if schema, err := node.ParseFrontmatterSchema([]byte("version: \"1\"\n")); err != nil || schema != nil {
+		t.Errorf("no types section: got %v, %v; want nil, nil", schema, err)
+	}
+	_, err = node.ParseFrontmatterSchema([]byte("types:\n  scene:\n    required:\n      pov: strng\n"))
+	if err == nil || !strings.Contains(err.Error(), `unknown field type "strng"`) {
+		t.Errorf("unknown field type: err = %v", err)
+	}
+	if _, err := node.ParseFrontmatterSchema([]byte("types: [scene]\n")); err == nil {
+		t.Error("malformed types section: expected error")
+	}
+}
+
+func TestValidateFrontmatterSchema(t *testing.T) {
+	schema, err := node.ParseFrontmatterSchema([]byte(schemaTestConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		front string
+		want  []string
+	}{
+		{"valid scene", "type: scene\npov: Ann\nwords: 1200\ndue: 2025-06-01", nil},
+		{"untyped node", "title: x", nil},
+		{"undeclared type", "type: place", nil},
+		{"missing required", "type: scene", []string{`a.md: type "scene" requires frontmatter field "pov"`}},
+		{"null required", "type: scene\npov:", []string{`requires frontmatter field "pov"`}},
+		{"wrong required type", "type: character\naliases: Bob", []string{`a.md: frontmatter field "aliases" must be list, got string`}},
+		{"quoted dates", "type: scene\npov: Ann\ndue: \"2025-06-01\"", nil},
+		{"quoted timestamp", "type: scene\npov: Ann\ndue: '2025-06-01T10:00:00Z'", nil},
+		{"bool for number", "type: scene\npov: Ann\nwords: true", []string{`must be number, got bool`}},
+		{"list for string", "type: scene\npov: [Ann, Bob]", []string{`must be string, got list`}},
+		{"map for string", "type: scene\npov: {name: Ann}", []string{`must be string, got map`}},
+		{"wrong optional type", "type: scene\npov: Ann\nwords: many\ndue: soon", []string{
+			`frontmatter field "due" must be date, got string`,
+			`frontmatter field "words" must be number, got string`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := []byte("---\nid: a\n" + tt.front + "\n---\nBody\n")
+			diags := node.ValidateFrontmatterSchema("a.md", content, schema)
+			if len(diags) != len(tt.want) {
+				t.Fatalf("got %d diagnostics %+v, want %d", len(diags), diags, len(tt.want))
+			}
+			for i, d := range diags {
+				if d.Code != node.AUD010 || d.Severity != node.SeverityError || d.Path != "a.md" {
+					t.Errorf("diag[%d] = %+v, want AUD010 error on a.md", i, d)
+				}
+				if !strings.Contains(d.Message, tt.want[i]) {
+					t.Errorf("diag[%d].Message = %q, want it to contain %q", i, d.Message, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateFrontmatterSchema_SkipsUncheckableFiles(t *testing.T) {
+	schema, err := node.ParseFrontmatterSchema([]byte(schemaTestConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"no frontmatter":      "Body only\n",
+		"invalid frontmatter": "---\ntype: [scene\n---\nBody\n",
+	} {
+		if diags := node.ValidateFrontmatterSchema("a.md", []byte(content), schema); diags != nil {
+			t.Errorf("%s: diags = %+v, want none", name, diags)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package node
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Slugify returns a URL- and filename-safe slug for title: lowercase letters
+// and digits, with every other run of characters collapsed into a single
+// hyphen and leading and trailing hyphens dropped. Non-ASCII letters are kept
+// (lowercased) rather than transliterated, so the result is deterministic
+// without a Unicode normalization table. A title with no letters or digits
+// yields "".
+func Slugify(title string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(title) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			hyphen = b.Len() > 0
+			continue
+		}
+		if hyphen {
+			b.WriteByte('-')
+			hyphen = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// FrontmatterSlug returns the slug a node declares for itself with the
+// "slug:" frontmatter key, normalized by Slugify. Content without a readable
+// frontmatter block, or without the key, declares no slug.
+func FrontmatterSlug(content []byte) string {
+	m := frontmatterRE.FindSubmatch(content)
+	if m == nil {
+		return ""
+	}
+	var fields struct {
+		Slug string `yaml:"slug"`
+	}
+	if err := yaml.Unmarshal(m[1], &fields); err != nil {
+		return ""
+	}
+	return Slugify(fields.Slug)
+}
+
+// SlugEntry is one node to assign a slug to.
+type SlugEntry struct {
+	// Key identifies the node across runs (its binder target path).
+	Key string
+	// Title is the node's display title; it seeds the slug of a new node.
+	Title string
+	// Override is the node's frontmatter slug, which always wins.
+	Override string
+}
+
+// AssignSlugs returns a slug for every entry, keyed by SlugEntry.Key. A
+// node's override is used when set; otherwise the slug it was given in prev
+// is kept, so retitling a node does not break links to it; otherwise its
+// slug is derived from its title (or "untitled"). Slugs are claimed in that
+// order of precedence, then entry order, and a slug already taken gets the
+// lowest free numeric suffix ("-2", "-3", ...). Keys absent from entries are
+// dropped from the result.
+func AssignSlugs(entries []SlugEntry, prev map[string]string) map[string]string {
+	slugs := make(map[string]string, len(entries))
+	taken := make(map[string]bool, len(entries))
+	sources := []func(SlugEntry) string{
+		func(e SlugEntry) string { return e.Override },
+		func(e SlugEntry) string { return Slugify(prev[e.Key]) },
+		func(e SlugEntry) string {
+			if s := Slugify(e.Title); s != "" {
+				return s
+			}
+			return "untitled"
+		},
+	}
+	for _, source := range sources {
+		for _, e := range entries {
+			if _, done := slugs[e.Key]; done {
+				continue
+			}
+			want := source(e)
+			if want == "" {
+				continue
+			}
+			slug := want
+			for n := 2; taken[slug]; n++ {
+				slug = want + "-" + strconv.Itoa(n)
+			}
+			taken[slug] = true
+			slugs[e.Key] = slug
+		}
+	}
+	return slugs
+}
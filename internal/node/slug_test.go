@@ -0,0 +1,85 @@
+package node
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Chapter One", "chapter-one"},
+		{"  The Fall — of Rome!  ", "the-fall-of-rome"},
+		{"Part 2: Ashes & Embers", "part-2-ashes-embers"},
+		{"Über Größe", "über-größe"},
+		{"already-a-slug", "already-a-slug"},
+		{"***", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Slugify(tt.title); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestFrontmatterSlug(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"normalized", "---\nslug: The Opening\n---\nbody\n", "the-opening"},
+		{"no slug", "---\ntitle: Opening\n---\n", ""},
+		{"no frontmatter", "# Opening\n", ""},
+		{"invalid yaml", "---\nslug: [unclosed\n---\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FrontmatterSlug([]byte(tt.content)); got != tt.want {
+				t.Errorf("FrontmatterSlug() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssignSlugs(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []SlugEntry
+		prev    map[string]string
+		want    map[string]string
+	}{
+		{
+			name:    "from titles with collisions",
+			entries: []SlugEntry{{Key: "a.md", Title: "Intro"}, {Key: "b.md", Title: "Intro"}, {Key: "c.md", Title: "intro!"}, {Key: "d.md", Title: "?"}},
+			want:    map[string]string{"a.md": "intro", "b.md": "intro-2", "c.md": "intro-3", "d.md": "untitled"},
+		},
+		{
+			name:    "kept across retitling",
+			entries: []SlugEntry{{Key: "a.md", Title: "New Title"}, {Key: "b.md", Title: "Old Title"}},
+			prev:    map[string]string{"a.md": "old-title", "gone.md": "gone"},
+			want:    map[string]string{"a.md": "old-title", "b.md": "old-title-2"},
+		},
+		{
+			name:    "override wins over kept slug",
+			entries: []SlugEntry{{Key: "a.md", Title: "A"}, {Key: "b.md", Title: "B", Override: "prologue"}},
+			prev:    map[string]string{"a.md": "prologue"},
+			want:    map[string]string{"a.md": "prologue-2", "b.md": "prologue"},
+		},
+		{
+			name:    "duplicate keys",
+			entries: []SlugEntry{{Key: "a.md", Title: "A"}, {Key: "a.md", Title: "Again"}},
+			want:    map[string]string{"a.md": "a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AssignSlugs(tt.entries, tt.prev); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AssignSlugs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
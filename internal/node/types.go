@@ -10,8 +10,14 @@ type Frontmatter struct {
 	ID string `yaml:"id"`
 	// Title is the optional human-readable title for the node.
 	Title string `yaml:"title,omitempty"`
+	// Type is the optional node type (e.g. "scene"); see FrontmatterSchema.NodeType.
+	Type string `yaml:"type,omitempty"`
 	// Synopsis is the optional brief summary of the node's content.
 	Synopsis string `yaml:"synopsis,omitempty"`
+	// Source is the optional URL a captured node was taken from.
+	Source string `yaml:"source,omitempty"`
+	// Captured is the optional RFC3339 timestamp when Source was fetched.
+	Captured string `yaml:"captured,omitempty"`
 	// Created is the RFC3339 timestamp when the node was first created.
 	Created string `yaml:"created"`
 	// Updated is the RFC3339 timestamp when the node was last modified.
@@ -50,6 +56,20 @@ const (
 	AUD008 AuditCode = "AUD008"
 	// AUDW001 is a warning indicating a non-UUID filename linked in binder (backward-compatibility warning for Feature 001 projects).
 	AUDW001 AuditCode = "AUDW001"
+	// AUD009 indicates the binder file itself could not be parsed (e.g. invalid UTF-8 content).
+	AUD009 AuditCode = "AUD009"
+	// AUD010 indicates a node's frontmatter does not match the schema declared for its type in the project config.
+	AUD010 AuditCode = "AUD010"
+	// AUD011 is a warning indicating a node body links to a file or wikilink name that does not exist in the project.
+	AUD011 AuditCode = "AUD011"
+	// AUD012 is a warning indicating a project file is a cloud-sync conflict copy (Dropbox, Syncthing) of another.
+	AUD012 AuditCode = "AUD012"
+	// BNDE001 is an error propagated from the binder parser indicating a link target contains illegal path characters.
+	BNDE001 AuditCode = "BNDE001"
+	// BNDE002 is an error propagated from the binder parser indicating a link target resolves outside the project root.
+	BNDE002 AuditCode = "BNDE002"
+	// BNDE003 is an error propagated from the binder parser indicating a wikilink matches more than one project file.
+	BNDE003 AuditCode = "BNDE003"
 	// BNDW001 is a warning propagated from the binder parser indicating the binder file is missing its pragma comment.
 	BNDW001 AuditCode = "BNDW001"
 )
@@ -74,4 +94,7 @@ type AuditDiagnostic struct {
 	Message string
 	// Path is the file or resource path associated with the finding.
 	Path string
+	// Line is the 1-based line in Path the finding is about, or 0 when it
+	// is about the file as a whole.
+	Line int
 }
@@ -18,15 +18,16 @@ func ValidateFieldValue(s string) error {
 }
 
 // ValidateNewNodeInput validates the --target, --title, and --synopsis inputs
-// for --new mode. target may be empty (caller will generate one); at least one
-// of title or synopsis must be non-empty.
-func ValidateNewNodeInput(target, title, synopsis string) error {
+// for --new mode. target may be empty (caller will generate one), else it
+// must be a node filename of scheme; at least one of title or synopsis must be
+// non-empty.
+func ValidateNewNodeInput(scheme IDScheme, target, title, synopsis string) error {
 	if target != "" {
 		if strings.ContainsRune(target, os.PathSeparator) {
 			return fmt.Errorf("target must not contain path separators")
 		}
-		if !IsUUIDFilename(target) {
-			return fmt.Errorf("target must be a valid UUID filename when --new is set")
+		if !scheme.MatchFilename(target) {
+			return fmt.Errorf("target must be a valid %s filename when --new is set", scheme.Label())
 		}
 	}
 	if title == "" && synopsis == "" {
@@ -46,3 +47,35 @@ func ValidateNewNodeInput(target, title, synopsis string) error {
 	}
 	return nil
 }
+
+// ValidateMetaField validates setting frontmatter field key to value with
+// pmk meta set. The key must be a plain field name of at most 64
+// characters, and not one pmk manages itself: id, which pmk rename
+// changes, or created and updated. The value may be at most 500
+// characters for title and 2000 for any other field, without control
+// characters.
+func ValidateMetaField(key, value string) error {
+	if !fieldKeyRE.MatchString(key) {
+		return fmt.Errorf("invalid field name %q: use letters, digits, _ and -", key)
+	}
+	if len(key) > 64 {
+		return fmt.Errorf("field name must be 64 characters or fewer")
+	}
+	switch key {
+	case "id":
+		return fmt.Errorf("id is managed by pmk; use pmk rename to change it")
+	case "created", "updated":
+		return fmt.Errorf("%s is managed by pmk", key)
+	}
+	limit := 2000
+	if key == "title" {
+		limit = 500
+	}
+	if len(value) > limit {
+		return fmt.Errorf("%s must be %d characters or fewer", key, limit)
+	}
+	if err := ValidateFieldValue(value); err != nil {
+		return fmt.Errorf("%s must not contain control characters", key)
+	}
+	return nil
+}
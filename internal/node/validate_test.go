@@ -233,7 +233,7 @@ func TestValidateNewNodeInput(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := node.ValidateNewNodeInput(tt.target, tt.title, tt.synopsis)
+			err := node.ValidateNewNodeInput(node.DefaultIDScheme, tt.target, tt.title, tt.synopsis)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ValidateNewNodeInput(%q, %q, %q) error = %v, wantErr %v",
 					tt.target, tt.title, tt.synopsis, err, tt.wantErr)
@@ -241,3 +241,50 @@ func TestValidateNewNodeInput(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateNewNodeInput_UsesIDScheme(t *testing.T) {
+	err := node.ValidateNewNodeInput(node.ULIDIDs, "0192f0c1-3e7a-7000-8000-5a4b3c2d1e0f.md", "Title", "")
+	if err == nil || !strings.Contains(err.Error(), "valid ULID filename") {
+		t.Errorf("UUID target under ulid scheme: err = %v, want ULID filename error", err)
+	}
+	if err := node.ValidateNewNodeInput(node.ULIDIDs, "01J5Z3V0A4Q8W2E6R9T1Y3X5I7.md", "Title", ""); err == nil {
+		t.Error("ULID with the excluded letter I accepted")
+	}
+	if err := node.ValidateNewNodeInput(node.ULIDIDs, "01J5Z3V0A4Q8W2E6R9T1Y3X5H7.md", "Title", ""); err != nil {
+		t.Errorf("valid ULID target rejected: %v", err)
+	}
+}
+
+// TestValidateMetaField verifies the rules for setting a field with pmk
+// meta set: plain names, pmk-managed fields, length limits, and control
+// characters.
+func TestValidateMetaField(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		wantErr string
+	}{
+		{name: "plain field", key: "status", value: "drafted"},
+		{name: "empty value", key: "synopsis", value: ""},
+		{name: "title of exactly 500 chars", key: "title", value: strings.Repeat("a", 500)},
+		{name: "title exceeding 500 chars", key: "title", value: strings.Repeat("a", 501), wantErr: "title must be 500 characters or fewer"},
+		{name: "field of exactly 2000 chars", key: "notes", value: strings.Repeat("a", 2000)},
+		{name: "field exceeding 2000 chars", key: "notes", value: strings.Repeat("a", 2001), wantErr: "notes must be 2000 characters or fewer"},
+		{name: "control character", key: "status", value: "a\x00b", wantErr: "status must not contain control characters"},
+		{name: "key needing quotes", key: "my status", wantErr: `invalid field name "my status"`},
+		{name: "empty key", key: "", wantErr: "invalid field name"},
+		{name: "long key", key: strings.Repeat("k", 65), wantErr: "field name must be 64 characters or fewer"},
+		{name: "id", key: "id", value: "x", wantErr: "use pmk rename"},
+		{name: "created", key: "created", value: "2026-01-01T00:00:00Z", wantErr: "created is managed by pmk"},
+		{name: "updated", key: "updated", value: "2026-01-01T00:00:00Z", wantErr: "updated is managed by pmk"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := node.ValidateMetaField(tt.key, tt.value)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("ValidateMetaField(%q) = %v, want %q", tt.key, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+// Package release reads pmk's GitHub releases feed and unpacks the archives
+// goreleaser publishes there, for pmk version --check and pmk self-update.
+package release
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// LatestURL is the GitHub API endpoint describing the newest release.
+const LatestURL = "https://api.github.com/repos/eykd/prosemark-go/releases/latest"
+
+// ChecksumsAsset is the name of the SHA-256 checksum list published with
+// each release.
+const ChecksumsAsset = "checksums.txt"
+
+// BinaryName is the executable's name inside a release archive.
+const BinaryName = "pmk"
+
+// Release is one published release.
+type Release struct {
+	Tag     string  `json:"tag_name"`
+	HTMLURL string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is one file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// ParseLatest decodes the releases/latest API response in data.
+func ParseLatest(data []byte) (*Release, error) {
+	var r Release
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("decoding release: %w", err)
+	}
+	if r.Tag == "" {
+		return nil, errors.New("decoding release: no tag_name")
+	}
+	return &r, nil
+}
+
+// Version is the release's version: its tag without the leading "v", as
+// goreleaser stamps it into the binary.
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.Tag, "v")
+}
+
+// Asset returns the asset called name, or nil when the release has none.
+func (r *Release) Asset(name string) *Asset {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+// ArchiveName is the name of the release archive for version built for
+// goos/goarch, following the goreleaser name_template.
+func ArchiveName(version, goos, goarch string) string {
+	return fmt.Sprintf("prosemark-go_%s_%s_%s.tar.gz", version, goos, goarch)
+}
+
+// Compare orders versions a and b, each dot-separated numbers with an
+// optional leading "v", returning -1, 0, or +1. ok is false when either is
+// not such a version, as for a "dev" build.
+func Compare(a, b string) (cmp int, ok bool) {
+	pa, okA := versionParts(a)
+	pb, okB := versionParts(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+// versionParts splits v into its numeric fields.
+func versionParts(v string) ([]int, bool) {
+	fields := strings.Split(strings.TrimPrefix(v, "v"), ".")
+	parts := make([]int, len(fields))
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+// VerifyChecksum checks data, the asset called name, against checksums, a
+// checksums.txt listing of "<sha256 hex>  <name>" lines.
+func VerifyChecksum(checksums []byte, name string, data []byte) error {
+	sc := bufio.NewScanner(bytes.NewReader(checksums))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 2 || fields[1] != name {
+			continue
+		}
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(fields[0], hex.EncodeToString(sum[:])) {
+			return fmt.Errorf("checksum mismatch for %s", name)
+		}
+		return nil
+	}
+	return fmt.Errorf("no checksum listed for %s", name)
+}
+
+// ExtractBinary returns the contents of the regular file called name at the
+// top level of archive, a gzipped tarball.
+func ExtractBinary(archive []byte, name string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("reading archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("archive has no %s", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg || path.Clean(hdr.Name) != name {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("reading archive: %w", err)
+		}
+		return data, nil
+	}
+}
@@ -0,0 +1,156 @@
+package release
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// tarball returns a gzipped tar archive holding files, name to content.
+func tarball(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// gzipped returns data gzipped.
+func gzipped(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestParseLatest(t *testing.T) {
+	r, err := ParseLatest([]byte(`{"tag_name":"v2026.01.02.1234","assets":[{"name":"checksums.txt","browser_download_url":"https://example.com/checksums.txt"}]}`))
+	if err != nil {
+		t.Fatalf("ParseLatest: %v", err)
+	}
+	if r.Version() != "2026.01.02.1234" {
+		t.Errorf("Version() = %q", r.Version())
+	}
+	if a := r.Asset(ChecksumsAsset); a == nil || a.URL != "https://example.com/checksums.txt" {
+		t.Errorf("Asset(checksums.txt) = %+v", a)
+	}
+	if a := r.Asset("missing"); a != nil {
+		t.Errorf("Asset(missing) = %+v, want nil", a)
+	}
+
+	for _, bad := range []string{`not json`, `{}`} {
+		if _, err := ParseLatest([]byte(bad)); err == nil {
+			t.Errorf("ParseLatest(%q): want an error", bad)
+		}
+	}
+}
+
+func TestArchiveName(t *testing.T) {
+	if got := ArchiveName("2026.01.02.1234", "linux", "amd64"); got != "prosemark-go_2026.01.02.1234_linux_amd64.tar.gz" {
+		t.Errorf("ArchiveName = %q", got)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		want   int
+		wantOK bool
+	}{
+		{"2026.01.02.1234", "2026.01.02.1234", 0, true},
+		{"v2026.01.02.1234", "2026.01.02.1234", 0, true},
+		{"2026.01.02.1234", "2026.01.10.0001", -1, true},
+		{"2026.2.1", "2026.10.1", -1, true},
+		{"2026.01.03", "2026.01.02.1234", 1, true},
+		{"1.2", "1.2.0", 0, true},
+		{"dev", "2026.01.02.1234", 0, false},
+		{"2026.01.02.1234", "", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := Compare(tt.a, tt.b)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Compare(%q, %q) = %d, %v; want %d, %v", tt.a, tt.b, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	data := []byte("archive")
+	sum := sha256.Sum256(data)
+	checksums := []byte("0000  other.tar.gz\n" + hex.EncodeToString(sum[:]) + "  pmk.tar.gz\n")
+
+	if err := VerifyChecksum(checksums, "pmk.tar.gz", data); err != nil {
+		t.Errorf("matching checksum: %v", err)
+	}
+	if err := VerifyChecksum(checksums, "pmk.tar.gz", []byte("tampered")); err == nil || !strings.Contains(err.Error(), "mismatch") {
+		t.Errorf("tampered data: error = %v, want a mismatch", err)
+	}
+	if err := VerifyChecksum(checksums, "absent.tar.gz", data); err == nil || !strings.Contains(err.Error(), "no checksum") {
+		t.Errorf("unlisted asset: error = %v, want no checksum", err)
+	}
+}
+
+func TestExtractBinary(t *testing.T) {
+	archive := tarball(t, map[string]string{"README.md": "readme", "pmk": "binary"})
+	got, err := ExtractBinary(archive, BinaryName)
+	if err != nil || string(got) != "binary" {
+		t.Errorf("ExtractBinary = %q, %v; want the pmk file", got, err)
+	}
+
+	if _, err := ExtractBinary(tarball(t, map[string]string{"README.md": "readme"}), BinaryName); err == nil || !strings.Contains(err.Error(), "has no pmk") {
+		t.Errorf("missing binary: error = %v", err)
+	}
+	if _, err := ExtractBinary([]byte("not gzip"), BinaryName); err == nil {
+		t.Error("corrupt archive: want an error")
+	}
+}
+
+func TestExtractBinary_CorruptTar(t *testing.T) {
+	// A header promising more of pmk than the archive holds.
+	var truncated bytes.Buffer
+	tw := tar.NewWriter(&truncated)
+	if err := tw.WriteHeader(&tar.Header{Name: BinaryName, Mode: 0o755, Size: 100, Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte("short")); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		tar  []byte
+	}{
+		{"bad header", bytes.Repeat([]byte("x"), 512)},
+		{"truncated binary", truncated.Bytes()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ExtractBinary(gzipped(t, tt.tar), BinaryName); err == nil || !strings.Contains(err.Error(), "reading archive") {
+				t.Errorf("ExtractBinary = %v, want a read error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// Package safepath resolves project-relative paths without letting them
+// reach outside the project directory, whether by "..", an absolute path,
+// or a symlink. Every command that turns a binder target, an include, or a
+// user-supplied path into a file path goes through Resolve, so a new
+// command cannot reintroduce the traversal.
+package safepath
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+// ErrTraversal is wrapped by the errors Resolve and Rel return for a path
+// that escapes its root directory.
+var ErrTraversal = errors.New("path escapes the project directory")
+
+// Resolve joins the slash-separated target to root and returns the result,
+// or an error wrapping ErrTraversal when target is absolute, climbs out of
+// root with "..", or leads outside root through a symlink. A target that
+// does not exist yet is checked through its nearest existing ancestor, so
+// paths about to be written are guarded too. A root that is not on disk is
+// checked lexically only.
+func Resolve(root, target string) (string, error) {
+	if filepath.IsAbs(target) || strings.HasPrefix(target, "/") {
+		return "", traversal(target)
+	}
+	joined := filepath.Join(root, filepath.FromSlash(target))
+	if !within(filepath.Clean(root), joined) {
+		return "", traversal(target)
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return joined, nil
+	}
+	realRoot, err := filepath.EvalSymlinks(absRoot)
+	if err != nil {
+		return joined, nil
+	}
+	if real, err := evalExisting(filepath.Join(absRoot, filepath.FromSlash(target))); err != nil || !within(realRoot, real) {
+		return "", traversal(target)
+	}
+	return joined, nil
+}
+
+// Rel returns path relative to root, slash-separated, or an error wrapping
+// ErrTraversal when path lies outside root. Both are compared lexically, as
+// given.
+func Rel(root, path string) (string, error) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || escapes(rel) {
+		return "", traversal(path)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// Diagnostic returns the diagnostic commands report when Resolve refuses
+// target: BNDE002, as the binder parser reports a link target that climbs
+// out with "..", so a symlink escape reads the same.
+func Diagnostic(target string) binder.Diagnostic {
+	return binder.Diagnostic{
+		Severity: "error",
+		Code:     binder.CodePathEscapesRoot,
+		Message:  fmt.Sprintf("Link target resolves outside the project root: %s", target),
+	}
+}
+
+// traversal returns the ErrTraversal error for target.
+func traversal(target string) error {
+	return fmt.Errorf("%w: %s", ErrTraversal, target)
+}
+
+// within reports whether the clean path p is root or lies under it.
+func within(root, p string) bool {
+	rel, err := filepath.Rel(root, p)
+	return err == nil && !escapes(rel)
+}
+
+// escapes reports whether the relative path rel climbs out of its base.
+func escapes(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// evalExisting returns p with the symlinks of its longest existing prefix
+// resolved and the rest kept as it is. A dangling symlink is an error,
+// since where it leads cannot be checked.
+func evalExisting(p string) (string, error) {
+	var rest []string
+	for {
+		real, err := filepath.EvalSymlinks(p)
+		if err == nil {
+			return filepath.Join(append([]string{real}, rest...)...), nil
+		}
+		parent := filepath.Dir(p)
+		if !errors.Is(err, fs.ErrNotExist) || parent == p {
+			return "", err
+		}
+		if _, lerr := os.Lstat(p); lerr == nil {
+			return "", fmt.Errorf("dangling symlink: %w", err)
+		}
+		rest = append([]string{filepath.Base(p)}, rest...)
+		p = parent
+	}
+}
@@ -0,0 +1,134 @@
+package safepath_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/safepath"
+)
+
+func TestResolve_Lexical(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "proj") // not on disk
+	tests := []struct {
+		target  string
+		wantErr bool
+	}{
+		{"a.md", false},
+		{"part/a.md", false},
+		{"part/../a.md", false},
+		{"..", true},
+		{"../a.md", true},
+		{"part/../../a.md", true},
+		{"/etc/passwd", true},
+	}
+	for _, tt := range tests {
+		got, err := safepath.Resolve(root, tt.target)
+		if tt.wantErr {
+			if !errors.Is(err, safepath.ErrTraversal) {
+				t.Errorf("Resolve(%q) err = %v, want ErrTraversal", tt.target, err)
+			}
+			continue
+		}
+		if err != nil || got != filepath.Join(root, tt.target) {
+			t.Errorf("Resolve(%q) = %q, %v", tt.target, got, err)
+		}
+	}
+}
+
+func TestResolve_Symlinks(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "proj")
+	outside := filepath.Join(base, "outside")
+	for _, dir := range []string{filepath.Join(root, "part"), outside} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{filepath.Join(root, "part", "a.md"), filepath.Join(outside, "secret.md")} {
+		if err := os.WriteFile(f, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := map[string]string{
+		"inside.md":   filepath.Join(root, "part", "a.md"),
+		"escape.md":   filepath.Join(outside, "secret.md"),
+		"escapedir":   outside,
+		"dangling.md": filepath.Join(outside, "missing.md"),
+		"loop.md":     filepath.Join(root, "loop.md"),
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, name)); err != nil {
+			t.Skipf("symlinks unavailable: %v", err)
+		}
+	}
+	linkedRoot := filepath.Join(base, "linked")
+	if err := os.Symlink(root, linkedRoot); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		root, target string
+		wantErr      bool
+	}{
+		{root, "part/a.md", false},
+		{root, "inside.md", false},
+		{root, "part/new.md", false},
+		{root, "new/dir/new.md", false},
+		{linkedRoot, "part/a.md", false},
+		{root, "escape.md", true},
+		{root, "escapedir/secret.md", true},
+		{root, "escapedir/new.md", true},
+		{root, "dangling.md", true},
+		{root, "loop.md", true},
+		{linkedRoot, "escape.md", true},
+	}
+	for _, tt := range tests {
+		_, err := safepath.Resolve(tt.root, tt.target)
+		if got := errors.Is(err, safepath.ErrTraversal); got != tt.wantErr {
+			t.Errorf("Resolve(%q, %q) err = %v, want traversal %v", tt.root, tt.target, err, tt.wantErr)
+		}
+	}
+}
+
+func TestResolve_RelativeRootWithoutWorkingDirectory(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.Remove(dir); err != nil {
+		t.Skipf("cannot remove the working directory: %v", err)
+	}
+	if got, err := safepath.Resolve("proj", "a.md"); err != nil || got != filepath.Join("proj", "a.md") {
+		t.Errorf("Resolve() = %q, %v; want the lexical join", got, err)
+	}
+	if _, err := safepath.Resolve("proj", "../a.md"); !errors.Is(err, safepath.ErrTraversal) {
+		t.Errorf("Resolve(../a.md) err = %v, want ErrTraversal", err)
+	}
+}
+
+func TestRel(t *testing.T) {
+	tests := []struct {
+		path, want string
+		wantErr    bool
+	}{
+		{"/proj/a.md", "a.md", false},
+		{"/proj/part/a.md", "part/a.md", false},
+		{"/proj", ".", false},
+		{"/other/a.md", "", true},
+		{"/proj/../a.md", "", true},
+	}
+	for _, tt := range tests {
+		got, err := safepath.Rel("/proj", tt.path)
+		if errors.Is(err, safepath.ErrTraversal) != tt.wantErr || got != tt.want {
+			t.Errorf("Rel(%q) = %q, %v; want %q, traversal %v", tt.path, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestDiagnostic(t *testing.T) {
+	d := safepath.Diagnostic("../a.md")
+	if d.Code != binder.CodePathEscapesRoot || d.Severity != "error" || d.Message != "Link target resolves outside the project root: ../a.md" {
+		t.Errorf("Diagnostic() = %+v", d)
+	}
+}
@@ -0,0 +1,115 @@
+// Package stats counts the words and characters of a manuscript: per node,
+// per subtree of the binder, and against a word-count goal.
+package stats
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// Counts are the word and character counts of some prose.
+type Counts struct {
+	Words int `json:"words"`
+	// Characters counts the characters of the words, not the whitespace
+	// between them.
+	Characters int `json:"characters"`
+}
+
+// Add returns the sum of c and o.
+func (c Counts) Add(o Counts) Counts {
+	return Counts{Words: c.Words + o.Words, Characters: c.Characters + o.Characters}
+}
+
+// Count counts the words and characters of text. A word is a run of
+// non-space characters containing a letter or digit, so Markdown list
+// markers, heading hashes, and scene breaks are not words.
+func Count(text []byte) Counts {
+	var c Counts
+	for _, w := range strings.Fields(string(text)) {
+		if strings.IndexFunc(w, isWordRune) >= 0 {
+			c.Words++
+			c.Characters += len([]rune(w))
+		}
+	}
+	return c
+}
+
+// CountNode counts the prose of a node file (see node.Prose).
+func CountNode(content []byte) Counts {
+	return Count(node.Prose(content))
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// NodeCounts are the counts of one binder entry.
+type NodeCounts struct {
+	Target string `json:"target,omitempty"`
+	Title  string `json:"title"`
+	// Own counts the entry's node file; it is zero for placeholders, for
+	// files counted where the target first appears, and for files that
+	// could not be read.
+	Own Counts `json:"own"`
+	// Total counts the entry's whole subtree, itself included.
+	Total    Counts        `json:"total"`
+	Children []*NodeCounts `json:"children"`
+}
+
+// Tally counts every entry under root. count returns the counts of a target's
+// node file and is called once per distinct target, in binder order. Tally
+// returns the counts of root's children and the total over the whole binder,
+// in which each file is counted once. It stops at the first error count
+// returns.
+func Tally(root *binder.Node, count func(target string) (Counts, error)) ([]*NodeCounts, Counts, error) {
+	seen := make(map[string]bool)
+	var tally func(nodes []*binder.Node) ([]*NodeCounts, Counts, error)
+	tally = func(nodes []*binder.Node) ([]*NodeCounts, Counts, error) {
+		out := make([]*NodeCounts, 0, len(nodes))
+		var sum Counts
+		for _, n := range nodes {
+			nc := &NodeCounts{Target: n.Target, Title: n.Title}
+			if n.Target != "" && !seen[n.Target] {
+				seen[n.Target] = true
+				own, err := count(n.Target)
+				if err != nil {
+					return nil, Counts{}, err
+				}
+				nc.Own = own
+			}
+			var childTotal Counts
+			var err error
+			if nc.Children, childTotal, err = tally(n.Children); err != nil {
+				return nil, Counts{}, err
+			}
+			nc.Total = nc.Own.Add(childTotal)
+			sum = sum.Add(nc.Total)
+			out = append(out, nc)
+		}
+		return out, sum, nil
+	}
+	return tally(root.Children)
+}
+
+// Progress is the state of a word count against a goal.
+type Progress struct {
+	Goal      int `json:"goal"`
+	Words     int `json:"words"`
+	Remaining int `json:"remaining"`
+	// Percent is the share of the goal reached, rounded down; it exceeds
+	// 100 once the goal is passed.
+	Percent int `json:"percent"`
+}
+
+// NewProgress reports words against a positive goal.
+func NewProgress(words, goal int) Progress {
+	return Progress{
+		Goal:      goal,
+		Words:     words,
+		Remaining: max(goal-words, 0),
+		Percent:   words * 100 / goal,
+	}
+}
@@ -0,0 +1,118 @@
+package stats
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+)
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		text string
+		want Counts
+	}{
+		{"It was a dark night.\n", Counts{Words: 5, Characters: 16}},
+		{"- one\n- two, three\n\n* * *\n", Counts{Words: 3, Characters: 12}},
+		{"# Café 42\n", Counts{Words: 2, Characters: 6}},
+		{"", Counts{}},
+	}
+	for _, tt := range tests {
+		if got := Count([]byte(tt.text)); got != tt.want {
+			t.Errorf("Count(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCountNode(t *testing.T) {
+	tests := []struct {
+		content string
+		want    int
+	}{
+		{"---\ntitle: Many words in the title\n---\n\n# Chapter One\n\nIt was a dark night.\n", 7},
+		{"Keep %% editor: cut this %% going <!-- note: and this --> on.\n", 3},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := CountNode([]byte(tt.content)).Words; got != tt.want {
+			t.Errorf("CountNode(%q).Words = %d, want %d", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestTally(t *testing.T) {
+	root := &binder.Node{Children: []*binder.Node{
+		{Target: "part.md", Title: "Part", Children: []*binder.Node{
+			{Target: "ch1.md", Title: "One"},
+			{Title: "Draft"},
+		}},
+		{Target: "ch1.md", Title: "One again"},
+	}}
+	words := map[string]int{"part.md": 2, "ch1.md": 10}
+	var calls []string
+	nodes, total, err := Tally(root, func(target string) (Counts, error) {
+		calls = append(calls, target)
+		return Counts{Words: words[target], Characters: 5 * words[target]}, nil
+	})
+	if err != nil {
+		t.Fatalf("Tally: %v", err)
+	}
+
+	if want := []string{"part.md", "ch1.md"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("count called for %v, want %v", calls, want)
+	}
+	if want := (Counts{Words: 12, Characters: 60}); total != want {
+		t.Errorf("total = %+v, want %+v", total, want)
+	}
+	if len(nodes) != 2 {
+		t.Fatalf("len(nodes) = %d, want 2", len(nodes))
+	}
+	part := nodes[0]
+	if part.Own.Words != 2 || part.Total.Words != 12 || len(part.Children) != 2 {
+		t.Errorf("part = %+v", part)
+	}
+	if draft := part.Children[1]; draft.Own != (Counts{}) || draft.Total != (Counts{}) || draft.Children == nil {
+		t.Errorf("placeholder = %+v", draft)
+	}
+	if again := nodes[1]; again.Own != (Counts{}) || again.Total != (Counts{}) {
+		t.Errorf("repeated target = %+v, want zero counts", again)
+	}
+}
+
+func TestTally_CountError(t *testing.T) {
+	root := &binder.Node{Children: []*binder.Node{
+		{Target: "part.md", Children: []*binder.Node{{Target: "locked.md"}}},
+		{Target: "after.md"},
+	}}
+	errLocked := errors.New("locked")
+	var calls []string
+	_, _, err := Tally(root, func(target string) (Counts, error) {
+		calls = append(calls, target)
+		if target == "locked.md" {
+			return Counts{}, errLocked
+		}
+		return Counts{Words: 1}, nil
+	})
+	if !errors.Is(err, errLocked) {
+		t.Errorf("err = %v, want the count error", err)
+	}
+	if want := []string{"part.md", "locked.md"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("count called for %v, want it to stop at %v", calls, want)
+	}
+}
+
+func TestNewProgress(t *testing.T) {
+	tests := []struct {
+		words, goal int
+		want        Progress
+	}{
+		{1234, 5000, Progress{Goal: 5000, Words: 1234, Remaining: 3766, Percent: 24}},
+		{6000, 5000, Progress{Goal: 5000, Words: 6000, Remaining: 0, Percent: 120}},
+	}
+	for _, tt := range tests {
+		if got := NewProgress(tt.words, tt.goal); got != tt.want {
+			t.Errorf("NewProgress(%d, %d) = %+v, want %+v", tt.words, tt.goal, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package stats
+
+// Chapter is a chapter and the word count of its subtree.
+type Chapter struct {
+	Title  string `json:"title"`
+	Target string `json:"target,omitempty"`
+	Words  int    `json:"words"`
+}
+
+// Structure summarizes the shape of a binder. Entries above the chapter
+// depth are parts, entries at it are chapters, and entries below it are
+// scenes.
+type Structure struct {
+	ChapterDepth int `json:"chapterDepth"`
+	// Depths counts the entries at each depth; Depths[0] is the top level.
+	Depths   []int `json:"depths"`
+	Parts    int   `json:"parts"`
+	Chapters int   `json:"chapters"`
+	// Scenes counts the scenes that have a node file; placeholder scenes are
+	// counted in Placeholders instead.
+	Scenes       int `json:"scenes"`
+	Placeholders int `json:"placeholders"`
+	// AverageSceneWords is the mean own word count of the Scenes, rounded
+	// down.
+	AverageSceneWords int `json:"averageSceneWords"`
+	// Longest and Shortest are the chapters with the most and fewest words,
+	// the first in binder order on a tie; nil when there are no chapters.
+	Longest  *Chapter `json:"longest,omitempty"`
+	Shortest *Chapter `json:"shortest,omitempty"`
+}
+
+// DefaultChapterDepth guesses the chapter depth of a binder whose deepest
+// entries are at maxDepth: part/chapter/scene for three or more levels,
+// chapter/scene for two, and chapters alone for one.
+func DefaultChapterDepth(maxDepth int) int {
+	return min(max(maxDepth-1, 1), 2)
+}
+
+// MaxDepth returns the depth of the deepest of nodes and their descendants,
+// counting nodes themselves as depth 1; it is 0 when nodes is empty.
+func MaxDepth(nodes []*NodeCounts) int {
+	deepest := 0
+	for _, n := range nodes {
+		deepest = max(deepest, 1+MaxDepth(n.Children))
+	}
+	return deepest
+}
+
+// Summarize computes the Structure of the tallied nodes (see Tally) with
+// chapters at the given 1-based depth.
+func Summarize(nodes []*NodeCounts, chapterDepth int) Structure {
+	s := Structure{ChapterDepth: chapterDepth, Depths: []int{}}
+	sceneWords := 0
+	var walk func(nodes []*NodeCounts, depth int)
+	walk = func(nodes []*NodeCounts, depth int) {
+		for _, n := range nodes {
+			if len(s.Depths) < depth {
+				s.Depths = append(s.Depths, 0)
+			}
+			s.Depths[depth-1]++
+			switch {
+			case depth < chapterDepth:
+				s.Parts++
+			case depth == chapterDepth:
+				s.Chapters++
+				ch := &Chapter{Title: n.Title, Target: n.Target, Words: n.Total.Words}
+				if s.Longest == nil || ch.Words > s.Longest.Words {
+					s.Longest = ch
+				}
+				if s.Shortest == nil || ch.Words < s.Shortest.Words {
+					s.Shortest = ch
+				}
+			case n.Target == "":
+				s.Placeholders++
+			default:
+				s.Scenes++
+				sceneWords += n.Own.Words
+			}
+			walk(n.Children, depth+1)
+		}
+	}
+	walk(nodes, 1)
+	if s.Scenes > 0 {
+		s.AverageSceneWords = sceneWords / s.Scenes
+	}
+	return s
+}
@@ -0,0 +1,59 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	scene := func(title, target string, n int) *NodeCounts {
+		return &NodeCounts{Title: title, Target: target, Own: Counts{Words: n}, Total: Counts{Words: n}}
+	}
+	chapter := func(title string, n int, scenes ...*NodeCounts) *NodeCounts {
+		return &NodeCounts{Title: title, Target: title + ".md", Total: Counts{Words: n}, Children: scenes}
+	}
+	nodes := []*NodeCounts{
+		{Title: "Part One", Children: []*NodeCounts{
+			chapter("One", 300, scene("a", "a.md", 100), scene("b", "b.md", 200)),
+			chapter("Two", 500, scene("c", "c.md", 500), scene("Draft", "", 0)),
+		}},
+		{Title: "Part Two", Children: []*NodeCounts{chapter("Three", 300)}},
+	}
+
+	if got := MaxDepth(nodes); got != 3 {
+		t.Errorf("MaxDepth = %d, want 3", got)
+	}
+	got := Summarize(nodes, 2)
+	want := Structure{
+		ChapterDepth:      2,
+		Depths:            []int{2, 3, 4},
+		Parts:             2,
+		Chapters:          3,
+		Scenes:            3,
+		Placeholders:      1,
+		AverageSceneWords: 266,
+		Longest:           &Chapter{Title: "Two", Target: "Two.md", Words: 500},
+		Shortest:          &Chapter{Title: "One", Target: "One.md", Words: 300},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Summarize =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestSummarize_Empty(t *testing.T) {
+	got := Summarize(nil, 1)
+	if want := (Structure{ChapterDepth: 1, Depths: []int{}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Summarize(nil) = %+v, want %+v", got, want)
+	}
+	if MaxDepth(nil) != 0 {
+		t.Errorf("MaxDepth(nil) = %d, want 0", MaxDepth(nil))
+	}
+}
+
+func TestDefaultChapterDepth(t *testing.T) {
+	for maxDepth, want := range map[int]int{0: 1, 1: 1, 2: 1, 3: 2, 5: 2} {
+		if got := DefaultChapterDepth(maxDepth); got != want {
+			t.Errorf("DefaultChapterDepth(%d) = %d, want %d", maxDepth, got, want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Package watch supports long-running modes that re-validate a project as
+// its files change. A Debouncer coalesces the bursts of change events that
+// a git checkout or a sync client produces, so such a mode rescans the
+// project once and reports one consolidated diagnostics update per burst.
+// Watch produces those events from the platform's file notifications, and
+// Poll, its fallback, by rescanning the project on an interval.
+package watch
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultWindow is the debounce window used when none is configured.
+const DefaultWindow = 250 * time.Millisecond
+
+// maxWaitWindows caps how long a steady stream of events can postpone a
+// flush, in multiples of the window.
+const maxWaitWindows = 10
+
+// timer is the part of *time.Timer a Debouncer uses.
+type timer interface {
+	Stop() bool
+}
+
+// Debouncer collects changed paths and hands them to a flush function once
+// no further change has arrived for a full window. Under a steady stream of
+// events it still flushes at least every maxWaitWindows windows, so updates
+// are never starved. Flushes never overlap.
+type Debouncer struct {
+	window time.Duration
+	flush  func(paths []string)
+	// afterFunc schedules f after d; it is time.AfterFunc outside tests.
+	afterFunc func(d time.Duration, f func()) timer
+
+	mu      sync.Mutex
+	pending map[string]bool
+	first   time.Time
+	timer   timer
+	now     func() time.Time
+	stopped bool
+
+	flushMu sync.Mutex
+}
+
+// NewDebouncer returns a Debouncer that calls flush with the sorted, distinct
+// paths changed during each burst. A window of zero or less selects
+// DefaultWindow.
+func NewDebouncer(window time.Duration, flush func(paths []string)) *Debouncer {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Debouncer{
+		window:  window,
+		flush:   flush,
+		pending: make(map[string]bool),
+		now:     time.Now,
+		afterFunc: func(d time.Duration, f func()) timer {
+			return time.AfterFunc(d, f)
+		},
+	}
+}
+
+// Add records a change to path and restarts the quiet-period timer. Adding
+// after Stop does nothing.
+func (d *Debouncer) Add(path string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		return
+	}
+	now := d.now()
+	if len(d.pending) == 0 {
+		d.first = now
+	}
+	d.pending[path] = true
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	wait := min(d.window, d.first.Add(maxWaitWindows*d.window).Sub(now))
+	d.timer = d.afterFunc(max(wait, 0), d.Flush)
+}
+
+// Flush hands any pending paths to the flush function immediately.
+func (d *Debouncer) Flush() {
+	d.flushMu.Lock()
+	defer d.flushMu.Unlock()
+	if paths := d.take(); len(paths) > 0 {
+		d.flush(paths)
+	}
+}
+
+// Stop cancels any scheduled flush and drops pending paths; later Adds are
+// ignored. Call Flush first to deliver what is pending.
+func (d *Debouncer) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stopped = true
+	d.takeLocked()
+}
+
+// take returns and clears the pending paths, cancelling the timer.
+func (d *Debouncer) take() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.takeLocked()
+}
+
+// takeLocked is take for callers already holding d.mu.
+func (d *Debouncer) takeLocked() []string {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	paths := make([]string, 0, len(d.pending))
+	for p := range d.pending {
+		paths = append(paths, p)
+	}
+	clear(d.pending)
+	slices.Sort(paths)
+	return paths
+}
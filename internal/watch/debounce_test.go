@@ -0,0 +1,110 @@
+package watch
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeTimer records a scheduled flush for the test to fire by hand.
+type fakeTimer struct {
+	delay   time.Duration
+	f       func()
+	stopped bool
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.stopped = true
+	return true
+}
+
+// newTestDebouncer returns a Debouncer on a fake clock, the flushed batches,
+// the scheduled timers, and a function advancing the clock.
+func newTestDebouncer(window time.Duration) (*Debouncer, *[][]string, *[]*fakeTimer, func(time.Duration)) {
+	var batches [][]string
+	var timers []*fakeTimer
+	now := time.Unix(0, 0)
+	d := NewDebouncer(window, func(paths []string) { batches = append(batches, paths) })
+	d.now = func() time.Time { return now }
+	d.afterFunc = func(delay time.Duration, f func()) timer {
+		t := &fakeTimer{delay: delay, f: f}
+		timers = append(timers, t)
+		return t
+	}
+	return d, &batches, &timers, func(step time.Duration) { now = now.Add(step) }
+}
+
+func TestDebouncer_CoalescesBurst(t *testing.T) {
+	d, batches, timers, advance := newTestDebouncer(100 * time.Millisecond)
+	for _, p := range []string{"b.md", "a.md", "b.md", "_binder.md"} {
+		d.Add(p)
+		advance(10 * time.Millisecond)
+	}
+	if len(*timers) != 4 {
+		t.Fatalf("timers = %d, want one per event", len(*timers))
+	}
+	for _, tm := range (*timers)[:3] {
+		if !tm.stopped || tm.delay != 100*time.Millisecond {
+			t.Errorf("superseded timer = %+v, want stopped with a full window", tm)
+		}
+	}
+	last := (*timers)[3]
+	last.f()
+	if want := [][]string{{"_binder.md", "a.md", "b.md"}}; !reflect.DeepEqual(*batches, want) {
+		t.Errorf("batches = %v, want %v", *batches, want)
+	}
+
+	last.f() // a stale timer firing again has nothing to flush
+	if len(*batches) != 1 {
+		t.Errorf("batches = %v, want no empty flush", *batches)
+	}
+}
+
+func TestDebouncer_MaxWait(t *testing.T) {
+	d, _, timers, advance := newTestDebouncer(100 * time.Millisecond)
+	d.Add("a.md")
+	advance(950 * time.Millisecond)
+	d.Add("a.md")
+	advance(100 * time.Millisecond)
+	d.Add("a.md")
+	if got := (*timers)[1].delay; got != 50*time.Millisecond {
+		t.Errorf("delay near the cap = %v, want 50ms", got)
+	}
+	if got := (*timers)[2].delay; got != 0 {
+		t.Errorf("delay past the cap = %v, want 0", got)
+	}
+}
+
+func TestDebouncer_FlushAndStop(t *testing.T) {
+	d, batches, timers, _ := newTestDebouncer(0)
+	if d.window != DefaultWindow {
+		t.Errorf("window = %v, want DefaultWindow", d.window)
+	}
+	d.Add("a.md")
+	d.Flush()
+	if len(*batches) != 1 || !(*timers)[0].stopped {
+		t.Errorf("after Flush: batches = %v, timer stopped = %v", *batches, (*timers)[0].stopped)
+	}
+
+	d.Add("b.md")
+	d.Stop()
+	d.Add("c.md")
+	d.Flush()
+	if len(*batches) != 1 || len(*timers) != 2 || !(*timers)[1].stopped {
+		t.Errorf("after Stop: batches = %v, timers = %d", *batches, len(*timers))
+	}
+}
+
+func TestDebouncer_RealTimer(t *testing.T) {
+	flushed := make(chan []string, 1)
+	d := NewDebouncer(time.Millisecond, func(paths []string) { flushed <- paths })
+	d.Add("a.md")
+	select {
+	case got := <-flushed:
+		if !reflect.DeepEqual(got, []string{"a.md"}) {
+			t.Errorf("flushed %v, want [a.md]", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no flush")
+	}
+}
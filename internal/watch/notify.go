@@ -0,0 +1,124 @@
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// newWatcher creates the platform file watcher; tests replace it.
+var newWatcher = fsnotify.NewWatcher
+
+// relPath is filepath.Rel; tests replace it to exercise its error.
+var relPath = filepath.Rel
+
+// Watch adds the paths of the files under dir that change to d until ctx is
+// done. It uses the platform's file notifications, and falls back to Poll
+// with interval where they are unavailable or dir cannot be watched, such as
+// when the system's limit on watches is reached. Like Scan it skips the
+// .prosemark bookkeeping directory.
+func Watch(ctx context.Context, dir string, interval time.Duration, d *Debouncer) error {
+	n, err := startNotify(dir)
+	if err != nil {
+		return Poll(ctx, dir, interval, d)
+	}
+	defer func() { _ = n.w.Close() }() // nothing is left to report to
+	return n.run(ctx, d)
+}
+
+// notifier reports the changes fsnotify sees under dir.
+type notifier struct {
+	w   *fsnotify.Watcher
+	dir string
+}
+
+// startNotify watches dir and every directory under it.
+func startNotify(dir string) (*notifier, error) {
+	w, err := newWatcher()
+	if err != nil {
+		return nil, err
+	}
+	n := &notifier{w: w, dir: dir}
+	if err := n.watchTree(dir, func(string) {}); err != nil {
+		_ = w.Close() // the watch error is the one worth reporting
+		return nil, err
+	}
+	return n, nil
+}
+
+// watchTree watches root and the directories under it, calling found with
+// the relative slash path of each file there. fsnotify watches are not
+// recursive, so each directory needs its own.
+func (n *notifier) watchTree(root string, found func(rel string)) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := relPath(n.dir, path)
+		if err != nil {
+			return err
+		}
+		if skipped(rel) {
+			return filepath.SkipDir
+		}
+		if d.IsDir() {
+			return n.w.Add(path)
+		}
+		found(filepath.ToSlash(rel))
+		return nil
+	})
+}
+
+// run adds each change to d until ctx is done or the watcher stops.
+func (n *notifier) run(ctx context.Context, d *Debouncer) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-n.w.Events:
+			if !ok {
+				return nil
+			}
+			n.handle(ev, d)
+		case _, ok := <-n.w.Errors:
+			// An error, such as the event queue overflowing, loses events but
+			// leaves the watches in place, so watching goes on.
+			if !ok {
+				return nil
+			}
+		}
+	}
+}
+
+// handle adds the file ev names to d. A new directory is watched in turn,
+// and the files already in it are added, since they may have been created
+// before its watch was.
+func (n *notifier) handle(ev fsnotify.Event, d *Debouncer) {
+	if ev.Op == fsnotify.Chmod {
+		return // Poll ignores permission changes too
+	}
+	rel, err := relPath(n.dir, ev.Name)
+	if err != nil || skipped(rel) {
+		return // only paths under dir are watched, so an error cannot happen
+	}
+	if ev.Has(fsnotify.Create) {
+		if info, err := os.Lstat(ev.Name); err == nil && info.IsDir() {
+			// A directory removed as soon as it appeared has nothing to report.
+			_ = n.watchTree(ev.Name, d.Add)
+			return
+		}
+	}
+	d.Add(filepath.ToSlash(rel))
+}
+
+// skipped reports whether the relative path rel lies in a .prosemark
+// directory.
+func skipped(rel string) bool {
+	return slices.Contains(strings.Split(filepath.ToSlash(rel), "/"), ".prosemark")
+}
@@ -0,0 +1,234 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// collect returns a Debouncer whose flushes are sent to the returned
+// channel.
+func collect() (*Debouncer, chan []string) {
+	changed := make(chan []string, 16)
+	return NewDebouncer(time.Millisecond, func(paths []string) { changed <- paths }), changed
+}
+
+// awaitPaths reads flushes from changed until every path in want has been
+// reported, failing the test when that takes too long.
+func awaitPaths(t *testing.T, changed <-chan []string, want ...string) []string {
+	t.Helper()
+	var got []string
+	timeout := time.After(5 * time.Second)
+	for {
+		done := true
+		for _, p := range want {
+			if !slices.Contains(got, p) {
+				done = false
+			}
+		}
+		if done {
+			return got
+		}
+		select {
+		case paths := <-changed:
+			got = append(got, paths...)
+		case <-timeout:
+			t.Fatalf("changed = %v, want %v", got, want)
+		}
+	}
+}
+
+// failRel makes relPath fail until the test ends.
+func failRel(t *testing.T) {
+	t.Helper()
+	orig := relPath
+	relPath = func(string, string) (string, error) { return "", errors.New("rel failed") }
+	t.Cleanup(func() { relPath = orig })
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, "a.md"), "a")
+	write(t, filepath.Join(dir, "part", "ch1.md"), "one")
+	write(t, filepath.Join(dir, ".prosemark", "journal.jsonl"), "")
+
+	d, changed := collect()
+	defer d.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- Watch(ctx, dir, time.Hour, d) }()
+	time.Sleep(50 * time.Millisecond) // let the watches be added
+
+	write(t, filepath.Join(dir, ".prosemark", "journal.jsonl"), "entry")
+	write(t, filepath.Join(dir, "a.md"), "edited")
+	write(t, filepath.Join(dir, "part", "ch1.md"), "edited")
+	write(t, filepath.Join(dir, "new", "deep", "ch2.md"), "two")
+	got := awaitPaths(t, changed, "a.md", "part/ch1.md", "new/deep/ch2.md")
+
+	// A directory created after watching began is watched in turn.
+	write(t, filepath.Join(dir, "new", "deep", "ch3.md"), "three")
+	got = append(got, awaitPaths(t, changed, "new/deep/ch3.md")...)
+	for _, p := range got {
+		if skipped(p) {
+			t.Errorf("reported %s, in .prosemark", p)
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Watch: %v", err)
+	}
+}
+
+func TestWatch_FallsBackToPoll(t *testing.T) {
+	tests := []struct {
+		name       string
+		newWatcher func() (*fsnotify.Watcher, error)
+	}{
+		{name: "no notifications", newWatcher: func() (*fsnotify.Watcher, error) { return nil, errors.New("unsupported") }},
+		{name: "watch fails", newWatcher: func() (*fsnotify.Watcher, error) {
+			w, err := fsnotify.NewWatcher()
+			if err != nil {
+				return nil, err
+			}
+			// A closed watcher rejects every watch, as a full one does.
+			return w, w.Close()
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orig := newWatcher
+			newWatcher = tt.newWatcher
+			t.Cleanup(func() { newWatcher = orig })
+
+			dir := t.TempDir()
+			d, changed := collect()
+			defer d.Stop()
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error)
+			go func() { done <- Watch(ctx, dir, 5*time.Millisecond, d) }()
+			time.Sleep(20 * time.Millisecond) // let the first scan happen
+
+			write(t, filepath.Join(dir, "b.md"), "b")
+			awaitPaths(t, changed, "b.md")
+			cancel()
+			if err := <-done; err != nil {
+				t.Errorf("Watch: %v", err)
+			}
+		})
+	}
+}
+
+func TestWatch_MissingDirectory(t *testing.T) {
+	d, _ := collect()
+	defer d.Stop()
+	if err := Watch(context.Background(), filepath.Join(t.TempDir(), "missing"), 0, d); err == nil {
+		t.Error("Watch of a missing directory: want an error")
+	}
+}
+
+func TestStartNotify_RelError(t *testing.T) {
+	failRel(t)
+	if _, err := startNotify(t.TempDir()); err == nil {
+		t.Error("startNotify with a failing relPath: want an error")
+	}
+}
+
+func TestNotifier_Run(t *testing.T) {
+	tests := []struct {
+		name string
+		stop func(w *fsnotify.Watcher, cancel context.CancelFunc)
+	}{
+		{name: "events closed", stop: func(w *fsnotify.Watcher, _ context.CancelFunc) { close(w.Events) }},
+		{name: "errors closed", stop: func(w *fsnotify.Watcher, _ context.CancelFunc) { close(w.Errors) }},
+		{name: "error then cancel", stop: func(w *fsnotify.Watcher, cancel context.CancelFunc) {
+			w.Errors <- errors.New("queue overflow")
+			cancel()
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &fsnotify.Watcher{Events: make(chan fsnotify.Event), Errors: make(chan error)}
+			n := &notifier{w: w, dir: "/proj"}
+			d, changed := collect()
+			defer d.Stop()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			done := make(chan error)
+			go func() { done <- n.run(ctx, d) }()
+
+			w.Events <- fsnotify.Event{Name: "/proj/a.md", Op: fsnotify.Write}
+			if got := awaitPaths(t, changed, "a.md"); !reflect.DeepEqual(got, []string{"a.md"}) {
+				t.Errorf("changed = %v, want [a.md]", got)
+			}
+			tt.stop(w, cancel)
+			if err := <-done; err != nil {
+				t.Errorf("run: %v", err)
+			}
+		})
+	}
+}
+
+func TestNotifier_Handle(t *testing.T) {
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, "a.md"), "a")
+	tests := []struct {
+		name    string
+		ev      fsnotify.Event
+		failRel bool
+		want    []string
+	}{
+		{name: "write", ev: fsnotify.Event{Name: filepath.Join(dir, "a.md"), Op: fsnotify.Write}, want: []string{"a.md"}},
+		{name: "create file", ev: fsnotify.Event{Name: filepath.Join(dir, "a.md"), Op: fsnotify.Create}, want: []string{"a.md"}},
+		{name: "remove", ev: fsnotify.Event{Name: filepath.Join(dir, "gone", "b.md"), Op: fsnotify.Remove}, want: []string{"gone/b.md"}},
+		{name: "vanished directory", ev: fsnotify.Event{Name: filepath.Join(dir, "gone"), Op: fsnotify.Create}, want: []string{"gone"}},
+		{name: "chmod", ev: fsnotify.Event{Name: filepath.Join(dir, "a.md"), Op: fsnotify.Chmod}},
+		{name: "bookkeeping", ev: fsnotify.Event{Name: filepath.Join(dir, ".prosemark", "journal.jsonl"), Op: fsnotify.Write}},
+		{name: "rel error", ev: fsnotify.Event{Name: filepath.Join(dir, "a.md"), Op: fsnotify.Write}, failRel: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.failRel {
+				failRel(t)
+			}
+			var got []string
+			d := NewDebouncer(time.Hour, func(paths []string) { got = paths })
+			n := &notifier{dir: dir}
+			n.handle(tt.ev, d)
+			d.Flush()
+			d.Stop()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("handle added %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScan_RelError(t *testing.T) {
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, "a.md"), "a")
+	failRel(t)
+	if _, err := Scan(dir); err == nil {
+		t.Error("Scan with a failing relPath: want an error")
+	}
+}
+
+func TestSkipped(t *testing.T) {
+	for rel, want := range map[string]bool{
+		"a.md":                    false,
+		".prosemark":              true,
+		".prosemark/journal.json": true,
+		"part/.prosemark/x":       true,
+		"prosemark.md":            false,
+	} {
+		if got := skipped(rel); got != want {
+			t.Errorf("skipped(%q) = %v, want %v", rel, got, want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// DefaultPollInterval is how often Poll rescans when no interval is given.
+const DefaultPollInterval = time.Second
+
+// Stamp is what a Snapshot records of a file to notice it changing.
+type Stamp struct {
+	Size    int64
+	ModTime time.Time
+}
+
+// Snapshot maps the slash paths of the files under a directory, relative to
+// it, to their stamps.
+type Snapshot map[string]Stamp
+
+// Scan snapshots the files under dir, skipping the .prosemark bookkeeping
+// directory, whose caches and journal change as pmk itself runs.
+func Scan(dir string) (Snapshot, error) {
+	snap := make(Snapshot)
+	err := filepath.WalkDir(dir, snap.add(dir))
+	return snap, err
+}
+
+// add returns the WalkDir function with which Scan records the files under
+// dir in s.
+func (s Snapshot) add(dir string) fs.WalkDirFunc {
+	return func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".prosemark" && path != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // removed since it was listed
+		}
+		rel, err := relPath(dir, path)
+		if err != nil {
+			return err
+		}
+		s[filepath.ToSlash(rel)] = Stamp{Size: info.Size(), ModTime: info.ModTime()}
+		return nil
+	}
+}
+
+// Changed returns the sorted paths added, removed, or modified between s
+// and next.
+func (s Snapshot) Changed(next Snapshot) []string {
+	var paths []string
+	for p, stamp := range next {
+		if old, ok := s[p]; !ok || old.Size != stamp.Size || !old.ModTime.Equal(stamp.ModTime) {
+			paths = append(paths, p)
+		}
+	}
+	for p := range s {
+		if _, ok := next[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// Poll rescans dir every interval (DefaultPollInterval when zero or less)
+// until ctx is done, adding each changed path to d. It returns an error only
+// when the first scan fails; a later failed scan is retried at the next
+// tick.
+func Poll(ctx context.Context, dir string, interval time.Duration, d *Debouncer) error {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	prev, err := Scan(dir)
+	if err != nil {
+		return err
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			next, err := Scan(dir)
+			if err != nil {
+				continue
+			}
+			for _, p := range prev.Changed(next) {
+				d.Add(p)
+			}
+			prev = next
+		}
+	}
+}
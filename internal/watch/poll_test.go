@@ -0,0 +1,109 @@
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, "_binder.md"), "b")
+	write(t, filepath.Join(dir, "part", "ch1.md"), "one")
+	write(t, filepath.Join(dir, ".prosemark", "journal.jsonl"), "")
+
+	snap, err := Scan(dir)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(snap) != 2 || snap["part/ch1.md"].Size != 3 {
+		t.Errorf("Scan = %v, want the two project files", snap)
+	}
+	if _, err := Scan(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Scan of a missing directory: want an error")
+	}
+}
+
+func TestSnapshot_Changed(t *testing.T) {
+	t0 := time.Unix(100, 0)
+	prev := Snapshot{"a.md": {1, t0}, "b.md": {1, t0}, "c.md": {1, t0}, "d.md": {1, t0}}
+	next := Snapshot{"a.md": {1, t0}, "b.md": {2, t0}, "c.md": {1, t0.Add(time.Second)}, "e.md": {1, t0}}
+	if got, want := prev.Changed(next), []string{"b.md", "c.md", "d.md", "e.md"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Changed = %v, want %v", got, want)
+	}
+}
+
+func TestPoll(t *testing.T) {
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, "a.md"), "a")
+
+	changed := make(chan []string, 1)
+	d := NewDebouncer(time.Millisecond, func(paths []string) { changed <- paths })
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- Poll(ctx, dir, 5*time.Millisecond, d) }()
+
+	time.Sleep(20 * time.Millisecond) // let the first scan happen
+	write(t, filepath.Join(dir, "b.md"), "b")
+	select {
+	case paths := <-changed:
+		if !reflect.DeepEqual(paths, []string{"b.md"}) {
+			t.Errorf("changed = %v, want [b.md]", paths)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Poll: %v", err)
+	}
+}
+
+func TestPoll_Errors(t *testing.T) {
+	d := NewDebouncer(0, func([]string) {})
+	if err := Poll(context.Background(), filepath.Join(t.TempDir(), "missing"), 0, d); err == nil {
+		t.Error("Poll of a missing directory: want an error")
+	}
+
+	// A directory that disappears is retried rather than ending the poll.
+	dir := filepath.Join(t.TempDir(), "p")
+	write(t, filepath.Join(dir, "a.md"), "a")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- Poll(ctx, dir, 5*time.Millisecond, d) }()
+	time.Sleep(10 * time.Millisecond)
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Poll after removal: %v", err)
+	}
+}
+
+// vanishedEntry is a file listed by WalkDir but removed before its Info.
+type vanishedEntry struct{ fs.DirEntry }
+
+func (vanishedEntry) IsDir() bool                { return false }
+func (vanishedEntry) Info() (fs.FileInfo, error) { return nil, fs.ErrNotExist }
+
+func TestSnapshot_Add_SkipsVanishedFile(t *testing.T) {
+	snap := make(Snapshot)
+	if err := snap.add("/proj")("/proj/a.md", vanishedEntry{}, nil); err != nil || len(snap) != 0 {
+		t.Errorf("add = %v, snapshot %v; want the file skipped", err, snap)
+	}
+}
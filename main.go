@@ -16,6 +16,7 @@ var (
 )
 
 func main() {
+	cmd.SetBuildInfo(cmd.BuildInfo{Version: Version, Commit: Commit, BuildDate: BuildDate})
 	rootCmd := cmd.NewRootCmd()
 	rootCmd.Version = Version
 	if err := rootCmd.Execute(); err != nil {
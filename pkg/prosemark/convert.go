@@ -0,0 +1,106 @@
+package prosemark
+
+import (
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// internal returns p as pmk's own project type, keeping the settings
+// ScanProject found. A nil p stays nil.
+func (p *Project) internal() *binder.Project {
+	if p == nil {
+		return nil
+	}
+	var out binder.Project
+	if p.scanned != nil {
+		out = *p.scanned
+	}
+	out.Files, out.BinderDir = p.Files, p.BinderDir
+	return &out
+}
+
+// nodeOf returns the tree under n as this package's Node.
+func nodeOf(n *binder.Node) *Node {
+	out := &Node{Type: n.Type, Target: n.Target, Title: n.Title, Checked: n.Checked, Line: n.Line, Children: make([]*Node, len(n.Children))}
+	for i, c := range n.Children {
+		out.Children[i] = nodeOf(c)
+	}
+	return out
+}
+
+// diagnosticsOf returns diags as this package's Diagnostics, never nil.
+func diagnosticsOf(diags []binder.Diagnostic) []Diagnostic {
+	out := make([]Diagnostic, len(diags))
+	for i, d := range diags {
+		out[i] = Diagnostic{Severity: d.Severity, Code: d.Code, Message: d.Message}
+		if d.Location != nil {
+			loc := Location(*d.Location)
+			out[i].Location = &loc
+		}
+	}
+	return out
+}
+
+// entriesOf returns entries as this package's AffectedEntries; nil stays
+// nil.
+func entriesOf(entries []binder.AffectedEntry) []AffectedEntry {
+	if entries == nil {
+		return nil
+	}
+	out := make([]AffectedEntry, len(entries))
+	for i, e := range entries {
+		out[i] = AffectedEntry(e)
+	}
+	return out
+}
+
+// auditDiagnosticsOf returns diags as this package's AuditDiagnostics.
+func auditDiagnosticsOf(diags []node.AuditDiagnostic) []AuditDiagnostic {
+	if diags == nil {
+		return nil
+	}
+	out := make([]AuditDiagnostic, len(diags))
+	for i, d := range diags {
+		out[i] = AuditDiagnostic{Code: AuditCode(d.Code), Severity: AuditSeverity(d.Severity), Message: d.Message, Path: d.Path, Line: d.Line}
+	}
+	return out
+}
+
+// internal returns p as pmk's own parameters.
+func (p AddChildParams) internal() binder.AddChildParams {
+	return binder.AddChildParams{
+		ParentSelector: p.ParentSelector, Target: p.Target, Title: p.Title,
+		Position: p.Position, At: p.At, Before: p.Before, After: p.After,
+		Force: p.Force, Style: p.Style, ForceParse: p.ForceParse,
+	}
+}
+
+// internal returns p as pmk's own parameters.
+func (p DeleteParams) internal() binder.DeleteParams {
+	return binder.DeleteParams{Selector: p.Selector, Yes: p.Yes, ForceParse: p.ForceParse}
+}
+
+// internal returns p as pmk's own parameters.
+func (p MoveParams) internal() binder.MoveParams {
+	return binder.MoveParams{
+		SourceSelector: p.SourceSelector, DestinationParentSelector: p.DestinationParentSelector,
+		Position: p.Position, At: p.At, Before: p.Before, After: p.After,
+		Yes: p.Yes, PreserveExtras: p.PreserveExtras, ForceParse: p.ForceParse,
+	}
+}
+
+// internal returns p as pmk's own parameters.
+func (p ShiftParams) internal() binder.ShiftParams {
+	return binder.ShiftParams{Selector: p.Selector, ForceParse: p.ForceParse}
+}
+
+// internal returns p as pmk's own parameters.
+func (p RetitleParams) internal() binder.RetitleParams {
+	return binder.RetitleParams{Selector: p.Selector, Title: p.Title, ForceParse: p.ForceParse}
+}
+
+// internal returns o as pmk's own options.
+func (o DoctorOptions) internal() core.DoctorOptions {
+	return core.DoctorOptions{Subset: o.Subset, NoCache: o.NoCache}
+}
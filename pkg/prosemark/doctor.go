@@ -0,0 +1,71 @@
+package prosemark
+
+import (
+	"context"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
+	"github.com/eykd/prosemark-go/internal/node"
+)
+
+// AuditDiagnostic is a finding of Doctor.
+type AuditDiagnostic struct {
+	Code     AuditCode
+	Severity AuditSeverity
+	Message  string
+	Path     string // file the finding is about, relative to the project root
+	Line     int    // 1-based line in Path, or 0 for the whole file
+}
+
+// AuditCode identifies the rule behind an AuditDiagnostic, e.g. "AUD001".
+type AuditCode string
+
+// AuditSeverity is "error" or "warning".
+type AuditSeverity string
+
+// DoctorOptions adjust a Doctor audit.
+type DoctorOptions struct {
+	// Subset, when non-nil, limits the findings returned to these
+	// project-relative slash paths; the whole project is still audited.
+	Subset map[string]bool
+	// NoCache re-validates every node file instead of reusing cached
+	// results for unchanged files.
+	NoCache bool
+}
+
+// Doctor audits the project whose binder is binderPath, as pmk doctor does,
+// and like it caches frontmatter results in the project's .prosemark
+// directory unless opts.NoCache is set.
+func Doctor(ctx context.Context, binderPath string, opts DoctorOptions) ([]AuditDiagnostic, error) {
+	diags, err := core.Doctor(ctx, fileDoctorIO{}, binderPath, opts.internal())
+	return auditDiagnosticsOf(diags), err
+}
+
+// fileDoctorIO implements core.DoctorIO using OS file I/O.
+type fileDoctorIO struct{}
+
+// ReadBinder reads the binder file at path.
+func (fileDoctorIO) ReadBinder(path string) ([]byte, error) {
+	return fsio.ReadBinder(path)
+}
+
+// ListNodeFiles returns the node filenames of scheme found in dir.
+func (fileDoctorIO) ListNodeFiles(dir string, scheme node.IDScheme) ([]string, error) {
+	return fsio.ListFiles(dir, scheme.MatchFilename)
+}
+
+// ReadNodeFile reads the file at path, reporting whether it exists.
+func (fileDoctorIO) ReadNodeFile(path string) ([]byte, bool, error) {
+	return fsio.ReadFileIfExists(path)
+}
+
+// ScanProject scans the project directory for .md files.
+func (fileDoctorIO) ScanProject(ctx context.Context, binderPath string) (*binder.Project, error) {
+	return fsio.ScanProject(ctx, binderPath)
+}
+
+// WriteCache writes a cache file atomically, creating its directory.
+func (fileDoctorIO) WriteCache(path string, data []byte) error {
+	return fsio.WriteFileAtomicMkdir(path, ".doctor-cache", data)
+}
@@ -0,0 +1,258 @@
+// Package prosemark is the Go API to prosemark binders for editor plugins
+// and other tools that embed prosemark instead of running pmk: parsing,
+// structural edits, and project audits.
+//
+// The types are this package's own, converted from the ones pmk uses
+// internally, and encode to the JSON that pmk's --json output and the
+// binder schemas document. The names, signatures, and fields here are
+// stable; the packages behind them are not.
+package prosemark
+
+import (
+	"context"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/internal/binder/ops"
+	"github.com/eykd/prosemark-go/internal/core"
+	"github.com/eykd/prosemark-go/internal/fsio"
+)
+
+// Node is an entry of the binder tree; the root has Type "root".
+type Node struct {
+	Type     string  `json:"type"`              // "root" | "node" | "placeholder"
+	Target   string  `json:"target,omitempty"`  // linked file, relative to the project root
+	Title    string  `json:"title,omitempty"`   // link text
+	Checked  *bool   `json:"checked,omitempty"` // task state: nil when the entry has no checkbox
+	Children []*Node `json:"children"`          // never nil
+	Line     int     `json:"-"`                 // 1-based line of the entry's list item
+}
+
+// ParseResult is a parsed binder.
+type ParseResult struct {
+	Version string `json:"version"` // "1"
+	Root    *Node  `json:"root"`
+}
+
+// Project lists the files a binder's links resolve against. A Project from
+// ScanProject also carries the project's .prosemark.yml settings, such as
+// its severity overrides, to the functions it is passed to.
+type Project struct {
+	Files     []string // node files, relative to the project root
+	BinderDir string   // directory of the binder, relative to the project root
+
+	scanned *binder.Project
+}
+
+// Diagnostic is an error, warning, or info record from a parse or edit.
+type Diagnostic struct {
+	Severity string    `json:"severity"` // SeverityError, SeverityWarning, or SeverityInfo
+	Code     string    `json:"code"`     // e.g. "BNDW004", "OPE001"
+	Message  string    `json:"message"`
+	Location *Location `json:"location,omitempty"` // nil when the record has no source position
+}
+
+// Location is a position in a binder file.
+type Location struct {
+	Line       int `json:"line"`       // 1-based
+	Column     int `json:"column"`     // 1-based
+	ByteOffset int `json:"byteOffset"` // 0-based from the start of the file
+}
+
+// OpResult is the outcome of an edit.
+type OpResult struct {
+	Version     string       `json:"version"` // "1"
+	Changed     bool         `json:"changed"` // the binder's content, not only its blank lines or line endings, changed
+	Diagnostics []Diagnostic `json:"diagnostics"`
+	// Normalized lists the incidental normalizations that came with the
+	// edit: "blank-lines" and "line-endings".
+	Normalized []string        `json:"normalized,omitempty"`
+	Entries    []AffectedEntry `json:"entries,omitempty"`
+}
+
+// AffectedEntry locates an entry an edit inserted, moved, or removed in the
+// binder the edit returned.
+type AffectedEntry struct {
+	Target string `json:"target"`
+	Title  string `json:"title"`
+	Line   int    `json:"line"`           // 1-based; for a removed entry, where it stood
+	Path   []int  `json:"path,omitempty"` // 0-based child indices from the root; absent for removed entries
+}
+
+// AddChildParams are parameters for AddChild.
+type AddChildParams struct {
+	ParentSelector string // selector of the parent entry; "." for the root
+	Target         string // file the new entry links to
+	Title          string // link text; empty derives it from Target
+	Position       string // "last" (the default) or "first"
+	At             *int   // 0-based index among the parent's children
+	Before, After  string // selector of the sibling to insert next to
+	Force          bool   // add even when the parent already has an entry for Target
+	Style          string // link style: "inline" (the default), "reference", "wikilink", or "auto"
+	ForceParse     bool   // edit even though the binder has parse errors
+}
+
+// DeleteParams are parameters for Delete.
+type DeleteParams struct {
+	Selector   string // selector of the entries to remove
+	Yes        bool   // confirms the removal; required
+	ForceParse bool   // edit even though the binder has parse errors
+}
+
+// MoveParams are parameters for Move.
+type MoveParams struct {
+	SourceSelector            string // selector of the entries to move
+	DestinationParentSelector string // selector of the new parent; "." for the root
+	Position                  string // "last" (the default) or "first"
+	At                        *int   // 0-based index among the new parent's children
+	Before, After             string // selector of the sibling to move next to
+	Yes                       bool   // confirms the move; required
+	PreserveExtras            bool   // carry a moved entry's extra text along instead of dropping it
+	ForceParse                bool   // edit even though the binder has parse errors
+}
+
+// ShiftParams are parameters for Promote and Demote.
+type ShiftParams struct {
+	Selector   string // selector of the entry to shift
+	ForceParse bool   // edit even though the binder has parse errors
+}
+
+// RetitleParams are parameters for Retitle.
+type RetitleParams struct {
+	Selector   string // selector of the entries to retitle
+	Title      string // the new link text
+	ForceParse bool   // edit even though the binder has parse errors
+}
+
+// Diagnostic severities.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// HasErrors reports whether any of diags is an error.
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadBinder reads the binder file at path.
+func ReadBinder(path string) ([]byte, error) {
+	return fsio.ReadBinder(path)
+}
+
+// ScanProject lists the files of the project whose binder is binderPath.
+func ScanProject(ctx context.Context, binderPath string) (*Project, error) {
+	p, err := fsio.ScanProject(ctx, binderPath)
+	if err != nil {
+		return nil, err
+	}
+	return &Project{Files: p.Files, BinderDir: p.BinderDir, scanned: p}, nil
+}
+
+// Parse parses the binder src, resolving its links against project, which
+// may be nil. The error is non-nil only when parsing was cancelled.
+func Parse(ctx context.Context, src []byte, project *Project) (*ParseResult, []Diagnostic, error) {
+	if project == nil {
+		project = &Project{BinderDir: "."}
+	}
+	result, diags, err := binder.Parse(ctx, src, project.internal())
+	return &ParseResult{Version: result.Version, Root: nodeOf(result.Root)}, diagnosticsOf(diags), err
+}
+
+// Transaction applies AddChild, Move, and Delete edits to one binder, all or
+// nothing. Each edit runs against the binder the one before it left; once
+// one reports an error the transaction has failed, later edits do nothing,
+// and Bytes returns the binder as it began.
+type Transaction struct {
+	tx *ops.Transaction
+}
+
+// NewTransaction starts a transaction on the binder src.
+func NewTransaction(ctx context.Context, src []byte, project *Project) *Transaction {
+	return &Transaction{tx: ops.NewTransaction(ctx, src, project.internal())}
+}
+
+// AddChild adds an entry, returning the inserted entries and the edit's
+// diagnostics.
+func (t *Transaction) AddChild(params AddChildParams) ([]AffectedEntry, []Diagnostic) {
+	entries, diags := t.tx.AddChild(params.internal())
+	return entriesOf(entries), diagnosticsOf(diags)
+}
+
+// Move moves entries, returning the moved entries and the edit's
+// diagnostics.
+func (t *Transaction) Move(params MoveParams) ([]AffectedEntry, []Diagnostic) {
+	entries, diags := t.tx.Move(params.internal())
+	return entriesOf(entries), diagnosticsOf(diags)
+}
+
+// Delete removes entries and their subtrees, returning the removed entries
+// and the edit's diagnostics.
+func (t *Transaction) Delete(params DeleteParams) ([]AffectedEntry, []Diagnostic) {
+	entries, diags := t.tx.Delete(params.internal())
+	return entriesOf(entries), diagnosticsOf(diags)
+}
+
+// Failed reports whether an edit has reported an error.
+func (t *Transaction) Failed() bool {
+	return t.tx.Failed()
+}
+
+// Bytes returns the binder as the edits left it, or as it began when the
+// transaction has failed.
+func (t *Transaction) Bytes() []byte {
+	return t.tx.Bytes()
+}
+
+// AddChild adds an entry to the binder src and returns the new binder. When
+// the result has an error diagnostic the binder is returned unchanged.
+func AddChild(ctx context.Context, src []byte, project *Project, params AddChildParams) ([]byte, *OpResult) {
+	return edit(src)(ops.AddChildEntries(ctx, src, project.internal(), params.internal()))
+}
+
+// Delete removes entries and their subtrees from the binder src.
+func Delete(ctx context.Context, src []byte, project *Project, params DeleteParams) ([]byte, *OpResult) {
+	return edit(src)(ops.DeleteEntries(ctx, src, project.internal(), params.internal()))
+}
+
+// Move moves entries within the binder src.
+func Move(ctx context.Context, src []byte, project *Project, params MoveParams) ([]byte, *OpResult) {
+	return edit(src)(ops.MoveEntries(ctx, src, project.internal(), params.internal()))
+}
+
+// Promote moves an entry up one level in the binder src.
+func Promote(ctx context.Context, src []byte, project *Project, params ShiftParams) ([]byte, *OpResult) {
+	return edit(src)(ops.Promote(ctx, src, project.internal(), params.internal()))
+}
+
+// Demote moves an entry down one level in the binder src.
+func Demote(ctx context.Context, src []byte, project *Project, params ShiftParams) ([]byte, *OpResult) {
+	return edit(src)(ops.Demote(ctx, src, project.internal(), params.internal()))
+}
+
+// Retitle sets the title of entries in the binder src.
+func Retitle(ctx context.Context, src []byte, project *Project, params RetitleParams) ([]byte, *OpResult) {
+	return edit(src)(ops.Retitle(ctx, src, project.internal(), params.internal()))
+}
+
+// edit returns a function that turns an operation's output on src into the
+// binder and OpResult the edit functions return.
+func edit(src []byte) func([]byte, []binder.AffectedEntry, []binder.Diagnostic) ([]byte, *OpResult) {
+	return func(out []byte, entries []binder.AffectedEntry, diags []binder.Diagnostic) ([]byte, *OpResult) {
+		res := &OpResult{Version: "1", Diagnostics: diagnosticsOf(diags)}
+		if HasErrors(res.Diagnostics) {
+			return src, res
+		}
+		res.Changed, res.Normalized = core.ClassifyEdit(src, out)
+		if !res.Changed {
+			out = src
+		}
+		res.Entries = entriesOf(entries)
+		return out, res
+	}
+}
@@ -0,0 +1,257 @@
+package prosemark_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/eykd/prosemark-go/internal/binder"
+	"github.com/eykd/prosemark-go/pkg/prosemark"
+)
+
+const testBinder = "<!-- prosemark-binder:v1 -->\n\n- [One](ch1.md)\n- [Two](ch2.md)\n"
+
+func testProject() *prosemark.Project {
+	return &prosemark.Project{Files: []string{"ch1.md", "ch2.md", "ch3.md"}, BinderDir: "."}
+}
+
+func TestParse(t *testing.T) {
+	result, diags, err := prosemark.Parse(context.Background(), []byte(testBinder), nil)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := len(result.Root.Children); got != 2 {
+		t.Errorf("top-level entries = %d, want 2", got)
+	}
+	// Without a project every target is missing.
+	if len(diags) != 2 || diags[0].Code != "BNDW004" || prosemark.HasErrors(diags) {
+		t.Errorf("diags = %v, want two missing-file warnings", diags)
+	}
+
+	if _, diags, _ := prosemark.Parse(context.Background(), []byte(testBinder), testProject()); len(diags) != 0 {
+		t.Errorf("diags with project = %v, want none", diags)
+	}
+}
+
+func TestEdits(t *testing.T) {
+	ctx := context.Background()
+	src := []byte(testBinder)
+	tests := []struct {
+		name string
+		run  func() ([]byte, *prosemark.OpResult)
+		want string
+	}{
+		{
+			"add",
+			func() ([]byte, *prosemark.OpResult) {
+				return prosemark.AddChild(ctx, src, testProject(), prosemark.AddChildParams{ParentSelector: ".", Target: "ch3.md", Title: "Three"})
+			},
+			testBinder + "- [Three](ch3.md)\n",
+		},
+		{
+			"delete",
+			func() ([]byte, *prosemark.OpResult) {
+				return prosemark.Delete(ctx, src, testProject(), prosemark.DeleteParams{Selector: "ch1.md", Yes: true})
+			},
+			"<!-- prosemark-binder:v1 -->\n\n- [Two](ch2.md)\n",
+		},
+		{
+			"move",
+			func() ([]byte, *prosemark.OpResult) {
+				return prosemark.Move(ctx, src, testProject(), prosemark.MoveParams{SourceSelector: "ch2.md", DestinationParentSelector: ".", Position: "first", Yes: true})
+			},
+			"<!-- prosemark-binder:v1 -->\n\n- [Two](ch2.md)\n- [One](ch1.md)\n",
+		},
+		{
+			"demote",
+			func() ([]byte, *prosemark.OpResult) {
+				return prosemark.Demote(ctx, src, testProject(), prosemark.ShiftParams{Selector: "ch2.md"})
+			},
+			"<!-- prosemark-binder:v1 -->\n\n- [One](ch1.md)\n  - [Two](ch2.md)\n",
+		},
+		{
+			"promote",
+			func() ([]byte, *prosemark.OpResult) {
+				nested := []byte("<!-- prosemark-binder:v1 -->\n\n- [One](ch1.md)\n  - [Two](ch2.md)\n")
+				return prosemark.Promote(ctx, nested, testProject(), prosemark.ShiftParams{Selector: "ch2.md"})
+			},
+			testBinder,
+		},
+		{
+			"retitle",
+			func() ([]byte, *prosemark.OpResult) {
+				return prosemark.Retitle(ctx, src, testProject(), prosemark.RetitleParams{Selector: "ch1.md", Title: "First"})
+			},
+			"<!-- prosemark-binder:v1 -->\n\n- [First](ch1.md)\n- [Two](ch2.md)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, result := tt.run()
+			if string(out) != tt.want {
+				t.Errorf("binder = %q, want %q", out, tt.want)
+			}
+			if !result.Changed || len(result.Entries) == 0 || result.Diagnostics == nil {
+				t.Errorf("result = %+v, want a change with entries", result)
+			}
+		})
+	}
+}
+
+func TestEdit_ErrorLeavesBinderUnchanged(t *testing.T) {
+	out, result := prosemark.Delete(context.Background(), []byte(testBinder), testProject(), prosemark.DeleteParams{Selector: "nope.md", Yes: true})
+	if string(out) != testBinder {
+		t.Errorf("binder = %q, want it unchanged", out)
+	}
+	if result.Changed || !prosemark.HasErrors(result.Diagnostics) || result.Diagnostics[0].Code != "OPE001" {
+		t.Errorf("result = %+v, want an unchanged OPE001 result", result)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded["version"] != "1" || decoded["changed"] != false {
+		t.Errorf("JSON = %s, want the op-result schema", data)
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	tx := prosemark.NewTransaction(context.Background(), []byte(testBinder), testProject())
+	tx.AddChild(prosemark.AddChildParams{ParentSelector: ".", Target: "ch3.md"})
+	moved, _ := tx.Move(prosemark.MoveParams{SourceSelector: "ch3.md", DestinationParentSelector: ".", Position: "first", Yes: true})
+	if want := []prosemark.AffectedEntry{{Target: "ch3.md", Title: "ch3", Line: 3, Path: []int{0}}}; !reflect.DeepEqual(moved, want) {
+		t.Errorf("Move entries = %+v, want %+v", moved, want)
+	}
+	tx.Delete(prosemark.DeleteParams{Selector: "ch1.md", Yes: true})
+	if tx.Failed() {
+		t.Fatal("transaction failed")
+	}
+	if want := "<!-- prosemark-binder:v1 -->\n\n- [ch3](ch3.md)\n- [Two](ch2.md)\n"; string(tx.Bytes()) != want {
+		t.Errorf("Bytes = %q, want %q", tx.Bytes(), want)
+	}
+
+	entries, diags := tx.AddChild(prosemark.AddChildParams{ParentSelector: "nope.md", Target: "ch1.md"})
+	if entries != nil || !prosemark.HasErrors(diags) || !tx.Failed() {
+		t.Errorf("AddChild under a missing parent = %v, %v; want a failed transaction", entries, diags)
+	}
+}
+
+func TestEdit_Unchanged(t *testing.T) {
+	src := []byte(testBinder)
+	out, result := prosemark.AddChild(context.Background(), src, nil, prosemark.AddChildParams{ParentSelector: ".", Target: "ch1.md"})
+	if string(out) != testBinder || result.Changed || len(result.Diagnostics) == 0 || result.Diagnostics[0].Code != "OPW002" {
+		t.Errorf("AddChild of an existing entry = %q, %+v; want the binder unchanged with OPW002", out, result)
+	}
+}
+
+// TestJSONMatchesPmk verifies that the package's types encode as pmk's own
+// do, so their JSON is the one the schemas document.
+func TestJSONMatchesPmk(t *testing.T) {
+	ctx := context.Background()
+	src := []byte("<!-- prosemark-binder:v1 -->\n\n- [x] [One](ch1.md)\n  - [Two](ch2.md)\n- [Three](gone.md)\n")
+	project := testProject()
+	result, diags, err := prosemark.Parse(ctx, src, project)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want, wantDiags, err := binder.Parse(ctx, src, &binder.Project{Files: project.Files, BinderDir: project.BinderDir})
+	if err != nil {
+		t.Fatalf("binder.Parse: %v", err)
+	}
+	for _, tt := range []struct {
+		name      string
+		got, want any
+	}{
+		{"parse result", result, want},
+		{"diagnostics", diags, wantDiags},
+	} {
+		got, err := json.Marshal(tt.got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantJSON, err := json.Marshal(tt.want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(wantJSON) {
+			t.Errorf("%s JSON = %s, want %s", tt.name, got, wantJSON)
+		}
+	}
+	if diags[0].Location == nil || diags[0].Location.Line != 5 {
+		t.Errorf("diagnostic location = %+v, want line 5", diags[0].Location)
+	}
+	if got := result.Root.Children[0]; got.Line != 3 || got.Checked == nil || !*got.Checked {
+		t.Errorf("first entry = %+v, want a checked entry on line 3", got)
+	}
+}
+
+const testNodeFile = "01890a5d-ac96-774b-bcce-b302099a8057.md"
+
+func TestFileProject(t *testing.T) {
+	dir := t.TempDir()
+	binderPath := filepath.Join(dir, "_binder.md")
+	binderSrc := "<!-- prosemark-binder:v1 -->\n\n- [One](" + testNodeFile + ")\n- [Two](ch2.md)\n"
+	for name, content := range map[string]string{
+		"_binder.md":     binderSrc,
+		testNodeFile:     "---\nid: 01890a5d-ac96-774b-bcce-b302099a8057\ntitle: One\n---\n[gone](gone.md)\n",
+		".prosemark.yml": "diagnostics:\n  BNDW004: error\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+
+	src, err := prosemark.ReadBinder(binderPath)
+	if err != nil || string(src) != binderSrc {
+		t.Fatalf("ReadBinder = %q, %v", src, err)
+	}
+	project, err := prosemark.ScanProject(ctx, binderPath)
+	if err != nil {
+		t.Fatalf("ScanProject: %v", err)
+	}
+	if want := []string{testNodeFile}; !reflect.DeepEqual(project.Files, want) {
+		t.Errorf("Files = %v, want %v", project.Files, want)
+	}
+	// The scanned project keeps .prosemark.yml's severity overrides.
+	if _, diags, _ := prosemark.Parse(ctx, src, project); !prosemark.HasErrors(diags) {
+		t.Errorf("Parse with the scanned project = %v, want the missing ch2.md promoted to an error", diags)
+	}
+
+	diags, err := prosemark.Doctor(ctx, binderPath, prosemark.DoctorOptions{})
+	if err != nil {
+		t.Fatalf("Doctor: %v", err)
+	}
+	var missing, located bool
+	for _, d := range diags {
+		if d.Code == "AUD001" && d.Path == "ch2.md" {
+			missing = true
+		}
+		if d.Code == "AUD011" && d.Line == 5 {
+			located = true
+		}
+	}
+	if !missing {
+		t.Errorf("Doctor = %+v, want AUD001 for ch2.md", diags)
+	}
+	if !located {
+		t.Errorf("Doctor = %+v, want AUD011 on line 5", diags)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".prosemark", "cache", "doctor.json")); err != nil {
+		t.Errorf("doctor cache not written: %v", err)
+	}
+}
+
+func TestFileProject_Errors(t *testing.T) {
+	binderPath := filepath.Join(t.TempDir(), "missing", "_binder.md")
+	if project, err := prosemark.ScanProject(context.Background(), binderPath); err == nil {
+		t.Errorf("ScanProject = %+v, want an error for a missing project", project)
+	}
+	if diags, err := prosemark.Doctor(context.Background(), binderPath, prosemark.DoctorOptions{NoCache: true}); err == nil || diags != nil {
+		t.Errorf("Doctor = %+v, %v; want an error for a missing project", diags, err)
+	}
+}